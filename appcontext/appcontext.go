@@ -0,0 +1,242 @@
+// Package appcontext standardizes the request-scoped values of a service —
+// request ID, correlation ID, tenant, user, locale and deadline budget —
+// behind one typed accessor API. Values stored with it are also visible to
+// the loggers of observability/logger, the recording span of the context and
+// the tenancy package; Clone copies them into a context for goroutines that
+// outlive the request; and the HTTP and gRPC helpers propagate them between
+// services.
+package appcontext
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	loginterfaces "github.com/fsvxavier/nexs-lib/observability/logger/interfaces"
+	"github.com/fsvxavier/nexs-lib/tenancy"
+)
+
+// Span attributes of the values. The tenant uses tenancy.SpanAttribute.
+const (
+	SpanAttributeRequestID     = "request.id"
+	SpanAttributeCorrelationID = "correlation.id"
+	SpanAttributeUserID        = "enduser.id"
+	SpanAttributeLocale        = "locale"
+)
+
+// Values are the request-scoped values of a context. Empty fields are unset.
+type Values struct {
+	// RequestID identifies a single request, as received or generated by
+	// the middleware.
+	RequestID string
+	// CorrelationID identifies the flow the request belongs to, across
+	// services and messages. It defaults to the first request ID of the flow.
+	CorrelationID string
+	// TenantID is the tenant of the request.
+	TenantID string
+	// UserID is the authenticated user.
+	UserID string
+	// Locale is the language of the request, e.g. "pt-BR".
+	Locale string
+}
+
+// IsZero reports whether no value is set.
+func (v Values) IsZero() bool {
+	return v == Values{}
+}
+
+// merge returns v with the non-empty fields of other.
+func (v Values) merge(other Values) Values {
+	if other.RequestID != "" {
+		v.RequestID = other.RequestID
+	}
+	if other.CorrelationID != "" {
+		v.CorrelationID = other.CorrelationID
+	}
+	if other.TenantID != "" {
+		v.TenantID = other.TenantID
+	}
+	if other.UserID != "" {
+		v.UserID = other.UserID
+	}
+	if other.Locale != "" {
+		v.Locale = other.Locale
+	}
+	return v
+}
+
+// valuesKey is the context key of the values.
+type valuesKey struct{}
+
+// With returns a context carrying the values of ctx overridden by the
+// non-empty fields of v. The request, user and tenant IDs are also set
+// under the keys read by observability/logger, the tenant with
+// tenancy.WithTenant, and the new values become attributes of the recording
+// span of ctx.
+func With(ctx context.Context, v Values) context.Context {
+	if v.IsZero() {
+		return ctx
+	}
+	ctx = context.WithValue(ctx, valuesKey{}, From(ctx).merge(v))
+
+	if v.RequestID != "" {
+		ctx = context.WithValue(ctx, loginterfaces.RequestIDKey, v.RequestID)
+	}
+	if v.UserID != "" {
+		ctx = context.WithValue(ctx, loginterfaces.UserIDKey, v.UserID)
+	}
+	if v.TenantID != "" {
+		ctx = tenancy.WithTenant(ctx, v.TenantID)
+	}
+	if span := trace.SpanFromContext(ctx); span.IsRecording() {
+		span.SetAttributes(spanAttributes(Values{
+			RequestID:     v.RequestID,
+			CorrelationID: v.CorrelationID,
+			UserID:        v.UserID,
+			Locale:        v.Locale,
+		})...)
+	}
+	return ctx
+}
+
+// From returns the values of the context. The tenant falls back to the one
+// set with tenancy.WithTenant.
+func From(ctx context.Context) Values {
+	v, _ := ctx.Value(valuesKey{}).(Values)
+	if v.TenantID == "" {
+		v.TenantID, _ = tenancy.FromContext(ctx)
+	}
+	return v
+}
+
+// WithRequestID returns a context carrying the request ID.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return With(ctx, Values{RequestID: requestID})
+}
+
+// RequestID returns the request ID of the context.
+func RequestID(ctx context.Context) string {
+	return From(ctx).RequestID
+}
+
+// WithCorrelationID returns a context carrying the correlation ID.
+func WithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	return With(ctx, Values{CorrelationID: correlationID})
+}
+
+// CorrelationID returns the correlation ID of the context.
+func CorrelationID(ctx context.Context) string {
+	return From(ctx).CorrelationID
+}
+
+// WithTenantID returns a context carrying the tenant.
+func WithTenantID(ctx context.Context, tenantID string) context.Context {
+	return With(ctx, Values{TenantID: tenantID})
+}
+
+// TenantID returns the tenant of the context.
+func TenantID(ctx context.Context) string {
+	return From(ctx).TenantID
+}
+
+// WithUserID returns a context carrying the user.
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return With(ctx, Values{UserID: userID})
+}
+
+// UserID returns the user of the context.
+func UserID(ctx context.Context) string {
+	return From(ctx).UserID
+}
+
+// WithLocale returns a context carrying the locale.
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return With(ctx, Values{Locale: locale})
+}
+
+// Locale returns the locale of the context.
+func Locale(ctx context.Context) string {
+	return From(ctx).Locale
+}
+
+// WithBudget returns a context whose deadline is at most budget from now,
+// the time left to serve the request. An earlier deadline of ctx is kept.
+func WithBudget(ctx context.Context, budget time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, budget)
+}
+
+// Budget returns the time left before the deadline of the context, or false
+// when it has none. An expired deadline returns a zero budget.
+func Budget(ctx context.Context) (time.Duration, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return max(time.Until(deadline), 0), true
+}
+
+// Clone returns a context for a goroutine that outlives the request: it
+// carries the values and the span of ctx but neither its deadline nor its
+// cancellation, and none of its other values.
+func Clone(ctx context.Context) context.Context {
+	clone := context.Background()
+	if span := trace.SpanFromContext(ctx); span.SpanContext().IsValid() {
+		clone = trace.ContextWithSpan(clone, span)
+	}
+	return With(clone, From(ctx))
+}
+
+// SpanAttributes returns the span attributes of the values of the context,
+// for spans started before the values were known.
+func SpanAttributes(ctx context.Context) []attribute.KeyValue {
+	return spanAttributes(From(ctx))
+}
+
+func spanAttributes(v Values) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if v.RequestID != "" {
+		attrs = append(attrs, attribute.String(SpanAttributeRequestID, v.RequestID))
+	}
+	if v.CorrelationID != "" {
+		attrs = append(attrs, attribute.String(SpanAttributeCorrelationID, v.CorrelationID))
+	}
+	if v.TenantID != "" {
+		attrs = append(attrs, attribute.String(tenancy.SpanAttribute, v.TenantID))
+	}
+	if v.UserID != "" {
+		attrs = append(attrs, attribute.String(SpanAttributeUserID, v.UserID))
+	}
+	if v.Locale != "" {
+		attrs = append(attrs, attribute.String(SpanAttributeLocale, v.Locale))
+	}
+	return attrs
+}
+
+// LogAttrs returns the values of the context as slog attributes, for
+// loggers other than those of observability/logger.
+func LogAttrs(ctx context.Context) []slog.Attr {
+	v := From(ctx)
+	var attrs []slog.Attr
+	if v.RequestID != "" {
+		attrs = append(attrs, slog.String(string(loginterfaces.RequestIDKey), v.RequestID))
+	}
+	if v.CorrelationID != "" {
+		attrs = append(attrs, slog.String("correlation_id", v.CorrelationID))
+	}
+	if v.TenantID != "" {
+		attrs = append(attrs, slog.String(string(loginterfaces.TenantIDKey), v.TenantID))
+	}
+	if v.UserID != "" {
+		attrs = append(attrs, slog.String(string(loginterfaces.UserIDKey), v.UserID))
+	}
+	if v.Locale != "" {
+		attrs = append(attrs, slog.String("locale", v.Locale))
+	}
+	if budget, ok := Budget(ctx); ok {
+		attrs = append(attrs, slog.Duration("budget", budget))
+	}
+	return attrs
+}
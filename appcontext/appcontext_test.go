@@ -0,0 +1,275 @@
+package appcontext
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	loginterfaces "github.com/fsvxavier/nexs-lib/observability/logger/interfaces"
+	"github.com/fsvxavier/nexs-lib/tenancy"
+)
+
+func TestWith(t *testing.T) {
+	ctx := context.Background()
+	assert.True(t, From(ctx).IsZero())
+
+	ctx = With(ctx, Values{RequestID: "req-1", CorrelationID: "corr-1", TenantID: "acme"})
+	ctx = WithUserID(ctx, "user-1")
+	ctx = WithLocale(ctx, "pt-BR")
+	ctx = WithRequestID(ctx, "req-2")
+
+	assert.Equal(t, Values{
+		RequestID:     "req-2",
+		CorrelationID: "corr-1",
+		TenantID:      "acme",
+		UserID:        "user-1",
+		Locale:        "pt-BR",
+	}, From(ctx))
+	assert.Equal(t, "req-2", RequestID(ctx))
+	assert.Equal(t, "corr-1", CorrelationID(ctx))
+	assert.Equal(t, "acme", TenantID(ctx))
+	assert.Equal(t, "user-1", UserID(ctx))
+	assert.Equal(t, "pt-BR", Locale(ctx))
+
+	// The values are visible to the logger and tenancy packages.
+	assert.Equal(t, "req-2", ctx.Value(loginterfaces.RequestIDKey))
+	assert.Equal(t, "user-1", ctx.Value(loginterfaces.UserIDKey))
+	assert.Equal(t, "acme", ctx.Value(loginterfaces.TenantIDKey))
+	tenantID, err := tenancy.Require(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "acme", tenantID)
+
+	// The tenant set by tenancy is visible to appcontext.
+	assert.Equal(t, "globex", TenantID(tenancy.WithTenant(context.Background(), "globex")))
+}
+
+func TestWith_Span(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, span := provider.Tracer("test").Start(context.Background(), "request")
+
+	ctx = With(ctx, Values{RequestID: "req-1", TenantID: "acme", UserID: "user-1"})
+	span.End()
+
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.String(SpanAttributeRequestID, "req-1"),
+		attribute.String(tenancy.SpanAttribute, "acme"),
+		attribute.String(SpanAttributeUserID, "user-1"),
+	}, SpanAttributes(ctx))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	attrs := spans[0].Attributes()
+	assert.Contains(t, attrs, attribute.String(SpanAttributeRequestID, "req-1"))
+	assert.Contains(t, attrs, attribute.String(tenancy.SpanAttribute, "acme"))
+	assert.Contains(t, attrs, attribute.String(SpanAttributeUserID, "user-1"))
+}
+
+func TestBudget(t *testing.T) {
+	_, ok := Budget(context.Background())
+	assert.False(t, ok)
+
+	ctx, cancel := WithBudget(context.Background(), time.Minute)
+	defer cancel()
+	budget, ok := Budget(ctx)
+	assert.True(t, ok)
+	assert.InDelta(t, time.Minute, budget, float64(time.Second))
+
+	// An earlier deadline is kept.
+	shorter, cancelShorter := WithBudget(ctx, time.Hour)
+	defer cancelShorter()
+	budget, _ = Budget(shorter)
+	assert.LessOrEqual(t, budget, time.Minute)
+
+	expired, cancelExpired := WithBudget(context.Background(), -time.Second)
+	defer cancelExpired()
+	budget, ok = Budget(expired)
+	assert.True(t, ok)
+	assert.Zero(t, budget)
+}
+
+func TestClone(t *testing.T) {
+	provider := sdktrace.NewTracerProvider()
+	ctx, span := provider.Tracer("test").Start(context.Background(), "request")
+	defer span.End()
+
+	type otherKey struct{}
+	ctx = context.WithValue(ctx, otherKey{}, "other")
+	ctx = With(ctx, Values{RequestID: "req-1", TenantID: "acme", Locale: "en"})
+	ctx, cancel := WithBudget(ctx, time.Minute)
+
+	clone := Clone(ctx)
+	cancel()
+
+	require.Error(t, ctx.Err())
+	assert.NoError(t, clone.Err())
+	_, hasDeadline := clone.Deadline()
+	assert.False(t, hasDeadline)
+	assert.Nil(t, clone.Value(otherKey{}))
+
+	assert.Equal(t, From(ctx), From(clone))
+	assert.Equal(t, "req-1", clone.Value(loginterfaces.RequestIDKey))
+	tenantID, _ := tenancy.FromContext(clone)
+	assert.Equal(t, "acme", tenantID)
+	assert.Equal(t, span.SpanContext(), trace.SpanContextFromContext(clone))
+}
+
+func TestLogAttrs(t *testing.T) {
+	ctx := With(context.Background(), Values{RequestID: "req-1", CorrelationID: "corr-1"})
+	attrs := LogAttrs(ctx)
+	require.Len(t, attrs, 2)
+	assert.Equal(t, "request_id", attrs[0].Key)
+	assert.Equal(t, "req-1", attrs[0].Value.String())
+	assert.Equal(t, "correlation_id", attrs[1].Key)
+}
+
+func TestValuesFromHTTP(t *testing.T) {
+	header := http.Header{}
+	header.Set(HeaderRequestID, " req-1 ")
+	header.Set(HeaderCorrelationID, "corr\x01")
+	header.Set(HeaderTenantID, "acme")
+	header.Set(HeaderUserID, string(make([]byte, maxValueLength+1)))
+	header.Set(HeaderLocale, "pt-BR,pt;q=0.9,en;q=0.8")
+
+	assert.Equal(t, Values{RequestID: "req-1", TenantID: "acme", Locale: "pt-BR"}, ValuesFromHTTP(header))
+
+	header.Set(HeaderLocale, "*")
+	assert.Empty(t, ValuesFromHTTP(header).Locale)
+
+	_, ok := BudgetFromHTTP(header)
+	assert.False(t, ok)
+	header.Set(HeaderBudget, "1500")
+	budget, ok := BudgetFromHTTP(header)
+	assert.True(t, ok)
+	assert.Equal(t, 1500*time.Millisecond, budget)
+	header.Set(HeaderBudget, "-1")
+	_, ok = BudgetFromHTTP(header)
+	assert.False(t, ok)
+}
+
+func TestMiddleware(t *testing.T) {
+	var got Values
+	var budget time.Duration
+	var hasBudget bool
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = From(r.Context())
+		budget, hasBudget = Budget(r.Context())
+	})
+
+	t.Run("Generated", func(t *testing.T) {
+		m := New(Config{GenerateRequestID: func() string { return "generated" }})
+		rec := httptest.NewRecorder()
+		m.Handler(handler).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, Values{RequestID: "generated", CorrelationID: "generated"}, got)
+		assert.Equal(t, "generated", rec.Header().Get(HeaderRequestID))
+		assert.False(t, hasBudget)
+	})
+
+	t.Run("Propagated", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(HeaderRequestID, "req-1")
+		req.Header.Set(HeaderCorrelationID, "corr-1")
+		req.Header.Set(HeaderTenantID, "acme")
+		req.Header.Set(HeaderUserID, "user-1")
+		req.Header.Set(HeaderLocale, "en-US")
+
+		New(Config{}).Handler(handler).ServeHTTP(httptest.NewRecorder(), req)
+		assert.Equal(t, Values{RequestID: "req-1", CorrelationID: "corr-1", Locale: "en-US"}, got)
+
+		New(Config{TrustPropagated: true}).Handler(handler).ServeHTTP(httptest.NewRecorder(), req)
+		assert.Equal(t, Values{RequestID: "req-1", CorrelationID: "corr-1", TenantID: "acme", UserID: "user-1", Locale: "en-US"}, got)
+
+		req.Header.Set(HeaderTenantID, "not a tenant")
+		New(Config{TrustPropagated: true}).Handler(handler).ServeHTTP(httptest.NewRecorder(), req)
+		assert.Empty(t, got.TenantID)
+	})
+
+	t.Run("Budget", func(t *testing.T) {
+		m := New(Config{DefaultBudget: time.Second, MaxBudget: 5 * time.Second})
+
+		m.Handler(handler).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.True(t, hasBudget)
+		assert.LessOrEqual(t, budget, time.Second)
+		assert.Greater(t, budget, 500*time.Millisecond)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(HeaderBudget, "60000")
+		m.Handler(handler).ServeHTTP(httptest.NewRecorder(), req)
+		assert.True(t, hasBudget)
+		assert.LessOrEqual(t, budget, 5*time.Second)
+		assert.Greater(t, budget, 4*time.Second)
+	})
+}
+
+func TestTransport(t *testing.T) {
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	ctx := With(context.Background(), Values{RequestID: "req-1", CorrelationID: "corr-1", TenantID: "acme", Locale: "pt-BR"})
+	ctx, cancel := WithBudget(ctx, time.Minute)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set(HeaderLocale, "en")
+
+	resp, err := (&http.Client{Transport: Transport(nil)}).Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, "req-1", header.Get(HeaderRequestID))
+	assert.Equal(t, "corr-1", header.Get(HeaderCorrelationID))
+	assert.Equal(t, "acme", header.Get(HeaderTenantID))
+	assert.Empty(t, header.Get(HeaderUserID))
+	assert.Equal(t, "en", header.Get(HeaderLocale))
+	assert.NotEmpty(t, header.Get(HeaderBudget))
+	assert.Empty(t, req.Header.Get(HeaderRequestID))
+}
+
+func TestGRPC(t *testing.T) {
+	ctx := With(context.Background(), Values{RequestID: "req-1", CorrelationID: "corr-1", TenantID: "acme"})
+	ctx = metadata.AppendToOutgoingContext(ctx, MetadataCorrelationID, "corr-override")
+
+	var outgoing metadata.MD
+	invoker := func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		outgoing, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	}
+	require.NoError(t, UnaryClientInterceptor()(ctx, "/svc/Method", nil, nil, nil, invoker))
+	assert.Equal(t, []string{"req-1"}, outgoing.Get(MetadataRequestID))
+	assert.Equal(t, []string{"corr-override"}, outgoing.Get(MetadataCorrelationID))
+	assert.Equal(t, []string{"acme"}, outgoing.Get(MetadataTenantID))
+
+	incoming := metadata.NewIncomingContext(context.Background(), outgoing)
+	incoming, cancel := context.WithTimeout(incoming, time.Hour)
+	defer cancel()
+
+	var got Values
+	var budget time.Duration
+	handler := func(ctx context.Context, _ any) (any, error) {
+		got = From(ctx)
+		budget, _ = Budget(ctx)
+		return nil, nil
+	}
+	m := New(Config{TrustPropagated: true, MaxBudget: time.Minute})
+	_, err := m.UnaryServerInterceptor()(incoming, nil, &grpc.UnaryServerInfo{FullMethod: "/svc/Method"}, handler)
+	require.NoError(t, err)
+
+	assert.Equal(t, Values{RequestID: "req-1", CorrelationID: "corr-override", TenantID: "acme"}, got)
+	assert.LessOrEqual(t, budget, time.Minute)
+}
@@ -0,0 +1,116 @@
+package appcontext
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/fsvxavier/nexs-lib/tenancy"
+)
+
+// gRPC metadata keys propagating the values. Budgets travel as gRPC
+// deadlines.
+const (
+	MetadataRequestID     = "x-request-id"
+	MetadataCorrelationID = "x-correlation-id"
+	MetadataTenantID      = tenancy.MessageHeaderTenantID
+	MetadataUserID        = "x-user-id"
+	MetadataLocale        = "accept-language"
+)
+
+// InjectMetadata returns a context whose outgoing metadata carries the
+// values of ctx. Keys already in the outgoing metadata are kept.
+func InjectMetadata(ctx context.Context) context.Context {
+	v := From(ctx)
+	md, _ := metadata.FromOutgoingContext(ctx)
+	var kv []string
+	for _, pair := range [][2]string{
+		{MetadataRequestID, v.RequestID},
+		{MetadataCorrelationID, v.CorrelationID},
+		{MetadataTenantID, v.TenantID},
+		{MetadataUserID, v.UserID},
+		{MetadataLocale, v.Locale},
+	} {
+		if pair[1] != "" && len(md.Get(pair[0])) == 0 {
+			kv = append(kv, pair[0], pair[1])
+		}
+	}
+	if len(kv) == 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, kv...)
+}
+
+// ValuesFromMetadata returns the values propagated in incoming metadata.
+func ValuesFromMetadata(md metadata.MD) Values {
+	get := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+	return Values{
+		RequestID:     clean(get(MetadataRequestID)),
+		CorrelationID: clean(get(MetadataCorrelationID)),
+		TenantID:      clean(get(MetadataTenantID)),
+		UserID:        clean(get(MetadataUserID)),
+		Locale:        firstLanguage(get(MetadataLocale)),
+	}
+}
+
+// UnaryServerInterceptor sets the values of the incoming metadata in the
+// context of calls. The gRPC deadline is the budget; DefaultBudget and
+// MaxBudget apply to it. The request ID is sent back in the response header.
+func (m *Middleware) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, cancel := m.enterGRPC(ctx)
+		defer cancel()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is the stream version of UnaryServerInterceptor.
+func (m *Middleware) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, cancel := m.enterGRPC(ss.Context())
+		defer cancel()
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// enterGRPC returns the context of an incoming call.
+func (m *Middleware) enterGRPC(ctx context.Context) (context.Context, context.CancelFunc) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	budget, hasBudget := Budget(ctx)
+	ctx, cancel := m.enter(ctx, ValuesFromMetadata(md), budget, hasBudget)
+	// Without a server transport stream, as in direct calls of the
+	// interceptor, there is no header to set.
+	_ = grpc.SetHeader(ctx, metadata.Pairs(MetadataRequestID, RequestID(ctx)))
+	return ctx, cancel
+}
+
+// UnaryClientInterceptor propagates the values of the context to the
+// called service.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(InjectMetadata(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor is the stream version of UnaryClientInterceptor.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(InjectMetadata(ctx), desc, cc, method, opts...)
+	}
+}
+
+// contextStream overrides the context of a server stream.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,192 @@
+package appcontext
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/fsvxavier/nexs-lib/tenancy"
+)
+
+// HTTP headers propagating the values.
+const (
+	HeaderRequestID     = "X-Request-ID"
+	HeaderCorrelationID = "X-Correlation-ID"
+	HeaderTenantID      = tenancy.HeaderTenantID
+	HeaderUserID        = "X-User-ID"
+	HeaderLocale        = "Accept-Language"
+	// HeaderBudget carries the deadline budget in milliseconds.
+	HeaderBudget = "X-Request-Budget"
+)
+
+// maxValueLength bounds the length of propagated values.
+const maxValueLength = 128
+
+// InjectHTTP sets the values and the budget of the context in the headers
+// of an outgoing request. Headers already set are kept.
+func InjectHTTP(ctx context.Context, header http.Header) {
+	v := From(ctx)
+	setHeader(header, HeaderRequestID, v.RequestID)
+	setHeader(header, HeaderCorrelationID, v.CorrelationID)
+	setHeader(header, HeaderTenantID, v.TenantID)
+	setHeader(header, HeaderUserID, v.UserID)
+	setHeader(header, HeaderLocale, v.Locale)
+	if budget, ok := Budget(ctx); ok {
+		setHeader(header, HeaderBudget, strconv.FormatInt(budget.Milliseconds(), 10))
+	}
+}
+
+func setHeader(header http.Header, name, value string) {
+	if value != "" && header.Get(name) == "" {
+		header.Set(name, value)
+	}
+}
+
+// ValuesFromHTTP returns the values propagated in the headers of a request.
+// Values too long or with non-printable characters are ignored, and the
+// locale is the first language of Accept-Language.
+func ValuesFromHTTP(header http.Header) Values {
+	return Values{
+		RequestID:     clean(header.Get(HeaderRequestID)),
+		CorrelationID: clean(header.Get(HeaderCorrelationID)),
+		TenantID:      clean(header.Get(HeaderTenantID)),
+		UserID:        clean(header.Get(HeaderUserID)),
+		Locale:        firstLanguage(header.Get(HeaderLocale)),
+	}
+}
+
+// BudgetFromHTTP returns the budget propagated in the headers of a request.
+func BudgetFromHTTP(header http.Header) (time.Duration, bool) {
+	ms, err := strconv.ParseInt(strings.TrimSpace(header.Get(HeaderBudget)), 10, 64)
+	if err != nil || ms < 0 {
+		return 0, false
+	}
+	return time.Duration(ms) * time.Millisecond, true
+}
+
+// clean returns value trimmed, or "" when it is not a safe propagated value.
+func clean(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) > maxValueLength {
+		return ""
+	}
+	for i := 0; i < len(value); i++ {
+		if value[i] < 0x20 || value[i] > 0x7e {
+			return ""
+		}
+	}
+	return value
+}
+
+// firstLanguage returns the first language tag of an Accept-Language value.
+func firstLanguage(value string) string {
+	tag, _, _ := strings.Cut(value, ",")
+	tag, _, _ = strings.Cut(tag, ";")
+	tag = clean(tag)
+	if tag == "*" {
+		return ""
+	}
+	return tag
+}
+
+// Config configures the Middleware.
+type Config struct {
+	// GenerateRequestID creates the ID of requests without one. Defaults to
+	// random UUIDs.
+	GenerateRequestID func() string
+
+	// TrustPropagated accepts the tenant and user propagated by the caller.
+	// Enable it only for services reachable from trusted callers; otherwise
+	// the tenant and user come from the tenancy and authentication layers.
+	TrustPropagated bool
+
+	// DefaultBudget is the budget of requests without one. Zero leaves them
+	// without deadline.
+	DefaultBudget time.Duration
+
+	// MaxBudget caps propagated budgets. Zero disables the cap.
+	MaxBudget time.Duration
+}
+
+// DefaultConfig returns a default middleware configuration.
+func DefaultConfig() Config {
+	return Config{GenerateRequestID: uuid.NewString}
+}
+
+// Middleware sets the values propagated by callers in the context of HTTP
+// requests and gRPC calls.
+type Middleware struct {
+	config Config
+}
+
+// New creates a Middleware. Zero values of the configuration take defaults.
+func New(config Config) *Middleware {
+	if config.GenerateRequestID == nil {
+		config.GenerateRequestID = DefaultConfig().GenerateRequestID
+	}
+	return &Middleware{config: config}
+}
+
+// Handler wraps the next handler. The request ID is echoed in the
+// X-Request-ID response header.
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		budget, hasBudget := BudgetFromHTTP(r.Header)
+		ctx, cancel := m.enter(r.Context(), ValuesFromHTTP(r.Header), budget, hasBudget)
+		defer cancel()
+
+		w.Header().Set(HeaderRequestID, RequestID(ctx))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// enter returns the context of an incoming request.
+func (m *Middleware) enter(ctx context.Context, v Values, budget time.Duration, hasBudget bool) (context.Context, context.CancelFunc) {
+	if !m.config.TrustPropagated {
+		v.TenantID, v.UserID = "", ""
+	}
+	if v.TenantID != "" && tenancy.Validate(v.TenantID) != nil {
+		v.TenantID = ""
+	}
+	if v.RequestID == "" {
+		v.RequestID = m.config.GenerateRequestID()
+	}
+	if v.CorrelationID == "" {
+		v.CorrelationID = v.RequestID
+	}
+	ctx = With(ctx, v)
+
+	if !hasBudget && m.config.DefaultBudget > 0 {
+		budget, hasBudget = m.config.DefaultBudget, true
+	}
+	if hasBudget && m.config.MaxBudget > 0 && budget > m.config.MaxBudget {
+		budget = m.config.MaxBudget
+	}
+	if !hasBudget {
+		return ctx, func() {}
+	}
+	return WithBudget(ctx, budget)
+}
+
+// Transport propagates the values and budget of the request context to
+// outgoing requests. A nil base uses http.DefaultTransport.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		r = r.Clone(r.Context())
+		InjectHTTP(r.Context(), r.Header)
+		return base.RoundTrip(r)
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
@@ -0,0 +1,242 @@
+// Package authz checks permissions with role-based access control. A Policy
+// declares roles, their permissions and the resources they apply to; an
+// Authorizer checks the subject of a context against a required permission
+// and reports denials as Authorization domain errors carrying the required
+// and current roles, ready for HTTP or gRPC error mapping.
+package authz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/fsvxavier/nexs-lib/httpserver/middlewares"
+)
+
+// Authorization errors. The domain errors returned by the Authorizer wrap
+// them, so errors.Is can be used to tell failures apart.
+var (
+	ErrNoSubject        = errors.New("authz: no subject in context")
+	ErrPermissionDenied = errors.New("authz: permission denied")
+)
+
+// Subject is the authenticated caller being authorized.
+type Subject struct {
+	ID         string
+	Roles      []string
+	Attributes map[string]string
+}
+
+// attribute returns a subject attribute; "id" is the subject ID.
+func (s *Subject) attribute(name string) (string, bool) {
+	if name == "id" {
+		return s.ID, s.ID != ""
+	}
+	value, ok := s.Attributes[name]
+	return value, ok && value != ""
+}
+
+// subjectKey is the context key of the subject.
+type subjectKey struct{}
+
+// WithSubject returns a context carrying the subject.
+func WithSubject(ctx context.Context, subject *Subject) context.Context {
+	return context.WithValue(ctx, subjectKey{}, subject)
+}
+
+// SubjectFromContext returns the subject set by WithSubject.
+func SubjectFromContext(ctx context.Context) (*Subject, bool) {
+	subject, ok := ctx.Value(subjectKey{}).(*Subject)
+	return subject, ok && subject != nil
+}
+
+// Option configures an Authorizer.
+type Option func(*Authorizer)
+
+// WithSubjectResolver sets how the subject is taken from a context, e.g. from
+// the claims injected by an authentication middleware. Defaults to
+// SubjectFromContext.
+func WithSubjectResolver(resolve func(context.Context) (*Subject, bool)) Option {
+	return func(a *Authorizer) {
+		a.resolve = resolve
+	}
+}
+
+// Authorizer checks permissions against a policy. It is safe for concurrent
+// use, and SetPolicy can replace the policy while requests are checked.
+type Authorizer struct {
+	mu      sync.RWMutex
+	policy  Policy
+	grants  map[string][]grant
+	resolve func(context.Context) (*Subject, bool)
+}
+
+// New creates an Authorizer for the policy.
+func New(policy Policy, opts ...Option) (*Authorizer, error) {
+	grants, err := compile(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Authorizer{
+		policy:  policy,
+		grants:  grants,
+		resolve: SubjectFromContext,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a, nil
+}
+
+// Policy returns the current policy.
+func (a *Authorizer) Policy() Policy {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.policy
+}
+
+// SetPolicy replaces the policy. The current one is kept when the new policy
+// is invalid.
+func (a *Authorizer) SetPolicy(policy Policy) error {
+	grants, err := compile(policy)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.policy = policy
+	a.grants = grants
+	return nil
+}
+
+// Can reports whether the subject has the permission on the resource. An
+// empty resource only matches roles without resource restrictions.
+func (a *Authorizer) Can(subject *Subject, permission, resource string) bool {
+	if subject == nil {
+		return false
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	for _, role := range subject.Roles {
+		if a.roleCan(role, permission, resource, subject) {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorize checks the subject of the context against the permission on the
+// resource. It returns an Authentication domain error when the context has no
+// subject and an Authorization domain error when the permission is denied.
+func (a *Authorizer) Authorize(ctx context.Context, permission, resource string) error {
+	subject, ok := a.resolve(ctx)
+	if !ok {
+		return domainerrors.New(interfaces.AuthenticationError, "AUTHZ_NO_SUBJECT", "authentication required").Wrap(ErrNoSubject)
+	}
+	if a.Can(subject, permission, resource) {
+		return nil
+	}
+
+	metadata := map[string]interface{}{
+		"user_id":             subject.ID,
+		"required_permission": permission,
+		"required_roles":      a.RolesFor(permission, resource, subject),
+		"current_roles":       subject.Roles,
+	}
+	if resource != "" {
+		metadata["resource"] = resource
+	}
+	return domainerrors.NewWithMetadata(interfaces.AuthorizationError, "INSUFFICIENT_PERMISSIONS",
+		fmt.Sprintf("permission %s required", permission), metadata).Wrap(ErrPermissionDenied)
+}
+
+// RequireRole checks that the subject of the context has one of the roles.
+// Roles are compared by name, inheritance is not considered.
+func (a *Authorizer) RequireRole(ctx context.Context, roles ...string) error {
+	subject, ok := a.resolve(ctx)
+	if !ok {
+		return domainerrors.New(interfaces.AuthenticationError, "AUTHZ_NO_SUBJECT", "authentication required").Wrap(ErrNoSubject)
+	}
+	for _, required := range roles {
+		for _, role := range subject.Roles {
+			if role == required {
+				return nil
+			}
+		}
+	}
+
+	return domainerrors.NewWithMetadata(interfaces.AuthorizationError, "INSUFFICIENT_PERMISSIONS",
+		"role required", map[string]interface{}{
+			"user_id":        subject.ID,
+			"required_roles": roles,
+			"current_roles":  subject.Roles,
+		}).Wrap(ErrPermissionDenied)
+}
+
+// RolesFor returns the roles that grant the permission on the resource to the
+// subject, sorted by name. The subject only matters for placeholders and may
+// be nil.
+func (a *Authorizer) RolesFor(permission, resource string, subject *Subject) []string {
+	if subject == nil {
+		subject = &Subject{}
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	roles := []string{}
+	for role := range a.grants {
+		if a.roleCan(role, permission, resource, subject) {
+			roles = append(roles, role)
+		}
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// Handler returns a net/http middleware that authorizes every request. The
+// resource function may be nil for permissions without resource scopes.
+// Denied requests receive the domain error as problem+json, written by
+// middlewares.WriteProblem with the response profile of the default factory.
+func (a *Authorizer) Handler(permission string, resource func(*http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var res string
+			if resource != nil {
+				res = resource(r)
+			}
+			if err := a.Authorize(r.Context(), permission, res); err != nil {
+				middlewares.WriteProblem(w, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// roleCan checks the grants of a role. Callers must hold the read lock.
+func (a *Authorizer) roleCan(role, permission, resource string, subject *Subject) bool {
+	for _, g := range a.grants[role] {
+		if !matchPermission(g.permission, permission) {
+			continue
+		}
+		if len(g.resources) == 0 {
+			return true
+		}
+		for _, pattern := range g.resources {
+			if resource != "" && matchResource(pattern, resource, subject) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,189 @@
+package authz
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testPolicy = Policy{Roles: []Role{
+	{Name: "viewer", Permissions: []string{"orders:read"}},
+	{Name: "editor", Inherits: []string{"viewer"}, Permissions: []string{"orders:write"}, Resources: []string{"tenants/{tenant}/**"}},
+	{Name: "owner", Permissions: []string{"users:*"}, Resources: []string{"users/{id}"}},
+	{Name: "admin", Permissions: []string{"*"}},
+}}
+
+func newTestAuthorizer(t *testing.T, opts ...Option) *Authorizer {
+	t.Helper()
+	a, err := New(testPolicy, opts...)
+	require.NoError(t, err)
+	return a
+}
+
+func TestAuthorizer_Can(t *testing.T) {
+	a := newTestAuthorizer(t)
+	editor := &Subject{ID: "7", Roles: []string{"editor", "owner"}, Attributes: map[string]string{"tenant": "acme"}}
+
+	tests := []struct {
+		name       string
+		subject    *Subject
+		permission string
+		resource   string
+		want       bool
+	}{
+		{"inherited permission", editor, "orders:read", "", true},
+		{"scoped permission in scope", editor, "orders:write", "tenants/acme/orders/1", true},
+		{"scoped permission out of scope", editor, "orders:write", "tenants/other/orders/1", false},
+		{"scoped permission without resource", editor, "orders:write", "", false},
+		{"own resource", editor, "users:update", "users/7", true},
+		{"other resource", editor, "users:update", "users/8", false},
+		{"missing permission", editor, "orders:delete", "tenants/acme/orders/1", false},
+		{"admin", &Subject{Roles: []string{"admin"}}, "orders:delete", "tenants/other/orders/1", true},
+		{"unknown role", &Subject{Roles: []string{"ghost"}}, "orders:read", "", false},
+		{"nil subject", nil, "orders:read", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, a.Can(tt.subject, tt.permission, tt.resource))
+		})
+	}
+}
+
+func TestAuthorizer_Authorize(t *testing.T) {
+	a := newTestAuthorizer(t)
+	subject := &Subject{ID: "7", Roles: []string{"viewer"}, Attributes: map[string]string{"tenant": "acme"}}
+	ctx := WithSubject(context.Background(), subject)
+
+	require.NoError(t, a.Authorize(ctx, "orders:read", ""))
+
+	err := a.Authorize(ctx, "orders:write", "tenants/acme/orders/1")
+	require.ErrorIs(t, err, ErrPermissionDenied)
+	assert.True(t, domainerrors.IsType(err, interfaces.AuthorizationError))
+
+	var domainErr interfaces.DomainErrorInterface
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, "INSUFFICIENT_PERMISSIONS", domainErr.Code())
+	assert.Equal(t, http.StatusForbidden, domainErr.HTTPStatus())
+
+	metadata := domainErr.Metadata()
+	assert.Equal(t, "7", metadata["user_id"])
+	assert.Equal(t, "orders:write", metadata["required_permission"])
+	assert.Equal(t, "tenants/acme/orders/1", metadata["resource"])
+	assert.Equal(t, []string{"admin", "editor"}, metadata["required_roles"])
+	assert.Equal(t, []string{"viewer"}, metadata["current_roles"])
+
+	err = a.Authorize(context.Background(), "orders:read", "")
+	require.ErrorIs(t, err, ErrNoSubject)
+	assert.True(t, domainerrors.IsType(err, interfaces.AuthenticationError))
+}
+
+func TestAuthorizer_RequireRole(t *testing.T) {
+	a := newTestAuthorizer(t)
+	ctx := WithSubject(context.Background(), &Subject{ID: "7", Roles: []string{"viewer"}})
+
+	assert.NoError(t, a.RequireRole(ctx, "admin", "viewer"))
+
+	err := a.RequireRole(ctx, "admin")
+	require.ErrorIs(t, err, ErrPermissionDenied)
+	var domainErr interfaces.DomainErrorInterface
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, []string{"admin"}, domainErr.Metadata()["required_roles"])
+	assert.Equal(t, []string{"viewer"}, domainErr.Metadata()["current_roles"])
+
+	assert.ErrorIs(t, a.RequireRole(context.Background(), "admin"), ErrNoSubject)
+}
+
+func TestAuthorizer_SubjectResolver(t *testing.T) {
+	type userKey struct{}
+	a := newTestAuthorizer(t, WithSubjectResolver(func(ctx context.Context) (*Subject, bool) {
+		roles, ok := ctx.Value(userKey{}).([]string)
+		return &Subject{ID: "1", Roles: roles}, ok
+	}))
+
+	ctx := context.WithValue(context.Background(), userKey{}, []string{"admin"})
+	assert.NoError(t, a.Authorize(ctx, "orders:delete", "orders/1"))
+	assert.ErrorIs(t, a.Authorize(context.Background(), "orders:delete", "orders/1"), ErrNoSubject)
+}
+
+func TestAuthorizer_SetPolicy(t *testing.T) {
+	a := newTestAuthorizer(t)
+	subject := &Subject{Roles: []string{"viewer"}}
+	require.True(t, a.Can(subject, "orders:read", ""))
+
+	err := a.SetPolicy(Policy{Roles: []Role{{Name: "viewer", Inherits: []string{"missing"}}}})
+	require.ErrorIs(t, err, ErrInvalidPolicy)
+	assert.True(t, a.Can(subject, "orders:read", ""), "invalid policy must keep the current one")
+
+	require.NoError(t, a.SetPolicy(Policy{Roles: []Role{{Name: "viewer", Permissions: []string{"reports:read"}}}}))
+	assert.False(t, a.Can(subject, "orders:read", ""))
+	assert.True(t, a.Can(subject, "reports:read", ""))
+	assert.Len(t, a.Policy().Roles, 1)
+}
+
+func TestAuthorizer_Concurrent(t *testing.T) {
+	a := newTestAuthorizer(t)
+	subject := &Subject{Roles: []string{"viewer"}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			a.Can(subject, "orders:read", "")
+		}()
+		go func() {
+			defer wg.Done()
+			a.SetPolicy(testPolicy)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestAuthorizer_Handler(t *testing.T) {
+	a := newTestAuthorizer(t)
+	handler := a.Handler("orders:write", func(r *http.Request) string {
+		return "tenants/" + r.URL.Query().Get("tenant") + "/orders"
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	subject := &Subject{ID: "7", Roles: []string{"editor"}, Attributes: map[string]string{"tenant": "acme"}}
+
+	req := httptest.NewRequest(http.MethodPost, "/orders?tenant=acme", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req.WithContext(WithSubject(req.Context(), subject)))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/orders?tenant=other", nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req.WithContext(WithSubject(req.Context(), subject)))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "INSUFFICIENT_PERMISSIONS", body["code"])
+	assert.NotContains(t, body, "metadata", "roles are not exposed without a response profile allowing it")
+
+	factory := domainerrors.GetFactory()
+	factory.SetResponseProfile(domainerrors.InternalProfile())
+	t.Cleanup(func() { factory.SetResponseProfile(nil) })
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req.WithContext(WithSubject(req.Context(), subject)))
+	body = nil
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	metadata := body["metadata"].(map[string]interface{})
+	assert.Equal(t, "orders:write", metadata["required_permission"])
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/orders", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
@@ -0,0 +1,180 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fsvxavier/nexs-lib/config"
+)
+
+// ErrInvalidPolicy wraps the errors of a policy that cannot be compiled.
+var ErrInvalidPolicy = errors.New("authz: invalid policy")
+
+// Policy declares the roles of an application.
+type Policy struct {
+	Roles []Role `json:"roles"`
+}
+
+// Role grants permissions on the resources matching its patterns.
+//
+// Permissions have the form "resource:action", e.g. "orders:read"; "orders:*"
+// grants every action on orders and "*" grants everything. Resources are
+// slash-separated patterns where "*" matches one segment, "**" matches any
+// number of trailing segments and "{id}" or "{attribute}" is replaced by the
+// subject ID or attribute, e.g. "users/{id}" or "tenants/{tenant}/**". An
+// empty Resources list grants the permissions on every resource. Inherited
+// roles keep their own resources.
+type Role struct {
+	Name        string   `json:"name"`
+	Inherits    []string `json:"inherits"`
+	Permissions []string `json:"permissions"`
+	Resources   []string `json:"resources"`
+}
+
+// LoadPolicy loads a policy with the config package, e.g. from a YAML, JSON
+// or TOML file given with config.WithFile. Environment and flag layers apply
+// as for any configuration.
+func LoadPolicy(ctx context.Context, opts ...config.Option) (*Policy, error) {
+	policy, err := config.Load[Policy](ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := compile(*policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// grant is a permission on a set of resources.
+type grant struct {
+	permission string
+	resources  []string
+}
+
+// compile flattens the role inheritance into the grants of each role.
+func compile(policy Policy) (map[string][]grant, error) {
+	roles := make(map[string]Role, len(policy.Roles))
+	for _, role := range policy.Roles {
+		if role.Name == "" {
+			return nil, fmt.Errorf("%w: role without name", ErrInvalidPolicy)
+		}
+		if _, exists := roles[role.Name]; exists {
+			return nil, fmt.Errorf("%w: role %q declared twice", ErrInvalidPolicy, role.Name)
+		}
+		for _, permission := range role.Permissions {
+			if err := validatePermission(permission); err != nil {
+				return nil, fmt.Errorf("%w: role %q: %v", ErrInvalidPolicy, role.Name, err)
+			}
+		}
+		for _, resource := range role.Resources {
+			if err := validateResource(resource); err != nil {
+				return nil, fmt.Errorf("%w: role %q: %v", ErrInvalidPolicy, role.Name, err)
+			}
+		}
+		roles[role.Name] = role
+	}
+
+	compiled := make(map[string][]grant, len(roles))
+	var visit func(name string, path []string) ([]grant, error)
+	visit = func(name string, path []string) ([]grant, error) {
+		if grants, done := compiled[name]; done {
+			return grants, nil
+		}
+		for _, ancestor := range path {
+			if ancestor == name {
+				return nil, fmt.Errorf("%w: inheritance cycle %s", ErrInvalidPolicy, strings.Join(append(path, name), " -> "))
+			}
+		}
+		role, exists := roles[name]
+		if !exists {
+			return nil, fmt.Errorf("%w: role %q inherits unknown role %q", ErrInvalidPolicy, path[len(path)-1], name)
+		}
+
+		var grants []grant
+		for _, permission := range role.Permissions {
+			grants = append(grants, grant{permission: permission, resources: role.Resources})
+		}
+		for _, parent := range role.Inherits {
+			inherited, err := visit(parent, append(path, name))
+			if err != nil {
+				return nil, err
+			}
+			grants = append(grants, inherited...)
+		}
+		compiled[name] = grants
+		return grants, nil
+	}
+
+	for _, role := range policy.Roles {
+		if _, err := visit(role.Name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return compiled, nil
+}
+
+// validatePermission checks the "resource:action" form of a permission.
+func validatePermission(permission string) error {
+	if permission == "*" {
+		return nil
+	}
+	resource, action, found := strings.Cut(permission, ":")
+	if !found || resource == "" || action == "" || strings.Contains(action, ":") {
+		return fmt.Errorf("permission %q must have the form resource:action", permission)
+	}
+	return nil
+}
+
+// validateResource checks that "**" only ends a resource pattern.
+func validateResource(pattern string) error {
+	segments := strings.Split(pattern, "/")
+	for i, segment := range segments {
+		if segment == "" {
+			return fmt.Errorf("resource %q has an empty segment", pattern)
+		}
+		if segment == "**" && i != len(segments)-1 {
+			return fmt.Errorf("resource %q has ** before the last segment", pattern)
+		}
+	}
+	return nil
+}
+
+// matchPermission reports whether a granted permission covers the required one.
+func matchPermission(granted, required string) bool {
+	if granted == "*" || granted == required {
+		return true
+	}
+	grantedResource, grantedAction, _ := strings.Cut(granted, ":")
+	requiredResource, _, _ := strings.Cut(required, ":")
+	return grantedAction == "*" && grantedResource == requiredResource
+}
+
+// matchResource reports whether a resource matches a pattern, expanding the
+// placeholders with the subject. Placeholders of missing attributes never match.
+func matchResource(pattern, resource string, subject *Subject) bool {
+	patternSegments := strings.Split(pattern, "/")
+	resourceSegments := strings.Split(resource, "/")
+
+	for i, segment := range patternSegments {
+		if segment == "**" {
+			return len(resourceSegments) >= i
+		}
+		if i >= len(resourceSegments) {
+			return false
+		}
+		switch {
+		case segment == "*":
+			continue
+		case strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}"):
+			value, ok := subject.attribute(segment[1 : len(segment)-1])
+			if !ok || value != resourceSegments[i] {
+				return false
+			}
+		case segment != resourceSegments[i]:
+			return false
+		}
+	}
+	return len(patternSegments) == len(resourceSegments)
+}
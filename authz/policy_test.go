@@ -0,0 +1,131 @@
+package authz
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fsvxavier/nexs-lib/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompile_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy Policy
+		want   string
+	}{
+		{"role without name", Policy{Roles: []Role{{Permissions: []string{"orders:read"}}}}, "role without name"},
+		{"duplicated role", Policy{Roles: []Role{{Name: "a"}, {Name: "a"}}}, "declared twice"},
+		{"permission without action", Policy{Roles: []Role{{Name: "a", Permissions: []string{"orders"}}}}, "resource:action"},
+		{"empty resource segment", Policy{Roles: []Role{{Name: "a", Resources: []string{"orders//1"}}}}, "empty segment"},
+		{"double star in the middle", Policy{Roles: []Role{{Name: "a", Resources: []string{"tenants/**/orders"}}}}, "** before the last segment"},
+		{"unknown parent", Policy{Roles: []Role{{Name: "a", Inherits: []string{"b"}}}}, `inherits unknown role "b"`},
+		{"inheritance cycle", Policy{Roles: []Role{
+			{Name: "a", Inherits: []string{"b"}},
+			{Name: "b", Inherits: []string{"c"}},
+			{Name: "c", Inherits: []string{"a"}},
+		}}, "cycle a -> b -> c -> a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := compile(tt.policy)
+			require.ErrorIs(t, err, ErrInvalidPolicy)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestCompile_Inheritance(t *testing.T) {
+	grants, err := compile(Policy{Roles: []Role{
+		{Name: "admin", Inherits: []string{"editor"}, Permissions: []string{"users:*"}},
+		{Name: "editor", Inherits: []string{"viewer"}, Permissions: []string{"orders:write"}, Resources: []string{"tenants/{tenant}/**"}},
+		{Name: "viewer", Permissions: []string{"orders:read"}},
+	}})
+	require.NoError(t, err)
+
+	assert.Len(t, grants["viewer"], 1)
+	assert.Len(t, grants["editor"], 2)
+	assert.Len(t, grants["admin"], 3)
+	// Inherited grants keep the resources of their role
+	assert.Equal(t, []string{"tenants/{tenant}/**"}, grants["admin"][1].resources)
+	assert.Empty(t, grants["admin"][2].resources)
+}
+
+func TestMatchPermission(t *testing.T) {
+	tests := []struct {
+		granted, required string
+		want              bool
+	}{
+		{"orders:read", "orders:read", true},
+		{"orders:*", "orders:delete", true},
+		{"*", "users:write", true},
+		{"orders:read", "orders:write", false},
+		{"orders:*", "users:read", false},
+		{"order:*", "orders:read", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, matchPermission(tt.granted, tt.required), "%s covers %s", tt.granted, tt.required)
+	}
+}
+
+func TestMatchResource(t *testing.T) {
+	subject := &Subject{ID: "42", Attributes: map[string]string{"tenant": "acme"}}
+
+	tests := []struct {
+		pattern, resource string
+		want              bool
+	}{
+		{"orders/1", "orders/1", true},
+		{"orders/*", "orders/1", true},
+		{"orders/*", "orders/1/items", false},
+		{"orders/*", "orders", false},
+		{"tenants/acme/**", "tenants/acme", true},
+		{"tenants/acme/**", "tenants/acme/orders/1", true},
+		{"tenants/acme/**", "tenants/other/orders", false},
+		{"users/{id}", "users/42", true},
+		{"users/{id}", "users/43", false},
+		{"tenants/{tenant}/**", "tenants/acme/orders", true},
+		{"tenants/{tenant}/**", "tenants/other/orders", false},
+		{"tenants/{region}/**", "tenants/acme/orders", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, matchResource(tt.pattern, tt.resource, subject), "%s matches %s", tt.pattern, tt.resource)
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+roles:
+  - name: viewer
+    permissions: [orders:read]
+  - name: editor
+    inherits: [viewer]
+    permissions: [orders:write]
+    resources: ["tenants/{tenant}/**"]
+`), 0o600))
+
+	policy, err := LoadPolicy(context.Background(), config.WithFile(path))
+	require.NoError(t, err)
+	require.Len(t, policy.Roles, 2)
+	assert.Equal(t, "editor", policy.Roles[1].Name)
+	assert.Equal(t, []string{"viewer"}, policy.Roles[1].Inherits)
+	assert.Equal(t, []string{"tenants/{tenant}/**"}, policy.Roles[1].Resources)
+
+	invalid := filepath.Join(dir, "invalid.yaml")
+	require.NoError(t, os.WriteFile(invalid, []byte(`
+roles:
+  - name: editor
+    inherits: [viewer]
+`), 0o600))
+
+	_, err = LoadPolicy(context.Background(), config.WithFile(invalid))
+	assert.ErrorIs(t, err, ErrInvalidPolicy)
+}
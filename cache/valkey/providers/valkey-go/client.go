@@ -432,12 +432,7 @@ func (c *Client) Eval(ctx context.Context, script string, keys []string, args ..
 	cmd := c.client.B().Eval().Script(script).Numkeys(int64(len(keys))).Key(keys...).Arg(stringArgs...).Build()
 	result := c.client.Do(ctx, cmd)
 
-	if result.Error() != nil {
-		return nil, result.Error()
-	}
-
-	// Retornar resultado bruto - pode ser melhorado
-	return result, nil
+	return scriptResult(result)
 }
 
 func (c *Client) EvalSha(ctx context.Context, sha1 string, keys []string, args ...interface{}) (interface{}, error) {
@@ -447,13 +442,20 @@ func (c *Client) EvalSha(ctx context.Context, sha1 string, keys []string, args .
 	}
 
 	cmd := c.client.B().Evalsha().Sha1(sha1).Numkeys(int64(len(keys))).Key(keys...).Arg(stringArgs...).Build()
-	result := c.client.Do(ctx, cmd)
+	return scriptResult(c.client.Do(ctx, cmd))
+}
 
-	if result.Error() != nil {
-		return nil, result.Error()
+// scriptResult converte o retorno de um script Lua em valores Go: int64,
+// string, []interface{} ou nil quando o script retorna nil
+func scriptResult(result valkey.ValkeyResult) (interface{}, error) {
+	value, err := result.ToAny()
+	if valkey.IsValkeyNil(err) {
+		return nil, nil
 	}
-
-	return result, nil
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
 }
 
 func (c *Client) ScriptLoad(ctx context.Context, script string) (string, error) {
@@ -471,8 +473,12 @@ func (c *Client) ScriptLoad(ctx context.Context, script string) (string, error)
 
 // Subscribe implementa interfaces.IClient.Subscribe.
 func (c *Client) Subscribe(ctx context.Context, channels ...string) (interfaces.IPubSub, error) {
-	// Implementação básica - seria expandida
-	return nil, fmt.Errorf("Subscribe não implementado nesta versão")
+	ps := newPubSub(c.client)
+	if err := ps.Subscribe(ctx, channels...); err != nil {
+		ps.Close()
+		return nil, err
+	}
+	return ps, nil
 }
 
 // Publish implementa interfaces.IClient.Publish.
@@ -0,0 +1,150 @@
+package valkeygo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/valkey-io/valkey-go"
+
+	"github.com/fsvxavier/nexs-lib/cache/valkey/interfaces"
+)
+
+// PubSub implementa interfaces.IPubSub usando Client.Receive do valkey-go.
+// Cada canal ou padrão inscrito roda seu próprio Receive, que entrega as
+// mensagens para Receive como *interfaces.Message ou *interfaces.PMessage.
+type PubSub struct {
+	client   valkey.Client
+	messages chan interface{}
+	errs     chan error
+	subs     map[string]context.CancelFunc
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+}
+
+func newPubSub(client valkey.Client) *PubSub {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &PubSub{
+		client:   client,
+		messages: make(chan interface{}, 64),
+		errs:     make(chan error, 1),
+		subs:     make(map[string]context.CancelFunc),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Subscribe implementa interfaces.IPubSub.Subscribe.
+func (ps *PubSub) Subscribe(ctx context.Context, channels ...string) error {
+	for _, channel := range channels {
+		if err := ps.start("channel:"+channel, ps.client.B().Subscribe().Channel(channel).Build()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Unsubscribe implementa interfaces.IPubSub.Unsubscribe.
+func (ps *PubSub) Unsubscribe(ctx context.Context, channels ...string) error {
+	for _, channel := range channels {
+		ps.stop("channel:" + channel)
+	}
+	return nil
+}
+
+// PSubscribe implementa interfaces.IPubSub.PSubscribe.
+func (ps *PubSub) PSubscribe(ctx context.Context, patterns ...string) error {
+	for _, pattern := range patterns {
+		if err := ps.start("pattern:"+pattern, ps.client.B().Psubscribe().Pattern(pattern).Build()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PUnsubscribe implementa interfaces.IPubSub.PUnsubscribe.
+func (ps *PubSub) PUnsubscribe(ctx context.Context, patterns ...string) error {
+	for _, pattern := range patterns {
+		ps.stop("pattern:" + pattern)
+	}
+	return nil
+}
+
+// Receive implementa interfaces.IPubSub.Receive. Retorna a próxima mensagem
+// ou o erro de uma inscrição que falhou.
+func (ps *PubSub) Receive(ctx context.Context) (interface{}, error) {
+	select {
+	case msg := <-ps.messages:
+		return msg, nil
+	case err := <-ps.errs:
+		return nil, err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-ps.ctx.Done():
+		return nil, fmt.Errorf("pubsub fechado")
+	}
+}
+
+// Close implementa interfaces.IPubSub.Close.
+func (ps *PubSub) Close() error {
+	ps.cancel()
+	ps.wg.Wait()
+	return nil
+}
+
+// start inicia o Receive da inscrição em background
+func (ps *PubSub) start(key string, cmd valkey.Completed) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	if ps.ctx.Err() != nil {
+		return fmt.Errorf("pubsub fechado")
+	}
+	if _, exists := ps.subs[key]; exists {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(ps.ctx)
+	ps.subs[key] = cancel
+	ps.wg.Add(1)
+	go ps.receive(ctx, key, cmd)
+	return nil
+}
+
+// stop encerra o Receive da inscrição
+func (ps *PubSub) stop(key string) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	if cancel, exists := ps.subs[key]; exists {
+		cancel()
+		delete(ps.subs, key)
+	}
+}
+
+func (ps *PubSub) receive(ctx context.Context, key string, cmd valkey.Completed) {
+	defer ps.wg.Done()
+
+	err := ps.client.Receive(ctx, cmd, func(msg valkey.PubSubMessage) {
+		var out interface{}
+		if msg.Pattern != "" {
+			out = &interfaces.PMessage{Channel: msg.Channel, Pattern: msg.Pattern, Payload: msg.Message}
+		} else {
+			out = &interfaces.Message{Channel: msg.Channel, Payload: msg.Message}
+		}
+		select {
+		case ps.messages <- out:
+		case <-ctx.Done():
+		}
+	})
+
+	if err != nil && ctx.Err() == nil {
+		ps.stop(key)
+		select {
+		case ps.errs <- fmt.Errorf("inscrição %s encerrada: %w", key, err):
+		case <-ps.ctx.Done():
+		}
+	}
+}
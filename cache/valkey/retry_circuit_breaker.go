@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/fsvxavier/nexs-lib/cache/valkey/interfaces"
+	"github.com/fsvxavier/nexs-lib/clock"
 )
 
 // ExponentialBackoffRetryPolicy implementa retry com backoff exponencial.
@@ -122,16 +123,33 @@ type CircuitBreaker struct {
 	maxRequests  int
 	requests     int
 	lastFailure  time.Time
+	clock        clock.Clock
+}
+
+// CircuitBreakerOption configura um CircuitBreaker.
+type CircuitBreakerOption func(*CircuitBreaker)
+
+// WithCircuitBreakerClock define o relógio usado para medir o timeout do
+// circuito aberto, como um clock.Fake em testes. O padrão é clock.Real().
+func WithCircuitBreakerClock(c clock.Clock) CircuitBreakerOption {
+	return func(cb *CircuitBreaker) {
+		cb.clock = clock.OrReal(c)
+	}
 }
 
 // NewCircuitBreaker cria um novo circuit breaker.
-func NewCircuitBreaker(threshold int, timeout time.Duration, maxRequests int) interfaces.ICircuitBreaker {
-	return &CircuitBreaker{
+func NewCircuitBreaker(threshold int, timeout time.Duration, maxRequests int, opts ...CircuitBreakerOption) interfaces.ICircuitBreaker {
+	cb := &CircuitBreaker{
 		state:       StateClosed,
 		threshold:   threshold,
 		timeout:     timeout,
 		maxRequests: maxRequests,
+		clock:       clock.Real(),
+	}
+	for _, opt := range opts {
+		opt(cb)
 	}
+	return cb
 }
 
 // Execute executa uma função através do circuit breaker.
@@ -175,7 +193,7 @@ func (cb *CircuitBreaker) allowRequest() bool {
 		return true
 	case StateOpen:
 		// Verificar se é hora de tentar half-open
-		if time.Since(cb.lastFailure) > cb.timeout {
+		if cb.clock.Since(cb.lastFailure) > cb.timeout {
 			cb.state = StateHalfOpen
 			cb.requests = 0
 			return true
@@ -217,7 +235,7 @@ func (cb *CircuitBreaker) handleSuccess() {
 
 // handleFailure trata um resultado de falha.
 func (cb *CircuitBreaker) handleFailure() {
-	cb.lastFailure = time.Now()
+	cb.lastFailure = cb.clock.Now()
 
 	switch cb.state {
 	case StateClosed:
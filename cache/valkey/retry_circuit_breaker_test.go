@@ -9,6 +9,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/fsvxavier/nexs-lib/clock"
 )
 
 func TestNewExponentialBackoffRetryPolicy(t *testing.T) {
@@ -388,6 +390,28 @@ func TestCircuitBreaker_Execute_HalfOpenState(t *testing.T) {
 	assert.Equal(t, StateClosed, circuitBreaker.state)
 }
 
+func TestCircuitBreaker_Execute_FakeClock(t *testing.T) {
+	fake := clock.NewFake(time.Time{})
+	cb := NewCircuitBreaker(1, time.Minute, 1, WithCircuitBreakerClock(fake))
+	ctx := context.Background()
+
+	_, _ = cb.Execute(ctx, func() (interface{}, error) {
+		return nil, errors.New("operation failed")
+	})
+	assert.Equal(t, string(StateOpen), cb.State())
+
+	// O circuito continua aberto até o timeout
+	fake.Advance(59 * time.Second)
+	_, err := cb.Execute(ctx, func() (interface{}, error) { return "ok", nil })
+	assert.Error(t, err)
+
+	fake.Advance(2 * time.Second)
+	result, err := cb.Execute(ctx, func() (interface{}, error) { return "ok", nil })
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", result)
+	assert.Equal(t, string(StateClosed), cb.State())
+}
+
 func TestCircuitBreaker_Execute_MaxConcurrentRequests(t *testing.T) {
 	cb := NewCircuitBreaker(5, 1*time.Second, 1)
 	ctx := context.Background()
@@ -0,0 +1,160 @@
+package tiered
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/concurrency/coalesce"
+)
+
+// ErrNotFound deve ser retornado (ou encapsulado) pelo Loader quando a chave
+// não existe na origem. O resultado negativo é mantido por
+// LoadPolicy.NegativeTTL e GetOrLoad retorna ErrNotFound.
+var ErrNotFound = errors.New("chave não encontrada")
+
+// Loader carrega o valor de uma chave da origem, como um banco de dados, em
+// um miss de GetOrLoad.
+type Loader func(ctx context.Context, key string) (string, error)
+
+// LoadPolicy define como GetOrLoad mantém os valores de um espaço de chaves.
+type LoadPolicy struct {
+	// TTL expiração dos valores carregados no Valkey. A cópia local fica
+	// atualizada pelo menor entre TTL e o TTL local.
+	TTL time.Duration
+	// NegativeTTL tempo em que uma chave inexistente na origem é lembrada
+	// localmente, evitando consultar a origem a cada leitura. Zero desabilita.
+	NegativeTTL time.Duration
+	// StaleTTL tempo após a cópia local ficar desatualizada em que ela ainda é
+	// retornada enquanto uma recarga roda em background
+	// (stale-while-revalidate). Também cobre falhas da recarga. Zero desabilita.
+	StaleTTL time.Duration
+}
+
+// DefaultLoadPolicy política usada pelas chaves fora dos espaços registrados
+// com WithKeySpace.
+var DefaultLoadPolicy = LoadPolicy{
+	TTL:         5 * time.Minute,
+	NegativeTTL: 5 * time.Second,
+}
+
+// keySpace política de um prefixo de chaves
+type keySpace struct {
+	prefix string
+	policy LoadPolicy
+}
+
+// WithLoadPolicy define a política padrão de GetOrLoad.
+func WithLoadPolicy(policy LoadPolicy) Option {
+	return func(c *Cache) {
+		c.loadPolicy = policy
+	}
+}
+
+// WithKeySpace define a política de GetOrLoad para as chaves com o prefixo
+// informado. Quando mais de um prefixo casa com a chave, vale o mais longo.
+func WithKeySpace(prefix string, policy LoadPolicy) Option {
+	return func(c *Cache) {
+		c.keySpaces = append(c.keySpaces, keySpace{prefix: prefix, policy: policy})
+		sort.SliceStable(c.keySpaces, func(i, j int) bool {
+			return len(c.keySpaces[i].prefix) > len(c.keySpaces[j].prefix)
+		})
+	}
+}
+
+// GetOrLoad retorna o valor da chave, carregando-o com loader quando não está
+// em nenhuma camada. Chamadas concorrentes para a mesma chave executam uma
+// única carga (ver concurrency/coalesce); chaves inexistentes na origem são
+// lembradas por NegativeTTL; e uma cópia local desatualizada é retornada por
+// até StaleTTL enquanto a recarga roda em background. Erros de leitura do
+// Valkey são tratados como miss, e uma falha ao gravar o valor carregado não
+// impede seu retorno.
+func (c *Cache) GetOrLoad(ctx context.Context, key string, loader Loader) (string, error) {
+	policy := c.policyFor(key)
+
+	value, state := c.local.lookup(key)
+	switch state {
+	case entryFresh:
+		c.record(LayerLocal, "hit", &c.localStats.hits)
+		return value, nil
+	case entryNegative:
+		c.record(LayerLocal, "negative", &c.negativeHits)
+		return "", ErrNotFound
+	case entryStale:
+		c.record(LayerLocal, "stale", &c.staleServed)
+		go c.revalidate(context.WithoutCancel(ctx), key, loader, policy)
+		return value, nil
+	}
+	c.record(LayerLocal, "miss", &c.localStats.misses)
+
+	return c.loads.Do(ctx, key, func(ctx context.Context) (string, error) {
+		return c.load(ctx, key, loader, policy)
+	})
+}
+
+// LoadStats retorna as estatísticas de coalescência de GetOrLoad, incluindo
+// quantas cargas concorrentes foram evitadas.
+func (c *Cache) LoadStats() coalesce.Stats {
+	return c.loads.Stats()
+}
+
+// revalidate recarrega uma cópia em stale. Recargas concorrentes da mesma
+// chave se juntam à que já está em execução.
+func (c *Cache) revalidate(ctx context.Context, key string, loader Loader, policy LoadPolicy) {
+	_, _ = c.loads.Do(ctx, key, func(ctx context.Context) (string, error) {
+		return c.load(ctx, key, loader, policy)
+	})
+}
+
+// load busca a chave no Valkey e, se ausente, na origem
+func (c *Cache) load(ctx context.Context, key string, loader Loader, policy LoadPolicy) (string, error) {
+	ttl := c.ttlFor(policy.TTL)
+	generation := c.local.currentGeneration()
+
+	value, err := c.remote.Get(ctx, key)
+	switch {
+	case err == nil:
+		c.record(LayerRemote, "hit", &c.remoteStats.hits)
+		c.local.setIfCurrent(key, value, ttl, policy.StaleTTL, generation)
+		return value, nil
+	case isNotFound(err):
+		c.record(LayerRemote, "miss", &c.remoteStats.misses)
+	default:
+		c.record(LayerRemote, "error", &c.remoteStats.errors)
+	}
+
+	value, err = loader(ctx, key)
+	if errors.Is(err, ErrNotFound) {
+		c.record(LayerSource, "miss", &c.sourceStats.misses)
+		if policy.NegativeTTL > 0 {
+			c.local.setNegativeIfCurrent(key, policy.NegativeTTL, generation)
+		}
+		return "", ErrNotFound
+	}
+	if err != nil {
+		c.record(LayerSource, "error", &c.sourceStats.errors)
+		return "", err
+	}
+	c.record(LayerSource, "hit", &c.sourceStats.hits)
+
+	c.local.setIfCurrent(key, value, ttl, policy.StaleTTL, generation)
+	if err := c.remote.Set(ctx, key, value, policy.TTL); err != nil {
+		c.record(LayerRemote, "error", &c.remoteStats.errors)
+		return value, nil
+	}
+	// Descarta resultados negativos e cópias antigas das demais réplicas
+	_ = c.publish(ctx, key)
+	return value, nil
+}
+
+// policyFor retorna a política do espaço de chaves mais específico
+func (c *Cache) policyFor(key string) LoadPolicy {
+	for _, space := range c.keySpaces {
+		if strings.HasPrefix(key, space.prefix) {
+			return space.policy
+		}
+	}
+	return c.loadPolicy
+}
@@ -0,0 +1,179 @@
+package tiered
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+)
+
+func TestGetOrLoad_CoalescesConcurrentLoads(t *testing.T) {
+	server := newFakeServer()
+	cache := newReplica(t, server)
+	ctx := context.Background()
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	loader := func(ctx context.Context, key string) (string, error) {
+		calls.Add(1)
+		<-release
+		return "value-of-" + key, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]string, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			value, err := cache.GetOrLoad(ctx, "product:1", loader)
+			assert.NoError(t, err)
+			results[i] = value
+		}(i)
+	}
+	require.Eventually(t, func() bool { return cache.LoadStats().Calls == 10 }, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	for _, value := range results {
+		assert.Equal(t, "value-of-product:1", value)
+	}
+	assert.Equal(t, "value-of-product:1", server.data["product:1"])
+	assert.Equal(t, LayerStats{Hits: 1}, cache.Stats().Source)
+
+	// Leituras seguintes são servidas pela camada local
+	value, err := cache.GetOrLoad(ctx, "product:1", loader)
+	require.NoError(t, err)
+	assert.Equal(t, "value-of-product:1", value)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestGetOrLoad_UsesRemoteBeforeLoader(t *testing.T) {
+	server := newFakeServer()
+	cache := newReplica(t, server)
+	server.data["k"] = "remote"
+
+	value, err := cache.GetOrLoad(context.Background(), "k", func(ctx context.Context, key string) (string, error) {
+		t.Fatal("loader must not run when the value is in Valkey")
+		return "", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "remote", value)
+	assert.Equal(t, uint64(1), cache.Stats().Remote.Hits)
+}
+
+func TestGetOrLoad_NegativeCaching(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	server := newFakeServer()
+	cache := newReplica(t, server, WithClock(fake),
+		WithLoadPolicy(LoadPolicy{TTL: time.Minute, NegativeTTL: 5 * time.Second}))
+	ctx := context.Background()
+
+	var calls atomic.Int32
+	loader := func(ctx context.Context, key string) (string, error) {
+		calls.Add(1)
+		return "", fmt.Errorf("user %s: %w", key, ErrNotFound)
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err := cache.GetOrLoad(ctx, "user:404", loader)
+		assert.ErrorIs(t, err, ErrNotFound)
+	}
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Equal(t, uint64(2), cache.Stats().NegativeHits)
+
+	// O resultado negativo expira após NegativeTTL
+	fake.Advance(5 * time.Second)
+	_, err := cache.GetOrLoad(ctx, "user:404", loader)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, int32(2), calls.Load())
+
+	// Uma escrita descarta o resultado negativo
+	require.NoError(t, cache.Set(ctx, "user:404", "created", 0))
+	value, err := cache.GetOrLoad(ctx, "user:404", loader)
+	require.NoError(t, err)
+	assert.Equal(t, "created", value)
+}
+
+func TestGetOrLoad_StaleWhileRevalidate(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	server := newFakeServer()
+	cache := newReplica(t, server, WithClock(fake),
+		WithKeySpace("price:", LoadPolicy{TTL: 10 * time.Second, StaleTTL: time.Minute}))
+	ctx := context.Background()
+
+	var version atomic.Int32
+	loader := func(ctx context.Context, key string) (string, error) {
+		return fmt.Sprintf("v%d", version.Add(1)), nil
+	}
+
+	value, err := cache.GetOrLoad(ctx, "price:1", loader)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", value)
+
+	// O valor expirou no Valkey e a cópia local está em stale: ela é
+	// retornada de imediato e recarregada em background
+	server.mu.Lock()
+	delete(server.data, "price:1")
+	server.mu.Unlock()
+	fake.Advance(11 * time.Second)
+
+	value, err = cache.GetOrLoad(ctx, "price:1", loader)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", value)
+	assert.Equal(t, uint64(1), cache.Stats().StaleServed)
+
+	require.Eventually(t, func() bool { return cache.Stats().InvalidationsPublished == 2 }, time.Second, time.Millisecond)
+	value, err = cache.GetOrLoad(ctx, "price:1", loader)
+	require.NoError(t, err)
+	assert.Equal(t, "v2", value)
+
+	// Após a janela de stale a cópia não é mais usada
+	server.mu.Lock()
+	delete(server.data, "price:1")
+	server.mu.Unlock()
+	fake.Advance(2 * time.Minute)
+	_, err = cache.GetOrLoad(ctx, "price:1", func(ctx context.Context, key string) (string, error) {
+		return "", errors.New("origin down")
+	})
+	assert.EqualError(t, err, "origin down")
+}
+
+func TestGetOrLoad_LoaderErrorIsNotCached(t *testing.T) {
+	server := newFakeServer()
+	cache := newReplica(t, server)
+	ctx := context.Background()
+
+	_, err := cache.GetOrLoad(ctx, "k", func(ctx context.Context, key string) (string, error) {
+		return "", errors.New("timeout")
+	})
+	assert.EqualError(t, err, "timeout")
+	assert.Equal(t, uint64(1), cache.Stats().Source.Errors)
+
+	value, err := cache.GetOrLoad(ctx, "k", func(ctx context.Context, key string) (string, error) {
+		return "ok", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", value)
+}
+
+func TestPolicyFor_LongestPrefixWins(t *testing.T) {
+	users := LoadPolicy{TTL: time.Minute}
+	admins := LoadPolicy{TTL: time.Second}
+	cache := &Cache{loadPolicy: DefaultLoadPolicy}
+	WithKeySpace("user:", users)(cache)
+	WithKeySpace("user:admin:", admins)(cache)
+
+	assert.Equal(t, admins, cache.policyFor("user:admin:1"))
+	assert.Equal(t, users, cache.policyFor("user:1"))
+	assert.Equal(t, DefaultLoadPolicy, cache.policyFor("order:1"))
+}
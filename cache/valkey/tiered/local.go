@@ -0,0 +1,183 @@
+package tiered
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+)
+
+// localStore cache em memória com TTL e descarte LRU ao atingir maxEntries.
+// A geração é incrementada a cada invalidação e impede que uma leitura do
+// Valkey iniciada antes dela grave um valor já invalidado. Entradas podem ter
+// uma janela de stale após ficarem desatualizadas e registrar resultados
+// negativos (chave inexistente na origem).
+type localStore struct {
+	mu         sync.Mutex
+	entries    map[string]*list.Element
+	lru        *list.List
+	maxEntries int
+	clock      clock.Clock
+	generation uint64
+	evictions  uint64
+}
+
+type localEntry struct {
+	key        string
+	value      string
+	negative   bool
+	freshUntil time.Time
+	expiresAt  time.Time
+}
+
+// entryState estado de uma entrada retornado por lookup
+type entryState int
+
+const (
+	entryMissing entryState = iota
+	entryFresh
+	entryStale
+	entryNegative
+)
+
+func newLocalStore(maxEntries int, c clock.Clock) *localStore {
+	return &localStore{
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+		maxEntries: maxEntries,
+		clock:      c,
+	}
+}
+
+// get retorna o valor ainda atualizado da chave
+func (s *localStore) get(key string) (string, bool) {
+	value, state := s.lookup(key)
+	return value, state == entryFresh
+}
+
+// lookup retorna o valor da chave e se está atualizado, em stale ou é um
+// resultado negativo
+func (s *localStore) lookup(key string) (string, entryState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.entries[key]
+	if !ok {
+		return "", entryMissing
+	}
+	entry := elem.Value.(*localEntry)
+	now := s.clock.Now()
+	if !now.Before(entry.expiresAt) {
+		s.remove(elem)
+		return "", entryMissing
+	}
+	s.lru.MoveToFront(elem)
+
+	switch {
+	case entry.negative:
+		return "", entryNegative
+	case now.Before(entry.freshUntil):
+		return entry.value, entryFresh
+	default:
+		return entry.value, entryStale
+	}
+}
+
+// currentGeneration retorna a geração atual, a ser passada para setIfCurrent
+func (s *localStore) currentGeneration() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.generation
+}
+
+// set grava o valor incondicionalmente
+func (s *localStore) set(key, value string, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.store(key, value, false, ttl, 0)
+}
+
+// setIfCurrent grava o valor, atualizado por ttl e em stale por mais stale,
+// apenas se nenhuma invalidação ocorreu desde a geração informada
+func (s *localStore) setIfCurrent(key, value string, ttl, stale time.Duration, generation uint64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.generation != generation {
+		return false
+	}
+	s.store(key, value, false, ttl, stale)
+	return true
+}
+
+// setNegativeIfCurrent registra por ttl que a chave não existe na origem,
+// apenas se nenhuma invalidação ocorreu desde a geração informada
+func (s *localStore) setNegativeIfCurrent(key string, ttl time.Duration, generation uint64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.generation != generation {
+		return false
+	}
+	s.store(key, "", true, ttl, 0)
+	return true
+}
+
+// invalidate remove as chaves e avança a geração
+func (s *localStore) invalidate(keys ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.generation++
+	for _, key := range keys {
+		if elem, ok := s.entries[key]; ok {
+			s.remove(elem)
+		}
+	}
+}
+
+// flush remove todas as entradas
+func (s *localStore) flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.generation++
+	s.entries = make(map[string]*list.Element)
+	s.lru.Init()
+}
+
+func (s *localStore) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lru.Len()
+}
+
+func (s *localStore) evicted() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.evictions
+}
+
+func (s *localStore) store(key, value string, negative bool, ttl, stale time.Duration) {
+	freshUntil := s.clock.Now().Add(ttl)
+	entry := &localEntry{
+		key:        key,
+		value:      value,
+		negative:   negative,
+		freshUntil: freshUntil,
+		expiresAt:  freshUntil.Add(max(stale, 0)),
+	}
+	if elem, ok := s.entries[key]; ok {
+		elem.Value = entry
+		s.lru.MoveToFront(elem)
+		return
+	}
+
+	s.entries[key] = s.lru.PushFront(entry)
+	for s.maxEntries > 0 && s.lru.Len() > s.maxEntries {
+		s.remove(s.lru.Back())
+		s.evictions++
+	}
+}
+
+func (s *localStore) remove(elem *list.Element) {
+	s.lru.Remove(elem)
+	delete(s.entries, elem.Value.(*localEntry).key)
+}
@@ -0,0 +1,409 @@
+// Package tiered implementa um cache em duas camadas: uma cópia local em
+// memória na frente do Valkey/Redis. Leituras consultam primeiro a camada
+// local (read-through), escritas vão primeiro ao Valkey (write-through) e cada
+// escrita publica uma invalidação via pub/sub para que as demais réplicas
+// descartem suas cópias locais.
+package tiered
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/cache/valkey/interfaces"
+	"github.com/fsvxavier/nexs-lib/clock"
+	"github.com/fsvxavier/nexs-lib/concurrency/coalesce"
+)
+
+const (
+	// DefaultChannel canal de pub/sub usado para as invalidações
+	DefaultChannel = "nexs:cache:invalidate"
+	// DefaultLocalTTL tempo máximo de uma cópia local, que limita a
+	// inconsistência caso uma invalidação seja perdida
+	DefaultLocalTTL = time.Minute
+	// DefaultMaxEntries limite de entradas da camada local
+	DefaultMaxEntries = 10000
+	// DefaultResubscribeDelay espera antes de refazer uma inscrição perdida
+	DefaultResubscribeDelay = time.Second
+)
+
+// Camadas reportadas nas métricas
+const (
+	LayerLocal  = "local"
+	LayerRemote = "remote"
+	// LayerSource origem consultada pelo Loader de GetOrLoad
+	LayerSource = "source"
+)
+
+// Option configura o Cache.
+type Option func(*Cache)
+
+// WithChannel define o canal de invalidação. Todas as réplicas que
+// compartilham as chaves devem usar o mesmo canal.
+func WithChannel(channel string) Option {
+	return func(c *Cache) {
+		c.channel = channel
+	}
+}
+
+// WithLocalTTL define o TTL das cópias locais.
+func WithLocalTTL(ttl time.Duration) Option {
+	return func(c *Cache) {
+		c.localTTL = ttl
+	}
+}
+
+// WithMaxEntries define o limite de entradas da camada local. Ao atingi-lo as
+// entradas menos usadas são descartadas.
+func WithMaxEntries(n int) Option {
+	return func(c *Cache) {
+		c.maxEntries = n
+	}
+}
+
+// WithMetrics define o coletor que recebe hits, misses e erros por camada.
+func WithMetrics(metrics interfaces.IMetrics) Option {
+	return func(c *Cache) {
+		c.metrics = metrics
+	}
+}
+
+// WithNodeID define o identificador desta réplica, usado para ignorar as
+// próprias invalidações. O padrão é um identificador aleatório.
+func WithNodeID(id string) Option {
+	return func(c *Cache) {
+		c.nodeID = id
+	}
+}
+
+// WithClock define o relógio usado para expirar as cópias locais e aguardar
+// novas inscrições, como um clock.Fake em testes. O padrão é clock.Real().
+func WithClock(c clock.Clock) Option {
+	return func(cache *Cache) {
+		cache.clock = c
+	}
+}
+
+// LayerStats contadores de uma camada.
+type LayerStats struct {
+	Hits   uint64
+	Misses uint64
+	Errors uint64
+}
+
+// Stats estatísticas do cache por camada.
+type Stats struct {
+	Local                  LayerStats
+	Remote                 LayerStats
+	Source                 LayerStats
+	StaleServed            uint64
+	NegativeHits           uint64
+	LocalEntries           int
+	LocalEvictions         uint64
+	InvalidationsPublished uint64
+	InvalidationsReceived  uint64
+}
+
+type layerCounters struct {
+	hits   atomic.Uint64
+	misses atomic.Uint64
+	errors atomic.Uint64
+}
+
+func (l *layerCounters) snapshot() LayerStats {
+	return LayerStats{Hits: l.hits.Load(), Misses: l.misses.Load(), Errors: l.errors.Load()}
+}
+
+// invalidation mensagem publicada no canal de invalidação
+type invalidation struct {
+	Node string   `json:"node"`
+	Keys []string `json:"keys"`
+}
+
+// Cache cache em duas camadas sobre um interfaces.IClient.
+type Cache struct {
+	remote     interfaces.IClient
+	local      *localStore
+	channel    string
+	localTTL   time.Duration
+	maxEntries int
+	nodeID     string
+	metrics    interfaces.IMetrics
+	clock      clock.Clock
+	loadPolicy LoadPolicy
+	keySpaces  []keySpace
+	loads      coalesce.Group[string, string]
+
+	localStats   layerCounters
+	remoteStats  layerCounters
+	sourceStats  layerCounters
+	staleServed  atomic.Uint64
+	negativeHits atomic.Uint64
+	published    atomic.Uint64
+	received     atomic.Uint64
+
+	pubsub interfaces.IPubSub
+	cancel context.CancelFunc
+	done   chan struct{}
+	mu     sync.Mutex
+}
+
+// New cria o cache e se inscreve no canal de invalidação. As mensagens são
+// processadas em background até Close.
+func New(ctx context.Context, remote interfaces.IClient, opts ...Option) (*Cache, error) {
+	if remote == nil {
+		return nil, fmt.Errorf("cliente remoto não pode ser nil")
+	}
+
+	c := &Cache{
+		remote:     remote,
+		channel:    DefaultChannel,
+		localTTL:   DefaultLocalTTL,
+		maxEntries: DefaultMaxEntries,
+		loadPolicy: DefaultLoadPolicy,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.clock = clock.OrReal(c.clock)
+	if c.nodeID == "" {
+		c.nodeID = randomNodeID()
+	}
+	c.local = newLocalStore(c.maxEntries, c.clock)
+
+	pubsub, err := remote.Subscribe(ctx, c.channel)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao inscrever no canal de invalidação: %w", err)
+	}
+	c.pubsub = pubsub
+
+	listenCtx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.done = make(chan struct{})
+	go c.listen(listenCtx)
+
+	return c, nil
+}
+
+// Get retorna o valor da chave, consultando a camada local antes do Valkey.
+// Valores lidos do Valkey são copiados para a camada local.
+func (c *Cache) Get(ctx context.Context, key string) (string, error) {
+	if value, ok := c.local.get(key); ok {
+		c.record(LayerLocal, "hit", &c.localStats.hits)
+		return value, nil
+	}
+	c.record(LayerLocal, "miss", &c.localStats.misses)
+
+	// A geração é lida antes da consulta: se uma invalidação chegar enquanto
+	// o Valkey responde, o valor lido pode estar desatualizado
+	generation := c.local.currentGeneration()
+	value, err := c.remote.Get(ctx, key)
+	if err != nil {
+		if isNotFound(err) {
+			c.record(LayerRemote, "miss", &c.remoteStats.misses)
+		} else {
+			c.record(LayerRemote, "error", &c.remoteStats.errors)
+		}
+		return "", err
+	}
+	c.record(LayerRemote, "hit", &c.remoteStats.hits)
+
+	c.local.setIfCurrent(key, value, c.localTTL, 0, generation)
+	return value, nil
+}
+
+// Set grava o valor no Valkey e na camada local e invalida as cópias das
+// demais réplicas.
+func (c *Cache) Set(ctx context.Context, key, value string, expiration time.Duration) error {
+	if err := c.remote.Set(ctx, key, value, expiration); err != nil {
+		c.record(LayerRemote, "error", &c.remoteStats.errors)
+		return err
+	}
+
+	c.local.invalidate(key)
+	c.local.set(key, value, c.ttlFor(expiration))
+	return c.publish(ctx, key)
+}
+
+// Del remove as chaves do Valkey e de todas as camadas locais.
+func (c *Cache) Del(ctx context.Context, keys ...string) (int64, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	deleted, err := c.remote.Del(ctx, keys...)
+	if err != nil {
+		c.record(LayerRemote, "error", &c.remoteStats.errors)
+		return 0, err
+	}
+
+	c.local.invalidate(keys...)
+	return deleted, c.publish(ctx, keys...)
+}
+
+// Invalidate descarta as cópias locais das chaves em todas as réplicas sem
+// alterar o Valkey. Útil quando o valor foi alterado por outro processo.
+func (c *Cache) Invalidate(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	c.local.invalidate(keys...)
+	return c.publish(ctx, keys...)
+}
+
+// Stats retorna as estatísticas por camada.
+func (c *Cache) Stats() Stats {
+	return Stats{
+		Local:                  c.localStats.snapshot(),
+		Remote:                 c.remoteStats.snapshot(),
+		Source:                 c.sourceStats.snapshot(),
+		StaleServed:            c.staleServed.Load(),
+		NegativeHits:           c.negativeHits.Load(),
+		LocalEntries:           c.local.len(),
+		LocalEvictions:         c.local.evicted(),
+		InvalidationsPublished: c.published.Load(),
+		InvalidationsReceived:  c.received.Load(),
+	}
+}
+
+// Close encerra a inscrição no canal de invalidação. O cliente remoto não é
+// fechado.
+func (c *Cache) Close() error {
+	c.cancel()
+	<-c.done
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pubsub == nil {
+		return nil
+	}
+	err := c.pubsub.Close()
+	c.pubsub = nil
+	return err
+}
+
+// publish avisa as demais réplicas que as chaves mudaram
+func (c *Cache) publish(ctx context.Context, keys ...string) error {
+	payload, err := json.Marshal(invalidation{Node: c.nodeID, Keys: keys})
+	if err != nil {
+		return err
+	}
+	if _, err := c.remote.Publish(ctx, c.channel, string(payload)); err != nil {
+		c.record(LayerRemote, "error", &c.remoteStats.errors)
+		return fmt.Errorf("erro ao publicar invalidação: %w", err)
+	}
+	c.published.Add(1)
+	return nil
+}
+
+// listen aplica as invalidações recebidas. Se a inscrição cair, mensagens
+// podem ter sido perdidas: a camada local é esvaziada e a inscrição refeita.
+func (c *Cache) listen(ctx context.Context) {
+	defer close(c.done)
+
+	for {
+		c.mu.Lock()
+		pubsub := c.pubsub
+		c.mu.Unlock()
+
+		msg, err := pubsub.Receive(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			c.record(LayerRemote, "error", &c.remoteStats.errors)
+			c.local.flush()
+			if !c.resubscribe(ctx) {
+				return
+			}
+			continue
+		}
+
+		c.apply(msg)
+	}
+}
+
+// resubscribe refaz a inscrição até conseguir ou o cache ser fechado
+func (c *Cache) resubscribe(ctx context.Context) bool {
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-c.clock.After(DefaultResubscribeDelay):
+		}
+
+		pubsub, err := c.remote.Subscribe(ctx, c.channel)
+		if err != nil {
+			continue
+		}
+
+		c.mu.Lock()
+		old := c.pubsub
+		c.pubsub = pubsub
+		c.mu.Unlock()
+		if old != nil {
+			old.Close()
+		}
+
+		// Valores gravados entre a queda e a nova inscrição também foram perdidos
+		c.local.flush()
+		return true
+	}
+}
+
+// apply processa uma mensagem do canal de invalidação
+func (c *Cache) apply(msg interface{}) {
+	var payload string
+	switch m := msg.(type) {
+	case *interfaces.Message:
+		payload = m.Payload
+	case interfaces.Message:
+		payload = m.Payload
+	default:
+		// Confirmações de inscrição e outros eventos
+		return
+	}
+
+	var inv invalidation
+	if err := json.Unmarshal([]byte(payload), &inv); err != nil || inv.Node == c.nodeID {
+		return
+	}
+	c.received.Add(1)
+	c.local.invalidate(inv.Keys...)
+}
+
+// ttlFor limita o TTL local à expiração do valor no Valkey
+func (c *Cache) ttlFor(expiration time.Duration) time.Duration {
+	if expiration > 0 && expiration < c.localTTL {
+		return expiration
+	}
+	return c.localTTL
+}
+
+// record incrementa o contador e reporta a métrica da camada
+func (c *Cache) record(layer, result string, counter *atomic.Uint64) {
+	counter.Add(1)
+	if c.metrics != nil {
+		c.metrics.IncrementCounter("tiered_cache_requests", map[string]string{
+			"layer":  layer,
+			"result": result,
+		})
+	}
+}
+
+// isNotFound identifica o erro de chave ausente retornado pelos providers
+func isNotFound(err error) bool {
+	return strings.Contains(err.Error(), "key not found")
+}
+
+func randomNodeID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
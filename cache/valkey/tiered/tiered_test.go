@@ -0,0 +1,265 @@
+package tiered
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fsvxavier/nexs-lib/cache/valkey/interfaces"
+	"github.com/fsvxavier/nexs-lib/clock"
+)
+
+// fakeServer simula um Valkey compartilhado pelas réplicas
+type fakeServer struct {
+	mu           sync.Mutex
+	data         map[string]string
+	gets         int
+	subscribers  []*fakePubSub
+	subscribeErr error
+}
+
+func newFakeServer() *fakeServer {
+	return &fakeServer{data: make(map[string]string)}
+}
+
+func (s *fakeServer) getCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.gets
+}
+
+// fakeClient implementa apenas os comandos usados pelo Cache
+type fakeClient struct {
+	interfaces.IClient
+	server *fakeServer
+}
+
+func (c *fakeClient) Get(ctx context.Context, key string) (string, error) {
+	c.server.mu.Lock()
+	defer c.server.mu.Unlock()
+	c.server.gets++
+	value, ok := c.server.data[key]
+	if !ok {
+		return "", fmt.Errorf("key not found")
+	}
+	return value, nil
+}
+
+func (c *fakeClient) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	c.server.mu.Lock()
+	defer c.server.mu.Unlock()
+	c.server.data[key] = fmt.Sprintf("%v", value)
+	return nil
+}
+
+func (c *fakeClient) Del(ctx context.Context, keys ...string) (int64, error) {
+	c.server.mu.Lock()
+	defer c.server.mu.Unlock()
+	var deleted int64
+	for _, key := range keys {
+		if _, ok := c.server.data[key]; ok {
+			delete(c.server.data, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (c *fakeClient) Subscribe(ctx context.Context, channels ...string) (interfaces.IPubSub, error) {
+	c.server.mu.Lock()
+	defer c.server.mu.Unlock()
+	if c.server.subscribeErr != nil {
+		return nil, c.server.subscribeErr
+	}
+	ps := &fakePubSub{messages: make(chan interface{}, 16), closed: make(chan struct{})}
+	c.server.subscribers = append(c.server.subscribers, ps)
+	return ps, nil
+}
+
+func (c *fakeClient) Publish(ctx context.Context, channel string, message interface{}) (int64, error) {
+	c.server.mu.Lock()
+	defer c.server.mu.Unlock()
+	for _, ps := range c.server.subscribers {
+		ps.messages <- &interfaces.Message{Channel: channel, Payload: message.(string)}
+	}
+	return int64(len(c.server.subscribers)), nil
+}
+
+type fakePubSub struct {
+	interfaces.IPubSub
+	messages  chan interface{}
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func (p *fakePubSub) Receive(ctx context.Context) (interface{}, error) {
+	select {
+	case msg := <-p.messages:
+		if err, ok := msg.(error); ok {
+			return nil, err
+		}
+		return msg, nil
+	case <-p.closed:
+		return nil, errors.New("closed")
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (p *fakePubSub) Close() error {
+	p.closeOnce.Do(func() { close(p.closed) })
+	return nil
+}
+
+func newReplica(t *testing.T, server *fakeServer, opts ...Option) *Cache {
+	t.Helper()
+	cache, err := New(context.Background(), &fakeClient{server: server}, opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { cache.Close() })
+	return cache
+}
+
+func TestCache_ReadThroughAndWriteThrough(t *testing.T) {
+	server := newFakeServer()
+	cache := newReplica(t, server)
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "user:1", "alice", time.Hour))
+	assert.Equal(t, "alice", server.data["user:1"])
+
+	// A escrita já popula a camada local
+	value, err := cache.Get(ctx, "user:1")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", value)
+	assert.Equal(t, 0, server.getCount())
+
+	// Miss local busca no Valkey e copia para a camada local
+	server.data["user:2"] = "bob"
+	for i := 0; i < 3; i++ {
+		value, err = cache.Get(ctx, "user:2")
+		require.NoError(t, err)
+		assert.Equal(t, "bob", value)
+	}
+	assert.Equal(t, 1, server.getCount())
+
+	_, err = cache.Get(ctx, "missing")
+	assert.Error(t, err)
+
+	stats := cache.Stats()
+	assert.Equal(t, LayerStats{Hits: 3, Misses: 2}, stats.Local)
+	assert.Equal(t, LayerStats{Hits: 1, Misses: 1}, stats.Remote)
+	assert.Equal(t, 2, stats.LocalEntries)
+}
+
+func TestCache_InvalidationAcrossReplicas(t *testing.T) {
+	server := newFakeServer()
+	a := newReplica(t, server, WithNodeID("a"))
+	b := newReplica(t, server, WithNodeID("b"))
+	ctx := context.Background()
+
+	require.NoError(t, a.Set(ctx, "config", "v1", 0))
+	value, err := b.Get(ctx, "config")
+	require.NoError(t, err)
+	assert.Equal(t, "v1", value)
+
+	// A escrita em a invalida a cópia local de b
+	require.NoError(t, a.Set(ctx, "config", "v2", 0))
+	require.Eventually(t, func() bool { return b.Stats().InvalidationsReceived >= 2 }, time.Second, time.Millisecond)
+
+	value, err = b.Get(ctx, "config")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", value)
+
+	// Del remove de todas as camadas
+	_, err = a.Del(ctx, "config")
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return b.Stats().InvalidationsReceived >= 3 }, time.Second, time.Millisecond)
+	_, err = b.Get(ctx, "config")
+	assert.Error(t, err)
+
+	// Cada réplica ignora as próprias invalidações
+	assert.Equal(t, uint64(0), a.Stats().InvalidationsReceived)
+	assert.Equal(t, uint64(3), a.Stats().InvalidationsPublished)
+}
+
+func TestCache_LocalTTLAndEviction(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	server := newFakeServer()
+	cache := newReplica(t, server, WithClock(fake), WithLocalTTL(time.Minute), WithMaxEntries(2))
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "short", "1", 10*time.Second))
+	require.NoError(t, cache.Set(ctx, "long", "2", 0))
+
+	// A cópia local respeita a expiração do Valkey quando menor que o TTL local
+	fake.Advance(11 * time.Second)
+	_, err := cache.Get(ctx, "short")
+	require.NoError(t, err)
+	assert.Equal(t, 1, server.getCount())
+
+	require.NoError(t, cache.Set(ctx, "third", "3", 0))
+	assert.Equal(t, 2, cache.Stats().LocalEntries)
+	assert.Equal(t, uint64(1), cache.Stats().LocalEvictions)
+
+	fake.Advance(time.Minute)
+	_, err = cache.Get(ctx, "third")
+	require.NoError(t, err)
+	assert.Equal(t, 2, server.getCount())
+}
+
+func TestCache_StaleFillIsDiscarded(t *testing.T) {
+	server := newFakeServer()
+	cache := newReplica(t, server)
+
+	// Uma invalidação entre a leitura da geração e a gravação local impede
+	// que o valor lido do Valkey seja copiado
+	generation := cache.local.currentGeneration()
+	cache.local.invalidate("k")
+	assert.False(t, cache.local.setIfCurrent("k", "stale", time.Minute, 0, generation))
+	_, ok := cache.local.get("k")
+	assert.False(t, ok)
+}
+
+func TestCache_ResubscribeFlushesLocalCopies(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	server := newFakeServer()
+	cache := newReplica(t, server, WithClock(fake))
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "k", "v", 0))
+	require.Equal(t, 1, cache.Stats().LocalEntries)
+
+	// Queda da inscrição: mensagens podem ter sido perdidas
+	server.mu.Lock()
+	first := server.subscribers[0]
+	server.mu.Unlock()
+	first.messages <- errors.New("connection reset")
+
+	require.Eventually(t, func() bool { return cache.Stats().LocalEntries == 0 }, time.Second, time.Millisecond)
+	assert.Equal(t, uint64(1), cache.Stats().Remote.Errors)
+
+	// Após o intervalo a inscrição é refeita
+	require.Eventually(t, func() bool {
+		fake.Advance(DefaultResubscribeDelay)
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		return len(server.subscribers) == 2
+	}, time.Second, time.Millisecond)
+}
+
+func TestNew_SubscribeError(t *testing.T) {
+	server := newFakeServer()
+	server.subscribeErr = errors.New("not supported")
+
+	_, err := New(context.Background(), &fakeClient{server: server})
+	assert.ErrorContains(t, err, "not supported")
+
+	_, err = New(context.Background(), nil)
+	assert.Error(t, err)
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/fsvxavier/nexs-lib/cache/valkey/config"
 	"github.com/fsvxavier/nexs-lib/cache/valkey/hooks"
 	"github.com/fsvxavier/nexs-lib/cache/valkey/interfaces"
+	"github.com/fsvxavier/nexs-lib/concurrency/coalesce"
 )
 
 // Client representa o cliente principal do Valkey.
@@ -24,6 +25,7 @@ type Client struct {
 	healthChecker  interfaces.IHealthChecker
 	retryPolicy    interfaces.IRetryPolicy
 	circuitBreaker interfaces.ICircuitBreaker
+	loads          coalesce.Group[string, string]
 	mu             sync.RWMutex
 	closed         bool
 }
@@ -303,6 +305,33 @@ func (c *Client) Get(ctx context.Context, key string) (string, error) {
 	return result.(string), nil
 }
 
+// GetOrLoad obtém o valor de key e, quando ausente, carrega-o com load e o
+// grava com a expiração informada. Chamadas concorrentes para a mesma chave
+// executam load uma única vez (ver concurrency/coalesce), evitando que um
+// cache miss sobrecarregue a origem. Erros de leitura do cache são tratados
+// como miss, e uma falha ao gravar o valor carregado não impede seu retorno.
+func (c *Client) GetOrLoad(ctx context.Context, key string, expiration time.Duration, load func(ctx context.Context) (string, error)) (string, error) {
+	if value, err := c.Get(ctx, key); err == nil {
+		return value, nil
+	}
+
+	return c.loads.Do(ctx, key, func(ctx context.Context) (string, error) {
+		value, err := load(ctx)
+		if err != nil {
+			return "", err
+		}
+		// Falhas do SET já são reportadas pelos hooks de execução
+		_ = c.Set(ctx, key, value, expiration)
+		return value, nil
+	})
+}
+
+// LoadStats retorna as estatísticas de GetOrLoad, incluindo quantas cargas
+// concorrentes foram evitadas.
+func (c *Client) LoadStats() coalesce.Stats {
+	return c.loads.Stats()
+}
+
 // Set implementa IClient.Set.
 func (c *Client) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
 	_, err := c.executeWithHooks(ctx, "SET", []interface{}{key, value, expiration}, func() (interface{}, error) {
@@ -3,6 +3,8 @@ package valkey
 import (
 	"context"
 	"errors"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -427,6 +429,79 @@ func TestClient_Set(t *testing.T) {
 	})
 }
 
+func TestClient_GetOrLoad(t *testing.T) {
+	manager := NewManager()
+	mockProvider := &MockProvider{}
+	mockClient := &MockClient{}
+
+	mockProvider.On("Name").Return("test-provider")
+	mockProvider.On("NewClient", mock.Anything).Return(mockClient, nil)
+
+	err := manager.RegisterProvider("valkey-go", mockProvider)
+	require.NoError(t, err)
+
+	cfg := config.DefaultConfig()
+	cfg.KeyPrefix = "test:"
+	cfg.MaxRetries = 0
+	cfg.CircuitBreakerEnabled = false
+	client, err := manager.NewClient(cfg)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	t.Run("hit", func(t *testing.T) {
+		mockClient.On("Get", ctx, "test:cached").Return("value", nil).Once()
+
+		result, err := client.GetOrLoad(ctx, "cached", time.Minute, func(context.Context) (string, error) {
+			t.Fatal("load must not run on a hit")
+			return "", nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "value", result)
+	})
+
+	t.Run("concurrent misses load once", func(t *testing.T) {
+		mockClient.On("Get", ctx, "test:user:1").Return("", errors.New("key not found"))
+		mockClient.On("Set", mock.Anything, "test:user:1", "loaded", time.Minute).Return(nil).Once()
+
+		var loads atomic.Int32
+		release := make(chan struct{})
+		load := func(context.Context) (string, error) {
+			loads.Add(1)
+			<-release
+			return "loaded", nil
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				result, err := client.GetOrLoad(ctx, "user:1", time.Minute, load)
+				assert.NoError(t, err)
+				assert.Equal(t, "loaded", result)
+			}()
+		}
+		require.Eventually(t, func() bool { return client.LoadStats().Coalesced == 4 }, time.Second, time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), loads.Load())
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("load error", func(t *testing.T) {
+		mockClient.On("Get", ctx, "test:missing").Return("", errors.New("key not found")).Once()
+
+		_, err := client.GetOrLoad(ctx, "missing", time.Minute, func(context.Context) (string, error) {
+			return "", errors.New("not in database")
+		})
+
+		assert.EqualError(t, err, "not in database")
+	})
+}
+
 func TestClient_KeyPrefix(t *testing.T) {
 	manager := NewManager()
 	mockProvider := &MockProvider{}
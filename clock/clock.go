@@ -0,0 +1,103 @@
+// Package clock abstracts the passage of time, so code reading the time or
+// waiting on timers can be tested deterministically. Production code uses
+// Real, backed by the time package; tests use a Fake, whose time only moves
+// when the test advances it.
+package clock
+
+import "time"
+
+// Clock tells the time and creates timers.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// Since returns the time elapsed since t.
+	Since(t time.Time) time.Duration
+
+	// Until returns the duration until t.
+	Until(t time.Time) time.Duration
+
+	// Sleep pauses the calling goroutine for at least d.
+	Sleep(d time.Duration)
+
+	// After waits for d to elapse and then sends the time on the channel.
+	After(d time.Duration) <-chan time.Time
+
+	// NewTimer creates a Timer firing after d.
+	NewTimer(d time.Duration) Timer
+
+	// AfterFunc calls f after d. The Timer can cancel the call.
+	AfterFunc(d time.Duration, f func()) Timer
+
+	// NewTicker creates a Ticker ticking every d. d must be positive.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer is a single event, see time.Timer.
+type Timer interface {
+	// C returns the channel receiving the time when the timer fires; nil
+	// for timers created by AfterFunc.
+	C() <-chan time.Time
+
+	// Stop prevents the timer from firing. It reports whether the call
+	// stopped the timer.
+	Stop() bool
+
+	// Reset changes the timer to fire after d. It reports whether the
+	// timer was active.
+	Reset(d time.Duration) bool
+}
+
+// Ticker delivers ticks at intervals, see time.Ticker.
+type Ticker interface {
+	// C returns the channel receiving the ticks.
+	C() <-chan time.Time
+
+	// Stop turns off the ticker.
+	Stop()
+
+	// Reset changes the interval of the ticker. d must be positive.
+	Reset(d time.Duration)
+}
+
+// Real returns the Clock of the time package.
+func Real() Clock {
+	return realClock{}
+}
+
+// OrReal returns c, or Real when c is nil, for optional clock options.
+func OrReal(c Clock) Clock {
+	if c == nil {
+		return Real()
+	}
+	return c
+}
+
+// realClock implements Clock with the time package.
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) Since(t time.Time) time.Duration        { return time.Since(t) }
+func (realClock) Until(t time.Time) time.Duration        { return time.Until(t) }
+func (realClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return realTimer{time.AfterFunc(d, f)}
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTimer struct{ *time.Timer }
+
+func (t realTimer) C() <-chan time.Time { return t.Timer.C }
+
+type realTicker struct{ *time.Ticker }
+
+func (t realTicker) C() <-chan time.Time { return t.Ticker.C }
@@ -0,0 +1,161 @@
+package clock
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var start = time.Date(2025, time.March, 10, 12, 0, 0, 0, time.UTC)
+
+func TestReal(t *testing.T) {
+	c := Real()
+	before := time.Now()
+	assert.False(t, c.Now().Before(before))
+	assert.GreaterOrEqual(t, c.Since(before), time.Duration(0))
+
+	timer := c.NewTimer(time.Millisecond)
+	select {
+	case <-timer.C():
+	case <-time.After(time.Second):
+		t.Fatal("real timer did not fire")
+	}
+
+	ticker := c.NewTicker(time.Millisecond)
+	<-ticker.C()
+	ticker.Stop()
+
+	assert.Equal(t, c, OrReal(nil))
+	assert.Equal(t, Clock(NewFake(start)).Now(), OrReal(NewFake(start)).Now())
+}
+
+func TestFake_NowAndSetTime(t *testing.T) {
+	f := NewFake(start)
+	assert.Equal(t, start, f.Now())
+
+	f.Advance(90 * time.Second)
+	assert.Equal(t, start.Add(90*time.Second), f.Now())
+	assert.Equal(t, 90*time.Second, f.Since(start))
+	assert.Equal(t, -90*time.Second, f.Until(start))
+
+	f.SetTime(start)
+	assert.Equal(t, start, f.Now())
+
+	assert.Equal(t, time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), NewFake(time.Time{}).Now())
+}
+
+func TestFake_Timer(t *testing.T) {
+	f := NewFake(start)
+	timer := f.NewTimer(time.Minute)
+	assert.Equal(t, 1, f.Waiters())
+
+	f.Advance(59 * time.Second)
+	select {
+	case <-timer.C():
+		t.Fatal("timer fired early")
+	default:
+	}
+
+	f.Advance(time.Second)
+	select {
+	case fired := <-timer.C():
+		assert.Equal(t, start.Add(time.Minute), fired)
+	default:
+		t.Fatal("timer did not fire")
+	}
+	assert.Equal(t, 0, f.Waiters())
+	assert.False(t, timer.Stop())
+
+	assert.False(t, timer.Reset(time.Second))
+	assert.True(t, timer.Stop())
+	f.Advance(time.Hour)
+	select {
+	case <-timer.C():
+		t.Fatal("stopped timer fired")
+	default:
+	}
+}
+
+func TestFake_ImmediateTimer(t *testing.T) {
+	f := NewFake(start)
+	select {
+	case <-f.After(0):
+	default:
+		t.Fatal("zero timer did not fire")
+	}
+
+	done := make(chan struct{})
+	f.AfterFunc(0, func() { close(done) })
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("zero AfterFunc was not called")
+	}
+}
+
+func TestFake_AfterFuncOrder(t *testing.T) {
+	f := NewFake(start)
+	var order []int
+	var at []time.Time
+	f.AfterFunc(3*time.Second, func() { order = append(order, 3); at = append(at, f.Now()) })
+	f.AfterFunc(time.Second, func() { order = append(order, 1); at = append(at, f.Now()) })
+	stopped := f.AfterFunc(2*time.Second, func() { order = append(order, 2) })
+	require.True(t, stopped.Stop())
+
+	f.Advance(5 * time.Second)
+	assert.Equal(t, []int{1, 3}, order)
+	assert.Equal(t, []time.Time{start.Add(time.Second), start.Add(3 * time.Second)}, at)
+	assert.Equal(t, start.Add(5*time.Second), f.Now())
+}
+
+func TestFake_Ticker(t *testing.T) {
+	f := NewFake(start)
+	ticker := f.NewTicker(10 * time.Second)
+
+	f.Advance(10 * time.Second)
+	assert.Equal(t, start.Add(10*time.Second), <-ticker.C())
+
+	// Several periods deliver one tick, like time.Ticker.
+	f.Advance(35 * time.Second)
+	assert.Equal(t, start.Add(20*time.Second), <-ticker.C())
+	select {
+	case <-ticker.C():
+		t.Fatal("ticker delivered dropped ticks")
+	default:
+	}
+
+	ticker.Reset(time.Minute)
+	f.Advance(time.Minute)
+	assert.Equal(t, start.Add(105*time.Second), <-ticker.C())
+
+	ticker.Stop()
+	f.Advance(time.Hour)
+	assert.Equal(t, 0, f.Waiters())
+
+	assert.Panics(t, func() { f.NewTicker(0) })
+}
+
+func TestFake_SleepAndBlockUntil(t *testing.T) {
+	f := NewFake(start)
+	var woke atomic.Bool
+	done := make(chan struct{})
+	go func() {
+		f.Sleep(time.Hour)
+		woke.Store(true)
+		close(done)
+	}()
+
+	f.BlockUntil(1)
+	assert.False(t, woke.Load())
+	f.Advance(time.Hour)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("sleeper did not wake up")
+	}
+	assert.True(t, woke.Load())
+}
@@ -0,0 +1,255 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fake is a Clock whose time only moves when Advance or SetTime is called.
+// Timers, tickers, sleeps and AfterFunc calls fire, in deadline order, as the
+// time passes their deadline; AfterFunc functions run on the goroutine
+// advancing the clock, so their effects are visible when Advance returns.
+// Like time.Ticker, a ticker passed several times by one Advance delivers a
+// single tick. It is safe for concurrent use.
+type Fake struct {
+	mu      sync.Mutex
+	waiting *sync.Cond
+	now     time.Time
+	timers  []*fakeTimer
+}
+
+// NewFake creates a Fake clock starting at start, or at 2024-01-01 UTC when
+// start is zero.
+func NewFake(start time.Time) *Fake {
+	if start.IsZero() {
+		start = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+	f := &Fake{now: start}
+	f.waiting = sync.NewCond(&f.mu)
+	return f
+}
+
+// Now returns the current fake time.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Since returns the fake time elapsed since t.
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+// Until returns the fake duration until t.
+func (f *Fake) Until(t time.Time) time.Duration {
+	return t.Sub(f.Now())
+}
+
+// Sleep blocks until the clock is advanced by d.
+func (f *Fake) Sleep(d time.Duration) {
+	<-f.After(d)
+}
+
+// After returns a channel receiving the time once the clock is advanced by d.
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.NewTimer(d).C()
+}
+
+// NewTimer creates a Timer firing once the clock is advanced by d.
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	t := &fakeTimer{clock: f, c: make(chan time.Time, 1)}
+	f.schedule(t, d)
+	return t
+}
+
+// AfterFunc calls fn once the clock is advanced by d. Non-positive durations
+// call fn at once on its own goroutine, like time.AfterFunc.
+func (f *Fake) AfterFunc(d time.Duration, fn func()) Timer {
+	t := &fakeTimer{clock: f, fn: fn}
+	f.schedule(t, d)
+	return t
+}
+
+// NewTicker creates a Ticker ticking each time the clock passes a multiple
+// of d.
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	t := &fakeTimer{clock: f, c: make(chan time.Time, 1), period: d, ticker: true}
+	f.schedule(t, d)
+	return fakeTicker{t}
+}
+
+// Advance moves the time forward by d, firing the timers it passes.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	target := f.now.Add(d)
+	f.mu.Unlock()
+	f.advanceTo(target)
+}
+
+// SetTime moves the time to t, firing the timers it passes. Moving the time
+// backwards fires nothing.
+func (f *Fake) SetTime(t time.Time) {
+	f.mu.Lock()
+	if !t.After(f.now) {
+		f.now = t
+		f.mu.Unlock()
+		return
+	}
+	f.mu.Unlock()
+	f.advanceTo(t)
+}
+
+// Waiters returns the number of active timers, tickers and sleeps.
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.timers)
+}
+
+// BlockUntil blocks until at least n timers, tickers or sleeps are active.
+// Tests call it before Advance to make sure the goroutine under test is
+// waiting on the clock.
+func (f *Fake) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.timers) < n {
+		f.waiting.Wait()
+	}
+}
+
+// advanceTo fires, in deadline order, the timers due by target, then sets
+// the time to target.
+func (f *Fake) advanceTo(target time.Time) {
+	for {
+		f.mu.Lock()
+		if len(f.timers) == 0 || f.timers[0].deadline.After(target) {
+			if target.After(f.now) {
+				f.now = target
+			}
+			f.mu.Unlock()
+			return
+		}
+
+		t := f.timers[0]
+		if t.deadline.After(f.now) {
+			f.now = t.deadline
+		}
+		if t.period > 0 {
+			t.deadline = t.deadline.Add(t.period)
+			f.sort()
+		} else {
+			f.remove(t)
+		}
+		now := f.now
+		f.mu.Unlock()
+
+		t.fire(now, false)
+	}
+}
+
+// schedule activates the timer to fire after d. f.mu must not be held.
+func (f *Fake) schedule(t *fakeTimer, d time.Duration) bool {
+	f.mu.Lock()
+	active := f.remove(t)
+	now := f.now
+	if d <= 0 && !t.ticker {
+		f.mu.Unlock()
+		t.fire(now, true)
+		return active
+	}
+	t.deadline = now.Add(d)
+	f.timers = append(f.timers, t)
+	f.sort()
+	f.waiting.Broadcast()
+	f.mu.Unlock()
+	return active
+}
+
+// remove deactivates the timer and reports whether it was active. f.mu must
+// be held.
+func (f *Fake) remove(t *fakeTimer) bool {
+	for i, active := range f.timers {
+		if active == t {
+			f.timers = append(f.timers[:i], f.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// sort orders the timers by deadline, keeping creation order for equal
+// deadlines. f.mu must be held.
+func (f *Fake) sort() {
+	sort.SliceStable(f.timers, func(i, j int) bool {
+		return f.timers[i].deadline.Before(f.timers[j].deadline)
+	})
+}
+
+// fakeTimer implements Timer for Fake, and tickers through fakeTicker.
+type fakeTimer struct {
+	clock    *Fake
+	c        chan time.Time
+	fn       func()
+	ticker   bool
+	period   time.Duration
+	deadline time.Time
+}
+
+// fire delivers the time or calls the function. Immediate AfterFunc calls
+// run on their own goroutine, so callers holding locks do not deadlock.
+func (t *fakeTimer) fire(now time.Time, immediate bool) {
+	if t.fn != nil {
+		if immediate {
+			go t.fn()
+		} else {
+			t.fn()
+		}
+		return
+	}
+	select {
+	case t.c <- now:
+	default:
+	}
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+// Stop deactivates the timer and reports whether it was active.
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.clock.remove(t)
+}
+
+// Reset reschedules the timer after d.
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	return t.clock.schedule(t, d)
+}
+
+// fakeTicker implements Ticker for Fake.
+type fakeTicker struct {
+	*fakeTimer
+}
+
+// Stop deactivates the ticker.
+func (t fakeTicker) Stop() {
+	t.fakeTimer.Stop()
+}
+
+// Reset changes the interval; the next tick is d after the current time.
+func (t fakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("clock: non-positive interval for Ticker.Reset")
+	}
+	t.clock.mu.Lock()
+	t.period = d
+	t.clock.mu.Unlock()
+	t.clock.schedule(t.fakeTimer, d)
+}
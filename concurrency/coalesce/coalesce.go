@@ -0,0 +1,259 @@
+// Package coalesce deduplicates concurrent calls for the same key, like
+// singleflight: the first caller of Do runs the function and the callers
+// arriving while it runs share its result. Results can be kept for a short
+// TTL, so that bursts arriving right after a call are served without running
+// it again, and Stats counts how many calls were spared — the size of the
+// stampedes the group absorbed.
+package coalesce
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+// ErrPanic is wrapped by the errors of functions that panicked.
+var ErrPanic = errors.New("coalesce: function panicked")
+
+// Stats are the counters of a Group.
+type Stats struct {
+	// Calls is the number of Do calls.
+	Calls int64
+	// Executions is the number of times a function ran.
+	Executions int64
+	// Coalesced is the number of calls that joined a running execution.
+	Coalesced int64
+	// Hits is the number of calls served by a result kept for the TTL.
+	Hits int64
+	// Errors is the number of executions that failed.
+	Errors int64
+	// MaxWaiters is the largest number of callers that shared one
+	// execution.
+	MaxWaiters int64
+}
+
+// Saved returns the number of calls that did not run their function.
+func (s Stats) Saved() int64 {
+	return s.Coalesced + s.Hits
+}
+
+// Option configures a Group.
+type Option func(*options)
+
+type options struct {
+	ttl   time.Duration
+	clock clock.Clock
+}
+
+// WithTTL keeps successful results for ttl after their execution. Errors are
+// never kept. Zero, the default, only deduplicates concurrent calls.
+func WithTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.ttl = max(ttl, 0)
+	}
+}
+
+// WithClock sets the clock of result expiry. Defaults to clock.Real().
+func WithClock(c clock.Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+// Loader loads the value of a key, e.g. from a database on a cache miss.
+type Loader[K comparable, V any] func(ctx context.Context, key K) (V, error)
+
+// Group coalesces calls by key. The zero value deduplicates concurrent calls
+// without TTL; use New for options. A Group must not be copied.
+type Group[K comparable, V any] struct {
+	options options
+
+	mu       sync.Mutex
+	inflight map[K]*call[V]
+	results  map[K]*result[V]
+
+	calls, executions, coalesced, hits, errors, maxWaiters atomic.Int64
+}
+
+// call is a running execution.
+type call[V any] struct {
+	done    chan struct{}
+	waiters int64
+	value   V
+	err     error
+}
+
+// result is a kept result.
+type result[V any] struct {
+	value   V
+	expires time.Time
+	timer   clock.Timer
+}
+
+// New creates a Group.
+func New[K comparable, V any](opts ...Option) *Group[K, V] {
+	g := &Group[K, V]{}
+	for _, opt := range opts {
+		opt(&g.options)
+	}
+	return g
+}
+
+// Do returns the result of fn for key. When an execution for key is running
+// the caller waits for it instead of running fn, and when a result is kept
+// it is returned at once. fn runs with the context of the first caller
+// detached from its cancellation, so that one caller giving up does not fail
+// the others; a caller whose ctx is done stops waiting and gets ctx.Err().
+// A panic in fn becomes a ServerError returned to every waiter.
+func (g *Group[K, V]) Do(ctx context.Context, key K, fn func(ctx context.Context) (V, error)) (V, error) {
+	g.calls.Add(1)
+	clk := clock.OrReal(g.options.clock)
+
+	g.mu.Lock()
+	if r, ok := g.results[key]; ok {
+		if clk.Now().Before(r.expires) {
+			g.mu.Unlock()
+			g.hits.Add(1)
+			return r.value, nil
+		}
+		g.forgetResult(key, r)
+	}
+
+	c, running := g.inflight[key]
+	if running {
+		c.waiters++
+		g.mu.Unlock()
+		g.coalesced.Add(1)
+		return wait(ctx, c)
+	}
+
+	c = &call[V]{done: make(chan struct{}), waiters: 1}
+	if g.inflight == nil {
+		g.inflight = make(map[K]*call[V])
+	}
+	g.inflight[key] = c
+	g.mu.Unlock()
+
+	g.executions.Add(1)
+	go g.execute(context.WithoutCancel(ctx), key, c, fn)
+	return wait(ctx, c)
+}
+
+// Loader returns a Loader coalescing the calls of load by key, for cache
+// loaders that must not stampede the backing store on a miss.
+func (g *Group[K, V]) Loader(load Loader[K, V]) Loader[K, V] {
+	return func(ctx context.Context, key K) (V, error) {
+		return g.Do(ctx, key, func(ctx context.Context) (V, error) {
+			return load(ctx, key)
+		})
+	}
+}
+
+// Forget drops the kept result of key, e.g. after the value changed. A
+// running execution is not affected, but later calls do not join it.
+func (g *Group[K, V]) Forget(key K) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if r, ok := g.results[key]; ok {
+		g.forgetResult(key, r)
+	}
+	delete(g.inflight, key)
+}
+
+// Stats returns the counters of the group.
+func (g *Group[K, V]) Stats() Stats {
+	return Stats{
+		Calls:      g.calls.Load(),
+		Executions: g.executions.Load(),
+		Coalesced:  g.coalesced.Load(),
+		Hits:       g.hits.Load(),
+		Errors:     g.errors.Load(),
+		MaxWaiters: g.maxWaiters.Load(),
+	}
+}
+
+// execute runs fn, publishes its result to the waiters of c and keeps it for
+// the TTL.
+func (g *Group[K, V]) execute(ctx context.Context, key K, c *call[V], fn func(ctx context.Context) (V, error)) {
+	c.value, c.err = run(ctx, fn)
+	if c.err != nil {
+		g.errors.Add(1)
+	}
+
+	g.mu.Lock()
+	// Forget may have replaced the call: only the current one is removed.
+	if g.inflight[key] == c {
+		delete(g.inflight, key)
+		if c.err == nil && g.options.ttl > 0 {
+			g.keep(key, c.value)
+		}
+	}
+	waiters := c.waiters
+	g.mu.Unlock()
+
+	for {
+		peak := g.maxWaiters.Load()
+		if waiters <= peak || g.maxWaiters.CompareAndSwap(peak, waiters) {
+			break
+		}
+	}
+	close(c.done)
+}
+
+// keep stores value for the TTL. g.mu must be held.
+func (g *Group[K, V]) keep(key K, value V) {
+	clk := clock.OrReal(g.options.clock)
+	if g.results == nil {
+		g.results = make(map[K]*result[V])
+	}
+	r := &result[V]{value: value, expires: clk.Now().Add(g.options.ttl)}
+	// Expired results are dropped even when their key is not asked again.
+	r.timer = clk.AfterFunc(g.options.ttl, func() {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		if g.results[key] == r {
+			delete(g.results, key)
+		}
+	})
+	g.results[key] = r
+}
+
+// forgetResult drops a kept result. g.mu must be held.
+func (g *Group[K, V]) forgetResult(key K, r *result[V]) {
+	r.timer.Stop()
+	delete(g.results, key)
+}
+
+// wait returns the result of c once done, or the error of ctx.
+func wait[V any](ctx context.Context, c *call[V]) (V, error) {
+	select {
+	case <-c.done:
+		return c.value, c.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// run calls fn, turning a panic into an error.
+func run[V any](ctx context.Context, fn func(ctx context.Context) (V, error)) (value V, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = domainerrors.NewWithMetadata(interfaces.ServerError, "COALESCE_PANIC",
+				fmt.Sprintf("coalesced function panicked: %v", r), map[string]interface{}{
+					"panic": fmt.Sprint(r),
+					"stack": string(debug.Stack()),
+				}).Wrap(ErrPanic)
+		}
+	}()
+	return fn(ctx)
+}
@@ -0,0 +1,178 @@
+package coalesce
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+func TestDo_Coalesces(t *testing.T) {
+	var g Group[string, int]
+	var executions atomic.Int32
+	release := make(chan struct{})
+
+	const callers = 10
+	var wg sync.WaitGroup
+	results := make([]int, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := g.Do(context.Background(), "user:1", func(context.Context) (int, error) {
+				executions.Add(1)
+				<-release
+				return 42, nil
+			})
+			require.NoError(t, err)
+			results[i] = v
+		}()
+	}
+
+	require.Eventually(t, func() bool { return g.Stats().Coalesced == callers-1 }, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), executions.Load())
+	for _, v := range results {
+		assert.Equal(t, 42, v)
+	}
+	stats := g.Stats()
+	assert.Equal(t, int64(1), stats.Executions)
+	assert.Equal(t, int64(callers-1), stats.Coalesced)
+	assert.Equal(t, int64(callers-1), stats.Saved())
+	assert.Equal(t, int64(callers), stats.MaxWaiters)
+}
+
+func TestDo_DistinctKeys(t *testing.T) {
+	g := New[int, int]()
+	for i := 0; i < 3; i++ {
+		v, err := g.Do(context.Background(), i, func(context.Context) (int, error) { return i * 10, nil })
+		require.NoError(t, err)
+		assert.Equal(t, i*10, v)
+	}
+	assert.Equal(t, int64(3), g.Stats().Executions)
+}
+
+func TestDo_TTL(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	g := New[string, string](WithTTL(time.Second), WithClock(fake))
+
+	var executions int
+	load := func(context.Context) (string, error) {
+		executions++
+		return "value", nil
+	}
+
+	for i := 0; i < 3; i++ {
+		v, err := g.Do(context.Background(), "k", load)
+		require.NoError(t, err)
+		assert.Equal(t, "value", v)
+	}
+	assert.Equal(t, 1, executions)
+	assert.Equal(t, int64(2), g.Stats().Hits)
+
+	fake.Advance(time.Second)
+	_, err := g.Do(context.Background(), "k", load)
+	require.NoError(t, err)
+	assert.Equal(t, 2, executions)
+
+	g.Forget("k")
+	_, err = g.Do(context.Background(), "k", load)
+	require.NoError(t, err)
+	assert.Equal(t, 3, executions)
+}
+
+func TestDo_ErrorsAreNotKept(t *testing.T) {
+	g := New[string, int](WithTTL(time.Minute))
+	boom := errors.New("db down")
+
+	_, err := g.Do(context.Background(), "k", func(context.Context) (int, error) { return 0, boom })
+	assert.ErrorIs(t, err, boom)
+
+	v, err := g.Do(context.Background(), "k", func(context.Context) (int, error) { return 7, nil })
+	require.NoError(t, err)
+	assert.Equal(t, 7, v)
+
+	stats := g.Stats()
+	assert.Equal(t, int64(2), stats.Executions)
+	assert.Equal(t, int64(1), stats.Errors)
+}
+
+func TestDo_CallerCancellation(t *testing.T) {
+	var g Group[string, int]
+	release := make(chan struct{})
+	fn := func(ctx context.Context) (int, error) {
+		<-release
+		return 1, ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := g.Do(ctx, "k", fn)
+		first <- err
+	}()
+	require.Eventually(t, func() bool { return g.Stats().Executions == 1 }, time.Second, time.Millisecond)
+
+	second := make(chan int, 1)
+	go func() {
+		v, _ := g.Do(context.Background(), "k", fn)
+		second <- v
+	}()
+	require.Eventually(t, func() bool { return g.Stats().Coalesced == 1 }, time.Second, time.Millisecond)
+
+	// The first caller gives up; the execution goes on for the second.
+	cancel()
+	assert.ErrorIs(t, <-first, context.Canceled)
+	close(release)
+	assert.Equal(t, 1, <-second)
+}
+
+func TestDo_Panic(t *testing.T) {
+	var g Group[string, int]
+	_, err := g.Do(context.Background(), "k", func(context.Context) (int, error) {
+		panic("boom")
+	})
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrPanic)
+	var domainErr interfaces.DomainErrorInterface
+	require.True(t, errors.As(err, &domainErr))
+	assert.Equal(t, "COALESCE_PANIC", domainErr.Code())
+	assert.Equal(t, interfaces.ServerError, domainErr.Type())
+}
+
+func TestLoader(t *testing.T) {
+	g := New[int, string]()
+	var executions atomic.Int32
+	release := make(chan struct{})
+	load := g.Loader(func(_ context.Context, id int) (string, error) {
+		executions.Add(1)
+		<-release
+		return "user", nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := load(context.Background(), 1)
+			assert.NoError(t, err)
+			assert.Equal(t, "user", v)
+		}()
+	}
+	require.Eventually(t, func() bool { return g.Stats().Coalesced == 4 }, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), executions.Load())
+}
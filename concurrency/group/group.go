@@ -0,0 +1,208 @@
+// Package group runs related tasks in goroutines and waits for them, like
+// errgroup: panics of tasks become ServerError domain errors carrying their
+// stack, SetLimit bounds the number of running tasks, every task runs in a
+// span named after its label, and the group either cancels its context on
+// the first error or collects the errors of all tasks.
+package group
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+// ErrPanic is wrapped by the errors of tasks that panicked.
+var ErrPanic = errors.New("group: task panicked")
+
+// tracerName identifies the spans of tasks.
+const tracerName = "github.com/fsvxavier/nexs-lib/concurrency/group"
+
+// SpanAttributeTask is the span attribute of the task label.
+const SpanAttributeTask = "group.task"
+
+// Mode selects how a group reacts to failed tasks.
+type Mode int
+
+const (
+	// FailFast cancels the group context on the first error, which Wait
+	// returns. It is the default.
+	FailFast Mode = iota
+	// CollectAll lets every task run to completion; Wait returns the errors
+	// of all failed tasks joined, in completion order.
+	CollectAll
+)
+
+// Option configures a Group.
+type Option func(*Group)
+
+// WithMode sets the error mode of the group.
+func WithMode(mode Mode) Option {
+	return func(g *Group) {
+		g.mode = mode
+	}
+}
+
+// WithLimit bounds the number of running tasks, see SetLimit.
+func WithLimit(n int) Option {
+	return func(g *Group) {
+		g.SetLimit(n)
+	}
+}
+
+// WithTracerProvider sets the provider of the task spans. Defaults to the
+// global provider.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(g *Group) {
+		g.tracer = provider.Tracer(tracerName)
+	}
+}
+
+// Group runs tasks and waits for them. Use New; a Group must not be copied.
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	mode   Mode
+	tracer trace.Tracer
+
+	wg  sync.WaitGroup
+	sem chan struct{}
+
+	mu   sync.Mutex
+	errs []error
+}
+
+// New creates a Group and the context of its tasks, derived from ctx. The
+// context is cancelled when a task fails in FailFast mode, and in any case
+// when Wait returns; its cause is the first error.
+func New(ctx context.Context, opts ...Option) (*Group, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	g := &Group{
+		ctx:    ctx,
+		cancel: cancel,
+		tracer: otel.GetTracerProvider().Tracer(tracerName),
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g, ctx
+}
+
+// SetLimit bounds the number of running tasks to n; a negative n removes
+// the bound. Go blocks while the limit is reached. The limit must not change
+// while tasks are running.
+func (g *Group) SetLimit(n int) {
+	if n < 0 {
+		g.sem = nil
+		return
+	}
+	if len(g.sem) != 0 {
+		panic(fmt.Errorf("group: modify limit while %d tasks are running", len(g.sem)))
+	}
+	g.sem = make(chan struct{}, n)
+}
+
+// Go runs fn in a goroutine with the group context, in a span named label.
+// It blocks while the limit of running tasks is reached.
+func (g *Group) Go(label string, fn func(ctx context.Context) error) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+	g.start(label, fn)
+}
+
+// TryGo runs fn like Go when the limit of running tasks is not reached, and
+// reports whether it did.
+func (g *Group) TryGo(label string, fn func(ctx context.Context) error) bool {
+	if g.sem != nil {
+		select {
+		case g.sem <- struct{}{}:
+		default:
+			return false
+		}
+	}
+	g.start(label, fn)
+	return true
+}
+
+// Wait waits for all tasks, cancels the group context and returns the first
+// error in FailFast mode, or all errors joined in CollectAll mode.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+
+	g.mu.Lock()
+	errs := g.errs
+	g.mu.Unlock()
+
+	var err error
+	switch {
+	case len(errs) == 0:
+	case g.mode == CollectAll:
+		err = errors.Join(errs...)
+	default:
+		err = errs[0]
+	}
+	g.cancel(err)
+	return err
+}
+
+// start runs a task whose slot is acquired.
+func (g *Group) start(label string, fn func(ctx context.Context) error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if g.sem != nil {
+			defer func() { <-g.sem }()
+		}
+
+		if err := g.run(label, fn); err != nil {
+			g.mu.Lock()
+			g.errs = append(g.errs, err)
+			first := len(g.errs) == 1
+			g.mu.Unlock()
+
+			if first && g.mode == FailFast {
+				g.cancel(err)
+			}
+		}
+	}()
+}
+
+// run runs a task in its span, turning a panic into an error.
+func (g *Group) run(label string, fn func(ctx context.Context) error) (err error) {
+	name := label
+	if name == "" {
+		name = SpanAttributeTask
+	}
+	ctx, span := g.tracer.Start(g.ctx, name, trace.WithAttributes(attribute.String(SpanAttributeTask, label)))
+	defer func() {
+		if p := recover(); p != nil {
+			err = panicError(label, p)
+		}
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+	return fn(ctx)
+}
+
+// panicError returns the domain error of a panicked task.
+func panicError(label string, p any) error {
+	return domainerrors.NewWithMetadata(interfaces.ServerError, "TASK_PANIC",
+		fmt.Sprintf("task %q panicked: %v", label, p), map[string]interface{}{
+			"task":  label,
+			"panic": fmt.Sprint(p),
+			"stack": string(debug.Stack()),
+		}).Wrap(ErrPanic)
+}
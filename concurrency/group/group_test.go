@@ -0,0 +1,160 @@
+package group
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+func TestGroup(t *testing.T) {
+	g, ctx := New(context.Background())
+	var count atomic.Int32
+	for i := 0; i < 10; i++ {
+		g.Go("task", func(context.Context) error {
+			count.Add(1)
+			return nil
+		})
+	}
+	require.NoError(t, g.Wait())
+	assert.Equal(t, int32(10), count.Load())
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+}
+
+func TestGroup_FailFast(t *testing.T) {
+	boom := errors.New("boom")
+	g, ctx := New(context.Background())
+
+	g.Go("fails", func(context.Context) error { return boom })
+	g.Go("waits", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	assert.Same(t, boom, g.Wait())
+	assert.Same(t, boom, context.Cause(ctx))
+}
+
+func TestGroup_CollectAll(t *testing.T) {
+	first, second := errors.New("first"), errors.New("second")
+	g, ctx := New(context.Background(), WithMode(CollectAll))
+
+	release := make(chan struct{})
+	var completed atomic.Bool
+	g.Go("first", func(context.Context) error { return first })
+	g.Go("second", func(context.Context) error {
+		<-release
+		return second
+	})
+	g.Go("slow", func(ctx context.Context) error {
+		<-release
+		// The context is not cancelled by the failures.
+		completed.Store(ctx.Err() == nil)
+		return nil
+	})
+
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	err := g.Wait()
+	assert.ErrorIs(t, err, first)
+	assert.ErrorIs(t, err, second)
+	assert.True(t, completed.Load())
+	assert.Error(t, ctx.Err())
+}
+
+func TestGroup_Panic(t *testing.T) {
+	g, _ := New(context.Background())
+	g.Go("explodes", func(context.Context) error {
+		panic("kaboom")
+	})
+
+	err := g.Wait()
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrPanic)
+
+	var domainErr interfaces.DomainErrorInterface
+	require.True(t, errors.As(err, &domainErr))
+	assert.Equal(t, interfaces.ServerError, domainErr.Type())
+	assert.Equal(t, "TASK_PANIC", domainErr.Code())
+	assert.Contains(t, domainErr.Error(), `task "explodes" panicked: kaboom`)
+	assert.Equal(t, "explodes", domainErr.Metadata()["task"])
+	assert.Contains(t, domainErr.Metadata()["stack"], "TestGroup_Panic")
+}
+
+func TestGroup_SetLimit(t *testing.T) {
+	g, _ := New(context.Background(), WithLimit(2))
+	var running, peak atomic.Int32
+	for i := 0; i < 8; i++ {
+		g.Go("limited", func(context.Context) error {
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			running.Add(-1)
+			return nil
+		})
+	}
+	require.NoError(t, g.Wait())
+	assert.Equal(t, int32(2), peak.Load())
+}
+
+func TestGroup_TryGo(t *testing.T) {
+	g, _ := New(context.Background())
+	g.SetLimit(1)
+
+	release := make(chan struct{})
+	assert.True(t, g.TryGo("first", func(context.Context) error {
+		<-release
+		return nil
+	}))
+	assert.False(t, g.TryGo("second", func(context.Context) error { return nil }))
+	assert.Panics(t, func() { g.SetLimit(3) })
+
+	close(release)
+	require.NoError(t, g.Wait())
+	assert.True(t, g.TryGo("third", func(context.Context) error { return nil }))
+	require.NoError(t, g.Wait())
+}
+
+func TestGroup_Tracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	parentCtx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+
+	g, _ := New(parentCtx, WithTracerProvider(provider), WithMode(CollectAll))
+	g.Go("load-user", func(context.Context) error { return nil })
+	g.Go("load-orders", func(context.Context) error { return errors.New("timeout") })
+	_ = g.Wait()
+	parent.End()
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	require.Len(t, spans, 3)
+
+	user := spans["load-user"]
+	require.NotNil(t, user)
+	assert.Equal(t, parent.SpanContext().SpanID(), user.Parent().SpanID())
+	assert.Contains(t, user.Attributes(), attribute.String(SpanAttributeTask, "load-user"))
+	assert.Equal(t, codes.Unset, user.Status().Code)
+
+	orders := spans["load-orders"]
+	require.NotNil(t, orders)
+	assert.Equal(t, codes.Error, orders.Status().Code)
+	assert.Equal(t, "timeout", orders.Status().Description)
+}
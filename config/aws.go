@@ -0,0 +1,196 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// AWSConfig configures the AWS Secrets Manager resolver. Empty fields are read
+// from the standard AWS environment variables.
+type AWSConfig struct {
+	// Region of the secrets. Defaults to AWS_REGION or AWS_DEFAULT_REGION.
+	Region string
+	// AccessKeyID defaults to AWS_ACCESS_KEY_ID.
+	AccessKeyID string
+	// SecretAccessKey defaults to AWS_SECRET_ACCESS_KEY.
+	SecretAccessKey string
+	// SessionToken defaults to AWS_SESSION_TOKEN.
+	SessionToken string
+	// Endpoint overrides the regional endpoint, e.g. for LocalStack.
+	Endpoint string
+	// HTTPClient used for requests. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// AWSResolver reads secrets from AWS Secrets Manager. The reference path is
+// the secret name or ARN: secretref://aws/prod/db#password.
+type AWSResolver struct {
+	cfg AWSConfig
+	now func() time.Time
+}
+
+// NewAWSResolver creates a Secrets Manager resolver.
+func NewAWSResolver(cfg AWSConfig) *AWSResolver {
+	if cfg.Region == "" {
+		cfg.Region = os.Getenv("AWS_REGION")
+	}
+	if cfg.Region == "" {
+		cfg.Region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if cfg.AccessKeyID == "" {
+		cfg.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+		cfg.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		cfg.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://secretsmanager." + cfg.Region + ".amazonaws.com"
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+	return &AWSResolver{cfg: cfg, now: time.Now}
+}
+
+// Scheme implements SecretResolver.
+func (a *AWSResolver) Scheme() string {
+	return "aws"
+}
+
+// Resolve implements SecretResolver. Binary secrets are returned as raw bytes.
+func (a *AWSResolver) Resolve(ctx context.Context, path string) (string, error) {
+	payload, err := json.Marshal(map[string]string{"SecretId": path})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.cfg.Endpoint+"/", bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	a.sign(req, payload)
+
+	resp, err := a.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSecretResponse))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", awsError(resp.StatusCode, body)
+	}
+
+	var response struct {
+		SecretString *string `json:"SecretString"`
+		SecretBinary string  `json:"SecretBinary"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("aws: decode response: %w", err)
+	}
+	if response.SecretString != nil {
+		return *response.SecretString, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(response.SecretBinary)
+	if err != nil {
+		return "", fmt.Errorf("aws: decode binary secret: %w", err)
+	}
+	return string(data), nil
+}
+
+// awsError converts an error response of the JSON protocol.
+func awsError(status int, body []byte) error {
+	var response struct {
+		Type    string `json:"__type"`
+		Message string `json:"message"`
+	}
+	_ = json.Unmarshal(body, &response)
+	// The type may be qualified, e.g. "com.amazonaws...#ResourceNotFoundException"
+	kind := response.Type[strings.LastIndex(response.Type, "#")+1:]
+
+	if kind == "ResourceNotFoundException" {
+		return fmt.Errorf("%w: %s", ErrSecretNotFound, response.Message)
+	}
+	if kind == "" {
+		return fmt.Errorf("aws: unexpected status %d", status)
+	}
+	return errors.New("aws: " + kind + ": " + response.Message)
+}
+
+// sign adds the AWS Signature Version 4 headers to the request.
+func (a *AWSResolver) sign(req *http.Request, payload []byte) {
+	now := a.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(payload)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if a.cfg.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", a.cfg.SessionToken)
+	}
+
+	headers := []string{"content-type", "host", "x-amz-date", "x-amz-target"}
+	values := map[string]string{
+		"content-type": req.Header.Get("Content-Type"),
+		"host":         req.URL.Host,
+		"x-amz-date":   amzDate,
+		"x-amz-target": req.Header.Get("X-Amz-Target"),
+	}
+	if a.cfg.SessionToken != "" {
+		headers = append(headers, "x-amz-security-token")
+		values["x-amz-security-token"] = a.cfg.SessionToken
+	}
+
+	var canonicalHeaders strings.Builder
+	for _, h := range headers {
+		canonicalHeaders.WriteString(h + ":" + strings.TrimSpace(values[h]) + "\n")
+	}
+	signedHeaders := strings.Join(headers, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method, path, req.URL.RawQuery, canonicalHeaders.String(), signedHeaders, payloadHash,
+	}, "\n")
+
+	scope := date + "/" + a.cfg.Region + "/secretsmanager/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+a.cfg.SecretAccessKey), date)
+	key = hmacSHA256(key, a.cfg.Region)
+	key = hmacSHA256(key, "secretsmanager")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+a.cfg.AccessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
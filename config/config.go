@@ -0,0 +1,218 @@
+// Package config loads typed configuration from layered sources. Layers are
+// merged with increasing precedence: defaults < files (YAML, JSON or TOML) <
+// custom sources < environment variables < command line flags. The result is
+// validated with the struct validator before being returned, and can be kept
+// in a Store and refreshed by a Watcher when the files change.
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"reflect"
+
+	"github.com/fsvxavier/nexs-lib/validation/validator"
+)
+
+// ErrInvalidConfig wraps validation failures of a loaded configuration.
+var ErrInvalidConfig = errors.New("config: invalid configuration")
+
+// options holds the loader configuration shared by every type parameter.
+type options struct {
+	defaults  any
+	files     []*fileSource
+	sources   []Source
+	envPrefix string
+	useEnv    bool
+	flags     *flag.FlagSet
+	secrets   *Secrets
+	validate  func(any) error
+}
+
+// Option configures a Loader.
+type Option func(*options)
+
+// WithDefaults sets the lowest-precedence layer, usually a value of the config type.
+func WithDefaults(defaults any) Option {
+	return func(o *options) {
+		o.defaults = defaults
+	}
+}
+
+// WithFile adds a required configuration file. The format is detected from the
+// extension: .yaml, .yml, .json or .toml. Files are merged in the order added.
+func WithFile(path string) Option {
+	return func(o *options) {
+		o.files = append(o.files, &fileSource{path: path})
+	}
+}
+
+// WithOptionalFile adds a configuration file that is skipped when missing.
+func WithOptionalFile(path string) Option {
+	return func(o *options) {
+		o.files = append(o.files, &fileSource{path: path, optional: true})
+	}
+}
+
+// WithSource adds a custom layer, merged after the files.
+func WithSource(source Source) Option {
+	return func(o *options) {
+		o.sources = append(o.sources, source)
+	}
+}
+
+// WithEnv overlays environment variables named after the field paths, e.g.
+// APP_DATABASE_MAX_CONNS for the field database.max_conns with prefix "APP".
+func WithEnv(prefix string) Option {
+	return func(o *options) {
+		o.useEnv = true
+		o.envPrefix = prefix
+	}
+}
+
+// WithFlags overlays the flags explicitly set on the parsed FlagSet. Flags are
+// matched by field path, as "database.max_conns" or "database-max-conns".
+func WithFlags(fs *flag.FlagSet) Option {
+	return func(o *options) {
+		o.flags = fs
+	}
+}
+
+// WithSecrets resolves secretref:// values of every layer at load time.
+func WithSecrets(secrets *Secrets) Option {
+	return func(o *options) {
+		o.secrets = secrets
+	}
+}
+
+// WithValidator replaces the default validation (validator.ValidateStruct).
+// A nil function disables validation.
+func WithValidator(validate func(any) error) Option {
+	return func(o *options) {
+		o.validate = validate
+	}
+}
+
+// Loader loads configurations of type T.
+type Loader[T any] struct {
+	opts options
+}
+
+// NewLoader creates a loader for the config type T, which must be a struct.
+func NewLoader[T any](opts ...Option) *Loader[T] {
+	o := options{
+		validate: func(cfg any) error { return validator.ValidateStruct(cfg) },
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Loader[T]{opts: o}
+}
+
+// Load loads a configuration using a temporary Loader.
+func Load[T any](ctx context.Context, opts ...Option) (*T, error) {
+	return NewLoader[T](opts...).Load(ctx)
+}
+
+// Load reads every layer, merges them, decodes the result into a new T and
+// validates it.
+func (l *Loader[T]) Load(ctx context.Context) (*T, error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config: type %s is not a struct", t)
+	}
+
+	layers, err := l.layers(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := make(map[string]any)
+	for _, layer := range layers {
+		var values any = layer.values
+		if l.opts.secrets != nil {
+			if values, err = resolveSecrets(ctx, l.opts.secrets, values, ""); err != nil {
+				return nil, fmt.Errorf("config: %s: %w", layer.name, err)
+			}
+		}
+
+		normalized, err := coerce(t, values, "")
+		if err != nil {
+			return nil, fmt.Errorf("config: %s: %w", layer.name, err)
+		}
+		mergeMaps(merged, normalized.(map[string]any))
+	}
+
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return nil, fmt.Errorf("config: encode merged values: %w", err)
+	}
+	cfg := new(T)
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("config: decode into %s: %w", t, err)
+	}
+
+	if l.opts.validate != nil {
+		if err := l.opts.validate(cfg); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+		}
+	}
+	return cfg, nil
+}
+
+// Files returns the configuration files used by the loader.
+func (l *Loader[T]) Files() []string {
+	files := make([]string, len(l.opts.files))
+	for i, f := range l.opts.files {
+		files[i] = f.path
+	}
+	return files
+}
+
+// layer is a named set of values to merge.
+type layer struct {
+	name   string
+	values map[string]any
+}
+
+// layers reads the layers in precedence order.
+func (l *Loader[T]) layers(ctx context.Context, t reflect.Type) ([]layer, error) {
+	var layers []layer
+
+	if l.opts.defaults != nil {
+		values, err := toMap(l.opts.defaults)
+		if err != nil {
+			return nil, fmt.Errorf("config: defaults: %w", err)
+		}
+		layers = append(layers, layer{name: "defaults", values: values})
+	}
+
+	sources := make([]Source, 0, len(l.opts.files)+len(l.opts.sources))
+	for _, f := range l.opts.files {
+		sources = append(sources, f)
+	}
+	sources = append(sources, l.opts.sources...)
+
+	for _, source := range sources {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		values, err := source.Load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if values != nil {
+			layers = append(layers, layer{name: source.Name(), values: values})
+		}
+	}
+
+	if l.opts.useEnv {
+		layers = append(layers, layer{name: "env", values: envValues(t, l.opts.envPrefix)})
+	}
+	if l.opts.flags != nil {
+		layers = append(layers, layer{name: "flags", values: flagValues(t, l.opts.flags)})
+	}
+	return layers, nil
+}
@@ -0,0 +1,218 @@
+package config
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/parsers/bytesize"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type databaseConfig struct {
+	Host     string        `json:"host" validate:"required"`
+	Port     int           `json:"port" validate:"min=1,max=65535"`
+	MaxConns int           `json:"max_conns"`
+	Timeout  time.Duration `json:"timeout"`
+}
+
+type appConfig struct {
+	Name     string            `json:"name" validate:"required"`
+	Debug    bool              `json:"debug"`
+	Tags     []string          `json:"tags"`
+	Labels   map[string]string `json:"labels"`
+	Database databaseConfig    `json:"database"`
+	Cache    *struct {
+		Enabled bool `json:"enabled"`
+	} `json:"cache"`
+}
+
+var defaults = appConfig{
+	Name:     "app",
+	Database: databaseConfig{Host: "localhost", Port: 5432, MaxConns: 10, Timeout: 5 * time.Second},
+}
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_Formats(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.yaml": "name: yaml\ndatabase:\n  port: 6432\n  timeout: 30s\ntags: [a, b]\n",
+		"config.json": `{"name": "json", "database": {"port": 6432, "timeout": "30s"}, "tags": ["a", "b"]}`,
+		"config.toml": "name = \"toml\"\ntags = [\"a\", \"b\"]\n[database]\nport = 6432\ntimeout = \"30s\"\n",
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := writeFile(t, dir, name, content)
+
+			cfg, err := Load[appConfig](context.Background(), WithDefaults(defaults), WithFile(path))
+			require.NoError(t, err)
+
+			assert.Equal(t, filepath.Ext(name)[1:], cfg.Name)
+			assert.Equal(t, []string{"a", "b"}, cfg.Tags)
+			assert.Equal(t, 6432, cfg.Database.Port)
+			assert.Equal(t, 30*time.Second, cfg.Database.Timeout)
+			// Values missing from the file keep the defaults
+			assert.Equal(t, "localhost", cfg.Database.Host)
+			assert.Equal(t, 10, cfg.Database.MaxConns)
+		})
+	}
+}
+
+func TestLoad_Layers(t *testing.T) {
+	dir := t.TempDir()
+	base := writeFile(t, dir, "base.yaml", "name: base\ndebug: true\ndatabase:\n  host: db\n  max_conns: 20\n")
+	local := writeFile(t, dir, "local.json", `{"Database": {"Port": 7000}, "labels": {"team": "core"}}`)
+
+	t.Setenv("APP_DATABASE_MAX_CONNS", "50")
+	t.Setenv("APP_TAGS", "x, y")
+	t.Setenv("APP_CACHE_ENABLED", "true")
+	t.Setenv("APP_DATABASE_HOST", "env-db")
+
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.String("database.host", "", "database host")
+	fs.Int("database-max-conns", 0, "max connections")
+	fs.Bool("debug", false, "debug mode (not set)")
+	require.NoError(t, fs.Parse([]string{"-database.host=flag-db"}))
+
+	cfg, err := Load[appConfig](context.Background(),
+		WithDefaults(defaults),
+		WithFile(base),
+		WithFile(local),
+		WithOptionalFile(filepath.Join(dir, "missing.yaml")),
+		WithEnv("APP"),
+		WithFlags(fs),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, "base", cfg.Name)
+	assert.True(t, cfg.Debug, "unset flags must not override other layers")
+	assert.Equal(t, 7000, cfg.Database.Port)
+	assert.Equal(t, 50, cfg.Database.MaxConns)
+	assert.Equal(t, "flag-db", cfg.Database.Host)
+	assert.Equal(t, []string{"x", "y"}, cfg.Tags)
+	assert.Equal(t, map[string]string{"team": "core"}, cfg.Labels)
+	require.NotNil(t, cfg.Cache)
+	assert.True(t, cfg.Cache.Enabled)
+}
+
+type staticSource map[string]any
+
+func (s staticSource) Name() string { return "static" }
+
+func (s staticSource) Load(context.Context) (map[string]any, error) { return s, nil }
+
+func TestLoad_Errors(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	_, err := Load[appConfig](ctx, WithFile(filepath.Join(dir, "missing.yaml")))
+	assert.Error(t, err)
+
+	_, err = Load[appConfig](ctx, WithFile(writeFile(t, dir, "config.ini", "name=x")))
+	assert.ErrorContains(t, err, "unsupported config format")
+
+	_, err = Load[appConfig](ctx, WithFile(writeFile(t, dir, "broken.json", "{")))
+	assert.Error(t, err)
+
+	_, err = Load[appConfig](ctx, WithDefaults(defaults), WithSource(staticSource{"database": map[string]any{"port": "abc"}}))
+	assert.ErrorContains(t, err, "database.port")
+
+	_, err = Load[appConfig](ctx, WithDefaults(defaults), WithSource(staticSource{"database": map[string]any{"timeout": "soon"}}))
+	assert.ErrorContains(t, err, "invalid duration")
+
+	// The configuration is validated before being returned
+	_, err = Load[appConfig](ctx, WithDefaults(defaults), WithSource(staticSource{"name": ""}))
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+
+	cfg, err := Load[appConfig](ctx, WithSource(staticSource{"name": ""}), WithValidator(nil))
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Name)
+
+	_, err = Load[string](ctx)
+	assert.Error(t, err)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = Load[appConfig](canceled, WithSource(staticSource{}))
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestEnvName(t *testing.T) {
+	assert.Equal(t, "APP_DATABASE_MAX_CONNS", EnvName("APP", "database", "max_conns"))
+	assert.Equal(t, "HTTP_READ_TIMEOUT", EnvName("", "http", "read-timeout"))
+}
+
+func TestStore(t *testing.T) {
+	first := &appConfig{Name: "first"}
+	store := NewStore(first)
+	assert.Same(t, first, store.Get())
+
+	var calls []string
+	store.OnReload(func(old, new *appConfig) {
+		calls = append(calls, old.Name+"->"+new.Name)
+	})
+
+	snapshot := store.Get()
+	second := &appConfig{Name: "second"}
+	store.Set(second)
+
+	// Snapshots taken before the swap are not changed
+	assert.Equal(t, "first", snapshot.Name)
+	assert.Same(t, second, store.Get())
+	assert.Equal(t, []string{"first->second"}, calls)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_ = store.Get().Name
+		}()
+		go func() {
+			defer wg.Done()
+			store.Set(&appConfig{Name: "concurrent"})
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, calls, 11)
+}
+
+func TestLoad_DefaultsAreNotShared(t *testing.T) {
+	cfg, err := Load[appConfig](context.Background(), WithDefaults(&defaults))
+	require.NoError(t, err)
+
+	cfg.Database.Host = "changed"
+	assert.Equal(t, "localhost", defaults.Database.Host)
+}
+
+func TestLoad_Unmarshalers(t *testing.T) {
+	type limits struct {
+		MaxPayload bytesize.Size `json:"max_payload"`
+		CacheSize  bytesize.Size `json:"cache_size"`
+	}
+
+	dir := t.TempDir()
+	path := writeFile(t, dir, "limits.yaml", "max_payload: 4MiB\ncache_size: 1024\n")
+	t.Setenv("LIMITS_CACHE_SIZE", "512MB")
+
+	cfg, err := Load[limits](context.Background(), WithFile(path), WithEnv("LIMITS"))
+	require.NoError(t, err)
+	assert.Equal(t, 4*bytesize.MiB, cfg.MaxPayload)
+	assert.Equal(t, 512*bytesize.MB, cfg.CacheSize)
+
+	t.Setenv("LIMITS_CACHE_SIZE", "512 parsecs")
+	_, err = Load[limits](context.Background(), WithEnv("LIMITS"))
+	assert.ErrorIs(t, err, bytesize.ErrInvalidSize)
+}
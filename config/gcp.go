@@ -0,0 +1,99 @@
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// DefaultGCPSecretManagerEndpoint is the Secret Manager REST endpoint.
+const DefaultGCPSecretManagerEndpoint = "https://secretmanager.googleapis.com"
+
+// GCPConfig configures the Google Cloud Secret Manager resolver.
+type GCPConfig struct {
+	// Project used for short references. Defaults to GOOGLE_CLOUD_PROJECT.
+	Project string
+	// Token returns an OAuth2 access token, e.g. from golang.org/x/oauth2/google.
+	Token func(ctx context.Context) (string, error)
+	// Endpoint of the REST API. Defaults to DefaultGCPSecretManagerEndpoint.
+	Endpoint string
+	// HTTPClient used for requests. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// GCPResolver reads secrets from Google Cloud Secret Manager. References use
+// the secret name, e.g. secretref://gcp/db-password, or the full resource name
+// projects/<project>/secrets/<secret>/versions/<version>. The latest version
+// is used when none is given.
+type GCPResolver struct {
+	cfg GCPConfig
+}
+
+// NewGCPResolver creates a Secret Manager resolver.
+func NewGCPResolver(cfg GCPConfig) *GCPResolver {
+	if cfg.Project == "" {
+		cfg.Project = os.Getenv("GOOGLE_CLOUD_PROJECT")
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = DefaultGCPSecretManagerEndpoint
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+	return &GCPResolver{cfg: cfg}
+}
+
+// Scheme implements SecretResolver.
+func (g *GCPResolver) Scheme() string {
+	return "gcp"
+}
+
+// Resolve implements SecretResolver.
+func (g *GCPResolver) Resolve(ctx context.Context, path string) (string, error) {
+	name := strings.TrimPrefix(path, "/")
+	if !strings.HasPrefix(name, "projects/") {
+		if g.cfg.Project == "" {
+			return "", fmt.Errorf("gcp: project is required for secret %q", name)
+		}
+		name = "projects/" + g.cfg.Project + "/secrets/" + name
+	}
+	if !strings.Contains(name, "/versions/") {
+		name += "/versions/latest"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.cfg.Endpoint+"/v1/"+name+":access", nil)
+	if err != nil {
+		return "", err
+	}
+	if g.cfg.Token != nil {
+		token, err := g.cfg.Token(ctx)
+		if err != nil {
+			return "", fmt.Errorf("gcp: access token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	body, err := doSecretRequest(g.cfg.HTTPClient, req)
+	if err != nil {
+		return "", err
+	}
+
+	var response struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("gcp: decode response: %w", err)
+	}
+	data, err := base64.StdEncoding.DecodeString(response.Payload.Data)
+	if err != nil {
+		return "", fmt.Errorf("gcp: decode payload: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,304 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SecretRefPrefix prefixes config values resolved by a SecretResolver:
+// secretref://<provider>/<path>[#<key>].
+const SecretRefPrefix = "secretref://"
+
+var (
+	// ErrSecretNotFound indicates a secret or key that does not exist.
+	ErrSecretNotFound = errors.New("config: secret not found")
+	// ErrUnknownSecretProvider indicates a reference to an unregistered provider.
+	ErrUnknownSecretProvider = errors.New("config: unknown secret provider")
+	// ErrInvalidSecretRef indicates a malformed secret reference.
+	ErrInvalidSecretRef = errors.New("config: invalid secret reference")
+)
+
+// SecretResolver fetches secrets from a provider.
+type SecretResolver interface {
+	// Scheme is the provider name used in references (e.g. "vault").
+	Scheme() string
+	// Resolve returns the secret value at the path. Structured secrets are
+	// returned as JSON objects, from which references select a key.
+	Resolve(ctx context.Context, path string) (string, error)
+}
+
+// SecretRef is a parsed secret reference.
+type SecretRef struct {
+	Provider string
+	Path     string
+	Key      string
+}
+
+// String returns the reference in the secretref:// format.
+func (r SecretRef) String() string {
+	s := SecretRefPrefix + r.Provider + "/" + r.Path
+	if r.Key != "" {
+		s += "#" + r.Key
+	}
+	return s
+}
+
+// IsSecretRef reports whether the value is a secret reference.
+func IsSecretRef(value string) bool {
+	return strings.HasPrefix(value, SecretRefPrefix)
+}
+
+// ParseSecretRef parses a reference such as secretref://vault/secret/data/db#password.
+func ParseSecretRef(value string) (SecretRef, error) {
+	rest, ok := strings.CutPrefix(value, SecretRefPrefix)
+	if !ok {
+		return SecretRef{}, fmt.Errorf("%w: missing %s prefix", ErrInvalidSecretRef, SecretRefPrefix)
+	}
+	rest, key, _ := strings.Cut(rest, "#")
+	provider, path, _ := strings.Cut(rest, "/")
+	if provider == "" || path == "" {
+		return SecretRef{}, fmt.Errorf("%w: expected %s<provider>/<path>[#key]", ErrInvalidSecretRef, SecretRefPrefix)
+	}
+	return SecretRef{Provider: provider, Path: path, Key: key}, nil
+}
+
+// SecretsOption configures Secrets.
+type SecretsOption func(*Secrets)
+
+// WithResolver registers a secret resolver under its scheme.
+func WithResolver(resolver SecretResolver) SecretsOption {
+	return func(s *Secrets) {
+		s.resolvers[resolver.Scheme()] = resolver
+	}
+}
+
+// WithSecretTTL sets how long fetched secrets are cached. Expired secrets are
+// fetched again on the next resolution. Zero (default) caches until Refresh.
+func WithSecretTTL(ttl time.Duration) SecretsOption {
+	return func(s *Secrets) {
+		s.ttl = ttl
+	}
+}
+
+// WithSecretErrorHandler sets the handler of refresh errors in Watch.
+func WithSecretErrorHandler(fn func(error)) SecretsOption {
+	return func(s *Secrets) {
+		s.onError = fn
+	}
+}
+
+// Secrets resolves secret references using the registered providers, caching
+// the fetched values and notifying rotations.
+type Secrets struct {
+	resolvers map[string]SecretResolver
+	ttl       time.Duration
+	onError   func(error)
+	now       func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cachedSecret
+	hooks []func(ref string)
+}
+
+// cachedSecret is a fetched secret value.
+type cachedSecret struct {
+	value     string
+	fetchedAt time.Time
+}
+
+// NewSecrets creates a secret registry. The file provider is always available.
+func NewSecrets(opts ...SecretsOption) *Secrets {
+	s := &Secrets{
+		resolvers: map[string]SecretResolver{"file": FileResolver{}},
+		now:       time.Now,
+		cache:     make(map[string]cachedSecret),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// OnRotate registers a hook invoked when a cached secret changes, with the
+// reference (without key) of the rotated secret. It is usually used to reload
+// the configuration, e.g. calling Watcher.Reload.
+func (s *Secrets) OnRotate(fn func(ref string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks = append(s.hooks, fn)
+}
+
+// Resolve resolves a secret reference, using the cache when possible.
+func (s *Secrets) Resolve(ctx context.Context, value string) (string, error) {
+	ref, err := ParseSecretRef(value)
+	if err != nil {
+		return "", err
+	}
+
+	id := SecretRef{Provider: ref.Provider, Path: ref.Path}.String()
+	s.mu.Lock()
+	cached, ok := s.cache[id]
+	s.mu.Unlock()
+
+	secret := cached.value
+	if !ok || (s.ttl > 0 && s.now().Sub(cached.fetchedAt) >= s.ttl) {
+		if secret, err = s.fetch(ctx, ref, id); err != nil {
+			return "", err
+		}
+	}
+	return selectKey(secret, ref.Key)
+}
+
+// Refresh fetches every cached secret again, invoking the rotation hooks for
+// the ones that changed. Secrets that fail to refresh keep the cached value.
+func (s *Secrets) Refresh(ctx context.Context) error {
+	s.mu.Lock()
+	ids := make([]string, 0, len(s.cache))
+	for id := range s.cache {
+		ids = append(ids, id)
+	}
+	s.mu.Unlock()
+
+	var errs []error
+	for _, id := range ids {
+		ref, _ := ParseSecretRef(id)
+		if _, err := s.fetch(ctx, ref, id); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Watch refreshes the cached secrets at the given interval until the context
+// is canceled, reporting refresh errors to the error handler.
+func (s *Secrets) Watch(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := s.Refresh(ctx); err != nil && s.onError != nil {
+				s.onError(err)
+			}
+		}
+	}
+}
+
+// fetch reads the secret from the provider, updates the cache and notifies rotations.
+func (s *Secrets) fetch(ctx context.Context, ref SecretRef, id string) (string, error) {
+	resolver, ok := s.resolvers[ref.Provider]
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrUnknownSecretProvider, ref.Provider)
+	}
+
+	value, err := resolver.Resolve(ctx, ref.Path)
+	if err != nil {
+		return "", fmt.Errorf("config: resolve %s: %w", id, err)
+	}
+
+	s.mu.Lock()
+	previous, existed := s.cache[id]
+	s.cache[id] = cachedSecret{value: value, fetchedAt: s.now()}
+	var hooks []func(string)
+	if existed && previous.value != value {
+		hooks = append(hooks, s.hooks...)
+	}
+	s.mu.Unlock()
+
+	for _, hook := range hooks {
+		hook(id)
+	}
+	return value, nil
+}
+
+// selectKey returns the key of a JSON object secret, or the whole secret
+// when no key is given.
+func selectKey(secret, key string) (string, error) {
+	if key == "" {
+		return secret, nil
+	}
+
+	var object map[string]any
+	if err := json.Unmarshal([]byte(secret), &object); err != nil {
+		return "", fmt.Errorf("%w: secret is not a JSON object, cannot select key %q", ErrSecretNotFound, key)
+	}
+	value, ok := object[key]
+	if !ok {
+		return "", fmt.Errorf("%w: key %q", ErrSecretNotFound, key)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// resolveSecrets returns a copy of the layer values with the secret
+// references replaced by the secrets.
+func resolveSecrets(ctx context.Context, secrets *Secrets, value any, path string) (any, error) {
+	switch v := value.(type) {
+	case string:
+		if !IsSecretRef(v) {
+			return v, nil
+		}
+		resolved, err := secrets.Resolve(ctx, v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", pathName(path), err)
+		}
+		return resolved, nil
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, item := range v {
+			resolved, err := resolveSecrets(ctx, secrets, item, joinPath(path, key))
+			if err != nil {
+				return nil, err
+			}
+			result[key] = resolved
+		}
+		return result, nil
+	case []any:
+		result := make([]any, len(v))
+		for i, item := range v {
+			resolved, err := resolveSecrets(ctx, secrets, item, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			result[i] = resolved
+		}
+		return result, nil
+	}
+	return value, nil
+}
+
+// FileResolver reads secrets from files, such as Docker and Kubernetes
+// mounted secrets: secretref://file/run/secrets/db_password. Paths are absolute.
+type FileResolver struct{}
+
+// Scheme implements SecretResolver.
+func (FileResolver) Scheme() string {
+	return "file"
+}
+
+// Resolve implements SecretResolver.
+func (FileResolver) Resolve(_ context.Context, path string) (string, error) {
+	data, err := os.ReadFile("/" + strings.TrimPrefix(path, "/"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("%w: %v", ErrSecretNotFound, err)
+		}
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
@@ -0,0 +1,292 @@
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mapResolver serves secrets from a map.
+type mapResolver struct {
+	mu      sync.Mutex
+	secrets map[string]string
+	calls   int
+}
+
+func (m *mapResolver) Scheme() string { return "mem" }
+
+func (m *mapResolver) Resolve(_ context.Context, path string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls++
+	value, ok := m.secrets[path]
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+	return value, nil
+}
+
+func (m *mapResolver) set(path, value string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.secrets[path] = value
+}
+
+func TestParseSecretRef(t *testing.T) {
+	ref, err := ParseSecretRef("secretref://vault/secret/data/db#password")
+	require.NoError(t, err)
+	assert.Equal(t, SecretRef{Provider: "vault", Path: "secret/data/db", Key: "password"}, ref)
+	assert.Equal(t, "secretref://vault/secret/data/db#password", ref.String())
+
+	for _, invalid := range []string{"vault/secret", "secretref://vault", "secretref:///path"} {
+		_, err := ParseSecretRef(invalid)
+		assert.ErrorIs(t, err, ErrInvalidSecretRef, invalid)
+	}
+}
+
+func TestSecrets_Resolve(t *testing.T) {
+	resolver := &mapResolver{secrets: map[string]string{
+		"db":    `{"user":"app","password":"s3cret","port":5433}`,
+		"token": "abc",
+	}}
+	secrets := NewSecrets(WithResolver(resolver))
+	ctx := context.Background()
+
+	value, err := secrets.Resolve(ctx, "secretref://mem/db#password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", value)
+
+	value, err = secrets.Resolve(ctx, "secretref://mem/db#port")
+	require.NoError(t, err)
+	assert.Equal(t, "5433", value)
+
+	value, err = secrets.Resolve(ctx, "secretref://mem/token")
+	require.NoError(t, err)
+	assert.Equal(t, "abc", value)
+
+	assert.Equal(t, 2, resolver.calls, "secrets are cached per path")
+
+	_, err = secrets.Resolve(ctx, "secretref://mem/db#missing")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+	_, err = secrets.Resolve(ctx, "secretref://mem/token#key")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+	_, err = secrets.Resolve(ctx, "secretref://mem/unknown")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+	_, err = secrets.Resolve(ctx, "secretref://other/path")
+	assert.ErrorIs(t, err, ErrUnknownSecretProvider)
+}
+
+func TestSecrets_TTLAndRotation(t *testing.T) {
+	resolver := &mapResolver{secrets: map[string]string{"token": "v1"}}
+	now := time.Now()
+	secrets := NewSecrets(WithResolver(resolver), WithSecretTTL(time.Minute))
+	secrets.now = func() time.Time { return now }
+
+	var rotated []string
+	secrets.OnRotate(func(ref string) { rotated = append(rotated, ref) })
+
+	ctx := context.Background()
+	value, err := secrets.Resolve(ctx, "secretref://mem/token")
+	require.NoError(t, err)
+	assert.Equal(t, "v1", value)
+
+	resolver.set("token", "v2")
+	value, err = secrets.Resolve(ctx, "secretref://mem/token")
+	require.NoError(t, err)
+	assert.Equal(t, "v1", value, "cached until the TTL expires")
+
+	now = now.Add(time.Minute)
+	value, err = secrets.Resolve(ctx, "secretref://mem/token")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", value)
+	assert.Equal(t, []string{"secretref://mem/token"}, rotated)
+
+	resolver.set("token", "v3")
+	require.NoError(t, secrets.Refresh(ctx))
+	assert.Len(t, rotated, 2)
+
+	require.NoError(t, secrets.Refresh(ctx))
+	assert.Len(t, rotated, 2, "unchanged secrets do not rotate")
+}
+
+func TestLoad_WithSecrets(t *testing.T) {
+	resolver := &mapResolver{secrets: map[string]string{
+		"db": `{"host":"db.internal","port":"6432"}`,
+	}}
+	dir := t.TempDir()
+	path := writeFile(t, dir, "config.yaml", `
+name: app
+database:
+  host: secretref://mem/db#host
+  port: secretref://mem/db#port
+tags: [a, secretref://mem/db#host]
+`)
+
+	cfg, err := Load[appConfig](context.Background(),
+		WithFile(path),
+		WithSecrets(NewSecrets(WithResolver(resolver))),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "db.internal", cfg.Database.Host)
+	assert.Equal(t, 6432, cfg.Database.Port)
+	assert.Equal(t, []string{"a", "db.internal"}, cfg.Tags)
+
+	_, err = Load[appConfig](context.Background(), WithFile(path))
+	assert.Error(t, err, "references are not resolved without secrets")
+}
+
+func TestFileResolver(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "password", "s3cret\n")
+
+	value, err := NewSecrets().Resolve(context.Background(), "secretref://file"+path)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", value)
+
+	_, err = NewSecrets().Resolve(context.Background(), "secretref://file"+filepath.Join(dir, "missing"))
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+}
+
+func TestVaultResolver(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "token", r.Header.Get("X-Vault-Token"))
+		switch r.URL.Path {
+		case "/v1/secret/data/db":
+			_, _ = w.Write([]byte(`{"data":{"data":{"password":"s3cret"},"metadata":{"version":3}}}`))
+		case "/v1/kv/db":
+			_, _ = w.Write([]byte(`{"data":{"password":"v1"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	secrets := NewSecrets(WithResolver(NewVaultResolver(VaultConfig{Address: server.URL, Token: "token"})))
+	ctx := context.Background()
+
+	value, err := secrets.Resolve(ctx, "secretref://vault/secret/data/db#password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", value)
+
+	value, err = secrets.Resolve(ctx, "secretref://vault/kv/db#password")
+	require.NoError(t, err)
+	assert.Equal(t, "v1", value)
+
+	_, err = secrets.Resolve(ctx, "secretref://vault/secret/data/missing#password")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+}
+
+func TestAWSResolver(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secretsmanager.GetSecretValue", r.Header.Get("X-Amz-Target"))
+		assert.Equal(t, "session", r.Header.Get("X-Amz-Security-Token"))
+		auth := r.Header.Get("Authorization")
+		assert.True(t, strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/"), auth)
+		assert.Contains(t, auth, "/us-east-1/secretsmanager/aws4_request")
+
+		var request struct{ SecretId string }
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		switch request.SecretId {
+		case "prod/db":
+			_, _ = w.Write([]byte(`{"SecretString":"{\"password\":\"s3cret\"}"}`))
+		case "prod/cert":
+			_, _ = w.Write([]byte(`{"SecretBinary":"` + base64.StdEncoding.EncodeToString([]byte("binary")) + `"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"ResourceNotFoundException","message":"not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	resolver := NewAWSResolver(AWSConfig{
+		Region:          "us-east-1",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+		SessionToken:    "session",
+		Endpoint:        server.URL,
+	})
+	secrets := NewSecrets(WithResolver(resolver))
+	ctx := context.Background()
+
+	value, err := secrets.Resolve(ctx, "secretref://aws/prod/db#password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", value)
+
+	value, err = secrets.Resolve(ctx, "secretref://aws/prod/cert")
+	require.NoError(t, err)
+	assert.Equal(t, "binary", value)
+
+	_, err = secrets.Resolve(ctx, "secretref://aws/prod/missing")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+}
+
+func TestAWSResolver_Signature(t *testing.T) {
+	// The signature is deterministic for a fixed time and credentials
+	resolver := NewAWSResolver(AWSConfig{
+		Region:          "us-east-1",
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		Endpoint:        "https://secretsmanager.us-east-1.amazonaws.com",
+	})
+	resolver.now = func() time.Time { return time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC) }
+
+	sign := func() string {
+		req, err := http.NewRequest(http.MethodPost, resolver.cfg.Endpoint+"/", nil)
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+		req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+		resolver.sign(req, []byte(`{"SecretId":"prod/db"}`))
+		assert.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+		return req.Header.Get("Authorization")
+	}
+
+	auth := sign()
+	assert.Contains(t, auth, "Credential=AKIDEXAMPLE/20150830/us-east-1/secretsmanager/aws4_request")
+	assert.Contains(t, auth, "SignedHeaders=content-type;host;x-amz-date;x-amz-target")
+	assert.Regexp(t, `Signature=[0-9a-f]{64}$`, auth)
+	assert.Equal(t, auth, sign())
+}
+
+func TestGCPResolver(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/v1/projects/demo/secrets/db-password/versions/latest:access":
+			_, _ = w.Write([]byte(`{"payload":{"data":"` + base64.StdEncoding.EncodeToString([]byte("s3cret")) + `"}}`))
+		case "/v1/projects/other/secrets/api/versions/2:access":
+			_, _ = w.Write([]byte(`{"payload":{"data":"` + base64.StdEncoding.EncodeToString([]byte(`{"key":"v2"}`)) + `"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	resolver := NewGCPResolver(GCPConfig{
+		Project:  "demo",
+		Endpoint: server.URL,
+		Token:    func(context.Context) (string, error) { return "token", nil },
+	})
+	secrets := NewSecrets(WithResolver(resolver))
+	ctx := context.Background()
+
+	value, err := secrets.Resolve(ctx, "secretref://gcp/db-password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", value)
+
+	value, err = secrets.Resolve(ctx, "secretref://gcp/projects/other/secrets/api/versions/2#key")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", value)
+
+	_, err = secrets.Resolve(ctx, "secretref://gcp/missing")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+}
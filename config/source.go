@@ -0,0 +1,393 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Source provides a configuration layer as nested maps keyed by the JSON
+// field names of the config type. String values are converted to the field types.
+type Source interface {
+	// Name identifies the source in error messages.
+	Name() string
+	// Load returns the layer values, or nil when the source has nothing to add.
+	Load(ctx context.Context) (map[string]any, error)
+}
+
+// fileSource reads YAML, JSON or TOML files.
+type fileSource struct {
+	path     string
+	optional bool
+}
+
+// Name implements Source.
+func (f *fileSource) Name() string {
+	return f.path
+}
+
+// Load implements Source.
+func (f *fileSource) Load(_ context.Context) (map[string]any, error) {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		if f.optional && errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("config: read %s: %w", f.path, err)
+	}
+
+	values, err := Decode(filepath.Ext(f.path), data)
+	if err != nil {
+		return nil, fmt.Errorf("config: %s: %w", f.path, err)
+	}
+	return values, nil
+}
+
+// Decode parses YAML, JSON or TOML content into nested maps. The format is
+// given by a file extension (".yaml", ".yml", ".json" or ".toml").
+func Decode(ext string, data []byte) (map[string]any, error) {
+	values := make(map[string]any)
+
+	var err error
+	switch strings.ToLower(ext) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		err = decoder.Decode(&values)
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	default:
+		return nil, fmt.Errorf("unsupported config format %q", ext)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// toMap converts a value to nested maps through its JSON representation.
+func toMap(v any) (map[string]any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]any)
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// mergeMaps merges src into dst; nested maps are merged and other values replaced.
+func mergeMaps(dst, src map[string]any) {
+	for key, value := range src {
+		nested, ok := value.(map[string]any)
+		if existing, isMap := dst[key].(map[string]any); ok && isMap {
+			mergeMaps(existing, nested)
+			continue
+		}
+		dst[key] = value
+	}
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	timeType            = reflect.TypeOf(time.Time{})
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// field is a struct field addressed by its JSON name.
+type field struct {
+	name string
+	typ  reflect.Type
+}
+
+// fields returns the exported fields of the struct by JSON name, flattening
+// embedded structs as encoding/json does.
+func fields(t reflect.Type) []field {
+	var result []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		ft := sf.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			result = append(result, fields(ft)...)
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		result = append(result, field{name: name, typ: sf.Type})
+	}
+	return result
+}
+
+// isNested reports whether the type is a struct navigated field by field.
+func isNested(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != timeType
+}
+
+// coerce normalizes the keys of a layer to the JSON field names and converts
+// string values to the field types (numbers, booleans, durations, lists).
+func coerce(t reflect.Type, value any, path string) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case isNested(t):
+		m, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s: expected an object, got %T", pathName(path), value)
+		}
+		result := make(map[string]any, len(m))
+		for key, v := range m {
+			result[key] = v
+		}
+		for _, f := range fields(t) {
+			key, v, found := lookupKey(m, f.name)
+			if !found {
+				continue
+			}
+			converted, err := coerce(f.typ, v, joinPath(path, f.name))
+			if err != nil {
+				return nil, err
+			}
+			delete(result, key)
+			result[f.name] = converted
+		}
+		return result, nil
+
+	case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String:
+		m, ok := value.(map[string]any)
+		if !ok {
+			return value, nil
+		}
+		result := make(map[string]any, len(m))
+		for key, v := range m {
+			converted, err := coerce(t.Elem(), v, joinPath(path, key))
+			if err != nil {
+				return nil, err
+			}
+			result[key] = converted
+		}
+		return result, nil
+
+	case t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8:
+		var items []any
+		switch v := value.(type) {
+		case string:
+			for _, item := range strings.Split(v, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+		case []any:
+			items = v
+		default:
+			return value, nil
+		}
+		result := make([]any, len(items))
+		for i, item := range items {
+			converted, err := coerce(t.Elem(), item, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			result[i] = converted
+		}
+		return result, nil
+	}
+
+	return coerceScalar(t, value, path)
+}
+
+// coerceScalar converts a scalar value to the JSON representation of the type.
+func coerceScalar(t reflect.Type, value any, path string) (any, error) {
+	s, isString := value.(string)
+
+	if t == durationType {
+		if isString {
+			d, err := time.ParseDuration(strings.TrimSpace(s))
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid duration %q", pathName(path), s)
+			}
+			return int64(d), nil
+		}
+		return value, nil
+	}
+
+	// Types such as bytesize.Size parse their own strings
+	if isString && (reflect.PointerTo(t).Implements(jsonUnmarshalerType) ||
+		reflect.PointerTo(t).Implements(textUnmarshalerType)) {
+		return value, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		if !isString && value != nil {
+			return fmt.Sprint(value), nil
+		}
+	case reflect.Bool:
+		if isString {
+			b, err := strconv.ParseBool(strings.TrimSpace(s))
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid boolean %q", pathName(path), s)
+			}
+			return b, nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if isString {
+			n := json.Number(strings.TrimSpace(s))
+			if _, err := n.Float64(); err != nil {
+				return nil, fmt.Errorf("%s: invalid number %q", pathName(path), s)
+			}
+			return n, nil
+		}
+	}
+	return value, nil
+}
+
+// lookupKey finds the key by exact name or, as encoding/json does, ignoring case.
+func lookupKey(m map[string]any, name string) (string, any, bool) {
+	if v, ok := m[name]; ok {
+		return name, v, true
+	}
+	for key, v := range m {
+		if strings.EqualFold(key, name) {
+			return key, v, true
+		}
+	}
+	return "", nil, false
+}
+
+// joinPath joins field path segments with dots.
+func joinPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// pathName returns a printable path.
+func pathName(path string) string {
+	if path == "" {
+		return "root"
+	}
+	return path
+}
+
+// leaves visits the leaf fields of the config type with their paths.
+func leaves(t reflect.Type, path []string, visit func(path []string)) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	for _, f := range fields(t) {
+		fieldPath := append(append([]string(nil), path...), f.name)
+		ft := f.typ
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if isNested(ft) {
+			leaves(ft, fieldPath, visit)
+			continue
+		}
+		visit(fieldPath)
+	}
+}
+
+// setPath stores the value at the nested path.
+func setPath(m map[string]any, path []string, value any) {
+	for _, key := range path[:len(path)-1] {
+		nested, ok := m[key].(map[string]any)
+		if !ok {
+			nested = make(map[string]any)
+			m[key] = nested
+		}
+		m = nested
+	}
+	m[path[len(path)-1]] = value
+}
+
+// EnvName returns the environment variable name of a field path, e.g.
+// APP_DATABASE_MAX_CONNS for prefix "APP" and path [database max_conns].
+func EnvName(prefix string, path ...string) string {
+	name := strings.Join(path, "_")
+	if prefix != "" {
+		name = prefix + "_" + name
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+// envValues reads the environment variables of the leaf fields.
+func envValues(t reflect.Type, prefix string) map[string]any {
+	values := make(map[string]any)
+	leaves(t, nil, func(path []string) {
+		if value, ok := os.LookupEnv(EnvName(prefix, path...)); ok {
+			setPath(values, path, value)
+		}
+	})
+	return values
+}
+
+// flagValues reads the flags explicitly set for the leaf fields.
+func flagValues(t reflect.Type, fs *flag.FlagSet) map[string]any {
+	set := make(map[string]string)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = f.Value.String()
+	})
+
+	values := make(map[string]any)
+	leaves(t, nil, func(path []string) {
+		dotted := strings.Join(path, ".")
+		dashed := strings.ReplaceAll(strings.Join(path, "-"), "_", "-")
+		if value, ok := set[dotted]; ok {
+			setPath(values, path, value)
+		} else if value, ok := set[dashed]; ok {
+			setPath(values, path, value)
+		}
+	})
+	return values
+}
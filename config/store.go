@@ -0,0 +1,47 @@
+package config
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Store holds the current configuration. Readers get immutable snapshots: a
+// reload replaces the pointer atomically, so a snapshot obtained with Get is
+// never changed and stays consistent while in use. Snapshots must not be modified.
+type Store[T any] struct {
+	current   atomic.Pointer[T]
+	mu        sync.Mutex
+	callbacks []func(old, new *T)
+}
+
+// NewStore creates a store with the initial configuration.
+func NewStore[T any](initial *T) *Store[T] {
+	s := &Store[T]{}
+	s.current.Store(initial)
+	return s
+}
+
+// Get returns the current snapshot.
+func (s *Store[T]) Get() *T {
+	return s.current.Load()
+}
+
+// OnReload registers a callback invoked after each configuration change, with
+// the previous and the new snapshots.
+func (s *Store[T]) OnReload(fn func(old, new *T)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.callbacks = append(s.callbacks, fn)
+}
+
+// Set replaces the configuration and invokes the reload callbacks in the
+// order they were registered.
+func (s *Store[T]) Set(cfg *T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old := s.current.Swap(cfg)
+	for _, fn := range s.callbacks {
+		fn(old, cfg)
+	}
+}
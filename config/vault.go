@@ -0,0 +1,115 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// maxSecretResponse limits the size of provider responses.
+const maxSecretResponse = 1 << 20
+
+// VaultConfig configures the HashiCorp Vault resolver.
+type VaultConfig struct {
+	// Address of the Vault server. Defaults to VAULT_ADDR.
+	Address string
+	// Token used for authentication. Defaults to VAULT_TOKEN.
+	Token string
+	// Namespace for Vault Enterprise. Defaults to VAULT_NAMESPACE.
+	Namespace string
+	// HTTPClient used for requests. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// VaultResolver reads secrets from the Vault HTTP API. The reference path is
+// the API path, e.g. secretref://vault/secret/data/app#password for the KV v2
+// engine mounted at secret/. KV v1 and v2 responses are supported.
+type VaultResolver struct {
+	cfg VaultConfig
+}
+
+// NewVaultResolver creates a Vault resolver.
+func NewVaultResolver(cfg VaultConfig) *VaultResolver {
+	if cfg.Address == "" {
+		cfg.Address = os.Getenv("VAULT_ADDR")
+	}
+	if cfg.Token == "" {
+		cfg.Token = os.Getenv("VAULT_TOKEN")
+	}
+	if cfg.Namespace == "" {
+		cfg.Namespace = os.Getenv("VAULT_NAMESPACE")
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+	cfg.Address = strings.TrimRight(cfg.Address, "/")
+	return &VaultResolver{cfg: cfg}
+}
+
+// Scheme implements SecretResolver.
+func (v *VaultResolver) Scheme() string {
+	return "vault"
+}
+
+// Resolve implements SecretResolver, returning the secret data as a JSON object.
+func (v *VaultResolver) Resolve(ctx context.Context, path string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.cfg.Address+"/v1/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", v.cfg.Token)
+	if v.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.cfg.Namespace)
+	}
+
+	body, err := doSecretRequest(v.cfg.HTTPClient, req)
+	if err != nil {
+		return "", err
+	}
+
+	var response struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("vault: decode response: %w", err)
+	}
+
+	// KV v2 wraps the secret in data.data, alongside data.metadata
+	data, hasData := response.Data["data"]
+	if _, hasMetadata := response.Data["metadata"]; hasData && hasMetadata {
+		return string(data), nil
+	}
+
+	encoded, err := json.Marshal(response.Data)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// doSecretRequest sends the request and returns the body of successful responses.
+func doSecretRequest(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSecretResponse))
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrSecretNotFound
+	case resp.StatusCode >= 300:
+		// The body is not included as it may echo sensitive data
+		return nil, fmt.Errorf("unexpected status %d from %s", resp.StatusCode, req.URL.Host)
+	}
+	return body, nil
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// DefaultWatchInterval is the default interval between file checks.
+const DefaultWatchInterval = time.Second
+
+// WatchOption configures a Watcher.
+type WatchOption func(*watchOptions)
+
+type watchOptions struct {
+	interval time.Duration
+	onError  func(error)
+}
+
+// WithInterval sets the interval between file checks.
+func WithInterval(interval time.Duration) WatchOption {
+	return func(o *watchOptions) {
+		if interval > 0 {
+			o.interval = interval
+		}
+	}
+}
+
+// WithErrorHandler sets the handler of reload errors. The current
+// configuration is kept when a reload fails.
+func WithErrorHandler(fn func(error)) WatchOption {
+	return func(o *watchOptions) {
+		o.onError = fn
+	}
+}
+
+// Watcher reloads the configuration when the loader files change. Changes are
+// detected by polling the modification time and size of the files; a new
+// configuration is only applied to the store after it loads and validates.
+type Watcher[T any] struct {
+	loader *Loader[T]
+	store  *Store[T]
+	opts   watchOptions
+	stamps map[string]fileStamp
+}
+
+// fileStamp identifies a version of a file.
+type fileStamp struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+// NewWatcher creates a watcher that reloads the store using the loader.
+func NewWatcher[T any](loader *Loader[T], store *Store[T], opts ...WatchOption) *Watcher[T] {
+	o := watchOptions{interval: DefaultWatchInterval}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	w := &Watcher[T]{loader: loader, store: store, opts: o}
+	w.stamps = w.snapshot()
+	return w
+}
+
+// Watch checks the files until the context is canceled. It is usually run in
+// its own goroutine and returns the context error.
+func (w *Watcher[T]) Watch(ctx context.Context) error {
+	ticker := time.NewTicker(w.opts.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if !w.changed() {
+				continue
+			}
+			if err := w.Reload(ctx); err != nil && w.opts.onError != nil {
+				w.opts.onError(err)
+			}
+		}
+	}
+}
+
+// Reload loads and validates the configuration and, on success, applies it to
+// the store, invoking the reload callbacks.
+func (w *Watcher[T]) Reload(ctx context.Context) error {
+	cfg, err := w.loader.Load(ctx)
+	if err != nil {
+		return err
+	}
+	w.store.Set(cfg)
+	return nil
+}
+
+// changed reports whether any file changed since the last check.
+func (w *Watcher[T]) changed() bool {
+	stamps := w.snapshot()
+	changed := false
+	for path, stamp := range stamps {
+		if w.stamps[path] != stamp {
+			changed = true
+			break
+		}
+	}
+	w.stamps = stamps
+	return changed
+}
+
+// snapshot stats the loader files.
+func (w *Watcher[T]) snapshot() map[string]fileStamp {
+	stamps := make(map[string]fileStamp)
+	for _, path := range w.loader.Files() {
+		info, err := os.Stat(path)
+		if err != nil {
+			stamps[path] = fileStamp{}
+			continue
+		}
+		stamps[path] = fileStamp{exists: true, size: info.Size(), modTime: info.ModTime()}
+	}
+	return stamps
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcher(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("name: v1\n"), 0o600))
+
+	loader := NewLoader[appConfig](WithDefaults(defaults), WithFile(path))
+	cfg, err := loader.Load(context.Background())
+	require.NoError(t, err)
+
+	store := NewStore(cfg)
+	reloaded := make(chan string, 10)
+	store.OnReload(func(old, new *appConfig) {
+		reloaded <- old.Name + "->" + new.Name
+	})
+
+	var mu sync.Mutex
+	var errs []error
+	watcher := NewWatcher(loader, store, WithInterval(10*time.Millisecond), WithErrorHandler(func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, err)
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- watcher.Watch(ctx) }()
+
+	writeVersion := func(content string, modTime time.Time) {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+
+	writeVersion("name: v2\n", time.Now().Add(time.Minute))
+	select {
+	case change := <-reloaded:
+		assert.Equal(t, "v1->v2", change)
+	case <-time.After(2 * time.Second):
+		t.Fatal("configuration was not reloaded")
+	}
+	assert.Equal(t, "v2", store.Get().Name)
+
+	// Invalid configurations are reported and not applied
+	writeVersion("name: \"\"\n", time.Now().Add(2*time.Minute))
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(errs) > 0
+	}, 2*time.Second, 10*time.Millisecond)
+	mu.Lock()
+	assert.ErrorIs(t, errs[0], ErrInvalidConfig)
+	mu.Unlock()
+	assert.Equal(t, "v2", store.Get().Name)
+	assert.Empty(t, reloaded)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
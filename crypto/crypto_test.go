@@ -0,0 +1,338 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingProvider counts the calls to the provider it wraps.
+type countingProvider struct {
+	KeyProvider
+	generated, decrypted atomic.Int32
+}
+
+func (p *countingProvider) GenerateDataKey(ctx context.Context) (DataKey, error) {
+	p.generated.Add(1)
+	return p.KeyProvider.GenerateDataKey(ctx)
+}
+
+func (p *countingProvider) DecryptDataKey(ctx context.Context, keyID string, ciphertext []byte) ([]byte, error) {
+	p.decrypted.Add(1)
+	return p.KeyProvider.DecryptDataKey(ctx, keyID, ciphertext)
+}
+
+func randomKey(t *testing.T) []byte {
+	t.Helper()
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	return key
+}
+
+func newLocal(t *testing.T, current string, keys map[string][]byte) *LocalKeyProvider {
+	t.Helper()
+	p, err := NewLocalKeyProvider(current, keys)
+	require.NoError(t, err)
+	return p
+}
+
+func newEnvelope(t *testing.T, provider KeyProvider, opts ...Option) *Envelope {
+	t.Helper()
+	e, err := New(context.Background(), provider, opts...)
+	require.NoError(t, err)
+	return e
+}
+
+func TestEnvelope_EncryptDecrypt(t *testing.T) {
+	ctx := context.Background()
+	e := newEnvelope(t, newLocal(t, "k1", map[string][]byte{"k1": randomKey(t)}))
+
+	a, err := e.Encrypt(ctx, []byte("4111 1111 1111 1111"), []byte("customer-1"))
+	require.NoError(t, err)
+	b, err := e.Encrypt(ctx, []byte("4111 1111 1111 1111"), []byte("customer-1"))
+	require.NoError(t, err)
+	assert.NotEqual(t, a, b)
+	assert.NotContains(t, string(a), "4111")
+
+	plaintext, err := e.Decrypt(ctx, a, []byte("customer-1"))
+	require.NoError(t, err)
+	assert.Equal(t, "4111 1111 1111 1111", string(plaintext))
+
+	_, err = e.Decrypt(ctx, a, []byte("customer-2"))
+	assert.ErrorIs(t, err, ErrDecrypt)
+	tampered := bytes.Clone(a)
+	tampered[len(tampered)-1] ^= 1
+	_, err = e.Decrypt(ctx, tampered, []byte("customer-1"))
+	assert.ErrorIs(t, err, ErrDecrypt)
+	_, err = e.Decrypt(ctx, []byte("plain text"), nil)
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+	_, err = e.Decrypt(ctx, a[:20], nil)
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+}
+
+func TestEnvelope_DataKeyRotation(t *testing.T) {
+	ctx := context.Background()
+	provider := &countingProvider{KeyProvider: newLocal(t, "k1", map[string][]byte{"k1": randomKey(t)})}
+	fake := clock.NewFake(time.Time{})
+	e := newEnvelope(t, provider, WithClock(fake), WithDataKeyRotation(time.Minute, 3))
+
+	var ciphertexts [][]byte
+	for range 4 {
+		c, err := e.Encrypt(ctx, []byte("value"), nil)
+		require.NoError(t, err)
+		ciphertexts = append(ciphertexts, c)
+	}
+	assert.Equal(t, int32(2), provider.generated.Load(), "a new data key after 3 uses")
+
+	fake.Advance(time.Minute)
+	c, err := e.Encrypt(ctx, []byte("value"), nil)
+	require.NoError(t, err)
+	ciphertexts = append(ciphertexts, c)
+	assert.Equal(t, int32(3), provider.generated.Load(), "a new data key after a minute")
+
+	// the data keys of this instance are cached, others are decrypted once
+	other := newEnvelope(t, provider)
+	for _, c := range ciphertexts {
+		plaintext, err := e.Decrypt(ctx, c, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "value", string(plaintext))
+		_, err = other.Decrypt(ctx, c, nil)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(3), provider.decrypted.Load())
+}
+
+func TestEnvelope_KeyEncryptionKeyRotation(t *testing.T) {
+	ctx := context.Background()
+	k1, k2 := randomKey(t), randomKey(t)
+	old := newEnvelope(t, newLocal(t, "k1", map[string][]byte{"k1": k1}))
+	encrypted, err := old.EncryptString(ctx, "secret")
+	require.NoError(t, err)
+
+	rotated := newEnvelope(t, newLocal(t, "k2", map[string][]byte{"k1": k1, "k2": k2}))
+	plaintext, err := rotated.DecryptString(ctx, encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", plaintext)
+
+	retired := newEnvelope(t, newLocal(t, "k2", map[string][]byte{"k2": k2}))
+	_, err = retired.DecryptString(ctx, encrypted)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestEnvelope_Deterministic(t *testing.T) {
+	ctx := context.Background()
+	provider := newLocal(t, "k1", map[string][]byte{"k1": randomKey(t)})
+	wrapped, err := GenerateWrappedKey(ctx, provider)
+	require.NoError(t, err)
+
+	// the key travels through the configuration as text
+	text, err := wrapped.MarshalText()
+	require.NoError(t, err)
+	var loaded WrappedKey
+	require.NoError(t, loaded.UnmarshalText(text))
+	assert.Equal(t, wrapped, loaded)
+	assert.Error(t, new(WrappedKey).UnmarshalText([]byte("bm90IGEga2V5")))
+
+	a := newEnvelope(t, provider, WithDeterministicKey(wrapped))
+	b := newEnvelope(t, provider, WithDeterministicKey(loaded))
+	x, err := a.EncryptDeterministicString(ctx, "ana@example.com")
+	require.NoError(t, err)
+	y, err := b.EncryptDeterministicString(ctx, "ana@example.com")
+	require.NoError(t, err)
+	z, err := b.EncryptDeterministicString(ctx, "bia@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, x, y)
+	assert.NotEqual(t, x, z)
+
+	// associated data separates equal values of different contexts
+	p, err := a.EncryptDeterministic(ctx, []byte("ana@example.com"), []byte("users.email"))
+	require.NoError(t, err)
+	q, err := a.EncryptDeterministic(ctx, []byte("ana@example.com"), []byte("leads.email"))
+	require.NoError(t, err)
+	assert.NotEqual(t, p, q)
+
+	plaintext, err := newEnvelope(t, provider).DecryptString(ctx, x)
+	require.NoError(t, err)
+	assert.Equal(t, "ana@example.com", plaintext)
+
+	_, err = newEnvelope(t, provider).EncryptDeterministicString(ctx, "ana@example.com")
+	assert.ErrorIs(t, err, ErrNoDeterministicKey)
+	_, err = New(ctx, newLocal(t, "k2", map[string][]byte{"k2": randomKey(t)}), WithDeterministicKey(wrapped))
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+type address struct {
+	Street string `encrypt:"random" json:"street"`
+	City   string `json:"city"`
+}
+
+type customer struct {
+	ID        string    `json:"id"`
+	Email     string    `encrypt:"deterministic" json:"email"`
+	Document  *string   `encrypt:"random" json:"document"`
+	Card      []byte    `encrypt:"random" json:"card"`
+	Note      string    `encrypt:"random" json:"note"`
+	Address   *address  `json:"address"`
+	Previous  []address `json:"previous"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newCustomer() customer {
+	document := "123.456.789-00"
+	return customer{
+		ID:        "c1",
+		Email:     "ana@example.com",
+		Document:  &document,
+		Card:      []byte("4111"),
+		Address:   &address{Street: "Rua A, 1", City: "Recife"},
+		Previous:  []address{{Street: "Rua B, 2", City: "Olinda"}},
+		CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestEnvelope_Fields(t *testing.T) {
+	ctx := context.Background()
+	provider := newLocal(t, "k1", map[string][]byte{"k1": randomKey(t)})
+	wrapped, err := GenerateWrappedKey(ctx, provider)
+	require.NoError(t, err)
+	e := newEnvelope(t, provider, WithDeterministicKey(wrapped))
+
+	c := newCustomer()
+	require.NoError(t, e.EncryptFields(ctx, &c))
+	assert.Equal(t, "c1", c.ID)
+	assert.Empty(t, c.Note, "empty values are kept")
+	assert.Equal(t, "Recife", c.Address.City)
+	for _, encrypted := range []string{c.Email, *c.Document, string(c.Card), c.Address.Street, c.Previous[0].Street} {
+		assert.NotContains(t, []string{"ana@example.com", "123.456.789-00", "4111", "Rua A, 1", "Rua B, 2"}, encrypted)
+	}
+	search, err := e.EncryptDeterministicString(ctx, "ana@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, search, c.Email, "searchable with the deterministic ciphertext")
+
+	other := newCustomer()
+	require.NoError(t, e.EncryptFields(ctx, &other))
+	rows := []customer{c, other}
+	require.NoError(t, e.DecryptFields(ctx, &rows))
+	for _, row := range rows {
+		assert.Equal(t, "ana@example.com", row.Email)
+		assert.Equal(t, "4111", string(row.Card))
+		assert.Equal(t, "Rua B, 2", row.Previous[0].Street)
+	}
+
+	assert.ErrorIs(t, e.EncryptFields(ctx, c), ErrNotPointer)
+	bad := struct {
+		Age int `encrypt:"random"`
+	}{Age: 30}
+	assert.ErrorContains(t, e.EncryptFields(ctx, &bad), "unsupported type int")
+	typo := struct {
+		Name string `encrypt:"deterministc"`
+	}{Name: "ana"}
+	assert.ErrorContains(t, e.EncryptFields(ctx, &typo), "invalid encrypt tag")
+}
+
+func TestFieldCodec(t *testing.T) {
+	ctx := context.Background()
+	provider := newLocal(t, "k1", map[string][]byte{"k1": randomKey(t)})
+	wrapped, err := GenerateWrappedKey(ctx, provider)
+	require.NoError(t, err)
+	codec := NewFieldCodec(newEnvelope(t, provider, WithDeterministicKey(wrapped)))
+
+	c := newCustomer()
+	encoded, err := codec.Marshal(ctx, &c)
+	require.NoError(t, err)
+	assert.NotContains(t, encoded, "ana@example.com")
+	assert.NotContains(t, encoded, "Rua A")
+	assert.Contains(t, encoded, "Recife")
+	assert.Equal(t, newCustomer(), c, "the value is not modified")
+
+	var decoded customer
+	require.NoError(t, codec.Unmarshal(ctx, encoded, &decoded))
+	assert.Equal(t, newCustomer(), decoded)
+
+	var raw map[string]any
+	require.NoError(t, json.Unmarshal([]byte(encoded), &raw))
+	assert.Equal(t, "c1", raw["id"])
+	assert.ErrorIs(t, codec.Unmarshal(ctx, encoded, decoded), ErrNotPointer)
+}
+
+func TestEnvelope_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	e := newEnvelope(t, newLocal(t, "k1", map[string][]byte{"k1": randomKey(t)}), WithDataKeyRotation(0, 10))
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				c, err := e.EncryptString(ctx, "value")
+				assert.NoError(t, err)
+				p, err := e.DecryptString(ctx, c)
+				assert.NoError(t, err)
+				assert.Equal(t, "value", p)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestNewLocalKeyProvider_Errors(t *testing.T) {
+	_, err := NewLocalKeyProvider("k2", map[string][]byte{"k1": randomKey(t)})
+	assert.Error(t, err)
+	_, err = NewLocalKeyProvider("k1", map[string][]byte{"k1": []byte("short")})
+	assert.Error(t, err)
+}
+
+func TestVaultTransit(t *testing.T) {
+	dataKey := randomKey(t)
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "token", r.Header.Get("X-Vault-Token"))
+		paths = append(paths, r.URL.Path)
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		switch {
+		case r.URL.Path == "/v1/transit/datakey/plaintext/app":
+			assert.Equal(t, float64(256), body["bits"])
+			json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{
+				"plaintext":  base64.StdEncoding.EncodeToString(dataKey),
+				"ciphertext": "vault:v1:wrapped",
+			}})
+		case r.URL.Path == "/v1/transit/decrypt/app" && body["ciphertext"] == "vault:v1:wrapped":
+			json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{
+				"plaintext": base64.StdEncoding.EncodeToString(dataKey),
+			}})
+		case strings.HasPrefix(r.URL.Path, "/v1/transit/decrypt/"):
+			http.NotFound(w, r)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	provider := NewVaultTransit(VaultTransitConfig{Address: server.URL + "/", Token: "token", Key: "app"})
+	encrypted, err := newEnvelope(t, provider).EncryptString(ctx, "secret")
+	require.NoError(t, err)
+	plaintext, err := newEnvelope(t, provider).DecryptString(ctx, encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", plaintext)
+	assert.Equal(t, []string{"/v1/transit/datakey/plaintext/app", "/v1/transit/decrypt/app"}, paths)
+
+	_, err = provider.DecryptDataKey(ctx, "other", []byte("vault:v1:wrapped"))
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
@@ -0,0 +1,366 @@
+// Package crypto encrypts application data with envelope encryption: data is
+// encrypted with AES-256-GCM by data keys, which are encrypted by key
+// encryption keys held by a pluggable KeyProvider (a KMS or a local keyring)
+// and stored inside each ciphertext. Deterministic encryption, which gives
+// the same ciphertext for the same plaintext, keeps fields searchable by
+// equality. EncryptFields, DecryptFields and FieldCodec encrypt the tagged
+// fields of structs; the cache and db packages do not call them, so the
+// application encrypts values before writing them and decrypts them after
+// reading.
+package crypto
+
+import (
+	"context"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+)
+
+// Defaults of the data key rotation.
+const (
+	DefaultDataKeyMaxAge  = time.Hour
+	DefaultDataKeyMaxUses = 1 << 24
+)
+
+// maxCachedKeys bounds the decrypted data keys kept by an Envelope.
+const maxCachedKeys = 1024
+
+// Ciphertext header.
+const (
+	formatVersion     byte = 1
+	modeRandom        byte = 1
+	modeDeterministic byte = 2
+)
+
+// Labels deriving the keys of the deterministic mode from its data key.
+const (
+	deterministicEncLabel = "nexs-lib/crypto deterministic encryption"
+	deterministicMACLabel = "nexs-lib/crypto deterministic nonce"
+)
+
+var (
+	// ErrInvalidCiphertext is returned for data that is not a ciphertext of
+	// this package.
+	ErrInvalidCiphertext = errors.New("crypto: invalid ciphertext")
+	// ErrDecrypt is returned when a ciphertext fails authentication: it was
+	// modified, or encrypted with other associated data.
+	ErrDecrypt = errors.New("crypto: message authentication failed")
+	// ErrNoDeterministicKey is returned by the deterministic methods of an
+	// Envelope created without WithDeterministicKey.
+	ErrNoDeterministicKey = errors.New("crypto: no deterministic key")
+)
+
+// Option configures an Envelope.
+type Option func(*Envelope)
+
+// WithDataKeyRotation sets how long and for how many encryptions a data key
+// is used before a new one is generated. Fewer encryptions per key cost more
+// KeyProvider calls; AES-GCM with random nonces must not exceed 2^32.
+// Defaults to DefaultDataKeyMaxAge and DefaultDataKeyMaxUses.
+func WithDataKeyRotation(maxAge time.Duration, maxUses int) Option {
+	return func(e *Envelope) {
+		if maxAge > 0 {
+			e.maxAge = maxAge
+		}
+		if maxUses > 0 {
+			e.maxUses = maxUses
+		}
+	}
+}
+
+// WithDeterministicKey enables deterministic encryption with the data key,
+// created once with GenerateWrappedKey and kept in the configuration. All
+// the instances searching the same fields must use the same key.
+func WithDeterministicKey(key WrappedKey) Option {
+	return func(e *Envelope) {
+		e.deterministicKey = &key
+	}
+}
+
+// WithClock sets the clock of the data key rotation. Defaults to the real
+// clock.
+func WithClock(c clock.Clock) Option {
+	return func(e *Envelope) {
+		e.clock = c
+	}
+}
+
+// dataKey is a data key ready to encrypt.
+type dataKey struct {
+	header  []byte
+	aead    cipher.AEAD
+	mac     []byte
+	created time.Time
+	uses    int
+}
+
+// Envelope encrypts and decrypts data with data keys of a KeyProvider. It is
+// safe for concurrent use.
+type Envelope struct {
+	provider         KeyProvider
+	maxAge           time.Duration
+	maxUses          int
+	clock            clock.Clock
+	deterministicKey *WrappedKey
+
+	deterministic *dataKey
+
+	mu      sync.Mutex
+	current *dataKey
+	// keys holds the decrypted data keys by header.
+	keys map[string]*dataKey
+}
+
+// New creates an Envelope encrypting with data keys of provider. It decrypts
+// the deterministic key, when set, failing when it cannot.
+func New(ctx context.Context, provider KeyProvider, opts ...Option) (*Envelope, error) {
+	e := &Envelope{
+		provider: provider,
+		maxAge:   DefaultDataKeyMaxAge,
+		maxUses:  DefaultDataKeyMaxUses,
+		keys:     make(map[string]*dataKey),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	e.clock = clock.OrReal(e.clock)
+
+	if e.deterministicKey != nil {
+		header, err := appendHeader(modeDeterministic, e.deterministicKey.KeyID, e.deterministicKey.Ciphertext)
+		if err != nil {
+			return nil, err
+		}
+		if e.deterministic, err = e.key(ctx, header); err != nil {
+			return nil, fmt.Errorf("crypto: deterministic key: %w", err)
+		}
+	}
+	return e, nil
+}
+
+// GenerateWrappedKey generates a data key with provider and returns its
+// encrypted form, for WithDeterministicKey.
+func GenerateWrappedKey(ctx context.Context, provider KeyProvider) (WrappedKey, error) {
+	key, err := provider.GenerateDataKey(ctx)
+	if err != nil {
+		return WrappedKey{}, err
+	}
+	return key.Wrapped(), nil
+}
+
+// Encrypt encrypts plaintext with a random nonce, so equal plaintexts give
+// different ciphertexts. The associated data, such as a record id, is
+// authenticated but not stored: Decrypt must be given the same.
+func (e *Envelope) Encrypt(ctx context.Context, plaintext, aad []byte) ([]byte, error) {
+	key, err := e.currentKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, key.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return seal(key, nonce, plaintext, aad), nil
+}
+
+// EncryptDeterministic encrypts plaintext so equal plaintexts with equal
+// associated data give equal ciphertexts, which can be compared in queries.
+// It reveals which values are equal, so use it only for searched fields.
+func (e *Envelope) EncryptDeterministic(_ context.Context, plaintext, aad []byte) ([]byte, error) {
+	key := e.deterministic
+	if key == nil {
+		return nil, ErrNoDeterministicKey
+	}
+	mac := hmac.New(sha256.New, key.mac)
+	mac.Write(key.header)
+	mac.Write(binary.BigEndian.AppendUint32(nil, uint32(len(aad))))
+	mac.Write(aad)
+	mac.Write(plaintext)
+	return seal(key, mac.Sum(nil)[:key.aead.NonceSize()], plaintext, aad), nil
+}
+
+// Decrypt decrypts a ciphertext of Encrypt or EncryptDeterministic, which
+// hold the encrypted data key: data encrypted before a key rotation is still
+// decrypted while the provider has the old key encryption key.
+func (e *Envelope) Decrypt(ctx context.Context, ciphertext, aad []byte) ([]byte, error) {
+	header, body, err := splitHeader(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	key, err := e.key(ctx, header)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) < key.aead.NonceSize() {
+		return nil, ErrInvalidCiphertext
+	}
+	nonce, sealed := body[:key.aead.NonceSize()], body[key.aead.NonceSize():]
+	plaintext, err := key.aead.Open(nil, nonce, sealed, append(append([]byte{}, header...), aad...))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+// EncryptString encrypts s with Encrypt, returning base64.
+func (e *Envelope) EncryptString(ctx context.Context, s string) (string, error) {
+	ciphertext, err := e.Encrypt(ctx, []byte(s), nil)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// EncryptDeterministicString encrypts s with EncryptDeterministic, returning
+// base64. It returns the value to compare with searchable fields, e.g.
+// WHERE email = $1.
+func (e *Envelope) EncryptDeterministicString(ctx context.Context, s string) (string, error) {
+	ciphertext, err := e.EncryptDeterministic(ctx, []byte(s), nil)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// DecryptString decrypts the result of EncryptString or
+// EncryptDeterministicString.
+func (e *Envelope) DecryptString(ctx context.Context, s string) (string, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", ErrInvalidCiphertext
+	}
+	plaintext, err := e.Decrypt(ctx, ciphertext, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// currentKey returns the data key of Encrypt, generating a new one when it
+// expired.
+func (e *Envelope) currentKey(ctx context.Context) (*dataKey, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if key := e.current; key != nil && key.uses < e.maxUses && e.clock.Since(key.created) < e.maxAge {
+		key.uses++
+		return key, nil
+	}
+
+	generated, err := e.provider.GenerateDataKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("crypto: generate data key: %w", err)
+	}
+	header, err := appendHeader(modeRandom, generated.KeyID, generated.Ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	key, err := newDataKey(modeRandom, header, generated.Plaintext)
+	if err != nil {
+		return nil, err
+	}
+	key.created, key.uses = e.clock.Now(), 1
+	e.current = key
+	e.cache(key)
+	return key, nil
+}
+
+// key returns the data key of the header, decrypting it with the provider
+// when it is not cached.
+func (e *Envelope) key(ctx context.Context, header []byte) (*dataKey, error) {
+	e.mu.Lock()
+	key, ok := e.keys[string(header)]
+	e.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	keyID, ciphertext, _, err := readKeyRef(header[2:])
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := e.provider.DecryptDataKey(ctx, keyID, ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("crypto: decrypt data key: %w", err)
+	}
+	// the header may be part of the caller's ciphertext
+	header = append([]byte(nil), header...)
+	if key, err = newDataKey(header[1], header, plaintext); err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	e.cache(key)
+	e.mu.Unlock()
+	return key, nil
+}
+
+// cache keeps the key for Decrypt, emptying the cache when full. It is
+// called with mu held.
+func (e *Envelope) cache(key *dataKey) {
+	if len(e.keys) >= maxCachedKeys {
+		clear(e.keys)
+	}
+	e.keys[string(key.header)] = key
+}
+
+// newDataKey creates the ciphers of a data key. Deterministic keys derive an
+// encryption key and a nonce key from the data key.
+func newDataKey(mode byte, header, plaintext []byte) (*dataKey, error) {
+	key := &dataKey{header: header}
+	encKey := plaintext
+	if mode == modeDeterministic {
+		var err error
+		if encKey, err = hkdf.Key(sha256.New, plaintext, nil, deterministicEncLabel, DataKeySize); err != nil {
+			return nil, err
+		}
+		if key.mac, err = hkdf.Key(sha256.New, plaintext, nil, deterministicMACLabel, DataKeySize); err != nil {
+			return nil, err
+		}
+	}
+	aead, err := newAEAD(encKey)
+	if err != nil {
+		return nil, fmt.Errorf("crypto: invalid data key: %w", err)
+	}
+	key.aead = aead
+	return key, nil
+}
+
+// seal encrypts the plaintext, authenticating the header with the
+// associated data.
+func seal(key *dataKey, nonce, plaintext, aad []byte) []byte {
+	out := make([]byte, 0, len(key.header)+len(nonce)+len(plaintext)+key.aead.Overhead())
+	out = append(out, key.header...)
+	out = append(out, nonce...)
+	return key.aead.Seal(out, nonce, plaintext, append(append([]byte{}, key.header...), aad...))
+}
+
+// appendHeader returns the ciphertext header: format version, mode, key
+// encryption key id and encrypted data key.
+func appendHeader(mode byte, keyID string, ciphertext []byte) ([]byte, error) {
+	return appendKeyRef([]byte{formatVersion, mode}, keyID, ciphertext)
+}
+
+// splitHeader splits a ciphertext in its header and its nonce and sealed
+// data.
+func splitHeader(ciphertext []byte) (header, body []byte, err error) {
+	if len(ciphertext) < 2 || ciphertext[0] != formatVersion ||
+		(ciphertext[1] != modeRandom && ciphertext[1] != modeDeterministic) {
+		return nil, nil, ErrInvalidCiphertext
+	}
+	_, _, rest, err := readKeyRef(ciphertext[2:])
+	if err != nil {
+		return nil, nil, err
+	}
+	n := len(ciphertext) - len(rest)
+	return ciphertext[:n:n], rest, nil
+}
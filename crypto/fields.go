@@ -0,0 +1,226 @@
+package crypto
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// TagName is the struct tag marking the fields encrypted by EncryptFields
+// and FieldCodec, with the value TagRandom or TagDeterministic:
+//
+//	type Customer struct {
+//		ID       string
+//		Email    string `encrypt:"deterministic"`
+//		Document string `encrypt:"random"`
+//	}
+const TagName = "encrypt"
+
+// Values of TagName.
+const (
+	// TagRandom encrypts the field with Encrypt.
+	TagRandom = "random"
+	// TagDeterministic encrypts the field with EncryptDeterministic, so it
+	// can be searched by equality.
+	TagDeterministic = "deterministic"
+)
+
+// ErrNotPointer is returned by EncryptFields, DecryptFields and
+// FieldCodec.Unmarshal when not given a non-nil pointer.
+var ErrNotPointer = errors.New("crypto: value must be a non-nil pointer")
+
+// EncryptFields encrypts, in place, the tagged fields of the struct pointed
+// by v, e.g. before inserting it in the database. Tagged fields are string,
+// []byte or pointers to them; strings are encrypted to base64. Nested
+// structs, pointers and slices of them are walked; maps and interfaces are
+// not. Empty and nil values are left as they are.
+func (e *Envelope) EncryptFields(ctx context.Context, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return ErrNotPointer
+	}
+	return e.walk(ctx, rv.Elem(), true, false)
+}
+
+// DecryptFields decrypts, in place, the tagged fields of the struct, or
+// slice of structs, pointed by v, e.g. after scanning it from the database.
+func (e *Envelope) DecryptFields(ctx context.Context, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return ErrNotPointer
+	}
+	return e.walk(ctx, rv.Elem(), false, false)
+}
+
+// walk encrypts or decrypts the tagged fields reachable from v. With clone,
+// the pointers and slices walked are replaced by copies first, so the
+// caller's value is not modified.
+func (e *Envelope) walk(ctx context.Context, v reflect.Value, encrypt, clone bool) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		if clone {
+			cp := reflect.New(v.Elem().Type())
+			cp.Elem().Set(v.Elem())
+			v.Set(cp)
+		}
+		return e.walk(ctx, v.Elem(), encrypt, clone)
+
+	case reflect.Slice, reflect.Array:
+		if !walkable(v.Type().Elem()) {
+			return nil
+		}
+		if clone && v.Kind() == reflect.Slice && !v.IsNil() {
+			cp := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+			reflect.Copy(cp, v)
+			v.Set(cp)
+		}
+		for i := range v.Len() {
+			if err := e.walk(ctx, v.Index(i), encrypt, clone); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Struct:
+		t := v.Type()
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			mode, tagged := f.Tag.Lookup(TagName)
+			if !tagged {
+				if walkable(f.Type) {
+					if err := e.walk(ctx, v.Field(i), encrypt, clone); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if err := e.field(ctx, v.Field(i), mode, encrypt, clone); err != nil {
+				return fmt.Errorf("crypto: field %s.%s: %w", t.Name(), f.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// walkable reports whether values of t may hold tagged fields.
+func walkable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return walkable(t.Elem())
+	case reflect.Struct:
+		return true
+	}
+	return false
+}
+
+// field encrypts or decrypts a tagged field.
+func (e *Envelope) field(ctx context.Context, v reflect.Value, mode string, encrypt, clone bool) error {
+	if mode != TagRandom && mode != TagDeterministic {
+		return fmt.Errorf("invalid %s tag %q", TagName, mode)
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		if clone {
+			cp := reflect.New(v.Elem().Type())
+			cp.Elem().Set(v.Elem())
+			v.Set(cp)
+		}
+		v = v.Elem()
+	}
+
+	switch {
+	case v.Kind() == reflect.String:
+		if v.Len() == 0 {
+			return nil
+		}
+		var s string
+		var err error
+		switch {
+		case !encrypt:
+			s, err = e.DecryptString(ctx, v.String())
+		case mode == TagDeterministic:
+			s, err = e.EncryptDeterministicString(ctx, v.String())
+		default:
+			s, err = e.EncryptString(ctx, v.String())
+		}
+		if err != nil {
+			return err
+		}
+		v.SetString(s)
+
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		if v.Len() == 0 {
+			return nil
+		}
+		var b []byte
+		var err error
+		switch {
+		case !encrypt:
+			b, err = e.Decrypt(ctx, v.Bytes(), nil)
+		case mode == TagDeterministic:
+			b, err = e.EncryptDeterministic(ctx, v.Bytes(), nil)
+		default:
+			b, err = e.Encrypt(ctx, v.Bytes(), nil)
+		}
+		if err != nil {
+			return err
+		}
+		v.SetBytes(b)
+
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// FieldCodec encodes values as JSON with their tagged fields encrypted. The
+// caller stores the result, e.g. with a cache/valkey client.
+type FieldCodec struct {
+	envelope *Envelope
+}
+
+// NewFieldCodec creates a FieldCodec encrypting with envelope.
+func NewFieldCodec(envelope *Envelope) *FieldCodec {
+	return &FieldCodec{envelope: envelope}
+}
+
+// Marshal encodes v, a struct or a pointer to one, with the tagged fields
+// encrypted. v is not modified.
+func (c *FieldCodec) Marshal(ctx context.Context, v any) (string, error) {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return "null", nil
+	}
+	cp := reflect.New(rv.Type()).Elem()
+	cp.Set(rv)
+	if err := c.envelope.walk(ctx, cp, true, true); err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(cp.Interface())
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Unmarshal decodes data into v, a pointer, and decrypts the tagged fields.
+func (c *FieldCodec) Unmarshal(ctx context.Context, data string, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return ErrNotPointer
+	}
+	if err := json.Unmarshal([]byte(data), v); err != nil {
+		return err
+	}
+	return c.envelope.walk(ctx, rv.Elem(), false, false)
+}
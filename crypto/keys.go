@@ -0,0 +1,171 @@
+package crypto
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// DataKeySize is the size of the generated data keys, for AES-256.
+const DataKeySize = 32
+
+// ErrUnknownKey is returned when a data key was encrypted by a key
+// encryption key the provider does not have.
+var ErrUnknownKey = errors.New("crypto: unknown key encryption key")
+
+// DataKey is a data encryption key generated by a KeyProvider: the plaintext
+// encrypts the data and is never stored; the ciphertext, encrypted by the key
+// encryption key KeyID, is stored with the data.
+type DataKey struct {
+	KeyID      string
+	Plaintext  []byte
+	Ciphertext []byte
+}
+
+// Wrapped returns the encrypted form of the key, which can be stored.
+func (k DataKey) Wrapped() WrappedKey {
+	return WrappedKey{KeyID: k.KeyID, Ciphertext: k.Ciphertext}
+}
+
+// WrappedKey is an encrypted data key. Its text form, used in configuration
+// files and environment variables, is base64 encoded.
+type WrappedKey struct {
+	KeyID      string
+	Ciphertext []byte
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (k WrappedKey) MarshalText() ([]byte, error) {
+	data, err := appendKeyRef(nil, k.KeyID, k.Ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(base64.RawURLEncoding.EncodeToString(data)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (k *WrappedKey) UnmarshalText(text []byte) error {
+	data, err := base64.RawURLEncoding.DecodeString(string(text))
+	if err != nil {
+		return fmt.Errorf("crypto: invalid wrapped key: %w", err)
+	}
+	keyID, ciphertext, rest, err := readKeyRef(data)
+	if err != nil || len(rest) > 0 {
+		return errors.New("crypto: invalid wrapped key")
+	}
+	k.KeyID, k.Ciphertext = keyID, ciphertext
+	return nil
+}
+
+// KeyProvider generates and decrypts data keys with key encryption keys held
+// by a KMS, such as Vault transit, AWS KMS or Google Cloud KMS, or locally.
+// Implementations must be safe for concurrent use.
+type KeyProvider interface {
+	// GenerateDataKey returns a new DataKeySize data key encrypted by the
+	// current key encryption key.
+	GenerateDataKey(ctx context.Context) (DataKey, error)
+
+	// DecryptDataKey decrypts a data key encrypted by the key encryption key
+	// keyID, returning an error wrapping ErrUnknownKey when it is unknown.
+	DecryptDataKey(ctx context.Context, keyID string, ciphertext []byte) ([]byte, error)
+}
+
+// LocalKeyProvider encrypts data keys with key encryption keys held in
+// memory, e.g. loaded from secrets. It keeps retired keys to decrypt old
+// data keys, so keys are rotated by adding a key and making it current.
+type LocalKeyProvider struct {
+	current string
+	keys    map[string]cipher.AEAD
+}
+
+// NewLocalKeyProvider creates a provider encrypting new data keys with the
+// key current. Keys are 16, 24 or 32 bytes long, for AES-128, AES-192 or
+// AES-256.
+func NewLocalKeyProvider(current string, keys map[string][]byte) (*LocalKeyProvider, error) {
+	if _, ok := keys[current]; !ok {
+		return nil, fmt.Errorf("crypto: current key %q not found", current)
+	}
+	p := &LocalKeyProvider{current: current, keys: make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("crypto: key %q: %w", id, err)
+		}
+		p.keys[id] = aead
+	}
+	return p, nil
+}
+
+// GenerateDataKey implements KeyProvider.
+func (p *LocalKeyProvider) GenerateDataKey(context.Context) (DataKey, error) {
+	plaintext := make([]byte, DataKeySize)
+	if _, err := rand.Read(plaintext); err != nil {
+		return DataKey{}, err
+	}
+	aead := p.keys[p.current]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+DataKeySize+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return DataKey{}, err
+	}
+	return DataKey{
+		KeyID:      p.current,
+		Plaintext:  plaintext,
+		Ciphertext: aead.Seal(nonce, nonce, plaintext, []byte(p.current)),
+	}, nil
+}
+
+// DecryptDataKey implements KeyProvider.
+func (p *LocalKeyProvider) DecryptDataKey(_ context.Context, keyID string, ciphertext []byte) ([]byte, error) {
+	aead, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, keyID)
+	}
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	nonce, sealed := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, sealed, []byte(keyID))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+// newAEAD creates an AES-GCM cipher with the key.
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// appendKeyRef appends the key id and the encrypted data key, each prefixed
+// by its length.
+func appendKeyRef(dst []byte, keyID string, ciphertext []byte) ([]byte, error) {
+	if len(keyID) == 0 || len(keyID) > 0xff || len(ciphertext) == 0 || len(ciphertext) > 0xffff {
+		return nil, errors.New("crypto: key id or encrypted data key too long or empty")
+	}
+	dst = append(dst, byte(len(keyID)))
+	dst = append(dst, keyID...)
+	dst = binary.BigEndian.AppendUint16(dst, uint16(len(ciphertext)))
+	return append(dst, ciphertext...), nil
+}
+
+// readKeyRef reads what appendKeyRef appended.
+func readKeyRef(data []byte) (keyID string, ciphertext, rest []byte, err error) {
+	if len(data) < 1 || data[0] == 0 || len(data) < 1+int(data[0])+2 {
+		return "", nil, nil, ErrInvalidCiphertext
+	}
+	keyID, data = string(data[1:1+int(data[0])]), data[1+int(data[0]):]
+	n := int(binary.BigEndian.Uint16(data))
+	if len(data) < 2+n {
+		return "", nil, nil, ErrInvalidCiphertext
+	}
+	return keyID, data[2 : 2+n], data[2+n:], nil
+}
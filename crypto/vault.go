@@ -0,0 +1,140 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// maxVaultResponse limits the size of Vault responses.
+const maxVaultResponse = 1 << 20
+
+// VaultTransitConfig configures the Vault transit key provider.
+type VaultTransitConfig struct {
+	// Address of the Vault server. Defaults to VAULT_ADDR.
+	Address string
+	// Token used for authentication. Defaults to VAULT_TOKEN.
+	Token string
+	// Namespace for Vault Enterprise. Defaults to VAULT_NAMESPACE.
+	Namespace string
+	// Mount of the transit engine. Defaults to "transit".
+	Mount string
+	// Key is the transit key encrypting the data keys.
+	Key string
+	// HTTPClient used for requests. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// VaultTransit is a KeyProvider generating data keys with the Vault transit
+// engine, whose keys never leave Vault. Transit key rotations are
+// transparent: the encrypted data keys carry their key version.
+type VaultTransit struct {
+	cfg VaultTransitConfig
+}
+
+// NewVaultTransit creates a Vault transit key provider.
+func NewVaultTransit(cfg VaultTransitConfig) *VaultTransit {
+	if cfg.Address == "" {
+		cfg.Address = os.Getenv("VAULT_ADDR")
+	}
+	if cfg.Token == "" {
+		cfg.Token = os.Getenv("VAULT_TOKEN")
+	}
+	if cfg.Namespace == "" {
+		cfg.Namespace = os.Getenv("VAULT_NAMESPACE")
+	}
+	if cfg.Mount == "" {
+		cfg.Mount = "transit"
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+	cfg.Address = strings.TrimRight(cfg.Address, "/")
+	cfg.Mount = strings.Trim(cfg.Mount, "/")
+	return &VaultTransit{cfg: cfg}
+}
+
+// GenerateDataKey implements KeyProvider.
+func (v *VaultTransit) GenerateDataKey(ctx context.Context) (DataKey, error) {
+	var response struct {
+		Plaintext  string `json:"plaintext"`
+		Ciphertext string `json:"ciphertext"`
+	}
+	if err := v.call(ctx, "datakey/plaintext/"+v.cfg.Key, map[string]any{"bits": DataKeySize * 8}, &response); err != nil {
+		return DataKey{}, err
+	}
+	plaintext, err := base64.StdEncoding.DecodeString(response.Plaintext)
+	if err != nil {
+		return DataKey{}, fmt.Errorf("vault: decode data key: %w", err)
+	}
+	return DataKey{KeyID: v.cfg.Key, Plaintext: plaintext, Ciphertext: []byte(response.Ciphertext)}, nil
+}
+
+// DecryptDataKey implements KeyProvider.
+func (v *VaultTransit) DecryptDataKey(ctx context.Context, keyID string, ciphertext []byte) ([]byte, error) {
+	var response struct {
+		Plaintext string `json:"plaintext"`
+	}
+	if err := v.call(ctx, "decrypt/"+keyID, map[string]any{"ciphertext": string(ciphertext)}, &response); err != nil {
+		return nil, err
+	}
+	plaintext, err := base64.StdEncoding.DecodeString(response.Plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("vault: decode data key: %w", err)
+	}
+	return plaintext, nil
+}
+
+// call posts the request to the transit path and decodes the data of the
+// response.
+func (v *VaultTransit) call(ctx context.Context, path string, request, data any) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		v.cfg.Address+"/v1/"+v.cfg.Mount+"/"+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Vault-Token", v.cfg.Token)
+	if v.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.cfg.Namespace)
+	}
+
+	resp, err := v.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	payload, err := io.ReadAll(io.LimitReader(resp.Body, maxVaultResponse))
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%w: %s/%s", ErrUnknownKey, v.cfg.Mount, path)
+	case resp.StatusCode >= 300:
+		// The body is not included as it may echo key material
+		return fmt.Errorf("vault: unexpected status %d from %s", resp.StatusCode, req.URL.Host)
+	}
+
+	var response struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(payload, &response); err != nil {
+		return fmt.Errorf("vault: decode response: %w", err)
+	}
+	if err := json.Unmarshal(response.Data, data); err != nil {
+		return fmt.Errorf("vault: decode response: %w", err)
+	}
+	return nil
+}
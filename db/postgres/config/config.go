@@ -1,11 +1,21 @@
 package config
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/fsvxavier/nexs-lib/db/postgres/interfaces"
+	"github.com/fsvxavier/nexs-lib/parsers/dsn"
+)
+
+// connStringParser valida strings de conexão em formato URL ou chave/valor.
+// Nenhuma parte é obrigatória pois a libpq assume host, usuário e banco padrão
+var connStringParser = dsn.NewParser(
+	dsn.WithSchemes("postgres", "postgresql"),
+	dsn.WithRequired(),
 )
 
 // DefaultConfig implementa IConfig com otimizações de memória
@@ -18,6 +28,8 @@ type DefaultConfig struct {
 	hookConfig         interfaces.HookConfig
 	failoverConfig     interfaces.FailoverConfig
 	readReplicaConfig  interfaces.ReadReplicaConfig
+	typeConfig         interfaces.TypeConfig
+	annotationConfig   interfaces.AnnotationConfig
 	multiTenantEnabled bool
 
 	// Mutex para thread-safety
@@ -82,6 +94,20 @@ func (c *DefaultConfig) GetConnectionString() string {
 	return c.connectionString
 }
 
+// GetDSN retorna a string de conexão interpretada
+func (c *DefaultConfig) GetDSN() (*dsn.DSN, error) {
+	return connStringParser.ParseString(context.Background(), c.GetConnectionString())
+}
+
+// RedactedConnectionString retorna a string de conexão sem credenciais, segura para logs
+func (c *DefaultConfig) RedactedConnectionString() string {
+	parsed, err := c.GetDSN()
+	if err != nil {
+		return "[invalid connection string]"
+	}
+	return parsed.Redacted()
+}
+
 // GetPoolConfig retorna configuração do pool
 func (c *DefaultConfig) GetPoolConfig() interfaces.PoolConfig {
 	c.mu.RLock()
@@ -124,6 +150,20 @@ func (c *DefaultConfig) GetReadReplicaConfig() interfaces.ReadReplicaConfig {
 	return c.readReplicaConfig
 }
 
+// GetTypeConfig retorna configuração de tipos customizados
+func (c *DefaultConfig) GetTypeConfig() interfaces.TypeConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.typeConfig
+}
+
+// GetAnnotationConfig retorna configuração de anotações das queries
+func (c *DefaultConfig) GetAnnotationConfig() interfaces.AnnotationConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.annotationConfig
+}
+
 // IsMultiTenantEnabled retorna se multi-tenant está habilitado
 func (c *DefaultConfig) IsMultiTenantEnabled() bool {
 	c.mu.RLock()
@@ -172,6 +212,28 @@ func (c *DefaultConfig) validateInternal() error {
 		return fmt.Errorf("connection string is required")
 	}
 
+	if _, err := connStringParser.ParseString(context.Background(), c.connectionString); err != nil {
+		return fmt.Errorf("invalid connection string: %w", err)
+	}
+
+	if c.readReplicaConfig.Enabled {
+		for i, connStr := range c.readReplicaConfig.ConnectionStrings {
+			if _, err := connStringParser.ParseString(context.Background(), connStr); err != nil {
+				return fmt.Errorf("invalid read replica connection string %d: %w", i, err)
+			}
+		}
+	}
+
+	for i, name := range c.typeConfig.Types {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("custom type %d has an empty name", i)
+		}
+	}
+
+	if len(c.annotationConfig.ApplicationName) > 63 {
+		return fmt.Errorf("application name cannot be longer than 63 characters")
+	}
+
 	if c.poolConfig.MaxConns <= 0 {
 		return fmt.Errorf("max connections must be greater than 0")
 	}
@@ -326,6 +388,47 @@ func WithReadReplicas(enabled bool, connectionStrings []string, loadBalanceMode
 	}
 }
 
+// WithTypes registra tipos customizados (enums, domains, composites, seus
+// arrays e hstore) em cada conexão, permitindo o scan para []T, tipos string
+// do Go e map[string]string
+func WithTypes(names ...string) ConfigOption {
+	return func(c *DefaultConfig) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.typeConfig.Types = make([]string, len(names))
+		copy(c.typeConfig.Types, names)
+		c.clearCache()
+	}
+}
+
+// WithApplicationName define o application_name das conexões, exibido no
+// pg_stat_activity e nos logs do servidor
+func WithApplicationName(name string) ConfigOption {
+	return func(c *DefaultConfig) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.annotationConfig.ApplicationName = name
+		c.clearCache()
+	}
+}
+
+// WithQueryComments habilita comentários sqlcommenter nas queries com as tags
+// fixas informadas (ex.: service). Com traceContext o traceparent do span
+// ativo também é incluído
+func WithQueryComments(tags map[string]string, traceContext bool) ConfigOption {
+	return func(c *DefaultConfig) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.annotationConfig.Comments = true
+		c.annotationConfig.TraceContext = traceContext
+		c.annotationConfig.Tags = make(map[string]string, len(tags))
+		for k, v := range tags {
+			c.annotationConfig.Tags[k] = v
+		}
+		c.clearCache()
+	}
+}
+
 // WithEnabledHooks configura hooks habilitados
 func WithEnabledHooks(hooks []interfaces.HookType) ConfigOption {
 	return func(c *DefaultConfig) {
@@ -0,0 +1,29 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/fsvxavier/nexs-lib/db/postgres/interfaces"
+	"github.com/fsvxavier/nexs-lib/resilience/hedge"
+)
+
+// HedgedRead executa fn em uma conexão de leitura e, se o hedger decidir que
+// ela está lenta, executa fn novamente em outra conexão de leitura — o
+// balanceamento do pool escolhe a réplica, então estratégias como round robin
+// levam a tentativa a outra réplica. O primeiro resultado é retornado e o
+// contexto da outra tentativa é cancelado.
+//
+// A conexão é liberada quando fn retorna: fn deve consumir todas as linhas.
+// Use apenas para leituras, que podem ser executadas mais de uma vez.
+func HedgedRead[T any](ctx context.Context, pool interfaces.IReplicaPool, h *hedge.Hedger[T], preference interfaces.ReadPreference,
+	fn func(ctx context.Context, conn interfaces.IConn) (T, error)) (T, error) {
+	return h.Do(ctx, func(ctx context.Context, attempt int) (T, error) {
+		conn, err := pool.AcquireRead(ctx, preference)
+		if err != nil {
+			var zero T
+			return zero, err
+		}
+		defer conn.Release()
+		return fn(ctx, conn)
+	})
+}
@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/db/postgres/interfaces"
+	"github.com/fsvxavier/nexs-lib/resilience/hedge"
+)
+
+// hedgeTestPool entrega conexões de réplicas alternadas
+type hedgeTestPool struct {
+	interfaces.IReplicaPool
+	mu       sync.Mutex
+	next     int
+	released []string
+}
+
+type hedgeTestConn struct {
+	interfaces.IConn
+	pool    *hedgeTestPool
+	replica string
+}
+
+func (p *hedgeTestPool) AcquireRead(ctx context.Context, preference interfaces.ReadPreference) (interfaces.IConn, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	replica := []string{"replica-1", "replica-2"}[p.next%2]
+	p.next++
+	return &hedgeTestConn{pool: p, replica: replica}, nil
+}
+
+func (c *hedgeTestConn) Release() {
+	c.pool.mu.Lock()
+	defer c.pool.mu.Unlock()
+	c.pool.released = append(c.pool.released, c.replica)
+}
+
+func TestHedgedRead(t *testing.T) {
+	pool := &hedgeTestPool{}
+	h := hedge.New[string](hedge.WithDelay(10 * time.Millisecond))
+
+	value, err := HedgedRead(context.Background(), pool, h, interfaces.ReadPreferenceSecondary,
+		func(ctx context.Context, conn interfaces.IConn) (string, error) {
+			replica := conn.(*hedgeTestConn).replica
+			if replica == "replica-1" {
+				<-ctx.Done()
+				return "", ctx.Err()
+			}
+			return replica, nil
+		})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if value != "replica-2" {
+		t.Errorf("Expected the hedged replica to win, got %q", value)
+	}
+
+	// A tentativa cancelada também libera sua conexão
+	deadline := time.Now().Add(time.Second)
+	for {
+		pool.mu.Lock()
+		released := len(pool.released)
+		pool.mu.Unlock()
+		if released == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected both connections released, got %d", released)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
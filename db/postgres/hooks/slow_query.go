@@ -0,0 +1,571 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+	"github.com/fsvxavier/nexs-lib/db/postgres/interfaces"
+)
+
+// Explainer executa o EXPLAIN (FORMAT JSON) de uma query e retorna o plano
+type Explainer interface {
+	Explain(ctx context.Context, query string, args []interface{}) (json.RawMessage, error)
+}
+
+// ExplainerFunc adapta uma função para Explainer
+type ExplainerFunc func(ctx context.Context, query string, args []interface{}) (json.RawMessage, error)
+
+// Explain implementa Explainer
+func (f ExplainerFunc) Explain(ctx context.Context, query string, args []interface{}) (json.RawMessage, error) {
+	return f(ctx, query, args)
+}
+
+// PoolExplainer executa o EXPLAIN em uma conexão do pool. Sem ANALYZE, a
+// query não é executada: o PostgreSQL apenas planeja com os mesmos parâmetros
+func PoolExplainer(pool interfaces.IPool) Explainer {
+	return ExplainerFunc(func(ctx context.Context, query string, args []interface{}) (json.RawMessage, error) {
+		var plan string
+		err := pool.AcquireFunc(ctx, func(conn interfaces.IConn) error {
+			return conn.QueryRow(ctx, explainPrefix+query, args...).Scan(&plan)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return json.RawMessage(plan), nil
+	})
+}
+
+// explainPrefix comando usado para capturar o plano
+const explainPrefix = "EXPLAIN (FORMAT JSON) "
+
+// SlowQueryPlan plano capturado de uma query lenta. Query e Plan têm os
+// literais substituídos por ?
+type SlowQueryPlan struct {
+	Operation  string
+	Query      string
+	Duration   time.Duration
+	Plan       json.RawMessage
+	CapturedAt time.Time
+	Err        error
+}
+
+// SlowQueryStats contadores do analisador
+type SlowQueryStats struct {
+	// Detected queries acima do limite
+	Detected int64
+	// Explained planos capturados
+	Explained int64
+	// Skipped queries descartadas pela amostragem, pelo cooldown ou por não
+	// serem explicáveis
+	Skipped int64
+	// Throttled queries descartadas pelo rate limit ou pela concorrência
+	Throttled int64
+	// Failed EXPLAINs que retornaram erro
+	Failed int64
+}
+
+// SlowQueryOption configura o SlowQueryAnalyzer
+type SlowQueryOption func(*SlowQueryAnalyzer)
+
+// WithThreshold duração a partir da qual a query é considerada lenta
+// (padrão 1s)
+func WithThreshold(threshold time.Duration) SlowQueryOption {
+	return func(a *SlowQueryAnalyzer) {
+		a.threshold = threshold
+	}
+}
+
+// WithSampleRate fração das queries lentas que terão o plano capturado, de 0
+// a 1 (padrão 0.1)
+func WithSampleRate(rate float64) SlowQueryOption {
+	return func(a *SlowQueryAnalyzer) {
+		a.sampleRate = rate
+	}
+}
+
+// WithRateLimit limita o número de EXPLAINs por intervalo (padrão 10 por
+// minuto)
+func WithRateLimit(limit int, interval time.Duration) SlowQueryOption {
+	return func(a *SlowQueryAnalyzer) {
+		a.rateLimit = limit
+		a.rateInterval = interval
+	}
+}
+
+// WithMaxConcurrent número máximo de EXPLAINs simultâneos (padrão 1). Queries
+// lentas que chegam com todos em andamento são descartadas
+func WithMaxConcurrent(n int) SlowQueryOption {
+	return func(a *SlowQueryAnalyzer) {
+		a.maxConcurrent = n
+	}
+}
+
+// WithPlanCooldown intervalo mínimo entre capturas da mesma query (padrão
+// 5 minutos)
+func WithPlanCooldown(cooldown time.Duration) SlowQueryOption {
+	return func(a *SlowQueryAnalyzer) {
+		a.cooldown = cooldown
+	}
+}
+
+// WithExplainTimeout tempo máximo de cada EXPLAIN (padrão 5s)
+func WithExplainTimeout(timeout time.Duration) SlowQueryOption {
+	return func(a *SlowQueryAnalyzer) {
+		a.explainTimeout = timeout
+	}
+}
+
+// WithMaxPlanBytes tamanho máximo do plano anexado ao span (padrão 32 KiB)
+func WithMaxPlanBytes(n int) SlowQueryOption {
+	return func(a *SlowQueryAnalyzer) {
+		a.maxPlanBytes = n
+	}
+}
+
+// WithPlanHandler recebe cada plano capturado, por exemplo para registrá-lo
+// no log. Também é chamado quando o EXPLAIN falha, com Err preenchido
+func WithPlanHandler(handler func(ctx context.Context, plan SlowQueryPlan)) SlowQueryOption {
+	return func(a *SlowQueryAnalyzer) {
+		a.handler = handler
+	}
+}
+
+// WithTracerProvider define o tracer provider dos spans de plano (padrão o
+// provider global)
+func WithTracerProvider(tp trace.TracerProvider) SlowQueryOption {
+	return func(a *SlowQueryAnalyzer) {
+		a.tracerProvider = tp
+	}
+}
+
+// WithSlowQueryClock define o relógio usado no rate limit e no cooldown
+func WithSlowQueryClock(c clock.Clock) SlowQueryOption {
+	return func(a *SlowQueryAnalyzer) {
+		a.clock = c
+	}
+}
+
+// SlowQueryAnalyzer captura, de forma assíncrona e amostrada, o plano de
+// execução das queries que passam do limite. O plano é anexado a um span
+// filho do span da query (postgres.explain) e entregue ao plan handler
+type SlowQueryAnalyzer struct {
+	explainer      Explainer
+	threshold      time.Duration
+	sampleRate     float64
+	rateLimit      int
+	rateInterval   time.Duration
+	maxConcurrent  int
+	cooldown       time.Duration
+	explainTimeout time.Duration
+	maxPlanBytes   int
+	handler        func(ctx context.Context, plan SlowQueryPlan)
+	tracerProvider trace.TracerProvider
+	clock          clock.Clock
+	random         func() float64
+
+	mu          sync.Mutex
+	windowStart time.Time
+	windowCount int
+	lastPlanned map[string]time.Time
+	closed      bool
+
+	slots chan struct{}
+	wg    sync.WaitGroup
+
+	detected  atomic.Int64
+	explained atomic.Int64
+	skipped   atomic.Int64
+	throttled atomic.Int64
+	failed    atomic.Int64
+}
+
+// NewSlowQueryAnalyzer cria o analisador. Registre-o com Register
+func NewSlowQueryAnalyzer(explainer Explainer, opts ...SlowQueryOption) *SlowQueryAnalyzer {
+	a := &SlowQueryAnalyzer{
+		explainer:      explainer,
+		threshold:      time.Second,
+		sampleRate:     0.1,
+		rateLimit:      10,
+		rateInterval:   time.Minute,
+		maxConcurrent:  1,
+		cooldown:       5 * time.Minute,
+		explainTimeout: 5 * time.Second,
+		maxPlanBytes:   32 * 1024,
+		random:         rand.Float64,
+		lastPlanned:    make(map[string]time.Time),
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	a.clock = clock.OrReal(a.clock)
+	if a.tracerProvider == nil {
+		a.tracerProvider = otel.GetTracerProvider()
+	}
+	a.slots = make(chan struct{}, max(a.maxConcurrent, 1))
+	return a
+}
+
+// Register registra o analisador nos hooks AfterQueryHook e AfterExecHook
+func (a *SlowQueryAnalyzer) Register(hm interfaces.IHookManager) error {
+	if err := hm.RegisterHook(interfaces.AfterQueryHook, a.Hook()); err != nil {
+		return fmt.Errorf("failed to register slow query hook: %w", err)
+	}
+	if err := hm.RegisterHook(interfaces.AfterExecHook, a.Hook()); err != nil {
+		return fmt.Errorf("failed to register slow query hook: %w", err)
+	}
+	return nil
+}
+
+// Hook retorna o hook que detecta as queries lentas. Ele nunca bloqueia nem
+// interrompe a operação
+func (a *SlowQueryAnalyzer) Hook() interfaces.Hook {
+	return func(execCtx *interfaces.ExecutionContext) *interfaces.HookResult {
+		a.observe(execCtx)
+		return &interfaces.HookResult{Continue: true}
+	}
+}
+
+// observe decide se a query terá o plano capturado e dispara o EXPLAIN
+func (a *SlowQueryAnalyzer) observe(execCtx *interfaces.ExecutionContext) {
+	if execCtx.Duration < a.threshold {
+		return
+	}
+	// O próprio EXPLAIN passa pelos hooks da conexão
+	if isExplain(execCtx) {
+		return
+	}
+	a.detected.Add(1)
+
+	query := ScrubSQL(execCtx.Query)
+	if !explainable(execCtx.Query) || a.random() >= a.sampleRate {
+		a.skipped.Add(1)
+		return
+	}
+
+	select {
+	case a.slots <- struct{}{}:
+	default:
+		a.throttled.Add(1)
+		return
+	}
+	if !a.admit(query) {
+		<-a.slots
+		return
+	}
+
+	// O EXPLAIN roda após o fim da requisição, mas mantém o trace da query
+	ctx := context.Background()
+	if execCtx.Context != nil {
+		ctx = context.WithoutCancel(execCtx.Context)
+	}
+	args := append([]interface{}(nil), execCtx.Args...)
+	plan := SlowQueryPlan{Operation: execCtx.Operation, Query: query, Duration: execCtx.Duration}
+
+	go func() {
+		defer a.wg.Done()
+		defer func() { <-a.slots }()
+		a.explain(ctx, execCtx.Query, args, plan)
+	}()
+}
+
+// admit aplica o cooldown por query e o rate limit. Quando aceita, registra
+// o EXPLAIN no WaitGroup ainda sob o lock, para que Close o aguarde
+func (a *SlowQueryAnalyzer) admit(query string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.closed {
+		a.skipped.Add(1)
+		return false
+	}
+
+	now := a.clock.Now()
+	if last, ok := a.lastPlanned[query]; ok && now.Sub(last) < a.cooldown {
+		a.skipped.Add(1)
+		return false
+	}
+
+	if now.Sub(a.windowStart) >= a.rateInterval {
+		a.windowStart, a.windowCount = now, 0
+	}
+	if a.rateLimit > 0 && a.windowCount >= a.rateLimit {
+		a.throttled.Add(1)
+		return false
+	}
+	a.windowCount++
+
+	// Remove entradas expiradas para limitar o uso de memória
+	if len(a.lastPlanned) >= 1000 {
+		for q, last := range a.lastPlanned {
+			if now.Sub(last) >= a.cooldown {
+				delete(a.lastPlanned, q)
+			}
+		}
+	}
+	a.lastPlanned[query] = now
+	a.wg.Add(1)
+	return true
+}
+
+// explain executa o EXPLAIN e publica o plano no span e no handler
+func (a *SlowQueryAnalyzer) explain(ctx context.Context, query string, args []interface{}, plan SlowQueryPlan) {
+	ctx, span := a.tracerProvider.Tracer("github.com/fsvxavier/nexs-lib/db/postgres").Start(
+		ctx, "postgres.explain",
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.operation", plan.Operation),
+			attribute.String("db.statement", plan.Query),
+			attribute.Int64("db.query.duration_ms", plan.Duration.Milliseconds()),
+		),
+	)
+	defer span.End()
+
+	explainCtx, cancel := context.WithTimeout(context.WithValue(ctx, explainKey{}, true), a.explainTimeout)
+	defer cancel()
+
+	raw, err := a.explainer.Explain(explainCtx, query, args)
+	plan.CapturedAt = a.clock.Now()
+	if err != nil {
+		a.failed.Add(1)
+		plan.Err = fmt.Errorf("explain failed: %w", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "explain failed")
+	} else {
+		a.explained.Add(1)
+		plan.Plan = ScrubPlan(raw)
+		span.SetAttributes(attribute.String("db.postgresql.plan", truncate(string(plan.Plan), a.maxPlanBytes)))
+	}
+
+	if a.handler != nil {
+		a.handler(ctx, plan)
+	}
+}
+
+// Stats retorna os contadores do analisador
+func (a *SlowQueryAnalyzer) Stats() SlowQueryStats {
+	return SlowQueryStats{
+		Detected:  a.detected.Load(),
+		Explained: a.explained.Load(),
+		Skipped:   a.skipped.Load(),
+		Throttled: a.throttled.Load(),
+		Failed:    a.failed.Load(),
+	}
+}
+
+// Close para de capturar planos e aguarda os EXPLAINs em andamento
+func (a *SlowQueryAnalyzer) Close() {
+	a.mu.Lock()
+	a.closed = true
+	a.mu.Unlock()
+	a.wg.Wait()
+}
+
+// explainKey marca o contexto do EXPLAIN para que ele não seja analisado
+type explainKey struct{}
+
+func isExplain(execCtx *interfaces.ExecutionContext) bool {
+	if execCtx.Context != nil && execCtx.Context.Value(explainKey{}) != nil {
+		return true
+	}
+	return hasKeyword(execCtx.Query, "EXPLAIN")
+}
+
+// explainable aceita um único comando DML; DDL, utilitários e múltiplos
+// comandos não são planejados
+func explainable(query string) bool {
+	trimmed := strings.TrimRight(strings.TrimSpace(query), "; \t\n")
+	if strings.Contains(ScrubSQL(trimmed), ";") {
+		return false
+	}
+	for _, keyword := range []string{"SELECT", "WITH", "INSERT", "UPDATE", "DELETE", "VALUES", "TABLE"} {
+		if hasKeyword(trimmed, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasKeyword verifica se a query começa com a palavra-chave, ignorando
+// espaços e comentários iniciais
+func hasKeyword(query, keyword string) bool {
+	query = strings.TrimSpace(stripLeadingComments(query))
+	return len(query) >= len(keyword) && strings.EqualFold(query[:len(keyword)], keyword)
+}
+
+func stripLeadingComments(query string) string {
+	for {
+		query = strings.TrimSpace(query)
+		switch {
+		case strings.HasPrefix(query, "--"):
+			end := strings.IndexByte(query, '\n')
+			if end < 0 {
+				return ""
+			}
+			query = query[end+1:]
+		case strings.HasPrefix(query, "/*"):
+			end := strings.Index(query, "*/")
+			if end < 0 {
+				return ""
+			}
+			query = query[end+2:]
+		default:
+			return query
+		}
+	}
+}
+
+// ScrubSQL substitui os literais (strings, strings com dollar quoting e
+// números) por ? e remove comentários. Placeholders ($1) e identificadores,
+// inclusive entre aspas duplas, são preservados
+func ScrubSQL(query string) string {
+	var b strings.Builder
+	b.Grow(len(query))
+
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'':
+			// String literal, com '' como escape
+			i++
+			for i < len(query) {
+				if query[i] == '\'' {
+					if i+1 < len(query) && query[i+1] == '\'' {
+						i += 2
+						continue
+					}
+					break
+				}
+				i++
+			}
+			i++
+			b.WriteByte('?')
+		case c == '"':
+			end := strings.IndexByte(query[i+1:], '"')
+			if end < 0 {
+				b.WriteString(query[i:])
+				return b.String()
+			}
+			b.WriteString(query[i : i+end+2])
+			i += end + 2
+		case c == '-' && i+1 < len(query) && query[i+1] == '-':
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				return strings.TrimRight(b.String(), " ")
+			}
+			i += end
+		case c == '/' && i+1 < len(query) && query[i+1] == '*':
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return strings.TrimRight(b.String(), " ")
+			}
+			i += end + 4
+		case c == '$' && i+1 < len(query) && isDigit(query[i+1]):
+			// Placeholder posicional
+			j := i + 1
+			for j < len(query) && isDigit(query[j]) {
+				j++
+			}
+			b.WriteString(query[i:j])
+			i = j
+		case c == '$' && (i == 0 || !isIdentChar(query[i-1])) && dollarTag(query[i:]) != "":
+			// Dollar quoting: $$...$$ ou $tag$...$tag$
+			tag := dollarTag(query[i:])
+			closing := strings.Index(query[i+len(tag):], tag)
+			if closing < 0 {
+				b.WriteString(query[i:])
+				return b.String()
+			}
+			i += len(tag) + closing + len(tag)
+			b.WriteByte('?')
+		case isDigit(c) && (i == 0 || !isIdentChar(query[i-1])):
+			j := i
+			for j < len(query) && (isDigit(query[j]) || query[j] == '.' ||
+				query[j] == 'e' || query[j] == 'E' ||
+				((query[j] == '+' || query[j] == '-') && (query[j-1] == 'e' || query[j-1] == 'E'))) {
+				j++
+			}
+			b.WriteByte('?')
+			i = j
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+// ScrubPlan aplica ScrubSQL a todos os textos do plano JSON, removendo os
+// valores dos parâmetros que o PostgreSQL inclui em filtros e condições
+func ScrubPlan(plan json.RawMessage) json.RawMessage {
+	var tree interface{}
+	if err := json.Unmarshal(plan, &tree); err != nil {
+		return nil
+	}
+	scrubbed, err := json.Marshal(scrubValue(tree))
+	if err != nil {
+		return nil
+	}
+	return scrubbed
+}
+
+func scrubValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case string:
+		return ScrubSQL(value)
+	case []interface{}:
+		for i := range value {
+			value[i] = scrubValue(value[i])
+		}
+	case map[string]interface{}:
+		for key := range value {
+			value[key] = scrubValue(value[key])
+		}
+	}
+	return v
+}
+
+// dollarTag retorna a tag de abertura ($$ ou $tag$) no início de s, ou vazio
+func dollarTag(s string) string {
+	end := strings.IndexByte(s[1:], '$')
+	if end < 0 {
+		return ""
+	}
+	for i := 1; i <= end; i++ {
+		if !isIdentChar(s[i]) || s[i] == '$' {
+			return ""
+		}
+	}
+	return s[:end+2]
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '$' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+// truncate corta s em até n bytes sem quebrar runes; n <= 0 desativa o
+// limite
+func truncate(s string, n int) string {
+	if n <= 0 || len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,245 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+	"github.com/fsvxavier/nexs-lib/db/postgres/interfaces"
+)
+
+const testPlan = `[{"Plan":{"Node Type":"Seq Scan","Relation Name":"users","Filter":"(email = 'john@example.com'::text)","Total Cost":12.5}}]`
+
+type recordingExplainer struct {
+	mu      sync.Mutex
+	queries []string
+	err     error
+	block   chan struct{}
+}
+
+func (e *recordingExplainer) Explain(ctx context.Context, query string, args []interface{}) (json.RawMessage, error) {
+	if e.block != nil {
+		<-e.block
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.queries = append(e.queries, query)
+	if e.err != nil {
+		return nil, e.err
+	}
+	return json.RawMessage(testPlan), nil
+}
+
+func (e *recordingExplainer) calls() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.queries)
+}
+
+func slowQuery(ctx context.Context, query string, d time.Duration) *interfaces.ExecutionContext {
+	return &interfaces.ExecutionContext{
+		Context:   ctx,
+		Operation: "query",
+		Query:     query,
+		Args:      []interface{}{"john@example.com"},
+		Duration:  d,
+	}
+}
+
+func TestSlowQueryAnalyzer_CapturesPlan(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer tp.Shutdown(context.Background())
+
+	var plans []SlowQueryPlan
+	var mu sync.Mutex
+	explainer := &recordingExplainer{}
+	analyzer := NewSlowQueryAnalyzer(explainer,
+		WithThreshold(100*time.Millisecond),
+		WithSampleRate(1),
+		WithTracerProvider(tp),
+		WithPlanHandler(func(ctx context.Context, plan SlowQueryPlan) {
+			mu.Lock()
+			defer mu.Unlock()
+			plans = append(plans, plan)
+		}),
+	)
+
+	hm := NewHookManager(time.Second)
+	if err := analyzer.Register(hm); err != nil {
+		t.Fatalf("Register returned error: %v", err)
+	}
+
+	ctx, span := tp.Tracer("test").Start(context.Background(), "GET /users")
+	fast := slowQuery(ctx, "SELECT * FROM users WHERE id = $1", 10*time.Millisecond)
+	if err := hm.ExecuteHooks(interfaces.AfterQueryHook, fast); err != nil {
+		t.Fatalf("ExecuteHooks returned error: %v", err)
+	}
+	slow := slowQuery(ctx, "SELECT * FROM users WHERE email = $1 AND status = 'active' LIMIT 10", 2*time.Second)
+	if err := hm.ExecuteHooks(interfaces.AfterQueryHook, slow); err != nil {
+		t.Fatalf("ExecuteHooks returned error: %v", err)
+	}
+	span.End()
+	analyzer.Close()
+
+	if explainer.calls() != 1 || explainer.queries[0] != slow.Query {
+		t.Fatalf("expected one EXPLAIN of the original query, got %v", explainer.queries)
+	}
+
+	if len(plans) != 1 {
+		t.Fatalf("expected 1 plan, got %d", len(plans))
+	}
+	plan := plans[0]
+	if plan.Query != "SELECT * FROM users WHERE email = $1 AND status = ? LIMIT ?" {
+		t.Errorf("unexpected scrubbed query %q", plan.Query)
+	}
+	if strings.Contains(string(plan.Plan), "john@example.com") || !strings.Contains(string(plan.Plan), `(email = ?::text)`) {
+		t.Errorf("expected the plan to be scrubbed, got %s", plan.Plan)
+	}
+	if plan.Duration != 2*time.Second || plan.Err != nil {
+		t.Errorf("unexpected plan %+v", plan)
+	}
+
+	// O span do plano é filho do span da query
+	var explainSpan *tracetest.SpanStub
+	for _, s := range exporter.GetSpans() {
+		if s.Name == "postgres.explain" {
+			explainSpan = &s
+		}
+	}
+	if explainSpan == nil {
+		t.Fatal("expected a postgres.explain span")
+	}
+	if explainSpan.Parent.SpanID() != span.SpanContext().SpanID() {
+		t.Error("expected the explain span to be a child of the query span")
+	}
+	attrs := attribute.NewSet(explainSpan.Attributes...)
+	if v, ok := attrs.Value("db.postgresql.plan"); !ok || v.AsString() != string(plan.Plan) {
+		t.Errorf("expected the plan attribute, got %v", v)
+	}
+	if v, _ := attrs.Value("db.statement"); v.AsString() != plan.Query {
+		t.Errorf("expected the scrubbed statement, got %v", v)
+	}
+
+	stats := analyzer.Stats()
+	if stats.Detected != 1 || stats.Explained != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestSlowQueryAnalyzer_Safeguards(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	explainer := &recordingExplainer{}
+	analyzer := NewSlowQueryAnalyzer(explainer,
+		WithThreshold(time.Second),
+		WithSampleRate(1),
+		WithRateLimit(2, time.Minute),
+		WithPlanCooldown(time.Hour),
+		WithSlowQueryClock(fake),
+	)
+	hook := analyzer.Hook()
+	observe := func(query string) {
+		hook(slowQuery(context.Background(), query, 2*time.Second))
+		analyzer.wg.Wait()
+	}
+
+	observe("SELECT * FROM orders WHERE id = 1")
+	observe("SELECT * FROM orders WHERE id = 2") // mesma query após o scrub: cooldown
+	observe("SELECT * FROM items")
+	observe("SELECT * FROM users") // terceira no minuto: rate limit
+	observe("EXPLAIN SELECT * FROM users")
+	observe("CREATE INDEX CONCURRENTLY idx ON users (email)")
+	observe("SELECT 1; DROP TABLE users")
+
+	if explainer.calls() != 2 {
+		t.Fatalf("expected 2 EXPLAINs, got %v", explainer.queries)
+	}
+
+	fake.Advance(time.Minute)
+	observe("SELECT * FROM users")
+	if explainer.calls() != 3 {
+		t.Errorf("expected the rate limit to reset after the interval, got %d", explainer.calls())
+	}
+
+	stats := analyzer.Stats()
+	if stats.Detected != 7 || stats.Throttled != 1 || stats.Skipped != 3 || stats.Explained != 3 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestSlowQueryAnalyzer_SamplingAndConcurrency(t *testing.T) {
+	explainer := &recordingExplainer{block: make(chan struct{})}
+	analyzer := NewSlowQueryAnalyzer(explainer, WithSampleRate(0.5), WithRateLimit(0, time.Minute))
+	samples := []float64{0.9, 0.1, 0.2, 0}
+	analyzer.random = func() float64 {
+		v := samples[0]
+		samples = samples[1:]
+		return v
+	}
+	hook := analyzer.Hook()
+
+	hook(slowQuery(context.Background(), "SELECT * FROM a", 2*time.Second)) // fora da amostra
+	hook(slowQuery(context.Background(), "SELECT * FROM b", 2*time.Second)) // ocupa o único slot
+	hook(slowQuery(context.Background(), "SELECT * FROM c", 2*time.Second)) // sem slot livre
+
+	close(explainer.block)
+	analyzer.Close()
+
+	stats := analyzer.Stats()
+	if stats.Skipped != 1 || stats.Throttled != 1 || stats.Explained != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	// Após Close nenhum plano é capturado
+	hook(slowQuery(context.Background(), "SELECT * FROM d", 2*time.Second))
+	if explainer.calls() != 1 {
+		t.Errorf("expected no EXPLAIN after Close, got %d", explainer.calls())
+	}
+}
+
+func TestSlowQueryAnalyzer_ExplainFailure(t *testing.T) {
+	var got SlowQueryPlan
+	explainer := &recordingExplainer{err: errors.New("permission denied")}
+	analyzer := NewSlowQueryAnalyzer(explainer,
+		WithSampleRate(1),
+		WithPlanHandler(func(ctx context.Context, plan SlowQueryPlan) { got = plan }),
+	)
+
+	analyzer.Hook()(slowQuery(context.Background(), "DELETE FROM sessions WHERE expires_at < now()", 3*time.Second))
+	analyzer.Close()
+
+	if got.Err == nil || got.Plan != nil {
+		t.Errorf("expected the handler to receive the error, got %+v", got)
+	}
+	if analyzer.Stats().Failed != 1 {
+		t.Errorf("expected 1 failure, got %+v", analyzer.Stats())
+	}
+}
+
+func TestScrubSQL(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"SELECT * FROM t WHERE a = 'x' AND b = 42", "SELECT * FROM t WHERE a = ? AND b = ?"},
+		{"SELECT * FROM t WHERE name = 'O''Brien'", "SELECT * FROM t WHERE name = ?"},
+		{"SELECT * FROM t2 WHERE c = $1 AND d > 1.5e3", "SELECT * FROM t2 WHERE c = $1 AND d > ?"},
+		{`SELECT "col1" FROM "table 2" WHERE x = -7`, `SELECT "col1" FROM "table 2" WHERE x = -?`},
+		{"SELECT $$secret$$, $tag$other$tag$", "SELECT ?, ?"},
+		{"SELECT 1 -- token=abc\nFROM t /* user 42 */", "SELECT ? \nFROM t "},
+	}
+	for _, tt := range tests {
+		if got := ScrubSQL(tt.query); got != tt.want {
+			t.Errorf("ScrubSQL(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
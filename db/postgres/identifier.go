@@ -0,0 +1,27 @@
+package postgres
+
+import (
+	"regexp"
+	"strings"
+)
+
+// identifierPattern aceita identificadores sem aspas, opcionalmente
+// qualificados pelo schema
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// ValidIdentifier informa se name é um identificador sem aspas, opcionalmente
+// qualificado pelo schema (ex.: "jobs" ou "public.jobs"). Nomes de tabelas e
+// colunas não podem ser parâmetros da consulta, então devem ser validados
+// antes de compor o SQL
+func ValidIdentifier(name string) bool {
+	return identifierPattern.MatchString(name)
+}
+
+// UnqualifiedName retorna o identificador sem o schema, para compor nomes de
+// índices e constraints (ex.: "public.jobs" vira "jobs")
+func UnqualifiedName(name string) string {
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
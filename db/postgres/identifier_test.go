@@ -0,0 +1,31 @@
+package postgres
+
+import "testing"
+
+func TestValidIdentifier(t *testing.T) {
+	valid := []string{"jobs", "_jobs", "public.jobs", "Jobs_2"}
+	for _, name := range valid {
+		if !ValidIdentifier(name) {
+			t.Errorf("ValidIdentifier(%q) = false, want true", name)
+		}
+	}
+
+	invalid := []string{"", "2jobs", "a.b.c", "jobs;drop", `"jobs"`, "jobs ", "public."}
+	for _, name := range invalid {
+		if ValidIdentifier(name) {
+			t.Errorf("ValidIdentifier(%q) = true, want false", name)
+		}
+	}
+}
+
+func TestUnqualifiedName(t *testing.T) {
+	tests := map[string]string{
+		"jobs":        "jobs",
+		"public.jobs": "jobs",
+	}
+	for name, want := range tests {
+		if got := UnqualifiedName(name); got != want {
+			t.Errorf("UnqualifiedName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package interfaces
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
@@ -14,6 +15,11 @@ type IConn interface {
 	QueryAll(ctx context.Context, dst interface{}, query string, args ...interface{}) error
 	QueryCount(ctx context.Context, query string, args ...interface{}) (int64, error)
 
+	// Streaming operations: percorre o resultado por um cursor, buscando
+	// FetchSize linhas por vez, sem carregar todo o resultado em memória
+	QueryStream(ctx context.Context, query string, args []interface{}, fn func(IRow) error) error
+	QueryStreamWithOptions(ctx context.Context, query string, args []interface{}, fn func(IRow) error, opts StreamOptions) error
+
 	// Execution operations
 	Exec(ctx context.Context, query string, args ...interface{}) (ICommandTag, error)
 
@@ -27,6 +33,9 @@ type IConn interface {
 	// Connection management
 	Release()
 	Close(ctx context.Context) error
+	// Destroy fecha a conexão física em vez de devolvê-la ao pool, para
+	// conexões cujo estado de sessão não pôde ser restaurado
+	Destroy(ctx context.Context) error
 	Ping(ctx context.Context) error
 	IsClosed() bool
 
@@ -203,6 +212,19 @@ const (
 	TxDeferrableModeDeferrable
 )
 
+// ErrStopStream pode ser retornado pela função do QueryStream para encerrar o
+// streaming antes do fim do resultado sem erro
+var ErrStopStream = errors.New("stop stream")
+
+// DefaultStreamFetchSize linhas buscadas por FETCH quando StreamOptions.FetchSize é zero
+const DefaultStreamFetchSize = 1000
+
+// StreamOptions representa opções do streaming de queries
+type StreamOptions struct {
+	// FetchSize linhas buscadas do cursor por vez. Limita a memória usada
+	FetchSize int
+}
+
 // TxOptions representa opções de transação
 type TxOptions struct {
 	IsoLevel       TxIsoLevel
@@ -67,6 +67,8 @@ type IConfig interface {
 	GetHookConfig() HookConfig
 	GetFailoverConfig() FailoverConfig
 	GetReadReplicaConfig() ReadReplicaConfig
+	GetTypeConfig() TypeConfig
+	GetAnnotationConfig() AnnotationConfig
 	IsMultiTenantEnabled() bool
 	Validate() error
 }
@@ -125,6 +127,24 @@ type ReadReplicaConfig struct {
 	HealthCheckInterval time.Duration
 }
 
+// TypeConfig tipos customizados registrados em cada conexão nova. Aceita
+// enums, domains, composites, seus arrays (ex.: "_mood") e hstore. Tipos
+// nativos como arrays e jsonb não precisam ser informados
+type TypeConfig struct {
+	Types []string
+}
+
+// AnnotationConfig identificação das conexões e queries para correlação no
+// pg_stat_activity. Com Comments habilitado as queries recebem um comentário
+// sqlcommenter com as Tags fixas, as tags do contexto (sqlcomment.WithTags) e,
+// com TraceContext, o traceparent do span ativo
+type AnnotationConfig struct {
+	ApplicationName string
+	Comments        bool
+	Tags            map[string]string
+	TraceContext    bool
+}
+
 // LoadBalanceMode modo de balanceamento de carga
 type LoadBalanceMode int
 
@@ -97,6 +97,9 @@ var (
 	WithReadReplicas     = config.WithReadReplicas
 	WithEnabledHooks     = config.WithEnabledHooks
 	WithCustomHook       = config.WithCustomHook
+	WithTypes            = config.WithTypes
+	WithApplicationName  = config.WithApplicationName
+	WithQueryComments    = config.WithQueryComments
 )
 
 // ProviderType re-exporta para facilitar o uso
@@ -135,12 +138,15 @@ type (
 	HookConfig        = interfaces.HookConfig
 	FailoverConfig    = interfaces.FailoverConfig
 	ReadReplicaConfig = interfaces.ReadReplicaConfig
+	TypeConfig        = interfaces.TypeConfig
+	AnnotationConfig  = interfaces.AnnotationConfig
 	ConnectionStats   = interfaces.ConnectionStats
 	PoolStats         = interfaces.PoolStats
 	MemoryStats       = interfaces.MemoryStats
 	RetryStats        = interfaces.RetryStats
 	FailoverStats     = interfaces.FailoverStats
 	TxOptions         = interfaces.TxOptions
+	StreamOptions     = interfaces.StreamOptions
 	ExecutionContext  = interfaces.ExecutionContext
 	HookResult        = interfaces.HookResult
 	Notification      = interfaces.Notification
@@ -200,6 +206,12 @@ const (
 	CustomHookBase        = interfaces.CustomHookBase
 )
 
+// ErrStopStream encerra o QueryStream sem erro
+var ErrStopStream = interfaces.ErrStopStream
+
+// DefaultStreamFetchSize linhas buscadas por FETCH no QueryStream
+const DefaultStreamFetchSize = interfaces.DefaultStreamFetchSize
+
 // Funções de conveniência
 
 // Connect cria uma conexão simples usando configuração padrão
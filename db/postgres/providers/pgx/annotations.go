@@ -0,0 +1,65 @@
+package pgxprovider
+
+import (
+	"context"
+
+	"github.com/fsvxavier/nexs-lib/db/postgres/interfaces"
+	"github.com/fsvxavier/nexs-lib/db/postgres/sqlcomment"
+	"github.com/jackc/pgx/v5"
+)
+
+// applicationNameParam parâmetro de runtime exibido no pg_stat_activity
+const applicationNameParam = "application_name"
+
+// applyApplicationName define o application_name configurado nos parâmetros
+// de runtime da conexão, sobrescrevendo o da string de conexão
+func applyApplicationName(params map[string]string, config interfaces.IConfig) {
+	if name := config.GetAnnotationConfig().ApplicationName; name != "" {
+		params[applicationNameParam] = name
+	}
+}
+
+// annotate anexa à query o comentário sqlcommenter configurado. Com o
+// traceparent cada execução tem um SQL diferente, então a query é executada
+// com QueryExecModeDescribeExec para não encher o cache de prepared statements
+func annotate(ctx context.Context, config interfaces.IConfig, query string, args []interface{}) (string, []interface{}) {
+	if config == nil {
+		return query, args
+	}
+	cfg := config.GetAnnotationConfig()
+	if !cfg.Comments {
+		return query, args
+	}
+
+	ctxTags := sqlcomment.Tags(ctx)
+	tags := make(map[string]string, len(cfg.Tags)+len(ctxTags)+1)
+	for k, v := range cfg.Tags {
+		tags[k] = v
+	}
+	for k, v := range ctxTags {
+		tags[k] = v
+	}
+
+	perExecution := false
+	if cfg.TraceContext {
+		if traceparent := sqlcomment.Traceparent(ctx); traceparent != "" {
+			tags[sqlcomment.KeyTraceparent] = traceparent
+			perExecution = true
+		}
+	}
+
+	annotated := sqlcomment.Append(query, tags)
+	if perExecution && annotated != query && !hasExecMode(args) {
+		args = append([]interface{}{pgx.QueryExecModeDescribeExec}, args...)
+	}
+	return annotated, args
+}
+
+// hasExecMode indica se o chamador já escolheu o modo de execução do pgx
+func hasExecMode(args []interface{}) bool {
+	if len(args) == 0 {
+		return false
+	}
+	_, ok := args[0].(pgx.QueryExecMode)
+	return ok
+}
@@ -0,0 +1,70 @@
+package pgxprovider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fsvxavier/nexs-lib/db/postgres/config"
+	"github.com/fsvxavier/nexs-lib/db/postgres/sqlcomment"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestAnnotate(t *testing.T) {
+	cfg := config.NewDefaultConfig("postgres://localhost/app").(*config.DefaultConfig)
+
+	// Sem comentários habilitados a query não muda
+	query, args := annotate(context.Background(), cfg, "SELECT 1", nil)
+	if query != "SELECT 1" || args != nil {
+		t.Errorf("Expected the query unchanged, got %q %v", query, args)
+	}
+
+	cfg.Apply(config.WithQueryComments(map[string]string{sqlcomment.KeyService: "orders-api"}, true))
+
+	ctx := sqlcomment.WithTags(context.Background(), sqlcomment.KeyRoute, "/orders")
+	query, args = annotate(ctx, cfg, "SELECT * FROM orders WHERE id = $1", []interface{}{42})
+	if query != "SELECT * FROM orders WHERE id = $1 /*route='%2Forders',service='orders-api'*/" {
+		t.Errorf("Unexpected annotated query %q", query)
+	}
+	if len(args) != 1 {
+		t.Errorf("Expected the args unchanged without a span, got %v", args)
+	}
+
+	// Com o traceparent o SQL muda a cada execução e o cache é evitado
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{2},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx = trace.ContextWithSpanContext(ctx, sc)
+	query, args = annotate(ctx, cfg, "SELECT * FROM orders WHERE id = $1", []interface{}{42})
+	if query != "SELECT * FROM orders WHERE id = $1 /*route='%2Forders',service='orders-api',traceparent='"+
+		sqlcomment.Traceparent(ctx)+"'*/" {
+		t.Errorf("Unexpected annotated query %q", query)
+	}
+	if len(args) != 2 || args[0] != pgx.QueryExecModeDescribeExec || args[1] != 42 {
+		t.Errorf("Expected the describe exec mode, got %v", args)
+	}
+
+	// O modo escolhido pelo chamador é mantido
+	_, args = annotate(ctx, cfg, "SELECT 1", []interface{}{pgx.QueryExecModeSimpleProtocol})
+	if len(args) != 1 || args[0] != pgx.QueryExecModeSimpleProtocol {
+		t.Errorf("Expected the caller exec mode, got %v", args)
+	}
+}
+
+func TestApplyApplicationName(t *testing.T) {
+	params := map[string]string{"application_name": "from-dsn"}
+	cfg := config.NewDefaultConfig("postgres://localhost/app").(*config.DefaultConfig)
+
+	applyApplicationName(params, cfg)
+	if params["application_name"] != "from-dsn" {
+		t.Errorf("Expected the connection string value, got %q", params["application_name"])
+	}
+
+	cfg.Apply(config.WithApplicationName("billing-worker"))
+	applyApplicationName(params, cfg)
+	if params["application_name"] != "billing-worker" {
+		t.Errorf("Expected the configured name, got %q", params["application_name"])
+	}
+}
@@ -0,0 +1,93 @@
+package pgxprovider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/fsvxavier/nexs-lib/db/postgres/interfaces"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Tipos nativos já suportados pelo pgx no scan:
+//   - arrays (int[], text[], uuid[]...) para []T
+//   - json/jsonb para structs, maps e slices via json.Unmarshal
+//   - enums para tipos string do Go (type Status string)
+//
+// Enums, domains e composites têm OID dinâmico e precisam ser carregados
+// do catálogo em cada conexão para que seus arrays e valores binários sejam
+// decodificados. O hstore não é carregado por LoadTypes e é registrado à parte
+
+const (
+	hstoreTypeName      = "hstore"
+	hstoreArrayTypeName = "_hstore"
+)
+
+// registerTypes registra na conexão os codecs dos tipos do TypeConfig
+func registerTypes(ctx context.Context, conn *pgx.Conn, cfg interfaces.TypeConfig) error {
+	if len(cfg.Types) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(cfg.Types))
+	hstore := false
+	for _, name := range cfg.Types {
+		if isHstore(name) {
+			hstore = true
+			continue
+		}
+		names = append(names, name)
+	}
+
+	if hstore {
+		if err := registerHstore(ctx, conn); err != nil {
+			return err
+		}
+	}
+
+	if len(names) == 0 {
+		return nil
+	}
+
+	types, err := conn.LoadTypes(ctx, names)
+	if err != nil {
+		return fmt.Errorf("failed to load custom types %v: %w", names, err)
+	}
+	conn.TypeMap().RegisterTypes(types)
+
+	return nil
+}
+
+// registerHstore busca os OIDs do hstore, criados pela extensão, e registra
+// os codecs do tipo e do seu array
+func registerHstore(ctx context.Context, conn *pgx.Conn) error {
+	var oid, arrayOID uint32
+	err := conn.QueryRow(ctx, "SELECT 'hstore'::regtype::oid, '_hstore'::regtype::oid").Scan(&oid, &arrayOID)
+	if err != nil {
+		return fmt.Errorf("failed to load hstore type (is the extension installed?): %w", err)
+	}
+
+	registerHstoreCodec(conn.TypeMap(), oid, arrayOID)
+	return nil
+}
+
+// registerHstoreCodec registra o hstore, que é decodificado para
+// map[string]string ou pgtype.Hstore quando há valores NULL
+func registerHstoreCodec(m *pgtype.Map, oid, arrayOID uint32) {
+	hstore := &pgtype.Type{Name: hstoreTypeName, OID: oid, Codec: pgtype.HstoreCodec{}}
+	m.RegisterType(hstore)
+	m.RegisterType(&pgtype.Type{
+		Name:  hstoreArrayTypeName,
+		OID:   arrayOID,
+		Codec: &pgtype.ArrayCodec{ElementType: hstore},
+	})
+}
+
+// isHstore indica se o nome, com ou sem schema, é o hstore ou seu array
+func isHstore(name string) bool {
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+	return name == hstoreTypeName || name == hstoreArrayTypeName
+}
@@ -0,0 +1,125 @@
+package pgxprovider
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type mood string
+
+type profile struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+// OIDs fictícios, como os atribuídos pelo servidor a tipos customizados
+const (
+	testHstoreOID    = 90001
+	testHstoreArrOID = 90002
+	testMoodOID      = 90003
+	testMoodArrOID   = 90004
+)
+
+func newTestTypeMap() *pgtype.Map {
+	m := pgtype.NewMap()
+	registerHstoreCodec(m, testHstoreOID, testHstoreArrOID)
+
+	enum := &pgtype.Type{Name: "mood", OID: testMoodOID, Codec: &pgtype.EnumCodec{}}
+	m.RegisterType(enum)
+	m.RegisterType(&pgtype.Type{Name: "_mood", OID: testMoodArrOID, Codec: &pgtype.ArrayCodec{ElementType: enum}})
+	return m
+}
+
+func TestCodecs_Hstore(t *testing.T) {
+	m := newTestTypeMap()
+
+	var got map[string]string
+	src := []byte(`"color"=>"blue", "size"=>"x l"`)
+	if err := m.Scan(testHstoreOID, pgtype.TextFormatCode, src, &got); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if len(got) != 2 || got["color"] != "blue" || got["size"] != "x l" {
+		t.Errorf("Unexpected hstore %v", got)
+	}
+
+	// Ida e volta no formato binário
+	buf, err := m.Encode(testHstoreOID, pgtype.BinaryFormatCode, map[string]string{"a": "1"}, nil)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	got = nil
+	if err := m.Scan(testHstoreOID, pgtype.BinaryFormatCode, buf, &got); err != nil || got["a"] != "1" {
+		t.Errorf("Unexpected binary round trip %v: %v", got, err)
+	}
+
+	// Valores NULL exigem pgtype.Hstore
+	var withNull pgtype.Hstore
+	if err := m.Scan(testHstoreOID, pgtype.TextFormatCode, []byte(`"a"=>NULL`), &withNull); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if v, ok := withNull["a"]; !ok || v != nil {
+		t.Errorf("Expected a NULL value, got %v", withNull)
+	}
+
+	var arr []map[string]string
+	if err := m.Scan(testHstoreArrOID, pgtype.TextFormatCode, []byte(`{"\"a\"=>\"1\""}`), &arr); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if len(arr) != 1 || arr[0]["a"] != "1" {
+		t.Errorf("Unexpected hstore array %v", arr)
+	}
+}
+
+func TestCodecs_Enum(t *testing.T) {
+	m := newTestTypeMap()
+
+	var single mood
+	if err := m.Scan(testMoodOID, pgtype.TextFormatCode, []byte("happy"), &single); err != nil || single != "happy" {
+		t.Errorf("Unexpected enum %q: %v", single, err)
+	}
+
+	var list []mood
+	if err := m.Scan(testMoodArrOID, pgtype.TextFormatCode, []byte("{happy,sad}"), &list); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if len(list) != 2 || list[0] != "happy" || list[1] != "sad" {
+		t.Errorf("Unexpected enum array %v", list)
+	}
+}
+
+func TestCodecs_BuiltinTypes(t *testing.T) {
+	m := pgtype.NewMap()
+
+	var p profile
+	src := []byte(`{"name":"john","tags":["admin","ops"]}`)
+	if err := m.Scan(pgtype.JSONBOID, pgtype.TextFormatCode, src, &p); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if p.Name != "john" || len(p.Tags) != 2 {
+		t.Errorf("Unexpected profile %+v", p)
+	}
+
+	var ids []int64
+	if err := m.Scan(pgtype.Int8ArrayOID, pgtype.TextFormatCode, []byte("{1,2,3}"), &ids); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if len(ids) != 3 || ids[2] != 3 {
+		t.Errorf("Unexpected array %v", ids)
+	}
+}
+
+func TestIsHstore(t *testing.T) {
+	tests := map[string]bool{
+		"hstore":        true,
+		"_hstore":       true,
+		"public.hstore": true,
+		"mood":          false,
+		"hstore_like":   false,
+	}
+	for name, want := range tests {
+		if got := isHstore(name); got != want {
+			t.Errorf("isHstore(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
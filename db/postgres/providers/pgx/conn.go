@@ -88,12 +88,19 @@ func NewConn(ctx context.Context, config interfaces.IConfig,
 		return nil, err
 	}
 
+	applyApplicationName(pgxConfig.RuntimeParams, config)
+
 	// Criar conexão PGX
 	pgxConn, err := pgx.ConnectConfig(ctx, pgxConfig)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := registerTypes(ctx, pgxConn, config.GetTypeConfig()); err != nil {
+		pgxConn.Close(ctx)
+		return nil, err
+	}
+
 	// Criar wrapper para conexão direta (não do pool)
 	conn := &Conn{
 		conn:        pgxConn,
@@ -136,6 +143,7 @@ func (c *Conn) QueryRow(ctx context.Context, query string, args ...interface{})
 		}()
 	}
 
+	query, args = annotate(ctx, c.config, query, args)
 	row := c.getConn().QueryRow(ctx, query, args...)
 	return &Row{row: row}
 }
@@ -167,6 +175,7 @@ func (c *Conn) Query(ctx context.Context, query string, args ...interface{}) (in
 		}()
 	}
 
+	query, args = annotate(ctx, c.config, query, args)
 	rows, err := c.getConn().Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
@@ -246,6 +255,7 @@ func (c *Conn) Exec(ctx context.Context, query string, args ...interface{}) (int
 		}()
 	}
 
+	query, args = annotate(ctx, c.config, query, args)
 	cmdTag, err := c.getConn().Exec(ctx, query, args...)
 	if err != nil {
 		return nil, err
@@ -345,6 +355,29 @@ func (c *Conn) Close(ctx context.Context) error {
 	return err
 }
 
+// Destroy fecha a conexão física. Conexões do pool são retiradas dele
+// com Hijack, para que nenhum outro Acquire as receba
+func (c *Conn) Destroy(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil
+	}
+
+	var err error
+	switch conn := c.conn.(type) {
+	case *pgx.Conn:
+		err = conn.Close(ctx)
+	case *pgxpool.Conn:
+		err = conn.Hijack().Close(ctx)
+	}
+
+	c.closed = true
+	c.acquired = false
+	return err
+}
+
 // Ping verifica conectividade
 func (c *Conn) Ping(ctx context.Context) error {
 	c.mu.RLock()
@@ -429,6 +462,7 @@ func (c *Conn) CopyTo(ctx context.Context, w interfaces.ICopyToWriter, query str
 	}
 
 	// Para pgx.Conn, não há CopyTo direto. Vamos implementar uma versão básica
+	query, args = annotate(ctx, c.config, query, args)
 	rows, err := c.getConn().Query(ctx, query, args...)
 	if err != nil {
 		return err
@@ -264,6 +264,14 @@ func (rc *ReplicaConn) Release() {
 	rc.IConn.Release()
 }
 
+// Destroy fecha a conexão e decrementa contador
+func (rc *ReplicaConn) Destroy(ctx context.Context) error {
+	if rc.replicaInfo != nil {
+		rc.replicaInfo.DecrementConnections()
+	}
+	return rc.IConn.Destroy(ctx)
+}
+
 // ReplicaPoolBuilder para construir pools de réplicas
 type ReplicaPoolBuilder struct {
 	primaryPool          interfaces.IPool
@@ -95,6 +95,11 @@ func (m *MockConn) Close(ctx context.Context) error {
 
 func (m *MockConn) Release() {}
 
+func (m *MockConn) Destroy(ctx context.Context) error {
+	m.closed = true
+	return nil
+}
+
 func (m *MockConn) Begin(ctx context.Context) (interfaces.ITransaction, error) {
 	return nil, fmt.Errorf("not implemented")
 }
@@ -171,6 +176,14 @@ func (m *MockConn) QueryCount(ctx context.Context, query string, args ...interfa
 	return 0, fmt.Errorf("not implemented")
 }
 
+func (m *MockConn) QueryStream(ctx context.Context, query string, args []interface{}, fn func(interfaces.IRow) error) error {
+	return fmt.Errorf("not implemented")
+}
+
+func (m *MockConn) QueryStreamWithOptions(ctx context.Context, query string, args []interface{}, fn func(interfaces.IRow) error, opts interfaces.StreamOptions) error {
+	return fmt.Errorf("not implemented")
+}
+
 func (m *MockConn) QueryOne(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
 	return fmt.Errorf("not implemented")
 }
@@ -9,6 +9,7 @@ import (
 
 	"github.com/fsvxavier/nexs-lib/db/postgres/interfaces"
 	"github.com/fsvxavier/nexs-lib/db/postgres/providers/pgx/internal/monitoring"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -110,6 +111,14 @@ func NewPool(ctx context.Context, config interfaces.IConfig,
 
 	// Configurações avançadas
 	pgxConfig.MaxConnLifetimeJitter = time.Duration(float64(poolConfig.MaxConnLifetime) * 0.1) // 10% jitter
+	applyApplicationName(pgxConfig.ConnConfig.RuntimeParams, config)
+
+	// Registrar tipos customizados em cada conexão criada pelo pool
+	if typeConfig := config.GetTypeConfig(); len(typeConfig.Types) > 0 {
+		pgxConfig.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+			return registerTypes(ctx, conn, typeConfig)
+		}
+	}
 
 	// Criar pool PGX
 	pgxPool, err := pgxpool.NewWithConfig(ctx, pgxConfig)
@@ -0,0 +1,156 @@
+package pgxprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/db/postgres/interfaces"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// cursorSeq gera nomes únicos para os cursores de streaming
+var cursorSeq atomic.Uint64
+
+// cursorQuerier operações usadas pelo streaming, comuns a pgx.Tx e às conexões
+type cursorQuerier interface {
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+}
+
+// QueryStream percorre o resultado da query linha a linha com o fetch size padrão
+func (c *Conn) QueryStream(ctx context.Context, query string, args []interface{}, fn func(interfaces.IRow) error) error {
+	return c.QueryStreamWithOptions(ctx, query, args, fn, interfaces.StreamOptions{})
+}
+
+// QueryStreamWithOptions percorre o resultado da query por um cursor. Como
+// cursores só existem dentro de transações, a query roda em uma transação
+// própria, confirmada ao fim do streaming
+func (c *Conn) QueryStreamWithOptions(ctx context.Context, query string, args []interface{}, fn func(interfaces.IRow) error, opts interfaces.StreamOptions) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return ErrConnClosed
+	}
+
+	// Executar hook de query
+	if c.hookManager != nil {
+		execCtx := &interfaces.ExecutionContext{
+			Context:   ctx,
+			Operation: "query_stream",
+			Query:     query,
+			Args:      args,
+			StartTime: time.Now(),
+		}
+		if err := c.hookManager.ExecuteHooks(interfaces.BeforeQueryHook, execCtx); err != nil {
+			return err
+		}
+		defer func() {
+			execCtx.Duration = time.Since(execCtx.StartTime)
+			c.hookManager.ExecuteHooks(interfaces.AfterQueryHook, execCtx)
+		}()
+	}
+
+	query, args = annotate(ctx, c.config, query, args)
+	tx, err := c.getConn().BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to begin stream transaction: %w", err)
+	}
+
+	if err := streamCursor(ctx, tx, query, args, fn, opts); err != nil {
+		tx.Rollback(ctx)
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// QueryStream implementa ITransaction.QueryStream
+func (t *Transaction) QueryStream(ctx context.Context, query string, args []interface{}, fn func(interfaces.IRow) error) error {
+	return t.QueryStreamWithOptions(ctx, query, args, fn, interfaces.StreamOptions{})
+}
+
+// QueryStreamWithOptions implementa ITransaction.QueryStreamWithOptions
+// usando um cursor na própria transação
+func (t *Transaction) QueryStreamWithOptions(ctx context.Context, query string, args []interface{}, fn func(interfaces.IRow) error, opts interfaces.StreamOptions) error {
+	// Executar hook de query
+	if t.hookManager != nil {
+		execCtx := &interfaces.ExecutionContext{
+			Context:   ctx,
+			Operation: "tx_query_stream",
+			Query:     query,
+			Args:      args,
+			StartTime: time.Now(),
+		}
+		if err := t.hookManager.ExecuteHooks(interfaces.BeforeQueryHook, execCtx); err != nil {
+			return err
+		}
+		defer func() {
+			execCtx.Duration = time.Since(execCtx.StartTime)
+			t.hookManager.ExecuteHooks(interfaces.AfterQueryHook, execCtx)
+		}()
+	}
+
+	query, args = annotate(ctx, t.config, query, args)
+	return streamCursor(ctx, t.tx, query, args, fn, opts)
+}
+
+// streamCursor declara um cursor para a query e busca FetchSize linhas por
+// vez, chamando fn para cada linha. Apenas um lote fica em memória
+func streamCursor(ctx context.Context, q cursorQuerier, query string, args []interface{}, fn func(interfaces.IRow) error, opts interfaces.StreamOptions) error {
+	fetchSize := opts.FetchSize
+	if fetchSize <= 0 {
+		fetchSize = interfaces.DefaultStreamFetchSize
+	}
+
+	name := "nexs_stream_" + strconv.FormatUint(cursorSeq.Add(1), 10)
+	if _, err := q.Exec(ctx, "DECLARE "+name+" NO SCROLL CURSOR FOR "+query, args...); err != nil {
+		return fmt.Errorf("failed to declare cursor: %w", err)
+	}
+
+	fetch := "FETCH FORWARD " + strconv.Itoa(fetchSize) + " FROM " + name
+	for {
+		fetched, err := fetchBatch(ctx, q, fetch, fn)
+		if errors.Is(err, interfaces.ErrStopStream) {
+			break
+		}
+		if err != nil {
+			// Melhor esforço: a transação pode ter sido abortada pelo erro
+			q.Exec(ctx, "CLOSE "+name)
+			return err
+		}
+		if fetched < fetchSize {
+			break
+		}
+	}
+
+	if _, err := q.Exec(ctx, "CLOSE "+name); err != nil {
+		return fmt.Errorf("failed to close cursor: %w", err)
+	}
+	return nil
+}
+
+// fetchBatch busca um lote do cursor e retorna quantas linhas foram lidas
+func fetchBatch(ctx context.Context, q cursorQuerier, fetch string, fn func(interfaces.IRow) error) (int, error) {
+	rows, err := q.Query(ctx, fetch)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch from cursor: %w", err)
+	}
+	defer rows.Close()
+
+	row := &Row{row: rows}
+	fetched := 0
+	for rows.Next() {
+		fetched++
+		if err := fn(row); err != nil {
+			return fetched, err
+		}
+	}
+
+	return fetched, rows.Err()
+}
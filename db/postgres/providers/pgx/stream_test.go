@@ -0,0 +1,141 @@
+package pgxprovider
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/fsvxavier/nexs-lib/db/postgres/interfaces"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakeCursor simula um cursor sobre os inteiros 1..total
+type fakeCursor struct {
+	total      int
+	position   int
+	statements []string
+	fetchErr   error
+	maxBatch   int
+}
+
+func (f *fakeCursor) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	f.statements = append(f.statements, sql)
+	return pgconn.CommandTag{}, nil
+}
+
+func (f *fakeCursor) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	f.statements = append(f.statements, sql)
+	if f.fetchErr != nil {
+		return nil, f.fetchErr
+	}
+
+	size, err := strconv.Atoi(strings.Fields(sql)[2])
+	if err != nil {
+		return nil, err
+	}
+	rows := &fakeRows{}
+	for len(rows.values) < size && f.position < f.total {
+		f.position++
+		rows.values = append(rows.values, f.position)
+	}
+	if len(rows.values) > f.maxBatch {
+		f.maxBatch = len(rows.values)
+	}
+	return rows, nil
+}
+
+type fakeRows struct {
+	pgx.Rows
+	values []int
+	index  int
+}
+
+func (r *fakeRows) Next() bool {
+	r.index++
+	return r.index <= len(r.values)
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	*dest[0].(*int) = r.values[r.index-1]
+	return nil
+}
+
+func (r *fakeRows) Close()     {}
+func (r *fakeRows) Err() error { return nil }
+
+func TestStreamCursor_FetchesInBatches(t *testing.T) {
+	cursor := &fakeCursor{total: 25}
+	sum := 0
+	err := streamCursor(context.Background(), cursor, "SELECT id FROM events WHERE kind = $1", []interface{}{"click"},
+		func(row interfaces.IRow) error {
+			var id int
+			if err := row.Scan(&id); err != nil {
+				return err
+			}
+			sum += id
+			return nil
+		}, interfaces.StreamOptions{FetchSize: 10})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if sum != 325 {
+		t.Errorf("Expected every row to be visited, got sum %d", sum)
+	}
+	if cursor.maxBatch != 10 {
+		t.Errorf("Expected batches of at most 10 rows, got %d", cursor.maxBatch)
+	}
+
+	name := strings.Fields(cursor.statements[0])[1]
+	want := []string{
+		"DECLARE " + name + " NO SCROLL CURSOR FOR SELECT id FROM events WHERE kind = $1",
+		"FETCH FORWARD 10 FROM " + name,
+		"FETCH FORWARD 10 FROM " + name,
+		"FETCH FORWARD 10 FROM " + name,
+		"CLOSE " + name,
+	}
+	if strings.Join(cursor.statements, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected statements:\n%s", strings.Join(cursor.statements, "\n"))
+	}
+}
+
+func TestStreamCursor_StopAndErrors(t *testing.T) {
+	// ErrStopStream encerra sem erro e fecha o cursor
+	cursor := &fakeCursor{total: 5000}
+	visited := 0
+	err := streamCursor(context.Background(), cursor, "SELECT id FROM events", nil,
+		func(row interfaces.IRow) error {
+			visited++
+			if visited == 3 {
+				return interfaces.ErrStopStream
+			}
+			return nil
+		}, interfaces.StreamOptions{})
+	if err != nil || visited != 3 {
+		t.Errorf("Expected the stream to stop after 3 rows, got %d rows: %v", visited, err)
+	}
+	if cursor.maxBatch != interfaces.DefaultStreamFetchSize {
+		t.Errorf("Expected the default fetch size, got %d", cursor.maxBatch)
+	}
+	if last := cursor.statements[len(cursor.statements)-1]; !strings.HasPrefix(last, "CLOSE ") {
+		t.Errorf("Expected the cursor to be closed, got %q", last)
+	}
+
+	// Erros da função são devolvidos
+	fnErr := errors.New("boom")
+	err = streamCursor(context.Background(), &fakeCursor{total: 5}, "SELECT 1", nil,
+		func(row interfaces.IRow) error { return fnErr }, interfaces.StreamOptions{})
+	if !errors.Is(err, fnErr) {
+		t.Errorf("Expected the callback error, got %v", err)
+	}
+
+	// Erros do FETCH são devolvidos com contexto
+	err = streamCursor(context.Background(), &fakeCursor{fetchErr: errors.New("canceled")}, "SELECT 1", nil,
+		func(row interfaces.IRow) error { return nil }, interfaces.StreamOptions{})
+	if err == nil || !strings.Contains(err.Error(), "failed to fetch from cursor") {
+		t.Errorf("Expected a fetch error, got %v", err)
+	}
+}
@@ -207,6 +207,7 @@ func (t *Transaction) QueryRow(ctx context.Context, query string, args ...interf
 		}()
 	}
 
+	query, args = annotate(ctx, t.config, query, args)
 	row := t.tx.QueryRow(ctx, query, args...)
 	return &Row{row: row}
 }
@@ -231,6 +232,7 @@ func (t *Transaction) Query(ctx context.Context, query string, args ...interface
 		}()
 	}
 
+	query, args = annotate(ctx, t.config, query, args)
 	rows, err := t.tx.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
@@ -285,6 +287,7 @@ func (t *Transaction) Exec(ctx context.Context, query string, args ...interface{
 		}()
 	}
 
+	query, args = annotate(ctx, t.config, query, args)
 	cmdTag, err := t.tx.Exec(ctx, query, args...)
 	if err != nil {
 		return nil, err
@@ -367,6 +370,12 @@ func (t *Transaction) Close(ctx context.Context) error {
 	return t.Rollback(ctx)
 }
 
+// Destroy implementa ITransaction.Destroy (não aplicável para transações,
+// que apenas são desfeitas)
+func (t *Transaction) Destroy(ctx context.Context) error {
+	return t.Rollback(ctx)
+}
+
 // Ping implementa ITransaction.Ping (não aplicável para transações)
 func (t *Transaction) Ping(ctx context.Context) error {
 	return errors.New("ping not available in transaction")
@@ -403,6 +412,7 @@ func (t *Transaction) CopyFrom(ctx context.Context, tableName string, columnName
 // CopyTo implementa ITransaction.CopyTo
 func (t *Transaction) CopyTo(ctx context.Context, w interfaces.ICopyToWriter, query string, args ...interface{}) error {
 	// Para pgx.Tx, não há CopyTo direto. Vamos implementar uma versão básica
+	query, args = annotate(ctx, t.config, query, args)
 	rows, err := t.tx.Query(ctx, query, args...)
 	if err != nil {
 		return err
@@ -0,0 +1,35 @@
+package sharding
+
+import (
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+// Códigos dos erros de domínio do pacote
+const (
+	// CodeInvalidShardMap mapa de shards inválido (ConfigurationError)
+	CodeInvalidShardMap = "INVALID_SHARD_MAP"
+	// CodeShardKeyRequired chave de shard ausente (ValidationError)
+	CodeShardKeyRequired = "SHARD_KEY_REQUIRED"
+	// CodeInvalidKey chave de shard incompatível com o mapa (ValidationError)
+	CodeInvalidKey = "INVALID_SHARD_KEY"
+	// CodeShardUnavailable shard do mapa sem pool (ServiceUnavailableError)
+	CodeShardUnavailable = "SHARD_UNAVAILABLE"
+	// CodePartialFailure parte dos shards falhou no scatter-gather (DatabaseError)
+	CodePartialFailure = "SHARD_PARTIAL_FAILURE"
+	// CodeScatterFailed todos os shards falharam no scatter-gather (DatabaseError)
+	CodeScatterFailed = "SHARD_SCATTER_FAILED"
+)
+
+func invalidMap(message string) error {
+	return domainerrors.New(interfaces.ConfigurationError, CodeInvalidShardMap, message)
+}
+
+func missingKey() error {
+	return domainerrors.New(interfaces.ValidationError, CodeShardKeyRequired, "shard key is required")
+}
+
+func unavailable(shard string) error {
+	return domainerrors.NewWithMetadata(interfaces.ServiceUnavailableError, CodeShardUnavailable,
+		"shard has no connection pool", map[string]interface{}{"shard": shard})
+}
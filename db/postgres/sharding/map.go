@@ -0,0 +1,301 @@
+package sharding
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+// Estratégias de distribuição das chaves
+const (
+	// StrategyHash distribui as chaves por hash em slots, e os slots em
+	// faixas por shard. Rebalancear é mover faixas de slots entre shards,
+	// sem recalcular o hash das chaves
+	StrategyHash = "hash"
+	// StrategyRange distribui as chaves por faixas ordenadas, como IDs
+	// sequenciais ou prefixos de região
+	StrategyRange = "range"
+)
+
+// DefaultSlots número padrão de slots da estratégia hash. Deve ser maior que
+// o número máximo de shards previsto: os slots não podem mudar depois que
+// os dados foram distribuídos
+const DefaultSlots = 1024
+
+// MapConfig é a configuração do mapa de shards, carregável de arquivos
+// JSON, do pacote config ou de variáveis de ambiente
+type MapConfig struct {
+	// Version identifica a versão do mapa, para auditoria dos rebalanceamentos
+	Version int64 `json:"version" yaml:"version"`
+	// Strategy é StrategyHash ou StrategyRange
+	Strategy string `json:"strategy" yaml:"strategy"`
+	// Slots é o número de slots da estratégia hash; zero usa DefaultSlots
+	Slots int `json:"slots,omitempty" yaml:"slots,omitempty"`
+	// Numeric compara as chaves e os limites das faixas como inteiros na
+	// estratégia range, em vez de strings
+	Numeric bool          `json:"numeric,omitempty" yaml:"numeric,omitempty"`
+	Shards  []ShardConfig `json:"shards" yaml:"shards"`
+}
+
+// ShardConfig é a configuração de um shard
+type ShardConfig struct {
+	ID string `json:"id" yaml:"id"`
+	// DSN é a connection string do shard, usada por ConnectPools
+	DSN string `json:"dsn,omitempty" yaml:"dsn,omitempty"`
+	// Slots são as faixas de slots do shard na estratégia hash, como
+	// "0-511" ou "512"
+	Slots []string `json:"slots,omitempty" yaml:"slots,omitempty"`
+	// From é o limite inferior, inclusivo, da faixa do shard na estratégia
+	// range; vazio é o início. A faixa vai até o From seguinte
+	From string `json:"from,omitempty" yaml:"from,omitempty"`
+}
+
+// Map é o mapa imutável de chaves para shards
+type Map struct {
+	version  int64
+	strategy string
+	numeric  bool
+	shards   []string
+	// hash: shard de cada slot
+	slots []string
+	// range: faixas ordenadas pelo limite inferior
+	ranges []keyRange
+}
+
+// keyRange é a faixa de um shard na estratégia range
+type keyRange struct {
+	from    string
+	fromInt int64
+	open    bool // sem limite inferior
+	shard   string
+}
+
+// NewMap valida a configuração e cria o mapa. Na estratégia hash todos os
+// slots devem pertencer a exatamente um shard; na range os limites devem
+// ser únicos e um shard deve começar no início
+func NewMap(cfg MapConfig) (*Map, error) {
+	m := &Map{version: cfg.Version, strategy: cfg.Strategy, numeric: cfg.Numeric}
+	if len(cfg.Shards) == 0 {
+		return nil, invalidMap("no shards configured")
+	}
+	for _, shard := range cfg.Shards {
+		if shard.ID == "" {
+			return nil, invalidMap("shard id is required")
+		}
+		if slices.Contains(m.shards, shard.ID) {
+			return nil, invalidMap(fmt.Sprintf("duplicate shard %q", shard.ID))
+		}
+		m.shards = append(m.shards, shard.ID)
+	}
+
+	switch cfg.Strategy {
+	case StrategyHash:
+		return m, m.buildSlots(cfg)
+	case StrategyRange:
+		return m, m.buildRanges(cfg)
+	default:
+		return nil, invalidMap(fmt.Sprintf("unsupported strategy %q", cfg.Strategy))
+	}
+}
+
+// buildSlots atribui os slots aos shards
+func (m *Map) buildSlots(cfg MapConfig) error {
+	n := cfg.Slots
+	if n <= 0 {
+		n = DefaultSlots
+	}
+	m.slots = make([]string, n)
+	for _, shard := range cfg.Shards {
+		for _, spec := range shard.Slots {
+			first, last, err := parseSlots(spec)
+			if err != nil || last >= n {
+				return invalidMap(fmt.Sprintf("shard %q: invalid slots %q for %d slots", shard.ID, spec, n))
+			}
+			for slot := first; slot <= last; slot++ {
+				if owner := m.slots[slot]; owner != "" {
+					return invalidMap(fmt.Sprintf("slot %d assigned to shards %q and %q", slot, owner, shard.ID))
+				}
+				m.slots[slot] = shard.ID
+			}
+		}
+	}
+	for slot, owner := range m.slots {
+		if owner == "" {
+			return invalidMap(fmt.Sprintf("slot %d is not assigned to any shard", slot))
+		}
+	}
+	return nil
+}
+
+// parseSlots interpreta "first-last" ou "slot"
+func parseSlots(spec string) (int, int, error) {
+	firstSpec, lastSpec, isRange := strings.Cut(strings.TrimSpace(spec), "-")
+	first, err := strconv.Atoi(strings.TrimSpace(firstSpec))
+	if err != nil {
+		return 0, 0, err
+	}
+	last := first
+	if isRange {
+		if last, err = strconv.Atoi(strings.TrimSpace(lastSpec)); err != nil {
+			return 0, 0, err
+		}
+	}
+	if first < 0 || last < first {
+		return 0, 0, fmt.Errorf("invalid slot range %q", spec)
+	}
+	return first, last, nil
+}
+
+// buildRanges ordena as faixas dos shards
+func (m *Map) buildRanges(cfg MapConfig) error {
+	for _, shard := range cfg.Shards {
+		r := keyRange{from: shard.From, open: shard.From == "", shard: shard.ID}
+		if m.numeric && !r.open {
+			n, err := strconv.ParseInt(shard.From, 10, 64)
+			if err != nil {
+				return invalidMap(fmt.Sprintf("shard %q: from %q is not an integer", shard.ID, shard.From))
+			}
+			r.fromInt = n
+		}
+		m.ranges = append(m.ranges, r)
+	}
+	slices.SortFunc(m.ranges, m.compareRanges)
+	if !m.ranges[0].open {
+		return invalidMap("a shard must start the key space with an empty from")
+	}
+	for i := 1; i < len(m.ranges); i++ {
+		if m.compareRanges(m.ranges[i-1], m.ranges[i]) == 0 {
+			return invalidMap(fmt.Sprintf("shards %q and %q start at the same key", m.ranges[i-1].shard, m.ranges[i].shard))
+		}
+	}
+	return nil
+}
+
+func (m *Map) compareRanges(a, b keyRange) int {
+	switch {
+	case a.open && b.open:
+		return 0
+	case a.open:
+		return -1
+	case b.open:
+		return 1
+	case m.numeric:
+		return compareInt(a.fromInt, b.fromInt)
+	}
+	return strings.Compare(a.from, b.from)
+}
+
+func compareInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// ParseMap cria o mapa a partir do JSON de um MapConfig
+func ParseMap(data []byte) (*Map, error) {
+	var cfg MapConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, invalidMap(fmt.Sprintf("failed to decode shard map: %v", err))
+	}
+	return NewMap(cfg)
+}
+
+// LoadMap lê o mapa de um arquivo JSON
+func LoadMap(path string) (*Map, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shard map %s: %w", path, err)
+	}
+	return ParseMap(data)
+}
+
+// Version retorna a versão do mapa
+func (m *Map) Version() int64 {
+	return m.version
+}
+
+// Strategy retorna a estratégia do mapa
+func (m *Map) Strategy() string {
+	return m.strategy
+}
+
+// Shards retorna os IDs dos shards, na ordem da configuração
+func (m *Map) Shards() []string {
+	return slices.Clone(m.shards)
+}
+
+// Slot retorna o slot da chave na estratégia hash: FNV-1a de 64 bits módulo
+// o número de slots. O cálculo é estável entre versões da biblioteca
+func (m *Map) Slot(key string) int {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum64() % uint64(len(m.slots)))
+}
+
+// ShardFor retorna o shard da chave
+func (m *Map) ShardFor(key string) (string, error) {
+	if key == "" {
+		return "", missingKey()
+	}
+	if m.strategy == StrategyHash {
+		return m.slots[m.Slot(key)], nil
+	}
+
+	probe := keyRange{from: key}
+	if m.numeric {
+		n, err := strconv.ParseInt(key, 10, 64)
+		if err != nil {
+			return "", domainerrors.NewWithMetadata(interfaces.ValidationError, CodeInvalidKey,
+				fmt.Sprintf("shard key %q is not an integer", key), map[string]interface{}{"key": key})
+		}
+		probe.fromInt = n
+	}
+	// última faixa com limite inferior <= chave
+	i, found := slices.BinarySearchFunc(m.ranges, probe, m.compareRanges)
+	if !found {
+		i--
+	}
+	return m.ranges[i].shard, nil
+}
+
+// Move é uma faixa de slots que muda de shard entre duas versões do mapa
+type Move struct {
+	First int    `json:"first"`
+	Last  int    `json:"last"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// Moves retorna as faixas de slots que mudam de shard de m para next, para
+// planejar a cópia dos dados de um rebalanceamento. Ambos devem ser mapas
+// hash com o mesmo número de slots
+func (m *Map) Moves(next *Map) ([]Move, error) {
+	if m.strategy != StrategyHash || next.strategy != StrategyHash || len(m.slots) != len(next.slots) {
+		return nil, invalidMap("moves require hash maps with the same number of slots")
+	}
+	var moves []Move
+	for slot := range m.slots {
+		from, to := m.slots[slot], next.slots[slot]
+		if from == to {
+			continue
+		}
+		if n := len(moves); n > 0 && moves[n-1].Last == slot-1 && moves[n-1].From == from && moves[n-1].To == to {
+			moves[n-1].Last = slot
+			continue
+		}
+		moves = append(moves, Move{First: slot, Last: slot, From: from, To: to})
+	}
+	return moves, nil
+}
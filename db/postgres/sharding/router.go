@@ -0,0 +1,225 @@
+package sharding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/fsvxavier/nexs-lib/db/postgres"
+	pginterfaces "github.com/fsvxavier/nexs-lib/db/postgres/interfaces"
+	"github.com/fsvxavier/nexs-lib/tenancy"
+)
+
+// KeyFunc extrai a chave de shard do contexto, usada quando a operação não
+// recebe a chave explicitamente
+type KeyFunc func(ctx context.Context) (string, error)
+
+// TenantKey usa o tenant do contexto como chave de shard
+func TenantKey(ctx context.Context) (string, error) {
+	tenantID, ok := tenancy.FromContext(ctx)
+	if !ok {
+		return "", missingKey()
+	}
+	return tenantID, nil
+}
+
+// ConnectFunc cria o pool de um shard a partir do DSN
+type ConnectFunc func(ctx context.Context, dsn string) (pginterfaces.IPool, error)
+
+// Option configura o Router
+type Option func(*Router)
+
+// WithKeyFunc define a extração da chave de shard do contexto
+func WithKeyFunc(fn KeyFunc) Option {
+	return func(r *Router) {
+		r.keyFunc = fn
+	}
+}
+
+// Router roteia as operações para o pool do shard de cada chave. O mapa e os
+// pools podem ser trocados em execução com Update, para rebalanceamentos
+type Router struct {
+	keyFunc KeyFunc
+
+	mu    sync.RWMutex
+	m     *Map
+	pools map[string]pginterfaces.IPool
+}
+
+// NewRouter cria o roteador. Todo shard do mapa deve ter um pool
+func NewRouter(m *Map, pools map[string]pginterfaces.IPool, opts ...Option) (*Router, error) {
+	if err := checkPools(m, pools); err != nil {
+		return nil, err
+	}
+	r := &Router{m: m, pools: maps.Clone(pools)}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r, nil
+}
+
+// ConnectPools cria um pool por shard do mapa com connect, ou com
+// postgres.ConnectPool se nil. Em caso de falha os pools já criados são
+// fechados
+func ConnectPools(ctx context.Context, cfg MapConfig, connect ConnectFunc) (map[string]pginterfaces.IPool, error) {
+	if connect == nil {
+		connect = postgres.ConnectPool
+	}
+	pools := make(map[string]pginterfaces.IPool, len(cfg.Shards))
+	for _, shard := range cfg.Shards {
+		if shard.DSN == "" {
+			closePools(pools)
+			return nil, invalidMap(fmt.Sprintf("shard %q: dsn is required", shard.ID))
+		}
+		pool, err := connect(ctx, shard.DSN)
+		if err != nil {
+			closePools(pools)
+			return nil, fmt.Errorf("failed to connect to shard %s: %w", shard.ID, err)
+		}
+		pools[shard.ID] = pool
+	}
+	return pools, nil
+}
+
+// Map retorna o mapa em uso
+func (r *Router) Map() *Map {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.m
+}
+
+// Update troca o mapa em uso. pools são adicionados aos pools existentes,
+// para os shards novos; os pools dos shards que deixaram o mapa são
+// removidos e retornados, para que o chamador os feche depois de drenar as
+// operações em andamento
+func (r *Router) Update(m *Map, pools map[string]pginterfaces.IPool) (map[string]pginterfaces.IPool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	merged := maps.Clone(r.pools)
+	maps.Copy(merged, pools)
+	if err := checkPools(m, merged); err != nil {
+		return nil, err
+	}
+
+	removed := make(map[string]pginterfaces.IPool)
+	for id, pool := range merged {
+		if !slices.Contains(m.shards, id) {
+			removed[id] = pool
+			delete(merged, id)
+		}
+	}
+	r.m = m
+	r.pools = merged
+	return removed, nil
+}
+
+// Shard retorna o shard da chave. Uma chave vazia é extraída do contexto
+// pela KeyFunc
+func (r *Router) Shard(ctx context.Context, key string) (string, error) {
+	shard, _, err := r.route(ctx, key)
+	return shard, err
+}
+
+// Pool retorna o pool do shard
+func (r *Router) Pool(shard string) (pginterfaces.IPool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	pool, ok := r.pools[shard]
+	if !ok {
+		return nil, unavailable(shard)
+	}
+	return pool, nil
+}
+
+// Acquire obtém uma conexão do shard da chave
+func (r *Router) Acquire(ctx context.Context, key string) (pginterfaces.IConn, error) {
+	_, pool, err := r.route(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return pool.Acquire(ctx)
+}
+
+// RunInTx executa fn em uma transação no shard da chave. A transação é
+// confirmada se fn retornar nil e desfeita se fn retornar erro ou entrar em
+// pânico
+func (r *Router) RunInTx(ctx context.Context, key string, fn func(ctx context.Context, tx pginterfaces.ITransaction) error) error {
+	return r.RunInTxWithOptions(ctx, key, pginterfaces.TxOptions{}, fn)
+}
+
+// RunInTxWithOptions é RunInTx com opções da transação
+func (r *Router) RunInTxWithOptions(ctx context.Context, key string, txOptions pginterfaces.TxOptions,
+	fn func(ctx context.Context, tx pginterfaces.ITransaction) error) (err error) {
+	conn, err := r.Acquire(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	tx, err := conn.BeginTx(ctx, txOptions)
+	if err != nil {
+		return err
+	}
+	committing := false
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(ctx)
+			panic(p)
+		}
+		if err != nil && !committing {
+			if rbErr := tx.Rollback(ctx); rbErr != nil {
+				err = errors.Join(err, fmt.Errorf("rollback failed: %w", rbErr))
+			}
+		}
+	}()
+
+	if err = fn(ctx, tx); err != nil {
+		return err
+	}
+	committing = true
+	return tx.Commit(ctx)
+}
+
+// route resolve o shard e o pool da chave
+func (r *Router) route(ctx context.Context, key string) (string, pginterfaces.IPool, error) {
+	if key == "" && r.keyFunc != nil {
+		var err error
+		if key, err = r.keyFunc(ctx); err != nil {
+			return "", nil, err
+		}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	shard, err := r.m.ShardFor(key)
+	if err != nil {
+		return "", nil, err
+	}
+	pool, ok := r.pools[shard]
+	if !ok {
+		return "", nil, unavailable(shard)
+	}
+	return shard, pool, nil
+}
+
+func checkPools(m *Map, pools map[string]pginterfaces.IPool) error {
+	if m == nil {
+		return invalidMap("shard map is required")
+	}
+	for _, shard := range m.shards {
+		if pools[shard] == nil {
+			return unavailable(shard)
+		}
+	}
+	return nil
+}
+
+func closePools(pools map[string]pginterfaces.IPool) {
+	for _, pool := range pools {
+		pool.Close()
+	}
+}
@@ -0,0 +1,138 @@
+package sharding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	pginterfaces "github.com/fsvxavier/nexs-lib/db/postgres/interfaces"
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+// ScatterOption configura ScatterGather
+type ScatterOption func(*scatterOptions)
+
+type scatterOptions struct {
+	concurrency int
+	shards      []string
+}
+
+// WithConcurrency limita o número de shards consultados ao mesmo tempo;
+// zero, o padrão, consulta todos em paralelo
+func WithConcurrency(n int) ScatterOption {
+	return func(o *scatterOptions) {
+		o.concurrency = n
+	}
+}
+
+// WithShards restringe a consulta aos shards informados
+func WithShards(shards ...string) ScatterOption {
+	return func(o *scatterOptions) {
+		o.shards = shards
+	}
+}
+
+// ScatterGather executa fn em uma conexão de cada shard e retorna os
+// resultados por shard. Quando parte dos shards falha, os resultados dos
+// demais são retornados junto de um DatabaseError CodePartialFailure, com os
+// shards em falha e com sucesso nos metadados; quando todos falham, o erro é
+// CodeScatterFailed. Os erros de cada shard são acessíveis com errors.Is e
+// errors.As.
+//
+// A conexão é liberada quando fn retorna: fn deve consumir todas as linhas.
+func ScatterGather[T any](ctx context.Context, r *Router,
+	fn func(ctx context.Context, shard string, conn pginterfaces.IConn) (T, error), opts ...ScatterOption) (map[string]T, error) {
+	o := scatterOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	shards := o.shards
+	if len(shards) == 0 {
+		shards = r.Map().Shards()
+	}
+	concurrency := o.concurrency
+	if concurrency <= 0 || concurrency > len(shards) {
+		concurrency = len(shards)
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]T, len(shards))
+		errs    = make(map[string]error)
+		sem     = make(chan struct{}, concurrency)
+	)
+	for _, shard := range shards {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			result, err := queryShard(ctx, r, shard, fn)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs[shard] = err
+				return
+			}
+			results[shard] = result
+		}()
+	}
+	wg.Wait()
+
+	if len(errs) == 0 {
+		return results, nil
+	}
+	return results, scatterError(shards, errs)
+}
+
+func queryShard[T any](ctx context.Context, r *Router, shard string,
+	fn func(ctx context.Context, shard string, conn pginterfaces.IConn) (T, error)) (T, error) {
+	var zero T
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+	pool, err := r.Pool(shard)
+	if err != nil {
+		return zero, err
+	}
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return zero, err
+	}
+	defer conn.Release()
+	return fn(ctx, shard, conn)
+}
+
+// scatterError agrega os erros dos shards em um erro de domínio
+func scatterError(shards []string, errs map[string]error) error {
+	var failed, succeeded []string
+	for _, shard := range shards {
+		if _, ok := errs[shard]; ok {
+			failed = append(failed, shard)
+		} else {
+			succeeded = append(succeeded, shard)
+		}
+	}
+	slices.Sort(failed)
+	slices.Sort(succeeded)
+
+	joined := make([]error, 0, len(failed))
+	for _, shard := range failed {
+		joined = append(joined, fmt.Errorf("shard %s: %w", shard, errs[shard]))
+	}
+
+	code, message := CodePartialFailure, fmt.Sprintf("%d of %d shards failed", len(failed), len(shards))
+	if len(succeeded) == 0 {
+		code, message = CodeScatterFailed, "all shards failed"
+	}
+	return domainerrors.NewWithMetadata(interfaces.DatabaseError, code, message, map[string]interface{}{
+		"failed_shards":    failed,
+		"succeeded_shards": succeeded,
+	}).Wrap(errors.Join(joined...))
+}
@@ -0,0 +1,477 @@
+package sharding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	pginterfaces "github.com/fsvxavier/nexs-lib/db/postgres/interfaces"
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/fsvxavier/nexs-lib/tenancy"
+)
+
+// testPool registra as operações executadas em um shard
+type testPool struct {
+	pginterfaces.IPool
+	shard      string
+	acquireErr error
+
+	mu     sync.Mutex
+	events []string
+	closed bool
+}
+
+type testConn struct {
+	pginterfaces.IConn
+	pool *testPool
+}
+
+type testTx struct {
+	pginterfaces.ITransaction
+	pool *testPool
+}
+
+func (p *testPool) Acquire(ctx context.Context) (pginterfaces.IConn, error) {
+	if p.acquireErr != nil {
+		return nil, p.acquireErr
+	}
+	p.record("acquire")
+	return &testConn{pool: p}, nil
+}
+
+func (p *testPool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+}
+
+func (p *testPool) record(event string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, event)
+}
+
+func (p *testPool) Events() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.events...)
+}
+
+func (c *testConn) Release() {
+	c.pool.record("release")
+}
+
+func (c *testConn) BeginTx(ctx context.Context, txOptions pginterfaces.TxOptions) (pginterfaces.ITransaction, error) {
+	c.pool.record(fmt.Sprintf("begin %d", txOptions.IsoLevel))
+	return &testTx{pool: c.pool}, nil
+}
+
+func (t *testTx) Commit(ctx context.Context) error {
+	t.pool.record("commit")
+	return nil
+}
+
+func (t *testTx) Rollback(ctx context.Context) error {
+	t.pool.record("rollback")
+	return nil
+}
+
+func newPools(shards ...string) map[string]pginterfaces.IPool {
+	pools := make(map[string]pginterfaces.IPool, len(shards))
+	for _, shard := range shards {
+		pools[shard] = &testPool{shard: shard}
+	}
+	return pools
+}
+
+func hashConfig() MapConfig {
+	return MapConfig{
+		Version:  1,
+		Strategy: StrategyHash,
+		Slots:    8,
+		Shards: []ShardConfig{
+			{ID: "shard-a", DSN: "postgres://a", Slots: []string{"0-3"}},
+			{ID: "shard-b", DSN: "postgres://b", Slots: []string{"4-6", "7"}},
+		},
+	}
+}
+
+func mustMap(t *testing.T, cfg MapConfig) *Map {
+	t.Helper()
+	m, err := NewMap(cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return m
+}
+
+func errorCode(err error) string {
+	var domainErr interfaces.DomainErrorInterface
+	if errors.As(err, &domainErr) {
+		return domainErr.Code()
+	}
+	return ""
+}
+
+func TestMap_Hash(t *testing.T) {
+	m := mustMap(t, hashConfig())
+
+	counts := map[string]int{}
+	for i := 0; i < 200; i++ {
+		key := fmt.Sprintf("customer-%d", i)
+		shard, err := m.ShardFor(key)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		want := "shard-a"
+		if m.Slot(key) >= 4 {
+			want = "shard-b"
+		}
+		if shard != want {
+			t.Fatalf("Expected %s for slot %d, got %s", want, m.Slot(key), shard)
+		}
+		again, _ := m.ShardFor(key)
+		if again != shard {
+			t.Fatalf("Expected a stable shard for %s", key)
+		}
+		counts[shard]++
+	}
+	if counts["shard-a"] == 0 || counts["shard-b"] == 0 {
+		t.Errorf("Expected keys on both shards, got %v", counts)
+	}
+
+	if _, err := m.ShardFor(""); errorCode(err) != CodeShardKeyRequired {
+		t.Errorf("Expected %s, got %v", CodeShardKeyRequired, err)
+	}
+}
+
+func TestMap_Range(t *testing.T) {
+	m := mustMap(t, MapConfig{
+		Strategy: StrategyRange,
+		Numeric:  true,
+		Shards: []ShardConfig{
+			{ID: "high", From: "1000000"},
+			{ID: "low"},
+			{ID: "mid", From: "1000"},
+		},
+	})
+
+	tests := map[string]string{"-5": "low", "1": "low", "999": "low", "1000": "mid", "999999": "mid", "1000000": "high", "5000000": "high"}
+	for key, want := range tests {
+		shard, err := m.ShardFor(key)
+		if err != nil {
+			t.Fatalf("Unexpected error for %s: %v", key, err)
+		}
+		if shard != want {
+			t.Errorf("Expected %s for key %s, got %s", want, key, shard)
+		}
+	}
+	if _, err := m.ShardFor("abc"); errorCode(err) != CodeInvalidKey {
+		t.Errorf("Expected %s, got %v", CodeInvalidKey, err)
+	}
+
+	lexical := mustMap(t, MapConfig{
+		Strategy: StrategyRange,
+		Shards:   []ShardConfig{{ID: "a-l"}, {ID: "m-z", From: "m"}},
+	})
+	for key, want := range map[string]string{"alice": "a-l", "lucas": "a-l", "m": "m-z", "zoe": "m-z"} {
+		if shard, _ := lexical.ShardFor(key); shard != want {
+			t.Errorf("Expected %s for key %s, got %s", want, key, shard)
+		}
+	}
+}
+
+func TestNewMap_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  MapConfig
+	}{
+		{"no shards", MapConfig{Strategy: StrategyHash}},
+		{"strategy", MapConfig{Strategy: "modulo", Shards: []ShardConfig{{ID: "a"}}}},
+		{"duplicate shard", MapConfig{Strategy: StrategyRange, Shards: []ShardConfig{{ID: "a"}, {ID: "a", From: "m"}}}},
+		{"missing slots", MapConfig{Strategy: StrategyHash, Slots: 4, Shards: []ShardConfig{{ID: "a", Slots: []string{"0-2"}}}}},
+		{"overlapping slots", MapConfig{Strategy: StrategyHash, Slots: 4, Shards: []ShardConfig{
+			{ID: "a", Slots: []string{"0-2"}}, {ID: "b", Slots: []string{"2-3"}}}}},
+		{"slot out of range", MapConfig{Strategy: StrategyHash, Slots: 4, Shards: []ShardConfig{{ID: "a", Slots: []string{"0-4"}}}}},
+		{"malformed slots", MapConfig{Strategy: StrategyHash, Slots: 4, Shards: []ShardConfig{{ID: "a", Slots: []string{"3-0"}}}}},
+		{"no start", MapConfig{Strategy: StrategyRange, Shards: []ShardConfig{{ID: "a", From: "a"}}}},
+		{"same start", MapConfig{Strategy: StrategyRange, Shards: []ShardConfig{{ID: "a"}, {ID: "b", From: "m"}, {ID: "c", From: "m"}}}},
+		{"numeric bound", MapConfig{Strategy: StrategyRange, Numeric: true, Shards: []ShardConfig{{ID: "a"}, {ID: "b", From: "x"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewMap(tt.cfg)
+			if errorCode(err) != CodeInvalidShardMap {
+				t.Fatalf("Expected %s, got %v", CodeInvalidShardMap, err)
+			}
+			var domainErr interfaces.DomainErrorInterface
+			if errors.As(err, &domainErr); domainErr.Type() != interfaces.ConfigurationError {
+				t.Errorf("Expected a configuration error, got %s", domainErr.Type())
+			}
+		})
+	}
+}
+
+func TestLoadMap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shards.json")
+	data := `{"version": 3, "strategy": "hash", "slots": 2, "shards": [
+		{"id": "a", "dsn": "postgres://a", "slots": ["0"]},
+		{"id": "b", "dsn": "postgres://b", "slots": ["1"]}]}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := LoadMap(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if m.Version() != 3 || m.Strategy() != StrategyHash || !reflect.DeepEqual(m.Shards(), []string{"a", "b"}) {
+		t.Errorf("Unexpected map: version %d, strategy %s, shards %v", m.Version(), m.Strategy(), m.Shards())
+	}
+
+	if _, err := ParseMap([]byte("{")); errorCode(err) != CodeInvalidShardMap {
+		t.Errorf("Expected %s, got %v", CodeInvalidShardMap, err)
+	}
+	if _, err := LoadMap(filepath.Join(t.TempDir(), "missing.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected a not exist error, got %v", err)
+	}
+}
+
+func TestMap_Moves(t *testing.T) {
+	current := mustMap(t, hashConfig())
+	next := hashConfig()
+	next.Version = 2
+	next.Shards = []ShardConfig{
+		{ID: "shard-a", Slots: []string{"0-2"}},
+		{ID: "shard-b", Slots: []string{"4", "7"}},
+		{ID: "shard-c", Slots: []string{"3", "5-6"}},
+	}
+
+	moves, err := current.Moves(mustMap(t, next))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []Move{
+		{First: 3, Last: 3, From: "shard-a", To: "shard-c"},
+		{First: 5, Last: 6, From: "shard-b", To: "shard-c"},
+	}
+	if !reflect.DeepEqual(moves, want) {
+		t.Errorf("Expected %v, got %v", want, moves)
+	}
+
+	ranged := mustMap(t, MapConfig{Strategy: StrategyRange, Shards: []ShardConfig{{ID: "a"}}})
+	if _, err := current.Moves(ranged); errorCode(err) != CodeInvalidShardMap {
+		t.Errorf("Expected %s, got %v", CodeInvalidShardMap, err)
+	}
+}
+
+func TestRouter_RunInTx(t *testing.T) {
+	pools := newPools("shard-a", "shard-b")
+	router, err := NewRouter(mustMap(t, hashConfig()), pools, WithKeyFunc(TenantKey))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ctx := tenancy.WithTenant(context.Background(), "acme")
+	shard, err := router.Shard(ctx, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	pool := pools[shard].(*testPool)
+
+	err = router.RunInTxWithOptions(ctx, "", pginterfaces.TxOptions{IsoLevel: pginterfaces.TxIsoLevelSerializable},
+		func(ctx context.Context, tx pginterfaces.ITransaction) error {
+			if tx.(*testTx).pool != pool {
+				t.Errorf("Expected the transaction on %s", shard)
+			}
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []string{"acquire", fmt.Sprintf("begin %d", pginterfaces.TxIsoLevelSerializable), "commit", "release"}
+	if got := pool.Events(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	failure := errors.New("insert failed")
+	if err := router.RunInTx(ctx, "", func(context.Context, pginterfaces.ITransaction) error { return failure }); !errors.Is(err, failure) {
+		t.Errorf("Expected %v, got %v", failure, err)
+	}
+	if got := pool.Events(); got[len(got)-2] != "rollback" {
+		t.Errorf("Expected a rollback, got %v", got)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected the panic to be propagated")
+			}
+		}()
+		_ = router.RunInTx(ctx, "", func(context.Context, pginterfaces.ITransaction) error { panic("boom") })
+	}()
+	if got := pool.Events(); got[len(got)-2] != "rollback" || got[len(got)-1] != "release" {
+		t.Errorf("Expected a rollback and release after the panic, got %v", got)
+	}
+
+	err = router.RunInTx(context.Background(), "", func(context.Context, pginterfaces.ITransaction) error { return nil })
+	if errorCode(err) != CodeShardKeyRequired {
+		t.Errorf("Expected %s without a tenant, got %v", CodeShardKeyRequired, err)
+	}
+}
+
+func TestRouter_Update(t *testing.T) {
+	if _, err := NewRouter(mustMap(t, hashConfig()), newPools("shard-a")); errorCode(err) != CodeShardUnavailable {
+		t.Fatalf("Expected %s, got %v", CodeShardUnavailable, err)
+	}
+
+	router, err := NewRouter(mustMap(t, hashConfig()), newPools("shard-a", "shard-b"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	next := hashConfig()
+	next.Version = 2
+	next.Shards = []ShardConfig{
+		{ID: "shard-a", Slots: []string{"0-3"}},
+		{ID: "shard-c", Slots: []string{"4-7"}},
+	}
+	nextMap := mustMap(t, next)
+	if _, err := router.Update(nextMap, nil); errorCode(err) != CodeShardUnavailable {
+		t.Fatalf("Expected %s, got %v", CodeShardUnavailable, err)
+	}
+	if router.Map().Version() != 1 {
+		t.Fatal("Expected the map to be kept after a failed update")
+	}
+
+	removed, err := router.Update(nextMap, newPools("shard-c"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(removed) != 1 || removed["shard-b"] == nil {
+		t.Errorf("Expected shard-b to be removed, got %v", removed)
+	}
+	if router.Map().Version() != 2 {
+		t.Errorf("Expected version 2, got %d", router.Map().Version())
+	}
+	for i := 0; i < 50; i++ {
+		shard, err := router.Shard(context.Background(), fmt.Sprintf("key-%d", i))
+		if err != nil || shard == "shard-b" {
+			t.Fatalf("Unexpected route to %s: %v", shard, err)
+		}
+	}
+	if _, err := router.Pool("shard-b"); errorCode(err) != CodeShardUnavailable {
+		t.Errorf("Expected %s, got %v", CodeShardUnavailable, err)
+	}
+}
+
+func TestConnectPools(t *testing.T) {
+	var opened []*testPool
+	connect := func(ctx context.Context, dsn string) (pginterfaces.IPool, error) {
+		if dsn == "postgres://b" {
+			return nil, errors.New("connection refused")
+		}
+		pool := &testPool{shard: dsn}
+		opened = append(opened, pool)
+		return pool, nil
+	}
+
+	cfg := hashConfig()
+	if _, err := ConnectPools(context.Background(), cfg, connect); err == nil {
+		t.Fatal("Expected an error")
+	}
+	if len(opened) != 1 || !opened[0].closed {
+		t.Errorf("Expected the opened pool to be closed")
+	}
+
+	cfg.Shards[1].DSN = "postgres://b2"
+	pools, err := ConnectPools(context.Background(), cfg, connect)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if pools["shard-b"].(*testPool).shard != "postgres://b2" {
+		t.Errorf("Expected shard-b connected to its dsn")
+	}
+
+	cfg.Shards[0].DSN = ""
+	if _, err := ConnectPools(context.Background(), cfg, connect); errorCode(err) != CodeInvalidShardMap {
+		t.Errorf("Expected %s, got %v", CodeInvalidShardMap, err)
+	}
+}
+
+func TestScatterGather(t *testing.T) {
+	pools := newPools("shard-a", "shard-b")
+	router, err := NewRouter(mustMap(t, hashConfig()), pools)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	count := func(ctx context.Context, shard string, conn pginterfaces.IConn) (int, error) {
+		return len(shard), nil
+	}
+	results, err := ScatterGather(context.Background(), router, count, WithConcurrency(1))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(results, map[string]int{"shard-a": 7, "shard-b": 7}) {
+		t.Errorf("Unexpected results: %v", results)
+	}
+	for shard, pool := range pools {
+		if got := pool.(*testPool).Events(); !reflect.DeepEqual(got, []string{"acquire", "release"}) {
+			t.Errorf("Expected %s connection released, got %v", shard, got)
+		}
+	}
+
+	results, err = ScatterGather(context.Background(), router, count, WithShards("shard-b"))
+	if err != nil || !reflect.DeepEqual(results, map[string]int{"shard-b": 7}) {
+		t.Errorf("Unexpected results: %v, %v", results, err)
+	}
+}
+
+func TestScatterGather_PartialFailure(t *testing.T) {
+	down := errors.New("connection refused")
+	pools := newPools("shard-a", "shard-b")
+	pools["shard-b"].(*testPool).acquireErr = down
+	router, err := NewRouter(mustMap(t, hashConfig()), pools)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	results, err := ScatterGather(context.Background(), router,
+		func(ctx context.Context, shard string, conn pginterfaces.IConn) (string, error) {
+			return shard, nil
+		})
+	if !reflect.DeepEqual(results, map[string]string{"shard-a": "shard-a"}) {
+		t.Errorf("Expected the successful results, got %v", results)
+	}
+	if errorCode(err) != CodePartialFailure || !errors.Is(err, down) {
+		t.Fatalf("Expected %s wrapping the shard error, got %v", CodePartialFailure, err)
+	}
+	var domainErr *domainerrors.DomainError
+	if !errors.As(err, &domainErr) {
+		t.Fatalf("Expected a domain error, got %T", err)
+	}
+	if domainErr.Type() != interfaces.DatabaseError {
+		t.Errorf("Expected a database error, got %s", domainErr.Type())
+	}
+	metadata := domainErr.Metadata()
+	if !reflect.DeepEqual(metadata["failed_shards"], []string{"shard-b"}) ||
+		!reflect.DeepEqual(metadata["succeeded_shards"], []string{"shard-a"}) {
+		t.Errorf("Unexpected metadata: %v", metadata)
+	}
+
+	pools["shard-a"].(*testPool).acquireErr = down
+	results, err = ScatterGather(context.Background(), router,
+		func(ctx context.Context, shard string, conn pginterfaces.IConn) (string, error) {
+			return shard, nil
+		})
+	if len(results) != 0 || errorCode(err) != CodeScatterFailed {
+		t.Errorf("Expected %s, got %v, %v", CodeScatterFailed, results, err)
+	}
+}
@@ -0,0 +1,113 @@
+// Package sqlcomment anota queries com comentários no formato sqlcommenter
+// (/*key='value',...*/), permitindo correlacionar o pg_stat_activity e os
+// logs do PostgreSQL com serviços, rotas e traces.
+//
+// Especificação: https://google.github.io/sqlcommenter/spec/
+package sqlcomment
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tags padrão do sqlcommenter
+const (
+	KeyApplication = "application"
+	KeyService     = "service"
+	KeyRoute       = "route"
+	KeyController  = "controller"
+	KeyAction      = "action"
+	KeyTraceparent = "traceparent"
+)
+
+type tagsKey struct{}
+
+// WithTags retorna um contexto com tags adicionais para as queries executadas
+// com ele. Recebe pares chave/valor e mantém as tags já presentes no contexto
+//
+//	ctx = sqlcomment.WithTags(ctx, sqlcomment.KeyRoute, "/orders/{id}")
+func WithTags(ctx context.Context, keyValues ...string) context.Context {
+	parent := Tags(ctx)
+	tags := make(map[string]string, len(parent)+len(keyValues)/2)
+	for k, v := range parent {
+		tags[k] = v
+	}
+	for i := 0; i+1 < len(keyValues); i += 2 {
+		tags[keyValues[i]] = keyValues[i+1]
+	}
+	return context.WithValue(ctx, tagsKey{}, tags)
+}
+
+// Tags retorna as tags do contexto. O map não deve ser alterado
+func Tags(ctx context.Context) map[string]string {
+	tags, _ := ctx.Value(tagsKey{}).(map[string]string)
+	return tags
+}
+
+// Traceparent retorna o span ativo no formato W3C traceparent, ou vazio
+// quando não há span válido no contexto
+func Traceparent(ctx context.Context) string {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return ""
+	}
+	return "00-" + sc.TraceID().String() + "-" + sc.SpanID().String() + "-" + sc.TraceFlags().String()
+}
+
+// Format formata as tags como comentário, com as chaves em ordem e valores
+// codificados conforme a especificação. Retorna vazio sem tags
+func Format(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(tags))
+	for k, v := range tags {
+		if k != "" && v != "" {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString("/*")
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(encode(k))
+		b.WriteString("='")
+		b.WriteString(encode(tags[k]))
+		b.WriteByte('\'')
+	}
+	b.WriteString("*/")
+	return b.String()
+}
+
+// Append anexa o comentário das tags ao fim da query, antes do ponto e
+// vírgula final. Queries que já têm comentário não são alteradas
+func Append(query string, tags map[string]string) string {
+	comment := Format(tags)
+	if comment == "" || strings.Contains(query, "/*") {
+		return query
+	}
+
+	trimmed := strings.TrimRight(query, " \t\r\n")
+	if strings.HasSuffix(trimmed, ";") {
+		return strings.TrimRight(trimmed[:len(trimmed)-1], " \t\r\n") + " " + comment + ";"
+	}
+	return trimmed + " " + comment
+}
+
+// encode aplica URL encoding e escapa aspas simples
+func encode(s string) string {
+	s = strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+	return strings.ReplaceAll(s, "'", `\'`)
+}
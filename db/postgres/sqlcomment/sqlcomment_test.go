@@ -0,0 +1,77 @@
+package sqlcomment
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestFormat(t *testing.T) {
+	got := Format(map[string]string{
+		KeyService: "orders-api",
+		KeyRoute:   "/orders/{id}",
+		"note":     "it's done",
+		"empty":    "",
+	})
+	want := `/*note='it%27s%20done',route='%2Forders%2F%7Bid%7D',service='orders-api'*/`
+	if got != want {
+		t.Errorf("Format() = %q, want %q", got, want)
+	}
+
+	if got := Format(nil); got != "" {
+		t.Errorf("Expected no comment without tags, got %q", got)
+	}
+}
+
+func TestAppend(t *testing.T) {
+	tags := map[string]string{KeyService: "api"}
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"SELECT 1", "SELECT 1 /*service='api'*/"},
+		{"SELECT 1;\n", "SELECT 1 /*service='api'*/;"},
+		{"SELECT /* hint */ 1", "SELECT /* hint */ 1"},
+	}
+	for _, tt := range tests {
+		if got := Append(tt.query, tags); got != tt.want {
+			t.Errorf("Append(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+
+	if got := Append("SELECT 1", nil); got != "SELECT 1" {
+		t.Errorf("Expected the query unchanged without tags, got %q", got)
+	}
+}
+
+func TestWithTags(t *testing.T) {
+	ctx := WithTags(context.Background(), KeyRoute, "/a", KeyAction, "list")
+	child := WithTags(ctx, KeyRoute, "/b")
+
+	if tags := Tags(child); tags[KeyRoute] != "/b" || tags[KeyAction] != "list" {
+		t.Errorf("Unexpected child tags %v", tags)
+	}
+	if tags := Tags(ctx); tags[KeyRoute] != "/a" {
+		t.Errorf("Expected the parent tags unchanged, got %v", tags)
+	}
+	if tags := Tags(context.Background()); tags != nil {
+		t.Errorf("Expected no tags, got %v", tags)
+	}
+}
+
+func TestTraceparent(t *testing.T) {
+	if got := Traceparent(context.Background()); got != "" {
+		t.Errorf("Expected no traceparent without span, got %q", got)
+	}
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+	if got := Traceparent(ctx); got != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Errorf("Unexpected traceparent %q", got)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/fsvxavier/nexs-lib/clock"
 	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
 	"github.com/fsvxavier/nexs-lib/domainerrors/performance"
 )
@@ -47,6 +48,30 @@ func TestErrorAggregation(t *testing.T) {
 			t.Error("Expected automatic flush after window expiration")
 		}
 	})
+
+	t.Run("WindowTriggerFakeClock", func(t *testing.T) {
+		fake := clock.NewFake(time.Time{})
+		aggregator := NewErrorAggregator(10, time.Minute, WithAggregatorClock(fake))
+		defer aggregator.Close()
+
+		// Erro não é liberado ao pool: o flush ocorre depois de Add retornar
+		aggregator.Add(performance.NewPooledError(interfaces.ValidationError, "TEST_ERROR", "test error"))
+
+		// Window reiniciada a cada erro: 59s não disparam o flush
+		fake.Advance(59 * time.Second)
+		if aggregator.Count() != 1 {
+			t.Fatalf("Expected 1 pending error before window expiration, got %d", aggregator.Count())
+		}
+
+		fake.Advance(time.Second)
+		deadline := time.Now().Add(time.Second)
+		for aggregator.HasErrors() && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		if aggregator.HasErrors() {
+			t.Error("Expected automatic flush after window expiration")
+		}
+	})
 }
 
 func TestConditionalHooks(t *testing.T) {
@@ -6,6 +6,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/fsvxavier/nexs-lib/clock"
 	"github.com/fsvxavier/nexs-lib/domainerrors/hooks"
 	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
 )
@@ -16,19 +17,35 @@ type ErrorAggregator struct {
 	threshold int
 	window    time.Duration
 	mu        sync.RWMutex
-	timer     *time.Timer
+	clock     clock.Clock
+	timer     clock.Timer
 	flushChan chan struct{}
 	closed    bool
 }
 
+// AggregatorOption configura o ErrorAggregator
+type AggregatorOption func(*ErrorAggregator)
+
+// WithAggregatorClock define o relógio usado pela janela de agregação
+// (padrão clock.Real()); testes usam clock.NewFake
+func WithAggregatorClock(c clock.Clock) AggregatorOption {
+	return func(ea *ErrorAggregator) {
+		ea.clock = clock.OrReal(c)
+	}
+}
+
 // NewErrorAggregator cria um novo agregador de erros
-func NewErrorAggregator(threshold int, window time.Duration) *ErrorAggregator {
+func NewErrorAggregator(threshold int, window time.Duration, opts ...AggregatorOption) *ErrorAggregator {
 	ea := &ErrorAggregator{
 		errors:    make([]interfaces.DomainErrorInterface, 0),
 		threshold: threshold,
 		window:    window,
+		clock:     clock.Real(),
 		flushChan: make(chan struct{}, 1),
 	}
+	for _, opt := range opts {
+		opt(ea)
+	}
 
 	// Inicia goroutine para flush automático
 	go ea.autoFlush()
@@ -47,7 +64,7 @@ func (ea *ErrorAggregator) Add(err interfaces.DomainErrorInterface) error {
 	if ea.timer != nil {
 		ea.timer.Reset(ea.window)
 	} else {
-		ea.timer = time.AfterFunc(ea.window, func() {
+		ea.timer = ea.clock.AfterFunc(ea.window, func() {
 			ea.mu.RLock()
 			closed := ea.closed
 			ea.mu.RUnlock()
@@ -109,6 +126,8 @@ func (ea *ErrorAggregator) createAggregatedError() interfaces.DomainErrorInterfa
 		}
 	}
 
+	now := ea.clock.Now()
+
 	// Criar erro agregado usando factory pattern
 	// Assumindo que existe uma função para criar erros
 	return &AggregatedError{
@@ -120,9 +139,9 @@ func (ea *ErrorAggregator) createAggregatedError() interfaces.DomainErrorInterfa
 			"error_codes":   errorCodes,
 			"error_types":   errorTypes,
 			"dominant_type": string(dominantType),
-			"aggregated_at": time.Now().Format(time.RFC3339),
+			"aggregated_at": now.Format(time.RFC3339),
 		},
-		timestamp: time.Now(),
+		timestamp: now,
 		errors:    ea.errors,
 	}
 }
@@ -0,0 +1,232 @@
+package advanced
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+// Alert descreve a severidade de uma ocorrência de erro
+type Alert struct {
+	Fingerprint  string
+	Code         string
+	Type         interfaces.ErrorType
+	BaseSeverity domainerrors.Severity
+	Severity     domainerrors.Severity
+	// Escalated indica que Severity foi elevada por repetição
+	Escalated bool
+	// Count é o número de ocorrências na janela da regra aplicada, limitado
+	// ao maior threshold das regras mais um
+	Count  int
+	Window time.Duration
+	Error  interfaces.DomainErrorInterface
+	At     time.Time
+}
+
+// AlertSink recebe os alertas de escalonamento
+type AlertSink interface {
+	Alert(ctx context.Context, alert Alert) error
+}
+
+// AlertSinkFunc adapta uma função para AlertSink
+type AlertSinkFunc func(ctx context.Context, alert Alert) error
+
+// Alert implementa AlertSink
+func (f AlertSinkFunc) Alert(ctx context.Context, alert Alert) error {
+	return f(ctx, alert)
+}
+
+// EscalatorOption configura o SeverityEscalator
+type EscalatorOption func(*SeverityEscalator)
+
+// WithEscalatorCatalog define o catálogo com as políticas de severidade
+// (padrão domainerrors.GetCatalog())
+func WithEscalatorCatalog(catalog *domainerrors.Catalog) EscalatorOption {
+	return func(se *SeverityEscalator) {
+		se.catalog = catalog
+	}
+}
+
+// WithEscalatorClock define o relógio das janelas de repetição
+// (padrão clock.Real()); testes usam clock.NewFake
+func WithEscalatorClock(c clock.Clock) EscalatorOption {
+	return func(se *SeverityEscalator) {
+		se.clock = clock.OrReal(c)
+	}
+}
+
+// WithAlertSink adiciona um destino para os alertas de escalonamento
+func WithAlertSink(sink AlertSink) EscalatorOption {
+	return func(se *SeverityEscalator) {
+		if sink != nil {
+			se.sinks = append(se.sinks, sink)
+		}
+	}
+}
+
+// SeverityEscalator conta as ocorrências de cada fingerprint e eleva a
+// severidade conforme as regras de escalonamento do catálogo. Os destinos de
+// alerta são notificados quando um fingerprint sobe de severidade, uma vez
+// por nível, até que a repetição cesse e ele volte à severidade base.
+type SeverityEscalator struct {
+	catalog  *domainerrors.Catalog
+	clock    clock.Clock
+	sinks    []AlertSink
+	states   map[string]*occurrences
+	observed int
+	mu       sync.Mutex
+}
+
+// occurrences guarda as ocorrências recentes de um fingerprint
+type occurrences struct {
+	times   []time.Time
+	window  time.Duration
+	alerted domainerrors.Severity
+}
+
+// sweepEvery é o intervalo, em ocorrências, da limpeza de fingerprints inativos
+const sweepEvery = 1024
+
+// NewSeverityEscalator cria um novo SeverityEscalator
+func NewSeverityEscalator(opts ...EscalatorOption) *SeverityEscalator {
+	se := &SeverityEscalator{
+		catalog: domainerrors.GetCatalog(),
+		clock:   clock.Real(),
+		states:  make(map[string]*occurrences),
+	}
+	for _, opt := range opts {
+		opt(se)
+	}
+	return se
+}
+
+// Observe registra uma ocorrência de err e retorna sua severidade. Quando a
+// ocorrência eleva a severidade do fingerprint, os destinos são notificados e
+// seus erros retornados.
+func (se *SeverityEscalator) Observe(ctx context.Context, err interfaces.DomainErrorInterface) (Alert, error) {
+	if err == nil {
+		return Alert{}, nil
+	}
+
+	policy := se.catalog.Policy(err.Code(), err.Type())
+	now := se.clock.Now()
+	alert := Alert{
+		Fingerprint:  domainerrors.Fingerprint(err),
+		Code:         err.Code(),
+		Type:         err.Type(),
+		BaseSeverity: policy.Severity,
+		Severity:     policy.Severity,
+		Count:        1,
+		Error:        err,
+		At:           now,
+	}
+	if len(policy.Escalation) == 0 {
+		return alert, nil
+	}
+
+	se.mu.Lock()
+	state := se.record(alert.Fingerprint, policy.Escalation, now)
+	for _, rule := range policy.Escalation {
+		count := state.countSince(now.Add(-rule.Window))
+		if count > rule.Threshold && rule.Severity > alert.Severity {
+			alert.Severity = rule.Severity
+			alert.Count = count
+			alert.Window = rule.Window
+		}
+	}
+	alert.Escalated = alert.Severity > alert.BaseSeverity
+
+	notify := false
+	switch {
+	case !alert.Escalated:
+		state.alerted = 0
+	case alert.Severity > state.alerted:
+		state.alerted = alert.Severity
+		notify = true
+	}
+	se.mu.Unlock()
+
+	if !notify {
+		return alert, nil
+	}
+	var errs []error
+	for _, sink := range se.sinks {
+		if sinkErr := sink.Alert(ctx, alert); sinkErr != nil {
+			errs = append(errs, sinkErr)
+		}
+	}
+	return alert, errors.Join(errs...)
+}
+
+// Hook retorna um ErrorHookFunc que observa os erros, para registro com
+// hooks.RegisterErrorHook ou RegisterConditionalErrorHook
+func (se *SeverityEscalator) Hook() interfaces.ErrorHookFunc {
+	return func(ctx context.Context, err interfaces.DomainErrorInterface) error {
+		_, sinkErr := se.Observe(ctx, err)
+		return sinkErr
+	}
+}
+
+// Tracked retorna o número de fingerprints com ocorrências recentes
+func (se *SeverityEscalator) Tracked() int {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+	return len(se.states)
+}
+
+// record adiciona uma ocorrência, descartando as que saíram da maior janela
+// das regras. se.mu deve estar bloqueado.
+func (se *SeverityEscalator) record(fingerprint string, rules []domainerrors.EscalationRule, now time.Time) *occurrences {
+	se.observed++
+	if se.observed%sweepEvery == 0 {
+		se.sweep(now)
+	}
+
+	var window time.Duration
+	limit := 1
+	for _, rule := range rules {
+		window = max(window, rule.Window)
+		limit = max(limit, rule.Threshold+1)
+	}
+
+	state, ok := se.states[fingerprint]
+	if !ok {
+		state = &occurrences{}
+		se.states[fingerprint] = state
+	}
+	state.window = window
+	state.times = append(state.times, now)
+
+	// Descarta ocorrências fora da janela e além do necessário para as regras
+	start := 0
+	for start < len(state.times) && !state.times[start].After(now.Add(-window)) {
+		start++
+	}
+	start = max(start, len(state.times)-limit)
+	state.times = append(state.times[:0], state.times[start:]...)
+	return state
+}
+
+// sweep remove fingerprints sem ocorrências na sua janela. se.mu deve estar
+// bloqueado.
+func (se *SeverityEscalator) sweep(now time.Time) {
+	for fingerprint, state := range se.states {
+		if len(state.times) == 0 || !state.times[len(state.times)-1].After(now.Add(-state.window)) {
+			delete(se.states, fingerprint)
+		}
+	}
+}
+
+// countSince retorna o número de ocorrências depois de since
+func (o *occurrences) countSince(since time.Time) int {
+	count := 0
+	for i := len(o.times) - 1; i >= 0 && o.times[i].After(since); i-- {
+		count++
+	}
+	return count
+}
@@ -0,0 +1,137 @@
+package advanced
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+func TestSeverityEscalator(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	catalog := domainerrors.NewCatalog()
+	catalog.Register(domainerrors.CodeInfo{
+		Code:     "PAYMENT_GATEWAY_DOWN",
+		Severity: domainerrors.SeverityMedium,
+		Escalation: []domainerrors.EscalationRule{
+			{Threshold: 3, Window: time.Minute, Severity: domainerrors.SeverityHigh},
+			{Threshold: 5, Window: time.Minute, Severity: domainerrors.SeverityCritical},
+		},
+	})
+
+	var mu sync.Mutex
+	var alerts []Alert
+	escalator := NewSeverityEscalator(
+		WithEscalatorCatalog(catalog),
+		WithEscalatorClock(fake),
+		WithAlertSink(AlertSinkFunc(func(ctx context.Context, alert Alert) error {
+			mu.Lock()
+			defer mu.Unlock()
+			alerts = append(alerts, alert)
+			return nil
+		})),
+	)
+
+	err := domainerrors.New(interfaces.ExternalServiceError, "PAYMENT_GATEWAY_DOWN", "gateway down")
+	var severities []domainerrors.Severity
+	for i := 0; i < 7; i++ {
+		alert, sinkErr := escalator.Observe(context.Background(), err)
+		if sinkErr != nil {
+			t.Fatalf("unexpected sink error: %v", sinkErr)
+		}
+		severities = append(severities, alert.Severity)
+		fake.Advance(time.Second)
+	}
+
+	expected := []domainerrors.Severity{
+		domainerrors.SeverityMedium, domainerrors.SeverityMedium, domainerrors.SeverityMedium,
+		domainerrors.SeverityHigh, domainerrors.SeverityHigh,
+		domainerrors.SeverityCritical, domainerrors.SeverityCritical,
+	}
+	for i := range expected {
+		if severities[i] != expected[i] {
+			t.Fatalf("occurrence %d: expected %s, got %s", i+1, expected[i], severities[i])
+		}
+	}
+
+	// Um alerta por nível de escalonamento
+	if len(alerts) != 2 {
+		t.Fatalf("expected 2 alerts, got %d", len(alerts))
+	}
+	if alerts[0].Severity != domainerrors.SeverityHigh || alerts[0].Count != 4 || !alerts[0].Escalated {
+		t.Errorf("unexpected first alert: %+v", alerts[0])
+	}
+	if alerts[1].Severity != domainerrors.SeverityCritical || alerts[1].BaseSeverity != domainerrors.SeverityMedium {
+		t.Errorf("unexpected second alert: %+v", alerts[1])
+	}
+	if alerts[0].Fingerprint != "external_service_error:PAYMENT_GATEWAY_DOWN" {
+		t.Errorf("unexpected fingerprint %q", alerts[0].Fingerprint)
+	}
+
+	// Após a janela, volta à severidade base e pode alertar de novo
+	fake.Advance(2 * time.Minute)
+	alert, _ := escalator.Observe(context.Background(), err)
+	if alert.Severity != domainerrors.SeverityMedium || alert.Escalated {
+		t.Errorf("expected base severity after the window, got %+v", alert)
+	}
+	for i := 0; i < 3; i++ {
+		escalator.Observe(context.Background(), err)
+	}
+	if len(alerts) != 3 {
+		t.Errorf("expected a new alert after the repetition restarted, got %d", len(alerts))
+	}
+}
+
+func TestSeverityEscalator_TypePolicy(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	catalog := domainerrors.NewCatalog()
+	catalog.SetTypePolicy(interfaces.DatabaseError, domainerrors.SeverityPolicy{
+		Escalation: []domainerrors.EscalationRule{{Threshold: 1, Window: time.Minute, Severity: domainerrors.SeverityCritical}},
+	})
+
+	sinkErr := errors.New("pager unavailable")
+	escalator := NewSeverityEscalator(
+		WithEscalatorCatalog(catalog),
+		WithEscalatorClock(fake),
+		WithAlertSink(AlertSinkFunc(func(context.Context, Alert) error { return sinkErr })),
+	)
+	hook := escalator.Hook()
+
+	err := domainerrors.New(interfaces.DatabaseError, "DB_TIMEOUT", "db timeout")
+	if hookErr := hook(context.Background(), err); hookErr != nil {
+		t.Fatalf("unexpected error on first occurrence: %v", hookErr)
+	}
+	if hookErr := hook(context.Background(), err); !errors.Is(hookErr, sinkErr) {
+		t.Fatalf("expected sink error, got %v", hookErr)
+	}
+
+	// Fingerprints distintos são contados separadamente
+	other := err.WithMetadata(domainerrors.FingerprintMetadataKey, "db-timeout:orders")
+	alert, _ := escalator.Observe(context.Background(), other)
+	if alert.Escalated || alert.Severity != domainerrors.SeverityHigh {
+		t.Errorf("expected default high severity for a new fingerprint, got %+v", alert)
+	}
+	if escalator.Tracked() != 2 {
+		t.Errorf("expected 2 tracked fingerprints, got %d", escalator.Tracked())
+	}
+}
+
+func TestSeverityEscalator_NoRules(t *testing.T) {
+	escalator := NewSeverityEscalator(WithEscalatorCatalog(domainerrors.NewCatalog()))
+	err := domainerrors.NewValidationError("INVALID_EMAIL", "invalid email")
+
+	for i := 0; i < 10; i++ {
+		alert, _ := escalator.Observe(context.Background(), err)
+		if alert.Severity != domainerrors.SeverityLow || alert.Escalated {
+			t.Fatalf("expected low severity without rules, got %+v", alert)
+		}
+	}
+	if escalator.Tracked() != 0 {
+		t.Errorf("expected no tracking without rules, got %d", escalator.Tracked())
+	}
+}
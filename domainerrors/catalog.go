@@ -0,0 +1,139 @@
+package domainerrors
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+// CodeInfo descreve um código de erro no catálogo
+type CodeInfo struct {
+	// Code é o código do erro, como retornado por Code()
+	Code string
+	// Title resume o erro para quem consulta a documentação
+	Title string
+	// DocsURL aponta para a documentação do código; quando vazio, é derivado
+	// da URL base do catálogo
+	DocsURL string
+	// RunbookURL aponta para o runbook de atendimento do código
+	RunbookURL string
+	// Severity é a severidade base do código; zero herda a do tipo
+	Severity Severity
+	// Escalation eleva a severidade quando o código se repete; nil herda as
+	// regras do tipo
+	Escalation []EscalationRule
+}
+
+// Catalog associa códigos de erro à sua documentação, runbook e política de
+// severidade
+type Catalog struct {
+	docsBaseURL string
+	codes       map[string]CodeInfo
+	types       map[interfaces.ErrorType]SeverityPolicy
+	mu          sync.RWMutex
+}
+
+// NewCatalog cria um catálogo vazio
+func NewCatalog() *Catalog {
+	return &Catalog{
+		codes: make(map[string]CodeInfo),
+		types: make(map[interfaces.ErrorType]SeverityPolicy),
+	}
+}
+
+// SetDocsBaseURL define a URL base da documentação. Códigos sem DocsURL
+// próprio usam a URL base seguida do código, por exemplo
+// https://docs.example.com/errors/USER_NOT_FOUND.
+func (c *Catalog) SetDocsBaseURL(baseURL string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.docsBaseURL = baseURL
+}
+
+// Register adiciona ou substitui um código no catálogo
+func (c *Catalog) Register(info CodeInfo) {
+	if info.Code == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.codes[info.Code] = info
+}
+
+// Lookup retorna as informações de um código, com DocsURL já resolvida
+func (c *Catalog) Lookup(code string) (CodeInfo, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	info, ok := c.codes[code]
+	if ok {
+		info.DocsURL = c.docsURLLocked(code, info)
+	}
+	return info, ok
+}
+
+// DocsURL retorna a URL da documentação de um código, ou "" quando o código
+// não está no catálogo e não há URL base
+func (c *Catalog) DocsURL(code string) string {
+	if code == "" {
+		return ""
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.docsURLLocked(code, c.codes[code])
+}
+
+// RunbookURL retorna a URL do runbook de um código, ou "" quando não há
+func (c *Catalog) RunbookURL(code string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.codes[code].RunbookURL
+}
+
+// Codes retorna os códigos registrados, ordenados pelo código
+func (c *Catalog) Codes() []CodeInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	codes := make([]CodeInfo, 0, len(c.codes))
+	for code, info := range c.codes {
+		info.DocsURL = c.docsURLLocked(code, info)
+		codes = append(codes, info)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i].Code < codes[j].Code })
+	return codes
+}
+
+func (c *Catalog) docsURLLocked(code string, info CodeInfo) string {
+	if info.DocsURL != "" {
+		return info.DocsURL
+	}
+	if c.docsBaseURL == "" {
+		return ""
+	}
+	return strings.TrimSuffix(c.docsBaseURL, "/") + "/" + url.PathEscape(code)
+}
+
+// defaultCatalog é o catálogo consultado por DomainError.DocsURL e RunbookURL
+var defaultCatalog = NewCatalog()
+
+// GetCatalog retorna o catálogo padrão
+func GetCatalog() *Catalog {
+	return defaultCatalog
+}
+
+// RegisterCode registra um código no catálogo padrão
+func RegisterCode(info CodeInfo) {
+	defaultCatalog.Register(info)
+}
+
+// DocsURL retorna a URL da documentação do código do erro no catálogo padrão
+func (e *DomainError) DocsURL() string {
+	return defaultCatalog.DocsURL(e.code)
+}
+
+// RunbookURL retorna a URL do runbook do código do erro no catálogo padrão
+func (e *DomainError) RunbookURL() string {
+	return defaultCatalog.RunbookURL(e.code)
+}
@@ -0,0 +1,112 @@
+//go:build unit
+
+package domainerrors
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+func TestCatalog(t *testing.T) {
+	t.Parallel()
+
+	catalog := NewCatalog()
+	assert.Empty(t, catalog.DocsURL("USER_NOT_FOUND"))
+
+	catalog.Register(CodeInfo{Code: "USER_NOT_FOUND", Title: "User not found", RunbookURL: "https://runbooks.example.com/users"})
+	catalog.Register(CodeInfo{Code: "PAYMENT_DECLINED", DocsURL: "https://pay.example.com/declined"})
+	catalog.Register(CodeInfo{})
+
+	// Sem URL base, apenas URLs explícitas são retornadas
+	assert.Empty(t, catalog.DocsURL("USER_NOT_FOUND"))
+	assert.Equal(t, "https://pay.example.com/declined", catalog.DocsURL("PAYMENT_DECLINED"))
+
+	catalog.SetDocsBaseURL("https://docs.example.com/errors/")
+	assert.Equal(t, "https://docs.example.com/errors/USER_NOT_FOUND", catalog.DocsURL("USER_NOT_FOUND"))
+	assert.Equal(t, "https://docs.example.com/errors/UNREGISTERED", catalog.DocsURL("UNREGISTERED"))
+	assert.Equal(t, "https://pay.example.com/declined", catalog.DocsURL("PAYMENT_DECLINED"))
+	assert.Equal(t, "https://runbooks.example.com/users", catalog.RunbookURL("USER_NOT_FOUND"))
+	assert.Empty(t, catalog.RunbookURL("UNREGISTERED"))
+	assert.Empty(t, catalog.DocsURL(""))
+
+	info, ok := catalog.Lookup("USER_NOT_FOUND")
+	require.True(t, ok)
+	assert.Equal(t, "User not found", info.Title)
+	assert.Equal(t, "https://docs.example.com/errors/USER_NOT_FOUND", info.DocsURL)
+
+	codes := catalog.Codes()
+	require.Len(t, codes, 2)
+	assert.Equal(t, "PAYMENT_DECLINED", codes[0].Code)
+	assert.Equal(t, "USER_NOT_FOUND", codes[1].Code)
+}
+
+func TestDomainError_DocsURL(t *testing.T) {
+	t.Parallel()
+
+	RegisterCode(CodeInfo{
+		Code:       "CATALOG_TEST_ORDER_LOCKED",
+		DocsURL:    "https://docs.example.com/errors/order-locked",
+		RunbookURL: "https://runbooks.example.com/order-locked",
+	})
+
+	err := New(interfaces.ConflictError, "CATALOG_TEST_ORDER_LOCKED", "order locked")
+	docs, ok := err.(interfaces.DocsProvider)
+	require.True(t, ok)
+	assert.Equal(t, "https://docs.example.com/errors/order-locked", docs.DocsURL())
+	assert.Equal(t, "https://runbooks.example.com/order-locked", docs.RunbookURL())
+
+	data, jsonErr := err.ToJSON()
+	require.NoError(t, jsonErr)
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "https://docs.example.com/errors/order-locked", decoded["docs_url"])
+
+	// Códigos fora do catálogo não têm documentação
+	other := New(interfaces.ConflictError, "CATALOG_TEST_UNKNOWN", "unknown")
+	assert.Empty(t, other.(interfaces.DocsProvider).DocsURL())
+}
+
+func TestCatalog_Policy(t *testing.T) {
+	t.Parallel()
+
+	catalog := NewCatalog()
+	typeRules := []EscalationRule{{Threshold: 10, Window: time.Minute, Severity: SeverityHigh}}
+	catalog.SetTypePolicy(interfaces.ExternalServiceError, SeverityPolicy{Severity: SeverityMedium, Escalation: typeRules})
+	catalog.Register(CodeInfo{Code: "GATEWAY_DOWN", Severity: SeverityHigh})
+	catalog.Register(CodeInfo{
+		Code:       "GATEWAY_SLOW",
+		Escalation: []EscalationRule{{Threshold: 3, Window: time.Minute, Severity: SeverityCritical}},
+	})
+
+	// O código define a severidade e herda as regras do tipo
+	policy := catalog.Policy("GATEWAY_DOWN", interfaces.ExternalServiceError)
+	assert.Equal(t, SeverityHigh, policy.Severity)
+	assert.Equal(t, typeRules, policy.Escalation)
+
+	// O código define as regras e herda a severidade do tipo
+	policy = catalog.Policy("GATEWAY_SLOW", interfaces.ExternalServiceError)
+	assert.Equal(t, SeverityMedium, policy.Severity)
+	assert.Equal(t, SeverityCritical, policy.Escalation[0].Severity)
+
+	// Sem política: 5xx é high, os demais low
+	assert.Equal(t, SeverityHigh, catalog.Policy("ANY", interfaces.DatabaseError).Severity)
+	assert.Equal(t, SeverityLow, catalog.Policy("ANY", interfaces.ValidationError).Severity)
+	assert.Empty(t, catalog.Policy("ANY", interfaces.ValidationError).Escalation)
+
+	assert.Equal(t, "critical", SeverityCritical.String())
+	assert.Equal(t, SeverityLow, NewValidationError("CATALOG_TEST_INVALID", "invalid").(*DomainError).Severity())
+}
+
+func TestFingerprint(t *testing.T) {
+	t.Parallel()
+
+	err := NewNotFoundError("USER_NOT_FOUND", "user not found")
+	assert.Equal(t, "not_found_error:USER_NOT_FOUND", Fingerprint(err))
+	assert.Equal(t, "users-db", Fingerprint(err.WithMetadata(FingerprintMetadataKey, "users-db")))
+}
@@ -2,7 +2,6 @@ package domainerrors
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -14,6 +13,7 @@ import (
 
 	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
 	"github.com/fsvxavier/nexs-lib/domainerrors/internal"
+	"github.com/fsvxavier/nexs-lib/pools"
 )
 
 // DomainError implementa a interface DomainErrorInterface
@@ -33,6 +33,8 @@ type DomainError struct {
 // ErrorFactory implementa a interface ErrorFactory
 type ErrorFactory struct {
 	stackCapture interfaces.StackTraceCapture
+	translations *TranslationRegistry
+	profile      *ResponseProfile
 	mu           sync.RWMutex
 }
 
@@ -266,10 +268,15 @@ func init() {
 }
 
 // NewErrorFactory cria uma nova fábrica de erros
-func NewErrorFactory(stackCapture interfaces.StackTraceCapture) *ErrorFactory {
-	return &ErrorFactory{
+func NewErrorFactory(stackCapture interfaces.StackTraceCapture, opts ...FactoryOption) *ErrorFactory {
+	f := &ErrorFactory{
 		stackCapture: stackCapture,
+		translations: NewTranslationRegistry(),
 	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
 }
 
 // NewManager cria um novo gerenciador
@@ -302,11 +309,7 @@ func (e *DomainError) Metadata() map[string]interface{} {
 		return make(map[string]interface{})
 	}
 	// Retorna uma cópia para evitar modificações externas
-	result := make(map[string]interface{})
-	for k, v := range e.metadata {
-		result[k] = v
-	}
-	return result
+	return copyMetadata(e.metadata)
 }
 
 // HTTPStatus retorna o código HTTP correspondente ao tipo de erro
@@ -342,7 +345,7 @@ func (e *DomainError) WithMetadata(key string, value interface{}) interfaces.Dom
 	if newError.metadata == nil {
 		newError.metadata = make(map[string]interface{})
 	}
-	newError.metadata[key] = value
+	newError.metadata[key] = copyValue(value)
 	return newError
 }
 
@@ -367,6 +370,7 @@ func (e *DomainError) ToJSON() ([]byte, error) {
 		Stack     []interfaces.StackFrame `json:"stack,omitempty"`
 		Timestamp time.Time               `json:"timestamp"`
 		Cause     string                  `json:"cause,omitempty"`
+		DocsURL   string                  `json:"docs_url,omitempty"`
 	}
 
 	jsonErr := errorJSON{
@@ -377,16 +381,18 @@ func (e *DomainError) ToJSON() ([]byte, error) {
 		Metadata:  e.metadata,
 		Stack:     e.stack,
 		Timestamp: e.timestamp,
+		DocsURL:   e.DocsURL(),
 	}
 
 	if e.cause != nil {
 		jsonErr.Cause = e.cause.Error()
 	}
 
-	return json.Marshal(jsonErr)
+	return pools.MarshalJSON(jsonErr)
 }
 
-// clone cria uma cópia profunda do erro
+// clone cria uma cópia do erro. Os valores dos metadados não são copiados,
+// pois nunca são alterados depois de anexados ao erro.
 func (e *DomainError) clone() *DomainError {
 	newError := &DomainError{
 		id:           e.id,
@@ -438,15 +444,33 @@ func (f *ErrorFactory) NewWithMetadata(errorType interfaces.ErrorType, code, mes
 		code:         code,
 		message:      message,
 		errorType:    errorType,
-		metadata:     metadata,
+		metadata:     copyMetadata(metadata),
 		timestamp:    time.Now(),
 		stack:        f.stackCapture.CaptureStackTrace(1),
 		stackCapture: f.stackCapture,
 	}
 }
 
-// Wrap encapsula um erro existente
+// Wrap encapsula um erro existente. Com tipo ou código vazios, os campos
+// vazios e os metadados vêm dos tradutores registrados, como em WithCause
 func (f *ErrorFactory) Wrap(err error, errorType interfaces.ErrorType, code, message string) interfaces.DomainErrorInterface {
+	metadata := make(map[string]interface{})
+	if errorType == "" || code == "" {
+		translation := f.translate(err)
+		if errorType == "" {
+			errorType = translation.Type
+		}
+		if code == "" {
+			code = translation.Code
+		}
+		if message == "" {
+			message = translation.Message
+		}
+		if translated := copyMetadata(translation.Metadata); translated != nil {
+			metadata = translated
+		}
+	}
+
 	f.mu.RLock()
 	defer f.mu.RUnlock()
 
@@ -455,7 +479,7 @@ func (f *ErrorFactory) Wrap(err error, errorType interfaces.ErrorType, code, mes
 		code:         code,
 		message:      message,
 		errorType:    errorType,
-		metadata:     make(map[string]interface{}),
+		metadata:     metadata,
 		cause:        err,
 		timestamp:    time.Now(),
 		stack:        f.stackCapture.CaptureStackTrace(1),
@@ -490,33 +514,34 @@ func (c *ErrorTypeChecker) IsType(err error, errorType interfaces.ErrorType) boo
 // MapHTTPStatus mapeia tipos de erro para códigos HTTP
 func MapHTTPStatus(errorType interfaces.ErrorType) int {
 	statusMap := map[interfaces.ErrorType]int{
-		interfaces.ValidationError:           http.StatusBadRequest,           // 400
-		interfaces.BadRequestError:           http.StatusBadRequest,           // 400
-		interfaces.AuthenticationError:       http.StatusUnauthorized,         // 401
-		interfaces.AuthorizationError:        http.StatusForbidden,            // 403
-		interfaces.NotFoundError:             http.StatusNotFound,             // 404
-		interfaces.ConflictError:             http.StatusConflict,             // 409
-		interfaces.UnprocessableEntityError:  http.StatusUnprocessableEntity,  // 422
-		interfaces.UnsupportedMediaTypeError: http.StatusUnsupportedMediaType, // 415
-		interfaces.RateLimitError:            http.StatusTooManyRequests,      // 429
-		interfaces.BusinessError:             http.StatusUnprocessableEntity,  // 422
-		interfaces.WorkflowError:             http.StatusUnprocessableEntity,  // 422
-		interfaces.DatabaseError:             http.StatusInternalServerError,  // 500
-		interfaces.ExternalServiceError:      http.StatusBadGateway,           // 502
-		interfaces.ServiceUnavailableError:   http.StatusServiceUnavailable,   // 503
-		interfaces.TimeoutError:              http.StatusGatewayTimeout,       // 504
-		interfaces.InfrastructureError:       http.StatusInternalServerError,  // 500
-		interfaces.DependencyError:           http.StatusInternalServerError,  // 500
-		interfaces.SecurityError:             http.StatusInternalServerError,  // 500
-		interfaces.ResourceExhaustedError:    http.StatusInternalServerError,  // 500
-		interfaces.CircuitBreakerError:       http.StatusServiceUnavailable,   // 503
-		interfaces.SerializationError:        http.StatusInternalServerError,  // 500
-		interfaces.CacheError:                http.StatusInternalServerError,  // 500
-		interfaces.MigrationError:            http.StatusInternalServerError,  // 500
-		interfaces.ConfigurationError:        http.StatusInternalServerError,  // 500
-		interfaces.UnsupportedOperationError: http.StatusNotImplemented,       // 501
-		interfaces.InvalidSchemaError:        http.StatusBadRequest,           // 400
-		interfaces.ServerError:               http.StatusInternalServerError,  // 500
+		interfaces.ValidationError:           http.StatusBadRequest,            // 400
+		interfaces.BadRequestError:           http.StatusBadRequest,            // 400
+		interfaces.AuthenticationError:       http.StatusUnauthorized,          // 401
+		interfaces.AuthorizationError:        http.StatusForbidden,             // 403
+		interfaces.NotFoundError:             http.StatusNotFound,              // 404
+		interfaces.ConflictError:             http.StatusConflict,              // 409
+		interfaces.UnprocessableEntityError:  http.StatusUnprocessableEntity,   // 422
+		interfaces.UnsupportedMediaTypeError: http.StatusUnsupportedMediaType,  // 415
+		interfaces.PayloadTooLargeError:      http.StatusRequestEntityTooLarge, // 413
+		interfaces.RateLimitError:            http.StatusTooManyRequests,       // 429
+		interfaces.BusinessError:             http.StatusUnprocessableEntity,   // 422
+		interfaces.WorkflowError:             http.StatusUnprocessableEntity,   // 422
+		interfaces.DatabaseError:             http.StatusInternalServerError,   // 500
+		interfaces.ExternalServiceError:      http.StatusBadGateway,            // 502
+		interfaces.ServiceUnavailableError:   http.StatusServiceUnavailable,    // 503
+		interfaces.TimeoutError:              http.StatusGatewayTimeout,        // 504
+		interfaces.InfrastructureError:       http.StatusInternalServerError,   // 500
+		interfaces.DependencyError:           http.StatusInternalServerError,   // 500
+		interfaces.SecurityError:             http.StatusInternalServerError,   // 500
+		interfaces.ResourceExhaustedError:    http.StatusInternalServerError,   // 500
+		interfaces.CircuitBreakerError:       http.StatusServiceUnavailable,    // 503
+		interfaces.SerializationError:        http.StatusInternalServerError,   // 500
+		interfaces.CacheError:                http.StatusInternalServerError,   // 500
+		interfaces.MigrationError:            http.StatusInternalServerError,   // 500
+		interfaces.ConfigurationError:        http.StatusInternalServerError,   // 500
+		interfaces.UnsupportedOperationError: http.StatusNotImplemented,        // 501
+		interfaces.InvalidSchemaError:        http.StatusBadRequest,            // 400
+		interfaces.ServerError:               http.StatusInternalServerError,   // 500
 	}
 
 	if status, exists := statusMap[errorType]; exists {
@@ -0,0 +1,134 @@
+package domainerrors
+
+import (
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+// Imutabilidade
+//
+// Um DomainError não muda depois de criado: os metadados recebidos por
+// NewWithMetadata e WithMetadata são copiados, Metadata retorna uma cópia, e
+// WithMetadata, Wrap, WithContext e CloneWith retornam novos erros. Assim um
+// erro pode ser lido e enriquecido por várias goroutines sem sincronização.
+//
+// As cópias percorrem map[string]interface{} e []interface{} aninhados; outros
+// tipos por referência (ponteiros, structs com mapas) são compartilhados e não
+// devem ser alterados depois de anexados ao erro.
+//
+// A garantia vale para *DomainError. Outras implementações de
+// DomainErrorInterface definem a própria semântica: performance.PooledDomainError,
+// feito para reaproveitar alocações, altera o próprio erro em WithMetadata,
+// WithContext e Wrap.
+
+// MetadataMutation altera os metadados de um erro em CloneWith. Recebe uma
+// cópia exclusiva, que pode ser alterada livremente.
+type MetadataMutation func(metadata map[string]interface{})
+
+// SetMetadata define uma chave dos metadados
+func SetMetadata(key string, value interface{}) MetadataMutation {
+	return func(metadata map[string]interface{}) {
+		metadata[key] = copyValue(value)
+	}
+}
+
+// MergeMetadata define várias chaves dos metadados
+func MergeMetadata(values map[string]interface{}) MetadataMutation {
+	values = copyMetadata(values)
+	return func(metadata map[string]interface{}) {
+		for k, v := range values {
+			metadata[k] = v
+		}
+	}
+}
+
+// DeleteMetadata remove chaves dos metadados
+func DeleteMetadata(keys ...string) MetadataMutation {
+	return func(metadata map[string]interface{}) {
+		for _, key := range keys {
+			delete(metadata, key)
+		}
+	}
+}
+
+// CloneWith retorna um novo erro com as mutações aplicadas a uma cópia dos
+// metadados. O erro original não é alterado.
+func (e *DomainError) CloneWith(mutations ...MetadataMutation) interfaces.DomainErrorInterface {
+	newError := e.clone()
+	metadata := copyMetadata(e.metadata)
+	if metadata == nil {
+		metadata = make(map[string]interface{})
+	}
+	for _, mutate := range mutations {
+		if mutate != nil {
+			mutate(metadata)
+		}
+	}
+	newError.metadata = metadata
+	return newError
+}
+
+// CloneWith aplica as mutações a err. Erros que não são *DomainError são
+// enriquecidos com WithMetadata, chave a chave, e remoções não se aplicam a
+// eles; a imutabilidade depende então do WithMetadata da implementação, e
+// implementações mutáveis como performance.PooledDomainError são alteradas e
+// retornadas.
+func CloneWith(err interfaces.DomainErrorInterface, mutations ...MetadataMutation) interfaces.DomainErrorInterface {
+	if err == nil {
+		return nil
+	}
+	if domainErr, ok := err.(*DomainError); ok {
+		return domainErr.CloneWith(mutations...)
+	}
+
+	// Metadata pode retornar o mapa interno do erro
+	metadata := copyMetadata(err.Metadata())
+	if metadata == nil {
+		metadata = make(map[string]interface{})
+	}
+	for _, mutate := range mutations {
+		if mutate != nil {
+			mutate(metadata)
+		}
+	}
+	for k, v := range metadata {
+		err = err.WithMetadata(k, v)
+	}
+	return err
+}
+
+// copyMetadata copia metadados, incluindo mapas e slices aninhados
+func copyMetadata(metadata map[string]interface{}) map[string]interface{} {
+	if metadata == nil {
+		return nil
+	}
+	result := make(map[string]interface{}, len(metadata))
+	for k, v := range metadata {
+		result[k] = copyValue(v)
+	}
+	return result
+}
+
+// copyValue copia mapas e slices genéricos; os demais valores são retornados
+// como estão
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return copyMetadata(v)
+	case map[string]string:
+		result := make(map[string]string, len(v))
+		for k, s := range v {
+			result[k] = s
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = copyValue(item)
+		}
+		return result
+	case []string:
+		return append([]string(nil), v...)
+	default:
+		return value
+	}
+}
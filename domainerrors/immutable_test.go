@@ -0,0 +1,132 @@
+//go:build unit
+
+package domainerrors
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/fsvxavier/nexs-lib/domainerrors/performance"
+)
+
+func TestDomainError_CloneWith(t *testing.T) {
+	t.Parallel()
+
+	original := NewWithMetadata(interfaces.ValidationError, "INVALID_ORDER", "invalid order", map[string]interface{}{
+		"order_id": "o-1",
+		"internal": "secret",
+	})
+
+	clone := original.(*DomainError).CloneWith(
+		SetMetadata("attempt", 2),
+		MergeMetadata(map[string]interface{}{"tenant": "acme"}),
+		DeleteMetadata("internal"),
+	)
+
+	assert.Equal(t, map[string]interface{}{"order_id": "o-1", "attempt": 2, "tenant": "acme"}, clone.Metadata())
+	assert.Equal(t, map[string]interface{}{"order_id": "o-1", "internal": "secret"}, original.Metadata())
+	assert.Equal(t, original.Code(), clone.Code())
+	assert.Equal(t, original.Type(), clone.Type())
+}
+
+func TestCloneWith_OtherImplementations(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, CloneWith(nil, SetMetadata("k", "v")))
+
+	err := CloneWith(New(interfaces.NotFoundError, "NOT_FOUND", "not found"), SetMetadata("id", 7))
+	assert.Equal(t, 7, err.Metadata()["id"])
+
+	// PooledDomainError is mutable: CloneWith enriches and returns the same
+	// error, and removals do not apply
+	pooled := performance.NewPooledErrorWithMetadata(interfaces.ValidationError, "INVALID", "invalid",
+		map[string]interface{}{"internal": "secret"})
+	defer pooled.Release()
+	enriched := CloneWith(pooled, SetMetadata("attempt", 2), DeleteMetadata("internal"))
+	assert.Same(t, pooled, enriched)
+	assert.Equal(t, map[string]interface{}{"internal": "secret", "attempt": 2}, pooled.Metadata())
+}
+
+func TestDomainError_MetadataIsolation(t *testing.T) {
+	t.Parallel()
+
+	fields := map[string]interface{}{"email": "required"}
+	source := map[string]interface{}{"fields": fields, "tags": []interface{}{"a"}}
+	err := NewWithMetadata(interfaces.ValidationError, "VALIDATION_FAILED", "validation failed", source)
+
+	// Alterar o mapa de origem não afeta o erro
+	source["extra"] = true
+	fields["email"] = "changed"
+
+	// Alterar o resultado de Metadata, inclusive aninhado, também não
+	result := err.Metadata()
+	result["fields"].(map[string]interface{})["email"] = "mutated"
+	result["tags"].([]interface{})[0] = "mutated"
+
+	metadata := err.Metadata()
+	assert.NotContains(t, metadata, "extra")
+	assert.Equal(t, "required", metadata["fields"].(map[string]interface{})["email"])
+	assert.Equal(t, "a", metadata["tags"].([]interface{})[0])
+
+	// Valores anexados por WithMetadata são copiados
+	nested := map[string]interface{}{"step": 1}
+	enriched := err.WithMetadata("context", nested)
+	nested["step"] = 2
+	assert.Equal(t, 1, enriched.Metadata()["context"].(map[string]interface{})["step"])
+}
+
+func TestDomainError_ConcurrentEnrichment(t *testing.T) {
+	t.Parallel()
+
+	source := map[string]interface{}{
+		"fields": map[string]interface{}{"name": "required"},
+		"ids":    []interface{}{1, 2, 3},
+	}
+	base := NewWithMetadata(interfaces.ValidationError, "VALIDATION_FAILED", "validation failed", source)
+
+	const goroutines = 32
+	results := make([]interfaces.DomainErrorInterface, goroutines)
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			key := fmt.Sprintf("worker_%d", i)
+
+			enriched := base.WithMetadata(key, i)
+			enriched = CloneWith(enriched, SetMetadata("attempt", i), DeleteMetadata("ids"))
+			enriched.Metadata()["fields"].(map[string]interface{})[key] = "local"
+
+			_, jsonErr := base.ToJSON()
+			assert.NoError(t, jsonErr)
+			_ = base.Metadata()
+			_ = base.Error()
+			results[i] = enriched
+		}()
+	}
+	// O chamador que forneceu os metadados continua livre para alterá-los
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < goroutines; i++ {
+			source[fmt.Sprintf("late_%d", i)] = i
+			source["fields"].(map[string]interface{})["late"] = i
+		}
+	}()
+	wg.Wait()
+
+	metadata := base.Metadata()
+	assert.Len(t, metadata, 2)
+	assert.Equal(t, map[string]interface{}{"name": "required"}, metadata["fields"])
+	for i, result := range results {
+		require.NotNil(t, result)
+		assert.Equal(t, i, result.Metadata()[fmt.Sprintf("worker_%d", i)])
+		assert.Equal(t, i, result.Metadata()["attempt"])
+		assert.NotContains(t, result.Metadata(), "ids")
+	}
+}
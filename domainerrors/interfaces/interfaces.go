@@ -33,6 +33,7 @@ const (
 	ConflictError             ErrorType = "conflict_error"
 	InvalidSchemaError        ErrorType = "invalid_schema_error"
 	UnsupportedMediaTypeError ErrorType = "unsupported_media_type_error"
+	PayloadTooLargeError      ErrorType = "payload_too_large_error"
 	ServerError               ErrorType = "server_error"
 	UnprocessableEntityError  ErrorType = "unprocessable_entity_error"
 	ServiceUnavailableError   ErrorType = "service_unavailable_error"
@@ -97,6 +98,12 @@ type HTTPStatusProvider interface {
 	HTTPStatus() int
 }
 
+// DocsProvider define interface para erros com documentação e runbook
+type DocsProvider interface {
+	DocsURL() string
+	RunbookURL() string
+}
+
 // ErrorFactory define interface para criação de erros
 type ErrorFactory interface {
 	New(errorType ErrorType, code, message string) DomainErrorInterface
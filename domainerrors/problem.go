@@ -0,0 +1,161 @@
+package domainerrors
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+// ProblemContentType é o media type de problem details (RFC 9457)
+const ProblemContentType = "application/problem+json"
+
+// Membros de extensão do problem+json que carregam o tipo e a severidade do
+// erro de domínio, escritos pelo WriteProblem do httpserver/middlewares
+const (
+	ProblemErrorTypeMember = "error_type"
+	ProblemSeverityMember  = "severity"
+)
+
+// SeverityMetadataKey é a chave de metadados que substitui a severidade do
+// catálogo, como nos erros reconstruídos por FromHTTPResponse
+const SeverityMetadataKey = "severity"
+
+// maxProblemSize limita o corpo lido por FromHTTPResponse
+const maxProblemSize = 1 << 20
+
+// ErrRemote é encapsulado pelos erros reconstruídos de respostas HTTP,
+// identificando erros vindos de outro serviço
+var ErrRemote = errors.New("domainerrors: remote error")
+
+// ErrorTypeForStatus retorna o tipo de erro de um status HTTP de erro: o
+// inverso de MapHTTPStatus para os status mapeados, ExternalServiceError para
+// os demais 5xx e BadRequestError para os demais 4xx
+func ErrorTypeForStatus(status int) interfaces.ErrorType {
+	switch status {
+	case http.StatusBadRequest:
+		return interfaces.BadRequestError
+	case http.StatusUnauthorized:
+		return interfaces.AuthenticationError
+	case http.StatusForbidden:
+		return interfaces.AuthorizationError
+	case http.StatusNotFound:
+		return interfaces.NotFoundError
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return interfaces.TimeoutError
+	case http.StatusConflict:
+		return interfaces.ConflictError
+	case http.StatusRequestEntityTooLarge:
+		return interfaces.PayloadTooLargeError
+	case http.StatusUnsupportedMediaType:
+		return interfaces.UnsupportedMediaTypeError
+	case http.StatusUnprocessableEntity:
+		return interfaces.UnprocessableEntityError
+	case http.StatusTooManyRequests:
+		return interfaces.RateLimitError
+	case http.StatusNotImplemented:
+		return interfaces.UnsupportedOperationError
+	case http.StatusServiceUnavailable:
+		return interfaces.ServiceUnavailableError
+	}
+	if status >= http.StatusInternalServerError {
+		return interfaces.ExternalServiceError
+	}
+	return interfaces.BadRequestError
+}
+
+// FromHTTPResponse reconstrói o erro de domínio de uma resposta de erro de
+// outro serviço, preservando código, tipo, mensagem e severidade entre
+// chamadas. Retorna nil para status abaixo de 400. O corpo lido é devolvido a
+// resp.Body, que pode ser lido novamente. Veja FromProblem.
+func FromHTTPResponse(resp *http.Response) interfaces.DomainErrorInterface {
+	if resp == nil || resp.StatusCode < http.StatusBadRequest {
+		return nil
+	}
+
+	var body []byte
+	if resp.Body != nil {
+		body, _ = io.ReadAll(io.LimitReader(resp.Body, maxProblemSize))
+		resp.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), resp.Body), Closer: resp.Body}
+	}
+	return FromProblem(resp.StatusCode, resp.Header.Get("Content-Type"), body)
+}
+
+// FromProblem reconstrói o erro de domínio de um corpo de resposta de erro.
+// Corpos problem+json (ou JSON) dão o código ("code"), a mensagem ("detail",
+// senão "title"), o tipo ("error_type") e a severidade ("severity"); os demais
+// membros, como "errors", "instance" e extensões, vão para os metadados, com
+// o status em "status_code" e o "type" do problema em "problem_type". Sem
+// esses membros, o código é HTTP_<status> e o tipo vem de
+// ErrorTypeForStatus. O erro encapsula ErrRemote. Retorna nil para status
+// abaixo de 400.
+func FromProblem(status int, contentType string, body []byte) interfaces.DomainErrorInterface {
+	if status < http.StatusBadRequest {
+		return nil
+	}
+
+	code := fmt.Sprintf("HTTP_%d", status)
+	errorType := ErrorTypeForStatus(status)
+	message := fmt.Sprintf("server responded with status %d", status)
+	metadata := map[string]interface{}{"status_code": status}
+
+	members := decodeProblem(contentType, body)
+	if text, _ := members["code"].(string); text != "" {
+		code = text
+	}
+	if text, _ := members["title"].(string); text != "" {
+		message = text
+	}
+	if text, _ := members["detail"].(string); text != "" {
+		message = text
+	}
+	if text, _ := members[ProblemErrorTypeMember].(string); text != "" {
+		errorType = interfaces.ErrorType(text)
+	}
+	if text, _ := members[ProblemSeverityMember].(string); text != "" {
+		if _, ok := ParseSeverity(text); ok {
+			metadata[SeverityMetadataKey] = text
+		}
+	}
+	if text, _ := members["type"].(string); text != "" && text != "about:blank" {
+		metadata["problem_type"] = text
+	}
+	for member, value := range members {
+		switch member {
+		case "code", "title", "detail", "status", "type", ProblemErrorTypeMember, ProblemSeverityMember:
+		default:
+			metadata[member] = value
+		}
+	}
+
+	return NewWithMetadata(errorType, code, message, metadata).Wrap(ErrRemote)
+}
+
+// decodeProblem decodifica os membros de um corpo JSON; outros media types e
+// corpos inválidos não têm membros
+func decodeProblem(contentType string, body []byte) map[string]interface{} {
+	if contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || (mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json")) {
+			return nil
+		}
+	}
+
+	var members map[string]interface{}
+	if err := json.Unmarshal(body, &members); err != nil {
+		return nil
+	}
+	return members
+}
+
+// readCloser devolve o corpo lido sem perder o Close da resposta
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,116 @@
+//go:build unit
+
+package domainerrors
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+func TestFromHTTPResponse_Problem(t *testing.T) {
+	t.Parallel()
+
+	body := `{"type":"https://docs.example.com/errors/ORDER_NOT_FOUND","title":"Not Found","status":404,` +
+		`"detail":"order 42 not found","code":"ORDER_NOT_FOUND","error_type":"not_found_error",` +
+		`"severity":"medium","instance":"/orders/42","runbook":"https://runbooks.example.com/orders"}`
+	resp := &http.Response{
+		StatusCode: http.StatusNotFound,
+		Header:     http.Header{"Content-Type": []string{"application/problem+json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+
+	err := FromHTTPResponse(resp)
+	require.NotNil(t, err)
+	assert.Equal(t, "ORDER_NOT_FOUND", err.Code())
+	assert.Equal(t, interfaces.NotFoundError, err.Type())
+	assert.Equal(t, "order 42 not found", err.Error())
+	assert.Equal(t, http.StatusNotFound, err.HTTPStatus())
+	assert.ErrorIs(t, err, ErrRemote)
+	assert.Equal(t, SeverityMedium, err.(*DomainError).Severity())
+
+	metadata := err.Metadata()
+	assert.Equal(t, http.StatusNotFound, metadata["status_code"])
+	assert.Equal(t, "https://docs.example.com/errors/ORDER_NOT_FOUND", metadata["problem_type"])
+	assert.Equal(t, "/orders/42", metadata["instance"])
+	assert.Equal(t, "https://runbooks.example.com/orders", metadata["runbook"])
+	assert.NotContains(t, metadata, "detail")
+
+	// O corpo continua disponível para o chamador
+	data, readErr := io.ReadAll(resp.Body)
+	require.NoError(t, readErr)
+	assert.Equal(t, body, string(data))
+}
+
+func TestFromProblem_KeepsTypeAcrossHops(t *testing.T) {
+	t.Parallel()
+
+	// Um erro de validação do serviço B continua 400 no serviço A, em vez de 502
+	err := FromProblem(http.StatusBadRequest, "application/problem+json; charset=utf-8",
+		[]byte(`{"code":"INVALID_CPF","detail":"invalid cpf","error_type":"validation_error",`+
+			`"errors":[{"field":"cpf","tag":"cpf"}]}`))
+	require.NotNil(t, err)
+	assert.True(t, IsType(err, interfaces.ValidationError))
+	assert.Equal(t, http.StatusBadRequest, err.HTTPStatus())
+	assert.Len(t, err.Metadata()["errors"], 1)
+}
+
+func TestFromProblem_Fallbacks(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		code        string
+		errorType   interfaces.ErrorType
+		message     string
+	}{
+		{"Plain text", 502, "text/html", "<html>bad gateway</html>", "HTTP_502",
+			interfaces.ExternalServiceError, "server responded with status 502"},
+		{"Invalid JSON", 409, "application/json", "{", "HTTP_409",
+			interfaces.ConflictError, "server responded with status 409"},
+		{"Title only", 429, "application/problem+json", `{"title":"Too Many Requests"}`, "HTTP_429",
+			interfaces.RateLimitError, "Too Many Requests"},
+		{"Empty body", 418, "", "", "HTTP_418",
+			interfaces.BadRequestError, "server responded with status 418"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := FromProblem(tt.status, tt.contentType, []byte(tt.body))
+			require.NotNil(t, err)
+			assert.Equal(t, tt.code, err.Code())
+			assert.Equal(t, tt.errorType, err.Type())
+			assert.Equal(t, tt.message, err.Error())
+		})
+	}
+
+	assert.Nil(t, FromProblem(http.StatusOK, "application/json", []byte(`{"code":"OK"}`)))
+	assert.Nil(t, FromHTTPResponse(&http.Response{StatusCode: http.StatusNoContent}))
+	assert.Nil(t, FromHTTPResponse(nil))
+}
+
+func TestSeverity_Metadata(t *testing.T) {
+	t.Parallel()
+
+	err := NewWithMetadata(interfaces.ValidationError, "SEVERITY_TEST", "invalid",
+		map[string]interface{}{SeverityMetadataKey: "critical"})
+	assert.Equal(t, SeverityCritical, err.(*DomainError).Severity())
+
+	err = NewWithMetadata(interfaces.ValidationError, "SEVERITY_TEST", "invalid",
+		map[string]interface{}{SeverityMetadataKey: "unknown"})
+	assert.Equal(t, SeverityLow, err.(*DomainError).Severity())
+
+	_, ok := ParseSeverity("urgent")
+	assert.False(t, ok)
+	assert.False(t, errors.Is(err, ErrRemote))
+}
@@ -0,0 +1,196 @@
+package domainerrors
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/fsvxavier/nexs-lib/masking"
+)
+
+// Nomes dos perfis de resposta pré-definidos
+const (
+	PublicProfileName   = "public"
+	InternalProfileName = "internal"
+)
+
+// PublicError é o código e a mensagem expostos no lugar de um código interno
+type PublicError struct {
+	Code string
+	// Message mensagem exposta; vazia usa o texto do status HTTP
+	Message string
+}
+
+// ResponseProfile define o que uma resposta de erro expõe. Respostas internas
+// (entre serviços, ferramentas administrativas) trazem metadados, causa e
+// stack trace; respostas públicas os omitem e trocam códigos internos por
+// códigos genéricos.
+type ResponseProfile struct {
+	Name string
+
+	// Codes troca códigos internos por códigos públicos, em qualquer status
+	Codes map[string]PublicError
+
+	// GenericServerErrors troca o código, a mensagem e o tipo dos erros 5xx
+	// sem entrada em Codes pelos genéricos do status, ex.: INTERNAL_ERROR
+	GenericServerErrors bool
+
+	// Metadata expõe todos os metadados; senão apenas os de MetadataKeys
+	Metadata     bool
+	MetadataKeys []string
+
+	// Severity, Cause e Stack expõem a severidade, a mensagem da causa e o
+	// stack trace
+	Severity bool
+	Cause    bool
+	Stack    bool
+
+	// Redactor mascara dados pessoais e segredos da resposta: os metadados
+	// pelo nome da chave e a mensagem e a causa por detecção no texto
+	Redactor *masking.Redactor
+}
+
+// PublicProfile retorna o perfil das respostas públicas: erros 5xx genéricos,
+// sem metadados, severidade, causa ou stack trace. Erros 4xx mantêm código e
+// mensagem, destinados ao cliente; use Codes para trocar os sensíveis.
+func PublicProfile() *ResponseProfile {
+	return &ResponseProfile{Name: PublicProfileName, GenericServerErrors: true}
+}
+
+// InternalProfile retorna o perfil das respostas internas, com metadados,
+// severidade, causa e stack trace
+func InternalProfile() *ResponseProfile {
+	return &ResponseProfile{
+		Name:     InternalProfileName,
+		Metadata: true,
+		Severity: true,
+		Cause:    true,
+		Stack:    true,
+	}
+}
+
+// ErrorResponse é um erro renderizado por um ResponseProfile
+type ErrorResponse struct {
+	Status   int
+	Code     string
+	Message  string
+	Type     interfaces.ErrorType
+	Severity string
+	Metadata map[string]interface{}
+	Cause    string
+	Stack    string
+}
+
+// genericServerErrors são os erros genéricos de GenericServerErrors por status
+var genericServerErrors = map[int]struct {
+	code      string
+	errorType interfaces.ErrorType
+}{
+	http.StatusInternalServerError: {"INTERNAL_ERROR", interfaces.ServerError},
+	http.StatusNotImplemented:      {"NOT_IMPLEMENTED", interfaces.UnsupportedOperationError},
+	http.StatusBadGateway:          {"BAD_GATEWAY", interfaces.ExternalServiceError},
+	http.StatusServiceUnavailable:  {"SERVICE_UNAVAILABLE", interfaces.ServiceUnavailableError},
+	http.StatusGatewayTimeout:      {"GATEWAY_TIMEOUT", interfaces.TimeoutError},
+}
+
+// Render converte err na resposta do perfil. Erros que não são de domínio
+// viram um INTERNAL_ERROR genérico, com a mensagem apenas como causa.
+func (p *ResponseProfile) Render(err error) ErrorResponse {
+	var domainErr interfaces.DomainErrorInterface
+	if !errors.As(err, &domainErr) {
+		resp := ErrorResponse{
+			Status:  http.StatusInternalServerError,
+			Code:    "INTERNAL_ERROR",
+			Message: "internal server error",
+			Type:    interfaces.ServerError,
+		}
+		if p.Cause && err != nil {
+			resp.Cause = err.Error()
+		}
+		return p.redact(resp)
+	}
+
+	resp := ErrorResponse{
+		Status:  domainErr.HTTPStatus(),
+		Code:    domainErr.Code(),
+		Message: domainErr.Error(),
+		Type:    domainErr.Type(),
+	}
+	if public, ok := p.Codes[resp.Code]; ok {
+		resp.Code, resp.Message = public.Code, public.Message
+		if resp.Message == "" {
+			resp.Message = http.StatusText(resp.Status)
+		}
+	} else if p.GenericServerErrors && resp.Status >= http.StatusInternalServerError {
+		generic, exists := genericServerErrors[resp.Status]
+		if !exists {
+			generic = genericServerErrors[http.StatusInternalServerError]
+		}
+		resp.Code, resp.Type = generic.code, generic.errorType
+		resp.Message = http.StatusText(resp.Status)
+	}
+
+	if severity, ok := domainErr.(interface{ Severity() Severity }); ok && p.Severity {
+		resp.Severity = severity.Severity().String()
+	}
+	if p.Metadata {
+		resp.Metadata = domainErr.Metadata()
+	} else if len(p.MetadataKeys) > 0 {
+		metadata := domainErr.Metadata()
+		for _, key := range p.MetadataKeys {
+			if value, ok := metadata[key]; ok {
+				if resp.Metadata == nil {
+					resp.Metadata = make(map[string]interface{})
+				}
+				resp.Metadata[key] = value
+			}
+		}
+	}
+	if p.Cause {
+		if cause := errors.Unwrap(domainErr); cause != nil {
+			resp.Cause = cause.Error()
+		}
+	}
+	if p.Stack {
+		resp.Stack = domainErr.StackTrace()
+	}
+	return p.redact(resp)
+}
+
+// redact mascara a resposta com o Redactor do perfil. Os metadados são
+// copiados, pois pertencem ao erro.
+func (p *ResponseProfile) redact(resp ErrorResponse) ErrorResponse {
+	if p.Redactor == nil {
+		return resp
+	}
+	resp.Message = p.Redactor.Text(resp.Message)
+	resp.Cause = p.Redactor.Text(resp.Cause)
+	resp.Metadata = p.Redactor.Map(resp.Metadata)
+	return resp
+}
+
+// FactoryOption configura um ErrorFactory
+type FactoryOption func(*ErrorFactory)
+
+// WithResponseProfile define o perfil de resposta padrão da fábrica, usado
+// por quem renderiza os erros sem perfil próprio, como o WriteProblem do
+// httpserver/middlewares
+func WithResponseProfile(profile *ResponseProfile) FactoryOption {
+	return func(f *ErrorFactory) {
+		f.profile = profile
+	}
+}
+
+// ResponseProfile retorna o perfil de resposta padrão da fábrica, ou nil
+func (f *ErrorFactory) ResponseProfile() *ResponseProfile {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.profile
+}
+
+// SetResponseProfile altera o perfil de resposta padrão da fábrica
+func (f *ErrorFactory) SetResponseProfile(profile *ResponseProfile) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.profile = profile
+}
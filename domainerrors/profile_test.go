@@ -0,0 +1,152 @@
+//go:build unit
+
+package domainerrors
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/fsvxavier/nexs-lib/masking"
+)
+
+// sensitiveErrors são erros com detalhes que não podem chegar ao cliente
+func sensitiveErrors() []error {
+	return []error{
+		NewWithMetadata(interfaces.DatabaseError, "DB_QUERY_FAILED",
+			"query on users failed for password hunter2",
+			map[string]interface{}{"query": "SELECT * FROM users WHERE password = 'hunter2'"}).
+			Wrap(errors.New("pq: connection to 10.0.0.5:5432 refused")),
+		NewWithMetadata(interfaces.ExternalServiceError, "PAYMENT_PROVIDER_FAILED",
+			"acquirer returned hunter2 token", map[string]interface{}{"token": "hunter2"}),
+		NewWithMetadata(interfaces.AuthenticationError, "USER_PASSWORD_HASH_MISMATCH",
+			"bcrypt hash mismatch for user 42", map[string]interface{}{"user_id": 42}),
+		errors.New("dial tcp 10.0.0.5:5432: password hunter2 rejected"),
+	}
+}
+
+func TestPublicProfile_NoDetailLeakage(t *testing.T) {
+	t.Parallel()
+
+	profile := PublicProfile()
+	profile.Codes = map[string]PublicError{
+		"USER_PASSWORD_HASH_MISMATCH": {Code: "INVALID_CREDENTIALS", Message: "invalid credentials"},
+	}
+
+	leaks := []string{
+		"hunter2", "10.0.0.5", "bcrypt", "SELECT", "user_id", "pq:",
+		"DB_QUERY_FAILED", "PAYMENT_PROVIDER_FAILED", "USER_PASSWORD_HASH_MISMATCH",
+		"database_error", ".go:",
+	}
+	for _, err := range sensitiveErrors() {
+		resp := profile.Render(err)
+		data, marshalErr := json.Marshal(resp)
+		require.NoError(t, marshalErr)
+
+		for _, leak := range leaks {
+			assert.NotContains(t, string(data), leak, "public response of %q", err)
+		}
+		assert.Empty(t, resp.Metadata)
+		assert.Empty(t, resp.Cause)
+		assert.Empty(t, resp.Stack)
+		assert.Empty(t, resp.Severity)
+	}
+}
+
+func TestPublicProfile_Codes(t *testing.T) {
+	t.Parallel()
+
+	profile := PublicProfile()
+	profile.Codes = map[string]PublicError{"USER_PASSWORD_HASH_MISMATCH": {Code: "INVALID_CREDENTIALS"}}
+	errs := sensitiveErrors()
+
+	tests := []struct {
+		err       error
+		status    int
+		code      string
+		errorType interfaces.ErrorType
+		message   string
+	}{
+		{errs[0], http.StatusInternalServerError, "INTERNAL_ERROR", interfaces.ServerError, "Internal Server Error"},
+		{errs[1], http.StatusBadGateway, "BAD_GATEWAY", interfaces.ExternalServiceError, "Bad Gateway"},
+		{errs[2], http.StatusUnauthorized, "INVALID_CREDENTIALS", interfaces.AuthenticationError, "Unauthorized"},
+		{errs[3], http.StatusInternalServerError, "INTERNAL_ERROR", interfaces.ServerError, "internal server error"},
+		{NewNotFoundError("ORDER_NOT_FOUND", "order not found"),
+			http.StatusNotFound, "ORDER_NOT_FOUND", interfaces.NotFoundError, "order not found"},
+	}
+
+	for _, tt := range tests {
+		resp := profile.Render(tt.err)
+		assert.Equal(t, tt.status, resp.Status)
+		assert.Equal(t, tt.code, resp.Code)
+		assert.Equal(t, tt.errorType, resp.Type)
+		assert.Equal(t, tt.message, resp.Message)
+	}
+}
+
+func TestPublicProfile_MetadataKeys(t *testing.T) {
+	t.Parallel()
+
+	profile := PublicProfile()
+	profile.MetadataKeys = []string{"retry_after"}
+
+	resp := profile.Render(NewWithMetadata(interfaces.RateLimitError, "RATE_LIMITED", "too many requests",
+		map[string]interface{}{"retry_after": 30, "client_ip": "10.0.0.5"}))
+	assert.Equal(t, map[string]interface{}{"retry_after": 30}, resp.Metadata)
+
+	resp = profile.Render(NewRateLimitError("RATE_LIMITED", "too many requests"))
+	assert.Nil(t, resp.Metadata)
+}
+
+func TestInternalProfile_IncludesDetails(t *testing.T) {
+	t.Parallel()
+
+	resp := InternalProfile().Render(sensitiveErrors()[0])
+	assert.Equal(t, "DB_QUERY_FAILED", resp.Code)
+	assert.Equal(t, interfaces.DatabaseError, resp.Type)
+	assert.Equal(t, "query on users failed for password hunter2", resp.Message)
+	assert.Contains(t, resp.Metadata, "query")
+	assert.Equal(t, "pq: connection to 10.0.0.5:5432 refused", resp.Cause)
+	assert.Equal(t, "high", resp.Severity)
+	assert.NotEmpty(t, resp.Stack)
+
+	resp = InternalProfile().Render(sensitiveErrors()[3])
+	assert.Equal(t, "INTERNAL_ERROR", resp.Code)
+	assert.Contains(t, resp.Cause, "10.0.0.5")
+}
+
+func TestResponseProfile_Redactor(t *testing.T) {
+	t.Parallel()
+
+	metadata := map[string]interface{}{"email": "ana.souza@example.com", "order_id": 42}
+	err := NewWithMetadata(interfaces.ValidationError, "CUSTOMER_INVALID",
+		"customer 123.456.789-09 already exists", metadata).
+		Wrap(errors.New("duplicate key ana.souza@example.com"))
+
+	profile := InternalProfile()
+	profile.Redactor = masking.NewRedactor()
+	resp := profile.Render(err)
+	assert.Equal(t, "customer ***.456.789-** already exists", resp.Message)
+	assert.Equal(t, "duplicate key a********@example.com", resp.Cause)
+	assert.Equal(t, "a********@example.com", resp.Metadata["email"])
+	assert.Equal(t, 42, resp.Metadata["order_id"])
+	assert.Equal(t, "ana.souza@example.com", metadata["email"], "error metadata is not modified")
+}
+
+func TestErrorFactory_ResponseProfile(t *testing.T) {
+	t.Parallel()
+
+	factory := NewErrorFactory(nil, WithResponseProfile(PublicProfile()))
+	require.NotNil(t, factory.ResponseProfile())
+	assert.Equal(t, PublicProfileName, factory.ResponseProfile().Name)
+
+	factory.SetResponseProfile(InternalProfile())
+	assert.Equal(t, InternalProfileName, factory.ResponseProfile().Name)
+
+	assert.Nil(t, NewErrorFactory(nil).ResponseProfile())
+}
@@ -0,0 +1,132 @@
+package domainerrors
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+// Severity representa a gravidade de um erro para alertas. O valor zero
+// indica severidade não definida.
+type Severity int
+
+const (
+	// SeverityLow são erros esperados, como validações
+	SeverityLow Severity = iota + 1
+	// SeverityMedium são falhas que merecem acompanhamento
+	SeverityMedium
+	// SeverityHigh são falhas que exigem ação
+	SeverityHigh
+	// SeverityCritical são falhas que exigem ação imediata
+	SeverityCritical
+)
+
+// String retorna o nome da severidade
+func (s Severity) String() string {
+	switch s {
+	case SeverityLow:
+		return "low"
+	case SeverityMedium:
+		return "medium"
+	case SeverityHigh:
+		return "high"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+}
+
+// ParseSeverity converte o nome de uma severidade ("low", "medium", "high"
+// ou "critical")
+func ParseSeverity(s string) (Severity, bool) {
+	switch s {
+	case "low":
+		return SeverityLow, true
+	case "medium":
+		return SeverityMedium, true
+	case "high":
+		return SeverityHigh, true
+	case "critical":
+		return SeverityCritical, true
+	}
+	return 0, false
+}
+
+// EscalationRule eleva a severidade para Severity quando o mesmo fingerprint
+// ocorre mais de Threshold vezes dentro de Window
+type EscalationRule struct {
+	Threshold int
+	Window    time.Duration
+	Severity  Severity
+}
+
+// SeverityPolicy define a severidade base de um código ou tipo e as regras
+// que a elevam em caso de repetição
+type SeverityPolicy struct {
+	Severity   Severity
+	Escalation []EscalationRule
+}
+
+// SetTypePolicy define a política de severidade de um tipo de erro, usada
+// pelos códigos que não definem a sua
+func (c *Catalog) SetTypePolicy(errorType interfaces.ErrorType, policy SeverityPolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.types[errorType] = policy
+}
+
+// Policy resolve a política de severidade de um erro: a do código, senão a
+// do tipo, senão a padrão, que é SeverityHigh para tipos com status HTTP 5xx e
+// SeverityLow para os demais, sem escalonamento. A severidade e as regras são
+// resolvidas separadamente, então um código pode definir apenas a severidade
+// e herdar as regras do tipo.
+func (c *Catalog) Policy(code string, errorType interfaces.ErrorType) SeverityPolicy {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	info := c.codes[code]
+	typePolicy := c.types[errorType]
+
+	policy := SeverityPolicy{Severity: info.Severity, Escalation: info.Escalation}
+	if policy.Severity == 0 {
+		policy.Severity = typePolicy.Severity
+	}
+	if policy.Severity == 0 {
+		policy.Severity = SeverityLow
+		if MapHTTPStatus(errorType) >= http.StatusInternalServerError {
+			policy.Severity = SeverityHigh
+		}
+	}
+	if policy.Escalation == nil {
+		policy.Escalation = typePolicy.Escalation
+	}
+	return policy
+}
+
+// Severity retorna a severidade base do erro no catálogo padrão, ou a do
+// metadado "severity", quando definido, como nos erros reconstruídos de
+// respostas HTTP de outros serviços
+func (e *DomainError) Severity() Severity {
+	if name, ok := e.metadata[SeverityMetadataKey].(string); ok {
+		if severity, ok := ParseSeverity(name); ok {
+			return severity
+		}
+	}
+	return defaultCatalog.Policy(e.code, e.errorType).Severity
+}
+
+// FingerprintMetadataKey é a chave de metadados que substitui o fingerprint
+// padrão de um erro
+const FingerprintMetadataKey = "fingerprint"
+
+// Fingerprint identifica ocorrências do mesmo erro: o metadado
+// "fingerprint", quando definido, senão o tipo e o código
+func Fingerprint(err interfaces.DomainErrorInterface) string {
+	if fingerprint, ok := err.Metadata()[FingerprintMetadataKey].(string); ok && fingerprint != "" {
+		return fingerprint
+	}
+	return string(err.Type()) + ":" + err.Code()
+}
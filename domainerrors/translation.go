@@ -0,0 +1,333 @@
+package domainerrors
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+// Translation descreve o erro de domínio correspondente a um erro de terceiros
+type Translation struct {
+	Type     interfaces.ErrorType
+	Code     string
+	Message  string
+	Metadata map[string]interface{}
+}
+
+// Translator converte um erro de terceiros em uma Translation, retornando
+// false quando não reconhece o erro
+type Translator func(err error) (Translation, bool)
+
+// namedTranslator associa um tradutor ao nome com que foi registrado
+type namedTranslator struct {
+	name       string
+	translator Translator
+}
+
+// TranslationRegistry mantém os tradutores usados por ErrorFactory.WithCause.
+// Tradutores registrados depois têm precedência, permitindo sobrescrever os
+// padrões.
+type TranslationRegistry struct {
+	translators []namedTranslator
+	mu          sync.RWMutex
+}
+
+// NewTranslationRegistry cria um registro com os tradutores padrão: erros do
+// PostgreSQL (pgx e lib/pq, via SQLState), sql.ErrNoRows,
+// context.DeadlineExceeded, net.Error, io.EOF e erros de JSON
+func NewTranslationRegistry() *TranslationRegistry {
+	r := &TranslationRegistry{}
+	r.Register("json", translateJSON)
+	r.Register("io", translateIO)
+	r.Register("net", translateNet)
+	r.Register("context", translateContext)
+	r.Register("sql", translateSQL)
+	r.Register("postgres", translatePostgres)
+	return r
+}
+
+// Register adiciona um tradutor. Um tradutor com o mesmo nome é substituído
+// e passa a ter a maior precedência.
+func (r *TranslationRegistry) Register(name string, translator Translator) {
+	if translator == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.removeLocked(name)
+	r.translators = append(r.translators, namedTranslator{name: name, translator: translator})
+}
+
+// Unregister remove um tradutor
+func (r *TranslationRegistry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.removeLocked(name)
+}
+
+// Names retorna os nomes dos tradutores, do mais ao menos prioritário
+func (r *TranslationRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.translators))
+	for i := len(r.translators) - 1; i >= 0; i-- {
+		names = append(names, r.translators[i].name)
+	}
+	return names
+}
+
+// Translate retorna a tradução do primeiro tradutor que reconhece err
+func (r *TranslationRegistry) Translate(err error) (Translation, bool) {
+	if err == nil {
+		return Translation{}, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for i := len(r.translators) - 1; i >= 0; i-- {
+		if translation, ok := r.translators[i].translator(err); ok {
+			return translation, true
+		}
+	}
+	return Translation{}, false
+}
+
+func (r *TranslationRegistry) removeLocked(name string) {
+	for i, t := range r.translators {
+		if t.name == name {
+			r.translators = append(r.translators[:i], r.translators[i+1:]...)
+			return
+		}
+	}
+}
+
+// Código e mensagem dos erros sem tradução
+const (
+	UntranslatedErrorCode    = "INTERNAL_ERROR"
+	UntranslatedErrorMessage = "internal error"
+)
+
+// WithCause encapsula err em um erro de domínio cujo tipo, código e mensagem
+// vêm dos tradutores registrados; erros não reconhecidos viram ServerError
+// INTERNAL_ERROR. Erros que já são de domínio são retornados sem alteração.
+// A causa é preservada, então errors.Is e errors.As continuam funcionando.
+func (f *ErrorFactory) WithCause(err error) interfaces.DomainErrorInterface {
+	if err == nil {
+		return nil
+	}
+	var domainErr interfaces.DomainErrorInterface
+	if errors.As(err, &domainErr) {
+		return domainErr
+	}
+
+	translation := f.translate(err)
+	metadata := copyMetadata(translation.Metadata)
+	if metadata == nil {
+		metadata = make(map[string]interface{})
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return &DomainError{
+		id:           uuid.New().String(),
+		code:         translation.Code,
+		message:      translation.Message,
+		errorType:    translation.Type,
+		metadata:     metadata,
+		cause:        err,
+		timestamp:    time.Now(),
+		stack:        f.stackCapture.CaptureStackTrace(1),
+		stackCapture: f.stackCapture,
+	}
+}
+
+// translate retorna a tradução de err, ou ServerError INTERNAL_ERROR quando
+// nenhum tradutor o reconhece
+func (f *ErrorFactory) translate(err error) Translation {
+	if translation, ok := f.Translations().Translate(err); ok {
+		return translation
+	}
+	return Translation{
+		Type:    interfaces.ServerError,
+		Code:    UntranslatedErrorCode,
+		Message: UntranslatedErrorMessage,
+	}
+}
+
+// Translations retorna o registro de tradutores da fábrica
+func (f *ErrorFactory) Translations() *TranslationRegistry {
+	f.mu.RLock()
+	translations := f.translations
+	f.mu.RUnlock()
+	if translations != nil {
+		return translations
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.translations == nil {
+		f.translations = NewTranslationRegistry()
+	}
+	return f.translations
+}
+
+// WithCause encapsula err usando a fábrica padrão, ver ErrorFactory.WithCause
+func WithCause(err error) interfaces.DomainErrorInterface {
+	return defaultFactory.WithCause(err)
+}
+
+// RegisterTranslator registra um tradutor na fábrica padrão
+func RegisterTranslator(name string, translator Translator) {
+	defaultFactory.Translations().Register(name, translator)
+}
+
+// Tradutores padrão
+
+// translateJSON traduz erros de decodificação de JSON, normalmente causados
+// por payloads inválidos do cliente
+func translateJSON(err error) (Translation, bool) {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return Translation{
+			Type:     interfaces.BadRequestError,
+			Code:     "INVALID_JSON",
+			Message:  "malformed JSON",
+			Metadata: map[string]interface{}{"offset": syntaxErr.Offset},
+		}, true
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return Translation{
+			Type:    interfaces.ValidationError,
+			Code:    "INVALID_JSON_TYPE",
+			Message: "invalid JSON value type",
+			Metadata: map[string]interface{}{
+				"field":    typeErr.Field,
+				"expected": typeErr.Type.String(),
+				"value":    typeErr.Value,
+			},
+		}, true
+	}
+	return Translation{}, false
+}
+
+// translateIO traduz o fim inesperado de uma leitura, como um corpo vazio ou
+// truncado
+func translateIO(err error) (Translation, bool) {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return Translation{
+			Type:    interfaces.BadRequestError,
+			Code:    "UNEXPECTED_EOF",
+			Message: "unexpected end of input",
+		}, true
+	}
+	return Translation{}, false
+}
+
+// translateNet traduz erros de rede, inclusive os encapsulados por url.Error
+func translateNet(err error) (Translation, bool) {
+	var netErr net.Error
+	if !errors.As(err, &netErr) {
+		return Translation{}, false
+	}
+	if netErr.Timeout() {
+		return Translation{
+			Type:    interfaces.TimeoutError,
+			Code:    "NETWORK_TIMEOUT",
+			Message: "network operation timed out",
+		}, true
+	}
+
+	metadata := make(map[string]interface{})
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		metadata["op"] = opErr.Op
+		if opErr.Addr != nil {
+			metadata["addr"] = opErr.Addr.String()
+		}
+	}
+	return Translation{
+		Type:     interfaces.ExternalServiceError,
+		Code:     "NETWORK_ERROR",
+		Message:  "network operation failed",
+		Metadata: metadata,
+	}, true
+}
+
+// translateContext traduz prazos de context expirados
+func translateContext(err error) (Translation, bool) {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return Translation{
+			Type:    interfaces.TimeoutError,
+			Code:    "DEADLINE_EXCEEDED",
+			Message: "deadline exceeded",
+		}, true
+	}
+	return Translation{}, false
+}
+
+// translateSQL traduz a ausência de linhas; pgx.ErrNoRows também satisfaz
+// errors.Is(err, sql.ErrNoRows)
+func translateSQL(err error) (Translation, bool) {
+	if errors.Is(err, sql.ErrNoRows) {
+		return Translation{
+			Type:    interfaces.NotFoundError,
+			Code:    "NOT_FOUND",
+			Message: "resource not found",
+		}, true
+	}
+	return Translation{}, false
+}
+
+// sqlStateError é implementado por pgconn.PgError e pq.Error
+type sqlStateError interface {
+	SQLState() string
+}
+
+// translatePostgres traduz erros do PostgreSQL pelo SQLSTATE, sem depender
+// do driver
+func translatePostgres(err error) (Translation, bool) {
+	var pgErr sqlStateError
+	if !errors.As(err, &pgErr) {
+		return Translation{}, false
+	}
+	state := pgErr.SQLState()
+	translation := Translation{
+		Type:     interfaces.DatabaseError,
+		Code:     "DATABASE_ERROR",
+		Message:  "database error",
+		Metadata: map[string]interface{}{"sqlstate": state},
+	}
+
+	switch {
+	case state == "23505":
+		translation.Type, translation.Code, translation.Message = interfaces.ConflictError, "UNIQUE_VIOLATION", "resource already exists"
+	case state == "23503":
+		translation.Type, translation.Code, translation.Message = interfaces.ConflictError, "FOREIGN_KEY_VIOLATION", "referenced resource conflict"
+	case state == "23502", state == "23514":
+		translation.Type, translation.Code, translation.Message = interfaces.ValidationError, "CONSTRAINT_VIOLATION", "constraint violation"
+	case strings.HasPrefix(state, "22"):
+		translation.Type, translation.Code, translation.Message = interfaces.ValidationError, "INVALID_DATA", "invalid data"
+	case state == "40001", state == "40P01":
+		translation.Type, translation.Code, translation.Message = interfaces.ConflictError, "SERIALIZATION_FAILURE", "concurrent update conflict"
+		translation.Metadata["retryable"] = true
+	case state == "57014":
+		translation.Type, translation.Code, translation.Message = interfaces.TimeoutError, "QUERY_CANCELED", "query canceled"
+	case strings.HasPrefix(state, "08"):
+		translation.Type, translation.Code, translation.Message = interfaces.ServiceUnavailableError, "DATABASE_UNAVAILABLE", "database unavailable"
+	case strings.HasPrefix(state, "53"):
+		translation.Type, translation.Code, translation.Message = interfaces.ResourceExhaustedError, "DATABASE_RESOURCES_EXHAUSTED", "database resources exhausted"
+	}
+	return translation, true
+}
@@ -0,0 +1,168 @@
+//go:build unit
+
+package domainerrors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/fsvxavier/nexs-lib/domainerrors/internal"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestErrorFactory_WithCause(t *testing.T) {
+	t.Parallel()
+
+	syntaxErr := json.Unmarshal([]byte(`{"a":}`), new(map[string]interface{}))
+	typeErr := json.Unmarshal([]byte(`{"age":"ten"}`), new(struct {
+		Age int `json:"age"`
+	}))
+
+	tests := []struct {
+		name      string
+		err       error
+		errorType interfaces.ErrorType
+		code      string
+	}{
+		{"json syntax", syntaxErr, interfaces.BadRequestError, "INVALID_JSON"},
+		{"json type", typeErr, interfaces.ValidationError, "INVALID_JSON_TYPE"},
+		{"io eof", io.EOF, interfaces.BadRequestError, "UNEXPECTED_EOF"},
+		{"wrapped unexpected eof", fmt.Errorf("decode body: %w", io.ErrUnexpectedEOF), interfaces.BadRequestError, "UNEXPECTED_EOF"},
+		{"deadline", context.DeadlineExceeded, interfaces.TimeoutError, "DEADLINE_EXCEEDED"},
+		{"net op error", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, interfaces.ExternalServiceError, "NETWORK_ERROR"},
+		{"net timeout", &url.Error{Op: "Get", URL: "http://api", Err: timeoutError{}}, interfaces.TimeoutError, "NETWORK_TIMEOUT"},
+		{"pgx no rows", pgx.ErrNoRows, interfaces.NotFoundError, "NOT_FOUND"},
+		{"pg unique", &pgconn.PgError{Code: "23505"}, interfaces.ConflictError, "UNIQUE_VIOLATION"},
+		{"pg foreign key", fmt.Errorf("insert order: %w", &pgconn.PgError{Code: "23503"}), interfaces.ConflictError, "FOREIGN_KEY_VIOLATION"},
+		{"pg not null", &pgconn.PgError{Code: "23502"}, interfaces.ValidationError, "CONSTRAINT_VIOLATION"},
+		{"pg data exception", &pgconn.PgError{Code: "22P02"}, interfaces.ValidationError, "INVALID_DATA"},
+		{"pg deadlock", &pgconn.PgError{Code: "40P01"}, interfaces.ConflictError, "SERIALIZATION_FAILURE"},
+		{"pg canceled", &pgconn.PgError{Code: "57014"}, interfaces.TimeoutError, "QUERY_CANCELED"},
+		{"pg connection", &pgconn.PgError{Code: "08006"}, interfaces.ServiceUnavailableError, "DATABASE_UNAVAILABLE"},
+		{"pg other", &pgconn.PgError{Code: "42P01"}, interfaces.DatabaseError, "DATABASE_ERROR"},
+		{"unknown", errors.New("boom"), interfaces.ServerError, UntranslatedErrorCode},
+	}
+
+	factory := NewErrorFactory(internal.DefaultStackTraceCapture())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := factory.WithCause(tt.err)
+
+			require.NotNil(t, err)
+			assert.Equal(t, tt.errorType, err.Type())
+			assert.Equal(t, tt.code, err.Code())
+			assert.ErrorIs(t, err, tt.err)
+			assert.NotNil(t, err.Metadata())
+		})
+	}
+}
+
+func TestErrorFactory_WithCause_Metadata(t *testing.T) {
+	t.Parallel()
+
+	err := WithCause(&pgconn.PgError{Code: "40001"})
+	assert.Equal(t, "40001", err.Metadata()["sqlstate"])
+	assert.Equal(t, true, err.Metadata()["retryable"])
+
+	var pgErr *pgconn.PgError
+	require.True(t, errors.As(err, &pgErr))
+	assert.Equal(t, "40001", pgErr.Code)
+
+	err = WithCause(&net.OpError{Op: "dial", Net: "tcp", Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5432}, Err: errors.New("refused")})
+	assert.Equal(t, "dial", err.Metadata()["op"])
+	assert.Equal(t, "10.0.0.1:5432", err.Metadata()["addr"])
+}
+
+func TestErrorFactory_WithCause_DomainError(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, WithCause(nil))
+
+	original := NewNotFoundError("USER_NOT_FOUND", "user not found")
+	assert.Same(t, original, WithCause(original))
+	assert.Same(t, original, WithCause(fmt.Errorf("lookup: %w", original)))
+}
+
+func TestErrorFactory_Wrap_Translates(t *testing.T) {
+	t.Parallel()
+
+	err := Wrap(pgx.ErrNoRows, "", "", "")
+	assert.Equal(t, interfaces.NotFoundError, err.Type())
+	assert.Equal(t, "NOT_FOUND", err.Code())
+	assert.True(t, errors.Is(err, pgx.ErrNoRows))
+
+	err = Wrap(&pgconn.PgError{Code: "40001"}, "", "", "retry later")
+	assert.Equal(t, interfaces.ConflictError, err.Type())
+	assert.Equal(t, "SERIALIZATION_FAILURE", err.Code())
+	assert.Equal(t, "retry later", err.Error())
+	assert.Equal(t, true, err.Metadata()["retryable"])
+
+	err = Wrap(pgx.ErrNoRows, interfaces.ValidationError, "", "")
+	assert.Equal(t, interfaces.ValidationError, err.Type())
+	assert.Equal(t, "NOT_FOUND", err.Code())
+
+	err = Wrap(pgx.ErrNoRows, interfaces.DatabaseError, "USER_LOOKUP", "lookup failed")
+	assert.Equal(t, interfaces.DatabaseError, err.Type())
+	assert.Equal(t, "USER_LOOKUP", err.Code())
+	assert.Empty(t, err.Metadata())
+
+	err = Wrap(errors.New("boom"), "", "", "")
+	assert.Equal(t, interfaces.ServerError, err.Type())
+	assert.Equal(t, UntranslatedErrorCode, err.Code())
+}
+
+func TestTranslationRegistry(t *testing.T) {
+	t.Parallel()
+
+	errQuota := errors.New("quota exceeded")
+	factory := NewErrorFactory(internal.DefaultStackTraceCapture())
+	registry := factory.Translations()
+
+	registry.Register("quota", func(err error) (Translation, bool) {
+		if errors.Is(err, errQuota) {
+			return Translation{Type: interfaces.RateLimitError, Code: "QUOTA_EXCEEDED", Message: "quota exceeded"}, true
+		}
+		return Translation{}, false
+	})
+	assert.Equal(t, "quota", registry.Names()[0])
+
+	err := factory.WithCause(errQuota)
+	assert.Equal(t, interfaces.RateLimitError, err.Type())
+	assert.Equal(t, "QUOTA_EXCEEDED", err.Code())
+
+	// Tradutores registrados depois têm precedência sobre os padrões
+	registry.Register("eof-override", func(err error) (Translation, bool) {
+		if errors.Is(err, io.EOF) {
+			return Translation{Type: interfaces.ValidationError, Code: "EMPTY_BODY", Message: "empty body"}, true
+		}
+		return Translation{}, false
+	})
+	assert.Equal(t, "EMPTY_BODY", factory.WithCause(io.EOF).Code())
+
+	registry.Unregister("eof-override")
+	assert.Equal(t, "UNEXPECTED_EOF", factory.WithCause(io.EOF).Code())
+
+	registry.Unregister("postgres")
+	assert.Equal(t, UntranslatedErrorCode, factory.WithCause(&pgconn.PgError{Code: "23505"}).Code())
+	assert.NotContains(t, registry.Names(), "postgres")
+
+	_, ok := registry.Translate(nil)
+	assert.False(t, ok)
+}
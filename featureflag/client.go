@@ -0,0 +1,199 @@
+// Package featureflag evaluates feature flags of boolean, string, number and
+// JSON values for an evaluation context (user, tenant and attributes), with
+// rule-based targeting and percentage rollouts. Flag definitions come from
+// providers: static definitions, configuration files and environment
+// variables. Typed accessors fall back to the caller default when a flag is
+// missing, disabled or of another type, and an OpenFeature-compatible adapter
+// exposes the client to OpenFeature SDKs.
+package featureflag
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// Evaluation errors.
+var (
+	ErrFlagNotFound = errors.New("featureflag: flag not found")
+	ErrTypeMismatch = errors.New("featureflag: type mismatch")
+	ErrInvalidFlag  = errors.New("featureflag: invalid flag")
+)
+
+// Evaluation reasons, as defined by OpenFeature.
+const (
+	ReasonStatic         = "STATIC"
+	ReasonDefault        = "DEFAULT"
+	ReasonTargetingMatch = "TARGETING_MATCH"
+	ReasonSplit          = "SPLIT"
+	ReasonDisabled       = "DISABLED"
+	ReasonError          = "ERROR"
+)
+
+// Evaluation is the result of evaluating a flag.
+type Evaluation struct {
+	Key     string
+	Variant string
+	Value   any
+	Reason  string
+	Err     error
+}
+
+// Hook observes evaluations, e.g. to record metrics or exposure events.
+type Hook func(ctx context.Context, ec EvalContext, evaluation Evaluation)
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHook adds a hook called after each evaluation.
+func WithHook(hook Hook) Option {
+	return func(c *Client) {
+		if hook != nil {
+			c.hooks = append(c.hooks, hook)
+		}
+	}
+}
+
+// Client evaluates the flags of a provider.
+type Client struct {
+	provider Provider
+	hooks    []Hook
+}
+
+// New creates a Client.
+func New(provider Provider, opts ...Option) *Client {
+	c := &Client{provider: provider}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Evaluate evaluates the flag for the evaluation context carried by ctx.
+func (c *Client) Evaluate(ctx context.Context, key string) Evaluation {
+	return c.EvaluateFor(ctx, key, EvalContextFromContext(ctx))
+}
+
+// EvaluateFor evaluates the flag for the evaluation context.
+func (c *Client) EvaluateFor(ctx context.Context, key string, ec EvalContext) Evaluation {
+	evaluation := c.evaluate(ctx, key, ec)
+	c.notify(ctx, ec, evaluation)
+	return evaluation
+}
+
+// evaluate evaluates the flag without notifying the hooks.
+func (c *Client) evaluate(ctx context.Context, key string, ec EvalContext) Evaluation {
+	flag, err := c.provider.Flag(ctx, key)
+	if err != nil {
+		return Evaluation{Key: key, Reason: ReasonError, Err: err}
+	}
+	if flag.Disabled {
+		return Evaluation{Key: key, Reason: ReasonDisabled}
+	}
+
+	variant, reason := flag.evaluate(ec)
+	return Evaluation{Key: key, Variant: variant, Value: flag.Variants[variant], Reason: reason}
+}
+
+// notify calls the hooks.
+func (c *Client) notify(ctx context.Context, ec EvalContext, evaluation Evaluation) {
+	for _, hook := range c.hooks {
+		hook(ctx, ec, evaluation)
+	}
+}
+
+// Bool returns the value of a boolean flag, or def.
+func (c *Client) Bool(ctx context.Context, key string, def bool) bool {
+	return Get(ctx, c, key, def)
+}
+
+// String returns the value of a string flag, or def.
+func (c *Client) String(ctx context.Context, key string, def string) string {
+	return Get(ctx, c, key, def)
+}
+
+// Float returns the value of a number flag, or def.
+func (c *Client) Float(ctx context.Context, key string, def float64) float64 {
+	return Get(ctx, c, key, def)
+}
+
+// Int returns the value of a number flag, or def when it is not an integer.
+func (c *Client) Int(ctx context.Context, key string, def int64) int64 {
+	return Get(ctx, c, key, def)
+}
+
+// Get returns the value of the flag as T, or def when the flag is missing,
+// disabled or not convertible to T. JSON values are decoded into maps,
+// slices and structs.
+func Get[T any](ctx context.Context, c *Client, key string, def T) T {
+	value, _ := GetDetails(ctx, c, key, def)
+	return value
+}
+
+// GetDetails is Get returning the evaluation too.
+func GetDetails[T any](ctx context.Context, c *Client, key string, def T) (T, Evaluation) {
+	ec := EvalContextFromContext(ctx)
+	evaluation := c.evaluate(ctx, key, ec)
+
+	value := def
+	if evaluation.Err == nil && evaluation.Reason != ReasonDisabled {
+		if converted, ok := convert[T](evaluation.Value); ok {
+			value = converted
+		} else {
+			evaluation.Reason = ReasonError
+			evaluation.Err = fmt.Errorf("%w: flag %q of type %T is not a %T", ErrTypeMismatch, key, evaluation.Value, def)
+		}
+	}
+	if evaluation.Err != nil || evaluation.Reason == ReasonDisabled {
+		evaluation.Value = def
+	}
+
+	c.notify(ctx, ec, evaluation)
+	return value, evaluation
+}
+
+// convert converts a variant value to T.
+func convert[T any](value any) (T, bool) {
+	var out T
+	if v, ok := value.(T); ok {
+		return v, true
+	}
+
+	target := reflect.ValueOf(&out).Elem()
+	switch target.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		f, ok := value.(float64)
+		if !ok || f != math.Trunc(f) || target.OverflowInt(int64(f)) {
+			return out, false
+		}
+		target.SetInt(int64(f))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		f, ok := value.(float64)
+		if !ok || f < 0 || f != math.Trunc(f) || target.OverflowUint(uint64(f)) {
+			return out, false
+		}
+		target.SetUint(uint64(f))
+	case reflect.Float32:
+		f, ok := value.(float64)
+		if !ok {
+			return out, false
+		}
+		target.SetFloat(f)
+	case reflect.Map, reflect.Slice, reflect.Struct, reflect.Pointer:
+		switch value.(type) {
+		case map[string]any, []any:
+		default:
+			return out, false
+		}
+		data, err := json.Marshal(value)
+		if err != nil || json.Unmarshal(data, &out) != nil {
+			return out, false
+		}
+	default:
+		return out, false
+	}
+	return out, true
+}
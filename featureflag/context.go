@@ -0,0 +1,62 @@
+package featureflag
+
+import "context"
+
+// Attributes of the evaluation context with dedicated fields.
+const (
+	AttributeUserID   = "user_id"
+	AttributeTenantID = "tenant_id"
+)
+
+// EvalContext describes who a flag is evaluated for.
+type EvalContext struct {
+	UserID     string
+	TenantID   string
+	Attributes map[string]any
+}
+
+// TargetingKey returns the key assigning the context to rollout buckets: the
+// user, or the tenant for contexts without user.
+func (ec EvalContext) TargetingKey() string {
+	if ec.UserID != "" {
+		return ec.UserID
+	}
+	return ec.TenantID
+}
+
+// Lookup returns the value of an attribute.
+func (ec EvalContext) Lookup(attribute string) (any, bool) {
+	switch attribute {
+	case AttributeUserID:
+		return ec.UserID, ec.UserID != ""
+	case AttributeTenantID:
+		return ec.TenantID, ec.TenantID != ""
+	}
+	value, ok := ec.Attributes[attribute]
+	return value, ok
+}
+
+// With returns a copy of the context with the attribute set.
+func (ec EvalContext) With(attribute string, value any) EvalContext {
+	attributes := make(map[string]any, len(ec.Attributes)+1)
+	for k, v := range ec.Attributes {
+		attributes[k] = v
+	}
+	attributes[attribute] = value
+	ec.Attributes = attributes
+	return ec
+}
+
+type evalContextKey struct{}
+
+// WithEvalContext returns a copy of ctx carrying the evaluation context.
+func WithEvalContext(ctx context.Context, ec EvalContext) context.Context {
+	return context.WithValue(ctx, evalContextKey{}, ec)
+}
+
+// EvalContextFromContext returns the evaluation context carried by ctx, or
+// an empty one.
+func EvalContextFromContext(ctx context.Context) EvalContext {
+	ec, _ := ctx.Value(evalContextKey{}).(EvalContext)
+	return ec
+}
@@ -0,0 +1,275 @@
+package featureflag
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func checkoutFlag() Flag {
+	return Flag{
+		Key:            "new-checkout",
+		Variants:       map[string]any{"on": true, "off": false},
+		DefaultVariant: "off",
+		Rules: []Rule{
+			{
+				Name:       "beta tenants",
+				Conditions: []Condition{{Attribute: AttributeTenantID, Operator: OpIn, Values: []any{"acme", "globex"}}},
+				Variant:    "on",
+			},
+			{
+				Name: "internal users",
+				Conditions: []Condition{
+					{Attribute: "email", Operator: OpEndsWith, Values: []any{"@example.com"}},
+					{Attribute: "age", Operator: OpGreaterEq, Values: []any{18}},
+				},
+				Variant: "on",
+			},
+		},
+		Rollout: []Split{{Variant: "on", Weight: 20}, {Variant: "off", Weight: 80}},
+	}
+}
+
+func newTestClient(t *testing.T, flags ...Flag) *Client {
+	t.Helper()
+	provider, err := NewStaticProvider(flags...)
+	require.NoError(t, err)
+	return New(provider)
+}
+
+func TestFlag_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		flag Flag
+	}{
+		{"no key", Flag{Variants: map[string]any{"on": true}, DefaultVariant: "on"}},
+		{"no variants", Flag{Key: "f"}},
+		{"unknown default", Flag{Key: "f", Variants: map[string]any{"on": true}, DefaultVariant: "off"}},
+		{"mixed types", Flag{Key: "f", Variants: map[string]any{"on": true, "off": "no"}, DefaultVariant: "on"}},
+		{"weights", Flag{Key: "f", Variants: map[string]any{"on": true}, DefaultVariant: "on",
+			Rollout: []Split{{Variant: "on", Weight: 50}}}},
+		{"unknown operator", Flag{Key: "f", Variants: map[string]any{"on": true}, DefaultVariant: "on",
+			Rules: []Rule{{Conditions: []Condition{{Attribute: "a", Operator: "like"}}, Variant: "on"}}}},
+		{"invalid pattern", Flag{Key: "f", Variants: map[string]any{"on": true}, DefaultVariant: "on",
+			Rules: []Rule{{Conditions: []Condition{{Attribute: "a", Operator: OpMatches, Values: []any{"("}}}, Variant: "on"}}}},
+		{"rule without outcome", Flag{Key: "f", Variants: map[string]any{"on": true}, DefaultVariant: "on",
+			Rules: []Rule{{Conditions: []Condition{{Attribute: "a", Operator: OpExists}}}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.flag.Validate(), ErrInvalidFlag)
+		})
+	}
+
+	flag := checkoutFlag()
+	require.NoError(t, flag.Validate())
+}
+
+func TestClient_Targeting(t *testing.T) {
+	client := newTestClient(t, checkoutFlag())
+
+	tests := []struct {
+		name    string
+		ec      EvalContext
+		variant string
+		reason  string
+	}{
+		{"tenant rule", EvalContext{UserID: "u1", TenantID: "acme"}, "on", ReasonTargetingMatch},
+		{"attribute rule", EvalContext{UserID: "u1", Attributes: map[string]any{"email": "ana@example.com", "age": 30}}, "on", ReasonTargetingMatch},
+		{"partial match", EvalContext{Attributes: map[string]any{"email": "ana@example.com", "age": 16}}, "off", ReasonDefault},
+		{"no targeting key", EvalContext{}, "off", ReasonDefault},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluation := client.EvaluateFor(context.Background(), "new-checkout", tt.ec)
+			require.NoError(t, evaluation.Err)
+			assert.Equal(t, tt.variant, evaluation.Variant)
+			assert.Equal(t, tt.reason, evaluation.Reason)
+		})
+	}
+
+	ctx := WithEvalContext(context.Background(), EvalContext{TenantID: "globex"})
+	assert.True(t, client.Bool(ctx, "new-checkout", false))
+}
+
+func TestClient_Rollout(t *testing.T) {
+	client := newTestClient(t, checkoutFlag())
+	ctx := context.Background()
+
+	on := 0
+	for i := 0; i < 10000; i++ {
+		ec := EvalContext{UserID: fmt.Sprintf("user-%d", i)}
+		evaluation := client.EvaluateFor(ctx, "new-checkout", ec)
+		assert.Equal(t, ReasonSplit, evaluation.Reason)
+		if evaluation.Value == true {
+			on++
+		}
+		// Users keep their variant across evaluations
+		assert.Equal(t, evaluation.Variant, client.EvaluateFor(ctx, "new-checkout", ec).Variant)
+	}
+	assert.InDelta(t, 2000, on, 200)
+
+	// Tenant-level rollouts put all users of a tenant in the same bucket
+	flag := checkoutFlag()
+	flag.Rules = nil
+	flag.BucketBy = AttributeTenantID
+	flag.Rollout = []Split{{Variant: "on", Weight: 50}, {Variant: "off", Weight: 50}}
+	client = newTestClient(t, flag)
+	first := client.EvaluateFor(ctx, "new-checkout", EvalContext{UserID: "a", TenantID: "acme"})
+	for i := 0; i < 20; i++ {
+		ec := EvalContext{UserID: fmt.Sprintf("user-%d", i), TenantID: "acme"}
+		assert.Equal(t, first.Variant, client.EvaluateFor(ctx, "new-checkout", ec).Variant)
+	}
+}
+
+func TestClient_TypedAccessors(t *testing.T) {
+	type limits struct {
+		Requests int      `json:"requests"`
+		Regions  []string `json:"regions"`
+	}
+	client := newTestClient(t,
+		Flag{Key: "theme", Variants: map[string]any{"dark": "dark", "light": "light"}, DefaultVariant: "dark"},
+		Flag{Key: "max-items", Variants: map[string]any{"small": 10, "large": 100.5}, DefaultVariant: "small"},
+		Flag{Key: "ratio", Variants: map[string]any{"v": 100.5}, DefaultVariant: "v"},
+		Flag{Key: "limits", Variants: map[string]any{"v": map[string]any{"requests": 5, "regions": []string{"br", "us"}}}, DefaultVariant: "v"},
+		Flag{Key: "off", Disabled: true, Variants: map[string]any{"v": true}, DefaultVariant: "v"},
+	)
+	ctx := context.Background()
+
+	assert.Equal(t, "dark", client.String(ctx, "theme", "light"))
+	assert.Equal(t, int64(10), client.Int(ctx, "max-items", 1))
+	assert.Equal(t, 10, Get(ctx, client, "max-items", 1))
+	assert.Equal(t, 100.5, client.Float(ctx, "ratio", 0))
+	assert.Equal(t, int64(7), client.Int(ctx, "ratio", 7), "non-integers fall back")
+	assert.Equal(t, limits{Requests: 5, Regions: []string{"br", "us"}}, Get(ctx, client, "limits", limits{}))
+
+	value, evaluation := GetDetails(ctx, client, "theme", false)
+	assert.False(t, value)
+	assert.ErrorIs(t, evaluation.Err, ErrTypeMismatch)
+	assert.Equal(t, ReasonError, evaluation.Reason)
+
+	value, evaluation = GetDetails(ctx, client, "missing", true)
+	assert.True(t, value)
+	assert.ErrorIs(t, evaluation.Err, ErrFlagNotFound)
+
+	value, evaluation = GetDetails(ctx, client, "off", false)
+	assert.False(t, value)
+	assert.Equal(t, ReasonDisabled, evaluation.Reason)
+}
+
+func TestClient_Hooks(t *testing.T) {
+	var evaluations []Evaluation
+	provider, err := NewStaticProvider(checkoutFlag())
+	require.NoError(t, err)
+	client := New(provider, WithHook(func(ctx context.Context, ec EvalContext, evaluation Evaluation) {
+		evaluations = append(evaluations, evaluation)
+	}))
+
+	ctx := WithEvalContext(context.Background(), EvalContext{TenantID: "acme"})
+	client.Bool(ctx, "new-checkout", false)
+	client.String(ctx, "new-checkout", "x")
+
+	require.Len(t, evaluations, 2)
+	assert.Equal(t, "on", evaluations[0].Variant)
+	assert.ErrorIs(t, evaluations[1].Err, ErrTypeMismatch)
+	assert.Equal(t, "x", evaluations[1].Value)
+}
+
+func TestFileProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+flags:
+  new-checkout:
+    variants:
+      "on": true
+      "off": false
+    default_variant: "off"
+    rules:
+      - name: beta
+        conditions:
+          - attribute: tenant_id
+            operator: in
+            values: [acme]
+        variant: "on"
+  page-size:
+    variants: {default: 20}
+    default_variant: default
+`), 0o600))
+
+	provider, err := NewFileProvider(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"new-checkout", "page-size"}, provider.Keys())
+
+	client := New(provider)
+	ctx := WithEvalContext(context.Background(), EvalContext{TenantID: "acme"})
+	assert.True(t, client.Bool(ctx, "new-checkout", false))
+	assert.Equal(t, int64(20), client.Int(ctx, "page-size", 0))
+
+	// Invalid files keep the flags
+	require.NoError(t, os.WriteFile(path, []byte("flags:\n  broken:\n    default_variant: x\n"), 0o600))
+	assert.ErrorIs(t, provider.Reload(), ErrInvalidFlag)
+	assert.Equal(t, int64(20), client.Int(ctx, "page-size", 0))
+
+	flags, err := ParseFlags(".json", []byte(`{"flags": {"page-size": {"variants": {"v": 50}, "default_variant": "v"}}}`))
+	require.NoError(t, err)
+	require.Len(t, flags, 1)
+	assert.Equal(t, "page-size", flags[0].Key)
+}
+
+func TestEnvProvider(t *testing.T) {
+	t.Setenv("FEATURE_NEW_CHECKOUT", "true")
+	t.Setenv("FEATURE_THEME", "dark")
+	t.Setenv("FEATURE_LIMITS", `{"requests": 5}`)
+
+	file, err := NewStaticProvider(
+		Flag{Key: "new-checkout", Variants: map[string]any{"v": false}, DefaultVariant: "v"},
+		Flag{Key: "page-size", Variants: map[string]any{"v": 20}, DefaultVariant: "v"},
+	)
+	require.NoError(t, err)
+	client := New(NewChainProvider(NewEnvProvider("FEATURE"), file))
+	ctx := context.Background()
+
+	assert.True(t, client.Bool(ctx, "new-checkout", false), "environment overrides the file")
+	assert.Equal(t, int64(20), client.Int(ctx, "page-size", 0))
+	assert.Equal(t, "dark", client.String(ctx, "theme", "light"))
+	assert.Equal(t, map[string]any{"requests": 5.0}, Get[map[string]any](ctx, client, "limits", nil))
+
+	_, evaluation := GetDetails(ctx, client, "missing", false)
+	assert.ErrorIs(t, evaluation.Err, ErrFlagNotFound)
+}
+
+func TestOpenFeatureProvider(t *testing.T) {
+	client := newTestClient(t, checkoutFlag(),
+		Flag{Key: "page-size", Variants: map[string]any{"v": 20}, DefaultVariant: "v"})
+	p := NewOpenFeatureProvider(client, "")
+	ctx := context.Background()
+
+	assert.Equal(t, "nexs-featureflag", p.Metadata().Name)
+
+	detail := p.BooleanEvaluation(ctx, "new-checkout", false, map[string]any{TargetingKey: "u1", "tenant_id": "acme"})
+	assert.True(t, detail.Value)
+	assert.Equal(t, "on", detail.Variant)
+	assert.Equal(t, ReasonTargetingMatch, detail.Reason)
+
+	intDetail := p.IntEvaluation(ctx, "page-size", 1, nil)
+	assert.Equal(t, int64(20), intDetail.Value)
+	assert.Equal(t, ReasonStatic, intDetail.Reason)
+
+	stringDetail := p.StringEvaluation(ctx, "page-size", "x", nil)
+	assert.Equal(t, "x", stringDetail.Value)
+	assert.Equal(t, ErrorCodeTypeMismatch, stringDetail.ErrorCode)
+
+	objectDetail := p.ObjectEvaluation(ctx, "missing", "fallback", nil)
+	assert.Equal(t, "fallback", objectDetail.Value)
+	assert.Equal(t, ErrorCodeFlagNotFound, objectDetail.ErrorCode)
+	assert.Equal(t, ReasonError, objectDetail.Reason)
+
+	ec := FromOpenFeature(map[string]any{TargetingKey: "u1", "plan": "pro"})
+	assert.Equal(t, "u1", ec.UserID)
+	assert.Equal(t, map[string]any{"plan": "pro"}, ec.Attributes)
+}
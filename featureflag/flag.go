@@ -0,0 +1,376 @@
+package featureflag
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Condition operators.
+const (
+	OpEquals     = "eq"
+	OpNotEquals  = "neq"
+	OpIn         = "in"
+	OpNotIn      = "not_in"
+	OpContains   = "contains"
+	OpStartsWith = "starts_with"
+	OpEndsWith   = "ends_with"
+	OpMatches    = "matches"
+	OpGreater    = "gt"
+	OpGreaterEq  = "gte"
+	OpLess       = "lt"
+	OpLessEq     = "lte"
+	OpExists     = "exists"
+)
+
+// Flag is the definition of a feature flag. Its variants are the values it
+// can take; rules are evaluated in order, and the first matching rule
+// selects the variant. Contexts matched by no rule get the rollout, or the
+// default variant.
+type Flag struct {
+	Key            string         `json:"key"`
+	Description    string         `json:"description,omitempty"`
+	Disabled       bool           `json:"disabled,omitempty"`
+	Variants       map[string]any `json:"variants"`
+	DefaultVariant string         `json:"default_variant"`
+	Rules          []Rule         `json:"rules,omitempty"`
+	Rollout        []Split        `json:"rollout,omitempty"`
+
+	// BucketBy is the attribute assigning contexts to rollout buckets.
+	// Defaults to the targeting key, see EvalContext.
+	BucketBy string `json:"bucket_by,omitempty"`
+}
+
+// Rule selects a variant, or a rollout, for the contexts matching all its
+// conditions.
+type Rule struct {
+	Name       string      `json:"name,omitempty"`
+	Conditions []Condition `json:"conditions"`
+	Variant    string      `json:"variant,omitempty"`
+	Rollout    []Split     `json:"rollout,omitempty"`
+}
+
+// Condition compares an attribute of the evaluation context with values.
+// The attributes "user_id" and "tenant_id" are the user and tenant of the
+// context; others are looked up in its attributes.
+type Condition struct {
+	Attribute string `json:"attribute"`
+	Operator  string `json:"operator"`
+	Values    []any  `json:"values,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// Split is the share of a rollout, in percent, getting a variant.
+type Split struct {
+	Variant string  `json:"variant"`
+	Weight  float64 `json:"weight"`
+}
+
+// Validate checks the flag definition and prepares it for evaluation.
+// Providers validate the flags they return.
+func (f *Flag) Validate() error {
+	if f.Key == "" {
+		return fmt.Errorf("%w: key is required", ErrInvalidFlag)
+	}
+	if len(f.Variants) == 0 {
+		return fmt.Errorf("%w: flag %q has no variants", ErrInvalidFlag, f.Key)
+	}
+
+	var kind string
+	for name, value := range f.Variants {
+		normalized, k, err := normalizeValue(value)
+		if err != nil {
+			return fmt.Errorf("%w: variant %q of flag %q: %v", ErrInvalidFlag, name, f.Key, err)
+		}
+		if kind != "" && k != kind {
+			return fmt.Errorf("%w: variants of flag %q mix %s and %s values", ErrInvalidFlag, f.Key, kind, k)
+		}
+		kind = k
+		f.Variants[name] = normalized
+	}
+
+	if err := f.checkVariant(f.DefaultVariant); err != nil {
+		return err
+	}
+	if err := f.checkRollout(f.Rollout); err != nil {
+		return err
+	}
+	for i := range f.Rules {
+		rule := &f.Rules[i]
+		if (rule.Variant == "") == (len(rule.Rollout) == 0) {
+			return fmt.Errorf("%w: rule %d of flag %q needs either a variant or a rollout", ErrInvalidFlag, i, f.Key)
+		}
+		if rule.Variant != "" {
+			if err := f.checkVariant(rule.Variant); err != nil {
+				return err
+			}
+		}
+		if err := f.checkRollout(rule.Rollout); err != nil {
+			return err
+		}
+		for j := range rule.Conditions {
+			if err := rule.Conditions[j].compile(); err != nil {
+				return fmt.Errorf("%w: rule %d of flag %q: %v", ErrInvalidFlag, i, f.Key, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkVariant checks that the variant exists.
+func (f *Flag) checkVariant(variant string) error {
+	if _, ok := f.Variants[variant]; !ok {
+		return fmt.Errorf("%w: flag %q has no variant %q", ErrInvalidFlag, f.Key, variant)
+	}
+	return nil
+}
+
+// checkRollout checks the variants of a rollout and that its weights add up
+// to 100.
+func (f *Flag) checkRollout(rollout []Split) error {
+	if len(rollout) == 0 {
+		return nil
+	}
+	var total float64
+	for _, split := range rollout {
+		if err := f.checkVariant(split.Variant); err != nil {
+			return err
+		}
+		if split.Weight < 0 {
+			return fmt.Errorf("%w: negative rollout weight in flag %q", ErrInvalidFlag, f.Key)
+		}
+		total += split.Weight
+	}
+	if total < 99.999 || total > 100.001 {
+		return fmt.Errorf("%w: rollout weights of flag %q add up to %g, not 100", ErrInvalidFlag, f.Key, total)
+	}
+	return nil
+}
+
+// compile checks the operator and compiles regular expressions.
+func (c *Condition) compile() error {
+	if c.Attribute == "" {
+		return fmt.Errorf("condition without attribute")
+	}
+	switch c.Operator {
+	case OpEquals, OpNotEquals, OpIn, OpNotIn, OpContains, OpStartsWith, OpEndsWith,
+		OpGreater, OpGreaterEq, OpLess, OpLessEq:
+		if len(c.Values) == 0 {
+			return fmt.Errorf("operator %q needs values", c.Operator)
+		}
+	case OpMatches:
+		if len(c.Values) != 1 {
+			return fmt.Errorf("operator %q needs one pattern", c.Operator)
+		}
+		pattern, err := regexp.Compile(fmt.Sprint(c.Values[0]))
+		if err != nil {
+			return err
+		}
+		c.pattern = pattern
+	case OpExists:
+	default:
+		return fmt.Errorf("unknown operator %q", c.Operator)
+	}
+	return nil
+}
+
+// evaluate selects the variant of the flag for the context.
+func (f *Flag) evaluate(ec EvalContext) (variant, reason string) {
+	for _, rule := range f.Rules {
+		if !rule.matches(ec) {
+			continue
+		}
+		if rule.Variant != "" {
+			return rule.Variant, ReasonTargetingMatch
+		}
+		if variant, ok := f.split(rule.Rollout, ec); ok {
+			return variant, ReasonTargetingMatch
+		}
+	}
+	if variant, ok := f.split(f.Rollout, ec); ok {
+		return variant, ReasonSplit
+	}
+	if len(f.Rules) == 0 && len(f.Rollout) == 0 {
+		return f.DefaultVariant, ReasonStatic
+	}
+	return f.DefaultVariant, ReasonDefault
+}
+
+// split assigns the context to a variant of the rollout. Contexts without
+// bucketing value get no variant.
+func (f *Flag) split(rollout []Split, ec EvalContext) (string, bool) {
+	if len(rollout) == 0 {
+		return "", false
+	}
+	key := ec.TargetingKey()
+	if f.BucketBy != "" {
+		value, ok := ec.Lookup(f.BucketBy)
+		if !ok {
+			return "", false
+		}
+		key = fmt.Sprint(value)
+	}
+	if key == "" {
+		return "", false
+	}
+
+	point := bucket(f.Key, key)
+	var cumulative float64
+	for _, split := range rollout {
+		cumulative += split.Weight
+		if point < cumulative {
+			return split.Variant, true
+		}
+	}
+	return rollout[len(rollout)-1].Variant, true
+}
+
+// bucket maps the key to a point in [0, 100), stable for the flag and
+// independent between flags.
+func bucket(flag, key string) float64 {
+	sum := sha256.Sum256([]byte(flag + "/" + key))
+	return float64(binary.BigEndian.Uint64(sum[:8])%100000) / 1000
+}
+
+// matches reports whether the context matches all conditions.
+func (r Rule) matches(ec EvalContext) bool {
+	for _, c := range r.Conditions {
+		if !c.matches(ec) {
+			return false
+		}
+	}
+	return true
+}
+
+// matches reports whether the context matches the condition.
+func (c Condition) matches(ec EvalContext) bool {
+	value, ok := ec.Lookup(c.Attribute)
+	switch c.Operator {
+	case OpExists:
+		return ok
+	case OpNotEquals, OpNotIn:
+		return !ok || !c.any(value, equal)
+	}
+	if !ok {
+		return false
+	}
+
+	switch c.Operator {
+	case OpEquals, OpIn:
+		return c.any(value, equal)
+	case OpContains:
+		return c.any(value, func(a, b any) bool { return strings.Contains(fmt.Sprint(a), fmt.Sprint(b)) })
+	case OpStartsWith:
+		return c.any(value, func(a, b any) bool { return strings.HasPrefix(fmt.Sprint(a), fmt.Sprint(b)) })
+	case OpEndsWith:
+		return c.any(value, func(a, b any) bool { return strings.HasSuffix(fmt.Sprint(a), fmt.Sprint(b)) })
+	case OpMatches:
+		return c.pattern.MatchString(fmt.Sprint(value))
+	case OpGreater:
+		return c.compare(value, func(d float64) bool { return d > 0 })
+	case OpGreaterEq:
+		return c.compare(value, func(d float64) bool { return d >= 0 })
+	case OpLess:
+		return c.compare(value, func(d float64) bool { return d < 0 })
+	case OpLessEq:
+		return c.compare(value, func(d float64) bool { return d <= 0 })
+	}
+	return false
+}
+
+// any reports whether the value matches any of the condition values.
+func (c Condition) any(value any, match func(a, b any) bool) bool {
+	for _, v := range c.Values {
+		if match(value, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// compare compares the value with the first condition value as numbers.
+func (c Condition) compare(value any, check func(diff float64) bool) bool {
+	a, ok := toFloat(value)
+	if !ok {
+		return false
+	}
+	b, ok := toFloat(c.Values[0])
+	return ok && check(a-b)
+}
+
+// equal compares values as numbers when both are numeric, and as strings
+// otherwise.
+func equal(a, b any) bool {
+	if x, ok := toFloat(a); ok {
+		if y, ok := toFloat(b); ok {
+			return x == y
+		}
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+// toFloat converts numbers and numeric strings to float64.
+func toFloat(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// normalizeValue converts a variant value to bool, string, float64 or its
+// JSON form of maps and slices, and returns its kind.
+func normalizeValue(v any) (any, string, error) {
+	switch v := v.(type) {
+	case bool:
+		return v, "bool", nil
+	case string:
+		return v, "string", nil
+	case nil:
+		return nil, "", fmt.Errorf("null value")
+	}
+	if f, ok := toFloat(v); ok {
+		return f, "number", nil
+	}
+
+	kind := reflect.TypeOf(v).Kind()
+	if kind != reflect.Map && kind != reflect.Slice && kind != reflect.Struct {
+		return nil, "", fmt.Errorf("unsupported value type %T", v)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, "", err
+	}
+	var normalized any
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, "", err
+	}
+	return normalized, "object", nil
+}
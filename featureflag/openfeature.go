@@ -0,0 +1,159 @@
+package featureflag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// OpenFeature error codes.
+const (
+	ErrorCodeFlagNotFound = "FLAG_NOT_FOUND"
+	ErrorCodeTypeMismatch = "TYPE_MISMATCH"
+	ErrorCodeParseError   = "PARSE_ERROR"
+	ErrorCodeGeneral      = "GENERAL"
+)
+
+// TargetingKey is the OpenFeature attribute of the targeting key.
+const TargetingKey = "targetingKey"
+
+// ProviderMetadata describes the provider to OpenFeature.
+type ProviderMetadata struct {
+	Name string
+}
+
+// ResolutionDetail is the OpenFeature resolution of a flag.
+type ResolutionDetail struct {
+	Variant      string
+	Reason       string
+	ErrorCode    string
+	ErrorMessage string
+}
+
+// BoolResolutionDetail is the resolution of a boolean flag.
+type BoolResolutionDetail struct {
+	Value bool
+	ResolutionDetail
+}
+
+// StringResolutionDetail is the resolution of a string flag.
+type StringResolutionDetail struct {
+	Value string
+	ResolutionDetail
+}
+
+// FloatResolutionDetail is the resolution of a number flag.
+type FloatResolutionDetail struct {
+	Value float64
+	ResolutionDetail
+}
+
+// IntResolutionDetail is the resolution of an integer flag.
+type IntResolutionDetail struct {
+	Value int64
+	ResolutionDetail
+}
+
+// InterfaceResolutionDetail is the resolution of an object flag.
+type InterfaceResolutionDetail struct {
+	Value any
+	ResolutionDetail
+}
+
+// OpenFeatureProvider adapts a Client to the provider contract of the
+// OpenFeature Go SDK: its methods have the signatures of FeatureProvider,
+// with flattened evaluation contexts as maps. Wrapping it into the SDK types
+// is a field-by-field copy, so this package does not depend on the SDK.
+type OpenFeatureProvider struct {
+	client *Client
+	name   string
+}
+
+// NewOpenFeatureProvider creates an OpenFeatureProvider named name,
+// "nexs-featureflag" when empty.
+func NewOpenFeatureProvider(client *Client, name string) *OpenFeatureProvider {
+	if name == "" {
+		name = "nexs-featureflag"
+	}
+	return &OpenFeatureProvider{client: client, name: name}
+}
+
+// Metadata returns the provider metadata.
+func (p *OpenFeatureProvider) Metadata() ProviderMetadata {
+	return ProviderMetadata{Name: p.name}
+}
+
+// BooleanEvaluation resolves a boolean flag.
+func (p *OpenFeatureProvider) BooleanEvaluation(ctx context.Context, flag string, defaultValue bool, evalCtx map[string]any) BoolResolutionDetail {
+	value, detail := resolve(ctx, p.client, flag, defaultValue, evalCtx)
+	return BoolResolutionDetail{Value: value, ResolutionDetail: detail}
+}
+
+// StringEvaluation resolves a string flag.
+func (p *OpenFeatureProvider) StringEvaluation(ctx context.Context, flag string, defaultValue string, evalCtx map[string]any) StringResolutionDetail {
+	value, detail := resolve(ctx, p.client, flag, defaultValue, evalCtx)
+	return StringResolutionDetail{Value: value, ResolutionDetail: detail}
+}
+
+// FloatEvaluation resolves a number flag.
+func (p *OpenFeatureProvider) FloatEvaluation(ctx context.Context, flag string, defaultValue float64, evalCtx map[string]any) FloatResolutionDetail {
+	value, detail := resolve(ctx, p.client, flag, defaultValue, evalCtx)
+	return FloatResolutionDetail{Value: value, ResolutionDetail: detail}
+}
+
+// IntEvaluation resolves an integer flag.
+func (p *OpenFeatureProvider) IntEvaluation(ctx context.Context, flag string, defaultValue int64, evalCtx map[string]any) IntResolutionDetail {
+	value, detail := resolve(ctx, p.client, flag, defaultValue, evalCtx)
+	return IntResolutionDetail{Value: value, ResolutionDetail: detail}
+}
+
+// ObjectEvaluation resolves a flag of any type, JSON values included.
+func (p *OpenFeatureProvider) ObjectEvaluation(ctx context.Context, flag string, defaultValue any, evalCtx map[string]any) InterfaceResolutionDetail {
+	value, detail := resolve(ctx, p.client, flag, defaultValue, evalCtx)
+	return InterfaceResolutionDetail{Value: value, ResolutionDetail: detail}
+}
+
+// resolve evaluates the flag for a flattened OpenFeature context.
+func resolve[T any](ctx context.Context, client *Client, flag string, def T, evalCtx map[string]any) (T, ResolutionDetail) {
+	ctx = WithEvalContext(ctx, FromOpenFeature(evalCtx))
+	value, evaluation := GetDetails(ctx, client, flag, def)
+
+	detail := ResolutionDetail{Variant: evaluation.Variant, Reason: evaluation.Reason}
+	if evaluation.Err != nil {
+		detail.Variant = ""
+		detail.ErrorMessage = evaluation.Err.Error()
+		switch {
+		case errors.Is(evaluation.Err, ErrFlagNotFound):
+			detail.ErrorCode = ErrorCodeFlagNotFound
+		case errors.Is(evaluation.Err, ErrTypeMismatch):
+			detail.ErrorCode = ErrorCodeTypeMismatch
+		case errors.Is(evaluation.Err, ErrInvalidFlag):
+			detail.ErrorCode = ErrorCodeParseError
+		default:
+			detail.ErrorCode = ErrorCodeGeneral
+		}
+	}
+	return value, detail
+}
+
+// FromOpenFeature converts a flattened OpenFeature context. The user is
+// "user_id", or the targeting key; the tenant is "tenant_id". Other entries
+// are attributes.
+func FromOpenFeature(evalCtx map[string]any) EvalContext {
+	ec := EvalContext{Attributes: make(map[string]any, len(evalCtx))}
+	for key, value := range evalCtx {
+		switch key {
+		case AttributeUserID:
+			ec.UserID = fmt.Sprint(value)
+		case AttributeTenantID:
+			ec.TenantID = fmt.Sprint(value)
+		case TargetingKey:
+		default:
+			ec.Attributes[key] = value
+		}
+	}
+	if key, ok := evalCtx[TargetingKey]; ok && ec.UserID == "" {
+		ec.UserID = fmt.Sprint(key)
+	}
+	return ec
+}
@@ -0,0 +1,207 @@
+package featureflag
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/fsvxavier/nexs-lib/config"
+)
+
+// Provider supplies flag definitions.
+type Provider interface {
+	// Flag returns the validated definition of the flag, or an error
+	// wrapping ErrFlagNotFound.
+	Flag(ctx context.Context, key string) (*Flag, error)
+}
+
+// StaticProvider serves flags defined in code or loaded from files. Its
+// flags can be replaced at runtime.
+type StaticProvider struct {
+	mu    sync.RWMutex
+	flags map[string]*Flag
+}
+
+// NewStaticProvider creates a StaticProvider with the flags.
+func NewStaticProvider(flags ...Flag) (*StaticProvider, error) {
+	p := &StaticProvider{}
+	if err := p.Set(flags...); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Flag implements Provider.
+func (p *StaticProvider) Flag(_ context.Context, key string) (*Flag, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	flag, ok := p.flags[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrFlagNotFound, key)
+	}
+	return flag, nil
+}
+
+// Keys returns the keys of the flags, sorted.
+func (p *StaticProvider) Keys() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	keys := make([]string, 0, len(p.flags))
+	for key := range p.flags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Set validates the flags and replaces all flags with them. On error the
+// flags are kept.
+func (p *StaticProvider) Set(flags ...Flag) error {
+	m := make(map[string]*Flag, len(flags))
+	for i := range flags {
+		flag := flags[i]
+		if err := flag.Validate(); err != nil {
+			return err
+		}
+		if _, ok := m[flag.Key]; ok {
+			return fmt.Errorf("%w: duplicate flag %q", ErrInvalidFlag, flag.Key)
+		}
+		m[flag.Key] = &flag
+	}
+
+	p.mu.Lock()
+	p.flags = m
+	p.mu.Unlock()
+	return nil
+}
+
+// flagsFile is the layout of flag files.
+type flagsFile struct {
+	Flags map[string]Flag `json:"flags"`
+}
+
+// FileProvider serves flags from a YAML, JSON or TOML file, with the layout
+//
+//	flags:
+//	  new-checkout:
+//	    variants: {"on": true, "off": false}
+//	    default_variant: "off"
+//	    rules: [...]
+//	    rollout: [...]
+//
+// Reload reads the file again.
+type FileProvider struct {
+	*StaticProvider
+	path string
+}
+
+// NewFileProvider creates a FileProvider and loads the file.
+func NewFileProvider(path string) (*FileProvider, error) {
+	p := &FileProvider{StaticProvider: &StaticProvider{}, path: path}
+	if err := p.Reload(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Reload reads the file and replaces the flags. On error the flags are
+// kept.
+func (p *FileProvider) Reload() error {
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return fmt.Errorf("featureflag: read %s: %w", p.path, err)
+	}
+	flags, err := ParseFlags(filepath.Ext(p.path), data)
+	if err != nil {
+		return fmt.Errorf("featureflag: %s: %w", p.path, err)
+	}
+	return p.Set(flags...)
+}
+
+// ParseFlags parses flag definitions in the layout of FileProvider. The
+// format is given by a file extension (".yaml", ".yml", ".json" or ".toml").
+func ParseFlags(ext string, data []byte) ([]Flag, error) {
+	values, err := config.Decode(ext, data)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	var file flagsFile
+	if err := json.Unmarshal(encoded, &file); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFlag, err)
+	}
+
+	flags := make([]Flag, 0, len(file.Flags))
+	for key, flag := range file.Flags {
+		flag.Key = key
+		flags = append(flags, flag)
+	}
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Key < flags[j].Key })
+	return flags, nil
+}
+
+// EnvProvider serves flags from environment variables named after the
+// prefix and the flag key, e.g. FEATURE_NEW_CHECKOUT for prefix "FEATURE"
+// and key "new-checkout". Values are parsed as JSON (true, 10, "text",
+// {"a": 1}), and taken as strings otherwise. Each flag has a single variant
+// "env" and no targeting.
+type EnvProvider struct {
+	prefix string
+}
+
+// NewEnvProvider creates an EnvProvider.
+func NewEnvProvider(prefix string) *EnvProvider {
+	return &EnvProvider{prefix: prefix}
+}
+
+// Flag implements Provider.
+func (p *EnvProvider) Flag(_ context.Context, key string) (*Flag, error) {
+	name := config.EnvName(p.prefix, key)
+	raw, ok := os.LookupEnv(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrFlagNotFound, key)
+	}
+
+	var value any = raw
+	var decoded any
+	if err := json.Unmarshal([]byte(strings.TrimSpace(raw)), &decoded); err == nil && decoded != nil {
+		value = decoded
+	}
+
+	flag := &Flag{Key: key, Variants: map[string]any{"env": value}, DefaultVariant: "env"}
+	if err := flag.Validate(); err != nil {
+		return nil, fmt.Errorf("featureflag: %s: %w", name, err)
+	}
+	return flag, nil
+}
+
+// ChainProvider serves each flag from the first provider defining it, e.g.
+// environment overrides before a file.
+type ChainProvider struct {
+	providers []Provider
+}
+
+// NewChainProvider creates a ChainProvider.
+func NewChainProvider(providers ...Provider) *ChainProvider {
+	return &ChainProvider{providers: providers}
+}
+
+// Flag implements Provider.
+func (p *ChainProvider) Flag(ctx context.Context, key string) (*Flag, error) {
+	for _, provider := range p.providers {
+		flag, err := provider.Flag(ctx, key)
+		if err == nil || !errors.Is(err, ErrFlagNotFound) {
+			return flag, err
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrFlagNotFound, key)
+}
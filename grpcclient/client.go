@@ -0,0 +1,295 @@
+// Package grpcclient builds gRPC client connections from configuration
+// structs: load balancing policy, per-method timeouts and retry policies
+// (generated as service config JSON), keepalive and TLS or mTLS. The
+// configuration is validated before dialing, and invalid settings are
+// reported as ConfigurationError domain errors listing every violation, so
+// misconfigured clients fail at startup instead of on the first call.
+package grpcclient
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+// Load balancing policies.
+const (
+	PickFirst  = "pick_first"
+	RoundRobin = "round_robin"
+)
+
+// CodeInvalidConfig is the code of the domain errors returned for invalid
+// configurations.
+const CodeInvalidConfig = "INVALID_GRPC_CLIENT_CONFIG"
+
+// Limits enforced by gRPC.
+const (
+	// MaxRetryAttempts is the highest number of attempts gRPC performs;
+	// higher values are silently lowered.
+	MaxRetryAttempts = 5
+	// MinKeepaliveTime is the shortest keepalive interval gRPC allows;
+	// shorter intervals are silently raised.
+	MinKeepaliveTime = 10 * time.Second
+)
+
+// Config configures a client connection.
+type Config struct {
+	// Target is the name resolved by gRPC, e.g. dns:///orders:50051.
+	Target string
+
+	// LoadBalancing is the load balancing policy, PickFirst or RoundRobin.
+	// RoundRobin needs a resolver returning every backend, such as dns.
+	// Defaults to PickFirst.
+	LoadBalancing string
+
+	// Timeout and Retry apply to the methods without their own in Methods.
+	// Zero values disable them.
+	Timeout time.Duration
+	Retry   *RetryPolicy
+
+	// Methods overrides the timeout and retry policy of services or
+	// methods. The config of a method replaces the defaults entirely.
+	Methods []MethodConfig
+
+	// Keepalive configures the keepalive pings.
+	Keepalive KeepaliveConfig
+
+	// TLS configures the transport security. The zero value uses TLS with
+	// the system roots.
+	TLS TLSConfig
+
+	// Insecure disables transport security, for local development and
+	// sidecar proxies. It cannot be combined with TLS settings.
+	Insecure bool
+
+	// DialOptions are appended to the generated options.
+	DialOptions []grpc.DialOption
+}
+
+// MethodConfig configures the calls of a service or of one of its methods.
+type MethodConfig struct {
+	// Service is the fully qualified service name, e.g. orders.v1.Orders.
+	Service string
+	// Method is the method name. Empty applies to every method of Service.
+	Method string
+
+	// Timeout bounds the calls, retries included. Zero means no timeout.
+	Timeout time.Duration
+	// Retry is the retry policy. Nil disables retries.
+	Retry *RetryPolicy
+	// WaitForReady makes calls wait for a connection instead of failing
+	// while the backends are unavailable.
+	WaitForReady bool
+}
+
+// name returns the name of the method in messages.
+func (m MethodConfig) name() string {
+	if m.Method == "" {
+		return m.Service
+	}
+	return m.Service + "/" + m.Method
+}
+
+// RetryPolicy is a gRPC retry policy. Retries are transparent to callers and
+// only happen before response headers are received.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, the first one included,
+	// between 2 and MaxRetryAttempts.
+	MaxAttempts int
+	// InitialBackoff and MaxBackoff bound the random delay before each
+	// retry, which grows by BackoffMultiplier.
+	InitialBackoff    time.Duration
+	MaxBackoff        time.Duration
+	BackoffMultiplier float64
+	// RetryableCodes lists the status codes retried.
+	RetryableCodes []codes.Code
+}
+
+// DefaultRetryPolicy returns a retry policy of 3 attempts retrying
+// Unavailable, with backoff from 100ms to 1s.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:       3,
+		InitialBackoff:    100 * time.Millisecond,
+		MaxBackoff:        time.Second,
+		BackoffMultiplier: 2,
+		RetryableCodes:    []codes.Code{codes.Unavailable},
+	}
+}
+
+// KeepaliveConfig configures the keepalive pings of the client. Servers
+// close connections pinging more often than their enforcement policy
+// allows, 5 minutes by default in gRPC servers.
+type KeepaliveConfig struct {
+	// Time is the idle time after which the client pings the server. Zero
+	// disables the pings; otherwise at least MinKeepaliveTime.
+	Time time.Duration
+	// Timeout is how long the client waits for the ping ack before closing
+	// the connection. Defaults to 20s in gRPC.
+	Timeout time.Duration
+	// PermitWithoutStream sends pings without active calls.
+	PermitWithoutStream bool
+}
+
+// Validate checks the configuration and returns a ConfigurationError domain
+// error listing the violations in its "violations" metadata.
+func (c Config) Validate() error {
+	var violations []string
+	add := func(format string, args ...any) {
+		violations = append(violations, fmt.Sprintf(format, args...))
+	}
+
+	if c.Target == "" {
+		add("target is required")
+	}
+	switch c.LoadBalancing {
+	case "", PickFirst, RoundRobin:
+	default:
+		add("unknown load balancing policy %q (want %s or %s)", c.LoadBalancing, PickFirst, RoundRobin)
+	}
+	if c.Timeout < 0 {
+		add("timeout cannot be negative: %v", c.Timeout)
+	}
+	if c.Retry != nil {
+		for _, v := range c.Retry.violations() {
+			add("retry policy: %s", v)
+		}
+	}
+
+	seen := make(map[string]bool, len(c.Methods))
+	for i, m := range c.Methods {
+		switch {
+		case m.Service == "":
+			add("methods[%d]: service is required", i)
+			continue
+		case strings.Contains(m.Service, "/") || strings.Contains(m.Method, "/"):
+			add("methods[%d]: service and method are names, not paths: %q", i, m.name())
+			continue
+		case seen[m.name()]:
+			add("methods[%d]: %s is configured more than once", i, m.name())
+		}
+		seen[m.name()] = true
+		if m.Timeout < 0 {
+			add("%s: timeout cannot be negative: %v", m.name(), m.Timeout)
+		}
+		if m.Retry != nil {
+			for _, v := range m.Retry.violations() {
+				add("%s: retry policy: %s", m.name(), v)
+			}
+		}
+	}
+
+	if k := c.Keepalive; k.Time < 0 || (k.Time > 0 && k.Time < MinKeepaliveTime) {
+		add("keepalive time must be zero or at least %v: %v", MinKeepaliveTime, k.Time)
+	}
+	if c.Keepalive.Timeout < 0 {
+		add("keepalive timeout cannot be negative: %v", c.Keepalive.Timeout)
+	}
+
+	if c.Insecure && !c.TLS.isZero() {
+		add("insecure cannot be combined with TLS settings")
+	}
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		add("TLS cert file and key file must be set together")
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+	return invalidConfig(violations)
+}
+
+// violations returns what is wrong with the policy.
+func (p *RetryPolicy) violations() []string {
+	var violations []string
+	if p.MaxAttempts < 2 || p.MaxAttempts > MaxRetryAttempts {
+		violations = append(violations, fmt.Sprintf("max attempts must be between 2 and %d: %d", MaxRetryAttempts, p.MaxAttempts))
+	}
+	if p.InitialBackoff <= 0 {
+		violations = append(violations, fmt.Sprintf("initial backoff must be positive: %v", p.InitialBackoff))
+	}
+	if p.MaxBackoff < p.InitialBackoff {
+		violations = append(violations, fmt.Sprintf("max backoff %v is shorter than the initial backoff %v", p.MaxBackoff, p.InitialBackoff))
+	}
+	if p.BackoffMultiplier <= 0 {
+		violations = append(violations, fmt.Sprintf("backoff multiplier must be positive: %v", p.BackoffMultiplier))
+	}
+	if len(p.RetryableCodes) == 0 {
+		violations = append(violations, "retryable codes are required")
+	}
+	for _, code := range p.RetryableCodes {
+		if _, ok := codeNames[code]; !ok || code == codes.OK {
+			violations = append(violations, fmt.Sprintf("code %v cannot be retried", code))
+		}
+	}
+	return violations
+}
+
+// invalidConfig returns the domain error of the violations.
+func invalidConfig(violations []string) error {
+	return domainerrors.NewWithMetadata(interfaces.ConfigurationError, CodeInvalidConfig,
+		"invalid gRPC client configuration: "+strings.Join(violations, "; "),
+		map[string]interface{}{"violations": violations})
+}
+
+// BuildDialOptions validates the configuration and returns the dial options
+// implementing it. The service config is the default one: it applies when
+// the resolver does not return a service config of its own.
+func (c Config) BuildDialOptions() ([]grpc.DialOption, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	var creds credentials.TransportCredentials
+	if c.Insecure {
+		creds = insecure.NewCredentials()
+	} else {
+		tlsConfig, err := c.TLS.Build()
+		if err != nil {
+			return nil, err
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	serviceConfig, err := c.ServiceConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultServiceConfig(serviceConfig),
+	}
+	if k := c.Keepalive; k.Time > 0 {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                k.Time,
+			Timeout:             k.Timeout,
+			PermitWithoutStream: k.PermitWithoutStream,
+		}))
+	}
+	return append(opts, c.DialOptions...), nil
+}
+
+// NewClient validates the configuration and creates a client connection to
+// the target. Like grpc.NewClient, it does not connect until the first call.
+func NewClient(c Config) (*grpc.ClientConn, error) {
+	opts, err := c.BuildDialOptions()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := grpc.NewClient(c.Target, opts...)
+	if err != nil {
+		return nil, domainerrors.Wrap(err, interfaces.ConfigurationError, CodeInvalidConfig,
+			fmt.Sprintf("failed to create gRPC client for %s: %v", c.Target, err))
+	}
+	return conn, nil
+}
@@ -0,0 +1,304 @@
+package grpcclient
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+func TestValidate(t *testing.T) {
+	config := Config{
+		LoadBalancing: "least_request",
+		Retry:         &RetryPolicy{MaxAttempts: 8, InitialBackoff: time.Second, MaxBackoff: time.Millisecond},
+		Methods: []MethodConfig{
+			{Service: "orders.v1.Orders", Method: "Create", Timeout: -time.Second},
+			{Service: "orders.v1.Orders", Method: "Create"},
+			{Service: "/orders.v1.Orders/Get"},
+		},
+		Keepalive: KeepaliveConfig{Time: time.Second},
+		TLS:       TLSConfig{CertFile: "client.pem"},
+		Insecure:  true,
+	}
+
+	err := config.Validate()
+	var domainErr interfaces.DomainErrorInterface
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, interfaces.ConfigurationError, domainErr.Type())
+	assert.Equal(t, CodeInvalidConfig, domainErr.Code())
+	assert.Equal(t, []string{
+		"target is required",
+		`unknown load balancing policy "least_request" (want pick_first or round_robin)`,
+		"retry policy: max attempts must be between 2 and 5: 8",
+		"retry policy: max backoff 1ms is shorter than the initial backoff 1s",
+		"retry policy: backoff multiplier must be positive: 0",
+		"retry policy: retryable codes are required",
+		"orders.v1.Orders/Create: timeout cannot be negative: -1s",
+		"methods[1]: orders.v1.Orders/Create is configured more than once",
+		`methods[2]: service and method are names, not paths: "/orders.v1.Orders/Get"`,
+		"keepalive time must be zero or at least 10s: 1s",
+		"insecure cannot be combined with TLS settings",
+		"TLS cert file and key file must be set together",
+	}, domainErr.Metadata()["violations"])
+	assert.Contains(t, err.Error(), "invalid gRPC client configuration: target is required; ")
+
+	_, err = NewClient(config)
+	assert.ErrorAs(t, err, &domainErr)
+
+	policy := DefaultRetryPolicy()
+	policy.RetryableCodes = append(policy.RetryableCodes, codes.OK)
+	err = Config{Target: "dns:///orders:50051", Retry: policy}.Validate()
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, []string{"retry policy: code OK cannot be retried"}, domainErr.Metadata()["violations"])
+
+	assert.NoError(t, Config{Target: "dns:///orders:50051", Retry: DefaultRetryPolicy()}.Validate())
+}
+
+func TestServiceConfig(t *testing.T) {
+	config := Config{
+		Target:        "dns:///orders:50051",
+		LoadBalancing: RoundRobin,
+		Timeout:       5 * time.Second,
+		Retry:         DefaultRetryPolicy(),
+		Methods: []MethodConfig{{
+			Service:      "orders.v1.Orders",
+			Method:       "Create",
+			Timeout:      1500 * time.Millisecond,
+			WaitForReady: true,
+			Retry: &RetryPolicy{
+				MaxAttempts:       4,
+				InitialBackoff:    50 * time.Millisecond,
+				MaxBackoff:        2 * time.Second,
+				BackoffMultiplier: 1.5,
+				RetryableCodes:    []codes.Code{codes.Unavailable, codes.Canceled, codes.ResourceExhausted},
+			},
+		}, {
+			Service: "orders.v1.Reports",
+			Timeout: time.Minute,
+		}},
+		Insecure: true,
+	}
+
+	sc, err := config.ServiceConfig()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+  "loadBalancingConfig": [{"round_robin": {}}],
+  "methodConfig": [
+    {
+      "name": [{}],
+      "timeout": "5s",
+      "retryPolicy": {"maxAttempts": 3, "initialBackoff": "0.1s", "maxBackoff": "1s", "backoffMultiplier": 2,
+        "retryableStatusCodes": ["UNAVAILABLE"]}
+    },
+    {
+      "name": [{"service": "orders.v1.Orders", "method": "Create"}],
+      "timeout": "1.5s",
+      "waitForReady": true,
+      "retryPolicy": {"maxAttempts": 4, "initialBackoff": "0.05s", "maxBackoff": "2s", "backoffMultiplier": 1.5,
+        "retryableStatusCodes": ["UNAVAILABLE", "CANCELLED", "RESOURCE_EXHAUSTED"]}
+    },
+    {"name": [{"service": "orders.v1.Reports"}], "timeout": "60s"}
+  ]
+}`, sc)
+
+	// gRPC parses the default service config when the client is created
+	conn, err := NewClient(config)
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	sc, err = Config{Target: "dns:///orders:50051"}.ServiceConfig()
+	require.NoError(t, err)
+	var parsed map[string]any
+	require.NoError(t, json.Unmarshal([]byte(sc), &parsed))
+	assert.Equal(t, []any{map[string]any{"pick_first": map[string]any{}}}, parsed["loadBalancingConfig"])
+	assert.NotContains(t, parsed, "methodConfig")
+}
+
+// flakyHealth fails the first calls with Unavailable.
+type flakyHealth struct {
+	healthpb.UnimplementedHealthServer
+	failures int64
+	calls    atomic.Int64
+}
+
+func (h *flakyHealth) Check(ctx context.Context, _ *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if h.calls.Add(1) <= h.failures {
+		return nil, status.Error(codes.Unavailable, "warming up")
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+// serve serves the health service on an in-memory listener and returns the
+// dial option connecting to it.
+func serve(t *testing.T, health healthpb.HealthServer, opts ...grpc.ServerOption) grpc.DialOption {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(opts...)
+	healthpb.RegisterHealthServer(server, health)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	return grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return lis.DialContext(ctx)
+	})
+}
+
+func TestNewClient_Retry(t *testing.T) {
+	health := &flakyHealth{failures: 2}
+	dialer := serve(t, health)
+
+	config := Config{
+		Target:   "passthrough:///bufnet",
+		Insecure: true,
+		Methods: []MethodConfig{{
+			Service: healthpb.Health_ServiceDesc.ServiceName,
+			Retry: &RetryPolicy{
+				MaxAttempts:       3,
+				InitialBackoff:    time.Millisecond,
+				MaxBackoff:        10 * time.Millisecond,
+				BackoffMultiplier: 2,
+				RetryableCodes:    []codes.Code{codes.Unavailable},
+			},
+		}},
+		DialOptions: []grpc.DialOption{dialer},
+	}
+	conn, err := NewClient(config)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+	assert.Equal(t, int64(3), health.calls.Load())
+
+	// without the retry policy the first failure reaches the caller
+	health.calls.Store(0)
+	config.Methods = nil
+	plain, err := NewClient(config)
+	require.NoError(t, err)
+	defer plain.Close()
+	_, err = healthpb.NewHealthClient(plain).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+// writeCert creates a certificate signed by parent, or self-signed when
+// parent is nil, and writes it and its key as PEM files in dir.
+func writeCert(t *testing.T, dir, name string, template *x509.Certificate, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name+".pem"),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name+"-key.pem"),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return cert, key
+}
+
+func TestNewClient_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	notAfter := time.Now().Add(time.Hour)
+	ca, caKey := writeCert(t, dir, "ca", &x509.Certificate{
+		SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "test CA"}, NotAfter: notAfter,
+		IsCA: true, BasicConstraintsValid: true, KeyUsage: x509.KeyUsageCertSign,
+	}, nil, nil)
+	writeCert(t, dir, "server", &x509.Certificate{
+		SerialNumber: big.NewInt(2), Subject: pkix.Name{CommonName: "orders"}, NotAfter: notAfter,
+		DNSNames: []string{"orders.internal"}, ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca, caKey)
+	writeCert(t, dir, "client", &x509.Certificate{
+		SerialNumber: big.NewInt(3), Subject: pkix.Name{CommonName: "billing"}, NotAfter: notAfter,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca, caKey)
+
+	serverCert, err := tls.LoadX509KeyPair(filepath.Join(dir, "server.pem"), filepath.Join(dir, "server-key.pem"))
+	require.NoError(t, err)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca)
+	dialer := serve(t, &flakyHealth{}, grpc.Creds(credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	})))
+
+	check := func(tlsConfig TLSConfig) error {
+		conn, err := NewClient(Config{Target: "passthrough:///bufnet", TLS: tlsConfig, DialOptions: []grpc.DialOption{dialer}})
+		require.NoError(t, err)
+		defer conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+		return err
+	}
+
+	assert.NoError(t, check(TLSConfig{
+		CAFile:     filepath.Join(dir, "ca.pem"),
+		CertFile:   filepath.Join(dir, "client.pem"),
+		KeyFile:    filepath.Join(dir, "client-key.pem"),
+		ServerName: "orders.internal",
+	}))
+	// the server requires a client certificate
+	assert.Equal(t, codes.Unavailable, status.Code(check(TLSConfig{
+		CAFile:     filepath.Join(dir, "ca.pem"),
+		ServerName: "orders.internal",
+	})))
+}
+
+func TestTLSConfig_BuildErrors(t *testing.T) {
+	dir := t.TempDir()
+	notPEM := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(notPEM, []byte("not a certificate"), 0o600))
+
+	for _, config := range []TLSConfig{
+		{CAFile: filepath.Join(dir, "missing.pem")},
+		{CAFile: notPEM},
+		{CertFile: filepath.Join(dir, "missing.pem"), KeyFile: filepath.Join(dir, "missing-key.pem")},
+	} {
+		_, err := config.Build()
+		var domainErr interfaces.DomainErrorInterface
+		require.ErrorAs(t, err, &domainErr)
+		assert.Equal(t, interfaces.ConfigurationError, domainErr.Type())
+		assert.Equal(t, CodeInvalidConfig, domainErr.Code())
+
+		_, err = NewClient(Config{Target: "dns:///orders:50051", TLS: config})
+		assert.ErrorAs(t, err, &domainErr)
+	}
+
+	tlsConfig, err := TLSConfig{}.Build()
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), tlsConfig.MinVersion)
+	assert.Nil(t, tlsConfig.RootCAs)
+}
@@ -0,0 +1,121 @@
+package grpcclient
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// codeNames are the names of the codes in service configs.
+var codeNames = map[codes.Code]string{
+	codes.OK:                 "OK",
+	codes.Canceled:           "CANCELLED",
+	codes.Unknown:            "UNKNOWN",
+	codes.InvalidArgument:    "INVALID_ARGUMENT",
+	codes.DeadlineExceeded:   "DEADLINE_EXCEEDED",
+	codes.NotFound:           "NOT_FOUND",
+	codes.AlreadyExists:      "ALREADY_EXISTS",
+	codes.PermissionDenied:   "PERMISSION_DENIED",
+	codes.ResourceExhausted:  "RESOURCE_EXHAUSTED",
+	codes.FailedPrecondition: "FAILED_PRECONDITION",
+	codes.Aborted:            "ABORTED",
+	codes.OutOfRange:         "OUT_OF_RANGE",
+	codes.Unimplemented:      "UNIMPLEMENTED",
+	codes.Internal:           "INTERNAL",
+	codes.Unavailable:        "UNAVAILABLE",
+	codes.DataLoss:           "DATA_LOSS",
+	codes.Unauthenticated:    "UNAUTHENTICATED",
+}
+
+// serviceConfig is the JSON representation of a gRPC service config, see
+// https://github.com/grpc/grpc/blob/master/doc/service_config.md.
+type serviceConfig struct {
+	LoadBalancingConfig []map[string]struct{} `json:"loadBalancingConfig"`
+	MethodConfig        []methodConfig        `json:"methodConfig,omitempty"`
+}
+
+type methodConfig struct {
+	Name         []methodName `json:"name"`
+	Timeout      string       `json:"timeout,omitempty"`
+	WaitForReady bool         `json:"waitForReady,omitempty"`
+	RetryPolicy  *retryPolicy `json:"retryPolicy,omitempty"`
+}
+
+type methodName struct {
+	Service string `json:"service,omitempty"`
+	Method  string `json:"method,omitempty"`
+}
+
+type retryPolicy struct {
+	MaxAttempts          int      `json:"maxAttempts"`
+	InitialBackoff       string   `json:"initialBackoff"`
+	MaxBackoff           string   `json:"maxBackoff"`
+	BackoffMultiplier    float64  `json:"backoffMultiplier"`
+	RetryableStatusCodes []string `json:"retryableStatusCodes"`
+}
+
+// ServiceConfig validates the configuration and returns its service config
+// JSON: the load balancing policy and the method configs. The defaults of
+// Config come first, as the config of every method; gRPC applies the most
+// specific config of each call.
+func (c Config) ServiceConfig() (string, error) {
+	if err := c.Validate(); err != nil {
+		return "", err
+	}
+
+	policy := c.LoadBalancing
+	if policy == "" {
+		policy = PickFirst
+	}
+	sc := serviceConfig{LoadBalancingConfig: []map[string]struct{}{{policy: {}}}}
+
+	if c.Timeout > 0 || c.Retry != nil {
+		sc.MethodConfig = append(sc.MethodConfig, methodConfig{
+			Name:        []methodName{{}},
+			Timeout:     duration(c.Timeout),
+			RetryPolicy: c.Retry.toJSON(),
+		})
+	}
+	for _, m := range c.Methods {
+		sc.MethodConfig = append(sc.MethodConfig, methodConfig{
+			Name:         []methodName{{Service: m.Service, Method: m.Method}},
+			Timeout:      duration(m.Timeout),
+			WaitForReady: m.WaitForReady,
+			RetryPolicy:  m.Retry.toJSON(),
+		})
+	}
+
+	data, err := json.Marshal(sc)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// toJSON returns the JSON representation of the policy, nil for nil.
+func (p *RetryPolicy) toJSON() *retryPolicy {
+	if p == nil {
+		return nil
+	}
+	names := make([]string, len(p.RetryableCodes))
+	for i, code := range p.RetryableCodes {
+		names[i] = codeNames[code]
+	}
+	return &retryPolicy{
+		MaxAttempts:          p.MaxAttempts,
+		InitialBackoff:       duration(p.InitialBackoff),
+		MaxBackoff:           duration(p.MaxBackoff),
+		BackoffMultiplier:    p.BackoffMultiplier,
+		RetryableStatusCodes: names,
+	}
+}
+
+// duration formats a duration as a protobuf JSON duration, empty for zero.
+func duration(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
@@ -0,0 +1,76 @@
+package grpcclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+// TLSConfig configures TLS. Without CAFile the system roots verify the
+// server; with CertFile and KeyFile the client authenticates with its
+// certificate (mTLS).
+type TLSConfig struct {
+	// CAFile is a PEM file of the certificate authorities trusted to sign
+	// the server certificate.
+	CAFile string
+	// CertFile and KeyFile are the PEM files of the client certificate.
+	CertFile string
+	KeyFile  string
+	// ServerName overrides the name verified in the server certificate,
+	// which defaults to the host of the target.
+	ServerName string
+	// InsecureSkipVerify disables the verification of the server
+	// certificate. Only for tests.
+	InsecureSkipVerify bool
+}
+
+// isZero reports whether no setting is set.
+func (t TLSConfig) isZero() bool {
+	return t == TLSConfig{}
+}
+
+// Build loads the files and returns the TLS configuration, with TLS 1.2 as
+// minimum version. Files that cannot be loaded return a ConfigurationError
+// domain error.
+func (t TLSConfig) Build() (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, tlsError(err, "failed to read CA file %s: %v", t.CAFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, tlsError(nil, "CA file %s has no PEM certificates", t.CAFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if t.CertFile != "" || t.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, tlsError(err, "failed to load client certificate %s: %v", t.CertFile, err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// tlsError returns a ConfigurationError domain error wrapping cause.
+func tlsError(cause error, format string, args ...any) error {
+	err := domainerrors.New(interfaces.ConfigurationError, CodeInvalidConfig,
+		"invalid gRPC client TLS configuration: "+fmt.Sprintf(format, args...))
+	if cause != nil {
+		return err.Wrap(cause)
+	}
+	return err
+}
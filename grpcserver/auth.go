@@ -0,0 +1,89 @@
+package grpcserver
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/fsvxavier/nexs-lib/httpserver/middlewares"
+)
+
+// AuthorizationMetadata is the metadata key carrying credentials.
+const AuthorizationMetadata = "authorization"
+
+// AuthFunc authenticates a call to fullMethod. It returns the context passed
+// to the handler, typically carrying the authenticated identity, or an error,
+// typically an AuthenticationError domain error.
+type AuthFunc func(ctx context.Context, fullMethod string) (context.Context, error)
+
+// AuthUnaryInterceptor authenticates calls with auth, except calls to
+// public methods. Public entries are full method names
+// ("/pkg.Service/Method") or service prefixes ending with a slash
+// ("/grpc.health.v1.Health/").
+func AuthUnaryInterceptor(auth AuthFunc, public ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !isPublic(info.FullMethod, public) {
+			var err error
+			if ctx, err = auth(ctx, info.FullMethod); err != nil {
+				return nil, err
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// AuthStreamInterceptor is the stream version of AuthUnaryInterceptor.
+func AuthStreamInterceptor(auth AuthFunc, public ...string) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if isPublic(info.FullMethod, public) {
+			return handler(srv, ss)
+		}
+		ctx, err := auth(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// isPublic reports whether method is exempt from authentication.
+func isPublic(method string, public []string) bool {
+	for _, p := range public {
+		if method == p || (strings.HasSuffix(p, "/") && strings.HasPrefix(method, p)) {
+			return true
+		}
+	}
+	return false
+}
+
+// BearerToken returns the bearer token of the authorization metadata.
+func BearerToken(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+	for _, value := range md.Get(AuthorizationMetadata) {
+		scheme, token, found := strings.Cut(value, " ")
+		if found && strings.EqualFold(scheme, "Bearer") && token != "" {
+			return strings.TrimSpace(token), true
+		}
+	}
+	return "", false
+}
+
+// JWTAuth authenticates calls with the bearer JWT of the authorization
+// metadata, verified by jwt as for HTTP requests. Handlers read the claims
+// with middlewares.JWTClaimsFromContext.
+func JWTAuth(jwt *middlewares.JWTMiddleware) AuthFunc {
+	return func(ctx context.Context, _ string) (context.Context, error) {
+		// A missing token is rejected by Verify with JWT_MISSING.
+		token, _ := BearerToken(ctx)
+		claims, err := jwt.Verify(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+		return middlewares.ContextWithJWTClaims(ctx, claims), nil
+	}
+}
@@ -0,0 +1,327 @@
+package grpcserver
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/fsvxavier/nexs-lib/httpserver/middlewares"
+	"github.com/fsvxavier/nexs-lib/validation/validator"
+)
+
+// echoServer echoes its input, greeting the JWT subject; some inputs fail.
+type echoServer struct{}
+
+func (echoServer) Echo(ctx context.Context, in *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+	switch in.Value {
+	case "panic":
+		panic("boom")
+	case "missing":
+		return nil, domainerrors.New(interfaces.NotFoundError, "ORDER_NOT_FOUND", "order not found").
+			WithMetadata("order_id", 42)
+	}
+	subject := "anonymous"
+	if claims, ok := middlewares.JWTClaimsFromContext(ctx); ok {
+		subject = claims.Subject
+	}
+	return wrapperspb.String(subject + ": " + in.Value), nil
+}
+
+var echoDesc = grpc.ServiceDesc{
+	ServiceName: "test.Echo",
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Echo",
+		Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+			in := new(wrapperspb.StringValue)
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			handler := func(ctx context.Context, req any) (any, error) {
+				return srv.(echoServer).Echo(ctx, req.(*wrapperspb.StringValue))
+			}
+			return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/test.Echo/Echo"}, handler)
+		},
+	}},
+}
+
+func signHS256(t *testing.T, secret []byte, claims map[string]any) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": "HS256", "typ": "JWT"})
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(input))
+	return input + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+type call struct {
+	method string
+	code   codes.Code
+}
+
+func startServer(t *testing.T, config Config) (*Server, *grpc.ClientConn) {
+	t.Helper()
+	server := New(config)
+	server.RegisterService(&echoDesc, echoServer{})
+
+	lis := bufconn.Listen(1 << 20)
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(lis) }()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		assert.NoError(t, server.Shutdown(ctx))
+		assert.NoError(t, <-served)
+	})
+	return server, conn
+}
+
+func echo(ctx context.Context, conn *grpc.ClientConn, value string) (string, error) {
+	out := new(wrapperspb.StringValue)
+	err := conn.Invoke(ctx, "/test.Echo/Echo", wrapperspb.String(value), out)
+	return out.GetValue(), err
+}
+
+func errorInfoOf(t *testing.T, err error) *errdetails.ErrorInfo {
+	t.Helper()
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info
+		}
+	}
+	t.Fatalf("no ErrorInfo in %v", err)
+	return nil
+}
+
+func TestServer(t *testing.T) {
+	secret := []byte("secret")
+	var logs bytes.Buffer
+	var mu sync.Mutex
+	var calls []call
+
+	_, conn := startServer(t, Config{
+		Logger: slog.New(slog.NewJSONHandler(&logs, nil)),
+		Auth:   JWTAuth(middlewares.NewJWTMiddlewareWithConfig(1, middlewares.JWTConfig{Secret: secret})),
+		Metrics: func(method string, code codes.Code, _ time.Duration) {
+			mu.Lock()
+			defer mu.Unlock()
+			calls = append(calls, call{method, code})
+		},
+	})
+	ctx := context.Background()
+
+	// The health service is public.
+	health, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: "test.Echo"})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, health.Status)
+
+	_, err = echo(ctx, conn, "hi")
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Equal(t, "JWT_MISSING", errorInfoOf(t, err).Reason)
+
+	token := signHS256(t, secret, map[string]any{"sub": "user-1", "exp": time.Now().Add(time.Hour).Unix()})
+	authCtx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+
+	got, err := echo(authCtx, conn, "hi")
+	require.NoError(t, err)
+	assert.Equal(t, "user-1: hi", got)
+
+	_, err = echo(authCtx, conn, "missing")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, "order not found", status.Convert(err).Message())
+	info := errorInfoOf(t, err)
+	assert.Equal(t, "ORDER_NOT_FOUND", info.Reason)
+	assert.Equal(t, map[string]string{"type": "not_found_error", "order_id": "42"}, info.Metadata)
+
+	_, err = echo(authCtx, conn, "panic")
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, internalMessage, status.Convert(err).Message())
+	assert.Equal(t, "PANIC", errorInfoOf(t, err).Reason)
+
+	assert.Contains(t, logs.String(), `"msg":"grpc handler panicked"`)
+	assert.Contains(t, logs.String(), `"grpc.code":"NotFound"`)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []call{
+		{"/grpc.health.v1.Health/Check", codes.OK},
+		{"/test.Echo/Echo", codes.Unauthenticated},
+		{"/test.Echo/Echo", codes.OK},
+		{"/test.Echo/Echo", codes.NotFound},
+		{"/test.Echo/Echo", codes.Internal},
+	}, calls)
+}
+
+func TestServer_Shutdown(t *testing.T) {
+	server := New(Config{DisableLogging: true})
+	lis := bufconn.Listen(1 << 20)
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(lis) }()
+
+	require.Eventually(t, func() bool { return server.Addr() != nil }, time.Second, time.Millisecond)
+	require.NoError(t, server.Shutdown(context.Background()))
+	require.NoError(t, <-served)
+
+	resp, err := server.Health().Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.Status)
+	assert.ErrorIs(t, server.Serve(bufconn.Listen(1)), ErrServerStopped)
+}
+
+type createOrder struct {
+	Customer string `json:"customer" validate:"required"`
+	Quantity int    `json:"quantity" validate:"min=1"`
+}
+
+type pgvError struct{ field, reason string }
+
+func (e pgvError) Error() string  { return e.field + ": " + e.reason }
+func (e pgvError) Field() string  { return e.field }
+func (e pgvError) Reason() string { return e.reason }
+
+type pgvMessage struct{ err error }
+
+func (m pgvMessage) Validate() error { return m.err }
+
+func TestValidationUnaryInterceptor(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Orders/Create"}
+	handler := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+	chain := func(validate ValidateFunc, req any) error {
+		_, err := StatusUnaryInterceptor()(context.Background(), req, info, func(ctx context.Context, req any) (any, error) {
+			return ValidationUnaryInterceptor(validate)(ctx, req, info, handler)
+		})
+		return err
+	}
+
+	err := chain(StructValidator(), &createOrder{Quantity: -1})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	var badRequest *errdetails.BadRequest
+	for _, detail := range status.Convert(err).Details() {
+		if br, ok := detail.(*errdetails.BadRequest); ok {
+			badRequest = br
+		}
+	}
+	require.NotNil(t, badRequest)
+	require.Len(t, badRequest.FieldViolations, 2)
+	assert.Equal(t, "customer", badRequest.FieldViolations[0].Field)
+	assert.Equal(t, "required", badRequest.FieldViolations[0].Reason)
+	assert.Equal(t, "quantity", badRequest.FieldViolations[1].Field)
+
+	assert.NoError(t, chain(StructValidator(), &createOrder{Customer: "c-1", Quantity: 2}))
+
+	err = chain(nil, pgvMessage{err: pgvError{"email", "value must be a valid email address"}})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.NoError(t, chain(nil, pgvMessage{}))
+}
+
+func TestToStatus(t *testing.T) {
+	cases := []struct {
+		name    string
+		err     error
+		code    codes.Code
+		message string
+	}{
+		{"nil", nil, codes.OK, ""},
+		{"validation", domainerrors.NewValidationError("INVALID", "invalid input"), codes.InvalidArgument, "invalid input"},
+		{"authorization", domainerrors.New(interfaces.AuthorizationError, "FORBIDDEN", "forbidden"), codes.PermissionDenied, "forbidden"},
+		{"business", domainerrors.New(interfaces.BusinessError, "INSUFFICIENT_FUNDS", "insufficient funds"), codes.FailedPrecondition, "insufficient funds"},
+		{"rate limit", domainerrors.New(interfaces.RateLimitError, "RATE_LIMITED", "slow down"), codes.ResourceExhausted, "slow down"},
+		{"database", domainerrors.New(interfaces.DatabaseError, "DB", "connection refused on 10.0.0.1"), codes.Internal, internalMessage},
+		{"status", status.Error(codes.AlreadyExists, "exists"), codes.AlreadyExists, "exists"},
+		{"deadline", context.DeadlineExceeded, codes.DeadlineExceeded, context.DeadlineExceeded.Error()},
+		{"canceled", context.Canceled, codes.Canceled, context.Canceled.Error()},
+		{"plain", errors.New("secret details"), codes.Internal, internalMessage},
+		{"validator", validator.ValidationErrors{{Field: "name", Rule: "required", Message: "is required"}}, codes.InvalidArgument, "name: is required"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			st := ToStatus(tc.err)
+			assert.Equal(t, tc.code, st.Code())
+			assert.Equal(t, tc.message, st.Message())
+		})
+	}
+}
+
+func TestAuthUnaryInterceptor(t *testing.T) {
+	auth := func(ctx context.Context, method string) (context.Context, error) {
+		return nil, domainerrors.New(interfaces.AuthenticationError, "DENIED", "denied")
+	}
+	handler := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+	interceptor := AuthUnaryInterceptor(auth, "/test.Public/", "/test.Orders/List")
+
+	for method, allowed := range map[string]bool{
+		"/test.Public/Anything": true,
+		"/test.Orders/List":     true,
+		"/test.Orders/Create":   false,
+		"/test.PublicX/Call":    false,
+	} {
+		_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		assert.Equal(t, allowed, err == nil, method)
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "bearer abc"))
+	token, ok := BearerToken(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "abc", token)
+	_, ok = BearerToken(metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Basic abc")))
+	assert.False(t, ok)
+}
+
+func TestTracingUnaryInterceptor(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	interceptor := TracingUnaryInterceptor(provider, propagation.TraceContext{})
+
+	traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("traceparent", traceparent))
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test.Echo/Echo"}, func(ctx context.Context, req any) (any, error) {
+		assert.True(t, trace.SpanContextFromContext(ctx).IsValid())
+		return nil, status.Error(codes.NotFound, "missing")
+	})
+	require.Error(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "test.Echo/Echo", span.Name())
+	assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+	assert.True(t, strings.HasPrefix(span.Status().Description, "missing"))
+}
@@ -0,0 +1,326 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/fsvxavier/nexs-lib/validation/validator"
+)
+
+// tracerName identifies the spans created by the tracing interceptors.
+const tracerName = "github.com/fsvxavier/nexs-lib/grpcserver"
+
+// RecoveryUnaryInterceptor turns panics of handlers into ServerError domain
+// errors, logging the panic value and stack.
+func RecoveryUnaryInterceptor(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = panicError(ctx, logger, info.FullMethod, p)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// RecoveryStreamInterceptor is the stream version of RecoveryUnaryInterceptor.
+func RecoveryStreamInterceptor(logger *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = panicError(ss.Context(), logger, info.FullMethod, p)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+// panicError logs a recovered panic and returns its domain error.
+func panicError(ctx context.Context, logger *slog.Logger, method string, p any) error {
+	logger.ErrorContext(ctx, "grpc handler panicked",
+		slog.String("grpc.method", method),
+		slog.Any("panic", p),
+		slog.String("stack", string(debug.Stack())))
+	return domainerrors.New(interfaces.ServerError, "PANIC", fmt.Sprintf("handler %s panicked: %v", method, p)).
+		WithMetadata("method", method)
+}
+
+// StatusUnaryInterceptor converts the errors of handlers into gRPC statuses
+// with ToStatus.
+func StatusUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return nil, ToStatus(err).Err()
+		}
+		return resp, nil
+	}
+}
+
+// StatusStreamInterceptor is the stream version of StatusUnaryInterceptor.
+func StatusStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, ss); err != nil {
+			return ToStatus(err).Err()
+		}
+		return nil
+	}
+}
+
+// ValidateFunc validates a request message. Returned errors are converted
+// with ToStatus, so validator.ValidationErrors become InvalidArgument.
+type ValidateFunc func(ctx context.Context, req any) error
+
+// ValidationUnaryInterceptor validates requests before calling the handler.
+// Messages with a ValidateAll() error or Validate() error method, such as
+// those generated by protoc-gen-validate, are validated with it; validate,
+// when not nil, runs afterwards on every message, e.g. StructValidator.
+func ValidationUnaryInterceptor(validate ValidateFunc) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := validateMessage(ctx, req, validate); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// ValidationStreamInterceptor validates every message received by stream
+// handlers.
+func ValidationStreamInterceptor(validate ValidateFunc) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &validatingStream{ServerStream: ss, validate: validate})
+	}
+}
+
+// StructValidator validates messages with the tags of validation/validator,
+// for hand-written request types and generated code with validate tags.
+func StructValidator() ValidateFunc {
+	return func(ctx context.Context, req any) error {
+		return validator.ValidateStruct(req)
+	}
+}
+
+// validateMessage runs the validation of a message.
+func validateMessage(ctx context.Context, req any, validate ValidateFunc) error {
+	switch v := req.(type) {
+	case interface{ ValidateAll() error }:
+		if err := v.ValidateAll(); err != nil {
+			return err
+		}
+	case interface{ Validate() error }:
+		if err := v.Validate(); err != nil {
+			return err
+		}
+	}
+	if validate != nil {
+		return validate(ctx, req)
+	}
+	return nil
+}
+
+// validatingStream validates the messages received from the client.
+type validatingStream struct {
+	grpc.ServerStream
+	validate ValidateFunc
+}
+
+func (s *validatingStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return validateMessage(s.Context(), m, s.validate)
+}
+
+// MetricsFunc receives the method, status code and duration of every call.
+type MetricsFunc func(method string, code codes.Code, duration time.Duration)
+
+// MetricsUnaryInterceptor reports every call to collector.
+func MetricsUnaryInterceptor(collector MetricsFunc) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		collector(info.FullMethod, status.Code(err), time.Since(start))
+		return resp, err
+	}
+}
+
+// MetricsStreamInterceptor reports every stream to collector when it ends.
+func MetricsStreamInterceptor(collector MetricsFunc) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		collector(info.FullMethod, status.Code(err), time.Since(start))
+		return err
+	}
+}
+
+// LoggingUnaryInterceptor logs every call with its method, code, duration
+// and peer. Server-side failures are logged at error level, other failures
+// at warn level and successes at info level.
+func LoggingUnaryInterceptor(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(ctx, logger, info.FullMethod, "unary", err, time.Since(start))
+		return resp, err
+	}
+}
+
+// LoggingStreamInterceptor logs every stream when it ends.
+func LoggingStreamInterceptor(logger *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logCall(ss.Context(), logger, info.FullMethod, "stream", err, time.Since(start))
+		return err
+	}
+}
+
+// logCall logs the outcome of a call.
+func logCall(ctx context.Context, logger *slog.Logger, method, kind string, err error, duration time.Duration) {
+	st := status.Convert(err)
+	attrs := []slog.Attr{
+		slog.String("grpc.method", method),
+		slog.String("grpc.kind", kind),
+		slog.String("grpc.code", st.Code().String()),
+		slog.Duration("duration", duration),
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		attrs = append(attrs, slog.String("peer", p.Addr.String()))
+	}
+
+	level := slog.LevelInfo
+	switch st.Code() {
+	case codes.OK:
+	case codes.Unknown, codes.Internal, codes.DataLoss, codes.Unavailable:
+		level = slog.LevelError
+		attrs = append(attrs, slog.String("error", st.Message()))
+	default:
+		level = slog.LevelWarn
+		attrs = append(attrs, slog.String("error", st.Message()))
+	}
+	logger.LogAttrs(ctx, level, "grpc call", attrs...)
+}
+
+// TracingUnaryInterceptor creates a server span per call, continuing the
+// trace propagated in the request metadata. A nil provider or propagator
+// selects the global one.
+func TracingUnaryInterceptor(provider trace.TracerProvider, propagator propagation.TextMapPropagator) grpc.UnaryServerInterceptor {
+	tracer, propagator := tracing(provider, propagator)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, span := startSpan(ctx, tracer, propagator, info.FullMethod)
+		defer span.End()
+
+		resp, err := handler(ctx, req)
+		endSpan(span, err)
+		return resp, err
+	}
+}
+
+// TracingStreamInterceptor is the stream version of TracingUnaryInterceptor.
+func TracingStreamInterceptor(provider trace.TracerProvider, propagator propagation.TextMapPropagator) grpc.StreamServerInterceptor {
+	tracer, propagator := tracing(provider, propagator)
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, span := startSpan(ss.Context(), tracer, propagator, info.FullMethod)
+		defer span.End()
+
+		err := handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+		endSpan(span, err)
+		return err
+	}
+}
+
+// tracing resolves the tracer and propagator of the tracing interceptors.
+func tracing(provider trace.TracerProvider, propagator propagation.TextMapPropagator) (trace.Tracer, propagation.TextMapPropagator) {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	if propagator == nil {
+		propagator = otel.GetTextMapPropagator()
+	}
+	return provider.Tracer(tracerName), propagator
+}
+
+// startSpan extracts the propagated trace and starts the span of a call.
+func startSpan(ctx context.Context, tracer trace.Tracer, propagator propagation.TextMapPropagator, method string) (context.Context, trace.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = propagator.Extract(ctx, metadataCarrier(md))
+
+	service, name := splitMethod(method)
+	return tracer.Start(ctx, strings.TrimPrefix(method, "/"),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "grpc"),
+			attribute.String("rpc.service", service),
+			attribute.String("rpc.method", name),
+		),
+	)
+}
+
+// endSpan records the status of a call on its span.
+func endSpan(span trace.Span, err error) {
+	st := status.Convert(err)
+	span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(st.Code())))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, st.Message())
+	}
+}
+
+// splitMethod splits "/package.Service/Method" into service and method.
+func splitMethod(fullMethod string) (string, string) {
+	service, method, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !ok {
+		return "", service
+	}
+	return service, method
+}
+
+// metadataCarrier adapts gRPC metadata to a propagation.TextMapCarrier.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if values := metadata.MD(c).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// contextStream overrides the context of a server stream.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
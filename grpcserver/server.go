@@ -0,0 +1,245 @@
+// Package grpcserver builds gRPC servers with the conventions of the HTTP
+// toolkit: panic recovery into domain errors, domain errors converted to gRPC
+// statuses, request validation, authentication, logging, metrics and tracing
+// interceptors, the standard health service and graceful shutdown.
+package grpcserver
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"sync"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// DefaultAddr is the listen address of servers without one.
+const DefaultAddr = ":50051"
+
+// ErrServerStopped is returned by Serve after Shutdown.
+var ErrServerStopped = errors.New("grpcserver: server stopped")
+
+// Config configures a Server. The zero value serves on DefaultAddr with
+// recovery, status conversion, validation, logging and tracing enabled.
+type Config struct {
+	// Addr is the listen address of ListenAndServe. Defaults to DefaultAddr.
+	Addr string
+
+	// Logger logs calls and recovered panics. Defaults to slog.Default().
+	Logger *slog.Logger
+
+	// Auth authenticates calls, except those to PublicMethods and to the
+	// health and reflection services. Nil disables authentication.
+	Auth AuthFunc
+	// PublicMethods lists full method names, or service prefixes ending with
+	// a slash, exempt from Auth.
+	PublicMethods []string
+
+	// Validate runs after the Validate methods of the messages, e.g.
+	// StructValidator(). Nil validates with the message methods only.
+	Validate ValidateFunc
+
+	// Metrics receives the method, code and duration of every call.
+	Metrics MetricsFunc
+
+	// TracerProvider and Propagator default to the global ones.
+	TracerProvider trace.TracerProvider
+	Propagator     propagation.TextMapPropagator
+	// DisableTracing disables the tracing interceptors.
+	DisableTracing bool
+	// DisableLogging disables the logging interceptors.
+	DisableLogging bool
+
+	// Reflection registers the server reflection service.
+	Reflection bool
+
+	// TLSConfig enables TLS.
+	TLSConfig *tls.Config
+
+	// UnaryInterceptors and StreamInterceptors run after the built-in ones,
+	// right before the handlers.
+	UnaryInterceptors  []grpc.UnaryServerInterceptor
+	StreamInterceptors []grpc.StreamServerInterceptor
+
+	// ServerOptions are passed to grpc.NewServer, e.g. keepalive settings or
+	// message size limits.
+	ServerOptions []grpc.ServerOption
+}
+
+// DefaultConfig returns the default configuration.
+func DefaultConfig() Config {
+	return Config{Addr: DefaultAddr, Logger: slog.Default()}
+}
+
+// Server is a gRPC server with the health service registered. It implements
+// grpc.ServiceRegistrar, so generated Register functions accept it.
+type Server struct {
+	config Config
+	server *grpc.Server
+	health *health.Server
+
+	mu       sync.Mutex
+	listener net.Listener
+	stopped  bool
+}
+
+// New creates a Server. The interceptors run in this order: tracing,
+// metrics, logging, status conversion, recovery, authentication, validation
+// and the interceptors of the config.
+func New(config Config) *Server {
+	if config.Addr == "" {
+		config.Addr = DefaultAddr
+	}
+	if config.Logger == nil {
+		config.Logger = slog.Default()
+	}
+
+	public := append([]string{"/" + healthpb.Health_ServiceDesc.ServiceName + "/",
+		"/grpc.reflection.v1.ServerReflection/", "/grpc.reflection.v1alpha.ServerReflection/"},
+		config.PublicMethods...)
+
+	var unary []grpc.UnaryServerInterceptor
+	var stream []grpc.StreamServerInterceptor
+	if !config.DisableTracing {
+		unary = append(unary, TracingUnaryInterceptor(config.TracerProvider, config.Propagator))
+		stream = append(stream, TracingStreamInterceptor(config.TracerProvider, config.Propagator))
+	}
+	if config.Metrics != nil {
+		unary = append(unary, MetricsUnaryInterceptor(config.Metrics))
+		stream = append(stream, MetricsStreamInterceptor(config.Metrics))
+	}
+	if !config.DisableLogging {
+		unary = append(unary, LoggingUnaryInterceptor(config.Logger))
+		stream = append(stream, LoggingStreamInterceptor(config.Logger))
+	}
+	unary = append(unary, StatusUnaryInterceptor(), RecoveryUnaryInterceptor(config.Logger))
+	stream = append(stream, StatusStreamInterceptor(), RecoveryStreamInterceptor(config.Logger))
+	if config.Auth != nil {
+		unary = append(unary, AuthUnaryInterceptor(config.Auth, public...))
+		stream = append(stream, AuthStreamInterceptor(config.Auth, public...))
+	}
+	unary = append(unary, ValidationUnaryInterceptor(config.Validate))
+	stream = append(stream, ValidationStreamInterceptor(config.Validate))
+	unary = append(unary, config.UnaryInterceptors...)
+	stream = append(stream, config.StreamInterceptors...)
+
+	opts := []grpc.ServerOption{grpc.ChainUnaryInterceptor(unary...), grpc.ChainStreamInterceptor(stream...)}
+	if config.TLSConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(config.TLSConfig)))
+	}
+	opts = append(opts, config.ServerOptions...)
+
+	s := &Server{
+		config: config,
+		server: grpc.NewServer(opts...),
+		health: health.NewServer(),
+	}
+	healthpb.RegisterHealthServer(s.server, s.health)
+	if config.Reflection {
+		reflection.Register(s.server)
+	}
+	s.health.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	return s
+}
+
+// RegisterService registers a service implementation, see grpc.ServiceRegistrar.
+func (s *Server) RegisterService(desc *grpc.ServiceDesc, impl any) {
+	s.server.RegisterService(desc, impl)
+}
+
+// GRPCServer returns the underlying grpc.Server.
+func (s *Server) GRPCServer() *grpc.Server {
+	return s.server
+}
+
+// Health returns the health service, to report the status of dependencies
+// with SetServingStatus.
+func (s *Server) Health() *health.Server {
+	return s.health
+}
+
+// SetServing sets the health status of service, or of the whole server
+// when service is empty.
+func (s *Server) SetServing(service string, serving bool) {
+	status := healthpb.HealthCheckResponse_NOT_SERVING
+	if serving {
+		status = healthpb.HealthCheckResponse_SERVING
+	}
+	s.health.SetServingStatus(service, status)
+}
+
+// Serve serves on lis until Shutdown. It marks the server and its services
+// as serving in the health service.
+func (s *Server) Serve(lis net.Listener) error {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return ErrServerStopped
+	}
+	s.listener = lis
+	s.mu.Unlock()
+
+	for service := range s.server.GetServiceInfo() {
+		s.health.SetServingStatus(service, healthpb.HealthCheckResponse_SERVING)
+	}
+	s.health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+
+	if err := s.server.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
+
+// ListenAndServe listens on the configured address and serves.
+func (s *Server) ListenAndServe() error {
+	lis, err := net.Listen("tcp", s.config.Addr)
+	if err != nil {
+		return fmt.Errorf("grpcserver: listen on %s: %w", s.config.Addr, err)
+	}
+	return s.Serve(lis)
+}
+
+// Addr returns the address the server listens on, or nil before Serve.
+func (s *Server) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// Shutdown stops the server gracefully: the health service reports
+// NOT_SERVING, new calls are refused and running calls finish. When ctx is
+// done first, the remaining calls are cancelled and ctx.Err() is returned.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+
+	s.health.Shutdown()
+
+	done := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.server.Stop()
+		<-done
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,147 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/fsvxavier/nexs-lib/validation/validator"
+)
+
+// internalMessage replaces the message of errors that are not domain errors,
+// so internal details do not reach clients.
+const internalMessage = "internal server error"
+
+// codeByType maps domain error types to gRPC codes.
+var codeByType = map[interfaces.ErrorType]codes.Code{
+	interfaces.ValidationError:           codes.InvalidArgument,
+	interfaces.BadRequestError:           codes.InvalidArgument,
+	interfaces.InvalidSchemaError:        codes.InvalidArgument,
+	interfaces.UnsupportedMediaTypeError: codes.InvalidArgument,
+	interfaces.PayloadTooLargeError:      codes.InvalidArgument,
+	interfaces.AuthenticationError:       codes.Unauthenticated,
+	interfaces.AuthorizationError:        codes.PermissionDenied,
+	interfaces.NotFoundError:             codes.NotFound,
+	interfaces.ConflictError:             codes.Aborted,
+	interfaces.BusinessError:             codes.FailedPrecondition,
+	interfaces.UnprocessableEntityError:  codes.FailedPrecondition,
+	interfaces.WorkflowError:             codes.FailedPrecondition,
+	interfaces.RateLimitError:            codes.ResourceExhausted,
+	interfaces.ResourceExhaustedError:    codes.ResourceExhausted,
+	interfaces.TimeoutError:              codes.DeadlineExceeded,
+	interfaces.ExternalServiceError:      codes.Unavailable,
+	interfaces.ServiceUnavailableError:   codes.Unavailable,
+	interfaces.CircuitBreakerError:       codes.Unavailable,
+	interfaces.UnsupportedOperationError: codes.Unimplemented,
+}
+
+// CodeOf returns the gRPC code of a domain error type. Types without a
+// specific code, such as database or infrastructure errors, map to Internal.
+func CodeOf(errorType interfaces.ErrorType) codes.Code {
+	if code, ok := codeByType[errorType]; ok {
+		return code
+	}
+	return codes.Internal
+}
+
+// FieldViolation is implemented by validation errors of a single field, such
+// as those generated by protoc-gen-validate.
+type FieldViolation interface {
+	Field() string
+	Reason() string
+}
+
+// ToStatus converts an error returned by a handler into a gRPC status:
+//   - validator.ValidationErrors and FieldViolation errors become
+//     InvalidArgument with a BadRequest detail listing the fields;
+//   - domain errors get the code of their type, their message and an
+//     ErrorInfo detail whose reason is the error code;
+//   - gRPC status errors and context errors keep their code;
+//   - any other error becomes Internal with a generic message.
+func ToStatus(err error) *status.Status {
+	if err == nil {
+		return status.New(codes.OK, "")
+	}
+
+	if violations := fieldViolations(err); violations != nil {
+		st := status.New(codes.InvalidArgument, err.Error())
+		return withDetails(st,
+			&errdetails.ErrorInfo{Reason: "VALIDATION_ERROR", Metadata: map[string]string{"type": string(interfaces.ValidationError)}},
+			&errdetails.BadRequest{FieldViolations: violations})
+	}
+
+	var domainErr interfaces.DomainErrorInterface
+	if errors.As(err, &domainErr) {
+		code := CodeOf(domainErr.Type())
+		message := domainErr.Error()
+		if code == codes.Internal {
+			message = internalMessage
+		}
+		return withDetails(status.New(code, message), errorInfo(domainErr))
+	}
+
+	if st, ok := status.FromError(err); ok {
+		return st
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err)
+	}
+	return status.New(codes.Internal, internalMessage)
+}
+
+// fieldViolations returns the field violations of validation errors, or nil
+// for other errors.
+func fieldViolations(err error) []*errdetails.BadRequest_FieldViolation {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) && len(validationErrs) > 0 {
+		violations := make([]*errdetails.BadRequest_FieldViolation, len(validationErrs))
+		for i, fe := range validationErrs {
+			violations[i] = &errdetails.BadRequest_FieldViolation{Field: fe.Field, Description: fe.Message, Reason: fe.Rule}
+		}
+		return violations
+	}
+
+	// protoc-gen-validate returns a FieldViolation, or a multi-error listing
+	// them from ValidateAll.
+	var all interface{ AllErrors() []error }
+	if errors.As(err, &all) {
+		var violations []*errdetails.BadRequest_FieldViolation
+		for _, e := range all.AllErrors() {
+			violations = append(violations, fieldViolations(e)...)
+		}
+		return violations
+	}
+	var violation FieldViolation
+	if errors.As(err, &violation) {
+		return []*errdetails.BadRequest_FieldViolation{{Field: violation.Field(), Description: violation.Reason()}}
+	}
+	return nil
+}
+
+// errorInfo describes a domain error: its code as reason, its type and its
+// metadata, except the stack trace.
+func errorInfo(err interfaces.DomainErrorInterface) *errdetails.ErrorInfo {
+	metadata := map[string]string{"type": string(err.Type())}
+	for key, value := range err.Metadata() {
+		if key == "stack" {
+			continue
+		}
+		metadata[key] = fmt.Sprint(value)
+	}
+	return &errdetails.ErrorInfo{Reason: err.Code(), Metadata: metadata}
+}
+
+// withDetails attaches details to st, keeping st when they cannot be encoded.
+func withDetails(st *status.Status, details ...protoadapt.MessageV1) *status.Status {
+	detailed, err := st.WithDetails(details...)
+	if err != nil {
+		return st
+	}
+	return detailed
+}
@@ -0,0 +1,23 @@
+// Package hash provides constant-time comparisons, HMAC signing with secret
+// rotation and password hashing with argon2id (the default) or bcrypt,
+// rehashing stored passwords to the current parameters when they are
+// verified.
+package hash
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+)
+
+// Equal reports whether a and b are equal in time independent of their
+// contents, e.g. for API keys or tokens. Unlike subtle.ConstantTimeCompare,
+// it also hides whether the lengths differ: it compares SHA-256 digests.
+func Equal(a, b []byte) bool {
+	da, db := sha256.Sum256(a), sha256.Sum256(b)
+	return subtle.ConstantTimeCompare(da[:], db[:]) == 1
+}
+
+// EqualString is Equal for strings.
+func EqualString(a, b string) bool {
+	return Equal([]byte(a), []byte(b))
+}
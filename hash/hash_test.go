@@ -0,0 +1,164 @@
+package hash
+
+import (
+	"crypto/sha512"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fastArgon2id keeps the tests fast; never use such parameters in production.
+var fastArgon2id = Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestEqual(t *testing.T) {
+	assert.True(t, EqualString("token", "token"))
+	assert.False(t, EqualString("token", "tokem"))
+	assert.False(t, EqualString("token", "token-longer"))
+	assert.True(t, Equal(nil, []byte{}))
+}
+
+func TestSigner(t *testing.T) {
+	old, err := NewSigner([]byte("old-secret-0123456"))
+	require.NoError(t, err)
+	signer, err := NewSigner([]byte("new-secret-0123456"), WithPreviousSecrets([]byte("old-secret-0123456")))
+	require.NoError(t, err)
+
+	signature := signer.Sign([]byte("payload"))
+	assert.Len(t, signature, 32)
+	assert.NoError(t, signer.Verify([]byte("payload"), signature))
+	assert.ErrorIs(t, signer.Verify([]byte("payload!"), signature), ErrInvalidSignature)
+	assert.ErrorIs(t, old.Verify([]byte("payload"), signature), ErrInvalidSignature)
+
+	// signatures of the previous secret are accepted during the rotation
+	legacy := old.SignString("payload")
+	assert.NoError(t, signer.VerifyString("payload", legacy))
+	resign, err := signer.NeedsResign([]byte("payload"), old.Sign([]byte("payload")))
+	require.NoError(t, err)
+	assert.True(t, resign)
+	resign, err = signer.NeedsResign([]byte("payload"), signature)
+	require.NoError(t, err)
+	assert.False(t, resign)
+	_, err = signer.NeedsResign([]byte("payload"), []byte("forged"))
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	assert.ErrorIs(t, signer.VerifyString("payload", "not base64!"), ErrInvalidSignature)
+	assert.NotContains(t, signer.SignString("payload"), "=")
+
+	sha512Signer, err := NewSigner([]byte("new-secret-0123456"), WithHash(sha512.New))
+	require.NoError(t, err)
+	assert.Len(t, sha512Signer.Sign([]byte("payload")), 64)
+}
+
+func TestNewSigner_WeakSecret(t *testing.T) {
+	for _, secret := range [][]byte{nil, {}, []byte("fifteen-bytes!!")} {
+		signer, err := NewSigner(secret)
+		assert.ErrorIs(t, err, ErrWeakSecret)
+		assert.Nil(t, signer)
+	}
+
+	_, err := NewSigner(make([]byte, MinSecretLength))
+	assert.NoError(t, err)
+}
+
+func TestPasswordHasher_Argon2id(t *testing.T) {
+	h, err := NewPasswordHasher(WithArgon2id(fastArgon2id))
+	require.NoError(t, err)
+
+	encoded, err := h.Hash("correct horse")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(encoded, "$argon2id$v=19$m=1024,t=1,p=1$"), encoded)
+	other, err := h.Hash("correct horse")
+	require.NoError(t, err)
+	assert.NotEqual(t, encoded, other, "salted")
+
+	ok, err := h.Verify("correct horse", encoded)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = h.Verify("battery staple", encoded)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.False(t, h.NeedsRehash(encoded))
+
+	stronger := fastArgon2id
+	stronger.Iterations = 2
+	upgraded, err := NewPasswordHasher(WithArgon2id(stronger))
+	require.NoError(t, err)
+	assert.True(t, upgraded.NeedsRehash(encoded))
+	ok, err = upgraded.Verify("correct horse", encoded)
+	require.NoError(t, err)
+	assert.True(t, ok, "hashes of other parameters are still verified")
+}
+
+func TestPasswordHasher_Bcrypt(t *testing.T) {
+	h, err := NewPasswordHasher(WithBcrypt(bcrypt.MinCost))
+	require.NoError(t, err)
+
+	encoded, err := h.Hash("correct horse")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(encoded, "$2a$04$"), encoded)
+	ok, err := h.Verify("correct horse", encoded)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = h.Verify("battery staple", encoded)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.False(t, h.NeedsRehash(encoded))
+
+	_, err = h.Hash(strings.Repeat("a", 73))
+	assert.Error(t, err, "bcrypt would ignore the bytes beyond 72")
+
+	defaults, err := NewPasswordHasher(WithBcrypt(0))
+	require.NoError(t, err)
+	assert.True(t, defaults.NeedsRehash(encoded))
+}
+
+func TestPasswordHasher_VerifyAndRehash(t *testing.T) {
+	legacy, err := NewPasswordHasher(WithBcrypt(bcrypt.MinCost))
+	require.NoError(t, err)
+	stored, err := legacy.Hash("correct horse")
+	require.NoError(t, err)
+
+	h, err := NewPasswordHasher(WithArgon2id(fastArgon2id))
+	require.NoError(t, err)
+
+	ok, rehashed, err := h.VerifyAndRehash("battery staple", stored)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Empty(t, rehashed)
+
+	ok, rehashed, err = h.VerifyAndRehash("correct horse", stored)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	require.True(t, strings.HasPrefix(rehashed, "$argon2id$"), "bcrypt hashes migrate to argon2id")
+
+	ok, again, err := h.VerifyAndRehash("correct horse", rehashed)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Empty(t, again, "current hashes are kept")
+}
+
+func TestPasswordHasher_Errors(t *testing.T) {
+	_, err := NewPasswordHasher(WithArgon2id(Argon2idParams{Memory: 1024, Iterations: 0, Parallelism: 1, SaltLength: 16, KeyLength: 32}))
+	assert.ErrorIs(t, err, ErrInvalidParams)
+	_, err = NewPasswordHasher(WithBcrypt(40))
+	assert.ErrorIs(t, err, ErrInvalidParams)
+
+	h, err := NewPasswordHasher(WithArgon2id(fastArgon2id))
+	require.NoError(t, err)
+	for encoded, want := range map[string]error{
+		"plain": ErrUnsupportedHash,
+		"$argon2i$v=19$m=1024,t=1,p=1$c2FsdA$a2V5":                                ErrUnsupportedHash,
+		"$argon2id$v=16$m=1024,t=1,p=1$c2FsdHNhbHRzYWx0$a2V5a2V5a2V5a2V5a2V5a2V5": ErrUnsupportedHash,
+		"$argon2id$v=19$m=1024,t=0,p=1$c2FsdHNhbHRzYWx0$a2V5a2V5a2V5a2V5a2V5a2V5": ErrInvalidHash,
+		"$argon2id$v=19$m=1024,t=1,p=1$!!!$a2V5":                                  ErrInvalidHash,
+		"$argon2id$v=19$m=1024":                                                   ErrInvalidHash,
+		"$2a$04$short":                                                            ErrInvalidHash,
+	} {
+		_, err := h.Verify("password", encoded)
+		assert.ErrorIs(t, err, want, encoded)
+		assert.True(t, h.NeedsRehash(encoded), encoded)
+	}
+}
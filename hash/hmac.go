@@ -0,0 +1,122 @@
+package hash
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	stdhash "hash"
+)
+
+// MinSecretLength is the minimum length of the current secret of a Signer.
+const MinSecretLength = 16
+
+var (
+	// ErrInvalidSignature is returned when a signature does not match the
+	// message with any of the secrets.
+	ErrInvalidSignature = errors.New("hash: invalid signature")
+	// ErrWeakSecret is returned by NewSigner for a secret shorter than
+	// MinSecretLength, e.g. one missing from the configuration.
+	ErrWeakSecret = errors.New("hash: HMAC secret shorter than 16 bytes")
+)
+
+// SignerOption configures a Signer.
+type SignerOption func(*Signer)
+
+// WithPreviousSecrets accepts signatures made with retired secrets, so a
+// secret can be rotated without invalidating the signatures in flight.
+func WithPreviousSecrets(secrets ...[]byte) SignerOption {
+	return func(s *Signer) {
+		for _, secret := range secrets {
+			if len(secret) > 0 {
+				s.previous = append(s.previous, secret)
+			}
+		}
+	}
+}
+
+// WithHash sets the hash function of the HMAC. Defaults to SHA-256.
+func WithHash(fn func() stdhash.Hash) SignerOption {
+	return func(s *Signer) {
+		if fn != nil {
+			s.hash = fn
+		}
+	}
+}
+
+// Signer signs messages with HMAC and verifies their signatures. It is safe
+// for concurrent use.
+type Signer struct {
+	secret   []byte
+	previous [][]byte
+	hash     func() stdhash.Hash
+}
+
+// NewSigner creates a Signer signing with secret. Secrets shorter than
+// MinSecretLength return ErrWeakSecret, so a missing secret never signs
+// with an empty key.
+func NewSigner(secret []byte, opts ...SignerOption) (*Signer, error) {
+	if len(secret) < MinSecretLength {
+		return nil, ErrWeakSecret
+	}
+	s := &Signer{secret: secret, hash: sha256.New}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+// Sign returns the HMAC of the message with the current secret.
+func (s *Signer) Sign(message []byte) []byte {
+	return s.mac(s.secret, message)
+}
+
+// SignString returns the HMAC of the message as unpadded base64url.
+func (s *Signer) SignString(message string) string {
+	return base64.RawURLEncoding.EncodeToString(s.Sign([]byte(message)))
+}
+
+// Verify checks the HMAC of the message against the current and previous
+// secrets, returning ErrInvalidSignature when none matches. Each secret is
+// compared in constant time.
+func (s *Signer) Verify(message, signature []byte) error {
+	_, err := s.verify(message, signature)
+	return err
+}
+
+// VerifyString is Verify for signatures of SignString.
+func (s *Signer) VerifyString(message, signature string) error {
+	decoded, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	return s.Verify([]byte(message), decoded)
+}
+
+// NeedsResign reports whether a valid signature was made with a previous
+// secret, so long-lived signed values can be signed again after a rotation.
+// Invalid signatures return ErrInvalidSignature.
+func (s *Signer) NeedsResign(message, signature []byte) (bool, error) {
+	current, err := s.verify(message, signature)
+	return !current, err
+}
+
+// verify returns whether the signature was made with the current secret.
+func (s *Signer) verify(message, signature []byte) (current bool, err error) {
+	if hmac.Equal(signature, s.mac(s.secret, message)) {
+		return true, nil
+	}
+	for _, secret := range s.previous {
+		if hmac.Equal(signature, s.mac(secret, message)) {
+			return false, nil
+		}
+	}
+	return false, ErrInvalidSignature
+}
+
+// mac computes the HMAC of the message with the secret.
+func (s *Signer) mac(secret, message []byte) []byte {
+	mac := hmac.New(s.hash, secret)
+	mac.Write(message)
+	return mac.Sum(nil)
+}
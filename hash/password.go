@@ -0,0 +1,242 @@
+package hash
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Algorithm is a password hashing algorithm.
+type Algorithm string
+
+// Supported algorithms.
+const (
+	Argon2id Algorithm = "argon2id"
+	Bcrypt   Algorithm = "bcrypt"
+)
+
+// DefaultBcryptCost is the bcrypt cost of WithBcrypt(0).
+const DefaultBcryptCost = 12
+
+var (
+	// ErrInvalidHash is returned for encoded hashes that cannot be parsed.
+	ErrInvalidHash = errors.New("hash: invalid password hash")
+	// ErrUnsupportedHash is returned for hashes of unknown algorithms.
+	ErrUnsupportedHash = errors.New("hash: unsupported password hash")
+	// ErrInvalidParams is returned for parameters out of the valid range.
+	ErrInvalidParams = errors.New("hash: invalid password hashing parameters")
+)
+
+// Argon2idParams are the argon2id parameters. Memory and Iterations trade
+// hashing time for resistance to guessing; tune them to take about as long
+// as the login latency allows.
+type Argon2idParams struct {
+	// Memory in KiB.
+	Memory uint32
+	// Iterations over the memory.
+	Iterations uint32
+	// Parallelism is the number of threads.
+	Parallelism uint8
+	// SaltLength in bytes, at least 8.
+	SaltLength uint32
+	// KeyLength in bytes, at least 16.
+	KeyLength uint32
+}
+
+// DefaultArgon2idParams are the second recommended option of RFC 9106,
+// adjusted to 2 threads: 64 MiB and 3 iterations.
+var DefaultArgon2idParams = Argon2idParams{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// validate checks the parameters are in the range argon2 accepts.
+func (p Argon2idParams) validate() error {
+	if p.Iterations < 1 || p.Parallelism < 1 || p.Memory < 8*uint32(p.Parallelism) ||
+		p.SaltLength < 8 || p.KeyLength < 16 {
+		return fmt.Errorf("%w: argon2id %+v", ErrInvalidParams, p)
+	}
+	return nil
+}
+
+// PasswordOption configures a PasswordHasher.
+type PasswordOption func(*PasswordHasher)
+
+// WithArgon2id hashes new passwords with argon2id and the parameters. It is
+// the default, with DefaultArgon2idParams.
+func WithArgon2id(params Argon2idParams) PasswordOption {
+	return func(h *PasswordHasher) {
+		h.algorithm, h.argon2 = Argon2id, params
+	}
+}
+
+// WithBcrypt hashes new passwords with bcrypt and the cost, or
+// DefaultBcryptCost when zero, for compatibility with systems that only
+// verify bcrypt. bcrypt ignores passwords beyond 72 bytes, so longer ones
+// are rejected.
+func WithBcrypt(cost int) PasswordOption {
+	return func(h *PasswordHasher) {
+		if cost == 0 {
+			cost = DefaultBcryptCost
+		}
+		h.algorithm, h.bcryptCost = Bcrypt, cost
+	}
+}
+
+// PasswordHasher hashes passwords with the configured algorithm and
+// verifies hashes of every supported algorithm, so stored hashes can be
+// migrated to new parameters or from bcrypt to argon2id as users log in.
+type PasswordHasher struct {
+	algorithm  Algorithm
+	argon2     Argon2idParams
+	bcryptCost int
+}
+
+// NewPasswordHasher creates a PasswordHasher, argon2id with
+// DefaultArgon2idParams by default. It fails with ErrInvalidParams for
+// parameters out of range.
+func NewPasswordHasher(opts ...PasswordOption) (*PasswordHasher, error) {
+	h := &PasswordHasher{algorithm: Argon2id, argon2: DefaultArgon2idParams, bcryptCost: DefaultBcryptCost}
+	for _, opt := range opts {
+		opt(h)
+	}
+	if err := h.argon2.validate(); err != nil {
+		return nil, err
+	}
+	if h.bcryptCost < bcrypt.MinCost || h.bcryptCost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("%w: bcrypt cost %d", ErrInvalidParams, h.bcryptCost)
+	}
+	return h, nil
+}
+
+// Hash hashes the password with a random salt. Argon2id hashes use the PHC
+// string format, $argon2id$v=19$m=65536,t=3,p=2$salt$key, bcrypt hashes the
+// modular crypt format, $2a$12$...
+func (h *PasswordHasher) Hash(password string) (string, error) {
+	if h.algorithm == Bcrypt {
+		encoded, err := bcrypt.GenerateFromPassword([]byte(password), h.bcryptCost)
+		if err != nil {
+			return "", fmt.Errorf("hash: bcrypt: %w", err)
+		}
+		return string(encoded), nil
+	}
+
+	salt := make([]byte, h.argon2.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	p := h.argon2
+	key := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, p.Memory, p.Iterations, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify reports whether the password matches the encoded hash, of any
+// supported algorithm. Malformed hashes return ErrInvalidHash and unknown
+// algorithms ErrUnsupportedHash.
+func (h *PasswordHasher) Verify(password, encoded string) (bool, error) {
+	switch algorithmOf(encoded) {
+	case Argon2id:
+		p, salt, key, err := decodeArgon2id(encoded)
+		if err != nil {
+			return false, err
+		}
+		computed := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+		return subtle.ConstantTimeCompare(key, computed) == 1, nil
+	case Bcrypt:
+		err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+		switch {
+		case err == nil:
+			return true, nil
+		case errors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
+			return false, nil
+		default:
+			return false, fmt.Errorf("%w: %v", ErrInvalidHash, err)
+		}
+	}
+	return false, ErrUnsupportedHash
+}
+
+// NeedsRehash reports whether the encoded hash was made with another
+// algorithm or other parameters than the configured ones.
+func (h *PasswordHasher) NeedsRehash(encoded string) bool {
+	algorithm := algorithmOf(encoded)
+	if algorithm != h.algorithm {
+		return true
+	}
+	if algorithm == Bcrypt {
+		cost, err := bcrypt.Cost([]byte(encoded))
+		return err != nil || cost != h.bcryptCost
+	}
+	p, _, _, err := decodeArgon2id(encoded)
+	return err != nil || p != h.argon2
+}
+
+// VerifyAndRehash verifies the password and, when it matches a hash that
+// needs a rehash, hashes it with the configured algorithm and parameters.
+// The caller stores rehashed, when not empty, in place of encoded:
+//
+//	ok, rehashed, err := hasher.VerifyAndRehash(password, user.PasswordHash)
+//	if ok && rehashed != "" {
+//		repo.UpdatePasswordHash(ctx, user.ID, rehashed)
+//	}
+func (h *PasswordHasher) VerifyAndRehash(password, encoded string) (ok bool, rehashed string, err error) {
+	if ok, err = h.Verify(password, encoded); !ok || err != nil {
+		return ok, "", err
+	}
+	if !h.NeedsRehash(encoded) {
+		return true, "", nil
+	}
+	rehashed, err = h.Hash(password)
+	if err != nil {
+		// the password is valid; the rehash is retried on the next login
+		return true, "", nil
+	}
+	return true, rehashed, nil
+}
+
+// algorithmOf returns the algorithm of an encoded hash, or "" when unknown.
+func algorithmOf(encoded string) Algorithm {
+	switch {
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		return Argon2id
+	case strings.HasPrefix(encoded, "$2a$"), strings.HasPrefix(encoded, "$2b$"), strings.HasPrefix(encoded, "$2y$"):
+		return Bcrypt
+	}
+	return ""
+}
+
+// decodeArgon2id parses a PHC argon2id hash.
+func decodeArgon2id(encoded string) (p Argon2idParams, salt, key []byte, err error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return p, nil, nil, ErrInvalidHash
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, fmt.Errorf("%w: argon2 version %q", ErrUnsupportedHash, parts[2])
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism); err != nil {
+		return p, nil, nil, ErrInvalidHash
+	}
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return p, nil, nil, ErrInvalidHash
+	}
+	if key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return p, nil, nil, ErrInvalidHash
+	}
+	p.SaltLength, p.KeyLength = uint32(len(salt)), uint32(len(key))
+	if err := p.validate(); err != nil {
+		return p, nil, nil, ErrInvalidHash
+	}
+	return p, salt, key, nil
+}
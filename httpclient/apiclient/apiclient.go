@@ -0,0 +1,286 @@
+// Package apiclient is the runtime of the API clients generated from OpenAPI
+// documents by apiclient-gen (see the gen package). Requests and successful
+// responses are validated against the document, and the error responses it
+// documents are decoded into their generated types and returned as domain
+// errors.
+package apiclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	domaininterfaces "github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/fsvxavier/nexs-lib/httpclient"
+	"github.com/fsvxavier/nexs-lib/httpclient/interfaces"
+	"github.com/fsvxavier/nexs-lib/validation/validator/openapi"
+)
+
+// CodeMalformedResponse is the code of the error returned when a successful
+// response body cannot be decoded.
+const CodeMalformedResponse = "API_MALFORMED_RESPONSE"
+
+// Client sends the operations of an API through an httpclient client.
+type Client struct {
+	http              interfaces.Client
+	doc               *openapi.Document
+	validator         *openapi.Validator
+	validateRequests  bool
+	validateResponses bool
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithoutRequestValidation sends requests without validating them against
+// the document.
+func WithoutRequestValidation() Option {
+	return func(c *Client) {
+		c.validateRequests = false
+	}
+}
+
+// WithoutResponseValidation decodes successful responses without validating
+// them against the document.
+func WithoutResponseValidation() Option {
+	return func(c *Client) {
+		c.validateResponses = false
+	}
+}
+
+// New creates a client of the API described by spec, in JSON or YAML. The
+// base URL of client must point to the API server.
+func New(client interfaces.Client, spec []byte, opts ...Option) (*Client, error) {
+	if client == nil {
+		return nil, errors.New("apiclient: client cannot be nil")
+	}
+	doc, err := openapi.Load(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Client{
+		http:              client,
+		doc:               doc,
+		validator:         openapi.New(doc),
+		validateRequests:  true,
+		validateResponses: true,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Operation is a request to an operation of the API.
+type Operation struct {
+	// Method is the HTTP method.
+	Method string
+	// Path is the operation path with the path parameters expanded, relative
+	// to the server URL.
+	Path string
+	// Body is encoded as JSON when not nil.
+	Body any
+	// Errors creates the value decoding an error response, by response key of
+	// the document: a status code, a range such as "4XX", or "default".
+	Errors map[string]func() any
+
+	query   url.Values
+	headers map[string]string
+}
+
+// AddQuery adds a query parameter.
+func (op *Operation) AddQuery(name string, value any) {
+	if op.query == nil {
+		op.query = url.Values{}
+	}
+	op.query.Add(name, format(value))
+}
+
+// SetHeader sets a request header.
+func (op *Operation) SetHeader(name string, value any) {
+	if op.headers == nil {
+		op.headers = make(map[string]string)
+	}
+	op.headers[name] = format(value)
+}
+
+// PathValue formats a path parameter.
+func PathValue(value any) string {
+	return url.PathEscape(format(value))
+}
+
+// format formats a parameter value: times as RFC 3339, the others with fmt.
+func format(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case fmt.Stringer:
+		return v.String()
+	}
+	return fmt.Sprint(value)
+}
+
+// Do sends the operation, decoding a successful response body into out when
+// not nil. Invalid requests and responses fail with the domain errors of the
+// openapi validator, failed requests with those of httpclient.ToDomainError,
+// and documented error responses with a domain error wrapping a
+// *ResponseError.
+func (c *Client) Do(ctx context.Context, op *Operation, out any) error {
+	endpoint := op.Path
+	if len(op.query) > 0 {
+		endpoint += "?" + op.query.Encode()
+	}
+
+	headers := make(map[string]string, len(op.headers)+1)
+	for name, value := range op.headers {
+		headers[name] = value
+	}
+	var body []byte
+	if op.Body != nil {
+		var err error
+		if body, err = json.Marshal(op.Body); err != nil {
+			return domainerrors.New(domaininterfaces.ValidationError, openapi.CodeMalformedBody,
+				"failed to encode request body").Wrap(err)
+		}
+		headers["Content-Type"] = "application/json"
+	}
+
+	if c.validateRequests {
+		if err := c.validator.ValidateRequest(c.validationRequest(ctx, op.Method, endpoint, headers, body)); err != nil {
+			return err
+		}
+	}
+
+	req := &interfaces.Request{
+		Method:               op.Method,
+		URL:                  endpoint,
+		Headers:              headers,
+		DisableAutoUnmarshal: true,
+	}
+	if body != nil {
+		req.Body = body
+	}
+	resp, err := c.http.Do(ctx, req)
+	if err != nil || resp.StatusCode >= http.StatusBadRequest {
+		return c.responseError(req, resp, err, op.Errors)
+	}
+
+	if c.validateResponses {
+		header := make(http.Header, len(resp.Headers))
+		for name, value := range resp.Headers {
+			header.Set(name, value)
+		}
+		if err := c.validator.ValidateResponse(c.validationRequest(ctx, op.Method, endpoint, nil, nil),
+			resp.StatusCode, header, resp.Body); err != nil {
+			return err
+		}
+	}
+
+	if out != nil && len(resp.Body) > 0 {
+		if err := json.Unmarshal(resp.Body, out); err != nil {
+			return domainerrors.New(domaininterfaces.ServerError, CodeMalformedResponse,
+				"failed to decode response body").Wrap(err)
+		}
+	}
+	return nil
+}
+
+// validationRequest builds the request checked by the validator, whose path
+// includes the base path of the document server.
+func (c *Client) validationRequest(ctx context.Context, method, endpoint string, headers map[string]string, body []byte) *http.Request {
+	r, _ := http.NewRequestWithContext(ctx, method, "http://api"+c.doc.BasePath()+"/"+strings.TrimPrefix(endpoint, "/"), bytes.NewReader(body))
+	for name, value := range headers {
+		r.Header.Set(name, value)
+	}
+	return r
+}
+
+// ResponseError is wrapped by the domain error of a documented error
+// response, holding its decoded body.
+type ResponseError struct {
+	StatusCode int
+	// Body is the value created by Operation.Errors, holding the response.
+	Body any
+
+	cause error
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("server responded with status %d", e.StatusCode)
+}
+
+// Unwrap returns the cause of the domain error, such as
+// domainerrors.ErrRemote.
+func (e *ResponseError) Unwrap() error {
+	return e.cause
+}
+
+// ErrorBody returns the decoded body of a documented error response.
+func ErrorBody[T any](err error) (*T, bool) {
+	var respErr *ResponseError
+	if !errors.As(err, &respErr) {
+		return nil, false
+	}
+	body, ok := respErr.Body.(*T)
+	return body, ok
+}
+
+// responseError maps a failed request to a domain error. A documented error
+// body is decoded into its type; its code and message fields, when present,
+// become the code and message of the error, unless it is a problem+json
+// response already rebuilt by httpclient.ToDomainError.
+func (c *Client) responseError(req *interfaces.Request, resp *interfaces.Response, err error, models map[string]func() any) error {
+	mapped := httpclient.ToDomainError(req, resp, err)
+	if err != nil || resp == nil {
+		return mapped
+	}
+	domainErr, ok := mapped.(domaininterfaces.DomainErrorInterface)
+	newModel := matchStatus(models, resp.StatusCode)
+	if !ok || newModel == nil {
+		return mapped
+	}
+
+	model := newModel()
+	if json.Unmarshal(resp.Body, model) != nil {
+		return mapped
+	}
+
+	var fields struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		Detail  string `json:"detail"`
+		Title   string `json:"title"`
+	}
+	_ = json.Unmarshal(resp.Body, &fields)
+	if fields.Code != "" && !errors.Is(domainErr, domainerrors.ErrRemote) {
+		message := fields.Message
+		for _, alternative := range []string{fields.Detail, fields.Title, domainErr.Error()} {
+			if message == "" {
+				message = alternative
+			}
+		}
+		domainErr = domainerrors.NewWithMetadata(domainErr.Type(), fields.Code, message, domainErr.Metadata())
+	}
+	return domainErr.Wrap(&ResponseError{StatusCode: resp.StatusCode, Body: model, cause: domainErr.Unwrap()})
+}
+
+// matchStatus returns the model of a status: the exact code, its range, or
+// default.
+func matchStatus(models map[string]func() any, status int) func() any {
+	for _, key := range []string{fmt.Sprint(status), fmt.Sprintf("%dXX", status/100), "default"} {
+		if newModel, ok := models[key]; ok {
+			return newModel
+		}
+	}
+	return nil
+}
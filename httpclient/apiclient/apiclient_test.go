@@ -0,0 +1,56 @@
+package apiclient
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperation_Parameters(t *testing.T) {
+	op := &Operation{}
+	op.AddQuery("since", time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC))
+	op.AddQuery("tag", "a b")
+	op.AddQuery("tag", 7)
+	op.SetHeader("X-Limit", int64(10))
+
+	assert.Equal(t, "since=2025-01-02T03%3A04%3A05Z&tag=a+b&tag=7", op.query.Encode())
+	assert.Equal(t, "10", op.headers["X-Limit"])
+	assert.Equal(t, "a%2Fb", PathValue("a/b"))
+	assert.Equal(t, "42", PathValue(42))
+}
+
+func TestMatchStatus(t *testing.T) {
+	models := map[string]func() any{
+		"404":     func() any { return "404" },
+		"4XX":     func() any { return "4XX" },
+		"default": func() any { return "default" },
+	}
+	assert.Equal(t, "404", matchStatus(models, 404)())
+	assert.Equal(t, "4XX", matchStatus(models, 409)())
+	assert.Equal(t, "default", matchStatus(models, 500)())
+	assert.Nil(t, matchStatus(map[string]func() any{"404": models["404"]}, 500))
+}
+
+func TestErrorBody(t *testing.T) {
+	type problem struct{ Code string }
+	cause := errors.New("remote")
+	err := &ResponseError{StatusCode: 409, Body: &problem{Code: "CONFLICT"}, cause: cause}
+
+	body, ok := ErrorBody[problem](err)
+	require.True(t, ok)
+	assert.Equal(t, "CONFLICT", body.Code)
+	assert.ErrorIs(t, err, cause)
+
+	_, ok = ErrorBody[struct{}](err)
+	assert.False(t, ok)
+	_, ok = ErrorBody[problem](cause)
+	assert.False(t, ok)
+}
+
+func TestNew_InvalidDocument(t *testing.T) {
+	_, err := New(nil, []byte(`openapi: 3.1.0`))
+	assert.Error(t, err)
+}
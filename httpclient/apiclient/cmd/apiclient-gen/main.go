@@ -0,0 +1,62 @@
+// Command apiclient-gen generates a typed API client from an OpenAPI 3
+// document, built on the httpclient package:
+//
+//	apiclient-gen -spec orders.yaml -package orders -out client_gen.go
+//
+// Typically run with go:generate next to the document.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fsvxavier/nexs-lib/httpclient/apiclient/gen"
+)
+
+func main() {
+	specPath := flag.String("spec", "", "OpenAPI document, in JSON or YAML")
+	pkg := flag.String("package", "", "package name of the generated file (defaults to the output directory name)")
+	out := flag.String("out", "", "generated file (defaults to standard output)")
+	client := flag.String("client", gen.DefaultClientName, "name of the client type")
+	flag.Parse()
+
+	if err := run(*specPath, *pkg, *out, *client); err != nil {
+		fmt.Fprintln(os.Stderr, "apiclient-gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(specPath, pkg, out, client string) error {
+	if specPath == "" {
+		return fmt.Errorf("-spec is required")
+	}
+	spec, err := os.ReadFile(filepath.Clean(specPath))
+	if err != nil {
+		return err
+	}
+
+	if pkg == "" {
+		dir, err := filepath.Abs(filepath.Dir(out))
+		if err != nil {
+			return err
+		}
+		pkg = filepath.Base(dir)
+	}
+
+	source, err := gen.Generate(spec, gen.Config{
+		Package:    pkg,
+		ClientName: client,
+		Source:     filepath.Base(specPath),
+	})
+	if err != nil {
+		return err
+	}
+
+	if out == "" {
+		_, err = os.Stdout.Write(source)
+		return err
+	}
+	return os.WriteFile(out, source, 0o644)
+}
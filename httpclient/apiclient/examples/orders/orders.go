@@ -0,0 +1,5 @@
+// Package orders is a client generated by apiclient-gen from orders.yaml,
+// an example of the generated code.
+package orders
+
+//go:generate go run ../../cmd/apiclient-gen -spec orders.yaml -package orders -out client_gen.go
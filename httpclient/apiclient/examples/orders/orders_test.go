@@ -0,0 +1,184 @@
+package orders
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	domaininterfaces "github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/fsvxavier/nexs-lib/httpclient"
+	"github.com/fsvxavier/nexs-lib/httpclient/apiclient"
+	"github.com/fsvxavier/nexs-lib/httpclient/config"
+	"github.com/fsvxavier/nexs-lib/httpclient/interfaces"
+	"github.com/fsvxavier/nexs-lib/validation/validator/openapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var created = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func newClient(t *testing.T, handler http.HandlerFunc, opts ...apiclient.Option) (*Client, *atomic.Int64) {
+	t.Helper()
+	var calls atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := config.DefaultConfig()
+	cfg.BaseURL = server.URL + "/v1"
+	cfg.RetryConfig.MaxRetries = 0
+	httpClient, err := httpclient.NewWithConfig(interfaces.ProviderNetHTTP, cfg)
+	require.NoError(t, err)
+
+	client, err := NewClient(httpClient, opts...)
+	require.NoError(t, err)
+	return client, &calls
+}
+
+func writeJSON(w http.ResponseWriter, contentType string, status int, body any) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func TestListOrders(t *testing.T) {
+	client, _ := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/orders", r.URL.Path)
+		assert.Equal(t, "10", r.URL.Query().Get("limit"))
+		assert.Equal(t, []string{"open", "paid"}, r.URL.Query()["status"])
+		assert.Equal(t, "acme", r.Header.Get("X-Tenant-ID"))
+		writeJSON(w, "application/json", http.StatusOK, []Order{
+			{ID: 1, Status: OrderStatusOpen, CreatedAt: created, Items: []Item{{Sku: "A1", Quantity: 2}}},
+		})
+	})
+
+	limit := int64(10)
+	orders, err := client.ListOrders(context.Background(), &ListOrdersParams{
+		Limit:     &limit,
+		Status:    []OrderStatus{OrderStatusOpen, OrderStatusPaid},
+		XTenantID: "acme",
+	})
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	assert.Equal(t, int64(1), orders[0].ID)
+	assert.Equal(t, created, orders[0].CreatedAt)
+	assert.Equal(t, "A1", orders[0].Items[0].Sku)
+}
+
+func TestCreateOrder_InvalidRequestIsNotSent(t *testing.T) {
+	client, calls := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	_, err := client.CreateOrder(context.Background(), &CreateOrderParams{XTenantID: "acme"}, &NewOrder{Items: []Item{}})
+
+	var domainErr domaininterfaces.DomainErrorInterface
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, openapi.CodeInvalidRequest, domainErr.Code())
+	assert.Equal(t, domaininterfaces.ValidationError, domainErr.Type())
+	assert.Equal(t, int64(0), calls.Load())
+}
+
+func TestCreateOrder_ProblemResponse(t *testing.T) {
+	client, _ := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body NewOrder
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "B2", body.Items[0].Sku)
+		writeJSON(w, "application/problem+json", http.StatusUnprocessableEntity, map[string]any{
+			"code":   "OUT_OF_STOCK",
+			"title":  "product out of stock",
+			"errors": []map[string]string{{"field": "items[0].sku", "message": "unavailable"}},
+		})
+	})
+
+	_, err := client.CreateOrder(context.Background(), &CreateOrderParams{XTenantID: "acme"},
+		&NewOrder{Items: []Item{{Sku: "B2", Quantity: 1}}})
+
+	var domainErr domaininterfaces.DomainErrorInterface
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, "OUT_OF_STOCK", domainErr.Code())
+	assert.Equal(t, domaininterfaces.UnprocessableEntityError, domainErr.Type())
+	assert.ErrorIs(t, err, domainerrors.ErrRemote)
+
+	problem, ok := apiclient.ErrorBody[Problem](err)
+	require.True(t, ok)
+	require.Len(t, problem.Errors, 1)
+	assert.Equal(t, "items[0].sku", *problem.Errors[0].Field)
+}
+
+func TestGetOrder_DocumentedError(t *testing.T) {
+	client, _ := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/orders/42", r.URL.Path)
+		writeJSON(w, "application/json", http.StatusNotFound, Error{Code: "ORDER_NOT_FOUND", Message: "order 42 not found"})
+	})
+
+	_, err := client.GetOrder(context.Background(), 42)
+
+	var domainErr domaininterfaces.DomainErrorInterface
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, "ORDER_NOT_FOUND", domainErr.Code())
+	assert.Equal(t, "order 42 not found", domainErr.Error())
+	assert.Equal(t, domaininterfaces.NotFoundError, domainErr.Type())
+	assert.Equal(t, 404, domainErr.Metadata()["status_code"])
+
+	body, ok := apiclient.ErrorBody[Error](err)
+	require.True(t, ok)
+	assert.Equal(t, "ORDER_NOT_FOUND", body.Code)
+}
+
+func TestGetOrder_UndocumentedError(t *testing.T) {
+	client, _ := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	_, err := client.GetOrder(context.Background(), 42)
+
+	var domainErr domaininterfaces.DomainErrorInterface
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, "HTTP_502", domainErr.Code())
+	_, ok := apiclient.ErrorBody[Error](err)
+	assert.False(t, ok)
+}
+
+func TestGetOrder_InvalidResponse(t *testing.T) {
+	invalid := func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, "application/json", http.StatusOK, map[string]any{"id": 42, "items": []any{}})
+	}
+
+	client, _ := newClient(t, invalid)
+	_, err := client.GetOrder(context.Background(), 42)
+	var domainErr domaininterfaces.DomainErrorInterface
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, openapi.CodeInvalidResponse, domainErr.Code())
+
+	client, _ = newClient(t, invalid, apiclient.WithoutResponseValidation())
+	order, err := client.GetOrder(context.Background(), 42)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), order.ID)
+}
+
+func TestCancelOrder(t *testing.T) {
+	client, _ := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		if r.URL.Path == "/v1/orders/7" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeJSON(w, "application/json", http.StatusConflict, Error{Code: "ORDER_PAID", Message: "order is paid"})
+	})
+
+	require.NoError(t, client.CancelOrder(context.Background(), 7))
+
+	err := client.CancelOrder(context.Background(), 8)
+	var domainErr domaininterfaces.DomainErrorInterface
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, "ORDER_PAID", domainErr.Code())
+	assert.Equal(t, domaininterfaces.ConflictError, domainErr.Type())
+}
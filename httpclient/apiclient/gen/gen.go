@@ -0,0 +1,825 @@
+// Package gen generates typed API clients from OpenAPI 3 documents. The
+// generated code declares a type per component schema and a method per
+// operation, built on the apiclient runtime over an httpclient client:
+// path parameters are arguments, query and header parameters are fields of
+// a <Operation>Params struct, and the JSON request and response bodies are
+// typed. Error responses documented with a JSON schema are decoded into
+// their type, retrieved with apiclient.ErrorBody.
+package gen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/fsvxavier/nexs-lib/validation/validator/openapi"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultClientName is the name of the generated client type.
+const DefaultClientName = "Client"
+
+// ErrInvalidConfig is returned for a configuration without package name.
+var ErrInvalidConfig = errors.New("gen: invalid config")
+
+// Config configures the generated code.
+type Config struct {
+	// Package is the package name of the generated file.
+	Package string
+	// ClientName is the name of the client type, DefaultClientName by default.
+	ClientName string
+	// Source names the document in the header of the generated file.
+	Source string
+}
+
+// operationMethods are the methods of a path item, in generation order.
+var operationMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Generate returns the formatted source of the client of the document, in
+// JSON or YAML.
+func Generate(spec []byte, cfg Config) ([]byte, error) {
+	if cfg.Package == "" {
+		return nil, fmt.Errorf("%w: package name is required", ErrInvalidConfig)
+	}
+	if cfg.ClientName == "" {
+		cfg.ClientName = DefaultClientName
+	}
+	// The runtime loads the document with the openapi validator
+	if _, err := openapi.Load(spec); err != nil {
+		return nil, err
+	}
+
+	var raw any
+	if err := yaml.Unmarshal(spec, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", openapi.ErrInvalidDocument, err)
+	}
+	root, _ := normalize(raw).(map[string]any)
+
+	g := &generator{
+		cfg:     cfg,
+		root:    root,
+		names:   make(map[string]bool),
+		refs:    make(map[string]string),
+		imports: map[string]bool{"github.com/fsvxavier/nexs-lib/httpclient/apiclient": true},
+	}
+	if err := g.generate(spec); err != nil {
+		return nil, err
+	}
+
+	source, err := format.Source(g.out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("gen: failed to format generated code: %w", err)
+	}
+	return source, nil
+}
+
+// generator holds the state of a generation.
+type generator struct {
+	cfg  Config
+	root map[string]any
+
+	// names are the declared type names and refs the type of each
+	// component schema by reference.
+	names   map[string]bool
+	refs    map[string]string
+	decls   []decl
+	imports map[string]bool
+
+	out bytes.Buffer
+}
+
+// decl is a type declaration.
+type decl struct {
+	name   string
+	source string
+}
+
+func (g *generator) generate(spec []byte) error {
+	schemas, _ := lookup(g.root, "components", "schemas").(map[string]any)
+	schemaNames := sortedKeys(schemas)
+	for _, name := range schemaNames {
+		g.refs["#/components/schemas/"+name] = g.uniqueName(goName(name))
+	}
+	for _, name := range schemaNames {
+		schema, _ := schemas[name].(map[string]any)
+		g.declare(g.refs["#/components/schemas/"+name], name, schema)
+	}
+
+	var operations bytes.Buffer
+	paths, _ := g.root["paths"].(map[string]any)
+	for _, template := range sortedKeys(paths) {
+		item, err := g.resolve(paths[template])
+		if err != nil {
+			return err
+		}
+		for _, method := range operationMethods {
+			operation, ok := item[method].(map[string]any)
+			if !ok {
+				continue
+			}
+			op, err := g.operation(method, template, item, operation)
+			if err != nil {
+				return err
+			}
+			g.writeOperation(&operations, op)
+		}
+	}
+
+	g.writeHeader(spec)
+	g.out.Write(operations.Bytes())
+	sort.Slice(g.decls, func(i, j int) bool { return g.decls[i].name < g.decls[j].name })
+	for _, d := range g.decls {
+		g.out.WriteString(d.source)
+	}
+	return nil
+}
+
+// writeHeader writes the package clause, the imports, the embedded
+// document and the client type.
+func (g *generator) writeHeader(spec []byte) {
+	w := &g.out
+	if g.cfg.Source != "" {
+		fmt.Fprintf(w, "// Code generated by apiclient-gen from %s. DO NOT EDIT.\n\n", g.cfg.Source)
+	} else {
+		w.WriteString("// Code generated by apiclient-gen. DO NOT EDIT.\n\n")
+	}
+	fmt.Fprintf(w, "package %s\n\n", g.cfg.Package)
+
+	g.imports["github.com/fsvxavier/nexs-lib/httpclient/interfaces"] = true
+	var std, modules []string
+	for _, path := range sortedKeys(g.imports) {
+		if strings.Contains(strings.Split(path, "/")[0], ".") {
+			modules = append(modules, path)
+		} else {
+			std = append(std, path)
+		}
+	}
+	w.WriteString("import (\n")
+	for _, path := range std {
+		fmt.Fprintf(w, "\t%q\n", path)
+	}
+	w.WriteString("\n")
+	for _, path := range modules {
+		fmt.Fprintf(w, "\t%q\n", path)
+	}
+	w.WriteString(")\n\n")
+
+	w.WriteString("// spec is the document the client was generated from, validating the\n// requests and responses.\n")
+	if bytes.ContainsRune(spec, '`') {
+		fmt.Fprintf(w, "const spec = %s\n\n", strconv.Quote(string(spec)))
+	} else {
+		fmt.Fprintf(w, "const spec = `%s`\n\n", spec)
+	}
+
+	title, _ := lookup(g.root, "info", "title").(string)
+	name := g.cfg.ClientName
+	if title != "" {
+		fmt.Fprintf(w, "// %s is the client of the %s API.\n", name, title)
+	} else {
+		fmt.Fprintf(w, "// %s is the client of the API.\n", name)
+	}
+	fmt.Fprintf(w, "type %s struct {\n\tapi *apiclient.Client\n}\n\n", name)
+	fmt.Fprintf(w, "// New%s creates the client, sending requests through client, whose\n// base URL must point to the API server.\n", name)
+	fmt.Fprintf(w, "func New%s(client interfaces.Client, opts ...apiclient.Option) (*%s, error) {\n", name, name)
+	w.WriteString("\tapi, err := apiclient.New(client, []byte(spec), opts...)\n\tif err != nil {\n\t\treturn nil, err\n\t}\n")
+	fmt.Fprintf(w, "\treturn &%s{api: api}, nil\n}\n\n", name)
+}
+
+// operation describes a generated method.
+type operation struct {
+	name        string
+	method      string
+	template    string
+	summary     string
+	pathParams  []*param
+	params      []*param
+	paramsType  string
+	bodyType    string
+	resultType  string
+	resultValue bool
+	errors      []errorModel
+}
+
+// param is an operation parameter.
+type param struct {
+	name     string
+	in       string
+	field    string
+	arg      string
+	typ      string
+	required bool
+}
+
+// errorModel is the type of a documented error response.
+type errorModel struct {
+	key string
+	typ string
+}
+
+func (g *generator) operation(method, template string, item, raw map[string]any) (*operation, error) {
+	op := &operation{method: strings.ToUpper(method), template: template}
+	if id, _ := raw["operationId"].(string); id != "" {
+		op.name = goName(id)
+	} else {
+		op.name = goName(method + " " + template)
+	}
+	op.summary, _ = raw["summary"].(string)
+
+	params, err := g.parameters(op.name, item["parameters"], raw["parameters"])
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range params {
+		switch p.in {
+		case "path":
+			op.pathParams = append(op.pathParams, p)
+		case "query", "header":
+			op.params = append(op.params, p)
+		}
+	}
+	// Path arguments follow the template order
+	sort.SliceStable(op.pathParams, func(i, j int) bool {
+		return strings.Index(template, "{"+op.pathParams[i].name+"}") < strings.Index(template, "{"+op.pathParams[j].name+"}")
+	})
+	if len(op.params) > 0 {
+		op.paramsType = g.declareParams(op)
+	}
+
+	if rawBody, ok := raw["requestBody"]; ok {
+		body, err := g.resolve(rawBody)
+		if err != nil {
+			return nil, err
+		}
+		if schema, ok := jsonSchema(body); ok {
+			op.bodyType = pointerTo(g.typeOf(schema, op.name+"Request"))
+		}
+	}
+
+	responses, _ := raw["responses"].(map[string]any)
+	keys := sortedKeys(responses)
+	for _, key := range keys {
+		response, err := g.resolve(responses[key])
+		if err != nil {
+			return nil, err
+		}
+		schema, ok := jsonSchema(response)
+		switch {
+		case strings.HasPrefix(key, "2"):
+			if op.resultType == "" && ok {
+				op.resultType = g.typeOf(schema, op.name+"Response")
+				op.resultValue = !pointerable(op.resultType)
+			}
+		case ok && (key == "default" || key[0] == '4' || key[0] == '5'):
+			hint := op.name + "Error"
+			if key != "default" {
+				hint = op.name + strings.ToUpper(key) + "Error"
+			}
+			op.errors = append(op.errors, errorModel{key: key, typ: g.typeOf(schema, hint)})
+		}
+	}
+	return op, nil
+}
+
+// parameters resolves the parameters of a path item and an operation, whose
+// own parameters override those with the same name and location.
+func (g *generator) parameters(opName string, itemParams, opParams any) ([]*param, error) {
+	var params []*param
+	index := make(map[string]int)
+	for _, list := range []any{itemParams, opParams} {
+		items, _ := list.([]any)
+		for _, item := range items {
+			node, err := g.resolve(item)
+			if err != nil {
+				return nil, err
+			}
+			p := &param{}
+			p.name, _ = node["name"].(string)
+			p.in, _ = node["in"].(string)
+			p.required, _ = node["required"].(bool)
+			p.required = p.required || p.in == "path"
+			p.field = goName(p.name)
+			p.arg = argName(p.name)
+			schema, _ := node["schema"].(map[string]any)
+			p.typ = g.typeOf(schema, opName+p.field)
+
+			key := p.in + ":" + p.name
+			if i, ok := index[key]; ok {
+				params[i] = p
+				continue
+			}
+			index[key] = len(params)
+			params = append(params, p)
+		}
+	}
+	return params, nil
+}
+
+// declareParams declares the struct of the query and header parameters.
+func (g *generator) declareParams(op *operation) string {
+	name := g.uniqueName(op.name + "Params")
+	var b strings.Builder
+	fmt.Fprintf(&b, "// %s are the query and header parameters of %s.\n", name, op.name)
+	fmt.Fprintf(&b, "type %s struct {\n", name)
+	for _, p := range op.params {
+		typ := p.typ
+		if !p.required && pointerable(typ) {
+			typ = "*" + typ
+		}
+		fmt.Fprintf(&b, "\t// %s is the %s parameter %s.\n", p.field, p.in, p.name)
+		fmt.Fprintf(&b, "\t%s %s\n", p.field, typ)
+	}
+	b.WriteString("}\n\n")
+	g.decls = append(g.decls, decl{name: name, source: b.String()})
+	return name
+}
+
+// writeOperation writes the method of an operation.
+func (g *generator) writeOperation(w *bytes.Buffer, op *operation) {
+	g.imports["context"] = true
+
+	args := []string{"ctx context.Context"}
+	for _, p := range op.pathParams {
+		args = append(args, p.arg+" "+p.typ)
+	}
+	if op.paramsType != "" {
+		args = append(args, "params *"+op.paramsType)
+	}
+	if op.bodyType != "" {
+		args = append(args, "body "+op.bodyType)
+	}
+
+	result := "error"
+	switch {
+	case op.resultType == "":
+	case op.resultValue:
+		result = "(" + op.resultType + ", error)"
+	default:
+		result = "(*" + op.resultType + ", error)"
+	}
+
+	fmt.Fprintf(w, "// %s sends %s %s.\n", op.name, op.method, op.template)
+	if op.summary != "" {
+		fmt.Fprintf(w, "//\n// %s\n", strings.TrimSpace(op.summary))
+	}
+	fmt.Fprintf(w, "func (c *%s) %s(%s) %s {\n", g.cfg.ClientName, op.name, strings.Join(args, ", "), result)
+	fmt.Fprintf(w, "\top := &apiclient.Operation{\n\t\tMethod: %q,\n\t\tPath: %s,\n", op.method, pathExpr(op))
+	if len(op.errors) > 0 {
+		w.WriteString("\t\tErrors: map[string]func() any{\n")
+		for _, e := range op.errors {
+			fmt.Fprintf(w, "\t\t\t%q: func() any { return new(%s) },\n", e.key, e.typ)
+		}
+		w.WriteString("\t\t},\n")
+	}
+	w.WriteString("\t}\n")
+
+	if op.paramsType != "" {
+		w.WriteString("\tif params != nil {\n")
+		for _, p := range op.params {
+			add := "op.AddQuery"
+			if p.in == "header" {
+				add = "op.SetHeader"
+			}
+			switch {
+			case strings.HasPrefix(p.typ, "[]") && p.in == "query":
+				fmt.Fprintf(w, "\t\tfor _, value := range params.%s {\n\t\t\t%s(%q, value)\n\t\t}\n", p.field, add, p.name)
+			case !p.required && pointerable(p.typ):
+				fmt.Fprintf(w, "\t\tif params.%s != nil {\n\t\t\t%s(%q, *params.%s)\n\t\t}\n", p.field, add, p.name, p.field)
+			default:
+				fmt.Fprintf(w, "\t\t%s(%q, params.%s)\n", add, p.name, p.field)
+			}
+		}
+		w.WriteString("\t}\n")
+	}
+	if op.bodyType != "" {
+		w.WriteString("\tif body != nil {\n\t\top.Body = body\n\t}\n")
+	}
+
+	if op.resultType == "" {
+		w.WriteString("\treturn c.api.Do(ctx, op, nil)\n}\n\n")
+		return
+	}
+	fmt.Fprintf(w, "\tvar out %s\n", op.resultType)
+	w.WriteString("\tif err := c.api.Do(ctx, op, &out); err != nil {\n\t\treturn nil, err\n\t}\n")
+	if op.resultValue {
+		w.WriteString("\treturn out, nil\n}\n\n")
+	} else {
+		w.WriteString("\treturn &out, nil\n}\n\n")
+	}
+}
+
+// pathExpr returns the expression of the operation path.
+func pathExpr(op *operation) string {
+	args := make(map[string]string, len(op.pathParams))
+	for _, p := range op.pathParams {
+		args[p.name] = p.arg
+	}
+
+	var parts []string
+	literal := ""
+	rest := op.template
+	for rest != "" {
+		start := strings.Index(rest, "{")
+		end := strings.Index(rest, "}")
+		if start < 0 || end < start {
+			literal += rest
+			break
+		}
+		literal += rest[:start]
+		name := rest[start+1 : end]
+		rest = rest[end+1:]
+		arg, ok := args[name]
+		if !ok {
+			literal += "{" + name + "}"
+			continue
+		}
+		if literal != "" {
+			parts = append(parts, strconv.Quote(literal))
+			literal = ""
+		}
+		parts = append(parts, "apiclient.PathValue("+arg+")")
+	}
+	if literal != "" || len(parts) == 0 {
+		parts = append(parts, strconv.Quote(literal))
+	}
+	return strings.Join(parts, " + ")
+}
+
+// declare declares the type of a schema.
+func (g *generator) declare(name, schemaName string, schema map[string]any) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "// %s is the %s schema.\n", name, schemaName)
+	if description, _ := schema["description"].(string); description != "" {
+		b.WriteString("//\n")
+		for _, line := range strings.Split(strings.TrimSpace(description), "\n") {
+			fmt.Fprintf(&b, "// %s\n", strings.TrimSpace(line))
+		}
+	}
+
+	enum, _ := schema["enum"].([]any)
+	switch {
+	case schemaType(schema) == "string" && len(enum) > 0:
+		fmt.Fprintf(&b, "type %s string\n\n", name)
+		fmt.Fprintf(&b, "// %s values.\nconst (\n", name)
+		for _, value := range enum {
+			text := fmt.Sprint(value)
+			fmt.Fprintf(&b, "\t%s %s = %q\n", name+goName(text), name, text)
+		}
+		b.WriteString(")\n\n")
+	case isObject(schema) || schema["allOf"] != nil && g.objectMembers(schema):
+		b.WriteString(g.structType(name, schema))
+	default:
+		fmt.Fprintf(&b, "type %s %s\n\n", name, g.typeOf(schema, name+"Item"))
+	}
+	g.decls = append(g.decls, decl{name: name, source: b.String()})
+}
+
+// objectMembers reports whether the members of allOf are objects, merged
+// into a struct.
+func (g *generator) objectMembers(schema map[string]any) bool {
+	members, _ := schema["allOf"].([]any)
+	for _, member := range members {
+		node, err := g.resolve(member)
+		if err != nil || !isObject(node) {
+			return false
+		}
+	}
+	return len(members) > 1
+}
+
+// structType returns the declaration of an object schema, with the
+// properties of its allOf members.
+func (g *generator) structType(name string, schema map[string]any) string {
+	properties := make(map[string]any)
+	required := make(map[string]bool)
+	var collect func(node map[string]any)
+	collect = func(node map[string]any) {
+		for prop, value := range mapOf(node["properties"]) {
+			properties[prop] = value
+		}
+		for _, prop := range listOf(node["required"]) {
+			required[fmt.Sprint(prop)] = true
+		}
+		for _, member := range listOf(node["allOf"]) {
+			if resolved, err := g.resolve(member); err == nil {
+				collect(resolved)
+			}
+		}
+	}
+	collect(schema)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "type %s struct {\n", name)
+	for _, prop := range sortedKeys(properties) {
+		propSchema, _ := properties[prop].(map[string]any)
+		field := goName(prop)
+		typ := g.typeOf(propSchema, name+field)
+		tag := prop
+		if !required[prop] {
+			tag += ",omitempty"
+		}
+		if (!required[prop] || nullable(propSchema)) && pointerable(typ) {
+			typ = "*" + typ
+		}
+		if description, _ := propSchema["description"].(string); description != "" {
+			fmt.Fprintf(&b, "\t// %s\n", strings.Join(strings.Fields(description), " "))
+		}
+		fmt.Fprintf(&b, "\t%s %s `json:%q`\n", field, typ, tag)
+	}
+	b.WriteString("}\n\n")
+	return b.String()
+}
+
+// typeOf returns the Go type of a schema, declaring inline objects with the
+// name hint.
+func (g *generator) typeOf(schema map[string]any, hint string) string {
+	if schema == nil {
+		return "any"
+	}
+	if ref, ok := schema["$ref"].(string); ok {
+		if name, ok := g.refs[ref]; ok {
+			return name
+		}
+		return "any"
+	}
+	if members, ok := schema["allOf"].([]any); ok && len(members) == 1 {
+		member, _ := members[0].(map[string]any)
+		return g.typeOf(member, hint)
+	}
+	if schema["oneOf"] != nil || schema["anyOf"] != nil {
+		g.imports["encoding/json"] = true
+		return "json.RawMessage"
+	}
+
+	switch schemaType(schema) {
+	case "string":
+		switch schema["format"] {
+		case "date-time":
+			g.imports["time"] = true
+			return "time.Time"
+		case "byte":
+			return "[]byte"
+		}
+		return "string"
+	case "integer":
+		if schema["format"] == "int32" {
+			return "int32"
+		}
+		return "int64"
+	case "number":
+		if schema["format"] == "float" {
+			return "float32"
+		}
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		items, _ := schema["items"].(map[string]any)
+		return "[]" + g.typeOf(items, hint+"Item")
+	}
+
+	if isObject(schema) || schema["allOf"] != nil && g.objectMembers(schema) {
+		if len(mapOf(schema["properties"])) == 0 && schema["allOf"] == nil {
+			if values, ok := schema["additionalProperties"].(map[string]any); ok {
+				return "map[string]" + g.typeOf(values, hint+"Value")
+			}
+			return "map[string]any"
+		}
+		name := g.uniqueName(hint)
+		g.decls = append(g.decls, decl{name: name, source: fmt.Sprintf("// %s is an inline schema.\n", name) + g.structType(name, schema)})
+		return name
+	}
+	return "any"
+}
+
+// uniqueName reserves a type name, numbering it when taken.
+func (g *generator) uniqueName(name string) string {
+	unique := name
+	for i := 2; g.names[unique]; i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+	g.names[unique] = true
+	return unique
+}
+
+// resolve follows local references.
+func (g *generator) resolve(node any) (map[string]any, error) {
+	for depth := 0; depth < 32; depth++ {
+		obj, ok := node.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: expected an object", openapi.ErrInvalidDocument)
+		}
+		ref, ok := obj["$ref"].(string)
+		if !ok {
+			return obj, nil
+		}
+		if !strings.HasPrefix(ref, "#/") {
+			return nil, fmt.Errorf("%w: only local references are supported, got %q", openapi.ErrInvalidDocument, ref)
+		}
+		var tokens []string
+		for _, token := range strings.Split(ref[2:], "/") {
+			tokens = append(tokens, strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~"))
+		}
+		if node = lookup(g.root, tokens...); node == nil {
+			return nil, fmt.Errorf("%w: unresolved reference %q", openapi.ErrInvalidDocument, ref)
+		}
+	}
+	return nil, fmt.Errorf("%w: reference cycle", openapi.ErrInvalidDocument)
+}
+
+// jsonSchema returns the schema of the JSON content of a request body or
+// response.
+func jsonSchema(node map[string]any) (map[string]any, bool) {
+	content := mapOf(node["content"])
+	for _, mediaType := range sortedKeys(content) {
+		if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "/json") {
+			schema, ok := mapOf(content[mediaType])["schema"].(map[string]any)
+			return schema, ok
+		}
+	}
+	return nil, false
+}
+
+// schemaType returns the type of a schema, ignoring "null" in type lists.
+func schemaType(schema map[string]any) string {
+	switch typ := schema["type"].(type) {
+	case string:
+		return typ
+	case []any:
+		for _, t := range typ {
+			if t != "null" {
+				return fmt.Sprint(t)
+			}
+		}
+	}
+	return ""
+}
+
+// nullable reports whether a schema accepts null.
+func nullable(schema map[string]any) bool {
+	if value, _ := schema["nullable"].(bool); value {
+		return true
+	}
+	for _, t := range listOf(schema["type"]) {
+		if t == "null" {
+			return true
+		}
+	}
+	return false
+}
+
+func isObject(schema map[string]any) bool {
+	return schemaType(schema) == "object" || schema["properties"] != nil
+}
+
+// pointerable reports whether optional values of a type are pointers,
+// which is not needed by slices, maps and interfaces.
+func pointerable(typ string) bool {
+	return !strings.HasPrefix(typ, "[]") && !strings.HasPrefix(typ, "map[") &&
+		typ != "any" && typ != "json.RawMessage"
+}
+
+func pointerTo(typ string) string {
+	if pointerable(typ) {
+		return "*" + typ
+	}
+	return typ
+}
+
+// initialisms are written in upper case in Go names.
+var initialisms = map[string]bool{
+	"API": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true,
+	"JSON": true, "SQL": true, "URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// words splits a name on separators and case changes.
+func words(name string) []string {
+	var parts []string
+	var current []rune
+	runes := []rune(name)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(current) > 0 {
+				parts = append(parts, string(current))
+				current = nil
+			}
+			continue
+		}
+		if unicode.IsUpper(r) && len(current) > 0 &&
+			(unicode.IsLower(current[len(current)-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+			parts = append(parts, string(current))
+			current = nil
+		}
+		current = append(current, r)
+	}
+	if len(current) > 0 {
+		parts = append(parts, string(current))
+	}
+	return parts
+}
+
+// goName returns the exported Go name of an identifier.
+func goName(name string) string {
+	var b strings.Builder
+	for _, word := range words(name) {
+		if upper := strings.ToUpper(word); initialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		runes := []rune(strings.ToLower(word))
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	result := b.String()
+	if result == "" || unicode.IsDigit([]rune(result)[0]) {
+		result = "N" + result
+	}
+	return result
+}
+
+// reserved are the names not usable as arguments.
+var reserved = map[string]bool{
+	"break": true, "case": true, "chan": true, "const": true, "continue": true, "default": true,
+	"defer": true, "else": true, "fallthrough": true, "for": true, "func": true, "go": true,
+	"goto": true, "if": true, "import": true, "interface": true, "map": true, "package": true,
+	"range": true, "return": true, "select": true, "struct": true, "switch": true, "type": true,
+	"var": true, "ctx": true, "params": true, "body": true, "op": true, "out": true, "err": true, "c": true,
+}
+
+// argName returns the unexported Go name of a parameter.
+func argName(name string) string {
+	parts := words(name)
+	if len(parts) == 0 {
+		return "arg"
+	}
+	arg := strings.ToLower(parts[0]) + strings.TrimPrefix(goName(name), goName(parts[0]))
+	if reserved[arg] || unicode.IsDigit([]rune(arg)[0]) {
+		arg += "Param"
+	}
+	return arg
+}
+
+// normalize converts the YAML mappings to map[string]any, as YAML keys
+// such as response codes may decode to integers.
+func normalize(node any) any {
+	switch v := node.(type) {
+	case map[string]any:
+		for key, value := range v {
+			v[key] = normalize(value)
+		}
+		return v
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for key, value := range v {
+			m[fmt.Sprint(key)] = normalize(value)
+		}
+		return m
+	case []any:
+		for i, value := range v {
+			v[i] = normalize(value)
+		}
+		return v
+	}
+	return node
+}
+
+func lookup(node any, tokens ...string) any {
+	for _, token := range tokens {
+		switch v := node.(type) {
+		case map[string]any:
+			node = v[token]
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil
+			}
+			node = v[i]
+		default:
+			return nil
+		}
+	}
+	return node
+}
+
+func mapOf(node any) map[string]any {
+	m, _ := node.(map[string]any)
+	return m
+}
+
+func listOf(node any) []any {
+	l, _ := node.([]any)
+	return l
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
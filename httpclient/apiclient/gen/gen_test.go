@@ -0,0 +1,109 @@
+package gen
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/fsvxavier/nexs-lib/validation/validator/openapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerate_Example checks that the generated example is up to date; run
+// go generate in examples/orders after changing the generator.
+func TestGenerate_Example(t *testing.T) {
+	spec, err := os.ReadFile("../examples/orders/orders.yaml")
+	require.NoError(t, err)
+	want, err := os.ReadFile("../examples/orders/client_gen.go")
+	require.NoError(t, err)
+
+	got, err := Generate(spec, Config{Package: "orders", Source: "orders.yaml"})
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+}
+
+func TestGenerate_InlineSchemas(t *testing.T) {
+	spec := `{
+  "openapi": "3.0.3",
+  "info": {"title": "Inline", "version": "1"},
+  "paths": {
+    "/users/{user-id}/tags": {
+      "put": {
+        "parameters": [
+          {"name": "user-id", "in": "path", "required": true, "schema": {"type": "string", "format": "uuid"}},
+          {"name": "type", "in": "query", "required": true, "schema": {"type": "string"}}
+        ],
+        "requestBody": {"content": {"application/json": {"schema": {
+          "type": "object", "properties": {"tags": {"type": "array", "items": {"type": "string"}}}
+        }}}},
+        "responses": {
+          "200": {"description": "ok", "content": {"application/json": {"schema": {
+            "type": "object", "additionalProperties": {"type": "integer"}
+          }}}},
+          "409": {"description": "conflict", "content": {"application/json": {"schema": {
+            "type": "object", "properties": {"reason": {"type": "string", "nullable": true}}
+          }}}}
+        }
+      }
+    }
+  }
+}`
+	source, err := Generate([]byte(spec), Config{Package: "users", ClientName: "API"})
+	require.NoError(t, err)
+	code := string(source)
+
+	assert.Contains(t, code, "// Code generated by apiclient-gen. DO NOT EDIT.")
+	assert.Contains(t, code, "type API struct")
+	assert.Contains(t, code, "func NewAPI(client interfaces.Client")
+	assert.Contains(t, code, "func (c *API) PutUsersUserIDTags(ctx context.Context, userID string, params *PutUsersUserIDTagsParams, body *PutUsersUserIDTagsRequest) (map[string]int64, error)")
+	assert.Contains(t, code, `Path:   "/users/" + apiclient.PathValue(userID) + "/tags"`)
+	assert.Contains(t, code, `op.AddQuery("type", params.Type)`)
+	assert.Contains(t, code, "Tags []string `json:\"tags,omitempty\"`")
+	assert.Contains(t, code, `"409": func() any { return new(PutUsersUserIDTags409Error) }`)
+	assert.Contains(t, code, "Reason *string `json:\"reason,omitempty\"`")
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	_, err := Generate([]byte(`openapi: 3.1.0`), Config{})
+	assert.True(t, errors.Is(err, ErrInvalidConfig))
+
+	_, err = Generate([]byte(`swagger: "2.0"`), Config{Package: "api"})
+	assert.True(t, errors.Is(err, openapi.ErrInvalidDocument))
+
+	_, err = Generate([]byte(`
+openapi: 3.1.0
+info: {title: Remote, version: "1"}
+paths:
+  /a:
+    get:
+      responses:
+        "200":
+          $ref: "other.yaml#/components/responses/A"
+`), Config{Package: "api"})
+	require.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "local references"))
+}
+
+func TestGoName(t *testing.T) {
+	tests := map[string]string{
+		"listOrders":   "ListOrders",
+		"created_at":   "CreatedAt",
+		"X-Tenant-ID":  "XTenantID",
+		"userId":       "UserID",
+		"HTTPServer":   "HTTPServer",
+		"api_url":      "APIURL",
+		"2fa":          "N2fa",
+		"get /orders":  "GetOrders",
+		"order-status": "OrderStatus",
+	}
+	for input, want := range tests {
+		assert.Equal(t, want, goName(input), input)
+	}
+
+	assert.Equal(t, "orderID", argName("orderId"))
+	assert.Equal(t, "xTenantID", argName("X-Tenant-ID"))
+	assert.Equal(t, "typeParam", argName("type"))
+	assert.Equal(t, "id", argName("id"))
+}
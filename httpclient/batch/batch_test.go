@@ -47,6 +47,10 @@ func (c *mockClient) Execute(ctx context.Context, method, endpoint string, body
 	return nil, nil
 }
 
+func (c *mockClient) Do(ctx context.Context, req *interfaces.Request) (*interfaces.Response, error) {
+	return nil, nil
+}
+
 func (c *mockClient) SetHeaders(headers map[string]string) interfaces.Client {
 	return c
 }
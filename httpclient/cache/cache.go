@@ -0,0 +1,397 @@
+// Package cache provides an HTTP response cache for clients, as an
+// http.RoundTripper following RFC 9111: responses are reused while fresh
+// according to Cache-Control, Expires and Age, and stale responses are
+// revalidated with ETag and Last-Modified conditional requests.
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+)
+
+// HeaderCacheStatus is set on the responses returned by the Transport.
+const HeaderCacheStatus = "X-Cache"
+
+// Cache status values.
+const (
+	// StatusHit is a fresh stored response.
+	StatusHit = "HIT"
+	// StatusRevalidated is a stored response validated with a 304.
+	StatusRevalidated = "REVALIDATED"
+	// StatusMiss is a response from the origin.
+	StatusMiss = "MISS"
+	// StatusBypass is a response to a request the cache does not handle,
+	// such as unsafe methods and bypassed hosts.
+	StatusBypass = "BYPASS"
+)
+
+const (
+	// DefaultKeyPrefix prefixes the storage keys.
+	DefaultKeyPrefix = "httpcache:"
+	// DefaultMaxBodySize is the largest body stored.
+	DefaultMaxBodySize = 1 << 20
+	// DefaultRetention is how long stale responses are kept for revalidation.
+	DefaultRetention = 24 * time.Hour
+)
+
+// Stats are the counters of a Transport.
+type Stats struct {
+	Hits          int64
+	Revalidations int64
+	Misses        int64
+	Bypasses      int64
+}
+
+// Transport is a caching http.RoundTripper. GET requests are served from the
+// storage while fresh; other safe methods pass through, and successful
+// unsafe requests invalidate the stored response of their URL.
+type Transport struct {
+	next        http.RoundTripper
+	storage     Storage
+	clock       clock.Clock
+	prefix      string
+	maxBodySize int64
+	retention   time.Duration
+	shared      bool
+	bypass      []func(*http.Request) bool
+
+	hits, revalidations, misses, bypasses atomic.Int64
+}
+
+// Option configures a Transport.
+type Option func(*Transport)
+
+// WithStorage sets the storage. The default is a MemoryStorage with
+// DefaultMaxEntries entries; use the Valkey client or the tiered cache to
+// share the cache between replicas.
+func WithStorage(storage Storage) Option {
+	return func(t *Transport) {
+		t.storage = storage
+	}
+}
+
+// WithClock sets the clock measuring the age of responses.
+func WithClock(c clock.Clock) Option {
+	return func(t *Transport) {
+		t.clock = c
+	}
+}
+
+// WithKeyPrefix sets the prefix of the storage keys.
+func WithKeyPrefix(prefix string) Option {
+	return func(t *Transport) {
+		t.prefix = prefix
+	}
+}
+
+// WithMaxBodySize sets the largest body stored. Larger responses are
+// returned without being stored.
+func WithMaxBodySize(size int64) Option {
+	return func(t *Transport) {
+		t.maxBodySize = size
+	}
+}
+
+// WithRetention sets how long responses are kept after becoming stale, to
+// be revalidated or served to requests with max-stale.
+func WithRetention(retention time.Duration) Option {
+	return func(t *Transport) {
+		t.retention = retention
+	}
+}
+
+// WithShared makes the cache behave as a shared cache, as when its storage
+// is shared by replicas serving different users: responses marked private
+// are not stored, nor responses to requests with Authorization unless the
+// response allows it, and s-maxage takes precedence over max-age.
+func WithShared() Option {
+	return func(t *Transport) {
+		t.shared = true
+	}
+}
+
+// WithBypassHosts bypasses the cache for the hosts. A pattern such as
+// "*.internal" matches the subdomains of internal.
+func WithBypassHosts(patterns ...string) Option {
+	return WithBypass(func(req *http.Request) bool {
+		host := strings.ToLower(req.URL.Hostname())
+		for _, pattern := range patterns {
+			pattern = strings.ToLower(pattern)
+			if host == pattern || (strings.HasPrefix(pattern, "*.") && strings.HasSuffix(host, pattern[1:])) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// WithBypass adds a rule that sends the matching requests directly to the
+// origin, neither reading nor storing responses.
+func WithBypass(rule func(*http.Request) bool) Option {
+	return func(t *Transport) {
+		t.bypass = append(t.bypass, rule)
+	}
+}
+
+// NewTransport creates a caching transport sending requests to next, or to
+// http.DefaultTransport when nil.
+func NewTransport(next http.RoundTripper, opts ...Option) *Transport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	t := &Transport{
+		next:        next,
+		prefix:      DefaultKeyPrefix,
+		maxBodySize: DefaultMaxBodySize,
+		retention:   DefaultRetention,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	t.clock = clock.OrReal(t.clock)
+	if t.storage == nil {
+		t.storage = NewMemoryStorage(DefaultMaxEntries, t.clock)
+	}
+	return t
+}
+
+// Wrap returns a function creating the caching transport over another, for
+// interfaces.Config.WrapTransport.
+func Wrap(opts ...Option) func(http.RoundTripper) http.RoundTripper {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return NewTransport(next, opts...)
+	}
+}
+
+// Stats returns the counters of the transport.
+func (t *Transport) Stats() Stats {
+	return Stats{
+		Hits:          t.hits.Load(),
+		Revalidations: t.revalidations.Load(),
+		Misses:        t.misses.Load(),
+		Bypasses:      t.bypasses.Load(),
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqCC := parseDirectives(req.Header)
+	if !t.handles(req) {
+		t.bypasses.Add(1)
+		resp, err := t.next.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		if !safeMethod(req.Method) && resp.StatusCode < 400 {
+			t.invalidate(req, resp)
+		}
+		resp.Header.Set(HeaderCacheStatus, StatusBypass)
+		return resp, nil
+	}
+
+	ctx := req.Context()
+	key := t.key(req.URL)
+	stored := t.load(req, key)
+	if stored != nil && stored.fresh(reqCC, t.shared, t.clock.Now()) {
+		t.hits.Add(1)
+		return stored.response(req, StatusHit, t.clock.Now()), nil
+	}
+	if reqCC.has("only-if-cached") {
+		t.misses.Add(1)
+		return gatewayTimeout(req), nil
+	}
+
+	outgoing := req
+	if stored != nil && stored.validators() && !conditional(req) {
+		outgoing = req.Clone(ctx)
+		if etag := stored.Header.Get("ETag"); etag != "" {
+			outgoing.Header.Set("If-None-Match", etag)
+		}
+		if modified := stored.Header.Get("Last-Modified"); modified != "" {
+			outgoing.Header.Set("If-Modified-Since", modified)
+		}
+	}
+
+	requestTime := t.clock.Now()
+	resp, err := t.next.RoundTrip(outgoing)
+	if err != nil {
+		return nil, err
+	}
+	responseTime := t.clock.Now()
+
+	if outgoing != req && resp.StatusCode == http.StatusNotModified {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		stored.update(resp.Header, requestTime, responseTime)
+		t.save(req, key, stored)
+		t.revalidations.Add(1)
+		return stored.response(req, StatusRevalidated, t.clock.Now()), nil
+	}
+
+	t.misses.Add(1)
+	resp.Header.Set(HeaderCacheStatus, StatusMiss)
+	if reqCC.has("no-store") {
+		return resp, nil
+	}
+	return t.store(req, key, resp, requestTime, responseTime)
+}
+
+// handles reports whether the request may be served from the cache.
+func (t *Transport) handles(req *http.Request) bool {
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return false
+	}
+	for _, bypass := range t.bypass {
+		if bypass(req) {
+			return false
+		}
+	}
+	return true
+}
+
+// store saves a response from the origin when it is storable, returning it
+// with the body buffered.
+func (t *Transport) store(req *http.Request, key string, resp *http.Response, requestTime, responseTime time.Time) (*http.Response, error) {
+	e := &entry{
+		Status:       resp.StatusCode,
+		Header:       resp.Header.Clone(),
+		RequestTime:  requestTime,
+		ResponseTime: responseTime,
+	}
+	e.Header.Del(HeaderCacheStatus)
+	if !t.storable(req, e) {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, t.maxBodySize+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if int64(len(body)) > t.maxBodySize {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp, nil
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	e.Body = body
+	e.Vary = make(map[string]string)
+	for _, name := range varyHeaders(e.Header) {
+		e.Vary[name] = strings.Join(req.Header.Values(name), ", ")
+	}
+	t.save(req, key, e)
+	return resp, nil
+}
+
+// storable reports whether a response may be stored (RFC 9111, 3).
+func (t *Transport) storable(req *http.Request, e *entry) bool {
+	cc := parseDirectives(e.Header)
+	switch {
+	case e.Status < 200 || e.Status == http.StatusPartialContent || e.Status == http.StatusNotModified:
+		return false
+	case cc.has("no-store"):
+		return false
+	case t.shared && cc.has("private"):
+		return false
+	case t.shared && req.Header.Get("Authorization") != "" &&
+		!cc.has("public") && !cc.has("s-maxage") && !cc.has("must-revalidate"):
+		return false
+	}
+	for _, name := range varyHeaders(e.Header) {
+		if name == "*" {
+			return false
+		}
+	}
+	if !e.explicit() && !heuristicStatus[e.Status] && !cc.has("public") {
+		return false
+	}
+	return e.lifetime(t.shared) > 0 || e.validators() || cc.has("no-cache")
+}
+
+// load returns the stored response selected by the request, or nil.
+func (t *Transport) load(req *http.Request, key string) *entry {
+	value, err := t.storage.Get(req.Context(), key)
+	if err != nil {
+		return nil
+	}
+	var e entry
+	if err := json.Unmarshal([]byte(value), &e); err != nil || !e.matches(req) {
+		return nil
+	}
+	return &e
+}
+
+// save writes the entry, kept while fresh plus the retention.
+func (t *Transport) save(req *http.Request, key string, e *entry) {
+	ttl := e.lifetime(t.shared) - e.age(t.clock.Now()) + t.retention
+	if ttl <= 0 {
+		return
+	}
+	value, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	_ = t.storage.Set(req.Context(), key, string(value), ttl)
+}
+
+// invalidate removes the responses stored for the URL of an unsafe request
+// and for its Location and Content-Location on the same host (RFC 9111, 4.4).
+func (t *Transport) invalidate(req *http.Request, resp *http.Response) {
+	keys := []string{t.key(req.URL)}
+	for _, name := range []string{"Location", "Content-Location"} {
+		if location := resp.Header.Get(name); location != "" {
+			if u, err := req.URL.Parse(location); err == nil && u.Host == req.URL.Host {
+				keys = append(keys, t.key(u))
+			}
+		}
+	}
+	_, _ = t.storage.Del(req.Context(), keys...)
+}
+
+// key returns the storage key of a URL.
+func (t *Transport) key(u *url.URL) string {
+	target := *u
+	target.Fragment = ""
+	return t.prefix + target.String()
+}
+
+// safeMethod reports whether the method is safe (RFC 9110, 9.2.1).
+func safeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// conditional reports whether the request has its own preconditions.
+func conditional(req *http.Request) bool {
+	return req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != ""
+}
+
+// gatewayTimeout is the response to only-if-cached requests without a
+// stored response (RFC 9111, 5.2.1.7).
+func gatewayTimeout(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:     "504 Gateway Timeout",
+		StatusCode: http.StatusGatewayTimeout,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{HeaderCacheStatus: []string{StatusMiss}},
+		Body:       http.NoBody,
+		Request:    req,
+	}
+}
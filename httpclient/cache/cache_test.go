@@ -0,0 +1,294 @@
+package cache
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+	"github.com/fsvxavier/nexs-lib/httpclient/interfaces"
+	"github.com/fsvxavier/nexs-lib/httpclient/providers/nethttp"
+)
+
+// origin is a fake server recording the requests it receives.
+type origin struct {
+	clock    *clock.Fake
+	requests []*http.Request
+	handler  func(req *http.Request) (int, http.Header, string)
+}
+
+func (o *origin) RoundTrip(req *http.Request) (*http.Response, error) {
+	o.requests = append(o.requests, req)
+	status, header, body := o.handler(req)
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set("Date", o.clock.Now().UTC().Format(http.TimeFormat))
+	return &http.Response{
+		StatusCode: status,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func newOrigin(handler func(req *http.Request) (int, http.Header, string)) *origin {
+	return &origin{clock: clock.NewFake(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)), handler: handler}
+}
+
+func get(t *testing.T, rt http.RoundTripper, url string, header ...string) (*http.Response, string) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	return resp, string(body)
+}
+
+func TestTransport_FreshAndRevalidated(t *testing.T) {
+	o := newOrigin(func(req *http.Request) (int, http.Header, string) {
+		header := http.Header{"Cache-Control": {"max-age=60"}, "Etag": {`"v1"`}}
+		if req.Header.Get("If-None-Match") == `"v1"` {
+			return http.StatusNotModified, http.Header{"Cache-Control": {"max-age=120"}}, ""
+		}
+		return http.StatusOK, header, "catalog"
+	})
+	transport := NewTransport(o, WithClock(o.clock))
+
+	resp, body := get(t, transport, "http://api.test/catalog")
+	if resp.Header.Get(HeaderCacheStatus) != StatusMiss || body != "catalog" {
+		t.Fatalf("Expected a miss, got %s %q", resp.Header.Get(HeaderCacheStatus), body)
+	}
+
+	o.clock.Advance(30 * time.Second)
+	resp, body = get(t, transport, "http://api.test/catalog")
+	if resp.Header.Get(HeaderCacheStatus) != StatusHit || body != "catalog" || resp.Header.Get("Age") != "30" {
+		t.Errorf("Expected a hit with age 30, got %s %q age %s", resp.Header.Get(HeaderCacheStatus), body, resp.Header.Get("Age"))
+	}
+	if len(o.requests) != 1 {
+		t.Errorf("Expected 1 origin request, got %d", len(o.requests))
+	}
+
+	// Stale: revalidated with If-None-Match and refreshed by the 304
+	o.clock.Advance(time.Minute)
+	resp, body = get(t, transport, "http://api.test/catalog")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get(HeaderCacheStatus) != StatusRevalidated || body != "catalog" {
+		t.Errorf("Expected a revalidated response, got %d %s %q", resp.StatusCode, resp.Header.Get(HeaderCacheStatus), body)
+	}
+	if len(o.requests) != 2 || o.requests[1].Header.Get("If-None-Match") != `"v1"` {
+		t.Fatalf("Expected a conditional request, got %d requests", len(o.requests))
+	}
+
+	o.clock.Advance(90 * time.Second)
+	if resp, _ = get(t, transport, "http://api.test/catalog"); resp.Header.Get(HeaderCacheStatus) != StatusHit {
+		t.Errorf("Expected the max-age of the 304 applied, got %s", resp.Header.Get(HeaderCacheStatus))
+	}
+
+	stats := transport.Stats()
+	if stats.Hits != 2 || stats.Revalidations != 1 || stats.Misses != 1 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestTransport_Directives(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      http.Header
+		advance     time.Duration
+		reqHeader   []string
+		wantStatus  string
+		wantOrigins int
+	}{
+		{"no-store", http.Header{"Cache-Control": {"no-store, max-age=60"}}, 0, nil, StatusMiss, 2},
+		{"no-cache revalidates", http.Header{"Cache-Control": {"no-cache"}, "Etag": {`"a"`}}, 0, nil, StatusRevalidated, 2},
+		{"private in private cache", http.Header{"Cache-Control": {"private, max-age=60"}}, 0, nil, StatusHit, 1},
+		{"expires", http.Header{"Expires": {"Thu, 01 Jan 2026 12:01:00 GMT"}}, 30 * time.Second, nil, StatusHit, 1},
+		{"expired", http.Header{"Expires": {"Thu, 01 Jan 2026 12:01:00 GMT"}}, 2 * time.Minute, nil, StatusMiss, 2},
+		{"heuristic", http.Header{"Last-Modified": {"Wed, 31 Dec 2025 12:00:00 GMT"}}, time.Hour, nil, StatusHit, 1},
+		{"request no-cache", http.Header{"Cache-Control": {"max-age=60"}}, 0, []string{"Cache-Control", "no-cache"}, StatusMiss, 2},
+		{"request pragma", http.Header{"Cache-Control": {"max-age=60"}}, 0, []string{"Pragma", "no-cache"}, StatusMiss, 2},
+		{"request max-age", http.Header{"Cache-Control": {"max-age=60"}}, 20 * time.Second, []string{"Cache-Control", "max-age=10"}, StatusMiss, 2},
+		{"request max-stale", http.Header{"Cache-Control": {"max-age=60"}}, 90 * time.Second, []string{"Cache-Control", "max-stale=60"}, StatusHit, 1},
+		{"must-revalidate", http.Header{"Cache-Control": {"max-age=60, must-revalidate"}}, 90 * time.Second, []string{"Cache-Control", "max-stale"}, StatusMiss, 2},
+		{"vary star", http.Header{"Cache-Control": {"max-age=60"}, "Vary": {"*"}}, 0, nil, StatusMiss, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newOrigin(func(req *http.Request) (int, http.Header, string) {
+				if req.Header.Get("If-None-Match") != "" {
+					return http.StatusNotModified, nil, ""
+				}
+				return http.StatusOK, tt.header.Clone(), "body"
+			})
+			transport := NewTransport(o, WithClock(o.clock))
+
+			get(t, transport, "http://api.test/resource")
+			o.clock.Advance(tt.advance)
+			resp, body := get(t, transport, "http://api.test/resource", tt.reqHeader...)
+			if resp.Header.Get(HeaderCacheStatus) != tt.wantStatus || body != "body" {
+				t.Errorf("Expected %s, got %s %q", tt.wantStatus, resp.Header.Get(HeaderCacheStatus), body)
+			}
+			if len(o.requests) != tt.wantOrigins {
+				t.Errorf("Expected %d origin requests, got %d", tt.wantOrigins, len(o.requests))
+			}
+		})
+	}
+}
+
+func TestTransport_Vary(t *testing.T) {
+	o := newOrigin(func(req *http.Request) (int, http.Header, string) {
+		header := http.Header{"Cache-Control": {"max-age=60"}, "Vary": {"Accept-Language"}}
+		return http.StatusOK, header, "hello " + req.Header.Get("Accept-Language")
+	})
+	transport := NewTransport(o, WithClock(o.clock))
+
+	get(t, transport, "http://api.test/greeting", "Accept-Language", "pt")
+	if _, body := get(t, transport, "http://api.test/greeting", "Accept-Language", "pt"); body != "hello pt" || len(o.requests) != 1 {
+		t.Errorf("Expected a hit for the same language, got %q", body)
+	}
+	if _, body := get(t, transport, "http://api.test/greeting", "Accept-Language", "en"); body != "hello en" || len(o.requests) != 2 {
+		t.Errorf("Expected a miss for another language, got %q", body)
+	}
+}
+
+func TestTransport_InvalidationAndBypass(t *testing.T) {
+	o := newOrigin(func(req *http.Request) (int, http.Header, string) {
+		if req.Method == http.MethodPost {
+			return http.StatusCreated, http.Header{"Location": {"/orders/2"}}, ""
+		}
+		return http.StatusOK, http.Header{"Cache-Control": {"max-age=60"}}, req.URL.Path
+	})
+	transport := NewTransport(o, WithClock(o.clock), WithBypassHosts("*.internal", "metrics.test"))
+	ctx := context.Background()
+
+	get(t, transport, "http://api.test/orders")
+	get(t, transport, "http://api.test/orders/2")
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "http://api.test/orders", strings.NewReader("{}"))
+	resp, err := transport.RoundTrip(req)
+	if err != nil || resp.Header.Get(HeaderCacheStatus) != StatusBypass {
+		t.Fatalf("Expected a bypass, got %v", err)
+	}
+
+	// The POST invalidates its URL and its Location
+	get(t, transport, "http://api.test/orders")
+	get(t, transport, "http://api.test/orders/2")
+	if len(o.requests) != 5 {
+		t.Errorf("Expected the stored responses invalidated, got %d origin requests", len(o.requests))
+	}
+
+	for _, url := range []string{"http://billing.internal/a", "http://metrics.test/a", "http://metrics.test/a"} {
+		if resp, _ := get(t, transport, url); resp.Header.Get(HeaderCacheStatus) != StatusBypass {
+			t.Errorf("Expected %s bypassed, got %s", url, resp.Header.Get(HeaderCacheStatus))
+		}
+	}
+	if len(o.requests) != 8 || transport.Stats().Bypasses != 4 {
+		t.Errorf("Expected bypassed requests sent to the origin, got %d", len(o.requests))
+	}
+}
+
+func TestTransport_Shared(t *testing.T) {
+	o := newOrigin(func(req *http.Request) (int, http.Header, string) {
+		switch req.URL.Path {
+		case "/private":
+			return http.StatusOK, http.Header{"Cache-Control": {"private, max-age=60"}}, ""
+		case "/public":
+			return http.StatusOK, http.Header{"Cache-Control": {"public, max-age=0, s-maxage=60"}}, ""
+		}
+		return http.StatusOK, http.Header{"Cache-Control": {"max-age=60"}}, ""
+	})
+	transport := NewTransport(o, WithClock(o.clock), WithShared())
+
+	for _, path := range []string{"/private", "/private", "/auth", "/auth", "/public", "/public"} {
+		get(t, transport, "http://api.test"+path, "Authorization", "Bearer token")
+	}
+	if len(o.requests) != 5 {
+		t.Errorf("Expected only the public response reused, got %d origin requests", len(o.requests))
+	}
+}
+
+func TestTransport_OnlyIfCachedAndLargeBodies(t *testing.T) {
+	large := strings.Repeat("x", 64)
+	o := newOrigin(func(req *http.Request) (int, http.Header, string) {
+		return http.StatusOK, http.Header{"Cache-Control": {"max-age=60"}}, large
+	})
+	transport := NewTransport(o, WithClock(o.clock), WithMaxBodySize(32))
+
+	resp, _ := get(t, transport, "http://api.test/a", "Cache-Control", "only-if-cached")
+	if resp.StatusCode != http.StatusGatewayTimeout || len(o.requests) != 0 {
+		t.Errorf("Expected 504 without origin requests, got %d", resp.StatusCode)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, body := get(t, transport, "http://api.test/a"); body != large {
+			t.Errorf("Expected the whole body, got %d bytes", len(body))
+		}
+	}
+	if len(o.requests) != 2 {
+		t.Errorf("Expected the large body not stored, got %d origin requests", len(o.requests))
+	}
+}
+
+func TestMemoryStorage(t *testing.T) {
+	fake := clock.NewFake(time.Time{})
+	storage := NewMemoryStorage(2, fake)
+	ctx := context.Background()
+
+	_ = storage.Set(ctx, "a", "1", time.Minute)
+	_ = storage.Set(ctx, "b", []byte("2"), 0)
+	_, _ = storage.Get(ctx, "a")
+	_ = storage.Set(ctx, "c", "3", 0)
+
+	if _, err := storage.Get(ctx, "b"); err != ErrNotFound {
+		t.Errorf("Expected the least recently used evicted, got %v", err)
+	}
+	fake.Advance(time.Minute)
+	if _, err := storage.Get(ctx, "a"); err != ErrNotFound {
+		t.Errorf("Expected the entry expired, got %v", err)
+	}
+	if value, err := storage.Get(ctx, "c"); err != nil || value != "3" {
+		t.Errorf("Unexpected value %q, %v", value, err)
+	}
+	if n, _ := storage.Del(ctx, "c", "d"); n != 1 || storage.Len() != 0 {
+		t.Errorf("Expected 1 key removed, got %d", n)
+	}
+}
+
+func TestTransport_WithNetHTTPProvider(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Cache-Control", "max-age=60")
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	provider, err := nethttp.NewProvider(&interfaces.Config{
+		BaseURL:       server.URL,
+		Timeout:       5 * time.Second,
+		WrapTransport: Wrap(),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		resp, err := provider.DoRequest(context.Background(), &interfaces.Request{Method: "GET", URL: server.URL + "/status"})
+		if err != nil || string(resp.Body) != `{"ok":true}` {
+			t.Fatalf("Unexpected response %v, %v", resp, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Expected the responses cached, got %d server calls", calls)
+	}
+}
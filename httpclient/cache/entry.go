@@ -0,0 +1,210 @@
+package cache
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxHeuristicLifetime caps the freshness inferred from Last-Modified.
+const maxHeuristicLifetime = 24 * time.Hour
+
+// heuristicStatus are the status codes cacheable by default (RFC 9110, 15.1),
+// which may be stored without explicit freshness.
+var heuristicStatus = map[int]bool{
+	200: true, 203: true, 204: true, 300: true, 301: true, 308: true,
+	404: true, 405: true, 410: true, 414: true, 501: true,
+}
+
+// directives are the Cache-Control directives of a message, by lowercase name.
+type directives map[string]string
+
+// parseDirectives parses the Cache-Control header. Without it, "Pragma:
+// no-cache" is read as no-cache.
+func parseDirectives(header http.Header) directives {
+	d := directives{}
+	for _, line := range header.Values("Cache-Control") {
+		for _, part := range strings.Split(line, ",") {
+			name, value, _ := strings.Cut(part, "=")
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name != "" {
+				d[name] = strings.Trim(strings.TrimSpace(value), `"`)
+			}
+		}
+	}
+	if len(d) == 0 && strings.Contains(strings.ToLower(header.Get("Pragma")), "no-cache") {
+		d["no-cache"] = ""
+	}
+	return d
+}
+
+func (d directives) has(name string) bool {
+	_, ok := d[name]
+	return ok
+}
+
+// seconds returns a delta-seconds directive. Invalid values are read as
+// zero, so that the response is considered stale.
+func (d directives) seconds(name string) (time.Duration, bool) {
+	value, ok := d[name]
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, true
+	}
+	return time.Duration(n) * time.Second, true
+}
+
+// entry is a stored response.
+type entry struct {
+	Status       int         `json:"status"`
+	Header       http.Header `json:"header"`
+	Body         []byte      `json:"body"`
+	RequestTime  time.Time   `json:"request_time"`
+	ResponseTime time.Time   `json:"response_time"`
+	// Vary holds the values of the request headers named by Vary.
+	Vary map[string]string `json:"vary,omitempty"`
+}
+
+// date returns the Date header, or the response time when absent.
+func (e *entry) date() time.Time {
+	if date, err := http.ParseTime(e.Header.Get("Date")); err == nil {
+		return date
+	}
+	return e.ResponseTime
+}
+
+// lifetime returns the freshness lifetime (RFC 9111, 4.2.1): s-maxage for
+// shared caches, max-age, Expires or a heuristic of 10% of the time since
+// Last-Modified.
+func (e *entry) lifetime(shared bool) time.Duration {
+	cc := parseDirectives(e.Header)
+	if shared {
+		if sMaxAge, ok := cc.seconds("s-maxage"); ok {
+			return sMaxAge
+		}
+	}
+	if maxAge, ok := cc.seconds("max-age"); ok {
+		return maxAge
+	}
+	if expires := e.Header.Get("Expires"); expires != "" {
+		t, err := http.ParseTime(expires)
+		if err != nil {
+			return 0
+		}
+		return max(t.Sub(e.date()), 0)
+	}
+	if modified, err := http.ParseTime(e.Header.Get("Last-Modified")); err == nil && heuristicStatus[e.Status] {
+		return min(max(e.date().Sub(modified)/10, 0), maxHeuristicLifetime)
+	}
+	return 0
+}
+
+// explicit reports whether the response has explicit freshness.
+func (e *entry) explicit() bool {
+	cc := parseDirectives(e.Header)
+	return cc.has("max-age") || cc.has("s-maxage") || e.Header.Get("Expires") != ""
+}
+
+// age returns the current age (RFC 9111, 4.2.3).
+func (e *entry) age(now time.Time) time.Duration {
+	apparent := max(e.ResponseTime.Sub(e.date()), 0)
+	var ageValue time.Duration
+	if n, err := strconv.ParseInt(strings.TrimSpace(e.Header.Get("Age")), 10, 64); err == nil && n > 0 {
+		ageValue = time.Duration(n) * time.Second
+	}
+	corrected := ageValue + e.ResponseTime.Sub(e.RequestTime)
+	return max(apparent, corrected) + now.Sub(e.ResponseTime)
+}
+
+// fresh reports whether the entry can be used without revalidation, given
+// the directives of the request.
+func (e *entry) fresh(req directives, shared bool, now time.Time) bool {
+	cc := parseDirectives(e.Header)
+	if cc.has("no-cache") || req.has("no-cache") {
+		return false
+	}
+
+	lifetime := e.lifetime(shared)
+	if maxAge, ok := req.seconds("max-age"); ok {
+		lifetime = min(lifetime, maxAge)
+	}
+	age := e.age(now)
+	if minFresh, ok := req.seconds("min-fresh"); ok {
+		age += minFresh
+	}
+	if lifetime > age {
+		return true
+	}
+
+	if !req.has("max-stale") || cc.has("must-revalidate") || (shared && cc.has("proxy-revalidate")) {
+		return false
+	}
+	maxStale, _ := req.seconds("max-stale")
+	return req["max-stale"] == "" || age-lifetime <= maxStale
+}
+
+// validators reports whether the entry can be revalidated.
+func (e *entry) validators() bool {
+	return e.Header.Get("ETag") != "" || e.Header.Get("Last-Modified") != ""
+}
+
+// matches reports whether the request selects this entry, comparing the
+// request headers named by Vary.
+func (e *entry) matches(req *http.Request) bool {
+	for _, name := range varyHeaders(e.Header) {
+		if e.Vary[name] != strings.Join(req.Header.Values(name), ", ") {
+			return false
+		}
+	}
+	return true
+}
+
+// update merges the headers of a 304 response into the entry (RFC 9111, 4.3.4).
+func (e *entry) update(header http.Header, requestTime, responseTime time.Time) {
+	for name, values := range header {
+		if name == "Content-Length" {
+			continue
+		}
+		e.Header[name] = values
+	}
+	e.RequestTime, e.ResponseTime = requestTime, responseTime
+}
+
+// response builds the response returned for req, with the Age header and
+// the cache status.
+func (e *entry) response(req *http.Request, status string, now time.Time) *http.Response {
+	header := e.Header.Clone()
+	header.Set("Age", strconv.FormatInt(int64(e.age(now)/time.Second), 10))
+	header.Set(HeaderCacheStatus, status)
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status)),
+		StatusCode:    e.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// varyHeaders returns the canonical header names listed by Vary.
+func varyHeaders(header http.Header) []string {
+	var names []string
+	for _, line := range header.Values("Vary") {
+		for _, name := range strings.Split(line, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	return names
+}
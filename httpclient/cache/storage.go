@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+)
+
+// DefaultMaxEntries is the entry limit of the default memory storage.
+const DefaultMaxEntries = 1000
+
+// ErrNotFound is returned by MemoryStorage.Get for absent or expired keys.
+var ErrNotFound = errors.New("key not found")
+
+// Storage stores the cached responses. Its methods match those of the Valkey
+// client (cache/valkey) and of the tiered cache (cache/valkey/tiered), so
+// they can be used directly to share the cache between replicas. Any error
+// of Get is treated as a miss, and errors of Set and Del are ignored, as the
+// cache is best-effort.
+type Storage interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	Del(ctx context.Context, keys ...string) (int64, error)
+}
+
+// MemoryStorage is an in-memory Storage with expiration and LRU eviction.
+type MemoryStorage struct {
+	mu         sync.Mutex
+	clock      clock.Clock
+	maxEntries int
+	entries    map[string]*list.Element
+	lru        *list.List
+}
+
+type memoryEntry struct {
+	key       string
+	value     string
+	expiresAt time.Time
+}
+
+// NewMemoryStorage creates a memory storage holding up to maxEntries
+// responses; when full, the least recently used is evicted. A nil clock
+// uses the real clock.
+func NewMemoryStorage(maxEntries int, c clock.Clock) *MemoryStorage {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	return &MemoryStorage{
+		clock:      clock.OrReal(c),
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// Get returns the value of key, or ErrNotFound.
+func (s *MemoryStorage) Get(ctx context.Context, key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.entries[key]
+	if !ok {
+		return "", ErrNotFound
+	}
+	entry := elem.Value.(*memoryEntry)
+	if !entry.expiresAt.IsZero() && !s.clock.Now().Before(entry.expiresAt) {
+		s.remove(elem)
+		return "", ErrNotFound
+	}
+	s.lru.MoveToFront(elem)
+	return entry.value, nil
+}
+
+// Set stores value, a string or []byte, for key. A zero expiration keeps it
+// until evicted.
+func (s *MemoryStorage) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	var str string
+	switch v := value.(type) {
+	case string:
+		str = v
+	case []byte:
+		str = string(v)
+	default:
+		return errors.New("value must be a string or []byte")
+	}
+
+	var expiresAt time.Time
+	if expiration > 0 {
+		expiresAt = s.clock.Now().Add(expiration)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.entries[key]; ok {
+		entry := elem.Value.(*memoryEntry)
+		entry.value, entry.expiresAt = str, expiresAt
+		s.lru.MoveToFront(elem)
+		return nil
+	}
+	s.entries[key] = s.lru.PushFront(&memoryEntry{key: key, value: str, expiresAt: expiresAt})
+	for s.lru.Len() > s.maxEntries {
+		s.remove(s.lru.Back())
+	}
+	return nil
+}
+
+// Del removes the keys, returning how many existed.
+func (s *MemoryStorage) Del(ctx context.Context, keys ...string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var removed int64
+	for _, key := range keys {
+		if elem, ok := s.entries[key]; ok {
+			s.remove(elem)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// Len returns the number of stored entries, including expired ones not yet
+// removed.
+func (s *MemoryStorage) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lru.Len()
+}
+
+func (s *MemoryStorage) remove(elem *list.Element) {
+	s.lru.Remove(elem)
+	delete(s.entries, elem.Value.(*memoryEntry).key)
+}
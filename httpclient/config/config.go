@@ -2,17 +2,25 @@
 package config
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/fsvxavier/nexs-lib/httpclient/interfaces"
+	"go.opentelemetry.io/otel/metric"
 )
 
+// DefaultMaxIdleConnsPerHost is the default number of idle connections kept
+// per host, above the net/http default of 2 that limits reuse for clients
+// calling few hosts.
+const DefaultMaxIdleConnsPerHost = 10
+
 // DefaultConfig returns a default configuration for HTTP clients.
 func DefaultConfig() *interfaces.Config {
 	return &interfaces.Config{
 		BaseURL:             "",
 		Timeout:             30 * time.Second,
 		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: DefaultMaxIdleConnsPerHost,
 		IdleConnTimeout:     90 * time.Second,
 		TLSHandshakeTimeout: 10 * time.Second,
 		DisableKeepAlives:   false,
@@ -167,6 +175,45 @@ func (b *Builder) WithMetricsEnabled(enabled bool) *Builder {
 	return b
 }
 
+// WithMaxIdleConnsPerHost sets the maximum number of idle connections per host.
+func (b *Builder) WithMaxIdleConnsPerHost(max int) *Builder {
+	b.config.MaxIdleConnsPerHost = max
+	return b
+}
+
+// WithMaxConnsPerHost limits the connections per host, dialing, active and
+// idle. Zero means no limit.
+func (b *Builder) WithMaxConnsPerHost(max int) *Builder {
+	b.config.MaxConnsPerHost = max
+	return b
+}
+
+// WithMaxConcurrentPerHost limits the requests in flight per host; the
+// others wait for a slot until their context is done.
+func (b *Builder) WithMaxConcurrentPerHost(max int) *Builder {
+	b.config.MaxConcurrentPerHost = max
+	return b
+}
+
+// WithDNSCache caches DNS lookups for the TTL.
+func (b *Builder) WithDNSCache(ttl time.Duration) *Builder {
+	b.config.DNSCacheTTL = ttl
+	return b
+}
+
+// WithMeterProvider sets the meter provider of the transport metrics.
+func (b *Builder) WithMeterProvider(provider metric.MeterProvider) *Builder {
+	b.config.MeterProvider = provider
+	return b
+}
+
+// WithTransportWrapper wraps the transport of the net/http provider, such
+// as with cache.Wrap for response caching.
+func (b *Builder) WithTransportWrapper(wrap func(http.RoundTripper) http.RoundTripper) *Builder {
+	b.config.WrapTransport = wrap
+	return b
+}
+
 // Build returns the constructed configuration.
 func (b *Builder) Build() *interfaces.Config {
 	return b.config
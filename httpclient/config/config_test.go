@@ -17,6 +17,7 @@ func TestDefaultConfig(t *testing.T) {
 	assert.Equal(t, "", config.BaseURL)
 	assert.Equal(t, 30*time.Second, config.Timeout)
 	assert.Equal(t, 100, config.MaxIdleConns)
+	assert.Equal(t, DefaultMaxIdleConnsPerHost, config.MaxIdleConnsPerHost)
 	assert.Equal(t, 90*time.Second, config.IdleConnTimeout)
 	assert.Equal(t, 10*time.Second, config.TLSHandshakeTimeout)
 	assert.False(t, config.DisableKeepAlives)
@@ -160,6 +161,22 @@ func TestBuilder(t *testing.T) {
 	assert.Equal(t, 2*time.Second, config.RetryConfig.InitialInterval)
 }
 
+func TestBuilderConnectionPool(t *testing.T) {
+	t.Parallel()
+
+	config := NewBuilder().
+		WithMaxIdleConnsPerHost(20).
+		WithMaxConnsPerHost(50).
+		WithMaxConcurrentPerHost(8).
+		WithDNSCache(time.Minute).
+		Build()
+
+	assert.Equal(t, 20, config.MaxIdleConnsPerHost)
+	assert.Equal(t, 50, config.MaxConnsPerHost)
+	assert.Equal(t, 8, config.MaxConcurrentPerHost)
+	assert.Equal(t, time.Minute, config.DNSCacheTTL)
+}
+
 func TestBuilderWithNilHeaders(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,146 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	domaininterfaces "github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/fsvxavier/nexs-lib/httpclient/interfaces"
+	"github.com/fsvxavier/nexs-lib/httpclient/middleware"
+)
+
+// ErrorKind classifies why a request failed.
+type ErrorKind string
+
+const (
+	// KindTimeout means the request or the server timed out.
+	KindTimeout ErrorKind = "timeout"
+	// KindConnection means the server could not be reached.
+	KindConnection ErrorKind = "connection"
+	// KindCircuitOpen means the request was rejected by an open circuit breaker.
+	KindCircuitOpen ErrorKind = "circuit_open"
+	// KindClient means the server answered with a 4xx status.
+	KindClient ErrorKind = "client"
+	// KindServer means the server answered with a 5xx status.
+	KindServer ErrorKind = "server"
+	// KindCanceled means the caller canceled the request.
+	KindCanceled ErrorKind = "canceled"
+	// KindUnknown covers any other failure, such as an invalid request.
+	KindUnknown ErrorKind = "unknown"
+)
+
+// Classify returns the kind of failure of a request, or an empty kind when
+// the request succeeded.
+func Classify(resp *interfaces.Response, err error) ErrorKind {
+	if err != nil {
+		var netErr net.Error
+		switch {
+		case errors.Is(err, middleware.ErrCircuitOpen):
+			return KindCircuitOpen
+		case errors.Is(err, context.DeadlineExceeded),
+			errors.As(err, &netErr) && netErr.Timeout():
+			return KindTimeout
+		case errors.Is(err, context.Canceled):
+			return KindCanceled
+		case netErr != nil:
+			return KindConnection
+		}
+		return KindUnknown
+	}
+
+	switch {
+	case resp == nil:
+		return KindUnknown
+	case resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusGatewayTimeout:
+		return KindTimeout
+	case resp.StatusCode >= 500:
+		return KindServer
+	case resp.StatusCode >= 400:
+		return KindClient
+	}
+	return ""
+}
+
+// ToDomainError converts a failed request into a domain error whose type
+// follows the failure kind. It returns nil for successful requests. The
+// metadata holds the kind, method, URL and status, never the body. A
+// problem+json response, as written by services using this library, is
+// rebuilt with domainerrors.FromProblem instead, keeping the code, type,
+// message and severity of the remote error.
+func ToDomainError(req *interfaces.Request, resp *interfaces.Response, err error) error {
+	kind := Classify(resp, err)
+	if kind == "" {
+		return nil
+	}
+
+	metadata := map[string]interface{}{"kind": string(kind)}
+	if resp != nil {
+		metadata["status_code"] = resp.StatusCode
+		if req == nil {
+			req = resp.Request
+		}
+	}
+	if req != nil {
+		metadata["method"] = req.Method
+		metadata["url"] = req.URL
+	}
+
+	if err == nil && resp != nil {
+		if contentType := responseContentType(resp); isProblem(contentType) {
+			remote := domainerrors.FromProblem(resp.StatusCode, contentType, resp.Body)
+			return domainerrors.CloneWith(remote, domainerrors.MergeMetadata(metadata))
+		}
+		return domainerrors.NewWithMetadata(domainerrors.ErrorTypeForStatus(resp.StatusCode),
+			fmt.Sprintf("HTTP_%d", resp.StatusCode),
+			fmt.Sprintf("server responded with status %d", resp.StatusCode), metadata)
+	}
+
+	var domainErr domaininterfaces.DomainErrorInterface
+	switch kind {
+	case KindTimeout:
+		domainErr = domainerrors.NewWithMetadata(domaininterfaces.TimeoutError, "HTTP_TIMEOUT", "request timed out", metadata)
+	case KindConnection:
+		domainErr = domainerrors.NewWithMetadata(domaininterfaces.ExternalServiceError, "HTTP_CONNECTION_FAILED", "could not connect to the server", metadata)
+	case KindCircuitOpen:
+		domainErr = domainerrors.NewWithMetadata(domaininterfaces.CircuitBreakerError, "HTTP_CIRCUIT_OPEN", "circuit breaker is open", metadata)
+	case KindCanceled:
+		domainErr = domainerrors.NewWithMetadata(domaininterfaces.TimeoutError, "HTTP_CANCELED", "request canceled", metadata)
+	default:
+		if err == nil {
+			err = errors.New("empty response")
+		}
+		domainErr = domainerrors.NewWithMetadata(domaininterfaces.ExternalServiceError, "HTTP_REQUEST_FAILED", "request failed", metadata)
+	}
+	return domainErr.Wrap(err)
+}
+
+// DomainErrorHandler is an ErrorHandler that turns 4xx and 5xx responses into
+// domain errors. Use it with Client.SetErrorHandler.
+func DomainErrorHandler(resp *interfaces.Response) error {
+	return ToDomainError(nil, resp, nil)
+}
+
+// responseContentType returns the Content-Type of the response.
+func responseContentType(resp *interfaces.Response) string {
+	if resp.ContentType != "" {
+		return resp.ContentType
+	}
+	for key, value := range resp.Headers {
+		if strings.EqualFold(key, "Content-Type") {
+			return value
+		}
+	}
+	return ""
+}
+
+// isProblem reports whether the content type is problem+json.
+func isProblem(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == domainerrors.ProblemContentType
+}
@@ -0,0 +1,92 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	domaininterfaces "github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/fsvxavier/nexs-lib/httpclient/interfaces"
+	"github.com/fsvxavier/nexs-lib/httpclient/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	t.Parallel()
+
+	connErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	tests := []struct {
+		name     string
+		resp     *interfaces.Response
+		err      error
+		expected ErrorKind
+	}{
+		{"success", &interfaces.Response{StatusCode: 204}, nil, ""},
+		{"deadline", nil, fmt.Errorf("HTTP request failed: %w", context.DeadlineExceeded), KindTimeout},
+		{"canceled", nil, context.Canceled, KindCanceled},
+		{"connection", nil, fmt.Errorf("HTTP request failed: %w", connErr), KindConnection},
+		{"circuit open", nil, middleware.ErrCircuitOpen, KindCircuitOpen},
+		{"other error", nil, errors.New("invalid URL"), KindUnknown},
+		{"client error", &interfaces.Response{StatusCode: 409}, nil, KindClient},
+		{"server error", &interfaces.Response{StatusCode: 502}, nil, KindServer},
+		{"gateway timeout", &interfaces.Response{StatusCode: 504}, nil, KindTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Classify(tt.resp, tt.err))
+		})
+	}
+}
+
+func TestToDomainError(t *testing.T) {
+	t.Parallel()
+
+	req := &interfaces.Request{Method: "GET", URL: "/orders/1"}
+	tests := []struct {
+		status   int
+		expected domaininterfaces.ErrorType
+	}{
+		{400, domaininterfaces.BadRequestError},
+		{401, domaininterfaces.AuthenticationError},
+		{403, domaininterfaces.AuthorizationError},
+		{404, domaininterfaces.NotFoundError},
+		{418, domaininterfaces.BadRequestError},
+		{429, domaininterfaces.RateLimitError},
+		{500, domaininterfaces.ExternalServiceError},
+		{503, domaininterfaces.ServiceUnavailableError},
+	}
+
+	for _, tt := range tests {
+		err := ToDomainError(req, &interfaces.Response{StatusCode: tt.status}, nil)
+		assert.True(t, domainerrors.IsType(err, tt.expected), "status %d: got %v", tt.status, err)
+	}
+
+	assert.NoError(t, ToDomainError(req, &interfaces.Response{StatusCode: 200}, nil))
+	assert.NoError(t, DomainErrorHandler(&interfaces.Response{StatusCode: 302}))
+
+	problem := &interfaces.Response{
+		StatusCode: 422,
+		Headers:    map[string]string{"Content-Type": "application/problem+json"},
+		Body: []byte(`{"title":"Unprocessable Entity","status":422,"code":"INSUFFICIENT_FUNDS",` +
+			`"detail":"insufficient funds","error_type":"business_error","severity":"medium"}`),
+	}
+	err := ToDomainError(req, problem, nil)
+	var domainErr domaininterfaces.DomainErrorInterface
+	if assert.ErrorAs(t, err, &domainErr) {
+		assert.Equal(t, "INSUFFICIENT_FUNDS", domainErr.Code())
+		assert.Equal(t, domaininterfaces.BusinessError, domainErr.Type())
+		assert.Equal(t, "insufficient funds", domainErr.Error())
+		assert.Equal(t, "/orders/1", domainErr.Metadata()["url"])
+		assert.Equal(t, "client", domainErr.Metadata()["kind"])
+	}
+	assert.ErrorIs(t, err, domainerrors.ErrRemote)
+
+	cause := &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+	err = ToDomainError(req, nil, cause)
+	assert.True(t, domainerrors.IsType(err, domaininterfaces.ExternalServiceError))
+	assert.ErrorIs(t, err, cause)
+}
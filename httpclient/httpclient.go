@@ -6,6 +6,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"strings"
 	"sync"
 	"time"
 
@@ -215,6 +217,10 @@ func optimizeConfigForReuse(cfg *interfaces.Config) *interfaces.Config {
 		optimized.MaxIdleConns = 100 // Allow more idle connections
 	}
 
+	if optimized.MaxIdleConnsPerHost == 0 {
+		optimized.MaxIdleConnsPerHost = config.DefaultMaxIdleConnsPerHost
+	}
+
 	if optimized.IdleConnTimeout == 0 {
 		optimized.IdleConnTimeout = 90 * time.Second // Keep connections alive longer
 	}
@@ -319,14 +325,27 @@ func (c *Client) Options(ctx context.Context, endpoint string) (*interfaces.Resp
 
 // Execute performs an HTTP request with the specified method, endpoint, and body.
 func (c *Client) Execute(ctx context.Context, method, endpoint string, body interface{}) (*interfaces.Response, error) {
-	req := &interfaces.Request{
+	return c.Do(ctx, &interfaces.Request{
 		Method:  method,
 		URL:     endpoint,
 		Headers: make(map[string]string),
 		Body:    body,
-		Timeout: c.config.Timeout,
-		Context: ctx,
+	})
+}
+
+// Do performs a prepared request through hooks, middlewares and retries.
+// Requests without a timeout use the client timeout.
+func (c *Client) Do(ctx context.Context, req *interfaces.Request) (*interfaces.Response, error) {
+	if req == nil {
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+	if req.Headers == nil {
+		req.Headers = make(map[string]string)
+	}
+	if req.Timeout == 0 {
+		req.Timeout = c.config.Timeout
 	}
+	req.Context = ctx
 
 	// Execute pre-request hooks
 	c.mu.RLock()
@@ -350,7 +369,7 @@ func (c *Client) Execute(ctx context.Context, method, endpoint string, body inte
 	if len(middlewares) > 0 {
 		// Create middleware chain
 		handler := func(ctx context.Context, req *interfaces.Request) (*interfaces.Response, error) {
-			if c.retryConfig != nil && c.retryConfig.MaxRetries > 0 {
+			if c.shouldRetry(req) {
 				return c.executeWithRetry(ctx, req)
 			}
 			return c.provider.DoRequest(ctx, req)
@@ -368,7 +387,7 @@ func (c *Client) Execute(ctx context.Context, method, endpoint string, body inte
 		resp, err = handler(ctx, req)
 	} else {
 		// Execute with retry logic
-		if c.retryConfig != nil && c.retryConfig.MaxRetries > 0 {
+		if c.shouldRetry(req) {
 			resp, err = c.executeWithRetry(ctx, req)
 		} else {
 			resp, err = c.provider.DoRequest(ctx, req)
@@ -396,6 +415,29 @@ func (c *Client) Execute(ctx context.Context, method, endpoint string, body inte
 	return resp, nil
 }
 
+// IdempotencyKeyHeader is the header that makes non-idempotent requests retryable.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IsIdempotent reports whether an HTTP method is idempotent (RFC 9110 9.2.2).
+func IsIdempotent(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// shouldRetry reports whether retries are enabled for the request. Only
+// idempotent methods are retried, unless the request carries an
+// Idempotency-Key header telling the server to deduplicate it.
+func (c *Client) shouldRetry(req *interfaces.Request) bool {
+	if c.retryConfig == nil || c.retryConfig.MaxRetries <= 0 {
+		return false
+	}
+	return IsIdempotent(req.Method) || req.Headers[IdempotencyKeyHeader] != ""
+}
+
 // executeWithRetry executes a request with retry logic.
 func (c *Client) executeWithRetry(ctx context.Context, req *interfaces.Request) (*interfaces.Response, error) {
 	var lastResp *interfaces.Response
@@ -3,7 +3,10 @@ package interfaces
 
 import (
 	"context"
+	"net/http"
 	"time"
+
+	"go.opentelemetry.io/otel/metric"
 )
 
 // Client represents the main HTTP client interface with request capabilities.
@@ -17,8 +20,9 @@ type Client interface {
 	Head(ctx context.Context, endpoint string) (*Response, error)
 	Options(ctx context.Context, endpoint string) (*Response, error)
 
-	// Generic execute method
+	// Generic execute methods
 	Execute(ctx context.Context, method, endpoint string, body interface{}) (*Response, error)
+	Do(ctx context.Context, req *Request) (*Response, error)
 
 	// Configuration methods
 	SetHeaders(headers map[string]string) Client
@@ -133,6 +137,23 @@ type Config struct {
 	BatchingEnabled   bool
 	MaxBatchSize      int
 	BatchTimeout      time.Duration
+
+	// Connection pool tuning of the net/http provider: idle and total
+	// connections per host, requests in flight per host (the others wait
+	// for a slot) and the TTL of cached DNS lookups. Zero disables each.
+	MaxIdleConnsPerHost  int
+	MaxConnsPerHost      int
+	MaxConcurrentPerHost int
+	DNSCacheTTL          time.Duration
+
+	// MeterProvider receives the transport metrics of the net/http provider
+	// when MetricsEnabled (see httpclient/transport). Defaults to the global
+	// provider.
+	MeterProvider metric.MeterProvider
+
+	// WrapTransport wraps the transport of the net/http provider, for
+	// example with the response cache of httpclient/cache.
+	WrapTransport func(http.RoundTripper) http.RoundTripper
 }
 
 // ProviderMetrics holds provider performance metrics.
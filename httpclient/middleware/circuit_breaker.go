@@ -0,0 +1,235 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+	"github.com/fsvxavier/nexs-lib/httpclient/interfaces"
+)
+
+// ErrCircuitOpen is returned when the circuit breaker of a host is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitState represents the state of a circuit breaker.
+type CircuitState string
+
+const (
+	// CircuitClosed lets requests through and counts failures.
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen rejects requests until the open timeout expires.
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen lets a limited number of probe requests through.
+	CircuitHalfOpen CircuitState = "half_open"
+)
+
+// CircuitBreakerConfig configures the circuit breaker middleware.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures that opens the circuit.
+	FailureThreshold int
+	// OpenTimeout is how long the circuit stays open before probing the host again.
+	OpenTimeout time.Duration
+	// HalfOpenRequests is the number of successful probes needed to close the circuit.
+	HalfOpenRequests int
+	// IsFailure decides whether a result counts as a failure. Defaults to
+	// transport errors and 5xx responses.
+	IsFailure func(*interfaces.Response, error) bool
+	// OnStateChange is called when the circuit of a host changes state. It runs
+	// while the breaker is locked and must not call back into the middleware.
+	OnStateChange func(host string, from, to CircuitState)
+	// Clock measures the open timeout. Defaults to clock.Real().
+	Clock clock.Clock
+}
+
+// DefaultCircuitBreakerConfig returns a circuit breaker configuration that
+// opens after 5 consecutive failures for 30 seconds.
+func DefaultCircuitBreakerConfig() CircuitBreakerConfig {
+	return CircuitBreakerConfig{
+		FailureThreshold: 5,
+		OpenTimeout:      30 * time.Second,
+		HalfOpenRequests: 1,
+		IsFailure:        DefaultFailureCondition,
+		Clock:            clock.Real(),
+	}
+}
+
+// DefaultFailureCondition counts transport errors and 5xx responses as failures.
+func DefaultFailureCondition(resp *interfaces.Response, err error) bool {
+	return err != nil || resp == nil || resp.StatusCode >= 500
+}
+
+// CircuitBreakerMiddleware keeps a circuit breaker per host so that a
+// failing dependency does not slow down requests to healthy ones.
+type CircuitBreakerMiddleware struct {
+	config   CircuitBreakerConfig
+	breakers map[string]*hostBreaker
+	mu       sync.Mutex
+}
+
+// hostBreaker holds the circuit state of a single host.
+type hostBreaker struct {
+	state     CircuitState
+	failures  int
+	successes int
+	inFlight  int
+	openedAt  time.Time
+}
+
+// NewCircuitBreakerMiddleware creates a new circuit breaker middleware.
+// Zero values in the configuration are replaced by the defaults.
+func NewCircuitBreakerMiddleware(config CircuitBreakerConfig) *CircuitBreakerMiddleware {
+	defaults := DefaultCircuitBreakerConfig()
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = defaults.FailureThreshold
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = defaults.OpenTimeout
+	}
+	if config.HalfOpenRequests <= 0 {
+		config.HalfOpenRequests = defaults.HalfOpenRequests
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = defaults.IsFailure
+	}
+	if config.Clock == nil {
+		config.Clock = defaults.Clock
+	}
+
+	return &CircuitBreakerMiddleware{
+		config:   config,
+		breakers: make(map[string]*hostBreaker),
+	}
+}
+
+// Process implements the Middleware interface.
+func (m *CircuitBreakerMiddleware) Process(ctx context.Context, req *interfaces.Request, next func(context.Context, *interfaces.Request) (*interfaces.Response, error)) (*interfaces.Response, error) {
+	host := requestHost(req.URL)
+	if !m.allow(host) {
+		return nil, ErrCircuitOpen
+	}
+
+	resp, err := next(ctx, req)
+
+	// Cancellations by the caller say nothing about the host health
+	if errors.Is(err, context.Canceled) {
+		m.release(host)
+		return resp, err
+	}
+	m.record(host, !m.config.IsFailure(resp, err))
+	return resp, err
+}
+
+// State returns the circuit state of a host.
+func (m *CircuitBreakerMiddleware) State(host string) CircuitState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if b, exists := m.breakers[host]; exists {
+		return b.state
+	}
+	return CircuitClosed
+}
+
+// Reset closes the circuits of all hosts.
+func (m *CircuitBreakerMiddleware) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.breakers = make(map[string]*hostBreaker)
+}
+
+// allow reports whether a request to the host may proceed.
+func (m *CircuitBreakerMiddleware) allow(host string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	b := m.breaker(host)
+	switch b.state {
+	case CircuitOpen:
+		if m.config.Clock.Since(b.openedAt) < m.config.OpenTimeout {
+			return false
+		}
+		m.setState(host, b, CircuitHalfOpen)
+		fallthrough
+	case CircuitHalfOpen:
+		if b.inFlight >= m.config.HalfOpenRequests {
+			return false
+		}
+	}
+	b.inFlight++
+	return true
+}
+
+// release frees an in-flight slot without recording a result.
+func (m *CircuitBreakerMiddleware) release(host string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if b := m.breaker(host); b.inFlight > 0 {
+		b.inFlight--
+	}
+}
+
+// record updates the circuit of the host with the result of a request.
+func (m *CircuitBreakerMiddleware) record(host string, success bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	b := m.breaker(host)
+	if b.inFlight > 0 {
+		b.inFlight--
+	}
+
+	switch {
+	case success && b.state == CircuitHalfOpen:
+		b.successes++
+		if b.successes >= m.config.HalfOpenRequests {
+			m.setState(host, b, CircuitClosed)
+		}
+	case success:
+		b.failures = 0
+	case b.state == CircuitHalfOpen:
+		m.setState(host, b, CircuitOpen)
+	case b.state == CircuitClosed:
+		b.failures++
+		if b.failures >= m.config.FailureThreshold {
+			m.setState(host, b, CircuitOpen)
+		}
+	}
+}
+
+// breaker returns the breaker of a host, creating it when needed.
+func (m *CircuitBreakerMiddleware) breaker(host string) *hostBreaker {
+	b, exists := m.breakers[host]
+	if !exists {
+		b = &hostBreaker{state: CircuitClosed}
+		m.breakers[host] = b
+	}
+	return b
+}
+
+// setState moves a breaker to a new state and resets its counters.
+func (m *CircuitBreakerMiddleware) setState(host string, b *hostBreaker, state CircuitState) {
+	from := b.state
+	b.state = state
+	b.failures = 0
+	b.successes = 0
+	if state == CircuitOpen {
+		b.openedAt = m.config.Clock.Now()
+	}
+	if m.config.OnStateChange != nil && from != state {
+		m.config.OnStateChange(host, from, state)
+	}
+}
+
+// requestHost returns the host of a request URL. Relative URLs, resolved
+// against the client base URL, share the empty host.
+func requestHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+	"github.com/fsvxavier/nexs-lib/httpclient/interfaces"
+)
+
+func TestCircuitBreakerMiddleware_PerHost(t *testing.T) {
+	var transitions []string
+	fake := clock.NewFake(time.Time{})
+	cb := NewCircuitBreakerMiddleware(CircuitBreakerConfig{
+		Clock:            fake,
+		FailureThreshold: 2,
+		OpenTimeout:      time.Minute,
+		OnStateChange: func(host string, from, to CircuitState) {
+			transitions = append(transitions, host+":"+string(from)+"->"+string(to))
+		},
+	})
+
+	calls := 0
+	status := 500
+	next := func(ctx context.Context, req *interfaces.Request) (*interfaces.Response, error) {
+		calls++
+		return &interfaces.Response{StatusCode: status}, nil
+	}
+	ctx := context.Background()
+	failing := &interfaces.Request{Method: "GET", URL: "http://failing.test/a"}
+	healthy := &interfaces.Request{Method: "GET", URL: "http://healthy.test/a"}
+
+	for i := 0; i < 2; i++ {
+		if _, err := cb.Process(ctx, failing, next); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if cb.State("failing.test") != CircuitOpen {
+		t.Fatalf("Expected open circuit, got %s", cb.State("failing.test"))
+	}
+
+	if _, err := cb.Process(ctx, failing, next); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected open circuit to skip the request, got %d calls", calls)
+	}
+
+	// Other hosts are not affected
+	status = 200
+	if _, err := cb.Process(ctx, healthy, next); err != nil {
+		t.Errorf("Unexpected error for healthy host: %v", err)
+	}
+
+	// After the timeout a probe closes the circuit
+	fake.Advance(time.Minute)
+	if _, err := cb.Process(ctx, failing, next); err != nil {
+		t.Errorf("Unexpected error for probe: %v", err)
+	}
+	if cb.State("failing.test") != CircuitClosed {
+		t.Errorf("Expected closed circuit, got %s", cb.State("failing.test"))
+	}
+
+	expected := []string{
+		"failing.test:closed->open",
+		"failing.test:open->half_open",
+		"failing.test:half_open->closed",
+	}
+	if len(transitions) != len(expected) {
+		t.Fatalf("Expected transitions %v, got %v", expected, transitions)
+	}
+	for i := range expected {
+		if transitions[i] != expected[i] {
+			t.Errorf("Expected transition %s, got %s", expected[i], transitions[i])
+		}
+	}
+}
+
+func TestCircuitBreakerMiddleware_HalfOpenFailure(t *testing.T) {
+	fake := clock.NewFake(time.Time{})
+	cb := NewCircuitBreakerMiddleware(CircuitBreakerConfig{FailureThreshold: 1, OpenTimeout: time.Second, Clock: fake})
+
+	fail := func(ctx context.Context, req *interfaces.Request) (*interfaces.Response, error) {
+		return nil, errors.New("connection refused")
+	}
+	ctx := context.Background()
+	req := &interfaces.Request{Method: "GET", URL: "/relative"}
+
+	cb.Process(ctx, req, fail)
+	if cb.State("") != CircuitOpen {
+		t.Fatalf("Expected open circuit, got %s", cb.State(""))
+	}
+
+	fake.Advance(time.Second)
+	cb.Process(ctx, req, fail)
+	if cb.State("") != CircuitOpen {
+		t.Errorf("Expected failed probe to reopen the circuit, got %s", cb.State(""))
+	}
+	if _, err := cb.Process(ctx, req, fail); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen, got %v", err)
+	}
+
+	cb.Reset()
+	if cb.State("") != CircuitClosed {
+		t.Errorf("Expected closed circuit after reset, got %s", cb.State(""))
+	}
+}
+
+func TestCircuitBreakerMiddleware_IgnoresCancellation(t *testing.T) {
+	cb := NewCircuitBreakerMiddleware(CircuitBreakerConfig{FailureThreshold: 1})
+	canceled := func(ctx context.Context, req *interfaces.Request) (*interfaces.Response, error) {
+		return nil, context.Canceled
+	}
+
+	cb.Process(context.Background(), &interfaces.Request{URL: "http://api.test"}, canceled)
+	if cb.State("api.test") != CircuitClosed {
+		t.Errorf("Expected cancellation not to open the circuit, got %s", cb.State("api.test"))
+	}
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/fsvxavier/nexs-lib/httpclient/interfaces"
+	"github.com/fsvxavier/nexs-lib/resilience/hedge"
+)
+
+// HedgeConfig configures the hedge middleware.
+type HedgeConfig struct {
+	// Hedger runs the hedged requests and learns their latency. Defaults to
+	// hedge.New with its defaults; share one per dependency.
+	Hedger *hedge.Hedger[*interfaces.Response]
+	// Replicas are the base URLs, such as "https://replica-2.internal", the
+	// hedged attempts are sent to in turn. Empty sends them to the original
+	// URL, for services behind a load balancer.
+	Replicas []string
+	// Methods are hedged; other requests pass through. Defaults to GET, HEAD
+	// and OPTIONS, as a hedge sends the request twice.
+	Methods []string
+}
+
+// HedgeMiddleware sends a duplicate of slow requests to another replica and
+// returns the first response, cancelling the other (see resilience/hedge).
+type HedgeMiddleware struct {
+	config  HedgeConfig
+	methods map[string]bool
+}
+
+// NewHedgeMiddleware creates a new hedge middleware. Zero values in the
+// configuration are replaced by the defaults.
+func NewHedgeMiddleware(config HedgeConfig) *HedgeMiddleware {
+	if config.Hedger == nil {
+		config.Hedger = hedge.New[*interfaces.Response]()
+	}
+	if len(config.Methods) == 0 {
+		config.Methods = []string{"GET", "HEAD", "OPTIONS"}
+	}
+
+	methods := make(map[string]bool, len(config.Methods))
+	for _, method := range config.Methods {
+		methods[strings.ToUpper(method)] = true
+	}
+	return &HedgeMiddleware{config: config, methods: methods}
+}
+
+// Process implements the Middleware interface.
+func (m *HedgeMiddleware) Process(ctx context.Context, req *interfaces.Request, next func(context.Context, *interfaces.Request) (*interfaces.Response, error)) (*interfaces.Response, error) {
+	if !m.methods[strings.ToUpper(req.Method)] {
+		return next(ctx, req)
+	}
+
+	return m.config.Hedger.Do(ctx, func(ctx context.Context, attempt int) (*interfaces.Response, error) {
+		return next(ctx, m.attemptRequest(req, attempt))
+	})
+}
+
+// Stats returns the counters of the hedger.
+func (m *HedgeMiddleware) Stats() hedge.Stats {
+	return m.config.Hedger.Stats()
+}
+
+// attemptRequest copies the request for an attempt, so that attempts running
+// concurrently do not share headers, and points hedged attempts to a replica.
+func (m *HedgeMiddleware) attemptRequest(req *interfaces.Request, attempt int) *interfaces.Request {
+	r := *req
+	r.Headers = make(map[string]string, len(req.Headers))
+	for key, value := range req.Headers {
+		r.Headers[key] = value
+	}
+
+	if attempt > 0 && len(m.config.Replicas) > 0 {
+		r.URL = replicaURL(req.URL, m.config.Replicas[(attempt-1)%len(m.config.Replicas)])
+	}
+	return &r
+}
+
+// replicaURL replaces the scheme and host of rawURL with those of replica.
+func replicaURL(rawURL, replica string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	base, err := url.Parse(replica)
+	if err != nil || base.Host == "" {
+		return rawURL
+	}
+	u.Scheme, u.Host = base.Scheme, base.Host
+	return u.String()
+}
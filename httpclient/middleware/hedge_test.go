@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/httpclient/interfaces"
+	"github.com/fsvxavier/nexs-lib/resilience/hedge"
+)
+
+func TestHedgeMiddleware_SendsSlowRequestsToReplica(t *testing.T) {
+	hm := NewHedgeMiddleware(HedgeConfig{
+		Hedger:   hedge.New[*interfaces.Response](hedge.WithDelay(10 * time.Millisecond)),
+		Replicas: []string{"https://replica-2.test"},
+	})
+
+	var mu sync.Mutex
+	var urls []string
+	next := func(ctx context.Context, req *interfaces.Request) (*interfaces.Response, error) {
+		mu.Lock()
+		urls = append(urls, req.URL)
+		mu.Unlock()
+		req.Headers["X-Attempt"] = req.URL
+
+		if req.URL == "https://primary.test/users/1?expand=true" {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return &interfaces.Response{StatusCode: 200, Body: []byte(req.URL)}, nil
+	}
+
+	req := &interfaces.Request{
+		Method:  "GET",
+		URL:     "https://primary.test/users/1?expand=true",
+		Headers: map[string]string{"Accept": "application/json"},
+	}
+	resp, err := hm.Process(context.Background(), req, next)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(resp.Body) != "https://replica-2.test/users/1?expand=true" {
+		t.Errorf("Expected the replica response, got %s", resp.Body)
+	}
+	if len(req.Headers) != 1 {
+		t.Errorf("Expected the original headers untouched, got %v", req.Headers)
+	}
+	if stats := hm.Stats(); stats.Hedges != 1 || stats.HedgeWins != 1 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestHedgeMiddleware_SkipsNonIdempotentMethods(t *testing.T) {
+	hm := NewHedgeMiddleware(HedgeConfig{
+		Hedger: hedge.New[*interfaces.Response](hedge.WithDelay(time.Millisecond)),
+	})
+
+	calls := 0
+	next := func(ctx context.Context, req *interfaces.Request) (*interfaces.Response, error) {
+		calls++
+		time.Sleep(10 * time.Millisecond)
+		return &interfaces.Response{StatusCode: 201}, nil
+	}
+
+	if _, err := hm.Process(context.Background(), &interfaces.Request{Method: "POST", URL: "https://primary.test/orders"}, next); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if calls != 1 || hm.Stats().Calls != 0 {
+		t.Errorf("Expected POST to pass through once, got %d calls", calls)
+	}
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fsvxavier/nexs-lib/httpclient/interfaces"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies the spans created by the tracing middleware.
+const tracerName = "github.com/fsvxavier/nexs-lib/httpclient"
+
+// TracingMiddleware creates a client span per request and propagates the
+// trace context to the server through the request headers.
+type TracingMiddleware struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// TracingOption configures the tracing middleware.
+type TracingOption func(*TracingMiddleware)
+
+// WithTracerProvider sets the tracer provider. Defaults to the global provider.
+func WithTracerProvider(provider trace.TracerProvider) TracingOption {
+	return func(m *TracingMiddleware) {
+		m.tracer = provider.Tracer(tracerName)
+	}
+}
+
+// WithPropagator sets the propagator. Defaults to the global propagator.
+func WithPropagator(propagator propagation.TextMapPropagator) TracingOption {
+	return func(m *TracingMiddleware) {
+		m.propagator = propagator
+	}
+}
+
+// NewTracingMiddleware creates a new tracing middleware.
+func NewTracingMiddleware(opts ...TracingOption) *TracingMiddleware {
+	m := &TracingMiddleware{
+		tracer:     otel.Tracer(tracerName),
+		propagator: otel.GetTextMapPropagator(),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Process implements the Middleware interface.
+func (m *TracingMiddleware) Process(ctx context.Context, req *interfaces.Request, next func(context.Context, *interfaces.Request) (*interfaces.Response, error)) (*interfaces.Response, error) {
+	ctx, span := m.tracer.Start(ctx, "HTTP "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("url.full", req.URL),
+		),
+	)
+	defer span.End()
+
+	if req.Headers == nil {
+		req.Headers = make(map[string]string)
+	}
+	m.propagator.Inject(ctx, propagation.MapCarrier(req.Headers))
+
+	resp, err := next(ctx, req)
+
+	if resp != nil {
+		span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+		if resp.StatusCode >= 500 {
+			span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", resp.StatusCode))
+		}
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return resp, err
+}
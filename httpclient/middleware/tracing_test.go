@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fsvxavier/nexs-lib/httpclient/interfaces"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracingMiddleware(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	m := NewTracingMiddleware(WithTracerProvider(provider), WithPropagator(propagation.TraceContext{}))
+
+	var traceparent string
+	next := func(ctx context.Context, req *interfaces.Request) (*interfaces.Response, error) {
+		traceparent = req.Headers["traceparent"]
+		if !trace.SpanContextFromContext(ctx).IsValid() {
+			t.Error("Expected span in context")
+		}
+		return &interfaces.Response{StatusCode: 503}, nil
+	}
+
+	req := &interfaces.Request{Method: "GET", URL: "http://api.test/users"}
+	if _, err := m.Process(context.Background(), req, next); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "HTTP GET" || span.SpanKind() != trace.SpanKindClient {
+		t.Errorf("Unexpected span %s (%s)", span.Name(), span.SpanKind())
+	}
+	if span.Status().Code != codes.Error {
+		t.Errorf("Expected error status for 503, got %v", span.Status().Code)
+	}
+	if traceparent == "" || traceparent[3:35] != span.SpanContext().TraceID().String() {
+		t.Errorf("Expected traceparent with trace ID %s, got %q", span.SpanContext().TraceID(), traceparent)
+	}
+
+	var found bool
+	for _, attr := range span.Attributes() {
+		if attr.Key == "http.response.status_code" && attr.Value.AsInt64() == 503 {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected status code attribute, got %v", span.Attributes())
+	}
+}
+
+func TestTracingMiddleware_Error(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	m := NewTracingMiddleware(WithTracerProvider(provider))
+
+	next := func(ctx context.Context, req *interfaces.Request) (*interfaces.Response, error) {
+		return nil, errors.New("connection refused")
+	}
+	if _, err := m.Process(context.Background(), &interfaces.Request{Method: "POST", URL: "/x"}, next); err == nil {
+		t.Fatal("Expected error")
+	}
+
+	span := recorder.Ended()[0]
+	if span.Status().Code != codes.Error || len(span.Events()) == 0 {
+		t.Errorf("Expected recorded error, got status %v and %d events", span.Status().Code, len(span.Events()))
+	}
+}
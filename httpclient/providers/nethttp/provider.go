@@ -15,7 +15,10 @@ import (
 	"time"
 
 	"github.com/fsvxavier/nexs-lib/httpclient/interfaces"
+	"github.com/fsvxavier/nexs-lib/httpclient/transport"
+	"github.com/fsvxavier/nexs-lib/pools"
 	jsoniter "github.com/json-iterator/go"
+	"go.opentelemetry.io/otel"
 )
 
 var (
@@ -72,8 +75,19 @@ func (p *Provider) Configure(config *interfaces.Config) error {
 
 	p.config = config
 
-	transport := &http.Transport{
+	dialer := &net.Dialer{
+		Timeout:   config.Timeout,
+		KeepAlive: 30 * time.Second,
+	}
+	dialContext := dialer.DialContext
+	if config.DNSCacheTTL > 0 {
+		dialContext = transport.NewDNSCache(config.DNSCacheTTL, dialer).DialContext
+	}
+
+	httpTransport := &http.Transport{
 		MaxIdleConns:        config.MaxIdleConns,
+		MaxIdleConnsPerHost: config.MaxIdleConnsPerHost,
+		MaxConnsPerHost:     config.MaxConnsPerHost,
 		IdleConnTimeout:     config.IdleConnTimeout,
 		TLSHandshakeTimeout: config.TLSHandshakeTimeout,
 		DisableKeepAlives:   config.DisableKeepAlives,
@@ -81,14 +95,31 @@ func (p *Provider) Configure(config *interfaces.Config) error {
 		TLSClientConfig: &tls.Config{
 			InsecureSkipVerify: config.InsecureSkipVerify,
 		},
-		DialContext: (&net.Dialer{
-			Timeout:   config.Timeout,
-			KeepAlive: 30 * time.Second,
-		}).DialContext,
+		DialContext: dialContext,
+	}
+
+	var roundTripper http.RoundTripper = httpTransport
+	if config.MaxConcurrentPerHost > 0 || config.MetricsEnabled {
+		opts := []transport.Option{transport.WithMaxConcurrentPerHost(config.MaxConcurrentPerHost)}
+		if config.MetricsEnabled {
+			meterProvider := config.MeterProvider
+			if meterProvider == nil {
+				meterProvider = otel.GetMeterProvider()
+			}
+			opts = append(opts, transport.WithMeterProvider(meterProvider))
+		}
+		limited, err := transport.New(httpTransport, opts...)
+		if err != nil {
+			return fmt.Errorf("failed to create transport: %w", err)
+		}
+		roundTripper = limited
+	}
+	if config.WrapTransport != nil {
+		roundTripper = config.WrapTransport(roundTripper)
 	}
 
 	p.client = &http.Client{
-		Transport: transport,
+		Transport: roundTripper,
 		Timeout:   config.Timeout,
 	}
 
@@ -181,7 +212,7 @@ func (p *Provider) DoRequest(ctx context.Context, req *interfaces.Request) (*int
 	defer httpResp.Body.Close()
 
 	// Read response body
-	respBody, err := io.ReadAll(httpResp.Body)
+	respBody, err := pools.ReadAll(httpResp.Body, httpResp.ContentLength)
 	if err != nil {
 		p.updateMetricsEnd(start, false)
 		return nil, fmt.Errorf("failed to read response body: %w", err)
@@ -10,8 +10,11 @@ import (
 
 	"github.com/fsvxavier/nexs-lib/httpclient/config"
 	"github.com/fsvxavier/nexs-lib/httpclient/interfaces"
+	"github.com/fsvxavier/nexs-lib/httpclient/transport"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 )
 
 func TestNewProvider(t *testing.T) {
@@ -276,6 +279,49 @@ func TestProvider_Metrics(t *testing.T) {
 	assert.False(t, metrics.LastRequestTime.IsZero())
 }
 
+func TestProvider_TransportMetrics(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	reader := sdkmetric.NewManualReader()
+	cfg := config.NewBuilder().
+		WithBaseURL(strings.Replace(server.URL, "127.0.0.1", "localhost", 1)).
+		WithMaxConcurrentPerHost(2).
+		WithDNSCache(time.Minute).
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))).
+		Build()
+	provider, err := NewProvider(cfg)
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, err = provider.DoRequest(context.Background(), &interfaces.Request{Method: "GET", URL: "/"})
+		require.NoError(t, err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	counts := map[string]uint64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if hist, ok := m.Data.(metricdata.Histogram[float64]); ok {
+				for _, dp := range hist.DataPoints {
+					counts[m.Name] += dp.Count
+				}
+			}
+		}
+	}
+
+	// The connection is reused, so it is dialed once
+	assert.Equal(t, uint64(2), counts[transport.MetricQueueWait])
+	assert.Equal(t, uint64(2), counts[transport.MetricTTFB])
+	assert.Equal(t, uint64(1), counts[transport.MetricDNSDuration])
+	assert.Equal(t, uint64(1), counts[transport.MetricConnectTime])
+}
+
 func TestProvider_MetricsDisabled(t *testing.T) {
 	t.Parallel()
 
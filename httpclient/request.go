@@ -0,0 +1,203 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	domaininterfaces "github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/fsvxavier/nexs-lib/httpclient/interfaces"
+)
+
+// RequestBuilder builds a request fluently and sends it through a client.
+// Failures are returned as domain errors classified by ErrorKind.
+//
+//	var user User
+//	resp, err := httpclient.NewRequest(client).
+//		Get("/users/{id}").
+//		PathParam("id", "42").
+//		Query("expand", "roles").
+//		Timeout(2 * time.Second).
+//		Decode(&user).
+//		Send(ctx)
+type RequestBuilder struct {
+	client  interfaces.Client
+	method  string
+	path    string
+	params  map[string]string
+	query   url.Values
+	headers map[string]string
+	body    interface{}
+	timeout time.Duration
+	target  interface{}
+	err     error
+}
+
+// NewRequest creates a request builder for the client.
+func NewRequest(client interfaces.Client) *RequestBuilder {
+	return &RequestBuilder{
+		client:  client,
+		method:  http.MethodGet,
+		params:  make(map[string]string),
+		query:   make(url.Values),
+		headers: make(map[string]string),
+	}
+}
+
+// Method sets the method and endpoint of the request.
+func (b *RequestBuilder) Method(method, endpoint string) *RequestBuilder {
+	b.method = strings.ToUpper(method)
+	b.path = endpoint
+	return b
+}
+
+// Get sets a GET request to the endpoint.
+func (b *RequestBuilder) Get(endpoint string) *RequestBuilder {
+	return b.Method(http.MethodGet, endpoint)
+}
+
+// Post sets a POST request to the endpoint.
+func (b *RequestBuilder) Post(endpoint string) *RequestBuilder {
+	return b.Method(http.MethodPost, endpoint)
+}
+
+// Put sets a PUT request to the endpoint.
+func (b *RequestBuilder) Put(endpoint string) *RequestBuilder {
+	return b.Method(http.MethodPut, endpoint)
+}
+
+// Patch sets a PATCH request to the endpoint.
+func (b *RequestBuilder) Patch(endpoint string) *RequestBuilder {
+	return b.Method(http.MethodPatch, endpoint)
+}
+
+// Delete sets a DELETE request to the endpoint.
+func (b *RequestBuilder) Delete(endpoint string) *RequestBuilder {
+	return b.Method(http.MethodDelete, endpoint)
+}
+
+// PathParam replaces {name} in the endpoint with the escaped value.
+func (b *RequestBuilder) PathParam(name, value string) *RequestBuilder {
+	b.params[name] = value
+	return b
+}
+
+// Query adds a query parameter.
+func (b *RequestBuilder) Query(key, value string) *RequestBuilder {
+	b.query.Add(key, value)
+	return b
+}
+
+// Header sets a request header.
+func (b *RequestBuilder) Header(key, value string) *RequestBuilder {
+	b.headers[key] = value
+	return b
+}
+
+// IdempotencyKey sets the Idempotency-Key header, which makes non-idempotent
+// methods such as POST eligible for retries.
+func (b *RequestBuilder) IdempotencyKey(key string) *RequestBuilder {
+	return b.Header(IdempotencyKeyHeader, key)
+}
+
+// Body sets a raw body ([]byte, string or io.Reader). Other values are
+// encoded by the provider.
+func (b *RequestBuilder) Body(body interface{}) *RequestBuilder {
+	b.body = body
+	return b
+}
+
+// JSON encodes v as the request body and sets the JSON content type.
+func (b *RequestBuilder) JSON(v interface{}) *RequestBuilder {
+	data, err := json.Marshal(v)
+	if err != nil {
+		b.err = domainerrors.Wrap(err, domaininterfaces.SerializationError, "HTTP_ENCODE_FAILED", "failed to encode request body")
+		return b
+	}
+	b.body = data
+	b.headers["Content-Type"] = "application/json"
+	return b
+}
+
+// Timeout limits the request duration, retries included.
+func (b *RequestBuilder) Timeout(timeout time.Duration) *RequestBuilder {
+	b.timeout = timeout
+	return b
+}
+
+// Decode sets the target that successful JSON responses are decoded into.
+func (b *RequestBuilder) Decode(target interface{}) *RequestBuilder {
+	b.target = target
+	b.headers["Accept"] = "application/json"
+	return b
+}
+
+// Build returns the request without sending it.
+func (b *RequestBuilder) Build() (*interfaces.Request, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	endpoint := b.path
+	for name, value := range b.params {
+		endpoint = strings.ReplaceAll(endpoint, "{"+name+"}", url.PathEscape(value))
+	}
+	if len(b.query) > 0 {
+		separator := "?"
+		if strings.Contains(endpoint, "?") {
+			separator = "&"
+		}
+		endpoint += separator + b.query.Encode()
+	}
+
+	headers := make(map[string]string, len(b.headers))
+	for key, value := range b.headers {
+		headers[key] = value
+	}
+
+	return &interfaces.Request{
+		Method:  b.method,
+		URL:     endpoint,
+		Headers: headers,
+		Body:    b.body,
+		Timeout: b.timeout,
+	}, nil
+}
+
+// Send sends the request. Transport failures and 4xx/5xx responses are
+// returned as domain errors together with the response, if any.
+func (b *RequestBuilder) Send(ctx context.Context) (*interfaces.Response, error) {
+	req, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	if b.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.timeout)
+		defer cancel()
+	}
+
+	resp, err := b.client.Do(ctx, req)
+
+	// Domain errors from error handlers, such as DomainErrorHandler, are kept
+	var domainErr domaininterfaces.DomainErrorInterface
+	if errors.As(err, &domainErr) {
+		return resp, err
+	}
+	if err := ToDomainError(req, resp, err); err != nil {
+		return resp, err
+	}
+
+	if b.target != nil && resp != nil && len(resp.Body) > 0 {
+		if err := json.Unmarshal(resp.Body, b.target); err != nil {
+			return resp, domainerrors.Wrap(err, domaininterfaces.SerializationError, "HTTP_DECODE_FAILED", "failed to decode response body")
+		}
+	}
+	return resp, nil
+}
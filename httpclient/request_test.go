@@ -0,0 +1,157 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	domaininterfaces "github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/fsvxavier/nexs-lib/httpclient/config"
+	"github.com/fsvxavier/nexs-lib/httpclient/interfaces"
+	"github.com/fsvxavier/nexs-lib/httpclient/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) (interfaces.Client, *httptest.Server) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	cfg := config.DefaultConfig()
+	cfg.BaseURL = server.URL
+	cfg.RetryConfig = &interfaces.RetryConfig{
+		MaxRetries:      2,
+		InitialInterval: time.Millisecond,
+		MaxInterval:     time.Millisecond,
+		Multiplier:      1,
+	}
+
+	client, err := NewFactory().CreateClient(interfaces.ProviderNetHTTP, cfg)
+	require.NoError(t, err)
+	return client, server
+}
+
+func TestRequestBuilder_JSON(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/users/a%2Fb", r.URL.EscapedPath())
+		assert.Equal(t, "roles", r.URL.Query().Get("expand"))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "req-1", r.Header.Get("X-Request-ID"))
+
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"name": body["name"], "id": "42"})
+	})
+
+	var user struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	resp, err := NewRequest(client).
+		Post("/users/{id}").
+		PathParam("id", "a/b").
+		Query("expand", "roles").
+		Header("X-Request-ID", "req-1").
+		JSON(map[string]string{"name": "Ana"}).
+		Decode(&user).
+		Send(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "42", user.ID)
+	assert.Equal(t, "Ana", user.Name)
+}
+
+func TestRequestBuilder_RetriesIdempotentMethods(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	ctx := context.Background()
+
+	_, err := NewRequest(client).Get("/flaky").Send(ctx)
+	require.Error(t, err)
+	assert.Equal(t, int32(3), calls.Load(), "GET is retried")
+
+	calls.Store(0)
+	_, err = NewRequest(client).Post("/flaky").JSON(map[string]int{"n": 1}).Send(ctx)
+	require.Error(t, err)
+	assert.Equal(t, int32(1), calls.Load(), "POST is not retried")
+
+	calls.Store(0)
+	_, err = NewRequest(client).Post("/flaky").IdempotencyKey("key-1").Send(ctx)
+	require.Error(t, err)
+	assert.Equal(t, int32(3), calls.Load(), "POST with idempotency key is retried")
+}
+
+func TestRequestBuilder_DomainErrors(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		}
+	})
+	ctx := context.Background()
+
+	resp, err := NewRequest(client).Get("/missing").Send(ctx)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.True(t, domainerrors.IsType(err, domaininterfaces.NotFoundError))
+
+	var domainErr domaininterfaces.DomainErrorInterface
+	require.True(t, errors.As(err, &domainErr))
+	assert.Equal(t, "HTTP_404", domainErr.Code())
+	assert.Equal(t, "client", domainErr.Metadata()["kind"])
+	assert.Equal(t, "/missing", domainErr.Metadata()["url"])
+
+	_, err = NewRequest(client).Get("/slow").Timeout(50 * time.Millisecond).Send(ctx)
+	require.Error(t, err)
+	assert.True(t, domainerrors.IsType(err, domaininterfaces.TimeoutError), "got %v", err)
+
+	_, err = NewRequest(client).Get("/").JSON(func() {}).Send(ctx)
+	assert.True(t, domainerrors.IsType(err, domaininterfaces.SerializationError))
+}
+
+func TestRequestBuilder_CircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	client.SetRetryConfig(nil)
+	client.AddMiddleware(middleware.NewCircuitBreakerMiddleware(middleware.CircuitBreakerConfig{
+		FailureThreshold: 2,
+		OpenTimeout:      time.Minute,
+	}))
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, err := NewRequest(client).Get("/down").Send(ctx)
+		assert.True(t, domainerrors.IsType(err, domaininterfaces.ExternalServiceError))
+	}
+
+	_, err := NewRequest(client).Get("/down").Send(ctx)
+	assert.True(t, domainerrors.IsType(err, domaininterfaces.CircuitBreakerError))
+	assert.ErrorIs(t, err, middleware.ErrCircuitOpen)
+	assert.Equal(t, int32(2), calls.Load())
+}
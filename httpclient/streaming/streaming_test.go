@@ -44,6 +44,9 @@ func (c *mockStreamClient) Options(ctx context.Context, endpoint string) (*inter
 func (c *mockStreamClient) Execute(ctx context.Context, method, endpoint string, body interface{}) (*interfaces.Response, error) {
 	return nil, nil
 }
+func (c *mockStreamClient) Do(ctx context.Context, req *interfaces.Request) (*interfaces.Response, error) {
+	return nil, nil
+}
 func (c *mockStreamClient) SetHeaders(headers map[string]string) interfaces.Client { return c }
 func (c *mockStreamClient) SetTimeout(timeout time.Duration) interfaces.Client     { return c }
 func (c *mockStreamClient) SetErrorHandler(handler interfaces.ErrorHandler) interfaces.Client {
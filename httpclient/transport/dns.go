@@ -0,0 +1,111 @@
+package transport
+
+import (
+	"context"
+	"net"
+	"net/http/httptrace"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+	"github.com/fsvxavier/nexs-lib/concurrency/coalesce"
+)
+
+// DNSCache resolves host names at most once per TTL, sharing the lookups of
+// concurrent dials. The resolved addresses are tried in order, and a host
+// whose addresses all fail is resolved again on the next dial.
+type DNSCache struct {
+	dialer *net.Dialer
+	lookup func(ctx context.Context, host string) ([]string, error)
+	group  *coalesce.Group[string, []string]
+}
+
+// DNSOption configures a DNSCache.
+type DNSOption func(*dnsOptions)
+
+type dnsOptions struct {
+	lookup func(ctx context.Context, host string) ([]string, error)
+	clock  clock.Clock
+}
+
+// WithLookup sets the function resolving host names. Defaults to
+// net.DefaultResolver.LookupHost.
+func WithLookup(lookup func(ctx context.Context, host string) ([]string, error)) DNSOption {
+	return func(o *dnsOptions) {
+		o.lookup = lookup
+	}
+}
+
+// WithDNSClock sets the clock of the TTL. Defaults to clock.Real().
+func WithDNSClock(c clock.Clock) DNSOption {
+	return func(o *dnsOptions) {
+		o.clock = c
+	}
+}
+
+// NewDNSCache creates a DNS cache dialing with dialer, or with a default
+// net.Dialer when nil.
+func NewDNSCache(ttl time.Duration, dialer *net.Dialer, opts ...DNSOption) *DNSCache {
+	o := dnsOptions{lookup: net.DefaultResolver.LookupHost}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if dialer == nil {
+		dialer = &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	}
+	return &DNSCache{
+		dialer: dialer,
+		lookup: o.lookup,
+		group:  coalesce.New[string, []string](coalesce.WithTTL(ttl), coalesce.WithClock(o.clock)),
+	}
+}
+
+// DialContext dials address, resolving its host through the cache. It can
+// be used as http.Transport.DialContext; the lookup is reported to the
+// httptrace.ClientTrace of the context, as the dials are to IP addresses.
+func (c *DNSCache) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil || net.ParseIP(host) != nil {
+		return c.dialer.DialContext(ctx, network, address)
+	}
+
+	trace := httptrace.ContextClientTrace(ctx)
+	if trace != nil && trace.DNSStart != nil {
+		trace.DNSStart(httptrace.DNSStartInfo{Host: host})
+	}
+	addrs, err := c.LookupHost(ctx, host)
+	if trace != nil && trace.DNSDone != nil {
+		trace.DNSDone(httptrace.DNSDoneInfo{Err: err})
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, addr := range addrs {
+		var conn net.Conn
+		if conn, err = c.dialer.DialContext(ctx, network, net.JoinHostPort(addr, port)); err == nil {
+			return conn, nil
+		}
+	}
+	c.group.Forget(host)
+	return nil, err
+}
+
+// LookupHost returns the addresses of host, from the cache while within the
+// TTL.
+func (c *DNSCache) LookupHost(ctx context.Context, host string) ([]string, error) {
+	return c.group.Do(ctx, host, func(ctx context.Context) ([]string, error) {
+		return c.lookup(ctx, host)
+	})
+}
+
+// Flush discards the cached addresses of the hosts.
+func (c *DNSCache) Flush(hosts ...string) {
+	for _, host := range hosts {
+		c.group.Forget(host)
+	}
+}
+
+// Stats returns the lookup counters: Executions are the lookups sent to the
+// resolver and Saved() those served by the cache.
+func (c *DNSCache) Stats() coalesce.Stats {
+	return c.group.Stats()
+}
@@ -0,0 +1,250 @@
+// Package transport provides the layers of the net/http provider transport:
+// per-host concurrent request limits, a DNS cache and connection metrics
+// (in-flight requests, queue wait, DNS, connect, TLS and time to first byte)
+// reported through OpenTelemetry (see observability/metrics).
+package transport
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/observability/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// meterName identifies the instruments created by the transport.
+const meterName = "github.com/fsvxavier/nexs-lib/httpclient"
+
+// Metric names.
+const (
+	MetricActiveRequests = "http.client.active_requests"
+	MetricQueuedRequests = "httpclient.queued_requests"
+	MetricQueueWait      = "httpclient.queue.wait"
+	MetricDNSDuration    = "httpclient.dns.duration"
+	MetricConnectTime    = "httpclient.connect.duration"
+	MetricTLSDuration    = "httpclient.tls.duration"
+	MetricTTFB           = "httpclient.ttfb"
+)
+
+// Transport limits the concurrent requests per host and records the
+// connection metrics of the requests sent to the next transport.
+type Transport struct {
+	next    http.RoundTripper
+	limiter *hostLimiter
+	meter   metric.Meter
+
+	active, queued                         metric.Int64UpDownCounter
+	queueWait, dns, connect, tlsTime, ttfb *metrics.LatencyHistogram
+}
+
+// Option configures a Transport.
+type Option func(*Transport)
+
+// WithMaxConcurrentPerHost limits the requests in flight per host; the
+// others wait for a slot, or for their context to be done. A request holds
+// its slot until the response body is closed. Zero means no limit.
+func WithMaxConcurrentPerHost(max int) Option {
+	return func(t *Transport) {
+		if max > 0 {
+			t.limiter = newHostLimiter(max)
+		}
+	}
+}
+
+// WithMeterProvider records the metrics with the meter provider. Without
+// it, no metrics are recorded.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(t *Transport) {
+		t.meter = provider.Meter(meterName)
+	}
+}
+
+// New creates a transport sending requests to next.
+func New(next http.RoundTripper, opts ...Option) (*Transport, error) {
+	t := &Transport{next: next}
+	for _, opt := range opts {
+		opt(t)
+	}
+	if t.meter == nil {
+		return t, nil
+	}
+
+	var err error
+	if t.active, err = t.meter.Int64UpDownCounter(MetricActiveRequests,
+		metric.WithDescription("Requests in flight"), metric.WithUnit("{request}")); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", MetricActiveRequests, err)
+	}
+	if t.queued, err = t.meter.Int64UpDownCounter(MetricQueuedRequests,
+		metric.WithDescription("Requests waiting for the per-host limit"), metric.WithUnit("{request}")); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", MetricQueuedRequests, err)
+	}
+	for _, h := range []struct {
+		target      **metrics.LatencyHistogram
+		name        string
+		description string
+	}{
+		{&t.queueWait, MetricQueueWait, "Time waiting for the per-host limit"},
+		{&t.dns, MetricDNSDuration, "DNS lookup duration"},
+		{&t.connect, MetricConnectTime, "TCP connect duration"},
+		{&t.tlsTime, MetricTLSDuration, "TLS handshake duration"},
+		{&t.ttfb, MetricTTFB, "Time from sending the request to the first response byte"},
+	} {
+		if *h.target, err = metrics.NewLatencyHistogram(t.meter, h.name, metrics.WithDescription(h.description)); err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", h.name, err)
+		}
+	}
+	return t, nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	attrs := attribute.String("server.address", req.URL.Hostname())
+
+	release := func() {}
+	if t.limiter != nil {
+		start := time.Now()
+		t.add(ctx, t.queued, 1, attrs)
+		var err error
+		release, err = t.limiter.acquire(ctx, req.URL.Host)
+		t.add(ctx, t.queued, -1, attrs)
+		if t.queueWait != nil {
+			t.queueWait.Record(ctx, time.Since(start), attrs)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if t.meter != nil {
+		t.add(ctx, t.active, 1, attrs)
+		limiterRelease := release
+		release = func() {
+			t.add(ctx, t.active, -1, attrs)
+			limiterRelease()
+		}
+		req = req.WithContext(httptrace.WithClientTrace(ctx, t.clientTrace(ctx, attrs)))
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+func (t *Transport) add(ctx context.Context, counter metric.Int64UpDownCounter, n int64, attrs attribute.KeyValue) {
+	if counter != nil {
+		counter.Add(ctx, n, metric.WithAttributes(attrs))
+	}
+}
+
+// clientTrace records the connection timings of a request. Hooks may run
+// on the goroutines dialing the connection, hence the lock.
+func (t *Transport) clientTrace(ctx context.Context, attrs attribute.KeyValue) *httptrace.ClientTrace {
+	var (
+		mu       sync.Mutex
+		dnsStart time.Time
+		tlsStart time.Time
+		dials    = make(map[string]time.Time)
+		wrote    time.Time
+	)
+	since := func(start *time.Time) (time.Duration, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		if start.IsZero() {
+			return 0, false
+		}
+		return time.Since(*start), true
+	}
+	set := func(start *time.Time) {
+		mu.Lock()
+		*start = time.Now()
+		mu.Unlock()
+	}
+
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { set(&dnsStart) },
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			if d, ok := since(&dnsStart); ok {
+				t.dns.Record(ctx, d, attrs)
+			}
+		},
+		ConnectStart: func(network, addr string) {
+			mu.Lock()
+			dials[network+addr] = time.Now()
+			mu.Unlock()
+		},
+		ConnectDone: func(network, addr string, err error) {
+			mu.Lock()
+			start, ok := dials[network+addr]
+			mu.Unlock()
+			if ok && err == nil {
+				t.connect.Record(ctx, time.Since(start), attrs)
+			}
+		},
+		TLSHandshakeStart: func() { set(&tlsStart) },
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			if d, ok := since(&tlsStart); ok && err == nil {
+				t.tlsTime.Record(ctx, d, attrs)
+			}
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) { set(&wrote) },
+		GotFirstResponseByte: func() {
+			if d, ok := since(&wrote); ok {
+				t.ttfb.Record(ctx, d, attrs)
+			}
+		},
+	}
+}
+
+// releaseBody releases the slot of a request when its body is closed.
+type releaseBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// hostLimiter is a semaphore per host.
+type hostLimiter struct {
+	max   int
+	mu    sync.Mutex
+	hosts map[string]chan struct{}
+}
+
+func newHostLimiter(max int) *hostLimiter {
+	return &hostLimiter{max: max, hosts: make(map[string]chan struct{})}
+}
+
+// acquire waits for a slot of host, returning the function releasing it.
+func (l *hostLimiter) acquire(ctx context.Context, host string) (func(), error) {
+	l.mu.Lock()
+	slots, ok := l.hosts[host]
+	if !ok {
+		slots = make(chan struct{}, l.max)
+		l.hosts[host] = slots
+	}
+	l.mu.Unlock()
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,201 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+	"github.com/fsvxavier/nexs-lib/observability/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestTransport_MaxConcurrentPerHost(t *testing.T) {
+	var inFlight, peak atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		inFlight.Add(-1)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	tr, err := New(http.DefaultTransport, WithMaxConcurrentPerHost(2))
+	require.NoError(t, err)
+	client := &http.Client{Transport: tr}
+
+	done := make(chan error, 6)
+	for i := 0; i < 6; i++ {
+		go func() {
+			resp, err := client.Get(server.URL)
+			if err == nil {
+				_, err = io.ReadAll(resp.Body)
+				resp.Body.Close()
+			}
+			done <- err
+		}()
+	}
+	for i := 0; i < 6; i++ {
+		require.NoError(t, <-done)
+	}
+	assert.Equal(t, int64(2), peak.Load())
+}
+
+func TestTransport_SlotHeldUntilBodyClosed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	tr, err := New(http.DefaultTransport, WithMaxConcurrentPerHost(1))
+	require.NoError(t, err)
+	client := &http.Client{Transport: tr}
+
+	first, err := client.Get(server.URL)
+	require.NoError(t, err)
+
+	// The slot stays taken until the body is closed
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	_, err = client.Do(req)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	first.Body.Close()
+	first.Body.Close()
+	second, err := client.Get(server.URL)
+	require.NoError(t, err)
+	second.Body.Close()
+}
+
+func TestTransport_Metrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	p := metrics.NewProvider()
+	defer p.Shutdown(context.Background())
+
+	tr, err := New(&http.Transport{}, WithMaxConcurrentPerHost(4), WithMeterProvider(p.MeterProvider()))
+	require.NoError(t, err)
+	client := &http.Client{Transport: tr}
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	_, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	rm, err := p.Collect(context.Background())
+	require.NoError(t, err)
+	found := map[string]metricdata.Aggregation{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			found[m.Name] = m.Data
+		}
+	}
+
+	for _, name := range []string{MetricQueueWait, MetricConnectTime, MetricTTFB} {
+		hist, ok := found[name].(metricdata.Histogram[float64])
+		require.True(t, ok, name)
+		require.Len(t, hist.DataPoints, 1, name)
+		assert.Equal(t, uint64(1), hist.DataPoints[0].Count, name)
+		host, _ := hist.DataPoints[0].Attributes.Value("server.address")
+		assert.Equal(t, "127.0.0.1", host.AsString(), name)
+	}
+	for _, name := range []string{MetricActiveRequests, MetricQueuedRequests} {
+		sum, ok := found[name].(metricdata.Sum[int64])
+		require.True(t, ok, name)
+		require.Len(t, sum.DataPoints, 1, name)
+		assert.Equal(t, int64(0), sum.DataPoints[0].Value, name)
+	}
+}
+
+func TestTransport_ErrorReleasesSlot(t *testing.T) {
+	failing := roundTripperFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("boom")
+	})
+	tr, err := New(failing, WithMaxConcurrentPerHost(1))
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+		_, err := tr.RoundTrip(req)
+		assert.EqualError(t, err, "boom")
+	}
+}
+
+func TestDNSCache_TTL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	var lookups atomic.Int64
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	dns := NewDNSCache(time.Minute, nil,
+		WithDNSClock(fake),
+		WithLookup(func(ctx context.Context, host string) ([]string, error) {
+			lookups.Add(1)
+			assert.Equal(t, "service.internal", host)
+			return []string{"127.0.0.1"}, nil
+		}))
+
+	dial := func() {
+		conn, err := dns.DialContext(context.Background(), "tcp", net.JoinHostPort("service.internal", port))
+		require.NoError(t, err)
+		conn.Close()
+	}
+
+	dial()
+	dial()
+	assert.Equal(t, int64(1), lookups.Load())
+
+	fake.Advance(2 * time.Minute)
+	dial()
+	assert.Equal(t, int64(2), lookups.Load())
+	assert.Equal(t, int64(1), dns.Stats().Saved())
+
+	dns.Flush("service.internal")
+	dial()
+	assert.Equal(t, int64(3), lookups.Load())
+}
+
+func TestDNSCache_FailedDialForgetsHost(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	listener.Close()
+
+	var lookups atomic.Int64
+	dns := NewDNSCache(time.Hour, &net.Dialer{Timeout: time.Second},
+		WithLookup(func(ctx context.Context, host string) ([]string, error) {
+			lookups.Add(1)
+			return []string{"127.0.0.1"}, nil
+		}))
+
+	for i := 0; i < 2; i++ {
+		_, err := dns.DialContext(context.Background(), "tcp", net.JoinHostPort("gone.internal", port))
+		assert.Error(t, err)
+	}
+	assert.Equal(t, int64(2), lookups.Load())
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -5,15 +5,13 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 
 	"github.com/fsvxavier/nexs-lib/httpserver"
 	"github.com/fsvxavier/nexs-lib/httpserver/config"
+	"github.com/fsvxavier/nexs-lib/lifecycle"
 )
 
 // LoggingObserver demonstrates how to implement a custom observer
@@ -136,29 +134,30 @@ func main() {
 		log.Fatalf("Failed to register health route: %v", err)
 	}
 
-	// Start server
-	ctx := context.Background()
-	if err := server.Start(ctx); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	// Start the server, wait for SIGINT or SIGTERM and stop it gracefully
+	manager := lifecycle.New()
+	err = manager.Register("http-server", lifecycle.Hook{
+		OnStart: func(ctx context.Context) error {
+			if err := server.Start(ctx); err != nil {
+				return err
+			}
+
+			// Log server info
+			log.Printf("🌟 Server running at: %s", server.GetAddr())
+			log.Println("📋 Available endpoints:")
+			log.Println("   GET /hello")
+			log.Println("   GET /hello/:name")
+			log.Println("   GET /health")
+			return nil
+		},
+		OnStop: server.Stop,
+	})
+	if err != nil {
+		log.Fatalf("Failed to register server: %v", err)
 	}
 
-	// Log server info
-	log.Printf("🌟 Server running at: %s", server.GetAddr())
-	log.Println("📋 Available endpoints:")
-	log.Println("   GET /hello")
-	log.Println("   GET /hello/:name")
-	log.Println("   GET /health")
-
-	// Wait for interrupt signal
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-	<-c
-
-	log.Println("🔄 Shutting down server...")
-
-	// Stop server gracefully
-	if err := server.Stop(ctx); err != nil {
-		log.Printf("Error stopping server: %v", err)
+	if err := manager.Run(context.Background()); err != nil {
+		log.Fatalf("Server lifecycle failed: %v", err)
 	}
 
 	log.Println("👋 Server stopped successfully")
@@ -4,13 +4,11 @@ import (
 	"context"
 	"log"
 	"net/http"
-	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
 	"github.com/fsvxavier/nexs-lib/httpserver/hooks"
 	"github.com/fsvxavier/nexs-lib/httpserver/middlewares"
+	"github.com/fsvxavier/nexs-lib/lifecycle"
 	"github.com/gin-gonic/gin"
 )
 
@@ -374,59 +372,52 @@ func main() {
 	ctx := context.Background()
 	startHook.OnStart(ctx, ":8080")
 
-	go func() {
-		log.Printf("🌟 Servidor completo iniciado na porta 8080")
-		log.Printf("")
-		log.Printf("📊 ENDPOINTS PÚBLICOS:")
-		log.Printf("   GET  /           - Página inicial")
-		log.Printf("   GET  /health     - Health check")
-		log.Printf("   GET  /public     - Área pública")
-		log.Printf("   GET  /docs       - Documentação")
-		log.Printf("")
-		log.Printf("🔒 ENDPOINTS PROTEGIDOS:")
-		log.Printf("   GET  /api/users     - Lista usuários")
-		log.Printf("   POST /api/users     - Criar usuário")
-		log.Printf("   GET  /api/profile   - Perfil atual")
-		log.Printf("   GET  /api/stats     - Estatísticas")
-		log.Printf("   GET  /api/slow      - Teste de latência")
-		log.Printf("   GET  /api/error     - Teste de erro")
-		log.Printf("   GET  /admin/*       - Área administrativa")
-		log.Printf("   GET  /metrics       - Métricas completas")
-		log.Printf("")
-		log.Printf("🔐 AUTENTICAÇÃO:")
-		log.Printf("   Basic Auth:")
-		log.Printf("     admin:admin123 | user:user123 | developer:dev123")
-		log.Printf("   API Keys:")
-		log.Printf("     X-API-Key: api-key-123 | X-API-Key: admin-key-456")
-		log.Printf("")
-		log.Printf("🧪 EXEMPLOS:")
-		log.Printf("   curl http://localhost:8080/")
-		log.Printf("   curl -u admin:admin123 http://localhost:8080/api/users")
-		log.Printf("   curl -H 'X-API-Key: api-key-123' http://localhost:8080/metrics")
-
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("❌ Erro ao iniciar servidor: %v", err)
-		}
-	}()
+	log.Printf("🌟 Servidor completo iniciado na porta 8080")
+	log.Printf("")
+	log.Printf("📊 ENDPOINTS PÚBLICOS:")
+	log.Printf("   GET  /           - Página inicial")
+	log.Printf("   GET  /health     - Health check")
+	log.Printf("   GET  /public     - Área pública")
+	log.Printf("   GET  /docs       - Documentação")
+	log.Printf("")
+	log.Printf("🔒 ENDPOINTS PROTEGIDOS:")
+	log.Printf("   GET  /api/users     - Lista usuários")
+	log.Printf("   POST /api/users     - Criar usuário")
+	log.Printf("   GET  /api/profile   - Perfil atual")
+	log.Printf("   GET  /api/stats     - Estatísticas")
+	log.Printf("   GET  /api/slow      - Teste de latência")
+	log.Printf("   GET  /api/error     - Teste de erro")
+	log.Printf("   GET  /admin/*       - Área administrativa")
+	log.Printf("   GET  /metrics       - Métricas completas")
+	log.Printf("")
+	log.Printf("🔐 AUTENTICAÇÃO:")
+	log.Printf("   Basic Auth:")
+	log.Printf("     admin:admin123 | user:user123 | developer:dev123")
+	log.Printf("   API Keys:")
+	log.Printf("     X-API-Key: api-key-123 | X-API-Key: admin-key-456")
+	log.Printf("")
+	log.Printf("🧪 EXEMPLOS:")
+	log.Printf("   curl http://localhost:8080/")
+	log.Printf("   curl -u admin:admin123 http://localhost:8080/api/users")
+	log.Printf("   curl -H 'X-API-Key: api-key-123' http://localhost:8080/metrics")
 
 	// ==============================
-	// GRACEFUL SHUTDOWN
+	// CICLO DE VIDA
 	// ==============================
 
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-
-	log.Printf("🛑 Iniciando shutdown graceful...")
-
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	stopHook.OnStop(shutdownCtx)
+	// O lifecycle.Manager inicia o servidor, aguarda SIGINT/SIGTERM e executa o
+	// shutdown graceful dentro do timeout
+	manager := lifecycle.New(lifecycle.WithDefaultTimeouts(lifecycle.DefaultStartTimeout, 10*time.Second))
+	if err := manager.Register("http-server", lifecycle.Service(srv.ListenAndServe, func(ctx context.Context) error {
+		stopHook.OnStop(ctx)
+		return srv.Shutdown(ctx)
+	})); err != nil {
+		log.Fatalf("❌ Erro ao registrar servidor: %v", err)
+	}
 
-	if err := srv.Shutdown(shutdownCtx); err != nil {
+	if err := manager.Run(ctx); err != nil {
 		log.Printf("❌ Erro durante shutdown: %v", err)
-		errorHook.OnError(shutdownCtx, err)
+		errorHook.OnError(ctx, err)
 	}
 
 	log.Printf("✅ Servidor finalizado com sucesso")
@@ -4,12 +4,10 @@ import (
 	"context"
 	"log"
 	"net/http"
-	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
 	"github.com/fsvxavier/nexs-lib/httpserver/hooks"
+	"github.com/fsvxavier/nexs-lib/lifecycle"
 	"github.com/gin-gonic/gin"
 )
 
@@ -130,40 +128,33 @@ func main() {
 	ctx := context.Background()
 	startHook.OnStart(ctx, ":8080")
 
-	go func() {
-		log.Printf("🌟 Servidor iniciado na porta 8080")
-		log.Printf("📊 Endpoints disponíveis:")
-		log.Printf("   GET  /           - Página inicial")
-		log.Printf("   GET  /health     - Health check")
-		log.Printf("   GET  /users      - Lista de usuários")
-		log.Printf("   GET  /error      - Simular erro")
-		log.Printf("   GET  /metrics    - Métricas dos hooks")
-		log.Printf("")
-		log.Printf("🧪 Teste com: curl http://localhost:8080/")
-
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("❌ Erro ao iniciar servidor: %v", err)
-		}
-	}()
+	log.Printf("🌟 Servidor iniciado na porta 8080")
+	log.Printf("📊 Endpoints disponíveis:")
+	log.Printf("   GET  /           - Página inicial")
+	log.Printf("   GET  /health     - Health check")
+	log.Printf("   GET  /users      - Lista de usuários")
+	log.Printf("   GET  /error      - Simular erro")
+	log.Printf("   GET  /metrics    - Métricas dos hooks")
+	log.Printf("")
+	log.Printf("🧪 Teste com: curl http://localhost:8080/")
 
 	// ==============================
-	// GRACEFUL SHUTDOWN
+	// CICLO DE VIDA
 	// ==============================
 
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-
-	log.Printf("🛑 Iniciando shutdown graceful...")
-
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	stopHook.OnStop(shutdownCtx)
+	// O lifecycle.Manager inicia o servidor, aguarda SIGINT/SIGTERM e executa o
+	// shutdown graceful dentro do timeout
+	manager := lifecycle.New(lifecycle.WithDefaultTimeouts(lifecycle.DefaultStartTimeout, 5*time.Second))
+	if err := manager.Register("http-server", lifecycle.Service(srv.ListenAndServe, func(ctx context.Context) error {
+		stopHook.OnStop(ctx)
+		return srv.Shutdown(ctx)
+	})); err != nil {
+		log.Fatalf("❌ Erro ao registrar servidor: %v", err)
+	}
 
-	if err := srv.Shutdown(shutdownCtx); err != nil {
+	if err := manager.Run(ctx); err != nil {
 		log.Printf("❌ Erro durante shutdown: %v", err)
-		errorHook.OnError(shutdownCtx, err)
+		errorHook.OnError(ctx, err)
 	}
 
 	log.Printf("✅ Servidor finalizado com sucesso")
@@ -4,12 +4,10 @@ import (
 	"context"
 	"log"
 	"net/http"
-	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
 	"github.com/fsvxavier/nexs-lib/httpserver/middlewares"
+	"github.com/fsvxavier/nexs-lib/lifecycle"
 	"github.com/gin-gonic/gin"
 )
 
@@ -194,51 +192,43 @@ func main() {
 		Handler: router,
 	}
 
-	go func() {
-		log.Printf("🌟 Servidor iniciado na porta 8080")
-		log.Printf("📊 Endpoints disponíveis:")
-		log.Printf("")
-		log.Printf("   🌍 PÚBLICOS:")
-		log.Printf("   GET  /           - Página inicial")
-		log.Printf("   GET  /health     - Health check")
-		log.Printf("   GET  /public     - Rota pública")
-		log.Printf("   GET  /info       - Informações do sistema")
-		log.Printf("")
-		log.Printf("   🔒 PROTEGIDOS (Basic Auth):")
-		log.Printf("   GET  /api/users     - Lista de usuários")
-		log.Printf("   POST /api/users     - Criar usuário")
-		log.Printf("   GET  /api/profile   - Perfil do usuário")
-		log.Printf("   GET  /api/admin     - Área administrativa")
-		log.Printf("")
-		log.Printf("🔐 Credenciais:")
-		log.Printf("   admin:secret123")
-		log.Printf("   user:password456")
-		log.Printf("")
-		log.Printf("🧪 Exemplos de uso:")
-		log.Printf("   curl http://localhost:8080/")
-		log.Printf("   curl http://localhost:8080/public")
-		log.Printf("   curl -u admin:secret123 http://localhost:8080/api/users")
-		log.Printf("   curl -u user:password456 http://localhost:8080/api/profile")
-
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("❌ Erro ao iniciar servidor: %v", err)
-		}
-	}()
+	log.Printf("🌟 Servidor iniciado na porta 8080")
+	log.Printf("📊 Endpoints disponíveis:")
+	log.Printf("")
+	log.Printf("   🌍 PÚBLICOS:")
+	log.Printf("   GET  /           - Página inicial")
+	log.Printf("   GET  /health     - Health check")
+	log.Printf("   GET  /public     - Rota pública")
+	log.Printf("   GET  /info       - Informações do sistema")
+	log.Printf("")
+	log.Printf("   🔒 PROTEGIDOS (Basic Auth):")
+	log.Printf("   GET  /api/users     - Lista de usuários")
+	log.Printf("   POST /api/users     - Criar usuário")
+	log.Printf("   GET  /api/profile   - Perfil do usuário")
+	log.Printf("   GET  /api/admin     - Área administrativa")
+	log.Printf("")
+	log.Printf("🔐 Credenciais:")
+	log.Printf("   admin:secret123")
+	log.Printf("   user:password456")
+	log.Printf("")
+	log.Printf("🧪 Exemplos de uso:")
+	log.Printf("   curl http://localhost:8080/")
+	log.Printf("   curl http://localhost:8080/public")
+	log.Printf("   curl -u admin:secret123 http://localhost:8080/api/users")
+	log.Printf("   curl -u user:password456 http://localhost:8080/api/profile")
 
 	// ==============================
-	// GRACEFUL SHUTDOWN
+	// CICLO DE VIDA
 	// ==============================
 
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-
-	log.Printf("🛑 Iniciando shutdown graceful...")
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	// O lifecycle.Manager inicia o servidor, aguarda SIGINT/SIGTERM e executa o
+	// shutdown graceful dentro do timeout
+	manager := lifecycle.New(lifecycle.WithDefaultTimeouts(lifecycle.DefaultStartTimeout, 5*time.Second))
+	if err := manager.Register("http-server", lifecycle.Service(srv.ListenAndServe, srv.Shutdown)); err != nil {
+		log.Fatalf("❌ Erro ao registrar servidor: %v", err)
+	}
 
-	if err := srv.Shutdown(ctx); err != nil {
+	if err := manager.Run(context.Background()); err != nil {
 		log.Printf("❌ Erro durante shutdown: %v", err)
 	}
 
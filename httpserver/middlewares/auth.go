@@ -284,6 +284,17 @@ func (am *AuthMiddleware) initializeProviders() {
 		provider := NewAPIKeyAuthProvider("apikey", am.config.ValidTokens, am.config.APIKeyHeader)
 		am.providers[provider.GetName()] = provider
 	}
+
+	if am.config.EnableJWTAuth && am.config.JWTSecret != "" {
+		config := DefaultJWTConfig()
+		config.Secret = []byte(am.config.JWTSecret)
+		config.Algorithms = []string{JWTAlgorithmHS256}
+		if am.config.JWTAlgorithm != "" {
+			config.Algorithms = []string{am.config.JWTAlgorithm}
+		}
+		provider := NewJWTMiddlewareWithConfig(0, config)
+		am.providers[provider.GetName()] = provider
+	}
 }
 
 // extractRequestInfo extracts relevant information from the request.
@@ -363,7 +374,7 @@ func (am *AuthMiddleware) authenticate(ctx context.Context, reqInfo *RequestInfo
 		}
 	}
 
-	if am.config.EnableBearerAuth || am.config.EnableAPIKeyAuth {
+	if am.config.EnableBearerAuth || am.config.EnableAPIKeyAuth || am.config.EnableJWTAuth {
 		if authHeader, exists := reqInfo.Headers[am.config.AuthHeader]; exists {
 			if strings.HasPrefix(authHeader, "Bearer ") {
 				token := strings.TrimPrefix(authHeader, "Bearer ")
@@ -0,0 +1,465 @@
+package middlewares
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	domaininterfaces "github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/fsvxavier/nexs-lib/validation/validator"
+)
+
+// ProblemContentType is the media type of problem details responses (RFC 9457).
+const ProblemContentType = domainerrors.ProblemContentType
+
+// Problem is a problem details response built from a domain error.
+type Problem struct {
+	Type   string                 `json:"type"`
+	Title  string                 `json:"title"`
+	Status int                    `json:"status"`
+	Detail string                 `json:"detail,omitempty"`
+	Code   string                 `json:"code,omitempty"`
+	Errors []validator.FieldError `json:"errors,omitempty"`
+
+	// ErrorType and Severity let clients rebuild the domain error with
+	// domainerrors.FromHTTPResponse.
+	ErrorType string `json:"error_type,omitempty"`
+	Severity  string `json:"severity,omitempty"`
+
+	// Runbook links the runbook of the code, when WithProblemDocs is set.
+	Runbook string `json:"runbook,omitempty"`
+
+	// Metadata, Cause and Stack are set only by response profiles exposing
+	// them, such as domainerrors.InternalProfile.
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	Cause    string                 `json:"cause,omitempty"`
+	Stack    string                 `json:"stack,omitempty"`
+}
+
+// ProblemOption configures WriteProblem.
+type ProblemOption func(*problemConfig)
+
+type problemConfig struct {
+	docs    bool
+	profile *domainerrors.ResponseProfile
+}
+
+// WithProblemDocs sets the problem type to the documentation URL of the
+// error code, and adds its runbook URL, as registered in the domainerrors
+// catalog, so that an API response links to the page explaining it. Codes
+// without documentation keep "about:blank".
+func WithProblemDocs() ProblemOption {
+	return func(c *problemConfig) {
+		c.docs = true
+	}
+}
+
+// WithProblemProfile renders errors with a domainerrors response profile,
+// e.g. domainerrors.PublicProfile() on public routes and
+// domainerrors.InternalProfile() on service-to-service routes. Without it,
+// the profile of the default domainerrors factory is used, if any.
+func WithProblemProfile(profile *domainerrors.ResponseProfile) ProblemOption {
+	return func(c *problemConfig) {
+		c.profile = profile
+	}
+}
+
+// BindConfig defines how BindAndValidate decodes requests.
+type BindConfig struct {
+	// MaxBodySize limits the JSON body.
+	MaxBodySize int64
+
+	// DisallowUnknownFields rejects JSON bodies with fields not in the type.
+	DisallowUnknownFields bool
+
+	// PathParam returns a path parameter. Defaults to http.Request.PathValue,
+	// filled by http.ServeMux patterns such as "GET /users/{id}".
+	PathParam func(r *http.Request, name string) string
+
+	// Validate validates the decoded value. Defaults to validator.ValidateStruct.
+	Validate func(interface{}) error
+
+	// ProblemOptions configure the problem+json responses of BindAndValidate.
+	ProblemOptions []ProblemOption
+}
+
+// BindOption configures BindAndValidate and Bind.
+type BindOption func(*BindConfig)
+
+// WithBindMaxBodySize sets the maximum JSON body size.
+func WithBindMaxBodySize(size int64) BindOption {
+	return func(c *BindConfig) {
+		c.MaxBodySize = size
+	}
+}
+
+// WithBindStrictJSON rejects JSON bodies with unknown fields.
+func WithBindStrictJSON() BindOption {
+	return func(c *BindConfig) {
+		c.DisallowUnknownFields = true
+	}
+}
+
+// WithBindPathParams sets how path parameters are read, for routers that do
+// not fill http.Request.PathValue.
+func WithBindPathParams(pathParam func(r *http.Request, name string) string) BindOption {
+	return func(c *BindConfig) {
+		c.PathParam = pathParam
+	}
+}
+
+// WithBindValidator replaces the struct validator.
+func WithBindValidator(validate func(interface{}) error) BindOption {
+	return func(c *BindConfig) {
+		c.Validate = validate
+	}
+}
+
+// WithBindProblemOptions configures the problem+json responses of
+// BindAndValidate, e.g. WithProblemDocs.
+func WithBindProblemOptions(opts ...ProblemOption) BindOption {
+	return func(c *BindConfig) {
+		c.ProblemOptions = append(c.ProblemOptions, opts...)
+	}
+}
+
+// DefaultBindConfig returns a default bind configuration.
+func DefaultBindConfig() BindConfig {
+	return BindConfig{
+		MaxBodySize: 1 << 20,
+		PathParam: func(r *http.Request, name string) string {
+			return r.PathValue(name)
+		},
+		Validate: validator.ValidateStruct,
+	}
+}
+
+// BindAndValidate adapts a typed handler to net/http. The request is decoded
+// into T from the JSON body, then the `query`, `header` and `path` tagged
+// fields, and validated before the handler is called. Decoding and
+// validation failures short-circuit with a 400 problem+json response.
+//
+// The handler result is written as JSON, or as 204 when nil. Handler errors
+// are written as problem+json with the status of their domain error type,
+// other errors as 500.
+//
+//	type GetUserRequest struct {
+//		ID     string `path:"id" validate:"required,uuid4"`
+//		Expand bool   `query:"expand"`
+//	}
+//
+//	mux.HandleFunc("GET /users/{id}", middlewares.BindAndValidate(
+//		func(ctx context.Context, req GetUserRequest) (interface{}, error) {
+//			return users.Get(ctx, req.ID, req.Expand)
+//		}))
+func BindAndValidate[T any](handler func(ctx context.Context, req T) (interface{}, error), opts ...BindOption) http.HandlerFunc {
+	problemOpts := newBindConfig(opts).ProblemOptions
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := Bind[T](r, opts...)
+		if err != nil {
+			WriteProblem(w, err, problemOpts...)
+			return
+		}
+
+		resp, err := handler(r.Context(), req)
+		if err != nil {
+			WriteProblem(w, err, problemOpts...)
+			return
+		}
+		if resp == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// Bind decodes and validates the request into T as BindAndValidate does. The
+// errors are BadRequest domain errors for malformed input and Validation
+// domain errors wrapping validator.ValidationErrors.
+func Bind[T any](r *http.Request, opts ...BindOption) (T, error) {
+	config := newBindConfig(opts)
+
+	var req T
+	if err := decodeBody(r, &req, config); err != nil {
+		return req, err
+	}
+
+	target := reflect.ValueOf(&req).Elem()
+	if target.Kind() == reflect.Struct {
+		if err := bindFields(r, target, config); err != nil {
+			return req, err
+		}
+	}
+
+	if config.Validate != nil {
+		if err := config.Validate(&req); err != nil {
+			var fieldErrs validator.ValidationErrors
+			if !errors.As(err, &fieldErrs) {
+				return req, err
+			}
+			return req, domainerrors.NewWithMetadata(domaininterfaces.ValidationError, "VALIDATION_FAILED",
+				"request validation failed", map[string]interface{}{
+					"fields": fieldErrs.ToMap(),
+				}).Wrap(fieldErrs)
+		}
+	}
+	return req, nil
+}
+
+// WriteProblem writes an error as a problem+json response. Domain errors
+// give the status, code, error type and severity; validator.ValidationErrors
+// are listed field by field and answered with 400 when not wrapped. With
+// WithProblemDocs the problem type links the documentation of the code.
+// With a response profile, see WithProblemProfile, the profile decides the
+// code and detail and which details are exposed.
+func WriteProblem(w http.ResponseWriter, err error, opts ...ProblemOption) {
+	var config problemConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if config.profile == nil {
+		config.profile = domainerrors.GetFactory().ResponseProfile()
+	}
+
+	problem := Problem{
+		Type:   "about:blank",
+		Status: http.StatusInternalServerError,
+		Detail: "internal server error",
+	}
+
+	var fieldErrs validator.ValidationErrors
+	if errors.As(err, &fieldErrs) {
+		problem.Status = http.StatusBadRequest
+		problem.Code = "VALIDATION_FAILED"
+		problem.Detail = "request validation failed"
+		problem.Errors = fieldErrs
+	}
+	var domainErr domaininterfaces.DomainErrorInterface
+	isDomainErr := errors.As(err, &domainErr)
+	switch {
+	case config.profile != nil && (isDomainErr || problem.Errors == nil):
+		resp := config.profile.Render(err)
+		problem.Status = resp.Status
+		problem.Code = resp.Code
+		problem.Detail = resp.Message
+		problem.ErrorType = string(resp.Type)
+		problem.Severity = resp.Severity
+		problem.Metadata = resp.Metadata
+		problem.Cause = resp.Cause
+		problem.Stack = resp.Stack
+
+		// Codes replaced by the profile must not leak through their docs
+		if docs, ok := domainErr.(domaininterfaces.DocsProvider); ok && config.docs && resp.Code == domainErr.Code() {
+			if docsURL := docs.DocsURL(); docsURL != "" {
+				problem.Type = docsURL
+			}
+			problem.Runbook = docs.RunbookURL()
+		}
+	case isDomainErr:
+		problem.Status = domainErr.HTTPStatus()
+		problem.Code = domainErr.Code()
+		problem.Detail = domainErr.Error()
+		problem.ErrorType = string(domainErr.Type())
+		if severity, ok := domainErr.(interface{ Severity() domainerrors.Severity }); ok {
+			problem.Severity = severity.Severity().String()
+		}
+
+		if docs, ok := domainErr.(domaininterfaces.DocsProvider); ok && config.docs {
+			if docsURL := docs.DocsURL(); docsURL != "" {
+				problem.Type = docsURL
+			}
+			problem.Runbook = docs.RunbookURL()
+		}
+	}
+	problem.Title = http.StatusText(problem.Status)
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+// newBindConfig returns the default configuration with opts applied.
+func newBindConfig(opts []BindOption) BindConfig {
+	config := DefaultBindConfig()
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return config
+}
+
+// decodeBody decodes a JSON body into v. Empty bodies are ignored.
+func decodeBody(r *http.Request, v interface{}, config BindConfig) error {
+	if r.Body == nil || r.Body == http.NoBody || r.ContentLength == 0 {
+		return nil
+	}
+
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || (mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json")) {
+			return domainerrors.NewWithMetadata(domaininterfaces.UnsupportedMediaTypeError, "UNSUPPORTED_MEDIA_TYPE",
+				"request body must be JSON", map[string]interface{}{"content_type": contentType})
+		}
+	}
+
+	body := io.LimitReader(r.Body, config.MaxBodySize+1)
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return domainerrors.Wrap(err, domaininterfaces.BadRequestError, "INVALID_REQUEST_BODY", "request body could not be read")
+	}
+	if int64(len(data)) > config.MaxBodySize {
+		return domainerrors.NewWithMetadata(domaininterfaces.PayloadTooLargeError, "REQUEST_BODY_TOO_LARGE",
+			"request body too large", map[string]interface{}{"max_size": config.MaxBodySize})
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return nil
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	if config.DisallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(v); err != nil {
+		return domainerrors.Wrap(err, domaininterfaces.BadRequestError, "INVALID_REQUEST_BODY",
+			fmt.Sprintf("invalid JSON body: %v", err))
+	}
+	return nil
+}
+
+// bindFields sets the fields tagged with query, header or path, following
+// embedded structs.
+func bindFields(r *http.Request, v reflect.Value, config BindConfig) error {
+	t := v.Type()
+	query := r.URL.Query()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if err := bindFields(r, v.Field(i), config); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var source, name string
+		var values []string
+		if name = field.Tag.Get("query"); name != "" {
+			source, values = "query", query[name]
+		} else if name = field.Tag.Get("header"); name != "" {
+			source, values = "header", r.Header.Values(name)
+		} else if name = field.Tag.Get("path"); name != "" {
+			source = "path"
+			if value := config.PathParam(r, name); value != "" {
+				values = []string{value}
+			}
+		}
+		if len(values) == 0 {
+			continue
+		}
+
+		if err := setFieldValue(v.Field(i), values); err != nil {
+			return domainerrors.NewWithMetadata(domaininterfaces.BadRequestError, "INVALID_"+strings.ToUpper(source)+"_PARAMETER",
+				fmt.Sprintf("invalid %s parameter %s: %v", source, name, err), map[string]interface{}{
+					"parameter": name,
+					"source":    source,
+				}).Wrap(err)
+		}
+	}
+	return nil
+}
+
+// textUnmarshalerType is the type of encoding.TextUnmarshaler.
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// setFieldValue converts the raw values to the field type. Slices take
+// repeated values or a comma-separated list.
+func setFieldValue(field reflect.Value, values []string) error {
+	if field.Kind() == reflect.Pointer {
+		ptr := reflect.New(field.Type().Elem())
+		if err := setFieldValue(ptr.Elem(), values); err != nil {
+			return err
+		}
+		field.Set(ptr)
+		return nil
+	}
+
+	if reflect.PointerTo(field.Type()).Implements(textUnmarshalerType) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(values[0]))
+	}
+
+	if field.Kind() == reflect.Slice {
+		var items []string
+		for _, value := range values {
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+		}
+		slice := reflect.MakeSlice(field.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setFieldValue(slice.Index(i), []string{item}); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+
+	value := values[0]
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.New("must be a boolean")
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if field.Type() == reflect.TypeOf(time.Duration(0)) {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return errors.New("must be a duration")
+			}
+			field.SetInt(int64(d))
+			return nil
+		}
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return errors.New("must be an integer")
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return errors.New("must be a non-negative integer")
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return errors.New("must be a number")
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
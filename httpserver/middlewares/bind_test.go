@@ -0,0 +1,367 @@
+package middlewares
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	domaininterfaces "github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/fsvxavier/nexs-lib/validation/validator"
+)
+
+type BindPaging struct {
+	Page  int `query:"page"`
+	Limit int `query:"limit" validate:"max=100"`
+}
+
+type bindRequest struct {
+	BindPaging
+	ID      string        `path:"id" validate:"required"`
+	Name    string        `json:"name" validate:"required,min_length=3"`
+	Tags    []string      `query:"tags"`
+	Active  *bool         `query:"active"`
+	Timeout time.Duration `query:"timeout"`
+	Trace   string        `header:"X-Trace-Id"`
+}
+
+func newBindRequest(method, target, body string) *http.Request {
+	var req *http.Request
+	if body == "" {
+		req = httptest.NewRequest(method, target, nil)
+	} else {
+		req = httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.SetPathValue("id", "42")
+	return req
+}
+
+func TestBind(t *testing.T) {
+	req := newBindRequest(http.MethodPut, "/users/42?page=2&limit=10&tags=a,b&tags=c&active=true&timeout=5s", `{"name":"Alice"}`)
+	req.Header.Set("X-Trace-Id", "trace-1")
+
+	got, err := Bind[bindRequest](req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if got.ID != "42" || got.Name != "Alice" || got.Trace != "trace-1" {
+		t.Errorf("Unexpected values: %+v", got)
+	}
+	if got.Page != 2 || got.Limit != 10 {
+		t.Errorf("Expected embedded paging 2/10, got %d/%d", got.Page, got.Limit)
+	}
+	if strings.Join(got.Tags, "|") != "a|b|c" {
+		t.Errorf("Expected tags a|b|c, got %v", got.Tags)
+	}
+	if got.Active == nil || !*got.Active {
+		t.Error("Expected active to be true")
+	}
+	if got.Timeout != 5*time.Second {
+		t.Errorf("Expected timeout 5s, got %v", got.Timeout)
+	}
+}
+
+func TestBind_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       *http.Request
+		opts      []BindOption
+		errorType domaininterfaces.ErrorType
+		code      string
+	}{
+		{
+			name:      "validation",
+			req:       newBindRequest(http.MethodPut, "/users/42", `{"name":"Al"}`),
+			errorType: domaininterfaces.ValidationError,
+			code:      "VALIDATION_FAILED",
+		},
+		{
+			name:      "malformed body",
+			req:       newBindRequest(http.MethodPut, "/users/42", `{"name":`),
+			errorType: domaininterfaces.BadRequestError,
+			code:      "INVALID_REQUEST_BODY",
+		},
+		{
+			name:      "unknown field",
+			req:       newBindRequest(http.MethodPut, "/users/42", `{"name":"Alice","admin":true}`),
+			opts:      []BindOption{WithBindStrictJSON()},
+			errorType: domaininterfaces.BadRequestError,
+			code:      "INVALID_REQUEST_BODY",
+		},
+		{
+			name:      "body too large",
+			req:       newBindRequest(http.MethodPut, "/users/42", `{"name":"Alice"}`),
+			opts:      []BindOption{WithBindMaxBodySize(4)},
+			errorType: domaininterfaces.PayloadTooLargeError,
+			code:      "REQUEST_BODY_TOO_LARGE",
+		},
+		{
+			name:      "invalid query parameter",
+			req:       newBindRequest(http.MethodPut, "/users/42?page=two", `{"name":"Alice"}`),
+			errorType: domaininterfaces.BadRequestError,
+			code:      "INVALID_QUERY_PARAMETER",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Bind[bindRequest](tt.req, tt.opts...)
+			if !domainerrors.IsType(err, tt.errorType) {
+				t.Fatalf("Expected %s, got %v", tt.errorType, err)
+			}
+			var domainErr domaininterfaces.DomainErrorInterface
+			if !errors.As(err, &domainErr) || domainErr.Code() != tt.code {
+				t.Errorf("Expected code %s, got %v", tt.code, err)
+			}
+		})
+	}
+}
+
+func TestBind_UnsupportedMediaType(t *testing.T) {
+	req := newBindRequest(http.MethodPut, "/users/42", "name=Alice")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	_, err := Bind[bindRequest](req)
+	if !domainerrors.IsType(err, domaininterfaces.UnsupportedMediaTypeError) {
+		t.Errorf("Expected unsupported media type error, got %v", err)
+	}
+}
+
+func TestBind_PathParams(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPut, "/users", strings.NewReader(`{"name":"Alice"}`))
+	got, err := Bind[bindRequest](req, WithBindPathParams(func(r *http.Request, name string) string {
+		return "custom-" + name
+	}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got.ID != "custom-id" {
+		t.Errorf("Expected custom-id, got %s", got.ID)
+	}
+}
+
+func TestBindAndValidate(t *testing.T) {
+	called := false
+	handler := BindAndValidate(func(ctx context.Context, req bindRequest) (interface{}, error) {
+		called = true
+		return map[string]string{"id": req.ID, "name": req.Name}, nil
+	})
+
+	rec := httptest.NewRecorder()
+	handler(rec, newBindRequest(http.MethodPut, "/users/42", `{"name":"Alice"}`))
+
+	if !called {
+		t.Fatal("Expected handler to be called")
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+	var body map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Expected JSON body, got %v", err)
+	}
+	if body["id"] != "42" || body["name"] != "Alice" {
+		t.Errorf("Unexpected body: %v", body)
+	}
+}
+
+func TestBindAndValidate_ValidationProblem(t *testing.T) {
+	handler := BindAndValidate(func(ctx context.Context, req bindRequest) (interface{}, error) {
+		t.Error("Handler should not be called")
+		return nil, nil
+	})
+
+	rec := httptest.NewRecorder()
+	handler(rec, newBindRequest(http.MethodPut, "/users/42?limit=500", `{}`))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != ProblemContentType {
+		t.Errorf("Expected %s, got %s", ProblemContentType, ct)
+	}
+
+	var problem Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("Expected problem body, got %v", err)
+	}
+	if problem.Status != http.StatusBadRequest || problem.Code != "VALIDATION_FAILED" {
+		t.Errorf("Unexpected problem: %+v", problem)
+	}
+
+	fields := map[string]bool{}
+	for _, fieldErr := range problem.Errors {
+		fields[fieldErr.Field] = true
+	}
+	if len(problem.Errors) != 2 || !fields["name"] {
+		t.Errorf("Expected name and limit errors, got %+v", problem.Errors)
+	}
+}
+
+func TestBindAndValidate_HandlerResult(t *testing.T) {
+	tests := []struct {
+		name   string
+		result interface{}
+		err    error
+		status int
+	}{
+		{"no content", nil, nil, http.StatusNoContent},
+		{"domain error", nil, domainerrors.New(domaininterfaces.NotFoundError, "USER_NOT_FOUND", "user not found"), http.StatusNotFound},
+		{"plain error", nil, errors.New("boom"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := BindAndValidate(func(ctx context.Context, req bindRequest) (interface{}, error) {
+				return tt.result, tt.err
+			})
+
+			rec := httptest.NewRecorder()
+			handler(rec, newBindRequest(http.MethodPut, "/users/42", `{"name":"Alice"}`))
+
+			if rec.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, rec.Code)
+			}
+			if tt.err != nil && strings.Contains(rec.Body.String(), "boom") {
+				t.Error("Expected internal errors not to be exposed")
+			}
+		})
+	}
+}
+
+func TestWriteProblem_ValidationErrors(t *testing.T) {
+	err := validator.ValidateStruct(&struct {
+		Email string `json:"email" validate:"required,email"`
+	}{})
+
+	rec := httptest.NewRecorder()
+	WriteProblem(rec, err)
+
+	var problem Problem
+	if jsonErr := json.Unmarshal(rec.Body.Bytes(), &problem); jsonErr != nil {
+		t.Fatalf("Expected problem body, got %v", jsonErr)
+	}
+	if len(problem.Errors) == 0 || problem.Errors[0].Field != "email" {
+		t.Errorf("Expected email field error, got %+v", problem.Errors)
+	}
+}
+
+func TestWriteProblem_Docs(t *testing.T) {
+	domainerrors.RegisterCode(domainerrors.CodeInfo{
+		Code:       "BIND_TEST_QUOTA_EXCEEDED",
+		DocsURL:    "https://docs.example.com/errors/quota",
+		RunbookURL: "https://runbooks.example.com/quota",
+	})
+	err := domainerrors.New(domaininterfaces.RateLimitError, "BIND_TEST_QUOTA_EXCEEDED", "quota exceeded")
+
+	rec := httptest.NewRecorder()
+	WriteProblem(rec, err)
+	var problem Problem
+	if jsonErr := json.Unmarshal(rec.Body.Bytes(), &problem); jsonErr != nil {
+		t.Fatalf("Expected problem body, got %v", jsonErr)
+	}
+	if problem.Type != "about:blank" || problem.Runbook != "" {
+		t.Errorf("Expected no docs without WithProblemDocs, got %+v", problem)
+	}
+
+	handler := BindAndValidate(func(ctx context.Context, req struct{}) (interface{}, error) {
+		return nil, err
+	}, WithBindProblemOptions(WithProblemDocs()))
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	problem = Problem{}
+	if jsonErr := json.Unmarshal(rec.Body.Bytes(), &problem); jsonErr != nil {
+		t.Fatalf("Expected problem body, got %v", jsonErr)
+	}
+	if problem.Type != "https://docs.example.com/errors/quota" {
+		t.Errorf("Expected docs type, got %q", problem.Type)
+	}
+	if problem.Runbook != "https://runbooks.example.com/quota" {
+		t.Errorf("Expected runbook, got %q", problem.Runbook)
+	}
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected status 429, got %d", rec.Code)
+	}
+}
+
+func TestWriteProblem_ClientRoundTrip(t *testing.T) {
+	err := domainerrors.New(domaininterfaces.ConflictError, "BIND_TEST_ORDER_EXISTS", "order already exists")
+
+	rec := httptest.NewRecorder()
+	WriteProblem(rec, err)
+
+	remote := domainerrors.FromHTTPResponse(rec.Result())
+	if remote == nil {
+		t.Fatal("Expected a rebuilt domain error")
+	}
+	if remote.Code() != "BIND_TEST_ORDER_EXISTS" || remote.Type() != domaininterfaces.ConflictError ||
+		remote.Error() != "order already exists" {
+		t.Errorf("Unexpected rebuilt error %v (%s, %s)", remote, remote.Code(), remote.Type())
+	}
+	if severity := remote.(interface{ Severity() domainerrors.Severity }).Severity(); severity != domainerrors.SeverityLow {
+		t.Errorf("Expected low severity, got %v", severity)
+	}
+}
+
+func TestWriteProblem_Profiles(t *testing.T) {
+	domainerrors.RegisterCode(domainerrors.CodeInfo{
+		Code:    "BIND_TEST_DB_FAILED",
+		DocsURL: "https://docs.internal.example.com/errors/db",
+	})
+	err := domainerrors.NewWithMetadata(domaininterfaces.DatabaseError, "BIND_TEST_DB_FAILED",
+		"query failed for password hunter2", map[string]interface{}{"query": "SELECT secret"}).
+		Wrap(errors.New("pq: connection to 10.0.0.5 refused"))
+
+	rec := httptest.NewRecorder()
+	WriteProblem(rec, err, WithProblemProfile(domainerrors.PublicProfile()), WithProblemDocs())
+	body := rec.Body.String()
+	for _, leak := range []string{"hunter2", "SELECT", "10.0.0.5", "BIND_TEST_DB_FAILED", "docs.internal", "database_error"} {
+		if strings.Contains(body, leak) {
+			t.Errorf("Public problem leaks %q: %s", leak, body)
+		}
+	}
+	var problem Problem
+	if jsonErr := json.Unmarshal(rec.Body.Bytes(), &problem); jsonErr != nil {
+		t.Fatalf("Expected problem body, got %v", jsonErr)
+	}
+	if problem.Code != "INTERNAL_ERROR" || problem.Status != http.StatusInternalServerError || problem.Type != "about:blank" {
+		t.Errorf("Unexpected public problem %+v", problem)
+	}
+
+	// O perfil padrão da fábrica vale para rotas sem perfil próprio
+	domainerrors.GetFactory().SetResponseProfile(domainerrors.InternalProfile())
+	t.Cleanup(func() { domainerrors.GetFactory().SetResponseProfile(nil) })
+
+	rec = httptest.NewRecorder()
+	WriteProblem(rec, err)
+	problem = Problem{}
+	if jsonErr := json.Unmarshal(rec.Body.Bytes(), &problem); jsonErr != nil {
+		t.Fatalf("Expected problem body, got %v", jsonErr)
+	}
+	if problem.Code != "BIND_TEST_DB_FAILED" || problem.Metadata["query"] != "SELECT secret" ||
+		problem.Cause != "pq: connection to 10.0.0.5 refused" || problem.Stack == "" {
+		t.Errorf("Expected internal details, got %+v", problem)
+	}
+
+	// Erros de validação continuam listados campo a campo
+	fieldErr := validator.ValidateStruct(&struct {
+		Email string `json:"email" validate:"required,email"`
+	}{})
+	rec = httptest.NewRecorder()
+	WriteProblem(rec, fieldErr, WithProblemProfile(domainerrors.PublicProfile()))
+	problem = Problem{}
+	if jsonErr := json.Unmarshal(rec.Body.Bytes(), &problem); jsonErr != nil {
+		t.Fatalf("Expected problem body, got %v", jsonErr)
+	}
+	if rec.Code != http.StatusBadRequest || len(problem.Errors) == 0 {
+		t.Errorf("Expected field errors, got %d %+v", rec.Code, problem)
+	}
+}
@@ -0,0 +1,395 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	domaininterfaces "github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+// ErrConcurrencyLimitExceeded is wrapped by the RateLimitError returned when
+// a key has as many requests in flight as its limit.
+var ErrConcurrencyLimitExceeded = errors.New("concurrency limit exceeded")
+
+// ConcurrencyLimitMode defines how the in-flight limit is computed.
+type ConcurrencyLimitMode int
+
+const (
+	// FixedConcurrency keeps MaxInFlight requests in flight per key.
+	FixedConcurrency ConcurrencyLimitMode = iota
+	// AdaptiveConcurrency starts at MaxInFlight and adjusts the limit of each
+	// key with AIMD: it grows by one per window of successful requests and is
+	// multiplied by BackoffRatio when a request fails or exceeds
+	// LatencyThreshold.
+	AdaptiveConcurrency
+)
+
+// String returns the name of the mode.
+func (m ConcurrencyLimitMode) String() string {
+	if m == AdaptiveConcurrency {
+		return "adaptive"
+	}
+	return "fixed"
+}
+
+// ConcurrencyLimitMiddleware limits the number of requests in flight per key,
+// complementing RateLimitMiddleware, which limits requests over time.
+type ConcurrencyLimitMiddleware struct {
+	*BaseMiddleware
+
+	// Configuration
+	config  ConcurrencyLimitConfig
+	limiter *ConcurrencyLimiter
+
+	// Metrics
+	totalRequests    int64
+	allowedRequests  int64
+	rejectedRequests int64
+}
+
+// ConcurrencyLimitConfig defines configuration options for concurrency
+// limiting.
+type ConcurrencyLimitConfig struct {
+	Mode ConcurrencyLimitMode
+
+	// MaxInFlight is the limit of FixedConcurrency and the initial limit of
+	// AdaptiveConcurrency.
+	MaxInFlight int
+
+	// Adaptive limit bounds and backoff. Requests slower than
+	// LatencyThreshold count as failures; zero only considers errors.
+	MinLimit         int
+	MaxLimit         int
+	BackoffRatio     float64
+	LatencyThreshold time.Duration
+
+	// IsFailure tells whether a request error signals overload. Defaults to
+	// errors other than domain errors with a 4xx status.
+	IsFailure func(err error) bool
+
+	// KeyFunc identifies the requests sharing a limit. Defaults to a single
+	// key for every request.
+	KeyFunc func(ctx context.Context, req interface{}) string
+
+	// SkipPaths are not limited.
+	SkipPaths []string
+
+	// Clock measures request latency. Defaults to clock.Real().
+	Clock clock.Clock
+}
+
+// NewConcurrencyLimitMiddleware creates a new concurrency limiting middleware
+// with default configuration.
+func NewConcurrencyLimitMiddleware(priority int) *ConcurrencyLimitMiddleware {
+	return NewConcurrencyLimitMiddlewareWithConfig(priority, DefaultConcurrencyLimitConfig())
+}
+
+// NewConcurrencyLimitMiddlewareWithConfig creates a new concurrency limiting
+// middleware with custom configuration. Empty fields are filled with the
+// defaults.
+func NewConcurrencyLimitMiddlewareWithConfig(priority int, config ConcurrencyLimitConfig) *ConcurrencyLimitMiddleware {
+	limiter := NewConcurrencyLimiter(config)
+	return &ConcurrencyLimitMiddleware{
+		BaseMiddleware: NewBaseMiddleware("concurrency_limit", priority),
+		config:         limiter.config,
+		limiter:        limiter,
+	}
+}
+
+// DefaultConcurrencyLimitConfig returns a default concurrency limiting
+// configuration.
+func DefaultConcurrencyLimitConfig() ConcurrencyLimitConfig {
+	return ConcurrencyLimitConfig{
+		Mode:         FixedConcurrency,
+		MaxInFlight:  100,
+		MinLimit:     1,
+		MaxLimit:     1000,
+		BackoffRatio: 0.9,
+		SkipPaths:    []string{"/health", "/metrics"},
+	}
+}
+
+// withConcurrencyLimitDefaults fills empty configuration fields.
+func withConcurrencyLimitDefaults(config ConcurrencyLimitConfig) ConcurrencyLimitConfig {
+	defaults := DefaultConcurrencyLimitConfig()
+	if config.MaxInFlight <= 0 {
+		config.MaxInFlight = defaults.MaxInFlight
+	}
+	if config.MinLimit <= 0 {
+		config.MinLimit = defaults.MinLimit
+	}
+	if config.MaxLimit <= 0 {
+		config.MaxLimit = defaults.MaxLimit
+	}
+	if config.MaxLimit < config.MaxInFlight {
+		config.MaxLimit = config.MaxInFlight
+	}
+	if config.BackoffRatio <= 0 || config.BackoffRatio >= 1 {
+		config.BackoffRatio = defaults.BackoffRatio
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = isOverloadError
+	}
+	config.Clock = clock.OrReal(config.Clock)
+	return config
+}
+
+// Process implements the Middleware interface for concurrency limiting.
+func (cm *ConcurrencyLimitMiddleware) Process(ctx context.Context, req interface{}, next MiddlewareNext) (resp interface{}, err error) {
+	if !cm.IsEnabled() {
+		return next(ctx, req)
+	}
+
+	var path string
+	if httpReq, ok := req.(map[string]interface{}); ok {
+		path, _ = httpReq["path"].(string)
+	}
+	if cm.shouldSkip(path) {
+		return next(ctx, req)
+	}
+
+	release, err := cm.acquire(cm.key(ctx, req))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { release(err) }()
+
+	return next(ctx, req)
+}
+
+// Handler returns a net/http middleware limiting the requests in flight.
+// Responses with a 5xx status count as failures for the adaptive limit.
+func (cm *ConcurrencyLimitMiddleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !cm.IsEnabled() || cm.shouldSkip(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		release, err := cm.acquire(cm.key(r.Context(), r))
+		if err != nil {
+			WriteProblem(w, err)
+			return
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		defer func() {
+			var failure error
+			if recorder.status >= http.StatusInternalServerError {
+				failure = fmt.Errorf("status %d", recorder.status)
+			}
+			release(failure)
+		}()
+		next.ServeHTTP(recorder, r)
+	})
+}
+
+// Limiter returns the limiter of the middleware.
+func (cm *ConcurrencyLimitMiddleware) Limiter() *ConcurrencyLimiter {
+	return cm.limiter
+}
+
+// GetConfig returns the current concurrency limiting configuration.
+func (cm *ConcurrencyLimitMiddleware) GetConfig() ConcurrencyLimitConfig {
+	return cm.config
+}
+
+// GetMetrics returns concurrency limiting metrics.
+func (cm *ConcurrencyLimitMiddleware) GetMetrics() map[string]interface{} {
+	return map[string]interface{}{
+		"total_requests":    atomic.LoadInt64(&cm.totalRequests),
+		"allowed_requests":  atomic.LoadInt64(&cm.allowedRequests),
+		"rejected_requests": atomic.LoadInt64(&cm.rejectedRequests),
+		"active_keys":       cm.limiter.Len(),
+		"mode":              cm.config.Mode.String(),
+	}
+}
+
+// Reset resets all metrics.
+func (cm *ConcurrencyLimitMiddleware) Reset() {
+	atomic.StoreInt64(&cm.totalRequests, 0)
+	atomic.StoreInt64(&cm.allowedRequests, 0)
+	atomic.StoreInt64(&cm.rejectedRequests, 0)
+}
+
+// acquire counts the request and takes a slot of key.
+func (cm *ConcurrencyLimitMiddleware) acquire(key string) (func(err error), error) {
+	atomic.AddInt64(&cm.totalRequests, 1)
+
+	release, err := cm.limiter.Acquire(key)
+	if err != nil {
+		atomic.AddInt64(&cm.rejectedRequests, 1)
+		cm.GetLogger().Warn("Concurrency limit exceeded for key: %s", key)
+		return nil, err
+	}
+	atomic.AddInt64(&cm.allowedRequests, 1)
+	return release, nil
+}
+
+func (cm *ConcurrencyLimitMiddleware) key(ctx context.Context, req interface{}) string {
+	if cm.config.KeyFunc != nil {
+		return cm.config.KeyFunc(ctx, req)
+	}
+	return "global"
+}
+
+func (cm *ConcurrencyLimitMiddleware) shouldSkip(path string) bool {
+	for _, skipPath := range cm.config.SkipPaths {
+		if path == skipPath {
+			return true
+		}
+	}
+	return false
+}
+
+// ConcurrencyLimiter limits the requests in flight per key. It can be used
+// without the middleware, e.g. around calls to a downstream service.
+type ConcurrencyLimiter struct {
+	config ConcurrencyLimitConfig
+
+	mu   sync.Mutex
+	keys map[string]*concurrencyState
+}
+
+// concurrencyState is the in-flight count and limit of a key.
+type concurrencyState struct {
+	inFlight int
+	limit    float64
+}
+
+// NewConcurrencyLimiter creates a new concurrency limiter. Empty fields of
+// config are filled with the defaults.
+func NewConcurrencyLimiter(config ConcurrencyLimitConfig) *ConcurrencyLimiter {
+	return &ConcurrencyLimiter{
+		config: withConcurrencyLimitDefaults(config),
+		keys:   make(map[string]*concurrencyState),
+	}
+}
+
+// Acquire takes a slot of key. The returned function must be called once the
+// request completes, with its error, so that the adaptive limit can react to
+// failures. When the key is at its limit, Acquire returns a RateLimitError
+// wrapping ErrConcurrencyLimitExceeded whose metadata hold the key, the
+// current limit and the requests in flight.
+func (cl *ConcurrencyLimiter) Acquire(key string) (func(err error), error) {
+	cl.mu.Lock()
+	state := cl.state(key)
+	limit := int(state.limit)
+	if state.inFlight >= limit {
+		inFlight := state.inFlight
+		cl.mu.Unlock()
+		return nil, domainerrors.NewWithMetadata(domaininterfaces.RateLimitError, "CONCURRENCY_LIMIT_EXCEEDED",
+			fmt.Sprintf("concurrency limit of %d exceeded for key %s", limit, key), map[string]interface{}{
+				"key":       key,
+				"limit":     limit,
+				"in_flight": inFlight,
+				"mode":      cl.config.Mode.String(),
+			}).Wrap(ErrConcurrencyLimitExceeded)
+	}
+	state.inFlight++
+	inFlight := state.inFlight
+	cl.mu.Unlock()
+
+	start := cl.config.Clock.Now()
+	var once sync.Once
+	return func(err error) {
+		once.Do(func() {
+			cl.release(key, inFlight, cl.config.Clock.Since(start), err)
+		})
+	}, nil
+}
+
+// Limit returns the current limit of key.
+func (cl *ConcurrencyLimiter) Limit(key string) int {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	if state, ok := cl.keys[key]; ok {
+		return int(state.limit)
+	}
+	return cl.config.MaxInFlight
+}
+
+// InFlight returns the requests in flight of key.
+func (cl *ConcurrencyLimiter) InFlight(key string) int {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	if state, ok := cl.keys[key]; ok {
+		return state.inFlight
+	}
+	return 0
+}
+
+// Len returns the number of keys tracked.
+func (cl *ConcurrencyLimiter) Len() int {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	return len(cl.keys)
+}
+
+// state returns the state of key, creating it. cl.mu must be held.
+func (cl *ConcurrencyLimiter) state(key string) *concurrencyState {
+	state, ok := cl.keys[key]
+	if !ok {
+		state = &concurrencyState{limit: float64(cl.config.MaxInFlight)}
+		cl.keys[key] = state
+	}
+	return state
+}
+
+// release frees the slot of a request and, in adaptive mode, adjusts the
+// limit with its outcome. inFlight is the count when the request started.
+func (cl *ConcurrencyLimiter) release(key string, inFlight int, latency time.Duration, err error) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	state := cl.state(key)
+	state.inFlight--
+
+	if cl.config.Mode == AdaptiveConcurrency {
+		failed := (err != nil && cl.config.IsFailure(err)) ||
+			(cl.config.LatencyThreshold > 0 && latency > cl.config.LatencyThreshold)
+		switch {
+		case failed:
+			state.limit = math.Max(float64(cl.config.MinLimit), math.Floor(state.limit*cl.config.BackoffRatio))
+		case inFlight*2 >= int(state.limit):
+			// Only grows while the limit is in use, so that an idle key does
+			// not accumulate a limit it never proved it can sustain.
+			state.limit = math.Min(float64(cl.config.MaxLimit), state.limit+1/state.limit)
+		}
+		return
+	}
+
+	// Fixed limits hold no state once idle.
+	if state.inFlight == 0 {
+		delete(cl.keys, key)
+	}
+}
+
+// isOverloadError is the default IsFailure: client errors, such as
+// validation failures, say nothing about the load of the service.
+func isOverloadError(err error) bool {
+	var domainErr domaininterfaces.DomainErrorInterface
+	if errors.As(err, &domainErr) {
+		return domainErr.HTTPStatus() >= http.StatusInternalServerError
+	}
+	return true
+}
+
+// statusRecorder records the status written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
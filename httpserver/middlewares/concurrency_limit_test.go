@@ -0,0 +1,223 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	domaininterfaces "github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+func TestConcurrencyLimiter_Fixed(t *testing.T) {
+	limiter := NewConcurrencyLimiter(ConcurrencyLimitConfig{MaxInFlight: 2})
+
+	release1, err := limiter.Acquire("tenant-a")
+	if err != nil {
+		t.Fatalf("Expected first acquire to succeed, got %v", err)
+	}
+	release2, err := limiter.Acquire("tenant-a")
+	if err != nil {
+		t.Fatalf("Expected second acquire to succeed, got %v", err)
+	}
+
+	_, err = limiter.Acquire("tenant-a")
+	if !errors.Is(err, ErrConcurrencyLimitExceeded) {
+		t.Fatalf("Expected ErrConcurrencyLimitExceeded, got %v", err)
+	}
+	if !domainerrors.IsType(err, domaininterfaces.RateLimitError) {
+		t.Errorf("Expected a RateLimitError, got %T", err)
+	}
+	var domainErr domaininterfaces.DomainErrorInterface
+	if !errors.As(err, &domainErr) {
+		t.Fatalf("Expected a domain error, got %T", err)
+	}
+	metadata := domainErr.Metadata()
+	if metadata["key"] != "tenant-a" || metadata["limit"] != 2 || metadata["in_flight"] != 2 || metadata["mode"] != "fixed" {
+		t.Errorf("Unexpected metadata %v", metadata)
+	}
+	if domainErr.HTTPStatus() != http.StatusTooManyRequests {
+		t.Errorf("Expected status 429, got %d", domainErr.HTTPStatus())
+	}
+
+	// Other keys have their own limit
+	if _, err := limiter.Acquire("tenant-b"); err != nil {
+		t.Errorf("Expected another key to be allowed, got %v", err)
+	}
+
+	// Releasing twice frees a single slot
+	release1(nil)
+	release1(nil)
+	if got := limiter.InFlight("tenant-a"); got != 1 {
+		t.Errorf("Expected 1 in flight, got %d", got)
+	}
+	release2(nil)
+	if got := limiter.Len(); got != 1 {
+		t.Errorf("Expected idle fixed keys to be dropped, got %d keys", got)
+	}
+}
+
+func TestConcurrencyLimiter_AdaptiveAIMD(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	limiter := NewConcurrencyLimiter(ConcurrencyLimitConfig{
+		Mode:             AdaptiveConcurrency,
+		MaxInFlight:      4,
+		MinLimit:         2,
+		MaxLimit:         5,
+		BackoffRatio:     0.5,
+		LatencyThreshold: time.Second,
+		Clock:            fake,
+	})
+
+	// A window of successful requests using the limit grows it by one
+	runWindow := func(n int, latency time.Duration, err error) {
+		releases := make([]func(error), 0, n)
+		for i := 0; i < n; i++ {
+			release, acquireErr := limiter.Acquire("svc")
+			if acquireErr != nil {
+				t.Fatalf("Unexpected acquire error: %v", acquireErr)
+			}
+			releases = append(releases, release)
+		}
+		fake.Advance(latency)
+		for _, release := range releases {
+			release(err)
+		}
+	}
+
+	runWindow(4, time.Millisecond, nil)
+	if got := limiter.Limit("svc"); got != 4 {
+		t.Errorf("Expected limit 4 before a full window, got %d", got)
+	}
+	runWindow(4, time.Millisecond, nil)
+	if got := limiter.Limit("svc"); got != 5 {
+		t.Errorf("Expected limit 5 after additive increase, got %d", got)
+	}
+
+	// Never above MaxLimit
+	for i := 0; i < 10; i++ {
+		runWindow(5, time.Millisecond, nil)
+	}
+	if got := limiter.Limit("svc"); got != 5 {
+		t.Errorf("Expected limit capped at 5, got %d", got)
+	}
+
+	// A slow request halves the limit
+	runWindow(1, 2*time.Second, nil)
+	if got := limiter.Limit("svc"); got != 2 {
+		t.Errorf("Expected limit 2 after a slow request, got %d", got)
+	}
+
+	// Never below MinLimit
+	runWindow(1, time.Millisecond, errors.New("connection refused"))
+	if got := limiter.Limit("svc"); got != 2 {
+		t.Errorf("Expected limit floored at 2, got %d", got)
+	}
+
+	_, err := limiter.Acquire("svc")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	_, _ = limiter.Acquire("svc")
+	_, err = limiter.Acquire("svc")
+	var domainErr domaininterfaces.DomainErrorInterface
+	if !errors.As(err, &domainErr) || domainErr.Metadata()["limit"] != 2 || domainErr.Metadata()["mode"] != "adaptive" {
+		t.Errorf("Expected the computed limit in the error, got %v", err)
+	}
+}
+
+func TestConcurrencyLimiter_ClientErrorsDoNotBackOff(t *testing.T) {
+	limiter := NewConcurrencyLimiter(ConcurrencyLimitConfig{Mode: AdaptiveConcurrency, MaxInFlight: 10})
+
+	release, err := limiter.Acquire("svc")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	release(domainerrors.NewValidationError("INVALID", "invalid input"))
+	if got := limiter.Limit("svc"); got != 10 {
+		t.Errorf("Expected a validation error to keep the limit, got %d", got)
+	}
+
+	release, _ = limiter.Acquire("svc")
+	release(domainerrors.New(domaininterfaces.ServerError, "BOOM", "boom"))
+	if got := limiter.Limit("svc"); got != 9 {
+		t.Errorf("Expected a server error to reduce the limit, got %d", got)
+	}
+}
+
+func TestConcurrencyLimitMiddleware_Process(t *testing.T) {
+	middleware := NewConcurrencyLimitMiddlewareWithConfig(1, ConcurrencyLimitConfig{
+		MaxInFlight: 1,
+		KeyFunc: func(ctx context.Context, req interface{}) string {
+			return req.(map[string]interface{})["ip"].(string)
+		},
+		SkipPaths: []string{"/health"},
+	})
+
+	entered := make(chan struct{})
+	unblock := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		_, err := middleware.Process(context.Background(), map[string]interface{}{"path": "/slow", "ip": "10.0.0.1"},
+			func(ctx context.Context, req interface{}) (interface{}, error) {
+				close(entered)
+				<-unblock
+				return "ok", nil
+			})
+		done <- err
+	}()
+	<-entered
+
+	next := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+	_, err := middleware.Process(context.Background(), map[string]interface{}{"path": "/slow", "ip": "10.0.0.1"}, next)
+	if !errors.Is(err, ErrConcurrencyLimitExceeded) {
+		t.Errorf("Expected the second request to be rejected, got %v", err)
+	}
+	if _, err := middleware.Process(context.Background(), map[string]interface{}{"path": "/slow", "ip": "10.0.0.2"}, next); err != nil {
+		t.Errorf("Expected another key to be allowed, got %v", err)
+	}
+	if _, err := middleware.Process(context.Background(), map[string]interface{}{"path": "/health", "ip": "10.0.0.1"}, next); err != nil {
+		t.Errorf("Expected skipped paths to be allowed, got %v", err)
+	}
+
+	close(unblock)
+	if err := <-done; err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	metrics := middleware.GetMetrics()
+	if metrics["total_requests"] != int64(3) || metrics["rejected_requests"] != int64(1) {
+		t.Errorf("Unexpected metrics %v", metrics)
+	}
+}
+
+func TestConcurrencyLimitMiddleware_Handler(t *testing.T) {
+	middleware := NewConcurrencyLimitMiddlewareWithConfig(1, ConcurrencyLimitConfig{
+		Mode:         AdaptiveConcurrency,
+		MaxInFlight:  4,
+		BackoffRatio: 0.5,
+	})
+
+	failing := middleware.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	failing.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api", nil))
+	if got := middleware.Limiter().Limit("global"); got != 2 {
+		t.Errorf("Expected a 5xx response to reduce the limit to 2, got %d", got)
+	}
+
+	release1, _ := middleware.Limiter().Acquire("global")
+	release2, _ := middleware.Limiter().Acquire("global")
+	defer release1(nil)
+	defer release2(nil)
+
+	rec := httptest.NewRecorder()
+	failing.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api", nil))
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected status 429, got %d", rec.Code)
+	}
+}
@@ -2,18 +2,31 @@ package middlewares
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
 	"strings"
 	"sync/atomic"
 	"time"
 )
 
+// CORS policy errors returned by CORSConfig.Validate.
+var (
+	ErrCORSInsecurePolicy = errors.New("cors policy allows credentials for any origin")
+	ErrCORSInvalidOrigin  = errors.New("cors origin pattern invalid")
+	ErrCORSInvalidRoute   = errors.New("cors route invalid")
+)
+
 // CORSMiddleware provides Cross-Origin Resource Sharing (CORS) functionality.
 type CORSMiddleware struct {
 	*BaseMiddleware
 
 	// Configuration
 	config CORSConfig
+	policy *corsPolicy
+	routes []corsRoute
 
 	// Metrics
 	preflightRequests int64
@@ -28,11 +41,15 @@ type CORSMiddleware struct {
 
 // CORSConfig defines configuration options for the CORS middleware.
 type CORSConfig struct {
-	// Origin configuration
-	AllowedOrigins   []string
-	AllowOriginFunc  func(string) bool
-	AllowAllOrigins  bool
-	AllowCredentials bool
+	// Origin configuration. AllowedOrigins accepts exact origins, "*" and
+	// wildcards such as "*.example.com" or "https://*.example.com";
+	// AllowedOriginPatterns accepts regular expressions matched against the
+	// whole origin.
+	AllowedOrigins        []string
+	AllowedOriginPatterns []string
+	AllowOriginFunc       func(string) bool
+	AllowAllOrigins       bool
+	AllowCredentials      bool
 
 	// Method configuration
 	AllowedMethods []string
@@ -47,16 +64,28 @@ type CORSConfig struct {
 	SkipPaths          []string
 	OptionsPassthrough bool
 
+	// Routes overrides the policy for requests under a path. The most
+	// specific route wins and its configuration replaces this one.
+	Routes []CORSRoute
+
 	// Security configuration
 	VaryByOrigin bool
 	Debug        bool
 }
 
+// CORSRoute applies its own CORS configuration to the requests whose path is
+// Path or starts with Path followed by "/".
+type CORSRoute struct {
+	Path   string
+	Config CORSConfig
+}
+
 // CORSRequest represents a CORS request context.
 type CORSRequest struct {
 	Origin           string
 	Method           string
 	Path             string
+	RequestedMethod  string
 	RequestedHeaders []string
 	IsPreflightReq   bool
 	IsCORSRequest    bool
@@ -73,22 +102,43 @@ type CORSResponse struct {
 	VaryHeaders      []string
 }
 
+// corsPolicy is a CORS configuration with its origin patterns compiled.
+type corsPolicy struct {
+	config   CORSConfig
+	patterns []*regexp.Regexp
+}
+
+// corsRoute binds a policy to a path prefix.
+type corsRoute struct {
+	path   string
+	policy *corsPolicy
+}
+
 // NewCORSMiddleware creates a new CORS middleware with default configuration.
 func NewCORSMiddleware(priority int) *CORSMiddleware {
-	return &CORSMiddleware{
-		BaseMiddleware: NewBaseMiddleware("cors", priority),
-		config:         DefaultCORSConfig(),
-		startTime:      time.Now(),
-	}
+	return NewCORSMiddlewareWithConfig(priority, DefaultCORSConfig())
 }
 
 // NewCORSMiddlewareWithConfig creates a new CORS middleware with custom configuration.
+// Invalid origin patterns are ignored; use NewValidatedCORSMiddleware to
+// reject them, and insecure policies, at startup.
 func NewCORSMiddlewareWithConfig(priority int, config CORSConfig) *CORSMiddleware {
-	return &CORSMiddleware{
+	cm := &CORSMiddleware{
 		BaseMiddleware: NewBaseMiddleware("cors", priority),
 		config:         config,
 		startTime:      time.Now(),
 	}
+	cm.compile()
+	return cm
+}
+
+// NewValidatedCORSMiddleware creates a new CORS middleware after validating
+// the configuration and its routes.
+func NewValidatedCORSMiddleware(priority int, config CORSConfig) (*CORSMiddleware, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return NewCORSMiddlewareWithConfig(priority, config), nil
 }
 
 // DefaultCORSConfig returns a default CORS configuration.
@@ -109,6 +159,50 @@ func DefaultCORSConfig() CORSConfig {
 	}
 }
 
+// Validate checks the configuration and its routes. Credentials combined
+// with any origin are rejected, since browsers refuse "*" with credentials
+// and reflecting every origin would expose authenticated responses to any site.
+func (c CORSConfig) Validate() error {
+	if c.AllowCredentials {
+		if c.AllowAllOrigins {
+			return fmt.Errorf("%w: AllowAllOrigins with AllowCredentials", ErrCORSInsecurePolicy)
+		}
+		for _, origin := range c.AllowedOrigins {
+			if origin == "*" {
+				return fmt.Errorf("%w: \"*\" with AllowCredentials", ErrCORSInsecurePolicy)
+			}
+		}
+	}
+
+	for _, origin := range c.AllowedOrigins {
+		if origin != "*" && strings.Count(origin, "*") > 1 {
+			return fmt.Errorf("%w: %q has more than one wildcard", ErrCORSInvalidOrigin, origin)
+		}
+	}
+	for _, pattern := range c.AllowedOriginPatterns {
+		if _, err := compileOriginPattern(pattern); err != nil {
+			return fmt.Errorf("%w: %q: %v", ErrCORSInvalidOrigin, pattern, err)
+		}
+	}
+
+	if c.MaxAge < 0 {
+		return fmt.Errorf("cors max age must not be negative: %d", c.MaxAge)
+	}
+
+	for _, route := range c.Routes {
+		if !strings.HasPrefix(route.Path, "/") {
+			return fmt.Errorf("%w: path %q must start with /", ErrCORSInvalidRoute, route.Path)
+		}
+		if len(route.Config.Routes) > 0 {
+			return fmt.Errorf("%w: path %q has nested routes", ErrCORSInvalidRoute, route.Path)
+		}
+		if err := route.Config.Validate(); err != nil {
+			return fmt.Errorf("route %s: %w", route.Path, err)
+		}
+	}
+	return nil
+}
+
 // Process implements the Middleware interface for CORS handling.
 func (cm *CORSMiddleware) Process(ctx context.Context, req interface{}, next MiddlewareNext) (interface{}, error) {
 	if !cm.IsEnabled() {
@@ -140,6 +234,59 @@ func (cm *CORSMiddleware) Process(ctx context.Context, req interface{}, next Mid
 	return next(ctx, req)
 }
 
+// Handler wraps a net/http handler, answering preflight requests and adding
+// the CORS headers before calling it. Blocked requests receive a 403.
+func (cm *CORSMiddleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !cm.IsEnabled() || cm.shouldSkipCORS(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		headers := make(map[string]string, len(r.Header))
+		for key := range r.Header {
+			headers[key] = r.Header.Get(key)
+		}
+		corsReq := cm.extractCORSRequest(map[string]interface{}{
+			"method":  r.Method,
+			"path":    r.URL.Path,
+			"headers": headers,
+		})
+		if !corsReq.IsCORSRequest {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		atomic.AddInt64(&cm.corsRequests, 1)
+		if corsReq.IsPreflightReq {
+			atomic.AddInt64(&cm.preflightRequests, 1)
+		}
+
+		corsResp, reason := cm.evaluate(corsReq)
+		if corsResp == nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprintf(w, `{"error": "CORS policy violation", "message": "%s"}`, reason)
+			return
+		}
+
+		for key, value := range cm.corsResponseToHeaders(corsResp) {
+			if key != "Vary" {
+				w.Header().Set(key, value)
+			}
+		}
+		for _, vary := range corsResp.VaryHeaders {
+			w.Header().Add("Vary", vary)
+		}
+
+		if corsReq.IsPreflightReq && !cm.config.OptionsPassthrough {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 // GetConfig returns the current CORS configuration.
 func (cm *CORSMiddleware) GetConfig() CORSConfig {
 	return cm.config
@@ -148,6 +295,7 @@ func (cm *CORSMiddleware) GetConfig() CORSConfig {
 // SetConfig updates the CORS configuration.
 func (cm *CORSMiddleware) SetConfig(config CORSConfig) {
 	cm.config = config
+	cm.compile()
 	cm.GetLogger().Info("CORS middleware configuration updated")
 }
 
@@ -202,8 +350,9 @@ func (cm *CORSMiddleware) extractCORSRequest(req interface{}) *CORSRequest {
 
 				// Check for preflight request
 				if corsReq.Method == "OPTIONS" && corsReq.IsCORSRequest {
-					if _, hasRequestMethod := h["Access-Control-Request-Method"]; hasRequestMethod {
+					if requestMethod, hasRequestMethod := h["Access-Control-Request-Method"]; hasRequestMethod {
 						corsReq.IsPreflightReq = true
+						corsReq.RequestedMethod = strings.ToUpper(strings.TrimSpace(requestMethod))
 
 						// Extract requested headers
 						if requestHeaders, hasRequestHeaders := h["Access-Control-Request-Headers"]; hasRequestHeaders {
@@ -232,32 +381,11 @@ func (cm *CORSMiddleware) shouldSkipCORS(path string) bool {
 func (cm *CORSMiddleware) handlePreflightRequest(ctx context.Context, corsReq *CORSRequest, req interface{}, next MiddlewareNext) (interface{}, error) {
 	cm.GetLogger().Debug("Handling CORS preflight request from origin: %s", corsReq.Origin)
 
-	// Check if origin is allowed
-	if !cm.isOriginAllowed(corsReq.Origin) {
-		atomic.AddInt64(&cm.corsBlocked, 1)
-		cm.GetLogger().Warn("CORS preflight blocked for origin: %s", corsReq.Origin)
-		return cm.createCORSErrorResponse("Origin not allowed")
+	corsResp, reason := cm.evaluate(corsReq)
+	if corsResp == nil {
+		return cm.createCORSErrorResponse(reason)
 	}
 
-	// Check if method is allowed
-	if !cm.isMethodAllowed(corsReq.Method) {
-		atomic.AddInt64(&cm.corsBlocked, 1)
-		cm.GetLogger().Warn("CORS preflight blocked for method: %s", corsReq.Method)
-		return cm.createCORSErrorResponse("Method not allowed")
-	}
-
-	// Check if requested headers are allowed
-	if !cm.areHeadersAllowed(corsReq.RequestedHeaders) {
-		atomic.AddInt64(&cm.corsBlocked, 1)
-		cm.GetLogger().Warn("CORS preflight blocked for headers: %v", corsReq.RequestedHeaders)
-		return cm.createCORSErrorResponse("Headers not allowed")
-	}
-
-	atomic.AddInt64(&cm.corsAllowed, 1)
-
-	// Create CORS response
-	corsResp := cm.createCORSResponse(corsReq)
-
 	// Create response with CORS headers
 	response := map[string]interface{}{
 		"status_code": 204, // No Content for preflight
@@ -281,18 +409,11 @@ func (cm *CORSMiddleware) handlePreflightRequest(ctx context.Context, corsReq *C
 func (cm *CORSMiddleware) handleCORSRequest(ctx context.Context, corsReq *CORSRequest, req interface{}, next MiddlewareNext) (interface{}, error) {
 	cm.GetLogger().Debug("Handling CORS request from origin: %s", corsReq.Origin)
 
-	// Check if origin is allowed
-	if !cm.isOriginAllowed(corsReq.Origin) {
-		atomic.AddInt64(&cm.corsBlocked, 1)
-		cm.GetLogger().Warn("CORS request blocked for origin: %s", corsReq.Origin)
-		return cm.createCORSErrorResponse("Origin not allowed")
+	corsResp, reason := cm.evaluate(corsReq)
+	if corsResp == nil {
+		return cm.createCORSErrorResponse(reason)
 	}
 
-	atomic.AddInt64(&cm.corsAllowed, 1)
-
-	// Create CORS response
-	corsResp := cm.createCORSResponse(corsReq)
-
 	// Add CORS headers to context for response modification
 	ctxWithCORS := context.WithValue(ctx, "cors_headers", corsResp)
 
@@ -302,11 +423,14 @@ func (cm *CORSMiddleware) handleCORSRequest(ctx context.Context, corsReq *CORSRe
 		return resp, err
 	}
 
-	// Add CORS headers to response
+	// Add CORS headers to response, keeping the Vary values set by the handler
 	if httpResp, ok := resp.(map[string]interface{}); ok {
 		if headers, exists := httpResp["headers"]; exists {
 			if h, ok := headers.(map[string]string); ok {
 				corsHeaders := cm.corsResponseToHeaders(corsResp)
+				if vary, hasVary := h["Vary"]; hasVary && vary != "" && corsHeaders["Vary"] != "" {
+					corsHeaders["Vary"] = vary + ", " + corsHeaders["Vary"]
+				}
 				for key, value := range corsHeaders {
 					h[key] = value
 				}
@@ -320,22 +444,108 @@ func (cm *CORSMiddleware) handleCORSRequest(ctx context.Context, corsReq *CORSRe
 	return resp, nil
 }
 
-// isOriginAllowed checks if the given origin is allowed.
-func (cm *CORSMiddleware) isOriginAllowed(origin string) bool {
+// evaluate checks a CORS request against the policy of its path and returns
+// the headers to send, or nil and the reason the request was blocked.
+func (cm *CORSMiddleware) evaluate(corsReq *CORSRequest) (*CORSResponse, string) {
+	policy := cm.policyFor(corsReq.Path)
+
+	// Check if origin is allowed
 	atomic.AddInt64(&cm.originChecks, 1)
+	if !policy.isOriginAllowed(corsReq.Origin) {
+		atomic.AddInt64(&cm.corsBlocked, 1)
+		cm.GetLogger().Warn("CORS request blocked for origin: %s", corsReq.Origin)
+		return nil, "Origin not allowed"
+	}
+
+	if corsReq.IsPreflightReq {
+		// Check if the method of the actual request is allowed
+		if !policy.isMethodAllowed(corsReq.RequestedMethod) {
+			atomic.AddInt64(&cm.corsBlocked, 1)
+			cm.GetLogger().Warn("CORS preflight blocked for method: %s", corsReq.RequestedMethod)
+			return nil, "Method not allowed"
+		}
+
+		// Check if requested headers are allowed
+		if !policy.areHeadersAllowed(corsReq.RequestedHeaders) {
+			atomic.AddInt64(&cm.corsBlocked, 1)
+			cm.GetLogger().Warn("CORS preflight blocked for headers: %v", corsReq.RequestedHeaders)
+			return nil, "Headers not allowed"
+		}
+	}
+
+	atomic.AddInt64(&cm.corsAllowed, 1)
+	return policy.createCORSResponse(corsReq), ""
+}
+
+// policyFor returns the policy of the most specific route matching the path,
+// or the global policy.
+func (cm *CORSMiddleware) policyFor(path string) *corsPolicy {
+	for _, route := range cm.routes {
+		if path == route.path || strings.HasPrefix(path, route.path+"/") {
+			return route.policy
+		}
+	}
+	return cm.policy
+}
+
+// compile builds the global and route policies from the configuration.
+func (cm *CORSMiddleware) compile() {
+	cm.policy = cm.newPolicy(cm.config)
+
+	routes := make([]corsRoute, 0, len(cm.config.Routes))
+	for _, route := range cm.config.Routes {
+		routes = append(routes, corsRoute{
+			path:   strings.TrimSuffix(route.Path, "/"),
+			policy: cm.newPolicy(route.Config),
+		})
+	}
+	// Longer paths are more specific and are matched first
+	sort.SliceStable(routes, func(i, j int) bool {
+		return len(routes[i].path) > len(routes[j].path)
+	})
+	cm.routes = routes
+}
+
+// newPolicy compiles the origin patterns of a configuration.
+func (cm *CORSMiddleware) newPolicy(config CORSConfig) *corsPolicy {
+	policy := &corsPolicy{config: config}
+	for _, pattern := range config.AllowedOriginPatterns {
+		re, err := compileOriginPattern(pattern)
+		if err != nil {
+			cm.GetLogger().Warn("Ignoring invalid CORS origin pattern %s: %v", pattern, err)
+			continue
+		}
+		policy.patterns = append(policy.patterns, re)
+	}
+	return policy
+}
 
-	if cm.config.AllowAllOrigins {
+// compileOriginPattern compiles a regular expression anchored to the whole origin.
+func compileOriginPattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + pattern + ")$")
+}
+
+// isOriginAllowed checks if the given origin is allowed.
+func (p *corsPolicy) isOriginAllowed(origin string) bool {
+	if p.config.AllowAllOrigins {
 		return true
 	}
 
 	// Use custom origin function if provided
-	if cm.config.AllowOriginFunc != nil {
-		return cm.config.AllowOriginFunc(origin)
+	if p.config.AllowOriginFunc != nil {
+		return p.config.AllowOriginFunc(origin)
 	}
 
 	// Check against allowed origins list
-	for _, allowedOrigin := range cm.config.AllowedOrigins {
-		if cm.matchOrigin(origin, allowedOrigin) {
+	for _, allowedOrigin := range p.config.AllowedOrigins {
+		if matchOrigin(origin, allowedOrigin) {
+			return true
+		}
+	}
+
+	// Check against origin patterns
+	for _, pattern := range p.patterns {
+		if pattern.MatchString(origin) {
 			return true
 		}
 	}
@@ -344,7 +554,7 @@ func (cm *CORSMiddleware) isOriginAllowed(origin string) bool {
 }
 
 // matchOrigin checks if the origin matches the allowed origin pattern.
-func (cm *CORSMiddleware) matchOrigin(origin, allowedOrigin string) bool {
+func matchOrigin(origin, allowedOrigin string) bool {
 	if allowedOrigin == "*" {
 		return true
 	}
@@ -356,18 +566,27 @@ func (cm *CORSMiddleware) matchOrigin(origin, allowedOrigin string) bool {
 	// Support for wildcard subdomains (e.g., *.example.com)
 	if strings.HasPrefix(allowedOrigin, "*.") {
 		domain := strings.TrimPrefix(allowedOrigin, "*.")
-		if strings.HasSuffix(origin, "."+domain) {
-			return true
+		return strings.HasSuffix(origin, "."+domain)
+	}
+
+	// Support for wildcards with scheme or port (e.g., https://*.example.com).
+	// The wildcard matches one or more host labels, never a scheme or port.
+	if prefix, suffix, found := strings.Cut(allowedOrigin, "*"); found {
+		if len(origin) <= len(prefix)+len(suffix) ||
+			!strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) {
+			return false
 		}
+		middle := origin[len(prefix) : len(origin)-len(suffix)]
+		return !strings.ContainsAny(middle, "/:@")
 	}
 
 	return false
 }
 
 // isMethodAllowed checks if the given method is allowed.
-func (cm *CORSMiddleware) isMethodAllowed(method string) bool {
-	for _, allowedMethod := range cm.config.AllowedMethods {
-		if strings.ToUpper(allowedMethod) == strings.ToUpper(method) {
+func (p *corsPolicy) isMethodAllowed(method string) bool {
+	for _, allowedMethod := range p.config.AllowedMethods {
+		if strings.EqualFold(allowedMethod, method) {
 			return true
 		}
 	}
@@ -375,9 +594,9 @@ func (cm *CORSMiddleware) isMethodAllowed(method string) bool {
 }
 
 // areHeadersAllowed checks if all requested headers are allowed.
-func (cm *CORSMiddleware) areHeadersAllowed(requestedHeaders []string) bool {
+func (p *corsPolicy) areHeadersAllowed(requestedHeaders []string) bool {
 	for _, requestedHeader := range requestedHeaders {
-		if !cm.isHeaderAllowed(requestedHeader) {
+		if !p.isHeaderAllowed(requestedHeader) {
 			return false
 		}
 	}
@@ -385,7 +604,7 @@ func (cm *CORSMiddleware) areHeadersAllowed(requestedHeaders []string) bool {
 }
 
 // isHeaderAllowed checks if a specific header is allowed.
-func (cm *CORSMiddleware) isHeaderAllowed(header string) bool {
+func (p *corsPolicy) isHeaderAllowed(header string) bool {
 	header = strings.ToLower(strings.TrimSpace(header))
 
 	// Always allow simple headers
@@ -400,7 +619,7 @@ func (cm *CORSMiddleware) isHeaderAllowed(header string) bool {
 	}
 
 	// Check against configured allowed headers
-	for _, allowedHeader := range cm.config.AllowedHeaders {
+	for _, allowedHeader := range p.config.AllowedHeaders {
 		if strings.ToLower(allowedHeader) == header {
 			return true
 		}
@@ -410,11 +629,11 @@ func (cm *CORSMiddleware) isHeaderAllowed(header string) bool {
 }
 
 // createCORSResponse creates a CORS response based on the request and configuration.
-func (cm *CORSMiddleware) createCORSResponse(corsReq *CORSRequest) *CORSResponse {
+func (p *corsPolicy) createCORSResponse(corsReq *CORSRequest) *CORSResponse {
 	corsResp := &CORSResponse{}
 
 	// Set Allow-Origin
-	if cm.config.AllowAllOrigins && !cm.config.AllowCredentials {
+	if p.config.AllowAllOrigins && !p.config.AllowCredentials {
 		corsResp.AllowOrigin = "*"
 	} else {
 		corsResp.AllowOrigin = corsReq.Origin
@@ -422,33 +641,39 @@ func (cm *CORSMiddleware) createCORSResponse(corsReq *CORSRequest) *CORSResponse
 
 	// Set Allow-Methods for preflight
 	if corsReq.IsPreflightReq {
-		corsResp.AllowMethods = strings.Join(cm.config.AllowedMethods, ", ")
+		corsResp.AllowMethods = strings.Join(p.config.AllowedMethods, ", ")
 	}
 
 	// Set Allow-Headers for preflight
-	if corsReq.IsPreflightReq && len(cm.config.AllowedHeaders) > 0 {
-		corsResp.AllowHeaders = strings.Join(cm.config.AllowedHeaders, ", ")
+	if corsReq.IsPreflightReq && len(p.config.AllowedHeaders) > 0 {
+		corsResp.AllowHeaders = strings.Join(p.config.AllowedHeaders, ", ")
 	}
 
 	// Set Expose-Headers
-	if len(cm.config.ExposedHeaders) > 0 {
-		corsResp.ExposeHeaders = strings.Join(cm.config.ExposedHeaders, ", ")
+	if len(p.config.ExposedHeaders) > 0 {
+		corsResp.ExposeHeaders = strings.Join(p.config.ExposedHeaders, ", ")
 	}
 
 	// Set Allow-Credentials
-	if cm.config.AllowCredentials {
+	if p.config.AllowCredentials {
 		corsResp.AllowCredentials = "true"
 	}
 
 	// Set Max-Age for preflight
-	if corsReq.IsPreflightReq && cm.config.MaxAge > 0 {
-		corsResp.MaxAge = fmt.Sprintf("%d", cm.config.MaxAge)
+	if corsReq.IsPreflightReq && p.config.MaxAge > 0 {
+		corsResp.MaxAge = fmt.Sprintf("%d", p.config.MaxAge)
 	}
 
-	// Set Vary headers
-	if cm.config.VaryByOrigin {
+	// Set Vary headers. Responses that reflect the origin must vary by it so
+	// that caches do not serve them to other origins, and preflight responses
+	// also depend on the requested method and headers.
+	if p.config.VaryByOrigin || corsResp.AllowOrigin != "*" {
 		corsResp.VaryHeaders = append(corsResp.VaryHeaders, "Origin")
 	}
+	if corsReq.IsPreflightReq {
+		corsResp.VaryHeaders = append(corsResp.VaryHeaders,
+			"Access-Control-Request-Method", "Access-Control-Request-Headers")
+	}
 
 	return corsResp
 }
@@ -532,6 +757,7 @@ func (cm *CORSMiddleware) Reset() {
 // AddAllowedOrigin adds an origin to the allowed origins list.
 func (cm *CORSMiddleware) AddAllowedOrigin(origin string) {
 	cm.config.AllowedOrigins = append(cm.config.AllowedOrigins, origin)
+	cm.compile()
 	cm.GetLogger().Info("Added allowed origin: %s", origin)
 }
 
@@ -540,6 +766,7 @@ func (cm *CORSMiddleware) RemoveAllowedOrigin(origin string) {
 	for i, allowedOrigin := range cm.config.AllowedOrigins {
 		if allowedOrigin == origin {
 			cm.config.AllowedOrigins = append(cm.config.AllowedOrigins[:i], cm.config.AllowedOrigins[i+1:]...)
+			cm.compile()
 			cm.GetLogger().Info("Removed allowed origin: %s", origin)
 			return
 		}
@@ -549,6 +776,7 @@ func (cm *CORSMiddleware) RemoveAllowedOrigin(origin string) {
 // SetAllowAllOrigins enables or disables allowing all origins.
 func (cm *CORSMiddleware) SetAllowAllOrigins(allow bool) {
 	cm.config.AllowAllOrigins = allow
+	cm.compile()
 	if allow {
 		cm.GetLogger().Info("Enabled allow all origins")
 	} else {
@@ -559,6 +787,7 @@ func (cm *CORSMiddleware) SetAllowAllOrigins(allow bool) {
 // SetAllowCredentials enables or disables credentials support.
 func (cm *CORSMiddleware) SetAllowCredentials(allow bool) {
 	cm.config.AllowCredentials = allow
+	cm.compile()
 	if allow {
 		cm.GetLogger().Info("Enabled credentials support")
 	} else {
@@ -2,6 +2,9 @@ package middlewares
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"strings"
 	"testing"
@@ -217,6 +220,7 @@ func TestCORSMiddleware_extractCORSRequest(t *testing.T) {
 				Origin:           "https://example.com",
 				Method:           "OPTIONS",
 				Path:             "/api/data",
+				RequestedMethod:  "POST",
 				RequestedHeaders: []string{"Content-Type", "Authorization"},
 				IsPreflightReq:   true,
 				IsCORSRequest:    true,
@@ -408,7 +412,7 @@ func TestCORSMiddleware_isOriginAllowed(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			cm := NewCORSMiddlewareWithConfig(1, tt.config)
-			got := cm.isOriginAllowed(tt.origin)
+			got := cm.policy.isOriginAllowed(tt.origin)
 			if got != tt.want {
 				t.Errorf("isOriginAllowed() = %v, want %v", got, tt.want)
 			}
@@ -416,9 +420,7 @@ func TestCORSMiddleware_isOriginAllowed(t *testing.T) {
 	}
 }
 
-func TestCORSMiddleware_matchOrigin(t *testing.T) {
-	cm := NewCORSMiddleware(1)
-
+func TestMatchOrigin(t *testing.T) {
 	tests := []struct {
 		name          string
 		origin        string
@@ -461,11 +463,41 @@ func TestCORSMiddleware_matchOrigin(t *testing.T) {
 			allowedOrigin: "https://allowed.com",
 			want:          false,
 		},
+		{
+			name:          "Scheme wildcard match",
+			origin:        "https://api.eu.example.com",
+			allowedOrigin: "https://*.example.com",
+			want:          true,
+		},
+		{
+			name:          "Scheme wildcard no match - different scheme",
+			origin:        "http://api.example.com",
+			allowedOrigin: "https://*.example.com",
+			want:          false,
+		},
+		{
+			name:          "Scheme wildcard no match - exact domain",
+			origin:        "https://example.com",
+			allowedOrigin: "https://*.example.com",
+			want:          false,
+		},
+		{
+			name:          "Port wildcard match",
+			origin:        "http://localhost:3000",
+			allowedOrigin: "http://localhost:*",
+			want:          true,
+		},
+		{
+			name:          "Wildcard does not match userinfo",
+			origin:        "https://evil.com@api.example.com",
+			allowedOrigin: "https://*.example.com",
+			want:          false,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := cm.matchOrigin(tt.origin, tt.allowedOrigin)
+			got := matchOrigin(tt.origin, tt.allowedOrigin)
 			if got != tt.want {
 				t.Errorf("matchOrigin() = %v, want %v", got, tt.want)
 			}
@@ -513,7 +545,7 @@ func TestCORSMiddleware_isMethodAllowed(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := cm.isMethodAllowed(tt.method)
+			got := cm.policy.isMethodAllowed(tt.method)
 			if got != tt.want {
 				t.Errorf("isMethodAllowed() = %v, want %v", got, tt.want)
 			}
@@ -566,7 +598,7 @@ func TestCORSMiddleware_isHeaderAllowed(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := cm.isHeaderAllowed(tt.header)
+			got := cm.policy.isHeaderAllowed(tt.header)
 			if got != tt.want {
 				t.Errorf("isHeaderAllowed() = %v, want %v", got, tt.want)
 			}
@@ -609,7 +641,7 @@ func TestCORSMiddleware_areHeadersAllowed(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := cm.areHeadersAllowed(tt.requestedHeaders)
+			got := cm.policy.areHeadersAllowed(tt.requestedHeaders)
 			if got != tt.want {
 				t.Errorf("areHeadersAllowed() = %v, want %v", got, tt.want)
 			}
@@ -744,7 +776,7 @@ func TestCORSMiddleware_createCORSResponse(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			cm := NewCORSMiddlewareWithConfig(1, tt.config)
-			got := cm.createCORSResponse(tt.corsReq)
+			got := cm.policy.createCORSResponse(tt.corsReq)
 			if !tt.check(got) {
 				t.Errorf("createCORSResponse() failed check for %s", tt.name)
 			}
@@ -1050,6 +1082,343 @@ func TestCORSMiddleware_Process(t *testing.T) {
 	}
 }
 
+func TestCORSMiddleware_OriginPatterns(t *testing.T) {
+	cm := NewCORSMiddlewareWithConfig(1, CORSConfig{
+		AllowedOriginPatterns: []string{`https://pr-[0-9]+\.preview\.example\.com`, "("},
+	})
+
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"https://pr-42.preview.example.com", true},
+		{"https://pr-abc.preview.example.com", false},
+		{"https://pr-42.preview.example.com.evil.com", false},
+		{"http://pr-42.preview.example.com", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			if got := cm.policy.isOriginAllowed(tt.origin); got != tt.want {
+				t.Errorf("isOriginAllowed(%q) = %v, want %v", tt.origin, got, tt.want)
+			}
+		})
+	}
+
+	if len(cm.policy.patterns) != 1 {
+		t.Errorf("invalid pattern should be ignored, got %d patterns", len(cm.policy.patterns))
+	}
+}
+
+func TestCORSConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  CORSConfig
+		wantErr error
+	}{
+		{
+			name:   "Default config",
+			config: DefaultCORSConfig(),
+		},
+		{
+			name: "Credentials with explicit origins",
+			config: CORSConfig{
+				AllowedOrigins:   []string{"https://app.example.com", "https://*.example.com"},
+				AllowCredentials: true,
+			},
+		},
+		{
+			name: "Credentials with wildcard origin",
+			config: CORSConfig{
+				AllowedOrigins:   []string{"https://app.example.com", "*"},
+				AllowCredentials: true,
+			},
+			wantErr: ErrCORSInsecurePolicy,
+		},
+		{
+			name: "Credentials with all origins",
+			config: CORSConfig{
+				AllowAllOrigins:  true,
+				AllowCredentials: true,
+			},
+			wantErr: ErrCORSInsecurePolicy,
+		},
+		{
+			name: "Invalid origin pattern",
+			config: CORSConfig{
+				AllowedOriginPatterns: []string{"https://[a-z"},
+			},
+			wantErr: ErrCORSInvalidOrigin,
+		},
+		{
+			name: "Origin with several wildcards",
+			config: CORSConfig{
+				AllowedOrigins: []string{"https://*.*.example.com"},
+			},
+			wantErr: ErrCORSInvalidOrigin,
+		},
+		{
+			name: "Relative route path",
+			config: CORSConfig{
+				Routes: []CORSRoute{{Path: "api", Config: DefaultCORSConfig()}},
+			},
+			wantErr: ErrCORSInvalidRoute,
+		},
+		{
+			name: "Insecure route policy",
+			config: CORSConfig{
+				AllowedOrigins: []string{"https://app.example.com"},
+				Routes: []CORSRoute{{
+					Path:   "/public",
+					Config: CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true},
+				}},
+			},
+			wantErr: ErrCORSInsecurePolicy,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewValidatedCORSMiddleware(t *testing.T) {
+	if _, err := NewValidatedCORSMiddleware(1, CORSConfig{AllowAllOrigins: true, AllowCredentials: true}); !errors.Is(err, ErrCORSInsecurePolicy) {
+		t.Errorf("NewValidatedCORSMiddleware() error = %v, want %v", err, ErrCORSInsecurePolicy)
+	}
+
+	cm, err := NewValidatedCORSMiddleware(1, DefaultCORSConfig())
+	if err != nil {
+		t.Fatalf("NewValidatedCORSMiddleware() error = %v", err)
+	}
+	if cm == nil || cm.Name() != "cors" {
+		t.Error("NewValidatedCORSMiddleware() returned an invalid middleware")
+	}
+}
+
+func TestCORSMiddleware_RoutePolicies(t *testing.T) {
+	config := CORSConfig{
+		AllowedOrigins: []string{"https://app.example.com"},
+		AllowedMethods: []string{"GET"},
+		Routes: []CORSRoute{
+			{
+				Path: "/public",
+				Config: CORSConfig{
+					AllowAllOrigins: true,
+					AllowedMethods:  []string{"GET", "POST"},
+				},
+			},
+			{
+				Path: "/public/admin/",
+				Config: CORSConfig{
+					AllowedOrigins:   []string{"https://admin.example.com"},
+					AllowedMethods:   []string{"GET", "DELETE"},
+					AllowCredentials: true,
+				},
+			},
+		},
+	}
+	cm := NewCORSMiddlewareWithConfig(1, config)
+
+	tests := []struct {
+		name       string
+		path       string
+		origin     string
+		method     string
+		wantStatus int
+		wantOrigin string
+	}{
+		{"Global policy allows app", "/api/users", "https://app.example.com", "GET", 204, "https://app.example.com"},
+		{"Global policy blocks other origins", "/api/users", "https://other.com", "GET", 403, ""},
+		{"Global policy blocks POST", "/api/users", "https://app.example.com", "POST", 403, ""},
+		{"Public route allows any origin", "/public/docs", "https://other.com", "POST", 204, "*"},
+		{"Route matches exact path", "/public", "https://other.com", "GET", 204, "*"},
+		{"Route does not match sibling prefix", "/publications", "https://other.com", "GET", 403, ""},
+		{"Most specific route wins", "/public/admin/users", "https://admin.example.com", "DELETE", 204, "https://admin.example.com"},
+		{"Most specific route blocks public origins", "/public/admin/users", "https://other.com", "GET", 403, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := map[string]interface{}{
+				"method": "OPTIONS",
+				"path":   tt.path,
+				"headers": map[string]string{
+					"Origin":                        tt.origin,
+					"Access-Control-Request-Method": tt.method,
+				},
+			}
+			next := func(ctx context.Context, req interface{}) (interface{}, error) {
+				t.Error("preflight should not call next")
+				return nil, nil
+			}
+
+			resp, err := cm.Process(context.Background(), req, next)
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+			httpResp := resp.(map[string]interface{})
+			if httpResp["status_code"] != tt.wantStatus {
+				t.Fatalf("status_code = %v, want %v", httpResp["status_code"], tt.wantStatus)
+			}
+			headers := httpResp["headers"].(map[string]string)
+			if got := headers["Access-Control-Allow-Origin"]; got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+		})
+	}
+}
+
+func TestCORSMiddleware_PreflightHeaders(t *testing.T) {
+	cm := NewCORSMiddlewareWithConfig(1, CORSConfig{
+		AllowedOrigins: []string{"https://app.example.com"},
+		AllowedMethods: []string{"GET", "PUT"},
+		AllowedHeaders: []string{"Authorization"},
+		MaxAge:         600,
+	})
+
+	req := map[string]interface{}{
+		"method": "OPTIONS",
+		"path":   "/api/users",
+		"headers": map[string]string{
+			"Origin":                         "https://app.example.com",
+			"Access-Control-Request-Method":  "PUT",
+			"Access-Control-Request-Headers": "authorization",
+		},
+	}
+	resp, err := cm.Process(context.Background(), req, nil)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	headers := resp.(map[string]interface{})["headers"].(map[string]string)
+	want := map[string]string{
+		"Access-Control-Allow-Origin":  "https://app.example.com",
+		"Access-Control-Allow-Methods": "GET, PUT",
+		"Access-Control-Allow-Headers": "Authorization",
+		"Access-Control-Max-Age":       "600",
+		"Vary":                         "Origin, Access-Control-Request-Method, Access-Control-Request-Headers",
+	}
+	for key, value := range want {
+		if headers[key] != value {
+			t.Errorf("%s = %q, want %q", key, headers[key], value)
+		}
+	}
+}
+
+func TestCORSMiddleware_VaryMerge(t *testing.T) {
+	cm := NewCORSMiddlewareWithConfig(1, CORSConfig{
+		AllowedOrigins: []string{"https://app.example.com"},
+	})
+
+	req := map[string]interface{}{
+		"method":  "GET",
+		"path":    "/api/users",
+		"headers": map[string]string{"Origin": "https://app.example.com"},
+	}
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return map[string]interface{}{
+			"status_code": 200,
+			"headers":     map[string]string{"Vary": "Accept-Encoding"},
+		}, nil
+	}
+
+	resp, err := cm.Process(context.Background(), req, next)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	headers := resp.(map[string]interface{})["headers"].(map[string]string)
+	if headers["Vary"] != "Accept-Encoding, Origin" {
+		t.Errorf("Vary = %q, want %q", headers["Vary"], "Accept-Encoding, Origin")
+	}
+}
+
+func TestCORSMiddleware_Handler(t *testing.T) {
+	cm := NewCORSMiddlewareWithConfig(1, CORSConfig{
+		AllowedOrigins:   []string{"https://*.example.com"},
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowCredentials: true,
+		MaxAge:           300,
+	})
+	handler := cm.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	t.Run("Preflight", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodOptions, "/api/users", nil)
+		req.Header.Set("Origin", "https://app.example.com")
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("status = %d, want %d", rec.Code, http.StatusNoContent)
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+			t.Errorf("Access-Control-Allow-Origin = %q", got)
+		}
+		if got := rec.Header().Get("Access-Control-Max-Age"); got != "300" {
+			t.Errorf("Access-Control-Max-Age = %q, want 300", got)
+		}
+		if got := rec.Header().Values("Vary"); len(got) != 3 {
+			t.Errorf("Vary = %v, want 3 values", got)
+		}
+	})
+
+	t.Run("Actual request", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/users", nil)
+		req.Header.Set("Origin", "https://app.example.com")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != "true" {
+			t.Errorf("Access-Control-Allow-Credentials = %q, want true", got)
+		}
+		if got := rec.Header().Values("Vary"); !reflect.DeepEqual(got, []string{"Origin", "Accept-Encoding"}) {
+			t.Errorf("Vary = %v", got)
+		}
+	})
+
+	t.Run("Blocked origin", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/users", nil)
+		req.Header.Set("Origin", "https://evil.com")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusForbidden)
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+			t.Errorf("Access-Control-Allow-Origin = %q, want empty", got)
+		}
+	})
+
+	t.Run("Same-origin request", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/users", nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+		}
+	})
+}
+
 // Benchmark tests
 func BenchmarkCORSMiddleware_isOriginAllowed(b *testing.B) {
 	config := CORSConfig{
@@ -1060,7 +1429,7 @@ func BenchmarkCORSMiddleware_isOriginAllowed(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		cm.isOriginAllowed(origin)
+		cm.policy.isOriginAllowed(origin)
 	}
 }
 
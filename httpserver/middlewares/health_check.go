@@ -17,8 +17,9 @@ type HealthCheckMiddleware struct {
 	config HealthCheckConfig
 
 	// Health checkers
-	checkers map[string]HealthChecker
-	mu       sync.RWMutex
+	checkers     map[string]HealthChecker
+	dependencies map[string][]string
+	mu           sync.RWMutex
 
 	// Metrics
 	totalChecks      int64
@@ -41,6 +42,9 @@ type HealthCheckConfig struct {
 	HealthPath    string
 	LivenessPath  string
 	ReadinessPath string
+	// TopologyPath serves the dependency graph of the checks with their
+	// status, for dashboards. Empty disables it.
+	TopologyPath string
 
 	// Check configuration
 	CheckInterval time.Duration
@@ -87,6 +91,13 @@ type HealthCheckResult struct {
 	Duration  time.Duration          `json:"duration"`
 	Timestamp time.Time              `json:"timestamp"`
 	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+
+	// Set when checks declare dependencies: the dependencies of the check,
+	// its status including theirs, and whether it was skipped because a
+	// critical dependency is unhealthy.
+	DependsOn       []string     `json:"depends_on,omitempty"`
+	EffectiveStatus HealthStatus `json:"effective_status,omitempty"`
+	Skipped         bool         `json:"skipped,omitempty"`
 }
 
 // HealthResult represents the overall health status.
@@ -142,6 +153,7 @@ func NewHealthCheckMiddleware(priority int) *HealthCheckMiddleware {
 		BaseMiddleware: NewBaseMiddleware("health_check", priority),
 		config:         DefaultHealthCheckConfig(),
 		checkers:       make(map[string]HealthChecker),
+		dependencies:   make(map[string][]string),
 		startTime:      time.Now(),
 	}
 }
@@ -152,6 +164,7 @@ func NewHealthCheckMiddlewareWithConfig(priority int, config HealthCheckConfig)
 		BaseMiddleware: NewBaseMiddleware("health_check", priority),
 		config:         config,
 		checkers:       make(map[string]HealthChecker),
+		dependencies:   make(map[string][]string),
 		startTime:      time.Now(),
 	}
 }
@@ -162,6 +175,7 @@ func DefaultHealthCheckConfig() HealthCheckConfig {
 		HealthPath:        "/health",
 		LivenessPath:      "/health/live",
 		ReadinessPath:     "/health/ready",
+		TopologyPath:      "/health/topology",
 		CheckInterval:     time.Second * 30,
 		CheckTimeout:      time.Second * 10,
 		CacheTimeout:      time.Second * 5,
@@ -211,18 +225,18 @@ func (hcm *HealthCheckMiddleware) SetConfig(config HealthCheckConfig) {
 	hcm.GetLogger().Info("Health check middleware configuration updated")
 }
 
-// AddChecker adds a health checker.
+// AddChecker adds a health checker. The dependencies of a
+// DependentHealthChecker are declared as with AddCheckerWithDependencies.
 func (hcm *HealthCheckMiddleware) AddChecker(checker HealthChecker) error {
 	if checker == nil {
 		return fmt.Errorf("checker cannot be nil")
 	}
 
-	hcm.mu.Lock()
-	hcm.checkers[checker.Name()] = checker
-	hcm.mu.Unlock()
-
-	hcm.GetLogger().Info("Health checker '%s' added", checker.Name())
-	return nil
+	var dependsOn []string
+	if dependent, ok := checker.(DependentHealthChecker); ok {
+		dependsOn = dependent.DependsOn()
+	}
+	return hcm.AddCheckerWithDependencies(checker, dependsOn...)
 }
 
 // RemoveChecker removes a health checker.
@@ -235,6 +249,7 @@ func (hcm *HealthCheckMiddleware) RemoveChecker(name string) error {
 	}
 
 	delete(hcm.checkers, name)
+	delete(hcm.dependencies, name)
 	hcm.GetLogger().Info("Health checker '%s' removed", name)
 	return nil
 }
@@ -299,16 +314,26 @@ func (hcm *HealthCheckMiddleware) extractPath(req interface{}) string {
 func (hcm *HealthCheckMiddleware) isHealthCheckPath(path string) bool {
 	return path == hcm.config.HealthPath ||
 		path == hcm.config.LivenessPath ||
-		path == hcm.config.ReadinessPath
+		path == hcm.config.ReadinessPath ||
+		(hcm.config.TopologyPath != "" && path == hcm.config.TopologyPath)
 }
 
 // handleHealthCheck handles health check requests.
 func (hcm *HealthCheckMiddleware) handleHealthCheck(ctx context.Context, path string) (interface{}, error) {
 	hcm.GetLogger().Debug("Handling health check request for path: %s", path)
 
+	if hcm.config.TopologyPath != "" && path == hcm.config.TopologyPath {
+		return hcm.createTopologyResponse(hcm.Topology(ctx)), nil
+	}
+	return hcm.createHealthResponse(hcm.healthResult(ctx, path)), nil
+}
+
+// healthResult returns the cached health check result, performing the
+// checks when it expired.
+func (hcm *HealthCheckMiddleware) healthResult(ctx context.Context, path string) *HealthResult {
 	// Check cache first
 	if cachedResult := hcm.getCachedResult(); cachedResult != nil {
-		return hcm.createHealthResponse(cachedResult), nil
+		return cachedResult
 	}
 
 	// Perform health checks
@@ -317,7 +342,7 @@ func (hcm *HealthCheckMiddleware) handleHealthCheck(ctx context.Context, path st
 	// Cache the result
 	hcm.setCachedResult(result)
 
-	return hcm.createHealthResponse(result), nil
+	return result
 }
 
 // getCachedResult retrieves cached health check result if valid.
@@ -367,14 +392,19 @@ func (hcm *HealthCheckMiddleware) performHealthChecks(ctx context.Context, path
 	}
 
 	// Run health checks
-	if hcm.config.ParallelChecks {
-		hcm.runParallelChecks(ctx, checkersToRun, result)
+	if hcm.hasDependencies() {
+		hcm.runDependencyChecks(ctx, checkersToRun, result)
+		result.Status = hcm.determineDependencyStatus(checkersToRun, result.Checks)
 	} else {
-		hcm.runSequentialChecks(ctx, checkersToRun, result)
-	}
+		if hcm.config.ParallelChecks {
+			hcm.runParallelChecks(ctx, checkersToRun, result)
+		} else {
+			hcm.runSequentialChecks(ctx, checkersToRun, result)
+		}
 
-	// Determine overall status
-	result.Status = hcm.determineOverallStatus(result.Checks)
+		// Determine overall status
+		result.Status = hcm.determineOverallStatus(result.Checks)
+	}
 	result.Duration = time.Since(startTime)
 
 	// Include metrics if configured
@@ -0,0 +1,392 @@
+package middlewares
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DependentHealthChecker is a HealthChecker depending on other checks, such as
+// a service check depending on the database and cache checks.
+type DependentHealthChecker interface {
+	HealthChecker
+	// DependsOn returns the names of the checks this check depends on.
+	DependsOn() []string
+}
+
+// HealthTopology is the dependency graph of the health checks with their
+// status, served on TopologyPath for dashboards.
+type HealthTopology struct {
+	Status    HealthStatus         `json:"status"`
+	Timestamp time.Time            `json:"timestamp"`
+	Nodes     []HealthTopologyNode `json:"nodes"`
+	Edges     []HealthTopologyEdge `json:"edges"`
+}
+
+// HealthTopologyNode is a check of the topology. Level is 0 for checks without
+// dependencies and one more than the deepest dependency otherwise.
+type HealthTopologyNode struct {
+	Name            string       `json:"name"`
+	Critical        bool         `json:"critical"`
+	Registered      bool         `json:"registered"`
+	Status          HealthStatus `json:"status"`
+	EffectiveStatus HealthStatus `json:"effective_status"`
+	Skipped         bool         `json:"skipped,omitempty"`
+	Level           int          `json:"level"`
+}
+
+// HealthTopologyEdge links a check to a check it depends on.
+type HealthTopologyEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// AddCheckerWithDependencies adds a health checker depending on the checks
+// with the given names, which may be added later.
+//
+// Once a check declares dependencies, checks run after their dependencies and
+// a check is skipped, and reported unhealthy, when a critical dependency is
+// unhealthy. A non-critical dependency that is not healthy degrades the
+// checks depending on it, and the overall status is unhealthy only when a
+// critical check is: otherwise problems report degraded. FailFast does not
+// apply.
+func (hcm *HealthCheckMiddleware) AddCheckerWithDependencies(checker HealthChecker, dependsOn ...string) error {
+	if checker == nil {
+		return fmt.Errorf("checker cannot be nil")
+	}
+
+	name := checker.Name()
+	hcm.mu.Lock()
+	if hcm.dependencies == nil {
+		hcm.dependencies = make(map[string][]string)
+	}
+	previous, hadPrevious := hcm.dependencies[name]
+	if len(dependsOn) > 0 {
+		hcm.dependencies[name] = slices.Clone(dependsOn)
+	} else {
+		delete(hcm.dependencies, name)
+	}
+	if cycle := findHealthDependencyCycle(hcm.dependencies); cycle != nil {
+		if hadPrevious {
+			hcm.dependencies[name] = previous
+		} else {
+			delete(hcm.dependencies, name)
+		}
+		hcm.mu.Unlock()
+		return fmt.Errorf("checker '%s' creates a dependency cycle: %v", name, cycle)
+	}
+	hcm.checkers[name] = checker
+	hcm.mu.Unlock()
+
+	if len(dependsOn) > 0 {
+		hcm.GetLogger().Info("Health checker '%s' added, depending on %v", name, dependsOn)
+	} else {
+		hcm.GetLogger().Info("Health checker '%s' added", name)
+	}
+	return nil
+}
+
+// Topology returns the dependency graph of the checks with the status of the
+// last health check, performing the checks when the cached result expired.
+func (hcm *HealthCheckMiddleware) Topology(ctx context.Context) *HealthTopology {
+	result := hcm.healthResult(ctx, hcm.config.HealthPath)
+
+	hcm.mu.RLock()
+	names := make([]string, 0, len(hcm.checkers))
+	critical := make(map[string]bool, len(hcm.checkers))
+	for name, checker := range hcm.checkers {
+		names = append(names, name)
+		critical[name] = checker.IsCritical()
+	}
+	dependencies := make(map[string][]string, len(hcm.dependencies))
+	for name, dependsOn := range hcm.dependencies {
+		dependencies[name] = dependsOn
+	}
+	hcm.mu.RUnlock()
+
+	// Dependencies never added are shown as unknown nodes
+	for _, dependsOn := range dependencies {
+		for _, dependency := range dependsOn {
+			if _, ok := critical[dependency]; !ok && !slices.Contains(names, dependency) {
+				names = append(names, dependency)
+			}
+		}
+	}
+	slices.Sort(names)
+	levels := healthCheckLevels(names, dependencies)
+
+	topology := &HealthTopology{
+		Status:    result.Status,
+		Timestamp: result.Timestamp,
+		Nodes:     make([]HealthTopologyNode, 0, len(names)),
+		Edges:     []HealthTopologyEdge{},
+	}
+	for _, name := range names {
+		_, registered := critical[name]
+		node := HealthTopologyNode{
+			Name:            name,
+			Critical:        critical[name],
+			Registered:      registered,
+			Status:          HealthStatusUnknown,
+			EffectiveStatus: HealthStatusUnknown,
+			Level:           levels[name],
+		}
+		if check, ok := result.Checks[name]; ok {
+			node.Status = check.Status
+			node.EffectiveStatus = check.Status
+			if check.EffectiveStatus != "" {
+				node.EffectiveStatus = check.EffectiveStatus
+			}
+			node.Skipped = check.Skipped
+		}
+		topology.Nodes = append(topology.Nodes, node)
+
+		for _, dependency := range dependencies[name] {
+			topology.Edges = append(topology.Edges, HealthTopologyEdge{From: name, To: dependency})
+		}
+	}
+	return topology
+}
+
+// hasDependencies reports whether any check declared dependencies.
+func (hcm *HealthCheckMiddleware) hasDependencies() bool {
+	hcm.mu.RLock()
+	defer hcm.mu.RUnlock()
+	return len(hcm.dependencies) > 0
+}
+
+// runDependencyChecks runs the checks level by level, so that dependencies
+// run first, skipping the checks with an unhealthy critical dependency.
+// Dependencies outside checkers, e.g. non-critical checks on the liveness
+// path, are ignored.
+func (hcm *HealthCheckMiddleware) runDependencyChecks(ctx context.Context, checkers []HealthChecker, result *HealthResult) {
+	byName := make(map[string]HealthChecker, len(checkers))
+	names := make([]string, 0, len(checkers))
+	for _, checker := range checkers {
+		byName[checker.Name()] = checker
+		names = append(names, checker.Name())
+	}
+	slices.Sort(names)
+
+	hcm.mu.RLock()
+	dependencies := make(map[string][]string, len(names))
+	for _, name := range names {
+		for _, dependency := range hcm.dependencies[name] {
+			if _, ok := byName[dependency]; ok {
+				dependencies[name] = append(dependencies[name], dependency)
+			}
+		}
+	}
+	hcm.mu.RUnlock()
+
+	levels := healthCheckLevels(names, dependencies)
+	depth := 0
+	for _, level := range levels {
+		if level+1 > depth {
+			depth = level + 1
+		}
+	}
+
+	for level := 0; level < depth; level++ {
+		var toRun []HealthChecker
+		for _, name := range names {
+			if levels[name] != level {
+				continue
+			}
+
+			var blockedBy []string
+			for _, dependency := range dependencies[name] {
+				if byName[dependency].IsCritical() && result.Checks[dependency].EffectiveStatus == HealthStatusUnhealthy {
+					blockedBy = append(blockedBy, dependency)
+				}
+			}
+			if len(blockedBy) > 0 {
+				result.Checks[name] = HealthCheckResult{
+					Name:            name,
+					Status:          HealthStatusUnhealthy,
+					Message:         fmt.Sprintf("Skipped: critical dependencies are unhealthy: %v", blockedBy),
+					Timestamp:       time.Now(),
+					Metadata:        map[string]interface{}{"blocked_by": blockedBy},
+					DependsOn:       dependencies[name],
+					EffectiveStatus: HealthStatusUnhealthy,
+					Skipped:         true,
+				}
+				continue
+			}
+			toRun = append(toRun, byName[name])
+		}
+
+		for _, checkResult := range hcm.runCheckLevel(ctx, toRun) {
+			name := checkResult.Name
+			checkResult.DependsOn = dependencies[name]
+			checkResult.EffectiveStatus = checkResult.Status
+			for _, dependency := range dependencies[name] {
+				if result.Checks[dependency].EffectiveStatus != HealthStatusHealthy {
+					checkResult.EffectiveStatus = worseHealthStatus(checkResult.EffectiveStatus, HealthStatusDegraded)
+				}
+			}
+			result.Checks[name] = checkResult
+		}
+	}
+}
+
+// runCheckLevel runs checks that do not depend on each other.
+func (hcm *HealthCheckMiddleware) runCheckLevel(ctx context.Context, checkers []HealthChecker) []HealthCheckResult {
+	results := make([]HealthCheckResult, len(checkers))
+	run := func(i int) {
+		checkCtx, cancel := context.WithTimeout(ctx, checkers[i].GetTimeout())
+		defer cancel()
+		results[i] = checkers[i].Check(checkCtx)
+		// The result is keyed by the checker name, which the graph refers to
+		results[i].Name = checkers[i].Name()
+	}
+
+	if !hcm.config.ParallelChecks {
+		for i := range checkers {
+			run(i)
+		}
+		return results
+	}
+
+	var wg sync.WaitGroup
+	for i := range checkers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			run(i)
+		}(i)
+	}
+	wg.Wait()
+	return results
+}
+
+// determineDependencyStatus determines the overall status from the effective
+// status of the checks: unhealthy when a critical check is unhealthy,
+// degraded when any other check is not healthy.
+func (hcm *HealthCheckMiddleware) determineDependencyStatus(checkers []HealthChecker, checks map[string]HealthCheckResult) HealthStatus {
+	status := HealthStatusHealthy
+	for _, checker := range checkers {
+		check, ok := checks[checker.Name()]
+		if !ok {
+			continue
+		}
+		effective := check.EffectiveStatus
+		if effective == HealthStatusUnhealthy && !checker.IsCritical() {
+			effective = HealthStatusDegraded
+		}
+		status = worseHealthStatus(status, effective)
+	}
+	return status
+}
+
+// createTopologyResponse creates an HTTP response for the topology.
+func (hcm *HealthCheckMiddleware) createTopologyResponse(topology *HealthTopology) interface{} {
+	jsonData, _ := json.MarshalIndent(topology, "", "  ")
+	return map[string]interface{}{
+		"status_code": hcm.config.SuccessStatusCode,
+		"headers": map[string]string{
+			"Content-Type":  "application/json",
+			"Cache-Control": "no-cache, no-store, must-revalidate",
+		},
+		"body": string(jsonData),
+	}
+}
+
+// worseHealthStatus returns the worse of two statuses; unknown counts as
+// degraded.
+func worseHealthStatus(a, b HealthStatus) HealthStatus {
+	severity := func(s HealthStatus) int {
+		switch s {
+		case HealthStatusHealthy:
+			return 0
+		case HealthStatusUnhealthy:
+			return 2
+		default:
+			return 1
+		}
+	}
+	if severity(b) > severity(a) {
+		if b == HealthStatusUnknown {
+			return HealthStatusDegraded
+		}
+		return b
+	}
+	return a
+}
+
+// healthCheckLevels returns the level of each name: 0 without dependencies
+// among names, one more than the deepest dependency otherwise. The graph
+// must be acyclic.
+func healthCheckLevels(names []string, dependencies map[string][]string) map[string]int {
+	known := make(map[string]bool, len(names))
+	for _, name := range names {
+		known[name] = true
+	}
+
+	levels := make(map[string]int, len(names))
+	var levelOf func(name string) int
+	levelOf = func(name string) int {
+		if level, ok := levels[name]; ok {
+			return level
+		}
+		level := 0
+		for _, dependency := range dependencies[name] {
+			if known[dependency] && levelOf(dependency)+1 > level {
+				level = levelOf(dependency) + 1
+			}
+		}
+		levels[name] = level
+		return level
+	}
+	for _, name := range names {
+		levelOf(name)
+	}
+	return levels
+}
+
+// findHealthDependencyCycle returns the names forming a dependency cycle, or
+// nil when the graph is acyclic.
+func findHealthDependencyCycle(dependencies map[string][]string) []string {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int, len(dependencies))
+	var path []string
+
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		switch state[name] {
+		case visiting:
+			start := slices.Index(path, name)
+			return append(slices.Clone(path[start:]), name)
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		path = append(path, name)
+		for _, dependency := range dependencies[name] {
+			if cycle := visit(dependency); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+
+	names := make([]string, 0, len(dependencies))
+	for name := range dependencies {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if cycle := visit(name); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
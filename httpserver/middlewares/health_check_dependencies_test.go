@@ -0,0 +1,165 @@
+package middlewares
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stubHealthChecker returns a fixed status and counts its runs.
+type stubHealthChecker struct {
+	name      string
+	status    HealthStatus
+	critical  bool
+	dependsOn []string
+	runs      atomic.Int32
+}
+
+func (s *stubHealthChecker) Check(ctx context.Context) HealthCheckResult {
+	s.runs.Add(1)
+	return HealthCheckResult{Name: s.name, Status: s.status, Timestamp: time.Now()}
+}
+
+func (s *stubHealthChecker) Name() string              { return s.name }
+func (s *stubHealthChecker) IsCritical() bool          { return s.critical }
+func (s *stubHealthChecker) GetTimeout() time.Duration { return time.Second }
+func (s *stubHealthChecker) DependsOn() []string       { return s.dependsOn }
+
+func newDependencyHealthCheck(t *testing.T, checkers ...*stubHealthChecker) *HealthCheckMiddleware {
+	t.Helper()
+	config := DefaultHealthCheckConfig()
+	config.CacheTimeout = 0
+	hcm := NewHealthCheckMiddlewareWithConfig(6, config)
+	for _, checker := range checkers {
+		if err := hcm.AddChecker(checker); err != nil {
+			t.Fatalf("AddChecker(%s) error = %v", checker.name, err)
+		}
+	}
+	return hcm
+}
+
+func TestHealthCheckDependencies_Status(t *testing.T) {
+	tests := []struct {
+		name    string
+		db      HealthStatus
+		cache   HealthStatus
+		want    HealthStatus
+		service HealthStatus
+		skipped bool
+	}{
+		{
+			name:    "All healthy",
+			db:      HealthStatusHealthy,
+			cache:   HealthStatusHealthy,
+			want:    HealthStatusHealthy,
+			service: HealthStatusHealthy,
+		},
+		{
+			name:    "Non-critical dependency down degrades",
+			db:      HealthStatusHealthy,
+			cache:   HealthStatusUnhealthy,
+			want:    HealthStatusDegraded,
+			service: HealthStatusDegraded,
+		},
+		{
+			name:    "Critical dependency down skips dependents",
+			db:      HealthStatusUnhealthy,
+			cache:   HealthStatusHealthy,
+			want:    HealthStatusUnhealthy,
+			service: HealthStatusUnhealthy,
+			skipped: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &stubHealthChecker{name: "db", status: tt.db, critical: true}
+			cache := &stubHealthChecker{name: "cache", status: tt.cache}
+			service := &stubHealthChecker{name: "service", status: HealthStatusHealthy, critical: true,
+				dependsOn: []string{"db", "cache"}}
+			hcm := newDependencyHealthCheck(t, service, db, cache)
+
+			result := hcm.performHealthChecks(context.Background(), "/health")
+			if result.Status != tt.want {
+				t.Errorf("Status = %v, want %v", result.Status, tt.want)
+			}
+			check := result.Checks["service"]
+			if check.EffectiveStatus != tt.service {
+				t.Errorf("service EffectiveStatus = %v, want %v", check.EffectiveStatus, tt.service)
+			}
+			if check.Skipped != tt.skipped {
+				t.Errorf("service Skipped = %v, want %v", check.Skipped, tt.skipped)
+			}
+			if runs := service.runs.Load(); (runs == 0) != tt.skipped {
+				t.Errorf("service ran %d times, skipped = %v", runs, tt.skipped)
+			}
+		})
+	}
+}
+
+func TestHealthCheckDependencies_Cycle(t *testing.T) {
+	hcm := newDependencyHealthCheck(t,
+		&stubHealthChecker{name: "a", dependsOn: []string{"b"}},
+		&stubHealthChecker{name: "b", dependsOn: []string{"c"}},
+	)
+
+	err := hcm.AddCheckerWithDependencies(&stubHealthChecker{name: "c"}, "a")
+	if err == nil {
+		t.Fatal("Expected a cycle error")
+	}
+	if _, err := hcm.GetChecker("c"); err == nil {
+		t.Error("Expected the checker creating the cycle not to be added")
+	}
+	if err := hcm.AddCheckerWithDependencies(&stubHealthChecker{name: "c"}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestHealthCheckDependencies_Topology(t *testing.T) {
+	hcm := newDependencyHealthCheck(t,
+		&stubHealthChecker{name: "db", status: HealthStatusUnhealthy, critical: true},
+		&stubHealthChecker{name: "service", status: HealthStatusHealthy, critical: true,
+			dependsOn: []string{"db", "search"}},
+	)
+
+	resp := hcm.createTopologyResponse(hcm.Topology(context.Background()))
+	var topology HealthTopology
+	if err := json.Unmarshal([]byte(resp.(map[string]interface{})["body"].(string)), &topology); err != nil {
+		t.Fatalf("Invalid topology JSON: %v", err)
+	}
+
+	if topology.Status != HealthStatusUnhealthy {
+		t.Errorf("Status = %v, want unhealthy", topology.Status)
+	}
+	want := []HealthTopologyNode{
+		{Name: "db", Critical: true, Registered: true, Status: HealthStatusUnhealthy, EffectiveStatus: HealthStatusUnhealthy},
+		{Name: "search", Status: HealthStatusUnknown, EffectiveStatus: HealthStatusUnknown},
+		{Name: "service", Critical: true, Registered: true, Status: HealthStatusUnhealthy,
+			EffectiveStatus: HealthStatusUnhealthy, Skipped: true, Level: 1},
+	}
+	if len(topology.Nodes) != len(want) {
+		t.Fatalf("Nodes = %+v, want %+v", topology.Nodes, want)
+	}
+	for i := range want {
+		if topology.Nodes[i] != want[i] {
+			t.Errorf("Node %d = %+v, want %+v", i, topology.Nodes[i], want[i])
+		}
+	}
+	if len(topology.Edges) != 2 || topology.Edges[0] != (HealthTopologyEdge{From: "service", To: "db"}) {
+		t.Errorf("Edges = %+v", topology.Edges)
+	}
+}
+
+func TestHealthCheckDependencies_TopologyPath(t *testing.T) {
+	hcm := newDependencyHealthCheck(t, &stubHealthChecker{name: "db", status: HealthStatusHealthy})
+
+	resp, err := hcm.Process(context.Background(), map[string]interface{}{"path": "/health/topology"}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if code := resp.(map[string]interface{})["status_code"]; code != 200 {
+		t.Errorf("status_code = %v, want 200", code)
+	}
+}
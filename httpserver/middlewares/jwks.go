@@ -0,0 +1,278 @@
+package middlewares
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// JWKSConfig defines configuration options for a remote JSON Web Key Set.
+type JWKSConfig struct {
+	// URL of the key set, e.g. https://issuer/.well-known/jwks.json.
+	URL string
+
+	// HTTPClient used to fetch the key set. Defaults to a client with Timeout.
+	HTTPClient *http.Client
+	Timeout    time.Duration
+
+	// RefreshInterval is how often Start refreshes the keys in background.
+	RefreshInterval time.Duration
+
+	// MinRefreshInterval limits the refreshes triggered by unknown key IDs,
+	// so tokens with random kids cannot flood the issuer.
+	MinRefreshInterval time.Duration
+}
+
+// JWKS caches the signing keys of a JSON Web Key Set. Keys are fetched on
+// first use, refreshed periodically by Start and refetched when a token is
+// signed by an unknown key, which picks up rotated keys without a restart.
+type JWKS struct {
+	config JWKSConfig
+
+	mu   sync.RWMutex
+	keys map[string]crypto.PublicKey
+	// attemptedAt is the start of the last fetch, successful or not, so an
+	// unreachable issuer is not hit on every unknown kid
+	attemptedAt time.Time
+
+	// refreshMu serializes fetches so concurrent misses share one request
+	refreshMu sync.Mutex
+
+	stop chan struct{}
+	done chan struct{}
+	now  func() time.Time
+}
+
+// jsonWebKey is a key of a JSON Web Key Set (RFC 7517).
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// NewJWKS creates a key set cache. Empty fields are filled with the defaults.
+func NewJWKS(config JWKSConfig) *JWKS {
+	if config.Timeout == 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: config.Timeout}
+	}
+	if config.RefreshInterval == 0 {
+		config.RefreshInterval = time.Hour
+	}
+	if config.MinRefreshInterval == 0 {
+		config.MinRefreshInterval = time.Minute
+	}
+
+	return &JWKS{
+		config: config,
+		keys:   make(map[string]crypto.PublicKey),
+		now:    time.Now,
+	}
+}
+
+// Start fetches the keys and refreshes them every RefreshInterval until Stop
+// is called or the context is done. Failed refreshes keep the cached keys.
+func (j *JWKS) Start(ctx context.Context) {
+	j.mu.Lock()
+	if j.stop != nil {
+		j.mu.Unlock()
+		return
+	}
+	j.stop = make(chan struct{})
+	j.done = make(chan struct{})
+	stop, done := j.stop, j.done
+	j.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(j.config.RefreshInterval)
+		defer ticker.Stop()
+
+		j.Refresh(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-stop:
+				return
+			case <-ticker.C:
+				j.Refresh(ctx)
+			}
+		}
+	}()
+}
+
+// Stop stops the background refresh started by Start.
+func (j *JWKS) Stop() {
+	j.mu.Lock()
+	stop, done := j.stop, j.done
+	j.stop, j.done = nil, nil
+	j.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+// Key returns the key with the given ID, refreshing the key set when the
+// key is unknown and the last refresh attempt is older than
+// MinRefreshInterval.
+func (j *JWKS) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	if key, ok := j.cachedKey(kid); ok {
+		return key, nil
+	}
+
+	j.refreshMu.Lock()
+	defer j.refreshMu.Unlock()
+
+	// Another request may have refreshed the keys while waiting
+	if key, ok := j.cachedKey(kid); ok {
+		return key, nil
+	}
+
+	j.mu.RLock()
+	attemptedAt := j.attemptedAt
+	j.mu.RUnlock()
+
+	if attemptedAt.IsZero() || j.now().Sub(attemptedAt) >= j.config.MinRefreshInterval {
+		if err := j.fetch(ctx); err != nil {
+			return nil, err
+		}
+		if key, ok := j.cachedKey(kid); ok {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: kid %q", ErrJWTKeyNotFound, kid)
+}
+
+// Refresh fetches the key set, replacing the cached keys on success.
+func (j *JWKS) Refresh(ctx context.Context) error {
+	j.refreshMu.Lock()
+	defer j.refreshMu.Unlock()
+	return j.fetch(ctx)
+}
+
+// cachedKey looks a key up in the cache. A token without kid matches the only
+// key of a single-key set.
+func (j *JWKS) cachedKey(kid string) (crypto.PublicKey, bool) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	if key, exists := j.keys[kid]; exists {
+		return key, true
+	}
+	if kid == "" && len(j.keys) == 1 {
+		for _, key := range j.keys {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+// fetch downloads and parses the key set. Callers must hold refreshMu.
+func (j *JWKS) fetch(ctx context.Context) error {
+	j.mu.Lock()
+	j.attemptedAt = j.now()
+	j.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.config.URL, nil)
+	if err != nil {
+		return fmt.Errorf("jwks request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := j.config.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("jwks fetch: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("jwks fetch: unexpected status %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("jwks decode: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		// Keys of unsupported types are skipped so one odd key does not
+		// invalidate the whole set
+		if key, err := jwk.publicKey(); err == nil {
+			keys[jwk.Kid] = key
+		}
+	}
+
+	j.mu.Lock()
+	j.keys = keys
+	j.mu.Unlock()
+	return nil
+}
+
+// publicKey converts the JWK into an RSA or P-256 ECDSA public key.
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != 32 || len(y) != 32 {
+			return nil, errors.New("invalid P-256 coordinates")
+		}
+		// Rejects points that are not on the curve
+		if _, err := ecdh.P256().NewPublicKey(append(append([]byte{4}, x...), y...)); err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
@@ -0,0 +1,229 @@
+package middlewares
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testJWKSServer serves a mutable key set and counts the fetches.
+type testJWKSServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	keys    []map[string]string
+	fetches int64
+}
+
+func newTestJWKSServer(t *testing.T) *testJWKSServer {
+	t.Helper()
+	s := &testJWKSServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&s.fetches, 1)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": s.keys})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *testJWKSServer) setKeys(keys ...map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = keys
+}
+
+func rsaJWK(kid string, key *rsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PublicKey) map[string]string {
+	x := make([]byte, 32)
+	y := make([]byte, 32)
+	key.X.FillBytes(x)
+	key.Y.FillBytes(y)
+	return map[string]string{
+		"kty": "EC",
+		"kid": kid,
+		"crv": "P-256",
+		"x":   base64.RawURLEncoding.EncodeToString(x),
+		"y":   base64.RawURLEncoding.EncodeToString(y),
+	}
+}
+
+func TestJWKS_Key(t *testing.T) {
+	rsaKey := newTestRSAKey(t)
+	ecKey := newTestECKey(t)
+	server := newTestJWKSServer(t)
+	server.setKeys(
+		rsaJWK("rsa-1", &rsaKey.PublicKey),
+		ecJWK("ec-1", &ecKey.PublicKey),
+		map[string]string{"kty": "RSA", "kid": "enc", "use": "enc"},
+		map[string]string{"kty": "OKP", "kid": "ed"},
+	)
+
+	jwks := NewJWKS(JWKSConfig{URL: server.URL})
+	ctx := context.Background()
+
+	key, err := jwks.Key(ctx, "rsa-1")
+	if err != nil {
+		t.Fatalf("Key(rsa-1) error = %v", err)
+	}
+	if pub, ok := key.(*rsa.PublicKey); !ok || !pub.Equal(&rsaKey.PublicKey) {
+		t.Errorf("Key(rsa-1) = %v", key)
+	}
+
+	key, err = jwks.Key(ctx, "ec-1")
+	if err != nil {
+		t.Fatalf("Key(ec-1) error = %v", err)
+	}
+	if pub, ok := key.(*ecdsa.PublicKey); !ok || !pub.Equal(&ecKey.PublicKey) {
+		t.Errorf("Key(ec-1) = %v", key)
+	}
+
+	if fetches := atomic.LoadInt64(&server.fetches); fetches != 1 {
+		t.Errorf("fetches = %d, want 1 for cached keys", fetches)
+	}
+
+	for _, kid := range []string{"enc", "ed"} {
+		if _, err := jwks.Key(ctx, kid); !errors.Is(err, ErrJWTKeyNotFound) {
+			t.Errorf("Key(%s) error = %v, want %v", kid, err, ErrJWTKeyNotFound)
+		}
+	}
+}
+
+func TestJWKS_Rotation(t *testing.T) {
+	oldKey := newTestRSAKey(t)
+	newKey := newTestRSAKey(t)
+	server := newTestJWKSServer(t)
+	server.setKeys(rsaJWK("old", &oldKey.PublicKey))
+
+	now := time.Unix(1700000000, 0)
+	jwks := NewJWKS(JWKSConfig{URL: server.URL, MinRefreshInterval: time.Minute})
+	jwks.now = func() time.Time { return now }
+
+	jm := NewJWTMiddleware(1, jwks)
+	jm.now = func() time.Time { return now }
+	ctx := context.Background()
+	claims := map[string]interface{}{"sub": "user-1", "exp": now.Add(time.Hour).Unix()}
+
+	if _, err := jm.Verify(ctx, signJWT(t, "RS256", "old", oldKey, claims)); err != nil {
+		t.Fatalf("Verify(old) error = %v", err)
+	}
+
+	// The issuer rotates its key; unknown kids are rate limited
+	server.setKeys(rsaJWK("new", &newKey.PublicKey))
+	if _, err := jm.Verify(ctx, signJWT(t, "RS256", "new", newKey, claims)); !errors.Is(err, ErrJWTKeyNotFound) {
+		t.Fatalf("Verify(new) before MinRefreshInterval error = %v, want %v", err, ErrJWTKeyNotFound)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := jm.Verify(ctx, signJWT(t, "RS256", "new", newKey, claims)); err != nil {
+		t.Fatalf("Verify(new) error = %v", err)
+	}
+	if _, err := jm.Verify(ctx, signJWT(t, "RS256", "old", oldKey, claims)); !errors.Is(err, ErrJWTKeyNotFound) {
+		t.Errorf("Verify(old) after rotation error = %v, want %v", err, ErrJWTKeyNotFound)
+	}
+	if fetches := atomic.LoadInt64(&server.fetches); fetches != 2 {
+		t.Errorf("fetches = %d, want 2", fetches)
+	}
+}
+
+func TestJWKS_StartStop(t *testing.T) {
+	key := newTestRSAKey(t)
+	server := newTestJWKSServer(t)
+	server.setKeys(rsaJWK("rsa-1", &key.PublicKey))
+
+	jwks := NewJWKS(JWKSConfig{URL: server.URL, RefreshInterval: 10 * time.Millisecond})
+	jwks.Start(context.Background())
+
+	deadline := time.Now().Add(2 * time.Second)
+	for atomic.LoadInt64(&server.fetches) < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	jwks.Stop()
+
+	if fetches := atomic.LoadInt64(&server.fetches); fetches < 3 {
+		t.Fatalf("fetches = %d, want background refreshes", fetches)
+	}
+	if _, ok := jwks.cachedKey("rsa-1"); !ok {
+		t.Error("key not cached by background refresh")
+	}
+
+	fetches := atomic.LoadInt64(&server.fetches)
+	time.Sleep(30 * time.Millisecond)
+	if atomic.LoadInt64(&server.fetches) != fetches {
+		t.Error("refresh continued after Stop")
+	}
+}
+
+func TestJWKS_RefreshError(t *testing.T) {
+	key := newTestRSAKey(t)
+	server := newTestJWKSServer(t)
+	server.setKeys(rsaJWK("rsa-1", &key.PublicKey))
+
+	jwks := NewJWKS(JWKSConfig{URL: server.URL})
+	ctx := context.Background()
+	if err := jwks.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	server.Close()
+	if err := jwks.Refresh(ctx); err == nil {
+		t.Error("Refresh() with server down should fail")
+	}
+	if _, err := jwks.Key(ctx, "rsa-1"); err != nil {
+		t.Errorf("Key() after failed refresh error = %v, want cached key", err)
+	}
+}
+
+func TestJWKS_UnknownKidWhileIssuerDown(t *testing.T) {
+	var fetches int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&fetches, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+
+	now := time.Unix(1700000000, 0)
+	jwks := NewJWKS(JWKSConfig{URL: server.URL, MinRefreshInterval: time.Minute})
+	jwks.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if _, err := jwks.Key(ctx, "unknown"); err == nil {
+		t.Fatal("Key() with issuer down should fail")
+	}
+	// Failed attempts are rate limited like successful ones
+	for range 5 {
+		if _, err := jwks.Key(ctx, "unknown"); !errors.Is(err, ErrJWTKeyNotFound) {
+			t.Fatalf("Key() error = %v, want %v", err, ErrJWTKeyNotFound)
+		}
+	}
+	if n := atomic.LoadInt64(&fetches); n != 1 {
+		t.Errorf("fetches = %d, want 1 within MinRefreshInterval", n)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := jwks.Key(ctx, "unknown"); err == nil || errors.Is(err, ErrJWTKeyNotFound) {
+		t.Errorf("Key() after MinRefreshInterval error = %v, want fetch error", err)
+	}
+	if n := atomic.LoadInt64(&fetches); n != 2 {
+		t.Errorf("fetches = %d, want 2", n)
+	}
+}
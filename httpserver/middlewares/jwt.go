@@ -0,0 +1,585 @@
+package middlewares
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	domaininterfaces "github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+// JWT validation errors. The domain errors returned by the middleware wrap
+// them, so errors.Is can be used to tell failures apart.
+var (
+	ErrJWTMissing          = errors.New("jwt missing")
+	ErrJWTMalformed        = errors.New("jwt malformed")
+	ErrJWTAlgorithmInvalid = errors.New("jwt algorithm not allowed")
+	ErrJWTKeyNotFound      = errors.New("jwt signing key not found")
+	ErrJWTSignatureInvalid = errors.New("jwt signature invalid")
+	ErrJWTExpired          = errors.New("jwt expired")
+	ErrJWTNotYetValid      = errors.New("jwt not yet valid")
+	ErrJWTIssuerInvalid    = errors.New("jwt issuer invalid")
+	ErrJWTAudienceInvalid  = errors.New("jwt audience invalid")
+)
+
+// Supported JWT signing algorithms.
+const (
+	JWTAlgorithmRS256 = "RS256"
+	JWTAlgorithmES256 = "ES256"
+	JWTAlgorithmHS256 = "HS256"
+)
+
+// jwtClaimsKey is the context key of the validated claims.
+type jwtClaimsKey struct{}
+
+// JWTMiddleware validates bearer JWTs and injects their claims into the
+// request context. It also implements AuthProvider, so it can be added to
+// an AuthMiddleware.
+type JWTMiddleware struct {
+	*BaseMiddleware
+
+	// Configuration, replaced as a whole by SetConfig
+	config atomic.Pointer[JWTConfig]
+
+	// Metrics
+	totalRequests    int64
+	acceptedRequests int64
+	rejectedRequests int64
+
+	// Internal state
+	startTime time.Time
+	now       func() time.Time
+}
+
+// JWTConfig defines configuration options for the JWT middleware.
+type JWTConfig struct {
+	// Algorithms accepted in the token header. "none" is never accepted.
+	Algorithms []string
+
+	// Keys. Secret verifies HS256; KeySet resolves RS256 and ES256 keys by
+	// kid, falling back to Keys, which maps kids to *rsa.PublicKey or
+	// *ecdsa.PublicKey values.
+	Secret []byte
+	KeySet *JWKS
+	Keys   map[string]crypto.PublicKey
+
+	// Claim checks. Audience accepts tokens with any of the values, ClockSkew
+	// tolerates clock differences with the issuer and RequireExpiration, set
+	// by DefaultJWTConfig, rejects tokens without exp.
+	Issuer            string
+	Audience          []string
+	ClockSkew         time.Duration
+	RequireExpiration bool
+
+	// Token extraction
+	Header     string
+	Scheme     string
+	QueryParam string
+
+	// SkipPaths are not authenticated.
+	SkipPaths []string
+
+	// Claims used to build the AuthUser of the AuthProvider methods. The
+	// roles claim is a string array, or a string holding a single role.
+	UsernameClaim string
+	RolesClaim    string
+}
+
+// JWTClaims holds the claims of a validated token.
+type JWTClaims struct {
+	Subject   string
+	Issuer    string
+	Audience  []string
+	ID        string
+	ExpiresAt time.Time
+	NotBefore time.Time
+	IssuedAt  time.Time
+
+	// Raw holds every claim of the token, registered ones included.
+	Raw map[string]interface{}
+}
+
+// jwtHeader is the JOSE header of a token.
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ"`
+}
+
+// NewJWTMiddleware creates a new JWT middleware verifying RS256 and ES256
+// tokens with the keys of a JWKS endpoint.
+func NewJWTMiddleware(priority int, keySet *JWKS) *JWTMiddleware {
+	config := DefaultJWTConfig()
+	config.KeySet = keySet
+	return NewJWTMiddlewareWithConfig(priority, config)
+}
+
+// NewJWTMiddlewareWithConfig creates a new JWT middleware with custom
+// configuration. Empty fields are filled with the defaults.
+func NewJWTMiddlewareWithConfig(priority int, config JWTConfig) *JWTMiddleware {
+	jm := &JWTMiddleware{
+		BaseMiddleware: NewBaseMiddleware("jwt", priority),
+		startTime:      time.Now(),
+		now:            time.Now,
+	}
+	config = withJWTDefaults(config)
+	jm.config.Store(&config)
+	return jm
+}
+
+// DefaultJWTConfig returns a default JWT configuration.
+func DefaultJWTConfig() JWTConfig {
+	return JWTConfig{
+		Algorithms:        []string{JWTAlgorithmRS256, JWTAlgorithmES256},
+		ClockSkew:         time.Minute,
+		RequireExpiration: true,
+		Header:            "Authorization",
+		Scheme:            "Bearer",
+		SkipPaths:         []string{"/health", "/metrics"},
+		UsernameClaim:     "preferred_username",
+		RolesClaim:        "roles",
+	}
+}
+
+// withJWTDefaults fills empty configuration fields.
+func withJWTDefaults(config JWTConfig) JWTConfig {
+	defaults := DefaultJWTConfig()
+	if len(config.Algorithms) == 0 {
+		if len(config.Secret) > 0 && config.KeySet == nil && len(config.Keys) == 0 {
+			config.Algorithms = []string{JWTAlgorithmHS256}
+		} else {
+			config.Algorithms = defaults.Algorithms
+		}
+	}
+	if config.Header == "" {
+		config.Header = defaults.Header
+	}
+	if config.Scheme == "" {
+		config.Scheme = defaults.Scheme
+	}
+	if config.UsernameClaim == "" {
+		config.UsernameClaim = defaults.UsernameClaim
+	}
+	if config.RolesClaim == "" {
+		config.RolesClaim = defaults.RolesClaim
+	}
+	return config
+}
+
+// JWTClaimsFromContext returns the claims injected by the JWT middleware.
+func JWTClaimsFromContext(ctx context.Context) (*JWTClaims, bool) {
+	claims, ok := ctx.Value(jwtClaimsKey{}).(*JWTClaims)
+	return claims, ok
+}
+
+// ContextWithJWTClaims returns a context carrying claims, for transports
+// verifying tokens with Verify outside the middleware, such as gRPC.
+func ContextWithJWTClaims(ctx context.Context, claims *JWTClaims) context.Context {
+	return context.WithValue(ctx, jwtClaimsKey{}, claims)
+}
+
+// Process implements the Middleware interface for JWT validation.
+func (jm *JWTMiddleware) Process(ctx context.Context, req interface{}, next MiddlewareNext) (interface{}, error) {
+	if !jm.IsEnabled() {
+		return next(ctx, req)
+	}
+
+	var path string
+	var headers, query map[string]string
+	if httpReq, ok := req.(map[string]interface{}); ok {
+		path, _ = httpReq["path"].(string)
+		headers, _ = httpReq["headers"].(map[string]string)
+		query, _ = httpReq["query"].(map[string]string)
+	}
+
+	config := jm.config.Load()
+	if shouldSkipJWT(config, path) {
+		return next(ctx, req)
+	}
+
+	claims, err := jm.verify(ctx, config, extractJWT(config, headers, query))
+	if err != nil {
+		return nil, err
+	}
+	return next(ContextWithJWTClaims(ctx, claims), req)
+}
+
+// Handler wraps a net/http handler, validating the token before calling it.
+// Rejected requests receive the domain error as JSON with its HTTP status.
+func (jm *JWTMiddleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		config := jm.config.Load()
+		if !jm.IsEnabled() || shouldSkipJWT(config, r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		headers := map[string]string{config.Header: r.Header.Get(config.Header)}
+		var query map[string]string
+		if config.QueryParam != "" {
+			query = map[string]string{config.QueryParam: r.URL.Query().Get(config.QueryParam)}
+		}
+
+		claims, err := jm.verify(r.Context(), config, extractJWT(config, headers, query))
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`%s error="invalid_token"`, config.Scheme))
+			WriteProblem(w, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ContextWithJWTClaims(r.Context(), claims)))
+	})
+}
+
+// Verify validates the signature and claims of a token.
+func (jm *JWTMiddleware) Verify(ctx context.Context, token string) (*JWTClaims, error) {
+	return jm.verify(ctx, jm.config.Load(), token)
+}
+
+// verify validates a token against a configuration snapshot.
+func (jm *JWTMiddleware) verify(ctx context.Context, config *JWTConfig, token string) (*JWTClaims, error) {
+	atomic.AddInt64(&jm.totalRequests, 1)
+
+	if token == "" {
+		return nil, jm.reject(ErrJWTMissing, "JWT_MISSING")
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, jm.reject(ErrJWTMalformed, "JWT_MALFORMED")
+	}
+
+	var header jwtHeader
+	if err := decodeJWTSegment(parts[0], &header); err != nil {
+		return nil, jm.reject(ErrJWTMalformed, "JWT_MALFORMED")
+	}
+	if !isJWTAlgorithmAllowed(config, header.Alg) {
+		return nil, jm.reject(fmt.Errorf("%w: %q", ErrJWTAlgorithmInvalid, header.Alg), "JWT_ALGORITHM_INVALID")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, jm.reject(ErrJWTMalformed, "JWT_MALFORMED")
+	}
+	if err := jm.verifySignature(ctx, config, header, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		if errors.Is(err, ErrJWTKeyNotFound) {
+			return nil, jm.reject(err, "JWT_KEY_NOT_FOUND")
+		}
+		return nil, jm.reject(err, "JWT_SIGNATURE_INVALID")
+	}
+
+	raw := make(map[string]interface{})
+	if err := decodeJWTSegment(parts[1], &raw); err != nil {
+		return nil, jm.reject(ErrJWTMalformed, "JWT_MALFORMED")
+	}
+	claims, err := parseJWTClaims(raw)
+	if err != nil {
+		return nil, jm.reject(err, "JWT_MALFORMED")
+	}
+	if err := jm.validateClaims(config, claims); err != nil {
+		code := "JWT_CLAIMS_INVALID"
+		switch {
+		case errors.Is(err, ErrJWTExpired):
+			code = "JWT_EXPIRED"
+		case errors.Is(err, ErrJWTNotYetValid):
+			code = "JWT_NOT_YET_VALID"
+		case errors.Is(err, ErrJWTIssuerInvalid):
+			code = "JWT_ISSUER_INVALID"
+		case errors.Is(err, ErrJWTAudienceInvalid):
+			code = "JWT_AUDIENCE_INVALID"
+		}
+		return nil, jm.reject(err, code)
+	}
+
+	atomic.AddInt64(&jm.acceptedRequests, 1)
+	return claims, nil
+}
+
+// Authenticate implements AuthProvider. Credentials must be the raw token.
+func (jm *JWTMiddleware) Authenticate(ctx context.Context, credentials interface{}) (*AuthUser, error) {
+	token, ok := credentials.(string)
+	if !ok {
+		return nil, jm.reject(ErrJWTMissing, "JWT_MISSING")
+	}
+	return jm.ValidateToken(ctx, token)
+}
+
+// ValidateToken implements AuthProvider, mapping the claims to an AuthUser.
+func (jm *JWTMiddleware) ValidateToken(ctx context.Context, token string) (*AuthUser, error) {
+	config := jm.config.Load()
+	claims, err := jm.verify(ctx, config, token)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &AuthUser{
+		ID:        claims.Subject,
+		Username:  claims.Subject,
+		Metadata:  claims.Raw,
+		ExpiresAt: claims.ExpiresAt,
+	}
+	if username, ok := claims.Raw[config.UsernameClaim].(string); ok && username != "" {
+		user.Username = username
+	}
+	if email, ok := claims.Raw["email"].(string); ok {
+		user.Email = email
+	}
+	user.Roles = claimStrings(claims.Raw[config.RolesClaim])
+	return user, nil
+}
+
+// GetName implements AuthProvider.
+func (jm *JWTMiddleware) GetName() string {
+	return jm.Name()
+}
+
+// GetConfig returns the current JWT configuration.
+func (jm *JWTMiddleware) GetConfig() JWTConfig {
+	return *jm.config.Load()
+}
+
+// SetConfig updates the JWT configuration, e.g. to rotate keys. It is safe
+// to call while requests are verified: each request uses the configuration
+// it started with.
+func (jm *JWTMiddleware) SetConfig(config JWTConfig) {
+	config = withJWTDefaults(config)
+	jm.config.Store(&config)
+	jm.GetLogger().Info("JWT middleware configuration updated")
+}
+
+// GetMetrics returns JWT metrics.
+func (jm *JWTMiddleware) GetMetrics() map[string]interface{} {
+	return map[string]interface{}{
+		"total_requests":    atomic.LoadInt64(&jm.totalRequests),
+		"accepted_requests": atomic.LoadInt64(&jm.acceptedRequests),
+		"rejected_requests": atomic.LoadInt64(&jm.rejectedRequests),
+		"uptime":            time.Since(jm.startTime),
+	}
+}
+
+// Reset resets all metrics.
+func (jm *JWTMiddleware) Reset() {
+	atomic.StoreInt64(&jm.totalRequests, 0)
+	atomic.StoreInt64(&jm.acceptedRequests, 0)
+	atomic.StoreInt64(&jm.rejectedRequests, 0)
+	jm.startTime = time.Now()
+	jm.GetLogger().Info("JWT middleware metrics reset")
+}
+
+// shouldSkipJWT determines if authentication should be skipped for a path.
+func shouldSkipJWT(config *JWTConfig, path string) bool {
+	for _, skipPath := range config.SkipPaths {
+		if path == skipPath {
+			return true
+		}
+	}
+	return false
+}
+
+// extractJWT returns the token of the auth header or of the query parameter.
+func extractJWT(config *JWTConfig, headers, query map[string]string) string {
+	if value := headerValue(headers, config.Header); value != "" {
+		scheme, token, found := strings.Cut(value, " ")
+		if found && strings.EqualFold(scheme, config.Scheme) {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+	if config.QueryParam != "" {
+		return query[config.QueryParam]
+	}
+	return ""
+}
+
+// isJWTAlgorithmAllowed checks the token algorithm against the configuration.
+func isJWTAlgorithmAllowed(config *JWTConfig, alg string) bool {
+	for _, allowed := range config.Algorithms {
+		if alg == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// verifySignature checks the signature with the key of the algorithm. Key
+// types are checked against the algorithm to prevent algorithm confusion.
+func (jm *JWTMiddleware) verifySignature(ctx context.Context, config *JWTConfig, header jwtHeader, signingInput, signature []byte) error {
+	digest := sha256.Sum256(signingInput)
+
+	if header.Alg == JWTAlgorithmHS256 {
+		if len(config.Secret) == 0 {
+			return ErrJWTKeyNotFound
+		}
+		mac := hmac.New(sha256.New, config.Secret)
+		mac.Write(signingInput)
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return ErrJWTSignatureInvalid
+		}
+		return nil
+	}
+
+	key, err := jm.resolveKey(ctx, config, header.Kid)
+	if err != nil {
+		return err
+	}
+
+	switch header.Alg {
+	case JWTAlgorithmRS256:
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w: key %q is not an RSA key", ErrJWTSignatureInvalid, header.Kid)
+		}
+		if err := rsa.VerifyPKCS1v15(rsaKey, crypto.SHA256, digest[:], signature); err != nil {
+			return ErrJWTSignatureInvalid
+		}
+		return nil
+	case JWTAlgorithmES256:
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok || ecKey.Curve.Params().Name != "P-256" {
+			return fmt.Errorf("%w: key %q is not a P-256 key", ErrJWTSignatureInvalid, header.Kid)
+		}
+		// JWS encodes ES256 signatures as the 32-byte R and S concatenated
+		if len(signature) != 64 {
+			return ErrJWTSignatureInvalid
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(ecKey, digest[:], r, s) {
+			return ErrJWTSignatureInvalid
+		}
+		return nil
+	}
+	return ErrJWTAlgorithmInvalid
+}
+
+// resolveKey returns the public key of a kid from the key set or the static keys.
+func (jm *JWTMiddleware) resolveKey(ctx context.Context, config *JWTConfig, kid string) (crypto.PublicKey, error) {
+	if key, exists := config.Keys[kid]; exists {
+		return key, nil
+	}
+	if config.KeySet != nil {
+		key, err := config.KeySet.Key(ctx, kid)
+		if err != nil && !errors.Is(err, ErrJWTKeyNotFound) {
+			jm.GetLogger().Error("JWKS refresh failed: %v", err)
+			return nil, fmt.Errorf("%w: %v", ErrJWTKeyNotFound, err)
+		}
+		return key, err
+	}
+	return nil, fmt.Errorf("%w: kid %q", ErrJWTKeyNotFound, kid)
+}
+
+// validateClaims checks the time, issuer and audience claims.
+func (jm *JWTMiddleware) validateClaims(config *JWTConfig, claims *JWTClaims) error {
+	now := jm.now()
+	skew := config.ClockSkew
+
+	if claims.ExpiresAt.IsZero() {
+		if config.RequireExpiration {
+			return fmt.Errorf("%w: missing exp claim", ErrJWTExpired)
+		}
+	} else if now.After(claims.ExpiresAt.Add(skew)) {
+		return ErrJWTExpired
+	}
+	if !claims.NotBefore.IsZero() && now.Add(skew).Before(claims.NotBefore) {
+		return ErrJWTNotYetValid
+	}
+	if !claims.IssuedAt.IsZero() && now.Add(skew).Before(claims.IssuedAt) {
+		return fmt.Errorf("%w: issued in the future", ErrJWTNotYetValid)
+	}
+
+	if config.Issuer != "" && claims.Issuer != config.Issuer {
+		return fmt.Errorf("%w: %q", ErrJWTIssuerInvalid, claims.Issuer)
+	}
+
+	if len(config.Audience) > 0 {
+		for _, expected := range config.Audience {
+			for _, audience := range claims.Audience {
+				if audience == expected {
+					return nil
+				}
+			}
+		}
+		return fmt.Errorf("%w: %v", ErrJWTAudienceInvalid, claims.Audience)
+	}
+	return nil
+}
+
+// reject counts a rejected token and creates its domain error.
+func (jm *JWTMiddleware) reject(cause error, code string) error {
+	atomic.AddInt64(&jm.rejectedRequests, 1)
+	jm.GetLogger().Warn("JWT rejected: %v", cause)
+	return domainerrors.New(domaininterfaces.AuthenticationError, code, cause.Error()).Wrap(cause)
+}
+
+// decodeJWTSegment decodes a base64url JSON segment of a token.
+func decodeJWTSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// parseJWTClaims extracts the registered claims.
+func parseJWTClaims(raw map[string]interface{}) (*JWTClaims, error) {
+	claims := &JWTClaims{Raw: raw}
+	claims.Subject, _ = raw["sub"].(string)
+	claims.Issuer, _ = raw["iss"].(string)
+	claims.ID, _ = raw["jti"].(string)
+
+	switch aud := raw["aud"].(type) {
+	case nil:
+	case string, []interface{}:
+		claims.Audience = claimStrings(aud)
+	default:
+		return nil, fmt.Errorf("%w: invalid aud claim", ErrJWTMalformed)
+	}
+
+	for name, target := range map[string]*time.Time{
+		"exp": &claims.ExpiresAt,
+		"nbf": &claims.NotBefore,
+		"iat": &claims.IssuedAt,
+	} {
+		value, exists := raw[name]
+		if !exists {
+			continue
+		}
+		seconds, ok := value.(float64)
+		if !ok || math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+			return nil, fmt.Errorf("%w: invalid %s claim", ErrJWTMalformed, name)
+		}
+		*target = time.Unix(int64(seconds), 0)
+	}
+	return claims, nil
+}
+
+// claimStrings converts a string or string array claim into a slice. A
+// string is a single value (RFC 7519 §4.1.3), even when it has spaces.
+func claimStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
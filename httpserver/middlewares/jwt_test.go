@@ -0,0 +1,437 @@
+package middlewares
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	domaininterfaces "github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+// signJWT builds a token signed with an RSA, ECDSA or HMAC key.
+func signJWT(t *testing.T, alg, kid string, key interface{}, claims map[string]interface{}) string {
+	t.Helper()
+
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(input))
+
+	var signature []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		var err error
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatalf("SignPKCS1v15() error = %v", err)
+		}
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		if err != nil {
+			t.Fatalf("ecdsa.Sign() error = %v", err)
+		}
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(input))
+		signature = mac.Sum(nil)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func newTestRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey() error = %v", err)
+	}
+	return key
+}
+
+func newTestECKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey() error = %v", err)
+	}
+	return key
+}
+
+func TestNewJWTMiddlewareWithConfig(t *testing.T) {
+	jm := NewJWTMiddlewareWithConfig(2, JWTConfig{Secret: []byte("secret")})
+
+	if jm.Name() != "jwt" || jm.Priority() != 2 {
+		t.Errorf("NewJWTMiddlewareWithConfig() name = %s, priority = %d", jm.Name(), jm.Priority())
+	}
+
+	config := jm.GetConfig()
+	if len(config.Algorithms) != 1 || config.Algorithms[0] != JWTAlgorithmHS256 {
+		t.Errorf("Algorithms = %v, want [HS256] for secret-only config", config.Algorithms)
+	}
+	if config.Header != "Authorization" || config.Scheme != "Bearer" {
+		t.Errorf("Header = %q, Scheme = %q", config.Header, config.Scheme)
+	}
+
+	jm = NewJWTMiddleware(1, NewJWKS(JWKSConfig{URL: "http://localhost/jwks"}))
+	config = jm.GetConfig()
+	if len(config.Algorithms) != 2 || config.KeySet == nil {
+		t.Errorf("NewJWTMiddleware() Algorithms = %v, KeySet = %v", config.Algorithms, config.KeySet)
+	}
+}
+
+func TestJWTMiddleware_Verify(t *testing.T) {
+	rsaKey := newTestRSAKey(t)
+	ecKey := newTestECKey(t)
+	otherRSAKey := newTestRSAKey(t)
+	secret := []byte("hs256-secret")
+	now := time.Unix(1700000000, 0)
+
+	config := JWTConfig{
+		Algorithms: []string{JWTAlgorithmRS256, JWTAlgorithmES256, JWTAlgorithmHS256},
+		Secret:     secret,
+		Keys: map[string]crypto.PublicKey{
+			"rsa": &rsaKey.PublicKey,
+			"ec":  &ecKey.PublicKey,
+		},
+		Issuer:            "https://issuer.example.com",
+		Audience:          []string{"api"},
+		ClockSkew:         30 * time.Second,
+		RequireExpiration: true,
+	}
+
+	validClaims := func() map[string]interface{} {
+		return map[string]interface{}{
+			"sub": "user-1",
+			"iss": "https://issuer.example.com",
+			"aud": []string{"web", "api"},
+			"exp": now.Add(time.Minute).Unix(),
+			"iat": now.Unix(),
+		}
+	}
+	with := func(key string, value interface{}) map[string]interface{} {
+		claims := validClaims()
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+		return claims
+	}
+
+	tests := []struct {
+		name     string
+		token    string
+		wantErr  error
+		wantCode string
+	}{
+		{"RS256", signJWT(t, "RS256", "rsa", rsaKey, validClaims()), nil, ""},
+		{"ES256", signJWT(t, "ES256", "ec", ecKey, validClaims()), nil, ""},
+		{"HS256", signJWT(t, "HS256", "", secret, validClaims()), nil, ""},
+		{"Audience as string", signJWT(t, "RS256", "rsa", rsaKey, with("aud", "api")), nil, ""},
+		{"Expired within skew", signJWT(t, "RS256", "rsa", rsaKey, with("exp", now.Add(-20*time.Second).Unix())), nil, ""},
+		{"Missing token", "", ErrJWTMissing, "JWT_MISSING"},
+		{"Malformed token", "not-a-jwt", ErrJWTMalformed, "JWT_MALFORMED"},
+		{"Algorithm none", signJWT(t, "none", "", nil, validClaims()), ErrJWTAlgorithmInvalid, "JWT_ALGORITHM_INVALID"},
+		{"Wrong key", signJWT(t, "RS256", "rsa", otherRSAKey, validClaims()), ErrJWTSignatureInvalid, "JWT_SIGNATURE_INVALID"},
+		{"Unknown kid", signJWT(t, "RS256", "other", rsaKey, validClaims()), ErrJWTKeyNotFound, "JWT_KEY_NOT_FOUND"},
+		{"Algorithm confusion", signJWT(t, "ES256", "rsa", ecKey, validClaims()), ErrJWTSignatureInvalid, "JWT_SIGNATURE_INVALID"},
+		{"Wrong secret", signJWT(t, "HS256", "", []byte("other"), validClaims()), ErrJWTSignatureInvalid, "JWT_SIGNATURE_INVALID"},
+		{"Expired", signJWT(t, "RS256", "rsa", rsaKey, with("exp", now.Add(-time.Minute).Unix())), ErrJWTExpired, "JWT_EXPIRED"},
+		{"Missing exp", signJWT(t, "RS256", "rsa", rsaKey, with("exp", nil)), ErrJWTExpired, "JWT_EXPIRED"},
+		{"Not yet valid", signJWT(t, "RS256", "rsa", rsaKey, with("nbf", now.Add(time.Minute).Unix())), ErrJWTNotYetValid, "JWT_NOT_YET_VALID"},
+		{"Wrong issuer", signJWT(t, "RS256", "rsa", rsaKey, with("iss", "https://evil.com")), ErrJWTIssuerInvalid, "JWT_ISSUER_INVALID"},
+		{"Wrong audience", signJWT(t, "RS256", "rsa", rsaKey, with("aud", "other")), ErrJWTAudienceInvalid, "JWT_AUDIENCE_INVALID"},
+		{"Audience with spaces", signJWT(t, "RS256", "rsa", rsaKey, with("aud", "other api")), ErrJWTAudienceInvalid, "JWT_AUDIENCE_INVALID"},
+		{"Invalid exp", signJWT(t, "RS256", "rsa", rsaKey, with("exp", "tomorrow")), ErrJWTMalformed, "JWT_MALFORMED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jm := NewJWTMiddlewareWithConfig(1, config)
+			jm.now = func() time.Time { return now }
+
+			claims, err := jm.Verify(context.Background(), tt.token)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("Verify() error = %v", err)
+				}
+				if claims.Subject != "user-1" || claims.Issuer != "https://issuer.example.com" {
+					t.Errorf("Verify() claims = %+v", claims)
+				}
+				return
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			var domainErr domaininterfaces.DomainErrorInterface
+			if !errors.As(err, &domainErr) {
+				t.Fatalf("Verify() error %T is not a domain error", err)
+			}
+			if domainErr.Type() != domaininterfaces.AuthenticationError || domainErr.Code() != tt.wantCode {
+				t.Errorf("Verify() error type = %s, code = %s, want %s", domainErr.Type(), domainErr.Code(), tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestJWTMiddleware_Process(t *testing.T) {
+	secret := []byte("secret")
+	jm := NewJWTMiddlewareWithConfig(1, JWTConfig{Secret: secret, QueryParam: "access_token", SkipPaths: []string{"/health"}})
+	token := signJWT(t, "HS256", "", secret, map[string]interface{}{
+		"sub":   "user-1",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": []string{"admin"},
+	})
+
+	tests := []struct {
+		name    string
+		req     map[string]interface{}
+		wantErr bool
+		wantSub string
+	}{
+		{
+			name: "Bearer header",
+			req: map[string]interface{}{
+				"path":    "/api/users",
+				"headers": map[string]string{"authorization": "bearer " + token},
+			},
+			wantSub: "user-1",
+		},
+		{
+			name: "Query parameter",
+			req: map[string]interface{}{
+				"path":  "/api/users",
+				"query": map[string]string{"access_token": token},
+			},
+			wantSub: "user-1",
+		},
+		{
+			name: "Skip path",
+			req: map[string]interface{}{
+				"path": "/health",
+			},
+		},
+		{
+			name: "Wrong scheme",
+			req: map[string]interface{}{
+				"path":    "/api/users",
+				"headers": map[string]string{"Authorization": "Basic " + token},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Missing token",
+			req: map[string]interface{}{
+				"path": "/api/users",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotSub string
+			next := func(ctx context.Context, req interface{}) (interface{}, error) {
+				if claims, ok := JWTClaimsFromContext(ctx); ok {
+					gotSub = claims.Subject
+				}
+				return "ok", nil
+			}
+
+			_, err := jm.Process(context.Background(), tt.req, next)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Process() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotSub != tt.wantSub {
+				t.Errorf("claims subject = %q, want %q", gotSub, tt.wantSub)
+			}
+		})
+	}
+}
+
+func TestJWTMiddleware_Handler(t *testing.T) {
+	secret := []byte("secret")
+	jm := NewJWTMiddlewareWithConfig(1, JWTConfig{Secret: secret})
+	handler := jm.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := JWTClaimsFromContext(r.Context())
+		if !ok {
+			t.Error("claims not found in context")
+			return
+		}
+		w.Write([]byte(claims.Subject))
+	}))
+
+	token := signJWT(t, "HS256", "", secret, map[string]interface{}{
+		"sub": "user-1",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	req := httptest.NewRequest(http.MethodGet, "/api/users", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK || rec.Body.String() != "user-1" {
+		t.Errorf("status = %d, body = %q", rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/users", nil)
+	req.Header.Set("Authorization", "Bearer "+token+"x")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	if rec.Header().Get("WWW-Authenticate") == "" {
+		t.Error("WWW-Authenticate header not set")
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body["code"] != "JWT_SIGNATURE_INVALID" {
+		t.Errorf("body = %s", rec.Body.String())
+	}
+}
+
+func TestJWTMiddleware_SetConfigConcurrent(t *testing.T) {
+	secret := []byte("secret")
+	jm := NewJWTMiddlewareWithConfig(1, JWTConfig{Secret: secret})
+	handler := jm.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	token := signJWT(t, "HS256", "", secret, map[string]interface{}{
+		"sub": "user-1",
+		"iss": "issuer",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+
+	// The configuration is replaced while tokens are verified; every
+	// configuration accepts the token
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := range 100 {
+			config := jm.GetConfig()
+			config.Issuer = ""
+			if i%2 == 0 {
+				config.Issuer = "issuer"
+			}
+			config.ClockSkew = time.Duration(i) * time.Second
+			jm.SetConfig(config)
+		}
+	}()
+	for range 100 {
+		req := httptest.NewRequest(http.MethodGet, "/api/users", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("status = %d during reconfiguration, body = %s", rec.Code, rec.Body.String())
+		}
+	}
+	wg.Wait()
+}
+
+func TestJWTMiddleware_ValidateToken(t *testing.T) {
+	secret := []byte("secret")
+	jm := NewJWTMiddlewareWithConfig(1, JWTConfig{Secret: secret})
+	token := signJWT(t, "HS256", "", secret, map[string]interface{}{
+		"sub":                "user-1",
+		"preferred_username": "jane",
+		"email":              "jane@example.com",
+		"roles":              []string{"admin", "user"},
+		"exp":                time.Now().Add(time.Hour).Unix(),
+	})
+
+	user, err := jm.ValidateToken(context.Background(), token)
+	if err != nil {
+		t.Fatalf("ValidateToken() error = %v", err)
+	}
+	if user.ID != "user-1" || user.Username != "jane" || user.Email != "jane@example.com" {
+		t.Errorf("ValidateToken() user = %+v", user)
+	}
+	if len(user.Roles) != 2 || user.Roles[0] != "admin" {
+		t.Errorf("ValidateToken() roles = %v", user.Roles)
+	}
+
+	if _, err := jm.Authenticate(context.Background(), 42); !errors.Is(err, ErrJWTMissing) {
+		t.Errorf("Authenticate() error = %v, want %v", err, ErrJWTMissing)
+	}
+}
+
+func TestAuthMiddleware_JWT(t *testing.T) {
+	config := DefaultAuthConfig()
+	config.EnableBasicAuth = false
+	config.EnableBearerAuth = false
+	config.EnableJWTAuth = true
+	config.JWTSecret = "secret"
+	am := NewAuthMiddlewareWithConfig(1, config)
+
+	if _, err := am.GetProvider("jwt"); err != nil {
+		t.Fatalf("GetProvider(jwt) error = %v", err)
+	}
+
+	token := signJWT(t, "HS256", "", []byte("secret"), map[string]interface{}{
+		"sub": "user-1",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	req := map[string]interface{}{
+		"path":    "/api/users",
+		"headers": map[string]string{"Authorization": "Bearer " + token},
+	}
+
+	var user *AuthUser
+	_, err := am.Process(context.Background(), req, func(ctx context.Context, req interface{}) (interface{}, error) {
+		user, _ = ctx.Value("user").(*AuthUser)
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if user == nil || user.ID != "user-1" {
+		t.Errorf("Process() user = %+v", user)
+	}
+}
+
+func TestJWTMiddleware_Reset(t *testing.T) {
+	jm := NewJWTMiddlewareWithConfig(1, JWTConfig{Secret: []byte("secret")})
+	jm.Verify(context.Background(), "")
+
+	metrics := jm.GetMetrics()
+	if metrics["total_requests"].(int64) != 1 || metrics["rejected_requests"].(int64) != 1 {
+		t.Errorf("GetMetrics() = %v", metrics)
+	}
+
+	jm.Reset()
+	metrics = jm.GetMetrics()
+	if metrics["total_requests"].(int64) != 0 || metrics["rejected_requests"].(int64) != 0 {
+		t.Errorf("Reset() did not reset metrics: %v", metrics)
+	}
+}
+
+func TestClaimStrings(t *testing.T) {
+	if got := claimStrings("https://api.example.com orders"); len(got) != 1 || got[0] != "https://api.example.com orders" {
+		t.Errorf("claimStrings(string) = %q, want a single value", got)
+	}
+	if got := claimStrings([]interface{}{"web", "api", 1}); len(got) != 2 || got[0] != "web" || got[1] != "api" {
+		t.Errorf("claimStrings(array) = %q", got)
+	}
+	if got := claimStrings(42); got != nil {
+		t.Errorf("claimStrings(number) = %q, want nil", got)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/fsvxavier/nexs-lib/clock"
 )
 
 // RateLimitMiddleware provides rate limiting functionality.
@@ -28,7 +30,7 @@ type RateLimitMiddleware struct {
 
 	// Internal state
 	startTime     time.Time
-	cleanupTicker *time.Ticker
+	cleanupTicker clock.Ticker
 	stopCleanup   chan bool
 }
 
@@ -66,6 +68,9 @@ type RateLimitConfig struct {
 	CleanupInterval time.Duration
 	MemoryLimit     int64
 
+	// Clock measures windows, refills and cleanups. Defaults to clock.Real().
+	Clock clock.Clock
+
 	// Response configuration
 	RetryAfterHeader bool
 	IncludeHeaders   bool
@@ -102,6 +107,7 @@ type RateLimiter struct {
 	key      string
 	config   RateLimit
 	strategy RateLimitStrategy
+	clock    clock.Clock
 
 	// Token bucket fields
 	tokens     float64
@@ -154,7 +160,7 @@ func NewRateLimitMiddlewareWithConfig(priority int, config RateLimitConfig) *Rat
 		BaseMiddleware: NewBaseMiddleware("rate_limit", priority),
 		config:         config,
 		limiters:       make(map[string]*RateLimiter),
-		startTime:      time.Now(),
+		startTime:      clock.OrReal(config.Clock).Now(),
 		stopCleanup:    make(chan bool, 1),
 	}
 
@@ -283,7 +289,7 @@ func (rlm *RateLimitMiddleware) GetMetrics() map[string]interface{} {
 		"blocked_rate":     blockedRate,
 		"active_limiters":  activeLimiters,
 		"reset_count":      atomic.LoadInt64(&rlm.resetCount),
-		"uptime":           time.Since(rlm.startTime),
+		"uptime":           rlm.clock().Since(rlm.startTime),
 	}
 }
 
@@ -435,7 +441,7 @@ func (rlm *RateLimitMiddleware) getLimiter(key string, reqInfo *RateLimitRequest
 
 	// Create new limiter
 	rateLimit := rlm.getRateLimitForRequest(reqInfo)
-	limiter = NewRateLimiter(key, rateLimit, rlm.config.Strategy)
+	limiter = NewRateLimiterWithClock(key, rateLimit, rlm.config.Strategy, rlm.clock())
 
 	rlm.mu.Lock()
 	rlm.limiters[key] = limiter
@@ -511,7 +517,7 @@ func (rlm *RateLimitMiddleware) addRateLimitHeaders(resp interface{}, rateLimitC
 // startCleanupRoutine starts the cleanup routine for expired limiters.
 func (rlm *RateLimitMiddleware) startCleanupRoutine() {
 	if rlm.config.CleanupInterval > 0 {
-		rlm.cleanupTicker = time.NewTicker(rlm.config.CleanupInterval)
+		rlm.cleanupTicker = rlm.clock().NewTicker(rlm.config.CleanupInterval)
 		go rlm.cleanupRoutine()
 	}
 }
@@ -520,7 +526,7 @@ func (rlm *RateLimitMiddleware) startCleanupRoutine() {
 func (rlm *RateLimitMiddleware) cleanupRoutine() {
 	for {
 		select {
-		case <-rlm.cleanupTicker.C:
+		case <-rlm.cleanupTicker.C():
 			rlm.cleanup()
 		case <-rlm.stopCleanup:
 			rlm.cleanupTicker.Stop()
@@ -534,7 +540,7 @@ func (rlm *RateLimitMiddleware) cleanup() {
 	rlm.mu.Lock()
 	defer rlm.mu.Unlock()
 
-	now := time.Now()
+	now := rlm.clock().Now()
 	expiredKeys := []string{}
 
 	for key, limiter := range rlm.limiters {
@@ -571,10 +577,15 @@ func (rlm *RateLimitMiddleware) Reset() {
 	rlm.limiters = make(map[string]*RateLimiter)
 	rlm.mu.Unlock()
 
-	rlm.startTime = time.Now()
+	rlm.startTime = rlm.clock().Now()
 	rlm.GetLogger().Info("Rate limit middleware metrics and limiters reset")
 }
 
+// clock returns the configured clock.
+func (rlm *RateLimitMiddleware) clock() clock.Clock {
+	return clock.OrReal(rlm.config.Clock)
+}
+
 // Stop stops the cleanup routine.
 func (rlm *RateLimitMiddleware) Stop() {
 	if rlm.cleanupTicker != nil {
@@ -587,15 +598,23 @@ func (rlm *RateLimitMiddleware) Stop() {
 
 // NewRateLimiter creates a new rate limiter.
 func NewRateLimiter(key string, config RateLimit, strategy RateLimitStrategy) *RateLimiter {
+	return NewRateLimiterWithClock(key, config, strategy, clock.Real())
+}
+
+// NewRateLimiterWithClock creates a new rate limiter measuring time with c.
+func NewRateLimiterWithClock(key string, config RateLimit, strategy RateLimitStrategy, c clock.Clock) *RateLimiter {
+	c = clock.OrReal(c)
+	now := c.Now()
 	return &RateLimiter{
 		key:         key,
 		config:      config,
 		strategy:    strategy,
+		clock:       c,
 		tokens:      float64(config.BurstSize),
-		lastRefill:  time.Now(),
-		windowStart: time.Now(),
+		lastRefill:  now,
+		windowStart: now,
 		requests:    make([]time.Time, 0),
-		lastReset:   time.Now(),
+		lastReset:   now,
 	}
 }
 
@@ -604,7 +623,7 @@ func (rl *RateLimiter) checkLimit() *RateLimitContext {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
-	now := time.Now()
+	now := rl.clock.Now()
 
 	switch rl.strategy {
 	case TokenBucket:
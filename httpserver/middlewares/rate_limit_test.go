@@ -6,6 +6,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/fsvxavier/nexs-lib/clock"
 )
 
 func TestNewRateLimitMiddleware(t *testing.T) {
@@ -839,7 +841,8 @@ func TestRateLimiter_checkTokenBucket(t *testing.T) {
 		BurstSize:         5,   // Allow burst of 5
 	}
 
-	limiter := NewRateLimiter("test", config, TokenBucket)
+	fake := clock.NewFake(time.Time{})
+	limiter := NewRateLimiterWithClock("test", config, TokenBucket, fake)
 
 	// Test initial requests (should be allowed due to burst capacity)
 	for i := 0; i < 5; i++ {
@@ -855,8 +858,8 @@ func TestRateLimiter_checkTokenBucket(t *testing.T) {
 		t.Error("Request should be blocked after burst capacity exhausted")
 	}
 
-	// Wait and test token refill
-	time.Sleep(time.Millisecond * 600) // Wait for ~1.2 tokens to be added
+	// Advance the clock and test token refill
+	fake.Advance(time.Millisecond * 600) // ~1.2 tokens are added
 	ctx = limiter.checkLimit()
 	if !ctx.Allowed {
 		t.Error("Request should be allowed after token refill")
@@ -927,6 +930,59 @@ func TestRateLimiter_checkSlidingWindow(t *testing.T) {
 	}
 }
 
+func TestRateLimiter_checkSlidingWindowExpiry(t *testing.T) {
+	fake := clock.NewFake(time.Time{})
+	limiter := NewRateLimiterWithClock("test", RateLimit{RequestsPerMinute: 2}, SlidingWindow, fake)
+
+	limiter.checkLimit()
+	fake.Advance(30 * time.Second)
+	limiter.checkLimit()
+
+	ctx := limiter.checkLimit()
+	if ctx.Allowed {
+		t.Fatal("Third request in the window should be blocked")
+	}
+	if ctx.RetryAfter != 30*time.Second {
+		t.Errorf("RetryAfter = %v, want %v", ctx.RetryAfter, 30*time.Second)
+	}
+
+	// The first request leaves the window after a minute
+	fake.Advance(30*time.Second + time.Millisecond)
+	if ctx := limiter.checkLimit(); !ctx.Allowed {
+		t.Error("Request should be allowed once the oldest request left the window")
+	}
+	if ctx := limiter.checkLimit(); ctx.Allowed {
+		t.Error("Request should be blocked while the window is full")
+	}
+}
+
+func TestRateLimitMiddleware_CleanupWithClock(t *testing.T) {
+	fake := clock.NewFake(time.Time{})
+	config := DefaultRateLimitConfig()
+	config.CleanupInterval = time.Minute
+	config.Clock = fake
+	rlm := NewRateLimitMiddlewareWithConfig(1, config)
+	defer rlm.Stop()
+
+	rlm.getLimiter("client", &RateLimitRequestInfo{})
+	fake.BlockUntil(1)
+	fake.Advance(3 * time.Minute)
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		rlm.mu.RLock()
+		remaining := len(rlm.limiters)
+		rlm.mu.RUnlock()
+		if remaining == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Idle limiter was not cleaned up")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
 func TestRateLimitMiddleware_createRateLimitErrorResponse(t *testing.T) {
 	config := DefaultRateLimitConfig()
 	config.ErrorStatusCode = 429
@@ -0,0 +1,455 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	domaininterfaces "github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+// Webhook verification errors. The domain errors returned by the middleware
+// wrap them, so errors.Is can be used to tell failures apart.
+var (
+	ErrWebhookSignatureMissing = errors.New("webhook signature missing")
+	ErrWebhookSignatureInvalid = errors.New("webhook signature invalid")
+	ErrWebhookTimestampInvalid = errors.New("webhook timestamp invalid")
+	ErrWebhookTimestampExpired = errors.New("webhook timestamp outside tolerance")
+	ErrWebhookBodyTooLarge     = errors.New("webhook body too large")
+)
+
+// WebhookScheme defines how signatures are encoded in the request headers.
+type WebhookScheme string
+
+const (
+	// WebhookSchemeHex expects a hex HMAC in SignatureHeader, optionally
+	// prefixed by SignaturePrefix (e.g. GitHub's "sha256=").
+	WebhookSchemeHex WebhookScheme = "hex"
+	// WebhookSchemeBase64 expects a base64 HMAC in SignatureHeader, optionally
+	// prefixed by SignaturePrefix.
+	WebhookSchemeBase64 WebhookScheme = "base64"
+	// WebhookSchemeStripe expects "t=<unix>,v1=<hex>[,v1=<hex>]" in
+	// SignatureHeader, signing "<timestamp>.<body>".
+	WebhookSchemeStripe WebhookScheme = "stripe"
+	// WebhookSchemeStandard follows the Standard Webhooks specification:
+	// "v1,<base64> ..." in webhook-signature, signing "<id>.<timestamp>.<body>".
+	WebhookSchemeStandard WebhookScheme = "standard"
+)
+
+// WebhookMiddleware verifies HMAC signatures of incoming webhooks and rejects
+// replayed deliveries whose timestamp is outside the tolerance.
+type WebhookMiddleware struct {
+	*BaseMiddleware
+
+	// Configuration, replaced as a whole by SetConfig so each request uses
+	// one snapshot
+	config atomic.Pointer[WebhookConfig]
+
+	// Metrics
+	totalRequests    int64
+	verifiedRequests int64
+	rejectedRequests int64
+
+	// Internal state
+	startTime time.Time
+	now       func() time.Time
+}
+
+// WebhookConfig defines configuration options for the webhook middleware.
+type WebhookConfig struct {
+	// Secrets used to verify signatures. Any of them is accepted so secrets
+	// can be rotated; the first one is used by Sign.
+	Secrets []string
+
+	// Signature format
+	Scheme          WebhookScheme
+	SignatureHeader string
+	SignaturePrefix string
+	Hash            func() hash.Hash
+
+	// Replay protection. TimestampHeader holds Unix seconds and is signed as
+	// "<timestamp>.<body>" by the hex and base64 schemes; leave it empty to
+	// sign the body only. IDHeader is used by the standard scheme.
+	TimestampHeader string
+	IDHeader        string
+	Tolerance       time.Duration
+
+	// Paths to verify; empty verifies every request.
+	Paths []string
+
+	// MaxBodySize limits the body read by Handler.
+	MaxBodySize int64
+}
+
+// NewWebhookMiddleware creates a new webhook middleware verifying hex
+// HMAC-SHA256 signatures with the given secrets.
+func NewWebhookMiddleware(priority int, secrets ...string) *WebhookMiddleware {
+	config := DefaultWebhookConfig()
+	config.Secrets = secrets
+	return NewWebhookMiddlewareWithConfig(priority, config)
+}
+
+// NewWebhookMiddlewareWithConfig creates a new webhook middleware with custom
+// configuration. Empty fields are filled with the defaults of the scheme.
+func NewWebhookMiddlewareWithConfig(priority int, config WebhookConfig) *WebhookMiddleware {
+	wm := &WebhookMiddleware{
+		BaseMiddleware: NewBaseMiddleware("webhook", priority),
+		startTime:      time.Now(),
+		now:            time.Now,
+	}
+	config = withWebhookDefaults(config)
+	wm.config.Store(&config)
+	return wm
+}
+
+// DefaultWebhookConfig returns a default webhook configuration.
+func DefaultWebhookConfig() WebhookConfig {
+	return WebhookConfig{
+		Scheme:          WebhookSchemeHex,
+		SignatureHeader: "X-Signature",
+		TimestampHeader: "X-Timestamp",
+		Hash:            sha256.New,
+		Tolerance:       5 * time.Minute,
+		MaxBodySize:     1 << 20,
+	}
+}
+
+// withWebhookDefaults fills empty configuration fields.
+func withWebhookDefaults(config WebhookConfig) WebhookConfig {
+	if config.Scheme == "" {
+		config.Scheme = WebhookSchemeHex
+	}
+	if config.SignatureHeader == "" {
+		switch config.Scheme {
+		case WebhookSchemeStripe:
+			config.SignatureHeader = "Stripe-Signature"
+		case WebhookSchemeStandard:
+			config.SignatureHeader = "webhook-signature"
+		default:
+			config.SignatureHeader = "X-Signature"
+		}
+	}
+	if config.Scheme == WebhookSchemeStandard {
+		if config.TimestampHeader == "" {
+			config.TimestampHeader = "webhook-timestamp"
+		}
+		if config.IDHeader == "" {
+			config.IDHeader = "webhook-id"
+		}
+	}
+	if config.Hash == nil {
+		config.Hash = sha256.New
+	}
+	if config.Tolerance == 0 {
+		config.Tolerance = 5 * time.Minute
+	}
+	if config.MaxBodySize == 0 {
+		config.MaxBodySize = 1 << 20
+	}
+	return config
+}
+
+// Process implements the Middleware interface for webhook verification. The
+// request must carry the raw body under "body" as []byte or string.
+func (wm *WebhookMiddleware) Process(ctx context.Context, req interface{}, next MiddlewareNext) (interface{}, error) {
+	if !wm.IsEnabled() {
+		return next(ctx, req)
+	}
+
+	var path string
+	var headers map[string]string
+	var body []byte
+	if httpReq, ok := req.(map[string]interface{}); ok {
+		path, _ = httpReq["path"].(string)
+		headers, _ = httpReq["headers"].(map[string]string)
+		switch b := httpReq["body"].(type) {
+		case []byte:
+			body = b
+		case string:
+			body = []byte(b)
+		}
+	}
+
+	config := wm.config.Load()
+	if !shouldVerify(config, path) {
+		return next(ctx, req)
+	}
+	if err := wm.verify(config, headers, body); err != nil {
+		return nil, err
+	}
+	return next(ctx, req)
+}
+
+// Handler wraps a net/http handler, verifying the request before calling it.
+// Rejected requests receive the domain error as JSON with its HTTP status.
+func (wm *WebhookMiddleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		config := wm.config.Load()
+		if !wm.IsEnabled() || !shouldVerify(config, r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, config.MaxBodySize+1))
+		if err != nil {
+			err = wm.reject(config, err, domaininterfaces.BadRequestError, "WEBHOOK_BODY_UNREADABLE", "webhook body could not be read")
+		} else if int64(len(body)) > config.MaxBodySize {
+			err = wm.reject(config, ErrWebhookBodyTooLarge, domaininterfaces.PayloadTooLargeError, "WEBHOOK_BODY_TOO_LARGE", "webhook body too large")
+		} else {
+			headers := make(map[string]string, len(r.Header))
+			for key := range r.Header {
+				headers[key] = r.Header.Get(key)
+			}
+			err = wm.verify(config, headers, body)
+		}
+		if err != nil {
+			WriteProblem(w, err)
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Verify checks the signature and timestamp of a webhook. Header names are
+// matched case-insensitively.
+func (wm *WebhookMiddleware) Verify(headers map[string]string, body []byte) error {
+	return wm.verify(wm.config.Load(), headers, body)
+}
+
+// verify checks a webhook against a configuration snapshot.
+func (wm *WebhookMiddleware) verify(config *WebhookConfig, headers map[string]string, body []byte) error {
+	atomic.AddInt64(&wm.totalRequests, 1)
+
+	signature := headerValue(headers, config.SignatureHeader)
+	if signature == "" {
+		return wm.reject(config, ErrWebhookSignatureMissing, domaininterfaces.AuthenticationError, "WEBHOOK_SIGNATURE_MISSING", webhookRejectedMessage)
+	}
+
+	var timestamp string
+	var candidates [][]byte
+	switch config.Scheme {
+	case WebhookSchemeStripe:
+		for _, part := range strings.Split(signature, ",") {
+			key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+			switch key {
+			case "t":
+				timestamp = value
+			case "v1":
+				if decoded, err := hex.DecodeString(value); err == nil {
+					candidates = append(candidates, decoded)
+				}
+			}
+		}
+	case WebhookSchemeStandard:
+		timestamp = headerValue(headers, config.TimestampHeader)
+		for _, part := range strings.Fields(signature) {
+			version, value, _ := strings.Cut(part, ",")
+			if version != "v1" {
+				continue
+			}
+			if decoded, err := base64.StdEncoding.DecodeString(value); err == nil {
+				candidates = append(candidates, decoded)
+			}
+		}
+	default:
+		if config.TimestampHeader != "" {
+			timestamp = headerValue(headers, config.TimestampHeader)
+		}
+		if decoded, err := decodeSignature(config, strings.TrimPrefix(signature, config.SignaturePrefix)); err == nil {
+			candidates = append(candidates, decoded)
+		}
+	}
+
+	if config.Scheme == WebhookSchemeStripe || config.Scheme == WebhookSchemeStandard || config.TimestampHeader != "" {
+		if err := wm.checkTimestamp(config, timestamp); err != nil {
+			return err
+		}
+	}
+
+	payload := signedPayload(config, headerValue(headers, config.IDHeader), timestamp, body)
+	for _, secret := range config.Secrets {
+		expected := webhookMAC(config, secret, payload)
+		for _, candidate := range candidates {
+			if hmac.Equal(expected, candidate) {
+				atomic.AddInt64(&wm.verifiedRequests, 1)
+				return nil
+			}
+		}
+	}
+	return wm.reject(config, ErrWebhookSignatureInvalid, domaininterfaces.AuthenticationError, "WEBHOOK_SIGNATURE_INVALID", webhookRejectedMessage)
+}
+
+// Sign returns the headers that make a delivery of body at the given time
+// pass verification, using the first secret. The id is only used by the
+// standard scheme.
+func (wm *WebhookMiddleware) Sign(id string, body []byte, at time.Time) (map[string]string, error) {
+	config := wm.config.Load()
+	if len(config.Secrets) == 0 {
+		return nil, errors.New("no webhook secret configured")
+	}
+
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	if config.Scheme == WebhookSchemeHex || config.Scheme == WebhookSchemeBase64 {
+		if config.TimestampHeader == "" {
+			timestamp = ""
+		}
+	}
+	mac := webhookMAC(config, config.Secrets[0], signedPayload(config, id, timestamp, body))
+
+	headers := make(map[string]string)
+	switch config.Scheme {
+	case WebhookSchemeStripe:
+		headers[config.SignatureHeader] = "t=" + timestamp + ",v1=" + hex.EncodeToString(mac)
+	case WebhookSchemeStandard:
+		headers[config.IDHeader] = id
+		headers[config.TimestampHeader] = timestamp
+		headers[config.SignatureHeader] = "v1," + base64.StdEncoding.EncodeToString(mac)
+	case WebhookSchemeBase64:
+		headers[config.SignatureHeader] = config.SignaturePrefix + base64.StdEncoding.EncodeToString(mac)
+	default:
+		headers[config.SignatureHeader] = config.SignaturePrefix + hex.EncodeToString(mac)
+	}
+	if timestamp != "" && config.Scheme != WebhookSchemeStripe {
+		headers[config.TimestampHeader] = timestamp
+	}
+	return headers, nil
+}
+
+// GetConfig returns the current webhook configuration.
+func (wm *WebhookMiddleware) GetConfig() WebhookConfig {
+	return *wm.config.Load()
+}
+
+// SetConfig updates the webhook configuration, e.g. to rotate secrets. It is
+// safe to call while requests are verified; each request uses either the old
+// or the new configuration.
+func (wm *WebhookMiddleware) SetConfig(config WebhookConfig) {
+	config = withWebhookDefaults(config)
+	wm.config.Store(&config)
+	wm.GetLogger().Info("Webhook middleware configuration updated")
+}
+
+// GetMetrics returns webhook verification metrics.
+func (wm *WebhookMiddleware) GetMetrics() map[string]interface{} {
+	return map[string]interface{}{
+		"total_requests":    atomic.LoadInt64(&wm.totalRequests),
+		"verified_requests": atomic.LoadInt64(&wm.verifiedRequests),
+		"rejected_requests": atomic.LoadInt64(&wm.rejectedRequests),
+		"uptime":            time.Since(wm.startTime),
+	}
+}
+
+// Reset resets all metrics.
+func (wm *WebhookMiddleware) Reset() {
+	atomic.StoreInt64(&wm.totalRequests, 0)
+	atomic.StoreInt64(&wm.verifiedRequests, 0)
+	atomic.StoreInt64(&wm.rejectedRequests, 0)
+	wm.startTime = time.Now()
+	wm.GetLogger().Info("Webhook middleware metrics reset")
+}
+
+// shouldVerify determines if a path must be verified.
+func shouldVerify(config *WebhookConfig, path string) bool {
+	if len(config.Paths) == 0 {
+		return true
+	}
+	for _, p := range config.Paths {
+		if path == p {
+			return true
+		}
+	}
+	return false
+}
+
+// checkTimestamp rejects missing timestamps and timestamps outside the
+// tolerance, in the past or in the future.
+func (wm *WebhookMiddleware) checkTimestamp(config *WebhookConfig, timestamp string) error {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return wm.reject(config, ErrWebhookTimestampInvalid, domaininterfaces.AuthenticationError, "WEBHOOK_TIMESTAMP_INVALID", webhookRejectedMessage)
+	}
+
+	age := wm.now().Sub(time.Unix(seconds, 0))
+	if age > config.Tolerance || age < -config.Tolerance {
+		return wm.reject(config, ErrWebhookTimestampExpired, domaininterfaces.AuthenticationError, "WEBHOOK_TIMESTAMP_EXPIRED", webhookRejectedMessage)
+	}
+	return nil
+}
+
+// signedPayload builds the signed content for the configured scheme.
+func signedPayload(config *WebhookConfig, id, timestamp string, body []byte) []byte {
+	var prefix string
+	switch {
+	case config.Scheme == WebhookSchemeStandard:
+		prefix = id + "." + timestamp + "."
+	case timestamp != "":
+		prefix = timestamp + "."
+	}
+	return append([]byte(prefix), body...)
+}
+
+// mac computes the HMAC of the payload with a secret.
+func webhookMAC(config *WebhookConfig, secret string, payload []byte) []byte {
+	key := []byte(secret)
+	// Standard Webhooks secrets are base64 with a "whsec_" prefix
+	if config.Scheme == WebhookSchemeStandard && strings.HasPrefix(secret, "whsec_") {
+		if decoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(secret, "whsec_")); err == nil {
+			key = decoded
+		}
+	}
+	mac := hmac.New(config.Hash, key)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// decodeSignature decodes a hex or base64 signature.
+func decodeSignature(config *WebhookConfig, signature string) ([]byte, error) {
+	if config.Scheme == WebhookSchemeBase64 {
+		return base64.StdEncoding.DecodeString(signature)
+	}
+	return hex.DecodeString(signature)
+}
+
+// webhookRejectedMessage is the message of the authentication failures, which
+// does not tell the sender which check failed.
+const webhookRejectedMessage = "webhook verification failed"
+
+// reject counts a rejected request and creates its domain error. The message
+// is the one exposed to the sender; the cause is only logged and wrapped.
+func (wm *WebhookMiddleware) reject(config *WebhookConfig, cause error, errorType domaininterfaces.ErrorType, code, message string) error {
+	atomic.AddInt64(&wm.rejectedRequests, 1)
+	wm.GetLogger().Warn("Webhook rejected: %v", cause)
+	return domainerrors.NewWithMetadata(errorType, code, message, map[string]interface{}{
+		"scheme": string(config.Scheme),
+	}).Wrap(cause)
+}
+
+// headerValue returns a header value matching the name case-insensitively.
+func headerValue(headers map[string]string, name string) string {
+	if name == "" {
+		return ""
+	}
+	if value, exists := headers[name]; exists {
+		return value
+	}
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
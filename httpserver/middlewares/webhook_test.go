@@ -0,0 +1,239 @@
+package middlewares
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	domaininterfaces "github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+func TestWebhookMiddleware_Schemes(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"event":"order.paid"}`)
+
+	configs := map[string]WebhookConfig{
+		"hex with timestamp": DefaultWebhookConfig(),
+		"github":             {SignatureHeader: "X-Hub-Signature-256", SignaturePrefix: "sha256="},
+		"base64":             {Scheme: WebhookSchemeBase64, TimestampHeader: "X-Timestamp"},
+		"stripe":             {Scheme: WebhookSchemeStripe},
+		"standard":           {Scheme: WebhookSchemeStandard},
+	}
+
+	for name, config := range configs {
+		t.Run(name, func(t *testing.T) {
+			config.Secrets = []string{"new-secret", "old-secret"}
+			wm := NewWebhookMiddlewareWithConfig(1, config)
+			wm.now = func() time.Time { return now }
+
+			headers, err := wm.Sign("msg_1", body, now)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if err := wm.Verify(headers, body); err != nil {
+				t.Errorf("Expected valid signature, got %v", err)
+			}
+
+			if err := wm.Verify(headers, []byte(`{"event":"order.refunded"}`)); !errors.Is(err, ErrWebhookSignatureInvalid) {
+				t.Errorf("Expected ErrWebhookSignatureInvalid for tampered body, got %v", err)
+			}
+
+			// Deliveries signed with a rotated-out secret are still accepted
+			config.Secrets = []string{"old-secret"}
+			signer := NewWebhookMiddlewareWithConfig(1, config)
+			headers, _ = signer.Sign("msg_1", body, now)
+			if err := wm.Verify(headers, body); err != nil {
+				t.Errorf("Expected signature with previous secret to be valid, got %v", err)
+			}
+
+			config.Secrets = []string{"unknown"}
+			signer = NewWebhookMiddlewareWithConfig(1, config)
+			headers, _ = signer.Sign("msg_1", body, now)
+			if err := wm.Verify(headers, body); !errors.Is(err, ErrWebhookSignatureInvalid) {
+				t.Errorf("Expected ErrWebhookSignatureInvalid for unknown secret, got %v", err)
+			}
+		})
+	}
+}
+
+func TestWebhookMiddleware_Replay(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte("payload")
+	wm := NewWebhookMiddleware(1, "secret")
+	wm.now = func() time.Time { return now }
+
+	headers, _ := wm.Sign("", body, now.Add(-10*time.Minute))
+	err := wm.Verify(headers, body)
+	if !errors.Is(err, ErrWebhookTimestampExpired) {
+		t.Fatalf("Expected ErrWebhookTimestampExpired, got %v", err)
+	}
+	if !domainerrors.IsType(err, domaininterfaces.AuthenticationError) {
+		t.Errorf("Expected authentication domain error, got %v", err)
+	}
+
+	headers, _ = wm.Sign("", body, now.Add(10*time.Minute))
+	if err := wm.Verify(headers, body); !errors.Is(err, ErrWebhookTimestampExpired) {
+		t.Errorf("Expected future timestamp to be rejected, got %v", err)
+	}
+
+	// Changing the timestamp invalidates the signature
+	headers, _ = wm.Sign("", body, now.Add(-10*time.Minute))
+	headers["X-Timestamp"] = strconv.FormatInt(now.Unix(), 10)
+	if err := wm.Verify(headers, body); !errors.Is(err, ErrWebhookSignatureInvalid) {
+		t.Errorf("Expected ErrWebhookSignatureInvalid, got %v", err)
+	}
+
+	delete(headers, "X-Timestamp")
+	if err := wm.Verify(headers, body); !errors.Is(err, ErrWebhookTimestampInvalid) {
+		t.Errorf("Expected ErrWebhookTimestampInvalid, got %v", err)
+	}
+
+	if err := wm.Verify(map[string]string{}, body); !errors.Is(err, ErrWebhookSignatureMissing) {
+		t.Errorf("Expected ErrWebhookSignatureMissing, got %v", err)
+	}
+
+	metrics := wm.GetMetrics()
+	if metrics["rejected_requests"].(int64) != 5 || metrics["verified_requests"].(int64) != 0 {
+		t.Errorf("Unexpected metrics %v", metrics)
+	}
+}
+
+func TestWebhookMiddleware_Process(t *testing.T) {
+	body := "payload"
+	config := DefaultWebhookConfig()
+	config.Secrets = []string{"secret"}
+	config.Paths = []string{"/webhooks"}
+	wm := NewWebhookMiddlewareWithConfig(1, config)
+
+	headers, _ := wm.Sign("", []byte(body), time.Now())
+	called := false
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		called = true
+		return "ok", nil
+	}
+
+	req := map[string]interface{}{"path": "/webhooks", "headers": headers, "body": body}
+	if _, err := wm.Process(context.Background(), req, next); err != nil || !called {
+		t.Errorf("Expected request to pass, got %v", err)
+	}
+
+	called = false
+	req["body"] = "tampered"
+	if _, err := wm.Process(context.Background(), req, next); err == nil || called {
+		t.Error("Expected tampered request to be rejected")
+	}
+
+	req["path"] = "/other"
+	if _, err := wm.Process(context.Background(), req, next); err != nil || !called {
+		t.Errorf("Expected unverified path to pass, got %v", err)
+	}
+}
+
+func TestWebhookMiddleware_Handler(t *testing.T) {
+	wm := NewWebhookMiddleware(1, "secret")
+	handler := wm.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		if err != nil || string(data) != "payload" {
+			t.Errorf("Expected body to be readable, got %q (%v)", data, err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	headers, _ := wm.Sign("", []byte("payload"), time.Now())
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader("payload"))
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("Expected 204, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader("payload"))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized || !strings.Contains(rec.Body.String(), "WEBHOOK_SIGNATURE_MISSING") {
+		t.Errorf("Expected 401 with error code, got %d %s", rec.Code, rec.Body.String())
+	}
+	if contentType := rec.Header().Get("Content-Type"); contentType != ProblemContentType {
+		t.Errorf("Expected %s, got %s", ProblemContentType, contentType)
+	}
+
+	// The response does not tell which check failed
+	headers, _ = wm.Sign("", []byte("payload"), time.Now().Add(-time.Hour))
+	req = httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader("payload"))
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	var problem Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("Expected problem body, got %s", rec.Body.String())
+	}
+	if problem.Detail != "webhook verification failed" || strings.Contains(rec.Body.String(), "tolerance") {
+		t.Errorf("Expected generic detail, got %s", rec.Body.String())
+	}
+
+	config := wm.GetConfig()
+	config.MaxBodySize = 4
+	wm.SetConfig(config)
+	headers, _ = wm.Sign("", []byte("payload"), time.Now())
+	req = httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader("payload"))
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusRequestEntityTooLarge || !strings.Contains(rec.Body.String(), "WEBHOOK_BODY_TOO_LARGE") {
+		t.Errorf("Expected 413 with error code, got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestWebhookMiddleware_SetConfigConcurrent(t *testing.T) {
+	wm := NewWebhookMiddleware(1, "old")
+	handler := wm.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	signer := NewWebhookMiddleware(1, "old")
+	headers, _ := signer.Sign("", []byte("payload"), time.Now())
+
+	// Secrets are rotated while deliveries are verified; both secrets are
+	// accepted during the rotation
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for range 100 {
+			config := wm.GetConfig()
+			config.Secrets = []string{"new", "old"}
+			wm.SetConfig(config)
+		}
+	}()
+	for range 100 {
+		req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader("payload"))
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("Expected 204 during rotation, got %d %s", rec.Code, rec.Body.String())
+		}
+	}
+	wg.Wait()
+
+	if secrets := wm.GetConfig().Secrets; len(secrets) != 2 || secrets[0] != "new" {
+		t.Errorf("Expected rotated secrets, got %v", secrets)
+	}
+}
@@ -0,0 +1,482 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"os/signal"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// ErrServerRunning is returned when Serve is called on a running server.
+var ErrServerRunning = errors.New("httpserver: server already running")
+
+// HealthCheck reports whether a dependency is ready to serve traffic.
+type HealthCheck func(ctx context.Context) error
+
+// ServerConfig configures a Server created with New.
+type ServerConfig struct {
+	// Addr is the TCP address to listen on.
+	Addr string
+
+	// Handler serves the application routes. When nil, routes are added with
+	// Handle and HandleFunc.
+	Handler http.Handler
+
+	// Timeouts of the underlying http.Server.
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+
+	// MaxHeaderBytes limits the size of request headers.
+	MaxHeaderBytes int
+
+	// TLSConfig enables TLS. CertFile and KeyFile are loaded when set; a TLS
+	// 1.2 minimum is used when only the files are given.
+	TLSConfig *tls.Config
+	CertFile  string
+	KeyFile   string
+
+	// ShutdownTimeout bounds the wait for in-flight requests on shutdown.
+	ShutdownTimeout time.Duration
+
+	// DrainDelay keeps serving after readiness turns to 503 on shutdown, so
+	// load balancers stop routing before connections are closed.
+	DrainDelay time.Duration
+
+	// EnableHealth mounts the liveness and readiness endpoints.
+	EnableHealth  bool
+	HealthPath    string
+	ReadinessPath string
+	HealthChecks  map[string]HealthCheck
+
+	// EnableMetrics mounts request metrics in the Prometheus text format.
+	EnableMetrics bool
+	MetricsPath   string
+
+	// EnablePprof mounts net/http/pprof. Keep it off on public listeners.
+	EnablePprof bool
+	PprofPath   string
+
+	// AccessLog logs every request with Logger.
+	AccessLog bool
+
+	// Logger receives access logs, recovered panics and server errors.
+	// Defaults to slog.Default().
+	Logger *slog.Logger
+}
+
+// DefaultServerConfig returns a configuration with production timeouts and
+// the health endpoints enabled.
+func DefaultServerConfig() ServerConfig {
+	return ServerConfig{
+		Addr:              ":8080",
+		ReadTimeout:       30 * time.Second,
+		ReadHeaderTimeout: 10 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       120 * time.Second,
+		MaxHeaderBytes:    1 << 20,
+		ShutdownTimeout:   30 * time.Second,
+		EnableHealth:      true,
+		HealthPath:        "/health",
+		ReadinessPath:     "/ready",
+		MetricsPath:       "/metrics",
+		PprofPath:         "/debug/pprof/",
+		AccessLog:         true,
+	}
+}
+
+// Validate validates the configuration.
+func (c ServerConfig) Validate() error {
+	if c.ReadTimeout < 0 || c.ReadHeaderTimeout < 0 || c.WriteTimeout < 0 || c.IdleTimeout < 0 {
+		return fmt.Errorf("timeouts cannot be negative")
+	}
+	if c.ShutdownTimeout <= 0 {
+		return fmt.Errorf("invalid shutdown timeout: %v (must be positive)", c.ShutdownTimeout)
+	}
+	if c.DrainDelay < 0 || c.DrainDelay >= c.ShutdownTimeout {
+		return fmt.Errorf("invalid drain delay: %v (must be shorter than the shutdown timeout)", c.DrainDelay)
+	}
+	if c.MaxHeaderBytes < 0 {
+		return fmt.Errorf("invalid max header bytes: %d", c.MaxHeaderBytes)
+	}
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return fmt.Errorf("cert file and key file must be set together")
+	}
+	return nil
+}
+
+// Server is a net/http server with timeouts, operational endpoints, access
+// logging, panic recovery and graceful shutdown.
+type Server struct {
+	config   ServerConfig
+	mux      *http.ServeMux
+	server   *http.Server
+	logger   *slog.Logger
+	mu       sync.Mutex
+	addr     net.Addr
+	running  bool
+	draining atomic.Bool
+	metrics  serverMetrics
+}
+
+// serverMetrics holds the request counters exposed on the metrics endpoint.
+type serverMetrics struct {
+	inFlight atomic.Int64
+	total    atomic.Int64
+	panics   atomic.Int64
+	byClass  [6]atomic.Int64
+	duration atomic.Int64
+}
+
+// New creates a Server. Operational endpoints are mounted next to the
+// application routes according to the configuration.
+func New(cfg ServerConfig) (*Server, error) {
+	defaults := DefaultServerConfig()
+	if cfg.ShutdownTimeout == 0 {
+		cfg.ShutdownTimeout = defaults.ShutdownTimeout
+	}
+	if cfg.HealthPath == "" {
+		cfg.HealthPath = defaults.HealthPath
+	}
+	if cfg.ReadinessPath == "" {
+		cfg.ReadinessPath = defaults.ReadinessPath
+	}
+	if cfg.MetricsPath == "" {
+		cfg.MetricsPath = defaults.MetricsPath
+	}
+	if cfg.PprofPath == "" {
+		cfg.PprofPath = defaults.PprofPath
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	s := &Server{
+		config: cfg,
+		mux:    http.NewServeMux(),
+		logger: cfg.Logger,
+	}
+	if s.logger == nil {
+		s.logger = slog.Default()
+	}
+	s.mountOperational()
+
+	s.server = &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           s.instrument(s.mux),
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+		TLSConfig:         cfg.TLSConfig,
+		ErrorLog:          slog.NewLogLogger(s.logger.Handler(), slog.LevelError),
+	}
+	if cfg.CertFile != "" && s.server.TLSConfig == nil {
+		s.server.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return s, nil
+}
+
+// Handle registers an application handler on the server mux.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// HandleFunc registers an application handler function on the server mux.
+func (s *Server) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	s.mux.HandleFunc(pattern, handler)
+}
+
+// Handler returns the instrumented handler, for tests and custom listeners.
+func (s *Server) Handler() http.Handler {
+	return s.server.Handler
+}
+
+// Addr returns the address the server listens on, or the configured address
+// before it starts.
+func (s *Server) Addr() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.addr != nil {
+		return s.addr.String()
+	}
+	return s.config.Addr
+}
+
+// InFlight returns the number of requests being served.
+func (s *Server) InFlight() int64 {
+	return s.metrics.inFlight.Load()
+}
+
+// Run serves until SIGINT or SIGTERM is received or the context is
+// cancelled, then shuts down gracefully.
+func (s *Server) Run(ctx context.Context) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return s.ListenAndServe(ctx)
+}
+
+// ListenAndServe listens on the configured address and serves until the
+// context is cancelled, then shuts down gracefully.
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.config.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.config.Addr, err)
+	}
+	return s.Serve(ctx, ln)
+}
+
+// Serve serves on the listener until the context is cancelled, then shuts
+// down gracefully within the shutdown timeout. It returns nil after a clean
+// shutdown.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		ln.Close()
+		return ErrServerRunning
+	}
+	s.running = true
+	s.addr = ln.Addr()
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.running = false
+		s.mu.Unlock()
+	}()
+
+	// Serve sets up HTTP/2 on TLSConfig concurrently, so read it beforehand
+	useTLS := s.server.TLSConfig != nil
+	errCh := make(chan error, 1)
+	go func() {
+		if useTLS {
+			errCh <- s.server.ServeTLS(ln, s.config.CertFile, s.config.KeyFile)
+			return
+		}
+		errCh <- s.server.Serve(ln)
+	}()
+	s.logger.Info("http server started", "addr", ln.Addr().String(), "tls", useTLS)
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.config.ShutdownTimeout)
+	defer cancel()
+	if err := s.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	<-errCh
+	return nil
+}
+
+// Shutdown stops the server gracefully: readiness turns to 503, the drain
+// delay elapses, then new connections are refused while in-flight requests
+// complete. Connections still open when the context expires are closed.
+// Shutdown matches observability.ShutdownFunc.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.draining.Store(true)
+	s.logger.Info("http server shutting down", "in_flight", s.InFlight())
+
+	if s.config.DrainDelay > 0 {
+		select {
+		case <-time.After(s.config.DrainDelay):
+		case <-ctx.Done():
+		}
+	}
+
+	if err := s.server.Shutdown(ctx); err != nil {
+		inFlight := s.InFlight()
+		s.server.Close()
+		return fmt.Errorf("graceful shutdown failed with %d requests in flight: %w", inFlight, err)
+	}
+	s.logger.Info("http server stopped")
+	return nil
+}
+
+// mountOperational mounts the health, metrics and pprof endpoints.
+func (s *Server) mountOperational() {
+	if s.config.Handler != nil {
+		s.mux.Handle("/", s.config.Handler)
+	}
+
+	if s.config.EnableHealth {
+		s.mux.HandleFunc(s.config.HealthPath, func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, map[string]interface{}{"status": "ok"})
+		})
+		s.mux.HandleFunc(s.config.ReadinessPath, s.handleReadiness)
+	}
+
+	if s.config.EnableMetrics {
+		s.mux.HandleFunc(s.config.MetricsPath, s.handleMetrics)
+	}
+
+	if s.config.EnablePprof {
+		prefix := s.config.PprofPath
+		s.mux.HandleFunc(prefix, pprof.Index)
+		s.mux.HandleFunc(prefix+"cmdline", pprof.Cmdline)
+		s.mux.HandleFunc(prefix+"profile", pprof.Profile)
+		s.mux.HandleFunc(prefix+"symbol", pprof.Symbol)
+		s.mux.HandleFunc(prefix+"trace", pprof.Trace)
+	}
+}
+
+// handleReadiness runs the health checks; the server is not ready while it
+// shuts down.
+func (s *Server) handleReadiness(w http.ResponseWriter, r *http.Request) {
+	if s.draining.Load() {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]interface{}{"status": "shutting_down"})
+		return
+	}
+
+	status := http.StatusOK
+	checks := make(map[string]string, len(s.config.HealthChecks))
+	for name, check := range s.config.HealthChecks {
+		if err := check(r.Context()); err != nil {
+			status = http.StatusServiceUnavailable
+			checks[name] = err.Error()
+			continue
+		}
+		checks[name] = "ok"
+	}
+
+	body := map[string]interface{}{"status": "ok", "checks": checks}
+	if status != http.StatusOK {
+		body["status"] = "unavailable"
+	}
+	writeJSON(w, status, body)
+}
+
+// handleMetrics writes the request metrics in the Prometheus text format.
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	fmt.Fprintln(w, "# TYPE http_requests_in_flight gauge")
+	fmt.Fprintf(w, "http_requests_in_flight %d\n", s.metrics.inFlight.Load())
+	fmt.Fprintln(w, "# TYPE http_requests_total counter")
+	for class := 1; class < len(s.metrics.byClass); class++ {
+		fmt.Fprintf(w, "http_requests_total{code=\"%dxx\"} %d\n", class, s.metrics.byClass[class].Load())
+	}
+	fmt.Fprintln(w, "# TYPE http_request_duration_seconds summary")
+	fmt.Fprintf(w, "http_request_duration_seconds_sum %g\n", time.Duration(s.metrics.duration.Load()).Seconds())
+	fmt.Fprintf(w, "http_request_duration_seconds_count %d\n", s.metrics.total.Load())
+	fmt.Fprintln(w, "# TYPE http_panics_recovered_total counter")
+	fmt.Fprintf(w, "http_panics_recovered_total %d\n", s.metrics.panics.Load())
+}
+
+// instrument wraps the mux with panic recovery, metrics and access logging.
+func (s *Server) instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+
+		s.metrics.inFlight.Add(1)
+		defer func() {
+			if p := recover(); p != nil {
+				if p == http.ErrAbortHandler {
+					panic(p)
+				}
+				s.metrics.panics.Add(1)
+				s.logger.Error("panic recovered",
+					"panic", p,
+					"method", r.Method,
+					"path", r.URL.Path,
+					"stack", string(debug.Stack()))
+				if !rec.wroteHeader {
+					writeJSON(rec, http.StatusInternalServerError, map[string]interface{}{
+						"code":    "INTERNAL_ERROR",
+						"message": "internal server error",
+					})
+				}
+			}
+
+			duration := time.Since(start)
+			s.metrics.inFlight.Add(-1)
+			s.metrics.total.Add(1)
+			s.metrics.duration.Add(int64(duration))
+			if class := rec.status() / 100; class > 0 && class < len(s.metrics.byClass) {
+				s.metrics.byClass[class].Add(1)
+			}
+
+			if s.config.AccessLog {
+				s.logger.LogAttrs(r.Context(), slog.LevelInfo, "http request",
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+					slog.Int("status", rec.status()),
+					slog.Int64("bytes", rec.bytes),
+					slog.Duration("duration", duration),
+					slog.String("remote_addr", r.RemoteAddr),
+					slog.String("user_agent", r.UserAgent()))
+			}
+		}()
+
+		next.ServeHTTP(rec, r)
+	})
+}
+
+// statusRecorder records the status and size of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	code        int
+	bytes       int64
+	wroteHeader bool
+}
+
+// WriteHeader records the status code.
+func (r *statusRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.code = code
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// Write records the response size.
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// status returns the recorded status, 200 when nothing was written.
+func (r *statusRecorder) status() int {
+	if r.code == 0 {
+		return http.StatusOK
+	}
+	return r.code
+}
+
+// writeJSON writes a JSON response.
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
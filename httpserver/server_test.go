@@ -0,0 +1,240 @@
+package httpserver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestServer(t *testing.T, mutate func(*ServerConfig)) *Server {
+	t.Helper()
+	cfg := DefaultServerConfig()
+	cfg.Addr = "127.0.0.1:0"
+	cfg.AccessLog = false
+	cfg.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	if mutate != nil {
+		mutate(&cfg)
+	}
+
+	s, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return s
+}
+
+func TestServerConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(*ServerConfig)
+	}{
+		{"negative timeout", func(c *ServerConfig) { c.ReadTimeout = -time.Second }},
+		{"drain delay longer than shutdown", func(c *ServerConfig) { c.DrainDelay = c.ShutdownTimeout }},
+		{"cert without key", func(c *ServerConfig) { c.CertFile = "cert.pem" }},
+		{"negative header size", func(c *ServerConfig) { c.MaxHeaderBytes = -1 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultServerConfig()
+			tt.mutate(&cfg)
+			if _, err := New(cfg); err == nil {
+				t.Error("Expected validation error")
+			}
+		})
+	}
+}
+
+func TestNew_Defaults(t *testing.T) {
+	s, err := New(ServerConfig{Addr: ":0"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if s.config.ShutdownTimeout != 30*time.Second {
+		t.Errorf("Expected default shutdown timeout, got %v", s.config.ShutdownTimeout)
+	}
+
+	tlsServer := newTestServer(t, func(c *ServerConfig) {
+		c.CertFile, c.KeyFile = "cert.pem", "key.pem"
+	})
+	if tlsServer.server.TLSConfig == nil || tlsServer.server.TLSConfig.MinVersion == 0 {
+		t.Error("Expected TLS config with a minimum version")
+	}
+}
+
+func TestServer_OperationalEndpoints(t *testing.T) {
+	s := newTestServer(t, func(c *ServerConfig) {
+		c.EnableMetrics = true
+		c.EnablePprof = true
+		c.HealthChecks = map[string]HealthCheck{
+			"db": func(ctx context.Context) error { return errors.New("connection refused") },
+		}
+	})
+	s.HandleFunc("GET /hello", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	})
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/hello", http.StatusOK, "hello"},
+		{"/health", http.StatusOK, `"ok"`},
+		{"/ready", http.StatusServiceUnavailable, "connection refused"},
+		{"/metrics", http.StatusOK, `http_requests_total{code="2xx"}`},
+		{"/debug/pprof/", http.StatusOK, "goroutine"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, rec.Code)
+			}
+			if !strings.Contains(rec.Body.String(), tt.body) {
+				t.Errorf("Expected body to contain %q, got %q", tt.body, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestServer_DisabledEndpoints(t *testing.T) {
+	s := newTestServer(t, nil)
+
+	for _, path := range []string{"/metrics", "/debug/pprof/"} {
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("Expected %s to be disabled, got %d", path, rec.Code)
+		}
+	}
+}
+
+func TestServer_PanicRecoveryAndAccessLog(t *testing.T) {
+	var logs bytes.Buffer
+	s := newTestServer(t, func(c *ServerConfig) {
+		c.AccessLog = true
+		c.Logger = slog.New(slog.NewTextHandler(&logs, nil))
+		c.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		})
+	})
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", rec.Code)
+	}
+	if s.metrics.panics.Load() != 1 || s.InFlight() != 0 {
+		t.Errorf("Expected 1 panic and no request in flight, got %d/%d", s.metrics.panics.Load(), s.InFlight())
+	}
+	for _, want := range []string{"panic recovered", "path=/orders", "status=500"} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("Expected logs to contain %q, got %s", want, logs.String())
+		}
+	}
+}
+
+func TestServer_GracefulShutdown(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	s := newTestServer(t, func(c *ServerConfig) {
+		c.ShutdownTimeout = 5 * time.Second
+		c.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+			w.Write([]byte("done"))
+		})
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- s.Serve(ctx, ln) }()
+
+	response := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String() + "/slow")
+		if err != nil {
+			response <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		response <- string(body)
+	}()
+
+	<-started
+	cancel()
+
+	// Readiness fails while the in-flight request drains
+	time.Sleep(50 * time.Millisecond)
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected readiness 503 during shutdown, got %d", rec.Code)
+	}
+
+	close(release)
+	if body := <-response; body != "done" {
+		t.Errorf("Expected in-flight request to complete, got %q", body)
+	}
+	if err := <-served; err != nil {
+		t.Errorf("Expected clean shutdown, got %v", err)
+	}
+}
+
+func TestServer_ShutdownTimeout(t *testing.T) {
+	started := make(chan struct{})
+	s := newTestServer(t, func(c *ServerConfig) {
+		c.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			time.Sleep(time.Second)
+		})
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	go s.Serve(context.Background(), ln)
+	go http.Get("http://" + ln.Addr().String() + "/stuck")
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = s.Shutdown(ctx)
+	if err == nil || !strings.Contains(err.Error(), "1 requests in flight") {
+		t.Errorf("Expected shutdown timeout error, got %v", err)
+	}
+}
+
+func TestServer_ServeTwice(t *testing.T) {
+	s := newTestServer(t, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	go s.Serve(ctx, ln)
+	time.Sleep(20 * time.Millisecond)
+
+	ln2, _ := net.Listen("tcp", "127.0.0.1:0")
+	if err := s.Serve(ctx, ln2); !errors.Is(err, ErrServerRunning) {
+		t.Errorf("Expected ErrServerRunning, got %v", err)
+	}
+}
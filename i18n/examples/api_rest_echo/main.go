@@ -15,6 +15,7 @@ import (
 	"github.com/fsvxavier/nexs-lib/i18n/config"
 	"github.com/fsvxavier/nexs-lib/i18n/interfaces"
 	"github.com/fsvxavier/nexs-lib/i18n/providers/json"
+	"github.com/fsvxavier/nexs-lib/parsers/acceptlang"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
@@ -169,10 +170,8 @@ func (api *I18nAPI) i18nMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 		// Determine language from query param, header, or default
 		lang := c.QueryParam("lang")
 		if lang == "" {
-			lang = c.Request().Header.Get("Accept-Language")
-			if lang == "" {
-				lang = "en"
-			}
+			lang = acceptlang.Negotiate(c.Request().Header.Get("Accept-Language"),
+				api.provider.GetSupportedLanguages(), api.provider.GetDefaultLanguage())
 		}
 
 		// Store language in context
@@ -14,6 +14,7 @@ import (
 	"github.com/fsvxavier/nexs-lib/i18n/config"
 	"github.com/fsvxavier/nexs-lib/i18n/interfaces"
 	"github.com/fsvxavier/nexs-lib/i18n/providers/json"
+	"github.com/fsvxavier/nexs-lib/parsers/acceptlang"
 	"github.com/gin-gonic/gin"
 )
 
@@ -155,10 +156,8 @@ func i18nMiddleware(i18nService *I18nService) gin.HandlerFunc {
 		// Determine language from query param, header, or default
 		lang := c.Query("lang")
 		if lang == "" {
-			lang = c.GetHeader("Accept-Language")
-			if lang == "" {
-				lang = "en"
-			}
+			lang = acceptlang.Negotiate(c.GetHeader("Accept-Language"),
+				i18nService.provider.GetSupportedLanguages(), i18nService.provider.GetDefaultLanguage())
 		}
 
 		// Store i18n service and language in context
@@ -0,0 +1,342 @@
+// Package idempotency makes retried HTTP requests safe. The middleware reads
+// the Idempotency-Key header, runs the first request under a lock held in a
+// Store and records its response; retries within the TTL replay the recorded
+// response instead of running the handler again. Concurrent duplicates and
+// keys reused with a different payload are rejected with Conflict domain
+// errors.
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/fsvxavier/nexs-lib/httpserver/middlewares"
+)
+
+// HeaderKey is the request header carrying the idempotency key.
+const HeaderKey = "Idempotency-Key"
+
+// HeaderReplayed is set on responses replayed from the store.
+const HeaderReplayed = "Idempotent-Replayed"
+
+// Idempotency errors. The domain errors written by the middleware wrap them,
+// so errors.Is can be used to tell failures apart.
+var (
+	ErrKeyRequired       = errors.New("idempotency: key required")
+	ErrKeyInvalid        = errors.New("idempotency: invalid key")
+	ErrKeyReused         = errors.New("idempotency: key reused with a different request")
+	ErrRequestInProgress = errors.New("idempotency: request in progress")
+	ErrBodyTooLarge      = errors.New("idempotency: request body too large")
+	ErrLockLost          = errors.New("idempotency: lock lost")
+)
+
+// Record is the state stored for a key: the fingerprint of the first request
+// and, once it completed, its response. Token identifies the request holding
+// the lock; Save and Delete only act on the record stored with it.
+type Record struct {
+	Token       string      `json:"token,omitempty"`
+	Fingerprint string      `json:"fingerprint"`
+	Completed   bool        `json:"completed"`
+	StatusCode  int         `json:"status_code,omitempty"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        []byte      `json:"body,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+// Store keeps the records. Implementations must make Lock atomic, so only
+// one of concurrent requests with the same key acquires it.
+type Store interface {
+	// Lock stores an in-progress record for the key when none exists. It
+	// returns true when the lock was acquired, otherwise the existing record.
+	Lock(ctx context.Context, key string, record *Record, ttl time.Duration) (bool, *Record, error)
+
+	// Save replaces the record of a locked key with the completed one. It
+	// returns ErrLockLost when the stored record no longer has the token of
+	// record, because the lock expired and another request took the key.
+	Save(ctx context.Context, key string, record *Record, ttl time.Duration) error
+
+	// Delete removes the record locked with token, releasing the key for a
+	// new attempt. Records of other tokens are left untouched.
+	Delete(ctx context.Context, key, token string) error
+}
+
+// Config configures the middleware.
+type Config struct {
+	// Store keeps the records. Defaults to a MemoryStore.
+	Store Store
+
+	// TTL is how long responses are replayed.
+	TTL time.Duration
+
+	// LockTTL bounds how long a request holds its key; the lock expires if
+	// the process dies before saving the response.
+	LockTTL time.Duration
+
+	// Methods are the methods made idempotent.
+	Methods []string
+
+	// Required rejects requests of those methods without a key.
+	Required bool
+
+	// MaxKeyLength limits the key size.
+	MaxKeyLength int
+
+	// MaxBodySize limits the request body read for the fingerprint.
+	MaxBodySize int64
+
+	// Scope returns a prefix isolating keys, e.g. per tenant or user, so
+	// clients cannot replay each other's responses.
+	Scope func(r *http.Request) string
+
+	// CacheServerErrors also records 5xx responses. By default they release
+	// the key so the request can be retried.
+	CacheServerErrors bool
+}
+
+// DefaultConfig returns a default middleware configuration.
+func DefaultConfig() Config {
+	return Config{
+		TTL:          24 * time.Hour,
+		LockTTL:      time.Minute,
+		Methods:      []string{http.MethodPost, http.MethodPatch},
+		MaxKeyLength: 255,
+		MaxBodySize:  1 << 20,
+	}
+}
+
+// Middleware applies idempotency keys to net/http handlers.
+type Middleware struct {
+	config Config
+	now    func() time.Time
+}
+
+// New creates a Middleware. Zero values of the configuration take defaults.
+func New(config Config) *Middleware {
+	defaults := DefaultConfig()
+	if config.Store == nil {
+		config.Store = NewMemoryStore()
+	}
+	if config.TTL <= 0 {
+		config.TTL = defaults.TTL
+	}
+	if config.LockTTL <= 0 {
+		config.LockTTL = defaults.LockTTL
+	}
+	if len(config.Methods) == 0 {
+		config.Methods = defaults.Methods
+	}
+	if config.MaxKeyLength <= 0 {
+		config.MaxKeyLength = defaults.MaxKeyLength
+	}
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = defaults.MaxBodySize
+	}
+
+	return &Middleware{config: config, now: time.Now}
+}
+
+// Handler wraps the next handler.
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !m.appliesTo(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key := r.Header.Get(HeaderKey)
+		if key == "" {
+			if m.config.Required {
+				middlewares.WriteProblem(w, domainerrors.New(interfaces.BadRequestError, "IDEMPOTENCY_KEY_REQUIRED",
+					fmt.Sprintf("%s header is required", HeaderKey)).Wrap(ErrKeyRequired))
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > m.config.MaxKeyLength {
+			middlewares.WriteProblem(w, domainerrors.NewWithMetadata(interfaces.BadRequestError, "IDEMPOTENCY_KEY_INVALID",
+				"idempotency key too long", map[string]interface{}{"max_length": m.config.MaxKeyLength}).Wrap(ErrKeyInvalid))
+			return
+		}
+
+		fingerprint, err := m.fingerprint(r)
+		if err != nil {
+			middlewares.WriteProblem(w, err)
+			return
+		}
+		if m.config.Scope != nil {
+			key = m.config.Scope(r) + ":" + key
+		}
+
+		m.serve(w, r, next, key, fingerprint)
+	})
+}
+
+// serve runs the request under the key lock or replays the stored response.
+func (m *Middleware) serve(w http.ResponseWriter, r *http.Request, next http.Handler, key, fingerprint string) {
+	ctx := r.Context()
+	token := rand.Text()
+
+	locked, existing, err := m.config.Store.Lock(ctx, key, &Record{
+		Token:       token,
+		Fingerprint: fingerprint,
+		CreatedAt:   m.now(),
+	}, m.config.LockTTL)
+	if err != nil {
+		middlewares.WriteProblem(w, domainerrors.Wrap(err, interfaces.InfrastructureError, "IDEMPOTENCY_STORE_ERROR", "idempotency store unavailable"))
+		return
+	}
+
+	if !locked {
+		switch {
+		case existing.Fingerprint != fingerprint:
+			middlewares.WriteProblem(w, domainerrors.NewWithMetadata(interfaces.ConflictError, "IDEMPOTENCY_KEY_REUSED",
+				"idempotency key already used with a different request", map[string]interface{}{
+					"idempotency_key": r.Header.Get(HeaderKey),
+				}).Wrap(ErrKeyReused))
+		case !existing.Completed:
+			w.Header().Set("Retry-After", "1")
+			middlewares.WriteProblem(w, domainerrors.NewWithMetadata(interfaces.ConflictError, "IDEMPOTENCY_REQUEST_IN_PROGRESS",
+				"a request with this idempotency key is in progress", map[string]interface{}{
+					"idempotency_key": r.Header.Get(HeaderKey),
+				}).Wrap(ErrRequestInProgress))
+		default:
+			replay(w, existing)
+		}
+		return
+	}
+
+	rec := &responseRecorder{ResponseWriter: w, header: make(http.Header)}
+	completed := false
+	defer func() {
+		// A panic or a server error releases the key for the retry
+		if !completed {
+			m.config.Store.Delete(context.WithoutCancel(ctx), key, token)
+		}
+	}()
+
+	next.ServeHTTP(rec, r)
+	rec.flush()
+
+	if rec.status() >= http.StatusInternalServerError && !m.config.CacheServerErrors {
+		return
+	}
+
+	record := &Record{
+		Token:       token,
+		Fingerprint: fingerprint,
+		Completed:   true,
+		StatusCode:  rec.status(),
+		Header:      rec.header,
+		Body:        rec.body.Bytes(),
+		CreatedAt:   m.now(),
+	}
+	if err := m.config.Store.Save(context.WithoutCancel(ctx), key, record, m.config.TTL); err != nil {
+		return
+	}
+	completed = true
+}
+
+// appliesTo reports whether the method is made idempotent.
+func (m *Middleware) appliesTo(method string) bool {
+	for _, candidate := range m.config.Methods {
+		if strings.EqualFold(candidate, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// fingerprint hashes the method, path and body of the request. The body is
+// restored for the handler.
+func (m *Middleware) fingerprint(r *http.Request) (string, error) {
+	var body []byte
+	if r.Body != nil {
+		var err error
+		body, err = io.ReadAll(io.LimitReader(r.Body, m.config.MaxBodySize+1))
+		if err != nil {
+			return "", domainerrors.Wrap(err, interfaces.BadRequestError, "INVALID_REQUEST_BODY", "request body could not be read")
+		}
+		if int64(len(body)) > m.config.MaxBodySize {
+			return "", domainerrors.NewWithMetadata(interfaces.PayloadTooLargeError, "REQUEST_BODY_TOO_LARGE",
+				"request body too large", map[string]interface{}{"max_size": m.config.MaxBodySize}).Wrap(ErrBodyTooLarge)
+		}
+		r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(r.Method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(r.URL.RequestURI()))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// replay writes a stored response.
+func replay(w http.ResponseWriter, record *Record) {
+	for name, values := range record.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(HeaderReplayed, "true")
+	w.WriteHeader(record.StatusCode)
+	w.Write(record.Body)
+}
+
+// responseRecorder buffers the response so it can be stored before it is
+// sent.
+type responseRecorder struct {
+	http.ResponseWriter
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+// Header returns the buffered header.
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+// WriteHeader records the status code.
+func (r *responseRecorder) WriteHeader(code int) {
+	if r.code == 0 {
+		r.code = code
+	}
+}
+
+// Write buffers the body.
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.code == 0 {
+		r.code = http.StatusOK
+	}
+	return r.body.Write(b)
+}
+
+// status returns the recorded status, 200 when nothing was written.
+func (r *responseRecorder) status() int {
+	if r.code == 0 {
+		return http.StatusOK
+	}
+	return r.code
+}
+
+// flush sends the buffered response.
+func (r *responseRecorder) flush() {
+	for name, values := range r.header {
+		r.ResponseWriter.Header()[name] = values
+	}
+	r.ResponseWriter.WriteHeader(r.status())
+	r.ResponseWriter.Write(r.body.Bytes())
+}
@@ -0,0 +1,288 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/httpserver/middlewares"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRequest(method, body, key string) *http.Request {
+	req := httptest.NewRequest(method, "/payments", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(HeaderKey, key)
+	}
+	return req
+}
+
+func serve(h http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func decodeError(t *testing.T, rec *httptest.ResponseRecorder) map[string]interface{} {
+	t.Helper()
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	return body
+}
+
+func TestMiddleware_Replay(t *testing.T) {
+	var calls atomic.Int32
+	h := New(Config{}).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		w.Header().Set("X-Payment-Id", "pay_1")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"call":%d}`, n)
+	}))
+
+	first := serve(h, newRequest(http.MethodPost, `{"amount":10}`, "key-1"))
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Empty(t, first.Header().Get(HeaderReplayed))
+
+	second := serve(h, newRequest(http.MethodPost, `{"amount":10}`, "key-1"))
+	assert.Equal(t, http.StatusCreated, second.Code)
+	assert.Equal(t, "true", second.Header().Get(HeaderReplayed))
+	assert.Equal(t, "pay_1", second.Header().Get("X-Payment-Id"))
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, int32(1), calls.Load())
+
+	serve(h, newRequest(http.MethodPost, `{"amount":10}`, "key-2"))
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestMiddleware_KeyReused(t *testing.T) {
+	h := New(Config{}).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	serve(h, newRequest(http.MethodPost, `{"amount":10}`, "key-1"))
+	rec := serve(h, newRequest(http.MethodPost, `{"amount":99}`, "key-1"))
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Equal(t, "IDEMPOTENCY_KEY_REUSED", decodeError(t, rec)["code"])
+}
+
+// failingStore fails every lock, as an unreachable store does.
+type failingStore struct {
+	Store
+}
+
+func (failingStore) Lock(ctx context.Context, key string, record *Record, ttl time.Duration) (bool, *Record, error) {
+	return false, nil, errors.New("dial tcp 10.0.0.7:6379: connection refused")
+}
+
+func TestMiddleware_ErrorResponseProfile(t *testing.T) {
+	factory := domainerrors.GetFactory()
+	t.Cleanup(func() { factory.SetResponseProfile(nil) })
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	factory.SetResponseProfile(domainerrors.InternalProfile())
+	h := New(Config{}).Handler(next)
+	serve(h, newRequest(http.MethodPost, `{"amount":10}`, "key-1"))
+	rec := serve(h, newRequest(http.MethodPost, `{"amount":99}`, "key-1"))
+	assert.Equal(t, middlewares.ProblemContentType, rec.Header().Get("Content-Type"))
+	assert.Equal(t, map[string]interface{}{"idempotency_key": "key-1"}, decodeError(t, rec)["metadata"])
+
+	factory.SetResponseProfile(domainerrors.PublicProfile())
+	rec = serve(h, newRequest(http.MethodPost, `{"amount":99}`, "key-1"))
+	assert.Equal(t, "IDEMPOTENCY_KEY_REUSED", decodeError(t, rec)["code"])
+	assert.NotContains(t, decodeError(t, rec), "metadata")
+
+	rec = serve(New(Config{Store: failingStore{}}).Handler(next), newRequest(http.MethodPost, `{}`, "key-2"))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	body := decodeError(t, rec)
+	assert.Equal(t, "INTERNAL_ERROR", body["code"])
+	assert.NotContains(t, rec.Body.String(), "10.0.0.7", "public responses hide internal causes")
+}
+
+func TestMiddleware_ConcurrentDuplicate(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	h := New(Config{}).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	var first *httptest.ResponseRecorder
+	go func() {
+		defer wg.Done()
+		first = serve(h, newRequest(http.MethodPost, `{}`, "key-1"))
+	}()
+
+	<-started
+	rec := serve(h, newRequest(http.MethodPost, `{}`, "key-1"))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Equal(t, "IDEMPOTENCY_REQUEST_IN_PROGRESS", decodeError(t, rec)["code"])
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, http.StatusCreated, first.Code)
+}
+
+func TestMiddleware_ServerErrorReleasesKey(t *testing.T) {
+	var calls atomic.Int32
+	h := New(Config{}).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	assert.Equal(t, http.StatusServiceUnavailable, serve(h, newRequest(http.MethodPost, `{}`, "key-1")).Code)
+	assert.Equal(t, http.StatusCreated, serve(h, newRequest(http.MethodPost, `{}`, "key-1")).Code)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestMiddleware_PanicReleasesKey(t *testing.T) {
+	store := NewMemoryStore()
+	h := New(Config{Store: store}).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	assert.Panics(t, func() { serve(h, newRequest(http.MethodPost, `{}`, "key-1")) })
+	assert.Equal(t, 0, store.Len())
+}
+
+func TestMiddleware_ExpiredLockIsFenced(t *testing.T) {
+	for _, status := range []int{http.StatusCreated, http.StatusInternalServerError} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			store := NewMemoryStore()
+			now := time.Now()
+			store.now = func() time.Time { return now }
+
+			var calls atomic.Int32
+			var h http.Handler
+			h = New(Config{Store: store, LockTTL: time.Second}).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if calls.Add(1) == 1 {
+					// The first attempt outlives its lock and a retry takes the key
+					now = now.Add(2 * time.Second)
+					assert.Equal(t, http.StatusCreated, serve(h, newRequest(http.MethodPost, `{}`, "key-1")).Code)
+					w.WriteHeader(status)
+					fmt.Fprint(w, "first")
+					return
+				}
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprint(w, "second")
+			}))
+
+			assert.Equal(t, status, serve(h, newRequest(http.MethodPost, `{}`, "key-1")).Code)
+
+			replayed := serve(h, newRequest(http.MethodPost, `{}`, "key-1"))
+			assert.Equal(t, "true", replayed.Header().Get(HeaderReplayed))
+			assert.Equal(t, "second", replayed.Body.String())
+			assert.Equal(t, int32(2), calls.Load())
+		})
+	}
+}
+
+func TestMiddleware_KeyValidation(t *testing.T) {
+	var calls atomic.Int32
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	})
+
+	optional := New(Config{}).Handler(next)
+	assert.Equal(t, http.StatusOK, serve(optional, newRequest(http.MethodPost, `{}`, "")).Code)
+	assert.Equal(t, http.StatusOK, serve(optional, newRequest(http.MethodGet, "", "key-1")).Code)
+
+	required := New(Config{Required: true, MaxKeyLength: 8}).Handler(next)
+	rec := serve(required, newRequest(http.MethodPost, `{}`, ""))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "IDEMPOTENCY_KEY_REQUIRED", decodeError(t, rec)["code"])
+
+	rec = serve(required, newRequest(http.MethodPost, `{}`, strings.Repeat("k", 9)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "IDEMPOTENCY_KEY_INVALID", decodeError(t, rec)["code"])
+
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestMiddleware_Scope(t *testing.T) {
+	var calls atomic.Int32
+	h := New(Config{Scope: func(r *http.Request) string {
+		return r.Header.Get("X-Tenant")
+	}}).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+
+	for _, tenant := range []string{"a", "b", "a"} {
+		req := newRequest(http.MethodPost, `{}`, "key-1")
+		req.Header.Set("X-Tenant", tenant)
+		serve(h, req)
+	}
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestMiddleware_BodyRestored(t *testing.T) {
+	h := New(Config{}).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]int
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		assert.Equal(t, 10, payload["amount"])
+	}))
+	serve(h, newRequest(http.MethodPost, `{"amount":10}`, "key-1"))
+
+	limited := New(Config{MaxBodySize: 4}).Handler(h)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, serve(limited, newRequest(http.MethodPost, `{"amount":10}`, "key-2")).Code)
+}
+
+func TestMemoryStore_Expiration(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	locked, _, err := store.Lock(t.Context(), "k", &Record{Fingerprint: "a"}, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, locked)
+
+	locked, existing, err := store.Lock(t.Context(), "k", &Record{Fingerprint: "b"}, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, locked)
+	assert.Equal(t, "a", existing.Fingerprint)
+
+	now = now.Add(2 * time.Minute)
+	locked, _, err = store.Lock(t.Context(), "k", &Record{Fingerprint: "b"}, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, locked, "expired lock must be acquired again")
+
+	now = now.Add(2 * time.Minute)
+	store.Cleanup()
+	assert.Equal(t, 0, store.Len())
+}
+
+func TestMemoryStore_SweepOnLock(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	for _, key := range []string{"a", "b", "c"} {
+		_, _, err := store.Lock(t.Context(), key, &Record{}, time.Second)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 3, store.Len())
+
+	// Expired records of other keys are removed by a later Lock, without
+	// calling Cleanup
+	now = now.Add(memorySweepInterval)
+	_, _, err := store.Lock(t.Context(), "d", &Record{}, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, 1, store.Len())
+}
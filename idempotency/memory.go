@@ -0,0 +1,101 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memorySweepInterval is the minimum time between the sweeps of expired
+// records done by Lock.
+const memorySweepInterval = time.Minute
+
+// MemoryStore keeps records in process memory. It suits single instance
+// services and tests; use a shared store when several instances serve the
+// same clients. Expired records are removed by Lock at most once per
+// minute, so the store only holds the keys of the last TTL.
+type MemoryStore struct {
+	mu        sync.Mutex
+	records   map[string]memoryEntry
+	now       func() time.Time
+	nextSweep time.Time
+}
+
+// memoryEntry is a record with its expiration.
+type memoryEntry struct {
+	record    Record
+	expiresAt time.Time
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		records: make(map[string]memoryEntry),
+		now:     time.Now,
+	}
+}
+
+// Lock implements Store.
+func (s *MemoryStore) Lock(ctx context.Context, key string, record *Record, ttl time.Duration) (bool, *Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if !now.Before(s.nextSweep) {
+		s.sweep(now)
+	}
+	if entry, ok := s.records[key]; ok && now.Before(entry.expiresAt) {
+		existing := entry.record
+		return false, &existing, nil
+	}
+
+	s.records[key] = memoryEntry{record: *record, expiresAt: now.Add(ttl)}
+	return true, nil, nil
+}
+
+// Save implements Store.
+func (s *MemoryStore) Save(ctx context.Context, key string, record *Record, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.records[key]; !ok || entry.record.Token != record.Token {
+		return ErrLockLost
+	}
+	s.records[key] = memoryEntry{record: *record, expiresAt: s.now().Add(ttl)}
+	return nil
+}
+
+// Delete implements Store.
+func (s *MemoryStore) Delete(ctx context.Context, key, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.records[key]; ok && entry.record.Token == token {
+		delete(s.records, key)
+	}
+	return nil
+}
+
+// Cleanup removes the expired records. Lock already does it periodically.
+func (s *MemoryStore) Cleanup() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(s.now())
+}
+
+// sweep removes the records expired at now. The caller holds the mutex.
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, entry := range s.records {
+		if !now.Before(entry.expiresAt) {
+			delete(s.records, key)
+		}
+	}
+	s.nextSweep = now.Add(memorySweepInterval)
+}
+
+// Len returns the number of stored records, expired ones included.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.records)
+}
@@ -0,0 +1,126 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/db/postgres"
+	pginterfaces "github.com/fsvxavier/nexs-lib/db/postgres/interfaces"
+)
+
+// PostgresConn is the part of the db/postgres connection used by the
+// PostgresStore; interfaces.IConn and transactions satisfy it.
+type PostgresConn interface {
+	Exec(ctx context.Context, query string, args ...interface{}) (pginterfaces.ICommandTag, error)
+	QueryRow(ctx context.Context, query string, args ...interface{}) pginterfaces.IRow
+}
+
+// PostgresStore keeps records in a PostgreSQL table created with Schema. The
+// lock is an insert that only succeeds when the key is absent or expired.
+type PostgresStore struct {
+	conn  PostgresConn
+	table string
+}
+
+// NewPostgresStore creates a PostgresStore on the table, "idempotency_keys"
+// when empty.
+func NewPostgresStore(conn PostgresConn, table string) (*PostgresStore, error) {
+	if table == "" {
+		table = "idempotency_keys"
+	}
+	if !postgres.ValidIdentifier(table) {
+		return nil, fmt.Errorf("invalid table name: %q", table)
+	}
+	return &PostgresStore{conn: conn, table: table}, nil
+}
+
+// Schema returns the statement creating the table.
+func (s *PostgresStore) Schema() string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	key TEXT PRIMARY KEY,
+	record JSONB NOT NULL,
+	expires_at TIMESTAMPTZ NOT NULL
+)`, s.table)
+}
+
+// Migrate creates the table when it does not exist.
+func (s *PostgresStore) Migrate(ctx context.Context) error {
+	if _, err := s.conn.Exec(ctx, s.Schema()); err != nil {
+		return fmt.Errorf("failed to create table %s: %w", s.table, err)
+	}
+	return nil
+}
+
+// Lock implements Store.
+func (s *PostgresStore) Lock(ctx context.Context, key string, record *Record, ttl time.Duration) (bool, *Record, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to encode record: %w", err)
+	}
+
+	tag, err := s.conn.Exec(ctx, fmt.Sprintf(`INSERT INTO %s (key, record, expires_at)
+VALUES ($1, $2, now() + $3 * interval '1 millisecond')
+ON CONFLICT (key) DO UPDATE SET record = EXCLUDED.record, expires_at = EXCLUDED.expires_at
+WHERE %s.expires_at <= now()`, s.table, s.table), key, string(data), ttl.Milliseconds())
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to lock key: %w", err)
+	}
+	if tag.RowsAffected() == 1 {
+		return true, nil, nil
+	}
+
+	// The subquery always yields one row, empty when the key expired meanwhile
+	var stored string
+	if err := s.conn.QueryRow(ctx, fmt.Sprintf(`SELECT COALESCE(
+	(SELECT record::text FROM %s WHERE key = $1 AND expires_at > now()), '')`, s.table), key).Scan(&stored); err != nil {
+		return false, nil, fmt.Errorf("failed to read record: %w", err)
+	}
+	if stored == "" {
+		existing := *record
+		return false, &existing, nil
+	}
+
+	var existing Record
+	if err := json.Unmarshal([]byte(stored), &existing); err != nil {
+		return false, nil, fmt.Errorf("failed to decode record: %w", err)
+	}
+	return false, &existing, nil
+}
+
+// Save implements Store.
+func (s *PostgresStore) Save(ctx context.Context, key string, record *Record, ttl time.Duration) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode record: %w", err)
+	}
+
+	tag, err := s.conn.Exec(ctx, fmt.Sprintf(`UPDATE %s SET record = $2, expires_at = now() + $3 * interval '1 millisecond'
+WHERE key = $1 AND record->>'token' = $4`, s.table), key, string(data), ttl.Milliseconds(), record.Token)
+	if err != nil {
+		return fmt.Errorf("failed to store record: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+// Delete implements Store.
+func (s *PostgresStore) Delete(ctx context.Context, key, token string) error {
+	if _, err := s.conn.Exec(ctx, fmt.Sprintf(`DELETE FROM %s WHERE key = $1 AND record->>'token' = $2`, s.table),
+		key, token); err != nil {
+		return fmt.Errorf("failed to delete key: %w", err)
+	}
+	return nil
+}
+
+// Cleanup deletes the expired records and returns how many were removed.
+func (s *PostgresStore) Cleanup(ctx context.Context) (int64, error) {
+	tag, err := s.conn.Exec(ctx, fmt.Sprintf(`DELETE FROM %s WHERE expires_at <= now()`, s.table))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired records: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
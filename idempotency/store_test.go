@@ -0,0 +1,189 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	valkeyinterfaces "github.com/fsvxavier/nexs-lib/cache/valkey/interfaces"
+	pginterfaces "github.com/fsvxavier/nexs-lib/db/postgres/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeValkey emulates the scripts of the ValkeyStore.
+type fakeValkey struct {
+	valkeyinterfaces.IClient
+	mu      sync.Mutex
+	strings map[string]string
+	expires map[string]string
+}
+
+func newFakeValkey() *fakeValkey {
+	return &fakeValkey{strings: map[string]string{}, expires: map[string]string{}}
+}
+
+func storedToken(data string) string {
+	var record Record
+	_ = json.Unmarshal([]byte(data), &record)
+	return record.Token
+}
+
+func (f *fakeValkey) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := keys[0]
+	current, exists := f.strings[key]
+	switch script {
+	case valkeyLockScript:
+		if exists {
+			return current, nil
+		}
+		f.strings[key] = args[0].(string)
+		f.expires[key] = fmt.Sprint(args[1])
+		return int64(1), nil
+	case valkeySaveScript:
+		if !exists || storedToken(current) != args[0] {
+			return int64(0), nil
+		}
+		f.strings[key] = args[1].(string)
+		f.expires[key] = fmt.Sprint(args[2])
+		return int64(1), nil
+	case valkeyDeleteScript:
+		if exists && storedToken(current) == args[0] {
+			delete(f.strings, key)
+			return int64(1), nil
+		}
+		return int64(0), nil
+	}
+	return nil, errors.New("unknown script")
+}
+
+func TestValkeyStore(t *testing.T) {
+	client := newFakeValkey()
+	store := NewValkeyStore(client, "")
+	ctx := context.Background()
+
+	locked, _, err := store.Lock(ctx, "k", &Record{Token: "t1", Fingerprint: "a"}, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, locked)
+	assert.Equal(t, "60000", client.expires["idempotency:k"])
+
+	locked, existing, err := store.Lock(ctx, "k", &Record{Token: "t2", Fingerprint: "b"}, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, locked)
+	assert.Equal(t, "a", existing.Fingerprint)
+	assert.False(t, existing.Completed)
+
+	assert.ErrorIs(t, store.Save(ctx, "k", &Record{Token: "t2", Fingerprint: "a", Completed: true}, time.Hour), ErrLockLost)
+	require.NoError(t, store.Save(ctx, "k", &Record{Token: "t1", Fingerprint: "a", Completed: true, StatusCode: 201, Body: []byte("ok")}, time.Hour))
+	assert.Equal(t, "3600000", client.expires["idempotency:k"])
+
+	_, existing, err = store.Lock(ctx, "k", &Record{Token: "t3", Fingerprint: "a"}, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, existing.Completed)
+	assert.Equal(t, []byte("ok"), existing.Body)
+
+	require.NoError(t, store.Delete(ctx, "k", "t2"))
+	assert.Contains(t, client.strings, "idempotency:k", "another token does not release the key")
+	require.NoError(t, store.Delete(ctx, "k", "t1"))
+	locked, _, err = store.Lock(ctx, "k", &Record{Token: "t4", Fingerprint: "b"}, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, locked)
+}
+
+// fakeTag is a command tag with the affected rows.
+type fakeTag struct {
+	pginterfaces.ICommandTag
+	rows int64
+}
+
+func (t fakeTag) RowsAffected() int64 { return t.rows }
+
+// fakeRow scans a single string.
+type fakeRow struct{ value string }
+
+func (r fakeRow) Scan(dest ...any) error {
+	*dest[0].(*string) = r.value
+	return nil
+}
+
+// fakePostgres emulates the statements of the PostgresStore.
+type fakePostgres struct {
+	records map[string]string
+	queries []string
+}
+
+func (f *fakePostgres) Exec(ctx context.Context, query string, args ...interface{}) (pginterfaces.ICommandTag, error) {
+	f.queries = append(f.queries, query)
+	key, _ := args[0].(string)
+	current, exists := f.records[key]
+	switch {
+	case strings.HasPrefix(query, "INSERT"):
+		if exists {
+			return fakeTag{}, nil
+		}
+		f.records[key] = args[1].(string)
+		return fakeTag{rows: 1}, nil
+	case strings.HasPrefix(query, "UPDATE"):
+		if !exists || storedToken(current) != args[3] {
+			return fakeTag{}, nil
+		}
+		f.records[key] = args[1].(string)
+		return fakeTag{rows: 1}, nil
+	case strings.HasPrefix(query, "DELETE"):
+		if !exists || storedToken(current) != args[1] {
+			return fakeTag{}, nil
+		}
+		delete(f.records, key)
+		return fakeTag{rows: 1}, nil
+	}
+	return fakeTag{}, nil
+}
+
+func (f *fakePostgres) QueryRow(ctx context.Context, query string, args ...interface{}) pginterfaces.IRow {
+	return fakeRow{value: f.records[args[0].(string)]}
+}
+
+func TestPostgresStore(t *testing.T) {
+	conn := &fakePostgres{records: map[string]string{}}
+	store, err := NewPostgresStore(conn, "")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	assert.Contains(t, store.Schema(), "CREATE TABLE IF NOT EXISTS idempotency_keys")
+
+	locked, _, err := store.Lock(ctx, "k", &Record{Token: "t1", Fingerprint: "a"}, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, locked)
+
+	locked, existing, err := store.Lock(ctx, "k", &Record{Token: "t2", Fingerprint: "b"}, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, locked)
+	assert.Equal(t, "a", existing.Fingerprint)
+
+	assert.ErrorIs(t, store.Save(ctx, "k", &Record{Token: "t2", Fingerprint: "a", Completed: true}, time.Hour), ErrLockLost)
+	require.NoError(t, store.Save(ctx, "k", &Record{Token: "t1", Fingerprint: "a", Completed: true, StatusCode: 201}, time.Hour))
+	_, existing, err = store.Lock(ctx, "k", &Record{Token: "t3", Fingerprint: "a"}, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, existing.Completed)
+	assert.Equal(t, 201, existing.StatusCode)
+
+	require.NoError(t, store.Delete(ctx, "k", "t2"))
+	assert.Len(t, conn.records, 1)
+	require.NoError(t, store.Delete(ctx, "k", "t1"))
+	assert.Empty(t, conn.records)
+}
+
+func TestNewPostgresStore_InvalidTable(t *testing.T) {
+	_, err := NewPostgresStore(&fakePostgres{}, "keys; DROP TABLE users")
+	assert.Error(t, err)
+
+	_, err = NewPostgresStore(&fakePostgres{}, "billing.idempotency_keys")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,116 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	valkeyinterfaces "github.com/fsvxavier/nexs-lib/cache/valkey/interfaces"
+)
+
+// Scripts of the ValkeyStore. The record and its TTL are written by a single
+// command, so a lock never outlives LockTTL; Save and Delete compare the
+// token stored in the record before acting.
+const (
+	// valkeyLockScript returns 1 when the lock was acquired, otherwise the
+	// stored record, or nil when it expired meanwhile.
+	valkeyLockScript = `if redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+	return 1
+end
+return redis.call('GET', KEYS[1])`
+
+	valkeySaveScript = `local current = redis.call('GET', KEYS[1])
+if not current or cjson.decode(current).token ~= ARGV[1] then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+return 1`
+
+	valkeyDeleteScript = `local current = redis.call('GET', KEYS[1])
+if current and cjson.decode(current).token == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0`
+)
+
+// ValkeyStore keeps records in Valkey or Redis through the cache/valkey
+// client, sharing keys between instances. Each key holds its record, written
+// with SET NX PX by a Lua script, so the provider must support Eval.
+type ValkeyStore struct {
+	client valkeyinterfaces.IClient
+	prefix string
+}
+
+// NewValkeyStore creates a ValkeyStore. Keys are prefixed with prefix,
+// "idempotency" when empty.
+func NewValkeyStore(client valkeyinterfaces.IClient, prefix string) *ValkeyStore {
+	if prefix == "" {
+		prefix = "idempotency"
+	}
+	return &ValkeyStore{client: client, prefix: prefix}
+}
+
+// Lock implements Store.
+func (s *ValkeyStore) Lock(ctx context.Context, key string, record *Record, ttl time.Duration) (bool, *Record, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to encode record: %w", err)
+	}
+
+	result, err := s.client.Eval(ctx, valkeyLockScript, []string{s.recordKey(key)}, string(data), milliseconds(ttl))
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to lock key: %w", err)
+	}
+
+	switch stored := result.(type) {
+	case int64:
+		return true, nil, nil
+	case string:
+		var existing Record
+		if err := json.Unmarshal([]byte(stored), &existing); err != nil {
+			return false, nil, fmt.Errorf("failed to decode record: %w", err)
+		}
+		return false, &existing, nil
+	case nil:
+		// The record expired between the commands of the script
+		return false, &Record{Fingerprint: record.Fingerprint}, nil
+	default:
+		return false, nil, fmt.Errorf("unexpected lock result %T", result)
+	}
+}
+
+// Save implements Store.
+func (s *ValkeyStore) Save(ctx context.Context, key string, record *Record, ttl time.Duration) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode record: %w", err)
+	}
+
+	result, err := s.client.Eval(ctx, valkeySaveScript, []string{s.recordKey(key)}, record.Token, string(data), milliseconds(ttl))
+	if err != nil {
+		return fmt.Errorf("failed to store record: %w", err)
+	}
+	if saved, _ := result.(int64); saved == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+// Delete implements Store.
+func (s *ValkeyStore) Delete(ctx context.Context, key, token string) error {
+	if _, err := s.client.Eval(ctx, valkeyDeleteScript, []string{s.recordKey(key)}, token); err != nil {
+		return fmt.Errorf("failed to delete key: %w", err)
+	}
+	return nil
+}
+
+// recordKey returns the key of the record.
+func (s *ValkeyStore) recordKey(key string) string {
+	return s.prefix + ":" + key
+}
+
+// milliseconds converts the TTL to the PX argument, at least 1.
+func milliseconds(ttl time.Duration) int64 {
+	return max(ttl.Milliseconds(), 1)
+}
@@ -0,0 +1,511 @@
+package pgqueue
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pginterfaces "github.com/fsvxavier/nexs-lib/db/postgres/interfaces"
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRow is a job row of the fake table.
+type fakeRow struct {
+	id          int64
+	queue       string
+	kind        string
+	payload     string
+	status      string
+	attempts    int
+	maxAttempts int
+	runAt       time.Time
+	lockedUntil time.Time
+	lastError   string
+	createdAt   time.Time
+}
+
+// fakeDB emulates the statements of the queue on an in-memory table.
+type fakeDB struct {
+	mu       sync.Mutex
+	rows     map[int64]*fakeRow
+	nextID   int64
+	acquired int
+	released int
+	failNext error
+}
+
+func newFakeDB() *fakeDB {
+	return &fakeDB{rows: map[int64]*fakeRow{}}
+}
+
+func (db *fakeDB) Acquire(ctx context.Context) (pginterfaces.IConn, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.acquired++
+	return &fakeConn{db: db}, nil
+}
+
+func (db *fakeDB) row(id int64) fakeRow {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return *db.rows[id]
+}
+
+// fakeConn implements the methods used by the queue.
+type fakeConn struct {
+	pginterfaces.IConn
+	db *fakeDB
+}
+
+func (c *fakeConn) Release() {
+	c.db.mu.Lock()
+	c.db.released++
+	c.db.mu.Unlock()
+}
+
+type fakeTag struct {
+	pginterfaces.ICommandTag
+	rows int64
+}
+
+func (t fakeTag) RowsAffected() int64 { return t.rows }
+
+type fakeScanner struct {
+	values []any
+	err    error
+}
+
+func (s fakeScanner) Scan(dest ...any) error {
+	if s.err != nil {
+		return s.err
+	}
+	for i, d := range dest {
+		switch d := d.(type) {
+		case *int64:
+			*d = s.values[i].(int64)
+		case *int:
+			*d = s.values[i].(int)
+		case *string:
+			*d = s.values[i].(string)
+		case *float64:
+			*d = s.values[i].(float64)
+		case *time.Time:
+			*d = s.values[i].(time.Time)
+		}
+	}
+	return nil
+}
+
+type fakeRows struct {
+	pginterfaces.IRows
+	rows [][]any
+	i    int
+}
+
+func (r *fakeRows) Next() bool {
+	r.i++
+	return r.i <= len(r.rows)
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	return fakeScanner{values: r.rows[r.i-1]}.Scan(dest...)
+}
+
+func (r *fakeRows) Close() error { return nil }
+func (r *fakeRows) Err() error   { return nil }
+
+func ms(arg any) time.Duration {
+	return time.Duration(arg.(int64)) * time.Millisecond
+}
+
+func (c *fakeConn) QueryRow(ctx context.Context, query string, args ...interface{}) pginterfaces.IRow {
+	db := c.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	now := time.Now()
+
+	switch {
+	case strings.HasPrefix(query, "INSERT INTO"):
+		runAt := now
+		if t := args[4].(*time.Time); t != nil {
+			runAt = *t
+		}
+		db.nextID++
+		db.rows[db.nextID] = &fakeRow{
+			id: db.nextID, queue: args[0].(string), kind: args[1].(string), payload: args[2].(string),
+			status: StatusPending, maxAttempts: args[3].(int), runAt: runAt.Add(ms(args[5])), createdAt: now,
+		}
+		return fakeScanner{values: []any{db.nextID}}
+	case strings.Contains(query, "count(*) FILTER"):
+		var ready, scheduled, running, failed int64
+		var oldest time.Time
+		for _, r := range db.rows {
+			if r.queue != args[0] {
+				continue
+			}
+			switch {
+			case r.status == StatusPending && !r.runAt.After(now):
+				ready++
+				if oldest.IsZero() || r.runAt.Before(oldest) {
+					oldest = r.runAt
+				}
+			case r.status == StatusPending:
+				scheduled++
+			case r.status == StatusRunning:
+				running++
+			case r.status == StatusFailed:
+				failed++
+			}
+		}
+		age := 0.0
+		if !oldest.IsZero() {
+			age = now.Sub(oldest).Seconds()
+		}
+		return fakeScanner{values: []any{ready, scheduled, running, failed, age}}
+	}
+	return fakeScanner{err: errors.New("unexpected query")}
+}
+
+func (c *fakeConn) Query(ctx context.Context, query string, args ...interface{}) (pginterfaces.IRows, error) {
+	db := c.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.failNext != nil {
+		err := db.failNext
+		db.failNext = nil
+		return nil, err
+	}
+	if !strings.Contains(query, "FOR UPDATE SKIP LOCKED") {
+		return nil, errors.New("unexpected query")
+	}
+
+	now := time.Now()
+	var ready []*fakeRow
+	for _, r := range db.rows {
+		if r.queue == args[0] && !r.runAt.After(now) &&
+			(r.status == StatusPending || (r.status == StatusRunning && r.lockedUntil.Before(now))) {
+			ready = append(ready, r)
+		}
+	}
+	sort.Slice(ready, func(i, j int) bool { return ready[i].id < ready[j].id })
+	if limit := args[1].(int); len(ready) > limit {
+		ready = ready[:limit]
+	}
+
+	rows := &fakeRows{}
+	for _, r := range ready {
+		r.status = StatusRunning
+		r.attempts++
+		r.lockedUntil = now.Add(ms(args[2]))
+		rows.rows = append(rows.rows, []any{r.id, r.queue, r.kind, r.payload, r.attempts, r.maxAttempts,
+			r.runAt, r.createdAt, r.lastError})
+	}
+	return rows, nil
+}
+
+func (c *fakeConn) Exec(ctx context.Context, query string, args ...interface{}) (pginterfaces.ICommandTag, error) {
+	db := c.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if strings.HasPrefix(query, "CREATE TABLE") {
+		return fakeTag{}, nil
+	}
+
+	r, ok := db.rows[args[0].(int64)]
+	if !ok {
+		return fakeTag{}, nil
+	}
+	switch {
+	case strings.Contains(query, "attempts = 0"):
+		if r.status != StatusFailed || r.queue != args[1] {
+			return fakeTag{}, nil
+		}
+		r.status, r.attempts, r.runAt = StatusPending, 0, time.Now()
+		return fakeTag{rows: 1}, nil
+	case r.status != StatusRunning || r.attempts != args[1].(int):
+		// Claimed again after the visibility timeout
+		return fakeTag{}, nil
+	case strings.HasPrefix(query, "DELETE FROM"):
+		delete(db.rows, r.id)
+	case strings.Contains(query, "SET status = 'pending'"):
+		r.status, r.runAt, r.lastError = StatusPending, time.Now().Add(ms(args[2])), args[3].(string)
+	case strings.Contains(query, "SET status = 'failed'"):
+		r.status, r.lastError = StatusFailed, args[2].(string)
+	default:
+		return nil, errors.New("unexpected statement")
+	}
+	return fakeTag{rows: 1}, nil
+}
+
+type emailPayload struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+}
+
+func newTestQueue(t *testing.T, db *fakeDB) *Queue {
+	t.Helper()
+	q, err := New(db, Config{Name: "mail"})
+	require.NoError(t, err)
+	return q
+}
+
+func runWorker(t *testing.T, w *Worker) (stop func()) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- w.Run(ctx) }()
+	return func() {
+		cancel()
+		require.NoError(t, <-done)
+	}
+}
+
+func TestNew(t *testing.T) {
+	q, err := New(newFakeDB(), Config{})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultQueue, q.Name())
+	assert.Contains(t, q.Schema(), "CREATE TABLE IF NOT EXISTS pgqueue_jobs")
+
+	q, err = New(newFakeDB(), Config{Table: "jobs.queue"})
+	require.NoError(t, err)
+	assert.Contains(t, q.Schema(), "queue_fetch_idx ON jobs.queue")
+	require.NoError(t, q.Migrate(context.Background()))
+
+	_, err = New(newFakeDB(), Config{Table: "jobs; DROP TABLE users"})
+	assert.Error(t, err)
+}
+
+func TestEnqueue(t *testing.T) {
+	db := newFakeDB()
+	q := newTestQueue(t, db)
+	ctx := context.Background()
+
+	id, err := Enqueue(ctx, q, "send_email", emailPayload{To: "a@b.com"})
+	require.NoError(t, err)
+	row := db.row(id)
+	assert.Equal(t, "mail", row.queue)
+	assert.Equal(t, `{"to":"a@b.com","subject":""}`, row.payload)
+	assert.Equal(t, DefaultMaxAttempts, row.maxAttempts)
+
+	id, err = Enqueue(ctx, q, "send_email", emailPayload{}, WithDelay(time.Hour), WithMaxAttempts(3))
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), db.row(id).runAt, time.Second)
+	assert.Equal(t, 3, db.row(id).maxAttempts)
+
+	runAt := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	id, err = Enqueue(ctx, q, "send_email", emailPayload{}, WithRunAt(runAt))
+	require.NoError(t, err)
+	assert.Equal(t, runAt, db.row(id).runAt)
+
+	_, err = Enqueue(ctx, q, "", emailPayload{})
+	assert.ErrorIs(t, err, ErrInvalidJob)
+	_, err = Enqueue(ctx, q, "send_email", emailPayload{}, WithMaxAttempts(0))
+	assert.ErrorIs(t, err, ErrInvalidJob)
+	_, err = Enqueue(ctx, q, "send_email", make(chan int))
+	assert.ErrorIs(t, err, ErrInvalidJob)
+
+	// Enqueueing through a transaction does not use the pool
+	acquired := db.acquired
+	tx := &fakeConn{db: db}
+	_, err = Enqueue(ctx, q, "send_email", emailPayload{}, WithConn(tx))
+	require.NoError(t, err)
+	assert.Equal(t, acquired, db.acquired)
+	assert.Equal(t, db.acquired, db.released, "every acquired connection is released")
+
+	stats, err := q.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), stats.Ready)
+	assert.Equal(t, int64(2), stats.Scheduled)
+}
+
+func TestWorker_Process(t *testing.T) {
+	db := newFakeDB()
+	q := newTestQueue(t, db)
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var sent []string
+	w := NewWorker(q, WorkerConfig{Concurrency: 2, PollInterval: 5 * time.Millisecond})
+	Handle(w, "send_email", func(ctx context.Context, job *Job, payload emailPayload) error {
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, payload.To)
+		return nil
+	})
+
+	for _, to := range []string{"a@b.com", "c@d.com", "e@f.com"} {
+		_, err := Enqueue(ctx, q, "send_email", emailPayload{To: to})
+		require.NoError(t, err)
+	}
+
+	stop := runWorker(t, w)
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(sent) == 3
+	}, 2*time.Second, 5*time.Millisecond)
+	stop()
+
+	assert.ElementsMatch(t, []string{"a@b.com", "c@d.com", "e@f.com"}, sent)
+	stats, err := q.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, Stats{}, stats, "completed jobs are deleted")
+
+	m := w.Metrics()
+	assert.Equal(t, int64(3), m.Claimed)
+	assert.Equal(t, int64(3), m.Succeeded)
+	assert.Zero(t, m.InFlight)
+	assert.Positive(t, m.AvgLatency)
+}
+
+func TestWorker_RetryAndFail(t *testing.T) {
+	db := newFakeDB()
+	q := newTestQueue(t, db)
+	ctx := context.Background()
+
+	var calls atomic.Int32
+	w := NewWorker(q, WorkerConfig{
+		PollInterval: 5 * time.Millisecond,
+		Backoff:      func(int) time.Duration { return 0 },
+	})
+	w.HandleRaw("flaky", func(ctx context.Context, job *Job) error {
+		calls.Add(1)
+		return errors.New("smtp unavailable")
+	})
+
+	id, err := Enqueue(ctx, q, "flaky", emailPayload{}, WithMaxAttempts(3))
+	require.NoError(t, err)
+
+	stop := runWorker(t, w)
+	require.Eventually(t, func() bool { return db.row(id).status == StatusFailed }, 2*time.Second, 5*time.Millisecond)
+	stop()
+
+	assert.Equal(t, int32(3), calls.Load())
+	row := db.row(id)
+	assert.Equal(t, 3, row.attempts)
+	assert.Equal(t, "smtp unavailable", row.lastError)
+	m := w.Metrics()
+	assert.Equal(t, int64(2), m.Retried)
+	assert.Equal(t, int64(1), m.Failed)
+
+	found, err := q.Retry(ctx, id)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, StatusPending, db.row(id).status)
+	assert.Zero(t, db.row(id).attempts)
+}
+
+func TestWorker_PermanentErrors(t *testing.T) {
+	db := newFakeDB()
+	q := newTestQueue(t, db)
+	ctx := context.Background()
+
+	w := NewWorker(q, WorkerConfig{PollInterval: 5 * time.Millisecond})
+	Handle(w, "typed", func(ctx context.Context, job *Job, payload emailPayload) error {
+		return nil
+	})
+	w.HandleRaw("invalid", func(ctx context.Context, job *Job) error {
+		return domainerrors.New(interfaces.ValidationError, "INVALID_RECIPIENT", "invalid recipient")
+	})
+	w.HandleRaw("panics", func(ctx context.Context, job *Job) error {
+		panic("boom")
+	})
+
+	undecodable, err := q.EnqueueRaw(ctx, "typed", []byte(`"not an object"`))
+	require.NoError(t, err)
+	invalid, err := Enqueue(ctx, q, "invalid", emailPayload{})
+	require.NoError(t, err)
+	panics, err := Enqueue(ctx, q, "panics", emailPayload{}, WithMaxAttempts(1))
+	require.NoError(t, err)
+
+	stop := runWorker(t, w)
+	require.Eventually(t, func() bool {
+		return db.row(undecodable).status == StatusFailed &&
+			db.row(invalid).status == StatusFailed &&
+			db.row(panics).status == StatusFailed
+	}, 2*time.Second, 5*time.Millisecond)
+	stop()
+
+	assert.Equal(t, 1, db.row(undecodable).attempts, "undecodable payloads are not retried")
+	assert.Equal(t, 1, db.row(invalid).attempts, "validation errors are not retried")
+	assert.Contains(t, db.row(panics).lastError, "panicked: boom")
+}
+
+func TestWorker_UnknownKindIsRetried(t *testing.T) {
+	db := newFakeDB()
+	q := newTestQueue(t, db)
+
+	id, err := Enqueue(context.Background(), q, "unknown", emailPayload{})
+	require.NoError(t, err)
+
+	w := NewWorker(q, WorkerConfig{PollInterval: 5 * time.Millisecond, Backoff: func(int) time.Duration { return time.Hour }})
+	stop := runWorker(t, w)
+	require.Eventually(t, func() bool { return w.Metrics().Retried == 1 }, 2*time.Second, 5*time.Millisecond)
+	stop()
+
+	row := db.row(id)
+	assert.Equal(t, StatusPending, row.status)
+	assert.True(t, row.runAt.After(time.Now().Add(50*time.Minute)))
+	assert.Contains(t, row.lastError, `no handler registered for job kind "unknown"`)
+}
+
+func TestWorker_VisibilityTimeout(t *testing.T) {
+	db := newFakeDB()
+	q := newTestQueue(t, db)
+
+	release := make(chan struct{})
+	var calls atomic.Int32
+	w := NewWorker(q, WorkerConfig{PollInterval: 5 * time.Millisecond, VisibilityTimeout: 30 * time.Millisecond})
+	w.HandleRaw("slow", func(ctx context.Context, job *Job) error {
+		calls.Add(1)
+		if job.Attempt == 1 {
+			<-ctx.Done()
+			<-release
+			return nil
+		}
+		return nil
+	})
+
+	id, err := Enqueue(context.Background(), q, "slow", emailPayload{})
+	require.NoError(t, err)
+
+	stop := runWorker(t, w)
+	// The job reappears after the visibility timeout and the second attempt
+	// completes it; the late result of the first attempt is ignored
+	require.Eventually(t, func() bool { return calls.Load() == 2 }, 2*time.Second, 5*time.Millisecond)
+	require.Eventually(t, func() bool {
+		db.mu.Lock()
+		defer db.mu.Unlock()
+		_, ok := db.rows[id]
+		return !ok
+	}, 2*time.Second, 5*time.Millisecond)
+	close(release)
+	stop()
+}
+
+func TestWorker_ClaimError(t *testing.T) {
+	db := newFakeDB()
+	q := newTestQueue(t, db)
+	db.failNext = errors.New("connection refused")
+
+	var done atomic.Bool
+	w := NewWorker(q, WorkerConfig{PollInterval: 5 * time.Millisecond})
+	w.HandleRaw("job", func(ctx context.Context, job *Job) error {
+		done.Store(true)
+		return nil
+	})
+	_, err := Enqueue(context.Background(), q, "job", emailPayload{})
+	require.NoError(t, err)
+
+	stop := runWorker(t, w)
+	require.Eventually(t, done.Load, 2*time.Second, 5*time.Millisecond, "claims are retried after errors")
+	stop()
+}
@@ -0,0 +1,369 @@
+// Package pgqueue is a job queue backed by a PostgreSQL table, for
+// lightweight async jobs without a message broker. Jobs are enqueued with a
+// typed JSON payload, optionally delayed, and consumed by a worker pool that
+// claims them with SELECT ... FOR UPDATE SKIP LOCKED. Claimed jobs become
+// visible again when their visibility timeout expires, and failed jobs are
+// retried with backoff until their maximum number of attempts.
+package pgqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/db/postgres"
+	pginterfaces "github.com/fsvxavier/nexs-lib/db/postgres/interfaces"
+)
+
+// Job statuses stored in the status column.
+const (
+	StatusPending = "pending"
+	StatusRunning = "running"
+	StatusFailed  = "failed"
+)
+
+// Defaults of the queue.
+const (
+	DefaultTable       = "pgqueue_jobs"
+	DefaultQueue       = "default"
+	DefaultMaxAttempts = 25
+)
+
+// ErrInvalidJob is returned when a job cannot be enqueued.
+var ErrInvalidJob = errors.New("pgqueue: invalid job")
+
+// Conn is the part of the db/postgres connection used by the queue;
+// interfaces.IConn and transactions satisfy it.
+type Conn interface {
+	Exec(ctx context.Context, query string, args ...interface{}) (pginterfaces.ICommandTag, error)
+	QueryRow(ctx context.Context, query string, args ...interface{}) pginterfaces.IRow
+	Query(ctx context.Context, query string, args ...interface{}) (pginterfaces.IRows, error)
+}
+
+// Pool provides connections to the queue; interfaces.IPool satisfies it.
+// Every operation acquires its own connection, so workers run concurrently.
+type Pool interface {
+	Acquire(ctx context.Context) (pginterfaces.IConn, error)
+}
+
+// Job is a job claimed from the queue.
+type Job struct {
+	ID          int64
+	Queue       string
+	Kind        string
+	Payload     json.RawMessage
+	Attempt     int
+	MaxAttempts int
+	RunAt       time.Time
+	CreatedAt   time.Time
+	LastError   string
+
+	// visibleAt is when the claim of the job expires.
+	visibleAt time.Time
+}
+
+// Config configures a Queue.
+type Config struct {
+	// Table stores the jobs. Defaults to DefaultTable.
+	Table string
+
+	// Name is the queue name; queues sharing a table are consumed
+	// independently. Defaults to DefaultQueue.
+	Name string
+}
+
+// Queue enqueues jobs into a queue of the table.
+type Queue struct {
+	pool  Pool
+	table string
+	name  string
+}
+
+// New creates a Queue.
+func New(pool Pool, config Config) (*Queue, error) {
+	if config.Table == "" {
+		config.Table = DefaultTable
+	}
+	if config.Name == "" {
+		config.Name = DefaultQueue
+	}
+	if !postgres.ValidIdentifier(config.Table) {
+		return nil, fmt.Errorf("invalid table name: %q", config.Table)
+	}
+	return &Queue{pool: pool, table: config.Table, name: config.Name}, nil
+}
+
+// Name returns the queue name.
+func (q *Queue) Name() string {
+	return q.name
+}
+
+// Schema returns the statements creating the table and its index.
+func (q *Queue) Schema() string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %[1]s (
+	id BIGSERIAL PRIMARY KEY,
+	queue TEXT NOT NULL,
+	kind TEXT NOT NULL,
+	payload JSONB NOT NULL,
+	status TEXT NOT NULL DEFAULT 'pending',
+	attempts INTEGER NOT NULL DEFAULT 0,
+	max_attempts INTEGER NOT NULL,
+	run_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	locked_until TIMESTAMPTZ,
+	last_error TEXT,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS %[2]s_fetch_idx ON %[1]s (queue, run_at) WHERE status <> 'failed'`,
+		q.table, postgres.UnqualifiedName(q.table))
+}
+
+// Migrate creates the table when it does not exist.
+func (q *Queue) Migrate(ctx context.Context) error {
+	return q.withConn(ctx, func(conn Conn) error {
+		if _, err := conn.Exec(ctx, q.Schema()); err != nil {
+			return fmt.Errorf("failed to create table %s: %w", q.table, err)
+		}
+		return nil
+	})
+}
+
+// withConn runs fn on a connection of the pool.
+func (q *Queue) withConn(ctx context.Context, fn func(conn Conn) error) error {
+	conn, err := q.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+	return fn(conn)
+}
+
+// enqueueOptions are the options of a job being enqueued.
+type enqueueOptions struct {
+	runAt       time.Time
+	delay       time.Duration
+	maxAttempts int
+	conn        Conn
+}
+
+// EnqueueOption configures a job being enqueued.
+type EnqueueOption func(*enqueueOptions)
+
+// WithDelay delays the job.
+func WithDelay(delay time.Duration) EnqueueOption {
+	return func(o *enqueueOptions) {
+		o.delay = delay
+	}
+}
+
+// WithRunAt schedules the job.
+func WithRunAt(runAt time.Time) EnqueueOption {
+	return func(o *enqueueOptions) {
+		o.runAt = runAt
+	}
+}
+
+// WithMaxAttempts sets the maximum number of attempts of the job. Defaults
+// to DefaultMaxAttempts.
+func WithMaxAttempts(n int) EnqueueOption {
+	return func(o *enqueueOptions) {
+		o.maxAttempts = n
+	}
+}
+
+// WithConn enqueues the job through conn, typically a transaction, so the
+// job is only visible when the transaction commits.
+func WithConn(conn Conn) EnqueueOption {
+	return func(o *enqueueOptions) {
+		o.conn = conn
+	}
+}
+
+// Enqueue enqueues a job of the kind with the payload encoded as JSON and
+// returns its ID.
+func Enqueue[T any](ctx context.Context, q *Queue, kind string, payload T, opts ...EnqueueOption) (int64, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return 0, fmt.Errorf("%w: encoding payload of %s: %v", ErrInvalidJob, kind, err)
+	}
+	return q.EnqueueRaw(ctx, kind, data, opts...)
+}
+
+// EnqueueRaw enqueues a job with a payload already encoded as JSON.
+func (q *Queue) EnqueueRaw(ctx context.Context, kind string, payload json.RawMessage, opts ...EnqueueOption) (int64, error) {
+	o := enqueueOptions{maxAttempts: DefaultMaxAttempts}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if kind == "" {
+		return 0, fmt.Errorf("%w: kind is required", ErrInvalidJob)
+	}
+	if o.maxAttempts <= 0 {
+		return 0, fmt.Errorf("%w: max attempts must be positive", ErrInvalidJob)
+	}
+	if !json.Valid(payload) {
+		return 0, fmt.Errorf("%w: payload of %s is not valid JSON", ErrInvalidJob, kind)
+	}
+
+	// run_at is computed by the database unless the job is scheduled, so
+	// delays do not depend on the clock of the replica
+	var runAt *time.Time
+	if !o.runAt.IsZero() {
+		runAt = &o.runAt
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (queue, kind, payload, max_attempts, run_at)
+VALUES ($1, $2, $3::jsonb, $4, COALESCE($5::timestamptz, now()) + $6 * interval '1 millisecond')
+RETURNING id`, q.table)
+	args := []interface{}{q.name, kind, string(payload), o.maxAttempts, runAt, o.delay.Milliseconds()}
+
+	var id int64
+	enqueue := func(conn Conn) error {
+		if err := conn.QueryRow(ctx, query, args...).Scan(&id); err != nil {
+			return fmt.Errorf("failed to enqueue job %s: %w", kind, err)
+		}
+		return nil
+	}
+	if o.conn != nil {
+		err := enqueue(o.conn)
+		return id, err
+	}
+	err := q.withConn(ctx, enqueue)
+	return id, err
+}
+
+// claim claims up to limit ready jobs, pending or whose visibility timeout
+// expired, for the visibility timeout.
+func (q *Queue) claim(ctx context.Context, limit int, visibility time.Duration) ([]*Job, error) {
+	var jobs []*Job
+	visibleAt := time.Now().Add(visibility)
+	err := q.withConn(ctx, func(conn Conn) error {
+		rows, err := conn.Query(ctx, fmt.Sprintf(`WITH next AS (
+	SELECT id FROM %[1]s
+	WHERE queue = $1 AND run_at <= now()
+		AND (status = 'pending' OR (status = 'running' AND locked_until < now()))
+	ORDER BY run_at, id
+	LIMIT $2
+	FOR UPDATE SKIP LOCKED
+)
+UPDATE %[1]s AS j SET status = 'running', attempts = j.attempts + 1,
+	locked_until = now() + $3 * interval '1 millisecond', updated_at = now()
+FROM next WHERE j.id = next.id
+RETURNING j.id, j.queue, j.kind, j.payload::text, j.attempts, j.max_attempts, j.run_at, j.created_at,
+	COALESCE(j.last_error, '')`, q.table), q.name, limit, visibility.Milliseconds())
+		if err != nil {
+			return fmt.Errorf("failed to claim jobs: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var job Job
+			var payload string
+			if err := rows.Scan(&job.ID, &job.Queue, &job.Kind, &payload, &job.Attempt, &job.MaxAttempts,
+				&job.RunAt, &job.CreatedAt, &job.LastError); err != nil {
+				return fmt.Errorf("failed to read claimed job: %w", err)
+			}
+			job.Payload = json.RawMessage(payload)
+			job.visibleAt = visibleAt
+			jobs = append(jobs, &job)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to claim jobs: %w", err)
+		}
+		return nil
+	})
+	return jobs, err
+}
+
+// complete deletes a job that succeeded. The attempt guards against a job
+// whose visibility timeout expired and was claimed again.
+func (q *Queue) complete(ctx context.Context, job *Job) error {
+	return q.withConn(ctx, func(conn Conn) error {
+		if _, err := conn.Exec(ctx, fmt.Sprintf(`DELETE FROM %s
+WHERE id = $1 AND attempts = $2 AND status = 'running'`, q.table), job.ID, job.Attempt); err != nil {
+			return fmt.Errorf("failed to complete job %d: %w", job.ID, err)
+		}
+		return nil
+	})
+}
+
+// retry makes a failed job pending again after the delay.
+func (q *Queue) retry(ctx context.Context, job *Job, delay time.Duration, cause string) error {
+	return q.withConn(ctx, func(conn Conn) error {
+		if _, err := conn.Exec(ctx, fmt.Sprintf(`UPDATE %s SET status = 'pending', locked_until = NULL,
+	run_at = now() + $3 * interval '1 millisecond', last_error = $4, updated_at = now()
+WHERE id = $1 AND attempts = $2 AND status = 'running'`, q.table),
+			job.ID, job.Attempt, delay.Milliseconds(), cause); err != nil {
+			return fmt.Errorf("failed to retry job %d: %w", job.ID, err)
+		}
+		return nil
+	})
+}
+
+// fail marks a job as failed for good. Failed jobs are kept for inspection
+// and can be enqueued again with Retry.
+func (q *Queue) fail(ctx context.Context, job *Job, cause string) error {
+	return q.withConn(ctx, func(conn Conn) error {
+		if _, err := conn.Exec(ctx, fmt.Sprintf(`UPDATE %s SET status = 'failed', locked_until = NULL,
+	last_error = $3, updated_at = now()
+WHERE id = $1 AND attempts = $2 AND status = 'running'`, q.table), job.ID, job.Attempt, cause); err != nil {
+			return fmt.Errorf("failed to mark job %d as failed: %w", job.ID, err)
+		}
+		return nil
+	})
+}
+
+// Retry makes a failed job pending again with its attempts reset. It
+// reports whether the job was found.
+func (q *Queue) Retry(ctx context.Context, id int64) (bool, error) {
+	var found bool
+	err := q.withConn(ctx, func(conn Conn) error {
+		tag, err := conn.Exec(ctx, fmt.Sprintf(`UPDATE %s SET status = 'pending', attempts = 0, run_at = now(),
+	updated_at = now()
+WHERE id = $1 AND queue = $2 AND status = 'failed'`, q.table), id, q.name)
+		if err != nil {
+			return fmt.Errorf("failed to retry job %d: %w", id, err)
+		}
+		found = tag.RowsAffected() == 1
+		return nil
+	})
+	return found, err
+}
+
+// Stats describes the depth of the queue.
+type Stats struct {
+	// Ready jobs are pending and due.
+	Ready int64
+	// Scheduled jobs are pending and not due yet.
+	Scheduled int64
+	// Running jobs are claimed by a worker.
+	Running int64
+	// Failed jobs exhausted their attempts.
+	Failed int64
+	// OldestReady is how long the oldest ready job has been due, the
+	// latency of the queue.
+	OldestReady time.Duration
+}
+
+// Stats returns the depth of the queue.
+func (q *Queue) Stats(ctx context.Context) (Stats, error) {
+	var stats Stats
+	var oldest float64
+	err := q.withConn(ctx, func(conn Conn) error {
+		if err := conn.QueryRow(ctx, fmt.Sprintf(`SELECT
+	count(*) FILTER (WHERE status = 'pending' AND run_at <= now()),
+	count(*) FILTER (WHERE status = 'pending' AND run_at > now()),
+	count(*) FILTER (WHERE status = 'running'),
+	count(*) FILTER (WHERE status = 'failed'),
+	COALESCE(EXTRACT(EPOCH FROM now() - min(run_at) FILTER (WHERE status = 'pending' AND run_at <= now())), 0)::float8
+FROM %s WHERE queue = $1`, q.table), q.name).Scan(
+			&stats.Ready, &stats.Scheduled, &stats.Running, &stats.Failed, &oldest); err != nil {
+			return fmt.Errorf("failed to read queue stats: %w", err)
+		}
+		return nil
+	})
+	stats.OldestReady = time.Duration(oldest * float64(time.Second))
+	return stats, err
+}
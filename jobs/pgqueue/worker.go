@@ -0,0 +1,274 @@
+package pgqueue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/fsvxavier/nexs-lib/messaging/consumer"
+)
+
+// Handler processes a job. Returning an error retries the job with backoff;
+// errors marked with consumer.Permanent and non-retryable domain errors, see
+// consumer.IsRetryable, fail it at once.
+type Handler func(ctx context.Context, job *Job) error
+
+// WorkerConfig configures a Worker.
+type WorkerConfig struct {
+	// Concurrency is the number of jobs processed at the same time.
+	Concurrency int
+
+	// PollInterval is the wait between claims when the queue is empty.
+	PollInterval time.Duration
+
+	// VisibilityTimeout is how long a claimed job stays invisible to other
+	// workers. The handler context expires with the claim, so a job is not
+	// processed twice at the same time.
+	VisibilityTimeout time.Duration
+
+	// Backoff returns the delay before each retry.
+	Backoff consumer.Backoff
+
+	// Logger receives claim and storage errors. Defaults to slog.Default().
+	Logger *slog.Logger
+}
+
+// DefaultWorkerConfig returns a default worker configuration.
+func DefaultWorkerConfig() WorkerConfig {
+	return WorkerConfig{
+		Concurrency:       4,
+		PollInterval:      time.Second,
+		VisibilityTimeout: 5 * time.Minute,
+		Backoff:           consumer.ExponentialBackoff(time.Second, time.Hour),
+	}
+}
+
+// Worker consumes the jobs of a queue with a pool of goroutines.
+type Worker struct {
+	queue    *Queue
+	config   WorkerConfig
+	handlers map[string]Handler
+	mu       sync.RWMutex
+
+	inFlight atomic.Int64
+	freed    chan struct{}
+	wg       sync.WaitGroup
+
+	metricsMu sync.Mutex
+	metrics   Metrics
+	latency   time.Duration
+	duration  time.Duration
+}
+
+// NewWorker creates a Worker. Zero fields of config take the defaults.
+func NewWorker(queue *Queue, config WorkerConfig) *Worker {
+	defaults := DefaultWorkerConfig()
+	if config.Concurrency <= 0 {
+		config.Concurrency = defaults.Concurrency
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = defaults.PollInterval
+	}
+	if config.VisibilityTimeout <= 0 {
+		config.VisibilityTimeout = defaults.VisibilityTimeout
+	}
+	if config.Backoff == nil {
+		config.Backoff = defaults.Backoff
+	}
+	if config.Logger == nil {
+		config.Logger = slog.Default()
+	}
+	return &Worker{
+		queue:    queue,
+		config:   config,
+		handlers: make(map[string]Handler),
+		freed:    make(chan struct{}, 1),
+	}
+}
+
+// HandleRaw registers the handler of the jobs of the kind.
+func (w *Worker) HandleRaw(kind string, handler Handler) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handlers[kind] = handler
+}
+
+// Handle registers the handler of the jobs of the kind, decoding their
+// payload into T. Payloads that cannot be decoded fail the job at once.
+func Handle[T any](w *Worker, kind string, fn func(ctx context.Context, job *Job, payload T) error) {
+	w.HandleRaw(kind, func(ctx context.Context, job *Job) error {
+		var payload T
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return consumer.Permanent(fmt.Errorf("failed to decode payload of job %d: %w", job.ID, err))
+		}
+		return fn(ctx, job, payload)
+	})
+}
+
+// Run claims and processes jobs until ctx is done, then waits for the jobs
+// being processed. Their results are stored even after ctx is done.
+func (w *Worker) Run(ctx context.Context) error {
+	defer w.wg.Wait()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		if free := w.config.Concurrency - int(w.inFlight.Load()); free > 0 {
+			jobs, err := w.queue.claim(ctx, free, w.config.VisibilityTimeout)
+			if err != nil && ctx.Err() == nil {
+				w.config.Logger.ErrorContext(ctx, "pgqueue claim failed", "queue", w.queue.name, "error", err)
+			}
+			for _, job := range jobs {
+				w.start(ctx, job)
+			}
+			// A full batch means more jobs may be ready
+			if err == nil && len(jobs) == free {
+				continue
+			}
+		}
+
+		timer.Reset(w.config.PollInterval)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-w.freed:
+		case <-timer.C:
+		}
+	}
+}
+
+// start processes the job in a new goroutine.
+func (w *Worker) start(ctx context.Context, job *Job) {
+	w.inFlight.Add(1)
+	w.wg.Add(1)
+	w.record(func(m *Metrics) { m.Claimed++ })
+
+	go func() {
+		defer func() {
+			w.inFlight.Add(-1)
+			w.wg.Done()
+			select {
+			case w.freed <- struct{}{}:
+			default:
+			}
+		}()
+		w.process(context.WithoutCancel(ctx), job)
+	}()
+}
+
+// process runs the handler and stores the result of the job.
+func (w *Worker) process(ctx context.Context, job *Job) {
+	started := time.Now()
+	latency := started.Sub(job.RunAt)
+
+	err := w.handle(ctx, job)
+	duration := time.Since(started)
+
+	switch {
+	case err == nil:
+		err = w.queue.complete(ctx, job)
+		w.record(func(m *Metrics) { m.Succeeded++ })
+	case !consumer.IsRetryable(err) || job.Attempt >= job.MaxAttempts:
+		cause := err.Error()
+		err = w.queue.fail(ctx, job, cause)
+		w.record(func(m *Metrics) { m.Failed++ })
+		w.config.Logger.WarnContext(ctx, "pgqueue job failed", "queue", job.Queue, "kind", job.Kind,
+			"job_id", job.ID, "attempt", job.Attempt, "error", cause)
+	default:
+		err = w.queue.retry(ctx, job, w.config.Backoff(job.Attempt), err.Error())
+		w.record(func(m *Metrics) { m.Retried++ })
+	}
+	if err != nil {
+		// The visibility timeout makes the job available again
+		w.config.Logger.ErrorContext(ctx, "pgqueue failed to store job result", "queue", job.Queue,
+			"job_id", job.ID, "error", err)
+	}
+
+	w.metricsMu.Lock()
+	w.latency += latency
+	w.duration += duration
+	if latency > w.metrics.MaxLatency {
+		w.metrics.MaxLatency = latency
+	}
+	if duration > w.metrics.MaxDuration {
+		w.metrics.MaxDuration = duration
+	}
+	w.metricsMu.Unlock()
+}
+
+// handle runs the handler of the job within the visibility timeout, turning
+// panics into ServerError domain errors.
+func (w *Worker) handle(ctx context.Context, job *Job) (err error) {
+	if job.Attempt > job.MaxAttempts {
+		// Claimed again after workers died or timed out on every attempt
+		return consumer.Permanent(fmt.Errorf("job %d exceeded %d attempts", job.ID, job.MaxAttempts))
+	}
+
+	w.mu.RLock()
+	handler, ok := w.handlers[job.Kind]
+	w.mu.RUnlock()
+	if !ok {
+		// Retried, so workers of a newer release may process it
+		return fmt.Errorf("no handler registered for job kind %q", job.Kind)
+	}
+
+	ctx, cancel := context.WithDeadline(ctx, job.visibleAt)
+	defer cancel()
+	defer func() {
+		if p := recover(); p != nil {
+			err = domainerrors.NewWithMetadata(interfaces.ServerError, "JOB_PANIC",
+				fmt.Sprintf("job %d panicked: %v", job.ID, p), map[string]interface{}{
+					"job_id": job.ID,
+					"kind":   job.Kind,
+					"stack":  string(debug.Stack()),
+				})
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// Metrics are the counters of a worker.
+type Metrics struct {
+	Claimed   int64
+	Succeeded int64
+	Retried   int64
+	Failed    int64
+	InFlight  int64
+
+	// Latency is the time between a job being due and its processing.
+	AvgLatency time.Duration
+	MaxLatency time.Duration
+
+	// Duration is the processing time of the handlers.
+	AvgDuration time.Duration
+	MaxDuration time.Duration
+}
+
+// record updates the counters.
+func (w *Worker) record(update func(m *Metrics)) {
+	w.metricsMu.Lock()
+	update(&w.metrics)
+	w.metricsMu.Unlock()
+}
+
+// Metrics returns a snapshot of the worker counters. Queue.Stats returns
+// the depth of the queue.
+func (w *Worker) Metrics() Metrics {
+	w.metricsMu.Lock()
+	defer w.metricsMu.Unlock()
+
+	m := w.metrics
+	m.InFlight = w.inFlight.Load()
+	if done := m.Succeeded + m.Retried + m.Failed; done > 0 {
+		m.AvgLatency = w.latency / time.Duration(done)
+		m.AvgDuration = w.duration / time.Duration(done)
+	}
+	return m
+}
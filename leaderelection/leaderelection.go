@@ -0,0 +1,386 @@
+// Package leaderelection elects a single replica to run a singleton
+// background process. Candidates campaign for a lease stored in a Lock, the
+// leader renews it while the process runs, and steps down when the renewal
+// fails for longer than the renew deadline, before another replica can take
+// over. Every lease carries a fencing token that increases with each new
+// leader, so storage written by the leader can reject stale leaders.
+package leaderelection
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Election errors.
+var (
+	ErrCampaignRunning = errors.New("leaderelection: already campaigning for key")
+	ErrLeadershipLost  = errors.New("leaderelection: leadership lost")
+	ErrInvalidConfig   = errors.New("leaderelection: invalid configuration")
+)
+
+// Default timings of an Elector.
+const (
+	DefaultLeaseDuration = 15 * time.Second
+	DefaultRenewDeadline = 10 * time.Second
+	DefaultRetryPeriod   = 2 * time.Second
+)
+
+// Lease is the leadership of a key held by a candidate.
+type Lease struct {
+	Key      string
+	Identity string
+
+	// Token is the fencing token of the lease. It is greater than the token
+	// of every previous lease of the key.
+	Token int64
+}
+
+// Lock stores the leases. Implementations must make Acquire atomic, and
+// never reuse a fencing token of a key.
+type Lock interface {
+	// Acquire takes the lease of the key for identity when it is free or
+	// expired. Otherwise it returns false and the lease of the current
+	// leader, zero when unknown.
+	Acquire(ctx context.Context, key, identity string, ttl time.Duration) (Lease, bool, error)
+
+	// Renew extends the lease for ttl. It returns false when the lease
+	// expired or was taken by another candidate.
+	Renew(ctx context.Context, lease Lease, ttl time.Duration) (bool, error)
+
+	// Release gives up the lease, so other candidates take over at once.
+	Release(ctx context.Context, lease Lease) error
+
+	// Leader returns the lease of the current leader of the key, and false
+	// when there is none.
+	Leader(ctx context.Context, key string) (Lease, bool, error)
+}
+
+// Callbacks are notified of the leadership of a key.
+type Callbacks struct {
+	// OnStartedLeading runs the singleton process. ctx is cancelled with
+	// the cause ErrLeadershipLost when the leadership is lost, and carries
+	// the lease, see LeaseFromContext. Required.
+	OnStartedLeading func(ctx context.Context, lease Lease)
+
+	// OnStoppedLeading is called after OnStartedLeading returns.
+	OnStoppedLeading func()
+
+	// OnNewLeader is called when a different leader is observed, including
+	// this candidate.
+	OnNewLeader func(identity string)
+}
+
+// Option configures an Elector.
+type Option func(*Elector)
+
+// WithIdentity sets the identity of the candidate, unique among the
+// replicas. Defaults to the host name with a random suffix.
+func WithIdentity(identity string) Option {
+	return func(e *Elector) {
+		if identity != "" {
+			e.identity = identity
+		}
+	}
+}
+
+// WithLeaseDuration sets how long a lease lasts without renewal; other
+// candidates wait that long after a leader dies. Defaults to
+// DefaultLeaseDuration.
+func WithLeaseDuration(d time.Duration) Option {
+	return func(e *Elector) {
+		e.leaseDuration = d
+	}
+}
+
+// WithRenewDeadline sets how long the leader keeps leading while renewals
+// fail. It must be shorter than the lease duration, leaving room for clock
+// drift. Defaults to DefaultRenewDeadline.
+func WithRenewDeadline(d time.Duration) Option {
+	return func(e *Elector) {
+		e.renewDeadline = d
+	}
+}
+
+// WithRetryPeriod sets the interval between acquisition attempts and
+// renewals. Defaults to DefaultRetryPeriod.
+func WithRetryPeriod(d time.Duration) Option {
+	return func(e *Elector) {
+		e.retryPeriod = d
+	}
+}
+
+// WithLogger sets the logger of the elector. Defaults to slog.Default().
+func WithLogger(logger *slog.Logger) Option {
+	return func(e *Elector) {
+		if logger != nil {
+			e.logger = logger
+		}
+	}
+}
+
+// Elector campaigns for the leadership of keys.
+type Elector struct {
+	lock          Lock
+	identity      string
+	leaseDuration time.Duration
+	renewDeadline time.Duration
+	retryPeriod   time.Duration
+	logger        *slog.Logger
+
+	mu        sync.Mutex
+	campaigns map[string]*campaign
+}
+
+// campaign is the state of a key being campaigned for.
+type campaign struct {
+	leading bool
+	leader  string
+}
+
+// New creates an Elector storing its leases in lock.
+func New(lock Lock, opts ...Option) *Elector {
+	e := &Elector{
+		lock:          lock,
+		identity:      defaultIdentity(),
+		leaseDuration: DefaultLeaseDuration,
+		renewDeadline: DefaultRenewDeadline,
+		retryPeriod:   DefaultRetryPeriod,
+		logger:        slog.Default(),
+		campaigns:     make(map[string]*campaign),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Run is a shortcut for New(lock, opts...).Run(ctx, key, callbacks).
+func Run(ctx context.Context, lock Lock, key string, callbacks Callbacks, opts ...Option) error {
+	return New(lock, opts...).Run(ctx, key, callbacks)
+}
+
+// Identity returns the identity of the candidate.
+func (e *Elector) Identity() string {
+	return e.identity
+}
+
+// IsLeader reports whether the candidate leads the key.
+func (e *Elector) IsLeader(key string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	c, ok := e.campaigns[key]
+	return ok && c.leading
+}
+
+// Leader returns the lease of the current leader of the key, as stored in
+// the lock.
+func (e *Elector) Leader(ctx context.Context, key string) (Lease, bool, error) {
+	return e.lock.Leader(ctx, key)
+}
+
+// Run campaigns for the key and runs OnStartedLeading while leading. When
+// the leadership is lost it waits for OnStartedLeading to return and
+// campaigns again. Run returns nil when ctx is done or OnStartedLeading
+// returns while leading, releasing the lease.
+func (e *Elector) Run(ctx context.Context, key string, callbacks Callbacks) error {
+	if err := e.validate(key, callbacks); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	if _, ok := e.campaigns[key]; ok {
+		e.mu.Unlock()
+		return ErrCampaignRunning
+	}
+	e.campaigns[key] = &campaign{}
+	e.mu.Unlock()
+	defer func() {
+		e.mu.Lock()
+		delete(e.campaigns, key)
+		e.mu.Unlock()
+	}()
+
+	for {
+		lease, acquiredAt, ok := e.acquire(ctx, key, callbacks)
+		if !ok {
+			return nil
+		}
+		if stop := e.lead(ctx, lease, acquiredAt, callbacks); stop {
+			return nil
+		}
+	}
+}
+
+// validate checks the configuration of a campaign.
+func (e *Elector) validate(key string, callbacks Callbacks) error {
+	switch {
+	case e.lock == nil:
+		return fmt.Errorf("%w: lock is required", ErrInvalidConfig)
+	case key == "":
+		return fmt.Errorf("%w: key is required", ErrInvalidConfig)
+	case callbacks.OnStartedLeading == nil:
+		return fmt.Errorf("%w: OnStartedLeading is required", ErrInvalidConfig)
+	case e.retryPeriod <= 0 || e.renewDeadline <= e.retryPeriod || e.leaseDuration <= e.renewDeadline:
+		return fmt.Errorf("%w: retry period %s, renew deadline %s and lease duration %s must be increasing",
+			ErrInvalidConfig, e.retryPeriod, e.renewDeadline, e.leaseDuration)
+	}
+	return nil
+}
+
+// acquire tries to acquire the lease every retry period until it succeeds or
+// ctx is done. It returns the time of the successful attempt, from which the
+// lease lasts.
+func (e *Elector) acquire(ctx context.Context, key string, callbacks Callbacks) (Lease, time.Time, bool) {
+	ticker := time.NewTicker(e.retryPeriod)
+	defer ticker.Stop()
+	for {
+		started := time.Now()
+		lease, acquired, err := e.lock.Acquire(ctx, key, e.identity, e.leaseDuration)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			e.logger.ErrorContext(ctx, "leader election failed to acquire lease", "key", key, "error", err)
+		case acquired:
+			e.observe(key, e.identity, true, callbacks)
+			e.logger.InfoContext(ctx, "leader election started leading", "key", key,
+				"identity", e.identity, "token", lease.Token)
+			return lease, started, true
+		case err == nil:
+			e.observe(key, lease.Identity, false, callbacks)
+		}
+
+		select {
+		case <-ctx.Done():
+			return Lease{}, time.Time{}, false
+		case <-ticker.C:
+		}
+	}
+}
+
+// lead runs OnStartedLeading and renews the lease until the leadership is
+// lost, ctx is done or OnStartedLeading returns. It reports whether the
+// campaign must stop.
+func (e *Elector) lead(ctx context.Context, lease Lease, renewedAt time.Time, callbacks Callbacks) bool {
+	leaderCtx, cancel := context.WithCancelCause(WithLease(ctx, lease))
+	defer cancel(nil)
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		callbacks.OnStartedLeading(leaderCtx, lease)
+	}()
+
+	stepDown := func(release bool, reason string) {
+		cancel(ErrLeadershipLost)
+		<-stopped
+		e.setLeading(lease.Key, false)
+		if release {
+			e.release(ctx, lease)
+		}
+		e.logger.InfoContext(ctx, "leader election stopped leading", "key", lease.Key,
+			"identity", e.identity, "token", lease.Token, "reason", reason)
+		if callbacks.OnStoppedLeading != nil {
+			callbacks.OnStoppedLeading()
+		}
+	}
+
+	ticker := time.NewTicker(e.retryPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopped:
+			stepDown(true, "process returned")
+			return true
+		case <-ctx.Done():
+			stepDown(true, "context done")
+			return true
+		case <-ticker.C:
+		}
+
+		// A renewal hanging past the renew deadline counts as failed, so the
+		// process stops before the lease can expire and be taken over
+		started := time.Now()
+		renewCtx, cancelRenew := context.WithDeadline(ctx, renewedAt.Add(e.renewDeadline))
+		renewed, err := e.lock.Renew(renewCtx, lease, e.leaseDuration)
+		cancelRenew()
+		switch {
+		case err == nil && renewed:
+			renewedAt = started
+		case err == nil:
+			stepDown(false, "lease lost")
+			return false
+		case time.Since(renewedAt) >= e.renewDeadline:
+			// Another candidate may acquire the lease once it expires, so
+			// the process stops before that. The lease may still be held.
+			e.logger.ErrorContext(ctx, "leader election failed to renew lease", "key", lease.Key, "error", err)
+			stepDown(true, "renew deadline exceeded")
+			return false
+		default:
+			e.logger.WarnContext(ctx, "leader election failed to renew lease", "key", lease.Key, "error", err)
+		}
+	}
+}
+
+// release gives up the lease, even when ctx is done.
+func (e *Elector) release(ctx context.Context, lease Lease) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), e.retryPeriod)
+	defer cancel()
+	if err := e.lock.Release(ctx, lease); err != nil {
+		// The lease expires after the lease duration
+		e.logger.WarnContext(ctx, "leader election failed to release lease", "key", lease.Key, "error", err)
+	}
+}
+
+// observe records the leader of the key and notifies a new one.
+func (e *Elector) observe(key, leader string, leading bool, callbacks Callbacks) {
+	e.mu.Lock()
+	c := e.campaigns[key]
+	changed := leader != "" && leader != c.leader
+	if leader != "" {
+		c.leader = leader
+	}
+	c.leading = leading
+	e.mu.Unlock()
+
+	if changed && callbacks.OnNewLeader != nil {
+		callbacks.OnNewLeader(leader)
+	}
+}
+
+// setLeading records whether the candidate leads the key.
+func (e *Elector) setLeading(key string, leading bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.campaigns[key].leading = leading
+}
+
+type leaseKey struct{}
+
+// WithLease returns a copy of ctx carrying the lease.
+func WithLease(ctx context.Context, lease Lease) context.Context {
+	return context.WithValue(ctx, leaseKey{}, lease)
+}
+
+// LeaseFromContext returns the lease of the leader running the process, to
+// send its fencing token along with writes.
+func LeaseFromContext(ctx context.Context) (Lease, bool) {
+	lease, ok := ctx.Value(leaseKey{}).(Lease)
+	return lease, ok
+}
+
+// defaultIdentity returns the host name with a random suffix.
+func defaultIdentity() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "candidate"
+	}
+	b := make([]byte, 4)
+	rand.Read(b)
+	return host + "-" + hex.EncodeToString(b)
+}
@@ -0,0 +1,543 @@
+package leaderelection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	valkeyinterfaces "github.com/fsvxavier/nexs-lib/cache/valkey/interfaces"
+	pginterfaces "github.com/fsvxavier/nexs-lib/db/postgres/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryLock is an in-process Lock with fault injection.
+type memoryLock struct {
+	mu       sync.Mutex
+	leases   map[string]Lease
+	expires  map[string]time.Time
+	tokens   map[string]int64
+	renewErr error
+	hang     bool
+}
+
+func newMemoryLock() *memoryLock {
+	return &memoryLock{leases: map[string]Lease{}, expires: map[string]time.Time{}, tokens: map[string]int64{}}
+}
+
+func (l *memoryLock) Acquire(ctx context.Context, key, identity string, ttl time.Duration) (Lease, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if lease, ok := l.leases[key]; ok && time.Now().Before(l.expires[key]) {
+		return lease, false, nil
+	}
+	l.tokens[key]++
+	lease := Lease{Key: key, Identity: identity, Token: l.tokens[key]}
+	l.leases[key] = lease
+	l.expires[key] = time.Now().Add(ttl)
+	return lease, true, nil
+}
+
+func (l *memoryLock) Renew(ctx context.Context, lease Lease, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	if l.hang {
+		l.mu.Unlock()
+		<-ctx.Done()
+		return false, ctx.Err()
+	}
+	defer l.mu.Unlock()
+	if l.renewErr != nil {
+		return false, l.renewErr
+	}
+	if l.leases[lease.Key] != lease || time.Now().After(l.expires[lease.Key]) {
+		return false, nil
+	}
+	l.expires[lease.Key] = time.Now().Add(ttl)
+	return true, nil
+}
+
+func (l *memoryLock) Release(ctx context.Context, lease Lease) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.leases[lease.Key] == lease {
+		delete(l.leases, lease.Key)
+	}
+	return nil
+}
+
+func (l *memoryLock) Leader(ctx context.Context, key string) (Lease, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lease, ok := l.leases[key]
+	return lease, ok && time.Now().Before(l.expires[key]), nil
+}
+
+// steal gives the lease of the key to another candidate.
+func (l *memoryLock) steal(key, identity string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens[key]++
+	l.leases[key] = Lease{Key: key, Identity: identity, Token: l.tokens[key]}
+	l.expires[key] = time.Now().Add(time.Hour)
+}
+
+func (l *memoryLock) setHang(hang bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.hang = hang
+}
+
+func (l *memoryLock) setRenewErr(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.renewErr = err
+}
+
+func fastOptions(identity string) []Option {
+	return []Option{
+		WithIdentity(identity),
+		WithLeaseDuration(150 * time.Millisecond),
+		WithRenewDeadline(100 * time.Millisecond),
+		WithRetryPeriod(10 * time.Millisecond),
+	}
+}
+
+func TestElector_Validate(t *testing.T) {
+	ctx := context.Background()
+	noop := Callbacks{OnStartedLeading: func(context.Context, Lease) {}}
+
+	assert.ErrorIs(t, New(nil).Run(ctx, "key", noop), ErrInvalidConfig)
+	assert.ErrorIs(t, New(newMemoryLock()).Run(ctx, "", noop), ErrInvalidConfig)
+	assert.ErrorIs(t, New(newMemoryLock()).Run(ctx, "key", Callbacks{}), ErrInvalidConfig)
+	assert.ErrorIs(t, New(newMemoryLock(), WithRenewDeadline(time.Minute)).Run(ctx, "key", noop), ErrInvalidConfig)
+
+	e := New(newMemoryLock())
+	assert.NotEmpty(t, e.Identity())
+	assert.NotEqual(t, e.Identity(), New(newMemoryLock()).Identity())
+}
+
+func TestElector_SingleLeader(t *testing.T) {
+	lock := newMemoryLock()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var leading atomic.Int32
+	var maxLeading atomic.Int32
+	var wg sync.WaitGroup
+	electors := make([]*Elector, 3)
+	for i := range electors {
+		electors[i] = New(lock, fastOptions(string(rune('a'+i)))...)
+		wg.Add(1)
+		go func(e *Elector) {
+			defer wg.Done()
+			err := e.Run(ctx, "reconciler", Callbacks{
+				OnStartedLeading: func(ctx context.Context, lease Lease) {
+					if n := leading.Add(1); n > maxLeading.Load() {
+						maxLeading.Store(n)
+					}
+					<-ctx.Done()
+					leading.Add(-1)
+				},
+			})
+			assert.NoError(t, err)
+		}(electors[i])
+	}
+
+	require.Eventually(t, func() bool { return leading.Load() == 1 }, time.Second, 5*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int32(1), maxLeading.Load())
+
+	leaders := 0
+	for _, e := range electors {
+		if e.IsLeader("reconciler") {
+			leaders++
+		}
+	}
+	assert.Equal(t, 1, leaders)
+
+	cancel()
+	wg.Wait()
+	_, ok, err := lock.Leader(context.Background(), "reconciler")
+	require.NoError(t, err)
+	assert.False(t, ok, "the lease is released when the context is done")
+}
+
+func TestElector_LeaseLost(t *testing.T) {
+	lock := newMemoryLock()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	e := New(lock, fastOptions("a")...)
+	started := make(chan Lease, 2)
+	causes := make(chan error, 2)
+	var stoppedCount atomic.Int32
+	var leaders []string
+	var mu sync.Mutex
+
+	done := make(chan error, 1)
+	go func() {
+		done <- e.Run(ctx, "reconciler", Callbacks{
+			OnStartedLeading: func(ctx context.Context, lease Lease) {
+				fromCtx, ok := LeaseFromContext(ctx)
+				assert.True(t, ok)
+				assert.Equal(t, lease, fromCtx)
+				started <- lease
+				<-ctx.Done()
+				causes <- context.Cause(ctx)
+			},
+			OnStoppedLeading: func() { stoppedCount.Add(1) },
+			OnNewLeader: func(identity string) {
+				mu.Lock()
+				leaders = append(leaders, identity)
+				mu.Unlock()
+			},
+		})
+	}()
+
+	first := <-started
+	assert.Equal(t, int64(1), first.Token)
+
+	// Another candidate takes the lease; the elector steps down and
+	// campaigns again
+	lock.steal("reconciler", "b")
+	assert.ErrorIs(t, <-causes, ErrLeadershipLost)
+	require.Eventually(t, func() bool { return stoppedCount.Load() == 1 }, time.Second, 5*time.Millisecond)
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(leaders) == 2
+	}, time.Second, 5*time.Millisecond)
+	assert.False(t, e.IsLeader("reconciler"))
+
+	leader, ok, err := e.Leader(ctx, "reconciler")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "b", leader.Identity)
+
+	require.NoError(t, lock.Release(ctx, leader))
+	second := <-started
+	assert.Greater(t, second.Token, leader.Token, "fencing tokens increase with each leader")
+
+	cancel()
+	require.NoError(t, <-done)
+	assert.Equal(t, []string{"a", "b", "a"}, leaders)
+	assert.Equal(t, int32(2), stoppedCount.Load())
+}
+
+func TestElector_RenewDeadline(t *testing.T) {
+	lock := newMemoryLock()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	e := New(lock, fastOptions("a")...)
+	started := make(chan time.Time, 2)
+	stopped := make(chan time.Time, 2)
+	go e.Run(ctx, "reconciler", Callbacks{
+		OnStartedLeading: func(ctx context.Context, lease Lease) {
+			started <- time.Now()
+			<-ctx.Done()
+		},
+		OnStoppedLeading: func() { stopped <- time.Now() },
+	})
+
+	<-started
+	lock.setRenewErr(errors.New("connection refused"))
+	failedAt := time.Now()
+
+	// Transient failures are tolerated until the renew deadline, which is
+	// before the lease expires
+	stoppedAt := <-stopped
+	assert.GreaterOrEqual(t, stoppedAt.Sub(failedAt), 50*time.Millisecond)
+	assert.Less(t, stoppedAt.Sub(failedAt), 150*time.Millisecond)
+
+	lock.setRenewErr(nil)
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("the elector did not campaign again")
+	}
+}
+
+func TestElector_RenewHangs(t *testing.T) {
+	lock := newMemoryLock()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	e := New(lock, fastOptions("a")...)
+	started := make(chan struct{}, 1)
+	stopped := make(chan time.Time, 1)
+	go e.Run(ctx, "reconciler", Callbacks{
+		OnStartedLeading: func(ctx context.Context, lease Lease) {
+			started <- struct{}{}
+			<-ctx.Done()
+		},
+		OnStoppedLeading: func() { stopped <- time.Now() },
+	})
+
+	<-started
+	lock.setHang(true)
+	hungAt := time.Now()
+
+	// A renewal that never returns is abandoned at the renew deadline,
+	// before the lease expires
+	select {
+	case stoppedAt := <-stopped:
+		assert.Less(t, stoppedAt.Sub(hungAt), 150*time.Millisecond)
+	case <-time.After(time.Second):
+		t.Fatal("the leader kept leading while the renewal hung")
+	}
+}
+
+func TestElector_ProcessReturns(t *testing.T) {
+	lock := newMemoryLock()
+	e := New(lock, fastOptions("a")...)
+
+	var stopped atomic.Bool
+	err := e.Run(context.Background(), "migration", Callbacks{
+		OnStartedLeading: func(ctx context.Context, lease Lease) {},
+		OnStoppedLeading: func() { stopped.Store(true) },
+	})
+	require.NoError(t, err)
+	assert.True(t, stopped.Load())
+
+	_, ok, err := lock.Leader(context.Background(), "migration")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestElector_CampaignRunning(t *testing.T) {
+	e := New(newMemoryLock(), fastOptions("a")...)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{})
+	go e.Run(ctx, "key", Callbacks{OnStartedLeading: func(ctx context.Context, lease Lease) {
+		close(started)
+		<-ctx.Done()
+	}})
+	<-started
+
+	err := e.Run(ctx, "key", Callbacks{OnStartedLeading: func(context.Context, Lease) {}})
+	assert.ErrorIs(t, err, ErrCampaignRunning)
+}
+
+// fakeValkey emulates the scripts of the ValkeyLock.
+type fakeValkey struct {
+	valkeyinterfaces.IClient
+	mu      sync.Mutex
+	strings map[string]string
+	expires map[string]string
+}
+
+func newFakeValkey() *fakeValkey {
+	return &fakeValkey{strings: map[string]string{}, expires: map[string]string{}}
+}
+
+func (f *fakeValkey) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	owner, exists := f.strings[keys[0]]
+	switch script {
+	case valkeyAcquireScript:
+		if exists {
+			return []interface{}{int64(0), owner}, nil
+		}
+		token, _ := strconv.ParseInt(f.strings[keys[1]], 10, 64)
+		f.strings[keys[1]] = strconv.FormatInt(token+1, 10)
+		owner = f.strings[keys[1]] + ":" + args[0].(string)
+		f.strings[keys[0]] = owner
+		f.expires[keys[0]] = fmt.Sprint(args[1])
+		return []interface{}{int64(1), owner}, nil
+	case valkeyRenewScript:
+		if !exists || owner != args[0] {
+			return int64(0), nil
+		}
+		f.expires[keys[0]] = fmt.Sprint(args[1])
+		return int64(1), nil
+	case valkeyReleaseScript:
+		if !exists || owner != args[0] {
+			return int64(0), nil
+		}
+		delete(f.strings, keys[0])
+		return int64(1), nil
+	case valkeyLeaderScript:
+		if !exists {
+			return nil, nil
+		}
+		return owner, nil
+	}
+	return nil, errors.New("unknown script")
+}
+
+// expire removes the owner of an expired lease.
+func (f *fakeValkey) expire(key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.strings, key)
+}
+
+func TestValkeyLock(t *testing.T) {
+	client := newFakeValkey()
+	l := NewValkeyLock(client, "")
+	ctx := context.Background()
+
+	lease, acquired, err := l.Acquire(ctx, "reconciler", "a", time.Minute)
+	require.NoError(t, err)
+	require.True(t, acquired)
+	assert.Equal(t, Lease{Key: "reconciler", Identity: "a", Token: 1}, lease)
+	assert.Equal(t, "60000", client.expires["leaderelection:{reconciler}:owner"])
+	assert.Empty(t, client.expires["leaderelection:{reconciler}:token"], "fencing tokens never expire")
+
+	current, acquired, err := l.Acquire(ctx, "reconciler", "b", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+	assert.Equal(t, lease, current)
+
+	renewed, err := l.Renew(ctx, lease, 2*time.Minute)
+	require.NoError(t, err)
+	assert.True(t, renewed)
+	assert.Equal(t, "120000", client.expires["leaderelection:{reconciler}:owner"])
+
+	// The lease expires and another candidate acquires it
+	client.expire("leaderelection:{reconciler}:owner")
+	next, acquired, err := l.Acquire(ctx, "reconciler", "b", time.Minute)
+	require.NoError(t, err)
+	require.True(t, acquired)
+	assert.Equal(t, int64(2), next.Token)
+
+	renewed, err = l.Renew(ctx, lease, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, renewed)
+	require.NoError(t, l.Release(ctx, lease))
+	leader, ok, err := l.Leader(ctx, "reconciler")
+	require.NoError(t, err)
+	require.True(t, ok, "a stale leader does not release the lease of the next one")
+	assert.Equal(t, next, leader)
+
+	require.NoError(t, l.Release(ctx, next))
+	_, ok, err = l.Leader(ctx, "reconciler")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+// fakePgDB records the statements of the PostgresLock and answers with
+// the configured results.
+type fakePgDB struct {
+	mu       sync.Mutex
+	queries  []string
+	args     [][]interface{}
+	token    int64
+	acquire  bool
+	holder   string
+	affected int64
+}
+
+func (db *fakePgDB) Acquire(ctx context.Context) (pginterfaces.IConn, error) {
+	return &fakePgConn{db: db}, nil
+}
+
+type fakePgConn struct {
+	pginterfaces.IConn
+	db *fakePgDB
+}
+
+func (c *fakePgConn) Release() {}
+
+func (c *fakePgConn) record(query string, args []interface{}) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.db.queries = append(c.db.queries, query)
+	c.db.args = append(c.db.args, args)
+}
+
+type fakeTag struct {
+	pginterfaces.ICommandTag
+	rows int64
+}
+
+func (t fakeTag) RowsAffected() int64 { return t.rows }
+
+func (c *fakePgConn) Exec(ctx context.Context, query string, args ...interface{}) (pginterfaces.ICommandTag, error) {
+	c.record(query, args)
+	return fakeTag{rows: c.db.affected}, nil
+}
+
+type fakeRows struct {
+	pginterfaces.IRows
+	values [][]interface{}
+	i      int
+}
+
+func (r *fakeRows) Next() bool {
+	r.i++
+	return r.i <= len(r.values)
+}
+
+func (r *fakeRows) Scan(dest ...interface{}) error {
+	for i, d := range dest {
+		switch d := d.(type) {
+		case *int64:
+			*d = r.values[r.i-1][i].(int64)
+		case *string:
+			*d = r.values[r.i-1][i].(string)
+		}
+	}
+	return nil
+}
+
+func (r *fakeRows) Close() error { return nil }
+func (r *fakeRows) Err() error   { return nil }
+
+func (c *fakePgConn) Query(ctx context.Context, query string, args ...interface{}) (pginterfaces.IRows, error) {
+	c.record(query, args)
+	switch {
+	case strings.HasPrefix(query, "INSERT") && c.db.acquire:
+		return &fakeRows{values: [][]interface{}{{c.db.token}}}, nil
+	case strings.HasPrefix(query, "SELECT") && c.db.holder != "":
+		return &fakeRows{values: [][]interface{}{{c.db.holder, c.db.token}}}, nil
+	}
+	return &fakeRows{}, nil
+}
+
+func TestPostgresLock(t *testing.T) {
+	_, err := NewPostgresLock(&fakePgDB{}, "leases; DROP TABLE users")
+	assert.Error(t, err)
+
+	db := &fakePgDB{token: 7, acquire: true}
+	l, err := NewPostgresLock(db, "")
+	require.NoError(t, err)
+	assert.Contains(t, l.Schema(), "CREATE TABLE IF NOT EXISTS leader_leases")
+	ctx := context.Background()
+
+	lease, acquired, err := l.Acquire(ctx, "reconciler", "a", 15*time.Second)
+	require.NoError(t, err)
+	require.True(t, acquired)
+	assert.Equal(t, Lease{Key: "reconciler", Identity: "a", Token: 7}, lease)
+	assert.Contains(t, db.queries[0], "WHERE l.expires_at <= now()")
+	assert.Equal(t, []interface{}{"reconciler", "a", int64(15000)}, db.args[0])
+
+	db.acquire, db.holder = false, "b"
+	current, acquired, err := l.Acquire(ctx, "reconciler", "a", 15*time.Second)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+	assert.Equal(t, Lease{Key: "reconciler", Identity: "b", Token: 7}, current)
+
+	db.affected = 1
+	renewed, err := l.Renew(ctx, lease, 15*time.Second)
+	require.NoError(t, err)
+	assert.True(t, renewed)
+	db.affected = 0
+	renewed, err = l.Renew(ctx, lease, 15*time.Second)
+	require.NoError(t, err)
+	assert.False(t, renewed)
+
+	require.NoError(t, l.Release(ctx, lease))
+	last := db.queries[len(db.queries)-1]
+	assert.True(t, strings.HasPrefix(last, "UPDATE"), "released leases keep their fencing token")
+}
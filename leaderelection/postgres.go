@@ -0,0 +1,167 @@
+package leaderelection
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/db/postgres"
+	pginterfaces "github.com/fsvxavier/nexs-lib/db/postgres/interfaces"
+)
+
+// DefaultTable stores the leases of a PostgresLock.
+const DefaultTable = "leader_leases"
+
+// PostgresPool is the part of the db/postgres pool used by the
+// PostgresLock; interfaces.IPool satisfies it.
+type PostgresPool interface {
+	Acquire(ctx context.Context) (pginterfaces.IConn, error)
+}
+
+// PostgresLock stores leases in a PostgreSQL table, one row per key.
+// Expirations use the database clock, so candidate clocks may drift, and
+// released leases keep their row so fencing tokens keep increasing.
+type PostgresLock struct {
+	pool  PostgresPool
+	table string
+}
+
+// NewPostgresLock creates a PostgresLock storing leases in table,
+// DefaultTable when empty.
+func NewPostgresLock(pool PostgresPool, table string) (*PostgresLock, error) {
+	if table == "" {
+		table = DefaultTable
+	}
+	if !postgres.ValidIdentifier(table) {
+		return nil, fmt.Errorf("invalid table name: %q", table)
+	}
+	return &PostgresLock{pool: pool, table: table}, nil
+}
+
+// Schema returns the statement creating the table.
+func (l *PostgresLock) Schema() string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	key TEXT PRIMARY KEY,
+	holder TEXT NOT NULL,
+	token BIGINT NOT NULL,
+	acquired_at TIMESTAMPTZ NOT NULL,
+	renewed_at TIMESTAMPTZ NOT NULL,
+	expires_at TIMESTAMPTZ NOT NULL
+)`, l.table)
+}
+
+// Migrate creates the table when it does not exist.
+func (l *PostgresLock) Migrate(ctx context.Context) error {
+	return l.withConn(ctx, func(conn pginterfaces.IConn) error {
+		if _, err := conn.Exec(ctx, l.Schema()); err != nil {
+			return fmt.Errorf("failed to create table %s: %w", l.table, err)
+		}
+		return nil
+	})
+}
+
+// Acquire implements Lock.
+func (l *PostgresLock) Acquire(ctx context.Context, key, identity string, ttl time.Duration) (Lease, bool, error) {
+	var lease Lease
+	var acquired bool
+	err := l.withConn(ctx, func(conn pginterfaces.IConn) error {
+		rows, err := conn.Query(ctx, fmt.Sprintf(`INSERT INTO %[1]s AS l (key, holder, token, acquired_at, renewed_at, expires_at)
+VALUES ($1, $2, 1, now(), now(), now() + $3 * interval '1 millisecond')
+ON CONFLICT (key) DO UPDATE SET holder = EXCLUDED.holder, token = l.token + 1,
+	acquired_at = now(), renewed_at = now(), expires_at = EXCLUDED.expires_at
+WHERE l.expires_at <= now()
+RETURNING token`, l.table), key, identity, ttl.Milliseconds())
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		if rows.Next() {
+			lease = Lease{Key: key, Identity: identity}
+			if err := rows.Scan(&lease.Token); err != nil {
+				return err
+			}
+			acquired = true
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return Lease{}, false, fmt.Errorf("failed to acquire lease: %w", err)
+	}
+	if !acquired {
+		leader, _, err := l.Leader(ctx, key)
+		return leader, false, err
+	}
+	return lease, true, nil
+}
+
+// Renew implements Lock.
+func (l *PostgresLock) Renew(ctx context.Context, lease Lease, ttl time.Duration) (bool, error) {
+	var renewed bool
+	err := l.withConn(ctx, func(conn pginterfaces.IConn) error {
+		tag, err := conn.Exec(ctx, fmt.Sprintf(`UPDATE %s
+SET renewed_at = now(), expires_at = now() + $4 * interval '1 millisecond'
+WHERE key = $1 AND holder = $2 AND token = $3 AND expires_at > now()`, l.table),
+			lease.Key, lease.Identity, lease.Token, ttl.Milliseconds())
+		if err != nil {
+			return err
+		}
+		renewed = tag.RowsAffected() == 1
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to renew lease: %w", err)
+	}
+	return renewed, nil
+}
+
+// Release implements Lock.
+func (l *PostgresLock) Release(ctx context.Context, lease Lease) error {
+	err := l.withConn(ctx, func(conn pginterfaces.IConn) error {
+		_, err := conn.Exec(ctx, fmt.Sprintf(`UPDATE %s SET expires_at = now()
+WHERE key = $1 AND holder = $2 AND token = $3 AND expires_at > now()`, l.table),
+			lease.Key, lease.Identity, lease.Token)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to release lease: %w", err)
+	}
+	return nil
+}
+
+// Leader implements Lock.
+func (l *PostgresLock) Leader(ctx context.Context, key string) (Lease, bool, error) {
+	var lease Lease
+	var found bool
+	err := l.withConn(ctx, func(conn pginterfaces.IConn) error {
+		rows, err := conn.Query(ctx, fmt.Sprintf(
+			`SELECT holder, token FROM %s WHERE key = $1 AND expires_at > now()`, l.table), key)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		if rows.Next() {
+			lease.Key = key
+			if err := rows.Scan(&lease.Identity, &lease.Token); err != nil {
+				return err
+			}
+			found = true
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return Lease{}, false, fmt.Errorf("failed to read leader: %w", err)
+	}
+	return lease, found, nil
+}
+
+// withConn runs fn on a connection of the pool.
+func (l *PostgresLock) withConn(ctx context.Context, fn func(conn pginterfaces.IConn) error) error {
+	conn, err := l.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+	return fn(conn)
+}
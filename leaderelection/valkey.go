@@ -0,0 +1,147 @@
+package leaderelection
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	valkeyinterfaces "github.com/fsvxavier/nexs-lib/cache/valkey/interfaces"
+)
+
+// Scripts of the ValkeyLock. KEYS[1] is the owner key, holding the lease as
+// "<token>:<identity>" with the lease TTL, and KEYS[2] the fencing token
+// counter. Each operation is a single script, so a lease always has a TTL and
+// renewals and releases only act on the lease they were given.
+const (
+	// valkeyAcquireScript returns {1, owner} when the lease was acquired,
+	// otherwise {0, owner} of the current leader
+	valkeyAcquireScript = `local owner = redis.call('GET', KEYS[1])
+if owner then
+	return {0, owner}
+end
+owner = redis.call('INCR', KEYS[2]) .. ':' .. ARGV[1]
+redis.call('SET', KEYS[1], owner, 'PX', ARGV[2])
+return {1, owner}`
+
+	valkeyRenewScript = `if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0`
+
+	valkeyReleaseScript = `if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0`
+
+	valkeyLeaderScript = `return redis.call('GET', KEYS[1])`
+)
+
+// ValkeyLock stores leases in Valkey or Redis through the cache/valkey
+// client, with Lua scripts run by Eval. The lease expires after the TTL. The
+// fencing token is a counter incremented by each new leader in the same
+// script that takes the lease, and never expires. The keys of a lease share a
+// hash tag, so the scripts also run on clusters.
+type ValkeyLock struct {
+	client valkeyinterfaces.IClient
+	prefix string
+}
+
+// NewValkeyLock creates a ValkeyLock. Keys are prefixed with prefix,
+// "leaderelection" when empty.
+func NewValkeyLock(client valkeyinterfaces.IClient, prefix string) *ValkeyLock {
+	if prefix == "" {
+		prefix = "leaderelection"
+	}
+	return &ValkeyLock{client: client, prefix: prefix}
+}
+
+// Acquire implements Lock.
+func (l *ValkeyLock) Acquire(ctx context.Context, key, identity string, ttl time.Duration) (Lease, bool, error) {
+	result, err := l.client.Eval(ctx, valkeyAcquireScript, []string{l.ownerKey(key), l.tokenKey(key)},
+		identity, milliseconds(ttl))
+	if err != nil {
+		return Lease{}, false, fmt.Errorf("failed to acquire lease: %w", err)
+	}
+
+	reply, _ := result.([]interface{})
+	if len(reply) != 2 {
+		return Lease{}, false, fmt.Errorf("unexpected acquire result %v", result)
+	}
+	acquired, _ := reply[0].(int64)
+	owner, _ := reply[1].(string)
+	lease, err := parseOwner(key, owner)
+	if err != nil {
+		return Lease{}, false, err
+	}
+	return lease, acquired == 1, nil
+}
+
+// Renew implements Lock.
+func (l *ValkeyLock) Renew(ctx context.Context, lease Lease, ttl time.Duration) (bool, error) {
+	result, err := l.client.Eval(ctx, valkeyRenewScript, []string{l.ownerKey(lease.Key)},
+		formatOwner(lease), milliseconds(ttl))
+	if err != nil {
+		return false, fmt.Errorf("failed to renew lease: %w", err)
+	}
+	renewed, _ := result.(int64)
+	return renewed == 1, nil
+}
+
+// Release implements Lock.
+func (l *ValkeyLock) Release(ctx context.Context, lease Lease) error {
+	// A lease that expired, and possibly was acquired by another candidate,
+	// is left untouched
+	if _, err := l.client.Eval(ctx, valkeyReleaseScript, []string{l.ownerKey(lease.Key)}, formatOwner(lease)); err != nil {
+		return fmt.Errorf("failed to release lease: %w", err)
+	}
+	return nil
+}
+
+// Leader implements Lock.
+func (l *ValkeyLock) Leader(ctx context.Context, key string) (Lease, bool, error) {
+	result, err := l.client.Eval(ctx, valkeyLeaderScript, []string{l.ownerKey(key)})
+	if err != nil {
+		return Lease{}, false, fmt.Errorf("failed to read lease owner: %w", err)
+	}
+	owner, ok := result.(string)
+	if !ok {
+		return Lease{}, false, nil
+	}
+	lease, err := parseOwner(key, owner)
+	if err != nil {
+		return Lease{}, false, err
+	}
+	return lease, true, nil
+}
+
+// ownerKey returns the key of the lease owner.
+func (l *ValkeyLock) ownerKey(key string) string {
+	return l.prefix + ":{" + key + "}:owner"
+}
+
+// tokenKey returns the key of the fencing token counter.
+func (l *ValkeyLock) tokenKey(key string) string {
+	return l.prefix + ":{" + key + "}:token"
+}
+
+// milliseconds converts the TTL to the PX argument, at least 1.
+func milliseconds(ttl time.Duration) int64 {
+	return max(ttl.Milliseconds(), 1)
+}
+
+// formatOwner encodes the owner of a lease as "<token>:<identity>".
+func formatOwner(lease Lease) string {
+	return strconv.FormatInt(lease.Token, 10) + ":" + lease.Identity
+}
+
+// parseOwner decodes an owner encoded by formatOwner.
+func parseOwner(key, owner string) (Lease, error) {
+	token, identity, ok := strings.Cut(owner, ":")
+	n, err := strconv.ParseInt(token, 10, 64)
+	if !ok || err != nil {
+		return Lease{}, fmt.Errorf("invalid lease owner %q", owner)
+	}
+	return Lease{Key: key, Identity: identity, Token: n}, nil
+}
@@ -0,0 +1,152 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// Component is a part of a service with a lifetime: a server, a consumer,
+// a scheduler, a connection pool. Start returns once the component is
+// running; Stop returns once it has released its resources. Both should
+// honour the deadline of their context.
+type Component interface {
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+}
+
+// Failer is implemented by components that can fail after starting, such as
+// servers whose listener breaks. A value received from Failed makes Run
+// stop the service and return it.
+type Failer interface {
+	Failed() <-chan error
+}
+
+// Hook is a Component made of functions. Nil functions do nothing, so Hook
+// adapts pools and clients that only need closing.
+type Hook struct {
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+}
+
+// Start calls OnStart.
+func (h Hook) Start(ctx context.Context) error {
+	if h.OnStart == nil {
+		return nil
+	}
+	return h.OnStart(ctx)
+}
+
+// Stop calls OnStop.
+func (h Hook) Stop(ctx context.Context) error {
+	if h.OnStop == nil {
+		return nil
+	}
+	return h.OnStop(ctx)
+}
+
+// Closer adapts a Close function, e.g. of a connection pool or a logger,
+// into a Component with nothing to start.
+func Closer(close func() error) Component {
+	return Hook{OnStop: func(context.Context) error { return close() }}
+}
+
+// background runs a blocking function from Start until Stop.
+type background struct {
+	serve func(ctx context.Context) error
+	stop  func(ctx context.Context) error
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+	failed chan error
+}
+
+// Runner adapts a function that runs until its context is cancelled, such
+// as pgqueue.Worker.Run or httpserver.Server's ListenAndServe, into a
+// Component. Start runs it in a goroutine; Stop
+// cancels its context and waits for it to return. An error returned before
+// Stop is reported through Failed.
+func Runner(run func(ctx context.Context) error) Component {
+	return &background{serve: run}
+}
+
+// Service adapts a server with a blocking serve function and a graceful
+// shutdown function, such as http.Server's ListenAndServe and Shutdown.
+// Stop calls shutdown and waits for serve to return. An error returned by
+// serve before Stop is reported through Failed; http.ErrServerClosed is not
+// an error.
+func Service(serve func() error, shutdown func(ctx context.Context) error) Component {
+	return &background{
+		serve: func(context.Context) error { return serve() },
+		stop:  shutdown,
+	}
+}
+
+// Start implements Component.
+func (b *background) Start(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.done != nil {
+		return ErrAlreadyStarted
+	}
+
+	// The run context outlives the start context: it ends with Stop.
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	b.cancel = cancel
+	b.done = make(chan struct{})
+	b.failed = make(chan error, 1)
+
+	go func() {
+		defer close(b.done)
+		err := b.serve(runCtx)
+		if errors.Is(err, http.ErrServerClosed) {
+			err = nil
+		}
+		if err != nil && runCtx.Err() == nil {
+			// Reported once, through Failed rather than by Stop.
+			b.failed <- err
+			err = nil
+		}
+		b.err = err
+	}()
+	return nil
+}
+
+// Stop implements Component.
+func (b *background) Stop(ctx context.Context) error {
+	b.mu.Lock()
+	cancel, done := b.cancel, b.done
+	b.mu.Unlock()
+	if done == nil {
+		return nil
+	}
+
+	var stopErr error
+	if b.stop != nil {
+		stopErr = b.stop(ctx)
+	}
+	cancel()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return errors.Join(stopErr, ctx.Err())
+	}
+	if stopErr != nil {
+		return stopErr
+	}
+	if errors.Is(b.err, context.Canceled) {
+		return nil
+	}
+	return b.err
+}
+
+// Failed implements Failer.
+func (b *background) Failed() <-chan error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failed
+}
@@ -0,0 +1,413 @@
+// Package lifecycle runs the components of a long-running service: they are
+// registered with their dependencies, started in dependency order, and, on
+// SIGINT, SIGTERM, cancellation or the failure of a component, stopped in
+// reverse order with a timeout per component. Stop errors are aggregated in
+// a *ShutdownError.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Lifecycle errors.
+var (
+	ErrDuplicateComponent = errors.New("lifecycle: component already registered")
+	ErrUnknownDependency  = errors.New("lifecycle: unknown dependency")
+	ErrDependencyCycle    = errors.New("lifecycle: dependency cycle")
+	ErrAlreadyStarted     = errors.New("lifecycle: already started")
+)
+
+// Default timeouts of components without their own.
+const (
+	DefaultStartTimeout = 30 * time.Second
+	DefaultStopTimeout  = 10 * time.Second
+)
+
+// Phase is the lifecycle step a component failed in.
+type Phase string
+
+const (
+	PhaseStart Phase = "start"
+	PhaseRun   Phase = "run"
+	PhaseStop  Phase = "stop"
+)
+
+// ComponentError is the failure of a component.
+type ComponentError struct {
+	Component string
+	Phase     Phase
+	Duration  time.Duration
+	Err       error
+}
+
+// Error implements error.
+func (e *ComponentError) Error() string {
+	if e.Phase == PhaseRun {
+		return fmt.Sprintf("component %s failed: %v", e.Component, e.Err)
+	}
+	return fmt.Sprintf("component %s %s failed after %s: %v", e.Component, e.Phase, e.Duration, e.Err)
+}
+
+// Unwrap returns the original error.
+func (e *ComponentError) Unwrap() error {
+	return e.Err
+}
+
+// ShutdownError aggregates the components that failed to stop.
+type ShutdownError struct {
+	Errors []*ComponentError
+}
+
+// Error implements error.
+func (e *ShutdownError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("shutdown failed for %d component(s): %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// Unwrap allows errors.Is and errors.As on the aggregated errors.
+func (e *ShutdownError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// component is a registered component.
+type component struct {
+	name         string
+	impl         Component
+	dependsOn    []string
+	startTimeout time.Duration
+	stopTimeout  time.Duration
+}
+
+// ComponentOption configures a registered component.
+type ComponentOption func(*component)
+
+// DependsOn declares the components this one uses. They are started before
+// it and stopped after it.
+func DependsOn(names ...string) ComponentOption {
+	return func(c *component) {
+		c.dependsOn = append(c.dependsOn, names...)
+	}
+}
+
+// WithStartTimeout sets the start timeout of the component.
+func WithStartTimeout(timeout time.Duration) ComponentOption {
+	return func(c *component) {
+		c.startTimeout = timeout
+	}
+}
+
+// WithStopTimeout sets the stop timeout of the component.
+func WithStopTimeout(timeout time.Duration) ComponentOption {
+	return func(c *component) {
+		c.stopTimeout = timeout
+	}
+}
+
+// Option configures a Manager.
+type Option func(*Manager)
+
+// WithLogger sets the logger of lifecycle events. Defaults to slog.Default().
+func WithLogger(logger *slog.Logger) Option {
+	return func(m *Manager) {
+		m.logger = logger
+	}
+}
+
+// WithSignals sets the signals stopping Run. Defaults to SIGINT and SIGTERM.
+func WithSignals(signals ...os.Signal) Option {
+	return func(m *Manager) {
+		m.signals = signals
+	}
+}
+
+// WithDefaultTimeouts sets the timeouts of components without their own.
+func WithDefaultTimeouts(start, stop time.Duration) Option {
+	return func(m *Manager) {
+		m.startTimeout, m.stopTimeout = start, stop
+	}
+}
+
+// Manager starts and stops the components of a service.
+type Manager struct {
+	logger       *slog.Logger
+	signals      []os.Signal
+	startTimeout time.Duration
+	stopTimeout  time.Duration
+
+	mu         sync.Mutex
+	components []*component
+	byName     map[string]*component
+	started    []*component
+	starting   bool
+	stopped    bool
+	stopDone   chan struct{}
+	stopResult error
+}
+
+// New creates a Manager.
+func New(opts ...Option) *Manager {
+	m := &Manager{
+		logger:       slog.Default(),
+		signals:      []os.Signal{os.Interrupt, syscall.SIGTERM},
+		startTimeout: DefaultStartTimeout,
+		stopTimeout:  DefaultStopTimeout,
+		byName:       make(map[string]*component),
+		stopDone:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Register adds a component. Components are registered before Start;
+// dependencies may be registered after their dependents.
+func (m *Manager) Register(name string, c Component, opts ...ComponentOption) error {
+	if name == "" || c == nil {
+		return fmt.Errorf("lifecycle: component %q: name and component are required", name)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.starting {
+		return ErrAlreadyStarted
+	}
+	if _, exists := m.byName[name]; exists {
+		return fmt.Errorf("%w: %s", ErrDuplicateComponent, name)
+	}
+
+	comp := &component{name: name, impl: c}
+	for _, opt := range opts {
+		opt(comp)
+	}
+	m.components = append(m.components, comp)
+	m.byName[name] = comp
+	return nil
+}
+
+// Order returns the start order of the components. They stop in reverse.
+func (m *Manager) Order() ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ordered, err := m.order()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(ordered))
+	for i, comp := range ordered {
+		names[i] = comp.name
+	}
+	return names, nil
+}
+
+// Start starts the components in dependency order, each within its start
+// timeout. When one fails, the components already started are stopped and
+// the start error is returned, joined with the stop errors.
+func (m *Manager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	if m.starting {
+		m.mu.Unlock()
+		return ErrAlreadyStarted
+	}
+	ordered, err := m.order()
+	if err != nil {
+		m.mu.Unlock()
+		return err
+	}
+	m.starting = true
+	m.mu.Unlock()
+
+	for _, comp := range ordered {
+		start := time.Now()
+		err := m.call(ctx, comp.impl.Start, timeoutOr(comp.startTimeout, m.startTimeout))
+		if err != nil {
+			startErr := &ComponentError{Component: comp.name, Phase: PhaseStart, Duration: time.Since(start), Err: err}
+			m.logger.ErrorContext(ctx, "component failed to start", "component", comp.name, "error", err)
+			return errors.Join(startErr, m.Stop(context.WithoutCancel(ctx)))
+		}
+
+		m.mu.Lock()
+		m.started = append(m.started, comp)
+		m.mu.Unlock()
+		m.logger.InfoContext(ctx, "component started", "component", comp.name, "duration", time.Since(start))
+	}
+	return nil
+}
+
+// Stop stops the started components in reverse start order, each within
+// its stop timeout. Every component is stopped even if others fail; the
+// failures are returned in a *ShutdownError. Later calls wait for the first
+// one and return its result.
+func (m *Manager) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	if m.stopped {
+		m.mu.Unlock()
+		<-m.stopDone
+		return m.stopResult
+	}
+	m.stopped = true
+	started := m.started
+	m.mu.Unlock()
+
+	var failures []*ComponentError
+	for i := len(started) - 1; i >= 0; i-- {
+		comp := started[i]
+		start := time.Now()
+		err := m.call(ctx, comp.impl.Stop, timeoutOr(comp.stopTimeout, m.stopTimeout))
+		if err != nil {
+			failures = append(failures, &ComponentError{Component: comp.name, Phase: PhaseStop, Duration: time.Since(start), Err: err})
+			m.logger.ErrorContext(ctx, "component failed to stop", "component", comp.name, "error", err)
+			continue
+		}
+		m.logger.InfoContext(ctx, "component stopped", "component", comp.name, "duration", time.Since(start))
+	}
+
+	var result error
+	if len(failures) > 0 {
+		result = &ShutdownError{Errors: failures}
+	}
+	m.stopResult = result
+	close(m.stopDone)
+	return result
+}
+
+// Run starts the components and blocks until a signal is received, ctx is
+// cancelled or a component fails, then stops them. It returns nil after a
+// clean shutdown; otherwise the start or run failure joined with the stop
+// errors.
+func (m *Manager) Run(ctx context.Context) error {
+	ctx, cancelSignals := signal.NotifyContext(ctx, m.signals...)
+	defer cancelSignals()
+
+	if err := m.Start(ctx); err != nil {
+		return err
+	}
+
+	failed := make(chan error, 1)
+	watchCtx, stopWatching := context.WithCancel(ctx)
+	defer stopWatching()
+	m.mu.Lock()
+	started := m.started
+	m.mu.Unlock()
+	for _, comp := range started {
+		failer, ok := comp.impl.(Failer)
+		if !ok {
+			continue
+		}
+		go func(name string, ch <-chan error) {
+			select {
+			case err := <-ch:
+				select {
+				case failed <- &ComponentError{Component: name, Phase: PhaseRun, Err: err}:
+				default:
+				}
+			case <-watchCtx.Done():
+			}
+		}(comp.name, failer.Failed())
+	}
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		m.logger.InfoContext(ctx, "shutting down", "cause", context.Cause(ctx))
+	case runErr = <-failed:
+		m.logger.ErrorContext(ctx, "shutting down after component failure", "error", runErr)
+	}
+
+	stopErr := m.Stop(context.WithoutCancel(ctx))
+	return errors.Join(runErr, stopErr)
+}
+
+// call runs fn with a timeout. A function ignoring its context is abandoned
+// when the timeout expires.
+func (m *Manager) call(ctx context.Context, fn func(context.Context) error, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				errCh <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		errCh <- fn(ctx)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func timeoutOr(timeout, fallback time.Duration) time.Duration {
+	if timeout > 0 {
+		return timeout
+	}
+	return fallback
+}
+
+// order sorts the components so that dependencies come first, keeping the
+// registration order between independent components.
+func (m *Manager) order() ([]*component, error) {
+	for _, comp := range m.components {
+		for _, dep := range comp.dependsOn {
+			if _, exists := m.byName[dep]; !exists {
+				return nil, fmt.Errorf("%w: %s depends on %s", ErrUnknownDependency, comp.name, dep)
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(m.components))
+	ordered := make([]*component, 0, len(m.components))
+
+	var visit func(comp *component, path []string) error
+	visit = func(comp *component, path []string) error {
+		switch state[comp.name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(append(path, comp.name), " -> "))
+		}
+		state[comp.name] = visiting
+		for _, dep := range comp.dependsOn {
+			if err := visit(m.byName[dep], append(path, comp.name)); err != nil {
+				return err
+			}
+		}
+		state[comp.name] = visited
+		ordered = append(ordered, comp)
+		return nil
+	}
+
+	for _, comp := range m.components {
+		if err := visit(comp, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
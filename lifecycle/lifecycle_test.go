@@ -0,0 +1,288 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder records the start and stop calls of components.
+type recorder struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *recorder) add(call string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call)
+}
+
+func (r *recorder) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.calls...)
+}
+
+func (r *recorder) component(name string, startErr, stopErr error) Component {
+	return Hook{
+		OnStart: func(context.Context) error {
+			r.add("start " + name)
+			return startErr
+		},
+		OnStop: func(context.Context) error {
+			r.add("stop " + name)
+			return stopErr
+		},
+	}
+}
+
+func newManager(opts ...Option) *Manager {
+	return New(append([]Option{WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))}, opts...)...)
+}
+
+func TestManager_Order(t *testing.T) {
+	m := newManager()
+	rec := &recorder{}
+	require.NoError(t, m.Register("http", rec.component("http", nil, nil), DependsOn("db", "cache")))
+	require.NoError(t, m.Register("worker", rec.component("worker", nil, nil), DependsOn("db")))
+	require.NoError(t, m.Register("db", rec.component("db", nil, nil)))
+	require.NoError(t, m.Register("cache", rec.component("cache", nil, nil)))
+
+	order, err := m.Order()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"db", "cache", "http", "worker"}, order)
+
+	require.NoError(t, m.Start(context.Background()))
+	require.NoError(t, m.Stop(context.Background()))
+	assert.Equal(t, []string{
+		"start db", "start cache", "start http", "start worker",
+		"stop worker", "stop http", "stop cache", "stop db",
+	}, rec.get())
+
+	// Stop is idempotent.
+	require.NoError(t, m.Stop(context.Background()))
+	assert.Len(t, rec.get(), 8)
+}
+
+func TestManager_Register(t *testing.T) {
+	m := newManager()
+	require.NoError(t, m.Register("a", Hook{}, DependsOn("b")))
+	assert.ErrorIs(t, m.Register("a", Hook{}), ErrDuplicateComponent)
+	assert.Error(t, m.Register("", Hook{}))
+	assert.Error(t, m.Register("nil", nil))
+
+	_, err := m.Order()
+	assert.ErrorIs(t, err, ErrUnknownDependency)
+
+	require.NoError(t, m.Register("b", Hook{}, DependsOn("c")))
+	require.NoError(t, m.Register("c", Hook{}, DependsOn("a")))
+	_, err = m.Order()
+	assert.ErrorIs(t, err, ErrDependencyCycle)
+	assert.Contains(t, err.Error(), "a -> b -> c -> a")
+	assert.ErrorIs(t, m.Start(context.Background()), ErrDependencyCycle)
+}
+
+func TestManager_StartFailure(t *testing.T) {
+	m := newManager()
+	rec := &recorder{}
+	boom := errors.New("boom")
+	require.NoError(t, m.Register("db", rec.component("db", nil, nil)))
+	require.NoError(t, m.Register("http", rec.component("http", boom, nil), DependsOn("db")))
+	require.NoError(t, m.Register("worker", rec.component("worker", nil, nil), DependsOn("http")))
+
+	err := m.Start(context.Background())
+	require.ErrorIs(t, err, boom)
+
+	var compErr *ComponentError
+	require.ErrorAs(t, err, &compErr)
+	assert.Equal(t, "http", compErr.Component)
+	assert.Equal(t, PhaseStart, compErr.Phase)
+	assert.Equal(t, []string{"start db", "start http", "stop db"}, rec.get())
+
+	assert.ErrorIs(t, m.Start(context.Background()), ErrAlreadyStarted)
+	assert.ErrorIs(t, m.Register("late", Hook{}), ErrAlreadyStarted)
+}
+
+func TestManager_StopErrors(t *testing.T) {
+	m := newManager(WithDefaultTimeouts(time.Second, 50*time.Millisecond))
+	rec := &recorder{}
+	boom := errors.New("boom")
+	require.NoError(t, m.Register("db", rec.component("db", nil, boom)))
+	require.NoError(t, m.Register("slow", Hook{OnStop: func(context.Context) error {
+		rec.add("stop slow")
+		select {} // ignores its context
+	}}, DependsOn("db")))
+	require.NoError(t, m.Register("panics", Hook{OnStop: func(context.Context) error {
+		panic("oops")
+	}}, DependsOn("slow")))
+	require.NoError(t, m.Register("ok", rec.component("ok", nil, nil), DependsOn("panics")))
+
+	require.NoError(t, m.Start(context.Background()))
+	err := m.Stop(context.Background())
+
+	var shutdownErr *ShutdownError
+	require.ErrorAs(t, err, &shutdownErr)
+	require.Len(t, shutdownErr.Errors, 3)
+	assert.Equal(t, "panics", shutdownErr.Errors[0].Component)
+	assert.Contains(t, shutdownErr.Errors[0].Error(), "panic: oops")
+	assert.Equal(t, "slow", shutdownErr.Errors[1].Component)
+	assert.ErrorIs(t, shutdownErr.Errors[1], context.DeadlineExceeded)
+	assert.Equal(t, "db", shutdownErr.Errors[2].Component)
+	assert.ErrorIs(t, err, boom)
+	assert.Equal(t, []string{"start db", "start ok", "stop ok", "stop slow", "stop db"}, rec.get())
+}
+
+func TestManager_Run(t *testing.T) {
+	t.Run("Cancel", func(t *testing.T) {
+		m := newManager()
+		rec := &recorder{}
+		require.NoError(t, m.Register("db", rec.component("db", nil, nil)))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() { done <- m.Run(ctx) }()
+
+		assert.Eventually(t, func() bool { return len(rec.get()) == 1 }, time.Second, time.Millisecond)
+		cancel()
+		require.NoError(t, <-done)
+		assert.Equal(t, []string{"start db", "stop db"}, rec.get())
+	})
+
+	t.Run("Signal", func(t *testing.T) {
+		m := newManager(WithSignals(syscall.SIGUSR1))
+		rec := &recorder{}
+		require.NoError(t, m.Register("db", rec.component("db", nil, nil)))
+
+		done := make(chan error, 1)
+		go func() { done <- m.Run(context.Background()) }()
+
+		assert.Eventually(t, func() bool { return len(rec.get()) == 1 }, time.Second, time.Millisecond)
+		require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("Run did not return after the signal")
+		}
+		assert.Equal(t, []string{"start db", "stop db"}, rec.get())
+	})
+
+	t.Run("ComponentFailure", func(t *testing.T) {
+		m := newManager()
+		rec := &recorder{}
+		boom := errors.New("boom")
+		fail := make(chan struct{})
+		require.NoError(t, m.Register("db", rec.component("db", nil, nil)))
+		require.NoError(t, m.Register("worker", Runner(func(ctx context.Context) error {
+			select {
+			case <-fail:
+				return boom
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}), DependsOn("db")))
+
+		done := make(chan error, 1)
+		go func() { done <- m.Run(context.Background()) }()
+
+		assert.Eventually(t, func() bool { return len(rec.get()) == 1 }, time.Second, time.Millisecond)
+		close(fail)
+		err := <-done
+		require.ErrorIs(t, err, boom)
+
+		var compErr *ComponentError
+		require.ErrorAs(t, err, &compErr)
+		assert.Equal(t, "worker", compErr.Component)
+		assert.Equal(t, PhaseRun, compErr.Phase)
+		assert.Equal(t, []string{"start db", "stop db"}, rec.get())
+	})
+}
+
+func TestRunner(t *testing.T) {
+	stopped := make(chan struct{})
+	runner := Runner(func(ctx context.Context) error {
+		<-ctx.Done()
+		close(stopped)
+		return ctx.Err()
+	})
+
+	// Stop before Start does nothing.
+	require.NoError(t, runner.Stop(context.Background()))
+
+	startCtx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, runner.Start(startCtx))
+	cancel() // the run context does not end with the start context
+	assert.ErrorIs(t, runner.Start(context.Background()), ErrAlreadyStarted)
+
+	select {
+	case <-stopped:
+		t.Fatal("runner stopped with its start context")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	require.NoError(t, runner.Stop(context.Background()))
+	<-stopped
+
+	stuck := Runner(func(context.Context) error { select {} })
+	require.NoError(t, stuck.Start(context.Background()))
+	ctx, cancelStop := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelStop()
+	assert.ErrorIs(t, stuck.Stop(ctx), context.DeadlineExceeded)
+}
+
+func TestService(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})}
+
+	service := Service(func() error { return server.Serve(ln) }, server.Shutdown)
+	require.NoError(t, service.Start(context.Background()))
+
+	resp, err := http.Get("http://" + ln.Addr().String())
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	require.NoError(t, service.Stop(context.Background()))
+	select {
+	case err := <-service.(Failer).Failed():
+		t.Fatalf("unexpected failure: %v", err)
+	default:
+	}
+
+	// A serve error is a failure.
+	broken := Service(func() error { return errors.New("listen: address in use") }, func(context.Context) error { return nil })
+	require.NoError(t, broken.Start(context.Background()))
+	select {
+	case err := <-broken.(Failer).Failed():
+		assert.EqualError(t, err, "listen: address in use")
+	case <-time.After(time.Second):
+		t.Fatal("no failure reported")
+	}
+	require.NoError(t, broken.Stop(context.Background()))
+}
+
+func TestCloser(t *testing.T) {
+	closed := false
+	c := Closer(func() error {
+		closed = true
+		return nil
+	})
+	require.NoError(t, c.Start(context.Background()))
+	assert.False(t, closed)
+	require.NoError(t, c.Stop(context.Background()))
+	assert.True(t, closed)
+}
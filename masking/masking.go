@@ -0,0 +1,208 @@
+// Package masking masks personal and secret data by data class (email,
+// phone, card number, CPF, name, secret) with interchangeable strategies:
+// partial masking for logs and support screens, redaction, tokenization and
+// consistent pseudonymization via HMAC for analytics and test fixtures. A
+// Redactor applies them by field name, struct tag or detection in free text;
+// it is shared by the logger (logger.NewRedactingLogger), the error
+// responses (domainerrors.ResponseProfile.Redactor) and the fixture
+// generators.
+package masking
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Class is a class of sensitive data.
+type Class string
+
+// Data classes.
+const (
+	Email  Class = "email"
+	Phone  Class = "phone"
+	PAN    Class = "pan"
+	CPF    Class = "cpf"
+	Name   Class = "name"
+	Secret Class = "secret"
+)
+
+// Redacted replaces the values masked by Redact.
+const Redacted = "[REDACTED]"
+
+// secretMask replaces secrets and values that cannot be partially masked;
+// its fixed length hides the length of the value.
+const secretMask = "********"
+
+// Strategy masks a value of a class. Implementations must be safe for
+// concurrent use.
+type Strategy interface {
+	Mask(class Class, value string) string
+}
+
+// StrategyFunc adapts a function to Strategy.
+type StrategyFunc func(class Class, value string) string
+
+// Mask implements Strategy.
+func (f StrategyFunc) Mask(class Class, value string) string {
+	return f(class, value)
+}
+
+// Redact replaces every value by Redacted.
+var Redact Strategy = StrategyFunc(func(Class, string) string {
+	return Redacted
+})
+
+// Partial keeps the parts of the value needed to recognize it, keeping its
+// format:
+//
+//	email  ana.souza@example.com  a********@example.com
+//	phone  +55 11 98765-4321      +** ** *****-4321
+//	pan    4111 1111 1111 1111    4111 11** **** 1111
+//	cpf    123.456.789-09         ***.456.789-**
+//	name   Ana Souza              A** S****
+//	secret s3cr3t                 ********
+var Partial Strategy = StrategyFunc(partial)
+
+// partial implements Partial.
+func partial(class Class, value string) string {
+	if value == "" {
+		return ""
+	}
+	switch class {
+	case Email:
+		at := strings.LastIndexByte(value, '@')
+		if at <= 0 {
+			return secretMask
+		}
+		local := []rune(value[:at])
+		return string(local[0]) + strings.Repeat("*", len(local)-1) + value[at:]
+	case Phone:
+		return maskDigits(value, func(i, n int) bool { return i >= n-4 })
+	case PAN:
+		return maskDigits(value, func(i, n int) bool { return n >= 13 && (i < 6 || i >= n-4) })
+	case CPF:
+		return maskDigits(value, func(i, n int) bool { return n == 11 && i >= 3 && i < 9 })
+	case Name:
+		var b strings.Builder
+		start := true
+		for _, r := range value {
+			switch {
+			case unicode.IsSpace(r) || r == '-' || r == '\'':
+				start = true
+				b.WriteRune(r)
+			case start:
+				start = false
+				b.WriteRune(r)
+			default:
+				b.WriteByte('*')
+			}
+		}
+		return b.String()
+	}
+	return secretMask
+}
+
+// maskDigits replaces the digits of value for which keep returns false by
+// '*', keeping the other characters. keep receives the index of the digit
+// among the n digits of value.
+func maskDigits(value string, keep func(i, n int) bool) string {
+	n := 0
+	for _, r := range value {
+		if isDigit(r) {
+			n++
+		}
+	}
+	if n == 0 {
+		return secretMask
+	}
+	out := []rune(value)
+	i := 0
+	for j, r := range out {
+		if !isDigit(r) {
+			continue
+		}
+		if !keep(i, n) {
+			out[j] = '*'
+		}
+		i++
+	}
+	return string(out)
+}
+
+// isDigit reports whether r is an ASCII digit.
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// digits returns the ASCII digits of s.
+func digits(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] >= '0' && s[i] <= '9' {
+			out = append(out, s[i]-'0')
+		}
+	}
+	return out
+}
+
+// ValidCPF reports whether s, formatted or not, is a CPF with valid check
+// digits.
+func ValidCPF(s string) bool {
+	d := digits(s)
+	if len(d) != 11 {
+		return false
+	}
+	same := true
+	for _, x := range d[1:] {
+		same = same && x == d[0]
+	}
+	if same {
+		return false
+	}
+	c1, c2 := cpfCheckDigits(d[:9])
+	return d[9] == c1 && d[10] == c2
+}
+
+// cpfCheckDigits returns the check digits of the 9 base digits of a CPF.
+func cpfCheckDigits(base []byte) (byte, byte) {
+	check := func(d []byte) byte {
+		sum := 0
+		for i, x := range d {
+			sum += int(x) * (len(d) + 1 - i)
+		}
+		r := sum * 10 % 11
+		if r == 10 {
+			r = 0
+		}
+		return byte(r)
+	}
+	c1 := check(base)
+	c2 := check(append(append([]byte{}, base...), c1))
+	return c1, c2
+}
+
+// ValidPAN reports whether s, formatted or not, has 13 to 19 digits with a
+// valid Luhn check digit.
+func ValidPAN(s string) bool {
+	d := digits(s)
+	if len(d) < 13 || len(d) > 19 {
+		return false
+	}
+	return luhnCheckDigit(d[:len(d)-1]) == d[len(d)-1]
+}
+
+// luhnCheckDigit returns the Luhn check digit of the digits.
+func luhnCheckDigit(d []byte) byte {
+	sum := 0
+	for i := len(d) - 1; i >= 0; i-- {
+		x := int(d[i])
+		if (len(d)-1-i)%2 == 0 {
+			x *= 2
+			if x > 9 {
+				x -= 9
+			}
+		}
+		sum += x
+	}
+	return byte((10 - sum%10) % 10)
+}
@@ -0,0 +1,211 @@
+package masking
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartial(t *testing.T) {
+	for _, tc := range []struct {
+		class       Class
+		value, want string
+	}{
+		{Email, "ana.souza@example.com", "a********@example.com"},
+		{Email, "not-an-email", "********"},
+		{Phone, "+55 11 98765-4321", "+** ** *****-4321"},
+		{PAN, "4111 1111 1111 1111", "4111 11** **** 1111"},
+		{PAN, "4111111111111111", "411111******1111"},
+		{PAN, "1234", "****"},
+		{CPF, "123.456.789-09", "***.456.789-**"},
+		{CPF, "12345678909", "***456789**"},
+		{Name, "Ana Souza", "A** S****"},
+		{Name, "João D'Ávila", "J*** D'Á****"},
+		{Secret, "s3cr3t", "********"},
+		{Email, "", ""},
+	} {
+		assert.Equal(t, tc.want, Partial.Mask(tc.class, tc.value), "%s %q", tc.class, tc.value)
+	}
+	assert.Equal(t, Redacted, Redact.Mask(Email, "ana@example.com"))
+}
+
+func TestValidators(t *testing.T) {
+	assert.True(t, ValidCPF("123.456.789-09"))
+	assert.False(t, ValidCPF("123.456.789-00"))
+	assert.False(t, ValidCPF("111.111.111-11"))
+	assert.True(t, ValidPAN("4111 1111 1111 1111"))
+	assert.False(t, ValidPAN("4111 1111 1111 1112"))
+	assert.False(t, ValidPAN("4111"))
+}
+
+func TestPseudonymizer(t *testing.T) {
+	p := NewPseudonymizer([]byte("key"))
+
+	for _, tc := range []struct {
+		class        Class
+		value, equal string
+		valid        func(string) bool
+	}{
+		{Email, "ana.souza@example.com", " Ana.Souza@Example.com", func(s string) bool {
+			return strings.HasSuffix(s, "@example.com") && !strings.ContainsAny(s, "áãéíóôú")
+		}},
+		{CPF, "123.456.789-09", "12345678909", ValidCPF},
+		{PAN, "5500 0000 0000 0004", "5500000000000004", func(s string) bool {
+			return ValidPAN(s) && strings.HasPrefix(s, "5500 00") && len(s) == 19
+		}},
+		{Phone, "+55 11 98765-4321", "+55 (11) 98765-4321", func(s string) bool {
+			return strings.HasPrefix(s, "+55 ") && len(s) == len("+55 11 98765-4321")
+		}},
+		{Name, "Ana Souza", "ana  souza", func(s string) bool { return len(strings.Fields(s)) == 3 }},
+	} {
+		pseudonym := p.Mask(tc.class, tc.value)
+		assert.NotEqual(t, tc.value, pseudonym)
+		assert.Equal(t, pseudonym, p.Mask(tc.class, tc.value), "consistent")
+		assert.Equal(t, normalize(tc.class, pseudonym), normalize(tc.class, p.Mask(tc.class, tc.equal)), "normalized")
+		assert.True(t, tc.valid(pseudonym), "%s %q", tc.class, pseudonym)
+		assert.NotEqual(t, pseudonym, NewPseudonymizer([]byte("other")).Mask(tc.class, tc.value), "keyed")
+	}
+
+	// Emails and names carry 64 bits of the HMAC, so they do not collide
+	// like the 20x20 names alone would
+	seen := make(map[string]string)
+	for i := range 2000 {
+		value := fmt.Sprintf("user%d@example.com", i)
+		pseudonym := p.Mask(Email, value)
+		assert.NotContains(t, seen, pseudonym, "%s and %s collide", value, seen[pseudonym])
+		seen[pseudonym] = value
+		assert.NotEqual(t, p.Mask(Name, fmt.Sprintf("User %d", i)), p.Mask(Name, fmt.Sprintf("User %d", i+1)))
+	}
+
+	assert.Len(t, p.Mask(CPF, "12345678909"), 11, "unformatted CPFs stay unformatted")
+	assert.Len(t, p.Mask(Secret, "s3cr3t"), 16)
+	assert.Empty(t, p.Mask(Email, ""))
+}
+
+func TestGenerator(t *testing.T) {
+	g, same := NewGenerator(42), NewGenerator(42)
+	for range 20 {
+		cpf := g.CPF()
+		assert.True(t, ValidCPF(cpf), cpf)
+		assert.Equal(t, cpf, same.CPF(), "reproducible")
+		pan := g.PAN()
+		assert.True(t, ValidPAN(pan), pan)
+		same.PAN()
+	}
+	assert.True(t, strings.HasSuffix(g.Email(), "@example.com"))
+	assert.True(t, strings.HasPrefix(g.Phone(), "+55 11 9"))
+	assert.NotEmpty(t, g.Name())
+	assert.NotEqual(t, NewGenerator(1).CPF(), NewGenerator(2).CPF())
+}
+
+func TestTokenizer(t *testing.T) {
+	tokenizer := NewTokenizer([]byte("key"), NewMemoryTokenStore())
+
+	token := tokenizer.Mask(PAN, "4111111111111111")
+	assert.True(t, strings.HasPrefix(token, "tok_pan_"), token)
+	assert.NotContains(t, token, "4111")
+	assert.Equal(t, token, tokenizer.Mask(PAN, "4111111111111111"))
+	assert.NotEqual(t, token, tokenizer.Mask(PAN, "5500000000000004"))
+
+	value, err := tokenizer.Detokenize(token)
+	require.NoError(t, err)
+	assert.Equal(t, "4111111111111111", value)
+	_, err = tokenizer.Detokenize("tok_pan_unknown")
+	assert.ErrorIs(t, err, ErrTokenNotFound)
+
+	failing := NewTokenizer([]byte("key"), failingStore{})
+	assert.Equal(t, Redacted, failing.Mask(PAN, "4111111111111111"))
+}
+
+type failingStore struct{}
+
+func (failingStore) Put(string, string) error   { return errors.New("unavailable") }
+func (failingStore) Get(string) (string, error) { return "", errors.New("unavailable") }
+
+func TestRedactor_Value(t *testing.T) {
+	r := NewRedactor(WithField(Name, "holder"), WithStrategy(Secret, Redact))
+
+	class, ok := r.ClassOf("Customer-Email")
+	assert.False(t, ok, class)
+	class, ok = r.ClassOf("e_mail")
+	assert.True(t, ok)
+	assert.Equal(t, Email, class)
+
+	assert.Equal(t, "a********@example.com", r.Value("E-Mail", "ana.souza@example.com"))
+	assert.Equal(t, Redacted, r.Value("api_key", "abc"))
+	assert.Equal(t, "A** S****", r.Value("holder", "Ana Souza"))
+	assert.Equal(t, "*******4321", r.Value("phone", 11987654321))
+	assert.Equal(t, "plain", r.Value("note", "plain"))
+	assert.Nil(t, r.Value("email", nil))
+
+	email := "ana.souza@example.com"
+	masked := r.Value("email", &email).(*string)
+	assert.Equal(t, "a********@example.com", *masked)
+	assert.Equal(t, "ana.souza@example.com", email)
+
+	original := map[string]any{
+		"cpf":     "123.456.789-09",
+		"contact": map[string]string{"telefone": "11 98765-4321"},
+		"items":   []any{map[string]any{"card_number": "4111111111111111"}},
+	}
+	out := r.Map(original)
+	assert.Equal(t, "***.456.789-**", out["cpf"])
+	assert.Equal(t, "** *****-4321", out["contact"].(map[string]string)["telefone"])
+	assert.Equal(t, "411111******1111", out["items"].([]any)[0].(map[string]any)["card_number"])
+	assert.Equal(t, "123.456.789-09", original["cpf"], "not modified")
+
+	// free text is only scanned with WithTextDetection
+	assert.Equal(t, "from ana@example.com", r.Value("note", "from ana@example.com"))
+}
+
+func TestRedactor_Text(t *testing.T) {
+	r := NewRedactor(WithTextDetection())
+
+	assert.Equal(t, "user a****@example.com paid with 4111 11** **** 1111, cpf ***.456.789-**",
+		r.Text("user ana.s@example.com paid with 4111 1111 1111 1111, cpf 123.456.789-09"))
+	assert.Equal(t, "order 12345678901 total 1234567890123", r.Text("order 12345678901 total 1234567890123"),
+		"numbers with invalid check digits are kept")
+
+	cause := errors.New("duplicate key ana@example.com")
+	masked := r.Value("error", cause).(error)
+	assert.Equal(t, "duplicate key a**@example.com", masked.Error())
+	assert.ErrorIs(t, masked, cause)
+	plain := errors.New("timeout")
+	assert.Same(t, plain, r.Value("error", plain))
+}
+
+func TestRedactor_Struct(t *testing.T) {
+	type card struct {
+		Number string `mask:"pan"`
+	}
+	type customer struct {
+		ID     int
+		Email  string  `mask:"email"`
+		CPF    *string `mask:"cpf"`
+		Phone  *string `mask:"phone"`
+		Cards  []card
+		Backup *card
+	}
+	cpf := "123.456.789-09"
+	c := customer{ID: 1, Email: "ana@example.com", CPF: &cpf, Cards: []card{{Number: "4111111111111111"}}, Backup: &card{Number: "5500000000000004"}}
+
+	r := NewRedactor(WithStrategy(PAN, NewPseudonymizer([]byte("key"))))
+	require.NoError(t, r.Struct(&c))
+	assert.Equal(t, 1, c.ID)
+	assert.Equal(t, "a**@example.com", c.Email)
+	assert.Equal(t, "***.456.789-**", *c.CPF)
+	assert.Nil(t, c.Phone)
+	assert.True(t, ValidPAN(c.Cards[0].Number))
+	assert.NotEqual(t, "4111111111111111", c.Cards[0].Number)
+	assert.NotEqual(t, "5500000000000004", c.Backup.Number)
+
+	assert.ErrorIs(t, r.Struct(c), ErrNotPointer)
+	invalid := struct {
+		Count int `mask:"secret"`
+	}{}
+	assert.Error(t, r.Struct(&invalid))
+}
@@ -0,0 +1,224 @@
+package masking
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+)
+
+// Names used by the pseudonyms and the fixtures.
+var (
+	firstNames = []string{
+		"Ana", "Bruno", "Carla", "Daniel", "Eduarda", "Felipe", "Gabriela", "Henrique", "Isabela", "João",
+		"Larissa", "Marcos", "Natália", "Otávio", "Paula", "Rafael", "Sofia", "Thiago", "Vitória", "Lucas",
+	}
+	lastNames = []string{
+		"Almeida", "Barbosa", "Cardoso", "Dias", "Esteves", "Ferreira", "Gomes", "Lima", "Martins", "Nunes",
+		"Oliveira", "Pereira", "Ribeiro", "Santos", "Teixeira", "Vieira", "Costa", "Souza", "Rocha", "Moura",
+	}
+)
+
+// fixtureDomain is the domain of the generated emails, reserved by RFC 2606.
+const fixtureDomain = "example.com"
+
+// suffixEncoding encodes the digest suffix of email and name pseudonyms.
+var suffixEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Pseudonymizer replaces values by fake values of the same class derived
+// from the HMAC of the value with a key. Pseudonyms are consistent, so joins
+// and counts over pseudonymized data still work; valid, with CPF check
+// digits and Luhn-valid card numbers, so they pass validation in tests;
+// and irreversible without the key. Values are normalized first: emails are
+// compared in lower case and numbers by their digits.
+//
+// Emails and names end with 64 bits of the HMAC in base32, so distinct
+// values get distinct pseudonyms in practice. CPFs, card numbers and phones
+// keep their format and have only the free digits for the HMAC, 9 for a CPF,
+// so large data sets may map distinct values to the same pseudonym.
+type Pseudonymizer struct {
+	key []byte
+}
+
+// NewPseudonymizer creates a Pseudonymizer with the HMAC key. Keep the key
+// secret: with it, pseudonyms of guessable values can be recomputed.
+func NewPseudonymizer(key []byte) *Pseudonymizer {
+	return &Pseudonymizer{key: key}
+}
+
+// Mask implements Strategy.
+func (p *Pseudonymizer) Mask(class Class, value string) string {
+	if value == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, p.key)
+	mac.Write([]byte(class))
+	mac.Write([]byte{0})
+	mac.Write([]byte(normalize(class, value)))
+	sum := mac.Sum(nil)
+	r := rand.New(rand.NewPCG(binary.LittleEndian.Uint64(sum[:8]), binary.LittleEndian.Uint64(sum[8:16])))
+
+	switch class {
+	case Email:
+		first, last := firstNames[r.IntN(len(firstNames))], lastNames[r.IntN(len(lastNames))]
+		suffix := strings.ToLower(suffixEncoding.EncodeToString(sum[16:24]))
+		return fmt.Sprintf("%s.%s.%s@%s", fold(first), fold(last), suffix, fixtureDomain)
+	case Name:
+		return generate(r, class, value) + " " + suffixEncoding.EncodeToString(sum[16:24])
+	}
+	return generate(r, class, value)
+}
+
+// normalize returns the form of the value whose pseudonym is computed.
+func normalize(class Class, value string) string {
+	switch class {
+	case Email:
+		return strings.ToLower(strings.TrimSpace(value))
+	case Phone, PAN, CPF:
+		d := digits(value)
+		for i := range d {
+			d[i] += '0'
+		}
+		return string(d)
+	case Name:
+		return strings.ToLower(strings.Join(strings.Fields(value), " "))
+	}
+	return value
+}
+
+// Generator generates fake values for test fixtures, with the formats of
+// the Pseudonymizer. The same seed generates the same values, so fixtures
+// are reproducible. It is not safe for concurrent use.
+type Generator struct {
+	r *rand.Rand
+}
+
+// NewGenerator creates a Generator with the seed.
+func NewGenerator(seed uint64) *Generator {
+	return &Generator{r: rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))}
+}
+
+// Value generates a value of the class.
+func (g *Generator) Value(class Class) string {
+	return generate(g.r, class, "")
+}
+
+// Email generates an email at example.com.
+func (g *Generator) Email() string { return g.Value(Email) }
+
+// Phone generates a Brazilian mobile number, +55 11 9XXXX-XXXX.
+func (g *Generator) Phone() string { return g.Value(Phone) }
+
+// PAN generates a Luhn-valid 16 digit Visa test card number.
+func (g *Generator) PAN() string { return g.Value(PAN) }
+
+// CPF generates a formatted CPF with valid check digits.
+func (g *Generator) CPF() string { return g.Value(CPF) }
+
+// Name generates a full name.
+func (g *Generator) Name() string { return g.Value(Name) }
+
+// generate creates a value of the class with r. A non-empty template is the
+// value being pseudonymized: its format (separators, length, card BIN and
+// phone country code) is kept.
+func generate(r *rand.Rand, class Class, template string) string {
+	switch class {
+	case Email:
+		first, last := firstNames[r.IntN(len(firstNames))], lastNames[r.IntN(len(lastNames))]
+		return fmt.Sprintf("%s.%s%d@%s", fold(first), fold(last), r.IntN(1000), fixtureDomain)
+
+	case Name:
+		return firstNames[r.IntN(len(firstNames))] + " " + lastNames[r.IntN(len(lastNames))]
+
+	case CPF:
+		d := randomDigits(r, 9)
+		c1, c2 := cpfCheckDigits(d)
+		d = append(d, c1, c2)
+		if template == "" || strings.ContainsAny(template, ".-") {
+			return format(d, "###.###.###-##")
+		}
+		return format(d, strings.Repeat("#", 11))
+
+	case PAN:
+		source := digits(template)
+		if len(source) < 13 || len(source) > 19 {
+			source, template = []byte{4}, ""
+			source = append(source, make([]byte, 15)...)
+		}
+		d := append(source[:6:6], randomDigits(r, len(source)-7)...)
+		d = append(d, luhnCheckDigit(d))
+		if template == "" {
+			return format(d, strings.Repeat("#", len(d)))
+		}
+		return format(d, digitPattern(template))
+
+	case Phone:
+		if template == "" {
+			d := append([]byte{5, 5, 1, 1, 9}, randomDigits(r, 8)...)
+			return format(d, "+## ## #####-####")
+		}
+		// keep the country code of international numbers
+		keep := 0
+		if strings.HasPrefix(template, "+") {
+			for _, c := range template[1:] {
+				if !isDigit(c) {
+					break
+				}
+				keep++
+			}
+		}
+		source := digits(template)
+		d := append(source[:keep:keep], randomDigits(r, len(source)-keep)...)
+		return format(d, digitPattern(template))
+	}
+
+	const hexDigits = "0123456789abcdef"
+	b := make([]byte, 16)
+	for i := range b {
+		b[i] = hexDigits[r.IntN(len(hexDigits))]
+	}
+	return string(b)
+}
+
+// randomDigits returns n random digits.
+func randomDigits(r *rand.Rand, n int) []byte {
+	d := make([]byte, n)
+	for i := range d {
+		d[i] = byte(r.IntN(10))
+	}
+	return d
+}
+
+// digitPattern replaces the digits of s by '#'.
+func digitPattern(s string) string {
+	return strings.Map(func(r rune) rune {
+		if isDigit(r) {
+			return '#'
+		}
+		return r
+	}, s)
+}
+
+// format writes the digits in the '#' of the pattern.
+func format(d []byte, pattern string) string {
+	var b strings.Builder
+	i := 0
+	for _, c := range pattern {
+		if c == '#' && i < len(d) {
+			b.WriteByte('0' + d[i])
+			i++
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// fold lowercases the name and removes its accents, for emails.
+func fold(s string) string {
+	return strings.NewReplacer("á", "a", "ã", "a", "â", "a", "é", "e", "ê", "e", "í", "i",
+		"ó", "o", "ô", "o", "õ", "o", "ú", "u", "ç", "c").Replace(strings.ToLower(s))
+}
@@ -0,0 +1,300 @@
+package masking
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// TagName is the struct tag marking the fields masked by Redactor.Struct,
+// with the class of the field:
+//
+//	type Customer struct {
+//		ID    string
+//		Email string `mask:"email"`
+//		CPF   string `mask:"cpf"`
+//	}
+const TagName = "mask"
+
+// ErrNotPointer is returned by Redactor.Struct when not given a non-nil
+// pointer.
+var ErrNotPointer = errors.New("masking: value must be a non-nil pointer")
+
+// DefaultFields are the field names classified by every Redactor, in the
+// normalized form of ClassOf.
+var DefaultFields = map[Class][]string{
+	Email:  {"email", "mail"},
+	Phone:  {"phone", "phonenumber", "telefone", "celular", "mobile"},
+	PAN:    {"pan", "cardnumber", "creditcard", "numerocartao"},
+	CPF:    {"cpf"},
+	Name:   {"fullname", "firstname", "lastname", "nome", "nomecompleto"},
+	Secret: {"password", "senha", "secret", "token", "accesstoken", "refreshtoken", "apikey", "authorization", "clientsecret"},
+}
+
+// Patterns of the values detected in free text.
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`)
+	cpfPattern   = regexp.MustCompile(`\b\d{3}\.?\d{3}\.?\d{3}-?\d{2}\b`)
+	panPattern   = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+)
+
+// Option configures a Redactor.
+type Option func(*Redactor)
+
+// WithField classifies the field names, in addition to DefaultFields.
+func WithField(class Class, names ...string) Option {
+	return func(r *Redactor) {
+		for _, name := range names {
+			r.fields[normalizeKey(name)] = class
+		}
+	}
+}
+
+// WithStrategy masks the values of the class with the strategy.
+func WithStrategy(class Class, s Strategy) Option {
+	return func(r *Redactor) {
+		r.strategies[class] = s
+	}
+}
+
+// WithDefaultStrategy masks the values of the classes without a strategy of
+// WithStrategy with s. The default is Partial.
+func WithDefaultStrategy(s Strategy) Option {
+	return func(r *Redactor) {
+		r.fallback = s
+	}
+}
+
+// WithTextDetection makes Value mask the emails, CPFs and card numbers found
+// in the strings and errors of fields that are not classified, as Text does.
+// CPFs and card numbers are only masked when their check digits are valid,
+// which avoids most false positives on ids and amounts.
+func WithTextDetection() Option {
+	return func(r *Redactor) {
+		r.detect = true
+	}
+}
+
+// Redactor masks values by the name of their field, by struct tag or by
+// detection in free text, with a strategy per class. It is safe for
+// concurrent use once created.
+type Redactor struct {
+	fields     map[string]Class
+	strategies map[Class]Strategy
+	fallback   Strategy
+	detect     bool
+}
+
+// NewRedactor creates a Redactor that classifies DefaultFields and masks
+// with Partial.
+func NewRedactor(opts ...Option) *Redactor {
+	r := &Redactor{
+		fields:     make(map[string]Class),
+		strategies: make(map[Class]Strategy),
+		fallback:   Partial,
+	}
+	for class, names := range DefaultFields {
+		for _, name := range names {
+			r.fields[name] = class
+		}
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// normalizeKey lowercases the field name and removes its separators, so
+// customer_email, customerEmail and Customer-Email are the same field.
+func normalizeKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '_', '-', '.', ' ':
+			return -1
+		}
+		return r
+	}, strings.ToLower(key))
+}
+
+// ClassOf returns the class of the field name. Names are compared in lower
+// case and without separators (_ - . and spaces).
+func (r *Redactor) ClassOf(key string) (Class, bool) {
+	class, ok := r.fields[normalizeKey(key)]
+	return class, ok
+}
+
+// Mask masks the value with the strategy of the class.
+func (r *Redactor) Mask(class Class, value string) string {
+	if s, ok := r.strategies[class]; ok {
+		return s.Mask(class, value)
+	}
+	return r.fallback.Mask(class, value)
+}
+
+// Value masks the value of the field. Values of classified fields are
+// masked whatever their type, formatted with fmt.Sprint when not strings;
+// maps and slices are walked so nested fields are masked too. The value is
+// never modified: masked maps and slices are copies.
+func (r *Redactor) Value(key string, value any) any {
+	if value == nil {
+		return nil
+	}
+	if class, ok := r.ClassOf(key); ok {
+		switch v := value.(type) {
+		case string:
+			return r.Mask(class, v)
+		case *string:
+			if v == nil {
+				return v
+			}
+			masked := r.Mask(class, *v)
+			return &masked
+		}
+		return r.Mask(class, fmt.Sprint(value))
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		return r.Map(v)
+	case map[string]string:
+		out := make(map[string]string, len(v))
+		for k, s := range v {
+			out[k] = r.Value(k, s).(string)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = r.Value(key, item)
+		}
+		return out
+	case string:
+		if r.detect {
+			return r.Text(v)
+		}
+	case error:
+		if r.detect {
+			if msg := v.Error(); r.Text(msg) != msg {
+				return &redactedError{msg: r.Text(msg), err: v}
+			}
+		}
+	}
+	return value
+}
+
+// Map returns a copy of the map with the values masked by Value.
+func (r *Redactor) Map(m map[string]any) map[string]any {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = r.Value(k, v)
+	}
+	return out
+}
+
+// Text masks the emails, CPFs with valid check digits and card numbers with
+// a valid Luhn check digit found in the text, e.g. log messages and error
+// messages. Phones and names are not detected: they cannot be told apart
+// from other numbers and words.
+func (r *Redactor) Text(s string) string {
+	if strings.IndexByte(s, '@') >= 0 {
+		s = emailPattern.ReplaceAllStringFunc(s, func(m string) string { return r.Mask(Email, m) })
+	}
+	if countDigits(s) < 11 {
+		return s
+	}
+	s = cpfPattern.ReplaceAllStringFunc(s, func(m string) string {
+		if !ValidCPF(m) {
+			return m
+		}
+		return r.Mask(CPF, m)
+	})
+	return panPattern.ReplaceAllStringFunc(s, func(m string) string {
+		if !ValidPAN(m) {
+			return m
+		}
+		return r.Mask(PAN, m)
+	})
+}
+
+// countDigits returns the number of ASCII digits in s.
+func countDigits(s string) int {
+	n := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] >= '0' && s[i] <= '9' {
+			n++
+		}
+	}
+	return n
+}
+
+// Struct masks, in place, the tagged fields of the struct, or slice of
+// structs, pointed by v. Tagged fields are string or *string; nested
+// structs, pointers and slices of them are walked.
+func (r *Redactor) Struct(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return ErrNotPointer
+	}
+	return r.walk(rv.Elem())
+}
+
+// walk masks the tagged fields reachable from v.
+func (r *Redactor) walk(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return r.walk(v.Elem())
+
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			if err := r.walk(v.Index(i)); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Struct:
+		t := v.Type()
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			class, tagged := f.Tag.Lookup(TagName)
+			if !tagged {
+				if err := r.walk(v.Field(i)); err != nil {
+					return err
+				}
+				continue
+			}
+			field := v.Field(i)
+			if field.Kind() == reflect.Pointer {
+				if field.IsNil() {
+					continue
+				}
+				field = field.Elem()
+			}
+			if field.Kind() != reflect.String {
+				return fmt.Errorf("masking: field %s.%s: %s tag on %s", t.Name(), f.Name, TagName, f.Type)
+			}
+			field.SetString(r.Mask(Class(class), field.String()))
+		}
+	}
+	return nil
+}
+
+// redactedError is an error whose message was masked; it still unwraps to
+// the original error for errors.Is and errors.As.
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+func (e *redactedError) Unwrap() error { return e.err }
@@ -0,0 +1,101 @@
+package masking
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// ErrTokenNotFound is returned by Detokenize for unknown tokens.
+var ErrTokenNotFound = errors.New("masking: token not found")
+
+// TokenStore stores the values of the tokens, so authorized services can
+// recover them. Implementations must be safe for concurrent use; store the
+// values encrypted (see the crypto package) in persistent stores.
+type TokenStore interface {
+	Put(token, value string) error
+	// Get returns ErrTokenNotFound for unknown tokens.
+	Get(token string) (string, error)
+}
+
+// MemoryTokenStore is a TokenStore in memory, for tests and single process
+// use.
+type MemoryTokenStore struct {
+	mu     sync.RWMutex
+	values map[string]string
+}
+
+// NewMemoryTokenStore creates an empty MemoryTokenStore.
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{values: make(map[string]string)}
+}
+
+// Put implements TokenStore.
+func (s *MemoryTokenStore) Put(token, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[token] = value
+	return nil
+}
+
+// Get implements TokenStore.
+func (s *MemoryTokenStore) Get(token string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	value, ok := s.values[token]
+	if !ok {
+		return "", ErrTokenNotFound
+	}
+	return value, nil
+}
+
+// tokenEncoding encodes the token digests.
+var tokenEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Tokenizer replaces values by tokens, tok_<class>_<digest>, and keeps the
+// values in a TokenStore to be recovered by Detokenize. Tokens are derived
+// from the HMAC of the value, so the same value always gets the same token
+// and the store does not grow with repeated values.
+type Tokenizer struct {
+	key   []byte
+	store TokenStore
+}
+
+// NewTokenizer creates a Tokenizer with the HMAC key and the store.
+func NewTokenizer(key []byte, store TokenStore) *Tokenizer {
+	return &Tokenizer{key: key, store: store}
+}
+
+// Token returns the token of the value, storing the value.
+func (t *Tokenizer) Token(class Class, value string) (string, error) {
+	mac := hmac.New(sha256.New, t.key)
+	mac.Write([]byte(class))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	token := "tok_" + string(class) + "_" + strings.ToLower(tokenEncoding.EncodeToString(mac.Sum(nil)[:20]))
+	if err := t.store.Put(token, value); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// Mask implements Strategy. Values whose token cannot be stored are replaced
+// by Redacted, never logged in clear.
+func (t *Tokenizer) Mask(class Class, value string) string {
+	if value == "" {
+		return ""
+	}
+	token, err := t.Token(class, value)
+	if err != nil {
+		return Redacted
+	}
+	return token
+}
+
+// Detokenize returns the value of the token.
+func (t *Tokenizer) Detokenize(token string) (string, error) {
+	return t.store.Get(token)
+}
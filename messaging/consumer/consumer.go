@@ -0,0 +1,206 @@
+// Package consumer provides broker independent middlewares for message
+// consumers: retries with backoff, inline or through retry topics, dead-letter
+// publishing with the serialized domain error, poison message detection and
+// per-message processing timeouts. Broker adapters convert their deliveries
+// to Message and acknowledge when the Handler returns nil.
+package consumer
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+// Headers set by the middlewares.
+const (
+	HeaderAttempt       = "x-attempt"
+	HeaderOriginalTopic = "x-original-topic"
+	HeaderError         = "x-error"
+	HeaderErrorCode     = "x-error-code"
+	HeaderFailedAt      = "x-failed-at"
+	HeaderRetryAfter    = "x-retry-after"
+)
+
+// Consumer errors. Errors returned by the middlewares wrap them, so errors.Is
+// can be used to tell failures apart.
+var (
+	ErrRetriesExhausted  = errors.New("consumer: retries exhausted")
+	ErrProcessingTimeout = errors.New("consumer: processing timeout")
+	ErrPoisonMessage     = errors.New("consumer: poison message")
+)
+
+// Message is a message received from a broker.
+type Message struct {
+	ID        string
+	Topic     string
+	Key       []byte
+	Value     []byte
+	Headers   map[string]string
+	Timestamp time.Time
+
+	// Attempt is the delivery attempt, starting at 1. Adapters of brokers
+	// counting redeliveries set it; otherwise the x-attempt header is used.
+	Attempt int
+}
+
+// attempt returns the delivery attempt of the message.
+func (m *Message) attempt() int {
+	if m.Attempt > 0 {
+		return m.Attempt
+	}
+	if n, err := strconv.Atoi(m.Headers[HeaderAttempt]); err == nil && n > 0 {
+		return n
+	}
+	return 1
+}
+
+// clone returns a copy of the message with its own headers.
+func (m *Message) clone() *Message {
+	c := *m
+	c.Headers = make(map[string]string, len(m.Headers)+4)
+	for k, v := range m.Headers {
+		c.Headers[k] = v
+	}
+	return &c
+}
+
+// Handler processes a message. A nil error acknowledges it.
+type Handler func(ctx context.Context, msg *Message) error
+
+// Middleware wraps a Handler.
+type Middleware func(Handler) Handler
+
+// Chain applies the middlewares to the handler, the first one outermost.
+func Chain(handler Handler, middlewares ...Middleware) Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// Config configures the middleware stack built by Stack.
+type Config struct {
+	// Timeout bounds the processing of each attempt. Zero disables it.
+	Timeout time.Duration
+
+	// Retry configures the retries.
+	Retry RetryConfig
+
+	// DeadLetter configures the dead-letter topic. Without a publisher
+	// failed messages are returned to the broker.
+	DeadLetter DeadLetterConfig
+
+	// Metrics records every attempt and the dead letters. Nil disables
+	// them.
+	Metrics *Metrics
+}
+
+// Stack returns the dead-letter, retry, metrics and timeout middlewares, in
+// that order, so every attempt has its own timeout and the dead-letter topic
+// receives the messages the retries could not process.
+func Stack(config Config) Middleware {
+	middlewares := make([]Middleware, 0, 4)
+	if config.DeadLetter.Publisher != nil {
+		deadLetter := config.DeadLetter
+		if m := config.Metrics; m != nil {
+			onDeadLetter := deadLetter.OnDeadLetter
+			deadLetter.OnDeadLetter = func(ctx context.Context, msg *Message, err error) {
+				m.DeadLettered(ctx, msg, err)
+				if onDeadLetter != nil {
+					onDeadLetter(ctx, msg, err)
+				}
+			}
+		}
+		middlewares = append(middlewares, DeadLetter(deadLetter))
+	}
+	middlewares = append(middlewares, Retry(config.Retry))
+	if config.Metrics != nil {
+		middlewares = append(middlewares, config.Metrics.Middleware())
+	}
+	if config.Timeout > 0 {
+		middlewares = append(middlewares, Timeout(config.Timeout))
+	}
+
+	return func(next Handler) Handler {
+		return Chain(next, middlewares...)
+	}
+}
+
+// Publisher publishes messages to retry and dead-letter topics.
+type Publisher interface {
+	Publish(ctx context.Context, topic string, msg *Message) error
+}
+
+// PublisherFunc adapts a function to Publisher.
+type PublisherFunc func(ctx context.Context, topic string, msg *Message) error
+
+// Publish implements Publisher.
+func (f PublisherFunc) Publish(ctx context.Context, topic string, msg *Message) error {
+	return f(ctx, topic, msg)
+}
+
+// Permanent marks an error as not retryable; the message goes straight to
+// the dead-letter topic.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// permanentError marks an error as not retryable. It unwraps to both
+// ErrPoisonMessage and the original error, so domain errors are still found
+// with errors.As.
+type permanentError struct {
+	err error
+}
+
+// Error returns the message of the original error.
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns ErrPoisonMessage and the original error.
+func (e *permanentError) Unwrap() []error {
+	return []error{ErrPoisonMessage, e.err}
+}
+
+// IsRetryable reports whether processing may succeed on a new attempt.
+// Permanent errors and validation, bad request, unprocessable entity and
+// not found domain errors are not retryable.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, ErrPoisonMessage) {
+		return false
+	}
+
+	var domainErr interfaces.DomainErrorInterface
+	if !errors.As(err, &domainErr) {
+		return true
+	}
+	switch domainErr.Type() {
+	case interfaces.ValidationError, interfaces.BadRequestError,
+		interfaces.UnprocessableEntityError, interfaces.NotFoundError:
+		return false
+	}
+	return true
+}
+
+// Backoff returns the delay before the given retry, starting at 1.
+type Backoff func(retry int) time.Duration
+
+// ExponentialBackoff doubles the delay on each retry, from min up to max.
+func ExponentialBackoff(min, max time.Duration) Backoff {
+	return func(retry int) time.Duration {
+		delay := min
+		for i := 1; i < retry && delay < max; i++ {
+			delay *= 2
+		}
+		if delay > max {
+			delay = max
+		}
+		return delay
+	}
+}
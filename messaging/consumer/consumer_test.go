@@ -0,0 +1,265 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingPublisher records published messages by topic.
+type recordingPublisher struct {
+	mu       sync.Mutex
+	messages map[string][]*Message
+	err      error
+}
+
+func newRecordingPublisher() *recordingPublisher {
+	return &recordingPublisher{messages: map[string][]*Message{}}
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, topic string, msg *Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	p.messages[topic] = append(p.messages[topic], msg)
+	return nil
+}
+
+func (p *recordingPublisher) published(topic string) []*Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.messages[topic]
+}
+
+func noBackoff(int) time.Duration { return 0 }
+
+func newMessage() *Message {
+	return &Message{ID: "m1", Topic: "orders", Value: []byte(`{}`), Headers: map[string]string{"trace-id": "t1"}}
+}
+
+func TestChain_Order(t *testing.T) {
+	var order []string
+	mw := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, msg *Message) error {
+				order = append(order, name)
+				return next(ctx, msg)
+			}
+		}
+	}
+
+	handler := Chain(func(ctx context.Context, msg *Message) error {
+		order = append(order, "handler")
+		return nil
+	}, mw("a"), mw("b"))
+
+	require.NoError(t, handler(context.Background(), newMessage()))
+	assert.Equal(t, []string{"a", "b", "handler"}, order)
+}
+
+func TestIsRetryable(t *testing.T) {
+	assert.True(t, IsRetryable(errors.New("connection reset")))
+	assert.True(t, IsRetryable(domainerrors.New(interfaces.TimeoutError, "TIMEOUT", "timeout")))
+	assert.False(t, IsRetryable(Permanent(errors.New("bad payload"))))
+	assert.False(t, IsRetryable(domainerrors.New(interfaces.ValidationError, "INVALID", "invalid")))
+	assert.False(t, IsRetryable(nil))
+
+	domainErr := domainerrors.New(interfaces.ConflictError, "CONFLICT", "conflict")
+	var found interfaces.DomainErrorInterface
+	require.ErrorAs(t, Permanent(domainErr), &found)
+	assert.Equal(t, "CONFLICT", found.Code())
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(100*time.Millisecond, time.Second)
+	assert.Equal(t, 100*time.Millisecond, backoff(1))
+	assert.Equal(t, 200*time.Millisecond, backoff(2))
+	assert.Equal(t, 800*time.Millisecond, backoff(4))
+	assert.Equal(t, time.Second, backoff(10))
+}
+
+func TestRetry_Inline(t *testing.T) {
+	var attempts []int
+	handler := Retry(RetryConfig{MaxAttempts: 3, Backoff: noBackoff})(func(ctx context.Context, msg *Message) error {
+		attempts = append(attempts, msg.Attempt)
+		if len(attempts) < 3 {
+			return errors.New("temporary")
+		}
+		return nil
+	})
+
+	require.NoError(t, handler(context.Background(), newMessage()))
+	assert.Equal(t, []int{0, 2, 3}, attempts)
+}
+
+func TestRetry_InlineExhausted(t *testing.T) {
+	var calls int
+	handler := Retry(RetryConfig{MaxAttempts: 2, Backoff: noBackoff})(func(ctx context.Context, msg *Message) error {
+		calls++
+		return errors.New("down")
+	})
+
+	err := handler(context.Background(), newMessage())
+	require.ErrorIs(t, err, ErrRetriesExhausted)
+	assert.Contains(t, err.Error(), "after 2 attempts")
+	assert.Equal(t, 2, calls)
+}
+
+func TestRetry_PoisonNotRetried(t *testing.T) {
+	var calls int
+	handler := Retry(RetryConfig{MaxAttempts: 5, Backoff: noBackoff})(func(ctx context.Context, msg *Message) error {
+		calls++
+		return Permanent(errors.New("cannot decode"))
+	})
+
+	err := handler(context.Background(), newMessage())
+	assert.ErrorIs(t, err, ErrPoisonMessage)
+	assert.Equal(t, 1, calls)
+}
+
+func TestRetry_Topics(t *testing.T) {
+	publisher := newRecordingPublisher()
+	handler := Retry(RetryConfig{
+		MaxAttempts: 3,
+		Backoff:     noBackoff,
+		Topics:      []string{"orders.retry.1", "orders.retry.2"},
+		Publisher:   publisher,
+	})(func(ctx context.Context, msg *Message) error {
+		return errors.New("down")
+	})
+
+	require.NoError(t, handler(context.Background(), newMessage()))
+	retried := publisher.published("orders.retry.1")
+	require.Len(t, retried, 1)
+	assert.Equal(t, "2", retried[0].Headers[HeaderAttempt])
+	assert.Equal(t, "orders", retried[0].Headers[HeaderOriginalTopic])
+	assert.Equal(t, "t1", retried[0].Headers["trace-id"])
+	assert.NotEmpty(t, retried[0].Headers[HeaderRetryAfter])
+
+	retried[0].Topic = "orders.retry.1"
+	require.NoError(t, handler(context.Background(), retried[0]))
+	require.Len(t, publisher.published("orders.retry.2"), 1)
+
+	last := publisher.published("orders.retry.2")[0]
+	assert.ErrorIs(t, handler(context.Background(), last), ErrRetriesExhausted)
+}
+
+func TestDeadLetter(t *testing.T) {
+	publisher := newRecordingPublisher()
+	var notified atomic.Int32
+	stack := Stack(Config{
+		Retry: RetryConfig{MaxAttempts: 2, Backoff: noBackoff},
+		DeadLetter: DeadLetterConfig{
+			Topic:     "orders.dlq",
+			Publisher: publisher,
+			OnDeadLetter: func(ctx context.Context, msg *Message, err error) {
+				notified.Add(1)
+			},
+		},
+	})
+
+	handler := stack(func(ctx context.Context, msg *Message) error {
+		return domainerrors.NewWithMetadata(interfaces.ExternalServiceError, "PAYMENT_GATEWAY_DOWN", "gateway down",
+			map[string]interface{}{"gateway": "acme"})
+	})
+
+	require.NoError(t, handler(context.Background(), newMessage()), "dead-lettered messages are acknowledged")
+	letters := publisher.published("orders.dlq")
+	require.Len(t, letters, 1)
+	assert.Equal(t, int32(1), notified.Load())
+
+	letter := letters[0]
+	assert.Equal(t, "t1", letter.Headers["trace-id"])
+	assert.Equal(t, "orders", letter.Headers[HeaderOriginalTopic])
+	assert.Equal(t, "PAYMENT_GATEWAY_DOWN", letter.Headers[HeaderErrorCode])
+	assert.NotEmpty(t, letter.Headers[HeaderFailedAt])
+	assert.Equal(t, []byte(`{}`), letter.Value)
+
+	var serialized map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(letter.Headers[HeaderError]), &serialized))
+	assert.Equal(t, "PAYMENT_GATEWAY_DOWN", serialized["code"])
+	assert.Equal(t, "acme", serialized["metadata"].(map[string]interface{})["gateway"])
+}
+
+func TestDeadLetter_BrokerRedelivery(t *testing.T) {
+	publisher := newRecordingPublisher()
+	handler := DeadLetter(DeadLetterConfig{Topic: "dlq", Publisher: publisher, MaxAttempts: 3})(
+		func(ctx context.Context, msg *Message) error {
+			return errors.New("down")
+		})
+
+	msg := newMessage()
+	msg.Attempt = 2
+	assert.Error(t, handler(context.Background(), msg), "failures before the last delivery are redelivered")
+	assert.Empty(t, publisher.published("dlq"))
+
+	msg.Attempt = 3
+	require.NoError(t, handler(context.Background(), msg))
+	require.Len(t, publisher.published("dlq"), 1)
+	assert.Equal(t, "MESSAGE_PROCESSING_FAILED", publisher.published("dlq")[0].Headers[HeaderErrorCode])
+}
+
+func TestDeadLetter_PublishFailure(t *testing.T) {
+	publisher := newRecordingPublisher()
+	publisher.err = errors.New("broker unavailable")
+	handler := DeadLetter(DeadLetterConfig{Topic: "dlq", Publisher: publisher})(
+		func(ctx context.Context, msg *Message) error {
+			return Permanent(errors.New("bad payload"))
+		})
+
+	err := handler(context.Background(), newMessage())
+	require.Error(t, err, "messages must not be acknowledged when the dead letter is lost")
+	assert.Contains(t, err.Error(), "broker unavailable")
+}
+
+func TestTimeout(t *testing.T) {
+	handler := Timeout(20 * time.Millisecond)(func(ctx context.Context, msg *Message) error {
+		time.Sleep(200 * time.Millisecond)
+		return nil
+	})
+
+	err := handler(context.Background(), newMessage())
+	require.ErrorIs(t, err, ErrProcessingTimeout)
+	assert.True(t, domainerrors.IsType(err, interfaces.TimeoutError))
+	assert.True(t, IsRetryable(err))
+
+	fast := Timeout(time.Second)(func(ctx context.Context, msg *Message) error {
+		_, ok := ctx.Deadline()
+		assert.True(t, ok)
+		return nil
+	})
+	assert.NoError(t, fast(context.Background(), newMessage()))
+}
+
+func TestTimeout_ParentCancelled(t *testing.T) {
+	handler := Timeout(time.Second)(func(ctx context.Context, msg *Message) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, handler(ctx, newMessage()), context.Canceled)
+}
+
+func TestTimeout_Panic(t *testing.T) {
+	handler := Timeout(time.Second)(func(ctx context.Context, msg *Message) error {
+		panic("boom")
+	})
+
+	err := handler(context.Background(), newMessage())
+	var domainErr interfaces.DomainErrorInterface
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, "MESSAGE_HANDLER_PANIC", domainErr.Code())
+}
@@ -0,0 +1,95 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+// DeadLetterConfig configures the DeadLetter middleware.
+type DeadLetterConfig struct {
+	// Topic receives the dead letters.
+	Topic string
+
+	// Publisher publishes the dead letters.
+	Publisher Publisher
+
+	// MaxAttempts sends the message to the dead-letter topic once the broker
+	// delivered it that many times, for brokers that redeliver failed
+	// messages. Zero relies on the Retry middleware only.
+	MaxAttempts int
+
+	// OnDeadLetter is called after a message is dead-lettered.
+	OnDeadLetter func(ctx context.Context, msg *Message, err error)
+}
+
+// DeadLetter publishes messages that cannot be processed to the dead-letter
+// topic and acknowledges them: poison messages, messages whose retries are
+// exhausted and messages delivered MaxAttempts times. The dead letter keeps
+// the original headers and carries the error serialized as a domain error
+// in the x-error header. Other failures are returned for redelivery.
+func DeadLetter(config DeadLetterConfig) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *Message) error {
+			err := next(ctx, msg)
+			if err == nil {
+				return nil
+			}
+
+			deadLetter := !IsRetryable(err) || errors.Is(err, ErrRetriesExhausted) ||
+				(config.MaxAttempts > 0 && msg.attempt() >= config.MaxAttempts)
+			if !deadLetter {
+				return err
+			}
+
+			letter := msg.clone()
+			if _, ok := letter.Headers[HeaderOriginalTopic]; !ok {
+				letter.Headers[HeaderOriginalTopic] = msg.Topic
+			}
+			letter.Headers[HeaderAttempt] = strconv.Itoa(msg.attempt())
+			letter.Headers[HeaderError] = SerializeError(err)
+			letter.Headers[HeaderErrorCode] = errorCode(err)
+			letter.Headers[HeaderFailedAt] = time.Now().UTC().Format(time.RFC3339Nano)
+
+			if pubErr := config.Publisher.Publish(context.WithoutCancel(ctx), config.Topic, letter); pubErr != nil {
+				return fmt.Errorf("failed to publish to dead-letter topic %s: %w (processing error: %v)", config.Topic, pubErr, err)
+			}
+			if config.OnDeadLetter != nil {
+				config.OnDeadLetter(ctx, msg, err)
+			}
+			return nil
+		}
+	}
+}
+
+// SerializeError returns the error as domain error JSON. Errors that are not
+// domain errors are wrapped in a ServerError first.
+func SerializeError(err error) string {
+	var domainErr interfaces.DomainErrorInterface
+	if !errors.As(err, &domainErr) {
+		domainErr = domainerrors.Wrap(err, interfaces.ServerError, "MESSAGE_PROCESSING_FAILED", err.Error())
+	}
+
+	data, jsonErr := domainErr.ToJSON()
+	if jsonErr != nil {
+		return err.Error()
+	}
+	return string(data)
+}
+
+// errorCode returns the code of the domain error in the chain.
+func errorCode(err error) string {
+	var domainErr interfaces.DomainErrorInterface
+	if errors.As(err, &domainErr) {
+		return domainErr.Code()
+	}
+	if errors.Is(err, ErrProcessingTimeout) {
+		return "PROCESSING_TIMEOUT"
+	}
+	return "MESSAGE_PROCESSING_FAILED"
+}
@@ -0,0 +1,194 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/observability/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// meterName identifies the instruments created by Metrics.
+const meterName = "github.com/fsvxavier/nexs-lib/messaging"
+
+// Metric names.
+const (
+	MetricMessages    = "messaging.consumer.messages"
+	MetricDuration    = "messaging.process.duration"
+	MetricRetries     = "messaging.consumer.retries"
+	MetricDeadLetters = "messaging.consumer.dead_letters"
+	MetricLag         = "messaging.consumer.lag"
+)
+
+// Attributes of the metrics.
+const (
+	AttrTopic     = "messaging.destination.name"
+	AttrPartition = "messaging.destination.partition.id"
+	AttrGroup     = "messaging.consumer.group.name"
+	AttrOutcome   = "outcome"
+	AttrErrorType = "error.type"
+)
+
+// Outcomes of processed messages.
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// PartitionLag is the lag of the consumer on a partition: the number of
+// messages between its committed offset and the end of the partition.
+type PartitionLag struct {
+	Topic     string
+	Partition int32
+	Lag       int64
+}
+
+// LagSource returns the lag of the consumer on each assigned partition.
+// Broker adapters implement it with the offsets of the broker; it is called
+// on every collection of the metrics.
+type LagSource func(ctx context.Context) ([]PartitionLag, error)
+
+// MetricsOption configures Metrics.
+type MetricsOption func(*Metrics)
+
+// WithConsumerGroup adds the consumer group to the attributes of the
+// metrics.
+func WithConsumerGroup(group string) MetricsOption {
+	return func(m *Metrics) {
+		m.group = group
+	}
+}
+
+// WithLagSource reports the lag returned by source as the
+// messaging.consumer.lag gauge, the metric autoscalers usually key off.
+func WithLagSource(source LagSource) MetricsOption {
+	return func(m *Metrics) {
+		m.lag = source
+	}
+}
+
+// Metrics records consumer metrics through OpenTelemetry (see
+// observability/metrics): processed messages by topic and outcome,
+// processing latency, retries, dead letters and, with a LagSource, the lag
+// per topic and partition. Stack wires it when set in Config.
+type Metrics struct {
+	group string
+	lag   LagSource
+
+	messages, retries, deadLetters metric.Int64Counter
+	duration                       *metrics.LatencyHistogram
+	registration                   metric.Registration
+}
+
+// NewMetrics creates the instruments with the meter provider.
+func NewMetrics(provider metric.MeterProvider, opts ...MetricsOption) (*Metrics, error) {
+	m := &Metrics{}
+	for _, opt := range opts {
+		opt(m)
+	}
+	meter := provider.Meter(meterName)
+
+	var err error
+	for _, c := range []struct {
+		target      *metric.Int64Counter
+		name        string
+		description string
+	}{
+		{&m.messages, MetricMessages, "Processing attempts of messages by outcome"},
+		{&m.retries, MetricRetries, "Processing attempts that retried a failed message"},
+		{&m.deadLetters, MetricDeadLetters, "Messages published to the dead-letter topic"},
+	} {
+		if *c.target, err = meter.Int64Counter(c.name,
+			metric.WithDescription(c.description), metric.WithUnit("{message}")); err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", c.name, err)
+		}
+	}
+	if m.duration, err = metrics.NewLatencyHistogram(meter, MetricDuration,
+		metrics.WithDescription("Processing duration of each attempt")); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", MetricDuration, err)
+	}
+
+	if m.lag != nil {
+		gauge, err := meter.Int64ObservableGauge(MetricLag,
+			metric.WithDescription("Messages between the committed offset and the end of the partition"),
+			metric.WithUnit("{message}"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", MetricLag, err)
+		}
+		if m.registration, err = meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+			lags, err := m.lag(ctx)
+			if err != nil {
+				return err
+			}
+			for _, l := range lags {
+				o.ObserveInt64(gauge, l.Lag, metric.WithAttributes(m.attrs(l.Topic,
+					attribute.Int(AttrPartition, int(l.Partition)))...))
+			}
+			return nil
+		}, gauge); err != nil {
+			return nil, fmt.Errorf("failed to register %s: %w", MetricLag, err)
+		}
+	}
+	return m, nil
+}
+
+// attrs returns the attributes of a topic.
+func (m *Metrics) attrs(topic string, extra ...attribute.KeyValue) []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, 2+len(extra))
+	attrs = append(attrs, attribute.String(AttrTopic, topic))
+	if m.group != "" {
+		attrs = append(attrs, attribute.String(AttrGroup, m.group))
+	}
+	return append(attrs, extra...)
+}
+
+// Middleware records every processing attempt: its duration, its outcome
+// and, from the second attempt on, a retry. It belongs inside the Retry
+// middleware, so inline retries are counted.
+func (m *Metrics) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *Message) error {
+			start := time.Now()
+			err := next(ctx, msg)
+
+			attrs := m.attrs(originalTopic(msg))
+			m.duration.Record(ctx, time.Since(start), attrs...)
+			if msg.attempt() > 1 {
+				m.retries.Add(ctx, 1, metric.WithAttributes(attrs...))
+			}
+			if err != nil {
+				attrs = append(attrs, attribute.String(AttrOutcome, OutcomeFailure),
+					attribute.String(AttrErrorType, errorCode(err)))
+			} else {
+				attrs = append(attrs, attribute.String(AttrOutcome, OutcomeSuccess))
+			}
+			m.messages.Add(ctx, 1, metric.WithAttributes(attrs...))
+			return err
+		}
+	}
+}
+
+// DeadLettered counts a dead-lettered message; its signature matches
+// DeadLetterConfig.OnDeadLetter.
+func (m *Metrics) DeadLettered(ctx context.Context, msg *Message, _ error) {
+	m.deadLetters.Add(ctx, 1, metric.WithAttributes(m.attrs(originalTopic(msg))...))
+}
+
+// originalTopic returns the topic a message was first published to, so
+// messages consumed from retry topics are reported with it.
+func originalTopic(msg *Message) string {
+	if original, ok := msg.Headers[HeaderOriginalTopic]; ok {
+		return original
+	}
+	return msg.Topic
+}
+
+// Close stops reporting the lag.
+func (m *Metrics) Close() error {
+	if m.registration == nil {
+		return nil
+	}
+	return m.registration.Unregister()
+}
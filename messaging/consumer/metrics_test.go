@@ -0,0 +1,119 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fsvxavier/nexs-lib/observability/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// collect returns the metrics collected by the provider by name.
+func collect(t *testing.T, p *metrics.Provider) map[string]metricdata.Aggregation {
+	t.Helper()
+	rm, err := p.Collect(context.Background())
+	require.NoError(t, err)
+	found := map[string]metricdata.Aggregation{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			found[m.Name] = m.Data
+		}
+	}
+	return found
+}
+
+// sumBy returns the values of a counter by the value of the attribute.
+func sumBy(t *testing.T, data metricdata.Aggregation, key string) map[string]int64 {
+	t.Helper()
+	sum, ok := data.(metricdata.Sum[int64])
+	require.True(t, ok)
+	values := map[string]int64{}
+	for _, dp := range sum.DataPoints {
+		v, _ := dp.Attributes.Value(attribute.Key(key))
+		values[v.AsString()] += dp.Value
+	}
+	return values
+}
+
+func TestMetrics_Stack(t *testing.T) {
+	p := metrics.NewProvider()
+	defer p.Shutdown(context.Background())
+	m, err := NewMetrics(p.MeterProvider(), WithConsumerGroup("billing"))
+	require.NoError(t, err)
+
+	publisher := newRecordingPublisher()
+	var deadLettered int
+	handler := Stack(Config{
+		Retry: RetryConfig{MaxAttempts: 3, Backoff: noBackoff},
+		DeadLetter: DeadLetterConfig{
+			Topic:        "orders.dlq",
+			Publisher:    publisher,
+			OnDeadLetter: func(context.Context, *Message, error) { deadLettered++ },
+		},
+		Metrics: m,
+	})(func(ctx context.Context, msg *Message) error {
+		if string(msg.Value) == "fail" {
+			return errors.New("downstream unavailable")
+		}
+		return nil
+	})
+
+	require.NoError(t, handler(context.Background(), &Message{Topic: "orders", Value: []byte("ok")}))
+	require.NoError(t, handler(context.Background(), &Message{Topic: "orders", Value: []byte("fail")}))
+	assert.Equal(t, 1, deadLettered)
+
+	found := collect(t, p)
+	assert.Equal(t, map[string]int64{OutcomeSuccess: 1, OutcomeFailure: 3}, sumBy(t, found[MetricMessages], AttrOutcome))
+	assert.Equal(t, map[string]int64{"orders": 2}, sumBy(t, found[MetricRetries], AttrTopic))
+	assert.Equal(t, map[string]int64{"billing": 1}, sumBy(t, found[MetricDeadLetters], AttrGroup))
+
+	hist, ok := found[MetricDuration].(metricdata.Histogram[float64])
+	require.True(t, ok)
+	require.Len(t, hist.DataPoints, 1)
+	assert.Equal(t, uint64(4), hist.DataPoints[0].Count)
+}
+
+func TestMetrics_RetryTopicsUseOriginalTopic(t *testing.T) {
+	p := metrics.NewProvider()
+	defer p.Shutdown(context.Background())
+	m, err := NewMetrics(p.MeterProvider())
+	require.NoError(t, err)
+
+	handler := m.Middleware()(func(ctx context.Context, msg *Message) error { return nil })
+	require.NoError(t, handler(context.Background(), &Message{
+		Topic:   "orders.retry.1",
+		Headers: map[string]string{HeaderOriginalTopic: "orders", HeaderAttempt: "2"},
+	}))
+
+	found := collect(t, p)
+	assert.Equal(t, map[string]int64{"orders": 1}, sumBy(t, found[MetricMessages], AttrTopic))
+	assert.Equal(t, map[string]int64{"orders": 1}, sumBy(t, found[MetricRetries], AttrTopic))
+}
+
+func TestMetrics_Lag(t *testing.T) {
+	p := metrics.NewProvider()
+	defer p.Shutdown(context.Background())
+	m, err := NewMetrics(p.MeterProvider(), WithLagSource(func(ctx context.Context) ([]PartitionLag, error) {
+		return []PartitionLag{{Topic: "orders", Partition: 0, Lag: 12}, {Topic: "orders", Partition: 1, Lag: 3}}, nil
+	}))
+	require.NoError(t, err)
+
+	gauge, ok := collect(t, p)[MetricLag].(metricdata.Gauge[int64])
+	require.True(t, ok)
+	lags := map[int64]int64{}
+	for _, dp := range gauge.DataPoints {
+		partition, _ := dp.Attributes.Value(AttrPartition)
+		topic, _ := dp.Attributes.Value(AttrTopic)
+		assert.Equal(t, "orders", topic.AsString())
+		lags[partition.AsInt64()] = dp.Value
+	}
+	assert.Equal(t, map[int64]int64{0: 12, 1: 3}, lags)
+
+	require.NoError(t, m.Close())
+	_, ok = collect(t, p)[MetricLag]
+	assert.False(t, ok)
+}
@@ -0,0 +1,143 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// RetryConfig configures the Retry middleware.
+type RetryConfig struct {
+	// MaxAttempts is the total number of attempts, the first one included.
+	MaxAttempts int
+
+	// Backoff returns the delay before each retry.
+	Backoff Backoff
+
+	// Topics enables retry topics: failed messages are published to
+	// Topics[retry-1], the last topic being reused, and acknowledged. The
+	// consumers of those topics run the same stack. Without topics, retries
+	// happen inline, holding the message.
+	Topics []string
+
+	// Publisher publishes to the retry topics.
+	Publisher Publisher
+}
+
+// DefaultRetryConfig returns a default retry configuration.
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts: 3,
+		Backoff:     ExponentialBackoff(time.Second, 30*time.Second),
+	}
+}
+
+// Retry retries failed messages. Errors that are not retryable are returned
+// at once. When the attempts are exhausted the error wraps
+// ErrRetriesExhausted.
+func Retry(config RetryConfig) Middleware {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultRetryConfig().MaxAttempts
+	}
+	if config.Backoff == nil {
+		config.Backoff = DefaultRetryConfig().Backoff
+	}
+
+	return func(next Handler) Handler {
+		if len(config.Topics) > 0 {
+			return retryTopics(config, next)
+		}
+		return retryInline(config, next)
+	}
+}
+
+// retryInline retries in the consumer, waiting for the backoff between
+// attempts.
+func retryInline(config RetryConfig, next Handler) Handler {
+	return func(ctx context.Context, msg *Message) error {
+		first := msg.attempt()
+
+		var err error
+		for attempt := first; ; attempt++ {
+			current := msg
+			if attempt != first {
+				current = msg.clone()
+				current.Attempt = attempt
+				current.Headers[HeaderAttempt] = strconv.Itoa(attempt)
+			}
+
+			if err = next(ctx, current); err == nil || !IsRetryable(err) {
+				return err
+			}
+			if attempt >= config.MaxAttempts {
+				return exhausted(err, attempt)
+			}
+
+			timer := time.NewTimer(config.Backoff(attempt))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// retryTopics publishes failed messages to the retry topics. The backoff is
+// carried in the HeaderRetryAfter header for consumers of delayed topics.
+func retryTopics(config RetryConfig, next Handler) Handler {
+	return func(ctx context.Context, msg *Message) error {
+		err := next(ctx, msg)
+		if err == nil || !IsRetryable(err) {
+			return err
+		}
+
+		attempt := msg.attempt()
+		if attempt >= config.MaxAttempts || config.Publisher == nil {
+			return exhausted(err, attempt)
+		}
+
+		topic := config.Topics[len(config.Topics)-1]
+		if attempt <= len(config.Topics) {
+			topic = config.Topics[attempt-1]
+		}
+
+		retry := msg.clone()
+		retry.Attempt = attempt + 1
+		retry.Headers[HeaderAttempt] = strconv.Itoa(attempt + 1)
+		retry.Headers[HeaderRetryAfter] = time.Now().Add(config.Backoff(attempt)).UTC().Format(time.RFC3339Nano)
+		if _, ok := retry.Headers[HeaderOriginalTopic]; !ok {
+			retry.Headers[HeaderOriginalTopic] = msg.Topic
+		}
+		retry.Headers[HeaderError] = err.Error()
+
+		if pubErr := config.Publisher.Publish(ctx, topic, retry); pubErr != nil {
+			return fmt.Errorf("failed to publish to retry topic %s: %w", topic, pubErr)
+		}
+		return nil
+	}
+}
+
+// exhausted wraps the last error once no attempts are left.
+func exhausted(err error, attempts int) error {
+	return &exhaustedError{err: err, attempts: attempts}
+}
+
+// exhaustedError unwraps to ErrRetriesExhausted and the last error, so the
+// domain error of the last attempt is still found with errors.As.
+type exhaustedError struct {
+	err      error
+	attempts int
+}
+
+// Error describes the last error.
+func (e *exhaustedError) Error() string {
+	return fmt.Sprintf("message failed after %d attempts: %v", e.attempts, e.err)
+}
+
+// Unwrap returns ErrRetriesExhausted and the last error.
+func (e *exhaustedError) Unwrap() []error {
+	return []error{ErrRetriesExhausted, e.err}
+}
@@ -0,0 +1,51 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+// Timeout bounds the processing time of each message. The handler receives
+// a context with the deadline; when it does not return in time the message
+// fails with a Timeout domain error wrapping ErrProcessingTimeout, which is
+// retryable, while the handler goroutine is left to observe the
+// cancellation. Panics of the handler are returned as ServerError domain
+// errors.
+func Timeout(timeout time.Duration) Middleware {
+	return func(next Handler) Handler {
+		return func(parent context.Context, msg *Message) error {
+			ctx, cancel := context.WithTimeout(parent, timeout)
+			defer cancel()
+
+			done := make(chan error, 1)
+			go func() {
+				defer func() {
+					if p := recover(); p != nil {
+						done <- domainerrors.New(interfaces.ServerError, "MESSAGE_HANDLER_PANIC",
+							fmt.Sprintf("message handler panic: %v", p))
+					}
+				}()
+				done <- next(ctx, msg)
+			}()
+
+			select {
+			case err := <-done:
+				return err
+			case <-ctx.Done():
+				if err := parent.Err(); err != nil {
+					return err
+				}
+				return domainerrors.NewWithMetadata(interfaces.TimeoutError, "PROCESSING_TIMEOUT",
+					fmt.Sprintf("message processing exceeded %v", timeout), map[string]interface{}{
+						"message_id": msg.ID,
+						"topic":      msg.Topic,
+						"timeout":    timeout.String(),
+					}).Wrap(ErrProcessingTimeout)
+			}
+		}
+	}
+}
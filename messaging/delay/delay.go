@@ -0,0 +1,125 @@
+// Package delay publishes messages delivered at a later time. Brokers with
+// native delays, such as RabbitMQ with the delayed message exchange plugin,
+// use Native, which publishes the message right away with its delay in a
+// header. Brokers without them, such as Kafka, use Store, which keeps the
+// messages in a PostgreSQL table until they are due and publishes them from
+// a scheduler job. Both implement Publisher, so producers do not depend on
+// the broker.
+package delay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+	"github.com/fsvxavier/nexs-lib/messaging/consumer"
+)
+
+// HeaderDelay is the header carrying the delay in milliseconds, as read by
+// the RabbitMQ delayed message exchange.
+const HeaderDelay = "x-delay"
+
+// MaxNativeDelay is the longest delay supported by the RabbitMQ delayed
+// message exchange.
+const MaxNativeDelay = (1<<32 - 1) * time.Millisecond
+
+// Delay errors. Errors returned by the package wrap them, so errors.Is can be
+// used to tell failures apart.
+var (
+	ErrMissingTopic  = errors.New("delay: message has no topic")
+	ErrDelayTooLong  = errors.New("delay: delay exceeds the broker maximum")
+	ErrInvalidConfig = errors.New("delay: invalid configuration")
+)
+
+// Publisher publishes messages delivered at a later time.
+type Publisher interface {
+	// PublishAt publishes the message to its topic, to be delivered at the
+	// time. Messages due in the past are delivered as soon as possible.
+	PublishAt(ctx context.Context, msg *consumer.Message, at time.Time) error
+}
+
+// PublishAfter publishes the message to be delivered after the delay.
+func PublishAfter(ctx context.Context, p Publisher, msg *consumer.Message, delay time.Duration) error {
+	return p.PublishAt(ctx, msg, time.Now().Add(delay))
+}
+
+// NativeOption configures a Native publisher.
+type NativeOption func(*Native)
+
+// WithHeader sets the header carrying the delay. Defaults to HeaderDelay.
+func WithHeader(header string) NativeOption {
+	return func(n *Native) {
+		if header != "" {
+			n.header = header
+		}
+	}
+}
+
+// WithMaxDelay sets the longest delay supported by the broker. Defaults to
+// MaxNativeDelay.
+func WithMaxDelay(max time.Duration) NativeOption {
+	return func(n *Native) {
+		if max > 0 {
+			n.maxDelay = max
+		}
+	}
+}
+
+// WithClock sets the clock computing delays. Defaults to the real clock.
+func WithClock(c clock.Clock) NativeOption {
+	return func(n *Native) {
+		n.clock = c
+	}
+}
+
+// Native delays messages with the native support of the broker: messages are
+// published right away with their delay in milliseconds in a header, and the
+// broker holds them until they are due. With RabbitMQ the topic must be an
+// exchange of type x-delayed-message, and the adapter must send the header
+// as an integer.
+type Native struct {
+	publisher consumer.Publisher
+	header    string
+	maxDelay  time.Duration
+	clock     clock.Clock
+}
+
+// NewNative creates a Native publisher publishing through publisher.
+func NewNative(publisher consumer.Publisher, opts ...NativeOption) *Native {
+	n := &Native{publisher: publisher, header: HeaderDelay, maxDelay: MaxNativeDelay}
+	for _, opt := range opts {
+		opt(n)
+	}
+	n.clock = clock.OrReal(n.clock)
+	return n
+}
+
+// PublishAt implements Publisher. The message is not modified; a copy with
+// the delay header is published.
+func (n *Native) PublishAt(ctx context.Context, msg *consumer.Message, at time.Time) error {
+	if msg.Topic == "" {
+		return ErrMissingTopic
+	}
+	delay := at.Sub(n.clock.Now())
+	if delay < 0 {
+		delay = 0
+	}
+	if delay > n.maxDelay {
+		return fmt.Errorf("%w: %s is longer than %s", ErrDelayTooLong, delay, n.maxDelay)
+	}
+
+	delayed := *msg
+	delayed.Headers = make(map[string]string, len(msg.Headers)+1)
+	for k, v := range msg.Headers {
+		delayed.Headers[k] = v
+	}
+	delayed.Headers[n.header] = strconv.FormatInt(delay.Milliseconds(), 10)
+
+	if err := n.publisher.Publish(ctx, msg.Topic, &delayed); err != nil {
+		return fmt.Errorf("delay: publishing to %s: %w", msg.Topic, err)
+	}
+	return nil
+}
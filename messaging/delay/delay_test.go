@@ -0,0 +1,418 @@
+package delay
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+	pginterfaces "github.com/fsvxavier/nexs-lib/db/postgres/interfaces"
+	"github.com/fsvxavier/nexs-lib/messaging/consumer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder is a publisher recording the published messages.
+type recorder struct {
+	mu       sync.Mutex
+	messages []*consumer.Message
+	failOn   string
+}
+
+func (r *recorder) Publish(ctx context.Context, topic string, msg *consumer.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if msg.ID == r.failOn {
+		return errors.New("broker unavailable")
+	}
+	r.messages = append(r.messages, msg)
+	return nil
+}
+
+func (r *recorder) ids() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := make([]string, len(r.messages))
+	for i, msg := range r.messages {
+		ids[i] = msg.ID
+	}
+	return ids
+}
+
+func TestNative_PublishAt(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	pub := &recorder{}
+	native := NewNative(pub, WithClock(clock.NewFake(now)))
+
+	msg := &consumer.Message{ID: "m-1", Topic: "orders.delayed", Headers: map[string]string{"a": "b"}}
+	require.NoError(t, native.PublishAt(context.Background(), msg, now.Add(90*time.Second)))
+	require.NoError(t, native.PublishAt(context.Background(), msg, now.Add(-time.Minute)))
+
+	require.Len(t, pub.messages, 2)
+	assert.Equal(t, "90000", pub.messages[0].Headers[HeaderDelay])
+	assert.Equal(t, "b", pub.messages[0].Headers["a"])
+	assert.Equal(t, "0", pub.messages[1].Headers[HeaderDelay])
+	assert.NotContains(t, msg.Headers, HeaderDelay)
+}
+
+func TestNative_Errors(t *testing.T) {
+	native := NewNative(&recorder{}, WithMaxDelay(time.Hour), WithHeader("x-delay-ms"))
+
+	err := native.PublishAt(context.Background(), &consumer.Message{ID: "m-1"}, time.Now())
+	assert.ErrorIs(t, err, ErrMissingTopic)
+
+	err = PublishAfter(context.Background(), native, &consumer.Message{ID: "m-1", Topic: "t"}, 2*time.Hour)
+	assert.ErrorIs(t, err, ErrDelayTooLong)
+}
+
+// fakeRow is a message of the fake table.
+type fakeRow struct {
+	id        int64
+	topic     string
+	messageID string
+	key       []byte
+	value     []byte
+	headers   string
+	timestamp time.Time
+	deliverAt time.Time
+	status    string
+	attempts  int
+	lastError string
+}
+
+// fakeDB emulates the statements of the store on an in-memory table. The
+// writes of a transaction are applied on commit.
+type fakeDB struct {
+	mu         sync.Mutex
+	rows       map[int64]*fakeRow
+	nextID     int64
+	released   int
+	rolledBack int
+}
+
+func newFakeDB() *fakeDB {
+	return &fakeDB{rows: map[int64]*fakeRow{}}
+}
+
+func (db *fakeDB) Acquire(ctx context.Context) (pginterfaces.IConn, error) {
+	return &fakeConn{db: db}, nil
+}
+
+type fakeConn struct {
+	pginterfaces.IConn
+	db *fakeDB
+}
+
+func (c *fakeConn) Release() {
+	c.db.mu.Lock()
+	c.db.released++
+	c.db.mu.Unlock()
+}
+
+func (c *fakeConn) Begin(ctx context.Context) (pginterfaces.ITransaction, error) {
+	return &fakeTx{db: c.db}, nil
+}
+
+func (c *fakeConn) Exec(ctx context.Context, query string, args ...interface{}) (pginterfaces.ICommandTag, error) {
+	db := c.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	switch {
+	case strings.HasPrefix(query, "CREATE TABLE"):
+		return fakeTag{}, nil
+	case strings.HasPrefix(query, "INSERT INTO"):
+		timestamp := time.Now()
+		if t := args[5].(*time.Time); t != nil {
+			timestamp = *t
+		}
+		db.nextID++
+		db.rows[db.nextID] = &fakeRow{
+			id: db.nextID, topic: args[0].(string), messageID: args[1].(string), key: args[2].([]byte),
+			value: args[3].([]byte), headers: args[4].(string), timestamp: timestamp, deliverAt: args[6].(time.Time),
+			status: StatusPending,
+		}
+		return fakeTag{rows: 1}, nil
+	case strings.HasPrefix(query, "UPDATE") && strings.Contains(query, "WHERE status = 'failed'"):
+		var n int64
+		for _, r := range db.rows {
+			if r.status == StatusFailed {
+				r.status, r.attempts, r.deliverAt = StatusPending, 0, time.Now()
+				n++
+			}
+		}
+		return fakeTag{rows: n}, nil
+	}
+	return nil, errors.New("unexpected query")
+}
+
+func (c *fakeConn) QueryRow(ctx context.Context, query string, args ...interface{}) pginterfaces.IRow {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	var count int64
+	for _, r := range c.db.rows {
+		if r.status == args[0].(string) {
+			count++
+		}
+	}
+	return fakeScanner{values: []any{count}}
+}
+
+// fakeTx stages the writes until commit.
+type fakeTx struct {
+	pginterfaces.ITransaction
+	db     *fakeDB
+	writes []func()
+}
+
+func (t *fakeTx) Query(ctx context.Context, query string, args ...interface{}) (pginterfaces.IRows, error) {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+	if !strings.Contains(query, "FOR UPDATE SKIP LOCKED") {
+		return nil, errors.New("unexpected query")
+	}
+
+	var due []*fakeRow
+	for _, r := range t.db.rows {
+		if r.status == StatusPending && !r.deliverAt.After(time.Now()) {
+			due = append(due, r)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].deliverAt.Equal(due[j].deliverAt) {
+			return due[i].deliverAt.Before(due[j].deliverAt)
+		}
+		return due[i].id < due[j].id
+	})
+	if limit := args[0].(int); len(due) > limit {
+		due = due[:limit]
+	}
+	rows := &fakeRows{}
+	for _, r := range due {
+		rows.rows = append(rows.rows, []any{r.id, r.topic, r.messageID, r.key, r.value, r.headers, r.timestamp, r.attempts})
+	}
+	return rows, nil
+}
+
+func (t *fakeTx) Exec(ctx context.Context, query string, args ...interface{}) (pginterfaces.ICommandTag, error) {
+	db := t.db
+	switch {
+	case strings.HasPrefix(query, "DELETE FROM"):
+		ids := args[0].([]int64)
+		t.writes = append(t.writes, func() {
+			for _, id := range ids {
+				delete(db.rows, id)
+			}
+		})
+		return fakeTag{rows: int64(len(ids))}, nil
+	case strings.HasPrefix(query, "UPDATE") && strings.Contains(query, "status = 'failed'"):
+		id, attempts, cause := args[0].(int64), args[1].(int), args[2].(string)
+		t.writes = append(t.writes, func() {
+			r := db.rows[id]
+			r.status, r.attempts, r.lastError = StatusFailed, attempts, cause
+		})
+		return fakeTag{rows: 1}, nil
+	case strings.HasPrefix(query, "UPDATE"):
+		id, attempts, cause, delay := args[0].(int64), args[1].(int), args[2].(string), args[3].(int64)
+		t.writes = append(t.writes, func() {
+			r := db.rows[id]
+			r.attempts, r.lastError = attempts, cause
+			r.deliverAt = time.Now().Add(time.Duration(delay) * time.Millisecond)
+		})
+		return fakeTag{rows: 1}, nil
+	}
+	return nil, errors.New("unexpected query")
+}
+
+func (t *fakeTx) Commit(ctx context.Context) error {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+	for _, write := range t.writes {
+		write()
+	}
+	return nil
+}
+
+func (t *fakeTx) Rollback(ctx context.Context) error {
+	t.db.mu.Lock()
+	t.db.rolledBack++
+	t.db.mu.Unlock()
+	return nil
+}
+
+type fakeTag struct {
+	pginterfaces.ICommandTag
+	rows int64
+}
+
+func (t fakeTag) RowsAffected() int64 { return t.rows }
+
+type fakeScanner struct {
+	values []any
+}
+
+func (s fakeScanner) Scan(dest ...any) error {
+	for i, d := range dest {
+		switch d := d.(type) {
+		case *int64:
+			*d = s.values[i].(int64)
+		case *int:
+			*d = s.values[i].(int)
+		case *string:
+			*d = s.values[i].(string)
+		case *[]byte:
+			*d = s.values[i].([]byte)
+		case *time.Time:
+			*d = s.values[i].(time.Time)
+		}
+	}
+	return nil
+}
+
+type fakeRows struct {
+	pginterfaces.IRows
+	rows [][]any
+	i    int
+}
+
+func (r *fakeRows) Next() bool {
+	r.i++
+	return r.i <= len(r.rows)
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	return fakeScanner{values: r.rows[r.i-1]}.Scan(dest...)
+}
+
+func (r *fakeRows) Close() error { return nil }
+func (r *fakeRows) Err() error   { return nil }
+
+func TestNewStore_InvalidTable(t *testing.T) {
+	_, err := NewStore(newFakeDB(), &recorder{}, StoreConfig{Table: "delayed; DROP TABLE users"})
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+
+	store, err := NewStore(newFakeDB(), &recorder{}, StoreConfig{Table: "messaging.delayed"})
+	require.NoError(t, err)
+	assert.Contains(t, store.Schema(), "delayed_deliver_at_idx ON messaging.delayed")
+	require.NoError(t, store.Migrate(context.Background()))
+}
+
+func TestStore_Dispatch(t *testing.T) {
+	db := newFakeDB()
+	pub := &recorder{}
+	store, err := NewStore(db, pub, StoreConfig{BatchSize: 2})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	sent := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	now := time.Now()
+	require.NoError(t, store.PublishAt(ctx, &consumer.Message{
+		ID: "m-2", Topic: "orders", Key: []byte("k"), Value: []byte("v"),
+		Headers: map[string]string{"x-tenant": "acme"}, Timestamp: sent,
+	}, now.Add(-time.Second)))
+	require.NoError(t, store.PublishAt(ctx, &consumer.Message{ID: "m-1", Topic: "orders"}, now.Add(-time.Minute)))
+	require.NoError(t, store.PublishAt(ctx, &consumer.Message{ID: "m-3", Topic: "billing"}, now))
+	require.NoError(t, store.PublishAt(ctx, &consumer.Message{ID: "later", Topic: "orders"}, now.Add(time.Hour)))
+
+	n, err := store.Dispatch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, []string{"m-1", "m-2", "m-3"}, pub.ids())
+
+	msg := pub.messages[1]
+	assert.Equal(t, "orders", msg.Topic)
+	assert.Equal(t, []byte("k"), msg.Key)
+	assert.Equal(t, []byte("v"), msg.Value)
+	assert.Equal(t, "acme", msg.Headers["x-tenant"])
+	assert.Equal(t, sent, msg.Timestamp)
+
+	pending, err := store.Pending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), pending)
+
+	require.NoError(t, store.DispatchJob()(ctx))
+	assert.Len(t, pub.ids(), 3)
+	assert.Equal(t, 1, db.rolledBack)
+	assert.Equal(t, 8, db.released)
+}
+
+func TestStore_DispatchFailure(t *testing.T) {
+	db := newFakeDB()
+	pub := &recorder{failOn: "m-2"}
+	store, err := NewStore(db, pub, StoreConfig{Backoff: func(int) time.Duration { return time.Hour }})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	now := time.Now()
+	for i, id := range []string{"m-1", "m-2", "m-3"} {
+		require.NoError(t, store.PublishAt(ctx, &consumer.Message{ID: id, Topic: "orders"},
+			now.Add(time.Duration(i-10)*time.Second)))
+	}
+
+	n, err := store.Dispatch(ctx)
+	assert.Error(t, err)
+	assert.Equal(t, 1, n)
+	assert.Len(t, db.rows, 2)
+	assert.Equal(t, 1, db.rows[2].attempts)
+	assert.Equal(t, "delay: publishing to orders: broker unavailable", db.rows[2].lastError)
+	assert.True(t, db.rows[2].deliverAt.After(now.Add(59*time.Minute)), "rescheduled after the backoff")
+
+	// the failed message waits for its backoff and does not hold back the
+	// next ones
+	n, err = store.Dispatch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []string{"m-1", "m-3"}, pub.ids())
+
+	pending, err := store.Pending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), pending)
+}
+
+func TestStore_DispatchDeadLetter(t *testing.T) {
+	db := newFakeDB()
+	pub := &recorder{failOn: "poison"}
+	store, err := NewStore(db, pub, StoreConfig{MaxAttempts: 3, Backoff: func(int) time.Duration { return 0 }})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, store.PublishAt(ctx, &consumer.Message{ID: "poison", Topic: "orders"}, time.Now().Add(-time.Minute)))
+	require.NoError(t, store.PublishAt(ctx, &consumer.Message{ID: "m-1", Topic: "orders"}, time.Now().Add(-time.Second)))
+
+	for range 3 {
+		_, err := store.Dispatch(ctx)
+		assert.Error(t, err)
+	}
+	assert.Equal(t, StatusFailed, db.rows[1].status)
+	assert.Equal(t, 3, db.rows[1].attempts)
+
+	// failed messages are no longer dispatched
+	n, err := store.Dispatch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.Equal(t, []string{"m-1"}, pub.ids())
+
+	failed, err := store.Failed(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), failed)
+
+	pub.failOn = ""
+	requeued, err := store.Requeue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), requeued)
+	n, err = store.Dispatch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []string{"m-1", "poison"}, pub.ids())
+}
+
+func TestStore_MissingTopic(t *testing.T) {
+	store, err := NewStore(newFakeDB(), &recorder{}, StoreConfig{})
+	require.NoError(t, err)
+	var p Publisher = store
+	assert.ErrorIs(t, p.PublishAt(context.Background(), &consumer.Message{ID: "m-1"}, time.Now()), ErrMissingTopic)
+}
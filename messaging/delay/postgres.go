@@ -0,0 +1,355 @@
+package delay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/db/postgres"
+	pginterfaces "github.com/fsvxavier/nexs-lib/db/postgres/interfaces"
+	"github.com/fsvxavier/nexs-lib/messaging/consumer"
+	"github.com/fsvxavier/nexs-lib/scheduler"
+)
+
+// Defaults of the store.
+const (
+	DefaultTable       = "messaging_delayed"
+	DefaultBatchSize   = 100
+	DefaultMaxAttempts = 10
+)
+
+// Message statuses stored in the status column.
+const (
+	StatusPending = "pending"
+	StatusFailed  = "failed"
+)
+
+// Pool provides connections to the store; interfaces.IPool satisfies it.
+type Pool interface {
+	Acquire(ctx context.Context) (pginterfaces.IConn, error)
+}
+
+// StoreConfig configures a Store.
+type StoreConfig struct {
+	// Table stores the delayed messages. Defaults to DefaultTable.
+	Table string
+
+	// BatchSize is the maximum number of messages published by each
+	// transaction of Dispatch. Defaults to DefaultBatchSize.
+	BatchSize int
+
+	// MaxAttempts is the number of failed publishes after which a message is
+	// marked as failed and no longer dispatched. Defaults to
+	// DefaultMaxAttempts.
+	MaxAttempts int
+
+	// Backoff returns how long a message whose publish failed waits before
+	// the next attempt. Defaults to an exponential backoff from one second
+	// to one hour.
+	Backoff consumer.Backoff
+}
+
+// Store delays messages for brokers without native delays. PublishAt stores
+// the message in a table, and Dispatch, run periodically by DispatchJob,
+// publishes the due messages. Dispatchers on several replicas claim
+// different messages with SELECT ... FOR UPDATE SKIP LOCKED.
+//
+// Messages are delivered at least once: a message published by a dispatch
+// whose transaction fails is published again by the next one. A message that
+// cannot be published is delivered again after Backoff, so it does not hold
+// back the others, and is marked as failed after MaxAttempts.
+type Store struct {
+	pool        Pool
+	publisher   consumer.Publisher
+	table       string
+	batchSize   int
+	maxAttempts int
+	backoff     consumer.Backoff
+}
+
+// NewStore creates a Store publishing the due messages through publisher.
+func NewStore(pool Pool, publisher consumer.Publisher, config StoreConfig) (*Store, error) {
+	if config.Table == "" {
+		config.Table = DefaultTable
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultMaxAttempts
+	}
+	if config.Backoff == nil {
+		config.Backoff = consumer.ExponentialBackoff(time.Second, time.Hour)
+	}
+	if !postgres.ValidIdentifier(config.Table) {
+		return nil, fmt.Errorf("%w: invalid table name: %q", ErrInvalidConfig, config.Table)
+	}
+	return &Store{
+		pool:        pool,
+		publisher:   publisher,
+		table:       config.Table,
+		batchSize:   config.BatchSize,
+		maxAttempts: config.MaxAttempts,
+		backoff:     config.Backoff,
+	}, nil
+}
+
+// Schema returns the statements creating the table and its index.
+func (s *Store) Schema() string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %[1]s (
+	id BIGSERIAL PRIMARY KEY,
+	topic TEXT NOT NULL,
+	message_id TEXT NOT NULL DEFAULT '',
+	message_key BYTEA,
+	message_value BYTEA,
+	headers JSONB NOT NULL DEFAULT '{}',
+	message_timestamp TIMESTAMPTZ NOT NULL DEFAULT now(),
+	deliver_at TIMESTAMPTZ NOT NULL,
+	status TEXT NOT NULL DEFAULT 'pending',
+	attempts INTEGER NOT NULL DEFAULT 0,
+	last_error TEXT,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS %[2]s_deliver_at_idx ON %[1]s (deliver_at) WHERE status = 'pending'`,
+		s.table, postgres.UnqualifiedName(s.table))
+}
+
+// Migrate creates the table when it does not exist.
+func (s *Store) Migrate(ctx context.Context) error {
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, s.Schema()); err != nil {
+		return fmt.Errorf("failed to create table %s: %w", s.table, err)
+	}
+	return nil
+}
+
+// PublishAt implements Publisher by storing the message until it is due.
+// Messages without a timestamp are stamped with the current time.
+func (s *Store) PublishAt(ctx context.Context, msg *consumer.Message, at time.Time) error {
+	if msg.Topic == "" {
+		return ErrMissingTopic
+	}
+	headers := msg.Headers
+	if headers == nil {
+		headers = map[string]string{}
+	}
+	encoded, err := json.Marshal(headers)
+	if err != nil {
+		return fmt.Errorf("failed to encode headers: %w", err)
+	}
+	var timestamp *time.Time
+	if !msg.Timestamp.IsZero() {
+		timestamp = &msg.Timestamp
+	}
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, fmt.Sprintf(`INSERT INTO %s
+	(topic, message_id, message_key, message_value, headers, message_timestamp, deliver_at)
+VALUES ($1, $2, $3, $4, $5::jsonb, COALESCE($6::timestamptz, now()), $7)`, s.table),
+		msg.Topic, msg.ID, msg.Key, msg.Value, string(encoded), timestamp, at); err != nil {
+		return fmt.Errorf("failed to store delayed message for %s: %w", msg.Topic, err)
+	}
+	return nil
+}
+
+// Dispatch publishes the due messages in batches until none is left, and
+// returns how many were published. When publishing fails, the messages
+// already published are removed, the failed message is rescheduled after
+// Backoff, or marked as failed after MaxAttempts, and the error is returned;
+// the others are retried by the next dispatch.
+func (s *Store) Dispatch(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		n, err := s.dispatchBatch(ctx)
+		total += n
+		if err != nil || n < s.batchSize {
+			return total, err
+		}
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+	}
+}
+
+// dispatchBatch publishes a batch of due messages in a transaction.
+func (s *Store) dispatchBatch(ctx context.Context) (int, error) {
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback(context.WithoutCancel(ctx))
+		}
+	}()
+
+	claimed, err := s.claim(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
+	if len(claimed) == 0 {
+		return 0, nil
+	}
+
+	published := 0
+	var publishErr error
+	for i, c := range claimed {
+		if err := s.publisher.Publish(ctx, c.msg.Topic, c.msg); err != nil {
+			publishErr = fmt.Errorf("delay: publishing to %s: %w", c.msg.Topic, err)
+			break
+		}
+		published = i + 1
+	}
+
+	if published > 0 {
+		ids := make([]int64, published)
+		for i, c := range claimed[:published] {
+			ids[i] = c.id
+		}
+		if _, err := tx.Exec(ctx, fmt.Sprintf(`DELETE FROM %s WHERE id = ANY($1)`, s.table), ids); err != nil {
+			return 0, fmt.Errorf("failed to delete dispatched messages: %w", err)
+		}
+	}
+	if publishErr != nil {
+		if err := s.recordFailure(ctx, tx, claimed[published], publishErr); err != nil {
+			return 0, errors.Join(publishErr, err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit dispatched messages: %w", err)
+	}
+	committed = true
+	return published, publishErr
+}
+
+// claimedMessage is a due message locked by a dispatch.
+type claimedMessage struct {
+	id       int64
+	attempts int
+	msg      *consumer.Message
+}
+
+// claim locks a batch of due messages.
+func (s *Store) claim(ctx context.Context, tx pginterfaces.ITransaction) ([]claimedMessage, error) {
+	rows, err := tx.Query(ctx, fmt.Sprintf(`SELECT id, topic, message_id, message_key, message_value, headers::text,
+	message_timestamp, attempts
+FROM %s
+WHERE status = 'pending' AND deliver_at <= now()
+ORDER BY deliver_at, id
+LIMIT $1
+FOR UPDATE SKIP LOCKED`, s.table), s.batchSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim delayed messages: %w", err)
+	}
+	defer rows.Close()
+
+	var claimed []claimedMessage
+	for rows.Next() {
+		var headers string
+		c := claimedMessage{msg: &consumer.Message{}}
+		if err := rows.Scan(&c.id, &c.msg.Topic, &c.msg.ID, &c.msg.Key, &c.msg.Value, &headers, &c.msg.Timestamp,
+			&c.attempts); err != nil {
+			return nil, fmt.Errorf("failed to read delayed message: %w", err)
+		}
+		if err := json.Unmarshal([]byte(headers), &c.msg.Headers); err != nil {
+			return nil, fmt.Errorf("failed to decode headers of delayed message %d: %w", c.id, err)
+		}
+		claimed = append(claimed, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to claim delayed messages: %w", err)
+	}
+	return claimed, nil
+}
+
+// recordFailure counts a failed publish of the message, delaying its next
+// attempt by the backoff or marking it as failed after the last attempt.
+func (s *Store) recordFailure(ctx context.Context, tx pginterfaces.ITransaction, c claimedMessage, cause error) error {
+	attempts := c.attempts + 1
+	if attempts >= s.maxAttempts {
+		if _, err := tx.Exec(ctx, fmt.Sprintf(`UPDATE %s SET status = 'failed', attempts = $2, last_error = $3
+WHERE id = $1`, s.table), c.id, attempts, cause.Error()); err != nil {
+			return fmt.Errorf("failed to mark delayed message %d as failed: %w", c.id, err)
+		}
+		return nil
+	}
+	if _, err := tx.Exec(ctx, fmt.Sprintf(`UPDATE %s SET attempts = $2, last_error = $3,
+	deliver_at = now() + $4 * interval '1 millisecond'
+WHERE id = $1`, s.table), c.id, attempts, cause.Error(), s.backoff(attempts).Milliseconds()); err != nil {
+		return fmt.Errorf("failed to reschedule delayed message %d: %w", c.id, err)
+	}
+	return nil
+}
+
+// DispatchJob returns a job running Dispatch, to be registered with
+// scheduler.Every; the interval bounds how late messages are delivered.
+func (s *Store) DispatchJob() scheduler.Job {
+	return func(ctx context.Context) error {
+		_, err := s.Dispatch(ctx)
+		return err
+	}
+}
+
+// Pending returns the number of messages waiting to be published, due or
+// not.
+func (s *Store) Pending(ctx context.Context) (int64, error) {
+	return s.count(ctx, StatusPending)
+}
+
+// Failed returns the number of messages marked as failed after MaxAttempts.
+func (s *Store) Failed(ctx context.Context) (int64, error) {
+	return s.count(ctx, StatusFailed)
+}
+
+// count returns the number of messages with the status.
+func (s *Store) count(ctx context.Context, status string) (int64, error) {
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	var count int64
+	if err := conn.QueryRow(ctx, fmt.Sprintf(`SELECT count(*) FROM %s WHERE status = $1`, s.table),
+		status).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count delayed messages: %w", err)
+	}
+	return count, nil
+}
+
+// Requeue makes the failed messages pending again with their attempts reset,
+// once the cause of the failures is fixed, and returns how many were
+// requeued.
+func (s *Store) Requeue(ctx context.Context) (int64, error) {
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	tag, err := conn.Exec(ctx, fmt.Sprintf(`UPDATE %s SET status = 'pending', attempts = 0, deliver_at = now()
+WHERE status = 'failed'`, s.table))
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue delayed messages: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,285 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// Content types of the codecs.
+const (
+	ContentTypeJSON  = "application/json"
+	ContentTypeProto = "application/protobuf"
+)
+
+// Codec encodes envelopes and their payloads.
+type Codec interface {
+	// ContentType returns the content type of the encoded envelopes.
+	ContentType() string
+
+	// Encode encodes the envelope.
+	Encode(env *Envelope) ([]byte, error)
+
+	// Decode decodes an envelope.
+	Decode(data []byte) (*Envelope, error)
+
+	// MarshalPayload encodes a payload for Envelope.Payload.
+	MarshalPayload(v any) ([]byte, error)
+
+	// UnmarshalPayload decodes Envelope.Payload into v, a pointer.
+	UnmarshalPayload(data []byte, v any) error
+}
+
+// JSON encodes envelopes as JSON objects with the payload embedded as JSON.
+// Payloads that are protobuf messages use the protobuf JSON mapping.
+var JSON Codec = jsonCodec{}
+
+// Proto encodes envelopes as protobuf messages. Payloads must be protobuf
+// messages. The wire format is the one of
+//
+//	message Envelope {
+//	  string id = 1;
+//	  string type = 2;
+//	  int32 version = 3;
+//	  google.protobuf.Timestamp occurred_at = 4;
+//	  string correlation_id = 5;
+//	  bytes payload = 6;
+//	}
+var Proto Codec = protoCodec{}
+
+// jsonCodec implements the JSON codec.
+type jsonCodec struct{}
+
+// jsonEnvelope is the JSON form of the envelope.
+type jsonEnvelope struct {
+	ID            string          `json:"id"`
+	Type          string          `json:"type"`
+	Version       int             `json:"version"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	CorrelationID string          `json:"correlation_id,omitempty"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// ContentType implements Codec.
+func (jsonCodec) ContentType() string {
+	return ContentTypeJSON
+}
+
+// Encode implements Codec.
+func (jsonCodec) Encode(env *Envelope) ([]byte, error) {
+	if err := env.Validate(); err != nil {
+		return nil, err
+	}
+	payload := json.RawMessage(env.Payload)
+	if len(payload) == 0 {
+		payload = json.RawMessage("null")
+	}
+	return json.Marshal(jsonEnvelope{
+		ID:            env.ID,
+		Type:          env.Type,
+		Version:       env.Version,
+		OccurredAt:    env.OccurredAt,
+		CorrelationID: env.CorrelationID,
+		Payload:       payload,
+	})
+}
+
+// Decode implements Codec.
+func (jsonCodec) Decode(data []byte) (*Envelope, error) {
+	var je jsonEnvelope
+	if err := json.Unmarshal(data, &je); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEnvelope, err)
+	}
+	env := &Envelope{
+		ID:            je.ID,
+		Type:          je.Type,
+		Version:       je.Version,
+		OccurredAt:    je.OccurredAt,
+		CorrelationID: je.CorrelationID,
+		Payload:       []byte(je.Payload),
+	}
+	if err := env.Validate(); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
+// MarshalPayload implements Codec.
+func (jsonCodec) MarshalPayload(v any) ([]byte, error) {
+	if m, ok := v.(proto.Message); ok {
+		return protojson.Marshal(m)
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalPayload implements Codec.
+func (jsonCodec) UnmarshalPayload(data []byte, v any) error {
+	if m, ok := v.(proto.Message); ok {
+		return protojson.Unmarshal(data, m)
+	}
+	return json.Unmarshal(data, v)
+}
+
+// protoCodec implements the protobuf codec.
+type protoCodec struct{}
+
+// Field numbers of the protobuf envelope and timestamp.
+const (
+	fieldID            protowire.Number = 1
+	fieldType          protowire.Number = 2
+	fieldVersion       protowire.Number = 3
+	fieldOccurredAt    protowire.Number = 4
+	fieldCorrelationID protowire.Number = 5
+	fieldPayload       protowire.Number = 6
+
+	fieldSeconds protowire.Number = 1
+	fieldNanos   protowire.Number = 2
+)
+
+// ContentType implements Codec.
+func (protoCodec) ContentType() string {
+	return ContentTypeProto
+}
+
+// Encode implements Codec.
+func (protoCodec) Encode(env *Envelope) ([]byte, error) {
+	if err := env.Validate(); err != nil {
+		return nil, err
+	}
+
+	var ts []byte
+	if secs := env.OccurredAt.Unix(); secs != 0 {
+		ts = protowire.AppendTag(ts, fieldSeconds, protowire.VarintType)
+		ts = protowire.AppendVarint(ts, uint64(secs))
+	}
+	if nanos := env.OccurredAt.Nanosecond(); nanos != 0 {
+		ts = protowire.AppendTag(ts, fieldNanos, protowire.VarintType)
+		ts = protowire.AppendVarint(ts, uint64(nanos))
+	}
+
+	b := make([]byte, 0, 64+len(env.Payload))
+	b = appendString(b, fieldID, env.ID)
+	b = appendString(b, fieldType, env.Type)
+	b = protowire.AppendTag(b, fieldVersion, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(env.Version))
+	b = protowire.AppendTag(b, fieldOccurredAt, protowire.BytesType)
+	b = protowire.AppendBytes(b, ts)
+	b = appendString(b, fieldCorrelationID, env.CorrelationID)
+	if len(env.Payload) > 0 {
+		b = protowire.AppendTag(b, fieldPayload, protowire.BytesType)
+		b = protowire.AppendBytes(b, env.Payload)
+	}
+	return b, nil
+}
+
+// appendString appends a string field, omitted when empty.
+func appendString(b []byte, num protowire.Number, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+// Decode implements Codec.
+func (protoCodec) Decode(data []byte) (*Envelope, error) {
+	env := &Envelope{}
+	var secs, nanos int64
+
+	err := consumeFields(data, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch {
+		case num == fieldVersion && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			env.Version = int(int32(v))
+			return n, nil
+		case typ != protowire.BytesType:
+			return protowire.ConsumeFieldValue(num, typ, b), nil
+		}
+
+		v, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			return n, nil
+		}
+		switch num {
+		case fieldID:
+			env.ID = string(v)
+		case fieldType:
+			env.Type = string(v)
+		case fieldCorrelationID:
+			env.CorrelationID = string(v)
+		case fieldPayload:
+			env.Payload = append([]byte(nil), v...)
+		case fieldOccurredAt:
+			err := consumeFields(v, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+				if typ != protowire.VarintType {
+					return protowire.ConsumeFieldValue(num, typ, b), nil
+				}
+				x, n := protowire.ConsumeVarint(b)
+				switch num {
+				case fieldSeconds:
+					secs = int64(x)
+				case fieldNanos:
+					nanos = int64(int32(x))
+				}
+				return n, nil
+			})
+			if err != nil {
+				return 0, err
+			}
+		}
+		return n, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	env.OccurredAt = time.Unix(secs, nanos).UTC()
+	if err := env.Validate(); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
+// consumeFields calls fn for each field of the protobuf message; fn returns
+// the length of the field value it consumed.
+func consumeFields(data []byte, fn func(num protowire.Number, typ protowire.Type, b []byte) (int, error)) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return fmt.Errorf("%w: %v", ErrInvalidEnvelope, protowire.ParseError(n))
+		}
+		data = data[n:]
+
+		n, err := fn(num, typ, data)
+		if err != nil {
+			return err
+		}
+		if n < 0 {
+			return fmt.Errorf("%w: %v", ErrInvalidEnvelope, protowire.ParseError(n))
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+// MarshalPayload implements Codec.
+func (protoCodec) MarshalPayload(v any) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T is not a protobuf message", ErrInvalidPayload, v)
+	}
+	return proto.Marshal(m)
+}
+
+// UnmarshalPayload implements Codec.
+func (protoCodec) UnmarshalPayload(data []byte, v any) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("%w: %T is not a protobuf message", ErrInvalidPayload, v)
+	}
+	return proto.Unmarshal(data, m)
+}
@@ -0,0 +1,87 @@
+// Package event defines the standard event envelope, its JSON and protobuf
+// codecs and a registry mapping event type and version to Go types. Payloads
+// are validated against the schemas of the registry, generated from the
+// validate tags by validator/schema, before they are published.
+package event
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event errors. Errors returned by the package wrap them, so errors.Is can be
+// used to tell failures apart.
+var (
+	ErrUnknownEvent      = errors.New("event: unknown event type or version")
+	ErrAlreadyRegistered = errors.New("event: event type and version already registered")
+	ErrInvalidPayload    = errors.New("event: invalid payload")
+	ErrInvalidEnvelope   = errors.New("event: invalid envelope")
+)
+
+// Envelope is the standard event envelope. Payload holds the payload encoded
+// by the codec of the envelope.
+type Envelope struct {
+	ID            string
+	Type          string
+	Version       int
+	OccurredAt    time.Time
+	CorrelationID string
+	Payload       []byte
+}
+
+// Validate checks the required fields of the envelope.
+func (e *Envelope) Validate() error {
+	switch {
+	case e.ID == "":
+		return fmt.Errorf("%w: id is required", ErrInvalidEnvelope)
+	case e.Type == "":
+		return fmt.Errorf("%w: type is required", ErrInvalidEnvelope)
+	case e.Version <= 0:
+		return fmt.Errorf("%w: version must be positive", ErrInvalidEnvelope)
+	case e.OccurredAt.IsZero():
+		return fmt.Errorf("%w: occurred_at is required", ErrInvalidEnvelope)
+	}
+	return nil
+}
+
+// Option configures an envelope created by Registry.NewEnvelope.
+type Option func(*Envelope)
+
+// WithID sets the event ID. By default a random UUID is used.
+func WithID(id string) Option {
+	return func(e *Envelope) {
+		e.ID = id
+	}
+}
+
+// WithOccurredAt sets when the event occurred. By default the current time
+// is used.
+func WithOccurredAt(t time.Time) Option {
+	return func(e *Envelope) {
+		e.OccurredAt = t
+	}
+}
+
+// WithCorrelationID sets the correlation ID shared by the events of a flow.
+func WithCorrelationID(id string) Option {
+	return func(e *Envelope) {
+		e.CorrelationID = id
+	}
+}
+
+// newEnvelope creates an envelope with the defaults and the options applied.
+func newEnvelope(eventType string, version int, opts ...Option) *Envelope {
+	env := &Envelope{
+		ID:         uuid.NewString(),
+		Type:       eventType,
+		Version:    version,
+		OccurredAt: time.Now().UTC(),
+	}
+	for _, opt := range opts {
+		opt(env)
+	}
+	return env
+}
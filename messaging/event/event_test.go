@@ -0,0 +1,208 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/fsvxavier/nexs-lib/messaging/consumer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type OrderCreated struct {
+	OrderID string  `json:"order_id" validate:"required,uuid"`
+	Amount  float64 `json:"amount" validate:"min=0.01"`
+	Email   string  `json:"email" validate:"required,email"`
+}
+
+type OrderCreatedV2 struct {
+	OrderID  string `json:"order_id" validate:"required"`
+	Currency string `json:"currency" validate:"required,min_length=3,max_length=3"`
+}
+
+func newTestRegistry(t *testing.T) *Registry {
+	t.Helper()
+	r := NewRegistry()
+	require.NoError(t, Register[OrderCreated](r, "order.created", 1))
+	require.NoError(t, Register[OrderCreatedV2](r, "order.created", 2))
+	require.NoError(t, Register[wrapperspb.StringValue](r, "note.added", 1))
+	return r
+}
+
+func validOrder() OrderCreated {
+	return OrderCreated{OrderID: "8f14e45f-ceea-4e7b-9d3c-1c2b6e7d8a90", Amount: 10.5, Email: "a@b.com"}
+}
+
+func TestRegistry_Register(t *testing.T) {
+	r := newTestRegistry(t)
+
+	err := Register[OrderCreated](r, "order.created", 1)
+	assert.ErrorIs(t, err, ErrAlreadyRegistered)
+	assert.Error(t, Register[OrderCreated](r, "", 1))
+	assert.Error(t, Register[string](r, "string.event", 1))
+
+	assert.Equal(t, []Key{{"note.added", 1}, {"order.created", 1}, {"order.created", 2}}, r.Keys())
+
+	s, err := r.Schema("order.created", 1)
+	require.NoError(t, err)
+	assert.Equal(t, "order.created/v1", s.ID)
+	assert.ElementsMatch(t, []string{"order_id", "email"}, s.Required)
+
+	_, err = r.Type("order.created", 3)
+	assert.ErrorIs(t, err, ErrUnknownEvent)
+	assert.True(t, domainerrors.IsType(err, interfaces.UnprocessableEntityError))
+}
+
+func TestRegistry_Validate(t *testing.T) {
+	r := newTestRegistry(t)
+
+	order := validOrder()
+	assert.NoError(t, r.Validate("order.created", 1, order))
+	assert.NoError(t, r.Validate("order.created", 1, &order))
+
+	err := r.Validate("order.created", 2, order)
+	assert.ErrorIs(t, err, ErrInvalidPayload, "payload type must match the version")
+
+	err = r.Validate("order.created", 1, OrderCreated{Amount: -1})
+	require.ErrorIs(t, err, ErrInvalidPayload)
+	var domainErr interfaces.DomainErrorInterface
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, interfaces.ValidationError, domainErr.Type())
+	fields := domainErr.Metadata()["errors"].(map[string][]string)
+	assert.Contains(t, fields, "order_id")
+	assert.Contains(t, fields, "amount")
+	assert.Contains(t, fields, "email")
+
+	var nilOrder *OrderCreated
+	assert.ErrorIs(t, r.Validate("order.created", 1, nilOrder), ErrInvalidPayload)
+}
+
+func TestJSONCodec_RoundTrip(t *testing.T) {
+	r := newTestRegistry(t)
+	occurred := time.Date(2024, 5, 1, 12, 30, 0, 123, time.UTC)
+
+	env, err := r.NewEnvelope(JSON, "order.created", 1, validOrder(),
+		WithCorrelationID("corr-1"), WithOccurredAt(occurred))
+	require.NoError(t, err)
+	assert.NotEmpty(t, env.ID)
+
+	data, err := JSON.Encode(env)
+	require.NoError(t, err)
+
+	var raw map[string]any
+	require.NoError(t, json.Unmarshal(data, &raw))
+	assert.Equal(t, "order.created", raw["type"])
+	assert.Equal(t, float64(1), raw["version"])
+	assert.Equal(t, "corr-1", raw["correlation_id"])
+	assert.Equal(t, "a@b.com", raw["payload"].(map[string]any)["email"], "payload is embedded as JSON")
+
+	decoded, err := JSON.Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, env.ID, decoded.ID)
+	assert.True(t, occurred.Equal(decoded.OccurredAt))
+
+	order, err := DecodeAs[OrderCreated](r, JSON, decoded)
+	require.NoError(t, err)
+	assert.Equal(t, validOrder(), *order)
+
+	_, err = DecodeAs[OrderCreatedV2](r, JSON, decoded)
+	assert.ErrorIs(t, err, ErrInvalidPayload)
+}
+
+func TestJSONCodec_Invalid(t *testing.T) {
+	_, err := JSON.Decode([]byte(`{"id":"1","type":"order.created","version":1}`))
+	assert.ErrorIs(t, err, ErrInvalidEnvelope)
+
+	_, err = JSON.Decode([]byte(`not json`))
+	assert.ErrorIs(t, err, ErrInvalidEnvelope)
+
+	r := newTestRegistry(t)
+	env := &Envelope{ID: "1", Type: "order.created", Version: 1, OccurredAt: time.Now(),
+		Payload: []byte(`{"order_id":"x","amount":1,"email":"a@b.com"}`)}
+	_, err = r.Decode(JSON, env)
+	assert.ErrorIs(t, err, ErrInvalidPayload, "decoded payloads are validated")
+}
+
+func TestProtoCodec_RoundTrip(t *testing.T) {
+	r := newTestRegistry(t)
+	occurred := time.Date(2024, 5, 1, 12, 30, 0, 987654321, time.UTC)
+
+	env, err := r.NewEnvelope(Proto, "note.added", 1, wrapperspb.String("hello"),
+		WithID("evt-1"), WithOccurredAt(occurred), WithCorrelationID("corr-1"))
+	require.NoError(t, err)
+
+	data, err := Proto.Encode(env)
+	require.NoError(t, err)
+
+	decoded, err := Proto.Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, "evt-1", decoded.ID)
+	assert.Equal(t, "note.added", decoded.Type)
+	assert.Equal(t, 1, decoded.Version)
+	assert.Equal(t, "corr-1", decoded.CorrelationID)
+	assert.True(t, occurred.Equal(decoded.OccurredAt))
+
+	note, err := DecodeAs[wrapperspb.StringValue](r, Proto, decoded)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", note.GetValue())
+
+	_, err = r.NewEnvelope(Proto, "order.created", 1, validOrder())
+	assert.ErrorIs(t, err, ErrInvalidPayload, "proto codec requires protobuf payloads")
+
+	_, err = Proto.Decode([]byte{0xff})
+	assert.ErrorIs(t, err, ErrInvalidEnvelope)
+}
+
+func TestPublisher(t *testing.T) {
+	r := newTestRegistry(t)
+	var published []*consumer.Message
+	pub := NewPublisher(r, JSON, consumer.PublisherFunc(func(ctx context.Context, topic string, msg *consumer.Message) error {
+		published = append(published, msg)
+		return nil
+	}))
+
+	env, err := pub.Publish(context.Background(), "orders", "order.created", 1, validOrder(), WithCorrelationID("corr-1"))
+	require.NoError(t, err)
+	require.Len(t, published, 1)
+
+	msg := published[0]
+	assert.Equal(t, env.ID, msg.ID)
+	assert.Equal(t, "orders", msg.Topic)
+	assert.Equal(t, ContentTypeJSON, msg.Headers[HeaderContentType])
+	assert.Equal(t, "order.created", msg.Headers[HeaderEventType])
+	assert.Equal(t, "1", msg.Headers[HeaderEventVersion])
+	assert.Equal(t, "corr-1", msg.Headers[HeaderCorrelationID])
+
+	_, err = pub.Publish(context.Background(), "orders", "order.created", 1, OrderCreated{})
+	assert.ErrorIs(t, err, ErrInvalidPayload)
+	assert.Len(t, published, 1, "invalid payloads are not published")
+
+	var received *OrderCreated
+	handler := Handler(r, JSON, func(ctx context.Context, env *Envelope, payload any) error {
+		received = payload.(*OrderCreated)
+		return nil
+	})
+	require.NoError(t, handler(context.Background(), msg))
+	assert.Equal(t, validOrder(), *received)
+
+	err = handler(context.Background(), &consumer.Message{ID: "bad", Value: []byte("{")})
+	assert.ErrorIs(t, err, ErrInvalidEnvelope)
+	assert.False(t, consumer.IsRetryable(err), "invalid envelopes are poison messages")
+}
+
+func TestPublisher_Error(t *testing.T) {
+	r := newTestRegistry(t)
+	pub := NewPublisher(r, JSON, consumer.PublisherFunc(func(ctx context.Context, topic string, msg *consumer.Message) error {
+		return errors.New("broker down")
+	}))
+
+	_, err := pub.Publish(context.Background(), "orders", "order.created", 1, validOrder())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "broker down")
+}
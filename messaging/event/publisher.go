@@ -0,0 +1,108 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/fsvxavier/nexs-lib/messaging/consumer"
+)
+
+// Headers set on messages carrying envelopes, so brokers and consumers can
+// route them without decoding the envelope.
+const (
+	HeaderContentType   = "content-type"
+	HeaderEventType     = "x-event-type"
+	HeaderEventVersion  = "x-event-version"
+	HeaderCorrelationID = "x-correlation-id"
+)
+
+// ToMessage encodes the envelope with the codec into a message.
+func ToMessage(codec Codec, env *Envelope) (*consumer.Message, error) {
+	data, err := codec.Encode(env)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := map[string]string{
+		HeaderContentType:  codec.ContentType(),
+		HeaderEventType:    env.Type,
+		HeaderEventVersion: strconv.Itoa(env.Version),
+	}
+	if env.CorrelationID != "" {
+		headers[HeaderCorrelationID] = env.CorrelationID
+	}
+	return &consumer.Message{
+		ID:        env.ID,
+		Value:     data,
+		Headers:   headers,
+		Timestamp: env.OccurredAt,
+	}, nil
+}
+
+// FromMessage decodes the envelope of a message with the codec. Invalid
+// envelopes return a BadRequest domain error, which the consumer middlewares
+// treat as a poison message.
+func FromMessage(codec Codec, msg *consumer.Message) (*Envelope, error) {
+	env, err := codec.Decode(msg.Value)
+	if err != nil {
+		return nil, domainerrors.NewWithMetadata(interfaces.BadRequestError, "INVALID_EVENT_ENVELOPE",
+			err.Error(), map[string]interface{}{
+				"message_id": msg.ID,
+				"topic":      msg.Topic,
+			}).Wrap(err)
+	}
+	return env, nil
+}
+
+// Publisher validates payloads against the registry and publishes them in
+// envelopes.
+type Publisher struct {
+	registry  *Registry
+	codec     Codec
+	publisher consumer.Publisher
+}
+
+// NewPublisher creates a publisher encoding envelopes with the codec.
+func NewPublisher(registry *Registry, codec Codec, publisher consumer.Publisher) *Publisher {
+	return &Publisher{registry: registry, codec: codec, publisher: publisher}
+}
+
+// Publish validates the payload, wraps it in an envelope and publishes it to
+// the topic. Invalid payloads are not published.
+func (p *Publisher) Publish(ctx context.Context, topic, eventType string, version int, payload any, opts ...Option) (*Envelope, error) {
+	env, err := p.registry.NewEnvelope(p.codec, eventType, version, payload, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := ToMessage(p.codec, env)
+	if err != nil {
+		return nil, err
+	}
+	msg.Topic = topic
+
+	if err := p.publisher.Publish(ctx, topic, msg); err != nil {
+		return nil, fmt.Errorf("event: publishing %s to %s: %w", Key{eventType, version}, topic, err)
+	}
+	return env, nil
+}
+
+// Handler returns a consumer handler that decodes the envelope of each
+// message and its payload, validated against the registry, before calling fn
+// with a pointer to the payload.
+func Handler(registry *Registry, codec Codec, fn func(ctx context.Context, env *Envelope, payload any) error) consumer.Handler {
+	return func(ctx context.Context, msg *consumer.Message) error {
+		env, err := FromMessage(codec, msg)
+		if err != nil {
+			return err
+		}
+		payload, err := registry.Decode(codec, env)
+		if err != nil {
+			return err
+		}
+		return fn(ctx, env, payload)
+	}
+}
@@ -0,0 +1,248 @@
+package event
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/fsvxavier/nexs-lib/validation/validator"
+	"github.com/fsvxavier/nexs-lib/validation/validator/schema"
+)
+
+// Key identifies an event type and version.
+type Key struct {
+	Type    string
+	Version int
+}
+
+// String returns the key as "type/v<version>".
+func (k Key) String() string {
+	return fmt.Sprintf("%s/v%d", k.Type, k.Version)
+}
+
+// entry is a registered event.
+type entry struct {
+	typ    reflect.Type
+	schema *schema.Schema
+}
+
+// Registry maps event types and versions to Go types and their schemas. It is
+// safe for concurrent use.
+type Registry struct {
+	mu        sync.RWMutex
+	entries   map[Key]entry
+	validator *validator.Validator
+}
+
+// RegistryOption configures a Registry.
+type RegistryOption func(*Registry)
+
+// WithValidator sets the validator of the payloads. By default a validator
+// with the default options is used.
+func WithValidator(v *validator.Validator) RegistryOption {
+	return func(r *Registry) {
+		if v != nil {
+			r.validator = v
+		}
+	}
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry(opts ...RegistryOption) *Registry {
+	r := &Registry{
+		entries:   make(map[Key]entry),
+		validator: validator.New(),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Register registers T, a struct type, as the payload of the event type and
+// version. Its JSON Schema is generated from the json and validate tags.
+func Register[T any](r *Registry, eventType string, version int) error {
+	if eventType == "" || version <= 0 {
+		return fmt.Errorf("event: invalid event type %q or version %d", eventType, version)
+	}
+
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	s, err := schema.Generate(typ, schema.WithID(Key{eventType, version}.String()))
+	if err != nil {
+		return fmt.Errorf("event: generating schema of %s: %w", Key{eventType, version}, err)
+	}
+
+	key := Key{Type: eventType, Version: version}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.entries[key]; ok {
+		return fmt.Errorf("%w: %s", ErrAlreadyRegistered, key)
+	}
+	r.entries[key] = entry{typ: typ, schema: s}
+	return nil
+}
+
+// MustRegister is like Register but panics on error.
+func MustRegister[T any](r *Registry, eventType string, version int) {
+	if err := Register[T](r, eventType, version); err != nil {
+		panic(err)
+	}
+}
+
+// lookup returns the registered event.
+func (r *Registry) lookup(eventType string, version int) (entry, error) {
+	r.mu.RLock()
+	e, ok := r.entries[Key{Type: eventType, Version: version}]
+	r.mu.RUnlock()
+	if !ok {
+		return entry{}, domainerrors.NewWithMetadata(interfaces.UnprocessableEntityError, "UNKNOWN_EVENT",
+			fmt.Sprintf("event %s is not registered", Key{eventType, version}), map[string]interface{}{
+				"event_type": eventType,
+				"version":    version,
+			}).Wrap(ErrUnknownEvent)
+	}
+	return e, nil
+}
+
+// Type returns the Go type registered for the event type and version.
+func (r *Registry) Type(eventType string, version int) (reflect.Type, error) {
+	e, err := r.lookup(eventType, version)
+	if err != nil {
+		return nil, err
+	}
+	return e.typ, nil
+}
+
+// Schema returns the JSON Schema registered for the event type and version.
+func (r *Registry) Schema(eventType string, version int) (*schema.Schema, error) {
+	e, err := r.lookup(eventType, version)
+	if err != nil {
+		return nil, err
+	}
+	return e.schema, nil
+}
+
+// Keys returns the registered events sorted by type and version.
+func (r *Registry) Keys() []Key {
+	r.mu.RLock()
+	keys := make([]Key, 0, len(r.entries))
+	for k := range r.entries {
+		keys = append(keys, k)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Type != keys[j].Type {
+			return keys[i].Type < keys[j].Type
+		}
+		return keys[i].Version < keys[j].Version
+	})
+	return keys
+}
+
+// Validate checks that the payload, a value or pointer of the registered
+// type, satisfies the schema of the event type and version. Invalid payloads
+// return a Validation domain error wrapping ErrInvalidPayload, with the field
+// errors in the "errors" metadata.
+func (r *Registry) Validate(eventType string, version int, payload any) error {
+	e, err := r.lookup(eventType, version)
+	if err != nil {
+		return err
+	}
+
+	t := reflect.TypeOf(payload)
+	if t != nil && t.Kind() == reflect.Pointer {
+		if reflect.ValueOf(payload).IsNil() {
+			t = nil
+		} else {
+			t = t.Elem()
+		}
+	}
+	if t != e.typ {
+		return domainerrors.NewWithMetadata(interfaces.ValidationError, "INVALID_EVENT_PAYLOAD",
+			fmt.Sprintf("payload of event %s must be %v, got %T", Key{eventType, version}, e.typ, payload),
+			map[string]interface{}{
+				"event_type": eventType,
+				"version":    version,
+			}).Wrap(ErrInvalidPayload)
+	}
+
+	if err := r.validator.ValidateStruct(payload); err != nil {
+		var ve validator.ValidationErrors
+		if !errors.As(err, &ve) {
+			return fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+		}
+		return domainerrors.NewWithMetadata(interfaces.ValidationError, "INVALID_EVENT_PAYLOAD",
+			fmt.Sprintf("payload of event %s is invalid", Key{eventType, version}), map[string]interface{}{
+				"event_type": eventType,
+				"version":    version,
+				"errors":     ve.ToMap(),
+			}).Wrap(ErrInvalidPayload)
+	}
+	return nil
+}
+
+// NewEnvelope validates the payload and wraps it in an envelope, encoded with
+// the codec.
+func (r *Registry) NewEnvelope(codec Codec, eventType string, version int, payload any, opts ...Option) (*Envelope, error) {
+	if err := r.Validate(eventType, version, payload); err != nil {
+		return nil, err
+	}
+
+	data, err := codec.MarshalPayload(payload)
+	if err != nil {
+		return nil, fmt.Errorf("event: encoding payload of %s: %w", Key{eventType, version}, err)
+	}
+
+	env := newEnvelope(eventType, version, opts...)
+	env.Payload = data
+	if err := env.Validate(); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
+// Decode decodes the payload of the envelope, encoded with the codec, into a
+// new value of the registered type and validates it. It returns a pointer to
+// the value.
+func (r *Registry) Decode(codec Codec, env *Envelope) (any, error) {
+	e, err := r.lookup(env.Type, env.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := reflect.New(e.typ).Interface()
+	if err := codec.UnmarshalPayload(env.Payload, payload); err != nil {
+		return nil, domainerrors.NewWithMetadata(interfaces.BadRequestError, "INVALID_EVENT_PAYLOAD",
+			fmt.Sprintf("payload of event %s cannot be decoded: %v", Key{env.Type, env.Version}, err),
+			map[string]interface{}{
+				"event_id":   env.ID,
+				"event_type": env.Type,
+				"version":    env.Version,
+			}).Wrap(ErrInvalidPayload)
+	}
+
+	if err := r.Validate(env.Type, env.Version, payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// DecodeAs decodes the payload of the envelope like Registry.Decode and
+// returns it as *T.
+func DecodeAs[T any](r *Registry, codec Codec, env *Envelope) (*T, error) {
+	payload, err := r.Decode(codec, env)
+	if err != nil {
+		return nil, err
+	}
+	typed, ok := payload.(*T)
+	if !ok {
+		return nil, fmt.Errorf("%w: event %s is %T, not %T", ErrInvalidPayload,
+			Key{env.Type, env.Version}, payload, (*T)(nil))
+	}
+	return typed, nil
+}
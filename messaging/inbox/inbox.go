@@ -0,0 +1,269 @@
+// Package inbox is a transactional inbox for message consumers backed by a
+// PostgreSQL table. The ID of every processed message is recorded in the same
+// transaction as the side effects of its handler, so a message redelivered
+// after a crash or a lost acknowledgement is detected and skipped: the
+// effects of a message are applied exactly once even though the broker
+// delivers it at least once.
+package inbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/db/postgres"
+	pginterfaces "github.com/fsvxavier/nexs-lib/db/postgres/interfaces"
+	"github.com/fsvxavier/nexs-lib/messaging/consumer"
+	"github.com/fsvxavier/nexs-lib/scheduler"
+)
+
+// Defaults of the inbox.
+const (
+	DefaultTable     = "messaging_inbox"
+	DefaultConsumer  = "default"
+	DefaultRetention = 7 * 24 * time.Hour
+)
+
+// ErrMissingID is returned for messages without an ID, which cannot be
+// deduplicated.
+var ErrMissingID = errors.New("inbox: message has no id")
+
+// Conn is the part of the db/postgres connection used to record messages;
+// interfaces.IConn and transactions satisfy it.
+type Conn interface {
+	Exec(ctx context.Context, query string, args ...interface{}) (pginterfaces.ICommandTag, error)
+}
+
+// Pool provides connections to the inbox; interfaces.IPool satisfies it.
+type Pool interface {
+	Acquire(ctx context.Context) (pginterfaces.IConn, error)
+}
+
+// Handler processes a message inside the transaction recording it. The side
+// effects of the handler must go through tx to be committed atomically with
+// the record.
+type Handler func(ctx context.Context, tx pginterfaces.ITransaction, msg *consumer.Message) error
+
+// Config configures an Inbox.
+type Config struct {
+	// Table stores the processed messages. Defaults to DefaultTable.
+	Table string
+
+	// Consumer names the consumer; consumers sharing a table deduplicate
+	// messages independently, so the same message can be processed once
+	// by each of them. Defaults to DefaultConsumer.
+	Consumer string
+
+	// Retention is how long processed messages are remembered by Cleanup.
+	// It must exceed the longest redelivery window of the broker.
+	// Defaults to DefaultRetention.
+	Retention time.Duration
+
+	// Key returns the deduplication key of a message. Defaults to the
+	// message ID.
+	Key func(msg *consumer.Message) string
+}
+
+// Metrics are the counters of an inbox.
+type Metrics struct {
+	// Processed messages were handled and committed.
+	Processed int64
+	// Duplicates were already recorded and skipped.
+	Duplicates int64
+	// Failed messages were rolled back because their handler or the
+	// commit failed.
+	Failed int64
+	// Cleaned records were deleted by Cleanup.
+	Cleaned int64
+}
+
+// Inbox records the messages processed by a consumer.
+type Inbox struct {
+	pool      Pool
+	table     string
+	consumer  string
+	retention time.Duration
+	key       func(msg *consumer.Message) string
+
+	metricsMu sync.Mutex
+	metrics   Metrics
+}
+
+// New creates an Inbox.
+func New(pool Pool, config Config) (*Inbox, error) {
+	if config.Table == "" {
+		config.Table = DefaultTable
+	}
+	if config.Consumer == "" {
+		config.Consumer = DefaultConsumer
+	}
+	if config.Retention <= 0 {
+		config.Retention = DefaultRetention
+	}
+	if config.Key == nil {
+		config.Key = func(msg *consumer.Message) string { return msg.ID }
+	}
+	if !postgres.ValidIdentifier(config.Table) {
+		return nil, fmt.Errorf("invalid table name: %q", config.Table)
+	}
+	return &Inbox{
+		pool:      pool,
+		table:     config.Table,
+		consumer:  config.Consumer,
+		retention: config.Retention,
+		key:       config.Key,
+	}, nil
+}
+
+// Schema returns the statements creating the table and its index.
+func (i *Inbox) Schema() string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %[1]s (
+	consumer TEXT NOT NULL,
+	message_id TEXT NOT NULL,
+	topic TEXT NOT NULL DEFAULT '',
+	processed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	PRIMARY KEY (consumer, message_id)
+);
+CREATE INDEX IF NOT EXISTS %[2]s_processed_at_idx ON %[1]s (processed_at)`,
+		i.table, postgres.UnqualifiedName(i.table))
+}
+
+// Migrate creates the table when it does not exist.
+func (i *Inbox) Migrate(ctx context.Context) error {
+	conn, err := i.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, i.Schema()); err != nil {
+		return fmt.Errorf("failed to create table %s: %w", i.table, err)
+	}
+	return nil
+}
+
+// Record records the message through conn, which must be the transaction of
+// the side effects of the message. It reports whether the message is new;
+// false means it was already processed and the transaction should be rolled
+// back. Handle calls it for consumers managing their own transactions.
+func (i *Inbox) Record(ctx context.Context, conn Conn, msg *consumer.Message) (bool, error) {
+	key := i.key(msg)
+	if key == "" {
+		return false, ErrMissingID
+	}
+
+	tag, err := conn.Exec(ctx, fmt.Sprintf(`INSERT INTO %s (consumer, message_id, topic)
+VALUES ($1, $2, $3)
+ON CONFLICT (consumer, message_id) DO NOTHING`, i.table), i.consumer, key, msg.Topic)
+	if err != nil {
+		return false, fmt.Errorf("failed to record message %s: %w", key, err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// Handle returns a consumer.Handler running handler inside a transaction
+// that records the message. Messages already recorded are acknowledged
+// without calling handler. When handler fails, the transaction and the
+// record are rolled back and the error is returned, so the message is
+// redelivered. Messages without an ID fail with a permanent error.
+//
+// A commit whose result is lost returns an error even though the record may
+// have been committed; the redelivery is then skipped as a duplicate.
+func (i *Inbox) Handle(handler Handler) consumer.Handler {
+	return func(ctx context.Context, msg *consumer.Message) error {
+		err := i.process(ctx, msg, handler)
+		if errors.Is(err, ErrMissingID) {
+			return consumer.Permanent(err)
+		}
+		return err
+	}
+}
+
+// process processes the message in a transaction.
+func (i *Inbox) process(ctx context.Context, msg *consumer.Message, handler Handler) (err error) {
+	conn, err := i.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			// the context may be cancelled; the rollback must still run
+			_ = tx.Rollback(context.WithoutCancel(ctx))
+		}
+		if err != nil {
+			i.record(func(m *Metrics) { m.Failed++ })
+		}
+	}()
+
+	isNew, err := i.Record(ctx, tx, msg)
+	if err != nil {
+		return err
+	}
+	if !isNew {
+		i.record(func(m *Metrics) { m.Duplicates++ })
+		return nil
+	}
+
+	if err := handler(ctx, tx, msg); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit message %s: %w", i.key(msg), err)
+	}
+	committed = true
+	i.record(func(m *Metrics) { m.Processed++ })
+	return nil
+}
+
+// Cleanup deletes the records of the consumer older than the retention and
+// returns how many were deleted. Messages redelivered after their record is
+// deleted are processed again.
+func (i *Inbox) Cleanup(ctx context.Context) (int64, error) {
+	conn, err := i.pool.Acquire(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	tag, err := conn.Exec(ctx, fmt.Sprintf(`DELETE FROM %s
+WHERE consumer = $1 AND processed_at < now() - $2 * interval '1 millisecond'`, i.table),
+		i.consumer, i.retention.Milliseconds())
+	if err != nil {
+		return 0, fmt.Errorf("failed to clean up inbox %s: %w", i.table, err)
+	}
+	deleted := tag.RowsAffected()
+	i.record(func(m *Metrics) { m.Cleaned += deleted })
+	return deleted, nil
+}
+
+// CleanupJob returns a job running Cleanup, to be registered with
+// scheduler.Every.
+func (i *Inbox) CleanupJob() scheduler.Job {
+	return func(ctx context.Context) error {
+		_, err := i.Cleanup(ctx)
+		return err
+	}
+}
+
+// record updates the counters.
+func (i *Inbox) record(update func(m *Metrics)) {
+	i.metricsMu.Lock()
+	update(&i.metrics)
+	i.metricsMu.Unlock()
+}
+
+// Metrics returns a snapshot of the inbox counters.
+func (i *Inbox) Metrics() Metrics {
+	i.metricsMu.Lock()
+	defer i.metricsMu.Unlock()
+	return i.metrics
+}
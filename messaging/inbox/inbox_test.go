@@ -0,0 +1,252 @@
+package inbox
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	pginterfaces "github.com/fsvxavier/nexs-lib/db/postgres/interfaces"
+	"github.com/fsvxavier/nexs-lib/messaging/consumer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDB emulates the inbox table and a counter updated by handlers. The
+// statements of a transaction are applied on commit.
+type fakeDB struct {
+	mu         sync.Mutex
+	records    map[string]time.Time
+	counter    int
+	released   int
+	rolledBack int
+	failCommit error
+}
+
+func newFakeDB() *fakeDB {
+	return &fakeDB{records: map[string]time.Time{}}
+}
+
+func (db *fakeDB) Acquire(ctx context.Context) (pginterfaces.IConn, error) {
+	return &fakeConn{db: db}, nil
+}
+
+type fakeConn struct {
+	pginterfaces.IConn
+	db *fakeDB
+}
+
+func (c *fakeConn) Release() {
+	c.db.mu.Lock()
+	c.db.released++
+	c.db.mu.Unlock()
+}
+
+func (c *fakeConn) Begin(ctx context.Context) (pginterfaces.ITransaction, error) {
+	return &fakeTx{db: c.db}, nil
+}
+
+func (c *fakeConn) Exec(ctx context.Context, query string, args ...interface{}) (pginterfaces.ICommandTag, error) {
+	db := c.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	switch {
+	case strings.HasPrefix(query, "CREATE TABLE"):
+		return fakeTag{}, nil
+	case strings.HasPrefix(query, "DELETE FROM"):
+		retention := time.Duration(args[1].(int64)) * time.Millisecond
+		var deleted int64
+		for key, at := range db.records {
+			if strings.HasPrefix(key, args[0].(string)+"/") && time.Since(at) > retention {
+				delete(db.records, key)
+				deleted++
+			}
+		}
+		return fakeTag{rows: deleted}, nil
+	}
+	return nil, errors.New("unexpected query")
+}
+
+// fakeTx stages the statements until commit.
+type fakeTx struct {
+	pginterfaces.ITransaction
+	db      *fakeDB
+	records []string
+	counter int
+}
+
+func (t *fakeTx) Exec(ctx context.Context, query string, args ...interface{}) (pginterfaces.ICommandTag, error) {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+
+	switch {
+	case strings.HasPrefix(query, "INSERT INTO"):
+		key := args[0].(string) + "/" + args[1].(string)
+		if _, ok := t.db.records[key]; ok {
+			return fakeTag{}, nil
+		}
+		t.records = append(t.records, key)
+		return fakeTag{rows: 1}, nil
+	case strings.HasPrefix(query, "UPDATE counter"):
+		t.counter++
+		return fakeTag{rows: 1}, nil
+	}
+	return nil, errors.New("unexpected query")
+}
+
+func (t *fakeTx) Commit(ctx context.Context) error {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+	if t.db.failCommit != nil {
+		return t.db.failCommit
+	}
+	for _, key := range t.records {
+		t.db.records[key] = time.Now()
+	}
+	t.db.counter += t.counter
+	return nil
+}
+
+func (t *fakeTx) Rollback(ctx context.Context) error {
+	t.db.mu.Lock()
+	t.db.rolledBack++
+	t.db.mu.Unlock()
+	return nil
+}
+
+type fakeTag struct {
+	pginterfaces.ICommandTag
+	rows int64
+}
+
+func (t fakeTag) RowsAffected() int64 { return t.rows }
+
+func increment(ctx context.Context, tx pginterfaces.ITransaction, msg *consumer.Message) error {
+	_, err := tx.Exec(ctx, "UPDATE counter SET value = value + 1")
+	return err
+}
+
+func TestNew_InvalidTable(t *testing.T) {
+	_, err := New(newFakeDB(), Config{Table: "inbox; DROP TABLE users"})
+	assert.Error(t, err)
+
+	inbox, err := New(newFakeDB(), Config{Table: "events.inbox"})
+	require.NoError(t, err)
+	assert.Contains(t, inbox.Schema(), "CREATE TABLE IF NOT EXISTS events.inbox")
+	assert.Contains(t, inbox.Schema(), "inbox_processed_at_idx ON events.inbox")
+	require.NoError(t, inbox.Migrate(context.Background()))
+}
+
+func TestHandle_SkipsDuplicates(t *testing.T) {
+	db := newFakeDB()
+	inbox, err := New(db, Config{})
+	require.NoError(t, err)
+	handler := inbox.Handle(increment)
+
+	msg := &consumer.Message{ID: "m-1", Topic: "orders"}
+	require.NoError(t, handler(context.Background(), msg))
+	require.NoError(t, handler(context.Background(), msg))
+	require.NoError(t, handler(context.Background(), &consumer.Message{ID: "m-2", Topic: "orders"}))
+
+	assert.Equal(t, 2, db.counter)
+	assert.Equal(t, 1, db.rolledBack)
+	assert.Equal(t, 3, db.released)
+	assert.Equal(t, Metrics{Processed: 2, Duplicates: 1}, inbox.Metrics())
+}
+
+func TestHandle_ConsumersAreIndependent(t *testing.T) {
+	db := newFakeDB()
+	billing, err := New(db, Config{Consumer: "billing"})
+	require.NoError(t, err)
+	shipping, err := New(db, Config{Consumer: "shipping"})
+	require.NoError(t, err)
+
+	msg := &consumer.Message{ID: "m-1"}
+	require.NoError(t, billing.Handle(increment)(context.Background(), msg))
+	require.NoError(t, shipping.Handle(increment)(context.Background(), msg))
+	assert.Equal(t, 2, db.counter)
+}
+
+func TestHandle_FailureRollsBack(t *testing.T) {
+	db := newFakeDB()
+	inbox, err := New(db, Config{})
+	require.NoError(t, err)
+
+	cause := errors.New("downstream unavailable")
+	failing := inbox.Handle(func(ctx context.Context, tx pginterfaces.ITransaction, msg *consumer.Message) error {
+		if err := increment(ctx, tx, msg); err != nil {
+			return err
+		}
+		return cause
+	})
+
+	msg := &consumer.Message{ID: "m-1"}
+	assert.ErrorIs(t, failing(context.Background(), msg), cause)
+	assert.Equal(t, 0, db.counter)
+	assert.Empty(t, db.records)
+
+	// the redelivery is processed since nothing was recorded
+	require.NoError(t, inbox.Handle(increment)(context.Background(), msg))
+	assert.Equal(t, 1, db.counter)
+	assert.Equal(t, Metrics{Processed: 1, Failed: 1}, inbox.Metrics())
+}
+
+func TestHandle_CommitFailure(t *testing.T) {
+	db := newFakeDB()
+	db.failCommit = errors.New("connection reset")
+	inbox, err := New(db, Config{})
+	require.NoError(t, err)
+
+	err = inbox.Handle(increment)(context.Background(), &consumer.Message{ID: "m-1"})
+	assert.ErrorIs(t, err, db.failCommit)
+	assert.Equal(t, 1, db.rolledBack)
+	assert.Equal(t, int64(1), inbox.Metrics().Failed)
+}
+
+func TestHandle_MissingID(t *testing.T) {
+	db := newFakeDB()
+	inbox, err := New(db, Config{})
+	require.NoError(t, err)
+
+	err = inbox.Handle(increment)(context.Background(), &consumer.Message{Topic: "orders"})
+	assert.ErrorIs(t, err, ErrMissingID)
+	assert.ErrorIs(t, err, consumer.ErrPoisonMessage)
+	assert.Equal(t, 0, db.counter)
+}
+
+func TestHandle_CustomKey(t *testing.T) {
+	db := newFakeDB()
+	inbox, err := New(db, Config{Key: func(msg *consumer.Message) string {
+		return msg.Headers["event-id"]
+	}})
+	require.NoError(t, err)
+	handler := inbox.Handle(increment)
+
+	headers := map[string]string{"event-id": "e-1"}
+	require.NoError(t, handler(context.Background(), &consumer.Message{ID: "1", Headers: headers}))
+	require.NoError(t, handler(context.Background(), &consumer.Message{ID: "2", Headers: headers}))
+	assert.Equal(t, 1, db.counter)
+}
+
+func TestCleanup(t *testing.T) {
+	db := newFakeDB()
+	inbox, err := New(db, Config{Consumer: "billing", Retention: time.Hour})
+	require.NoError(t, err)
+
+	db.records["billing/old"] = time.Now().Add(-2 * time.Hour)
+	db.records["billing/recent"] = time.Now()
+	db.records["shipping/old"] = time.Now().Add(-2 * time.Hour)
+
+	require.NoError(t, inbox.CleanupJob()(context.Background()))
+	assert.Contains(t, db.records, "billing/recent")
+	assert.Contains(t, db.records, "shipping/old")
+	assert.NotContains(t, db.records, "billing/old")
+	assert.Equal(t, int64(1), inbox.Metrics().Cleaned)
+
+	deleted, err := inbox.Cleanup(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), deleted)
+}
@@ -0,0 +1,145 @@
+package money
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Currency describes an ISO 4217 currency.
+type Currency struct {
+	// Code is the alphabetic code, such as "BRL".
+	Code string `json:"code"`
+	// Numeric is the three-digit numeric code, such as "986".
+	Numeric string `json:"numeric"`
+	// Name is the English name of the currency.
+	Name string `json:"name"`
+	// MinorUnits is the number of decimal places of the minor unit: 2 for
+	// cents, 0 for JPY, 3 for KWD.
+	MinorUnits int32 `json:"minor_units"`
+}
+
+// String returns the alphabetic code.
+func (c Currency) String() string {
+	return c.Code
+}
+
+// IsZero reports whether c is the zero Currency, which no Money may use.
+func (c Currency) IsZero() bool {
+	return c.Code == ""
+}
+
+// currencies is the registry of known currencies, keyed by alphabetic code.
+var currencies = struct {
+	mu     sync.RWMutex
+	byCode map[string]Currency
+}{byCode: make(map[string]Currency)}
+
+func init() {
+	for _, c := range []Currency{
+		{"AED", "784", "UAE Dirham", 2},
+		{"ARS", "032", "Argentine Peso", 2},
+		{"AUD", "036", "Australian Dollar", 2},
+		{"BHD", "048", "Bahraini Dinar", 3},
+		{"BOB", "068", "Boliviano", 2},
+		{"BRL", "986", "Brazilian Real", 2},
+		{"CAD", "124", "Canadian Dollar", 2},
+		{"CHF", "756", "Swiss Franc", 2},
+		{"CLF", "990", "Unidad de Fomento", 4},
+		{"CLP", "152", "Chilean Peso", 0},
+		{"CNY", "156", "Yuan Renminbi", 2},
+		{"COP", "170", "Colombian Peso", 2},
+		{"CZK", "203", "Czech Koruna", 2},
+		{"DKK", "208", "Danish Krone", 2},
+		{"EGP", "818", "Egyptian Pound", 2},
+		{"EUR", "978", "Euro", 2},
+		{"GBP", "826", "Pound Sterling", 2},
+		{"HKD", "344", "Hong Kong Dollar", 2},
+		{"HUF", "348", "Forint", 2},
+		{"IDR", "360", "Rupiah", 2},
+		{"ILS", "376", "New Israeli Sheqel", 2},
+		{"INR", "356", "Indian Rupee", 2},
+		{"ISK", "352", "Iceland Krona", 0},
+		{"JOD", "400", "Jordanian Dinar", 3},
+		{"JPY", "392", "Yen", 0},
+		{"KES", "404", "Kenyan Shilling", 2},
+		{"KRW", "410", "Won", 0},
+		{"KWD", "414", "Kuwaiti Dinar", 3},
+		{"MXN", "484", "Mexican Peso", 2},
+		{"MYR", "458", "Malaysian Ringgit", 2},
+		{"NGN", "566", "Naira", 2},
+		{"NOK", "578", "Norwegian Krone", 2},
+		{"NZD", "554", "New Zealand Dollar", 2},
+		{"OMR", "512", "Rial Omani", 3},
+		{"PEN", "604", "Sol", 2},
+		{"PHP", "608", "Philippine Peso", 2},
+		{"PLN", "985", "Zloty", 2},
+		{"PYG", "600", "Guarani", 0},
+		{"RUB", "643", "Russian Ruble", 2},
+		{"SAR", "682", "Saudi Riyal", 2},
+		{"SEK", "752", "Swedish Krona", 2},
+		{"SGD", "702", "Singapore Dollar", 2},
+		{"THB", "764", "Baht", 2},
+		{"TND", "788", "Tunisian Dinar", 3},
+		{"TRY", "949", "Turkish Lira", 2},
+		{"TWD", "901", "New Taiwan Dollar", 2},
+		{"USD", "840", "US Dollar", 2},
+		{"UYU", "858", "Peso Uruguayo", 2},
+		{"VND", "704", "Dong", 0},
+		{"XAF", "950", "CFA Franc BEAC", 0},
+		{"XOF", "952", "CFA Franc BCEAO", 0},
+		{"ZAR", "710", "Rand", 2},
+	} {
+		currencies.byCode[c.Code] = c
+	}
+}
+
+// LookupCurrency returns the currency of an alphabetic code, case
+// insensitively.
+func LookupCurrency(code string) (Currency, error) {
+	currencies.mu.RLock()
+	c, ok := currencies.byCode[strings.ToUpper(strings.TrimSpace(code))]
+	currencies.mu.RUnlock()
+	if !ok {
+		return Currency{}, unknownCurrency(code)
+	}
+	return c, nil
+}
+
+// MustCurrency is like LookupCurrency but panics on unknown codes.
+func MustCurrency(code string) Currency {
+	c, err := LookupCurrency(code)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// RegisterCurrency adds or replaces a currency, for codes missing from the
+// built-in table or internal units such as loyalty points.
+func RegisterCurrency(c Currency) error {
+	c.Code = strings.ToUpper(strings.TrimSpace(c.Code))
+	if len(c.Code) != 3 {
+		return fmt.Errorf("money: currency code %q must have 3 letters", c.Code)
+	}
+	if c.MinorUnits < 0 {
+		return fmt.Errorf("money: currency %s has negative minor units", c.Code)
+	}
+	currencies.mu.Lock()
+	defer currencies.mu.Unlock()
+	currencies.byCode[c.Code] = c
+	return nil
+}
+
+// Currencies returns the registered currencies sorted by code.
+func Currencies() []Currency {
+	currencies.mu.RLock()
+	list := make([]Currency, 0, len(currencies.byCode))
+	for _, c := range currencies.byCode {
+		list = append(list, c)
+	}
+	currencies.mu.RUnlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	return list
+}
@@ -0,0 +1,365 @@
+// Package money provides exact monetary amounts on top of shopspring decimals
+// and the locale-aware parsing of parsers/decimal: ISO 4217 currencies,
+// arithmetic with explicit rounding modes, allocation helpers, JSON mapping
+// and validator rules for amounts.
+//
+// A Money always holds an amount that fits the minor units of its currency;
+// operations that could produce sub-minor amounts take a RoundingMode, and
+// operations mixing currencies fail with a CURRENCY_MISMATCH domain error.
+package money
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	shopspring "github.com/shopspring/decimal"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/fsvxavier/nexs-lib/parsers/decimal"
+)
+
+var (
+	// ErrInvalidAmount is matched by errors of malformed amounts.
+	ErrInvalidAmount = errors.New("money: invalid amount")
+	// ErrUnknownCurrency is matched by errors of unregistered currency codes.
+	ErrUnknownCurrency = errors.New("money: unknown currency")
+	// ErrCurrencyMismatch is matched by errors of operations mixing currencies.
+	ErrCurrencyMismatch = errors.New("money: currency mismatch")
+	// ErrPrecision is matched by errors of amounts finer than the minor unit.
+	ErrPrecision = errors.New("money: amount exceeds currency precision")
+	// ErrDivisionByZero is returned when dividing by zero.
+	ErrDivisionByZero = errors.New("money: division by zero")
+	// ErrInvalidAllocation is matched by errors of invalid allocation ratios.
+	ErrInvalidAllocation = errors.New("money: invalid allocation")
+)
+
+// Money is an amount in a currency. The zero value has no currency and is
+// rejected by arithmetic; build values with New, Parse, FromMinor or ZeroOf.
+type Money struct {
+	amount   shopspring.Decimal
+	currency Currency
+}
+
+// New returns amount in the currency of code. Amounts with more decimal
+// places than the currency minor units fail with ErrPrecision; use
+// NewRounded to round them instead.
+func New(amount shopspring.Decimal, code string) (Money, error) {
+	currency, err := LookupCurrency(code)
+	if err != nil {
+		return Money{}, err
+	}
+	if decimalPlaces(amount) > currency.MinorUnits {
+		return Money{}, precisionError(amount, currency)
+	}
+	return Money{amount: amount, currency: currency}, nil
+}
+
+// NewRounded returns amount rounded with mode to the minor units of the
+// currency of code.
+func NewRounded(amount shopspring.Decimal, code string, mode RoundingMode) (Money, error) {
+	currency, err := LookupCurrency(code)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{amount: Round(amount, currency.MinorUnits, mode), currency: currency}, nil
+}
+
+// Parse parses amount in the currency of code with the parsers/decimal
+// parser, so opts may set the locale of the input: Parse("R$ 1.234,56", "BRL",
+// decimal.WithLocale(decimal.LocalePtBR)). The input defaults to en-US, as in
+// "10.50" or "1,234.56"; a currency written in it must be the one of code.
+func Parse(amount, code string, opts ...decimal.Option) (Money, error) {
+	currency, err := LookupCurrency(code)
+	if err != nil {
+		return Money{}, err
+	}
+	opts = append(opts[:len(opts):len(opts)], decimal.WithCurrencies(currency.Code))
+	parsed, err := decimal.NewParser(opts...).ParseString(context.Background(), amount)
+	if err != nil {
+		return Money{}, invalidAmount("invalid amount", err)
+	}
+	return New(parsed.Value, currency.Code)
+}
+
+// FromParsed converts the result of decimal.ParseMoney.
+func FromParsed(m decimal.Money) (Money, error) {
+	return New(m.Amount, m.Currency)
+}
+
+// MustParse is like Parse but panics on error. It is meant for constants in
+// code and tests.
+func MustParse(amount, code string, opts ...decimal.Option) Money {
+	m, err := Parse(amount, code, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// FromMinor returns the amount of units minor units, such as cents, in the
+// currency of code: FromMinor(1050, "BRL") is 10.50 BRL.
+func FromMinor(units int64, code string) (Money, error) {
+	currency, err := LookupCurrency(code)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{amount: shopspring.New(units, -currency.MinorUnits), currency: currency}, nil
+}
+
+// ZeroOf returns zero in the currency of code.
+func ZeroOf(code string) (Money, error) {
+	return FromMinor(0, code)
+}
+
+// Amount returns the amount.
+func (m Money) Amount() shopspring.Decimal {
+	return m.amount
+}
+
+// Currency returns the currency.
+func (m Money) Currency() Currency {
+	return m.currency
+}
+
+// MinorUnits returns the amount in minor units, such as cents. It fails when
+// the amount does not fit an int64.
+func (m Money) MinorUnits() (int64, error) {
+	units := m.amount.Shift(m.currency.MinorUnits).BigInt()
+	if !units.IsInt64() {
+		return 0, invalidAmount(fmt.Sprintf("amount %s overflows minor units", m), nil)
+	}
+	return units.Int64(), nil
+}
+
+// Add returns m + other. Both must have the same currency.
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	return Money{amount: m.amount.Add(other.amount), currency: m.currency}, nil
+}
+
+// Sub returns m - other. Both must have the same currency.
+func (m Money) Sub(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	return Money{amount: m.amount.Sub(other.amount), currency: m.currency}, nil
+}
+
+// Mul returns m × factor, rounded with mode to the minor units, for rates,
+// taxes and exchange conversions of the same currency.
+func (m Money) Mul(factor shopspring.Decimal, mode RoundingMode) Money {
+	return Money{amount: Round(m.amount.Mul(factor), m.currency.MinorUnits, mode), currency: m.currency}
+}
+
+// Div returns m ÷ divisor, rounded with mode to the minor units. Use Split or
+// Allocate to divide an amount into parts that add up to it.
+func (m Money) Div(divisor shopspring.Decimal, mode RoundingMode) (Money, error) {
+	amount, err := div(m.amount, divisor, m.currency.MinorUnits, mode)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{amount: amount, currency: m.currency}, nil
+}
+
+// Allocate splits m in proportion to ratios, without losing or creating minor
+// units: the parts always add up to m. The minor units left over by rounding
+// go, one each, to the first parts. Allocate([]int64{70, 30}) of 100.01
+// gives 70.01 and 30.00.
+func (m Money) Allocate(ratios ...int64) ([]Money, error) {
+	if len(ratios) == 0 {
+		return nil, invalidAllocation("at least one ratio is required")
+	}
+	total := new(big.Int)
+	for _, r := range ratios {
+		if r < 0 {
+			return nil, invalidAllocation("ratios must not be negative")
+		}
+		total.Add(total, big.NewInt(r))
+	}
+	if total.Sign() == 0 {
+		return nil, invalidAllocation("ratios must not all be zero")
+	}
+
+	units := m.amount.Shift(m.currency.MinorUnits).BigInt()
+	remainder := new(big.Int).Set(units)
+	shares := make([]*big.Int, len(ratios))
+	for i, r := range ratios {
+		share := new(big.Int).Mul(units, big.NewInt(r))
+		share.Quo(share, total)
+		shares[i] = share
+		remainder.Sub(remainder, share)
+	}
+
+	// |remainder| < len(ratios): hand out one unit per part, skipping parts
+	// with a zero ratio.
+	step := big.NewInt(int64(remainder.Sign()))
+	for i := 0; remainder.Sign() != 0; i = (i + 1) % len(ratios) {
+		if ratios[i] == 0 {
+			continue
+		}
+		shares[i].Add(shares[i], step)
+		remainder.Sub(remainder, step)
+	}
+
+	parts := make([]Money, len(shares))
+	for i, share := range shares {
+		parts[i] = Money{
+			amount:   shopspring.NewFromBigInt(share, -m.currency.MinorUnits),
+			currency: m.currency,
+		}
+	}
+	return parts, nil
+}
+
+// Split divides m into n parts differing by at most one minor unit, the
+// larger parts first: 10.00 split in 3 gives 3.34, 3.33 and 3.33.
+func (m Money) Split(n int) ([]Money, error) {
+	if n <= 0 {
+		return nil, invalidAllocation("the number of parts must be positive")
+	}
+	ratios := make([]int64, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+	return m.Allocate(ratios...)
+}
+
+// Neg returns -m.
+func (m Money) Neg() Money {
+	return Money{amount: m.amount.Neg(), currency: m.currency}
+}
+
+// Abs returns |m|.
+func (m Money) Abs() Money {
+	return Money{amount: m.amount.Abs(), currency: m.currency}
+}
+
+// Cmp compares m with other, which must have the same currency.
+func (m Money) Cmp(other Money) (int, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return 0, err
+	}
+	return m.amount.Cmp(other.amount), nil
+}
+
+// Equal reports whether m and other have the same currency and amount.
+func (m Money) Equal(other Money) bool {
+	return m.currency.Code == other.currency.Code && m.amount.Equal(other.amount)
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of the amount.
+func (m Money) Sign() int {
+	return m.amount.Sign()
+}
+
+// IsZero reports whether the amount is 0.
+func (m Money) IsZero() bool {
+	return m.amount.IsZero()
+}
+
+// IsPositive reports whether the amount is greater than 0.
+func (m Money) IsPositive() bool {
+	return m.amount.IsPositive()
+}
+
+// IsNegative reports whether the amount is less than 0.
+func (m Money) IsNegative() bool {
+	return m.amount.IsNegative()
+}
+
+// String returns the amount with the currency minor units and the code, as
+// in "10.50 BRL".
+func (m Money) String() string {
+	return m.amount.StringFixed(m.currency.MinorUnits) + " " + m.currency.Code
+}
+
+// moneyJSON is the JSON form of Money.
+type moneyJSON struct {
+	Amount   *shopspring.Decimal `json:"amount"`
+	Currency string              `json:"currency"`
+}
+
+// MarshalJSON encodes m as {"amount":"10.50","currency":"BRL"}, with the
+// amount written with the currency minor units.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}{m.amount.StringFixed(m.currency.MinorUnits), m.currency.Code})
+}
+
+// UnmarshalJSON decodes {"amount":"10.50","currency":"BRL"}; the amount may
+// also be a JSON number. Unknown currencies and amounts finer than the minor
+// unit are rejected.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var raw moneyJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return invalidAmount("invalid money value", err)
+	}
+	if raw.Amount == nil {
+		return invalidAmount("amount is required", nil)
+	}
+	parsed, err := New(*raw.Amount, raw.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// sameCurrency checks that m and other can be combined.
+func (m Money) sameCurrency(other Money) error {
+	if m.currency.IsZero() || other.currency.IsZero() {
+		return unknownCurrency("")
+	}
+	if m.currency.Code != other.currency.Code {
+		return domainerrors.New(interfaces.BusinessError, "CURRENCY_MISMATCH",
+			fmt.Sprintf("cannot combine %s with %s", m.currency.Code, other.currency.Code)).
+			WithMetadata("currency", m.currency.Code).
+			WithMetadata("other_currency", other.currency.Code).
+			Wrap(ErrCurrencyMismatch)
+	}
+	return nil
+}
+
+// invalidAmount returns the Validation domain error of a malformed amount.
+// The error matches both ErrInvalidAmount and cause, such as the sentinels of
+// parsers/decimal.
+func invalidAmount(message string, cause error) error {
+	wrapped := ErrInvalidAmount
+	if cause != nil {
+		message = fmt.Sprintf("%s: %v", message, cause)
+		wrapped = errors.Join(ErrInvalidAmount, cause)
+	}
+	return domainerrors.New(interfaces.ValidationError, "INVALID_AMOUNT", message).
+		WithMetadata("field", "amount").Wrap(wrapped)
+}
+
+// unknownCurrency returns the Validation domain error of an unknown code.
+func unknownCurrency(code string) error {
+	return domainerrors.New(interfaces.ValidationError, "UNKNOWN_CURRENCY",
+		fmt.Sprintf("unknown currency %q", code)).
+		WithMetadata("field", "currency").Wrap(ErrUnknownCurrency)
+}
+
+// precisionError returns the Validation domain error of an amount finer than
+// the minor unit of currency.
+func precisionError(amount shopspring.Decimal, currency Currency) error {
+	return domainerrors.New(interfaces.ValidationError, "AMOUNT_PRECISION",
+		fmt.Sprintf("amount %s has more than %d decimal places for %s", amount, currency.MinorUnits, currency.Code)).
+		WithMetadata("field", "amount").
+		WithMetadata("minor_units", currency.MinorUnits).
+		Wrap(ErrPrecision)
+}
+
+// invalidAllocation returns the Validation domain error of bad ratios.
+func invalidAllocation(message string) error {
+	return domainerrors.New(interfaces.ValidationError, "INVALID_ALLOCATION", message).
+		Wrap(ErrInvalidAllocation)
+}
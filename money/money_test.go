@@ -0,0 +1,278 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	shopspring "github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/parsers/decimal"
+	"github.com/fsvxavier/nexs-lib/validation/validator"
+)
+
+func TestRound(t *testing.T) {
+	cases := []struct {
+		value string
+		mode  RoundingMode
+		want  string
+	}{
+		{"2.345", RoundHalfUp, "2.35"},
+		{"-2.345", RoundHalfUp, "-2.35"},
+		{"2.345", RoundHalfDown, "2.34"},
+		{"2.3451", RoundHalfDown, "2.35"},
+		{"2.345", RoundHalfEven, "2.34"},
+		{"2.355", RoundHalfEven, "2.36"},
+		{"-2.355", RoundHalfEven, "-2.36"},
+		{"2.341", RoundUp, "2.35"},
+		{"-2.341", RoundUp, "-2.35"},
+		{"2.349", RoundDown, "2.34"},
+		{"-2.349", RoundDown, "-2.34"},
+		{"-2.349", RoundCeiling, "-2.34"},
+		{"2.341", RoundCeiling, "2.35"},
+		{"2.349", RoundFloor, "2.34"},
+		{"-2.341", RoundFloor, "-2.35"},
+		{"2.34", RoundUp, "2.34"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.value+"/"+tc.mode.String(), func(t *testing.T) {
+			got := Round(shopspring.RequireFromString(tc.value), 2, tc.mode)
+			assert.True(t, shopspring.RequireFromString(tc.want).Equal(got), "got %s", got)
+		})
+	}
+
+	assert.Equal(t, "1200", Round(shopspring.NewFromInt(1250), -2, RoundHalfEven).String())
+	assert.Equal(t, "1300", Round(shopspring.NewFromInt(1250), -2, RoundHalfUp).String())
+}
+
+func TestDiv(t *testing.T) {
+	d, err := div(shopspring.NewFromInt(10), shopspring.NewFromInt(3), 2, RoundHalfUp)
+	require.NoError(t, err)
+	assert.Equal(t, "3.33", d.String())
+
+	d, err = div(shopspring.NewFromInt(-10), shopspring.NewFromInt(3), 2, RoundFloor)
+	require.NoError(t, err)
+	assert.Equal(t, "-3.34", d.String())
+
+	// 0.125 is an exact tie at two places.
+	d, err = div(shopspring.NewFromInt(1), shopspring.NewFromInt(8), 2, RoundHalfEven)
+	require.NoError(t, err)
+	assert.Equal(t, "0.12", d.String())
+	d, err = div(shopspring.NewFromInt(1), shopspring.NewFromInt(-8), 2, RoundHalfUp)
+	require.NoError(t, err)
+	assert.Equal(t, "-0.13", d.String())
+
+	_, err = div(shopspring.NewFromInt(1), shopspring.Zero, 2, RoundHalfUp)
+	assert.ErrorIs(t, err, ErrDivisionByZero)
+}
+
+func TestDecimalPlaces(t *testing.T) {
+	assert.Equal(t, int32(1), decimalPlaces(shopspring.RequireFromString("10.50")))
+	assert.Equal(t, int32(0), decimalPlaces(shopspring.NewFromInt(100)))
+	assert.Equal(t, int32(3), decimalPlaces(shopspring.RequireFromString("-0.001")))
+}
+
+func TestCurrency(t *testing.T) {
+	brl, err := LookupCurrency("brl")
+	require.NoError(t, err)
+	assert.Equal(t, Currency{Code: "BRL", Numeric: "986", Name: "Brazilian Real", MinorUnits: 2}, brl)
+	assert.Equal(t, int32(0), MustCurrency("JPY").MinorUnits)
+	assert.Equal(t, int32(3), MustCurrency("KWD").MinorUnits)
+
+	_, err = LookupCurrency("XXX")
+	assert.ErrorIs(t, err, ErrUnknownCurrency)
+
+	require.NoError(t, RegisterCurrency(Currency{Code: "pts", Name: "Loyalty Points", MinorUnits: 0}))
+	assert.Equal(t, "Loyalty Points", MustCurrency("PTS").Name)
+	assert.Error(t, RegisterCurrency(Currency{Code: "TOOLONG"}))
+	assert.Error(t, RegisterCurrency(Currency{Code: "NEG", MinorUnits: -1}))
+
+	list := Currencies()
+	assert.Equal(t, "AED", list[0].Code)
+}
+
+func TestMoney_New(t *testing.T) {
+	m, err := Parse("10.5", "BRL")
+	require.NoError(t, err)
+	assert.Equal(t, "10.50 BRL", m.String())
+
+	_, err = Parse("10.555", "BRL")
+	assert.ErrorIs(t, err, ErrPrecision)
+	_, err = Parse("10.5", "JPY")
+	assert.ErrorIs(t, err, ErrPrecision)
+
+	m, err = NewRounded(shopspring.RequireFromString("10.555"), "BRL", RoundHalfEven)
+	require.NoError(t, err)
+	assert.Equal(t, "10.56 BRL", m.String())
+
+	m, err = FromMinor(1050, "KWD")
+	require.NoError(t, err)
+	assert.Equal(t, "1.050 KWD", m.String())
+	units, err := m.MinorUnits()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1050), units)
+
+	_, err = Parse("1", "ABC")
+	assert.ErrorIs(t, err, ErrUnknownCurrency)
+}
+
+func TestMoney_Parse(t *testing.T) {
+	m, err := Parse("1,234.5", "usd")
+	require.NoError(t, err)
+	assert.Equal(t, "1234.50 USD", m.String())
+
+	m, err = Parse("R$ 1.234,56", "BRL", decimal.WithLocale(decimal.LocalePtBR))
+	require.NoError(t, err)
+	assert.Equal(t, "1234.56 BRL", m.String())
+
+	_, err = Parse("€ 10", "BRL")
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+	assert.ErrorIs(t, err, decimal.ErrUnsupportedCurrency)
+
+	_, err = Parse("ten", "BRL")
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+	assert.ErrorIs(t, err, decimal.ErrInvalidDecimal)
+	var domainErr *domainerrors.DomainError
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, "INVALID_AMOUNT", domainErr.Code())
+
+	parsed, err := decimal.ParseMoney("1.234,5", decimal.WithLocale(decimal.LocaleDeDE))
+	require.NoError(t, err)
+	m, err = FromParsed(parsed)
+	require.NoError(t, err)
+	assert.Equal(t, "1234.50 EUR", m.String())
+}
+
+func TestMoney_Arithmetic(t *testing.T) {
+	a := MustParse("10.10", "USD")
+	b := MustParse("0.20", "USD")
+
+	sum, err := a.Add(b)
+	require.NoError(t, err)
+	assert.Equal(t, "10.30 USD", sum.String())
+
+	diff, err := b.Sub(a)
+	require.NoError(t, err)
+	assert.Equal(t, "-9.90 USD", diff.String())
+	assert.True(t, diff.IsNegative())
+	assert.Equal(t, "9.90 USD", diff.Abs().String())
+	assert.Equal(t, "9.90 USD", diff.Neg().String())
+
+	cmp, err := a.Cmp(b)
+	require.NoError(t, err)
+	assert.Equal(t, 1, cmp)
+	assert.True(t, a.Equal(MustParse("10.1", "USD")))
+	assert.False(t, a.Equal(MustParse("10.10", "EUR")))
+
+	// 7.5% tax on 10.10 is 0.7575.
+	tax := a.Mul(shopspring.RequireFromString("0.075"), RoundHalfEven)
+	assert.Equal(t, "0.76 USD", tax.String())
+
+	third, err := a.Div(shopspring.NewFromInt(3), RoundDown)
+	require.NoError(t, err)
+	assert.Equal(t, "3.36 USD", third.String())
+	_, err = a.Div(shopspring.Zero, RoundDown)
+	assert.ErrorIs(t, err, ErrDivisionByZero)
+
+	_, err = a.Add(MustParse("1", "EUR"))
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+	var domainErr *domainerrors.DomainError
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, "CURRENCY_MISMATCH", domainErr.Code())
+
+	_, err = a.Add(Money{})
+	assert.ErrorIs(t, err, ErrUnknownCurrency)
+}
+
+func TestMoney_Allocate(t *testing.T) {
+	amounts := func(parts []Money) []string {
+		out := make([]string, len(parts))
+		for i, p := range parts {
+			out[i] = p.Amount().StringFixed(p.Currency().MinorUnits)
+		}
+		return out
+	}
+
+	parts, err := MustParse("100.01", "BRL").Allocate(70, 30)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"70.01", "30.00"}, amounts(parts))
+
+	parts, err = MustParse("10.00", "BRL").Split(3)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"3.34", "3.33", "3.33"}, amounts(parts))
+
+	parts, err = MustParse("-10.00", "BRL").Split(3)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"-3.34", "-3.33", "-3.33"}, amounts(parts))
+
+	parts, err = MustParse("5", "JPY").Allocate(0, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0", "3", "2"}, amounts(parts))
+
+	_, err = MustParse("1", "BRL").Allocate()
+	assert.ErrorIs(t, err, ErrInvalidAllocation)
+	_, err = MustParse("1", "BRL").Allocate(0, 0)
+	assert.ErrorIs(t, err, ErrInvalidAllocation)
+	_, err = MustParse("1", "BRL").Allocate(1, -1)
+	assert.ErrorIs(t, err, ErrInvalidAllocation)
+	_, err = MustParse("1", "BRL").Split(0)
+	assert.ErrorIs(t, err, ErrInvalidAllocation)
+}
+
+func TestMoney_JSON(t *testing.T) {
+	data, err := json.Marshal(MustParse("10.5", "BRL"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"amount":"10.50","currency":"BRL"}`, string(data))
+
+	var m Money
+	require.NoError(t, json.Unmarshal([]byte(`{"amount":19.9,"currency":"usd"}`), &m))
+	assert.Equal(t, "19.90 USD", m.String())
+
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"amount":"1.001","currency":"USD"}`), &m), ErrPrecision)
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"amount":"1","currency":"ZZZ"}`), &m), ErrUnknownCurrency)
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"currency":"USD"}`), &m), ErrInvalidAmount)
+}
+
+type paymentRequest struct {
+	Total    Money              `json:"total" validate:"amount_positive,amount_max=1000"`
+	Fee      shopspring.Decimal `json:"fee" validate:"amount_min=0,amount_precision=BRL"`
+	Tip      string             `json:"tip" validate:"amount_precision=JPY"`
+	Currency string             `json:"currency" validate:"required,currency"`
+}
+
+func TestValidationRules(t *testing.T) {
+	valid := paymentRequest{
+		Total:    MustParse("99.90", "BRL"),
+		Fee:      shopspring.RequireFromString("1.5"),
+		Tip:      "10",
+		Currency: "BRL",
+	}
+	assert.NoError(t, validator.ValidateStruct(valid))
+
+	invalid := paymentRequest{
+		Total:    MustParse("1000.01", "BRL"),
+		Fee:      shopspring.RequireFromString("-0.001"),
+		Tip:      "1.5",
+		Currency: "REAL",
+	}
+	err := validator.ValidateStruct(invalid)
+	var errs validator.ValidationErrors
+	require.True(t, errors.As(err, &errs), "got %v", err)
+
+	rules := map[string][]string{}
+	for _, fe := range errs {
+		rules[fe.Field] = append(rules[fe.Field], fe.Rule)
+	}
+	assert.Equal(t, []string{"amount_max"}, rules["total"])
+	assert.Equal(t, []string{"amount_min", "amount_precision"}, rules["fee"])
+	assert.Equal(t, []string{"amount_precision"}, rules["tip"])
+	assert.Equal(t, []string{"currency"}, rules["currency"])
+	assert.Equal(t, "must have at most 2 decimal places", errs.ByField("fee")[1].Message)
+
+	assert.Error(t, validator.ValidateStruct(struct {
+		Total Money `validate:"amount_positive"`
+	}{MustParse("-1", "USD")}))
+}
@@ -0,0 +1,116 @@
+package money
+
+import (
+	"fmt"
+
+	shopspring "github.com/shopspring/decimal"
+)
+
+// RoundingMode selects how results are rounded to a number of places.
+type RoundingMode int
+
+const (
+	// RoundHalfUp rounds ties away from zero (2.5 → 3, -2.5 → -3).
+	RoundHalfUp RoundingMode = iota
+	// RoundHalfDown rounds ties towards zero (2.5 → 2, -2.5 → -2).
+	RoundHalfDown
+	// RoundHalfEven rounds ties to the even neighbour (2.5 → 2, 3.5 → 4),
+	// also known as banker's rounding.
+	RoundHalfEven
+	// RoundUp rounds away from zero (2.1 → 3, -2.1 → -3).
+	RoundUp
+	// RoundDown truncates towards zero (2.9 → 2, -2.9 → -2).
+	RoundDown
+	// RoundCeiling rounds towards positive infinity (2.1 → 3, -2.9 → -2).
+	RoundCeiling
+	// RoundFloor rounds towards negative infinity (2.9 → 2, -2.1 → -3).
+	RoundFloor
+)
+
+var roundingModeNames = map[RoundingMode]string{
+	RoundHalfUp:   "half_up",
+	RoundHalfDown: "half_down",
+	RoundHalfEven: "half_even",
+	RoundUp:       "up",
+	RoundDown:     "down",
+	RoundCeiling:  "ceiling",
+	RoundFloor:    "floor",
+}
+
+func (m RoundingMode) String() string {
+	if name, ok := roundingModeNames[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("RoundingMode(%d)", int(m))
+}
+
+// Round returns d rounded to places decimal places with mode. Negative places
+// round to tens, hundreds and so on.
+func Round(d shopspring.Decimal, places int32, mode RoundingMode) shopspring.Decimal {
+	truncated := d.RoundDown(places)
+	rest := d.Sub(truncated)
+	if rest.IsZero() {
+		return truncated
+	}
+	half := rest.Abs().Shift(places).Mul(shopspring.NewFromInt(2)).Cmp(shopspring.NewFromInt(1))
+	return roundTruncated(truncated, d.Sign() < 0, half, places, mode)
+}
+
+// div returns d ÷ divisor rounded to places decimal places with mode.
+func div(d, divisor shopspring.Decimal, places int32, mode RoundingMode) (shopspring.Decimal, error) {
+	if divisor.IsZero() {
+		return shopspring.Decimal{}, ErrDivisionByZero
+	}
+	q, r := d.QuoRem(divisor, places)
+	if r.IsZero() {
+		return q, nil
+	}
+	// The remainder is below |divisor| units of the last place; comparing
+	// twice the remainder with it tells whether the dropped part is below,
+	// at or above half a unit.
+	unit := divisor.Abs().Shift(-places)
+	half := r.Abs().Mul(shopspring.NewFromInt(2)).Cmp(unit)
+	negative := d.Sign()*divisor.Sign() < 0
+	return roundTruncated(q, negative, half, places, mode), nil
+}
+
+// roundTruncated finishes rounding an inexact result. truncated is the result
+// cut towards zero at places, negative its sign and half the comparison of the
+// dropped part with half a unit of the last place.
+func roundTruncated(truncated shopspring.Decimal, negative bool, half int, places int32, mode RoundingMode) shopspring.Decimal {
+	var away bool
+	switch mode {
+	case RoundHalfUp:
+		away = half >= 0
+	case RoundHalfDown:
+		away = half > 0
+	case RoundHalfEven:
+		away = half > 0 || (half == 0 && truncated.Shift(places).BigInt().Bit(0) == 1)
+	case RoundUp:
+		away = true
+	case RoundDown:
+		away = false
+	case RoundCeiling:
+		away = !negative
+	case RoundFloor:
+		away = negative
+	}
+	if !away {
+		return truncated
+	}
+	unit := shopspring.New(1, -places)
+	if negative {
+		return truncated.Sub(unit)
+	}
+	return truncated.Add(unit)
+}
+
+// decimalPlaces returns the number of decimal places d is written with,
+// ignoring trailing zeros: 10.50 has 1.
+func decimalPlaces(d shopspring.Decimal) int32 {
+	places := int32(0)
+	for !d.Equal(d.Truncate(places)) {
+		places++
+	}
+	return places
+}
@@ -0,0 +1,142 @@
+package money
+
+import (
+	"fmt"
+	"reflect"
+
+	shopspring "github.com/shopspring/decimal"
+
+	"github.com/fsvxavier/nexs-lib/parsers/decimal"
+	"github.com/fsvxavier/nexs-lib/validation/validator"
+)
+
+func init() {
+	validator.RegisterRule("currency", func(string) (*validator.Rule, error) {
+		return CurrencyCode(), nil
+	})
+	validator.RegisterRule("amount_positive", func(string) (*validator.Rule, error) {
+		return PositiveAmount(), nil
+	})
+	validator.RegisterRule("amount_min", decimalParam(MinAmount))
+	validator.RegisterRule("amount_max", decimalParam(MaxAmount))
+	validator.RegisterRule("amount_precision", func(param string) (*validator.Rule, error) {
+		if param != "" {
+			if _, err := LookupCurrency(param); err != nil {
+				return nil, err
+			}
+		}
+		return AmountPrecision(param), nil
+	})
+}
+
+// CurrencyCode validates that a string is a registered currency code.
+// Tag: validate:"currency".
+func CurrencyCode() *validator.Rule {
+	return validator.NewRule("currency", "must be a valid ISO 4217 currency code", func(fc *validator.FieldContext) bool {
+		code, ok := fc.Interface().(string)
+		if !ok {
+			return false
+		}
+		_, err := LookupCurrency(code)
+		return err == nil
+	})
+}
+
+// PositiveAmount validates that a Money, shopspring Decimal, decimal string or
+// number is greater than zero. Tag: validate:"amount_positive".
+func PositiveAmount() *validator.Rule {
+	return validator.NewRule("amount_positive", "must be a positive amount", func(fc *validator.FieldContext) bool {
+		amount, _, ok := amountOf(fc.Value)
+		return ok && amount.IsPositive()
+	})
+}
+
+// MinAmount validates that the amount is at least min.
+// Tag: validate:"amount_min=0.01".
+func MinAmount(min shopspring.Decimal) *validator.Rule {
+	return validator.NewRule("amount_min", "must be at least {min}", func(fc *validator.FieldContext) bool {
+		amount, _, ok := amountOf(fc.Value)
+		return ok && amount.Cmp(min) >= 0
+	}).WithParam("min", min.String())
+}
+
+// MaxAmount validates that the amount is at most max.
+// Tag: validate:"amount_max=10000".
+func MaxAmount(max shopspring.Decimal) *validator.Rule {
+	return validator.NewRule("amount_max", "must be at most {max}", func(fc *validator.FieldContext) bool {
+		amount, _, ok := amountOf(fc.Value)
+		return ok && amount.Cmp(max) <= 0
+	}).WithParam("max", max.String())
+}
+
+// AmountPrecision validates that the amount has no more decimal places than
+// the minor units of its currency: the currency of a Money value, or code for
+// Decimal, string and numeric fields. Tags: validate:"amount_precision" for
+// Money, validate:"amount_precision=BRL" otherwise.
+func AmountPrecision(code string) *validator.Rule {
+	rule := validator.NewRule("amount_precision", "must have at most {minor_units} decimal places", func(fc *validator.FieldContext) bool {
+		amount, currency, ok := amountOf(fc.Value)
+		if !ok {
+			return false
+		}
+		if currency.IsZero() {
+			c, err := LookupCurrency(code)
+			if err != nil {
+				return false
+			}
+			currency = c
+		}
+		return decimalPlaces(amount) <= currency.MinorUnits
+	})
+	if c, err := LookupCurrency(code); err == nil {
+		rule = rule.WithParam("minor_units", c.MinorUnits)
+	} else {
+		rule = rule.WithParam("minor_units", "the currency")
+	}
+	return rule
+}
+
+// decimalParam adapts a rule taking a decimal for use in struct tags.
+func decimalParam(fn func(shopspring.Decimal) *validator.Rule) validator.RuleFactory {
+	return func(param string) (*validator.Rule, error) {
+		d, err := decimal.ParseDecimal(param)
+		if err != nil {
+			return nil, fmt.Errorf("invalid amount parameter %q: %w", param, err)
+		}
+		return fn(d), nil
+	}
+}
+
+// amountOf extracts the amount, and the currency for Money, of a field.
+func amountOf(v reflect.Value) (shopspring.Decimal, Currency, bool) {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return shopspring.Decimal{}, Currency{}, false
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() || !v.CanInterface() {
+		return shopspring.Decimal{}, Currency{}, false
+	}
+
+	switch value := v.Interface().(type) {
+	case Money:
+		return value.amount, value.currency, true
+	case shopspring.Decimal:
+		return value, Currency{}, true
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		d, err := decimal.ParseDecimal(v.String())
+		return d, Currency{}, err == nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return shopspring.NewFromInt(v.Int()), Currency{}, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return shopspring.NewFromUint64(v.Uint()), Currency{}, true
+	case reflect.Float32, reflect.Float64:
+		return shopspring.NewFromFloat(v.Float()), Currency{}, true
+	default:
+		return shopspring.Decimal{}, Currency{}, false
+	}
+}
@@ -0,0 +1,492 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	awsxray "go.opentelemetry.io/contrib/propagators/aws/xray"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.28.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/fsvxavier/nexs-lib/observability/logger"
+	_ "github.com/fsvxavier/nexs-lib/observability/logger/providers/slog"
+	"github.com/fsvxavier/nexs-lib/observability/metrics"
+	tracerconfig "github.com/fsvxavier/nexs-lib/observability/tracer/config"
+	tracerinterfaces "github.com/fsvxavier/nexs-lib/observability/tracer/interfaces"
+	"github.com/fsvxavier/nexs-lib/observability/tracer/providers/file"
+	"github.com/fsvxavier/nexs-lib/observability/tracer/providers/grafana"
+	"github.com/fsvxavier/nexs-lib/observability/tracer/providers/memory"
+	"github.com/fsvxavier/nexs-lib/observability/tracer/providers/newrelic"
+	"github.com/fsvxavier/nexs-lib/observability/tracer/providers/noop"
+	"github.com/fsvxavier/nexs-lib/observability/tracer/providers/opentelemetry"
+	"github.com/fsvxavier/nexs-lib/observability/tracer/providers/xray"
+)
+
+// Nomes dos componentes registrados por Init no coordenador
+const (
+	ComponentTracer  = "observability.tracer"
+	ComponentMetrics = "observability.metrics"
+	ComponentLogger  = "observability.logger"
+)
+
+// DefaultMetricsExportInterval intervalo padrão de exportação OTLP das métricas
+const DefaultMetricsExportInterval = time.Minute
+
+// Config configura tracer, métricas, logger e propagadores em uma única
+// estrutura. As chaves json permitem carregá-la com o pacote config
+// (arquivos e variáveis de ambiente) ou com ConfigFromEnv
+type Config struct {
+	// ServiceName, ServiceVersion e Environment identificam o serviço em
+	// traces, métricas e logs, substituindo os valores de Tracer
+	ServiceName    string `json:"service_name" yaml:"service_name"`
+	ServiceVersion string `json:"service_version" yaml:"service_version"`
+	Environment    string `json:"environment" yaml:"environment"`
+
+	// Tracer configura o tracer provider; ExporterType vazio desativa a
+	// exportação (noop)
+	Tracer tracerinterfaces.Config `json:"tracer" yaml:"tracer"`
+
+	// Metrics configura o meter provider
+	Metrics MetricsConfig `json:"metrics" yaml:"metrics"`
+
+	// Logger configura o logger global
+	Logger LoggerConfig `json:"logger" yaml:"logger"`
+
+	// Propagators são os propagadores globais: tracecontext, baggage e xray.
+	// Vazio usa tracecontext e baggage
+	Propagators []string `json:"propagators" yaml:"propagators"`
+
+	// ShutdownTimeout é o timeout de finalização de cada componente; zero
+	// usa o padrão do coordenador
+	ShutdownTimeout time.Duration `json:"shutdown_timeout" yaml:"shutdown_timeout"`
+}
+
+// MetricsConfig configura o meter provider. As métricas são sempre expostas
+// no Handler de Telemetry.Metrics; com Endpoint também são exportadas via OTLP
+type MetricsConfig struct {
+	// Endpoint é a URL OTLP/HTTP das métricas, como
+	// http://collector:4318/v1/metrics; vazio desativa a exportação
+	Endpoint string            `json:"endpoint" yaml:"endpoint"`
+	Headers  map[string]string `json:"headers" yaml:"headers"`
+	Insecure bool              `json:"insecure" yaml:"insecure"`
+	// ExportInterval é o intervalo de exportação OTLP
+	ExportInterval time.Duration `json:"export_interval" yaml:"export_interval"`
+}
+
+// LoggerConfig configura o logger global
+type LoggerConfig struct {
+	// Provider é o provider registrado no logger (slog, zap, logrus ou
+	// zerolog); os demais que não o slog precisam ser importados pela
+	// aplicação para se registrarem
+	Provider  string `json:"provider" yaml:"provider"`
+	Level     string `json:"level" yaml:"level"`
+	Format    string `json:"format" yaml:"format"`
+	AddSource bool   `json:"add_source" yaml:"add_source"`
+}
+
+// DefaultConfig retorna a configuração padrão: tracing desativado, métricas
+// apenas expostas e logs JSON em nível info pelo slog
+func DefaultConfig() Config {
+	tracer := tracerconfig.DefaultConfig()
+	tracer.ExporterType = "noop"
+	tracer.Propagators = nil
+	return Config{
+		Environment: "development",
+		Tracer:      tracer,
+		Metrics:     MetricsConfig{ExportInterval: DefaultMetricsExportInterval},
+		Logger:      LoggerConfig{Provider: "slog", Level: "info", Format: string(logger.JSONFormat)},
+		Propagators: []string{"tracecontext", "baggage"},
+	}
+}
+
+// ConfigFromEnv carrega a configuração das variáveis de ambiente, sobre
+// DefaultConfig:
+//
+//   - SERVICE_NAME, SERVICE_VERSION e ENVIRONMENT
+//   - TRACER_*, as mesmas do tracer; sem TRACER_EXPORTER_TYPE o tracing fica desativado
+//   - METRICS_ENDPOINT, METRICS_INSECURE, METRICS_EXPORT_INTERVAL e METRICS_HEADER_*
+//   - LOG_PROVIDER, LOG_LEVEL, LOG_FORMAT e LOG_ADD_SOURCE
+//   - PROPAGATORS (CSV) e SHUTDOWN_TIMEOUT
+func ConfigFromEnv() Config {
+	cfg := DefaultConfig()
+	cfg.ServiceName = os.Getenv("SERVICE_NAME")
+	cfg.ServiceVersion = os.Getenv("SERVICE_VERSION")
+	if environment := os.Getenv("ENVIRONMENT"); environment != "" {
+		cfg.Environment = environment
+	}
+
+	cfg.Tracer = tracerconfig.LoadFromEnv()
+	if os.Getenv("TRACER_EXPORTER_TYPE") == "" {
+		cfg.Tracer.ExporterType = "noop"
+	}
+
+	cfg.Metrics.Endpoint = os.Getenv("METRICS_ENDPOINT")
+	if insecure, err := strconv.ParseBool(os.Getenv("METRICS_INSECURE")); err == nil {
+		cfg.Metrics.Insecure = insecure
+	}
+	if interval, err := time.ParseDuration(os.Getenv("METRICS_EXPORT_INTERVAL")); err == nil {
+		cfg.Metrics.ExportInterval = interval
+	}
+	for _, env := range os.Environ() {
+		if key, value, ok := strings.Cut(env, "="); ok && strings.HasPrefix(key, "METRICS_HEADER_") {
+			if cfg.Metrics.Headers == nil {
+				cfg.Metrics.Headers = make(map[string]string)
+			}
+			name := strings.ToLower(strings.ReplaceAll(strings.TrimPrefix(key, "METRICS_HEADER_"), "_", "-"))
+			cfg.Metrics.Headers[name] = value
+		}
+	}
+
+	if provider := os.Getenv("LOG_PROVIDER"); provider != "" {
+		cfg.Logger.Provider = provider
+	}
+	if level := os.Getenv("LOG_LEVEL"); level != "" {
+		cfg.Logger.Level = level
+	}
+	if format := os.Getenv("LOG_FORMAT"); format != "" {
+		cfg.Logger.Format = strings.ToLower(format)
+	}
+	if addSource, err := strconv.ParseBool(os.Getenv("LOG_ADD_SOURCE")); err == nil {
+		cfg.Logger.AddSource = addSource
+	}
+
+	if propagators := os.Getenv("PROPAGATORS"); propagators != "" {
+		cfg.Propagators = nil
+		for _, p := range strings.Split(propagators, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				cfg.Propagators = append(cfg.Propagators, p)
+			}
+		}
+	}
+	if timeout, err := time.ParseDuration(os.Getenv("SHUTDOWN_TIMEOUT")); err == nil {
+		cfg.ShutdownTimeout = timeout
+	}
+	return cfg
+}
+
+// InitOption configura Init
+type InitOption func(*initConfig)
+
+type initConfig struct {
+	factory     tracerinterfaces.TracerProviderFactory
+	coordinator *Coordinator
+	readers     []sdkmetric.Reader
+	logOutput   io.Writer
+}
+
+// WithTracerFactory substitui a factory dos tracer providers. A padrão
+// atende os providers do SDK OpenTelemetry; para o Datadog, que depende do
+// dd-trace-go, use tracer.NewFactory()
+func WithTracerFactory(factory tracerinterfaces.TracerProviderFactory) InitOption {
+	return func(c *initConfig) {
+		c.factory = factory
+	}
+}
+
+// WithCoordinator registra os componentes no coordenador informado, como o
+// DefaultCoordinator, em vez de um coordenador próprio
+func WithCoordinator(coordinator *Coordinator) InitOption {
+	return func(c *initConfig) {
+		c.coordinator = coordinator
+	}
+}
+
+// WithMetricReader adiciona um reader ao meter provider, além do OTLP
+func WithMetricReader(reader sdkmetric.Reader) InitOption {
+	return func(c *initConfig) {
+		c.readers = append(c.readers, reader)
+	}
+}
+
+// WithLogOutput define a saída dos logs; o padrão é os.Stdout
+func WithLogOutput(w io.Writer) InitOption {
+	return func(c *initConfig) {
+		c.logOutput = w
+	}
+}
+
+// Telemetry é o resultado de Init: os providers configurados, definidos
+// também como globais, para os middlewares de HTTP, gRPC, banco de dados e
+// mensageria, e o coordenador que os finaliza
+type Telemetry struct {
+	config         Config
+	tracer         tracerinterfaces.TracerProvider
+	tracerProvider oteltrace.TracerProvider
+	metrics        *metrics.Provider
+	propagator     propagation.TextMapPropagator
+	logger         logger.Logger
+	coordinator    *Coordinator
+}
+
+// Init configura, nesta ordem, o tracer provider, os propagadores, o meter
+// provider e o logger, definindo-os como globais (otel e logger), e os
+// registra no coordenador de shutdown: tracer, métricas e por último o
+// logger. Se uma etapa falha, as anteriores são finalizadas
+func Init(ctx context.Context, cfg Config, opts ...InitOption) (*Telemetry, error) {
+	ic := initConfig{factory: sdkFactory{}, logOutput: os.Stdout}
+	for _, opt := range opts {
+		opt(&ic)
+	}
+	if ic.coordinator == nil {
+		ic.coordinator = NewCoordinator()
+	}
+	if cfg.ServiceName == "" {
+		return nil, errors.New("observability: service name is required")
+	}
+	cfg = applyService(cfg)
+
+	propagator, err := newPropagator(cfg.Propagators)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Telemetry{config: cfg, propagator: propagator, coordinator: ic.coordinator}
+	var cleanup []ShutdownFunc
+	fail := func(err error) (*Telemetry, error) {
+		for i := len(cleanup) - 1; i >= 0; i-- {
+			_ = cleanup[i](ctx)
+		}
+		return nil, err
+	}
+
+	// Tracer
+	if err := tracerconfig.Validate(cfg.Tracer); err != nil {
+		return nil, fmt.Errorf("observability: invalid tracer configuration: %w", err)
+	}
+	t.tracer, err = ic.factory.CreateProvider(cfg.Tracer)
+	if err != nil {
+		return nil, fmt.Errorf("observability: failed to create tracer provider: %w", err)
+	}
+	t.tracerProvider, err = t.tracer.Init(ctx, cfg.Tracer)
+	if err != nil {
+		return nil, fmt.Errorf("observability: failed to initialize tracer provider: %w", err)
+	}
+	cleanup = append(cleanup, t.tracer.Shutdown)
+
+	// Propagadores: substituem os definidos pelo provider
+	otel.SetTextMapPropagator(propagator)
+
+	// Métricas
+	t.metrics, err = newMetrics(ctx, cfg, ic.readers)
+	if err != nil {
+		return fail(err)
+	}
+	cleanup = append(cleanup, t.metrics.Shutdown)
+	otel.SetMeterProvider(t.metrics.MeterProvider())
+
+	// Logger
+	t.logger, err = newLogger(cfg, ic.logOutput)
+	if err != nil {
+		return fail(err)
+	}
+
+	var componentOpts []ComponentOption
+	if cfg.ShutdownTimeout > 0 {
+		componentOpts = append(componentOpts, WithTimeout(cfg.ShutdownTimeout))
+	}
+	if err := t.coordinator.RegisterTracer(ComponentTracer, t.tracer, componentOpts...); err != nil {
+		return fail(fmt.Errorf("observability: %w", err))
+	}
+	if err := t.coordinator.RegisterMetrics(ComponentMetrics, t.metrics, componentOpts...); err != nil {
+		return fail(fmt.Errorf("observability: %w", err))
+	}
+	if err := t.coordinator.RegisterLogger(ComponentLogger, t.logger, componentOpts...); err != nil {
+		return fail(fmt.Errorf("observability: %w", err))
+	}
+	return t, nil
+}
+
+// applyService aplica a identificação do serviço à configuração do tracer
+func applyService(cfg Config) Config {
+	cfg.Tracer.ServiceName = cfg.ServiceName
+	if cfg.ServiceVersion != "" {
+		cfg.Tracer.Version = cfg.ServiceVersion
+	}
+	if cfg.Environment != "" {
+		cfg.Tracer.Environment = cfg.Environment
+	}
+	if cfg.Tracer.ExporterType == "" {
+		cfg.Tracer.ExporterType = "noop"
+	}
+	return cfg
+}
+
+// newPropagator cria o propagador composto com os nomes informados
+func newPropagator(names []string) (propagation.TextMapPropagator, error) {
+	if len(names) == 0 {
+		names = []string{"tracecontext", "baggage"}
+	}
+	propagators := make([]propagation.TextMapPropagator, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case "tracecontext":
+			propagators = append(propagators, propagation.TraceContext{})
+		case "baggage":
+			propagators = append(propagators, propagation.Baggage{})
+		case "xray":
+			propagators = append(propagators, awsxray.Propagator{})
+		default:
+			return nil, fmt.Errorf("observability: unsupported propagator: %s", name)
+		}
+	}
+	return propagation.NewCompositeTextMapPropagator(propagators...), nil
+}
+
+// newMetrics cria o meter provider com o resource do serviço e, quando
+// configurado, o exporter OTLP
+func newMetrics(ctx context.Context, cfg Config, readers []sdkmetric.Reader) (*metrics.Provider, error) {
+	attrs := []attribute.KeyValue{semconv.ServiceName(cfg.ServiceName)}
+	if cfg.ServiceVersion != "" {
+		attrs = append(attrs, semconv.ServiceVersion(cfg.ServiceVersion))
+	}
+	if cfg.Environment != "" {
+		attrs = append(attrs, semconv.DeploymentEnvironmentName(cfg.Environment))
+	}
+	opts := []metrics.Option{metrics.WithResource(resource.NewSchemaless(attrs...))}
+
+	if cfg.Metrics.Endpoint != "" {
+		exporterOpts := []otlpmetrichttp.Option{otlpmetrichttp.WithEndpointURL(cfg.Metrics.Endpoint)}
+		if len(cfg.Metrics.Headers) > 0 {
+			exporterOpts = append(exporterOpts, otlpmetrichttp.WithHeaders(cfg.Metrics.Headers))
+		}
+		if cfg.Metrics.Insecure {
+			exporterOpts = append(exporterOpts, otlpmetrichttp.WithInsecure())
+		}
+		exporter, err := otlpmetrichttp.New(ctx, exporterOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("observability: failed to create metrics exporter: %w", err)
+		}
+		interval := cfg.Metrics.ExportInterval
+		if interval <= 0 {
+			interval = DefaultMetricsExportInterval
+		}
+		opts = append(opts, metrics.WithReader(sdkmetric.NewPeriodicReader(exporter, sdkmetric.WithInterval(interval))))
+	}
+	for _, reader := range readers {
+		opts = append(opts, metrics.WithReader(reader))
+	}
+	return metrics.NewProvider(opts...), nil
+}
+
+// newLogger configura o provider de log informado como o logger global
+func newLogger(cfg Config, output io.Writer) (logger.Logger, error) {
+	lc := logger.DefaultConfig()
+	lc.Output = output
+	lc.ServiceName = cfg.ServiceName
+	lc.ServiceVersion = cfg.ServiceVersion
+	lc.Environment = cfg.Environment
+	lc.AddSource = cfg.Logger.AddSource
+	if cfg.Logger.Level != "" {
+		level, err := logger.ParseLevel(cfg.Logger.Level)
+		if err != nil {
+			return nil, fmt.Errorf("observability: %w", err)
+		}
+		lc.Level = level
+	}
+	if cfg.Logger.Format != "" {
+		lc.Format = logger.Format(cfg.Logger.Format)
+	}
+
+	provider := cfg.Logger.Provider
+	if provider == "" {
+		provider = "slog"
+	}
+	if err := logger.SetProvider(provider, lc); err != nil {
+		return nil, fmt.Errorf("observability: %w", err)
+	}
+	return logger.GetCurrentProvider(), nil
+}
+
+// Config retorna a configuração efetiva
+func (t *Telemetry) Config() Config {
+	return t.config
+}
+
+// TracerProvider retorna o tracer provider, para os middlewares que recebem
+// um trace.TracerProvider
+func (t *Telemetry) TracerProvider() oteltrace.TracerProvider {
+	return t.tracerProvider
+}
+
+// Tracer retorna um tracer do tracer provider
+func (t *Telemetry) Tracer(name string, opts ...oteltrace.TracerOption) oteltrace.Tracer {
+	return t.tracerProvider.Tracer(name, opts...)
+}
+
+// MeterProvider retorna o meter provider, para os middlewares que recebem um
+// metric.MeterProvider
+func (t *Telemetry) MeterProvider() metric.MeterProvider {
+	return t.metrics.MeterProvider()
+}
+
+// Meter retorna um meter do meter provider
+func (t *Telemetry) Meter(name string, opts ...metric.MeterOption) metric.Meter {
+	return t.metrics.Meter(name, opts...)
+}
+
+// Metrics retorna o provider de métricas, com o Handler para scrape
+func (t *Telemetry) Metrics() *metrics.Provider {
+	return t.metrics
+}
+
+// Propagator retorna o propagador composto
+func (t *Telemetry) Propagator() propagation.TextMapPropagator {
+	return t.propagator
+}
+
+// Logger retorna o logger global configurado
+func (t *Telemetry) Logger() logger.Logger {
+	return t.logger
+}
+
+// Coordinator retorna o coordenador de shutdown, para registrar os demais
+// componentes da aplicação
+func (t *Telemetry) Coordinator() *Coordinator {
+	return t.coordinator
+}
+
+// Shutdown finaliza todos os componentes do coordenador
+func (t *Telemetry) Shutdown(ctx context.Context) error {
+	return t.coordinator.Shutdown(ctx)
+}
+
+// sdkFactory cria os tracer providers baseados no SDK OpenTelemetry
+type sdkFactory struct{}
+
+func (sdkFactory) CreateProvider(config tracerinterfaces.Config) (tracerinterfaces.TracerProvider, error) {
+	switch config.ExporterType {
+	case "grafana":
+		return grafana.NewProvider(), nil
+	case "newrelic":
+		return newrelic.NewProvider(), nil
+	case "opentelemetry":
+		return opentelemetry.NewProvider(), nil
+	case "memory":
+		return memory.NewProvider(), nil
+	case "file":
+		return file.NewProvider(), nil
+	case "noop":
+		return noop.NewProvider(), nil
+	case "xray":
+		return xray.NewProvider(), nil
+	default:
+		return nil, fmt.Errorf("unsupported exporter type: %s (use WithTracerFactory(tracer.NewFactory()) for datadog)", config.ExporterType)
+	}
+}
+
+func (sdkFactory) SupportedTypes() []string {
+	return []string{"grafana", "newrelic", "opentelemetry", "memory", "file", "noop", "xray"}
+}
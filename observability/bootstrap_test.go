@@ -0,0 +1,145 @@
+package observability
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/fsvxavier/nexs-lib/observability/logger"
+)
+
+func TestInit(t *testing.T) {
+	var exports atomic.Int32
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/metrics" {
+			exports.Add(1)
+		}
+	}))
+	defer collector.Close()
+
+	cfg := DefaultConfig()
+	cfg.ServiceName = "orders"
+	cfg.ServiceVersion = "1.2.3"
+	cfg.Tracer.ExporterType = "memory"
+	cfg.Metrics.Endpoint = collector.URL + "/v1/metrics"
+	cfg.Metrics.Insecure = true
+	cfg.Logger.Level = "debug"
+
+	var logs bytes.Buffer
+	ctx := context.Background()
+	telemetry, err := Init(ctx, cfg, WithLogOutput(&logs))
+	require.NoError(t, err)
+
+	assert.Equal(t, "orders", telemetry.Config().Tracer.ServiceName)
+	assert.Equal(t, "1.2.3", telemetry.Config().Tracer.Version)
+	assert.Equal(t, telemetry.MeterProvider(), otel.GetMeterProvider())
+
+	spanCtx, span := telemetry.Tracer("test").Start(ctx, "GET /orders")
+	assert.True(t, span.SpanContext().IsSampled())
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(spanCtx, carrier)
+	assert.Contains(t, carrier, "traceparent")
+	span.End()
+
+	counter, err := telemetry.Meter("test").Int64Counter("orders.created")
+	require.NoError(t, err)
+	counter.Add(ctx, 1)
+	rec := httptest.NewRecorder()
+	telemetry.Metrics().Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, rec.Body.String(), "orders_created")
+
+	telemetry.Logger().Debug(ctx, "order created", logger.String("order.id", "42"))
+	assert.Same(t, telemetry.Logger(), logger.GetCurrentProvider())
+	assert.Contains(t, logs.String(), "order created")
+	assert.Contains(t, logs.String(), `"service":"orders"`)
+
+	names, err := telemetry.Coordinator().Components()
+	require.NoError(t, err)
+	assert.Equal(t, []string{ComponentTracer, ComponentMetrics, ComponentLogger}, names)
+
+	require.NoError(t, telemetry.Shutdown(ctx))
+	assert.Equal(t, int32(1), exports.Load(), "pending metrics are exported on shutdown")
+}
+
+func TestInit_Coordinator(t *testing.T) {
+	coordinator := NewCoordinator()
+	require.NoError(t, coordinator.Register("db", func(context.Context) error { return nil }))
+
+	cfg := DefaultConfig()
+	cfg.ServiceName = "orders"
+	cfg.ShutdownTimeout = time.Second
+	telemetry, err := Init(context.Background(), cfg, WithCoordinator(coordinator), WithLogOutput(&bytes.Buffer{}))
+	require.NoError(t, err)
+	assert.Same(t, coordinator, telemetry.Coordinator())
+
+	names, err := coordinator.Components()
+	require.NoError(t, err)
+	assert.Equal(t, []string{ComponentTracer, ComponentMetrics, ComponentLogger, "db"}, names)
+
+	_, err = Init(context.Background(), cfg, WithCoordinator(coordinator), WithLogOutput(&bytes.Buffer{}))
+	assert.ErrorContains(t, err, "already registered")
+	require.NoError(t, coordinator.Shutdown(context.Background()))
+}
+
+func TestInit_Errors(t *testing.T) {
+	ctx := context.Background()
+	valid := DefaultConfig()
+	valid.ServiceName = "orders"
+
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		err    string
+	}{
+		{"service name", func(c *Config) { c.ServiceName = "" }, "service name is required"},
+		{"propagator", func(c *Config) { c.Propagators = []string{"b3"} }, "unsupported propagator: b3"},
+		{"tracer config", func(c *Config) { c.Tracer.ExporterType = "opentelemetry" }, "endpoint is required"},
+		{"datadog", func(c *Config) { c.Tracer.ExporterType = "datadog"; c.Tracer.APIKey = "key" }, "WithTracerFactory"},
+		{"log level", func(c *Config) { c.Logger.Level = "verbose" }, "verbose"},
+		{"log provider", func(c *Config) { c.Logger.Provider = "missing" }, "provider 'missing' not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid
+			tt.modify(&cfg)
+			_, err := Init(ctx, cfg, WithLogOutput(&bytes.Buffer{}))
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("SERVICE_NAME", "orders")
+	t.Setenv("ENVIRONMENT", "production")
+	t.Setenv("TRACER_SAMPLING_RATIO", "0.25")
+	t.Setenv("METRICS_ENDPOINT", "http://collector:4318/v1/metrics")
+	t.Setenv("METRICS_EXPORT_INTERVAL", "15s")
+	t.Setenv("METRICS_HEADER_X_API_KEY", "secret")
+	t.Setenv("LOG_LEVEL", "warn")
+	t.Setenv("LOG_FORMAT", "TEXT")
+	t.Setenv("PROPAGATORS", "tracecontext, xray")
+	t.Setenv("SHUTDOWN_TIMEOUT", "3s")
+
+	cfg := ConfigFromEnv()
+	assert.Equal(t, "orders", cfg.ServiceName)
+	assert.Equal(t, "production", cfg.Environment)
+	assert.Equal(t, "noop", cfg.Tracer.ExporterType, "tracing is disabled without TRACER_EXPORTER_TYPE")
+	assert.Equal(t, 0.25, cfg.Tracer.SamplingRatio)
+	assert.Equal(t, MetricsConfig{
+		Endpoint:       "http://collector:4318/v1/metrics",
+		Headers:        map[string]string{"x-api-key": "secret"},
+		ExportInterval: 15 * time.Second,
+	}, cfg.Metrics)
+	assert.Equal(t, LoggerConfig{Provider: "slog", Level: "warn", Format: "text"}, cfg.Logger)
+	assert.Equal(t, []string{"tracecontext", "xray"}, cfg.Propagators)
+	assert.Equal(t, 3*time.Second, cfg.ShutdownTimeout)
+}
@@ -0,0 +1,315 @@
+// Package audit implementa um canal de log de auditoria separado dos logs da
+// aplicação: eventos estruturados (ator, ação, recurso e resultado) com campos
+// obrigatórios validados, encadeados por hash — cada entrada carrega o hash da
+// anterior, de modo que alterar, remover ou reordenar entradas quebra a
+// cadeia — e gravados em sinks de arquivo e PostgreSQL.
+package audit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+// ErrInvalidEvent é encapsulado pelo erro de eventos sem os campos obrigatórios
+var ErrInvalidEvent = errors.New("audit: invalid event")
+
+// ErrChainBroken é encapsulado pelo erro de Verify quando a cadeia foi violada
+var ErrChainBroken = errors.New("audit: hash chain broken")
+
+// Outcome é o resultado da ação auditada
+type Outcome string
+
+const (
+	OutcomeSuccess Outcome = "success"
+	OutcomeFailure Outcome = "failure"
+	OutcomeDenied  Outcome = "denied"
+)
+
+// Event é uma ação auditada
+type Event struct {
+	// Timestamp do evento; o instante do registro quando zero
+	Timestamp time.Time `json:"timestamp"`
+	// Actor quem executou a ação, ex.: "user:42" ou "service:billing"
+	Actor string `json:"actor"`
+	// Action a ação executada, ex.: "invoice.refund"
+	Action string `json:"action"`
+	// Resource o recurso afetado, ex.: "invoice:981"
+	Resource string `json:"resource"`
+	// Outcome o resultado da ação
+	Outcome Outcome `json:"outcome"`
+	// Reason motivo do resultado, ex.: a permissão que faltou
+	Reason string `json:"reason,omitempty"`
+	// Metadata dados adicionais; os valores devem ser serializáveis em JSON
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// Entry é um evento registrado na cadeia
+type Entry struct {
+	Event
+	// Sequence posição da entrada na cadeia, a partir de 1
+	Sequence uint64 `json:"sequence"`
+	// PrevHash hash da entrada anterior; vazio na primeira
+	PrevHash string `json:"prev_hash"`
+	// Hash SHA-256 (ou HMAC-SHA256 com WithHMACKey) da entrada e de PrevHash
+	Hash string `json:"hash"`
+}
+
+// Sink grava as entradas da cadeia
+type Sink interface {
+	Write(ctx context.Context, entry *Entry) error
+}
+
+// Option configura o Logger
+type Option func(*Logger)
+
+// WithSink adiciona sinks; cada entrada é gravada em todos, na ordem
+func WithSink(sinks ...Sink) Option {
+	return func(l *Logger) {
+		l.sinks = append(l.sinks, sinks...)
+	}
+}
+
+// WithRequiredMetadata exige as chaves nos metadados de todos os eventos,
+// ex.: "tenant_id" e "request_id"
+func WithRequiredMetadata(keys ...string) Option {
+	return func(l *Logger) {
+		l.requiredMetadata = append(l.requiredMetadata, keys...)
+	}
+}
+
+// WithHMACKey calcula os hashes com HMAC-SHA256: sem a chave, quem altera as
+// entradas não consegue recalcular a cadeia
+func WithHMACKey(key []byte) Option {
+	return func(l *Logger) {
+		l.key = key
+	}
+}
+
+// WithChainHead continua a cadeia a partir da última entrada gravada, ex.:
+// a retornada por FileSink.Last ou PostgresSink.Last após um restart
+func WithChainHead(last *Entry) Option {
+	return func(l *Logger) {
+		if last != nil {
+			l.sequence = last.Sequence
+			l.lastHash = last.Hash
+		}
+	}
+}
+
+// WithClock define o relógio dos timestamps (padrão clock.Real()); testes
+// usam clock.NewFake
+func WithClock(c clock.Clock) Option {
+	return func(l *Logger) {
+		l.clock = clock.OrReal(c)
+	}
+}
+
+// Logger registra eventos de auditoria em uma cadeia de hashes. É seguro para
+// uso concorrente; as entradas são numeradas e gravadas na ordem do registro.
+type Logger struct {
+	sinks            []Sink
+	requiredMetadata []string
+	key              []byte
+	clock            clock.Clock
+
+	mu       sync.Mutex
+	sequence uint64
+	lastHash string
+}
+
+// New cria um Logger
+func New(opts ...Option) *Logger {
+	l := &Logger{clock: clock.Real()}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Log valida o evento, encadeia-o à entrada anterior e grava-o nos sinks.
+// Um evento sem Actor, Action, Resource, um Outcome válido ou os metadados
+// obrigatórios retorna um ValidationError sem ser registrado. A entrada
+// entra na cadeia mesmo quando um sink falha: a lacuna nesse sink fica
+// visível para Verify. Os erros dos sinks são retornados juntos.
+func (l *Logger) Log(ctx context.Context, event Event) (*Entry, error) {
+	if err := l.validate(event); err != nil {
+		return nil, err
+	}
+
+	metadata, err := normalizeMetadata(event.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	event.Metadata = metadata
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if event.Timestamp.IsZero() {
+		event.Timestamp = l.clock.Now()
+	}
+	// O PostgreSQL guarda microssegundos; o hash deve sobreviver à gravação
+	event.Timestamp = event.Timestamp.UTC().Truncate(time.Microsecond)
+
+	entry := &Entry{Event: event, Sequence: l.sequence + 1, PrevHash: l.lastHash}
+	hash, err := entry.computeHash(l.key)
+	if err != nil {
+		return nil, err
+	}
+	entry.Hash = hash
+	l.sequence, l.lastHash = entry.Sequence, entry.Hash
+
+	var errs []error
+	for _, sink := range l.sinks {
+		if err := sink.Write(ctx, entry); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return entry, errors.Join(errs...)
+}
+
+// Head retorna a sequência e o hash da última entrada registrada
+func (l *Logger) Head() (uint64, string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.sequence, l.lastHash
+}
+
+// Verify confere uma sequência contínua de entradas, na ordem: a numeração, o
+// encadeamento de PrevHash e o hash de cada uma. key é a mesma de
+// WithHMACKey, ou nil. Retorna um SecurityError encapsulando ErrChainBroken
+// com a sequência da primeira entrada violada.
+func Verify(entries []Entry, key []byte) error {
+	for i := range entries {
+		entry := &entries[i]
+
+		var reason string
+		switch {
+		case i > 0 && entry.Sequence != entries[i-1].Sequence+1:
+			reason = fmt.Sprintf("expected sequence %d, found %d", entries[i-1].Sequence+1, entry.Sequence)
+		case i > 0 && entry.PrevHash != entries[i-1].Hash:
+			reason = "prev_hash does not match the previous entry"
+		case i == 0 && entry.Sequence == 1 && entry.PrevHash != "":
+			reason = "the first entry has a prev_hash"
+		}
+		if reason == "" {
+			hash, err := entry.computeHash(key)
+			if err != nil {
+				return err
+			}
+			if !hmac.Equal([]byte(hash), []byte(entry.Hash)) {
+				reason = "hash does not match the entry"
+			}
+		}
+
+		if reason != "" {
+			return domainerrors.NewWithMetadata(interfaces.SecurityError, "AUDIT_CHAIN_BROKEN",
+				fmt.Sprintf("audit chain broken at sequence %d: %s", entry.Sequence, reason),
+				map[string]interface{}{
+					"sequence": entry.Sequence,
+					"reason":   reason,
+				}).Wrap(ErrChainBroken)
+		}
+	}
+	return nil
+}
+
+// validate confere os campos obrigatórios do evento
+func (l *Logger) validate(event Event) error {
+	var missing []string
+	if event.Actor == "" {
+		missing = append(missing, "actor")
+	}
+	if event.Action == "" {
+		missing = append(missing, "action")
+	}
+	if event.Resource == "" {
+		missing = append(missing, "resource")
+	}
+	switch event.Outcome {
+	case OutcomeSuccess, OutcomeFailure, OutcomeDenied:
+	default:
+		missing = append(missing, "outcome")
+	}
+	for _, key := range l.requiredMetadata {
+		if _, ok := event.Metadata[key]; !ok {
+			missing = append(missing, "metadata."+key)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+	return domainerrors.NewWithMetadata(interfaces.ValidationError, "AUDIT_EVENT_INVALID",
+		fmt.Sprintf("audit event is missing required fields: %v", missing),
+		map[string]interface{}{"fields": missing}).Wrap(ErrInvalidEvent)
+}
+
+// hashedEntry são os campos cobertos pelo hash, em ordem fixa
+type hashedEntry struct {
+	Sequence  uint64         `json:"sequence"`
+	Timestamp string         `json:"timestamp"`
+	Actor     string         `json:"actor"`
+	Action    string         `json:"action"`
+	Resource  string         `json:"resource"`
+	Outcome   Outcome        `json:"outcome"`
+	Reason    string         `json:"reason"`
+	Metadata  map[string]any `json:"metadata"`
+	PrevHash  string         `json:"prev_hash"`
+}
+
+// computeHash calcula o hash da entrada; o JSON ordena as chaves dos
+// metadados, tornando a serialização determinística
+func (e *Entry) computeHash(key []byte) (string, error) {
+	data, err := json.Marshal(hashedEntry{
+		Sequence:  e.Sequence,
+		Timestamp: e.Timestamp.UTC().Format(time.RFC3339Nano),
+		Actor:     e.Actor,
+		Action:    e.Action,
+		Resource:  e.Resource,
+		Outcome:   e.Outcome,
+		Reason:    e.Reason,
+		Metadata:  e.Metadata,
+		PrevHash:  e.PrevHash,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+
+	if key != nil {
+		mac := hmac.New(sha256.New, key)
+		mac.Write(data)
+		return hex.EncodeToString(mac.Sum(nil)), nil
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// normalizeMetadata converte os metadados para a forma que têm depois de lidos
+// de um sink (números como float64, structs como mapas), para que o hash
+// recalculado por Verify seja o mesmo
+func normalizeMetadata(metadata map[string]any) (map[string]any, error) {
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit metadata: %w", err)
+	}
+	var normalized map[string]any
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, fmt.Errorf("failed to decode audit metadata: %w", err)
+	}
+	return normalized, nil
+}
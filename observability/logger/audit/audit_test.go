@@ -0,0 +1,280 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+	pginterfaces "github.com/fsvxavier/nexs-lib/db/postgres/interfaces"
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+func refund(invoice string) Event {
+	return Event{
+		Actor:    "user:42",
+		Action:   "invoice.refund",
+		Resource: "invoice:" + invoice,
+		Outcome:  OutcomeSuccess,
+		Metadata: map[string]any{"tenant_id": "acme", "amount": 1250},
+	}
+}
+
+func TestLogger_ChainsEntries(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 123456789, time.UTC))
+	l := New(WithClock(fake))
+	ctx := context.Background()
+
+	first, err := l.Log(ctx, refund("1"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	second, err := l.Log(ctx, refund("2"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if first.Sequence != 1 || first.PrevHash != "" {
+		t.Errorf("Unexpected first entry %+v", first)
+	}
+	if second.Sequence != 2 || second.PrevHash != first.Hash {
+		t.Errorf("Expected the second entry chained to the first, got %+v", second)
+	}
+	if !first.Timestamp.Equal(time.Date(2025, 1, 1, 0, 0, 0, 123456000, time.UTC)) {
+		t.Errorf("Expected the timestamp truncated to microseconds, got %v", first.Timestamp)
+	}
+	if err := Verify([]Entry{*first, *second}, nil); err != nil {
+		t.Errorf("Unexpected verification error: %v", err)
+	}
+}
+
+func TestLogger_ValidatesRequiredFields(t *testing.T) {
+	l := New(WithRequiredMetadata("tenant_id", "request_id"))
+
+	_, err := l.Log(context.Background(), Event{Actor: "user:42", Outcome: "maybe",
+		Metadata: map[string]any{"tenant_id": "acme"}})
+	if !errors.Is(err, ErrInvalidEvent) || !domainerrors.IsType(err, interfaces.ValidationError) {
+		t.Fatalf("Expected a validation error, got %v", err)
+	}
+	var domainErr interfaces.DomainErrorInterface
+	errors.As(err, &domainErr)
+	fields := domainErr.Metadata()["fields"].([]string)
+	if strings.Join(fields, ",") != "action,resource,outcome,metadata.request_id" {
+		t.Errorf("Unexpected missing fields %v", fields)
+	}
+	if sequence, _ := l.Head(); sequence != 0 {
+		t.Errorf("Expected the invalid event not to enter the chain")
+	}
+}
+
+func TestVerify_DetectsTampering(t *testing.T) {
+	l := New(WithHMACKey([]byte("secret")))
+	var entries []Entry
+	for _, invoice := range []string{"1", "2", "3"} {
+		entry, err := l.Log(context.Background(), refund(invoice))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		entries = append(entries, *entry)
+	}
+
+	tests := []struct {
+		name   string
+		tamper func([]Entry) []Entry
+		key    []byte
+	}{
+		{
+			name: "Altered field",
+			tamper: func(e []Entry) []Entry {
+				e[1].Outcome = OutcomeDenied
+				return e
+			},
+			key: []byte("secret"),
+		},
+		{
+			name:   "Removed entry",
+			tamper: func(e []Entry) []Entry { return append(e[:1], e[2:]...) },
+			key:    []byte("secret"),
+		},
+		{
+			name: "Recomputed without the key",
+			tamper: func(e []Entry) []Entry {
+				e[2].Actor = "user:7"
+				e[2].Hash, _ = e[2].computeHash(nil)
+				return e
+			},
+			key: []byte("secret"),
+		},
+		{
+			name:   "Wrong key",
+			tamper: func(e []Entry) []Entry { return e },
+			key:    []byte("other"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.tamper(append([]Entry(nil), entries...)), tt.key)
+			if !errors.Is(err, ErrChainBroken) || !domainerrors.IsType(err, interfaces.SecurityError) {
+				t.Errorf("Expected a broken chain, got %v", err)
+			}
+		})
+	}
+
+	if err := Verify(entries, []byte("secret")); err != nil {
+		t.Errorf("Unexpected verification error: %v", err)
+	}
+}
+
+func TestFileSink_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := NewFileSink(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	l := New(WithSink(sink))
+	for _, invoice := range []string{"1", "2"} {
+		if _, err := l.Log(context.Background(), refund(invoice)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// A cadeia continua após reabrir o arquivo
+	sink, err = NewFileSink(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer sink.Close()
+	last, err := sink.Last()
+	if err != nil || last.Sequence != 2 {
+		t.Fatalf("Expected the last entry, got %+v, %v", last, err)
+	}
+	if _, err := New(WithSink(sink), WithChainHead(last)).Log(context.Background(), refund("3")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	entries, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(entries))
+	}
+	if err := Verify(entries, nil); err != nil {
+		t.Errorf("Unexpected verification error: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected mode 0600, got %v", info.Mode().Perm())
+	}
+}
+
+// fakeAuditPostgres guarda as linhas inseridas pelo PostgresSink
+type fakeAuditPostgres struct {
+	rows [][]interface{}
+}
+
+type fakeCommandTag struct {
+	pginterfaces.ICommandTag
+}
+
+func (f *fakeAuditPostgres) Exec(ctx context.Context, query string, args ...interface{}) (pginterfaces.ICommandTag, error) {
+	if strings.HasPrefix(query, "INSERT") {
+		for _, row := range f.rows {
+			if row[0] == args[0] {
+				return nil, errors.New("duplicate key value violates unique constraint")
+			}
+		}
+		f.rows = append(f.rows, args)
+	}
+	return fakeCommandTag{}, nil
+}
+
+func (f *fakeAuditPostgres) Query(ctx context.Context, query string, args ...interface{}) (pginterfaces.IRows, error) {
+	rows := f.rows
+	if strings.Contains(query, "DESC LIMIT 1") && len(rows) > 0 {
+		rows = rows[len(rows)-1:]
+	}
+	return &fakeAuditRows{rows: rows, index: -1}, nil
+}
+
+type fakeAuditRows struct {
+	pginterfaces.IRows
+	rows  [][]interface{}
+	index int
+}
+
+func (r *fakeAuditRows) Next() bool {
+	r.index++
+	return r.index < len(r.rows)
+}
+
+func (r *fakeAuditRows) Scan(dest ...any) error {
+	row := r.rows[r.index]
+	*dest[0].(*int64) = row[0].(int64)
+	*dest[1].(*time.Time) = row[1].(time.Time)
+	for i := 2; i <= 6; i++ {
+		*dest[i].(*string) = row[i].(string)
+	}
+	if metadata, ok := row[7].(string); ok {
+		*dest[7].(**string) = &metadata
+	}
+	*dest[8].(*string) = row[8].(string)
+	*dest[9].(*string) = row[9].(string)
+	return nil
+}
+
+func (r *fakeAuditRows) Close() error { return nil }
+func (r *fakeAuditRows) Err() error   { return nil }
+
+func TestPostgresSink_RoundTrip(t *testing.T) {
+	conn := &fakeAuditPostgres{}
+	sink, err := NewPostgresSink(conn, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	l := New(WithSink(sink))
+	denied := refund("2")
+	denied.Outcome, denied.Reason, denied.Metadata = OutcomeDenied, "missing permission invoice:refund", nil
+	for _, event := range []Event{refund("1"), denied} {
+		if _, err := l.Log(context.Background(), event); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	entries, err := sink.Entries(context.Background(), 1, 100)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	if err := Verify(entries, nil); err != nil {
+		t.Errorf("Unexpected verification error: %v", err)
+	}
+
+	last, err := sink.Last(context.Background())
+	if err != nil || last.Sequence != 2 || last.Reason != denied.Reason {
+		t.Errorf("Unexpected last entry %+v, %v", last, err)
+	}
+
+	// Uma cadeia reiniciada sem WithChainHead colide com a sequência gravada
+	if _, err := New(WithSink(sink)).Log(context.Background(), refund("3")); err == nil {
+		t.Error("Expected the duplicate sequence to fail")
+	}
+
+	if _, err := NewPostgresSink(conn, "audit; DROP TABLE users"); err == nil {
+		t.Error("Expected an invalid table name error")
+	}
+}
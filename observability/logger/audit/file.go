@@ -0,0 +1,112 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// FileSinkOption configura o FileSink
+type FileSinkOption func(*FileSink)
+
+// WithFileSync força a gravação em disco (fsync) após cada entrada
+func WithFileSync(enabled bool) FileSinkOption {
+	return func(s *FileSink) {
+		s.sync = enabled
+	}
+}
+
+// FileSink grava as entradas em um arquivo, uma por linha em JSON, apenas
+// acrescentando ao final
+type FileSink struct {
+	path string
+	sync bool
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileSink abre, ou cria com permissão 0600, o arquivo de auditoria
+func NewFileSink(path string, opts ...FileSinkOption) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit file: %w", err)
+	}
+
+	s := &FileSink{path: path, file: file}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+// Write implementa Sink
+func (s *FileSink) Write(ctx context.Context, entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Write(data); err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+	if s.sync {
+		if err := s.file.Sync(); err != nil {
+			return fmt.Errorf("failed to sync audit file: %w", err)
+		}
+	}
+	return nil
+}
+
+// Last retorna a última entrada do arquivo, ou nil quando está vazio
+func (s *FileSink) Last() (*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := ReadFile(s.path)
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	return &entries[len(entries)-1], nil
+}
+
+// Close fecha o arquivo
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// ReadFile lê as entradas de um arquivo gravado pelo FileSink, para Verify
+func ReadFile(path string) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit file: %w", err)
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("failed to decode audit entry at line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit file: %w", err)
+	}
+	return entries, nil
+}
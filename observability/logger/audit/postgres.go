@@ -0,0 +1,139 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/db/postgres"
+	pginterfaces "github.com/fsvxavier/nexs-lib/db/postgres/interfaces"
+)
+
+// PostgresConn é a parte da conexão de db/postgres usada pelo PostgresSink;
+// interfaces.IConn e transações a satisfazem
+type PostgresConn interface {
+	Exec(ctx context.Context, query string, args ...interface{}) (pginterfaces.ICommandTag, error)
+	Query(ctx context.Context, query string, args ...interface{}) (pginterfaces.IRows, error)
+}
+
+// entryColumns são as colunas lidas por Entries e Last, na ordem de scan
+const entryColumns = `sequence, timestamp, actor, action, resource, outcome, reason, metadata::text, prev_hash, hash`
+
+// PostgresSink grava as entradas em uma tabela criada com Schema. A sequência
+// é a chave primária: uma entrada repetida ou fora de ordem falha na gravação.
+type PostgresSink struct {
+	conn  PostgresConn
+	table string
+}
+
+// NewPostgresSink cria um PostgresSink na tabela, "audit_log" quando vazia
+func NewPostgresSink(conn PostgresConn, table string) (*PostgresSink, error) {
+	if table == "" {
+		table = "audit_log"
+	}
+	if !postgres.ValidIdentifier(table) {
+		return nil, fmt.Errorf("invalid table name: %q", table)
+	}
+	return &PostgresSink{conn: conn, table: table}, nil
+}
+
+// Schema retorna o comando que cria a tabela. Para impedir alterações pela
+// aplicação, conceda ao seu usuário apenas INSERT e SELECT na tabela.
+func (s *PostgresSink) Schema() string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	sequence BIGINT PRIMARY KEY,
+	timestamp TIMESTAMPTZ NOT NULL,
+	actor TEXT NOT NULL,
+	action TEXT NOT NULL,
+	resource TEXT NOT NULL,
+	outcome TEXT NOT NULL,
+	reason TEXT NOT NULL DEFAULT '',
+	metadata JSONB,
+	prev_hash TEXT NOT NULL,
+	hash TEXT NOT NULL
+)`, s.table)
+}
+
+// Migrate cria a tabela quando ela não existe
+func (s *PostgresSink) Migrate(ctx context.Context) error {
+	if _, err := s.conn.Exec(ctx, s.Schema()); err != nil {
+		return fmt.Errorf("failed to create table %s: %w", s.table, err)
+	}
+	return nil
+}
+
+// Write implementa Sink
+func (s *PostgresSink) Write(ctx context.Context, entry *Entry) error {
+	var metadata interface{}
+	if entry.Metadata != nil {
+		data, err := json.Marshal(entry.Metadata)
+		if err != nil {
+			return fmt.Errorf("failed to encode audit metadata: %w", err)
+		}
+		metadata = string(data)
+	}
+
+	if _, err := s.conn.Exec(ctx, fmt.Sprintf(`INSERT INTO %s
+(sequence, timestamp, actor, action, resource, outcome, reason, metadata, prev_hash, hash)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`, s.table),
+		int64(entry.Sequence), entry.Timestamp, entry.Actor, entry.Action, entry.Resource,
+		string(entry.Outcome), entry.Reason, metadata, entry.PrevHash, entry.Hash); err != nil {
+		return fmt.Errorf("failed to insert audit entry %d: %w", entry.Sequence, err)
+	}
+	return nil
+}
+
+// Entries lê até limit entradas a partir da sequência from, em ordem, para
+// Verify
+func (s *PostgresSink) Entries(ctx context.Context, from uint64, limit int) ([]Entry, error) {
+	return s.query(ctx, fmt.Sprintf(`SELECT %s FROM %s WHERE sequence >= $1 ORDER BY sequence LIMIT $2`,
+		entryColumns, s.table), int64(from), limit)
+}
+
+// Last retorna a última entrada da tabela, ou nil quando está vazia
+func (s *PostgresSink) Last(ctx context.Context) (*Entry, error) {
+	entries, err := s.query(ctx, fmt.Sprintf(`SELECT %s FROM %s ORDER BY sequence DESC LIMIT 1`,
+		entryColumns, s.table))
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	return &entries[0], nil
+}
+
+// query lê entradas com as colunas de entryColumns
+func (s *PostgresSink) query(ctx context.Context, query string, args ...interface{}) ([]Entry, error) {
+	rows, err := s.conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var (
+			entry     Entry
+			sequence  int64
+			timestamp time.Time
+			outcome   string
+			metadata  *string
+		)
+		if err := rows.Scan(&sequence, &timestamp, &entry.Actor, &entry.Action, &entry.Resource,
+			&outcome, &entry.Reason, &metadata, &entry.PrevHash, &entry.Hash); err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		entry.Sequence = uint64(sequence)
+		entry.Timestamp = timestamp.UTC()
+		entry.Outcome = Outcome(outcome)
+		if metadata != nil {
+			if err := json.Unmarshal([]byte(*metadata), &entry.Metadata); err != nil {
+				return nil, fmt.Errorf("failed to decode audit metadata: %w", err)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit entries: %w", err)
+	}
+	return entries, nil
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/fsvxavier/nexs-lib/observability/logger/interfaces"
+	"github.com/fsvxavier/nexs-lib/pools"
 )
 
 // CircularBuffer implementa um buffer circular thread-safe para alta performance
@@ -315,22 +316,24 @@ func (cb *CircularBuffer) writeEntries(entries []*interfaces.LogEntry) error {
 		return nil
 	}
 
+	// Buffer do pool reutilizado por todas as entradas do lote
+	buf := pools.GetBuffer(0)
+	defer pools.PutBuffer(buf)
+	encoder := json.NewEncoder(buf)
+
 	for _, entry := range entries {
 		if entry == nil {
 			continue
 		}
 
-		// Serializa entrada como JSON
-		data, err := json.Marshal(entry)
-		if err != nil {
+		// Serializa entrada como JSON (Encode já adiciona o newline)
+		buf.Reset()
+		if err := encoder.Encode(entry); err != nil {
 			return fmt.Errorf("error marshaling log entry: %w", err)
 		}
 
-		// Adiciona newline
-		data = append(data, '\n')
-
 		// Escreve para o writer
-		if _, err := cb.writer.Write(data); err != nil {
+		if _, err := cb.writer.Write(buf.Bytes()); err != nil {
 			return fmt.Errorf("error writing to output: %w", err)
 		}
 	}
@@ -0,0 +1,225 @@
+package logger
+
+import (
+	"context"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/fsvxavier/nexs-lib/observability/logger/interfaces"
+)
+
+// traceCorrelationDisabled desliga a correlação automática de trace_id/span_id
+// do span ativo nas funções globais, habilitada por padrão
+var traceCorrelationDisabled atomic.Bool
+
+// EnableTraceCorrelation habilita/desabilita a correlação automática de traces
+// nas funções globais (Debug, Info, Error, ...). Habilitada por padrão
+func EnableTraceCorrelation(enabled bool) {
+	traceCorrelationDisabled.Store(!enabled)
+}
+
+// IsTraceCorrelationEnabled verifica se a correlação automática está habilitada
+func IsTraceCorrelationEnabled() bool {
+	return !traceCorrelationDisabled.Load()
+}
+
+// correlate aplica TraceContext quando a correlação global está habilitada
+func correlate(ctx context.Context) context.Context {
+	if traceCorrelationDisabled.Load() {
+		return ctx
+	}
+	return TraceContext(ctx)
+}
+
+// TraceContext retorna um contexto contendo trace_id e span_id do span ativo.
+// Os providers já extraem TraceIDKey e SpanIDKey do contexto, portanto o
+// contexto retornado pode ser repassado diretamente para qualquer Logger.
+// Valores já presentes no contexto não são sobrescritos.
+func TraceContext(ctx context.Context) context.Context {
+	if ctx == nil {
+		return ctx
+	}
+
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return ctx
+	}
+
+	if ctx.Value(TraceIDKey) == nil {
+		ctx = context.WithValue(ctx, TraceIDKey, sc.TraceID().String())
+	}
+	if ctx.Value(SpanIDKey) == nil {
+		ctx = context.WithValue(ctx, SpanIDKey, sc.SpanID().String())
+	}
+
+	return ctx
+}
+
+// TraceFields retorna os campos de correlação do span ativo no contexto
+func TraceFields(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+
+	return []Field{
+		String(string(TraceIDKey), sc.TraceID().String()),
+		String(string(SpanIDKey), sc.SpanID().String()),
+	}
+}
+
+// RecordSpanError registra o erro no span ativo (marcando o status como erro)
+// e emite um log estruturado com os mesmos campos de correlação.
+// Se l for nil, o logger global atual é utilizado.
+func RecordSpanError(ctx context.Context, l Logger, err error, msg string, fields ...Field) {
+	if err == nil {
+		return
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	span := trace.SpanFromContext(ctx)
+	if span.IsRecording() {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	if l == nil {
+		l = GetCurrentProvider()
+	}
+	if msg == "" {
+		msg = err.Error()
+	}
+
+	l.Error(TraceContext(ctx), msg, append(fields, ErrorField(err))...)
+}
+
+// correlatedLogger decora um Logger anexando a correlação de trace em cada chamada
+type correlatedLogger struct {
+	Logger
+}
+
+// NewCorrelatedLogger cria um Logger que anexa trace_id/span_id do span ativo
+// em todas as entradas de log
+func NewCorrelatedLogger(l Logger) Logger {
+	if l == nil {
+		return nil
+	}
+	if _, ok := l.(*correlatedLogger); ok {
+		return l
+	}
+	return &correlatedLogger{Logger: l}
+}
+
+func (c *correlatedLogger) Debug(ctx context.Context, msg string, fields ...Field) {
+	c.Logger.Debug(TraceContext(ctx), msg, fields...)
+}
+
+func (c *correlatedLogger) Info(ctx context.Context, msg string, fields ...Field) {
+	c.Logger.Info(TraceContext(ctx), msg, fields...)
+}
+
+func (c *correlatedLogger) Warn(ctx context.Context, msg string, fields ...Field) {
+	c.Logger.Warn(TraceContext(ctx), msg, fields...)
+}
+
+func (c *correlatedLogger) Error(ctx context.Context, msg string, fields ...Field) {
+	c.Logger.Error(TraceContext(ctx), msg, fields...)
+}
+
+func (c *correlatedLogger) Fatal(ctx context.Context, msg string, fields ...Field) {
+	c.Logger.Fatal(TraceContext(ctx), msg, fields...)
+}
+
+func (c *correlatedLogger) Panic(ctx context.Context, msg string, fields ...Field) {
+	c.Logger.Panic(TraceContext(ctx), msg, fields...)
+}
+
+func (c *correlatedLogger) Debugf(ctx context.Context, format string, args ...any) {
+	c.Logger.Debugf(TraceContext(ctx), format, args...)
+}
+
+func (c *correlatedLogger) Infof(ctx context.Context, format string, args ...any) {
+	c.Logger.Infof(TraceContext(ctx), format, args...)
+}
+
+func (c *correlatedLogger) Warnf(ctx context.Context, format string, args ...any) {
+	c.Logger.Warnf(TraceContext(ctx), format, args...)
+}
+
+func (c *correlatedLogger) Errorf(ctx context.Context, format string, args ...any) {
+	c.Logger.Errorf(TraceContext(ctx), format, args...)
+}
+
+func (c *correlatedLogger) Fatalf(ctx context.Context, format string, args ...any) {
+	c.Logger.Fatalf(TraceContext(ctx), format, args...)
+}
+
+func (c *correlatedLogger) Panicf(ctx context.Context, format string, args ...any) {
+	c.Logger.Panicf(TraceContext(ctx), format, args...)
+}
+
+func (c *correlatedLogger) DebugWithCode(ctx context.Context, code, msg string, fields ...Field) {
+	c.Logger.DebugWithCode(TraceContext(ctx), code, msg, fields...)
+}
+
+func (c *correlatedLogger) InfoWithCode(ctx context.Context, code, msg string, fields ...Field) {
+	c.Logger.InfoWithCode(TraceContext(ctx), code, msg, fields...)
+}
+
+func (c *correlatedLogger) WarnWithCode(ctx context.Context, code, msg string, fields ...Field) {
+	c.Logger.WarnWithCode(TraceContext(ctx), code, msg, fields...)
+}
+
+func (c *correlatedLogger) ErrorWithCode(ctx context.Context, code, msg string, fields ...Field) {
+	c.Logger.ErrorWithCode(TraceContext(ctx), code, msg, fields...)
+}
+
+func (c *correlatedLogger) WithFields(fields ...Field) Logger {
+	return &correlatedLogger{Logger: c.Logger.WithFields(fields...)}
+}
+
+func (c *correlatedLogger) WithContext(ctx context.Context) Logger {
+	return &correlatedLogger{Logger: c.Logger.WithContext(TraceContext(ctx))}
+}
+
+func (c *correlatedLogger) Clone() Logger {
+	return &correlatedLogger{Logger: c.Logger.Clone()}
+}
+
+// TraceCorrelationHook hook que adiciona trace_id/span_id nas entradas de log
+type TraceCorrelationHook struct {
+	*baseHook
+}
+
+// NewTraceCorrelationHook cria um hook de correlação de traces
+func NewTraceCorrelationHook() *TraceCorrelationHook {
+	return &TraceCorrelationHook{
+		baseHook: NewBaseHook("trace_correlation"),
+	}
+}
+
+// Execute adiciona os campos de correlação do span ativo na entrada
+func (t *TraceCorrelationHook) Execute(ctx context.Context, entry *interfaces.LogEntry) error {
+	if entry == nil {
+		return nil
+	}
+	if entry.Fields == nil {
+		entry.Fields = make(map[string]any)
+	}
+
+	for _, field := range TraceFields(ctx) {
+		if _, exists := entry.Fields[field.Key]; !exists {
+			entry.Fields[field.Key] = field.Value
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/fsvxavier/nexs-lib/observability/logger"
+	"github.com/fsvxavier/nexs-lib/observability/logger/interfaces"
+	"github.com/fsvxavier/nexs-lib/observability/logger/mocks"
+)
+
+func newTestTracer() (*tracetest.SpanRecorder, *sdktrace.TracerProvider) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	return recorder, tp
+}
+
+func TestTraceContext(t *testing.T) {
+	_, tp := newTestTracer()
+	ctx, span := tp.Tracer("test").Start(context.Background(), "op")
+	defer span.End()
+
+	traced := logger.TraceContext(ctx)
+	sc := span.SpanContext()
+
+	if got := traced.Value(logger.TraceIDKey); got != sc.TraceID().String() {
+		t.Errorf("Expected trace_id %s, got %v", sc.TraceID(), got)
+	}
+	if got := traced.Value(logger.SpanIDKey); got != sc.SpanID().String() {
+		t.Errorf("Expected span_id %s, got %v", sc.SpanID(), got)
+	}
+
+	// Sem span ativo o contexto deve ser retornado inalterado
+	plain := context.Background()
+	if logger.TraceContext(plain) != plain {
+		t.Error("Expected context without span to be returned unchanged")
+	}
+
+	// Valores explícitos não devem ser sobrescritos
+	custom := context.WithValue(ctx, logger.TraceIDKey, "custom")
+	if got := logger.TraceContext(custom).Value(logger.TraceIDKey); got != "custom" {
+		t.Errorf("Expected existing trace_id to be preserved, got %v", got)
+	}
+}
+
+func TestCorrelatedLogger(t *testing.T) {
+	_, tp := newTestTracer()
+	ctx, span := tp.Tracer("test").Start(context.Background(), "op")
+	defer span.End()
+
+	mock := mocks.NewMockLogger()
+	l := logger.NewCorrelatedLogger(mock)
+	l.Info(ctx, "correlated")
+
+	logs := mock.GetLogs()
+	if len(logs) != 1 {
+		t.Fatalf("Expected 1 log, got %d", len(logs))
+	}
+	if got := logs[0].Context.Value(logger.TraceIDKey); got != span.SpanContext().TraceID().String() {
+		t.Errorf("Expected trace_id in log context, got %v", got)
+	}
+
+	if logger.NewCorrelatedLogger(l) != l {
+		t.Error("Expected already correlated logger to be returned as is")
+	}
+}
+
+func TestGlobalTraceCorrelation(t *testing.T) {
+	var buf bytes.Buffer
+	if err := logger.SetProvider("slog", &logger.Config{
+		Level:  logger.InfoLevel,
+		Format: logger.JSONFormat,
+		Output: &buf,
+	}); err != nil {
+		t.Fatalf("Failed to set provider: %v", err)
+	}
+
+	if !logger.IsTraceCorrelationEnabled() {
+		t.Fatal("Expected trace correlation to be enabled by default")
+	}
+
+	_, tp := newTestTracer()
+	ctx, span := tp.Tracer("test").Start(context.Background(), "op")
+	defer span.End()
+
+	logger.Info(ctx, "global correlated")
+
+	if !strings.Contains(buf.String(), span.SpanContext().TraceID().String()) {
+		t.Errorf("Expected trace_id in output: %s", buf.String())
+	}
+
+	logger.EnableTraceCorrelation(false)
+	defer logger.EnableTraceCorrelation(true)
+	buf.Reset()
+	logger.Info(ctx, "not correlated")
+
+	if strings.Contains(buf.String(), span.SpanContext().TraceID().String()) {
+		t.Errorf("Expected no trace_id after opting out: %s", buf.String())
+	}
+}
+
+func TestRecordSpanError(t *testing.T) {
+	recorder, tp := newTestTracer()
+	ctx, span := tp.Tracer("test").Start(context.Background(), "op")
+
+	mock := mocks.NewMockLogger()
+	logger.RecordSpanError(ctx, mock, errors.New("boom"), "operation failed", logger.String("key", "value"))
+	span.End()
+
+	ended := recorder.Ended()
+	if len(ended) != 1 {
+		t.Fatalf("Expected 1 ended span, got %d", len(ended))
+	}
+	if ended[0].Status().Code != codes.Error {
+		t.Errorf("Expected span status error, got %v", ended[0].Status().Code)
+	}
+	if len(ended[0].Events()) == 0 {
+		t.Error("Expected error event recorded on span")
+	}
+
+	logs := mock.GetLogs()
+	if len(logs) != 1 || logs[0].Level != logger.ErrorLevel {
+		t.Fatalf("Expected 1 error log, got %+v", logs)
+	}
+	if got := logs[0].Context.Value(logger.SpanIDKey); got != span.SpanContext().SpanID().String() {
+		t.Errorf("Expected span_id in log context, got %v", got)
+	}
+}
+
+func TestTraceCorrelationHook(t *testing.T) {
+	_, tp := newTestTracer()
+	ctx, span := tp.Tracer("test").Start(context.Background(), "op")
+	defer span.End()
+
+	hook := logger.NewTraceCorrelationHook()
+	entry := &interfaces.LogEntry{}
+	if err := hook.Execute(ctx, entry); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if entry.Fields["trace_id"] != span.SpanContext().TraceID().String() {
+		t.Errorf("Expected trace_id field, got %v", entry.Fields["trace_id"])
+	}
+	if entry.Fields["span_id"] != span.SpanContext().SpanID().String() {
+		t.Errorf("Expected span_id field, got %v", entry.Fields["span_id"])
+	}
+}
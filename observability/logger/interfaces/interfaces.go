@@ -188,6 +188,7 @@ const (
 	SpanIDKey    ContextKey = "span_id"
 	UserIDKey    ContextKey = "user_id"
 	RequestIDKey ContextKey = "request_id"
+	TenantIDKey  ContextKey = "tenant_id"
 )
 
 // Metrics interface para métricas de logging
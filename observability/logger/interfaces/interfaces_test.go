@@ -57,6 +57,7 @@ func TestContextKey_Constants(t *testing.T) {
 		{SpanIDKey, "span_id"},
 		{UserIDKey, "user_id"},
 		{RequestIDKey, "request_id"},
+		{TenantIDKey, "tenant_id"},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,463 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+)
+
+// DefaultLevelTTL é a duração das alterações feitas pelo LevelHandler sem ttl
+const DefaultLevelTTL = 15 * time.Minute
+
+// ErrInvalidLevel é retornado por ParseLevel para níveis desconhecidos
+var ErrInvalidLevel = errors.New("logger: invalid level")
+
+// ParseLevel converte o nome de um nível ("debug", "INFO", "warning"...)
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return DebugLevel, nil
+	case "info":
+		return InfoLevel, nil
+	case "warn", "warning":
+		return WarnLevel, nil
+	case "error":
+		return ErrorLevel, nil
+	case "fatal":
+		return FatalLevel, nil
+	case "panic":
+		return PanicLevel, nil
+	}
+	return InfoLevel, fmt.Errorf("%w: %q", ErrInvalidLevel, s)
+}
+
+// LevelOverride é um nível alterado em tempo de execução
+type LevelOverride struct {
+	Level Level
+	// ExpiresAt instante em que o nível é revertido; zero quando permanente
+	ExpiresAt time.Time
+}
+
+// LevelState é o estado dos níveis de um LevelController
+type LevelState struct {
+	// Level nível global efetivo
+	Level Level
+	// ExpiresAt instante em que o nível global volta ao padrão; zero quando
+	// não há alteração temporária
+	ExpiresAt time.Time
+	// Modules níveis por módulo
+	Modules map[string]LevelOverride
+}
+
+// LevelOption configura um LevelController
+type LevelOption func(*LevelController)
+
+// WithLevelClock define o relógio das expirações (padrão clock.Real());
+// testes usam clock.NewFake
+func WithLevelClock(c clock.Clock) LevelOption {
+	return func(lc *LevelController) {
+		lc.clock = clock.OrReal(c)
+	}
+}
+
+// WithDefaultLevel define o nível global padrão (padrão: o nível atual do
+// logger base)
+func WithDefaultLevel(level Level) LevelOption {
+	return func(lc *LevelController) {
+		lc.base = level
+	}
+}
+
+// LevelController altera os níveis de log em tempo de execução, globalmente e
+// por módulo, com reversão automática após um TTL. A filtragem é feita pelos
+// loggers retornados por Module, independente do provider: como os providers
+// também filtram pelo próprio nível, configure o logger base em DebugLevel e
+// o nível padrão com WithDefaultLevel, e use os loggers do controller em vez
+// do logger base.
+type LevelController struct {
+	logger Logger
+	clock  clock.Clock
+
+	mu      sync.RWMutex
+	base    Level
+	global  *levelEntry
+	modules map[string]*levelEntry
+}
+
+// levelEntry é uma alteração de nível, com o timer da sua expiração
+type levelEntry struct {
+	level     Level
+	expiresAt time.Time
+	timer     clock.Timer
+}
+
+// NewLevelController cria um LevelController sobre o logger base l
+func NewLevelController(l Logger, opts ...LevelOption) *LevelController {
+	lc := &LevelController{
+		logger:  l,
+		clock:   clock.Real(),
+		base:    l.GetLevel(),
+		modules: make(map[string]*levelEntry),
+	}
+	for _, opt := range opts {
+		opt(lc)
+	}
+	return lc
+}
+
+// SetLevel altera o nível global. Com ttl positivo o nível volta ao padrão
+// após ttl; senão torna-se o novo padrão.
+func (lc *LevelController) SetLevel(level Level, ttl time.Duration) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	lc.stop(lc.global)
+	lc.global = nil
+	if ttl <= 0 {
+		lc.base = level
+	} else {
+		entry := &levelEntry{level: level, expiresAt: lc.clock.Now().Add(ttl)}
+		entry.timer = lc.clock.AfterFunc(ttl, func() { lc.expireGlobal(entry) })
+		lc.global = entry
+	}
+}
+
+// ResetLevel desfaz a alteração temporária do nível global
+func (lc *LevelController) ResetLevel() {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	lc.stop(lc.global)
+	lc.global = nil
+}
+
+// SetModuleLevel altera o nível de um módulo e dos seus submódulos: "db"
+// vale para "db", "db/postgres" e "db.postgres", a menos que o submódulo
+// tenha nível próprio. Com ttl positivo a alteração é revertida após ttl.
+func (lc *LevelController) SetModuleLevel(module string, level Level, ttl time.Duration) error {
+	module = strings.Trim(module, "/.")
+	if module == "" {
+		return errors.New("logger: module name is required")
+	}
+
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	lc.stop(lc.modules[module])
+	entry := &levelEntry{level: level}
+	if ttl > 0 {
+		entry.expiresAt = lc.clock.Now().Add(ttl)
+		entry.timer = lc.clock.AfterFunc(ttl, func() { lc.expireModule(module, entry) })
+	}
+	lc.modules[module] = entry
+	return nil
+}
+
+// ResetModuleLevel remove o nível do módulo, que volta a seguir o nível
+// do módulo pai ou o global
+func (lc *LevelController) ResetModuleLevel(module string) {
+	module = strings.Trim(module, "/.")
+
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	lc.stop(lc.modules[module])
+	delete(lc.modules, module)
+}
+
+// Level retorna o nível efetivo do módulo: o do prefixo mais longo com nível
+// próprio, senão o global. module vazio retorna o nível global.
+func (lc *LevelController) Level(module string) Level {
+	lc.mu.RLock()
+	defer lc.mu.RUnlock()
+	return lc.levelFor(module)
+}
+
+// Enabled informa se as entradas de level do módulo são emitidas
+func (lc *LevelController) Enabled(module string, level Level) bool {
+	return level >= lc.Level(module)
+}
+
+// State retorna o nível global e os níveis por módulo
+func (lc *LevelController) State() LevelState {
+	lc.mu.RLock()
+	defer lc.mu.RUnlock()
+
+	state := LevelState{Level: lc.globalLevel(), Modules: make(map[string]LevelOverride, len(lc.modules))}
+	if lc.global != nil {
+		state.ExpiresAt = lc.global.expiresAt
+	}
+	for module, entry := range lc.modules {
+		state.Modules[module] = LevelOverride{Level: entry.level, ExpiresAt: entry.expiresAt}
+	}
+	return state
+}
+
+// Module retorna um logger cujas entradas são filtradas pelo nível do módulo.
+// module vazio retorna um logger que segue o nível global.
+func (lc *LevelController) Module(module string) Logger {
+	return &moduleLogger{Logger: lc.logger, controller: lc, module: strings.Trim(module, "/.")}
+}
+
+func (lc *LevelController) expireGlobal(entry *levelEntry) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	// Ignora timers de alterações já substituídas
+	if lc.global == entry {
+		lc.global = nil
+	}
+}
+
+func (lc *LevelController) expireModule(module string, entry *levelEntry) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	if lc.modules[module] == entry {
+		delete(lc.modules, module)
+	}
+}
+
+func (lc *LevelController) stop(entry *levelEntry) {
+	if entry != nil && entry.timer != nil {
+		entry.timer.Stop()
+	}
+}
+
+func (lc *LevelController) globalLevel() Level {
+	if lc.global != nil {
+		return lc.global.level
+	}
+	return lc.base
+}
+
+func (lc *LevelController) levelFor(module string) Level {
+	module = strings.Trim(module, "/.")
+	for module != "" {
+		if entry, ok := lc.modules[module]; ok {
+			return entry.level
+		}
+		i := strings.LastIndexAny(module, "/.")
+		if i < 0 {
+			break
+		}
+		module = module[:i]
+	}
+	return lc.globalLevel()
+}
+
+// levelRequest é o corpo aceito pelo LevelHandler
+type levelRequest struct {
+	Level  string `json:"level"`
+	Module string `json:"module,omitempty"`
+	// TTL duração no formato de time.ParseDuration; "0" torna a alteração
+	// permanente e vazio usa DefaultLevelTTL
+	TTL string `json:"ttl,omitempty"`
+}
+
+// levelOverrideResponse é um nível no corpo de resposta do LevelHandler
+type levelOverrideResponse struct {
+	Level     string     `json:"level"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// levelStateResponse é o corpo de resposta do LevelHandler
+type levelStateResponse struct {
+	levelOverrideResponse
+	Modules map[string]levelOverrideResponse `json:"modules"`
+}
+
+// LevelHandler expõe o LevelController para administração:
+//
+//	GET    retorna o nível global e os níveis por módulo
+//	PUT    altera um nível: {"level": "debug", "module": "db/postgres", "ttl": "10m"}
+//	DELETE desfaz a alteração do nível global, ou do módulo em ?module=
+//
+// Sem module o PUT altera o nível global. Sem ttl a alteração vale por
+// DefaultLevelTTL; "0" a torna permanente. O handler não autentica as
+// requisições: monte-o em uma rota administrativa protegida.
+func (lc *LevelController) LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			if err := lc.handleSet(w, r); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		case http.MethodDelete:
+			if module := r.URL.Query().Get("module"); module != "" {
+				lc.ResetModuleLevel(module)
+			} else {
+				lc.ResetLevel()
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST, DELETE")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(newLevelStateResponse(lc.State()))
+	})
+}
+
+func (lc *LevelController) handleSet(w http.ResponseWriter, r *http.Request) error {
+	var req levelRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&req); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	level, err := ParseLevel(req.Level)
+	if err != nil {
+		return err
+	}
+	ttl := DefaultLevelTTL
+	if req.TTL != "" {
+		if ttl, err = time.ParseDuration(req.TTL); err != nil || ttl < 0 {
+			return fmt.Errorf("invalid ttl: %q", req.TTL)
+		}
+	}
+
+	if req.Module == "" {
+		lc.SetLevel(level, ttl)
+		return nil
+	}
+	return lc.SetModuleLevel(req.Module, level, ttl)
+}
+
+func newLevelOverrideResponse(level Level, expiresAt time.Time) levelOverrideResponse {
+	resp := levelOverrideResponse{Level: strings.ToLower(level.String())}
+	if !expiresAt.IsZero() {
+		resp.ExpiresAt = &expiresAt
+	}
+	return resp
+}
+
+func newLevelStateResponse(state LevelState) levelStateResponse {
+	resp := levelStateResponse{
+		levelOverrideResponse: newLevelOverrideResponse(state.Level, state.ExpiresAt),
+		Modules:               make(map[string]levelOverrideResponse, len(state.Modules)),
+	}
+	for module, override := range state.Modules {
+		resp.Modules[module] = newLevelOverrideResponse(override.Level, override.ExpiresAt)
+	}
+	return resp
+}
+
+// moduleLogger filtra as entradas pelo nível do módulo no LevelController.
+// Fatal e Panic nunca são filtrados.
+type moduleLogger struct {
+	Logger
+	controller *LevelController
+	module     string
+}
+
+func (m *moduleLogger) enabled(level Level) bool {
+	return m.controller.Enabled(m.module, level)
+}
+
+func (m *moduleLogger) Debug(ctx context.Context, msg string, fields ...Field) {
+	if m.enabled(DebugLevel) {
+		m.Logger.Debug(ctx, msg, fields...)
+	}
+}
+
+func (m *moduleLogger) Info(ctx context.Context, msg string, fields ...Field) {
+	if m.enabled(InfoLevel) {
+		m.Logger.Info(ctx, msg, fields...)
+	}
+}
+
+func (m *moduleLogger) Warn(ctx context.Context, msg string, fields ...Field) {
+	if m.enabled(WarnLevel) {
+		m.Logger.Warn(ctx, msg, fields...)
+	}
+}
+
+func (m *moduleLogger) Error(ctx context.Context, msg string, fields ...Field) {
+	if m.enabled(ErrorLevel) {
+		m.Logger.Error(ctx, msg, fields...)
+	}
+}
+
+func (m *moduleLogger) Debugf(ctx context.Context, format string, args ...any) {
+	if m.enabled(DebugLevel) {
+		m.Logger.Debugf(ctx, format, args...)
+	}
+}
+
+func (m *moduleLogger) Infof(ctx context.Context, format string, args ...any) {
+	if m.enabled(InfoLevel) {
+		m.Logger.Infof(ctx, format, args...)
+	}
+}
+
+func (m *moduleLogger) Warnf(ctx context.Context, format string, args ...any) {
+	if m.enabled(WarnLevel) {
+		m.Logger.Warnf(ctx, format, args...)
+	}
+}
+
+func (m *moduleLogger) Errorf(ctx context.Context, format string, args ...any) {
+	if m.enabled(ErrorLevel) {
+		m.Logger.Errorf(ctx, format, args...)
+	}
+}
+
+func (m *moduleLogger) DebugWithCode(ctx context.Context, code, msg string, fields ...Field) {
+	if m.enabled(DebugLevel) {
+		m.Logger.DebugWithCode(ctx, code, msg, fields...)
+	}
+}
+
+func (m *moduleLogger) InfoWithCode(ctx context.Context, code, msg string, fields ...Field) {
+	if m.enabled(InfoLevel) {
+		m.Logger.InfoWithCode(ctx, code, msg, fields...)
+	}
+}
+
+func (m *moduleLogger) WarnWithCode(ctx context.Context, code, msg string, fields ...Field) {
+	if m.enabled(WarnLevel) {
+		m.Logger.WarnWithCode(ctx, code, msg, fields...)
+	}
+}
+
+func (m *moduleLogger) ErrorWithCode(ctx context.Context, code, msg string, fields ...Field) {
+	if m.enabled(ErrorLevel) {
+		m.Logger.ErrorWithCode(ctx, code, msg, fields...)
+	}
+}
+
+func (m *moduleLogger) WithFields(fields ...Field) Logger {
+	return &moduleLogger{Logger: m.Logger.WithFields(fields...), controller: m.controller, module: m.module}
+}
+
+func (m *moduleLogger) WithContext(ctx context.Context) Logger {
+	return &moduleLogger{Logger: m.Logger.WithContext(ctx), controller: m.controller, module: m.module}
+}
+
+func (m *moduleLogger) Clone() Logger {
+	return &moduleLogger{Logger: m.Logger.Clone(), controller: m.controller, module: m.module}
+}
+
+// SetLevel altera permanentemente o nível do módulo, ou o global no logger
+// sem módulo
+func (m *moduleLogger) SetLevel(level Level) {
+	if m.module == "" {
+		m.controller.SetLevel(level, 0)
+		return
+	}
+	_ = m.controller.SetModuleLevel(m.module, level, 0)
+}
+
+// GetLevel retorna o nível efetivo do módulo
+func (m *moduleLogger) GetLevel() Level {
+	return m.controller.Level(m.module)
+}
@@ -0,0 +1,199 @@
+package logger_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/observability/logger"
+	"github.com/fsvxavier/nexs-lib/observability/logger/mocks"
+)
+
+func newTestLevelController() (*mocks.MockLogger, *logger.LevelController, func(time.Duration)) {
+	mock := mocks.NewMockLogger()
+	mock.SetLevel(logger.DebugLevel)
+	fake := newTestClock()
+	lc := logger.NewLevelController(mock, logger.WithDefaultLevel(logger.InfoLevel), logger.WithLevelClock(fake))
+	return mock, lc, fake.Advance
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected logger.Level
+	}{
+		{"debug", logger.DebugLevel},
+		{"INFO", logger.InfoLevel},
+		{" warning ", logger.WarnLevel},
+		{"Error", logger.ErrorLevel},
+	}
+	for _, tt := range tests {
+		level, err := logger.ParseLevel(tt.input)
+		if err != nil || level != tt.expected {
+			t.Errorf("ParseLevel(%q) = %v, %v, want %v", tt.input, level, err, tt.expected)
+		}
+	}
+
+	if _, err := logger.ParseLevel("verbose"); !errors.Is(err, logger.ErrInvalidLevel) {
+		t.Errorf("Expected ErrInvalidLevel, got %v", err)
+	}
+}
+
+func TestLevelController_ModulePrefixes(t *testing.T) {
+	mock, lc, _ := newTestLevelController()
+	ctx := context.Background()
+
+	if err := lc.SetModuleLevel("db", logger.DebugLevel, 0); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := lc.SetModuleLevel("db/postgres/pool", logger.ErrorLevel, 0); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	lc.Module("db/postgres").Debug(ctx, "query")
+	lc.Module("db.valkey").Debug(ctx, "command")
+	lc.Module("db/postgres/pool").Warn(ctx, "pool exhausted")
+	lc.Module("httpclient").Debug(ctx, "request")
+	lc.Module("dbx").Debug(ctx, "not a submodule")
+	lc.Module("").Info(ctx, "root")
+
+	var messages []string
+	for _, entry := range mock.GetLogs() {
+		messages = append(messages, entry.Message)
+	}
+	if got := strings.Join(messages, ","); got != "query,command,root" {
+		t.Errorf("Unexpected entries %q", got)
+	}
+
+	if err := lc.SetModuleLevel("/", logger.DebugLevel, 0); err == nil {
+		t.Error("Expected an error for an empty module")
+	}
+}
+
+func TestLevelController_RevertsAfterTTL(t *testing.T) {
+	_, lc, advance := newTestLevelController()
+
+	lc.SetLevel(logger.DebugLevel, time.Minute)
+	if err := lc.SetModuleLevel("db", logger.WarnLevel, 2*time.Minute); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if lc.Level("") != logger.DebugLevel || lc.Level("db/postgres") != logger.WarnLevel {
+		t.Fatalf("Unexpected levels %v, %v", lc.Level(""), lc.Level("db/postgres"))
+	}
+
+	advance(time.Minute)
+	if lc.Level("") != logger.InfoLevel {
+		t.Errorf("Expected the global level reverted, got %v", lc.Level(""))
+	}
+	if lc.Level("db") != logger.WarnLevel {
+		t.Errorf("Expected the module level kept, got %v", lc.Level("db"))
+	}
+
+	advance(time.Minute)
+	if lc.Level("db") != logger.InfoLevel || len(lc.State().Modules) != 0 {
+		t.Errorf("Expected the module level reverted, got %+v", lc.State())
+	}
+}
+
+func TestLevelController_ReplacedOverrideKeepsItsTTL(t *testing.T) {
+	_, lc, advance := newTestLevelController()
+
+	lc.SetLevel(logger.DebugLevel, time.Minute)
+	advance(30 * time.Second)
+	lc.SetLevel(logger.WarnLevel, time.Minute)
+
+	// O timer da primeira alteração não reverte a segunda
+	advance(45 * time.Second)
+	if lc.Level("") != logger.WarnLevel {
+		t.Errorf("Expected the second override active, got %v", lc.Level(""))
+	}
+	advance(15 * time.Second)
+	if lc.Level("") != logger.InfoLevel {
+		t.Errorf("Expected the global level reverted, got %v", lc.Level(""))
+	}
+
+	// Sem ttl o nível torna-se o novo padrão
+	lc.SetLevel(logger.ErrorLevel, 0)
+	lc.ResetLevel()
+	if lc.Level("") != logger.ErrorLevel {
+		t.Errorf("Expected the new default level, got %v", lc.Level(""))
+	}
+}
+
+func TestLevelController_ModuleLoggerDerivatives(t *testing.T) {
+	mock, lc, _ := newTestLevelController()
+	ctx := context.Background()
+
+	cache := lc.Module("cache").WithContext(ctx)
+	cache.Debug(ctx, "hidden")
+	cache.SetLevel(logger.DebugLevel)
+	cache.Debug(ctx, "visible")
+	cache.Debugf(ctx, "key %s", "a")
+
+	if cache.GetLevel() != logger.DebugLevel || lc.Level("cache") != logger.DebugLevel {
+		t.Errorf("Expected the module level set through the logger")
+	}
+	if got := mock.GetLogCount(); got != 2 {
+		t.Errorf("Expected 2 entries, got %d", got)
+	}
+}
+
+func TestLevelController_LevelHandler(t *testing.T) {
+	_, lc, _ := newTestLevelController()
+	handler := lc.LevelHandler()
+
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := do(http.MethodPut, "/admin/log-level", `{"level":"debug","module":"db/postgres","ttl":"10m"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var state struct {
+		Level   string `json:"level"`
+		Modules map[string]struct {
+			Level     string     `json:"level"`
+			ExpiresAt *time.Time `json:"expires_at"`
+		} `json:"modules"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &state); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	module := state.Modules["db/postgres"]
+	if state.Level != "info" || module.Level != "debug" || module.ExpiresAt == nil {
+		t.Errorf("Unexpected state %s", rec.Body)
+	}
+
+	// Sem ttl vale por DefaultLevelTTL
+	do(http.MethodPut, "/admin/log-level", `{"level":"warn"}`)
+	if expiresAt := lc.State().ExpiresAt; expiresAt.Sub(newTestClock().Now()) != logger.DefaultLevelTTL {
+		t.Errorf("Expected the default TTL, got %v", expiresAt)
+	}
+
+	do(http.MethodDelete, "/admin/log-level?module=db/postgres", "")
+	do(http.MethodDelete, "/admin/log-level", "")
+	if current := lc.State(); current.Level != logger.InfoLevel || len(current.Modules) != 0 {
+		t.Errorf("Expected the levels reset, got %+v", current)
+	}
+
+	for _, tt := range []struct{ method, body string }{
+		{http.MethodPut, `{"level":"verbose"}`},
+		{http.MethodPut, `{"level":"debug","ttl":"soon"}`},
+		{http.MethodPut, `not json`},
+	} {
+		if rec := do(tt.method, "/admin/log-level", tt.body); rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %d", tt.body, rec.Code)
+		}
+	}
+	if rec := do(http.MethodPatch, "/admin/log-level", ""); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405, got %d", rec.Code)
+	}
+}
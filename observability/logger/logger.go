@@ -37,6 +37,7 @@ const (
 	SpanIDKey    ContextKey = interfaces.SpanIDKey
 	UserIDKey    ContextKey = interfaces.UserIDKey
 	RequestIDKey ContextKey = interfaces.RequestIDKey
+	TenantIDKey  ContextKey = interfaces.TenantIDKey
 )
 
 // DefaultConfig retorna uma configuração padrão
@@ -163,84 +163,84 @@ func Debug(ctx context.Context, msg string, fields ...Field) {
 	globalManager.mu.RLock()
 	logger := globalManager.current
 	globalManager.mu.RUnlock()
-	logger.Debug(ctx, msg, fields...)
+	logger.Debug(correlate(ctx), msg, fields...)
 }
 
 func Info(ctx context.Context, msg string, fields ...Field) {
 	globalManager.mu.RLock()
 	logger := globalManager.current
 	globalManager.mu.RUnlock()
-	logger.Info(ctx, msg, fields...)
+	logger.Info(correlate(ctx), msg, fields...)
 }
 
 func Warn(ctx context.Context, msg string, fields ...Field) {
 	globalManager.mu.RLock()
 	logger := globalManager.current
 	globalManager.mu.RUnlock()
-	logger.Warn(ctx, msg, fields...)
+	logger.Warn(correlate(ctx), msg, fields...)
 }
 
 func Error(ctx context.Context, msg string, fields ...Field) {
 	globalManager.mu.RLock()
 	logger := globalManager.current
 	globalManager.mu.RUnlock()
-	logger.Error(ctx, msg, fields...)
+	logger.Error(correlate(ctx), msg, fields...)
 }
 
 func Fatal(ctx context.Context, msg string, fields ...Field) {
 	globalManager.mu.RLock()
 	logger := globalManager.current
 	globalManager.mu.RUnlock()
-	logger.Fatal(ctx, msg, fields...)
+	logger.Fatal(correlate(ctx), msg, fields...)
 }
 
 func Panic(ctx context.Context, msg string, fields ...Field) {
 	globalManager.mu.RLock()
 	logger := globalManager.current
 	globalManager.mu.RUnlock()
-	logger.Panic(ctx, msg, fields...)
+	logger.Panic(correlate(ctx), msg, fields...)
 }
 
 func Debugf(ctx context.Context, format string, args ...any) {
 	globalManager.mu.RLock()
 	logger := globalManager.current
 	globalManager.mu.RUnlock()
-	logger.Debugf(ctx, format, args...)
+	logger.Debugf(correlate(ctx), format, args...)
 }
 
 func Infof(ctx context.Context, format string, args ...any) {
 	globalManager.mu.RLock()
 	logger := globalManager.current
 	globalManager.mu.RUnlock()
-	logger.Infof(ctx, format, args...)
+	logger.Infof(correlate(ctx), format, args...)
 }
 
 func Warnf(ctx context.Context, format string, args ...any) {
 	globalManager.mu.RLock()
 	logger := globalManager.current
 	globalManager.mu.RUnlock()
-	logger.Warnf(ctx, format, args...)
+	logger.Warnf(correlate(ctx), format, args...)
 }
 
 func Errorf(ctx context.Context, format string, args ...any) {
 	globalManager.mu.RLock()
 	logger := globalManager.current
 	globalManager.mu.RUnlock()
-	logger.Errorf(ctx, format, args...)
+	logger.Errorf(correlate(ctx), format, args...)
 }
 
 func Fatalf(ctx context.Context, format string, args ...any) {
 	globalManager.mu.RLock()
 	logger := globalManager.current
 	globalManager.mu.RUnlock()
-	logger.Fatalf(ctx, format, args...)
+	logger.Fatalf(correlate(ctx), format, args...)
 }
 
 func Panicf(ctx context.Context, format string, args ...any) {
 	globalManager.mu.RLock()
 	logger := globalManager.current
 	globalManager.mu.RUnlock()
-	logger.Panicf(ctx, format, args...)
+	logger.Panicf(correlate(ctx), format, args...)
 }
 
 func WithFields(fields ...Field) Logger {
@@ -254,7 +254,7 @@ func WithContext(ctx context.Context) Logger {
 	globalManager.mu.RLock()
 	logger := globalManager.current
 	globalManager.mu.RUnlock()
-	return logger.WithContext(ctx)
+	return logger.WithContext(correlate(ctx))
 }
 
 // Métodos globais com código
@@ -262,28 +262,28 @@ func DebugWithCode(ctx context.Context, code, msg string, fields ...Field) {
 	globalManager.mu.RLock()
 	logger := globalManager.current
 	globalManager.mu.RUnlock()
-	logger.DebugWithCode(ctx, code, msg, fields...)
+	logger.DebugWithCode(correlate(ctx), code, msg, fields...)
 }
 
 func InfoWithCode(ctx context.Context, code, msg string, fields ...Field) {
 	globalManager.mu.RLock()
 	logger := globalManager.current
 	globalManager.mu.RUnlock()
-	logger.InfoWithCode(ctx, code, msg, fields...)
+	logger.InfoWithCode(correlate(ctx), code, msg, fields...)
 }
 
 func WarnWithCode(ctx context.Context, code, msg string, fields ...Field) {
 	globalManager.mu.RLock()
 	logger := globalManager.current
 	globalManager.mu.RUnlock()
-	logger.WarnWithCode(ctx, code, msg, fields...)
+	logger.WarnWithCode(correlate(ctx), code, msg, fields...)
 }
 
 func ErrorWithCode(ctx context.Context, code, msg string, fields ...Field) {
 	globalManager.mu.RLock()
 	logger := globalManager.current
 	globalManager.mu.RUnlock()
-	logger.ErrorWithCode(ctx, code, msg, fields...)
+	logger.ErrorWithCode(correlate(ctx), code, msg, fields...)
 }
 
 // noopLogger implementação vazia para quando nenhum provider está configurado
@@ -145,6 +145,9 @@ func (bh *bufferedHandler) addContextFields(ctx context.Context, entry *interfac
 	if requestID := ctx.Value(interfaces.RequestIDKey); requestID != nil {
 		entry.Fields["request_id"] = requestID
 	}
+	if tenantID := ctx.Value(interfaces.TenantIDKey); tenantID != nil {
+		entry.Fields["tenant_id"] = tenantID
+	}
 }
 
 // Configure configura o provider com as opções fornecidas
@@ -277,6 +280,10 @@ func (p *Provider) extractContextFields(ctx context.Context) []slog.Attr {
 		attrs = append(attrs, slog.Any(string(logger.RequestIDKey), requestID))
 	}
 
+	if tenantID := ctx.Value(logger.TenantIDKey); tenantID != nil {
+		attrs = append(attrs, slog.Any(string(logger.TenantIDKey), tenantID))
+	}
+
 	return attrs
 }
 
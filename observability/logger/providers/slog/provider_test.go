@@ -111,12 +111,13 @@ func TestSlogProviderContextFields(t *testing.T) {
 	ctx = context.WithValue(ctx, logger.SpanIDKey, "span-456")
 	ctx = context.WithValue(ctx, logger.UserIDKey, "user-789")
 	ctx = context.WithValue(ctx, logger.RequestIDKey, "req-abc")
+	ctx = context.WithValue(ctx, logger.TenantIDKey, "tenant-def")
 
 	provider.Info(ctx, "context test message")
 
 	output := buf.String()
 
-	expectedFields := []string{"trace-123", "span-456", "user-789", "req-abc"}
+	expectedFields := []string{"trace-123", "span-456", "user-789", "req-abc", "tenant-def"}
 	for _, field := range expectedFields {
 		if !strings.Contains(output, field) {
 			t.Errorf("Expected field '%s' in output: %s", field, output)
@@ -267,6 +267,10 @@ func (p *Provider) extractContextFields(ctx context.Context) []zap.Field {
 		fields = append(fields, zap.Any(string(logger.RequestIDKey), requestID))
 	}
 
+	if tenantID := ctx.Value(logger.TenantIDKey); tenantID != nil {
+		fields = append(fields, zap.Any(string(logger.TenantIDKey), tenantID))
+	}
+
 	return fields
 }
 
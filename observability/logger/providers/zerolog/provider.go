@@ -246,6 +246,10 @@ func (p *Provider) extractContextFields(ctx context.Context) map[string]interfac
 		fields[string(logger.RequestIDKey)] = requestID
 	}
 
+	if tenantID := ctx.Value(logger.TenantIDKey); tenantID != nil {
+		fields[string(logger.TenantIDKey)] = tenantID
+	}
+
 	return fields
 }
 
@@ -0,0 +1,120 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fsvxavier/nexs-lib/masking"
+)
+
+// redactingLogger decora um Logger mascarando dados sensíveis dos campos e
+// das mensagens antes de repassá-los
+type redactingLogger struct {
+	Logger
+	redactor *masking.Redactor
+}
+
+// NewRedactingLogger cria um Logger que mascara os campos classificados pelo
+// redactor (email, cpf, password, ...) e os emails, CPFs e cartões
+// encontrados nas mensagens. Os campos originais não são modificados.
+// Campos adicionados por WithFields também são mascarados.
+func NewRedactingLogger(l Logger, r *masking.Redactor) Logger {
+	if l == nil {
+		return nil
+	}
+	if r == nil {
+		r = masking.NewRedactor()
+	}
+	return &redactingLogger{Logger: l, redactor: r}
+}
+
+// fields retorna uma cópia dos campos com os valores mascarados
+func (r *redactingLogger) fields(fields []Field) []Field {
+	if len(fields) == 0 {
+		return fields
+	}
+	out := make([]Field, len(fields))
+	for i, f := range fields {
+		out[i] = Field{Key: f.Key, Value: r.redactor.Value(f.Key, f.Value)}
+	}
+	return out
+}
+
+func (r *redactingLogger) Debug(ctx context.Context, msg string, fields ...Field) {
+	r.Logger.Debug(ctx, r.redactor.Text(msg), r.fields(fields)...)
+}
+
+func (r *redactingLogger) Info(ctx context.Context, msg string, fields ...Field) {
+	r.Logger.Info(ctx, r.redactor.Text(msg), r.fields(fields)...)
+}
+
+func (r *redactingLogger) Warn(ctx context.Context, msg string, fields ...Field) {
+	r.Logger.Warn(ctx, r.redactor.Text(msg), r.fields(fields)...)
+}
+
+func (r *redactingLogger) Error(ctx context.Context, msg string, fields ...Field) {
+	r.Logger.Error(ctx, r.redactor.Text(msg), r.fields(fields)...)
+}
+
+func (r *redactingLogger) Fatal(ctx context.Context, msg string, fields ...Field) {
+	r.Logger.Fatal(ctx, r.redactor.Text(msg), r.fields(fields)...)
+}
+
+func (r *redactingLogger) Panic(ctx context.Context, msg string, fields ...Field) {
+	r.Logger.Panic(ctx, r.redactor.Text(msg), r.fields(fields)...)
+}
+
+// As variantes formatadas são formatadas aqui, pois os argumentos só podem
+// ser mascarados depois de formatados
+
+func (r *redactingLogger) Debugf(ctx context.Context, format string, args ...any) {
+	r.Logger.Debug(ctx, r.redactor.Text(fmt.Sprintf(format, args...)))
+}
+
+func (r *redactingLogger) Infof(ctx context.Context, format string, args ...any) {
+	r.Logger.Info(ctx, r.redactor.Text(fmt.Sprintf(format, args...)))
+}
+
+func (r *redactingLogger) Warnf(ctx context.Context, format string, args ...any) {
+	r.Logger.Warn(ctx, r.redactor.Text(fmt.Sprintf(format, args...)))
+}
+
+func (r *redactingLogger) Errorf(ctx context.Context, format string, args ...any) {
+	r.Logger.Error(ctx, r.redactor.Text(fmt.Sprintf(format, args...)))
+}
+
+func (r *redactingLogger) Fatalf(ctx context.Context, format string, args ...any) {
+	r.Logger.Fatal(ctx, r.redactor.Text(fmt.Sprintf(format, args...)))
+}
+
+func (r *redactingLogger) Panicf(ctx context.Context, format string, args ...any) {
+	r.Logger.Panic(ctx, r.redactor.Text(fmt.Sprintf(format, args...)))
+}
+
+func (r *redactingLogger) DebugWithCode(ctx context.Context, code, msg string, fields ...Field) {
+	r.Logger.DebugWithCode(ctx, code, r.redactor.Text(msg), r.fields(fields)...)
+}
+
+func (r *redactingLogger) InfoWithCode(ctx context.Context, code, msg string, fields ...Field) {
+	r.Logger.InfoWithCode(ctx, code, r.redactor.Text(msg), r.fields(fields)...)
+}
+
+func (r *redactingLogger) WarnWithCode(ctx context.Context, code, msg string, fields ...Field) {
+	r.Logger.WarnWithCode(ctx, code, r.redactor.Text(msg), r.fields(fields)...)
+}
+
+func (r *redactingLogger) ErrorWithCode(ctx context.Context, code, msg string, fields ...Field) {
+	r.Logger.ErrorWithCode(ctx, code, r.redactor.Text(msg), r.fields(fields)...)
+}
+
+func (r *redactingLogger) WithFields(fields ...Field) Logger {
+	return &redactingLogger{Logger: r.Logger.WithFields(r.fields(fields)...), redactor: r.redactor}
+}
+
+func (r *redactingLogger) WithContext(ctx context.Context) Logger {
+	return &redactingLogger{Logger: r.Logger.WithContext(ctx), redactor: r.redactor}
+}
+
+func (r *redactingLogger) Clone() Logger {
+	return &redactingLogger{Logger: r.Logger.Clone(), redactor: r.redactor}
+}
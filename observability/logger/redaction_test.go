@@ -0,0 +1,67 @@
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fsvxavier/nexs-lib/masking"
+	"github.com/fsvxavier/nexs-lib/observability/logger"
+	"github.com/fsvxavier/nexs-lib/observability/logger/mocks"
+)
+
+func TestRedactingLogger(t *testing.T) {
+	mock := mocks.NewMockLogger()
+	l := logger.NewRedactingLogger(mock, masking.NewRedactor(masking.WithTextDetection()))
+
+	payload := map[string]any{"cpf": "123.456.789-09", "plan": "gold"}
+	l.Info(context.Background(), "signup ana.souza@example.com",
+		logger.String("email", "ana.souza@example.com"),
+		logger.String("password", "s3cr3t"),
+		logger.Any("customer", payload),
+		logger.ErrorField(errors.New("card 4111 1111 1111 1111 declined")),
+		logger.String("plan", "gold"),
+	)
+
+	logs := mock.GetLogs()
+	if len(logs) != 1 {
+		t.Fatalf("Expected 1 log, got %d", len(logs))
+	}
+	entry := logs[0]
+	if entry.Message != "signup a********@example.com" {
+		t.Errorf("Expected masked message, got %q", entry.Message)
+	}
+
+	fields := make(map[string]any)
+	for _, f := range entry.Fields {
+		fields[f.Key] = f.Value
+	}
+	if fields["email"] != "a********@example.com" {
+		t.Errorf("Expected masked email, got %v", fields["email"])
+	}
+	if fields["password"] != "********" {
+		t.Errorf("Expected masked password, got %v", fields["password"])
+	}
+	if customer := fields["customer"].(map[string]any); customer["cpf"] != "***.456.789-**" || customer["plan"] != "gold" {
+		t.Errorf("Expected masked nested cpf, got %v", customer)
+	}
+	if payload["cpf"] != "123.456.789-09" {
+		t.Error("Expected original field values to be kept")
+	}
+	if err, ok := fields["error"].(error); !ok || err.Error() != "card 4111 11** **** 1111 declined" {
+		t.Errorf("Expected masked error, got %v", fields["error"])
+	}
+	if fields["plan"] != "gold" {
+		t.Errorf("Expected unclassified field unchanged, got %v", fields["plan"])
+	}
+
+	mock.Reset()
+	l.Warnf(context.Background(), "retrying %s", "ana.souza@example.com")
+	if last := mock.LastLog(); last == nil || last.Message != "retrying a********@example.com" || last.Level != logger.WarnLevel {
+		t.Errorf("Expected masked formatted message, got %+v", last)
+	}
+
+	if logger.NewRedactingLogger(nil, nil) != nil {
+		t.Error("Expected nil logger to stay nil")
+	}
+}
@@ -0,0 +1,387 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	dinterfaces "github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+// Valores padrão do sampling quando o SamplingConfig é nil ou incompleto
+const (
+	DefaultSamplingInitial    = 100
+	DefaultSamplingThereafter = 100
+	DefaultSamplingTick       = time.Second
+)
+
+// SuppressedField é o campo com o número de entradas suprimidas desde a
+// última entrada emitida do mesmo fingerprint
+const SuppressedField = "suppressed"
+
+// FingerprintField é o campo com o fingerprint das entradas do RateLimitedLogger
+const FingerprintField = "fingerprint"
+
+// SamplingStats contadores de um SampledLogger
+type SamplingStats struct {
+	Logged  int64 `json:"logged"`
+	Dropped int64 `json:"dropped"`
+}
+
+// SamplingOption configura um SampledLogger
+type SamplingOption func(*sampler)
+
+// WithSamplingClock define o relógio das janelas de sampling (padrão
+// clock.Real()); testes usam clock.NewFake
+func WithSamplingClock(c clock.Clock) SamplingOption {
+	return func(s *sampler) {
+		s.clock = clock.OrReal(c)
+	}
+}
+
+// sampler conta as entradas de cada nível e mensagem por janela de Tick
+type sampler struct {
+	initial    int
+	thereafter int
+	tick       time.Duration
+	clock      clock.Clock
+
+	mu     sync.Mutex
+	window time.Time
+	counts map[samplingKey]int
+
+	logged  atomic.Int64
+	dropped atomic.Int64
+}
+
+type samplingKey struct {
+	level Level
+	msg   string
+}
+
+// allow informa se a entrada deve ser emitida: as Initial primeiras de cada
+// nível e mensagem na janela, depois uma a cada Thereafter
+func (s *sampler) allow(level Level, msg string) bool {
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	if now.Sub(s.window) >= s.tick {
+		s.window = now
+		clear(s.counts)
+	}
+	key := samplingKey{level: level, msg: msg}
+	s.counts[key]++
+	n := s.counts[key]
+	s.mu.Unlock()
+
+	if n <= s.initial || (s.thereafter > 0 && (n-s.initial)%s.thereafter == 0) {
+		s.logged.Add(1)
+		return true
+	}
+	s.dropped.Add(1)
+	return false
+}
+
+// SampledLogger decora um Logger com sampling independente do provider: em
+// cada janela de Tick, emite as Initial primeiras entradas de cada nível e
+// mensagem e depois uma a cada Thereafter. Os métodos formatados usam o
+// formato como mensagem, agrupando entradas que só diferem nos argumentos.
+// Fatal e Panic nunca são descartados.
+type SampledLogger struct {
+	Logger
+	sampler *sampler
+}
+
+// NewSampledLogger cria um SampledLogger. Com config nil usa
+// DefaultSamplingInitial, DefaultSamplingThereafter e DefaultSamplingTick;
+// Initial e Tick zerados usam os padrões e Thereafter zero descarta todas as
+// entradas após as Initial, como no zap. Loggers derivados por WithFields,
+// WithContext e Clone compartilham os contadores.
+func NewSampledLogger(l Logger, config *SamplingConfig, opts ...SamplingOption) *SampledLogger {
+	s := &sampler{
+		initial:    DefaultSamplingInitial,
+		thereafter: DefaultSamplingThereafter,
+		tick:       DefaultSamplingTick,
+		clock:      clock.Real(),
+		counts:     make(map[samplingKey]int),
+	}
+	if config != nil {
+		if config.Initial > 0 {
+			s.initial = config.Initial
+		}
+		s.thereafter = max(config.Thereafter, 0)
+		if config.Tick > 0 {
+			s.tick = config.Tick
+		}
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return &SampledLogger{Logger: l, sampler: s}
+}
+
+// Stats retorna as entradas emitidas e descartadas pelo sampling
+func (s *SampledLogger) Stats() SamplingStats {
+	return SamplingStats{
+		Logged:  s.sampler.logged.Load(),
+		Dropped: s.sampler.dropped.Load(),
+	}
+}
+
+func (s *SampledLogger) Debug(ctx context.Context, msg string, fields ...Field) {
+	if s.sampler.allow(DebugLevel, msg) {
+		s.Logger.Debug(ctx, msg, fields...)
+	}
+}
+
+func (s *SampledLogger) Info(ctx context.Context, msg string, fields ...Field) {
+	if s.sampler.allow(InfoLevel, msg) {
+		s.Logger.Info(ctx, msg, fields...)
+	}
+}
+
+func (s *SampledLogger) Warn(ctx context.Context, msg string, fields ...Field) {
+	if s.sampler.allow(WarnLevel, msg) {
+		s.Logger.Warn(ctx, msg, fields...)
+	}
+}
+
+func (s *SampledLogger) Error(ctx context.Context, msg string, fields ...Field) {
+	if s.sampler.allow(ErrorLevel, msg) {
+		s.Logger.Error(ctx, msg, fields...)
+	}
+}
+
+func (s *SampledLogger) Debugf(ctx context.Context, format string, args ...any) {
+	if s.sampler.allow(DebugLevel, format) {
+		s.Logger.Debugf(ctx, format, args...)
+	}
+}
+
+func (s *SampledLogger) Infof(ctx context.Context, format string, args ...any) {
+	if s.sampler.allow(InfoLevel, format) {
+		s.Logger.Infof(ctx, format, args...)
+	}
+}
+
+func (s *SampledLogger) Warnf(ctx context.Context, format string, args ...any) {
+	if s.sampler.allow(WarnLevel, format) {
+		s.Logger.Warnf(ctx, format, args...)
+	}
+}
+
+func (s *SampledLogger) Errorf(ctx context.Context, format string, args ...any) {
+	if s.sampler.allow(ErrorLevel, format) {
+		s.Logger.Errorf(ctx, format, args...)
+	}
+}
+
+func (s *SampledLogger) DebugWithCode(ctx context.Context, code, msg string, fields ...Field) {
+	if s.sampler.allow(DebugLevel, code+":"+msg) {
+		s.Logger.DebugWithCode(ctx, code, msg, fields...)
+	}
+}
+
+func (s *SampledLogger) InfoWithCode(ctx context.Context, code, msg string, fields ...Field) {
+	if s.sampler.allow(InfoLevel, code+":"+msg) {
+		s.Logger.InfoWithCode(ctx, code, msg, fields...)
+	}
+}
+
+func (s *SampledLogger) WarnWithCode(ctx context.Context, code, msg string, fields ...Field) {
+	if s.sampler.allow(WarnLevel, code+":"+msg) {
+		s.Logger.WarnWithCode(ctx, code, msg, fields...)
+	}
+}
+
+func (s *SampledLogger) ErrorWithCode(ctx context.Context, code, msg string, fields ...Field) {
+	if s.sampler.allow(ErrorLevel, code+":"+msg) {
+		s.Logger.ErrorWithCode(ctx, code, msg, fields...)
+	}
+}
+
+func (s *SampledLogger) WithFields(fields ...Field) Logger {
+	return &SampledLogger{Logger: s.Logger.WithFields(fields...), sampler: s.sampler}
+}
+
+func (s *SampledLogger) WithContext(ctx context.Context) Logger {
+	return &SampledLogger{Logger: s.Logger.WithContext(ctx), sampler: s.sampler}
+}
+
+func (s *SampledLogger) Clone() Logger {
+	return &SampledLogger{Logger: s.Logger.Clone(), sampler: s.sampler}
+}
+
+// LogFingerprint identifica as entradas repetidas de um erro: o fingerprint
+// de domainerrors (o metadado "fingerprint" ou tipo e código) para domain
+// errors, senão a mensagem, já que mensagens de erro costumam conter IDs
+func LogFingerprint(msg string, err error) string {
+	var domainErr dinterfaces.DomainErrorInterface
+	if errors.As(err, &domainErr) {
+		return domainerrors.Fingerprint(domainErr)
+	}
+	return msg
+}
+
+// RateLimitStats contadores de um RateLimitedLogger
+type RateLimitStats struct {
+	Logged       int64 `json:"logged"`
+	Suppressed   int64 `json:"suppressed"`
+	Fingerprints int   `json:"fingerprints"`
+}
+
+// RateLimitOption configura um RateLimitedLogger
+type RateLimitOption func(*RateLimitedLogger)
+
+// WithRateLimitClock define o relógio das janelas de rate limit (padrão
+// clock.Real()); testes usam clock.NewFake
+func WithRateLimitClock(c clock.Clock) RateLimitOption {
+	return func(r *RateLimitedLogger) {
+		r.clock = clock.OrReal(c)
+	}
+}
+
+// RateLimitedLogger limita os warnings e erros repetidos durante tempestades
+// de erros: emite no máximo limit entradas de cada nível e fingerprint por
+// intervalo e suprime as demais. A próxima entrada emitida do fingerprint
+// traz o número de suprimidas no campo SuppressedField.
+type RateLimitedLogger struct {
+	logger   Logger
+	limit    int
+	interval time.Duration
+	clock    clock.Clock
+
+	mu        sync.Mutex
+	entries   map[rateLimitKey]*rateLimitEntry
+	lastSweep time.Time
+
+	logged     atomic.Int64
+	suppressed atomic.Int64
+}
+
+type rateLimitKey struct {
+	level       Level
+	fingerprint string
+}
+
+type rateLimitEntry struct {
+	window     time.Time
+	count      int
+	suppressed int
+}
+
+// NewRateLimitedLogger cria um RateLimitedLogger que emite até limit entradas
+// por fingerprint a cada interval
+func NewRateLimitedLogger(l Logger, limit int, interval time.Duration, opts ...RateLimitOption) *RateLimitedLogger {
+	r := &RateLimitedLogger{
+		logger:   l,
+		limit:    max(limit, 1),
+		interval: interval,
+		clock:    clock.Real(),
+		entries:  make(map[rateLimitKey]*rateLimitEntry),
+	}
+	if r.interval <= 0 {
+		r.interval = time.Minute
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	r.lastSweep = r.clock.Now()
+	return r
+}
+
+// Warn registra um warning limitado pelo fingerprint da mensagem
+func (r *RateLimitedLogger) Warn(ctx context.Context, msg string, fields ...Field) {
+	if fields, ok := r.allow(WarnLevel, msg, fields); ok {
+		r.logger.Warn(ctx, msg, fields...)
+	}
+}
+
+// Error registra um erro limitado pelo fingerprint da mensagem
+func (r *RateLimitedLogger) Error(ctx context.Context, msg string, fields ...Field) {
+	if fields, ok := r.allow(ErrorLevel, msg, fields); ok {
+		r.logger.Error(ctx, msg, fields...)
+	}
+}
+
+// WarnErr registra um warning com err, limitado pelo LogFingerprint de err
+func (r *RateLimitedLogger) WarnErr(ctx context.Context, err error, msg string, fields ...Field) {
+	if fields, ok := r.allow(WarnLevel, LogFingerprint(msg, err), fields); ok {
+		r.logger.Warn(ctx, msg, append(fields, ErrorField(err))...)
+	}
+}
+
+// ErrorErr registra um erro com err, limitado pelo LogFingerprint de err
+func (r *RateLimitedLogger) ErrorErr(ctx context.Context, err error, msg string, fields ...Field) {
+	if fields, ok := r.allow(ErrorLevel, LogFingerprint(msg, err), fields); ok {
+		r.logger.Error(ctx, msg, append(fields, ErrorField(err))...)
+	}
+}
+
+// Stats retorna as entradas emitidas e suprimidas e os fingerprints em
+// acompanhamento
+func (r *RateLimitedLogger) Stats() RateLimitStats {
+	r.mu.Lock()
+	fingerprints := len(r.entries)
+	r.mu.Unlock()
+
+	return RateLimitStats{
+		Logged:       r.logged.Load(),
+		Suppressed:   r.suppressed.Load(),
+		Fingerprints: fingerprints,
+	}
+}
+
+// allow informa se a entrada deve ser emitida, retornando os campos com o
+// fingerprint e as suprimidas desde a última entrada emitida
+func (r *RateLimitedLogger) allow(level Level, fingerprint string, fields []Field) ([]Field, bool) {
+	now := r.clock.Now()
+
+	r.mu.Lock()
+	r.sweep(now)
+	key := rateLimitKey{level: level, fingerprint: fingerprint}
+	entry, ok := r.entries[key]
+	if !ok {
+		entry = &rateLimitEntry{window: now}
+		r.entries[key] = entry
+	}
+	if now.Sub(entry.window) >= r.interval {
+		entry.window = now
+		entry.count = 0
+	}
+	if entry.count >= r.limit {
+		entry.suppressed++
+		r.mu.Unlock()
+		r.suppressed.Add(1)
+		return nil, false
+	}
+	entry.count++
+	suppressed := entry.suppressed
+	entry.suppressed = 0
+	r.mu.Unlock()
+
+	r.logged.Add(1)
+	fields = append(fields[:len(fields):len(fields)], String(FingerprintField, fingerprint))
+	if suppressed > 0 {
+		fields = append(fields, Int(SuppressedField, suppressed))
+	}
+	return fields, true
+}
+
+// sweep remove, uma vez por intervalo, os fingerprints com a janela expirada
+// e sem suprimidas a reportar. Deve ser chamado com r.mu travado.
+func (r *RateLimitedLogger) sweep(now time.Time) {
+	if now.Sub(r.lastSweep) < r.interval {
+		return
+	}
+	r.lastSweep = now
+	for key, entry := range r.entries {
+		if entry.suppressed == 0 && now.Sub(entry.window) >= r.interval {
+			delete(r.entries, key)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	dinterfaces "github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/fsvxavier/nexs-lib/observability/logger"
+	"github.com/fsvxavier/nexs-lib/observability/logger/mocks"
+)
+
+func newTestClock() *clock.Fake {
+	return clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+}
+
+func fieldValue(fields []logger.Field, key string) any {
+	for _, field := range fields {
+		if field.Key == key {
+			return field.Value
+		}
+	}
+	return nil
+}
+
+func TestSampledLogger_FirstNThenOneInM(t *testing.T) {
+	mock := mocks.NewMockLogger()
+	fake := newTestClock()
+	sampled := logger.NewSampledLogger(mock, &logger.SamplingConfig{Initial: 3, Thereafter: 5, Tick: time.Second},
+		logger.WithSamplingClock(fake))
+	ctx := context.Background()
+
+	for i := 0; i < 13; i++ {
+		sampled.Error(ctx, "connection refused")
+	}
+	// 3 iniciais e a 5ª e a 10ª depois delas
+	if got := len(mock.GetLogsByMessage("connection refused")); got != 5 {
+		t.Errorf("Expected 5 entries, got %d", got)
+	}
+
+	// Outras mensagens e níveis têm contadores próprios
+	sampled.Warn(ctx, "connection refused")
+	sampled.Info(ctx, "cache miss")
+	if got := mock.GetLogCount(); got != 7 {
+		t.Errorf("Expected 7 entries, got %d", got)
+	}
+
+	// Uma nova janela reinicia os contadores
+	fake.Advance(time.Second)
+	sampled.Error(ctx, "connection refused")
+	if got := len(mock.GetLogsByMessage("connection refused")); got != 7 {
+		t.Errorf("Expected the new window to log, got %d entries", got)
+	}
+
+	stats := sampled.Stats()
+	if stats.Logged != 8 || stats.Dropped != 8 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestSampledLogger_ThereafterZeroDropsAll(t *testing.T) {
+	mock := mocks.NewMockLogger()
+	sampled := logger.NewSampledLogger(mock, &logger.SamplingConfig{Initial: 2}, logger.WithSamplingClock(newTestClock()))
+
+	for i := 0; i < 10; i++ {
+		sampled.Infof(context.Background(), "request %d failed", i)
+	}
+	if got := mock.GetLogCount(); got != 2 {
+		t.Errorf("Expected 2 entries, got %d", got)
+	}
+}
+
+func TestRateLimitedLogger_SuppressesByFingerprint(t *testing.T) {
+	mock := mocks.NewMockLogger()
+	fake := newTestClock()
+	limited := logger.NewRateLimitedLogger(mock, 2, time.Minute, logger.WithRateLimitClock(fake))
+	ctx := context.Background()
+
+	// Domain errors com o mesmo tipo e código compartilham o fingerprint
+	for i := 0; i < 5; i++ {
+		err := domainerrors.New(dinterfaces.ExternalServiceError, "PAYMENT_GATEWAY_DOWN", "gateway timeout")
+		limited.ErrorErr(ctx, err, "charge failed")
+	}
+	limited.ErrorErr(ctx, errors.New("order 42 not found"), "lookup failed")
+
+	logs := mock.GetLogsByLevel(logger.ErrorLevel)
+	if len(logs) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(logs))
+	}
+	if fp := fieldValue(logs[0].Fields, logger.FingerprintField); fp != "external_service_error:PAYMENT_GATEWAY_DOWN" {
+		t.Errorf("Unexpected fingerprint %v", fp)
+	}
+
+	// A primeira entrada após a janela reporta as suprimidas
+	fake.Advance(time.Minute)
+	limited.ErrorErr(ctx, domainerrors.New(dinterfaces.ExternalServiceError, "PAYMENT_GATEWAY_DOWN", "gateway timeout"),
+		"charge failed")
+	logs = mock.GetLogsByLevel(logger.ErrorLevel)
+	if suppressed := fieldValue(logs[len(logs)-1].Fields, logger.SuppressedField); suppressed != 3 {
+		t.Errorf("Expected 3 suppressed, got %v", suppressed)
+	}
+
+	stats := limited.Stats()
+	if stats.Logged != 4 || stats.Suppressed != 3 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestRateLimitedLogger_LevelsAreSeparate(t *testing.T) {
+	mock := mocks.NewMockLogger()
+	limited := logger.NewRateLimitedLogger(mock, 1, time.Minute, logger.WithRateLimitClock(newTestClock()))
+	ctx := context.Background()
+
+	limited.Warn(ctx, "slow query")
+	limited.Warn(ctx, "slow query")
+	limited.Error(ctx, "slow query")
+
+	if got := len(mock.GetLogsByLevel(logger.WarnLevel)); got != 1 {
+		t.Errorf("Expected 1 warning, got %d", got)
+	}
+	if got := len(mock.GetLogsByLevel(logger.ErrorLevel)); got != 1 {
+		t.Errorf("Expected 1 error, got %d", got)
+	}
+}
+
+func TestLogFingerprint(t *testing.T) {
+	err := domainerrors.NewWithMetadata(dinterfaces.DatabaseError, "DB_TIMEOUT", "timeout",
+		map[string]interface{}{domainerrors.FingerprintMetadataKey: "orders-db"})
+	if got := logger.LogFingerprint("query failed", err); got != "orders-db" {
+		t.Errorf("Expected the metadata fingerprint, got %q", got)
+	}
+	if got := logger.LogFingerprint("query failed", errors.New("timeout after 5s")); got != "query failed" {
+		t.Errorf("Expected the message, got %q", got)
+	}
+}
@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+)
+
+// DefaultLatencyBuckets limites, em segundos, usados por LatencyHistogram.
+// São os mesmos buckets padrão do cliente Prometheus
+var DefaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// HistogramOption configura o LatencyHistogram
+type HistogramOption func(*histogramConfig)
+
+type histogramConfig struct {
+	description string
+	buckets     []float64
+	clock       clock.Clock
+}
+
+// WithDescription define a descrição do histograma
+func WithDescription(description string) HistogramOption {
+	return func(c *histogramConfig) {
+		c.description = description
+	}
+}
+
+// WithBuckets substitui os limites dos buckets, em segundos
+func WithBuckets(buckets ...float64) HistogramOption {
+	return func(c *histogramConfig) {
+		c.buckets = buckets
+	}
+}
+
+// WithClock define o relógio usado por Start
+func WithClock(c clock.Clock) HistogramOption {
+	return func(cfg *histogramConfig) {
+		cfg.clock = c
+	}
+}
+
+// LatencyHistogram histograma de durações em segundos. Como a medição recebe o
+// contexto, o SDK associa ao bucket o trace ID do span ativo como exemplar
+type LatencyHistogram struct {
+	histogram metric.Float64Histogram
+	clock     clock.Clock
+}
+
+// NewLatencyHistogram cria um histograma de latência no meter informado
+func NewLatencyHistogram(meter metric.Meter, name string, opts ...HistogramOption) (*LatencyHistogram, error) {
+	cfg := histogramConfig{buckets: DefaultLatencyBuckets}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	histogram, err := meter.Float64Histogram(name,
+		metric.WithUnit("s"),
+		metric.WithDescription(cfg.description),
+		metric.WithExplicitBucketBoundaries(cfg.buckets...),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create histogram %s: %w", name, err)
+	}
+
+	return &LatencyHistogram{histogram: histogram, clock: clock.OrReal(cfg.clock)}, nil
+}
+
+// Record registra a duração. O ctx deve ser o do span da operação medida
+func (h *LatencyHistogram) Record(ctx context.Context, d time.Duration, attrs ...attribute.KeyValue) {
+	h.histogram.Record(ctx, d.Seconds(), metric.WithAttributes(attrs...))
+}
+
+// Start inicia a medição e retorna a função que a encerra, registrando a
+// duração com o span ativo em ctx como exemplar
+//
+//	done := latency.Start(ctx)
+//	defer done(attribute.String("route", "/orders"))
+func (h *LatencyHistogram) Start(ctx context.Context) func(attrs ...attribute.KeyValue) {
+	start := h.clock.Now()
+	return func(attrs ...attribute.KeyValue) {
+		h.Record(ctx, h.clock.Since(start), attrs...)
+	}
+}
@@ -0,0 +1,131 @@
+// Package metrics fornece métricas OpenTelemetry com exemplars: medições
+// feitas com um span amostrado no contexto carregam o trace ID e o span ID, de
+// modo que dashboards podem ir de um bucket lento de um histograma direto para
+// um trace real. Os exemplars são exportados via OTLP pelos readers
+// configurados e expostos no formato OpenMetrics pelo Handler, para scrape do
+// Prometheus
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/exemplar"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// Option configura o Provider
+type Option func(*providerConfig)
+
+type providerConfig struct {
+	readers  []sdkmetric.Reader
+	resource *resource.Resource
+	filter   exemplar.Filter
+}
+
+// WithReader adiciona um reader, por exemplo um PeriodicReader com exporter
+// OTLP. Os exemplars seguem junto com os histogramas
+func WithReader(reader sdkmetric.Reader) Option {
+	return func(c *providerConfig) {
+		c.readers = append(c.readers, reader)
+	}
+}
+
+// WithResource define o resource das métricas
+func WithResource(res *resource.Resource) Option {
+	return func(c *providerConfig) {
+		c.resource = res
+	}
+}
+
+// WithExemplarFilter substitui o filtro de exemplars. O padrão,
+// exemplar.TraceBasedFilter, só registra medições com span amostrado
+func WithExemplarFilter(filter exemplar.Filter) Option {
+	return func(c *providerConfig) {
+		c.filter = filter
+	}
+}
+
+// Provider meter provider com exemplars habilitados e exposição OpenMetrics
+type Provider struct {
+	meterProvider *sdkmetric.MeterProvider
+	reader        *sdkmetric.ManualReader
+}
+
+// NewProvider cria o provider. Além dos readers configurados, um reader
+// interno atende o Handler
+func NewProvider(opts ...Option) *Provider {
+	cfg := providerConfig{filter: exemplar.TraceBasedFilter}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	reader := sdkmetric.NewManualReader()
+	options := []sdkmetric.Option{
+		sdkmetric.WithReader(reader),
+		sdkmetric.WithExemplarFilter(cfg.filter),
+	}
+	for _, r := range cfg.readers {
+		options = append(options, sdkmetric.WithReader(r))
+	}
+	if cfg.resource != nil {
+		options = append(options, sdkmetric.WithResource(cfg.resource))
+	}
+
+	return &Provider{
+		meterProvider: sdkmetric.NewMeterProvider(options...),
+		reader:        reader,
+	}
+}
+
+// Meter retorna um meter do provider
+func (p *Provider) Meter(name string, opts ...metric.MeterOption) metric.Meter {
+	return p.meterProvider.Meter(name, opts...)
+}
+
+// MeterProvider retorna o meter provider do SDK, para uso com
+// otel.SetMeterProvider
+func (p *Provider) MeterProvider() *sdkmetric.MeterProvider {
+	return p.meterProvider
+}
+
+// Collect coleta o estado atual das métricas
+func (p *Provider) Collect(ctx context.Context) (metricdata.ResourceMetrics, error) {
+	var rm metricdata.ResourceMetrics
+	if err := p.reader.Collect(ctx, &rm); err != nil {
+		return rm, fmt.Errorf("failed to collect metrics: %w", err)
+	}
+	return rm, nil
+}
+
+// Handler expõe as métricas para scrape. Clientes que aceitam
+// application/openmetrics-text (o Prometheus com exemplar storage habilitado)
+// recebem os exemplars; os demais recebem o formato texto do Prometheus, que
+// não suporta exemplars
+func (p *Provider) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rm, err := p.Collect(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		format := negotiate(r.Header.Get("Accept"))
+		w.Header().Set("Content-Type", format.contentType())
+		if err := writeMetrics(w, rm, format); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// Shutdown finaliza o provider, exportando as métricas pendentes
+func (p *Provider) Shutdown(ctx context.Context) error {
+	if err := p.meterProvider.Shutdown(ctx); err != nil {
+		return fmt.Errorf("failed to shutdown meter provider: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+)
+
+func newTracer(t *testing.T, sampler sdktrace.Sampler) oteltrace.Tracer {
+	t.Helper()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sampler))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	return tp.Tracer("test")
+}
+
+func scrape(t *testing.T, p *Provider, accept string) (string, string) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	rec := httptest.NewRecorder()
+	p.Handler().ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	return string(body), rec.Header().Get("Content-Type")
+}
+
+func TestLatencyHistogram_OpenMetricsExemplars(t *testing.T) {
+	p := NewProvider()
+	defer p.Shutdown(context.Background())
+
+	latency, err := NewLatencyHistogram(p.Meter("test"), "http.server.duration",
+		WithDescription("HTTP request latency"))
+	require.NoError(t, err)
+
+	ctx, span := newTracer(t, sdktrace.AlwaysSample()).Start(context.Background(), "GET /orders")
+	latency.Record(ctx, 200*time.Millisecond, attribute.String("route", "/orders"))
+	span.End()
+
+	// Medições sem span não geram exemplar
+	latency.Record(context.Background(), 3*time.Millisecond, attribute.String("route", "/orders"))
+
+	body, contentType := scrape(t, p, "application/openmetrics-text; version=1.0.0")
+	assert.Contains(t, contentType, "application/openmetrics-text")
+
+	sc := span.SpanContext()
+	assert.Contains(t, body, "# TYPE http_server_duration_seconds histogram\n")
+	assert.Contains(t, body, "# UNIT http_server_duration_seconds seconds\n")
+	assert.Contains(t, body, "# HELP http_server_duration_seconds HTTP request latency\n")
+	assert.Contains(t, body, `http_server_duration_seconds_bucket{route="/orders",le="0.005"} 1`+"\n")
+	assert.Contains(t, body, `http_server_duration_seconds_bucket{route="/orders",le="0.25"} 2 # {trace_id="`+
+		sc.TraceID().String()+`",span_id="`+sc.SpanID().String()+`"} 0.2 `)
+	assert.Contains(t, body, `http_server_duration_seconds_bucket{route="/orders",le="+Inf"} 2`+"\n")
+	assert.Contains(t, body, `http_server_duration_seconds_count{route="/orders"} 2`+"\n")
+	assert.True(t, strings.HasSuffix(body, "# EOF\n"))
+	assert.Equal(t, 1, strings.Count(body, "trace_id="))
+}
+
+func TestHandler_PrometheusFormatWithoutExemplars(t *testing.T) {
+	p := NewProvider()
+	defer p.Shutdown(context.Background())
+
+	latency, err := NewLatencyHistogram(p.Meter("test"), "db.query.duration")
+	require.NoError(t, err)
+	requests, err := p.Meter("test").Int64Counter("http.requests")
+	require.NoError(t, err)
+
+	ctx, span := newTracer(t, sdktrace.AlwaysSample()).Start(context.Background(), "query")
+	latency.Record(ctx, 20*time.Millisecond)
+	requests.Add(ctx, 3, metric.WithAttributes(attribute.String("method", "GET")))
+	span.End()
+
+	body, contentType := scrape(t, p, "")
+	assert.Contains(t, contentType, "text/plain; version=0.0.4")
+	assert.Contains(t, body, `db_query_duration_seconds_bucket{le="0.025"} 1`+"\n")
+	assert.Contains(t, body, "# TYPE http_requests_total counter\n")
+	assert.Contains(t, body, `http_requests_total{method="GET"} 3`+"\n")
+	assert.NotContains(t, body, "trace_id")
+	assert.NotContains(t, body, "# EOF")
+
+	// Em OpenMetrics o contador também leva exemplar
+	body, _ = scrape(t, p, "application/openmetrics-text")
+	assert.Contains(t, body, "# TYPE http_requests counter\n")
+	assert.Contains(t, body, `http_requests_total{method="GET"} 3 # {trace_id="`+span.SpanContext().TraceID().String())
+}
+
+func TestLatencyHistogram_UnsampledSpan(t *testing.T) {
+	p := NewProvider()
+	defer p.Shutdown(context.Background())
+
+	latency, err := NewLatencyHistogram(p.Meter("test"), "job.duration")
+	require.NoError(t, err)
+
+	ctx, span := newTracer(t, sdktrace.NeverSample()).Start(context.Background(), "job")
+	latency.Record(ctx, time.Second)
+	span.End()
+
+	body, _ := scrape(t, p, "application/openmetrics-text")
+	assert.Contains(t, body, `job_duration_seconds_bucket{le="1"} 1`+"\n")
+	assert.NotContains(t, body, "trace_id")
+}
+
+func TestLatencyHistogram_Start(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	p := NewProvider()
+	defer p.Shutdown(context.Background())
+
+	latency, err := NewLatencyHistogram(p.Meter("test"), "checkout.duration",
+		WithBuckets(1, 5), WithClock(fake))
+	require.NoError(t, err)
+
+	ctx, span := newTracer(t, sdktrace.AlwaysSample()).Start(context.Background(), "checkout")
+	done := latency.Start(ctx)
+	fake.Advance(3 * time.Second)
+	done(attribute.String("status", "ok"))
+	span.End()
+
+	// Os exemplars também ficam disponíveis para os readers OTLP
+	rm, err := p.Collect(context.Background())
+	require.NoError(t, err)
+	hist := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Histogram[float64])
+	require.Len(t, hist.DataPoints, 1)
+	dp := hist.DataPoints[0]
+	assert.Equal(t, []float64{1, 5}, dp.Bounds)
+	assert.Equal(t, float64(3), dp.Sum)
+	require.NotEmpty(t, dp.Exemplars)
+	assert.Equal(t, span.SpanContext().TraceID().String(), oteltrace.TraceID(dp.Exemplars[0].TraceID).String())
+}
+
+func TestMetricName(t *testing.T) {
+	assert.Equal(t, "http_server_duration_seconds", metricName("http.server.duration", "s"))
+	assert.Equal(t, "latency_seconds", metricName("latency_seconds", "s"))
+	assert.Equal(t, "payload_bytes", metricName("payload", "By"))
+	assert.Equal(t, "_2xx_responses", metricName("2xx-responses", "1"))
+	assert.Equal(t, "ns:requests", metricName("ns:requests", ""))
+}
@@ -0,0 +1,275 @@
+package metrics
+
+import (
+	"bufio"
+	"encoding/hex"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// format formato de exposição negociado com o cliente
+type format int
+
+const (
+	formatPrometheus format = iota
+	formatOpenMetrics
+)
+
+func (f format) contentType() string {
+	if f == formatOpenMetrics {
+		return "application/openmetrics-text; version=1.0.0; charset=utf-8"
+	}
+	return "text/plain; version=0.0.4; charset=utf-8"
+}
+
+// negotiate escolhe OpenMetrics quando o header Accept o inclui
+func negotiate(accept string) format {
+	if strings.Contains(accept, "application/openmetrics-text") {
+		return formatOpenMetrics
+	}
+	return formatPrometheus
+}
+
+// unitSuffixes sufixos Prometheus das unidades UCUM mais comuns
+var unitSuffixes = map[string]string{
+	"s":  "seconds",
+	"ms": "milliseconds",
+	"By": "bytes",
+}
+
+// writeMetrics escreve as métricas no formato informado. Histogramas e
+// contadores levam seus exemplars apenas em OpenMetrics
+func writeMetrics(w io.Writer, rm metricdata.ResourceMetrics, f format) error {
+	bw := bufio.NewWriter(w)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			writeMetric(bw, m, f)
+		}
+	}
+	if f == formatOpenMetrics {
+		bw.WriteString("# EOF\n")
+	}
+	return bw.Flush()
+}
+
+func writeMetric(w *bufio.Writer, m metricdata.Metrics, f format) {
+	name := metricName(m.Name, m.Unit)
+	switch data := m.Data.(type) {
+	case metricdata.Histogram[float64]:
+		writeHeader(w, name, "histogram", m, f)
+		writeHistogram(w, name, data.DataPoints, f)
+	case metricdata.Histogram[int64]:
+		writeHeader(w, name, "histogram", m, f)
+		writeHistogram(w, name, data.DataPoints, f)
+	case metricdata.Sum[float64]:
+		writeSum(w, name, m, data.DataPoints, data.IsMonotonic, f)
+	case metricdata.Sum[int64]:
+		writeSum(w, name, m, data.DataPoints, data.IsMonotonic, f)
+	case metricdata.Gauge[float64]:
+		writeHeader(w, name, "gauge", m, f)
+		writeDataPoints(w, name, data.DataPoints, false, f)
+	case metricdata.Gauge[int64]:
+		writeHeader(w, name, "gauge", m, f)
+		writeDataPoints(w, name, data.DataPoints, false, f)
+	}
+}
+
+// writeHeader escreve as linhas TYPE, UNIT e HELP da família
+func writeHeader(w *bufio.Writer, name, kind string, m metricdata.Metrics, f format) {
+	w.WriteString("# TYPE " + name + " " + kind + "\n")
+	if suffix, ok := unitSuffixes[m.Unit]; ok && f == formatOpenMetrics {
+		w.WriteString("# UNIT " + name + " " + suffix + "\n")
+	}
+	if m.Description != "" {
+		w.WriteString("# HELP " + name + " " + escapeHelp(m.Description) + "\n")
+	}
+}
+
+func writeSum[N int64 | float64](w *bufio.Writer, name string, m metricdata.Metrics, points []metricdata.DataPoint[N], monotonic bool, f format) {
+	if !monotonic {
+		writeHeader(w, name, "gauge", m, f)
+		writeDataPoints(w, name, points, false, f)
+		return
+	}
+
+	// Em OpenMetrics a família do contador não tem o sufixo _total; no
+	// formato Prometheus o TYPE usa o nome da amostra
+	family := strings.TrimSuffix(name, "_total")
+	if f == formatPrometheus {
+		writeHeader(w, family+"_total", "counter", m, f)
+	} else {
+		writeHeader(w, family, "counter", m, f)
+	}
+	writeDataPoints(w, family+"_total", points, true, f)
+}
+
+func writeDataPoints[N int64 | float64](w *bufio.Writer, name string, points []metricdata.DataPoint[N], exemplars bool, f format) {
+	sorted := make([]metricdata.DataPoint[N], len(points))
+	copy(sorted, points)
+	sort.Slice(sorted, func(i, j int) bool {
+		return labelString(sorted[i].Attributes) < labelString(sorted[j].Attributes)
+	})
+
+	for _, dp := range sorted {
+		w.WriteString(name + formatLabels(dp.Attributes, "", "") + " " + formatFloat(float64(dp.Value)))
+		if exemplars && f == formatOpenMetrics {
+			if ex, ok := latestExemplar(dp.Exemplars); ok {
+				writeExemplar(w, ex)
+			}
+		}
+		w.WriteString("\n")
+	}
+}
+
+func writeHistogram[N int64 | float64](w *bufio.Writer, name string, points []metricdata.HistogramDataPoint[N], f format) {
+	sorted := make([]metricdata.HistogramDataPoint[N], len(points))
+	copy(sorted, points)
+	sort.Slice(sorted, func(i, j int) bool {
+		return labelString(sorted[i].Attributes) < labelString(sorted[j].Attributes)
+	})
+
+	for _, dp := range sorted {
+		var byBucket map[int]metricdata.Exemplar[N]
+		if f == formatOpenMetrics {
+			byBucket = bucketExemplars(dp.Bounds, dp.Exemplars)
+		}
+
+		// Os buckets do OpenTelemetry não são cumulativos
+		var cumulative uint64
+		for i, count := range dp.BucketCounts {
+			cumulative += count
+			le := "+Inf"
+			if i < len(dp.Bounds) {
+				le = formatFloat(dp.Bounds[i])
+			}
+			w.WriteString(name + "_bucket" + formatLabels(dp.Attributes, "le", le) + " " + strconv.FormatUint(cumulative, 10))
+			if ex, ok := byBucket[i]; ok {
+				writeExemplar(w, ex)
+			}
+			w.WriteString("\n")
+		}
+
+		labels := formatLabels(dp.Attributes, "", "")
+		w.WriteString(name + "_count" + labels + " " + strconv.FormatUint(dp.Count, 10) + "\n")
+		w.WriteString(name + "_sum" + labels + " " + formatFloat(float64(dp.Sum)) + "\n")
+	}
+}
+
+// bucketExemplars associa cada exemplar com trace ao bucket do seu valor,
+// mantendo o mais recente de cada bucket
+func bucketExemplars[N int64 | float64](bounds []float64, exemplars []metricdata.Exemplar[N]) map[int]metricdata.Exemplar[N] {
+	result := make(map[int]metricdata.Exemplar[N], len(exemplars))
+	for _, ex := range exemplars {
+		if len(ex.TraceID) == 0 {
+			continue
+		}
+		bucket := sort.SearchFloat64s(bounds, float64(ex.Value))
+		if current, ok := result[bucket]; !ok || ex.Time.After(current.Time) {
+			result[bucket] = ex
+		}
+	}
+	return result
+}
+
+// latestExemplar retorna o exemplar com trace mais recente
+func latestExemplar[N int64 | float64](exemplars []metricdata.Exemplar[N]) (metricdata.Exemplar[N], bool) {
+	var latest metricdata.Exemplar[N]
+	found := false
+	for _, ex := range exemplars {
+		if len(ex.TraceID) > 0 && (!found || ex.Time.After(latest.Time)) {
+			latest, found = ex, true
+		}
+	}
+	return latest, found
+}
+
+// writeExemplar escreve o exemplar no formato
+// # {trace_id="...",span_id="..."} valor timestamp
+func writeExemplar[N int64 | float64](w *bufio.Writer, ex metricdata.Exemplar[N]) {
+	w.WriteString(` # {trace_id="` + hex.EncodeToString(ex.TraceID) + `"`)
+	if len(ex.SpanID) > 0 {
+		w.WriteString(`,span_id="` + hex.EncodeToString(ex.SpanID) + `"`)
+	}
+	w.WriteString("} " + formatFloat(float64(ex.Value)))
+	if !ex.Time.IsZero() {
+		w.WriteString(" " + strconv.FormatFloat(float64(ex.Time.UnixNano())/1e9, 'f', 3, 64))
+	}
+}
+
+// metricName converte o nome OpenTelemetry para o padrão Prometheus,
+// acrescentando o sufixo da unidade
+func metricName(name, unit string) string {
+	name = sanitizeName(name, true)
+	if suffix, ok := unitSuffixes[unit]; ok && !strings.HasSuffix(name, "_"+suffix) {
+		name += "_" + suffix
+	}
+	return name
+}
+
+// sanitizeName troca caracteres inválidos por _. Nomes de métricas aceitam :
+func sanitizeName(name string, allowColon bool) string {
+	var b strings.Builder
+	for i, r := range name {
+		valid := r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') ||
+			(i > 0 && r >= '0' && r <= '9') || (allowColon && r == ':')
+		if valid {
+			b.WriteRune(r)
+		} else if i == 0 && r >= '0' && r <= '9' {
+			b.WriteString("_")
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+// formatLabels formata os atributos como labels, com um label extra opcional
+func formatLabels(set attribute.Set, extraKey, extraValue string) string {
+	if set.Len() == 0 && extraKey == "" {
+		return ""
+	}
+
+	parts := make([]string, 0, set.Len()+1)
+	for _, kv := range set.ToSlice() {
+		parts = append(parts, sanitizeName(string(kv.Key), false)+`="`+escapeLabel(kv.Value.Emit())+`"`)
+	}
+	if extraKey != "" {
+		parts = append(parts, extraKey+`="`+extraValue+`"`)
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// labelString chave de ordenação das séries
+func labelString(set attribute.Set) string {
+	return formatLabels(set, "", "")
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+
+func escapeHelp(s string) string { return helpEscaper.Replace(s) }
+
+// formatFloat formata valores como o Prometheus, com +Inf, -Inf e NaN
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
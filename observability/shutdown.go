@@ -0,0 +1,406 @@
+// Package observability fornece utilitários transversais aos módulos de
+// observabilidade (tracer, logger e métricas)
+package observability
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ComponentKind define a categoria de um componente e sua ordem padrão de finalização
+type ComponentKind int
+
+const (
+	// KindTracer tracer providers são finalizados primeiro para exportar os spans pendentes
+	KindTracer ComponentKind = iota
+	// KindMetrics exporters de métricas são finalizados após os tracers
+	KindMetrics
+	// KindLogger loggers assíncronos são finalizados por último para registrar falhas anteriores
+	KindLogger
+	// KindCustom componentes genéricos sem ordem específica
+	KindCustom
+)
+
+// String retorna a representação em string do tipo de componente
+func (k ComponentKind) String() string {
+	switch k {
+	case KindTracer:
+		return "tracer"
+	case KindMetrics:
+		return "metrics"
+	case KindLogger:
+		return "logger"
+	default:
+		return "custom"
+	}
+}
+
+// DefaultComponentTimeout timeout padrão aplicado a cada componente
+const DefaultComponentTimeout = 5 * time.Second
+
+// ShutdownFunc função de finalização de um componente
+type ShutdownFunc func(ctx context.Context) error
+
+// Shutdowner é implementado por tracer providers e exporters de métricas
+type Shutdowner interface {
+	Shutdown(ctx context.Context) error
+}
+
+// Closer é implementado por loggers
+type Closer interface {
+	Close() error
+}
+
+// component representa um componente registrado no coordenador
+type component struct {
+	name      string
+	kind      ComponentKind
+	fn        ShutdownFunc
+	timeout   time.Duration
+	dependsOn []string
+	index     int
+}
+
+// ComponentOption configura um componente registrado
+type ComponentOption func(*component)
+
+// WithTimeout define o timeout de finalização do componente
+func WithTimeout(timeout time.Duration) ComponentOption {
+	return func(c *component) {
+		c.timeout = timeout
+	}
+}
+
+// WithKind define a categoria do componente
+func WithKind(kind ComponentKind) ComponentOption {
+	return func(c *component) {
+		c.kind = kind
+	}
+}
+
+// WithDependsOn declara componentes dos quais este depende. O componente é
+// finalizado antes de suas dependências, que permanecem ativas até lá
+func WithDependsOn(names ...string) ComponentOption {
+	return func(c *component) {
+		c.dependsOn = append(c.dependsOn, names...)
+	}
+}
+
+// ComponentError erro de finalização de um componente específico
+type ComponentError struct {
+	Component string
+	Kind      ComponentKind
+	Duration  time.Duration
+	Err       error
+}
+
+// Error implementa error
+func (e *ComponentError) Error() string {
+	return fmt.Sprintf("%s %s shutdown failed after %s: %v", e.Kind, e.Component, e.Duration, e.Err)
+}
+
+// Unwrap retorna o erro original
+func (e *ComponentError) Unwrap() error {
+	return e.Err
+}
+
+// ShutdownError agrega os erros de todos os componentes que falharam
+type ShutdownError struct {
+	Errors []*ComponentError
+}
+
+// Error implementa error
+func (e *ShutdownError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("observability shutdown failed for %d component(s): %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// Unwrap permite o uso de errors.Is/As sobre os erros agregados
+func (e *ShutdownError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Coordinator coordena a finalização dos componentes de observabilidade:
+// tracers, métricas e por último loggers. Em um serviço gerenciado pelo
+// pacote lifecycle, o Shutdown do coordenador é o OnStop de um único
+// componente, registrado antes dos demais para ser parado por último
+type Coordinator struct {
+	mu             sync.Mutex
+	components     map[string]*component
+	nextIndex      int
+	defaultTimeout time.Duration
+	done           bool
+	result         error
+}
+
+// NewCoordinator cria um novo coordenador de shutdown
+func NewCoordinator() *Coordinator {
+	return &Coordinator{
+		components:     make(map[string]*component),
+		defaultTimeout: DefaultComponentTimeout,
+	}
+}
+
+// SetDefaultTimeout define o timeout padrão para componentes sem timeout próprio
+func (c *Coordinator) SetDefaultTimeout(timeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.defaultTimeout = timeout
+}
+
+// Register registra uma função de finalização
+func (c *Coordinator) Register(name string, fn ShutdownFunc, opts ...ComponentOption) error {
+	if name == "" {
+		return fmt.Errorf("component name cannot be empty")
+	}
+	if fn == nil {
+		return fmt.Errorf("shutdown function for component '%s' cannot be nil", name)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.done {
+		return fmt.Errorf("coordinator already shut down")
+	}
+	if _, exists := c.components[name]; exists {
+		return fmt.Errorf("component '%s' already registered", name)
+	}
+
+	comp := &component{
+		name:  name,
+		kind:  KindCustom,
+		fn:    fn,
+		index: c.nextIndex,
+	}
+	for _, opt := range opts {
+		opt(comp)
+	}
+
+	c.components[name] = comp
+	c.nextIndex++
+	return nil
+}
+
+// RegisterTracer registra um tracer provider
+func (c *Coordinator) RegisterTracer(name string, provider Shutdowner, opts ...ComponentOption) error {
+	if provider == nil {
+		return fmt.Errorf("tracer provider '%s' cannot be nil", name)
+	}
+	return c.Register(name, provider.Shutdown, append([]ComponentOption{WithKind(KindTracer)}, opts...)...)
+}
+
+// RegisterMetrics registra um exporter/provider de métricas
+func (c *Coordinator) RegisterMetrics(name string, exporter Shutdowner, opts ...ComponentOption) error {
+	if exporter == nil {
+		return fmt.Errorf("metrics exporter '%s' cannot be nil", name)
+	}
+	return c.Register(name, exporter.Shutdown, append([]ComponentOption{WithKind(KindMetrics)}, opts...)...)
+}
+
+// RegisterLogger registra um logger. Se o logger expuser FlushBuffer, o buffer
+// é descarregado antes do Close
+func (c *Coordinator) RegisterLogger(name string, logger Closer, opts ...ComponentOption) error {
+	if logger == nil {
+		return fmt.Errorf("logger '%s' cannot be nil", name)
+	}
+
+	fn := func(ctx context.Context) error {
+		if flusher, ok := logger.(interface{ FlushBuffer() error }); ok {
+			if err := flusher.FlushBuffer(); err != nil {
+				return fmt.Errorf("failed to flush buffer: %w", err)
+			}
+		}
+		return logger.Close()
+	}
+
+	return c.Register(name, fn, append([]ComponentOption{WithKind(KindLogger)}, opts...)...)
+}
+
+// Components retorna os nomes dos componentes na ordem de finalização
+func (c *Coordinator) Components() ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ordered, err := c.order()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(ordered))
+	for i, comp := range ordered {
+		names[i] = comp.name
+	}
+	return names, nil
+}
+
+// Shutdown finaliza todos os componentes em ordem de dependência, aplicando o
+// timeout de cada componente. Todos os componentes são finalizados mesmo que
+// algum falhe; os erros são agregados em um *ShutdownError. Chamadas
+// subsequentes retornam o resultado da primeira execução
+func (c *Coordinator) Shutdown(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.done {
+		return c.result
+	}
+
+	ordered, err := c.order()
+	if err != nil {
+		return err
+	}
+	c.done = true
+
+	var failures []*ComponentError
+	for _, comp := range ordered {
+		if err := c.shutdownComponent(ctx, comp); err != nil {
+			failures = append(failures, err)
+		}
+	}
+
+	if len(failures) > 0 {
+		c.result = &ShutdownError{Errors: failures}
+	}
+	return c.result
+}
+
+// shutdownComponent finaliza um componente respeitando seu timeout
+func (c *Coordinator) shutdownComponent(ctx context.Context, comp *component) *ComponentError {
+	timeout := comp.timeout
+	if timeout <= 0 {
+		timeout = c.defaultTimeout
+	}
+
+	compCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	errCh := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				errCh <- fmt.Errorf("panic during shutdown: %v", r)
+			}
+		}()
+		errCh <- comp.fn(compCtx)
+	}()
+
+	var err error
+	select {
+	case err = <-errCh:
+	case <-compCtx.Done():
+		err = compCtx.Err()
+	}
+
+	if err == nil {
+		return nil
+	}
+
+	return &ComponentError{
+		Component: comp.name,
+		Kind:      comp.kind,
+		Duration:  time.Since(start),
+		Err:       err,
+	}
+}
+
+// order calcula a ordem de finalização: dependentes antes de suas dependências,
+// e, entre componentes independentes, por categoria e ordem de registro
+func (c *Coordinator) order() ([]*component, error) {
+	// edges[a] contém os componentes que precisam ser finalizados antes de a
+	pending := make(map[string]int, len(c.components))
+	edges := make(map[string][]string, len(c.components))
+
+	for name, comp := range c.components {
+		if _, ok := pending[name]; !ok {
+			pending[name] = 0
+		}
+		for _, dep := range comp.dependsOn {
+			if _, exists := c.components[dep]; !exists {
+				return nil, fmt.Errorf("component '%s' depends on unknown component '%s'", name, dep)
+			}
+			edges[name] = append(edges[name], dep)
+			pending[dep]++
+		}
+	}
+
+	less := func(a, b *component) bool {
+		if a.kind != b.kind {
+			return a.kind < b.kind
+		}
+		return a.index < b.index
+	}
+
+	var ready []*component
+	for name, count := range pending {
+		if count == 0 {
+			ready = append(ready, c.components[name])
+		}
+	}
+
+	result := make([]*component, 0, len(c.components))
+	for len(ready) > 0 {
+		sort.Slice(ready, func(i, j int) bool { return less(ready[i], ready[j]) })
+		next := ready[0]
+		ready = ready[1:]
+		result = append(result, next)
+
+		for _, dep := range edges[next.name] {
+			pending[dep]--
+			if pending[dep] == 0 {
+				ready = append(ready, c.components[dep])
+			}
+		}
+	}
+
+	if len(result) != len(c.components) {
+		return nil, errors.New("dependency cycle detected between observability components")
+	}
+
+	return result, nil
+}
+
+var defaultCoordinator = NewCoordinator()
+
+// DefaultCoordinator retorna o coordenador global
+func DefaultCoordinator() *Coordinator {
+	return defaultCoordinator
+}
+
+// Register registra um componente no coordenador global
+func Register(name string, fn ShutdownFunc, opts ...ComponentOption) error {
+	return defaultCoordinator.Register(name, fn, opts...)
+}
+
+// RegisterTracer registra um tracer provider no coordenador global
+func RegisterTracer(name string, provider Shutdowner, opts ...ComponentOption) error {
+	return defaultCoordinator.RegisterTracer(name, provider, opts...)
+}
+
+// RegisterMetrics registra um exporter de métricas no coordenador global
+func RegisterMetrics(name string, exporter Shutdowner, opts ...ComponentOption) error {
+	return defaultCoordinator.RegisterMetrics(name, exporter, opts...)
+}
+
+// RegisterLogger registra um logger no coordenador global
+func RegisterLogger(name string, logger Closer, opts ...ComponentOption) error {
+	return defaultCoordinator.RegisterLogger(name, logger, opts...)
+}
+
+// Shutdown finaliza todos os componentes registrados no coordenador global
+func Shutdown(ctx context.Context) error {
+	return defaultCoordinator.Shutdown(ctx)
+}
@@ -0,0 +1,160 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *recorder) fn(name string, err error) ShutdownFunc {
+	return func(ctx context.Context) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.calls = append(r.calls, name)
+		return err
+	}
+}
+
+type fakeLogger struct {
+	flushed bool
+	closed  bool
+}
+
+func (f *fakeLogger) FlushBuffer() error { f.flushed = true; return nil }
+func (f *fakeLogger) Close() error       { f.closed = true; return nil }
+
+type fakeTracer struct{ calls *recorder }
+
+func (f *fakeTracer) Shutdown(ctx context.Context) error {
+	return f.calls.fn("tracer", nil)(ctx)
+}
+
+func TestCoordinator_DefaultOrder(t *testing.T) {
+	rec := &recorder{}
+	c := NewCoordinator()
+
+	require.NoError(t, c.RegisterLogger("logger", &fakeLogger{}))
+	require.NoError(t, c.RegisterMetrics("metrics", &fakeTracer{calls: &recorder{}}))
+	require.NoError(t, c.RegisterTracer("tracer", &fakeTracer{calls: rec}))
+	require.NoError(t, c.Register("custom", rec.fn("custom", nil)))
+
+	names, err := c.Components()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tracer", "metrics", "logger", "custom"}, names)
+}
+
+func TestCoordinator_DependsOn(t *testing.T) {
+	rec := &recorder{}
+	c := NewCoordinator()
+
+	require.NoError(t, c.Register("db", rec.fn("db", nil)))
+	require.NoError(t, c.Register("worker", rec.fn("worker", nil), WithDependsOn("db")))
+	require.NoError(t, c.Register("tracer", rec.fn("tracer", nil), WithKind(KindTracer), WithDependsOn("worker")))
+
+	require.NoError(t, c.Shutdown(context.Background()))
+	assert.Equal(t, []string{"tracer", "worker", "db"}, rec.calls)
+}
+
+func TestCoordinator_AggregatesErrors(t *testing.T) {
+	rec := &recorder{}
+	errTracer := errors.New("tracer failed")
+	errLogger := errors.New("logger failed")
+	c := NewCoordinator()
+
+	require.NoError(t, c.Register("tracer", rec.fn("tracer", errTracer), WithKind(KindTracer)))
+	require.NoError(t, c.Register("metrics", rec.fn("metrics", nil), WithKind(KindMetrics)))
+	require.NoError(t, c.Register("logger", rec.fn("logger", errLogger), WithKind(KindLogger)))
+
+	err := c.Shutdown(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, []string{"tracer", "metrics", "logger"}, rec.calls)
+
+	var shutdownErr *ShutdownError
+	require.True(t, errors.As(err, &shutdownErr))
+	assert.Len(t, shutdownErr.Errors, 2)
+	assert.ErrorIs(t, err, errTracer)
+	assert.ErrorIs(t, err, errLogger)
+
+	// Chamadas subsequentes retornam o mesmo resultado sem reexecutar
+	assert.Equal(t, err, c.Shutdown(context.Background()))
+	assert.Len(t, rec.calls, 3)
+}
+
+func TestCoordinator_PerComponentTimeout(t *testing.T) {
+	rec := &recorder{}
+	c := NewCoordinator()
+
+	blocking := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	hanging := func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}
+
+	require.NoError(t, c.Register("blocking", blocking, WithKind(KindTracer), WithTimeout(10*time.Millisecond)))
+	require.NoError(t, c.Register("hanging", hanging, WithKind(KindMetrics), WithTimeout(10*time.Millisecond)))
+	require.NoError(t, c.Register("logger", rec.fn("logger", nil), WithKind(KindLogger)))
+
+	start := time.Now()
+	err := c.Shutdown(context.Background())
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, []string{"logger"}, rec.calls)
+}
+
+func TestCoordinator_RecoversPanic(t *testing.T) {
+	c := NewCoordinator()
+	require.NoError(t, c.Register("panicking", func(ctx context.Context) error { panic("boom") }))
+
+	err := c.Shutdown(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "boom")
+}
+
+func TestCoordinator_RegisterValidation(t *testing.T) {
+	c := NewCoordinator()
+
+	assert.Error(t, c.Register("", func(ctx context.Context) error { return nil }))
+	assert.Error(t, c.Register("nil", nil))
+	assert.Error(t, c.RegisterTracer("tracer", nil))
+	assert.Error(t, c.RegisterLogger("logger", nil))
+
+	require.NoError(t, c.Register("dup", func(ctx context.Context) error { return nil }))
+	assert.Error(t, c.Register("dup", func(ctx context.Context) error { return nil }))
+
+	require.NoError(t, c.Shutdown(context.Background()))
+	assert.Error(t, c.Register("late", func(ctx context.Context) error { return nil }))
+}
+
+func TestCoordinator_InvalidDependencies(t *testing.T) {
+	c := NewCoordinator()
+	require.NoError(t, c.Register("a", func(ctx context.Context) error { return nil }, WithDependsOn("missing")))
+	assert.Error(t, c.Shutdown(context.Background()))
+
+	c = NewCoordinator()
+	require.NoError(t, c.Register("a", func(ctx context.Context) error { return nil }, WithDependsOn("b")))
+	require.NoError(t, c.Register("b", func(ctx context.Context) error { return nil }, WithDependsOn("a")))
+	assert.Error(t, c.Shutdown(context.Background()))
+}
+
+func TestCoordinator_LoggerFlush(t *testing.T) {
+	l := &fakeLogger{}
+	c := NewCoordinator()
+	require.NoError(t, c.RegisterLogger("logger", l))
+	require.NoError(t, c.Shutdown(context.Background()))
+
+	assert.True(t, l.flushed)
+	assert.True(t, l.closed)
+}
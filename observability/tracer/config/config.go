@@ -127,6 +127,20 @@ func WithInsecure(insecure bool) ConfigOption {
 	}
 }
 
+// WithEventLimits define os limites aplicados aos eventos dos spans
+func WithEventLimits(limits interfaces.EventLimits) ConfigOption {
+	return func(c *interfaces.Config) {
+		c.Events = limits
+	}
+}
+
+// WithSpanMetrics configura as métricas RED derivadas dos spans
+func WithSpanMetrics(spanMetrics interfaces.SpanMetricsConfig) ConfigOption {
+	return func(c *interfaces.Config) {
+		c.SpanMetrics = spanMetrics
+	}
+}
+
 // NewConfig cria uma nova configuração aplicando as opções fornecidas
 func NewConfig(opts ...ConfigOption) interfaces.Config {
 	config := DefaultConfig()
@@ -204,6 +218,39 @@ func LoadFromEnv() interfaces.Config {
 		}
 	}
 
+	// Limites de eventos
+	for name, target := range map[string]*int{
+		"TRACER_EVENTS_MAX_PER_SPAN":      &config.Events.MaxEventsPerSpan,
+		"TRACER_EVENTS_MAX_ATTRIBUTES":    &config.Events.MaxAttributesPerEvent,
+		"TRACER_EVENTS_MAX_VALUE_LENGTH":  &config.Events.MaxAttributeValueLength,
+		"TRACER_EVENTS_MAX_PAYLOAD_BYTES": &config.Events.MaxEventPayloadBytes,
+	} {
+		if value := os.Getenv(name); value != "" {
+			if n, err := strconv.Atoi(value); err == nil {
+				*target = n
+			}
+		}
+	}
+
+	// Métricas derivadas dos spans
+	if enabled := os.Getenv("TRACER_SPAN_METRICS_ENABLED"); enabled != "" {
+		if enabledBool, err := strconv.ParseBool(enabled); err == nil {
+			config.SpanMetrics.Enabled = enabledBool
+		}
+	}
+	if dimensions := os.Getenv("TRACER_SPAN_METRICS_DIMENSIONS"); dimensions != "" {
+		for _, d := range strings.Split(dimensions, ",") {
+			if d = strings.TrimSpace(d); d != "" {
+				config.SpanMetrics.Dimensions = append(config.SpanMetrics.Dimensions, d)
+			}
+		}
+	}
+	if maxSeries := os.Getenv("TRACER_SPAN_METRICS_MAX_SERIES"); maxSeries != "" {
+		if n, err := strconv.Atoi(maxSeries); err == nil {
+			config.SpanMetrics.MaxSeries = n
+		}
+	}
+
 	if propagators := os.Getenv("TRACER_PROPAGATORS"); propagators != "" {
 		config.Propagators = strings.Split(propagators, ",")
 		for i, p := range config.Propagators {
@@ -247,7 +294,7 @@ func Validate(config interfaces.Config) error {
 		return fmt.Errorf("exporter type is required")
 	}
 
-	supportedTypes := []string{"datadog", "grafana", "newrelic", "opentelemetry"}
+	supportedTypes := []string{"datadog", "grafana", "newrelic", "opentelemetry", "memory", "file", "noop", "xray"}
 	found := false
 	for _, t := range supportedTypes {
 		if t == config.ExporterType {
@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"reflect"
 	"testing"
 
 	"github.com/fsvxavier/nexs-lib/observability/tracer/interfaces"
@@ -275,20 +276,25 @@ func TestDefaultConfig(t *testing.T) {
 func TestLoadFromEnv(t *testing.T) {
 	// Set test environment variables
 	testEnvVars := map[string]string{
-		"TRACER_SERVICE_NAME":    "test-service",
-		"TRACER_ENVIRONMENT":     "production",
-		"TRACER_EXPORTER_TYPE":   "datadog",
-		"TRACER_ENDPOINT":        "http://localhost:8080",
-		"TRACER_API_KEY":         "test-api-key",
-		"TRACER_LICENSE_KEY":     "test-license-key",
-		"TRACER_VERSION":         "2.0.0",
-		"TRACER_SAMPLING_RATIO":  "0.5",
-		"TRACER_INSECURE":        "true",
-		"TRACER_PROPAGATORS":     "tracecontext,b3,jaeger",
-		"TRACER_HEADER_AUTH":     "Bearer token123",
-		"TRACER_HEADER_X_CUSTOM": "custom-value",
-		"TRACER_ATTR_TEAM":       "platform",
-		"TRACER_ATTR_REGION":     "us-east-1",
+		"TRACER_SERVICE_NAME":             "test-service",
+		"TRACER_ENVIRONMENT":              "production",
+		"TRACER_EXPORTER_TYPE":            "datadog",
+		"TRACER_ENDPOINT":                 "http://localhost:8080",
+		"TRACER_API_KEY":                  "test-api-key",
+		"TRACER_LICENSE_KEY":              "test-license-key",
+		"TRACER_VERSION":                  "2.0.0",
+		"TRACER_SAMPLING_RATIO":           "0.5",
+		"TRACER_INSECURE":                 "true",
+		"TRACER_PROPAGATORS":              "tracecontext,b3,jaeger",
+		"TRACER_HEADER_AUTH":              "Bearer token123",
+		"TRACER_HEADER_X_CUSTOM":          "custom-value",
+		"TRACER_ATTR_TEAM":                "platform",
+		"TRACER_ATTR_REGION":              "us-east-1",
+		"TRACER_EVENTS_MAX_PER_SPAN":      "256",
+		"TRACER_EVENTS_MAX_PAYLOAD_BYTES": "-1",
+		"TRACER_SPAN_METRICS_ENABLED":     "true",
+		"TRACER_SPAN_METRICS_DIMENSIONS":  "http.route, rpc.method",
+		"TRACER_SPAN_METRICS_MAX_SERIES":  "500",
 	}
 
 	// Set environment variables
@@ -337,6 +343,19 @@ func TestLoadFromEnv(t *testing.T) {
 		t.Errorf("Expected SamplingRatio to be 0.5, got %f", config.SamplingRatio)
 	}
 
+	if config.Events.MaxEventsPerSpan != 256 || config.Events.MaxEventPayloadBytes != -1 {
+		t.Errorf("Expected event limits 256 and -1, got %+v", config.Events)
+	}
+
+	if !config.SpanMetrics.Enabled || config.SpanMetrics.MaxSeries != 500 {
+		t.Errorf("Expected span metrics enabled with 500 series, got %+v", config.SpanMetrics)
+	}
+
+	expectedDimensions := []string{"http.route", "rpc.method"}
+	if !reflect.DeepEqual(config.SpanMetrics.Dimensions, expectedDimensions) {
+		t.Errorf("Expected span metrics dimensions %v, got %v", expectedDimensions, config.SpanMetrics.Dimensions)
+	}
+
 	if !config.Insecure {
 		t.Error("Expected Insecure to be true")
 	}
@@ -0,0 +1,100 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Attr converte um valor Go em um atributo tipado: strings, booleanos,
+// inteiros, floats e seus slices mantêm o tipo; time.Time usa RFC 3339,
+// time.Duration, error e fmt.Stringer viram strings e os demais valores são
+// serializados como JSON
+func Attr(key string, value any) attribute.KeyValue {
+	k := attribute.Key(key)
+	switch v := value.(type) {
+	case nil:
+		return k.String("")
+	case attribute.Value:
+		return attribute.KeyValue{Key: k, Value: v}
+	case string:
+		return k.String(v)
+	case bool:
+		return k.Bool(v)
+	case int:
+		return k.Int(v)
+	case int8:
+		return k.Int64(int64(v))
+	case int16:
+		return k.Int64(int64(v))
+	case int32:
+		return k.Int64(int64(v))
+	case int64:
+		return k.Int64(v)
+	case uint:
+		return uintAttr(k, uint64(v))
+	case uint8:
+		return k.Int64(int64(v))
+	case uint16:
+		return k.Int64(int64(v))
+	case uint32:
+		return k.Int64(int64(v))
+	case uint64:
+		return uintAttr(k, v)
+	case float32:
+		return k.Float64(float64(v))
+	case float64:
+		return k.Float64(v)
+	case []string:
+		return k.StringSlice(v)
+	case []bool:
+		return k.BoolSlice(v)
+	case []int:
+		return k.IntSlice(v)
+	case []int64:
+		return k.Int64Slice(v)
+	case []float64:
+		return k.Float64Slice(v)
+	case time.Time:
+		return k.String(v.Format(time.RFC3339Nano))
+	case time.Duration:
+		return k.String(v.String())
+	case error:
+		return k.String(v.Error())
+	case fmt.Stringer:
+		return k.String(v.String())
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return k.String(fmt.Sprint(value))
+	}
+	return k.String(string(data))
+}
+
+// uintAttr usa string para valores que não cabem em int64
+func uintAttr(k attribute.Key, v uint64) attribute.KeyValue {
+	if v > math.MaxInt64 {
+		return k.String(fmt.Sprint(v))
+	}
+	return k.Int64(int64(v))
+}
+
+// Attrs converte um mapa de campos em atributos tipados, ordenados pela chave
+func Attrs(fields map[string]any) []attribute.KeyValue {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	attrs := make([]attribute.KeyValue, 0, len(keys))
+	for _, key := range keys {
+		attrs = append(attrs, Attr(key, fields[key]))
+	}
+	return attrs
+}
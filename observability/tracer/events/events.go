@@ -0,0 +1,293 @@
+// Package events formaliza a adição de eventos aos spans: atributos tipados e
+// validados, limite de eventos por span e truncamento de payloads grandes,
+// aplicados da mesma forma em todos os providers
+package events
+
+import (
+	"context"
+	"sync"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/fsvxavier/nexs-lib/observability/tracer/interfaces"
+)
+
+// Limites padrão, usados quando o valor configurado é zero
+const (
+	DefaultMaxEventsPerSpan        = 128
+	DefaultMaxAttributesPerEvent   = 64
+	DefaultMaxAttributeValueLength = 4096
+	DefaultMaxEventPayloadBytes    = 16384
+)
+
+// Atributos adicionados quando os limites são aplicados
+const (
+	// TruncatedKey marca eventos com atributos truncados ou descartados
+	TruncatedKey = attribute.Key("event.truncated")
+	// DroppedAttributesKey conta os atributos descartados de um evento
+	DroppedAttributesKey = attribute.Key("event.dropped_attributes")
+	// DroppedEventsKey conta, no span, os eventos descartados pelo limite
+	DroppedEventsKey = attribute.Key("span.dropped_events")
+)
+
+// markerAttributes número de atributos que Sanitize pode acrescentar
+const markerAttributes = 2
+
+// resolve substitui os zeros pelos limites padrão
+func resolve(limits interfaces.EventLimits) interfaces.EventLimits {
+	if limits.MaxEventsPerSpan == 0 {
+		limits.MaxEventsPerSpan = DefaultMaxEventsPerSpan
+	}
+	if limits.MaxAttributesPerEvent == 0 {
+		limits.MaxAttributesPerEvent = DefaultMaxAttributesPerEvent
+	}
+	if limits.MaxAttributeValueLength == 0 {
+		limits.MaxAttributeValueLength = DefaultMaxAttributeValueLength
+	}
+	if limits.MaxEventPayloadBytes == 0 {
+		limits.MaxEventPayloadBytes = DefaultMaxEventPayloadBytes
+	}
+	return limits
+}
+
+// SpanLimits converte os limites para o SDK do OpenTelemetry, para que o SDK
+// não descarte eventos aceitos pelo Guard (o padrão do SDK é 128 eventos)
+func SpanLimits(limits interfaces.EventLimits) sdktrace.SpanLimits {
+	limits = resolve(limits)
+	spanLimits := sdktrace.NewSpanLimits()
+	spanLimits.EventCountLimit = max(limits.MaxEventsPerSpan, -1)
+	spanLimits.AttributePerEventCountLimit = -1
+	if limits.MaxAttributesPerEvent > 0 {
+		spanLimits.AttributePerEventCountLimit = limits.MaxAttributesPerEvent + markerAttributes
+	}
+	return spanLimits
+}
+
+// Sanitize valida e limita os atributos de um evento. Atributos sem chave ou
+// sem tipo são descartados e chaves repetidas mantêm o último valor. Strings
+// maiores que MaxAttributeValueLength são truncadas e, quando o payload passa
+// de MaxEventPayloadBytes, a string que ultrapassa o limite é cortada e os
+// atributos seguintes que não cabem são descartados. Eventos alterados recebem
+// event.truncated e, se houve descarte, event.dropped_attributes
+func Sanitize(limits interfaces.EventLimits, attrs ...attribute.KeyValue) []attribute.KeyValue {
+	limits = resolve(limits)
+
+	dropped := 0
+	index := make(map[attribute.Key]int, len(attrs))
+	valid := make([]attribute.KeyValue, 0, len(attrs))
+	for _, kv := range attrs {
+		if !kv.Valid() {
+			dropped++
+			continue
+		}
+		if i, ok := index[kv.Key]; ok {
+			valid[i] = kv
+			continue
+		}
+		index[kv.Key] = len(valid)
+		valid = append(valid, kv)
+	}
+
+	truncated := false
+	budget := limits.MaxEventPayloadBytes
+	result := make([]attribute.KeyValue, 0, len(valid)+markerAttributes)
+	for i, kv := range valid {
+		if limits.MaxAttributesPerEvent > 0 && len(result) >= limits.MaxAttributesPerEvent {
+			dropped += len(valid) - i
+			break
+		}
+
+		var cut bool
+		if kv, cut = truncateValue(kv, limits.MaxAttributeValueLength); cut {
+			truncated = true
+		}
+
+		if budget >= 0 {
+			size := len(kv.Key) + valueSize(kv.Value)
+			if size > budget {
+				room := budget - len(kv.Key)
+				if kv.Value.Type() != attribute.STRING || room <= 0 {
+					dropped++
+					continue
+				}
+				kv = kv.Key.String(truncateString(kv.Value.AsString(), room))
+				size = len(kv.Key) + len(kv.Value.AsString())
+				truncated = true
+			}
+			budget -= size
+		}
+		result = append(result, kv)
+	}
+
+	if truncated || dropped > 0 {
+		result = append(result, TruncatedKey.Bool(true))
+	}
+	if dropped > 0 {
+		result = append(result, DroppedAttributesKey.Int(dropped))
+	}
+	return result
+}
+
+// truncateValue aplica o tamanho máximo a strings e slices de strings
+func truncateValue(kv attribute.KeyValue, maxLength int) (attribute.KeyValue, bool) {
+	if maxLength < 0 {
+		return kv, false
+	}
+	switch kv.Value.Type() {
+	case attribute.STRING:
+		if s := kv.Value.AsString(); len(s) > maxLength {
+			return kv.Key.String(truncateString(s, maxLength)), true
+		}
+	case attribute.STRINGSLICE:
+		values := kv.Value.AsStringSlice()
+		cut := false
+		for i, s := range values {
+			if len(s) > maxLength {
+				values[i] = truncateString(s, maxLength)
+				cut = true
+			}
+		}
+		if cut {
+			return kv.Key.StringSlice(values), true
+		}
+	}
+	return kv, false
+}
+
+// truncateString corta a string em até maxLength bytes sem quebrar runes
+func truncateString(s string, maxLength int) string {
+	if maxLength < 0 || len(s) <= maxLength {
+		return s
+	}
+	for maxLength > 0 && !utf8.RuneStart(s[maxLength]) {
+		maxLength--
+	}
+	return s[:maxLength]
+}
+
+// valueSize estima o tamanho serializado do valor em bytes
+func valueSize(v attribute.Value) int {
+	switch v.Type() {
+	case attribute.STRING:
+		return len(v.AsString())
+	case attribute.STRINGSLICE:
+		size := 0
+		for _, s := range v.AsStringSlice() {
+			size += len(s)
+		}
+		return size
+	case attribute.BOOL:
+		return 1
+	case attribute.BOOLSLICE:
+		return len(v.AsBoolSlice())
+	case attribute.INT64SLICE:
+		return 8 * len(v.AsInt64Slice())
+	case attribute.FLOAT64SLICE:
+		return 8 * len(v.AsFloat64Slice())
+	default:
+		return 8
+	}
+}
+
+// Guard envolve o tracer provider para que AddEvent e RecordError dos spans
+// respeitem os limites: eventos além de MaxEventsPerSpan são descartados e
+// contados em span.dropped_events, e os atributos passam por Sanitize
+func Guard(tp oteltrace.TracerProvider, limits interfaces.EventLimits) oteltrace.TracerProvider {
+	return &guardedProvider{TracerProvider: Unwrap(tp), limits: resolve(limits)}
+}
+
+// Unwrap retorna o tracer provider envolvido por Guard, ou o próprio provider
+func Unwrap(tp oteltrace.TracerProvider) oteltrace.TracerProvider {
+	if guarded, ok := tp.(*guardedProvider); ok {
+		return guarded.TracerProvider
+	}
+	return tp
+}
+
+type guardedProvider struct {
+	oteltrace.TracerProvider
+	limits interfaces.EventLimits
+}
+
+func (p *guardedProvider) Tracer(name string, opts ...oteltrace.TracerOption) oteltrace.Tracer {
+	return &guardedTracer{Tracer: p.TracerProvider.Tracer(name, opts...), provider: p}
+}
+
+type guardedTracer struct {
+	oteltrace.Tracer
+	provider *guardedProvider
+}
+
+func (t *guardedTracer) Start(ctx context.Context, name string, opts ...oteltrace.SpanStartOption) (context.Context, oteltrace.Span) {
+	ctx, span := t.Tracer.Start(ctx, name, opts...)
+	if !span.IsRecording() {
+		return ctx, span
+	}
+	guarded := &guardedSpan{Span: span, provider: t.provider}
+	return oteltrace.ContextWithSpan(ctx, guarded), guarded
+}
+
+type guardedSpan struct {
+	oteltrace.Span
+	provider *guardedProvider
+	mu       sync.Mutex
+	events   int
+	dropped  int
+}
+
+func (s *guardedSpan) AddEvent(name string, opts ...oteltrace.EventOption) {
+	// Após End o span deixa de gravar e Sanitize seria trabalho perdido
+	if !s.Span.IsRecording() || !s.reserve() {
+		return
+	}
+	name = truncateString(name, s.provider.limits.MaxAttributeValueLength)
+	s.Span.AddEvent(name, s.eventOptions(opts)...)
+}
+
+func (s *guardedSpan) RecordError(err error, opts ...oteltrace.EventOption) {
+	if err == nil || !s.Span.IsRecording() || !s.reserve() {
+		return
+	}
+	s.Span.RecordError(err, s.eventOptions(opts)...)
+}
+
+func (s *guardedSpan) End(opts ...oteltrace.SpanEndOption) {
+	s.mu.Lock()
+	dropped := s.dropped
+	s.mu.Unlock()
+
+	if dropped > 0 {
+		s.Span.SetAttributes(DroppedEventsKey.Int(dropped))
+	}
+	s.Span.End(opts...)
+}
+
+func (s *guardedSpan) TracerProvider() oteltrace.TracerProvider {
+	return s.provider
+}
+
+// reserve contabiliza um evento, retornando false quando o limite foi atingido
+func (s *guardedSpan) reserve() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if limit := s.provider.limits.MaxEventsPerSpan; limit >= 0 && s.events >= limit {
+		s.dropped++
+		return false
+	}
+	s.events++
+	return true
+}
+
+// eventOptions aplica Sanitize aos atributos, mantendo timestamp e stack trace
+func (s *guardedSpan) eventOptions(opts []oteltrace.EventOption) []oteltrace.EventOption {
+	cfg := oteltrace.NewEventConfig(opts...)
+	return []oteltrace.EventOption{
+		oteltrace.WithAttributes(Sanitize(s.provider.limits, cfg.Attributes()...)...),
+		oteltrace.WithTimestamp(cfg.Timestamp()),
+		oteltrace.WithStackTrace(cfg.StackTrace()),
+	}
+}
@@ -0,0 +1,152 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/fsvxavier/nexs-lib/observability/tracer/interfaces"
+)
+
+func newGuardedProvider(t *testing.T, limits interfaces.EventLimits) (oteltrace.TracerProvider, *tracetest.InMemoryExporter) {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSyncer(exporter),
+		sdktrace.WithSpanLimits(SpanLimits(limits)),
+	)
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	return Guard(tp, limits), exporter
+}
+
+func attrMap(attrs []attribute.KeyValue) map[attribute.Key]attribute.Value {
+	result := make(map[attribute.Key]attribute.Value, len(attrs))
+	for _, kv := range attrs {
+		result[kv.Key] = kv.Value
+	}
+	return result
+}
+
+func TestGuard_MaxEventsPerSpan(t *testing.T) {
+	// Acima do padrão do SDK (128) para garantir que SpanLimits o ajusta
+	tp, exporter := newGuardedProvider(t, interfaces.EventLimits{MaxEventsPerSpan: 200})
+
+	ctx, span := tp.Tracer("test").Start(context.Background(), "batch")
+	for i := 0; i < 205; i++ {
+		span.AddEvent("item")
+	}
+	// O span no contexto é o mesmo span protegido
+	oteltrace.SpanFromContext(ctx).RecordError(errors.New("boom"))
+	span.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Len(t, spans[0].Events, 200)
+	assert.Equal(t, int64(6), attrMap(spans[0].Attributes)[DroppedEventsKey].AsInt64())
+	assert.Same(t, tp, span.TracerProvider())
+}
+
+func TestGuard_OversizedPayload(t *testing.T) {
+	tp, exporter := newGuardedProvider(t, interfaces.EventLimits{
+		MaxAttributeValueLength: 10,
+		MaxEventPayloadBytes:    40,
+	})
+
+	_, span := tp.Tracer("test").Start(context.Background(), "upload")
+	timestamp := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	span.AddEvent("payload",
+		oteltrace.WithTimestamp(timestamp),
+		oteltrace.WithAttributes(
+			attribute.String("body", strings.Repeat("x", 1000)),
+			attribute.StringSlice("tags", []string{"short", strings.Repeat("y", 50)}),
+			attribute.String("", "no key"),
+			attribute.String("note", "ção-ção-ção"),
+			attribute.Int("size", 1000),
+		))
+	span.AddEvent("small", oteltrace.WithAttributes(attribute.String("id", "1"), attribute.String("id", "2")))
+	span.End()
+
+	events := exporter.GetSpans()[0].Events
+	require.Len(t, events, 2)
+	assert.Equal(t, timestamp, events[0].Time)
+
+	attrs := attrMap(events[0].Attributes)
+	assert.Equal(t, strings.Repeat("x", 10), attrs["body"].AsString())
+	assert.Equal(t, []string{"short", strings.Repeat("y", 10)}, attrs["tags"].AsStringSlice())
+	// "note" é cortado para caber no payload restante sem quebrar runes
+	assert.Equal(t, "ç", attrs["note"].AsString())
+	assert.NotContains(t, attrs, attribute.Key("size"))
+	assert.True(t, attrs[TruncatedKey].AsBool())
+	assert.Equal(t, int64(2), attrs[DroppedAttributesKey].AsInt64())
+
+	// Chaves repetidas mantêm o último valor e eventos intactos não são marcados
+	attrs = attrMap(events[1].Attributes)
+	assert.Equal(t, "2", attrs["id"].AsString())
+	assert.NotContains(t, attrs, TruncatedKey)
+}
+
+func TestSanitize_Unlimited(t *testing.T) {
+	limits := interfaces.EventLimits{
+		MaxAttributesPerEvent:   -1,
+		MaxAttributeValueLength: -1,
+		MaxEventPayloadBytes:    -1,
+	}
+	attrs := make([]attribute.KeyValue, 0, 100)
+	for i := 0; i < 100; i++ {
+		attrs = append(attrs, attribute.Int("k"+strings.Repeat("x", i), i))
+	}
+	attrs = append(attrs, attribute.String("big", strings.Repeat("z", 1<<20)))
+
+	assert.Equal(t, attrs, Sanitize(limits, attrs...))
+}
+
+func TestGuard_Unwrap(t *testing.T) {
+	tp := sdktrace.NewTracerProvider()
+	guarded := Guard(Guard(tp, interfaces.EventLimits{}), interfaces.EventLimits{MaxEventsPerSpan: 1})
+	assert.Same(t, tp, Unwrap(guarded))
+	assert.Same(t, tp, Unwrap(tp))
+}
+
+type status int
+
+func (s status) String() string { return "active" }
+
+func TestAttr(t *testing.T) {
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	attrs := attrMap(Attrs(map[string]any{
+		"string":   "value",
+		"bool":     true,
+		"int":      42,
+		"uint64":   uint64(1 << 63),
+		"float":    float32(1.5),
+		"ints":     []int{1, 2},
+		"time":     at,
+		"duration": 1500 * time.Millisecond,
+		"error":    errors.New("boom"),
+		"stringer": status(1),
+		"struct":   struct{ ID int }{ID: 7},
+		"nil":      nil,
+	}))
+
+	assert.Equal(t, "value", attrs["string"].AsString())
+	assert.True(t, attrs["bool"].AsBool())
+	assert.Equal(t, int64(42), attrs["int"].AsInt64())
+	assert.Equal(t, "9223372036854775808", attrs["uint64"].AsString())
+	assert.Equal(t, 1.5, attrs["float"].AsFloat64())
+	assert.Equal(t, []int64{1, 2}, attrs["ints"].AsInt64Slice())
+	assert.Equal(t, "2025-01-02T03:04:05Z", attrs["time"].AsString())
+	assert.Equal(t, "1.5s", attrs["duration"].AsString())
+	assert.Equal(t, "boom", attrs["error"].AsString())
+	assert.Equal(t, "active", attrs["stringer"].AsString())
+	assert.Equal(t, `{"ID":7}`, attrs["struct"].AsString())
+	assert.Equal(t, "", attrs["nil"].AsString())
+}
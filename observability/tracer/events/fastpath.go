@@ -0,0 +1,42 @@
+package events
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// SetAttributes adiciona ao span os atributos retornados por attrs. A função
+// só é chamada quando o span está gravando, evitando montar atributos (e
+// alocar o slice variádico) para spans não amostrados
+func SetAttributes(span oteltrace.Span, attrs func() []attribute.KeyValue) {
+	if !span.IsRecording() {
+		return
+	}
+	span.SetAttributes(attrs()...)
+}
+
+// AddEvent adiciona um evento com os atributos retornados por attrs, que só é
+// chamada quando o span está gravando. attrs pode ser nil
+func AddEvent(span oteltrace.Span, name string, attrs func() []attribute.KeyValue) {
+	if !span.IsRecording() {
+		return
+	}
+	if attrs == nil {
+		span.AddEvent(name)
+		return
+	}
+	span.AddEvent(name, oteltrace.WithAttributes(attrs()...))
+}
+
+// RecordError registra o erro com os atributos retornados por attrs, que só é
+// chamada quando o span está gravando e err não é nil. attrs pode ser nil
+func RecordError(span oteltrace.Span, err error, attrs func() []attribute.KeyValue) {
+	if err == nil || !span.IsRecording() {
+		return
+	}
+	if attrs == nil {
+		span.RecordError(err)
+		return
+	}
+	span.RecordError(err, oteltrace.WithAttributes(attrs()...))
+}
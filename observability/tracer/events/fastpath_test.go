@@ -0,0 +1,134 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/fsvxavier/nexs-lib/observability/tracer/interfaces"
+)
+
+// newUnsampledSpan cria um span não amostrado pelo SDK, envolvido pelo Guard
+func newUnsampledSpan(tb testing.TB) oteltrace.Span {
+	tb.Helper()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.NeverSample()))
+	tb.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	_, span := Guard(tp, interfaces.EventLimits{}).Tracer("test").Start(context.Background(), "unsampled")
+	return span
+}
+
+func TestFastPath_Recording(t *testing.T) {
+	tp, exporter := newGuardedProvider(t, interfaces.EventLimits{})
+	_, span := tp.Tracer("test").Start(context.Background(), "recording")
+
+	SetAttributes(span, func() []attribute.KeyValue {
+		return []attribute.KeyValue{attribute.String("user.id", "42")}
+	})
+	AddEvent(span, "cache.miss", func() []attribute.KeyValue {
+		return []attribute.KeyValue{attribute.String("cache.key", "user:42")}
+	})
+	AddEvent(span, "retry", nil)
+	RecordError(span, errors.New("boom"), nil)
+	RecordError(span, nil, nil)
+	span.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, attribute.StringValue("42"), attrMap(spans[0].Attributes)["user.id"])
+	require.Len(t, spans[0].Events, 3)
+	assert.Equal(t, "cache.miss", spans[0].Events[0].Name)
+	assert.Equal(t, attribute.StringValue("user:42"), attrMap(spans[0].Events[0].Attributes)["cache.key"])
+	assert.Equal(t, "retry", spans[0].Events[1].Name)
+	assert.Equal(t, "exception", spans[0].Events[2].Name)
+}
+
+func TestFastPath_Unsampled(t *testing.T) {
+	span := newUnsampledSpan(t)
+	require.False(t, span.IsRecording())
+
+	called := false
+	attrs := func() []attribute.KeyValue {
+		called = true
+		return nil
+	}
+	SetAttributes(span, attrs)
+	AddEvent(span, "event", attrs)
+	RecordError(span, errors.New("boom"), attrs)
+	assert.False(t, called, "attributes must not be built for unsampled spans")
+
+	allocs := testing.AllocsPerRun(100, func() {
+		SetAttributes(span, func() []attribute.KeyValue {
+			return []attribute.KeyValue{attribute.String("key", "value")}
+		})
+		AddEvent(span, "event", nil)
+	})
+	assert.Zero(t, allocs)
+}
+
+func TestGuard_EndedSpanSkipsSanitize(t *testing.T) {
+	tp, exporter := newGuardedProvider(t, interfaces.EventLimits{MaxEventsPerSpan: 1})
+	_, span := tp.Tracer("test").Start(context.Background(), "ended")
+	span.End()
+
+	// Eventos após End não contam como descartados
+	span.AddEvent("late")
+	span.RecordError(errors.New("late"))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Empty(t, spans[0].Events)
+	assert.NotContains(t, attrMap(spans[0].Attributes), DroppedEventsKey)
+}
+
+func BenchmarkSetAttributes_Unsampled(b *testing.B) {
+	span := newUnsampledSpan(b)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		SetAttributes(span, func() []attribute.KeyValue {
+			return []attribute.KeyValue{attribute.String("key", "value"), attribute.Int("count", 1)}
+		})
+	}
+}
+
+func BenchmarkAddEvent_Unsampled(b *testing.B) {
+	span := newUnsampledSpan(b)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		AddEvent(span, "event", func() []attribute.KeyValue {
+			return []attribute.KeyValue{attribute.String("key", "value")}
+		})
+	}
+}
+
+func BenchmarkSpanSetAttributes_Unsampled(b *testing.B) {
+	span := newUnsampledSpan(b)
+
+	// Chamada direta, para comparação: o slice variádico é alocado mesmo
+	// quando o span descarta os atributos
+	b.ReportAllocs()
+	for b.Loop() {
+		span.SetAttributes(attribute.String("key", "value"), attribute.Int("count", 1))
+	}
+}
+
+func BenchmarkAddEvent_Recording(b *testing.B) {
+	tp := sdktrace.NewTracerProvider()
+	b.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	limits := interfaces.EventLimits{MaxEventsPerSpan: -1}
+	_, span := Guard(tp, limits).Tracer("bench").Start(context.Background(), "recording")
+
+	b.ReportAllocs()
+	for b.Loop() {
+		AddEvent(span, "event", func() []attribute.KeyValue {
+			return []attribute.KeyValue{attribute.String("key", "value")}
+		})
+	}
+}
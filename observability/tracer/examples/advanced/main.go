@@ -11,6 +11,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/fsvxavier/nexs-lib/observability"
 	"github.com/fsvxavier/nexs-lib/observability/tracer"
 	"github.com/fsvxavier/nexs-lib/observability/tracer/config"
 	"go.opentelemetry.io/otel"
@@ -113,6 +114,15 @@ func initObservability(ctx context.Context) func() {
 		logger.Fatal("Failed to initialize tracer", zap.Error(err))
 	}
 
+	// O coordenador finaliza o tracer antes do logger, que registra as falhas
+	if err := observability.RegisterTracer("tracer", tracerManager); err != nil {
+		logger.Fatal("Failed to register tracer", zap.Error(err))
+	}
+	if err := observability.Register("logger", func(context.Context) error { return logger.Sync() },
+		observability.WithKind(observability.KindLogger)); err != nil {
+		logger.Fatal("Failed to register logger", zap.Error(err))
+	}
+
 	// Configurar TracerProvider global
 	otel.SetTracerProvider(tracerProvider)
 
@@ -126,10 +136,9 @@ func initObservability(ctx context.Context) func() {
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 		
-		if err := tracerManager.Shutdown(shutdownCtx); err != nil {
-			logger.Error("Failed to shutdown tracer", zap.Error(err))
+		if err := observability.Shutdown(shutdownCtx); err != nil {
+			logger.Error("Failed to shutdown observability", zap.Error(err))
 		}
-		logger.Sync()
 	}
 }
 
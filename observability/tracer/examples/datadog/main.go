@@ -11,6 +11,7 @@ import (
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 
+	"github.com/fsvxavier/nexs-lib/observability"
 	"github.com/fsvxavier/nexs-lib/observability/tracer"
 	"github.com/fsvxavier/nexs-lib/observability/tracer/config"
 	"github.com/fsvxavier/nexs-lib/observability/tracer/interfaces"
@@ -50,6 +51,11 @@ func main() {
 		log.Fatalf("❌ Erro ao inicializar tracer: %v", err)
 	}
 
+	// Registrar no coordenador, que finaliza os componentes em ordem
+	if err := observability.RegisterTracer("tracer", tracerManager); err != nil {
+		log.Fatalf("❌ Erro ao registrar tracer: %v", err)
+	}
+
 	// Configurar como tracer global (opcional)
 	otel.SetTracerProvider(tracerProvider)
 	fmt.Println("✅ Datadog tracer configurado globalmente")
@@ -66,7 +72,7 @@ func main() {
 
 	// Shutdown graceful
 	fmt.Println("🔄 Fazendo shutdown do tracer...")
-	if err := tracerManager.Shutdown(ctx); err != nil {
+	if err := observability.Shutdown(ctx); err != nil {
 		log.Printf("⚠️ Erro no shutdown: %v", err)
 	}
 
@@ -12,6 +12,7 @@ import (
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 
+	"github.com/fsvxavier/nexs-lib/observability"
 	"github.com/fsvxavier/nexs-lib/observability/tracer"
 	"github.com/fsvxavier/nexs-lib/observability/tracer/config"
 	"github.com/fsvxavier/nexs-lib/observability/tracer/interfaces"
@@ -81,6 +82,11 @@ func initGlobalTracing() func() {
 		log.Fatalf("❌ Erro ao inicializar tracer: %v", err)
 	}
 
+	// Registrar no coordenador, que finaliza os componentes em ordem
+	if err := observability.RegisterTracer("tracer", tracerManager); err != nil {
+		log.Fatalf("❌ Erro ao registrar tracer: %v", err)
+	}
+
 	// ⭐ CONFIGURAR COMO TRACER GLOBAL ⭐
 	// Isso permite que qualquer código da aplicação use otel.Tracer()
 	// sem precisar passar o TracerProvider explicitamente
@@ -92,7 +98,7 @@ func initGlobalTracing() func() {
 	// Retornar função de shutdown
 	return func() {
 		fmt.Println("🔄 Fazendo shutdown do tracer global...")
-		if err := tracerManager.Shutdown(ctx); err != nil {
+		if err := observability.Shutdown(ctx); err != nil {
 			log.Printf("⚠️ Erro no shutdown: %v", err)
 		}
 		fmt.Println("✅ Tracer global finalizado")
@@ -24,7 +24,7 @@ type Config struct {
 	// Environment é o ambiente de execução (dev, staging, prod)
 	Environment string `json:"environment" yaml:"environment"`
 
-	// ExporterType define qual provider usar (datadog, grafana, newrelic, opentelemetry)
+	// ExporterType define qual provider usar (datadog, grafana, newrelic, opentelemetry, memory, file)
 	ExporterType string `json:"exporter_type" yaml:"exporter_type"`
 
 	// Endpoint é o endpoint do trace collector
@@ -53,6 +53,50 @@ type Config struct {
 
 	// Attributes são atributos adicionais para os traces
 	Attributes map[string]string `json:"attributes" yaml:"attributes"`
+
+	// Events define os limites aplicados aos eventos dos spans
+	Events EventLimits `json:"events" yaml:"events"`
+
+	// SpanMetrics configura as métricas RED derivadas dos spans
+	SpanMetrics SpanMetricsConfig `json:"span_metrics" yaml:"span_metrics"`
+}
+
+// SpanMetricsConfig configura as métricas de taxa, erros e duração (RED)
+// derivadas dos spans, publicadas no MeterProvider global. Não é suportada
+// pelo provider Datadog, que não usa o SDK do OpenTelemetry
+type SpanMetricsConfig struct {
+	// Enabled ativa as métricas
+	Enabled bool `json:"enabled" yaml:"enabled"`
+
+	// Dimensions são atributos dos spans adicionados às métricas, como
+	// http.route. Devem ter baixa cardinalidade
+	Dimensions []string `json:"dimensions" yaml:"dimensions"`
+
+	// Buckets são os limites, em segundos, do histograma de duração (padrão
+	// metrics.DefaultLatencyBuckets)
+	Buckets []float64 `json:"buckets" yaml:"buckets"`
+
+	// MaxSeries é o número máximo de combinações de atributos (padrão 1000).
+	// As excedentes são agregadas em uma série de overflow
+	MaxSeries int `json:"max_series" yaml:"max_series"`
+}
+
+// EventLimits define os limites aplicados aos eventos dos spans. Zero usa o
+// padrão e valores negativos desativam o limite
+type EventLimits struct {
+	// MaxEventsPerSpan é o número máximo de eventos por span (padrão 128)
+	MaxEventsPerSpan int `json:"max_events_per_span" yaml:"max_events_per_span"`
+
+	// MaxAttributesPerEvent é o número máximo de atributos por evento (padrão 64)
+	MaxAttributesPerEvent int `json:"max_attributes_per_event" yaml:"max_attributes_per_event"`
+
+	// MaxAttributeValueLength é o tamanho máximo, em bytes, de cada valor
+	// string (padrão 4096)
+	MaxAttributeValueLength int `json:"max_attribute_value_length" yaml:"max_attribute_value_length"`
+
+	// MaxEventPayloadBytes é o tamanho máximo, em bytes, da soma das chaves e
+	// valores dos atributos de um evento (padrão 16384)
+	MaxEventPayloadBytes int `json:"max_event_payload_bytes" yaml:"max_event_payload_bytes"`
 }
 
 // TracerProviderFactory define a interface para criação de tracer providers
@@ -0,0 +1,310 @@
+// Package leakcheck detecta, em testes, spans iniciados e nunca finalizados e
+// contextos que escapam do ciclo de vida da requisição que os criou — como uma
+// goroutine que continua usando o contexto da requisição depois da resposta.
+// Os vazamentos são reportados no fim do teste com a stack de criação de cada
+// span:
+//
+//	provider := memory.NewProvider()
+//	tp, _ := provider.Init(ctx, cfg)
+//	detector := leakcheck.Verify(t, provider.TracerProvider())
+//
+//	ctx, end := detector.Request(ctx, "GET /orders")
+//	handle(ctx)
+//	end()
+//
+// Apenas spans gravados passam pelos span processors: use amostragem total
+// (SamplingRatio 1) ou spanmetrics.Sampler nos testes
+package leakcheck
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// Valores padrão do Detector
+const (
+	// DefaultGracePeriod tempo que Check aguarda os spans em aberto, para não
+	// reportar goroutines finalizando junto com o teste
+	DefaultGracePeriod = 100 * time.Millisecond
+	// DefaultStackDepth número máximo de frames das stacks de criação
+	DefaultStackDepth = 32
+)
+
+// Kind classifica um vazamento
+type Kind int
+
+const (
+	// Unfinished span iniciado e não finalizado até o fim do teste
+	Unfinished Kind = iota
+	// Escaped span em aberto no fim da requisição que o iniciou, ou iniciado
+	// a partir do contexto de uma requisição já finalizada
+	Escaped
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Unfinished:
+		return "unfinished span"
+	case Escaped:
+		return "span escaped its request"
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// Leak é um vazamento detectado
+type Leak struct {
+	Kind        Kind
+	Name        string
+	SpanContext oteltrace.SpanContext
+	StartTime   time.Time
+	// Request é o nome da requisição do span, vazio fora de requisições
+	Request string
+	// Stack é a stack de criação do span, sem os frames do SDK
+	Stack string
+}
+
+func (l Leak) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %q (trace %s, span %s)", l.Kind, l.Name, l.SpanContext.TraceID(), l.SpanContext.SpanID())
+	if l.Request != "" {
+		fmt.Fprintf(&b, " of request %q", l.Request)
+	}
+	if l.Stack != "" {
+		b.WriteString(", started at:\n")
+		b.WriteString(l.Stack)
+	}
+	return b.String()
+}
+
+// Option configura o Detector
+type Option func(*Detector)
+
+// WithGracePeriod define quanto tempo Check aguarda os spans em aberto
+// antes de reportá-los
+func WithGracePeriod(d time.Duration) Option {
+	return func(det *Detector) {
+		det.grace = d
+	}
+}
+
+// WithStackDepth define o número máximo de frames das stacks de criação; 0
+// desativa a captura, reduzindo o custo de cada span
+func WithStackDepth(n int) Option {
+	return func(det *Detector) {
+		det.depth = n
+	}
+}
+
+// IgnoreSpans ignora os spans cujo nome casa com um dos padrões path.Match,
+// como spans de background que vivem além do teste por design
+func IgnoreSpans(patterns ...string) Option {
+	return func(det *Detector) {
+		det.ignore = append(det.ignore, patterns...)
+	}
+}
+
+// Detector é um sdktrace.SpanProcessor que acompanha os spans em aberto e os
+// ciclos de vida das requisições
+type Detector struct {
+	grace  time.Duration
+	depth  int
+	ignore []string
+
+	mu      sync.Mutex
+	open    map[spanKey]*entry
+	escaped []Leak
+}
+
+var _ sdktrace.SpanProcessor = (*Detector)(nil)
+
+// spanKey identifica um span. O OnEnd recebe um snapshot, não o mesmo valor
+// recebido pelo OnStart
+type spanKey struct {
+	traceID oteltrace.TraceID
+	spanID  oteltrace.SpanID
+}
+
+func keyOf(s sdktrace.ReadOnlySpan) spanKey {
+	return spanKey{traceID: s.SpanContext().TraceID(), spanID: s.SpanContext().SpanID()}
+}
+
+// entry é um span em aberto
+type entry struct {
+	leak    Leak
+	request *request
+	// reported indica que o span já foi reportado como Escaped
+	reported bool
+}
+
+// New cria o Detector. Registre-o no TracerProvider com
+// sdktrace.WithSpanProcessor ou RegisterSpanProcessor, ou use Verify
+func New(opts ...Option) *Detector {
+	d := &Detector{
+		grace: DefaultGracePeriod,
+		depth: DefaultStackDepth,
+		open:  make(map[spanKey]*entry),
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Verify registra um Detector no TracerProvider e verifica os vazamentos no
+// fim do teste, reportando-os como erros
+func Verify(tb testing.TB, tp *sdktrace.TracerProvider, opts ...Option) *Detector {
+	tb.Helper()
+	d := New(opts...)
+	tp.RegisterSpanProcessor(d)
+	tb.Cleanup(func() {
+		d.Check(tb)
+		tp.UnregisterSpanProcessor(d)
+	})
+	return d
+}
+
+// OnStart registra o span em aberto com a sua stack de criação e, quando o
+// contexto pai pertence a uma requisição já finalizada, o vazamento
+func (d *Detector) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	if d.ignored(s.Name()) {
+		return
+	}
+	e := &entry{
+		leak: Leak{
+			Kind:        Unfinished,
+			Name:        s.Name(),
+			SpanContext: s.SpanContext(),
+			StartTime:   s.StartTime(),
+			Stack:       d.stack(),
+		},
+		request: requestFrom(parent),
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if r := e.request; r != nil {
+		e.leak.Request = r.name
+		if r.ended {
+			d.escape(e)
+		}
+	}
+	d.open[keyOf(s)] = e
+}
+
+// OnEnd remove o span dos spans em aberto
+func (d *Detector) OnEnd(s sdktrace.ReadOnlySpan) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.open, keyOf(s))
+}
+
+// Shutdown não tem recursos a liberar
+func (d *Detector) Shutdown(context.Context) error { return nil }
+
+// ForceFlush não tem dados retidos
+func (d *Detector) ForceFlush(context.Context) error { return nil }
+
+// Leaks retorna os spans que escaparam das suas requisições e os spans em
+// aberto, do mais antigo para o mais novo
+func (d *Detector) Leaks() []Leak {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	leaks := slices.Clone(d.escaped)
+	var unfinished []Leak
+	for _, e := range d.open {
+		if !e.reported {
+			unfinished = append(unfinished, e.leak)
+		}
+	}
+	slices.SortFunc(unfinished, func(a, b Leak) int {
+		return a.StartTime.Compare(b.StartTime)
+	})
+	return append(leaks, unfinished...)
+}
+
+// Check aguarda até o grace period que os spans em aberto sejam finalizados
+// e reporta os vazamentos restantes como erros do teste
+func (d *Detector) Check(tb testing.TB) {
+	tb.Helper()
+	deadline := time.Now().Add(d.grace)
+	for d.pending() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	for _, leak := range d.Leaks() {
+		tb.Errorf("leakcheck: %s", leak)
+	}
+}
+
+// pending informa se há spans em aberto ainda não reportados como escapados
+func (d *Detector) pending() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, e := range d.open {
+		if !e.reported {
+			return true
+		}
+	}
+	return false
+}
+
+// escape reporta o span como Escaped. Deve ser chamado com o lock
+func (d *Detector) escape(e *entry) {
+	leak := e.leak
+	leak.Kind = Escaped
+	d.escaped = append(d.escaped, leak)
+	e.reported = true
+}
+
+// ignored informa se o nome do span casa com os padrões ignorados
+func (d *Detector) ignored(name string) bool {
+	for _, pattern := range d.ignore {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// stack retorna a stack do chamador de tracer.Start, sem os frames do SDK e
+// deste pacote
+func (d *Detector) stack() string {
+	if d.depth <= 0 {
+		return ""
+	}
+	pcs := make([]uintptr, d.depth+16)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var b strings.Builder
+	written := 0
+	for written < d.depth {
+		frame, more := frames.Next()
+		if !internalFrame(frame.Function) {
+			fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+			written++
+		}
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
+
+// internalFrame informa se o frame é do SDK, dos wrappers do tracer ou
+// deste pacote
+func internalFrame(function string) bool {
+	return strings.HasPrefix(function, "go.opentelemetry.io/otel") ||
+		strings.HasPrefix(function, "github.com/fsvxavier/nexs-lib/observability/tracer/events.") ||
+		strings.HasPrefix(function, "github.com/fsvxavier/nexs-lib/observability/tracer/leakcheck.(*Detector)")
+}
@@ -0,0 +1,155 @@
+package leakcheck
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/fsvxavier/nexs-lib/observability/tracer/interfaces"
+	"github.com/fsvxavier/nexs-lib/observability/tracer/providers/memory"
+)
+
+// recorder captura os erros reportados por Check
+type recorder struct {
+	testing.TB
+	mu     sync.Mutex
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func newTracer(t *testing.T, opts ...Option) (oteltrace.Tracer, *Detector) {
+	t.Helper()
+	d := New(opts...)
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(d))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	return tp.Tracer("test"), d
+}
+
+func startLeaking(ctx context.Context, tracer oteltrace.Tracer) {
+	_, _ = tracer.Start(ctx, "forgotten")
+}
+
+func TestDetector_Unfinished(t *testing.T) {
+	tracer, d := newTracer(t, WithGracePeriod(0))
+	ctx := context.Background()
+
+	_, ended := tracer.Start(ctx, "ended")
+	ended.End()
+	startLeaking(ctx, tracer)
+
+	leaks := d.Leaks()
+	require.Len(t, leaks, 1)
+	assert.Equal(t, Unfinished, leaks[0].Kind)
+	assert.Equal(t, "forgotten", leaks[0].Name)
+	assert.Contains(t, leaks[0].Stack, "leakcheck.startLeaking")
+	assert.Contains(t, leaks[0].Stack, "leakcheck.TestDetector_Unfinished")
+	assert.NotContains(t, leaks[0].Stack, "go.opentelemetry.io/otel")
+
+	rec := &recorder{TB: t}
+	d.Check(rec)
+	require.Len(t, rec.errors, 1)
+	assert.Contains(t, rec.errors[0], `leakcheck: unfinished span "forgotten"`)
+	assert.Contains(t, rec.errors[0], "started at:\n")
+}
+
+func TestDetector_GracePeriod(t *testing.T) {
+	tracer, d := newTracer(t, WithGracePeriod(time.Second))
+	_, span := tracer.Start(context.Background(), "background")
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		span.End()
+	}()
+
+	rec := &recorder{TB: t}
+	d.Check(rec)
+	assert.Empty(t, rec.errors)
+}
+
+func TestDetector_Request(t *testing.T) {
+	tracer, d := newTracer(t, WithGracePeriod(0), WithStackDepth(0))
+	ctx, end := d.Request(context.Background(), "GET /orders")
+
+	_, handled := tracer.Start(ctx, "handled")
+	handled.End()
+	_, outlived := tracer.Start(ctx, "outlived")
+	_, detached := tracer.Start(Detach(ctx), "detached")
+	end()
+	end()
+	outlived.End()
+	detached.End()
+	_, late := tracer.Start(ctx, "late")
+	late.End()
+
+	leaks := d.Leaks()
+	require.Len(t, leaks, 2)
+	for i, name := range []string{"outlived", "late"} {
+		assert.Equal(t, Escaped, leaks[i].Kind)
+		assert.Equal(t, name, leaks[i].Name)
+		assert.Equal(t, "GET /orders", leaks[i].Request)
+		assert.Empty(t, leaks[i].Stack)
+	}
+	assert.Equal(t, `span escaped its request "outlived" (trace `+leaks[0].SpanContext.TraceID().String()+
+		", span "+leaks[0].SpanContext.SpanID().String()+`) of request "GET /orders"`, leaks[0].String())
+}
+
+func TestDetector_Middleware(t *testing.T) {
+	tracer, d := newTracer(t, WithGracePeriod(0), IgnoreSpans("audit.*"))
+	done := make(chan struct{})
+	handler := d.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, span := tracer.Start(r.Context(), "handler")
+		span.End()
+		_, audit := tracer.Start(r.Context(), "audit.write")
+		go func() {
+			defer close(done)
+			<-r.Context().Done()
+			_, span := tracer.Start(r.Context(), "after response")
+			span.End()
+			audit.End()
+		}()
+	}))
+
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+	resp, err := http.Get(srv.URL + "/orders")
+	require.NoError(t, err)
+	resp.Body.Close()
+	<-done
+
+	leaks := d.Leaks()
+	require.Len(t, leaks, 1)
+	assert.Equal(t, "after response", leaks[0].Name)
+	assert.Equal(t, "GET /orders", leaks[0].Request)
+}
+
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+	provider := memory.NewProvider()
+	tp, err := provider.Init(ctx, interfaces.Config{ServiceName: "orders", SamplingRatio: 1})
+	require.NoError(t, err)
+	defer provider.Shutdown(ctx)
+
+	d := Verify(t, provider.TracerProvider())
+	ctx, end := d.Request(ctx, "job")
+	_, span := tp.Tracer("test").Start(ctx, "work")
+	span.End()
+	end()
+
+	assert.Empty(t, d.Leaks())
+	assert.Equal(t, 1, provider.Exporter().Len())
+}
@@ -0,0 +1,66 @@
+package leakcheck
+
+import (
+	"context"
+	"net/http"
+)
+
+// requestKey é a chave do contexto para a requisição
+type requestKey struct{}
+
+// request é o ciclo de vida de uma requisição
+type request struct {
+	name  string
+	ended bool
+}
+
+// requestFrom retorna a requisição do contexto, ou nil
+func requestFrom(ctx context.Context) *request {
+	if ctx == nil {
+		return nil
+	}
+	r, _ := ctx.Value(requestKey{}).(*request)
+	return r
+}
+
+// Request inicia o ciclo de vida de uma requisição. Os spans iniciados a
+// partir do contexto retornado devem ser finalizados antes de end; os que
+// continuam em aberto, e os iniciados a partir do contexto depois de end,
+// são reportados como Escaped. end é idempotente
+func (d *Detector) Request(ctx context.Context, name string) (context.Context, func()) {
+	r := &request{name: name}
+	return context.WithValue(ctx, requestKey{}, r), func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		if r.ended {
+			return
+		}
+		r.ended = true
+		for _, e := range d.open {
+			if e.request == r && !e.reported {
+				d.escape(e)
+			}
+		}
+	}
+}
+
+// Middleware envolve cada requisição HTTP em um ciclo de vida, finalizado
+// quando o handler retorna
+func (d *Detector) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, end := d.Request(r.Context(), r.Method+" "+r.URL.Path)
+		defer end()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Detach retorna um contexto desvinculado da requisição, para o trabalho em
+// background que deve sobreviver a ela por design, como o envio assíncrono
+// de eventos. Os valores e o span do contexto são mantidos; o cancelamento
+// não é alterado, combine com context.WithoutCancel quando necessário
+func Detach(ctx context.Context) context.Context {
+	if requestFrom(ctx) == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, requestKey{}, (*request)(nil))
+}
@@ -0,0 +1,59 @@
+package tracer
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// WithLinks vincula o span iniciado aos span contexts informados. É a forma
+// de representar múltiplos pais, como um consumidor que processa em um único
+// span mensagens produzidas por traces diferentes. Contexts inválidos e
+// repetidos são ignorados
+func WithLinks(spanContexts ...oteltrace.SpanContext) oteltrace.SpanStartOption {
+	return oteltrace.WithLinks(Links(spanContexts)...)
+}
+
+// Links converte span contexts em links, ignorando contexts inválidos e
+// repetidos. Os atributos informados são adicionados a todos os links
+func Links(spanContexts []oteltrace.SpanContext, attrs ...attribute.KeyValue) []oteltrace.Link {
+	links := make([]oteltrace.Link, 0, len(spanContexts))
+	seen := make(map[linkKey]struct{}, len(spanContexts))
+	for _, sc := range spanContexts {
+		if !sc.IsValid() {
+			continue
+		}
+		key := linkKey{traceID: sc.TraceID(), spanID: sc.SpanID()}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		links = append(links, oteltrace.Link{SpanContext: sc, Attributes: attrs})
+	}
+	return links
+}
+
+// linkKey identifica o span referenciado por um link
+type linkKey struct {
+	traceID oteltrace.TraceID
+	spanID  oteltrace.SpanID
+}
+
+// SpanContextsFromCarriers extrai, com o propagador global, os span contexts
+// dos carriers informados, tipicamente os headers de cada mensagem de um lote.
+// Carriers sem contexto de trace são ignorados
+func SpanContextsFromCarriers(carriers ...propagation.TextMapCarrier) []oteltrace.SpanContext {
+	propagator := otel.GetTextMapPropagator()
+	spanContexts := make([]oteltrace.SpanContext, 0, len(carriers))
+	for _, carrier := range carriers {
+		// Extrai em um contexto vazio para não herdar o span do consumidor
+		sc := oteltrace.SpanContextFromContext(propagator.Extract(context.Background(), carrier))
+		if sc.IsValid() {
+			spanContexts = append(spanContexts, sc)
+		}
+	}
+	return spanContexts
+}
@@ -0,0 +1,77 @@
+package tracer
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/fsvxavier/nexs-lib/observability/tracer/interfaces"
+	"github.com/fsvxavier/nexs-lib/observability/tracer/providers/memory"
+)
+
+func TestWithLinks_BatchConsumer(t *testing.T) {
+	provider := memory.NewProvider()
+	tp, err := provider.Init(context.Background(), interfaces.Config{ServiceName: "consumer", SamplingRatio: 1.0})
+	if err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer provider.Shutdown(context.Background())
+	tr := tp.Tracer("test")
+
+	// Produtores de traces diferentes injetam o contexto nos headers das mensagens
+	var carriers []propagation.TextMapCarrier
+	for _, name := range []string{"producer-a", "producer-b"} {
+		ctx, span := tr.Start(context.Background(), name)
+		carrier := propagation.MapCarrier{}
+		otel.GetTextMapPropagator().Inject(ctx, carrier)
+		carriers = append(carriers, carrier, carrier) // mensagens repetidas do mesmo span
+		span.End()
+	}
+	carriers = append(carriers, propagation.MapCarrier{}) // mensagem sem contexto
+
+	// O consumidor já possui um span ativo, que não deve virar link
+	ctx, parent := tr.Start(context.Background(), "poll")
+	spanContexts := SpanContextsFromCarriers(carriers...)
+	if len(spanContexts) != 4 {
+		t.Fatalf("expected 4 span contexts, got %d", len(spanContexts))
+	}
+	_, span := tr.Start(ctx, "process-batch", WithLinks(spanContexts...))
+	span.End()
+	parent.End()
+
+	processed, ok := provider.Exporter().FindSpan("process-batch")
+	if !ok {
+		t.Fatal("process-batch span not exported")
+	}
+	if len(processed.Links) != 2 {
+		t.Fatalf("expected 2 links, got %d", len(processed.Links))
+	}
+	for i, name := range []string{"producer-a", "producer-b"} {
+		producer, _ := provider.Exporter().FindSpan(name)
+		if processed.Links[i].SpanContext.SpanID() != producer.SpanContext.SpanID() {
+			t.Errorf("link %d does not reference %s", i, name)
+		}
+		if processed.Links[i].SpanContext.TraceID() == processed.SpanContext.TraceID() {
+			t.Errorf("link %d should reference another trace", i)
+		}
+	}
+}
+
+func TestLinks_Attributes(t *testing.T) {
+	sc := oteltrace.NewSpanContext(oteltrace.SpanContextConfig{
+		TraceID: oteltrace.TraceID{1},
+		SpanID:  oteltrace.SpanID{1},
+	})
+
+	links := Links([]oteltrace.SpanContext{sc, {}, sc}, attribute.String("messaging.system", "kafka"))
+	if len(links) != 1 {
+		t.Fatalf("expected 1 link, got %d", len(links))
+	}
+	if links[0].Attributes[0].Value.AsString() != "kafka" {
+		t.Errorf("unexpected link attributes: %v", links[0].Attributes)
+	}
+}
@@ -9,14 +9,17 @@ import (
 	"go.opentelemetry.io/otel/propagation"
 	oteltrace "go.opentelemetry.io/otel/trace"
 
+	ddotel "github.com/DataDog/dd-trace-go/v2/ddtrace/opentelemetry"
 	ddtracer "github.com/DataDog/dd-trace-go/v2/ddtrace/tracer"
 
+	"github.com/fsvxavier/nexs-lib/observability/tracer/events"
 	"github.com/fsvxavier/nexs-lib/observability/tracer/interfaces"
 )
 
 // Provider implementa TracerProvider para Datadog APM
 type Provider struct {
-	initialized bool
+	initialized    bool
+	tracerProvider *ddotel.TracerProvider
 }
 
 // NewProvider cria uma nova instância do provider Datadog
@@ -56,26 +59,27 @@ func (p *Provider) Init(ctx context.Context, config interfaces.Config) (oteltrac
 		opts = append(opts, ddtracer.WithGlobalTag("version", config.Version))
 	}
 
-	// Iniciar tracer Datadog
-	ddtracer.Start(opts...)
-	p.initialized = true
-
 	// Configurar propagadores
 	if err := p.configurePropagators(config.Propagators); err != nil {
 		return nil, fmt.Errorf("failed to configure propagators: %w", err)
 	}
 
-	// Para compatibilidade com OpenTelemetry, retornamos um provider que usa o tracer global
-	// Em um cenário real, você pode usar um bridge específico ou implementar um wrapper
-	provider := otel.GetTracerProvider()
+	// Iniciar tracer Datadog pelo bridge OpenTelemetry, que converte os spans
+	// (links viram span links e eventos viram span events do Datadog)
+	p.tracerProvider = ddotel.NewTracerProvider(opts...)
+	p.initialized = true
+
+	// O tracer Datadog não limita eventos; o Guard aplica os limites
+	guarded := events.Guard(p.tracerProvider, config.Events)
+	otel.SetTracerProvider(guarded)
 
-	return provider, nil
+	return guarded, nil
 }
 
 // Shutdown finaliza o tracer provider Datadog
 func (p *Provider) Shutdown(ctx context.Context) error {
 	if p.initialized {
-		ddtracer.Stop()
+		_ = p.tracerProvider.Shutdown()
 		p.initialized = false
 	}
 	return nil
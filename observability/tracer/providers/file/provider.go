@@ -0,0 +1,283 @@
+// Package file fornece um tracer provider que escreve os spans como JSON
+// (um span por linha) em arquivo ou stdout, para depuração local sem agent
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.28.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/fsvxavier/nexs-lib/observability/tracer/events"
+	"github.com/fsvxavier/nexs-lib/observability/tracer/interfaces"
+	"github.com/fsvxavier/nexs-lib/observability/tracer/spanmetrics"
+)
+
+const (
+	// StdoutEndpoint direciona os spans para a saída padrão
+	StdoutEndpoint = "stdout"
+	// StderrEndpoint direciona os spans para a saída de erro
+	StderrEndpoint = "stderr"
+)
+
+// SpanRecord representação JSON de um span exportado
+type SpanRecord struct {
+	Name         string         `json:"name"`
+	TraceID      string         `json:"trace_id"`
+	SpanID       string         `json:"span_id"`
+	ParentSpanID string         `json:"parent_span_id,omitempty"`
+	Kind         string         `json:"kind"`
+	StartTime    time.Time      `json:"start_time"`
+	EndTime      time.Time      `json:"end_time"`
+	DurationMs   float64        `json:"duration_ms"`
+	StatusCode   string         `json:"status_code"`
+	StatusDesc   string         `json:"status_description,omitempty"`
+	Attributes   map[string]any `json:"attributes,omitempty"`
+	Events       []EventRecord  `json:"events,omitempty"`
+	Links        []LinkRecord   `json:"links,omitempty"`
+	Resource     map[string]any `json:"resource,omitempty"`
+	Scope        string         `json:"scope,omitempty"`
+}
+
+// EventRecord representação JSON de um evento de span
+type EventRecord struct {
+	Name       string         `json:"name"`
+	Time       time.Time      `json:"time"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+// LinkRecord representação JSON de um link de span
+type LinkRecord struct {
+	TraceID    string         `json:"trace_id"`
+	SpanID     string         `json:"span_id"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+// Exporter escreve spans como JSON lines em um io.Writer
+type Exporter struct {
+	mu      sync.Mutex
+	writer  io.Writer
+	closer  io.Closer
+	encoder *json.Encoder
+	stopped bool
+}
+
+// NewExporter cria um exporter que escreve no writer informado
+func NewExporter(w io.Writer) *Exporter {
+	e := &Exporter{
+		writer:  w,
+		encoder: json.NewEncoder(w),
+	}
+	if c, ok := w.(io.Closer); ok && w != os.Stdout && w != os.Stderr {
+		e.closer = c
+	}
+	return e
+}
+
+// NewFileExporter cria um exporter que escreve no arquivo informado (modo append)
+func NewFileExporter(path string) (*Exporter, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open trace file %s: %w", path, err)
+	}
+	return NewExporter(f), nil
+}
+
+// ExportSpans implementa trace.SpanExporter
+func (e *Exporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.stopped {
+		return nil
+	}
+
+	for _, s := range spans {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := e.encoder.Encode(ToRecord(s)); err != nil {
+			return fmt.Errorf("failed to encode span %s: %w", s.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// Shutdown implementa trace.SpanExporter
+func (e *Exporter) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.stopped {
+		return nil
+	}
+	e.stopped = true
+
+	if syncer, ok := e.writer.(interface{ Sync() error }); ok {
+		_ = syncer.Sync()
+	}
+	if e.closer != nil {
+		return e.closer.Close()
+	}
+	return nil
+}
+
+// ToRecord converte um span do SDK para sua representação JSON
+func ToRecord(s trace.ReadOnlySpan) SpanRecord {
+	record := SpanRecord{
+		Name:       s.Name(),
+		TraceID:    s.SpanContext().TraceID().String(),
+		SpanID:     s.SpanContext().SpanID().String(),
+		Kind:       s.SpanKind().String(),
+		StartTime:  s.StartTime(),
+		EndTime:    s.EndTime(),
+		DurationMs: float64(s.EndTime().Sub(s.StartTime())) / float64(time.Millisecond),
+		StatusCode: s.Status().Code.String(),
+		StatusDesc: s.Status().Description,
+		Attributes: attributesToMap(s.Attributes()),
+		Scope:      s.InstrumentationScope().Name,
+	}
+
+	if s.Parent().IsValid() {
+		record.ParentSpanID = s.Parent().SpanID().String()
+	}
+
+	if res := s.Resource(); res != nil {
+		record.Resource = attributesToMap(res.Attributes())
+	}
+
+	for _, ev := range s.Events() {
+		record.Events = append(record.Events, EventRecord{
+			Name:       ev.Name,
+			Time:       ev.Time,
+			Attributes: attributesToMap(ev.Attributes),
+		})
+	}
+
+	for _, link := range s.Links() {
+		record.Links = append(record.Links, LinkRecord{
+			TraceID:    link.SpanContext.TraceID().String(),
+			SpanID:     link.SpanContext.SpanID().String(),
+			Attributes: attributesToMap(link.Attributes),
+		})
+	}
+
+	return record
+}
+
+// attributesToMap converte atributos em um mapa serializável
+func attributesToMap(attrs []attribute.KeyValue) map[string]any {
+	if len(attrs) == 0 {
+		return nil
+	}
+	result := make(map[string]any, len(attrs))
+	for _, attr := range attrs {
+		result[string(attr.Key)] = attr.Value.AsInterface()
+	}
+	return result
+}
+
+// Provider implementa TracerProvider escrevendo spans em arquivo ou stdout
+type Provider struct {
+	tracerProvider *trace.TracerProvider
+	exporter       *Exporter
+	writer         io.Writer
+}
+
+// NewProvider cria uma nova instância do provider de arquivo.
+// O destino é definido por Config.Endpoint: caminho do arquivo, "stdout" ou "stderr"
+func NewProvider() *Provider {
+	return &Provider{}
+}
+
+// NewProviderWithWriter cria um provider que escreve no writer informado,
+// ignorando Config.Endpoint
+func NewProviderWithWriter(w io.Writer) *Provider {
+	return &Provider{writer: w}
+}
+
+// Init inicializa o tracer provider de arquivo
+func (p *Provider) Init(ctx context.Context, config interfaces.Config) (oteltrace.TracerProvider, error) {
+	exporter, err := p.createExporter(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create exporter: %w", err)
+	}
+	p.exporter = exporter
+
+	attrs := []attribute.KeyValue{
+		semconv.ServiceName(config.ServiceName),
+		semconv.ServiceVersion(config.Version),
+		semconv.DeploymentEnvironmentName(config.Environment),
+	}
+	for key, value := range config.Attributes {
+		attrs = append(attrs, attribute.String(key, value))
+	}
+
+	opts, err := spanmetrics.Options(config.SpanMetrics, trace.ParentBased(trace.TraceIDRatioBased(config.SamplingRatio)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create span metrics: %w", err)
+	}
+
+	p.tracerProvider = trace.NewTracerProvider(append(opts,
+		trace.WithBatcher(exporter),
+		trace.WithResource(resource.NewSchemaless(attrs...)),
+		trace.WithSpanLimits(events.SpanLimits(config.Events)),
+	)...)
+
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	// Aplicar os limites de eventos e definir como global
+	guarded := events.Guard(p.tracerProvider, config.Events)
+	otel.SetTracerProvider(guarded)
+
+	return guarded, nil
+}
+
+// Shutdown finaliza o tracer provider, garantindo o flush dos spans pendentes
+func (p *Provider) Shutdown(ctx context.Context) error {
+	if p.tracerProvider != nil {
+		if err := p.tracerProvider.Shutdown(ctx); err != nil {
+			return fmt.Errorf("failed to shutdown tracer provider: %w", err)
+		}
+	}
+	return nil
+}
+
+// ForceFlush exporta imediatamente os spans pendentes
+func (p *Provider) ForceFlush(ctx context.Context) error {
+	if p.tracerProvider != nil {
+		return p.tracerProvider.ForceFlush(ctx)
+	}
+	return nil
+}
+
+// createExporter cria o exporter de acordo com o destino configurado
+func (p *Provider) createExporter(config interfaces.Config) (*Exporter, error) {
+	if p.writer != nil {
+		return NewExporter(p.writer), nil
+	}
+
+	switch config.Endpoint {
+	case "", StdoutEndpoint:
+		return NewExporter(os.Stdout), nil
+	case StderrEndpoint:
+		return NewExporter(os.Stderr), nil
+	default:
+		return NewFileExporter(config.Endpoint)
+	}
+}
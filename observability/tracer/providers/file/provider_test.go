@@ -0,0 +1,113 @@
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/fsvxavier/nexs-lib/observability/tracer/interfaces"
+)
+
+func newTestConfig(endpoint string) interfaces.Config {
+	return interfaces.Config{
+		ServiceName:   "test-service",
+		Environment:   "test",
+		ExporterType:  "file",
+		Endpoint:      endpoint,
+		SamplingRatio: 1.0,
+		Version:       "1.0.0",
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	assert.NotNil(t, NewProvider())
+}
+
+func TestProvider_WritesJSONLines(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+	provider := NewProviderWithWriter(&buf)
+
+	tp, err := provider.Init(ctx, newTestConfig(""))
+	require.NoError(t, err)
+
+	parentCtx, parent := tp.Tracer("test").Start(ctx, "parent")
+	_, child := tp.Tracer("test").Start(parentCtx, "child")
+	child.SetAttributes(attribute.String("key", "value"))
+	child.AddEvent("checkpoint")
+	child.SetStatus(codes.Error, "failed")
+	child.End()
+	parent.End()
+
+	require.NoError(t, provider.Shutdown(ctx))
+
+	var records []SpanRecord
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var record SpanRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	require.Len(t, records, 2)
+
+	assert.Equal(t, "child", records[0].Name)
+	assert.Equal(t, records[1].SpanID, records[0].ParentSpanID)
+	assert.Equal(t, "value", records[0].Attributes["key"])
+	assert.Equal(t, "Error", records[0].StatusCode)
+	assert.Equal(t, "failed", records[0].StatusDesc)
+	require.Len(t, records[0].Events, 1)
+	assert.Equal(t, "checkpoint", records[0].Events[0].Name)
+	assert.Equal(t, "test-service", records[0].Resource["service.name"])
+	assert.Empty(t, records[1].ParentSpanID)
+}
+
+func TestProvider_WritesToFile(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "traces.jsonl")
+	provider := NewProvider()
+
+	tp, err := provider.Init(ctx, newTestConfig(path))
+	require.NoError(t, err)
+
+	_, span := tp.Tracer("test").Start(ctx, "operation")
+	span.End()
+	require.NoError(t, provider.Shutdown(ctx))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"name":"operation"`)
+}
+
+func TestProvider_InvalidPath(t *testing.T) {
+	provider := NewProvider()
+	_, err := provider.Init(context.Background(), newTestConfig(filepath.Join(t.TempDir(), "missing", "traces.jsonl")))
+	assert.Error(t, err)
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestExporter_WriteError(t *testing.T) {
+	ctx := context.Background()
+	exporter := NewExporter(failingWriter{})
+
+	err := exporter.ExportSpans(ctx, tracetest.SpanStubs{{Name: "operation"}}.Snapshots())
+	assert.Error(t, err)
+
+	assert.NoError(t, exporter.Shutdown(ctx))
+	assert.NoError(t, exporter.ExportSpans(ctx, tracetest.SpanStubs{{Name: "operation"}}.Snapshots()))
+}
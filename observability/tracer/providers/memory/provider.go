@@ -0,0 +1,226 @@
+// Package memory fornece um tracer provider que mantém os spans em memória,
+// com helpers de consulta para asserções em testes unitários
+package memory
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.28.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/fsvxavier/nexs-lib/observability/tracer/events"
+	"github.com/fsvxavier/nexs-lib/observability/tracer/interfaces"
+	"github.com/fsvxavier/nexs-lib/observability/tracer/spanmetrics"
+)
+
+// Span é o snapshot imutável de um span finalizado
+type Span = tracetest.SpanStub
+
+// Exporter armazena os spans exportados em memória
+type Exporter struct {
+	mu       sync.RWMutex
+	spans    []Span
+	stopped  bool
+	maxSpans int
+}
+
+// NewExporter cria um novo exporter em memória. maxSpans <= 0 significa sem limite;
+// quando o limite é atingido os spans mais antigos são descartados
+func NewExporter(maxSpans int) *Exporter {
+	return &Exporter{maxSpans: maxSpans}
+}
+
+// ExportSpans implementa trace.SpanExporter
+func (e *Exporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.stopped {
+		return nil
+	}
+
+	for _, s := range spans {
+		e.spans = append(e.spans, tracetest.SpanStubFromReadOnlySpan(s))
+	}
+
+	if e.maxSpans > 0 && len(e.spans) > e.maxSpans {
+		e.spans = append([]Span(nil), e.spans[len(e.spans)-e.maxSpans:]...)
+	}
+
+	return nil
+}
+
+// Shutdown implementa trace.SpanExporter
+func (e *Exporter) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.stopped = true
+	return nil
+}
+
+// Spans retorna uma cópia de todos os spans exportados
+func (e *Exporter) Spans() []Span {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	result := make([]Span, len(e.spans))
+	copy(result, e.spans)
+	return result
+}
+
+// Len retorna o número de spans armazenados
+func (e *Exporter) Len() int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return len(e.spans)
+}
+
+// Reset remove todos os spans armazenados
+func (e *Exporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = nil
+}
+
+// Filter retorna os spans que satisfazem o predicado
+func (e *Exporter) Filter(predicate func(Span) bool) []Span {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	var result []Span
+	for _, s := range e.spans {
+		if predicate(s) {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// SpansByName retorna os spans com o nome informado
+func (e *Exporter) SpansByName(name string) []Span {
+	return e.Filter(func(s Span) bool {
+		return s.Name == name
+	})
+}
+
+// SpansByAttribute retorna os spans que possuem o atributo com o valor informado
+func (e *Exporter) SpansByAttribute(key string, value any) []Span {
+	return e.Filter(func(s Span) bool {
+		return hasAttribute(s.Attributes, key, value)
+	})
+}
+
+// SpansByTraceID retorna os spans pertencentes ao trace informado
+func (e *Exporter) SpansByTraceID(traceID oteltrace.TraceID) []Span {
+	return e.Filter(func(s Span) bool {
+		return s.SpanContext.TraceID() == traceID
+	})
+}
+
+// FindSpan retorna o primeiro span com o nome informado
+func (e *Exporter) FindSpan(name string) (Span, bool) {
+	spans := e.SpansByName(name)
+	if len(spans) == 0 {
+		return Span{}, false
+	}
+	return spans[0], true
+}
+
+// ChildrenOf retorna os spans filhos diretos do span informado
+func (e *Exporter) ChildrenOf(parent Span) []Span {
+	return e.Filter(func(s Span) bool {
+		return s.Parent.SpanID() == parent.SpanContext.SpanID() &&
+			s.Parent.TraceID() == parent.SpanContext.TraceID()
+	})
+}
+
+// hasAttribute verifica se o atributo existe com o valor informado
+func hasAttribute(attrs []attribute.KeyValue, key string, value any) bool {
+	for _, attr := range attrs {
+		if string(attr.Key) != key {
+			continue
+		}
+		if value == nil {
+			return true
+		}
+		return reflect.DeepEqual(attr.Value.AsInterface(), value) || attr.Value.Emit() == fmt.Sprint(value)
+	}
+	return false
+}
+
+// Provider implementa TracerProvider armazenando os spans em memória
+type Provider struct {
+	tracerProvider *trace.TracerProvider
+	exporter       *Exporter
+}
+
+// NewProvider cria uma nova instância do provider em memória
+func NewProvider() *Provider {
+	return &Provider{
+		exporter: NewExporter(0),
+	}
+}
+
+// Init inicializa o tracer provider em memória
+func (p *Provider) Init(ctx context.Context, config interfaces.Config) (oteltrace.TracerProvider, error) {
+	attrs := []attribute.KeyValue{
+		semconv.ServiceName(config.ServiceName),
+		semconv.ServiceVersion(config.Version),
+		semconv.DeploymentEnvironmentName(config.Environment),
+	}
+	for key, value := range config.Attributes {
+		attrs = append(attrs, attribute.String(key, value))
+	}
+
+	opts, err := spanmetrics.Options(config.SpanMetrics, trace.ParentBased(trace.TraceIDRatioBased(config.SamplingRatio)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create span metrics: %w", err)
+	}
+
+	// Exportação síncrona para que os spans fiquem visíveis logo após End()
+	p.tracerProvider = trace.NewTracerProvider(append(opts,
+		trace.WithSyncer(p.exporter),
+		trace.WithResource(resource.NewSchemaless(attrs...)),
+		trace.WithSpanLimits(events.SpanLimits(config.Events)),
+	)...)
+
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	// Aplicar os limites de eventos e definir como global
+	guarded := events.Guard(p.tracerProvider, config.Events)
+	otel.SetTracerProvider(guarded)
+
+	return guarded, nil
+}
+
+// Shutdown finaliza o tracer provider
+func (p *Provider) Shutdown(ctx context.Context) error {
+	if p.tracerProvider != nil {
+		if err := p.tracerProvider.Shutdown(ctx); err != nil {
+			return fmt.Errorf("failed to shutdown tracer provider: %w", err)
+		}
+	}
+	return nil
+}
+
+// Exporter retorna o exporter em memória para consultas
+func (p *Provider) Exporter() *Exporter {
+	return p.exporter
+}
+
+// TracerProvider retorna o tracer provider do SDK
+func (p *Provider) TracerProvider() *trace.TracerProvider {
+	return p.tracerProvider
+}
@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/fsvxavier/nexs-lib/observability/tracer/interfaces"
+)
+
+func newTestConfig() interfaces.Config {
+	return interfaces.Config{
+		ServiceName:   "test-service",
+		Environment:   "test",
+		ExporterType:  "memory",
+		SamplingRatio: 1.0,
+		Version:       "1.0.0",
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	provider := NewProvider()
+	assert.NotNil(t, provider)
+	assert.NotNil(t, provider.Exporter())
+}
+
+func TestProvider_RecordsSpans(t *testing.T) {
+	ctx := context.Background()
+	provider := NewProvider()
+
+	tp, err := provider.Init(ctx, newTestConfig())
+	require.NoError(t, err)
+	defer provider.Shutdown(ctx)
+
+	tracer := tp.Tracer("test")
+	parentCtx, parent := tracer.Start(ctx, "parent")
+	_, child := tracer.Start(parentCtx, "child")
+	child.SetAttributes(attribute.String("http.method", "GET"), attribute.Int("http.status_code", 200))
+	child.End()
+	parent.End()
+
+	exporter := provider.Exporter()
+	assert.Equal(t, 2, exporter.Len())
+
+	found, ok := exporter.FindSpan("parent")
+	require.True(t, ok)
+	assert.Len(t, exporter.ChildrenOf(found), 1)
+
+	assert.Len(t, exporter.SpansByName("child"), 1)
+	assert.Len(t, exporter.SpansByAttribute("http.method", "GET"), 1)
+	assert.Len(t, exporter.SpansByAttribute("http.status_code", 200), 1)
+	assert.Len(t, exporter.SpansByAttribute("http.method", nil), 1)
+	assert.Empty(t, exporter.SpansByAttribute("http.method", "POST"))
+	assert.Len(t, exporter.SpansByTraceID(found.SpanContext.TraceID()), 2)
+
+	_, ok = exporter.FindSpan("missing")
+	assert.False(t, ok)
+
+	exporter.Reset()
+	assert.Equal(t, 0, exporter.Len())
+}
+
+func TestExporter_SpansBySliceAttribute(t *testing.T) {
+	ctx := context.Background()
+	provider := NewProvider()
+
+	tp, err := provider.Init(ctx, newTestConfig())
+	require.NoError(t, err)
+	defer provider.Shutdown(ctx)
+
+	_, span := tp.Tracer("test").Start(ctx, "tagged")
+	span.SetAttributes(attribute.StringSlice("tags", []string{"a", "b"}))
+	span.End()
+
+	exporter := provider.Exporter()
+	assert.Len(t, exporter.SpansByAttribute("tags", []string{"a", "b"}), 1)
+	assert.Empty(t, exporter.SpansByAttribute("tags", []string{"a"}))
+}
+
+func TestExporter_MaxSpans(t *testing.T) {
+	ctx := context.Background()
+	provider := &Provider{exporter: NewExporter(2)}
+
+	tp, err := provider.Init(ctx, newTestConfig())
+	require.NoError(t, err)
+	defer provider.Shutdown(ctx)
+
+	for _, name := range []string{"a", "b", "c"} {
+		_, span := tp.Tracer("test").Start(ctx, name)
+		span.End()
+	}
+
+	spans := provider.Exporter().Spans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "b", spans[0].Name)
+	assert.Equal(t, "c", spans[1].Name)
+}
+
+func TestProvider_ShutdownWithoutInit(t *testing.T) {
+	provider := NewProvider()
+	assert.NoError(t, provider.Shutdown(context.Background()))
+}
@@ -16,7 +16,9 @@ import (
 
 	"github.com/newrelic/go-agent/v3/newrelic"
 
+	"github.com/fsvxavier/nexs-lib/observability/tracer/events"
 	"github.com/fsvxavier/nexs-lib/observability/tracer/interfaces"
+	"github.com/fsvxavier/nexs-lib/observability/tracer/spanmetrics"
 )
 
 // Provider implementa TracerProvider para New Relic
@@ -62,12 +64,17 @@ func (p *Provider) Init(ctx context.Context, config interfaces.Config) (oteltrac
 	// Em uma implementação real, você pode criar um bridge específico
 	exporter := &noOpExporter{}
 
+	opts, err := spanmetrics.Options(config.SpanMetrics, sampler)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create span metrics: %w", err)
+	}
+
 	// Criar tracer provider
-	tracerProvider := trace.NewTracerProvider(
+	tracerProvider := trace.NewTracerProvider(append(opts,
 		trace.WithBatcher(exporter),
 		trace.WithResource(res),
-		trace.WithSampler(sampler),
-	)
+		trace.WithSpanLimits(events.SpanLimits(config.Events)),
+	)...)
 
 	p.tracerProvider = tracerProvider
 
@@ -76,10 +83,11 @@ func (p *Provider) Init(ctx context.Context, config interfaces.Config) (oteltrac
 		return nil, fmt.Errorf("failed to configure propagators: %w", err)
 	}
 
-	// Definir como global
-	otel.SetTracerProvider(tracerProvider)
+	// Aplicar os limites de eventos e definir como global
+	guarded := events.Guard(tracerProvider, config.Events)
+	otel.SetTracerProvider(guarded)
 
-	return tracerProvider, nil
+	return guarded, nil
 }
 
 // Shutdown finaliza o tracer provider New Relic
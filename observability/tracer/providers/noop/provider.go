@@ -0,0 +1,121 @@
+// Package noop fornece um tracer provider que não registra spans. Os spans
+// criados mantêm o span context recebido, de modo que o trace continua sendo
+// propagado para os serviços seguintes, e todas as operações têm custo
+// próximo de zero
+package noop
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+
+	"github.com/fsvxavier/nexs-lib/observability/tracer/interfaces"
+)
+
+// Provider implementa TracerProvider sem exportar spans
+type Provider struct{}
+
+// NewProvider cria uma nova instância do provider noop
+func NewProvider() *Provider {
+	return &Provider{}
+}
+
+// Init define o NoopTracerProvider como global. O propagador continua
+// configurado para que o contexto de trace recebido chegue aos serviços
+// chamados
+func (p *Provider) Init(ctx context.Context, config interfaces.Config) (oteltrace.TracerProvider, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	tp := NewTracerProvider()
+	otel.SetTracerProvider(tp)
+
+	return tp, nil
+}
+
+// Shutdown não tem recursos a liberar
+func (p *Provider) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// NoopTracerProvider cria tracers que não registram spans
+type NoopTracerProvider struct {
+	embedded.TracerProvider
+}
+
+// NewTracerProvider cria um NoopTracerProvider
+func NewTracerProvider() NoopTracerProvider {
+	return NoopTracerProvider{}
+}
+
+// Tracer retorna um NoopTracer
+func (NoopTracerProvider) Tracer(string, ...oteltrace.TracerOption) oteltrace.Tracer {
+	return NoopTracer{}
+}
+
+// NoopTracer cria spans que não registram nada
+type NoopTracer struct {
+	embedded.Tracer
+}
+
+// invalidSpan span sem contexto, pré-alocado para evitar alocações em Start
+var invalidSpan oteltrace.Span = NoopSpan{}
+
+// Start retorna um NoopSpan com o span context do pai. Sem pai válido, ou
+// quando o pai já é um NoopSpan, o contexto é retornado sem alocações
+func (NoopTracer) Start(ctx context.Context, _ string, _ ...oteltrace.SpanStartOption) (context.Context, oteltrace.Span) {
+	parent := oteltrace.SpanFromContext(ctx)
+	if span, ok := parent.(NoopSpan); ok {
+		return ctx, span
+	}
+
+	sc := parent.SpanContext()
+	if !sc.IsValid() {
+		return ctx, invalidSpan
+	}
+	span := NoopSpan{sc: sc}
+	return oteltrace.ContextWithSpan(ctx, span), span
+}
+
+// NoopSpan span que descarta todas as operações. IsRecording é sempre false
+type NoopSpan struct {
+	embedded.Span
+	sc oteltrace.SpanContext
+}
+
+// SpanContext retorna o span context herdado do pai
+func (s NoopSpan) SpanContext() oteltrace.SpanContext { return s.sc }
+
+// IsRecording retorna sempre false
+func (NoopSpan) IsRecording() bool { return false }
+
+// SetStatus não faz nada
+func (NoopSpan) SetStatus(codes.Code, string) {}
+
+// SetAttributes não faz nada
+func (NoopSpan) SetAttributes(...attribute.KeyValue) {}
+
+// SetName não faz nada
+func (NoopSpan) SetName(string) {}
+
+// AddEvent não faz nada
+func (NoopSpan) AddEvent(string, ...oteltrace.EventOption) {}
+
+// AddLink não faz nada
+func (NoopSpan) AddLink(oteltrace.Link) {}
+
+// RecordError não faz nada
+func (NoopSpan) RecordError(error, ...oteltrace.EventOption) {}
+
+// End não faz nada
+func (NoopSpan) End(...oteltrace.SpanEndOption) {}
+
+// TracerProvider retorna um NoopTracerProvider
+func (NoopSpan) TracerProvider() oteltrace.TracerProvider { return NoopTracerProvider{} }
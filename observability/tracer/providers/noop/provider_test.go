@@ -0,0 +1,138 @@
+package noop
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/fsvxavier/nexs-lib/observability/tracer/interfaces"
+)
+
+func TestProvider_Init(t *testing.T) {
+	provider := NewProvider()
+	tp, err := provider.Init(context.Background(), interfaces.Config{ServiceName: "noop-test"})
+	if err != nil {
+		t.Fatalf("Init returned error: %v", err)
+	}
+	defer provider.Shutdown(context.Background())
+
+	if _, ok := tp.(NoopTracerProvider); !ok {
+		t.Fatalf("expected NoopTracerProvider, got %T", tp)
+	}
+	if _, ok := otel.GetTracerProvider().Tracer("x").(NoopTracer); !ok {
+		t.Error("expected the global provider to create noop tracers")
+	}
+}
+
+func TestNoopTracer_PropagatesParentContext(t *testing.T) {
+	parent := oteltrace.NewSpanContext(oteltrace.SpanContextConfig{
+		TraceID:    oteltrace.TraceID{1},
+		SpanID:     oteltrace.SpanID{2},
+		TraceFlags: oteltrace.FlagsSampled,
+		Remote:     true,
+	})
+	ctx := oteltrace.ContextWithRemoteSpanContext(context.Background(), parent)
+
+	tracer := NewTracerProvider().Tracer("test")
+	ctx, span := tracer.Start(ctx, "operation")
+	defer span.End()
+
+	if span.IsRecording() {
+		t.Error("noop span must not record")
+	}
+	if !span.SpanContext().Equal(parent) {
+		t.Errorf("expected the parent span context, got %v", span.SpanContext())
+	}
+
+	// O contexto segue para os serviços chamados
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	if carrier.Get("traceparent") == "" {
+		t.Error("expected traceparent to be propagated")
+	}
+
+	// Filhos reutilizam o span do pai
+	childCtx, child := tracer.Start(ctx, "child")
+	if childCtx != ctx || !child.SpanContext().Equal(parent) {
+		t.Error("expected the child to reuse the parent noop span")
+	}
+}
+
+func TestNoopTracer_WithoutParent(t *testing.T) {
+	ctx := context.Background()
+	newCtx, span := NewTracerProvider().Tracer("test").Start(ctx, "operation")
+
+	if newCtx != ctx {
+		t.Error("expected the context to be returned unchanged")
+	}
+	if span.SpanContext().IsValid() {
+		t.Error("expected an invalid span context without parent")
+	}
+	if _, ok := span.TracerProvider().(NoopTracerProvider); !ok {
+		t.Error("expected NoopTracerProvider from the span")
+	}
+}
+
+func TestNoopSpan_ZeroAllocations(t *testing.T) {
+	tracer := NewTracerProvider().Tracer("test")
+	ctx, span := tracer.Start(context.Background(), "operation")
+	err := errors.New("boom")
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_, child := tracer.Start(ctx, "child")
+		if child.IsRecording() {
+			child.SetAttributes(attribute.String("key", "value"))
+		}
+		span.AddEvent("event")
+		span.RecordError(err)
+		child.End()
+	})
+	if allocs != 0 {
+		t.Errorf("expected no allocations, got %.0f", allocs)
+	}
+}
+
+func BenchmarkNoopTracer_Start(b *testing.B) {
+	tracer := NewTracerProvider().Tracer("bench")
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		_, span := tracer.Start(ctx, "operation")
+		span.End()
+	}
+}
+
+func BenchmarkNoopSpan_SetAttributes(b *testing.B) {
+	_, span := NewTracerProvider().Tracer("bench").Start(context.Background(), "operation")
+
+	b.ReportAllocs()
+	for b.Loop() {
+		span.SetAttributes(attribute.String("key", "value"), attribute.Int("count", 1))
+	}
+}
+
+func BenchmarkNoopSpan_SetAttributesIsRecording(b *testing.B) {
+	_, span := NewTracerProvider().Tracer("bench").Start(context.Background(), "operation")
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if span.IsRecording() {
+			span.SetAttributes(attribute.String("key", "value"), attribute.Int("count", 1))
+		}
+	}
+}
+
+func BenchmarkNoopSpan_AddEvent(b *testing.B) {
+	_, span := NewTracerProvider().Tracer("bench").Start(context.Background(), "operation")
+
+	b.ReportAllocs()
+	for b.Loop() {
+		span.AddEvent("event")
+	}
+}
@@ -19,7 +19,9 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 
+	"github.com/fsvxavier/nexs-lib/observability/tracer/events"
 	"github.com/fsvxavier/nexs-lib/observability/tracer/interfaces"
+	"github.com/fsvxavier/nexs-lib/observability/tracer/spanmetrics"
 )
 
 // Provider implementa TracerProvider para OpenTelemetry OTLP
@@ -48,15 +50,19 @@ func (p *Provider) Init(ctx context.Context, config interfaces.Config) (oteltrac
 	}
 	p.exporter = exporter
 
-	// Configurar sampler
+	// Configurar sampler e as métricas derivadas dos spans
 	sampler := trace.TraceIDRatioBased(config.SamplingRatio)
+	opts, err := spanmetrics.Options(config.SpanMetrics, sampler)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create span metrics: %w", err)
+	}
 
 	// Criar tracer provider
-	tracerProvider := trace.NewTracerProvider(
+	tracerProvider := trace.NewTracerProvider(append(opts,
 		trace.WithBatcher(exporter),
 		trace.WithResource(res),
-		trace.WithSampler(sampler),
-	)
+		trace.WithSpanLimits(events.SpanLimits(config.Events)),
+	)...)
 
 	p.tracerProvider = tracerProvider
 
@@ -65,10 +71,11 @@ func (p *Provider) Init(ctx context.Context, config interfaces.Config) (oteltrac
 		return nil, fmt.Errorf("failed to configure propagators: %w", err)
 	}
 
-	// Definir como global
-	otel.SetTracerProvider(tracerProvider)
+	// Aplicar os limites de eventos e definir como global
+	guarded := events.Guard(tracerProvider, config.Events)
+	otel.SetTracerProvider(guarded)
 
-	return tracerProvider, nil
+	return guarded, nil
 }
 
 // Shutdown finaliza o tracer provider
@@ -142,10 +149,16 @@ func (p *Provider) createGRPCExporter(ctx context.Context, config interfaces.Con
 // createHTTPExporter cria um exporter HTTP
 func (p *Provider) createHTTPExporter(ctx context.Context, config interfaces.Config) (trace.SpanExporter, error) {
 	opts := []otlptracehttp.Option{
-		otlptracehttp.WithEndpoint(config.Endpoint),
 		otlptracehttp.WithTimeout(30 * time.Second),
 	}
 
+	// Endpoints com esquema são URLs completas (ex.: http://localhost:4318/v1/traces)
+	if hasHTTPScheme(config.Endpoint) {
+		opts = append(opts, otlptracehttp.WithEndpointURL(config.Endpoint))
+	} else {
+		opts = append(opts, otlptracehttp.WithEndpoint(config.Endpoint))
+	}
+
 	// Configurar headers
 	if len(config.Headers) > 0 {
 		opts = append(opts, otlptracehttp.WithHeaders(config.Headers))
@@ -3,9 +3,18 @@ package opentelemetry
 import (
 	"context"
 	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/fsvxavier/nexs-lib/observability/tracer/interfaces"
 	"github.com/fsvxavier/nexs-lib/observability/tracer/mocks"
@@ -155,3 +164,55 @@ func TestProvider_MultipleInit(t *testing.T) {
 	err = mockProvider.Shutdown(ctx)
 	assert.NoError(t, err)
 }
+
+func TestProvider_ExportsSpanLinks(t *testing.T) {
+	var mu sync.Mutex
+	var requests []*collectortrace.ExportTraceServiceRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		request := &collectortrace.ExportTraceServiceRequest{}
+		require.NoError(t, proto.Unmarshal(body, request))
+
+		mu.Lock()
+		requests = append(requests, request)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/x-protobuf")
+	}))
+	defer server.Close()
+
+	provider := NewProvider()
+	tp, err := provider.Init(context.Background(), interfaces.Config{
+		ServiceName:   "consumer",
+		Endpoint:      server.URL + "/v1/traces",
+		SamplingRatio: 1.0,
+	})
+	require.NoError(t, err)
+
+	producer := oteltrace.NewSpanContext(oteltrace.SpanContextConfig{
+		TraceID:    oteltrace.TraceID{0xaa},
+		SpanID:     oteltrace.SpanID{0xbb},
+		TraceFlags: oteltrace.FlagsSampled,
+		Remote:     true,
+	})
+	_, span := tp.Tracer("test").Start(context.Background(), "process-batch",
+		oteltrace.WithLinks(oteltrace.Link{
+			SpanContext: producer,
+			Attributes:  []attribute.KeyValue{attribute.String("messaging.system", "kafka")},
+		}))
+	span.End()
+	require.NoError(t, provider.Shutdown(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, requests, 1)
+	spans := requests[0].ResourceSpans[0].ScopeSpans[0].Spans
+	require.Len(t, spans, 1)
+	require.Len(t, spans[0].Links, 1)
+
+	link := spans[0].Links[0]
+	assert.Equal(t, producer.TraceID().String(), oteltrace.TraceID(link.TraceId).String())
+	assert.Equal(t, producer.SpanID().String(), oteltrace.SpanID(link.SpanId).String())
+	assert.Equal(t, "messaging.system", link.Attributes[0].Key)
+	assert.Equal(t, "kafka", link.Attributes[0].Value.GetStringValue())
+}
@@ -0,0 +1,196 @@
+// Package xray fornece implementação de tracer provider para AWS X-Ray. Os
+// spans são enviados via OTLP para o AWS Distro for OpenTelemetry (ADOT),
+// que os converte em segments e subsegments, com trace IDs no formato do
+// X-Ray e propagação pelo header X-Amzn-Trace-Id
+package xray
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/contrib/propagators/aws/xray"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.28.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/fsvxavier/nexs-lib/observability/tracer/events"
+	"github.com/fsvxavier/nexs-lib/observability/tracer/interfaces"
+	"github.com/fsvxavier/nexs-lib/observability/tracer/spanmetrics"
+)
+
+// DefaultEndpoint endpoint OTLP gRPC do collector ADOT (sidecar no ECS ou
+// extensão na Lambda)
+const DefaultEndpoint = "localhost:4317"
+
+// TraceHeader header HTTP de propagação do X-Ray
+const TraceHeader = "X-Amzn-Trace-Id"
+
+// lambdaTraceEnv variável em que a Lambda expõe o header da invocação atual
+const lambdaTraceEnv = "_X_AMZN_TRACE_ID"
+
+// Provider implementa TracerProvider para AWS X-Ray
+type Provider struct {
+	tracerProvider *trace.TracerProvider
+	exporter       trace.SpanExporter
+}
+
+// NewProvider cria uma nova instância do provider X-Ray
+func NewProvider() *Provider {
+	return &Provider{}
+}
+
+// Init inicializa o tracer provider X-Ray. Sem endpoint, os spans são enviados
+// para o collector ADOT em localhost:4317
+func (p *Provider) Init(ctx context.Context, config interfaces.Config) (oteltrace.TracerProvider, error) {
+	res, err := p.createResource(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create resource: %w", err)
+	}
+
+	exporter, err := p.createExporter(ctx, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create exporter: %w", err)
+	}
+	p.exporter = exporter
+
+	opts, err := spanmetrics.Options(config.SpanMetrics, trace.ParentBased(trace.TraceIDRatioBased(config.SamplingRatio)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create span metrics: %w", err)
+	}
+
+	// O X-Ray exige trace IDs iniciados pelo timestamp em segundos
+	p.tracerProvider = trace.NewTracerProvider(append(opts,
+		trace.WithBatcher(exporter),
+		trace.WithResource(res),
+		trace.WithIDGenerator(xray.NewIDGenerator()),
+		trace.WithSpanLimits(events.SpanLimits(config.Events)),
+	)...)
+
+	// X-Amzn-Trace-Id tem prioridade; W3C mantém a interoperabilidade com
+	// serviços fora da AWS
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+		xray.Propagator{},
+	))
+
+	// Aplicar os limites de eventos e definir como global
+	guarded := events.Guard(p.tracerProvider, config.Events)
+	otel.SetTracerProvider(guarded)
+
+	return guarded, nil
+}
+
+// Shutdown finaliza o tracer provider
+func (p *Provider) Shutdown(ctx context.Context) error {
+	if p.tracerProvider != nil {
+		if err := p.tracerProvider.Shutdown(ctx); err != nil {
+			return fmt.Errorf("failed to shutdown tracer provider: %w", err)
+		}
+	}
+	return nil
+}
+
+// createResource cria o resource com os metadados do serviço e do ambiente AWS
+func (p *Provider) createResource(config interfaces.Config) (*resource.Resource, error) {
+	attrs := []attribute.KeyValue{
+		semconv.ServiceName(config.ServiceName),
+		semconv.ServiceVersion(config.Version),
+		semconv.DeploymentEnvironmentName(config.Environment),
+	}
+	attrs = append(attrs, awsAttributes()...)
+
+	for key, value := range config.Attributes {
+		attrs = append(attrs, attribute.String(key, value))
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes("", attrs...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create resource: %w", err)
+	}
+	return res, nil
+}
+
+// awsAttributes detecta Lambda e ECS pelas variáveis de ambiente do runtime
+func awsAttributes() []attribute.KeyValue {
+	attrs := []attribute.KeyValue{semconv.CloudProviderAWS}
+	if region := os.Getenv("AWS_REGION"); region != "" {
+		attrs = append(attrs, semconv.CloudRegion(region))
+	}
+
+	switch {
+	case os.Getenv("AWS_LAMBDA_FUNCTION_NAME") != "":
+		attrs = append(attrs,
+			semconv.CloudPlatformAWSLambda,
+			semconv.FaaSName(os.Getenv("AWS_LAMBDA_FUNCTION_NAME")),
+		)
+		if version := os.Getenv("AWS_LAMBDA_FUNCTION_VERSION"); version != "" {
+			attrs = append(attrs, semconv.FaaSVersion(version))
+		}
+	case os.Getenv("ECS_CONTAINER_METADATA_URI_V4") != "" || os.Getenv("ECS_CONTAINER_METADATA_URI") != "":
+		attrs = append(attrs, semconv.CloudPlatformAWSECS)
+	}
+	return attrs
+}
+
+// createExporter cria o exporter OTLP para o collector ADOT. Endpoints com
+// esquema http(s) usam OTLP/HTTP e os demais, gRPC
+func (p *Provider) createExporter(ctx context.Context, config interfaces.Config) (trace.SpanExporter, error) {
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+
+	if strings.HasPrefix(endpoint, "http://") || strings.HasPrefix(endpoint, "https://") {
+		opts := []otlptracehttp.Option{
+			otlptracehttp.WithEndpointURL(endpoint),
+			otlptracehttp.WithTimeout(30 * time.Second),
+		}
+		if len(config.Headers) > 0 {
+			opts = append(opts, otlptracehttp.WithHeaders(config.Headers))
+		}
+		return otlptracehttp.New(ctx, opts...)
+	}
+
+	// O collector ADOT roda localmente, sem TLS
+	opts := []otlptracegrpc.Option{
+		otlptracegrpc.WithEndpoint(endpoint),
+		otlptracegrpc.WithTimeout(30 * time.Second),
+	}
+	if config.Insecure || config.Endpoint == "" {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	if len(config.Headers) > 0 {
+		opts = append(opts, otlptracegrpc.WithHeaders(config.Headers))
+	}
+	return otlptracegrpc.New(ctx, opts...)
+}
+
+// ContextFromLambda retorna o contexto com o span context da invocação atual
+// da Lambda, lido de _X_AMZN_TRACE_ID, para que os spans do handler sejam
+// filhos do segment criado pela Lambda. Sem a variável, ctx é retornado
+func ContextFromLambda(ctx context.Context) context.Context {
+	header := os.Getenv(lambdaTraceEnv)
+	if header == "" {
+		return ctx
+	}
+	return xray.Propagator{}.Extract(ctx, propagation.MapCarrier{TraceHeader: header})
+}
+
+// HeaderValue formata o span context do contexto no formato do header
+// X-Amzn-Trace-Id (Root=...;Parent=...;Sampled=...), útil em logs e em
+// chamadas que não passam pelo propagador. Retorna vazio sem span válido
+func HeaderValue(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	xray.Propagator{}.Inject(ctx, carrier)
+	return carrier.Get(TraceHeader)
+}
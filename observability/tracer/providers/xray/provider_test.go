@@ -0,0 +1,147 @@
+package xray
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/fsvxavier/nexs-lib/observability/tracer/interfaces"
+)
+
+func TestNewProvider(t *testing.T) {
+	provider := NewProvider()
+	assert.NotNil(t, provider)
+	assert.NoError(t, provider.Shutdown(context.Background()))
+}
+
+func TestProvider_ExportsXRayTraceIDs(t *testing.T) {
+	t.Setenv("AWS_REGION", "us-east-1")
+	t.Setenv("AWS_LAMBDA_FUNCTION_NAME", "orders-handler")
+
+	var mu sync.Mutex
+	var requests []*collectortrace.ExportTraceServiceRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		request := &collectortrace.ExportTraceServiceRequest{}
+		require.NoError(t, proto.Unmarshal(body, request))
+
+		mu.Lock()
+		requests = append(requests, request)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/x-protobuf")
+	}))
+	defer server.Close()
+
+	provider := NewProvider()
+	tp, err := provider.Init(context.Background(), interfaces.Config{
+		ServiceName:   "orders",
+		Endpoint:      server.URL + "/v1/traces",
+		SamplingRatio: 1.0,
+	})
+	require.NoError(t, err)
+
+	before := time.Now().Unix()
+	_, span := tp.Tracer("test").Start(context.Background(), "handle")
+	span.End()
+	require.NoError(t, provider.Shutdown(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, requests, 1)
+	resourceSpans := requests[0].ResourceSpans[0]
+
+	resourceAttrs := map[string]string{}
+	for _, kv := range resourceSpans.Resource.Attributes {
+		resourceAttrs[kv.Key] = kv.Value.GetStringValue()
+	}
+	assert.Equal(t, "aws", resourceAttrs["cloud.provider"])
+	assert.Equal(t, "aws_lambda", resourceAttrs["cloud.platform"])
+	assert.Equal(t, "us-east-1", resourceAttrs["cloud.region"])
+	assert.Equal(t, "orders-handler", resourceAttrs["faas.name"])
+
+	// Os 4 primeiros bytes do trace ID são o timestamp exigido pelo X-Ray
+	spans := resourceSpans.ScopeSpans[0].Spans
+	require.Len(t, spans, 1)
+	epoch := int64(binary.BigEndian.Uint32(spans[0].TraceId[:4]))
+	assert.InDelta(t, before, epoch, 5)
+}
+
+func TestProvider_Propagation(t *testing.T) {
+	provider := NewProvider()
+	tp, err := provider.Init(context.Background(), interfaces.Config{
+		ServiceName:   "orders",
+		Endpoint:      "http://127.0.0.1:0/v1/traces",
+		SamplingRatio: 1.0,
+	})
+	require.NoError(t, err)
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = provider.Shutdown(ctx)
+	}()
+
+	ctx, span := tp.Tracer("test").Start(context.Background(), "handle")
+	defer span.End()
+
+	header := HeaderValue(ctx)
+	sc := span.SpanContext()
+	traceID := sc.TraceID().String()
+	expected := "Root=1-" + traceID[:8] + "-" + traceID[8:] + ";Parent=" + sc.SpanID().String() + ";Sampled=1"
+	assert.Equal(t, expected, header)
+
+	// O propagador global injeta X-Amzn-Trace-Id e traceparent
+	carrier := propagation.HeaderCarrier(http.Header{})
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	assert.Equal(t, header, carrier.Get(TraceHeader))
+	assert.NotEmpty(t, carrier.Get("traceparent"))
+
+	// Requisições vindas do ALB ou do API Gateway trazem só o header do X-Ray
+	incoming := propagation.HeaderCarrier(http.Header{})
+	incoming.Set(TraceHeader, "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1")
+	remote := oteltrace.SpanContextFromContext(otel.GetTextMapPropagator().Extract(context.Background(), incoming))
+	assert.Equal(t, "5759e988bd862e3fe1be46a994272793", remote.TraceID().String())
+	assert.Equal(t, "53995c3f42cd8ad8", remote.SpanID().String())
+	assert.True(t, remote.IsSampled())
+	assert.True(t, remote.IsRemote())
+}
+
+func TestContextFromLambda(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv(lambdaTraceEnv, "")
+	assert.Equal(t, ctx, ContextFromLambda(ctx))
+
+	t.Setenv(lambdaTraceEnv, "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=0;Lineage=a87bd80c:0")
+	sc := oteltrace.SpanContextFromContext(ContextFromLambda(ctx))
+	require.True(t, sc.IsValid())
+	assert.Equal(t, "5759e988bd862e3fe1be46a994272793", sc.TraceID().String())
+	assert.False(t, sc.IsSampled())
+
+	assert.Empty(t, HeaderValue(context.Background()))
+}
+
+func TestAWSAttributes_ECS(t *testing.T) {
+	t.Setenv("AWS_LAMBDA_FUNCTION_NAME", "")
+	t.Setenv("ECS_CONTAINER_METADATA_URI_V4", "http://169.254.170.2/v4/abc")
+
+	var platform string
+	for _, kv := range awsAttributes() {
+		if kv.Key == "cloud.platform" {
+			platform = kv.Value.AsString()
+		}
+	}
+	assert.Equal(t, "aws_ecs", platform)
+}
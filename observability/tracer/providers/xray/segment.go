@@ -0,0 +1,85 @@
+package xray
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.28.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// SegmentType tipo do documento X-Ray gerado a partir de um span
+type SegmentType string
+
+const (
+	// Segment representa o trabalho de um serviço: spans raiz e spans server
+	// ou consumer
+	Segment SegmentType = "segment"
+	// Subsegment representa uma etapa dentro de um segment: chamadas a
+	// dependências e spans internos
+	Subsegment SegmentType = "subsegment"
+)
+
+// Namespaces de subsegments reconhecidos pelo X-Ray
+const (
+	// NamespaceAWS chamadas a serviços da AWS feitas pelo AWS SDK
+	NamespaceAWS = "aws"
+	// NamespaceRemote chamadas a outras dependências (HTTP, bancos, filas)
+	NamespaceRemote = "remote"
+)
+
+// AnnotationsKey atributo com as chaves que o collector ADOT envia ao X-Ray
+// como annotations (indexadas e filtráveis); os demais atributos viram
+// metadata
+const AnnotationsKey = attribute.Key("aws.xray.annotations")
+
+// Classify retorna o tipo de documento X-Ray do span, seguindo as regras do
+// exporter awsxray do collector ADOT. Na Lambda, os spans do handler têm como
+// pai o segment da invocação e por isso viram subsegments
+func Classify(span trace.ReadOnlySpan) SegmentType {
+	kind := span.SpanKind()
+	if !span.Parent().IsValid() || kind == oteltrace.SpanKindServer || kind == oteltrace.SpanKindConsumer {
+		return Segment
+	}
+	return Subsegment
+}
+
+// Namespace retorna o namespace do subsegment: aws para chamadas do AWS SDK,
+// remote para spans client e producer e vazio para os demais
+func Namespace(span trace.ReadOnlySpan) string {
+	for _, kv := range span.Attributes() {
+		if kv.Key == semconv.RPCSystemKey && kv.Value.AsString() == "aws-api" {
+			return NamespaceAWS
+		}
+	}
+	switch span.SpanKind() {
+	case oteltrace.SpanKindClient, oteltrace.SpanKindProducer:
+		return NamespaceRemote
+	default:
+		return ""
+	}
+}
+
+// Annotations retorna os atributos acrescidos de aws.xray.annotations com as
+// chaves que devem ser indexadas. Só strings, números e booleanos podem ser
+// annotations; atributos de outros tipos continuam como metadata
+//
+//	span.SetAttributes(xray.Annotations(
+//	    attribute.String("tenant_id", tenant),
+//	    attribute.Int("order_items", len(items)),
+//	)...)
+func Annotations(attrs ...attribute.KeyValue) []attribute.KeyValue {
+	keys := make([]string, 0, len(attrs))
+	for _, kv := range attrs {
+		switch kv.Value.Type() {
+		case attribute.STRING, attribute.INT64, attribute.FLOAT64, attribute.BOOL:
+			keys = append(keys, string(kv.Key))
+		}
+	}
+
+	result := make([]attribute.KeyValue, 0, len(attrs)+1)
+	result = append(result, attrs...)
+	if len(keys) > 0 {
+		result = append(result, AnnotationsKey.StringSlice(keys))
+	}
+	return result
+}
@@ -0,0 +1,67 @@
+package xray
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestClassify(t *testing.T) {
+	parent := oteltrace.NewSpanContext(oteltrace.SpanContextConfig{
+		TraceID: oteltrace.TraceID{1},
+		SpanID:  oteltrace.SpanID{2},
+	})
+
+	tests := []struct {
+		name   string
+		kind   oteltrace.SpanKind
+		parent oteltrace.SpanContext
+		want   SegmentType
+	}{
+		{"root internal", oteltrace.SpanKindInternal, oteltrace.SpanContext{}, Segment},
+		{"server with parent", oteltrace.SpanKindServer, parent, Segment},
+		{"consumer with parent", oteltrace.SpanKindConsumer, parent, Segment},
+		{"client", oteltrace.SpanKindClient, parent, Subsegment},
+		{"internal", oteltrace.SpanKindInternal, parent, Subsegment},
+		{"lambda handler", oteltrace.SpanKindInternal, parent.WithRemote(true), Subsegment},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			span := tracetest.SpanStub{SpanKind: tt.kind, Parent: tt.parent}.Snapshot()
+			assert.Equal(t, tt.want, Classify(span))
+		})
+	}
+}
+
+func TestNamespace(t *testing.T) {
+	aws := tracetest.SpanStub{
+		SpanKind:   oteltrace.SpanKindClient,
+		Attributes: []attribute.KeyValue{attribute.String("rpc.system", "aws-api")},
+	}.Snapshot()
+	assert.Equal(t, NamespaceAWS, Namespace(aws))
+
+	producer := tracetest.SpanStub{SpanKind: oteltrace.SpanKindProducer}.Snapshot()
+	assert.Equal(t, NamespaceRemote, Namespace(producer))
+
+	internal := tracetest.SpanStub{SpanKind: oteltrace.SpanKindInternal}.Snapshot()
+	assert.Empty(t, Namespace(internal))
+}
+
+func TestAnnotations(t *testing.T) {
+	attrs := Annotations(
+		attribute.String("tenant_id", "acme"),
+		attribute.Int("order_items", 3),
+		attribute.StringSlice("skus", []string{"a", "b"}),
+	)
+
+	assert.Len(t, attrs, 4)
+	last := attrs[len(attrs)-1]
+	assert.Equal(t, AnnotationsKey, last.Key)
+	assert.Equal(t, []string{"tenant_id", "order_items"}, last.Value.AsStringSlice())
+
+	assert.Empty(t, Annotations())
+}
@@ -0,0 +1,286 @@
+// Package spanmetrics deriva dos spans as métricas RED — taxa de chamadas,
+// erros e duração — por serviço, nome e tipo de span, publicadas na API de
+// métricas do OpenTelemetry. Com o Processor registrado no TracerProvider,
+// cada operação instrumentada com tracing ganha as métricas dos dashboards
+// RED sem instrumentação manual. Os nomes e atributos seguem o conector
+// spanmetrics do OpenTelemetry Collector, para reaproveitar os seus
+// dashboards
+package spanmetrics
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.28.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/fsvxavier/nexs-lib/observability/metrics"
+	"github.com/fsvxavier/nexs-lib/observability/tracer/interfaces"
+)
+
+// meterName identifica os instrumentos criados pelo Processor
+const meterName = "github.com/fsvxavier/nexs-lib/observability/tracer/spanmetrics"
+
+// Nomes das métricas
+const (
+	// MetricCalls conta os spans finalizados; os com status.code
+	// STATUS_CODE_ERROR são os erros
+	MetricCalls = "traces.span.metrics.calls"
+	// MetricDuration é o histograma da duração dos spans, em segundos
+	MetricDuration = "traces.span.metrics.duration"
+)
+
+// Atributos das métricas
+const (
+	AttrServiceName = "service.name"
+	AttrSpanName    = "span.name"
+	AttrSpanKind    = "span.kind"
+	AttrStatusCode  = "status.code"
+	// AttrOverflow marca a série que agrega as combinações de atributos
+	// acima de MaxSeries
+	AttrOverflow = "otel.metric.overflow"
+)
+
+// DefaultMaxSeries número padrão de combinações de atributos
+const DefaultMaxSeries = 1000
+
+// Option configura o Processor
+type Option func(*Processor)
+
+// WithBuckets substitui os limites, em segundos, do histograma de duração
+func WithBuckets(buckets ...float64) Option {
+	return func(p *Processor) {
+		p.buckets = buckets
+	}
+}
+
+// WithDimensions adiciona às métricas os atributos dos spans informados,
+// como http.route ou rpc.method. Devem ter baixa cardinalidade: use a rota,
+// não a URL
+func WithDimensions(keys ...string) Option {
+	return func(p *Processor) {
+		for _, key := range keys {
+			p.dimensions = append(p.dimensions, attribute.Key(key))
+		}
+	}
+}
+
+// WithMaxSeries limita o número de combinações de atributos. As excedentes,
+// tipicamente nomes de spans com IDs, são agregadas em uma série por serviço
+// com o atributo otel.metric.overflow
+func WithMaxSeries(n int) Option {
+	return func(p *Processor) {
+		p.maxSeries = n
+	}
+}
+
+// WithSpanKinds restringe as métricas aos tipos de span informados, como
+// Server e Consumer para medir apenas as entradas do serviço
+func WithSpanKinds(kinds ...oteltrace.SpanKind) Option {
+	return func(p *Processor) {
+		p.kinds = make(map[oteltrace.SpanKind]bool, len(kinds))
+		for _, kind := range kinds {
+			p.kinds[kind] = true
+		}
+	}
+}
+
+// FromConfig converte a configuração do tracer em opções
+func FromConfig(cfg interfaces.SpanMetricsConfig) []Option {
+	opts := []Option{WithDimensions(cfg.Dimensions...)}
+	if len(cfg.Buckets) > 0 {
+		opts = append(opts, WithBuckets(cfg.Buckets...))
+	}
+	if cfg.MaxSeries > 0 {
+		opts = append(opts, WithMaxSeries(cfg.MaxSeries))
+	}
+	return opts
+}
+
+// Processor é um sdktrace.SpanProcessor que registra as métricas RED de cada
+// span finalizado. Só recebe os spans gravados: use Sampler para que os
+// spans descartados pela amostragem também sejam contabilizados
+type Processor struct {
+	calls      metric.Int64Counter
+	duration   metric.Float64Histogram
+	buckets    []float64
+	dimensions []attribute.Key
+	kinds      map[oteltrace.SpanKind]bool
+	maxSeries  int
+
+	mu     sync.RWMutex
+	series map[string]metric.MeasurementOption
+}
+
+var _ sdktrace.SpanProcessor = (*Processor)(nil)
+
+// New cria o Processor com os instrumentos no MeterProvider informado, ou no
+// global quando nil
+func New(provider metric.MeterProvider, opts ...Option) (*Processor, error) {
+	p := &Processor{
+		buckets:   metrics.DefaultLatencyBuckets,
+		maxSeries: DefaultMaxSeries,
+		series:    make(map[string]metric.MeasurementOption),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if provider == nil {
+		provider = otel.GetMeterProvider()
+	}
+	meter := provider.Meter(meterName)
+
+	var err error
+	p.calls, err = meter.Int64Counter(MetricCalls,
+		metric.WithUnit("{call}"),
+		metric.WithDescription("Spans finalizados, por serviço, nome, tipo e status"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create counter %s: %w", MetricCalls, err)
+	}
+	p.duration, err = meter.Float64Histogram(MetricDuration,
+		metric.WithUnit("s"),
+		metric.WithDescription("Duração dos spans, por serviço, nome, tipo e status"),
+		metric.WithExplicitBucketBoundaries(p.buckets...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create histogram %s: %w", MetricDuration, err)
+	}
+	return p, nil
+}
+
+// OnStart não faz nada: as métricas são registradas no fim do span
+func (p *Processor) OnStart(context.Context, sdktrace.ReadWriteSpan) {}
+
+// OnEnd registra a chamada e a duração do span. O contexto da medição carrega
+// o span, para que o SDK o associe como exemplar quando amostrado
+func (p *Processor) OnEnd(s sdktrace.ReadOnlySpan) {
+	if p.kinds != nil && !p.kinds[s.SpanKind()] {
+		return
+	}
+	attrs := p.attributes(s)
+	ctx := oteltrace.ContextWithSpanContext(context.Background(), s.SpanContext())
+	p.calls.Add(ctx, 1, attrs)
+	p.duration.Record(ctx, s.EndTime().Sub(s.StartTime()).Seconds(), attrs)
+}
+
+// Shutdown não tem recursos a liberar: as métricas são exportadas pelo
+// MeterProvider
+func (p *Processor) Shutdown(context.Context) error { return nil }
+
+// ForceFlush não tem dados retidos
+func (p *Processor) ForceFlush(context.Context) error { return nil }
+
+// attributes retorna os atributos das métricas do span, reaproveitando o
+// conjunto já criado para a mesma combinação
+func (p *Processor) attributes(s sdktrace.ReadOnlySpan) metric.MeasurementOption {
+	service := s.Resource().Set()
+	serviceName, _ := service.Value(semconv.ServiceNameKey)
+
+	kv := make([]attribute.KeyValue, 0, 4+len(p.dimensions))
+	kv = append(kv,
+		attribute.String(AttrServiceName, serviceName.Emit()),
+		attribute.String(AttrSpanName, s.Name()),
+		attribute.String(AttrSpanKind, "SPAN_KIND_"+strings.ToUpper(s.SpanKind().String())),
+		attribute.String(AttrStatusCode, "STATUS_CODE_"+strings.ToUpper(s.Status().Code.String())),
+	)
+	if len(p.dimensions) > 0 {
+		for _, attr := range s.Attributes() {
+			for _, key := range p.dimensions {
+				if attr.Key == key {
+					kv = append(kv, attr)
+				}
+			}
+		}
+	}
+
+	var key strings.Builder
+	for _, attr := range kv {
+		key.WriteString(string(attr.Key))
+		key.WriteByte('=')
+		key.WriteString(attr.Value.Emit())
+		key.WriteByte(0)
+	}
+
+	p.mu.RLock()
+	opt, ok := p.series[key.String()]
+	p.mu.RUnlock()
+	if ok {
+		return opt
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if opt, ok := p.series[key.String()]; ok {
+		return opt
+	}
+	if len(p.series) >= p.maxSeries {
+		return p.overflow(serviceName.Emit())
+	}
+	opt = metric.WithAttributeSet(attribute.NewSet(kv...))
+	p.series[key.String()] = opt
+	return opt
+}
+
+// overflow retorna a série de overflow do serviço. Deve ser chamado com o
+// lock; as séries de overflow não contam para o limite
+func (p *Processor) overflow(service string) metric.MeasurementOption {
+	key := "\x00overflow\x00" + service
+	if opt, ok := p.series[key]; ok {
+		return opt
+	}
+	opt := metric.WithAttributeSet(attribute.NewSet(
+		attribute.String(AttrServiceName, service),
+		attribute.Bool(AttrOverflow, true),
+	))
+	p.series[key] = opt
+	return opt
+}
+
+// Sampler envolve o sampler para que os spans que ele descarta sejam
+// gravados sem amostragem (RecordOnly): o Processor os recebe, mas os
+// exporters não. Assim as métricas contam todas as operações qualquer que
+// seja a taxa de amostragem, ao custo de gravar todos os spans em memória
+func Sampler(base sdktrace.Sampler) sdktrace.Sampler {
+	return recordAll{base: base}
+}
+
+type recordAll struct {
+	base sdktrace.Sampler
+}
+
+func (s recordAll) ShouldSample(params sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	result := s.base.ShouldSample(params)
+	if result.Decision == sdktrace.Drop {
+		result.Decision = sdktrace.RecordOnly
+	}
+	return result
+}
+
+func (s recordAll) Description() string {
+	return "RecordAll{" + s.base.Description() + "}"
+}
+
+// Options retorna as opções do TracerProvider do SDK com o sampler informado
+// e, quando as métricas estão ativas na configuração, o Processor no
+// MeterProvider global e o Sampler que grava os spans descartados
+//
+//	opts, err := spanmetrics.Options(config.SpanMetrics, sampler)
+//	tp := trace.NewTracerProvider(append(opts, trace.WithBatcher(exporter))...)
+func Options(cfg interfaces.SpanMetricsConfig, sampler sdktrace.Sampler) ([]sdktrace.TracerProviderOption, error) {
+	if !cfg.Enabled {
+		return []sdktrace.TracerProviderOption{sdktrace.WithSampler(sampler)}, nil
+	}
+	processor, err := New(nil, FromConfig(cfg)...)
+	if err != nil {
+		return nil, err
+	}
+	return []sdktrace.TracerProviderOption{
+		sdktrace.WithSampler(Sampler(sampler)),
+		sdktrace.WithSpanProcessor(processor),
+	}, nil
+}
@@ -0,0 +1,164 @@
+package spanmetrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.28.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/fsvxavier/nexs-lib/observability/tracer/interfaces"
+)
+
+// newTracer cria um tracer cujos spans passam pelo processor, com as
+// métricas lidas pelo reader retornado
+func newTracer(t *testing.T, sampler sdktrace.Sampler, opts ...Option) (oteltrace.Tracer, *sdkmetric.ManualReader, *tracetest.InMemoryExporter) {
+	t.Helper()
+	reader := sdkmetric.NewManualReader()
+	processor, err := New(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)), opts...)
+	require.NoError(t, err)
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(Sampler(sampler)),
+		sdktrace.WithSpanProcessor(processor),
+		sdktrace.WithSyncer(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName("orders"))),
+	)
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	return tp.Tracer("test"), reader, exporter
+}
+
+// collect retorna as métricas do reader por nome
+func collect(t *testing.T, reader *sdkmetric.ManualReader) map[string]metricdata.Aggregation {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	out := make(map[string]metricdata.Aggregation)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			out[m.Name] = m.Data
+		}
+	}
+	return out
+}
+
+// calls retorna as chamadas por span.name e status.code
+func calls(t *testing.T, data metricdata.Aggregation) map[[2]string]int64 {
+	t.Helper()
+	sum, ok := data.(metricdata.Sum[int64])
+	require.True(t, ok)
+	out := make(map[[2]string]int64)
+	for _, dp := range sum.DataPoints {
+		name, _ := dp.Attributes.Value(AttrSpanName)
+		status, _ := dp.Attributes.Value(AttrStatusCode)
+		out[[2]string{name.AsString(), status.AsString()}] += dp.Value
+	}
+	return out
+}
+
+func TestProcessor_RED(t *testing.T) {
+	tracer, reader, exporter := newTracer(t, sdktrace.NeverSample())
+	ctx := context.Background()
+
+	for i := range 3 {
+		_, span := tracer.Start(ctx, "GET /orders", oteltrace.WithSpanKind(oteltrace.SpanKindServer))
+		if i == 2 {
+			span.SetStatus(codes.Error, "boom")
+		}
+		span.End(oteltrace.WithTimestamp(time.Now().Add(50 * time.Millisecond)))
+	}
+	_, span := tracer.Start(ctx, "SELECT orders", oteltrace.WithSpanKind(oteltrace.SpanKindClient))
+	span.End()
+
+	assert.Empty(t, exporter.GetSpans(), "spans dropped by the sampler are not exported")
+
+	data := collect(t, reader)
+	assert.Equal(t, map[[2]string]int64{
+		{"GET /orders", "STATUS_CODE_UNSET"}:   2,
+		{"GET /orders", "STATUS_CODE_ERROR"}:   1,
+		{"SELECT orders", "STATUS_CODE_UNSET"}: 1,
+	}, calls(t, data[MetricCalls]))
+
+	histogram, ok := data[MetricDuration].(metricdata.Histogram[float64])
+	require.True(t, ok)
+	var count uint64
+	for _, dp := range histogram.DataPoints {
+		count += dp.Count
+		kind, _ := dp.Attributes.Value(AttrSpanKind)
+		service, _ := dp.Attributes.Value(AttrServiceName)
+		assert.Equal(t, "orders", service.AsString())
+		if kind.AsString() == "SPAN_KIND_SERVER" {
+			fastest, _ := dp.Min.Value()
+			assert.GreaterOrEqual(t, fastest, 0.05)
+		}
+	}
+	assert.Equal(t, uint64(4), count)
+}
+
+func TestProcessor_Options(t *testing.T) {
+	tracer, reader, _ := newTracer(t, sdktrace.AlwaysSample(),
+		WithDimensions("http.route"),
+		WithSpanKinds(oteltrace.SpanKindServer),
+		WithMaxSeries(2),
+	)
+	ctx := context.Background()
+	for _, id := range []string{"1", "2", "3", "4"} {
+		_, span := tracer.Start(ctx, "GET /orders/"+id,
+			oteltrace.WithSpanKind(oteltrace.SpanKindServer),
+			oteltrace.WithAttributes(attribute.String("http.route", "/orders/{id}"), attribute.String("order.id", id)))
+		span.End()
+	}
+	_, span := tracer.Start(ctx, "internal work")
+	span.End()
+
+	sum := collect(t, reader)[MetricCalls].(metricdata.Sum[int64])
+	require.Len(t, sum.DataPoints, 3, "two series and the overflow")
+	var overflow int64
+	for _, dp := range sum.DataPoints {
+		if v, ok := dp.Attributes.Value(AttrOverflow); ok && v.AsBool() {
+			overflow = dp.Value
+			continue
+		}
+		route, ok := dp.Attributes.Value("http.route")
+		assert.True(t, ok)
+		assert.Equal(t, "/orders/{id}", route.AsString())
+		_, ok = dp.Attributes.Value("order.id")
+		assert.False(t, ok, "only the dimensions are added")
+	}
+	assert.Equal(t, int64(2), overflow)
+}
+
+func TestOptions(t *testing.T) {
+	previous := otel.GetMeterProvider()
+	reader := sdkmetric.NewManualReader()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	t.Cleanup(func() { otel.SetMeterProvider(previous) })
+
+	opts, err := Options(interfaces.SpanMetricsConfig{}, sdktrace.AlwaysSample())
+	require.NoError(t, err)
+	assert.Len(t, opts, 1, "disabled: only the sampler")
+
+	opts, err = Options(interfaces.SpanMetricsConfig{Enabled: true, MaxSeries: 10}, sdktrace.TraceIDRatioBased(0))
+	require.NoError(t, err)
+	tp := sdktrace.NewTracerProvider(opts...)
+	defer tp.Shutdown(context.Background())
+
+	_, span := tp.Tracer("test").Start(context.Background(), "job")
+	assert.False(t, span.SpanContext().IsSampled())
+	assert.True(t, span.IsRecording())
+	span.End()
+
+	assert.Equal(t, map[[2]string]int64{{"job", "STATUS_CODE_UNSET"}: 1}, calls(t, collect(t, reader)[MetricCalls]))
+}
@@ -1,5 +1,6 @@
 // Package tracer fornece uma implementação unificada de tracing distribuído
 // com suporte a múltiplos providers (Datadog, Grafana, New Relic, OpenTelemetry)
+// e exporters locais (memória e arquivo) para desenvolvimento e testes
 package tracer
 
 import (
@@ -11,9 +12,13 @@ import (
 	"github.com/fsvxavier/nexs-lib/observability/tracer/config"
 	"github.com/fsvxavier/nexs-lib/observability/tracer/interfaces"
 	"github.com/fsvxavier/nexs-lib/observability/tracer/providers/datadog"
+	"github.com/fsvxavier/nexs-lib/observability/tracer/providers/file"
 	"github.com/fsvxavier/nexs-lib/observability/tracer/providers/grafana"
+	"github.com/fsvxavier/nexs-lib/observability/tracer/providers/memory"
 	"github.com/fsvxavier/nexs-lib/observability/tracer/providers/newrelic"
+	"github.com/fsvxavier/nexs-lib/observability/tracer/providers/noop"
 	"github.com/fsvxavier/nexs-lib/observability/tracer/providers/opentelemetry"
+	"github.com/fsvxavier/nexs-lib/observability/tracer/providers/xray"
 )
 
 // TracerManager gerencia o tracer provider ativo
@@ -87,6 +92,14 @@ func NewTracerProvider(config interfaces.Config) (interfaces.TracerProvider, err
 		return newrelic.NewProvider(), nil
 	case "opentelemetry":
 		return opentelemetry.NewProvider(), nil
+	case "memory":
+		return memory.NewProvider(), nil
+	case "file":
+		return file.NewProvider(), nil
+	case "noop":
+		return noop.NewProvider(), nil
+	case "xray":
+		return xray.NewProvider(), nil
 	default:
 		return nil, fmt.Errorf("unsupported exporter type: %s", config.ExporterType)
 	}
@@ -107,7 +120,7 @@ func (f *Factory) CreateProvider(config interfaces.Config) (interfaces.TracerPro
 
 // SupportedTypes retorna os tipos de exporters suportados
 func (f *Factory) SupportedTypes() []string {
-	return []string{"datadog", "grafana", "newrelic", "opentelemetry"}
+	return []string{"datadog", "grafana", "newrelic", "opentelemetry", "memory", "file", "noop", "xray"}
 }
 
 // QuickStart inicializa rapidamente um tracer com configuração mínima
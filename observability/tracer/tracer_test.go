@@ -242,6 +242,10 @@ func TestNewTracerProvider(t *testing.T) {
 		{"grafana", "grafana", false},
 		{"newrelic", "newrelic", false},
 		{"opentelemetry", "opentelemetry", false},
+		{"memory", "memory", false},
+		{"file", "file", false},
+		{"noop", "noop", false},
+		{"xray", "xray", false},
 		{"unsupported", "unsupported", true},
 	}
 
@@ -273,7 +277,7 @@ func TestFactory(t *testing.T) {
 
 	// Test SupportedTypes
 	types := factory.SupportedTypes()
-	expectedTypes := []string{"datadog", "grafana", "newrelic", "opentelemetry"}
+	expectedTypes := []string{"datadog", "grafana", "newrelic", "opentelemetry", "memory", "file", "noop", "xray"}
 
 	if len(types) != len(expectedTypes) {
 		t.Errorf("SupportedTypes() returned %d types, want %d", len(types), len(expectedTypes))
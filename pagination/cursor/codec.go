@@ -0,0 +1,174 @@
+// Package cursor provides keyset (cursor) and limit/offset pagination for
+// SQL queries: it parses limit, offset and signed cursor tokens from
+// requests, builds keyset predicates over the sort columns, counts totals
+// exactly, approximately or not at all, and builds response envelopes with
+// the next cursor.
+package cursor
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+// Pagination errors. The domain errors returned by the package wrap them, so
+// errors.Is can be used to tell failures apart.
+var (
+	ErrInvalidCursor = errors.New("cursor: invalid cursor")
+	ErrExpiredCursor = errors.New("cursor: expired cursor")
+	ErrInvalidParams = errors.New("cursor: invalid pagination parameters")
+	ErrInvalidKeyset = errors.New("cursor: invalid keyset")
+)
+
+// Cursor is the position after the last item of a page: the values of its
+// sort columns.
+type Cursor struct {
+	// Values are the sort column values of the last item, in keyset order.
+	Values []any
+
+	// Keyset identifies the sort order the cursor was issued for.
+	Keyset string
+
+	// IssuedAt is when the cursor was encoded.
+	IssuedAt time.Time
+}
+
+// payload is the signed part of a token.
+type payload struct {
+	Values   []any  `json:"v"`
+	Keyset   string `json:"k"`
+	IssuedAt int64  `json:"t"`
+}
+
+// CodecOption configures a Codec.
+type CodecOption func(*Codec)
+
+// WithTTL rejects cursors older than ttl. Defaults to no expiration.
+func WithTTL(ttl time.Duration) CodecOption {
+	return func(c *Codec) {
+		c.ttl = ttl
+	}
+}
+
+// WithPreviousSecrets accepts cursors signed with previous secrets, so the
+// secret can be rotated without breaking cursors held by clients.
+func WithPreviousSecrets(secrets ...[]byte) CodecOption {
+	return func(c *Codec) {
+		c.previous = append(c.previous, secrets...)
+	}
+}
+
+// Codec encodes cursors as opaque tokens signed with HMAC-SHA256, so clients
+// cannot forge positions. It is safe for concurrent use.
+type Codec struct {
+	secret   []byte
+	previous [][]byte
+	ttl      time.Duration
+	now      func() time.Time
+}
+
+// NewCodec creates a Codec signing with the secret.
+func NewCodec(secret []byte, opts ...CodecOption) *Codec {
+	c := &Codec{secret: secret, now: time.Now}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Encode returns the token of the cursor.
+func (c *Codec) Encode(cur Cursor) (string, error) {
+	issuedAt := cur.IssuedAt
+	if issuedAt.IsZero() {
+		issuedAt = c.now()
+	}
+	data, err := json.Marshal(payload{Values: cur.Values, Keyset: cur.Keyset, IssuedAt: issuedAt.Unix()})
+	if err != nil {
+		return "", err
+	}
+	body := base64.RawURLEncoding.EncodeToString(data)
+	return body + "." + base64.RawURLEncoding.EncodeToString(sign(c.secret, body)), nil
+}
+
+// Decode verifies the token and returns its cursor. Malformed, tampered and
+// expired tokens return a Validation domain error wrapping ErrInvalidCursor,
+// and also ErrExpiredCursor for expired ones.
+func (c *Codec) Decode(token string) (Cursor, error) {
+	body, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return Cursor{}, invalidCursor("malformed cursor", ErrInvalidCursor)
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !c.verify(body, mac) {
+		return Cursor{}, invalidCursor("invalid cursor signature", ErrInvalidCursor)
+	}
+	data, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return Cursor{}, invalidCursor("malformed cursor", ErrInvalidCursor)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var p payload
+	if err := decoder.Decode(&p); err != nil {
+		return Cursor{}, invalidCursor("malformed cursor", ErrInvalidCursor)
+	}
+
+	issuedAt := time.Unix(p.IssuedAt, 0)
+	if c.ttl > 0 && c.now().Sub(issuedAt) > c.ttl {
+		return Cursor{}, invalidCursor("cursor expired", errors.Join(ErrInvalidCursor, ErrExpiredCursor))
+	}
+	for i, value := range p.Values {
+		p.Values[i] = normalize(value)
+	}
+	return Cursor{Values: p.Values, Keyset: p.Keyset, IssuedAt: issuedAt}, nil
+}
+
+// verify checks the signature against the current and previous secrets.
+func (c *Codec) verify(body string, mac []byte) bool {
+	if hmac.Equal(mac, sign(c.secret, body)) {
+		return true
+	}
+	for _, secret := range c.previous {
+		if hmac.Equal(mac, sign(secret, body)) {
+			return true
+		}
+	}
+	return false
+}
+
+// sign returns the HMAC-SHA256 of the body.
+func sign(secret []byte, body string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(body))
+	return mac.Sum(nil)
+}
+
+// normalize converts decoded JSON numbers to int64 when integral and to
+// float64 otherwise, so large IDs survive the round trip.
+func normalize(value any) any {
+	number, ok := value.(json.Number)
+	if !ok {
+		return value
+	}
+	if i, err := strconv.ParseInt(number.String(), 10, 64); err == nil {
+		return i
+	}
+	f, _ := number.Float64()
+	return f
+}
+
+// invalidCursor returns the Validation domain error of a bad cursor.
+func invalidCursor(message string, cause error) error {
+	return domainerrors.New(interfaces.ValidationError, "INVALID_CURSOR", message).
+		WithMetadata("field", ParamCursor).Wrap(cause)
+}
@@ -0,0 +1,113 @@
+package cursor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	pginterfaces "github.com/fsvxavier/nexs-lib/db/postgres/interfaces"
+	"github.com/fsvxavier/nexs-lib/pagination/interfaces"
+)
+
+// Querier runs count queries; db/postgres connections satisfy it.
+type Querier interface {
+	QueryRow(ctx context.Context, query string, args ...any) pginterfaces.IRow
+}
+
+// Total is the number of rows of a query.
+type Total struct {
+	Value int64
+
+	// Exact is false for estimates and capped counts.
+	Exact bool
+}
+
+// Counter is a total-count strategy. It counts the rows of the base query,
+// before pagination, or returns nil when the total is not counted.
+type Counter interface {
+	Count(ctx context.Context, db Querier, baseQuery string, args ...any) (*Total, error)
+}
+
+// NoCount skips counting, the cheapest strategy for infinite scrolling.
+type NoCount struct{}
+
+// Count returns nil.
+func (NoCount) Count(ctx context.Context, db Querier, baseQuery string, args ...any) (*Total, error) {
+	return nil, nil
+}
+
+// ExactCount counts all rows. Builder, e.g. the query builder of the
+// pagination package, builds the count query; by default the base query is
+// wrapped in SELECT COUNT(*).
+type ExactCount struct {
+	Builder interfaces.QueryBuilder
+}
+
+// Count counts the rows of the base query.
+func (c ExactCount) Count(ctx context.Context, db Querier, baseQuery string, args ...any) (*Total, error) {
+	query := fmt.Sprintf("SELECT COUNT(*) FROM (%s) AS count_query", strings.TrimSpace(baseQuery))
+	if c.Builder != nil {
+		query = c.Builder.BuildCountQuery(baseQuery)
+	}
+	var count int64
+	if err := db.QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		return nil, fmt.Errorf("cursor: count rows: %w", err)
+	}
+	return &Total{Value: count, Exact: true}, nil
+}
+
+// CappedCount counts rows up to Max, bounding the cost of counting large
+// results; larger totals are reported as Max, not exact ("10000+").
+type CappedCount struct {
+	Max int64
+}
+
+// Count counts the rows of the base query up to Max.
+func (c CappedCount) Count(ctx context.Context, db Querier, baseQuery string, args ...any) (*Total, error) {
+	query := fmt.Sprintf("SELECT COUNT(*) FROM (SELECT 1 FROM (%s) AS count_query LIMIT %d) AS capped",
+		strings.TrimSpace(baseQuery), c.Max+1)
+	var count int64
+	if err := db.QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		return nil, fmt.Errorf("cursor: count rows: %w", err)
+	}
+	if count > c.Max {
+		return &Total{Value: c.Max}, nil
+	}
+	return &Total{Value: count, Exact: true}, nil
+}
+
+// EstimatedCount reads the row estimate of the PostgreSQL planner, which
+// costs no scan but is only as accurate as the table statistics. Estimates
+// below ExactBelow are replaced by an exact count, so small results get
+// accurate totals.
+type EstimatedCount struct {
+	ExactBelow int64
+	Builder    interfaces.QueryBuilder
+}
+
+// Count estimates the rows of the base query.
+func (c EstimatedCount) Count(ctx context.Context, db Querier, baseQuery string, args ...any) (*Total, error) {
+	var plan string
+	if err := db.QueryRow(ctx, "EXPLAIN (FORMAT JSON) "+strings.TrimSpace(baseQuery), args...).Scan(&plan); err != nil {
+		return nil, fmt.Errorf("cursor: explain query: %w", err)
+	}
+
+	var explain []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(plan), &explain); err != nil {
+		return nil, fmt.Errorf("cursor: parse query plan: %w", err)
+	}
+	if len(explain) == 0 {
+		return nil, fmt.Errorf("cursor: empty query plan")
+	}
+
+	estimate := int64(explain[0].Plan.Rows)
+	if estimate < c.ExactBelow {
+		return ExactCount{Builder: c.Builder}.Count(ctx, db, baseQuery, args...)
+	}
+	return &Total{Value: estimate}, nil
+}
@@ -0,0 +1,312 @@
+package cursor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pginterfaces "github.com/fsvxavier/nexs-lib/db/postgres/interfaces"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/fsvxavier/nexs-lib/pagination/config"
+	paginationinterfaces "github.com/fsvxavier/nexs-lib/pagination/interfaces"
+)
+
+func TestCodec_RoundTrip(t *testing.T) {
+	codec := NewCodec([]byte("secret"))
+
+	token, err := codec.Encode(Cursor{Values: []any{"2024-01-02T03:04:05Z", int64(9007199254740993), 1.5}, Keyset: "created_at:desc,id:desc"})
+	require.NoError(t, err)
+	assert.NotContains(t, token, "=")
+
+	cur, err := codec.Decode(token)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"2024-01-02T03:04:05Z", int64(9007199254740993), 1.5}, cur.Values)
+	assert.Equal(t, "created_at:desc,id:desc", cur.Keyset)
+	assert.WithinDuration(t, time.Now(), cur.IssuedAt, 2*time.Second)
+}
+
+func TestCodec_Invalid(t *testing.T) {
+	codec := NewCodec([]byte("secret"))
+	token, err := codec.Encode(Cursor{Values: []any{int64(1)}, Keyset: "id:asc"})
+	require.NoError(t, err)
+
+	body, signature, _ := strings.Cut(token, ".")
+	forged, err := NewCodec([]byte("other")).Encode(Cursor{Values: []any{int64(1000)}, Keyset: "id:asc"})
+	require.NoError(t, err)
+	forgedBody, _, _ := strings.Cut(forged, ".")
+
+	for name, token := range map[string]string{
+		"empty":         "",
+		"no signature":  body,
+		"bad base64":    body + ".!!!",
+		"wrong secret":  forged,
+		"swapped body":  forgedBody + "." + signature,
+		"garbage":       "abc.def",
+		"truncated sig": body + "." + signature[:10],
+	} {
+		_, err := codec.Decode(token)
+		require.Error(t, err, name)
+		assert.ErrorIs(t, err, ErrInvalidCursor, name)
+
+		var domainErr interfaces.DomainErrorInterface
+		require.True(t, errors.As(err, &domainErr), name)
+		assert.Equal(t, "INVALID_CURSOR", domainErr.Code())
+		assert.Equal(t, http.StatusBadRequest, domainErr.HTTPStatus())
+	}
+}
+
+func TestCodec_TTLAndRotation(t *testing.T) {
+	old := NewCodec([]byte("old"))
+	token, err := old.Encode(Cursor{Values: []any{int64(1)}, IssuedAt: time.Now().Add(-2 * time.Hour)})
+	require.NoError(t, err)
+
+	rotated := NewCodec([]byte("new"), WithPreviousSecrets([]byte("old")))
+	_, err = rotated.Decode(token)
+	assert.NoError(t, err)
+
+	expiring := NewCodec([]byte("new"), WithPreviousSecrets([]byte("old")), WithTTL(time.Hour))
+	_, err = expiring.Decode(token)
+	assert.ErrorIs(t, err, ErrExpiredCursor)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestNewKeyset(t *testing.T) {
+	keyset, err := NewKeyset(Desc("created_at"), Asc("o.id"))
+	require.NoError(t, err)
+	assert.Equal(t, "created_at:desc,o.id:asc", keyset.String())
+	assert.Equal(t, "created_at DESC, o.id ASC", keyset.OrderBy())
+
+	_, err = NewKeyset()
+	assert.ErrorIs(t, err, ErrInvalidKeyset)
+	_, err = NewKeyset(Asc("id; DROP TABLE orders"))
+	assert.ErrorIs(t, err, ErrInvalidKeyset)
+}
+
+func TestKeyset_Where(t *testing.T) {
+	uniform, err := NewKeyset(Desc("created_at"), Desc("id"))
+	require.NoError(t, err)
+	where, args := uniform.Where(Cursor{Values: []any{"t", int64(7)}}, 2)
+	assert.Equal(t, "(created_at, id) < ($2, $3)", where)
+	assert.Equal(t, []any{"t", int64(7)}, args)
+
+	mixed, err := NewKeyset(Asc("name"), Desc("score"), Asc("id"))
+	require.NoError(t, err)
+	where, _ = mixed.Where(Cursor{Values: []any{"a", 1, 2}}, 1)
+	assert.Equal(t, "((name > $1) OR (name = $1 AND score < $2) OR (name = $1 AND score = $2 AND id > $3))", where)
+}
+
+func newPaginator(t *testing.T, opts ...Option) *Paginator {
+	t.Helper()
+	keyset, err := NewKeyset(Desc("created_at"), Desc("id"))
+	require.NoError(t, err)
+	return New(keyset, NewCodec([]byte("secret")), opts...)
+}
+
+func TestPaginator_Parse(t *testing.T) {
+	p := newPaginator(t, WithConfig(&config.Config{DefaultLimit: 20, MaxLimit: 100}), WithMaxOffset(500))
+
+	req, err := p.Parse(url.Values{})
+	require.NoError(t, err)
+	assert.Equal(t, &Request{Limit: 20}, req)
+
+	req, err = p.Parse(url.Values{"limit": {"1000"}, "offset": {"40"}})
+	require.NoError(t, err)
+	assert.Equal(t, &Request{Limit: 100, Offset: 40}, req)
+
+	token, err := p.codec.Encode(Cursor{Values: []any{"t", int64(7)}, Keyset: p.Keyset().String()})
+	require.NoError(t, err)
+	req, err = p.Parse(url.Values{"cursor": {token}})
+	require.NoError(t, err)
+	require.NotNil(t, req.Cursor)
+	assert.Equal(t, []any{"t", int64(7)}, req.Cursor.Values)
+
+	for name, tc := range map[string]struct {
+		params url.Values
+		code   string
+		target error
+	}{
+		"limit not a number": {url.Values{"limit": {"ten"}}, "INVALID_LIMIT", ErrInvalidParams},
+		"zero limit":         {url.Values{"limit": {"0"}}, "INVALID_LIMIT", ErrInvalidParams},
+		"negative offset":    {url.Values{"offset": {"-1"}}, "INVALID_OFFSET", ErrInvalidParams},
+		"offset too large":   {url.Values{"offset": {"501"}}, "OFFSET_TOO_LARGE", ErrInvalidParams},
+		"offset and cursor":  {url.Values{"offset": {"1"}, "cursor": {token}}, "INVALID_PAGINATION", ErrInvalidParams},
+		"malformed cursor":   {url.Values{"cursor": {"nope"}}, "INVALID_CURSOR", ErrInvalidCursor},
+	} {
+		_, err := p.Parse(tc.params)
+		require.Error(t, err, name)
+		assert.ErrorIs(t, err, tc.target, name)
+
+		var domainErr interfaces.DomainErrorInterface
+		require.True(t, errors.As(err, &domainErr), name)
+		assert.Equal(t, tc.code, domainErr.Code(), name)
+	}
+}
+
+func TestPaginator_ParseForeignCursor(t *testing.T) {
+	p := newPaginator(t)
+	other, err := NewKeyset(Asc("name"))
+	require.NoError(t, err)
+
+	token, err := p.codec.Encode(Cursor{Values: []any{"a"}, Keyset: other.String()})
+	require.NoError(t, err)
+	_, err = p.Parse(url.Values{"cursor": {token}})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	keyset, err := NewKeyset(Asc("id"))
+	require.NoError(t, err)
+	_, err = New(keyset, nil).Parse(url.Values{"cursor": {token}})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestPaginator_Query(t *testing.T) {
+	p := newPaginator(t)
+	base := "SELECT id, created_at FROM orders WHERE tenant_id = $1"
+
+	query, args := p.Query(base, &Request{Limit: 10, Offset: 20}, 2)
+	assert.Equal(t, "SELECT * FROM (SELECT id, created_at FROM orders WHERE tenant_id = $1) AS page ORDER BY created_at DESC, id DESC LIMIT 11 OFFSET 20", query)
+	assert.Empty(t, args)
+
+	query, args = p.Query(base, &Request{Limit: 10, Cursor: &Cursor{Values: []any{"t", int64(7)}}}, 2)
+	assert.Equal(t, "SELECT * FROM (SELECT id, created_at FROM orders WHERE tenant_id = $1) AS page WHERE (created_at, id) < ($2, $3) ORDER BY created_at DESC, id DESC LIMIT 11", query)
+	assert.Equal(t, []any{"t", int64(7)}, args)
+}
+
+type order struct {
+	ID        int64  `json:"id"`
+	CreatedAt string `json:"created_at"`
+}
+
+func orderValues(o order) []any {
+	return []any{o.CreatedAt, o.ID}
+}
+
+func TestNewPage(t *testing.T) {
+	p := newPaginator(t)
+	rows := []order{{3, "c"}, {2, "b"}, {1, "a"}}
+
+	page, err := NewPage(p, &Request{Limit: 2}, rows, orderValues)
+	require.NoError(t, err)
+	assert.Equal(t, rows[:2], page.Items)
+	assert.True(t, page.HasMore)
+	require.NotNil(t, page.NextOffset)
+	assert.Equal(t, 2, *page.NextOffset)
+
+	cur, err := p.DecodeCursor(page.NextCursor)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"b", int64(2)}, cur.Values)
+
+	page, err = NewPage(p, &Request{Limit: 2, Cursor: &cur}, rows[2:], orderValues)
+	require.NoError(t, err)
+	assert.False(t, page.HasMore)
+	assert.Empty(t, page.NextCursor)
+	assert.Nil(t, page.NextOffset)
+
+	page, err = NewPage[order](p, &Request{Limit: 2}, nil, orderValues)
+	require.NoError(t, err)
+	page.SetTotal(&Total{Value: 1000})
+	data, err := json.Marshal(page)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"items":[],"has_more":false,"total":1000,"total_estimated":true}`, string(data))
+}
+
+// fakeRow scans a fixed value.
+type fakeRow struct {
+	value any
+	err   error
+}
+
+func (r fakeRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	switch d := dest[0].(type) {
+	case *int64:
+		*d = r.value.(int64)
+	case *string:
+		*d = r.value.(string)
+	default:
+		return fmt.Errorf("unsupported destination %T", d)
+	}
+	return nil
+}
+
+// fakeQuerier answers count and explain queries.
+type fakeQuerier struct {
+	count   int64
+	plan    string
+	queries []string
+}
+
+func (q *fakeQuerier) QueryRow(ctx context.Context, query string, args ...any) pginterfaces.IRow {
+	q.queries = append(q.queries, query)
+	if strings.HasPrefix(query, "EXPLAIN") {
+		return fakeRow{value: q.plan}
+	}
+	if strings.Contains(query, "LIMIT") {
+		var limit int64
+		fmt.Sscanf(query[strings.LastIndex(query, "LIMIT")+6:], "%d", &limit)
+		return fakeRow{value: min(q.count, limit)}
+	}
+	return fakeRow{value: q.count}
+}
+
+// countBuilder builds count queries like the standard query builder of the
+// pagination package.
+type countBuilder struct{}
+
+func (countBuilder) BuildQuery(baseQuery string, params *paginationinterfaces.PaginationParams) string {
+	return baseQuery
+}
+
+func (countBuilder) BuildCountQuery(baseQuery string) string {
+	return "SELECT COUNT(*) FROM (" + baseQuery + ") AS built"
+}
+
+func TestCounters(t *testing.T) {
+	ctx := context.Background()
+	db := &fakeQuerier{count: 42, plan: `[{"Plan": {"Plan Rows": 120000}}]`}
+	base := "SELECT id FROM orders WHERE tenant_id = $1"
+
+	total, err := NoCount{}.Count(ctx, db, base, "acme")
+	require.NoError(t, err)
+	assert.Nil(t, total)
+
+	total, err = ExactCount{}.Count(ctx, db, base, "acme")
+	require.NoError(t, err)
+	assert.Equal(t, &Total{Value: 42, Exact: true}, total)
+	assert.Equal(t, "SELECT COUNT(*) FROM (SELECT id FROM orders WHERE tenant_id = $1) AS count_query", db.queries[0])
+
+	_, err = ExactCount{Builder: countBuilder{}}.Count(ctx, db, base, "acme")
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT COUNT(*) FROM (SELECT id FROM orders WHERE tenant_id = $1) AS built", db.queries[1])
+
+	total, err = CappedCount{Max: 10}.Count(ctx, db, base, "acme")
+	require.NoError(t, err)
+	assert.Equal(t, &Total{Value: 10}, total)
+
+	total, err = CappedCount{Max: 100}.Count(ctx, db, base, "acme")
+	require.NoError(t, err)
+	assert.Equal(t, &Total{Value: 42, Exact: true}, total)
+
+	total, err = EstimatedCount{ExactBelow: 1000}.Count(ctx, db, base, "acme")
+	require.NoError(t, err)
+	assert.Equal(t, &Total{Value: 120000}, total)
+
+	db.plan = `[{"Plan": {"Plan Rows": 50}}]`
+	total, err = EstimatedCount{ExactBelow: 1000}.Count(ctx, db, base, "acme")
+	require.NoError(t, err)
+	assert.Equal(t, &Total{Value: 42, Exact: true}, total)
+
+	db.plan = `not json`
+	_, err = EstimatedCount{}.Count(ctx, db, base, "acme")
+	assert.Error(t, err)
+}
@@ -0,0 +1,124 @@
+package cursor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fsvxavier/nexs-lib/db/postgres"
+)
+
+// Column is a sort column of a keyset.
+type Column struct {
+	Name string
+	Desc bool
+}
+
+// Asc sorts by the column in ascending order.
+func Asc(name string) Column {
+	return Column{Name: name}
+}
+
+// Desc sorts by the column in descending order.
+func Desc(name string) Column {
+	return Column{Name: name, Desc: true}
+}
+
+// Keyset is the sort order of a paginated query. Its columns must identify
+// rows uniquely, e.g. ending with the primary key, and must not be NULL,
+// or pages would skip or repeat rows.
+type Keyset struct {
+	columns []Column
+	id      string
+}
+
+// NewKeyset creates the keyset of the columns.
+func NewKeyset(columns ...Column) (Keyset, error) {
+	if len(columns) == 0 {
+		return Keyset{}, fmt.Errorf("%w: no columns", ErrInvalidKeyset)
+	}
+	parts := make([]string, len(columns))
+	for i, column := range columns {
+		if !postgres.ValidIdentifier(column.Name) {
+			return Keyset{}, fmt.Errorf("%w: invalid column name %q", ErrInvalidKeyset, column.Name)
+		}
+		parts[i] = column.Name + ":" + direction(column)
+	}
+	return Keyset{columns: columns, id: strings.Join(parts, ",")}, nil
+}
+
+// Columns returns the sort columns.
+func (k Keyset) Columns() []Column {
+	return append([]Column(nil), k.columns...)
+}
+
+// String identifies the keyset, e.g. "created_at:desc,id:desc". Cursors
+// carry it, so a cursor cannot be used with another sort order.
+func (k Keyset) String() string {
+	return k.id
+}
+
+// OrderBy returns the ORDER BY clause of the keyset, without the keywords.
+func (k Keyset) OrderBy() string {
+	parts := make([]string, len(k.columns))
+	for i, column := range k.columns {
+		parts[i] = column.Name + " " + strings.ToUpper(direction(column))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Where returns the predicate selecting the rows after the cursor, with
+// placeholders numbered from firstArg, and its arguments. Keysets sorted in
+// a single direction use a row comparison, which PostgreSQL serves from a
+// matching index; mixed directions expand to OR-ed column comparisons.
+func (k Keyset) Where(cur Cursor, firstArg int) (string, []any) {
+	placeholders := make([]string, len(k.columns))
+	for i := range k.columns {
+		placeholders[i] = fmt.Sprintf("$%d", firstArg+i)
+	}
+
+	if k.uniform() {
+		names := make([]string, len(k.columns))
+		for i, column := range k.columns {
+			names[i] = column.Name
+		}
+		return fmt.Sprintf("(%s) %s (%s)", strings.Join(names, ", "), comparison(k.columns[0]),
+			strings.Join(placeholders, ", ")), cur.Values
+	}
+
+	terms := make([]string, len(k.columns))
+	for i, column := range k.columns {
+		conditions := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			conditions = append(conditions, k.columns[j].Name+" = "+placeholders[j])
+		}
+		conditions = append(conditions, column.Name+" "+comparison(column)+" "+placeholders[i])
+		terms[i] = "(" + strings.Join(conditions, " AND ") + ")"
+	}
+	return "(" + strings.Join(terms, " OR ") + ")", cur.Values
+}
+
+// uniform reports whether all columns sort in the same direction.
+func (k Keyset) uniform() bool {
+	for _, column := range k.columns[1:] {
+		if column.Desc != k.columns[0].Desc {
+			return false
+		}
+	}
+	return true
+}
+
+// direction returns "asc" or "desc".
+func direction(column Column) string {
+	if column.Desc {
+		return "desc"
+	}
+	return "asc"
+}
+
+// comparison returns the operator selecting rows after a value.
+func comparison(column Column) string {
+	if column.Desc {
+		return "<"
+	}
+	return ">"
+}
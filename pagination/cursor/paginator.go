@@ -0,0 +1,226 @@
+package cursor
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/fsvxavier/nexs-lib/pagination/config"
+)
+
+// Query parameters read by Parse.
+const (
+	ParamLimit  = "limit"
+	ParamOffset = "offset"
+	ParamCursor = "cursor"
+)
+
+// DefaultMaxOffset bounds offsets, which PostgreSQL serves by reading and
+// discarding every skipped row.
+const DefaultMaxOffset = 10000
+
+// Request is a parsed page request: either an offset or a cursor, never
+// both.
+type Request struct {
+	Limit  int
+	Offset int
+	Cursor *Cursor
+}
+
+// Option configures a Paginator.
+type Option func(*Paginator)
+
+// WithConfig sets the default and maximum limits. Defaults to
+// config.NewDefaultConfig.
+func WithConfig(cfg *config.Config) Option {
+	return func(p *Paginator) {
+		p.config = cfg
+	}
+}
+
+// WithMaxOffset sets the largest offset accepted; 0 disables offsets, so
+// clients must follow cursors. Defaults to DefaultMaxOffset.
+func WithMaxOffset(maxOffset int) Option {
+	return func(p *Paginator) {
+		p.maxOffset = maxOffset
+	}
+}
+
+// Paginator paginates the queries of a keyset. A nil codec disables
+// cursors, leaving limit/offset pagination.
+type Paginator struct {
+	keyset    Keyset
+	codec     *Codec
+	config    *config.Config
+	maxOffset int
+}
+
+// New creates a Paginator.
+func New(keyset Keyset, codec *Codec, opts ...Option) *Paginator {
+	p := &Paginator{keyset: keyset, codec: codec, maxOffset: DefaultMaxOffset}
+	for _, opt := range opts {
+		opt(p)
+	}
+	cfg := config.NewDefaultConfig()
+	if p.config != nil {
+		copied := *p.config
+		cfg = &copied
+	}
+	cfg.Validate()
+	p.config = cfg
+	return p
+}
+
+// Keyset returns the keyset of the paginator.
+func (p *Paginator) Keyset() Keyset {
+	return p.keyset
+}
+
+// Parse reads limit, offset and cursor from query parameters. Limits above
+// the maximum are capped, as by the page-based parser of the pagination
+// package; other invalid values return Validation domain errors wrapping
+// ErrInvalidParams or ErrInvalidCursor.
+func (p *Paginator) Parse(params url.Values) (*Request, error) {
+	req := &Request{Limit: p.config.DefaultLimit}
+
+	if value := params.Get(ParamLimit); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			return nil, invalidParams("INVALID_LIMIT", "limit must be a positive integer", ParamLimit)
+		}
+		req.Limit = min(limit, p.config.MaxLimit)
+	}
+
+	token := strings.TrimSpace(params.Get(ParamCursor))
+	if value := params.Get(ParamOffset); value != "" {
+		if token != "" {
+			return nil, invalidParams("INVALID_PAGINATION", "offset and cursor cannot be combined", ParamOffset)
+		}
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			return nil, invalidParams("INVALID_OFFSET", "offset must be a non-negative integer", ParamOffset)
+		}
+		if offset > p.maxOffset {
+			return nil, domainerrors.New(interfaces.ValidationError, "OFFSET_TOO_LARGE",
+				fmt.Sprintf("offset must not exceed %d, use the cursor", p.maxOffset)).
+				WithMetadata("field", ParamOffset).WithMetadata("max_offset", p.maxOffset).Wrap(ErrInvalidParams)
+		}
+		req.Offset = offset
+	}
+
+	if token != "" {
+		cur, err := p.DecodeCursor(token)
+		if err != nil {
+			return nil, err
+		}
+		req.Cursor = &cur
+	}
+	return req, nil
+}
+
+// DecodeCursor decodes a token and checks that it belongs to the keyset.
+func (p *Paginator) DecodeCursor(token string) (Cursor, error) {
+	if p.codec == nil {
+		return Cursor{}, invalidCursor("cursor pagination is not supported", ErrInvalidCursor)
+	}
+	cur, err := p.codec.Decode(token)
+	if err != nil {
+		return Cursor{}, err
+	}
+	if cur.Keyset != p.keyset.String() || len(cur.Values) != len(p.keyset.columns) {
+		return Cursor{}, invalidCursor("cursor does not match the sort order", ErrInvalidCursor)
+	}
+	return cur, nil
+}
+
+// Query wraps the base query, whose placeholders end before firstArg, with
+// the cursor predicate, the keyset order and the limit, and returns it with
+// the cursor arguments. It fetches one row more than the limit, so NewPage
+// knows whether another page follows. The sort columns must be output
+// columns of the base query.
+func (p *Paginator) Query(baseQuery string, req *Request, firstArg int) (string, []any) {
+	var query strings.Builder
+	var args []any
+
+	fmt.Fprintf(&query, "SELECT * FROM (%s) AS page", strings.TrimSpace(baseQuery))
+	if req.Cursor != nil {
+		where, whereArgs := p.keyset.Where(*req.Cursor, firstArg)
+		query.WriteString(" WHERE " + where)
+		args = whereArgs
+	}
+	fmt.Fprintf(&query, " ORDER BY %s LIMIT %d", p.keyset.OrderBy(), req.Limit+1)
+	if req.Offset > 0 {
+		fmt.Fprintf(&query, " OFFSET %d", req.Offset)
+	}
+	return query.String(), args
+}
+
+// Page is the response envelope of a page.
+type Page[T any] struct {
+	Items []T `json:"items"`
+
+	// NextCursor continues after the last item; empty on the last page or
+	// without codec.
+	NextCursor string `json:"next_cursor,omitempty"`
+
+	// NextOffset continues offset pagination; nil on the last page and for
+	// cursor requests.
+	NextOffset *int `json:"next_offset,omitempty"`
+
+	HasMore bool `json:"has_more"`
+
+	// Total is the number of items of all pages, when counted. Estimated
+	// totals set TotalEstimated.
+	Total          *int64 `json:"total,omitempty"`
+	TotalEstimated bool   `json:"total_estimated,omitempty"`
+}
+
+// SetTotal sets the total of the page; nil leaves it unknown.
+func (pg *Page[T]) SetTotal(total *Total) {
+	if total == nil {
+		return
+	}
+	value := total.Value
+	pg.Total = &value
+	pg.TotalEstimated = !total.Exact
+}
+
+// NewPage builds the page of the rows fetched by Paginator.Query. values
+// returns the sort column values of an item, in keyset order, for the next
+// cursor.
+func NewPage[T any](p *Paginator, req *Request, items []T, values func(T) []any) (*Page[T], error) {
+	page := &Page[T]{Items: items}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	if len(items) <= req.Limit {
+		return page, nil
+	}
+
+	page.Items = items[:req.Limit]
+	page.HasMore = true
+	if req.Cursor == nil {
+		next := req.Offset + req.Limit
+		page.NextOffset = &next
+	}
+	if p.codec != nil {
+		token, err := p.codec.Encode(Cursor{
+			Values: values(page.Items[len(page.Items)-1]),
+			Keyset: p.keyset.String(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("cursor: encode next cursor: %w", err)
+		}
+		page.NextCursor = token
+	}
+	return page, nil
+}
+
+// invalidParams returns the Validation domain error of a bad parameter.
+func invalidParams(code, message, field string) error {
+	return domainerrors.New(interfaces.ValidationError, code, message).
+		WithMetadata("field", field).Wrap(ErrInvalidParams)
+}
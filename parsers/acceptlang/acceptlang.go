@@ -0,0 +1,226 @@
+// Package acceptlang parses Accept-Language headers into quality-ordered
+// language lists and matches them against the supported locales.
+package acceptlang
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fsvxavier/nexs-lib/parsers/interfaces"
+)
+
+// Wildcard is the language range matching any language.
+const Wildcard = "*"
+
+// ErrInvalidLanguage indicates a malformed entry of an Accept-Language value.
+var ErrInvalidLanguage = errors.New("invalid language range")
+
+// Language is a language range of an Accept-Language value with its quality.
+type Language struct {
+	// Tag is the language tag, such as "pt-BR", or Wildcard.
+	Tag string
+	// Quality is the q weight, from 0 to 1. Zero marks the tag as not acceptable.
+	Quality float64
+}
+
+// Base returns the primary subtag, such as "pt" for "pt-BR".
+func (l Language) Base() string {
+	base, _, _ := strings.Cut(l.Tag, "-")
+	return base
+}
+
+// Languages is a list of language ranges ordered by descending quality.
+// Ranges of equal quality keep the order of the header.
+type Languages []Language
+
+// Tags returns the acceptable tags, in order.
+func (ls Languages) Tags() []string {
+	tags := make([]string, 0, len(ls))
+	for _, l := range ls {
+		if l.Quality > 0 {
+			tags = append(tags, l.Tag)
+		}
+	}
+	return tags
+}
+
+// Match returns the supported locale best matching the ranges, or fallback
+// when none matches. Each acceptable range is tried in order: first an exact
+// match, then a supported locale with the same primary subtag, so "pt-BR"
+// matches "pt" and "pt" matches "pt-BR". The wildcard matches the first
+// supported locale not excluded with q=0. Comparisons ignore case, and the
+// supported locale is returned as given.
+func (ls Languages) Match(supported []string, fallback string) string {
+	excluded := make(map[string]bool)
+	for _, l := range ls {
+		if l.Quality == 0 {
+			excluded[strings.ToLower(l.Tag)] = true
+		}
+	}
+
+	for _, l := range ls {
+		if l.Quality == 0 {
+			continue
+		}
+		if l.Tag == Wildcard {
+			for _, locale := range supported {
+				if !excluded[strings.ToLower(locale)] {
+					return locale
+				}
+			}
+			continue
+		}
+		for _, locale := range supported {
+			if strings.EqualFold(locale, l.Tag) && !excluded[strings.ToLower(locale)] {
+				return locale
+			}
+		}
+		for _, locale := range supported {
+			base, _, _ := strings.Cut(locale, "-")
+			if strings.EqualFold(base, l.Base()) && !excluded[strings.ToLower(locale)] {
+				return locale
+			}
+		}
+	}
+	return fallback
+}
+
+// Parser implements Accept-Language parsing.
+type Parser struct {
+	config *interfaces.ParserConfig
+}
+
+// NewParser creates a new Accept-Language parser with default configuration.
+func NewParser() *Parser {
+	return &Parser{
+		config: interfaces.DefaultConfig(),
+	}
+}
+
+// NewParserWithConfig creates a new Accept-Language parser with custom
+// configuration. Without strict mode, malformed entries are skipped instead
+// of failing the parse.
+func NewParserWithConfig(config *interfaces.ParserConfig) *Parser {
+	return &Parser{
+		config: config,
+	}
+}
+
+// Parse implements interfaces.Parser.
+func (p *Parser) Parse(ctx context.Context, data []byte) (*Languages, error) {
+	return p.ParseString(ctx, string(data))
+}
+
+// ParseString parses an Accept-Language value such as
+// "pt-BR,pt;q=0.9,en;q=0.8,*;q=0.1". An empty value results in an empty list.
+func (p *Parser) ParseString(ctx context.Context, input string) (*Languages, error) {
+	if p.config.MaxSize > 0 && int64(len(input)) > p.config.MaxSize {
+		return nil, &interfaces.ParseError{
+			Type:    interfaces.ErrorTypeSize,
+			Message: fmt.Sprintf("Accept-Language length %d exceeds maximum %d", len(input), p.config.MaxSize),
+		}
+	}
+
+	languages := Languages{}
+	for _, entry := range strings.Split(input, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		language, err := parseEntry(entry)
+		if err != nil {
+			if !p.config.StrictMode {
+				continue
+			}
+			return nil, &interfaces.ParseError{
+				Type:    interfaces.ErrorTypeSyntax,
+				Message: err.Error(),
+				Cause:   err,
+			}
+		}
+		languages = append(languages, language)
+	}
+
+	sort.SliceStable(languages, func(i, j int) bool {
+		return languages[i].Quality > languages[j].Quality
+	})
+	return &languages, nil
+}
+
+// Validate checks that the result is not nil.
+func (p *Parser) Validate(ctx context.Context, result *Languages) error {
+	if result == nil {
+		return &interfaces.ParseError{
+			Type:    interfaces.ErrorTypeValidation,
+			Message: "result cannot be nil",
+		}
+	}
+	return nil
+}
+
+// parseEntry parses a language range with its optional parameters.
+func parseEntry(entry string) (Language, error) {
+	parts := strings.Split(entry, ";")
+	tag := strings.TrimSpace(parts[0])
+	if !validTag(tag) {
+		return Language{}, fmt.Errorf("%w %q", ErrInvalidLanguage, strings.TrimSpace(entry))
+	}
+
+	language := Language{Tag: tag, Quality: 1}
+	for _, param := range parts[1:] {
+		key, value, _ := strings.Cut(param, "=")
+		if !strings.EqualFold(strings.TrimSpace(key), "q") {
+			continue
+		}
+		q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || q < 0 || q > 1 {
+			return Language{}, fmt.Errorf("%w %q: quality must be between 0 and 1", ErrInvalidLanguage, strings.TrimSpace(entry))
+		}
+		language.Quality = q
+	}
+	return language, nil
+}
+
+// validTag reports whether tag is the wildcard or a sequence of subtags of
+// 1 to 8 alphanumeric characters separated by hyphens.
+func validTag(tag string) bool {
+	if tag == Wildcard {
+		return true
+	}
+	if tag == "" {
+		return false
+	}
+	for i, subtag := range strings.Split(tag, "-") {
+		if len(subtag) == 0 || len(subtag) > 8 {
+			return false
+		}
+		for _, c := range subtag {
+			letter := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+			if !letter && (i == 0 || c < '0' || c > '9') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ParseAcceptLanguage parses an Accept-Language value, skipping malformed
+// entries.
+func ParseAcceptLanguage(input string) Languages {
+	config := interfaces.DefaultConfig()
+	config.StrictMode = false
+	languages, err := NewParserWithConfig(config).ParseString(context.Background(), input)
+	if err != nil {
+		return Languages{}
+	}
+	return *languages
+}
+
+// Negotiate returns the supported locale best matching an Accept-Language
+// value, or fallback when none matches.
+func Negotiate(header string, supported []string, fallback string) string {
+	return ParseAcceptLanguage(header).Match(supported, fallback)
+}
@@ -0,0 +1,84 @@
+package acceptlang
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/fsvxavier/nexs-lib/parsers/interfaces"
+)
+
+func TestParser_ParseString(t *testing.T) {
+	result, err := NewParser().ParseString(context.Background(), "en;q=0.8, pt-BR ,*;q=0.1,pt;q=0.9, es;Q=0.8")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := Languages{
+		{Tag: "pt-BR", Quality: 1},
+		{Tag: "pt", Quality: 0.9},
+		{Tag: "en", Quality: 0.8},
+		{Tag: "es", Quality: 0.8},
+		{Tag: "*", Quality: 0.1},
+	}
+	if !reflect.DeepEqual(*result, expected) {
+		t.Errorf("Expected %v, got %v", expected, *result)
+	}
+	if tags := result.Tags(); len(tags) != 5 || tags[0] != "pt-BR" {
+		t.Errorf("Unexpected tags %v", tags)
+	}
+
+	empty, err := NewParser().ParseString(context.Background(), "")
+	if err != nil || len(*empty) != 0 {
+		t.Errorf("Expected an empty list, got %v, %v", empty, err)
+	}
+}
+
+func TestParser_Invalid(t *testing.T) {
+	tests := []string{"pt_BR", "en;q=2", "en;q=abc", "1en", "toolongsubtag"}
+
+	for _, input := range tests {
+		t.Run(input, func(t *testing.T) {
+			_, err := NewParser().ParseString(context.Background(), input)
+			var parseErr *interfaces.ParseError
+			if !errors.As(err, &parseErr) || !errors.Is(err, ErrInvalidLanguage) {
+				t.Errorf("Expected ErrInvalidLanguage, got %v", err)
+			}
+		})
+	}
+
+	// Sem modo estrito as entradas inválidas são ignoradas
+	languages := ParseAcceptLanguage("pt_BR, en;q=2, es-419;q=0.5")
+	if len(languages) != 1 || languages[0].Tag != "es-419" {
+		t.Errorf("Expected only es-419, got %v", languages)
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	supported := []string{"en", "pt-BR", "es"}
+
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{"pt-BR,pt;q=0.9", "pt-BR"},
+		{"PT-br", "pt-BR"},
+		{"pt-PT,en;q=0.5", "pt-BR"},
+		{"pt", "pt-BR"},
+		{"fr-CA,fr;q=0.9,es;q=0.5", "es"},
+		{"de, *;q=0.5", "en"},
+		{"*, en;q=0", "pt-BR"},
+		{"de,fr", "es"},
+		{"", "es"},
+		{"en;q=0", "es"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if result := Negotiate(tt.header, supported, "es"); result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+// Package boolx provides boolean parsing with configurable alias tables, such
+// as "yes"/"no" and "on"/"off".
+package boolx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fsvxavier/nexs-lib/parsers/interfaces"
+	"github.com/fsvxavier/nexs-lib/parsers/internal"
+)
+
+// ErrInvalidBool indicates an input that is neither a truthy nor a falsy alias.
+var ErrInvalidBool = errors.New("invalid boolean")
+
+// DefaultTruthy returns the default aliases of true.
+func DefaultTruthy() []string {
+	return []string{"true", "t", "1", "yes", "y", "on", "enable", "enabled"}
+}
+
+// DefaultFalsy returns the default aliases of false.
+func DefaultFalsy() []string {
+	return []string{"false", "f", "0", "no", "n", "off", "disable", "disabled"}
+}
+
+// BoolError describes a value that could not be parsed, with suggestions.
+type BoolError struct {
+	// Input is the original input.
+	Input string
+	// Suggestions contains the closest accepted values.
+	Suggestions []string
+}
+
+// Error implements the error interface.
+func (e *BoolError) Error() string {
+	msg := fmt.Sprintf("%v: %q", ErrInvalidBool, e.Input)
+	if len(e.Suggestions) > 0 {
+		msg += " (did you mean " + strings.Join(quote(e.Suggestions), " or ") + "?)"
+	}
+	return msg
+}
+
+// Unwrap allows errors.Is(err, ErrInvalidBool).
+func (e *BoolError) Unwrap() error {
+	return ErrInvalidBool
+}
+
+// Parser implements boolean parsing with alias tables. Aliases are matched
+// case-insensitively, ignoring surrounding whitespace.
+type Parser struct {
+	config *interfaces.ParserConfig
+	values map[string]bool
+}
+
+// Option configures a Parser.
+type Option func(*Parser)
+
+// WithTruthy replaces the aliases of true.
+func WithTruthy(aliases ...string) Option {
+	return func(p *Parser) {
+		p.setAliases(true, aliases)
+	}
+}
+
+// WithFalsy replaces the aliases of false.
+func WithFalsy(aliases ...string) Option {
+	return func(p *Parser) {
+		p.setAliases(false, aliases)
+	}
+}
+
+// NewParser creates a new boolean parser with the default aliases.
+func NewParser(opts ...Option) *Parser {
+	p := &Parser{
+		config: interfaces.DefaultConfig(),
+		values: make(map[string]bool),
+	}
+	p.setAliases(true, DefaultTruthy())
+	p.setAliases(false, DefaultFalsy())
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// NewParserWithConfig creates a new boolean parser with custom configuration.
+func NewParserWithConfig(config *interfaces.ParserConfig, opts ...Option) *Parser {
+	parser := NewParser(opts...)
+	parser.config = config
+	return parser
+}
+
+// Parse implements interfaces.Parser.
+func (p *Parser) Parse(ctx context.Context, data []byte) (*bool, error) {
+	return p.ParseString(ctx, string(data))
+}
+
+// ParseString parses a boolean alias.
+func (p *Parser) ParseString(ctx context.Context, input string) (*bool, error) {
+	if p.config.MaxSize > 0 && int64(len(input)) > p.config.MaxSize {
+		return nil, &interfaces.ParseError{
+			Type:    interfaces.ErrorTypeSize,
+			Message: fmt.Sprintf("boolean length %d exceeds maximum %d", len(input), p.config.MaxSize),
+		}
+	}
+
+	key := strings.ToLower(strings.TrimSpace(input))
+	value, ok := p.values[key]
+	if !ok {
+		boolErr := &BoolError{
+			Input:       input,
+			Suggestions: internal.Closest(key, p.Aliases(), internal.DefaultMaxDistance(key)),
+		}
+		return nil, &interfaces.ParseError{
+			Type:    interfaces.ErrorTypeSyntax,
+			Message: boolErr.Error(),
+			Cause:   boolErr,
+		}
+	}
+	return &value, nil
+}
+
+// Validate implements interfaces.Parser.
+func (p *Parser) Validate(ctx context.Context, result *bool) error {
+	if result == nil {
+		return &interfaces.ParseError{
+			Type:    interfaces.ErrorTypeValidation,
+			Message: "result cannot be nil",
+		}
+	}
+	return nil
+}
+
+// Aliases returns the accepted values, sorted.
+func (p *Parser) Aliases() []string {
+	aliases := make([]string, 0, len(p.values))
+	for alias := range p.values {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+// setAliases replaces the aliases of value.
+func (p *Parser) setAliases(value bool, aliases []string) {
+	for alias, v := range p.values {
+		if v == value {
+			delete(p.values, alias)
+		}
+	}
+	for _, alias := range aliases {
+		p.values[strings.ToLower(strings.TrimSpace(alias))] = value
+	}
+}
+
+// ParseBool parses a boolean alias such as "yes", "off" or "1".
+func ParseBool(input string, opts ...Option) (bool, error) {
+	value, err := NewParser(opts...).ParseString(context.Background(), input)
+	if err != nil {
+		return false, err
+	}
+	return *value, nil
+}
+
+func quote(values []string) []string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+	return quoted
+}
@@ -0,0 +1,110 @@
+package boolx
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/fsvxavier/nexs-lib/parsers/interfaces"
+)
+
+func TestParseBool(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"true", true},
+		{"TRUE", true},
+		{"1", true},
+		{"yes", true},
+		{" Y ", true},
+		{"on", true},
+		{"Enabled", true},
+		{"false", false},
+		{"0", false},
+		{"No", false},
+		{"off", false},
+		{"disable", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			value, err := ParseBool(tt.input)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if value != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, value)
+			}
+		})
+	}
+}
+
+func TestParseBool_Invalid(t *testing.T) {
+	tests := []struct {
+		input       string
+		suggestions []string
+	}{
+		{"maybe", nil},
+		{"", nil},
+		{"tru", []string{"true"}},
+		{"yess", []string{"yes"}},
+		{"of", []string{"f", "off", "on"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := ParseBool(tt.input)
+			if !errors.Is(err, ErrInvalidBool) {
+				t.Fatalf("Expected ErrInvalidBool, got %v", err)
+			}
+			var parseErr *interfaces.ParseError
+			if !errors.As(err, &parseErr) || parseErr.Type != interfaces.ErrorTypeSyntax {
+				t.Errorf("Expected syntax ParseError, got %v", err)
+			}
+			var boolErr *BoolError
+			if !errors.As(err, &boolErr) {
+				t.Fatalf("Expected BoolError, got %v", err)
+			}
+			if len(tt.suggestions) > 0 && !reflect.DeepEqual(boolErr.Suggestions, tt.suggestions) {
+				t.Errorf("Expected suggestions %v, got %v", tt.suggestions, boolErr.Suggestions)
+			}
+			if len(tt.suggestions) == 0 && len(boolErr.Suggestions) > 0 {
+				t.Errorf("Expected no suggestions, got %v", boolErr.Suggestions)
+			}
+		})
+	}
+}
+
+func TestParser_CustomAliases(t *testing.T) {
+	parser := NewParser(
+		WithTruthy(append(DefaultTruthy(), "sim", "ligado")...),
+		WithFalsy("não", "desligado"),
+	)
+	ctx := context.Background()
+
+	for input, expected := range map[string]bool{"SIM": true, "ligado": true, "yes": true, "Não": false, "desligado": false} {
+		value, err := parser.ParseString(ctx, input)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", input, err)
+		}
+		if *value != expected {
+			t.Errorf("%s: expected %v, got %v", input, expected, *value)
+		}
+	}
+
+	if _, err := parser.ParseString(ctx, "no"); err == nil {
+		t.Error("Expected replaced falsy alias to be rejected")
+	}
+	if err := parser.Validate(ctx, nil); err == nil {
+		t.Error("Expected error for nil result")
+	}
+}
+
+func TestParser_Aliases(t *testing.T) {
+	parser := NewParser(WithTruthy("yes"), WithFalsy("no"))
+	if aliases := parser.Aliases(); !reflect.DeepEqual(aliases, []string{"no", "yes"}) {
+		t.Errorf("Unexpected aliases %v", aliases)
+	}
+}
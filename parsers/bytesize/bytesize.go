@@ -0,0 +1,322 @@
+// Package bytesize provides parsing and formatting of data sizes such as
+// "512MB" and "1.5GiB", distinguishing decimal (SI) and binary (IEC) units.
+package bytesize
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/fsvxavier/nexs-lib/parsers/interfaces"
+)
+
+// Size is a number of bytes.
+type Size int64
+
+// Decimal (SI) units.
+const (
+	Byte Size = 1
+	KB        = 1000 * Byte
+	MB        = 1000 * KB
+	GB        = 1000 * MB
+	TB        = 1000 * GB
+	PB        = 1000 * TB
+	EB        = 1000 * PB
+)
+
+// Binary (IEC) units.
+const (
+	KiB = 1024 * Byte
+	MiB = 1024 * KiB
+	GiB = 1024 * MiB
+	TiB = 1024 * GiB
+	PiB = 1024 * TiB
+	EiB = 1024 * PiB
+)
+
+// Bytes returns the size as an int64.
+func (s Size) Bytes() int64 {
+	return int64(s)
+}
+
+// String formats the size with binary units, e.g. "1.5GiB".
+func (s Size) String() string {
+	return Format(s, Binary)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s Size) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *Size) UnmarshalText(text []byte) error {
+	size, err := ParseSize(string(text))
+	if err != nil {
+		return err
+	}
+	*s = size
+	return nil
+}
+
+// UnmarshalJSON accepts sizes as strings ("512MB") or numbers of bytes.
+func (s *Size) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+		return s.UnmarshalText([]byte(text))
+	}
+	var n int64
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*s = Size(n)
+	return nil
+}
+
+// UnitSystem selects the units used when formatting.
+type UnitSystem int
+
+const (
+	// Binary formats with powers of 1024: KiB, MiB, GiB...
+	Binary UnitSystem = iota
+	// Decimal formats with powers of 1000: KB, MB, GB...
+	Decimal
+)
+
+var (
+	binaryUnits  = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+	decimalUnits = []string{"B", "KB", "MB", "GB", "TB", "PB", "EB"}
+)
+
+// Format formats the size with the largest unit of the system that keeps the
+// value at least 1, with up to two decimal places: Format(1536, Binary) is
+// "1.5KiB" and Format(1536, Decimal) is "1.54KB".
+func Format(s Size, system UnitSystem) string {
+	base, units := 1024.0, binaryUnits
+	if system == Decimal {
+		base, units = 1000.0, decimalUnits
+	}
+
+	value := float64(s)
+	sign := ""
+	if value < 0 {
+		sign, value = "-", -value
+	}
+
+	i := 0
+	for value >= base && i < len(units)-1 {
+		value /= base
+		i++
+	}
+	// Rounding may reach the next unit, e.g. 1023.999KiB
+	if math.Round(value*100)/100 >= base && i < len(units)-1 {
+		value /= base
+		i++
+	}
+	return sign + strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64) + units[i]
+}
+
+// Parser implements size parsing with bounds.
+type Parser struct {
+	config      *interfaces.ParserConfig
+	min         *Size
+	max         *Size
+	legacyUnits bool
+}
+
+// Option configures a Parser.
+type Option func(*Parser)
+
+// WithMin rejects sizes smaller than min.
+func WithMin(min Size) Option {
+	return func(p *Parser) {
+		p.min = &min
+	}
+}
+
+// WithMax rejects sizes larger than max.
+func WithMax(max Size) Option {
+	return func(p *Parser) {
+		p.max = &max
+	}
+}
+
+// WithLegacyUnits interprets KB, MB, GB... as powers of 1024, as JEDEC and
+// many older tools do. Binary units are not affected.
+func WithLegacyUnits() Option {
+	return func(p *Parser) {
+		p.legacyUnits = true
+	}
+}
+
+// NewParser creates a new size parser with default configuration.
+func NewParser(opts ...Option) *Parser {
+	p := &Parser{config: interfaces.DefaultConfig()}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// NewParserWithConfig creates a new size parser with custom configuration.
+func NewParserWithConfig(config *interfaces.ParserConfig, opts ...Option) *Parser {
+	parser := NewParser(opts...)
+	parser.config = config
+	return parser
+}
+
+// Parse implements interfaces.Parser.
+func (p *Parser) Parse(ctx context.Context, data []byte) (*Size, error) {
+	return p.ParseString(ctx, string(data))
+}
+
+// ParseString parses a size such as "512MB", "1.5 GiB", "64k" or "1024".
+// Units are case-insensitive and the trailing B is optional: "Ki", "Mi" and
+// "Gi" are binary, "K", "M" and "G" decimal. Numbers without a unit are bytes
+// and values that are not a whole number of bytes, such as "0.5B" or
+// "1.1KiB", are rejected with ErrInvalidSize.
+func (p *Parser) ParseString(ctx context.Context, input string) (*Size, error) {
+	if strings.TrimSpace(input) == "" {
+		return nil, &interfaces.ParseError{
+			Type:    interfaces.ErrorTypeValidation,
+			Message: "input size is empty",
+		}
+	}
+	if p.config.MaxSize > 0 && int64(len(input)) > p.config.MaxSize {
+		return nil, &interfaces.ParseError{
+			Type:    interfaces.ErrorTypeSize,
+			Message: fmt.Sprintf("size length %d exceeds maximum %d", len(input), p.config.MaxSize),
+		}
+	}
+
+	size, err := p.parse(strings.TrimSpace(input))
+	if err != nil {
+		return nil, &interfaces.ParseError{
+			Type:    interfaces.ErrorTypeSyntax,
+			Message: err.Error(),
+			Cause:   err,
+		}
+	}
+
+	if err := p.checkBounds(input, size); err != nil {
+		return nil, &interfaces.ParseError{
+			Type:    interfaces.ErrorTypeValidation,
+			Message: err.Error(),
+			Cause:   err,
+		}
+	}
+	return &size, nil
+}
+
+// Validate checks the size bounds.
+func (p *Parser) Validate(ctx context.Context, result *Size) error {
+	if result == nil {
+		return &interfaces.ParseError{
+			Type:    interfaces.ErrorTypeValidation,
+			Message: "result cannot be nil",
+		}
+	}
+	if err := p.checkBounds(result.String(), *result); err != nil {
+		return &interfaces.ParseError{
+			Type:    interfaces.ErrorTypeValidation,
+			Message: err.Error(),
+			Cause:   err,
+		}
+	}
+	return nil
+}
+
+// parse parses a trimmed size.
+func (p *Parser) parse(s string) (Size, error) {
+	end := strings.IndexFunc(s, func(r rune) bool {
+		return !(unicode.IsDigit(r) || r == '.' || r == '-' || r == '+')
+	})
+	if end < 0 {
+		end = len(s)
+	}
+	number, unit := s[:end], strings.TrimSpace(s[end:])
+
+	multiplier, ok := p.multiplier(unit)
+	if !ok {
+		return 0, newSizeError(s, ErrInvalidSize, unknownUnit(unit))
+	}
+
+	// Exact arithmetic avoids float rounding for large values, e.g. 8EiB-1
+	value, ok := new(big.Rat).SetString(number)
+	if !ok || number == "" {
+		return 0, newSizeError(s, ErrInvalidSize, nil)
+	}
+	if value.Sign() < 0 {
+		return 0, newSizeError(s, ErrInvalidSize, []string{"sizes cannot be negative"})
+	}
+	value.Mul(value, new(big.Rat).SetInt64(int64(multiplier)))
+	if !value.IsInt() {
+		// Fractions of a byte are rejected rather than silently truncated
+		floor := new(big.Int).Quo(value.Num(), value.Denom())
+		return 0, newSizeError(s, ErrInvalidSize, []string{
+			fmt.Sprintf("sizes must be a whole number of bytes, e.g. %q", floor.String()+"B"),
+		})
+	}
+	if !value.Num().IsInt64() {
+		return 0, newSizeError(s, ErrOutOfRange, []string{"sizes must fit in 63 bits (about 8EiB)"})
+	}
+	return Size(value.Num().Int64()), nil
+}
+
+// multiplier returns the size of a unit.
+func (p *Parser) multiplier(unit string) (Size, bool) {
+	u := strings.ToLower(unit)
+	u = strings.TrimSuffix(u, "ytes")
+	u = strings.TrimSuffix(u, "yte")
+	if u == "" || u == "b" {
+		return Byte, true
+	}
+
+	binary := strings.HasSuffix(u, "ib") || strings.HasSuffix(u, "i")
+	u = strings.TrimSuffix(strings.TrimSuffix(u, "b"), "i")
+	if len(u) != 1 {
+		return 0, false
+	}
+	exponent := strings.IndexByte("kmgtpe", u[0]) + 1
+	if exponent == 0 {
+		return 0, false
+	}
+
+	base := int64(1000)
+	if binary || p.legacyUnits {
+		base = 1024
+	}
+	size := int64(1)
+	for i := 0; i < exponent; i++ {
+		size *= base
+	}
+	return Size(size), true
+}
+
+// checkBounds verifies the configured bounds.
+func (p *Parser) checkBounds(input string, size Size) error {
+	switch {
+	case p.min != nil && size < *p.min:
+		return newSizeError(input, ErrOutOfRange, []string{"minimum is " + p.min.String()})
+	case p.max != nil && size > *p.max:
+		return newSizeError(input, ErrOutOfRange, []string{"maximum is " + p.max.String()})
+	}
+	return nil
+}
+
+// ParseSize parses a size such as "512MB" or "1.5GiB".
+func ParseSize(input string, opts ...Option) (Size, error) {
+	size, err := NewParser(opts...).ParseString(context.Background(), input)
+	if err != nil {
+		return 0, err
+	}
+	return *size, nil
+}
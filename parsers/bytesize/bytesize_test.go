@@ -0,0 +1,184 @@
+package bytesize
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fsvxavier/nexs-lib/parsers/interfaces"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Size
+	}{
+		{"1024", 1024},
+		{"0", 0},
+		{"512B", 512},
+		{"10 bytes", 10},
+		{"512MB", 512 * MB},
+		{"512mb", 512 * MB},
+		{"1.5GiB", GiB + 512*MiB},
+		{"1.5 GiB", GiB + 512*MiB},
+		{"64k", 64 * KB},
+		{"64Ki", 64 * KiB},
+		{"2TB", 2 * TB},
+		{"1PiB", PiB},
+		{"1EB", EB},
+		{"0.5KiB", 512},
+		{"1.001KB", 1001},
+		{"2.0B", 2},
+		{"+1KB", KB},
+		{".5MB", 500 * KB},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			size, err := ParseSize(tt.input)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if size != tt.expected {
+				t.Errorf("Expected %d, got %d", tt.expected, size)
+			}
+		})
+	}
+}
+
+func TestParseSize_LegacyUnits(t *testing.T) {
+	size, err := ParseSize("512MB", WithLegacyUnits())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if size != 512*MiB {
+		t.Errorf("Expected %d, got %d", 512*MiB, size)
+	}
+}
+
+func TestParseSize_Invalid(t *testing.T) {
+	tests := []struct {
+		input   string
+		target  error
+		message string
+	}{
+		{"MB", ErrInvalidSize, ""},
+		{"12parsecs", ErrInvalidSize, "valid units"},
+		{"12MiBs", ErrInvalidSize, "did you mean MiB or"},
+		{"-1KB", ErrInvalidSize, "negative"},
+		{"1.2.3MB", ErrInvalidSize, ""},
+		{"9EiB", ErrOutOfRange, "63 bits"},
+		{"0.5B", ErrInvalidSize, `whole number of bytes, e.g. "0B"`},
+		{"1.5B", ErrInvalidSize, `whole number of bytes, e.g. "1B"`},
+		{"1.0001KB", ErrInvalidSize, `"1000B"`},
+		{"1.1KiB", ErrInvalidSize, `"1126B"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := ParseSize(tt.input)
+			if !errors.Is(err, tt.target) {
+				t.Fatalf("Expected %v, got %v", tt.target, err)
+			}
+			var parseErr *interfaces.ParseError
+			if !errors.As(err, &parseErr) || parseErr.Type != interfaces.ErrorTypeSyntax {
+				t.Errorf("Expected syntax ParseError, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("Expected message to contain %q, got %q", tt.message, err.Error())
+			}
+		})
+	}
+
+	if _, err := ParseSize(" "); err == nil {
+		t.Error("Expected error for empty input")
+	}
+}
+
+func TestParser_Bounds(t *testing.T) {
+	parser := NewParser(WithMin(KiB), WithMax(10*MiB))
+	ctx := context.Background()
+
+	if _, err := parser.ParseString(ctx, "1MB"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	for _, input := range []string{"512", "11MiB"} {
+		_, err := parser.ParseString(ctx, input)
+		if !errors.Is(err, ErrOutOfRange) {
+			t.Errorf("%s: expected ErrOutOfRange, got %v", input, err)
+		}
+		var parseErr *interfaces.ParseError
+		if !errors.As(err, &parseErr) || parseErr.Type != interfaces.ErrorTypeValidation {
+			t.Errorf("%s: expected validation ParseError, got %v", input, err)
+		}
+	}
+
+	_, err := parser.ParseString(ctx, "11MiB")
+	if !strings.Contains(err.Error(), "maximum is 10MiB") {
+		t.Errorf("Unexpected message %q", err.Error())
+	}
+
+	size := 20 * MiB
+	if err := parser.Validate(ctx, &size); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("Expected ErrOutOfRange, got %v", err)
+	}
+	if err := parser.Validate(ctx, nil); err == nil {
+		t.Error("Expected error for nil result")
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		size     Size
+		system   UnitSystem
+		expected string
+	}{
+		{0, Binary, "0B"},
+		{1023, Binary, "1023B"},
+		{1536, Binary, "1.5KiB"},
+		{1536, Decimal, "1.54KB"},
+		{512 * MB, Decimal, "512MB"},
+		{GiB + 512*MiB, Binary, "1.5GiB"},
+		{MiB - 1, Binary, "1MiB"},
+		{EiB, Binary, "1EiB"},
+		{-2 * KiB, Binary, "-2KiB"},
+	}
+
+	for _, tt := range tests {
+		if got := Format(tt.size, tt.system); got != tt.expected {
+			t.Errorf("Format(%d) = %q, expected %q", tt.size, got, tt.expected)
+		}
+	}
+
+	if (1536 * Byte).String() != "1.5KiB" {
+		t.Errorf("Unexpected String() %q", (1536 * Byte).String())
+	}
+}
+
+func TestSize_JSON(t *testing.T) {
+	var cfg struct {
+		MaxPayload Size `json:"max_payload"`
+		CacheSize  Size `json:"cache_size"`
+	}
+	if err := json.Unmarshal([]byte(`{"max_payload":"4MiB","cache_size":1048576}`), &cfg); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.MaxPayload != 4*MiB || cfg.CacheSize != MiB {
+		t.Errorf("Unexpected sizes %d, %d", cfg.MaxPayload, cfg.CacheSize)
+	}
+
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(data) != `{"max_payload":"4MiB","cache_size":"1MiB"}` {
+		t.Errorf("Unexpected JSON %s", data)
+	}
+
+	if err := json.Unmarshal([]byte(`{"max_payload":"4 parsecs"}`), &cfg); !errors.Is(err, ErrInvalidSize) {
+		t.Errorf("Expected ErrInvalidSize, got %v", err)
+	}
+}
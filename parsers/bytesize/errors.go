@@ -0,0 +1,59 @@
+package bytesize
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fsvxavier/nexs-lib/parsers/internal"
+)
+
+var (
+	// ErrInvalidSize indicates an input that is not a valid size.
+	ErrInvalidSize = errors.New("invalid size")
+	// ErrOutOfRange indicates a size outside the configured bounds.
+	ErrOutOfRange = errors.New("size out of range")
+)
+
+// SizeError describes a size that could not be accepted, with suggestions
+// on how to fix it.
+type SizeError struct {
+	// Input is the original input.
+	Input string
+	// Err is ErrInvalidSize or ErrOutOfRange.
+	Err error
+	// Suggestions contains hints to fix the input.
+	Suggestions []string
+}
+
+// Error implements the error interface.
+func (e *SizeError) Error() string {
+	msg := fmt.Sprintf("%v: %q", e.Err, e.Input)
+	if len(e.Suggestions) > 0 {
+		msg += " (" + strings.Join(e.Suggestions, "; ") + ")"
+	}
+	return msg
+}
+
+// Unwrap allows errors.Is(err, ErrInvalidSize) and errors.Is(err, ErrOutOfRange).
+func (e *SizeError) Unwrap() error {
+	return e.Err
+}
+
+func newSizeError(input string, err error, suggestions []string) *SizeError {
+	return &SizeError{Input: input, Err: err, Suggestions: suggestions}
+}
+
+// unitNames are the units suggested for unknown ones.
+var unitNames = []string{
+	"B", "KB", "MB", "GB", "TB", "PB", "EB",
+	"KiB", "MiB", "GiB", "TiB", "PiB", "EiB",
+}
+
+// unknownUnit returns hints for an unknown unit.
+func unknownUnit(unit string) []string {
+	if closest := internal.Closest(unit, unitNames, internal.DefaultMaxDistance(unit)); len(closest) > 0 {
+		return []string{fmt.Sprintf("unknown unit %q, did you mean %s?", unit, strings.Join(closest, " or "))}
+	}
+	return []string{fmt.Sprintf("unknown unit %q, valid units: %s", unit, strings.Join(unitNames, ", "))}
+}
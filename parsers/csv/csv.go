@@ -0,0 +1,320 @@
+// Package csv provides streaming parsing of delimited files (CSV, TSV) into
+// typed structs, validated with the validation/validator struct tags and
+// with per-row error aggregation.
+package csv
+
+import (
+	"bytes"
+	"context"
+	stdcsv "encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/fsvxavier/nexs-lib/parsers/interfaces"
+	"github.com/fsvxavier/nexs-lib/validation/validator"
+)
+
+// Parser parses delimited rows into values of the struct type T. Columns are
+// matched by header name (case-insensitive) and cells are converted by the
+// field type; the validate tags of T are then checked on each row. Rows that
+// fail are skipped and reported in Errors.
+type Parser[T any] struct {
+	config *interfaces.ParserConfig
+	options
+}
+
+// Option configures a Parser.
+type Option func(*options)
+
+type options struct {
+	delimiter    rune
+	comment      rune
+	headers      []string
+	trimSpace    bool
+	maxErrors    int
+	maxErrorRate float64
+	validator    *validator.Validator
+}
+
+// WithDelimiter sets the field delimiter. The default is ','.
+func WithDelimiter(delimiter rune) Option {
+	return func(o *options) {
+		o.delimiter = delimiter
+	}
+}
+
+// WithTSV parses tab-separated values.
+func WithTSV() Option {
+	return WithDelimiter('\t')
+}
+
+// WithComment ignores lines starting with the comment character.
+func WithComment(comment rune) Option {
+	return func(o *options) {
+		o.comment = comment
+	}
+}
+
+// WithHeaders sets the column names for input without a header row.
+func WithHeaders(headers ...string) Option {
+	return func(o *options) {
+		o.headers = headers
+	}
+}
+
+// WithTrimSpace trims the surrounding whitespace of all cells, including
+// string fields. Other field types are always trimmed.
+func WithTrimSpace() Option {
+	return func(o *options) {
+		o.trimSpace = true
+	}
+}
+
+// WithMaxErrors tolerates up to max invalid rows. Parsing stops with
+// ErrTooManyErrors when the limit is exceeded.
+func WithMaxErrors(max int) Option {
+	return func(o *options) {
+		o.maxErrors = max
+	}
+}
+
+// WithMaxErrorRate tolerates invalid rows up to the rate (0 to 1) of the
+// rows read. The rate is checked at the end of the input, after the valid
+// rows were delivered.
+func WithMaxErrorRate(rate float64) Option {
+	return func(o *options) {
+		o.maxErrorRate = rate
+	}
+}
+
+// WithValidator sets the validator of the rows, such as one with custom
+// messages. The default is validator.New().
+func WithValidator(v *validator.Validator) Option {
+	return func(o *options) {
+		o.validator = v
+	}
+}
+
+// NewParser creates a parser for the struct type T. Without WithMaxErrors or
+// WithMaxErrorRate, the first invalid row stops parsing.
+func NewParser[T any](opts ...Option) *Parser[T] {
+	o := options{delimiter: ',', maxErrors: -1, maxErrorRate: -1}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.maxErrors < 0 && o.maxErrorRate < 0 {
+		o.maxErrors = 0
+	}
+	if o.validator == nil {
+		o.validator = validator.New()
+	}
+
+	return &Parser[T]{
+		config:  interfaces.DefaultConfig(),
+		options: o,
+	}
+}
+
+// NewParserWithConfig creates a parser with custom configuration. In strict
+// mode, a mapped column missing from the header fails the parse.
+func NewParserWithConfig[T any](config *interfaces.ParserConfig, opts ...Option) *Parser[T] {
+	parser := NewParser[T](opts...)
+	parser.config = config
+	return parser
+}
+
+// ParseAll parses all rows of data. The valid rows are returned even when
+// the error is *Errors.
+func (p *Parser[T]) ParseAll(ctx context.Context, data []byte) ([]T, error) {
+	if p.config.MaxSize > 0 && int64(len(data)) > p.config.MaxSize {
+		return nil, &interfaces.ParseError{
+			Type:    interfaces.ErrorTypeSize,
+			Message: fmt.Sprintf("csv size %d exceeds maximum %d", len(data), p.config.MaxSize),
+		}
+	}
+
+	var rows []T
+	err := p.ParseStream(ctx, bytes.NewReader(data), func(row *T) error {
+		rows = append(rows, *row)
+		return nil
+	})
+	return rows, err
+}
+
+// ParseStream implements interfaces.StreamParser, reading the rows one at a
+// time and calling callback with each valid row. Invalid rows are skipped
+// and returned as *Errors; within the tolerance, parsing continues until the
+// end of the input. Errors of the callback and of the reader stop parsing
+// and are returned as is.
+func (p *Parser[T]) ParseStream(ctx context.Context, reader io.Reader, callback func(*T) error) error {
+	columns, err := compileColumns(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return err
+	}
+
+	r := stdcsv.NewReader(reader)
+	r.Comma = p.delimiter
+	r.Comment = p.comment
+	r.FieldsPerRecord = -1
+
+	headers := p.headers
+	if len(headers) == 0 {
+		if headers, err = r.Read(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return &interfaces.ParseError{Type: interfaces.ErrorTypeSyntax, Message: "invalid csv header", Cause: err}
+		}
+	}
+
+	indexes, err := p.columnIndexes(columns, headers)
+	if err != nil {
+		return err
+	}
+
+	report := &Errors{}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		report.Rows++
+		var parseErr *stdcsv.ParseError
+		if errors.As(err, &parseErr) {
+			if p.reject(report, []*RowError{{
+				Row: report.Rows, Line: parseErr.StartLine, Reason: parseErr.Err.Error(), Err: err,
+			}}) {
+				return report
+			}
+			continue
+		} else if err != nil {
+			return &interfaces.ParseError{Type: interfaces.ErrorTypeIO, Message: "failed to read csv", Cause: err}
+		}
+
+		line, _ := r.FieldPos(0)
+		value := new(T)
+		rowErrs, err := p.decode(value, record, columns, indexes, report.Rows, line)
+		if err != nil {
+			return err
+		}
+		if len(rowErrs) > 0 {
+			if p.reject(report, rowErrs) {
+				return report
+			}
+			continue
+		}
+
+		if err := callback(value); err != nil {
+			return err
+		}
+	}
+
+	if p.maxErrorRate >= 0 && report.Rows > 0 && float64(report.Invalid)/float64(report.Rows) > p.maxErrorRate {
+		report.Exceeded = true
+	}
+	if report.Invalid > 0 {
+		return report
+	}
+	return nil
+}
+
+// columnIndexes returns the position of each column in the header, or -1
+// for columns absent from a non-strict parse.
+func (p *Parser[T]) columnIndexes(columns []column, headers []string) ([]int, error) {
+	positions := make(map[string]int, len(headers))
+	for i, header := range headers {
+		if i == 0 {
+			header = strings.TrimPrefix(header, "\ufeff")
+		}
+		positions[strings.ToLower(strings.TrimSpace(header))] = i
+	}
+
+	indexes := make([]int, len(columns))
+	for i, col := range columns {
+		position, ok := positions[strings.ToLower(col.header)]
+		if !ok {
+			if p.config.StrictMode {
+				return nil, fmt.Errorf("%w: %q", ErrMissingColumn, col.header)
+			}
+			position = -1
+		}
+		indexes[i] = position
+	}
+	return indexes, nil
+}
+
+// decode converts the record into value and validates it, returning the
+// row errors. The error is returned only for failures unrelated to the row.
+func (p *Parser[T]) decode(value *T, record []string, columns []column, indexes []int, row, line int) ([]*RowError, error) {
+	var rowErrs []*RowError
+	sv := reflect.ValueOf(value).Elem()
+	cells := make(map[string]string, len(columns))
+
+	for i, col := range columns {
+		if indexes[i] < 0 {
+			continue
+		}
+		if indexes[i] >= len(record) {
+			rowErrs = append(rowErrs, &RowError{Row: row, Line: line, Column: col.header, Reason: "missing cell"})
+			continue
+		}
+
+		cell := record[indexes[i]]
+		if p.trimSpace {
+			cell = strings.TrimSpace(cell)
+		}
+		cells[col.name] = cell
+		if !col.keepEmpty && strings.TrimSpace(cell) == "" {
+			continue
+		}
+		if err := col.set(sv.Field(col.field), cell); err != nil {
+			rowErrs = append(rowErrs, &RowError{
+				Row: row, Line: line, Column: col.header, Value: cell, Reason: err.Error(), Err: err,
+			})
+		}
+	}
+	if len(rowErrs) > 0 {
+		return rowErrs, nil
+	}
+
+	err := p.validator.ValidateStruct(value)
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return nil, err
+	}
+
+	headers := make(map[string]string, len(columns))
+	for _, col := range columns {
+		headers[col.name] = col.header
+	}
+	for _, fieldErr := range fieldErrs {
+		name, _, _ := strings.Cut(fieldErr.Field, ".")
+		name, _, _ = strings.Cut(name, "[")
+		header, ok := headers[name]
+		if !ok {
+			header = fieldErr.Field
+		}
+		rowErrs = append(rowErrs, &RowError{
+			Row: row, Line: line, Column: header, Value: cells[name], Reason: fieldErr.Message, Err: fieldErr,
+		})
+	}
+	return rowErrs, nil
+}
+
+// reject records an invalid row, reporting whether the tolerance was exceeded.
+func (p *Parser[T]) reject(report *Errors, rowErrs []*RowError) bool {
+	report.Errors = append(report.Errors, rowErrs...)
+	report.Invalid++
+	if p.maxErrors >= 0 && report.Invalid > p.maxErrors {
+		report.Exceeded = true
+	}
+	return report.Exceeded
+}
@@ -0,0 +1,205 @@
+package csv
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/parsers/interfaces"
+)
+
+type employee struct {
+	Name     string     `csv:"name" validate:"required,min_length=2"`
+	Email    string     `csv:"email" validate:"email"`
+	Salary   float64    `csv:"salary" validate:"min=0"`
+	Active   bool       `csv:"active"`
+	Hired    time.Time  `csv:"hired" layout:"02/01/2006"`
+	Manager  *int       `csv:"manager_id"`
+	Internal string     `csv:"-"`
+	Notice   *time.Time `csv:"notice"`
+}
+
+const employees = `name,email,salary,active,hired,manager_id,notice
+John Doe,john@example.com,7500.50,yes,10/01/2024,7,2025-06-30
+Jane Smith,jane@example.com,8500,no,01/02/2023,,
+`
+
+func TestParseAll(t *testing.T) {
+	rows, err := NewParser[employee]().ParseAll(context.Background(), []byte(employees))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("Expected 2 rows, got %d", len(rows))
+	}
+
+	john := rows[0]
+	if john.Name != "John Doe" || john.Salary != 7500.50 || !john.Active || john.Manager == nil || *john.Manager != 7 {
+		t.Errorf("Unexpected row %+v", john)
+	}
+	if !john.Hired.Equal(time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)) || john.Notice == nil {
+		t.Errorf("Unexpected dates %v, %v", john.Hired, john.Notice)
+	}
+	if rows[1].Active || rows[1].Manager != nil || rows[1].Notice != nil {
+		t.Errorf("Unexpected row %+v", rows[1])
+	}
+}
+
+const invalidEmployees = `name,email,salary,active,hired,manager_id,notice
+John Doe,john@example.com,7500,yes,10/01/2024,,
+J,not-an-email,-1,yes,10/01/2024,,
+Jane Smith,jane@example.com,abc,maybe,2024-01-10,,
+Ann Lee,ann@example.com,9000,no,10/01/2024,,
+`
+
+func TestParseStream_RowErrors(t *testing.T) {
+	var names []string
+	err := NewParser[employee](WithMaxErrors(5)).ParseStream(context.Background(), strings.NewReader(invalidEmployees),
+		func(e *employee) error {
+			names = append(names, e.Name)
+			return nil
+		})
+
+	var report *Errors
+	if !errors.As(err, &report) {
+		t.Fatalf("Expected *Errors, got %v", err)
+	}
+	if !errors.Is(err, ErrInvalidRows) || errors.Is(err, ErrTooManyErrors) {
+		t.Errorf("Unexpected error chain: %v", err)
+	}
+	if strings.Join(names, ",") != "John Doe,Ann Lee" {
+		t.Errorf("Expected the valid rows delivered, got %v", names)
+	}
+	if report.Rows != 4 || report.Invalid != 2 {
+		t.Errorf("Expected 2 of 4 invalid rows, got %d of %d", report.Invalid, report.Rows)
+	}
+
+	// Erros de validação usam o nome da coluna e a mensagem do validator
+	validation := report.ByRow(2)
+	if len(validation) != 3 {
+		t.Fatalf("Expected 3 errors in row 2, got %v", validation)
+	}
+	if first := validation[0]; first.Column != "name" || first.Value != "J" || first.Line != 3 ||
+		first.Reason != "must have at least 2 characters" {
+		t.Errorf("Unexpected error %+v", first)
+	}
+
+	// Erros de conversão são reportados por célula
+	conversion := report.ByRow(3)
+	columns := make([]string, len(conversion))
+	for i, rowErr := range conversion {
+		columns[i] = rowErr.Column
+	}
+	if strings.Join(columns, ",") != "salary,active,hired" {
+		t.Errorf("Unexpected columns %v", columns)
+	}
+	if !strings.Contains(conversion[0].Error(), `row 3 (line 4), column "salary": invalid number "abc"`) {
+		t.Errorf("Unexpected message %q", conversion[0].Error())
+	}
+}
+
+func TestParseStream_Tolerance(t *testing.T) {
+	ctx := context.Background()
+
+	// Sem tolerância a primeira linha inválida interrompe o parse
+	rows, err := NewParser[employee]().ParseAll(ctx, []byte(invalidEmployees))
+	var report *Errors
+	if !errors.As(err, &report) || !errors.Is(err, ErrTooManyErrors) {
+		t.Fatalf("Expected ErrTooManyErrors, got %v", err)
+	}
+	if len(rows) != 1 || report.Rows != 2 {
+		t.Errorf("Expected parsing stopped at row 2, got %d rows read", report.Rows)
+	}
+
+	rows, err = NewParser[employee](WithMaxErrors(1)).ParseAll(ctx, []byte(invalidEmployees))
+	if !errors.Is(err, ErrTooManyErrors) || len(rows) != 1 {
+		t.Errorf("Expected ErrTooManyErrors after 2 invalid rows, got %v", err)
+	}
+
+	// A taxa é verificada no fim da entrada
+	rows, err = NewParser[employee](WithMaxErrorRate(0.25)).ParseAll(ctx, []byte(invalidEmployees))
+	if !errors.Is(err, ErrTooManyErrors) || len(rows) != 2 {
+		t.Errorf("Expected ErrTooManyErrors for a 50%% rate, got %v", err)
+	}
+
+	_, err = NewParser[employee](WithMaxErrorRate(0.5)).ParseAll(ctx, []byte(invalidEmployees))
+	if !errors.Is(err, ErrInvalidRows) || errors.Is(err, ErrTooManyErrors) {
+		t.Errorf("Expected the rate tolerated, got %v", err)
+	}
+}
+
+type record struct {
+	ID    int64         `json:"id"`
+	Label string        `json:"label"`
+	TTL   time.Duration `json:"ttl"`
+}
+
+func TestParseStream_TSVWithoutHeader(t *testing.T) {
+	data := "# exported\n1\t first \t5m\n2\tsecond\t1h\n"
+	rows, err := NewParser[record](WithTSV(), WithComment('#'), WithTrimSpace(), WithHeaders("id", "label", "ttl")).
+		ParseAll(context.Background(), []byte(data))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(rows) != 2 || rows[0].Label != "first" || rows[1].TTL != time.Hour {
+		t.Errorf("Unexpected rows %+v", rows)
+	}
+}
+
+func TestParseStream_Columns(t *testing.T) {
+	ctx := context.Background()
+
+	// Cabeçalhos ignoram caixa, espaços e BOM; colunas extras são ignoradas
+	rows, err := NewParser[record]().ParseAll(ctx, []byte("\ufeffID, Label ,TTL,extra\n1,a,1s,x\n"))
+	if err != nil || len(rows) != 1 || rows[0].ID != 1 {
+		t.Fatalf("Unexpected result %+v, %v", rows, err)
+	}
+
+	_, err = NewParser[record]().ParseAll(ctx, []byte("id,label\n1,a\n"))
+	if !errors.Is(err, ErrMissingColumn) {
+		t.Errorf("Expected ErrMissingColumn, got %v", err)
+	}
+
+	config := interfaces.DefaultConfig()
+	config.StrictMode = false
+	rows, err = NewParserWithConfig[record](config).ParseAll(ctx, []byte("id,label\n1,a\n2\n"))
+	var report *Errors
+	if !errors.As(err, &report) || report.Errors[0].Reason != "missing cell" || len(rows) != 1 {
+		t.Errorf("Expected a missing cell error, got %+v, %v", rows, err)
+	}
+
+	// Erros de sintaxe rejeitam somente a linha
+	rows, err = NewParser[record](WithMaxErrors(1)).ParseAll(ctx, []byte("id,label,ttl\n1,a\"b,1s\n2,ok,1s\n"))
+	if !errors.As(err, &report) || report.Errors[0].Line != 2 || len(rows) != 1 || rows[0].ID != 2 {
+		t.Errorf("Expected the malformed row rejected, got %+v, %v", rows, err)
+	}
+
+	if _, err = NewParser[chan int]().ParseAll(ctx, []byte("a\n1\n")); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("Expected ErrUnsupportedType, got %v", err)
+	}
+}
+
+func TestParseStream_StopsOnCallbackAndContext(t *testing.T) {
+	stop := errors.New("stop")
+	err := NewParser[employee]().ParseStream(context.Background(), strings.NewReader(employees),
+		func(*employee) error { return stop })
+	if !errors.Is(err, stop) {
+		t.Errorf("Expected the callback error, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewParser[employee]().ParseAll(ctx, []byte(employees)); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	config := interfaces.DefaultConfig()
+	config.MaxSize = 10
+	_, err = NewParserWithConfig[employee](config).ParseAll(context.Background(), []byte(employees))
+	var parseErr *interfaces.ParseError
+	if !errors.As(err, &parseErr) || parseErr.Type != interfaces.ErrorTypeSize {
+		t.Errorf("Expected a size error, got %v", err)
+	}
+}
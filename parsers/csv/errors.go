@@ -0,0 +1,100 @@
+package csv
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrInvalidRows indicates that some rows could not be parsed or validated.
+	ErrInvalidRows = errors.New("invalid csv rows")
+	// ErrTooManyErrors indicates that the invalid rows exceeded the tolerance.
+	ErrTooManyErrors = errors.New("too many invalid csv rows")
+	// ErrMissingColumn indicates a mapped column absent from the header.
+	ErrMissingColumn = errors.New("missing csv column")
+	// ErrUnsupportedType indicates a struct field that cannot be parsed from a cell.
+	ErrUnsupportedType = errors.New("unsupported csv field type")
+)
+
+// maxReportedErrors limits the row errors listed by Errors.Error.
+const maxReportedErrors = 3
+
+// RowError describes why a row was rejected.
+type RowError struct {
+	// Row is the data row number, starting at 1 and excluding the header.
+	Row int
+	// Line is the input line where the row starts.
+	Line int
+	// Column is the column header, empty for errors about the whole row.
+	Column string
+	// Value is the cell content.
+	Value string
+	// Reason describes the failure.
+	Reason string
+	// Err is the underlying conversion or validation error.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *RowError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("row %d (line %d): %s", e.Row, e.Line, e.Reason)
+	}
+	return fmt.Sprintf("row %d (line %d), column %q: %s", e.Row, e.Line, e.Column, e.Reason)
+}
+
+// Unwrap returns the underlying error.
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// Errors aggregates the errors of the rejected rows of a parse.
+type Errors struct {
+	// Errors lists the failures in input order; a row may fail in several columns.
+	Errors []*RowError
+	// Rows is the number of data rows read.
+	Rows int
+	// Invalid is the number of rejected rows.
+	Invalid int
+	// Exceeded reports whether the invalid rows exceeded the tolerance.
+	Exceeded bool
+}
+
+// Error implements the error interface.
+func (e *Errors) Error() string {
+	msgs := make([]string, 0, maxReportedErrors+1)
+	for i, err := range e.Errors {
+		if i == maxReportedErrors {
+			msgs = append(msgs, fmt.Sprintf("and %d more", len(e.Errors)-maxReportedErrors))
+			break
+		}
+		msgs = append(msgs, err.Error())
+	}
+
+	cause := ErrInvalidRows
+	if e.Exceeded {
+		cause = ErrTooManyErrors
+	}
+	return fmt.Sprintf("%v: %d of %d rows rejected: %s", cause, e.Invalid, e.Rows, strings.Join(msgs, "; "))
+}
+
+// Unwrap allows errors.Is(err, ErrInvalidRows) and, when the tolerance was
+// exceeded, errors.Is(err, ErrTooManyErrors).
+func (e *Errors) Unwrap() []error {
+	if e.Exceeded {
+		return []error{ErrInvalidRows, ErrTooManyErrors}
+	}
+	return []error{ErrInvalidRows}
+}
+
+// ByRow returns the errors of a data row.
+func (e *Errors) ByRow(row int) []*RowError {
+	var result []*RowError
+	for _, err := range e.Errors {
+		if err.Row == row {
+			result = append(result, err)
+		}
+	}
+	return result
+}
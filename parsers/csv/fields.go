@@ -0,0 +1,187 @@
+package csv
+
+import (
+	"context"
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/parsers/boolx"
+)
+
+// setter parses a cell into a struct field.
+type setter func(field reflect.Value, cell string) error
+
+// column maps a struct field to a column of the input.
+type column struct {
+	// header is the column name matched against the header row.
+	header string
+	// field is the struct field index.
+	field int
+	// name is the field name used by the validator in its errors.
+	name string
+	// keepEmpty sets empty cells; other fields keep their zero value.
+	keepEmpty bool
+	set       setter
+}
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+)
+
+// boolParser parses boolean cells, accepting aliases like "yes" and "on".
+var boolParser = boolx.NewParser()
+
+// compileColumns maps the exported fields of the struct type t to columns.
+// The column name comes from the csv tag, then the json tag, then the field
+// name; "-" skips the field. Time fields accept a layout tag.
+func compileColumns(t reflect.Type) ([]column, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %s is not a struct", ErrUnsupportedType, t)
+	}
+
+	columns := make([]column, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		name := sf.Name
+		if jsonName, _, _ := strings.Cut(sf.Tag.Get("json"), ","); jsonName != "" && jsonName != "-" {
+			name = jsonName
+		}
+		header := name
+		if tag, _, _ := strings.Cut(sf.Tag.Get("csv"), ","); tag == "-" {
+			continue
+		} else if tag != "" {
+			header = tag
+		}
+
+		set, err := newSetter(sf.Type, sf.Tag.Get("layout"))
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", sf.Name, err)
+		}
+		columns = append(columns, column{
+			header:    header,
+			field:     i,
+			name:      name,
+			keepEmpty: sf.Type.Kind() == reflect.String,
+			set:       set,
+		})
+	}
+	return columns, nil
+}
+
+// newSetter returns the setter for a field type.
+func newSetter(t reflect.Type, layout string) (setter, error) {
+	if t.Kind() == reflect.Ptr {
+		elem, err := newSetter(t.Elem(), layout)
+		if err != nil {
+			return nil, err
+		}
+		return func(field reflect.Value, cell string) error {
+			if strings.TrimSpace(cell) == "" {
+				return nil
+			}
+			value := reflect.New(t.Elem())
+			if err := elem(value.Elem(), cell); err != nil {
+				return err
+			}
+			field.Set(value)
+			return nil
+		}, nil
+	}
+
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) && t != timeType {
+		return func(field reflect.Value, cell string) error {
+			if err := field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(cell)); err != nil {
+				return fmt.Errorf("invalid %s %q: %w", t, cell, err)
+			}
+			return nil
+		}, nil
+	}
+
+	switch t {
+	case timeType:
+		return timeSetter(layout), nil
+	case durationType:
+		return func(field reflect.Value, cell string) error {
+			d, err := time.ParseDuration(strings.TrimSpace(cell))
+			if err != nil {
+				return fmt.Errorf("invalid duration %q", cell)
+			}
+			field.SetInt(int64(d))
+			return nil
+		}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return func(field reflect.Value, cell string) error {
+			field.SetString(cell)
+			return nil
+		}, nil
+	case reflect.Bool:
+		return func(field reflect.Value, cell string) error {
+			value, err := boolParser.ParseString(context.Background(), cell)
+			if err != nil {
+				return fmt.Errorf("invalid boolean %q", cell)
+			}
+			field.SetBool(*value)
+			return nil
+		}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(field reflect.Value, cell string) error {
+			n, err := strconv.ParseInt(strings.TrimSpace(cell), 10, t.Bits())
+			if err != nil {
+				return fmt.Errorf("invalid integer %q: %w", cell, err.(*strconv.NumError).Err)
+			}
+			field.SetInt(n)
+			return nil
+		}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return func(field reflect.Value, cell string) error {
+			n, err := strconv.ParseUint(strings.TrimSpace(cell), 10, t.Bits())
+			if err != nil {
+				return fmt.Errorf("invalid unsigned integer %q: %w", cell, err.(*strconv.NumError).Err)
+			}
+			field.SetUint(n)
+			return nil
+		}, nil
+	case reflect.Float32, reflect.Float64:
+		return func(field reflect.Value, cell string) error {
+			n, err := strconv.ParseFloat(strings.TrimSpace(cell), t.Bits())
+			if err != nil {
+				return fmt.Errorf("invalid number %q: %w", cell, err.(*strconv.NumError).Err)
+			}
+			field.SetFloat(n)
+			return nil
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, t)
+}
+
+// timeSetter parses times with the layout, or RFC 3339 and dates by default.
+func timeSetter(layout string) setter {
+	layouts := []string{time.RFC3339, time.DateOnly}
+	if layout != "" {
+		layouts = []string{layout}
+	}
+	return func(field reflect.Value, cell string) error {
+		cell = strings.TrimSpace(cell)
+		for _, layout := range layouts {
+			if t, err := time.Parse(layout, cell); err == nil {
+				field.Set(reflect.ValueOf(t))
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid time %q, expected layout %s", cell, layouts[0])
+	}
+}
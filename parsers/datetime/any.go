@@ -0,0 +1,213 @@
+package datetime
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Pseudo-layouts for Unix epoch timestamps, accepted in format lists
+const (
+	// LayoutUnix parses the input as Unix epoch seconds
+	LayoutUnix = "unix"
+	// LayoutUnixMilli parses the input as Unix epoch milliseconds
+	LayoutUnixMilli = "unix_milli"
+)
+
+var (
+	// ErrUnrecognizedFormat is wrapped by FormatError when no layout matches the input
+	ErrUnrecognizedFormat = errors.New("unrecognized datetime format")
+	// ErrInvalidTimezone indicates a timezone name that could not be loaded
+	ErrInvalidTimezone = errors.New("invalid timezone")
+)
+
+// FormatError describes an input that could not be parsed, with the layouts
+// tried and suggestions on how to fix it
+type FormatError struct {
+	// Input is the original input
+	Input string
+	// Formats lists the layouts tried, when a format list was configured
+	Formats []string
+	// Suggestions contains hints to fix the input
+	Suggestions []string
+}
+
+// Error implements the error interface
+func (e *FormatError) Error() string {
+	msg := fmt.Sprintf("unable to parse date: %q", e.Input)
+	if len(e.Suggestions) > 0 {
+		msg += " (" + strings.Join(e.Suggestions, "; ") + ")"
+	}
+	return msg
+}
+
+// Unwrap allows errors.Is(err, ErrUnrecognizedFormat)
+func (e *FormatError) Unwrap() error {
+	return ErrUnrecognizedFormat
+}
+
+// DefaultFormats returns the ordered format list used as a starting point for
+// WithFormats: RFC 3339, ISO 8601 dates, day-first and month-first locale
+// formats and Unix epoch seconds/milliseconds. Day-first layouts come before
+// month-first ones, so ambiguous dates such as 02/03/2024 are read as 2 March
+func DefaultFormats() []string {
+	return []string{
+		time.RFC3339Nano,
+		time.RFC3339,
+		"2006-01-02T15:04:05",
+		"2006-01-02T15:04",
+		"2006-01-02 15:04:05",
+		"2006-01-02 15:04",
+		time.DateOnly,
+		"2006/01/02",
+		"02/01/2006 15:04:05",
+		"02/01/2006 15:04",
+		"02/01/2006",
+		"02.01.2006 15:04:05",
+		"02.01.2006",
+		"02-01-2006",
+		"01/02/2006 3:04:05 PM",
+		"01/02/2006 15:04:05",
+		"01/02/2006",
+		"Jan 2, 2006 15:04:05",
+		"Jan 2, 2006",
+		"January 2, 2006",
+		"2 Jan 2006",
+		time.RFC1123Z,
+		time.RFC1123,
+		LayoutUnixMilli,
+		LayoutUnix,
+	}
+}
+
+// WithFormats restricts parsing to the given layouts, tried in order. Layouts
+// may include LayoutUnix and LayoutUnixMilli
+func WithFormats(layouts ...string) ParserOption {
+	return func(p *compatibilityParser) error {
+		if len(layouts) == 0 {
+			return errors.New("datetime: format list is empty")
+		}
+		p.formats = append([]string(nil), layouts...)
+		return nil
+	}
+}
+
+// WithLocation sets the location used for inputs without a timezone
+func WithLocation(loc *time.Location) ParserOption {
+	return func(p *compatibilityParser) error {
+		if loc == nil {
+			return fmt.Errorf("datetime: location is nil: %w", ErrInvalidTimezone)
+		}
+		p.loc = loc
+		return nil
+	}
+}
+
+// WithTimezone sets, by IANA name, the location used for inputs without a timezone
+func WithTimezone(name string) ParserOption {
+	return func(p *compatibilityParser) error {
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			return fmt.Errorf("datetime: %w %q: %v", ErrInvalidTimezone, name, err)
+		}
+		p.loc = loc
+		return nil
+	}
+}
+
+// WithOutputLocation converts the parsed time to the given location
+func WithOutputLocation(loc *time.Location) ParserOption {
+	return func(p *compatibilityParser) error {
+		if loc == nil {
+			return fmt.Errorf("datetime: output location is nil: %w", ErrInvalidTimezone)
+		}
+		p.outputLoc = loc
+		return nil
+	}
+}
+
+// parseFormats parses the input using only the configured format list
+func (p *compatibilityParser) parseFormats() error {
+	input := strings.TrimSpace(p.datestr)
+	for _, layout := range p.formats {
+		t, ok := parseLayout(layout, input, p.loc)
+		if ok {
+			p.t = t
+			p.format = []byte(layout)
+			return nil
+		}
+	}
+	return newFormatError(p.datestr, p.formats)
+}
+
+// parseLayout parses the input with a Go layout or an epoch pseudo-layout
+func parseLayout(layout, input string, loc *time.Location) (time.Time, bool) {
+	switch layout {
+	case LayoutUnix, LayoutUnixMilli:
+		if len(input) == 0 || len(input) > 19 || strings.Trim(input, "0123456789") != "" {
+			return time.Time{}, false
+		}
+		n, err := strconv.ParseInt(input, 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		// Seconds have up to 10 digits and milliseconds 13, until the year 2286
+		if layout == LayoutUnix && len(input) <= 10 {
+			return time.Unix(n, 0).In(loc), true
+		}
+		if layout == LayoutUnixMilli && len(input) > 10 && len(input) <= 13 {
+			return time.UnixMilli(n).In(loc), true
+		}
+		return time.Time{}, false
+	default:
+		t, err := time.ParseInLocation(layout, input, loc)
+		return t, err == nil
+	}
+}
+
+// hasZone reports whether the layout carries timezone information
+func hasZone(layout string) bool {
+	return layout == LayoutUnix || layout == LayoutUnixMilli ||
+		strings.Contains(layout, "Z") || strings.Contains(layout, "MST") || strings.Contains(layout, "-07")
+}
+
+var (
+	ambiguousDatePattern = regexp.MustCompile(`^\d{1,2}[/.-]\d{1,2}[/.-]\d{2,4}`)
+	isoDatePattern       = regexp.MustCompile(`^\d{4}-\d{1,2}-\d{1,2}`)
+)
+
+// newFormatError builds the error with suggestions based on the input shape
+func newFormatError(input string, formats []string) *FormatError {
+	trimmed := strings.TrimSpace(input)
+	var suggestions []string
+
+	switch {
+	case trimmed == "":
+		suggestions = append(suggestions, "input is empty")
+	case strings.Trim(trimmed, "0123456789") == "":
+		if len(trimmed) != 10 && len(trimmed) != 13 {
+			suggestions = append(suggestions, "Unix timestamps must have 10 digits (seconds) or 13 digits (milliseconds)")
+		} else if formats != nil {
+			suggestions = append(suggestions, "add LayoutUnix or LayoutUnixMilli to the format list")
+		}
+	case ambiguousDatePattern.MatchString(trimmed):
+		suggestions = append(suggestions, "check the day/month order or use ISO 8601 (2006-01-02)")
+	case isoDatePattern.MatchString(trimmed):
+		suggestions = append(suggestions, "check the date values and the time separator (2006-01-02T15:04:05Z07:00)")
+	}
+
+	if formats != nil {
+		suggestions = append(suggestions, "expected one of: "+strings.Join(formats, ", "))
+	} else if trimmed != "" {
+		suggestions = append(suggestions, "use RFC 3339 (2006-01-02T15:04:05Z07:00)")
+	}
+
+	return &FormatError{
+		Input:       input,
+		Formats:     formats,
+		Suggestions: suggestions,
+	}
+}
@@ -0,0 +1,153 @@
+package datetime
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAnyWithFormats(t *testing.T) {
+	saoPaulo, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		t.Skipf("timezone data not available: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		input    string
+		opts     []ParserOption
+		expected time.Time
+		layout   string
+	}{
+		{
+			name:     "RFC3339",
+			input:    "2024-03-02T10:30:00Z",
+			opts:     []ParserOption{WithFormats(DefaultFormats()...)},
+			expected: time.Date(2024, 3, 2, 10, 30, 0, 0, time.UTC),
+			layout:   time.RFC3339Nano,
+		},
+		{
+			name:     "day first wins in default order",
+			input:    "02/03/2024",
+			opts:     []ParserOption{WithFormats(DefaultFormats()...)},
+			expected: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+			layout:   "02/01/2006",
+		},
+		{
+			name:     "custom order",
+			input:    "02/03/2024",
+			opts:     []ParserOption{WithFormats("01/02/2006", "02/01/2006")},
+			expected: time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC),
+			layout:   "01/02/2006",
+		},
+		{
+			name:     "epoch seconds",
+			input:    "1709375400",
+			opts:     []ParserOption{WithFormats(DefaultFormats()...)},
+			expected: time.Date(2024, 3, 2, 10, 30, 0, 0, time.UTC),
+			layout:   LayoutUnix,
+		},
+		{
+			name:     "epoch milliseconds",
+			input:    "1709375400123",
+			opts:     []ParserOption{WithFormats(DefaultFormats()...)},
+			expected: time.Date(2024, 3, 2, 10, 30, 0, 123e6, time.UTC),
+			layout:   LayoutUnixMilli,
+		},
+		{
+			name:     "timezone for inputs without offset",
+			input:    "2024-03-02 10:30:00",
+			opts:     []ParserOption{WithFormats(DefaultFormats()...), WithTimezone("America/Sao_Paulo")},
+			expected: time.Date(2024, 3, 2, 10, 30, 0, 0, saoPaulo),
+			layout:   "2006-01-02 15:04:05",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := ParseAny(tc.input, tc.opts...)
+			if err != nil {
+				t.Fatalf("ParseAny(%q) failed: %v", tc.input, err)
+			}
+			if !result.Equal(tc.expected) {
+				t.Errorf("ParseAny(%q) = %v, want %v", tc.input, result, tc.expected)
+			}
+
+			layout, err := ParseFormat(tc.input, tc.opts...)
+			if err != nil || layout != tc.layout {
+				t.Errorf("ParseFormat(%q) = %q, %v, want %q", tc.input, layout, err, tc.layout)
+			}
+		})
+	}
+}
+
+func TestParseAnyTimezoneOverride(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("timezone data not available: %v", err)
+	}
+
+	// Automatic detection also honors the location for inputs without offset
+	result, err := ParseAny("2024-03-02 10:30:00", WithLocation(tokyo))
+	if err != nil {
+		t.Fatalf("ParseAny failed: %v", err)
+	}
+	if !result.Equal(time.Date(2024, 3, 2, 10, 30, 0, 0, tokyo)) {
+		t.Errorf("ParseAny with location = %v", result)
+	}
+
+	result, err = ParseAny("2024-03-02T10:30:00Z", WithOutputLocation(tokyo))
+	if err != nil {
+		t.Fatalf("ParseAny failed: %v", err)
+	}
+	if result.Location() != tokyo || result.Hour() != 19 {
+		t.Errorf("ParseAny with output location = %v", result)
+	}
+
+	_, err = ParseAny("2024-03-02", WithTimezone("Mars/Olympus"))
+	if !errors.Is(err, ErrInvalidTimezone) {
+		t.Errorf("expected ErrInvalidTimezone, got %v", err)
+	}
+	if _, err = ParseAny("2024-03-02", WithLocation(nil)); !errors.Is(err, ErrInvalidTimezone) {
+		t.Errorf("expected ErrInvalidTimezone, got %v", err)
+	}
+	if _, err = ParseAny("2024-03-02", WithFormats()); err == nil {
+		t.Error("expected error for empty format list")
+	}
+}
+
+func TestParseAnyFormatError(t *testing.T) {
+	testCases := []struct {
+		input      string
+		opts       []ParserOption
+		suggestion string
+	}{
+		{"", nil, "input is empty"},
+		{"170937540012345", nil, "10 digits"},
+		{"1709375400", []ParserOption{WithFormats(time.RFC3339)}, "LayoutUnix"},
+		{"31/31/2024", []ParserOption{WithFormats("02/01/2006")}, "day/month order"},
+		{"2024-13-45", nil, "time separator"},
+		{"not a date", nil, "RFC 3339"},
+		{"yesterday-ish", []ParserOption{WithFormats(time.DateOnly)}, "expected one of: 2006-01-02"},
+	}
+
+	for _, tc := range testCases {
+		_, err := ParseAny(tc.input, tc.opts...)
+
+		var formatErr *FormatError
+		if !errors.As(err, &formatErr) {
+			t.Errorf("ParseAny(%q) error = %v, want *FormatError", tc.input, err)
+			continue
+		}
+		if !errors.Is(err, ErrUnrecognizedFormat) {
+			t.Errorf("ParseAny(%q) error should wrap ErrUnrecognizedFormat", tc.input)
+		}
+		if formatErr.Input != tc.input {
+			t.Errorf("FormatError.Input = %q, want %q", formatErr.Input, tc.input)
+		}
+		if !strings.Contains(strings.Join(formatErr.Suggestions, "; "), tc.suggestion) {
+			t.Errorf("ParseAny(%q) suggestions = %v, want %q", tc.input, formatErr.Suggestions, tc.suggestion)
+		}
+	}
+}
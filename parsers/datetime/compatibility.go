@@ -27,6 +27,8 @@ type compatibilityParser struct {
 	preferMonthFirst           bool
 	retryAmbiguousDateWithSwap bool
 	ambiguousMD                bool
+	formats                    []string
+	outputLoc                  *time.Location
 	format                     []byte
 	t                          time.Time
 	// Additional state fields for advanced parsing
@@ -158,7 +160,15 @@ func ParseStrict(datestr string, opts ...ParserOption) (time.Time, error) {
 
 // parseTime creates a new parser and performs initial parsing
 func parseTime(datestr string, loc *time.Location, opts ...ParserOption) (*compatibilityParser, error) {
-	p := newCompatibilityParser(datestr, loc, opts...)
+	p, err := newCompatibilityParser(datestr, loc, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// A configured format list replaces automatic detection
+	if p.formats != nil {
+		return p, p.parseFormats()
+	}
 
 	// Try new parser first for common formats
 	newParser := NewParser()
@@ -166,16 +176,21 @@ func parseTime(datestr string, loc *time.Location, opts ...ParserOption) (*compa
 		// Convert to compatibility parser result
 		p.t = result.Time
 		p.format = []byte(result.Layout)
+		if !hasZone(result.Layout) && p.loc != time.UTC {
+			p.t = time.Date(p.t.Year(), p.t.Month(), p.t.Day(), p.t.Hour(), p.t.Minute(), p.t.Second(), p.t.Nanosecond(), p.loc)
+		}
 		return p, nil
 	}
 
 	// Fallback to advanced parsing
-	err := p.parseAdvanced()
-	return p, err
+	if err := p.parseAdvanced(); err != nil {
+		return p, newFormatError(datestr, nil)
+	}
+	return p, nil
 }
 
 // newCompatibilityParser creates a new compatibility parser
-func newCompatibilityParser(dateStr string, loc *time.Location, opts ...ParserOption) *compatibilityParser {
+func newCompatibilityParser(dateStr string, loc *time.Location, opts ...ParserOption) (*compatibilityParser, error) {
 	p := &compatibilityParser{
 		datestr:                    dateStr,
 		loc:                        loc,
@@ -188,19 +203,27 @@ func newCompatibilityParser(dateStr string, loc *time.Location, opts ...ParserOp
 	}
 
 	for _, opt := range opts {
-		opt(p)
+		if err := opt(p); err != nil {
+			return nil, err
+		}
 	}
 
-	return p
+	return p, nil
 }
 
 // parse returns the parsed time
 func (p *compatibilityParser) parse() (time.Time, error) {
-	if !p.t.IsZero() {
-		return p.t, nil
+	if p.t.IsZero() {
+		p.t = p.build()
+	}
+	if p.outputLoc != nil {
+		return p.t.In(p.outputLoc), nil
 	}
+	return p.t, nil
+}
 
-	// Build time from parsed components
+// build creates the time from the parsed components
+func (p *compatibilityParser) build() time.Time {
 	if p.year == 0 {
 		p.year = time.Now().Year()
 	}
@@ -218,9 +241,7 @@ func (p *compatibilityParser) parse() (time.Time, error) {
 		loc = time.FixedZone("", offsetSeconds)
 	}
 
-	t := time.Date(p.year, time.Month(p.month), p.day, p.hour, p.min, p.sec, p.nsec, loc)
-	p.t = t
-	return t, nil
+	return time.Date(p.year, time.Month(p.month), p.day, p.hour, p.min, p.sec, p.nsec, loc)
 }
 
 // parseAdvanced performs advanced parsing similar to old module
@@ -0,0 +1,328 @@
+// Package decimal provides locale-aware parsing of decimal numbers and money
+// amounts into exact shopspring decimals.
+package decimal
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/fsvxavier/nexs-lib/parsers/interfaces"
+	shopspring "github.com/shopspring/decimal"
+)
+
+// ParsedDecimal represents a parsed number with metadata.
+type ParsedDecimal struct {
+	Value    shopspring.Decimal
+	Currency string // ISO 4217 code, empty when the input has no currency
+	Original string
+	Locale   string
+}
+
+// Money is an amount in a currency, as parsed. Convert it with
+// money.FromParsed for currency-aware arithmetic and rounding.
+type Money struct {
+	Amount   shopspring.Decimal
+	Currency string
+}
+
+// String returns the amount prefixed by the currency code, e.g. "BRL 1234.56".
+func (m Money) String() string {
+	return m.Currency + " " + m.Amount.String()
+}
+
+// Parser implements locale-aware decimal parsing.
+type Parser struct {
+	config     *interfaces.ParserConfig
+	locale     Locale
+	currencies []string
+	maxPlaces  int32
+}
+
+// Option configures a Parser.
+type Option func(*Parser)
+
+// WithLocale sets the locale of the inputs. Defaults to LocaleEnUS.
+func WithLocale(locale Locale) Option {
+	return func(p *Parser) {
+		p.locale = locale
+	}
+}
+
+// WithCurrencies restricts the accepted currencies to the given ISO 4217 codes.
+func WithCurrencies(codes ...string) Option {
+	return func(p *Parser) {
+		p.currencies = append([]string(nil), codes...)
+	}
+}
+
+// WithMaxDecimalPlaces rejects numbers written with more decimal places than places.
+func WithMaxDecimalPlaces(places int32) Option {
+	return func(p *Parser) {
+		p.maxPlaces = places
+	}
+}
+
+// NewParser creates a new decimal parser with default configuration.
+func NewParser(opts ...Option) *Parser {
+	p := &Parser{
+		config:    interfaces.DefaultConfig(),
+		locale:    LocaleEnUS,
+		maxPlaces: -1,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// NewParserWithConfig creates a new decimal parser with custom configuration.
+func NewParserWithConfig(config *interfaces.ParserConfig, opts ...Option) *Parser {
+	parser := NewParser(opts...)
+	parser.config = config
+	return parser
+}
+
+// Parse implements interfaces.Parser.
+func (p *Parser) Parse(ctx context.Context, data []byte) (*ParsedDecimal, error) {
+	return p.ParseString(ctx, string(data))
+}
+
+// ParseString parses a number written in the parser locale, such as
+// "1.234,56" in pt-BR or "1,234.56" in en-US. The amount may be signed,
+// enclosed in parentheses (negative) and preceded or followed by a currency
+// symbol ("R$", "€") or ISO 4217 code ("BRL").
+func (p *Parser) ParseString(ctx context.Context, input string) (*ParsedDecimal, error) {
+	if err := p.validateInput(input); err != nil {
+		return nil, err
+	}
+
+	number, negative, currency := p.extractAffixes(strings.TrimSpace(input))
+
+	value, ok := parseNumber(number, p.locale)
+	if !ok {
+		decimalErr := p.newSyntaxError(input, number)
+		return nil, &interfaces.ParseError{
+			Type:    interfaces.ErrorTypeSyntax,
+			Message: decimalErr.Error(),
+			Cause:   decimalErr,
+		}
+	}
+	if negative {
+		value = value.Neg()
+	}
+
+	result := &ParsedDecimal{
+		Value:    value,
+		Currency: currency,
+		Original: input,
+		Locale:   p.locale.Tag,
+	}
+	if err := p.Validate(ctx, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Validate checks the currency and decimal places of the parsed value.
+func (p *Parser) Validate(ctx context.Context, result *ParsedDecimal) error {
+	if result == nil {
+		return &interfaces.ParseError{
+			Type:    interfaces.ErrorTypeValidation,
+			Message: "result cannot be nil",
+		}
+	}
+
+	var decimalErr *DecimalError
+	switch {
+	case p.maxPlaces >= 0 && -result.Value.Exponent() > p.maxPlaces:
+		decimalErr = &DecimalError{
+			Input:    result.Original,
+			Err:      ErrTooManyDecimalPlaces,
+			Expected: fmt.Sprintf("at most %d decimal places", p.maxPlaces),
+		}
+	case result.Currency != "" && len(p.currencies) > 0 && !slices.Contains(p.currencies, result.Currency):
+		decimalErr = &DecimalError{
+			Input:    result.Original,
+			Err:      ErrUnsupportedCurrency,
+			Expected: "one of " + strings.Join(p.currencies, ", "),
+		}
+	default:
+		return nil
+	}
+	return &interfaces.ParseError{
+		Type:    interfaces.ErrorTypeValidation,
+		Message: decimalErr.Error(),
+		Cause:   decimalErr,
+	}
+}
+
+// ParseMoney parses an amount, using the locale currency when the input has none.
+func (p *Parser) ParseMoney(ctx context.Context, input string) (Money, error) {
+	parsed, err := p.ParseString(ctx, input)
+	if err != nil {
+		return Money{}, err
+	}
+	if parsed.Currency == "" {
+		parsed.Currency = p.locale.Currency
+		if err := p.Validate(ctx, parsed); err != nil {
+			return Money{}, err
+		}
+	}
+	return Money{Amount: parsed.Value, Currency: parsed.Currency}, nil
+}
+
+// validateInput validates the input string.
+func (p *Parser) validateInput(input string) error {
+	if strings.TrimSpace(input) == "" {
+		return &interfaces.ParseError{
+			Type:    interfaces.ErrorTypeValidation,
+			Message: "input decimal is empty",
+		}
+	}
+
+	if p.config.MaxSize > 0 && int64(len(input)) > p.config.MaxSize {
+		return &interfaces.ParseError{
+			Type:    interfaces.ErrorTypeSize,
+			Message: fmt.Sprintf("decimal length %d exceeds maximum %d", len(input), p.config.MaxSize),
+		}
+	}
+	return nil
+}
+
+// extractAffixes removes the sign and currency around the number, returning
+// the remaining number and the ISO code of the currency found.
+func (p *Parser) extractAffixes(s string) (number string, negative bool, currency string) {
+	if len(s) > 1 && strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative = true
+		s = s[1 : len(s)-1]
+	}
+
+	signed := false
+	for changed := true; changed; {
+		changed = false
+		s = strings.TrimFunc(s, isSpace)
+
+		if r, size := utf8.DecodeRuneInString(s); !signed && !negative && (r == '-' || r == '+' || r == '\u2212') {
+			signed, negative = true, r != '+'
+			s, changed = s[size:], true
+		}
+		if currency != "" {
+			continue
+		}
+		if code, n := p.currencyPrefix(s); n > 0 {
+			currency, s, changed = code, s[n:], true
+		} else if code, n := p.currencySuffix(s); n > 0 {
+			currency, s, changed = code, s[:len(s)-n], true
+		}
+	}
+	return s, negative, currency
+}
+
+// currencyPrefix matches a currency symbol or ISO code at the start of s,
+// returning the code and the matched length.
+func (p *Parser) currencyPrefix(s string) (string, int) {
+	for _, c := range currencySymbols {
+		if strings.HasPrefix(s, c.symbol) {
+			return p.locale.symbolCurrency(c.code), len(c.symbol)
+		}
+	}
+	if len(s) >= 3 && isCode(s[:3]) {
+		if r, _ := utf8.DecodeRuneInString(s[3:]); !unicode.IsLetter(r) {
+			return s[:3], 3
+		}
+	}
+	return "", 0
+}
+
+// currencySuffix matches a currency symbol or ISO code at the end of s.
+func (p *Parser) currencySuffix(s string) (string, int) {
+	for _, c := range currencySymbols {
+		if strings.HasSuffix(s, c.symbol) {
+			return p.locale.symbolCurrency(c.code), len(c.symbol)
+		}
+	}
+	if n := len(s); n >= 3 && isCode(s[n-3:]) {
+		if r, _ := utf8.DecodeLastRuneInString(s[:n-3]); !unicode.IsLetter(r) {
+			return s[n-3:], 3
+		}
+	}
+	return "", 0
+}
+
+// isCode reports whether s has the ISO 4217 code format.
+func isCode(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 'A' || s[i] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseNumber parses an unsigned number written with the locale separators.
+// Groups of thousands are optional but must be well formed.
+func parseNumber(s string, locale Locale) (shopspring.Decimal, bool) {
+	intPart, fracPart, hasFrac := strings.Cut(s, string(locale.Decimal))
+	if hasFrac && fracPart == "" {
+		return shopspring.Decimal{}, false
+	}
+
+	var b strings.Builder
+	digits, groups, groupLen := 0, 0, 0
+	for _, r := range intPart {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+			digits++
+			groupLen++
+		case locale.isGroup(r):
+			if groupLen == 0 || groupLen > 3 || (groups > 0 && groupLen != 3) {
+				return shopspring.Decimal{}, false
+			}
+			groups++
+			groupLen = 0
+		default:
+			return shopspring.Decimal{}, false
+		}
+	}
+	if groups > 0 && groupLen != 3 {
+		return shopspring.Decimal{}, false
+	}
+	if digits == 0 {
+		if !hasFrac {
+			return shopspring.Decimal{}, false
+		}
+		b.WriteByte('0')
+	}
+
+	if hasFrac {
+		b.WriteByte('.')
+		for _, r := range fracPart {
+			if r < '0' || r > '9' {
+				return shopspring.Decimal{}, false
+			}
+			b.WriteRune(r)
+		}
+	}
+
+	value, err := shopspring.NewFromString(b.String())
+	return value, err == nil
+}
+
+// ParseDecimal parses a locale-formatted number.
+func ParseDecimal(input string, opts ...Option) (shopspring.Decimal, error) {
+	result, err := NewParser(opts...).ParseString(context.Background(), input)
+	if err != nil {
+		return shopspring.Decimal{}, err
+	}
+	return result.Value, nil
+}
+
+// ParseMoney parses a locale-formatted money amount.
+func ParseMoney(input string, opts ...Option) (Money, error) {
+	return NewParser(opts...).ParseMoney(context.Background(), input)
+}
@@ -0,0 +1,210 @@
+package decimal
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fsvxavier/nexs-lib/parsers/interfaces"
+	shopspring "github.com/shopspring/decimal"
+)
+
+func TestParser_ParseString(t *testing.T) {
+	tests := []struct {
+		name     string
+		locale   Locale
+		input    string
+		expected string
+		currency string
+	}{
+		{"en-US grouped", LocaleEnUS, "1,234.56", "1234.56", ""},
+		{"en-US plain", LocaleEnUS, "1234.56", "1234.56", ""},
+		{"en-US dollar", LocaleEnUS, "$1,234.56", "1234.56", "USD"},
+		{"en-US negative dollar", LocaleEnUS, "-$1,234.56", "-1234.56", "USD"},
+		{"en-US parentheses", LocaleEnUS, "($1,234.56)", "-1234.56", "USD"},
+		{"en-US integer", LocaleEnUS, "1,000,000", "1000000", ""},
+		{"en-US leading dot", LocaleEnUS, ".5", "0.5", ""},
+		{"pt-BR grouped", LocalePtBR, "1.234,56", "1234.56", ""},
+		{"pt-BR symbol", LocalePtBR, "R$ 1.234,56", "1234.56", "BRL"},
+		{"pt-BR negative after symbol", LocalePtBR, "R$ -1.234,56", "-1234.56", "BRL"},
+		{"pt-BR dot is grouping", LocalePtBR, "1.234", "1234", ""},
+		{"pt-BR ISO code", LocalePtBR, "BRL 10,5", "10.5", "BRL"},
+		{"pt-BR dollar is USD", LocalePtBR, "$ 10,00", "10", "USD"},
+		{"de-DE euro suffix", LocaleDeDE, "1.234,56 €", "1234.56", "EUR"},
+		{"fr-FR narrow space", LocaleFrFR, "1\u202f234,56\u00a0€", "1234.56", "EUR"},
+		{"fr-FR space", LocaleFrFR, "1 234 567,8", "1234567.8", ""},
+		{"pt-PT space", LocalePtPT, "1 234,5 EUR", "1234.5", "EUR"},
+		{"de-CH apostrophe", LocaleDeCH, "CHF 1'234.50", "1234.5", "CHF"},
+		{"de-CH typographic apostrophe", LocaleDeCH, "1’234.50", "1234.5", ""},
+		{"ja-JP yen", LocaleJaJP, "¥1,000", "1000", "JPY"},
+		{"ISO suffix", LocaleEnUS, "99.90 EUR", "99.9", "EUR"},
+		{"plus sign", LocaleEnUS, "+1.5", "1.5", ""},
+		{"unicode minus", LocaleEnUS, "−1.5", "-1.5", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewParser(WithLocale(tt.locale))
+			result, err := parser.ParseString(context.Background(), tt.input)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !result.Value.Equal(shopspring.RequireFromString(tt.expected)) {
+				t.Errorf("Expected %s, got %s", tt.expected, result.Value)
+			}
+			if result.Currency != tt.currency {
+				t.Errorf("Expected currency %q, got %q", tt.currency, result.Currency)
+			}
+			if result.Locale != tt.locale.Tag {
+				t.Errorf("Expected locale %s, got %s", tt.locale.Tag, result.Locale)
+			}
+		})
+	}
+}
+
+func TestParser_ParseString_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		locale Locale
+		input  string
+	}{
+		{"bad grouping", LocaleEnUS, "1,23.45"},
+		{"short last group", LocaleEnUS, "12,34"},
+		{"two decimal separators", LocaleEnUS, "1.2.3"},
+		{"letters", LocaleEnUS, "12abc"},
+		{"only currency", LocalePtBR, "R$"},
+		{"double sign", LocaleEnUS, "--1"},
+		{"sign inside parentheses", LocaleEnUS, "(-1)"},
+		{"trailing separator", LocalePtBR, "1,"},
+		{"wrong convention", LocalePtBR, "1,234.56"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewParser(WithLocale(tt.locale)).ParseString(context.Background(), tt.input)
+			if err == nil {
+				t.Fatal("Expected error")
+			}
+			var parseErr *interfaces.ParseError
+			if !errors.As(err, &parseErr) || parseErr.Type != interfaces.ErrorTypeSyntax {
+				t.Errorf("Expected syntax ParseError, got %v", err)
+			}
+			if !errors.Is(err, ErrInvalidDecimal) {
+				t.Errorf("Expected ErrInvalidDecimal, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDecimalError_Hints(t *testing.T) {
+	_, err := ParseDecimal("1,234.56", WithLocale(LocalePtBR))
+	var decimalErr *DecimalError
+	if !errors.As(err, &decimalErr) {
+		t.Fatalf("Expected DecimalError, got %v", err)
+	}
+	if decimalErr.Expected != "1.234,56 (pt-BR)" {
+		t.Errorf("Unexpected expected format %q", decimalErr.Expected)
+	}
+	if len(decimalErr.Suggestions) != 1 || !strings.Contains(decimalErr.Suggestions[0], `"1.234,56"`) {
+		t.Errorf("Unexpected suggestions %v", decimalErr.Suggestions)
+	}
+
+	_, err = ParseDecimal("1,5")
+	if !errors.As(err, &decimalErr) || !strings.Contains(err.Error(), `did you mean "1.5"`) {
+		t.Errorf("Expected suggestion for en-US, got %v", err)
+	}
+}
+
+func TestParser_Validation(t *testing.T) {
+	parser := NewParser(WithLocale(LocalePtBR), WithCurrencies("BRL", "USD"), WithMaxDecimalPlaces(2))
+	ctx := context.Background()
+
+	if _, err := parser.ParseString(ctx, "R$ 1,50"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	_, err := parser.ParseString(ctx, "1,505")
+	if !errors.Is(err, ErrTooManyDecimalPlaces) {
+		t.Errorf("Expected ErrTooManyDecimalPlaces, got %v", err)
+	}
+
+	_, err = parser.ParseString(ctx, "€ 1,50")
+	if !errors.Is(err, ErrUnsupportedCurrency) {
+		t.Errorf("Expected ErrUnsupportedCurrency, got %v", err)
+	}
+	var parseErr *interfaces.ParseError
+	if !errors.As(err, &parseErr) || parseErr.Type != interfaces.ErrorTypeValidation {
+		t.Errorf("Expected validation ParseError, got %v", err)
+	}
+
+	if err := parser.Validate(ctx, nil); err == nil {
+		t.Error("Expected error for nil result")
+	}
+}
+
+func TestParseMoney(t *testing.T) {
+	money, err := ParseMoney("1.234,56", WithLocale(LocalePtBR))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if money.Currency != "BRL" || money.String() != "BRL 1234.56" {
+		t.Errorf("Unexpected money %s", money)
+	}
+
+	money, err = ParseMoney("US$ 10,00", WithLocale(LocalePtBR))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if money.Currency != "USD" {
+		t.Errorf("Expected USD, got %s", money.Currency)
+	}
+
+	_, err = ParseMoney("10,00", WithLocale(LocalePtBR), WithCurrencies("USD"))
+	if !errors.Is(err, ErrUnsupportedCurrency) {
+		t.Errorf("Expected ErrUnsupportedCurrency for the locale currency, got %v", err)
+	}
+}
+
+func TestParser_EmptyAndSize(t *testing.T) {
+	if _, err := ParseDecimal("  "); err == nil {
+		t.Error("Expected error for empty input")
+	}
+
+	config := interfaces.DefaultConfig()
+	config.MaxSize = 4
+	_, err := NewParserWithConfig(config).ParseString(context.Background(), "12345")
+	var parseErr *interfaces.ParseError
+	if !errors.As(err, &parseErr) || parseErr.Type != interfaces.ErrorTypeSize {
+		t.Errorf("Expected size error, got %v", err)
+	}
+}
+
+func TestLocale_Format(t *testing.T) {
+	tests := []struct {
+		locale   Locale
+		value    string
+		expected string
+	}{
+		{LocaleEnUS, "1234567.891", "1,234,567.891"},
+		{LocalePtBR, "-1234.50", "-1.234,50"},
+		{LocaleDeCH, "999", "999"},
+		{LocaleFrFR, "1000", "1\u202f000"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.locale.Format(shopspring.RequireFromString(tt.value)); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.locale.Tag, tt.expected, got)
+		}
+	}
+}
+
+func TestLookupLocale(t *testing.T) {
+	locale, ok := LookupLocale("pt_br")
+	if !ok || locale.Tag != "pt-BR" {
+		t.Errorf("Expected pt-BR, got %v", locale)
+	}
+	if _, ok := LookupLocale("xx-XX"); ok {
+		t.Error("Expected unknown locale")
+	}
+}
@@ -0,0 +1,87 @@
+package decimal
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	shopspring "github.com/shopspring/decimal"
+)
+
+var (
+	// ErrInvalidDecimal indicates an input that is not a number in the locale format.
+	ErrInvalidDecimal = errors.New("invalid decimal")
+	// ErrUnsupportedCurrency indicates a currency outside the accepted ones.
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	// ErrTooManyDecimalPlaces indicates more decimal places than allowed.
+	ErrTooManyDecimalPlaces = errors.New("too many decimal places")
+)
+
+// DecimalError describes a value that could not be accepted, with the
+// expected format and suggestions on how to fix it.
+type DecimalError struct {
+	// Input is the original input.
+	Input string
+	// Err is ErrInvalidDecimal, ErrUnsupportedCurrency or ErrTooManyDecimalPlaces.
+	Err error
+	// Expected is an example of the accepted format, e.g. "1.234,56".
+	Expected string
+	// Suggestions contains hints to fix the input.
+	Suggestions []string
+}
+
+// Error implements the error interface.
+func (e *DecimalError) Error() string {
+	msg := fmt.Sprintf("%v: %q", e.Err, e.Input)
+	hints := e.Suggestions
+	if e.Expected != "" {
+		hints = append([]string{"expected " + e.Expected}, hints...)
+	}
+	if len(hints) > 0 {
+		msg += " (" + strings.Join(hints, "; ") + ")"
+	}
+	return msg
+}
+
+// Unwrap allows errors.Is with the sentinel errors.
+func (e *DecimalError) Unwrap() error {
+	return e.Err
+}
+
+// example is the number used to show the expected format.
+var example = shopspring.New(123456, -2)
+
+// newSyntaxError builds an ErrInvalidDecimal error for the number part of the input.
+func (p *Parser) newSyntaxError(input, number string) *DecimalError {
+	return &DecimalError{
+		Input:       input,
+		Err:         ErrInvalidDecimal,
+		Expected:    fmt.Sprintf("%s (%s)", p.locale.Format(example), p.locale.Tag),
+		Suggestions: p.suggest(number),
+	}
+}
+
+// suggest returns hints to fix an invalid number.
+func (p *Parser) suggest(number string) []string {
+	if number == "" {
+		return []string{"no digits found"}
+	}
+
+	// Try the other convention, e.g. "1,234.56" given to a pt-BR parser
+	swapped := Locale{Decimal: ',', Group: '.'}
+	if p.locale.Decimal == ',' {
+		swapped = Locale{Decimal: '.', Group: ','}
+	}
+	if value, ok := parseNumber(number, swapped); ok {
+		return []string{fmt.Sprintf("%s uses %q as decimal separator, did you mean %q?",
+			p.locale.Tag, p.locale.Decimal, p.locale.Format(value))}
+	}
+
+	for _, r := range number {
+		if unicode.IsLetter(r) {
+			return []string{"currencies must be ISO 4217 codes (e.g. EUR) or symbols (e.g. €) before or after the amount"}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package decimal
+
+import (
+	"strings"
+
+	shopspring "github.com/shopspring/decimal"
+)
+
+// Locale describes how numbers and money amounts are written in a region.
+type Locale struct {
+	// Tag is the BCP 47 language tag, e.g. "pt-BR".
+	Tag string
+	// Decimal separates the integer and fractional parts.
+	Decimal rune
+	// Group separates groups of thousands.
+	Group rune
+	// Currency is the ISO 4217 code of the local currency.
+	Currency string
+	// Symbol is the local currency symbol.
+	Symbol string
+}
+
+// Predefined locales.
+var (
+	LocaleEnUS = Locale{Tag: "en-US", Decimal: '.', Group: ',', Currency: "USD", Symbol: "$"}
+	LocaleEnGB = Locale{Tag: "en-GB", Decimal: '.', Group: ',', Currency: "GBP", Symbol: "£"}
+	LocalePtBR = Locale{Tag: "pt-BR", Decimal: ',', Group: '.', Currency: "BRL", Symbol: "R$"}
+	LocalePtPT = Locale{Tag: "pt-PT", Decimal: ',', Group: '\u00a0', Currency: "EUR", Symbol: "€"}
+	LocaleEsES = Locale{Tag: "es-ES", Decimal: ',', Group: '.', Currency: "EUR", Symbol: "€"}
+	LocaleDeDE = Locale{Tag: "de-DE", Decimal: ',', Group: '.', Currency: "EUR", Symbol: "€"}
+	LocaleFrFR = Locale{Tag: "fr-FR", Decimal: ',', Group: '\u202f', Currency: "EUR", Symbol: "€"}
+	LocaleItIT = Locale{Tag: "it-IT", Decimal: ',', Group: '.', Currency: "EUR", Symbol: "€"}
+	LocaleDeCH = Locale{Tag: "de-CH", Decimal: '.', Group: '\'', Currency: "CHF", Symbol: "CHF"}
+	LocaleJaJP = Locale{Tag: "ja-JP", Decimal: '.', Group: ',', Currency: "JPY", Symbol: "¥"}
+)
+
+var locales = []Locale{
+	LocaleEnUS, LocaleEnGB, LocalePtBR, LocalePtPT, LocaleEsES,
+	LocaleDeDE, LocaleFrFR, LocaleItIT, LocaleDeCH, LocaleJaJP,
+}
+
+// LookupLocale returns the predefined locale for a tag such as "pt-BR" or
+// "pt_br". Tags are matched case-insensitively.
+func LookupLocale(tag string) (Locale, bool) {
+	tag = strings.ReplaceAll(tag, "_", "-")
+	for _, l := range locales {
+		if strings.EqualFold(l.Tag, tag) {
+			return l, true
+		}
+	}
+	return Locale{}, false
+}
+
+// Format writes d with the locale separators, keeping its decimal places.
+func (l Locale) Format(d shopspring.Decimal) string {
+	var s string
+	if d.Exponent() < 0 {
+		s = d.StringFixed(-d.Exponent())
+	} else {
+		s = d.String()
+	}
+
+	var b strings.Builder
+	if rest, ok := strings.CutPrefix(s, "-"); ok {
+		b.WriteByte('-')
+		s = rest
+	}
+	intPart, fracPart, hasFrac := strings.Cut(s, ".")
+	for i, r := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteRune(l.Group)
+		}
+		b.WriteRune(r)
+	}
+	if hasFrac {
+		b.WriteRune(l.Decimal)
+		b.WriteString(fracPart)
+	}
+	return b.String()
+}
+
+// isGroup reports whether r separates thousands in the locale. Locales grouping
+// with spaces accept any space variant, and apostrophes may be typographic.
+func (l Locale) isGroup(r rune) bool {
+	switch {
+	case r == l.Group:
+		return true
+	case isSpace(l.Group):
+		return isSpace(r)
+	case l.Group == '\'':
+		return r == '\u2019'
+	}
+	return false
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\u00a0' || r == '\u202f'
+}
+
+// currencySymbols maps symbols to ISO 4217 codes, longest first. The dollar
+// sign maps to the local currency of dollar locales.
+var currencySymbols = []struct {
+	symbol string
+	code   string
+}{
+	{"US$", "USD"}, {"R$", "BRL"}, {"C$", "CAD"}, {"A$", "AUD"},
+	{"€", "EUR"}, {"£", "GBP"}, {"¥", "JPY"}, {"₹", "INR"}, {"$", ""},
+}
+
+// symbolCurrency returns the currency of a symbol in the locale.
+func (l Locale) symbolCurrency(code string) string {
+	if code != "" {
+		return code
+	}
+	if l.Symbol == "$" {
+		return l.Currency
+	}
+	return "USD"
+}
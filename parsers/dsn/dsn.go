@@ -0,0 +1,530 @@
+// Package dsn parses database and message broker connection strings into
+// typed values that can be validated and safely logged.
+package dsn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fsvxavier/nexs-lib/parsers/interfaces"
+)
+
+// RedactedPassword replaces passwords in redacted output.
+const RedactedPassword = "xxxxx"
+
+var (
+	// ErrInvalidDSN indicates a malformed connection string.
+	ErrInvalidDSN = errors.New("invalid DSN")
+	// ErrMissingPart indicates a connection string without a required part.
+	ErrMissingPart = errors.New("missing DSN part")
+	// ErrUnsupportedScheme indicates a scheme outside the accepted ones.
+	ErrUnsupportedScheme = errors.New("unsupported DSN scheme")
+)
+
+// DefaultPorts maps schemes to the port used when none is given.
+var DefaultPorts = map[string]int{
+	"postgres":   5432,
+	"postgresql": 5432,
+	"mysql":      3306,
+	"sqlserver":  1433,
+	"clickhouse": 9000,
+	"mongodb":    27017,
+	"redis":      6379,
+	"rediss":     6379,
+	"valkey":     6379,
+	"amqp":       5672,
+	"amqps":      5671,
+	"nats":       4222,
+	"kafka":      9092,
+}
+
+// sensitiveParams are parameters redacted along with the password.
+var sensitiveParams = map[string]bool{
+	"password":    true,
+	"sslpassword": true,
+	"secret":      true,
+	"token":       true,
+	"api_key":     true,
+	"apikey":      true,
+	"access_key":  true,
+	"secret_key":  true,
+}
+
+// Part names a component that may be required in a DSN.
+type Part string
+
+// DSN parts.
+const (
+	PartHost     Part = "host"
+	PartUser     Part = "user"
+	PartPassword Part = "password"
+	PartDatabase Part = "database"
+)
+
+// Host is a host and port pair.
+type Host struct {
+	Name string
+	Port int
+}
+
+// String returns host:port, or the host alone when it has no port.
+func (h Host) String() string {
+	if h.Port == 0 {
+		return h.Name
+	}
+	return net.JoinHostPort(h.Name, strconv.Itoa(h.Port))
+}
+
+// DSN is a parsed connection string.
+type DSN struct {
+	Scheme   string
+	Username string
+	Password string
+	Hosts    []Host
+	Database string
+	Params   map[string]string
+	// KeyValue reports whether the DSN was written in the keyword/value
+	// format ("host=db user=app"), which String and Redacted preserve.
+	KeyValue bool
+}
+
+// Host returns the first host, or the zero Host when there is none.
+func (d *DSN) Host() Host {
+	if len(d.Hosts) == 0 {
+		return Host{}
+	}
+	return d.Hosts[0]
+}
+
+// Param returns a parameter value, or an empty string.
+func (d *DSN) Param(key string) string {
+	return d.Params[key]
+}
+
+// String returns the connection string, including credentials.
+func (d *DSN) String() string {
+	return d.format(false)
+}
+
+// Redacted returns the connection string with the password and sensitive
+// parameters replaced by RedactedPassword, for logging.
+func (d *DSN) Redacted() string {
+	return d.format(true)
+}
+
+// format writes the DSN in its original format.
+func (d *DSN) format(redact bool) string {
+	password := d.Password
+	if redact && password != "" {
+		password = RedactedPassword
+	}
+	param := func(key string) string {
+		if redact && sensitiveParams[strings.ToLower(key)] {
+			return RedactedPassword
+		}
+		return d.Params[key]
+	}
+	keys := make([]string, 0, len(d.Params))
+	for key := range d.Params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	if d.KeyValue {
+		var parts []string
+		add := func(key, value string) {
+			if value != "" {
+				parts = append(parts, key+"="+quoteValue(value))
+			}
+		}
+		names := make([]string, len(d.Hosts))
+		ports := make([]string, len(d.Hosts))
+		for i, h := range d.Hosts {
+			names[i] = h.Name
+			if h.Port > 0 {
+				ports[i] = strconv.Itoa(h.Port)
+			}
+		}
+		add("host", strings.Join(names, ","))
+		if !slices.Contains(ports, "") {
+			add("port", strings.Join(ports, ","))
+		}
+		add("user", d.Username)
+		add("password", password)
+		add("dbname", d.Database)
+		for _, key := range keys {
+			add(key, param(key))
+		}
+		return strings.Join(parts, " ")
+	}
+
+	var b strings.Builder
+	b.WriteString(d.Scheme + "://")
+	if password != "" {
+		b.WriteString(url.UserPassword(d.Username, password).String() + "@")
+	} else if d.Username != "" {
+		b.WriteString(url.User(d.Username).String() + "@")
+	}
+	for i, h := range d.Hosts {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(h.String())
+	}
+	if d.Database != "" {
+		b.WriteString("/" + url.PathEscape(d.Database))
+	}
+	if len(keys) > 0 {
+		query := url.Values{}
+		for _, key := range keys {
+			query.Set(key, param(key))
+		}
+		b.WriteString("?" + query.Encode())
+	}
+	return b.String()
+}
+
+// Parser implements connection string parsing.
+type Parser struct {
+	config   *interfaces.ParserConfig
+	schemes  []string
+	required []Part
+}
+
+// Option configures a Parser.
+type Option func(*Parser)
+
+// WithSchemes restricts the accepted schemes.
+func WithSchemes(schemes ...string) Option {
+	return func(p *Parser) {
+		p.schemes = append([]string(nil), schemes...)
+	}
+}
+
+// WithRequired sets the parts that must be present, replacing the default
+// (PartHost). Without arguments, no part is required.
+func WithRequired(parts ...Part) Option {
+	return func(p *Parser) {
+		p.required = append([]Part(nil), parts...)
+	}
+}
+
+// NewParser creates a new DSN parser with default configuration.
+func NewParser(opts ...Option) *Parser {
+	p := &Parser{
+		config:   interfaces.DefaultConfig(),
+		required: []Part{PartHost},
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// NewParserWithConfig creates a new DSN parser with custom configuration.
+func NewParserWithConfig(config *interfaces.ParserConfig, opts ...Option) *Parser {
+	parser := NewParser(opts...)
+	parser.config = config
+	return parser
+}
+
+// Parse implements interfaces.Parser.
+func (p *Parser) Parse(ctx context.Context, data []byte) (*DSN, error) {
+	return p.ParseString(ctx, string(data))
+}
+
+// ParseString parses a URL connection string such as
+// "postgres://user:pass@h1:5432,h2:5432/app?sslmode=require" or a PostgreSQL
+// keyword/value string such as "host=db user=app dbname=app". Error messages
+// never include the input, as it may contain credentials.
+func (p *Parser) ParseString(ctx context.Context, input string) (*DSN, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return nil, &interfaces.ParseError{
+			Type:    interfaces.ErrorTypeValidation,
+			Message: "input DSN is empty",
+		}
+	}
+	if p.config.MaxSize > 0 && int64(len(input)) > p.config.MaxSize {
+		return nil, &interfaces.ParseError{
+			Type:    interfaces.ErrorTypeSize,
+			Message: fmt.Sprintf("DSN length %d exceeds maximum %d", len(input), p.config.MaxSize),
+		}
+	}
+
+	var (
+		result *DSN
+		err    error
+	)
+	if scheme, _, ok := strings.Cut(input, "://"); ok && !strings.ContainsAny(scheme, "= \t") {
+		result, err = parseURL(input)
+	} else {
+		result, err = parseKeyValue(input)
+	}
+	if err != nil {
+		return nil, &interfaces.ParseError{
+			Type:    interfaces.ErrorTypeSyntax,
+			Message: err.Error(),
+			Cause:   err,
+		}
+	}
+
+	if err := p.Validate(ctx, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Validate checks the scheme and the required parts.
+func (p *Parser) Validate(ctx context.Context, result *DSN) error {
+	if result == nil {
+		return &interfaces.ParseError{
+			Type:    interfaces.ErrorTypeValidation,
+			Message: "result cannot be nil",
+		}
+	}
+
+	var err error
+	if len(p.schemes) > 0 && !slices.Contains(p.schemes, result.Scheme) {
+		err = fmt.Errorf("%w %q, expected one of %s", ErrUnsupportedScheme, result.Scheme, strings.Join(p.schemes, ", "))
+	}
+	for _, part := range p.required {
+		if err != nil {
+			break
+		}
+		var present bool
+		switch part {
+		case PartHost:
+			present = len(result.Hosts) > 0 || result.Params["host"] != ""
+		case PartUser:
+			present = result.Username != ""
+		case PartPassword:
+			present = result.Password != ""
+		case PartDatabase:
+			present = result.Database != ""
+		}
+		if !present {
+			err = fmt.Errorf("%w: %s", ErrMissingPart, part)
+		}
+	}
+
+	if err != nil {
+		return &interfaces.ParseError{
+			Type:    interfaces.ErrorTypeValidation,
+			Message: err.Error(),
+			Cause:   err,
+		}
+	}
+	return nil
+}
+
+// parseURL parses scheme://[user[:password]@]host[:port][,host[:port]...][/database][?params].
+func parseURL(input string) (*DSN, error) {
+	scheme, rest, _ := strings.Cut(input, "://")
+	scheme = strings.ToLower(scheme)
+	if scheme == "" || strings.IndexFunc(scheme, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '+' || r == '-' || r == '.')
+	}) >= 0 {
+		return nil, fmt.Errorf("%w: malformed scheme", ErrInvalidDSN)
+	}
+	result := &DSN{Scheme: scheme, Params: map[string]string{}}
+
+	rest, _, _ = strings.Cut(rest, "#")
+	rest, query, _ := strings.Cut(rest, "?")
+
+	// The password may contain unescaped '@' and '/', so the last '@' ends the user info
+	if at := strings.LastIndex(rest, "@"); at >= 0 {
+		userinfo := rest[:at]
+		rest = rest[at+1:]
+		user, password, _ := strings.Cut(userinfo, ":")
+		var err error
+		if result.Username, err = url.PathUnescape(user); err != nil {
+			return nil, fmt.Errorf("%w: malformed user name escaping", ErrInvalidDSN)
+		}
+		if result.Password, err = url.PathUnescape(password); err != nil {
+			return nil, fmt.Errorf("%w: malformed password escaping", ErrInvalidDSN)
+		}
+	}
+
+	authority, path, _ := strings.Cut(rest, "/")
+	if authority != "" {
+		for _, hostport := range strings.Split(authority, ",") {
+			host, err := parseHost(hostport, DefaultPorts[scheme])
+			if err != nil {
+				return nil, err
+			}
+			result.Hosts = append(result.Hosts, host)
+		}
+	}
+
+	database, err := url.PathUnescape(strings.TrimSuffix(path, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed database escaping", ErrInvalidDSN)
+	}
+	result.Database = database
+
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed query parameters", ErrInvalidDSN)
+	}
+	for key := range values {
+		result.Params[key] = values.Get(key)
+	}
+	return result, nil
+}
+
+// parseHost parses host[:port], including bracketed IPv6 addresses.
+func parseHost(hostport string, defaultPort int) (Host, error) {
+	if hostport == "" {
+		return Host{}, fmt.Errorf("%w: empty host in host list", ErrInvalidDSN)
+	}
+
+	name, port := hostport, ""
+	if i := strings.LastIndex(hostport, ":"); i >= 0 && !strings.HasSuffix(hostport, "]") {
+		var err error
+		if name, port, err = net.SplitHostPort(hostport); err != nil {
+			return Host{}, fmt.Errorf("%w: malformed host %q", ErrInvalidDSN, hostport)
+		}
+	}
+	name = strings.TrimSuffix(strings.TrimPrefix(name, "["), "]")
+	if name == "" {
+		return Host{}, fmt.Errorf("%w: empty host in host list", ErrInvalidDSN)
+	}
+
+	if port == "" {
+		return Host{Name: name, Port: defaultPort}, nil
+	}
+	n, err := strconv.Atoi(port)
+	if err != nil || n < 1 || n > 65535 {
+		return Host{}, fmt.Errorf("%w: invalid port %q", ErrInvalidDSN, port)
+	}
+	return Host{Name: name, Port: n}, nil
+}
+
+// parseKeyValue parses the PostgreSQL keyword/value format:
+// host=db port=5432 user=app password='s3 cret' dbname=app sslmode=require.
+func parseKeyValue(input string) (*DSN, error) {
+	result := &DSN{Scheme: "postgres", Params: map[string]string{}, KeyValue: true}
+
+	var hosts, ports []string
+	s := input
+	for {
+		s = strings.TrimLeft(s, " \t\n\r")
+		if s == "" {
+			break
+		}
+
+		eq := strings.IndexByte(s, '=')
+		if eq <= 0 {
+			return nil, fmt.Errorf("%w: expected keyword=value", ErrInvalidDSN)
+		}
+		key := strings.TrimSpace(s[:eq])
+		s = strings.TrimLeft(s[eq+1:], " \t")
+
+		value, rest, err := readValue(s)
+		if err != nil {
+			return nil, err
+		}
+		s = rest
+
+		switch key {
+		case "host", "hostaddr":
+			hosts = strings.Split(value, ",")
+		case "port":
+			ports = strings.Split(value, ",")
+		case "user":
+			result.Username = value
+		case "password":
+			result.Password = value
+		case "dbname":
+			result.Database = value
+		default:
+			result.Params[key] = value
+		}
+	}
+
+	if len(ports) > 1 && len(ports) != len(hosts) {
+		return nil, fmt.Errorf("%w: %d ports for %d hosts", ErrInvalidDSN, len(ports), len(hosts))
+	}
+	for i, name := range hosts {
+		port := DefaultPorts["postgres"]
+		if len(ports) > 0 {
+			p := ports[0]
+			if len(ports) > 1 {
+				p = ports[i]
+			}
+			n, err := strconv.Atoi(p)
+			if err != nil || n < 1 || n > 65535 {
+				return nil, fmt.Errorf("%w: invalid port %q", ErrInvalidDSN, p)
+			}
+			port = n
+		}
+		if name == "" {
+			return nil, fmt.Errorf("%w: empty host in host list", ErrInvalidDSN)
+		}
+		result.Hosts = append(result.Hosts, Host{Name: name, Port: port})
+	}
+	return result, nil
+}
+
+// readValue reads a bare or single-quoted value, returning the remaining input.
+func readValue(s string) (string, string, error) {
+	if !strings.HasPrefix(s, "'") {
+		end := strings.IndexAny(s, " \t\n\r")
+		if end < 0 {
+			end = len(s)
+		}
+		return unescapeValue(s[:end]), s[end:], nil
+	}
+
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				b.WriteByte(s[i])
+			}
+		case '\'':
+			return b.String(), s[i+1:], nil
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return "", "", fmt.Errorf("%w: unterminated quoted value", ErrInvalidDSN)
+}
+
+// unescapeValue removes backslash escapes from a bare value.
+func unescapeValue(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// quoteValue quotes keyword/value values containing spaces or quotes.
+func quoteValue(s string) string {
+	if s != "" && !strings.ContainsAny(s, ` '\`) {
+		return s
+	}
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+// ParseDSN parses a connection string.
+func ParseDSN(input string, opts ...Option) (*DSN, error) {
+	return NewParser(opts...).ParseString(context.Background(), input)
+}
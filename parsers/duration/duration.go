@@ -3,6 +3,7 @@ package duration
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -29,11 +30,30 @@ type ParsedDuration struct {
 type Parser struct {
 	config  *interfaces.ParserConfig
 	unitMap map[string]int64
+	min     *time.Duration
+	max     *time.Duration
+}
+
+// Option configures a Parser.
+type Option func(*Parser)
+
+// WithMin rejects durations shorter than min.
+func WithMin(min time.Duration) Option {
+	return func(p *Parser) {
+		p.min = &min
+	}
+}
+
+// WithMax rejects durations longer than max.
+func WithMax(max time.Duration) Option {
+	return func(p *Parser) {
+		p.max = &max
+	}
 }
 
 // NewParser creates a new duration parser with default configuration.
-func NewParser() *Parser {
-	return &Parser{
+func NewParser(opts ...Option) *Parser {
+	p := &Parser{
 		config: interfaces.DefaultConfig(),
 		unitMap: map[string]int64{
 			"ns": int64(time.Nanosecond),
@@ -48,11 +68,15 @@ func NewParser() *Parser {
 			"w":  int64(Week),
 		},
 	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
 }
 
 // NewParserWithConfig creates a new duration parser with custom configuration.
-func NewParserWithConfig(config *interfaces.ParserConfig) *Parser {
-	parser := NewParser()
+func NewParserWithConfig(config *interfaces.ParserConfig, opts ...Option) *Parser {
+	parser := NewParser(opts...)
 	parser.config = config
 	return parser
 }
@@ -62,30 +86,39 @@ func (p *Parser) Parse(ctx context.Context, data []byte) (*ParsedDuration, error
 	return p.ParseString(ctx, string(data))
 }
 
-// ParseString parses a duration string with enhanced unit support.
+// ParseString parses a duration string with enhanced unit support. Besides the
+// time.ParseDuration syntax, it accepts days (d), weeks (w), whitespace between
+// components ("1h 30m") and ISO 8601 durations ("P3DT4H").
 func (p *Parser) ParseString(ctx context.Context, input string) (*ParsedDuration, error) {
 	if err := p.validateInput(input); err != nil {
 		return nil, err
 	}
 
 	original := input
-	input = strings.TrimSpace(input)
+	input = strings.Join(strings.Fields(input), "")
 
-	// Try standard library first
-	if d, err := time.ParseDuration(input); err == nil {
-		return &ParsedDuration{
-			Duration: d,
-			Original: original,
-			Units:    p.extractUnits(input),
-		}, nil
+	duration, units, err := p.parse(input)
+	if errors.Is(err, ErrOverflow) {
+		overflowErr := newOverflowError(original)
+		return nil, &interfaces.ParseError{
+			Type:    interfaces.ErrorTypeValidation,
+			Message: overflowErr.Error(),
+			Cause:   overflowErr,
+		}
 	}
-
-	// Enhanced parsing with days and weeks
-	duration, err := p.parseEnhanced(input)
 	if err != nil {
+		durationErr := newDurationError(original, ErrInvalidDuration)
 		return nil, &interfaces.ParseError{
 			Type:    interfaces.ErrorTypeSyntax,
-			Message: fmt.Sprintf("unable to parse duration: %s", original),
+			Message: durationErr.Error(),
+			Cause:   durationErr,
+		}
+	}
+
+	if err := p.checkBounds(original, duration); err != nil {
+		return nil, &interfaces.ParseError{
+			Type:    interfaces.ErrorTypeValidation,
+			Message: err.Error(),
 			Cause:   err,
 		}
 	}
@@ -93,10 +126,49 @@ func (p *Parser) ParseString(ctx context.Context, input string) (*ParsedDuration
 	return &ParsedDuration{
 		Duration: duration,
 		Original: original,
-		Units:    p.extractUnits(input),
+		Units:    units,
 	}, nil
 }
 
+// parse parses a duration without whitespace, returning the units found.
+func (p *Parser) parse(s string) (time.Duration, []string, error) {
+	if isISO8601(s) {
+		d, err := parseISO8601(s)
+		return d, nil, err
+	}
+
+	// Try standard library first
+	if d, err := time.ParseDuration(s); err == nil {
+		return d, p.extractUnits(s), nil
+	}
+
+	// Enhanced parsing with days and weeks
+	d, err := p.parseEnhanced(s)
+	if err != nil {
+		return 0, nil, err
+	}
+	return d, p.extractUnits(s), nil
+}
+
+// checkBounds validates the duration against the configured min and max.
+func (p *Parser) checkBounds(input string, d time.Duration) error {
+	if p.min != nil && d < *p.min {
+		return &DurationError{
+			Input:       input,
+			Err:         ErrOutOfRange,
+			Suggestions: []string{"must be at least " + Humanize(*p.min)},
+		}
+	}
+	if p.max != nil && d > *p.max {
+		return &DurationError{
+			Input:       input,
+			Err:         ErrOutOfRange,
+			Suggestions: []string{"must be at most " + Humanize(*p.max)},
+		}
+	}
+	return nil
+}
+
 // parseEnhanced performs enhanced duration parsing with days and weeks.
 func (p *Parser) parseEnhanced(s string) (time.Duration, error) {
 	orig := s
@@ -137,7 +209,7 @@ func (p *Parser) parseEnhanced(s string) (time.Duration, error) {
 		pl := len(s)
 		v, s, err = leadingInt(s)
 		if err != nil {
-			return 0, fmt.Errorf("time: invalid duration %q", orig)
+			return 0, fmt.Errorf("%w: %q", ErrOverflow, orig)
 		}
 		pre := pl != len(s) // whether we consumed anything before a period
 
@@ -178,8 +250,7 @@ func (p *Parser) parseEnhanced(s string) (time.Duration, error) {
 			return 0, fmt.Errorf("time: unknown unit %q in duration %q", u, orig)
 		}
 		if v > 1<<63/uint64(unit) {
-			// overflow
-			return 0, fmt.Errorf("time: invalid duration %q", orig)
+			return 0, fmt.Errorf("%w: %q", ErrOverflow, orig)
 		}
 		v *= uint64(unit)
 		if f > 0 {
@@ -187,13 +258,13 @@ func (p *Parser) parseEnhanced(s string) (time.Duration, error) {
 			// v >= 0 && (f*unit/scale) <= 3.6e+12 (ns/h, h is the largest unit)
 			v += uint64(float64(f) * (float64(unit) / scale))
 			if v > 1<<63 {
-				// overflow
-				return 0, fmt.Errorf("time: invalid duration %q", orig)
+				return 0, fmt.Errorf("%w: %q", ErrOverflow, orig)
 			}
 		}
+		// v is at most 1<<63, so a sum past MaxInt64 wraps negative
 		d += int64(v)
-		if d > 1<<63-1 {
-			return 0, fmt.Errorf("time: invalid duration %q", orig)
+		if d < 0 {
+			return 0, fmt.Errorf("%w: %q", ErrOverflow, orig)
 		}
 	}
 
@@ -375,8 +446,8 @@ func (f *Formatter) FormatWriter(ctx context.Context, data *ParsedDuration, writ
 // Utility functions
 
 // ParseDuration parses a duration string with enhanced unit support.
-func ParseDuration(input string) (*ParsedDuration, error) {
-	parser := NewParser()
+func ParseDuration(input string, opts ...Option) (*ParsedDuration, error) {
+	parser := NewParser(opts...)
 	return parser.ParseString(context.Background(), input)
 }
 
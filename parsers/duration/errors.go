@@ -0,0 +1,125 @@
+package duration
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ErrInvalidDuration indicates an input that is not a valid duration.
+	ErrInvalidDuration = errors.New("invalid duration")
+	// ErrOutOfRange indicates a duration outside the configured bounds.
+	ErrOutOfRange = errors.New("duration out of range")
+	// ErrOverflow indicates a duration too long for time.Duration. It wraps
+	// ErrOutOfRange.
+	ErrOverflow = fmt.Errorf("%w: overflows time.Duration", ErrOutOfRange)
+)
+
+// DurationError describes a duration that could not be accepted, with
+// suggestions on how to fix it.
+type DurationError struct {
+	// Input is the original input.
+	Input string
+	// Err is ErrInvalidDuration, ErrOutOfRange or ErrOverflow.
+	Err error
+	// Suggestions contains hints to fix the input.
+	Suggestions []string
+}
+
+// Error implements the error interface.
+func (e *DurationError) Error() string {
+	msg := fmt.Sprintf("%v: %q", e.Err, e.Input)
+	if len(e.Suggestions) > 0 {
+		msg += " (" + strings.Join(e.Suggestions, "; ") + ")"
+	}
+	return msg
+}
+
+// Unwrap allows errors.Is(err, ErrInvalidDuration) and errors.Is(err, ErrOutOfRange).
+func (e *DurationError) Unwrap() error {
+	return e.Err
+}
+
+// unitAliases maps common unit spellings to the supported units.
+var unitAliases = map[string]string{
+	"nsec": "ns", "nanosecond": "ns", "nanoseconds": "ns",
+	"usec": "us", "microsecond": "us", "microseconds": "us",
+	"msec": "ms", "millisecond": "ms", "milliseconds": "ms",
+	"sec": "s", "secs": "s", "second": "s", "seconds": "s",
+	"min": "m", "mins": "m", "minute": "m", "minutes": "m",
+	"hr": "h", "hrs": "h", "hour": "h", "hours": "h",
+	"day": "d", "days": "d",
+	"wk": "w", "week": "w", "weeks": "w",
+}
+
+// variableUnits are calendar units without a fixed length.
+var variableUnits = map[string]bool{
+	"y": true, "yr": true, "year": true, "years": true,
+	"mo": true, "month": true, "months": true,
+}
+
+var unitPattern = regexp.MustCompile(`[^0-9.+\-]+`)
+
+// newOverflowError builds the error of a duration too long for time.Duration.
+func newOverflowError(input string) *DurationError {
+	return &DurationError{
+		Input:       input,
+		Err:         ErrOverflow,
+		Suggestions: []string{"durations are limited to about 292 years (106751d)"},
+	}
+}
+
+// newDurationError builds a syntax error with suggestions based on the input.
+func newDurationError(input string, err error) *DurationError {
+	return &DurationError{
+		Input:       input,
+		Err:         err,
+		Suggestions: suggest(input),
+	}
+}
+
+// suggest returns hints to fix an invalid duration.
+func suggest(input string) []string {
+	s := strings.Join(strings.Fields(input), "")
+
+	if isISO8601(s) {
+		datePart, _, _ := strings.Cut(strings.ToUpper(s), "T")
+		if strings.ContainsAny(datePart, "YM") {
+			return []string{"years and months have variable length, use days (e.g. P30D)"}
+		}
+		return []string{"ISO 8601 durations look like P3DT4H30M"}
+	}
+
+	var suggestions []string
+	fixable := true
+	fixed := unitPattern.ReplaceAllStringFunc(s, func(unit string) string {
+		lower := strings.ToLower(unit)
+		switch {
+		case knownUnits[unit]:
+			return unit
+		case knownUnits[lower]:
+			return lower
+		case unitAliases[lower] != "":
+			return unitAliases[lower]
+		case variableUnits[lower]:
+			suggestions = append(suggestions, "years and months have variable length, use days (e.g. 30d)")
+		default:
+			suggestions = append(suggestions, fmt.Sprintf("unknown unit %q", unit))
+		}
+		fixable = false
+		return unit
+	})
+
+	if fixable && fixed != s {
+		return []string{fmt.Sprintf("did you mean %q?", fixed)}
+	}
+	return append(suggestions, "valid units: ns, us, ms, s, m, h, d, w, or ISO 8601 such as P3DT4H")
+}
+
+// knownUnits units accepted by the parser.
+var knownUnits = map[string]bool{
+	"ns": true, "us": true, "µs": true, "μs": true, "ms": true,
+	"s": true, "m": true, "h": true, "d": true, "w": true,
+}
@@ -0,0 +1,117 @@
+package duration
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/parsers/interfaces"
+)
+
+func TestDurationErrorSuggestions(t *testing.T) {
+	tests := []struct {
+		input      string
+		suggestion string
+	}{
+		{"2 days", `did you mean "2d"?`},
+		{"1H30M", `did you mean "1h30m"?`},
+		{"5 mins 10 secs", `did you mean "5m10s"?`},
+		{"1y", "variable length"},
+		{"3 months", "variable length"},
+		{"10x", `unknown unit "x"`},
+		{"P1Y2D", "P30D"},
+		{"P1X", "P3DT4H30M"},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseDuration(tt.input)
+
+		var durationErr *DurationError
+		if !errors.As(err, &durationErr) {
+			t.Errorf("ParseDuration(%q) error = %v, want *DurationError", tt.input, err)
+			continue
+		}
+		if !errors.Is(err, ErrInvalidDuration) {
+			t.Errorf("ParseDuration(%q) error should wrap ErrInvalidDuration", tt.input)
+		}
+
+		var parseErr *interfaces.ParseError
+		if !errors.As(err, &parseErr) || parseErr.Type != interfaces.ErrorTypeSyntax {
+			t.Errorf("ParseDuration(%q) error should be a syntax ParseError", tt.input)
+		}
+
+		if !strings.Contains(strings.Join(durationErr.Suggestions, "; "), tt.suggestion) {
+			t.Errorf("ParseDuration(%q) suggestions = %v, want %q", tt.input, durationErr.Suggestions, tt.suggestion)
+		}
+	}
+}
+
+func TestParseDurationBounds(t *testing.T) {
+	opts := []Option{WithMin(time.Minute), WithMax(Day)}
+
+	result, err := ParseDuration("1h 30m", opts...)
+	if err != nil || result.Duration != 90*time.Minute {
+		t.Errorf("ParseDuration within bounds = %v, %v", result, err)
+	}
+
+	tests := []struct {
+		input      string
+		suggestion string
+	}{
+		{"30s", "must be at least 1m"},
+		{"2d", "must be at most 1d"},
+		{"P1W", "must be at most 1d"},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseDuration(tt.input, opts...)
+		if !errors.Is(err, ErrOutOfRange) {
+			t.Errorf("ParseDuration(%q) error = %v, want ErrOutOfRange", tt.input, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.suggestion) {
+			t.Errorf("ParseDuration(%q) error = %q, want %q", tt.input, err, tt.suggestion)
+		}
+
+		var parseErr *interfaces.ParseError
+		if !errors.As(err, &parseErr) || parseErr.Type != interfaces.ErrorTypeValidation {
+			t.Errorf("ParseDuration(%q) error should be a validation ParseError", tt.input)
+		}
+	}
+}
+
+func TestParseDurationOverflow(t *testing.T) {
+	tests := []string{
+		"106752d",
+		"15251w",
+		"2562048h 1d",
+		"9223372036854775808ns",
+		"P999999999999D",
+		"PT9999999999999999H",
+		"P106751DT24H",
+	}
+
+	for _, input := range tests {
+		_, err := ParseDuration(input)
+		if !errors.Is(err, ErrOverflow) || !errors.Is(err, ErrOutOfRange) {
+			t.Errorf("ParseDuration(%q) error = %v, want ErrOverflow", input, err)
+			continue
+		}
+		if errors.Is(err, ErrInvalidDuration) {
+			t.Errorf("ParseDuration(%q) error should not wrap ErrInvalidDuration", input)
+		}
+		if !strings.Contains(err.Error(), input) {
+			t.Errorf("ParseDuration(%q) error = %q, want the input", input, err)
+		}
+
+		var parseErr *interfaces.ParseError
+		if !errors.As(err, &parseErr) || parseErr.Type != interfaces.ErrorTypeValidation {
+			t.Errorf("ParseDuration(%q) error should be a validation ParseError", input)
+		}
+	}
+
+	if result, err := ParseDuration("106751d"); err != nil || result.Duration != 106751*Day {
+		t.Errorf("ParseDuration(106751d) = %v, %v", result, err)
+	}
+}
@@ -0,0 +1,54 @@
+package duration
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// humanUnits units used by Humanize, from largest to smallest.
+var humanUnits = []struct {
+	unit   time.Duration
+	symbol string
+}{
+	{Week, "w"},
+	{Day, "d"},
+	{time.Hour, "h"},
+	{time.Minute, "m"},
+	{time.Second, "s"},
+}
+
+// Humanize formats a duration in a human-friendly way, such as "1h 30m" or
+// "2d 4h". Durations of one second or more are truncated to seconds; shorter
+// ones use the standard representation (e.g. "250ms"). The output can be
+// parsed back by ParseDuration.
+func Humanize(d time.Duration) string {
+	if d == 0 {
+		return "0s"
+	}
+
+	var b strings.Builder
+	if d < 0 {
+		b.WriteByte('-')
+		d = -d
+	}
+
+	if d < time.Second {
+		b.WriteString(d.String())
+		return b.String()
+	}
+
+	for _, u := range humanUnits {
+		n := d / u.unit
+		if n == 0 {
+			continue
+		}
+		if b.Len() > 0 && b.String() != "-" {
+			b.WriteByte(' ')
+		}
+		b.WriteString(strconv.FormatInt(int64(n), 10))
+		b.WriteString(u.symbol)
+		d -= n * u.unit
+	}
+	return b.String()
+}
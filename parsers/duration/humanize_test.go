@@ -0,0 +1,38 @@
+package duration
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHumanize(t *testing.T) {
+	tests := []struct {
+		input    time.Duration
+		expected string
+	}{
+		{0, "0s"},
+		{90 * time.Minute, "1h 30m"},
+		{2*Day + 4*time.Hour, "2d 4h"},
+		{Week + Day + time.Second, "1w 1d 1s"},
+		{90*time.Second + 300*time.Millisecond, "1m 30s"},
+		{250 * time.Millisecond, "250ms"},
+		{-90 * time.Minute, "-1h 30m"},
+	}
+
+	for _, tt := range tests {
+		humanized := Humanize(tt.input)
+		if humanized != tt.expected {
+			t.Errorf("Humanize(%v) = %q, want %q", tt.input, humanized, tt.expected)
+		}
+
+		// The output can be parsed back
+		parsed, err := ParseDuration(humanized)
+		if err != nil {
+			t.Errorf("ParseDuration(%q) failed: %v", humanized, err)
+			continue
+		}
+		if parsed.Duration != tt.input.Truncate(time.Second) && tt.input.Abs() >= time.Second {
+			t.Errorf("ParseDuration(%q) = %v", humanized, parsed.Duration)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package duration
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// iso8601Pattern matches ISO 8601 durations such as P3DT4H30M or PT0.5S
+var iso8601Pattern = regexp.MustCompile(`^([-+]?)P` +
+	`(?:(\d+(?:[.,]\d+)?)Y)?(?:(\d+(?:[.,]\d+)?)M)?(?:(\d+(?:[.,]\d+)?)W)?(?:(\d+(?:[.,]\d+)?)D)?` +
+	`(?:T(?:(\d+(?:[.,]\d+)?)H)?(?:(\d+(?:[.,]\d+)?)M)?(?:(\d+(?:[.,]\d+)?)S)?)?$`)
+
+// iso8601Units unit of each iso8601Pattern group, starting at group 2.
+// Years and months have variable length and are only accepted when zero
+var iso8601Units = []time.Duration{0, 0, Week, Day, time.Hour, time.Minute, time.Second}
+
+// isISO8601 reports whether the input looks like an ISO 8601 duration.
+func isISO8601(s string) bool {
+	s = strings.TrimLeft(s, "+-")
+	return s != "" && (s[0] == 'P' || s[0] == 'p')
+}
+
+// parseISO8601 parses an ISO 8601 duration (PnYnMnWnDTnHnMnS).
+func parseISO8601(s string) (time.Duration, error) {
+	orig := s
+	s = strings.ToUpper(s)
+
+	m := iso8601Pattern.FindStringSubmatch(s)
+	if m == nil || strings.HasSuffix(s, "T") || strings.TrimLeft(s, "+-") == "P" {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidDuration, orig)
+	}
+
+	var total time.Duration
+	for i, unit := range iso8601Units {
+		value := m[i+2]
+		if value == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+		if errors.Is(err, strconv.ErrRange) {
+			return 0, fmt.Errorf("%w: %q", ErrOverflow, orig)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q", ErrInvalidDuration, orig)
+		}
+		if unit == 0 {
+			if f != 0 {
+				return 0, fmt.Errorf("%w: %q", ErrInvalidDuration, orig)
+			}
+			continue
+		}
+
+		// Integer components avoid float64 rounding on long durations
+		var component time.Duration
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil && n <= math.MaxInt64/int64(unit) {
+			component = time.Duration(n) * unit
+		} else if f*float64(unit) < math.MaxInt64 {
+			component = time.Duration(math.Round(f * float64(unit)))
+		} else {
+			return 0, fmt.Errorf("%w: %q", ErrOverflow, orig)
+		}
+
+		if total > math.MaxInt64-component {
+			return 0, fmt.Errorf("%w: %q", ErrOverflow, orig)
+		}
+		total += component
+	}
+
+	if m[1] == "-" {
+		total = -total
+	}
+	return total, nil
+}
+
+// FormatISO8601 formats a duration as ISO 8601 (e.g. P3DT4H30M). Weeks are
+// expressed in days, as the week designator cannot be combined with others.
+func FormatISO8601(d time.Duration) string {
+	if d == 0 {
+		return "PT0S"
+	}
+
+	var b strings.Builder
+	if d < 0 {
+		b.WriteByte('-')
+		d = -d
+	}
+	b.WriteByte('P')
+
+	if days := d / Day; days > 0 {
+		b.WriteString(strconv.FormatInt(int64(days), 10))
+		b.WriteByte('D')
+		d -= days * Day
+	}
+	if d == 0 {
+		return b.String()
+	}
+
+	b.WriteByte('T')
+	if hours := d / time.Hour; hours > 0 {
+		b.WriteString(strconv.FormatInt(int64(hours), 10))
+		b.WriteByte('H')
+		d -= hours * time.Hour
+	}
+	if minutes := d / time.Minute; minutes > 0 {
+		b.WriteString(strconv.FormatInt(int64(minutes), 10))
+		b.WriteByte('M')
+		d -= minutes * time.Minute
+	}
+	if d > 0 {
+		b.WriteString(strconv.FormatFloat(d.Seconds(), 'f', -1, 64))
+		b.WriteByte('S')
+	}
+	return b.String()
+}
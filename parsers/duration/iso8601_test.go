@@ -0,0 +1,73 @@
+package duration
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseISO8601(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected time.Duration
+		wantErr  bool
+	}{
+		{"days and hours", "P3DT4H", 3*Day + 4*time.Hour, false},
+		{"full time", "PT1H30M15S", time.Hour + 30*time.Minute + 15*time.Second, false},
+		{"weeks", "P2W", 2 * Week, false},
+		{"fractional seconds", "PT0.5S", 500 * time.Millisecond, false},
+		{"comma decimal", "PT1,5H", 90 * time.Minute, false},
+		{"negative", "-PT10M", -10 * time.Minute, false},
+		{"lowercase", "p1dt2h", Day + 2*time.Hour, false},
+		{"zero years", "P0Y0M1D", Day, false},
+		{"long duration", "P365D", 365 * Day, false},
+		{"years", "P1Y", 0, true},
+		{"months", "P2M", 0, true},
+		{"dangling T", "P1DT", 0, true},
+		{"wrong order", "PT1S2H", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseDuration(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidDuration) {
+					t.Errorf("ParseDuration(%q) error = %v, want ErrInvalidDuration", tt.input, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseDuration(%q) failed: %v", tt.input, err)
+			}
+			if result.Duration != tt.expected {
+				t.Errorf("ParseDuration(%q) = %v, want %v", tt.input, result.Duration, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatISO8601(t *testing.T) {
+	tests := []struct {
+		input    time.Duration
+		expected string
+	}{
+		{0, "PT0S"},
+		{3*Day + 4*time.Hour, "P3DT4H"},
+		{Week, "P7D"},
+		{time.Hour + 30*time.Minute + 1500*time.Millisecond, "PT1H30M1.5S"},
+		{-90 * time.Second, "-PT1M30S"},
+	}
+
+	for _, tt := range tests {
+		formatted := FormatISO8601(tt.input)
+		if formatted != tt.expected {
+			t.Errorf("FormatISO8601(%v) = %q, want %q", tt.input, formatted, tt.expected)
+		}
+
+		parsed, err := ParseDuration(formatted)
+		if err != nil || parsed.Duration != tt.input {
+			t.Errorf("ParseDuration(%q) = %v, %v, want %v", formatted, parsed, err, tt.input)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+// Package enum provides parsing of enumerated values with case folding and
+// closest-match suggestions on failure.
+package enum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fsvxavier/nexs-lib/parsers/interfaces"
+	"github.com/fsvxavier/nexs-lib/parsers/internal"
+)
+
+// ErrInvalidEnum indicates an input that is not one of the allowed values.
+var ErrInvalidEnum = errors.New("invalid enum value")
+
+// EnumError describes a value that is not allowed, with suggestions.
+type EnumError struct {
+	// Input is the original input.
+	Input string
+	// Allowed contains the accepted names.
+	Allowed []string
+	// Suggestions contains the closest accepted names.
+	Suggestions []string
+}
+
+// Error implements the error interface.
+func (e *EnumError) Error() string {
+	msg := fmt.Sprintf("%v: %q", ErrInvalidEnum, e.Input)
+	var hints []string
+	if len(e.Suggestions) > 0 {
+		quoted := make([]string, len(e.Suggestions))
+		for i, s := range e.Suggestions {
+			quoted[i] = fmt.Sprintf("%q", s)
+		}
+		hints = append(hints, "did you mean "+strings.Join(quoted, " or ")+"?")
+	}
+	if len(e.Allowed) > 0 {
+		hints = append(hints, "allowed: "+strings.Join(e.Allowed, ", "))
+	}
+	if len(hints) > 0 {
+		msg += " (" + strings.Join(hints, "; ") + ")"
+	}
+	return msg
+}
+
+// Unwrap allows errors.Is(err, ErrInvalidEnum).
+func (e *EnumError) Unwrap() error {
+	return ErrInvalidEnum
+}
+
+// Parser parses names into the values of an enumeration of type T.
+type Parser[T comparable] struct {
+	config        *interfaces.ParserConfig
+	values        map[string]T
+	names         []string
+	caseSensitive bool
+	maxDistance   int
+}
+
+// Option configures a Parser.
+type Option func(*options)
+
+type options struct {
+	caseSensitive bool
+	maxDistance   int
+}
+
+// WithCaseSensitive disables case folding.
+func WithCaseSensitive() Option {
+	return func(o *options) {
+		o.caseSensitive = true
+	}
+}
+
+// WithMaxDistance sets the maximum edit distance of suggestions. Zero disables
+// suggestions. By default it depends on the input length.
+func WithMaxDistance(distance int) Option {
+	return func(o *options) {
+		o.maxDistance = distance
+	}
+}
+
+// NewParser creates a parser for the enumeration described by values, which
+// maps names to values. Several names may map to the same value (aliases).
+func NewParser[T comparable](values map[string]T, opts ...Option) *Parser[T] {
+	o := options{maxDistance: -1}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	p := &Parser[T]{
+		config:        interfaces.DefaultConfig(),
+		values:        make(map[string]T, len(values)),
+		names:         make([]string, 0, len(values)),
+		caseSensitive: o.caseSensitive,
+		maxDistance:   o.maxDistance,
+	}
+	for name, value := range values {
+		p.values[p.fold(name)] = value
+		p.names = append(p.names, name)
+	}
+	sort.Strings(p.names)
+	return p
+}
+
+// NewParserWithConfig creates a parser with custom configuration.
+func NewParserWithConfig[T comparable](config *interfaces.ParserConfig, values map[string]T, opts ...Option) *Parser[T] {
+	parser := NewParser(values, opts...)
+	parser.config = config
+	return parser
+}
+
+// Of creates a parser for string-based enumerations, whose names are the values.
+func Of[T ~string](values []T, opts ...Option) *Parser[T] {
+	m := make(map[string]T, len(values))
+	for _, v := range values {
+		m[string(v)] = v
+	}
+	return NewParser(m, opts...)
+}
+
+// Parse implements interfaces.Parser.
+func (p *Parser[T]) Parse(ctx context.Context, data []byte) (*T, error) {
+	return p.ParseString(ctx, string(data))
+}
+
+// ParseString returns the value named by input.
+func (p *Parser[T]) ParseString(ctx context.Context, input string) (*T, error) {
+	if p.config.MaxSize > 0 && int64(len(input)) > p.config.MaxSize {
+		return nil, &interfaces.ParseError{
+			Type:    interfaces.ErrorTypeSize,
+			Message: fmt.Sprintf("enum length %d exceeds maximum %d", len(input), p.config.MaxSize),
+		}
+	}
+
+	name := strings.TrimSpace(input)
+	if value, ok := p.values[p.fold(name)]; ok {
+		return &value, nil
+	}
+
+	maxDistance := p.maxDistance
+	if maxDistance < 0 {
+		maxDistance = internal.DefaultMaxDistance(name)
+	}
+	enumErr := &EnumError{
+		Input:       input,
+		Allowed:     p.Names(),
+		Suggestions: internal.Closest(name, p.names, maxDistance),
+	}
+	return nil, &interfaces.ParseError{
+		Type:    interfaces.ErrorTypeValidation,
+		Message: enumErr.Error(),
+		Cause:   enumErr,
+	}
+}
+
+// Validate implements interfaces.Parser.
+func (p *Parser[T]) Validate(ctx context.Context, result *T) error {
+	if result == nil {
+		return &interfaces.ParseError{
+			Type:    interfaces.ErrorTypeValidation,
+			Message: "result cannot be nil",
+		}
+	}
+	return nil
+}
+
+// Names returns the accepted names, sorted.
+func (p *Parser[T]) Names() []string {
+	return append([]string(nil), p.names...)
+}
+
+func (p *Parser[T]) fold(name string) string {
+	if p.caseSensitive {
+		return name
+	}
+	return strings.ToLower(name)
+}
+
+// ParseEnum parses one of the allowed values of a string-based enumeration,
+// ignoring case unless WithCaseSensitive is given.
+func ParseEnum[T ~string](input string, allowed []T, opts ...Option) (T, error) {
+	value, err := Of(allowed, opts...).ParseString(context.Background(), input)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return *value, nil
+}
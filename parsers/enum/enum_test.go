@@ -0,0 +1,125 @@
+package enum
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fsvxavier/nexs-lib/parsers/interfaces"
+)
+
+type status string
+
+const (
+	statusActive   status = "active"
+	statusInactive status = "inactive"
+	statusPending  status = "pending"
+)
+
+var statuses = []status{statusActive, statusInactive, statusPending}
+
+func TestParseEnum(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected status
+	}{
+		{"active", statusActive},
+		{"ACTIVE", statusActive},
+		{" Pending ", statusPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			value, err := ParseEnum(tt.input, statuses)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if value != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, value)
+			}
+		})
+	}
+}
+
+func TestParseEnum_Invalid(t *testing.T) {
+	_, err := ParseEnum("actve", statuses)
+	if !errors.Is(err, ErrInvalidEnum) {
+		t.Fatalf("Expected ErrInvalidEnum, got %v", err)
+	}
+	var parseErr *interfaces.ParseError
+	if !errors.As(err, &parseErr) || parseErr.Type != interfaces.ErrorTypeValidation {
+		t.Errorf("Expected validation ParseError, got %v", err)
+	}
+
+	var enumErr *EnumError
+	if !errors.As(err, &enumErr) {
+		t.Fatalf("Expected EnumError, got %v", err)
+	}
+	if !reflect.DeepEqual(enumErr.Suggestions, []string{"active"}) {
+		t.Errorf("Unexpected suggestions %v", enumErr.Suggestions)
+	}
+	if !reflect.DeepEqual(enumErr.Allowed, []string{"active", "inactive", "pending"}) {
+		t.Errorf("Unexpected allowed values %v", enumErr.Allowed)
+	}
+	if !strings.Contains(err.Error(), `did you mean "active"?`) {
+		t.Errorf("Unexpected message %q", err.Error())
+	}
+
+	_, err = ParseEnum("unknown", statuses)
+	if !errors.As(err, &enumErr) || len(enumErr.Suggestions) != 0 {
+		t.Errorf("Expected no suggestions, got %v", err)
+	}
+}
+
+func TestParseEnum_Options(t *testing.T) {
+	if _, err := ParseEnum("Active", statuses, WithCaseSensitive()); err == nil {
+		t.Error("Expected case-sensitive parser to reject Active")
+	}
+
+	_, err := ParseEnum("actve", statuses, WithMaxDistance(0))
+	var enumErr *EnumError
+	if !errors.As(err, &enumErr) || len(enumErr.Suggestions) != 0 {
+		t.Errorf("Expected suggestions to be disabled, got %v", err)
+	}
+}
+
+type level int
+
+const (
+	levelDebug level = iota
+	levelInfo
+	levelWarn
+)
+
+func TestParser_IntEnum(t *testing.T) {
+	parser := NewParser(map[string]level{
+		"debug":   levelDebug,
+		"info":    levelInfo,
+		"warn":    levelWarn,
+		"warning": levelWarn,
+	})
+	ctx := context.Background()
+
+	value, err := parser.ParseString(ctx, "WARNING")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if *value != levelWarn {
+		t.Errorf("Expected warn, got %d", *value)
+	}
+
+	_, err = parser.ParseString(ctx, "debgu")
+	var enumErr *EnumError
+	if !errors.As(err, &enumErr) || !reflect.DeepEqual(enumErr.Suggestions, []string{"debug"}) {
+		t.Errorf("Expected debug suggestion, got %v", err)
+	}
+
+	if names := parser.Names(); !reflect.DeepEqual(names, []string{"debug", "info", "warn", "warning"}) {
+		t.Errorf("Unexpected names %v", names)
+	}
+	if err := parser.Validate(ctx, nil); err == nil {
+		t.Error("Expected error for nil result")
+	}
+}
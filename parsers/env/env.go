@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/fsvxavier/nexs-lib/parsers/boolx"
 	"github.com/fsvxavier/nexs-lib/parsers/interfaces"
 )
 
@@ -167,14 +168,15 @@ func (p *Parser) ParseFloat64(key string) *ParsedEnv {
 	return result
 }
 
-// ParseBool parses an environment variable as bool.
+// ParseBool parses an environment variable as bool, accepting the boolx
+// aliases such as "yes" and "off".
 func (p *Parser) ParseBool(key string) *ParsedEnv {
 	result := p.ParseEnvVar(key)
 	if !result.Found {
 		return result
 	}
 
-	if value, err := strconv.ParseBool(result.RawValue); err == nil {
+	if value, err := boolx.ParseBool(result.RawValue); err == nil {
 		result.Value = &value
 		result.Type = "bool"
 	} else {
@@ -375,7 +377,7 @@ func GetEnvInt(key string, defaultValue int) int {
 // GetEnvBool gets an environment variable as bool with a default value.
 func GetEnvBool(key string, defaultValue bool) bool {
 	if valueStr, exists := os.LookupEnv(key); exists {
-		if value, err := strconv.ParseBool(valueStr); err == nil {
+		if value, err := boolx.ParseBool(valueStr); err == nil {
 			return value
 		}
 	}
@@ -147,6 +147,8 @@ func TestParser_ParseBool(t *testing.T) {
 		{"0", false},
 		{"TRUE", true},
 		{"FALSE", false},
+		{"yes", true},
+		{"off", false},
 	}
 
 	for _, tt := range tests {
@@ -452,6 +454,8 @@ func TestParseEnvBool(t *testing.T) {
 		{"0", false},
 		{"TRUE", true},
 		{"FALSE", false},
+		{"yes", true},
+		{"off", false},
 	}
 
 	for _, tt := range tests {
@@ -3,6 +3,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"time"
@@ -16,6 +17,7 @@ func main() {
 
 	demonstrateBasicParsing()
 	demonstrateFormatDetection()
+	demonstrateFormatList()
 	demonstrateTimezoneParsing()
 	demonstrateFormatting()
 	demonstratePrecisionControl()
@@ -87,6 +89,34 @@ func demonstrateFormatDetection() {
 	fmt.Println()
 }
 
+func demonstrateFormatList() {
+	fmt.Println("2.1. Configurable Format List:")
+
+	// Brazilian inputs first, then the defaults (ISO, locale formats, Unix epoch)
+	formats := append([]string{"02/01/2006 15:04"}, datetime.DefaultFormats()...)
+
+	inputs := []string{"15/01/2025 14:30", "2025-01-15", "1736951400", "1736951400000", "15 de janeiro"}
+	for _, input := range inputs {
+		result, err := datetime.ParseAny(input,
+			datetime.WithFormats(formats...),
+			datetime.WithTimezone("America/Sao_Paulo"),
+			datetime.WithOutputLocation(time.UTC),
+		)
+
+		var formatErr *datetime.FormatError
+		switch {
+		case errors.As(err, &formatErr):
+			fmt.Printf("  %-20s -> ERROR: %v\n", input, formatErr.Suggestions[0])
+		case err != nil:
+			fmt.Printf("  %-20s -> ERROR: %v\n", input, err)
+		default:
+			fmt.Printf("  %-20s -> %s\n", input, result.Format(time.RFC3339))
+		}
+	}
+
+	fmt.Println()
+}
+
 func demonstrateTimezoneParsing() {
 	fmt.Println("3. Timezone Handling:")
 
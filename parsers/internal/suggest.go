@@ -0,0 +1,82 @@
+// Package internal provides helpers shared by the parsers.
+// This package is not part of the public API and should only be used internally.
+package internal
+
+import (
+	"sort"
+	"strings"
+)
+
+// MaxSuggestions limits the number of suggestions returned by Closest.
+const MaxSuggestions = 3
+
+// Levenshtein returns the edit distance between a and b, in runes.
+func Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 {
+		return len(rb)
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// DefaultMaxDistance is the distance accepted for a suggestion of input:
+// a third of its length rounded up, at most 3.
+func DefaultMaxDistance(input string) int {
+	return min(3, (len([]rune(input))+2)/3)
+}
+
+// Closest returns up to MaxSuggestions candidates within maxDistance of input,
+// nearest first. The comparison is case-insensitive.
+func Closest(input string, candidates []string, maxDistance int) []string {
+	type match struct {
+		candidate string
+		distance  int
+	}
+
+	if input == "" {
+		return nil
+	}
+
+	input = strings.ToLower(input)
+	var matches []match
+	seen := make(map[string]bool, len(candidates))
+	for _, candidate := range candidates {
+		if seen[candidate] {
+			continue
+		}
+		seen[candidate] = true
+		if d := Levenshtein(input, strings.ToLower(candidate)); d <= maxDistance {
+			matches = append(matches, match{candidate, d})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].distance < matches[j].distance
+	})
+	if len(matches) > MaxSuggestions {
+		matches = matches[:MaxSuggestions]
+	}
+
+	result := make([]string, len(matches))
+	for i, m := range matches {
+		result[i] = m.candidate
+	}
+	return result
+}
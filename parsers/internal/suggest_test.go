@@ -0,0 +1,60 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"abc", "abc", 0},
+		{"kitten", "sitting", 3},
+		{"actve", "active", 1},
+		{"ação", "acao", 2},
+	}
+
+	for _, tt := range tests {
+		if got := Levenshtein(tt.a, tt.b); got != tt.expected {
+			t.Errorf("Levenshtein(%q, %q) = %d, expected %d", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
+
+func TestClosest(t *testing.T) {
+	candidates := []string{"active", "inactive", "pending", "Archived"}
+
+	tests := []struct {
+		input    string
+		distance int
+		expected []string
+	}{
+		{"actve", 2, []string{"active"}},
+		{"ARCHIVE", 1, []string{"Archived"}},
+		{"xyz", 2, []string{}},
+		{"", 3, nil},
+		{"ctive", 3, []string{"active", "inactive"}},
+	}
+
+	for _, tt := range tests {
+		if got := Closest(tt.input, candidates, tt.distance); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("Closest(%q) = %v, expected %v", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestDefaultMaxDistance(t *testing.T) {
+	if d := DefaultMaxDistance("ab"); d != 1 {
+		t.Errorf("Expected 1, got %d", d)
+	}
+	if d := DefaultMaxDistance("yess"); d != 2 {
+		t.Errorf("Expected 2, got %d", d)
+	}
+	if d := DefaultMaxDistance("a very long value"); d != 3 {
+		t.Errorf("Expected 3, got %d", d)
+	}
+}
@@ -0,0 +1,200 @@
+package json
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	dinterfaces "github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/fsvxavier/nexs-lib/parsers/interfaces"
+)
+
+// DefaultMaxLineSize is the default maximum size of an NDJSON line.
+const DefaultMaxLineSize = 1 << 20
+
+// Codes of the domain errors reported for skipped NDJSON lines.
+const (
+	CodeMalformedLine = "NDJSON_MALFORMED_LINE"
+	CodeLineTooLong   = "NDJSON_LINE_TOO_LONG"
+)
+
+var (
+	// ErrMalformedLine indicates an NDJSON line that is not valid JSON for the target type.
+	ErrMalformedLine = errors.New("malformed ndjson line")
+	// ErrLineTooLong indicates an NDJSON line larger than the maximum line size.
+	ErrLineTooLong = errors.New("ndjson line too long")
+)
+
+// Record is a decoded NDJSON line. Malformed and oversized lines have a
+// zero Value and Err set to a validation domain error with the line number
+// in its "line" metadata.
+type Record[T any] struct {
+	// Line is the line number, starting at 1.
+	Line  int
+	Value T
+	Err   error
+}
+
+// NDJSONDecoder decodes newline-delimited JSON (JSON Lines) into values of
+// type T, one line at a time. Blank lines are ignored and malformed lines
+// are skipped, so one bad line does not stop the stream.
+type NDJSONDecoder[T any] struct {
+	ndjsonOptions
+}
+
+// NDJSONOption configures an NDJSONDecoder.
+type NDJSONOption func(*ndjsonOptions)
+
+type ndjsonOptions struct {
+	maxLineSize int
+	buffer      int
+}
+
+// WithMaxLineSize sets the maximum line size in bytes, excluding the line
+// terminator. Larger lines are skipped. The default is DefaultMaxLineSize.
+func WithMaxLineSize(size int) NDJSONOption {
+	return func(o *ndjsonOptions) {
+		o.maxLineSize = size
+	}
+}
+
+// WithRecordBuffer sets the capacity of the channel returned by Records. The
+// default is unbuffered, so decoding waits for the consumer.
+func WithRecordBuffer(size int) NDJSONOption {
+	return func(o *ndjsonOptions) {
+		o.buffer = size
+	}
+}
+
+// NewNDJSONDecoder creates an NDJSON decoder for values of type T.
+func NewNDJSONDecoder[T any](opts ...NDJSONOption) *NDJSONDecoder[T] {
+	o := ndjsonOptions{maxLineSize: DefaultMaxLineSize}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.maxLineSize <= 0 {
+		o.maxLineSize = DefaultMaxLineSize
+	}
+	return &NDJSONDecoder[T]{ndjsonOptions: o}
+}
+
+// Decode reads the lines of reader and calls fn with each record, including
+// the skipped lines, which have Err set. The next line is read only after fn
+// returns, providing backpressure. Decoding stops at the end of the input,
+// when ctx is done, when fn returns an error or on read errors.
+func (d *NDJSONDecoder[T]) Decode(ctx context.Context, reader io.Reader, fn func(Record[T]) error) error {
+	br := bufio.NewReaderSize(reader, d.maxLineSize+2)
+	for line := 1; ; line++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		data, tooLong, err := readLine(br)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return &interfaces.ParseError{
+				Type:    interfaces.ErrorTypeIO,
+				Message: fmt.Sprintf("failed to read ndjson line %d", line),
+				Line:    line,
+				Cause:   err,
+			}
+		}
+
+		data = bytes.TrimSpace(data)
+		if tooLong || len(data) > d.maxLineSize {
+			if fnErr := fn(Record[T]{Line: line, Err: lineTooLong(line, d.maxLineSize)}); fnErr != nil {
+				return fnErr
+			}
+		} else if len(data) > 0 {
+			record := Record[T]{Line: line}
+			if unmarshalErr := jsonInstance.Unmarshal(data, &record.Value); unmarshalErr != nil {
+				record = Record[T]{Line: line, Err: malformedLine(line, unmarshalErr)}
+			}
+			if fnErr := fn(record); fnErr != nil {
+				return fnErr
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+	}
+}
+
+// Records decodes reader in a goroutine, sending each record on the
+// returned channel, which is closed at the end. Decoding waits for the
+// consumer, so the channel must be drained or ctx canceled. wait returns the
+// error that stopped decoding, after the channel is closed.
+func (d *NDJSONDecoder[T]) Records(ctx context.Context, reader io.Reader) (records <-chan Record[T], wait func() error) {
+	ch := make(chan Record[T], d.buffer)
+	done := make(chan struct{})
+	var err error
+
+	go func() {
+		defer close(done)
+		defer close(ch)
+		err = d.Decode(ctx, reader, func(record Record[T]) error {
+			select {
+			case ch <- record:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+
+	return ch, func() error {
+		<-done
+		return err
+	}
+}
+
+// ParseStream implements interfaces.StreamParser, calling callback with each
+// decoded value. Skipped lines do not stop the stream; their errors are
+// returned joined at the end.
+func (d *NDJSONDecoder[T]) ParseStream(ctx context.Context, reader io.Reader, callback func(*T) error) error {
+	var skipped []error
+	err := d.Decode(ctx, reader, func(record Record[T]) error {
+		if record.Err != nil {
+			skipped = append(skipped, record.Err)
+			return nil
+		}
+		return callback(&record.Value)
+	})
+	if err != nil {
+		return err
+	}
+	return errors.Join(skipped...)
+}
+
+// readLine reads a line, discarding the rest of lines larger than the
+// reader buffer.
+func readLine(br *bufio.Reader) (line []byte, tooLong bool, err error) {
+	line, err = br.ReadSlice('\n')
+	if !errors.Is(err, bufio.ErrBufferFull) {
+		return line, false, err
+	}
+	for errors.Is(err, bufio.ErrBufferFull) {
+		_, err = br.ReadSlice('\n')
+	}
+	return nil, true, err
+}
+
+// malformedLine creates the domain error of a line that cannot be decoded.
+func malformedLine(line int, cause error) error {
+	return domainerrors.NewWithMetadata(dinterfaces.ValidationError, CodeMalformedLine,
+		fmt.Sprintf("malformed JSON at line %d", line),
+		map[string]interface{}{"line": line}).
+		Wrap(fmt.Errorf("%w: %w", ErrMalformedLine, cause))
+}
+
+// lineTooLong creates the domain error of a line larger than the maximum.
+func lineTooLong(line, maxLineSize int) error {
+	return domainerrors.NewWithMetadata(dinterfaces.ValidationError, CodeLineTooLong,
+		fmt.Sprintf("line %d exceeds the maximum size of %d bytes", line, maxLineSize),
+		map[string]interface{}{"line": line, "max_line_size": maxLineSize}).
+		Wrap(ErrLineTooLong)
+}
@@ -0,0 +1,156 @@
+package json
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	dinterfaces "github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+type event struct {
+	ID   int    `json:"id"`
+	Kind string `json:"kind"`
+}
+
+const events = `{"id":1,"kind":"created"}
+{"id":2,"kind":
+
+{"id":"three","kind":"updated"}
+{"id":4,"kind":"` + "deleted" + `","payload":"` + "xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx" + `"}
+{"id":5,"kind":"archived"}`
+
+func TestNDJSONDecoder_SkipsMalformedLines(t *testing.T) {
+	decoder := NewNDJSONDecoder[event](WithMaxLineSize(64))
+
+	var ids []int
+	var skipped []Record[event]
+	err := decoder.Decode(context.Background(), strings.NewReader(events), func(record Record[event]) error {
+		if record.Err != nil {
+			skipped = append(skipped, record)
+			return nil
+		}
+		ids = append(ids, record.Value.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 5 {
+		t.Errorf("Expected ids [1 5], got %v", ids)
+	}
+
+	expected := []struct {
+		line int
+		code string
+		err  error
+	}{
+		{2, CodeMalformedLine, ErrMalformedLine},
+		{4, CodeMalformedLine, ErrMalformedLine},
+		{5, CodeLineTooLong, ErrLineTooLong},
+	}
+	if len(skipped) != len(expected) {
+		t.Fatalf("Expected %d skipped lines, got %d", len(expected), len(skipped))
+	}
+	for i, want := range expected {
+		record := skipped[i]
+		var domainErr dinterfaces.DomainErrorInterface
+		if !errors.As(record.Err, &domainErr) {
+			t.Fatalf("Expected a domain error, got %T", record.Err)
+		}
+		if record.Line != want.line || domainErr.Code() != want.code || domainErr.Metadata()["line"] != want.line {
+			t.Errorf("Unexpected error at line %d: %v %v", record.Line, domainErr.Code(), domainErr.Metadata())
+		}
+		if !domainerrors.IsType(record.Err, dinterfaces.ValidationError) || !errors.Is(record.Err, want.err) {
+			t.Errorf("Unexpected error chain: %v", record.Err)
+		}
+	}
+}
+
+func TestNDJSONDecoder_ParseStream(t *testing.T) {
+	decoder := NewNDJSONDecoder[event](WithMaxLineSize(64))
+
+	count := 0
+	err := decoder.ParseStream(context.Background(), strings.NewReader(events), func(*event) error {
+		count++
+		return nil
+	})
+	if count != 2 {
+		t.Errorf("Expected 2 events, got %d", count)
+	}
+	if !errors.Is(err, ErrMalformedLine) || !errors.Is(err, ErrLineTooLong) {
+		t.Errorf("Expected the skipped lines joined, got %v", err)
+	}
+
+	stop := errors.New("stop")
+	err = decoder.ParseStream(context.Background(), strings.NewReader(events), func(*event) error { return stop })
+	if !errors.Is(err, stop) {
+		t.Errorf("Expected the callback error, got %v", err)
+	}
+}
+
+func TestNDJSONDecoder_Records(t *testing.T) {
+	input := strings.Repeat(`{"id":1,"kind":"created"}`+"\r\n", 100)
+	records, wait := NewNDJSONDecoder[event]().Records(context.Background(), strings.NewReader(input))
+
+	count := 0
+	for record := range records {
+		if record.Err != nil || record.Value.ID != 1 {
+			t.Fatalf("Unexpected record %+v", record)
+		}
+		count++
+	}
+	if err := wait(); err != nil || count != 100 {
+		t.Errorf("Expected 100 records, got %d, %v", count, err)
+	}
+}
+
+func TestNDJSONDecoder_RecordsBackpressure(t *testing.T) {
+	reader := &countingReader{Reader: strings.NewReader(strings.Repeat(`{"id":1}`+"\n", 10000))}
+	ctx, cancel := context.WithCancel(context.Background())
+	records, wait := NewNDJSONDecoder[event](WithRecordBuffer(1), WithMaxLineSize(16)).Records(ctx, reader)
+
+	<-records
+	cancel()
+	for range records {
+	}
+	if err := wait(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	// Sem consumidor, o decoder não lê toda a entrada
+	if reader.n >= 10000*9 {
+		t.Errorf("Expected the decoder to wait for the consumer, read %d bytes", reader.n)
+	}
+}
+
+type countingReader struct {
+	io.Reader
+	n int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.n += n
+	return n, err
+}
+
+func TestNDJSONDecoder_ReadError(t *testing.T) {
+	failure := errors.New("connection reset")
+	reader := io.MultiReader(strings.NewReader(`{"id":1}`+"\n"), &failingReader{err: failure})
+
+	err := NewNDJSONDecoder[event]().Decode(context.Background(), reader, func(Record[event]) error { return nil })
+	if !errors.Is(err, failure) {
+		t.Errorf("Expected the read error, got %v", err)
+	}
+}
+
+type failingReader struct {
+	err error
+}
+
+func (r *failingReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
@@ -5,12 +5,18 @@ import (
 	"context"
 	"time"
 
+	"github.com/fsvxavier/nexs-lib/parsers/acceptlang"
+	"github.com/fsvxavier/nexs-lib/parsers/boolx"
+	"github.com/fsvxavier/nexs-lib/parsers/bytesize"
+	"github.com/fsvxavier/nexs-lib/parsers/csv"
 	"github.com/fsvxavier/nexs-lib/parsers/datetime"
+	"github.com/fsvxavier/nexs-lib/parsers/decimal"
 	"github.com/fsvxavier/nexs-lib/parsers/duration"
 	"github.com/fsvxavier/nexs-lib/parsers/env"
 	"github.com/fsvxavier/nexs-lib/parsers/interfaces"
 	"github.com/fsvxavier/nexs-lib/parsers/json"
 	"github.com/fsvxavier/nexs-lib/parsers/url"
+	"github.com/fsvxavier/nexs-lib/parsers/useragent"
 )
 
 // Version of the parsers package.
@@ -55,11 +61,42 @@ func (f *Factory) Duration() *duration.Parser {
 	return duration.NewParserWithConfig(f.config)
 }
 
+// Bool creates a new boolean parser with alias tables.
+func (f *Factory) Bool(opts ...boolx.Option) *boolx.Parser {
+	return boolx.NewParserWithConfig(f.config, opts...)
+}
+
+// Decimal creates a new locale-aware decimal parser.
+func (f *Factory) Decimal(opts ...decimal.Option) *decimal.Parser {
+	return decimal.NewParserWithConfig(f.config, opts...)
+}
+
+// ByteSize creates a new data size parser.
+func (f *Factory) ByteSize(opts ...bytesize.Option) *bytesize.Parser {
+	return bytesize.NewParserWithConfig(f.config, opts...)
+}
+
+// UserAgent creates a new User-Agent parser.
+func (f *Factory) UserAgent(opts ...useragent.Option) *useragent.Parser {
+	return useragent.NewParserWithConfig(f.config, opts...)
+}
+
+// AcceptLanguage creates a new Accept-Language parser.
+func (f *Factory) AcceptLanguage() *acceptlang.Parser {
+	return acceptlang.NewParserWithConfig(f.config)
+}
+
 // Env creates a new environment variable parser.
 func (f *Factory) Env() *env.Parser {
 	return env.NewParserWithConfig(f.config)
 }
 
+// CSVTyped creates a new CSV/TSV parser for the struct type T. Go methods
+// cannot have type parameters, so it takes the factory as an argument.
+func CSVTyped[T any](f *Factory, opts ...csv.Option) *csv.Parser[T] {
+	return csv.NewParserWithConfig[T](f.config, opts...)
+}
+
 // Manager provides high-level parsing operations with context and metadata.
 type Manager struct {
 	factory *Factory
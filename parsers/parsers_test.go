@@ -62,6 +62,46 @@ func TestFactory_Duration(t *testing.T) {
 	}
 }
 
+func TestFactory_Bool(t *testing.T) {
+	factory := NewFactory()
+	parser := factory.Bool()
+	if parser == nil {
+		t.Error("Expected Bool parser to be created")
+	}
+}
+
+func TestFactory_Decimal(t *testing.T) {
+	factory := NewFactory()
+	parser := factory.Decimal()
+	if parser == nil {
+		t.Error("Expected Decimal parser to be created")
+	}
+}
+
+func TestFactory_ByteSize(t *testing.T) {
+	factory := NewFactory()
+	parser := factory.ByteSize()
+	if parser == nil {
+		t.Error("Expected ByteSize parser to be created")
+	}
+}
+
+func TestFactory_UserAgent(t *testing.T) {
+	factory := NewFactory()
+	parser := factory.UserAgent()
+	if parser == nil {
+		t.Error("Expected UserAgent parser to be created")
+	}
+}
+
+func TestFactory_AcceptLanguage(t *testing.T) {
+	factory := NewFactory()
+	parser := factory.AcceptLanguage()
+	if parser == nil {
+		t.Error("Expected AcceptLanguage parser to be created")
+	}
+}
+
 func TestFactory_Env(t *testing.T) {
 	factory := NewFactory()
 	parser := factory.Env()
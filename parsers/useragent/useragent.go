@@ -0,0 +1,368 @@
+// Package useragent classifies User-Agent headers by browser, operating
+// system, device and bot, for request logging and analytics.
+package useragent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/fsvxavier/nexs-lib/parsers/interfaces"
+)
+
+// Class is the kind of agent.
+type Class string
+
+// Agent classes.
+const (
+	// ClassBrowser is a web browser.
+	ClassBrowser Class = "browser"
+	// ClassBot is a crawler, spider or monitoring bot.
+	ClassBot Class = "bot"
+	// ClassClient is an HTTP library or command line tool, such as curl.
+	ClassClient Class = "client"
+	// ClassUnknown is an agent not recognized.
+	ClassUnknown Class = "unknown"
+)
+
+// Device is the kind of device of a browser.
+type Device string
+
+// Device kinds.
+const (
+	DeviceDesktop Device = "desktop"
+	DeviceMobile  Device = "mobile"
+	DeviceTablet  Device = "tablet"
+	DeviceUnknown Device = "unknown"
+)
+
+// UserAgent is a classified User-Agent value. Name and Version identify the
+// browser, bot or client, depending on the class.
+type UserAgent struct {
+	Raw       string
+	Class     Class
+	Name      string
+	Version   string
+	OS        string
+	OSVersion string
+	Device    Device
+}
+
+// IsBot reports whether the agent is a bot.
+func (u *UserAgent) IsBot() bool {
+	return u.Class == ClassBot
+}
+
+// IsMobile reports whether the agent runs on a phone or a tablet.
+func (u *UserAgent) IsMobile() bool {
+	return u.Device == DeviceMobile || u.Device == DeviceTablet
+}
+
+// String returns a short description such as "Chrome 124.0 on Windows 10".
+func (u *UserAgent) String() string {
+	s := u.Name
+	if s == "" {
+		s = string(u.Class)
+	}
+	if u.Version != "" {
+		s += " " + u.Version
+	}
+	if u.OS != "" {
+		s += " on " + strings.TrimSpace(u.OS+" "+u.OSVersion)
+	}
+	return s
+}
+
+// Fields returns the classification as log fields, omitting empty values.
+func (u *UserAgent) Fields() map[string]interface{} {
+	fields := map[string]interface{}{
+		"ua_class":  string(u.Class),
+		"ua_device": string(u.Device),
+	}
+	for key, value := range map[string]string{
+		"ua_name":       u.Name,
+		"ua_version":    u.Version,
+		"ua_os":         u.OS,
+		"ua_os_version": u.OSVersion,
+	} {
+		if value != "" {
+			fields[key] = value
+		}
+	}
+	return fields
+}
+
+// agent maps a token of the User-Agent to a name. The version follows the
+// token, when it ends with '/'.
+type agent struct {
+	token string
+	name  string
+}
+
+// defaultBots are matched case-insensitively, before the generic bot words.
+var defaultBots = []agent{
+	{"googlebot/", "Googlebot"},
+	{"google-inspectiontool/", "Google-InspectionTool"},
+	{"adsbot-google", "AdsBot-Google"},
+	{"bingbot/", "Bingbot"},
+	{"yandexbot/", "YandexBot"},
+	{"baiduspider/", "Baiduspider"},
+	{"duckduckbot/", "DuckDuckBot"},
+	{"slurp", "Yahoo! Slurp"},
+	{"applebot/", "Applebot"},
+	{"facebookexternalhit/", "Facebook"},
+	{"twitterbot/", "Twitterbot"},
+	{"linkedinbot/", "LinkedInBot"},
+	{"slackbot", "Slackbot"},
+	{"discordbot/", "Discordbot"},
+	{"telegrambot", "TelegramBot"},
+	{"whatsapp/", "WhatsApp"},
+	{"ahrefsbot/", "AhrefsBot"},
+	{"semrushbot/", "SemrushBot"},
+	{"petalbot", "PetalBot"},
+	{"gptbot/", "GPTBot"},
+	{"uptimerobot/", "UptimeRobot"},
+	{"pingdom", "Pingdom"},
+	{"headlesschrome/", "HeadlessChrome"},
+}
+
+// botWords classify unknown agents as bots.
+var botWords = []string{"bot", "crawler", "spider", "crawl", "scraper", "monitor"}
+
+// clients are HTTP libraries and tools, matched case-insensitively.
+var clients = []agent{
+	{"curl/", "curl"},
+	{"wget/", "Wget"},
+	{"python-requests/", "python-requests"},
+	{"python-urllib/", "Python-urllib"},
+	{"aiohttp/", "aiohttp"},
+	{"httpx/", "httpx"},
+	{"go-http-client/", "Go-http-client"},
+	{"okhttp/", "okhttp"},
+	{"apache-httpclient/", "Apache-HttpClient"},
+	{"java/", "Java"},
+	{"axios/", "axios"},
+	{"node-fetch/", "node-fetch"},
+	{"postmanruntime/", "PostmanRuntime"},
+	{"insomnia/", "Insomnia"},
+	{"grpc-", "gRPC"},
+}
+
+// browsers are matched in order, as browsers include the tokens of the
+// browsers they derive from.
+var browsers = []agent{
+	{"edg/", "Edge"},
+	{"edga/", "Edge"},
+	{"edgios/", "Edge"},
+	{"edge/", "Edge"},
+	{"opr/", "Opera"},
+	{"opera/", "Opera"},
+	{"samsungbrowser/", "Samsung Internet"},
+	{"yabrowser/", "Yandex Browser"},
+	{"vivaldi/", "Vivaldi"},
+	{"ucbrowser/", "UC Browser"},
+	{"crios/", "Chrome"},
+	{"fxios/", "Firefox"},
+	{"firefox/", "Firefox"},
+	{"chromium/", "Chromium"},
+	{"chrome/", "Chrome"},
+	{"msie ", "Internet Explorer"},
+	{"trident/", "Internet Explorer"},
+}
+
+// windowsVersions maps Windows NT versions to the marketing versions.
+var windowsVersions = map[string]string{
+	"10.0": "10",
+	"6.3":  "8.1",
+	"6.2":  "8",
+	"6.1":  "7",
+	"6.0":  "Vista",
+	"5.1":  "XP",
+}
+
+// Parser implements User-Agent classification.
+type Parser struct {
+	config *interfaces.ParserConfig
+	bots   []agent
+}
+
+// Option configures a Parser.
+type Option func(*Parser)
+
+// WithBots adds bot tokens, matched case-insensitively before the default
+// ones. The token is also the reported name.
+func WithBots(tokens ...string) Option {
+	return func(p *Parser) {
+		bots := make([]agent, 0, len(tokens)+len(p.bots))
+		for _, token := range tokens {
+			bots = append(bots, agent{token: strings.ToLower(token), name: token})
+		}
+		p.bots = append(bots, p.bots...)
+	}
+}
+
+// NewParser creates a new User-Agent parser with default configuration.
+func NewParser(opts ...Option) *Parser {
+	p := &Parser{
+		config: interfaces.DefaultConfig(),
+		bots:   defaultBots,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// NewParserWithConfig creates a new User-Agent parser with custom configuration.
+func NewParserWithConfig(config *interfaces.ParserConfig, opts ...Option) *Parser {
+	parser := NewParser(opts...)
+	parser.config = config
+	return parser
+}
+
+// Parse implements interfaces.Parser.
+func (p *Parser) Parse(ctx context.Context, data []byte) (*UserAgent, error) {
+	return p.ParseString(ctx, string(data))
+}
+
+// ParseString classifies a User-Agent value. Unrecognized values are not an
+// error; they result in ClassUnknown.
+func (p *Parser) ParseString(ctx context.Context, input string) (*UserAgent, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return nil, &interfaces.ParseError{
+			Type:    interfaces.ErrorTypeValidation,
+			Message: "input user agent is empty",
+		}
+	}
+	if p.config.MaxSize > 0 && int64(len(input)) > p.config.MaxSize {
+		return nil, &interfaces.ParseError{
+			Type:    interfaces.ErrorTypeSize,
+			Message: fmt.Sprintf("user agent length %d exceeds maximum %d", len(input), p.config.MaxSize),
+		}
+	}
+
+	lower := strings.ToLower(input)
+	ua := &UserAgent{Raw: input, Class: ClassUnknown, Device: DeviceUnknown}
+	ua.OS, ua.OSVersion = detectOS(input, lower)
+
+	if name, version, ok := match(input, lower, p.bots); ok {
+		ua.Class, ua.Name, ua.Version = ClassBot, name, version
+	} else if name, version, ok := match(input, lower, clients); ok {
+		ua.Class, ua.Name, ua.Version = ClassClient, name, version
+	} else if name, version, ok := detectBrowser(input, lower); ok {
+		ua.Class, ua.Name, ua.Version = ClassBrowser, name, version
+		ua.Device = detectDevice(lower, ua.OS)
+	}
+
+	if ua.Class == ClassUnknown || ua.Class == ClassBrowser {
+		for _, word := range botWords {
+			if strings.Contains(lower, word) {
+				ua.Class, ua.Name, ua.Version, ua.Device = ClassBot, "", "", DeviceUnknown
+				break
+			}
+		}
+	}
+	return ua, nil
+}
+
+// Validate checks that the result is not nil.
+func (p *Parser) Validate(ctx context.Context, result *UserAgent) error {
+	if result == nil {
+		return &interfaces.ParseError{
+			Type:    interfaces.ErrorTypeValidation,
+			Message: "result cannot be nil",
+		}
+	}
+	return nil
+}
+
+// match returns the name and version of the first agent whose token is in
+// the lowercase input.
+func match(input, lower string, agents []agent) (name, version string, ok bool) {
+	for _, a := range agents {
+		if i := strings.Index(lower, a.token); i >= 0 {
+			return a.name, versionAt(input, i+len(a.token)), true
+		}
+	}
+	return "", "", false
+}
+
+// detectBrowser detects the browser, handling Safari and Internet Explorer,
+// whose versions are not after the browser token.
+func detectBrowser(input, lower string) (name, version string, ok bool) {
+	name, version, ok = match(input, lower, browsers)
+	switch {
+	case ok && name == "Internet Explorer" && !strings.Contains(lower, "msie "):
+		if i := strings.Index(lower, "rv:"); i >= 0 {
+			version = versionAt(input, i+len("rv:"))
+		}
+	case !ok && strings.Contains(lower, "safari/"):
+		name, ok = "Safari", true
+		if i := strings.Index(lower, "version/"); i >= 0 {
+			version = versionAt(input, i+len("version/"))
+		}
+	}
+	return name, version, ok
+}
+
+// detectOS returns the operating system and its version.
+func detectOS(input, lower string) (os, version string) {
+	switch {
+	case strings.Contains(lower, "windows nt "):
+		nt := versionAt(input, strings.Index(lower, "windows nt ")+len("windows nt "))
+		if v, ok := windowsVersions[nt]; ok {
+			nt = v
+		}
+		return "Windows", nt
+	case strings.Contains(lower, "windows"):
+		return "Windows", ""
+	case strings.Contains(lower, "iphone") || strings.Contains(lower, "ipad") || strings.Contains(lower, "ipod"):
+		if i := strings.Index(lower, " os "); i >= 0 {
+			version = versionAt(input, i+len(" os "))
+		}
+		return "iOS", version
+	case strings.Contains(lower, "android"):
+		return "Android", versionAt(input, strings.Index(lower, "android")+len("android "))
+	case strings.Contains(lower, "cros "):
+		return "ChromeOS", ""
+	case strings.Contains(lower, "mac os x"):
+		return "macOS", versionAt(input, strings.Index(lower, "mac os x")+len("mac os x "))
+	case strings.Contains(lower, "linux"):
+		return "Linux", ""
+	}
+	return "", ""
+}
+
+// detectDevice returns the device of a browser.
+func detectDevice(lower, os string) Device {
+	switch {
+	case strings.Contains(lower, "ipad") || strings.Contains(lower, "tablet"):
+		return DeviceTablet
+	case os == "Android" && !strings.Contains(lower, "mobile"):
+		return DeviceTablet
+	case strings.Contains(lower, "mobi") || os == "iOS" || os == "Android":
+		return DeviceMobile
+	case os == "Windows" || os == "macOS" || os == "Linux" || os == "ChromeOS":
+		return DeviceDesktop
+	}
+	return DeviceUnknown
+}
+
+// versionAt returns the version starting at i, with underscores replaced by
+// dots, as in "Mac OS X 10_15_7".
+func versionAt(input string, i int) string {
+	if i < 0 || i > len(input) {
+		return ""
+	}
+	end := i
+	for end < len(input) && (input[end] >= '0' && input[end] <= '9' || input[end] == '.' || input[end] == '_') {
+		end++
+	}
+	return strings.Trim(strings.ReplaceAll(input[i:end], "_", "."), ".")
+}
+
+// ParseUserAgent classifies a User-Agent value.
+func ParseUserAgent(input string, opts ...Option) (*UserAgent, error) {
+	return NewParser(opts...).ParseString(context.Background(), input)
+}
@@ -0,0 +1,173 @@
+package useragent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fsvxavier/nexs-lib/parsers/interfaces"
+)
+
+func TestParser_ParseString(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected UserAgent
+	}{
+		{
+			name:  "chrome on windows",
+			input: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.6367.91 Safari/537.36",
+			expected: UserAgent{
+				Class: ClassBrowser, Name: "Chrome", Version: "124.0.6367.91",
+				OS: "Windows", OSVersion: "10", Device: DeviceDesktop,
+			},
+		},
+		{
+			name:  "edge on windows",
+			input: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36 Edg/124.0.2478.67",
+			expected: UserAgent{
+				Class: ClassBrowser, Name: "Edge", Version: "124.0.2478.67",
+				OS: "Windows", OSVersion: "10", Device: DeviceDesktop,
+			},
+		},
+		{
+			name:  "safari on iphone",
+			input: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4.1 Mobile/15E148 Safari/604.1",
+			expected: UserAgent{
+				Class: ClassBrowser, Name: "Safari", Version: "17.4.1",
+				OS: "iOS", OSVersion: "17.4.1", Device: DeviceMobile,
+			},
+		},
+		{
+			name:  "firefox on macos",
+			input: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10.15; rv:125.0) Gecko/20100101 Firefox/125.0",
+			expected: UserAgent{
+				Class: ClassBrowser, Name: "Firefox", Version: "125.0",
+				OS: "macOS", OSVersion: "10.15", Device: DeviceDesktop,
+			},
+		},
+		{
+			name:  "samsung internet on android phone",
+			input: "Mozilla/5.0 (Linux; Android 14; SM-S918B) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/24.0 Chrome/117.0.0.0 Mobile Safari/537.36",
+			expected: UserAgent{
+				Class: ClassBrowser, Name: "Samsung Internet", Version: "24.0",
+				OS: "Android", OSVersion: "14", Device: DeviceMobile,
+			},
+		},
+		{
+			name:  "chrome on android tablet",
+			input: "Mozilla/5.0 (Linux; Android 13; SM-X700) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+			expected: UserAgent{
+				Class: ClassBrowser, Name: "Chrome", Version: "124.0.0.0",
+				OS: "Android", OSVersion: "13", Device: DeviceTablet,
+			},
+		},
+		{
+			name:  "internet explorer 11",
+			input: "Mozilla/5.0 (Windows NT 6.1; WOW64; Trident/7.0; rv:11.0) like Gecko",
+			expected: UserAgent{
+				Class: ClassBrowser, Name: "Internet Explorer", Version: "11.0",
+				OS: "Windows", OSVersion: "7", Device: DeviceDesktop,
+			},
+		},
+		{
+			name:  "googlebot",
+			input: "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			expected: UserAgent{
+				Class: ClassBot, Name: "Googlebot", Version: "2.1", Device: DeviceUnknown,
+			},
+		},
+		{
+			name:  "generic crawler",
+			input: "Mozilla/5.0 (X11; Linux x86_64) Chrome/120.0 Safari/537.36 (compatible; ExampleCrawler/1.0)",
+			expected: UserAgent{
+				Class: ClassBot, OS: "Linux", Device: DeviceUnknown,
+			},
+		},
+		{
+			name:  "curl",
+			input: "curl/8.5.0",
+			expected: UserAgent{
+				Class: ClassClient, Name: "curl", Version: "8.5.0", Device: DeviceUnknown,
+			},
+		},
+		{
+			name:  "go http client",
+			input: "Go-http-client/1.1",
+			expected: UserAgent{
+				Class: ClassClient, Name: "Go-http-client", Version: "1.1", Device: DeviceUnknown,
+			},
+		},
+		{
+			name:     "unknown",
+			input:    "ACME Terminal",
+			expected: UserAgent{Class: ClassUnknown, Device: DeviceUnknown},
+		},
+	}
+
+	parser := NewParser()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parser.ParseString(context.Background(), tt.input)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			tt.expected.Raw = tt.input
+			if *result != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, *result)
+			}
+		})
+	}
+}
+
+func TestParser_WithBots(t *testing.T) {
+	input := "Mozilla/5.0 (Windows NT 10.0) Chrome/124.0 Safari/537.36 InternalProbe/3.2"
+
+	result, err := ParseUserAgent(input)
+	if err != nil || result.Class != ClassBrowser {
+		t.Fatalf("Expected a browser, got %+v, %v", result, err)
+	}
+
+	result, err = ParseUserAgent(input, WithBots("InternalProbe/"))
+	if err != nil || !result.IsBot() || result.Name != "InternalProbe/" || result.Version != "3.2" {
+		t.Errorf("Expected the custom bot, got %+v, %v", result, err)
+	}
+}
+
+func TestUserAgent_Fields(t *testing.T) {
+	result, err := ParseUserAgent("Mozilla/5.0 (iPad; CPU OS 16_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/124.0.6367.88 Mobile/15E148 Safari/604.1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !result.IsMobile() || result.Device != DeviceTablet || result.Name != "Chrome" {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	if result.String() != "Chrome 124.0.6367.88 on iOS 16.6" {
+		t.Errorf("Unexpected description %q", result.String())
+	}
+
+	fields := result.Fields()
+	if fields["ua_class"] != "browser" || fields["ua_os_version"] != "16.6" || fields["ua_device"] != "tablet" {
+		t.Errorf("Unexpected fields %v", fields)
+	}
+
+	curl, _ := ParseUserAgent("curl/8.5.0")
+	if _, ok := curl.Fields()["ua_os"]; ok {
+		t.Errorf("Expected empty values omitted, got %v", curl.Fields())
+	}
+}
+
+func TestParser_Errors(t *testing.T) {
+	var parseErr *interfaces.ParseError
+	if _, err := ParseUserAgent("  "); !errors.As(err, &parseErr) || parseErr.Type != interfaces.ErrorTypeValidation {
+		t.Errorf("Expected a validation error, got %v", err)
+	}
+
+	config := interfaces.DefaultConfig()
+	config.MaxSize = 16
+	_, err := NewParserWithConfig(config).ParseString(context.Background(), strings.Repeat("a", 17))
+	if !errors.As(err, &parseErr) || parseErr.Type != interfaces.ErrorTypeSize {
+		t.Errorf("Expected a size error, got %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package pools
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// DefaultSizeClasses are the buffer capacities of the default BufferPool.
+var DefaultSizeClasses = []int{512, 2 << 10, 8 << 10, 32 << 10, 128 << 10}
+
+// BufferPool pools bytes.Buffers in size classes. Get returns a buffer of
+// the smallest class fitting the size hint; Put files a buffer under the
+// largest class its capacity covers, and drops buffers larger than twice the
+// largest class, so that one huge payload does not pin memory.
+type BufferPool struct {
+	classes []int
+	pools   []*Pool[*bytes.Buffer]
+	maxSize int
+}
+
+// NewBufferPool creates a BufferPool with the given capacities, in
+// increasing order. Without classes it uses DefaultSizeClasses.
+func NewBufferPool(classes ...int) *BufferPool {
+	if len(classes) == 0 {
+		classes = DefaultSizeClasses
+	}
+	bp := &BufferPool{
+		classes: append([]int(nil), classes...),
+		pools:   make([]*Pool[*bytes.Buffer], len(classes)),
+		maxSize: 2 * classes[len(classes)-1],
+	}
+	for i, size := range bp.classes {
+		bp.pools[i] = New(func() *bytes.Buffer {
+			return bytes.NewBuffer(make([]byte, 0, size))
+		}, WithReset(func(b *bytes.Buffer) { b.Reset() }))
+	}
+	return bp
+}
+
+// Get returns an empty buffer with room for at least sizeHint bytes when
+// sizeHint fits a class; larger hints get a buffer of the largest class.
+func (bp *BufferPool) Get(sizeHint int) *bytes.Buffer {
+	return bp.pools[bp.classFor(sizeHint)].Get()
+}
+
+// Put returns a buffer to the pool. buf must not be used afterwards.
+func (bp *BufferPool) Put(buf *bytes.Buffer) {
+	if buf == nil {
+		return
+	}
+	capacity := buf.Cap()
+	if capacity > bp.maxSize || capacity < bp.classes[0] {
+		bp.pools[0].counters.discards.Add(1)
+		return
+	}
+	// The largest class the buffer can serve without growing.
+	i := len(bp.classes) - 1
+	for i > 0 && bp.classes[i] > capacity {
+		i--
+	}
+	bp.pools[i].Put(buf)
+}
+
+// Stats returns the counters of each size class, keyed by class capacity.
+func (bp *BufferPool) Stats() map[int]Stats {
+	stats := make(map[int]Stats, len(bp.classes))
+	for i, size := range bp.classes {
+		stats[size] = bp.pools[i].Stats()
+	}
+	return stats
+}
+
+// classFor returns the index of the smallest class holding size bytes.
+func (bp *BufferPool) classFor(size int) int {
+	for i, class := range bp.classes {
+		if size <= class {
+			return i
+		}
+	}
+	return len(bp.classes) - 1
+}
+
+// defaultBuffers is the package BufferPool.
+var defaultBuffers = NewBufferPool()
+
+// Buffers returns the package BufferPool, used by the helpers below and by
+// the domain error serializer, the logger buffer and the HTTP client.
+func Buffers() *BufferPool {
+	return defaultBuffers
+}
+
+// GetBuffer returns a buffer of the package BufferPool.
+func GetBuffer(sizeHint int) *bytes.Buffer {
+	return defaultBuffers.Get(sizeHint)
+}
+
+// PutBuffer returns a buffer to the package BufferPool.
+func PutBuffer(buf *bytes.Buffer) {
+	defaultBuffers.Put(buf)
+}
+
+// EncodeJSON writes the JSON encoding of v followed by a newline to w,
+// encoding into a pooled buffer so that w receives a single Write.
+func EncodeJSON(w io.Writer, v any) error {
+	buf := GetBuffer(0)
+	defer PutBuffer(buf)
+
+	if err := json.NewEncoder(buf).Encode(v); err != nil {
+		return err
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// MarshalJSON returns the JSON encoding of v like json.Marshal, encoding
+// into a pooled buffer and allocating only the returned slice.
+func MarshalJSON(v any) ([]byte, error) {
+	buf := GetBuffer(0)
+	defer PutBuffer(buf)
+
+	if err := json.NewEncoder(buf).Encode(v); err != nil {
+		return nil, err
+	}
+	// Encode terminates the value with a newline that Marshal does not.
+	return bytes.Clone(bytes.TrimSuffix(buf.Bytes(), []byte{'\n'})), nil
+}
+
+// ReadAll reads r until EOF like io.ReadAll, reading into a pooled buffer
+// and allocating only the returned slice. sizeHint, e.g. a Content-Length,
+// selects the buffer class; pass a negative value when unknown.
+func ReadAll(r io.Reader, sizeHint int64) ([]byte, error) {
+	hint := 0
+	if sizeHint > 0 && sizeHint < int64(defaultBuffers.maxSize) {
+		hint = int(sizeHint)
+	}
+	buf := GetBuffer(hint)
+	defer PutBuffer(buf)
+
+	if _, err := buf.ReadFrom(r); err != nil {
+		return nil, err
+	}
+	return bytes.Clone(buf.Bytes()), nil
+}
@@ -0,0 +1,122 @@
+// Package pools reduces allocations on hot paths: Pool is a typed sync.Pool
+// with reset and statistics, BufferPool keeps bytes.Buffers in size classes
+// so that large buffers are not handed out for small payloads, and
+// MarshalJSON, EncodeJSON and ReadAll use pooled buffers for the common
+// serialization and body-reading cases.
+//
+// Pooling pays off for objects allocated per request or per log entry whose
+// construction shows in profiles; Stats tells whether a pool is reused
+// (Hits) or mostly allocating (Misses).
+package pools
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Stats are the counters of a pool.
+type Stats struct {
+	// Gets is the number of objects handed out.
+	Gets int64
+	// Misses is the number of Gets that allocated a new object.
+	Misses int64
+	// Puts is the number of objects returned.
+	Puts int64
+	// Discards is the number of returned objects dropped, e.g. buffers too
+	// large to be kept.
+	Discards int64
+}
+
+// Hits returns the number of Gets served by a pooled object.
+func (s Stats) Hits() int64 {
+	return s.Gets - s.Misses
+}
+
+// HitRate returns the share of Gets served by a pooled object, 0 without
+// Gets.
+func (s Stats) HitRate() float64 {
+	if s.Gets == 0 {
+		return 0
+	}
+	return float64(s.Hits()) / float64(s.Gets)
+}
+
+// counters are the atomic counters behind Stats.
+type counters struct {
+	gets, misses, puts, discards atomic.Int64
+}
+
+func (c *counters) stats() Stats {
+	return Stats{
+		Gets:     c.gets.Load(),
+		Misses:   c.misses.Load(),
+		Puts:     c.puts.Load(),
+		Discards: c.discards.Load(),
+	}
+}
+
+// Pool is a typed sync.Pool. Objects are reset when returned, and Put may
+// refuse objects, e.g. grown too large, through the keep function. T should
+// be a pointer type: other values are copied into an interface on Put.
+type Pool[T any] struct {
+	pool     sync.Pool
+	reset    func(T)
+	keep     func(T) bool
+	counters counters
+}
+
+// PoolOption configures a Pool.
+type PoolOption[T any] func(*Pool[T])
+
+// WithReset sets the function clearing objects returned to the pool.
+func WithReset[T any](reset func(T)) PoolOption[T] {
+	return func(p *Pool[T]) {
+		p.reset = reset
+	}
+}
+
+// WithKeep sets the function deciding whether a returned object is kept.
+// Objects it rejects are left to the garbage collector.
+func WithKeep[T any](keep func(T) bool) PoolOption[T] {
+	return func(p *Pool[T]) {
+		p.keep = keep
+	}
+}
+
+// New creates a Pool allocating objects with newFn.
+func New[T any](newFn func() T, opts ...PoolOption[T]) *Pool[T] {
+	p := &Pool[T]{}
+	p.pool.New = func() any {
+		p.counters.misses.Add(1)
+		return newFn()
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Get returns a pooled or new object.
+func (p *Pool[T]) Get() T {
+	p.counters.gets.Add(1)
+	return p.pool.Get().(T)
+}
+
+// Put resets obj and returns it to the pool. obj must not be used
+// afterwards.
+func (p *Pool[T]) Put(obj T) {
+	if p.keep != nil && !p.keep(obj) {
+		p.counters.discards.Add(1)
+		return
+	}
+	if p.reset != nil {
+		p.reset(obj)
+	}
+	p.counters.puts.Add(1)
+	p.pool.Put(obj)
+}
+
+// Stats returns the counters of the pool.
+func (p *Pool[T]) Stats() Stats {
+	return p.counters.stats()
+}
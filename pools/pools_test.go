@@ -0,0 +1,182 @@
+package pools
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type record struct {
+	ID    int               `json:"id"`
+	Name  string            `json:"name"`
+	HTML  string            `json:"html"`
+	Attrs map[string]string `json:"attrs,omitempty"`
+}
+
+func TestPool(t *testing.T) {
+	p := New(func() *[]int {
+		s := make([]int, 0, 8)
+		return &s
+	},
+		WithReset(func(s *[]int) { *s = (*s)[:0] }),
+		WithKeep(func(s *[]int) bool { return cap(*s) <= 16 }),
+	)
+
+	s := p.Get()
+	*s = append(*s, 1, 2, 3)
+	p.Put(s)
+
+	big := p.Get()
+	*big = make([]int, 0, 64)
+	p.Put(big)
+
+	stats := p.Stats()
+	assert.GreaterOrEqual(t, stats.Gets, int64(2))
+	assert.GreaterOrEqual(t, stats.Misses, int64(1))
+	assert.Equal(t, int64(1), stats.Puts)
+	assert.Equal(t, int64(1), stats.Discards)
+	assert.Empty(t, *s, "returned objects are reset")
+}
+
+func TestStats(t *testing.T) {
+	assert.Zero(t, Stats{}.HitRate())
+
+	stats := Stats{Gets: 10, Misses: 2}
+	assert.Equal(t, int64(8), stats.Hits())
+	assert.InDelta(t, 0.8, stats.HitRate(), 1e-9)
+}
+
+func TestBufferPool(t *testing.T) {
+	bp := NewBufferPool(64, 256, 1024)
+
+	assert.GreaterOrEqual(t, bp.Get(0).Cap(), 64)
+	assert.GreaterOrEqual(t, bp.Get(100).Cap(), 256)
+	assert.GreaterOrEqual(t, bp.Get(5000).Cap(), 1024)
+
+	buf := bp.Get(10)
+	buf.WriteString("payload")
+	bp.Put(buf)
+	assert.Zero(t, buf.Len(), "returned buffers are reset")
+
+	// Larger than twice the largest class: dropped.
+	bp.Put(bytes.NewBuffer(make([]byte, 0, 4096)))
+	// Smaller than the smallest class: dropped.
+	bp.Put(bytes.NewBuffer(make([]byte, 0, 8)))
+	// Grown to 300 bytes: filed under the 256 class.
+	bp.Put(bytes.NewBuffer(make([]byte, 0, 300)))
+	bp.Put(nil)
+
+	stats := bp.Stats()
+	require.Len(t, stats, 3)
+	assert.Equal(t, int64(2), stats[64].Discards)
+	assert.Equal(t, int64(1), stats[256].Puts)
+	assert.Equal(t, int64(4), stats[64].Gets+stats[256].Gets+stats[1024].Gets)
+}
+
+func TestMarshalJSON(t *testing.T) {
+	values := []any{
+		record{ID: 1, Name: "a", HTML: "<b>&</b>", Attrs: map[string]string{"k": "v"}},
+		[]int{1, 2, 3},
+		"text",
+		nil,
+	}
+	for _, v := range values {
+		want, err := json.Marshal(v)
+		require.NoError(t, err)
+		got, err := MarshalJSON(v)
+		require.NoError(t, err)
+		assert.Equal(t, string(want), string(got))
+	}
+
+	// The result does not alias the pooled buffer.
+	first, err := MarshalJSON(record{Name: "first"})
+	require.NoError(t, err)
+	_, err = MarshalJSON(record{Name: "second"})
+	require.NoError(t, err)
+	assert.Contains(t, string(first), `"first"`)
+
+	_, err = MarshalJSON(make(chan int))
+	assert.Error(t, err)
+}
+
+func TestEncodeJSON(t *testing.T) {
+	var out strings.Builder
+	require.NoError(t, EncodeJSON(&out, record{ID: 7, Name: "x"}))
+	assert.Equal(t, `{"id":7,"name":"x","html":""}`+"\n", out.String())
+
+	w := &failingWriter{}
+	assert.EqualError(t, EncodeJSON(w, record{}), "disk full")
+	assert.Error(t, EncodeJSON(&out, func() {}))
+}
+
+func TestReadAll(t *testing.T) {
+	payload := strings.Repeat("x", 10_000)
+
+	got, err := ReadAll(strings.NewReader(payload), int64(len(payload)))
+	require.NoError(t, err)
+	assert.Equal(t, payload, string(got))
+
+	got, err = ReadAll(strings.NewReader("short"), -1)
+	require.NoError(t, err)
+	assert.Equal(t, "short", string(got))
+
+	got, err = ReadAll(strings.NewReader(""), 0)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	_, err = ReadAll(io.MultiReader(strings.NewReader("a"), &failingReader{}), -1)
+	assert.EqualError(t, err, "connection reset")
+}
+
+type failingWriter struct{}
+
+func (*failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+type failingReader struct{}
+
+func (*failingReader) Read([]byte) (int, error) { return 0, errors.New("connection reset") }
+
+var benchRecord = record{ID: 42, Name: "benchmark", HTML: "<p>", Attrs: map[string]string{"a": "1", "b": "2"}}
+
+func BenchmarkMarshal_Std(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = json.Marshal(benchRecord)
+	}
+}
+
+func BenchmarkMarshal_Pooled(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = MarshalJSON(benchRecord)
+	}
+}
+
+func BenchmarkEncode_Pooled(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = EncodeJSON(io.Discard, benchRecord)
+	}
+}
+
+func BenchmarkReadAll_Std(b *testing.B) {
+	payload := strings.Repeat("x", 16<<10)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = io.ReadAll(strings.NewReader(payload))
+	}
+}
+
+func BenchmarkReadAll_Pooled(b *testing.B) {
+	payload := strings.Repeat("x", 16<<10)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = ReadAll(strings.NewReader(payload), int64(len(payload)))
+	}
+}
@@ -0,0 +1,222 @@
+// Package fallback runs a chain of alternatives: the primary step and, when it
+// fails with an error worth falling back on — a dependency that is down, slow
+// or overloaded, not a request that is invalid — each secondary in turn. Every
+// step runs in its own span, and a composite error lists the failure of each
+// step when all of them fail.
+package fallback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+// tracerName identifies the spans created by fallback chains.
+const tracerName = "github.com/fsvxavier/nexs-lib/resilience/fallback"
+
+// ErrExhausted is wrapped by the error of a chain whose steps all failed.
+var ErrExhausted = errors.New("fallback: all steps failed")
+
+// DefaultTypes are the domain error types a chain falls back on unless On or
+// When is given: failures of a dependency, which another step may not share.
+var DefaultTypes = []interfaces.ErrorType{
+	interfaces.ExternalServiceError,
+	interfaces.TimeoutError,
+	interfaces.ServiceUnavailableError,
+	interfaces.CircuitBreakerError,
+	interfaces.DependencyError,
+	interfaces.InfrastructureError,
+	interfaces.RateLimitError,
+	interfaces.ResourceExhaustedError,
+}
+
+// Step is an alternative of a chain.
+type Step[V any] struct {
+	// Name identifies the step in spans and errors.
+	Name string
+	// Fn produces the value.
+	Fn func(ctx context.Context) (V, error)
+}
+
+// Named creates a step.
+func Named[V any](name string, fn func(ctx context.Context) (V, error)) Step[V] {
+	return Step[V]{Name: name, Fn: fn}
+}
+
+// StepError is the failure of a step, joined in the error of an exhausted
+// chain.
+type StepError struct {
+	// Step is the name of the step.
+	Step string
+	// Err is the error returned by the step.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *StepError) Error() string {
+	return e.Step + ": " + e.Err.Error()
+}
+
+// Unwrap returns the error of the step.
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// Option configures a chain.
+type Option func(*options)
+
+type options struct {
+	shouldFallback func(error) bool
+	tracer         trace.Tracer
+}
+
+// On falls back on errors with one of the given domain error types, and on
+// errors that are not domain errors. Defaults to DefaultTypes.
+func On(types ...interfaces.ErrorType) Option {
+	return func(o *options) {
+		o.shouldFallback = onTypes(types)
+	}
+}
+
+// When falls back on the errors for which fn returns true, replacing On.
+func When(fn func(err error) bool) Option {
+	return func(o *options) {
+		if fn != nil {
+			o.shouldFallback = fn
+		}
+	}
+}
+
+// WithTracerProvider sets the tracer provider of the spans. Defaults to the
+// global provider.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(o *options) {
+		o.tracer = provider.Tracer(tracerName)
+	}
+}
+
+// Chain runs a primary step and its fallbacks. A Chain is safe for concurrent
+// use once configured.
+type Chain[V any] struct {
+	steps   []Step[V]
+	options options
+}
+
+// Fallback creates a chain running primary and, when it fails with an error to
+// fall back on, each of secondaries in order.
+func Fallback[V any](primary Step[V], secondaries ...Step[V]) *Chain[V] {
+	return &Chain[V]{
+		steps: append([]Step[V]{primary}, secondaries...),
+		options: options{
+			shouldFallback: onTypes(DefaultTypes),
+			tracer:         otel.Tracer(tracerName),
+		},
+	}
+}
+
+// With applies options to the chain and returns it.
+func (c *Chain[V]) With(opts ...Option) *Chain[V] {
+	for _, opt := range opts {
+		opt(&c.options)
+	}
+	return c
+}
+
+// Do runs the steps in order and returns the value of the first that
+// succeeds. An error that is not one to fall back on is returned as is, as
+// is the error of ctx once it is done. When every step failed, Do returns a
+// ServiceUnavailableError wrapping ErrExhausted and a StepError per step.
+func (c *Chain[V]) Do(ctx context.Context) (V, error) {
+	ctx, span := c.options.tracer.Start(ctx, "fallback",
+		trace.WithAttributes(attribute.Int("fallback.steps", len(c.steps))))
+	defer span.End()
+
+	var zero V
+	failures := make([]error, 0, len(c.steps))
+	for i, step := range c.steps {
+		value, err := c.run(ctx, i, step)
+		if err == nil {
+			span.SetAttributes(
+				attribute.String("fallback.served_by", step.Name),
+				attribute.Int("fallback.index", i),
+			)
+			return value, nil
+		}
+
+		if ctx.Err() != nil || !c.options.shouldFallback(err) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return zero, err
+		}
+		failures = append(failures, &StepError{Step: step.Name, Err: err})
+	}
+
+	err := exhausted(failures)
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+	return zero, err
+}
+
+// run calls a step in its own span.
+func (c *Chain[V]) run(ctx context.Context, index int, step Step[V]) (V, error) {
+	ctx, span := c.options.tracer.Start(ctx, "fallback "+step.Name,
+		trace.WithAttributes(
+			attribute.String("fallback.step", step.Name),
+			attribute.Int("fallback.index", index),
+		))
+	defer span.End()
+
+	value, err := step.Fn(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		var domainErr interfaces.DomainErrorInterface
+		if errors.As(err, &domainErr) {
+			span.SetAttributes(attribute.String("error.type", string(domainErr.Type())))
+		}
+	}
+	return value, err
+}
+
+// exhausted builds the error of a chain whose steps all failed.
+func exhausted(failures []error) error {
+	steps := make([]string, len(failures))
+	messages := make([]string, len(failures))
+	for i, failure := range failures {
+		stepErr := failure.(*StepError)
+		steps[i] = stepErr.Step
+		messages[i] = stepErr.Error()
+	}
+
+	return domainerrors.NewWithMetadata(interfaces.ServiceUnavailableError, "FALLBACK_EXHAUSTED",
+		fmt.Sprintf("all %d fallback steps failed: %s", len(failures), strings.Join(messages, "; ")),
+		map[string]interface{}{
+			"steps":  steps,
+			"errors": messages,
+		}).Wrap(errors.Join(append([]error{ErrExhausted}, failures...)...))
+}
+
+// onTypes falls back on the given domain error types and on errors that are
+// not domain errors.
+func onTypes(types []interfaces.ErrorType) func(error) bool {
+	set := make(map[interfaces.ErrorType]bool, len(types))
+	for _, t := range types {
+		set[t] = true
+	}
+	return func(err error) bool {
+		var domainErr interfaces.DomainErrorInterface
+		if !errors.As(err, &domainErr) {
+			return true
+		}
+		return set[domainErr.Type()]
+	}
+}
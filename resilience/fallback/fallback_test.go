@@ -0,0 +1,160 @@
+package fallback
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+func failing(errorType interfaces.ErrorType, code string) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		return "", domainerrors.New(errorType, code, code)
+	}
+}
+
+func serving(value string) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		return value, nil
+	}
+}
+
+func TestDo_PrimarySucceeds(t *testing.T) {
+	secondaryCalled := false
+	value, err := Fallback(
+		Named("primary", serving("primary")),
+		Named("secondary", func(ctx context.Context) (string, error) {
+			secondaryCalled = true
+			return "secondary", nil
+		}),
+	).Do(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "primary", value)
+	assert.False(t, secondaryCalled)
+}
+
+func TestDo_FallsBackOnDependencyFailures(t *testing.T) {
+	value, err := Fallback(
+		Named("api", failing(interfaces.ExternalServiceError, "API_DOWN")),
+		Named("replica", failing(interfaces.TimeoutError, "REPLICA_TIMEOUT")),
+		Named("cache", serving("cached")),
+	).Do(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "cached", value)
+}
+
+func TestDo_StopsOnOtherErrors(t *testing.T) {
+	secondaryCalled := false
+	_, err := Fallback(
+		Named("api", failing(interfaces.ValidationError, "INVALID_ID")),
+		Named("cache", func(ctx context.Context) (string, error) {
+			secondaryCalled = true
+			return "cached", nil
+		}),
+	).Do(context.Background())
+
+	assert.True(t, domainerrors.IsType(err, interfaces.ValidationError))
+	assert.False(t, secondaryCalled)
+}
+
+func TestDo_OnAndWhen(t *testing.T) {
+	chain := Fallback(
+		Named("api", failing(interfaces.NotFoundError, "NOT_FOUND")),
+		Named("archive", serving("archived")),
+	)
+
+	_, err := chain.Do(context.Background())
+	assert.True(t, domainerrors.IsType(err, interfaces.NotFoundError))
+
+	value, err := chain.With(On(interfaces.NotFoundError)).Do(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "archived", value)
+
+	_, err = chain.With(When(func(err error) bool { return false })).Do(context.Background())
+	assert.True(t, domainerrors.IsType(err, interfaces.NotFoundError))
+}
+
+func TestDo_UntypedErrorsFallBack(t *testing.T) {
+	value, err := Fallback(
+		Named("api", func(ctx context.Context) (string, error) {
+			return "", errors.New("connection refused")
+		}),
+		Named("cache", serving("cached")),
+	).Do(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "cached", value)
+}
+
+func TestDo_CompositeErrorWhenAllFail(t *testing.T) {
+	connRefused := errors.New("connection refused")
+	_, err := Fallback(
+		Named("api", failing(interfaces.ExternalServiceError, "API_DOWN")),
+		Named("replica", func(ctx context.Context) (string, error) {
+			return "", connRefused
+		}),
+	).Do(context.Background())
+
+	assert.ErrorIs(t, err, ErrExhausted)
+	assert.ErrorIs(t, err, connRefused)
+	assert.EqualError(t, err, "all 2 fallback steps failed: api: API_DOWN; replica: connection refused")
+
+	var domainErr interfaces.DomainErrorInterface
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, interfaces.ServiceUnavailableError, domainErr.Type())
+	assert.Equal(t, "FALLBACK_EXHAUSTED", domainErr.Code())
+	assert.Equal(t, []string{"api", "replica"}, domainErr.Metadata()["steps"])
+
+	var stepErr *StepError
+	require.ErrorAs(t, err, &stepErr)
+	assert.Equal(t, "api", stepErr.Step)
+}
+
+func TestDo_StopsWhenContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	secondaryCalled := false
+
+	_, err := Fallback(
+		Named("api", func(ctx context.Context) (string, error) {
+			cancel()
+			return "", ctx.Err()
+		}),
+		Named("cache", func(ctx context.Context) (string, error) {
+			secondaryCalled = true
+			return "cached", nil
+		}),
+	).Do(ctx)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, secondaryCalled)
+}
+
+func TestDo_SpanPerStep(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	_, err := Fallback(
+		Named("api", failing(interfaces.TimeoutError, "API_TIMEOUT")),
+		Named("cache", serving("cached")),
+	).With(WithTracerProvider(provider)).Do(context.Background())
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	assert.Equal(t, "fallback api", spans[0].Name())
+	assert.Contains(t, spans[0].Attributes(), attribute.String("error.type", string(interfaces.TimeoutError)))
+	assert.Equal(t, "fallback cache", spans[1].Name())
+	assert.Equal(t, "fallback", spans[2].Name())
+	assert.Contains(t, spans[2].Attributes(), attribute.String("fallback.served_by", "cache"))
+	assert.Equal(t, spans[2].SpanContext().SpanID(), spans[0].Parent().SpanID())
+}
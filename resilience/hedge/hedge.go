@@ -0,0 +1,338 @@
+// Package hedge reduces tail latency with hedged requests: a call runs its
+// function and, when no result arrives within the latency budget — by
+// default the 95th percentile of recent calls — runs it again, typically
+// against another replica. The first success wins and the other attempts are
+// cancelled. A budget caps the share of calls that may hedge, so that a slow
+// dependency is not hit by twice the load, and Stats counts hedges and how
+// often they won.
+package hedge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"runtime/debug"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+// ErrPanic is wrapped by the errors of attempts that panicked.
+var ErrPanic = errors.New("hedge: attempt panicked")
+
+// Defaults of a Hedger.
+const (
+	DefaultPercentile   = 0.95
+	DefaultWindow       = 512
+	DefaultMinSamples   = 20
+	DefaultInitialDelay = 100 * time.Millisecond
+	DefaultMaxHedges    = 1
+	DefaultBudget       = 0.1
+	DefaultBudgetBurst  = 10
+)
+
+// Stats are the counters of a Hedger.
+type Stats struct {
+	// Calls is the number of Do calls.
+	Calls int64
+	// Hedges is the number of hedged attempts launched.
+	Hedges int64
+	// HedgeWins is the number of calls won by a hedged attempt.
+	HedgeWins int64
+	// BudgetExhausted is the number of hedges skipped by the budget.
+	BudgetExhausted int64
+	// Errors is the number of calls whose attempts all failed.
+	Errors int64
+	// Delay is the current hedging delay.
+	Delay time.Duration
+}
+
+// Option configures a Hedger.
+type Option func(*options)
+
+type options struct {
+	delay        time.Duration
+	percentile   float64
+	window       int
+	minSamples   int
+	initialDelay time.Duration
+	minDelay     time.Duration
+	maxHedges    int
+	budget       float64
+	burst        float64
+	clock        clock.Clock
+}
+
+// WithDelay hedges after a fixed delay instead of the observed percentile.
+func WithDelay(d time.Duration) Option {
+	return func(o *options) {
+		o.delay = max(d, 0)
+	}
+}
+
+// WithPercentile sets the latency percentile used as the hedging delay,
+// between 0 and 1. Defaults to DefaultPercentile.
+func WithPercentile(p float64) Option {
+	return func(o *options) {
+		if p > 0 && p < 1 {
+			o.percentile = p
+		}
+	}
+}
+
+// WithWindow sets the number of recent latencies the percentile is computed
+// from. Defaults to DefaultWindow.
+func WithWindow(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.window = n
+		}
+	}
+}
+
+// WithInitialDelay sets the delay used until minSamples latencies were
+// observed. Defaults to DefaultInitialDelay and DefaultMinSamples.
+func WithInitialDelay(d time.Duration, minSamples int) Option {
+	return func(o *options) {
+		o.initialDelay = max(d, 0)
+		o.minSamples = max(minSamples, 1)
+	}
+}
+
+// WithMinDelay sets a floor for the hedging delay, so that very fast
+// dependencies are not hedged on jitter.
+func WithMinDelay(d time.Duration) Option {
+	return func(o *options) {
+		o.minDelay = max(d, 0)
+	}
+}
+
+// WithMaxHedges sets the number of hedged attempts per call, each launched
+// one delay after the previous. Defaults to DefaultMaxHedges.
+func WithMaxHedges(n int) Option {
+	return func(o *options) {
+		o.maxHedges = max(n, 0)
+	}
+}
+
+// WithBudget caps hedges to ratio of the calls, e.g. 0.1 for at most one
+// hedge per ten calls, allowing bursts of up to burst hedges. Defaults to
+// DefaultBudget and DefaultBudgetBurst.
+func WithBudget(ratio float64, burst int) Option {
+	return func(o *options) {
+		o.budget = max(ratio, 0)
+		o.burst = float64(max(burst, 1))
+	}
+}
+
+// WithClock sets the clock of delays and latencies. Defaults to clock.Real().
+func WithClock(c clock.Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+// Hedger runs hedged calls returning V. A Hedger learns the latency of one
+// dependency: use one per dependency, and share it between its callers.
+type Hedger[V any] struct {
+	options options
+
+	mu        sync.Mutex
+	latencies []time.Duration
+	next      int
+	tokens    float64
+
+	calls, hedges, hedgeWins, budgetExhausted, errors atomic.Int64
+}
+
+// result is the outcome of an attempt.
+type result[V any] struct {
+	value   V
+	err     error
+	attempt int
+	latency time.Duration
+}
+
+// New creates a Hedger.
+func New[V any](opts ...Option) *Hedger[V] {
+	o := options{
+		percentile:   DefaultPercentile,
+		window:       DefaultWindow,
+		minSamples:   DefaultMinSamples,
+		initialDelay: DefaultInitialDelay,
+		maxHedges:    DefaultMaxHedges,
+		budget:       DefaultBudget,
+		burst:        DefaultBudgetBurst,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	o.clock = clock.OrReal(o.clock)
+
+	return &Hedger[V]{
+		options:   o,
+		latencies: make([]time.Duration, 0, o.window),
+		tokens:    o.burst,
+	}
+}
+
+// Do runs fn with attempt 0 and, while no attempt succeeded, launches
+// attempt 1, 2... one delay apart, up to the hedges allowed. attempt lets fn
+// pick another replica for hedged attempts. The first success is returned and
+// the context of the other attempts is cancelled.
+//
+// Hedging is not retrying: a failed attempt does not launch another one, and
+// when every launched attempt failed Do returns the last error. A panic in fn
+// becomes a ServerError.
+func (h *Hedger[V]) Do(ctx context.Context, fn func(ctx context.Context, attempt int) (V, error)) (V, error) {
+	h.calls.Add(1)
+	h.deposit()
+
+	ctx, cancel := context.WithCancel(ctx)
+	// Cancels the attempts still running once a result is returned.
+	defer cancel()
+
+	clk := h.options.clock
+	results := make(chan result[V], h.options.maxHedges+1)
+	launch := func(attempt int) {
+		go func() {
+			start := clk.Now()
+			value, err := run(ctx, attempt, fn)
+			results <- result[V]{value: value, err: err, attempt: attempt, latency: clk.Since(start)}
+		}()
+	}
+
+	launch(0)
+	launched, pending := 1, 1
+
+	var hedgeC <-chan time.Time
+	var timer clock.Timer
+	if h.options.maxHedges > 0 {
+		timer = clk.NewTimer(h.Delay())
+		defer timer.Stop()
+		hedgeC = timer.C()
+	}
+
+	var zero V
+	var lastErr error
+	for {
+		select {
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				h.observe(r.latency)
+				if r.attempt > 0 {
+					h.hedgeWins.Add(1)
+				}
+				return r.value, nil
+			}
+			lastErr = r.err
+			if pending == 0 {
+				h.errors.Add(1)
+				return zero, lastErr
+			}
+
+		case <-hedgeC:
+			if !h.spend() {
+				h.budgetExhausted.Add(1)
+				hedgeC = nil
+				continue
+			}
+			h.hedges.Add(1)
+			launch(launched)
+			launched++
+			pending++
+			if launched > h.options.maxHedges {
+				hedgeC = nil
+			} else {
+				timer.Reset(h.Delay())
+			}
+
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		}
+	}
+}
+
+// Delay returns the current hedging delay.
+func (h *Hedger[V]) Delay() time.Duration {
+	if h.options.delay > 0 {
+		return max(h.options.delay, h.options.minDelay)
+	}
+
+	h.mu.Lock()
+	if len(h.latencies) < h.options.minSamples {
+		h.mu.Unlock()
+		return max(h.options.initialDelay, h.options.minDelay)
+	}
+	sorted := slices.Clone(h.latencies)
+	h.mu.Unlock()
+
+	slices.Sort(sorted)
+	index := int(math.Ceil(h.options.percentile*float64(len(sorted)))) - 1
+	return max(sorted[max(index, 0)], h.options.minDelay)
+}
+
+// Stats returns the counters of the hedger.
+func (h *Hedger[V]) Stats() Stats {
+	return Stats{
+		Calls:           h.calls.Load(),
+		Hedges:          h.hedges.Load(),
+		HedgeWins:       h.hedgeWins.Load(),
+		BudgetExhausted: h.budgetExhausted.Load(),
+		Errors:          h.errors.Load(),
+		Delay:           h.Delay(),
+	}
+}
+
+// observe records the latency of a successful attempt.
+func (h *Hedger[V]) observe(latency time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.latencies) < h.options.window {
+		h.latencies = append(h.latencies, latency)
+		return
+	}
+	h.latencies[h.next] = latency
+	h.next = (h.next + 1) % h.options.window
+}
+
+// deposit adds the budget share of a call.
+func (h *Hedger[V]) deposit() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.tokens = min(h.tokens+h.options.budget, h.options.burst)
+}
+
+// spend takes the budget of a hedge, reporting whether it was available.
+func (h *Hedger[V]) spend() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.tokens < 1 {
+		return false
+	}
+	h.tokens--
+	return true
+}
+
+// run calls fn, turning a panic into an error.
+func run[V any](ctx context.Context, attempt int, fn func(ctx context.Context, attempt int) (V, error)) (value V, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = domainerrors.NewWithMetadata(interfaces.ServerError, "HEDGE_PANIC",
+				fmt.Sprintf("hedged attempt panicked: %v", r), map[string]interface{}{
+					"attempt": attempt,
+					"panic":   fmt.Sprint(r),
+					"stack":   string(debug.Stack()),
+				}).Wrap(ErrPanic)
+		}
+	}()
+	return fn(ctx, attempt)
+}
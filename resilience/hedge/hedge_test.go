@@ -0,0 +1,184 @@
+package hedge
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+func newFake() *clock.Fake {
+	return clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+}
+
+func TestDo_FastAttemptIsNotHedged(t *testing.T) {
+	h := New[string](WithDelay(time.Second))
+
+	value, err := h.Do(context.Background(), func(ctx context.Context, attempt int) (string, error) {
+		return "primary", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "primary", value)
+
+	stats := h.Stats()
+	assert.Equal(t, int64(1), stats.Calls)
+	assert.Equal(t, int64(0), stats.Hedges)
+}
+
+func TestDo_SlowAttemptIsHedgedAndLoserCancelled(t *testing.T) {
+	fake := newFake()
+	h := New[string](WithDelay(50*time.Millisecond), WithClock(fake))
+
+	loserCancelled := make(chan struct{})
+	done := make(chan string)
+	go func() {
+		value, err := h.Do(context.Background(), func(ctx context.Context, attempt int) (string, error) {
+			if attempt == 0 {
+				<-ctx.Done()
+				close(loserCancelled)
+				return "", ctx.Err()
+			}
+			return "replica", nil
+		})
+		assert.NoError(t, err)
+		done <- value
+	}()
+
+	fake.BlockUntil(1)
+	fake.Advance(50 * time.Millisecond)
+
+	assert.Equal(t, "replica", <-done)
+	<-loserCancelled
+
+	stats := h.Stats()
+	assert.Equal(t, int64(1), stats.Hedges)
+	assert.Equal(t, int64(1), stats.HedgeWins)
+}
+
+func TestDo_MaxHedges(t *testing.T) {
+	fake := newFake()
+	h := New[int](WithDelay(10*time.Millisecond), WithMaxHedges(2), WithBudget(1, 10), WithClock(fake))
+
+	var started atomic.Int32
+	release := make(chan struct{})
+	done := make(chan int)
+	go func() {
+		value, _ := h.Do(context.Background(), func(ctx context.Context, attempt int) (int, error) {
+			started.Add(1)
+			if attempt < 2 {
+				<-ctx.Done()
+				return 0, ctx.Err()
+			}
+			<-release
+			return attempt, nil
+		})
+		done <- value
+	}()
+
+	for i := 0; i < 2; i++ {
+		fake.BlockUntil(1)
+		fake.Advance(10 * time.Millisecond)
+		require.Eventually(t, func() bool { return started.Load() == int32(i+2) }, time.Second, time.Millisecond)
+	}
+	close(release)
+
+	assert.Equal(t, 2, <-done)
+	assert.Equal(t, int32(3), started.Load())
+	assert.Equal(t, int64(2), h.Stats().Hedges)
+}
+
+func TestDo_BudgetCapsHedges(t *testing.T) {
+	fake := newFake()
+	h := New[string](WithDelay(10*time.Millisecond), WithBudget(0, 1), WithClock(fake))
+
+	call := func() <-chan struct{} {
+		release := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, _ = h.Do(context.Background(), func(ctx context.Context, attempt int) (string, error) {
+				select {
+				case <-release:
+				case <-ctx.Done():
+				}
+				return "ok", nil
+			})
+		}()
+		fake.BlockUntil(1)
+		fake.Advance(10 * time.Millisecond)
+		require.Eventually(t, func() bool {
+			stats := h.Stats()
+			return stats.Hedges+stats.BudgetExhausted == stats.Calls
+		}, time.Second, time.Millisecond)
+		close(release)
+		return done
+	}
+
+	<-call()
+	<-call()
+
+	stats := h.Stats()
+	assert.Equal(t, int64(1), stats.Hedges)
+	assert.Equal(t, int64(1), stats.BudgetExhausted)
+}
+
+func TestDo_ErrorsAreNotRetried(t *testing.T) {
+	h := New[string](WithDelay(time.Hour))
+	var attempts atomic.Int32
+
+	_, err := h.Do(context.Background(), func(ctx context.Context, attempt int) (string, error) {
+		attempts.Add(1)
+		return "", errors.New("connection refused")
+	})
+	assert.EqualError(t, err, "connection refused")
+	assert.Equal(t, int32(1), attempts.Load())
+	assert.Equal(t, int64(1), h.Stats().Errors)
+}
+
+func TestDo_PanicBecomesServerError(t *testing.T) {
+	h := New[string]()
+
+	_, err := h.Do(context.Background(), func(ctx context.Context, attempt int) (string, error) {
+		panic("boom")
+	})
+	assert.ErrorIs(t, err, ErrPanic)
+	var domainErr interfaces.DomainErrorInterface
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, interfaces.ServerError, domainErr.Type())
+}
+
+func TestDo_ContextCancelled(t *testing.T) {
+	h := New[string](WithDelay(time.Hour))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := h.Do(ctx, func(ctx context.Context, attempt int) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestDelay_Percentile(t *testing.T) {
+	h := New[string](WithInitialDelay(time.Second, 10), WithPercentile(0.9), WithWindow(10),
+		WithMinDelay(5*time.Millisecond))
+	assert.Equal(t, time.Second, h.Delay())
+
+	for i := 1; i <= 10; i++ {
+		h.observe(time.Duration(i) * 10 * time.Millisecond)
+	}
+	assert.Equal(t, 90*time.Millisecond, h.Delay())
+
+	// The window keeps the most recent latencies
+	for i := 0; i < 10; i++ {
+		h.observe(time.Millisecond)
+	}
+	assert.Equal(t, 5*time.Millisecond, h.Delay())
+}
@@ -0,0 +1,339 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule returns the next activation time after t. A zero time means the
+// schedule has no further activations.
+type Schedule interface {
+	Next(t time.Time) time.Time
+}
+
+// ScheduleFunc adapts a function to Schedule.
+type ScheduleFunc func(t time.Time) time.Time
+
+// Next implements Schedule.
+func (f ScheduleFunc) Next(t time.Time) time.Time {
+	return f(t)
+}
+
+// Every returns a schedule activating at fixed intervals, rounded to the
+// second. Activations are multiples of the interval since the zero time, so
+// replicas started at different times compute the same activations.
+func Every(interval time.Duration) Schedule {
+	if interval < time.Second {
+		interval = time.Second
+	}
+	interval = interval.Round(time.Second)
+	return ScheduleFunc(func(t time.Time) time.Time {
+		return t.Truncate(interval).Add(interval)
+	})
+}
+
+// descriptors are the predefined cron specs.
+var descriptors = map[string]string{
+	"@yearly":   "0 0 0 1 1 *",
+	"@annually": "0 0 0 1 1 *",
+	"@monthly":  "0 0 0 1 * *",
+	"@weekly":   "0 0 0 * * 0",
+	"@daily":    "0 0 0 * * *",
+	"@midnight": "0 0 0 * * *",
+	"@hourly":   "0 0 * * * *",
+}
+
+// cronField describes a field of a cron spec.
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	secondField = cronField{name: "second", min: 0, max: 59}
+	minuteField = cronField{name: "minute", min: 0, max: 59}
+	hourField   = cronField{name: "hour", min: 0, max: 23}
+	domField    = cronField{name: "day of month", min: 1, max: 31}
+	monthField  = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowField = cronField{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// cronSchedule is a parsed cron spec. Each field is a bit set of the
+// accepted values.
+type cronSchedule struct {
+	second, minute, hour, dom, month, dow uint64
+
+	// domStar and dowStar record unrestricted day fields: when both day
+	// fields are restricted, a day matching either one is accepted.
+	domStar, dowStar bool
+
+	location *time.Location
+}
+
+// ParseCron parses a cron spec. It accepts the standard five fields (minute,
+// hour, day of month, month, day of week), an optional leading seconds
+// field, the descriptors @yearly, @annually, @monthly, @weekly, @daily,
+// @midnight, @hourly and "@every <duration>", and a "CRON_TZ=<zone>" prefix.
+// Fields accept *, ?, lists, ranges, steps and month and weekday names.
+// Without a time zone prefix the spec is evaluated in the local time zone.
+func ParseCron(spec string) (Schedule, error) {
+	return parseCron(spec, time.Local)
+}
+
+// parseCron parses a cron spec evaluated in location unless the spec has a
+// time zone prefix.
+func parseCron(spec string, location *time.Location) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+
+	if strings.HasPrefix(spec, "CRON_TZ=") || strings.HasPrefix(spec, "TZ=") {
+		tz, rest, _ := strings.Cut(spec, " ")
+		_, name, _ := strings.Cut(tz, "=")
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, fmt.Errorf("scheduler: invalid time zone %q: %w", name, err)
+		}
+		location = loc
+		spec = strings.TrimSpace(rest)
+	}
+
+	if strings.HasPrefix(spec, "@every ") {
+		d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every ")))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("scheduler: invalid interval in %q", spec)
+		}
+		return Every(d), nil
+	}
+	if descriptor, ok := descriptors[strings.ToLower(spec)]; ok {
+		spec = descriptor
+	}
+
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("scheduler: cron spec %q must have 5 or 6 fields, got %d", spec, len(fields))
+	}
+
+	s := &cronSchedule{location: location}
+	var err error
+	if s.second, err = parseField(fields[0], secondField); err != nil {
+		return nil, err
+	}
+	if s.minute, err = parseField(fields[1], minuteField); err != nil {
+		return nil, err
+	}
+	if s.hour, err = parseField(fields[2], hourField); err != nil {
+		return nil, err
+	}
+	if s.dom, err = parseField(fields[3], domField); err != nil {
+		return nil, err
+	}
+	if s.month, err = parseField(fields[4], monthField); err != nil {
+		return nil, err
+	}
+	if s.dow, err = parseField(fields[5], dowField); err != nil {
+		return nil, err
+	}
+	// 7 is also Sunday
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+	s.domStar = strings.HasPrefix(fields[3], "*") || fields[3] == "?"
+	s.dowStar = strings.HasPrefix(fields[5], "*") || fields[5] == "?"
+	return s, nil
+}
+
+// MustParseCron is like ParseCron but panics on error.
+func MustParseCron(spec string) Schedule {
+	s, err := ParseCron(spec)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// parseField parses a comma separated list of ranges into a bit set.
+func parseField(expr string, field cronField) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(expr, ",") {
+		bitsOf, err := parseRange(part, field)
+		if err != nil {
+			return 0, err
+		}
+		set |= bitsOf
+	}
+	return set, nil
+}
+
+// parseRange parses *, ?, a value, a-b, and any of them with a /step.
+func parseRange(expr string, field cronField) (uint64, error) {
+	rangeExpr, stepExpr, hasStep := strings.Cut(expr, "/")
+
+	start, end := field.min, field.max
+	switch {
+	case rangeExpr == "*" || rangeExpr == "?":
+		if field.max == 7 {
+			end = 6
+		}
+	case strings.Contains(rangeExpr, "-"):
+		lo, hi, _ := strings.Cut(rangeExpr, "-")
+		var err error
+		if start, err = parseValue(lo, field); err != nil {
+			return 0, err
+		}
+		if end, err = parseValue(hi, field); err != nil {
+			return 0, err
+		}
+	default:
+		value, err := parseValue(rangeExpr, field)
+		if err != nil {
+			return 0, err
+		}
+		start, end = value, value
+		if hasStep {
+			end = field.max
+		}
+	}
+	if start > end {
+		return 0, fmt.Errorf("scheduler: invalid %s range %q", field.name, expr)
+	}
+
+	step := 1
+	if hasStep {
+		n, err := strconv.Atoi(stepExpr)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("scheduler: invalid %s step %q", field.name, expr)
+		}
+		step = n
+	}
+
+	var set uint64
+	for v := start; v <= end; v += step {
+		set |= 1 << uint(v)
+	}
+	return set, nil
+}
+
+// parseValue parses a number or a name of the field.
+func parseValue(expr string, field cronField) (int, error) {
+	if v, ok := field.names[strings.ToLower(expr)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(expr)
+	if err != nil || v < field.min || v > field.max {
+		return 0, fmt.Errorf("scheduler: invalid %s %q, must be between %d and %d", field.name, expr, field.min, field.max)
+	}
+	return v, nil
+}
+
+// has reports whether the value is in the bit set.
+func has(set uint64, v int) bool {
+	return set&(1<<uint(v)) != 0
+}
+
+// maxYears bounds the search of Next for specs that never match, such as
+// February 30.
+const maxYears = 5
+
+// Next implements Schedule.
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	orig := t.Location()
+	t = t.In(s.location)
+	t = t.Add(time.Second - time.Duration(t.Nanosecond()))
+	yearLimit := t.Year() + maxYears
+
+	// Once a field is moved forward, the lower fields restart from zero
+	truncated := false
+
+wrap:
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+
+	for !has(s.month, int(t.Month())) {
+		if !truncated {
+			truncated = true
+			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, s.location)
+		}
+		t = t.AddDate(0, 1, 0)
+		if t.Month() == time.January {
+			goto wrap
+		}
+	}
+
+	for !s.dayMatches(t) {
+		if !truncated {
+			truncated = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, s.location)
+		}
+		t = t.AddDate(0, 0, 1)
+		// Days starting at 01:00 or 23:00 of the previous day because of
+		// daylight saving time transitions
+		if h := t.Hour(); h != 0 {
+			if h > 12 {
+				t = t.Add(time.Duration(24-h) * time.Hour)
+			} else {
+				t = t.Add(-time.Duration(h) * time.Hour)
+			}
+		}
+		if t.Day() == 1 {
+			goto wrap
+		}
+	}
+
+	for !has(s.hour, t.Hour()) {
+		if !truncated {
+			truncated = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, s.location)
+		}
+		t = t.Add(time.Hour)
+		if t.Hour() == 0 {
+			goto wrap
+		}
+	}
+
+	for !has(s.minute, t.Minute()) {
+		if !truncated {
+			truncated = true
+			t = t.Truncate(time.Minute)
+		}
+		t = t.Add(time.Minute)
+		if t.Minute() == 0 {
+			goto wrap
+		}
+	}
+
+	for !has(s.second, t.Second()) {
+		if !truncated {
+			truncated = true
+			t = t.Truncate(time.Second)
+		}
+		t = t.Add(time.Second)
+		if t.Second() == 0 {
+			goto wrap
+		}
+	}
+
+	return t.In(orig)
+}
+
+// dayMatches applies the day of month and day of week fields: when both are
+// restricted either one may match, as in Vixie cron.
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := has(s.dom, t.Day())
+	dowMatch := has(s.dow, int(t.Weekday()))
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,91 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCron_Next(t *testing.T) {
+	base := time.Date(2024, 1, 31, 10, 15, 30, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, 1, 31, 10, 16, 0, 0, time.UTC)},
+		{"*/5 * * * * *", time.Date(2024, 1, 31, 10, 15, 35, 0, time.UTC)},
+		{"30 9 * * *", time.Date(2024, 2, 1, 9, 30, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 * * MON-FRI", time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)},
+		{"0 0 * * sun", time.Date(2024, 2, 4, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 2, 4, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 feb *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"15,45 10 * * *", time.Date(2024, 1, 31, 10, 45, 0, 0, time.UTC)},
+		{"0 8-18/4 * * *", time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, 1, 31, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2024, 2, 4, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"@every 90s", time.Date(2024, 1, 31, 10, 16, 30, 0, time.UTC)}, // multiple of 90s since the zero time
+		// Day of month or day of week when both are restricted
+		{"0 0 15 * FRI", time.Date(2024, 2, 2, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := parseCron(tt.spec, time.UTC)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, s.Next(base))
+		})
+	}
+}
+
+func TestParseCron_TimeZone(t *testing.T) {
+	s, err := ParseCron("CRON_TZ=America/Sao_Paulo 0 9 * * *")
+	require.NoError(t, err)
+
+	next := s.Next(time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC), next)
+	assert.Equal(t, time.UTC, next.Location(), "the result keeps the location of the argument")
+}
+
+func TestParseCron_Never(t *testing.T) {
+	s, err := parseCron("0 0 30 feb *", time.UTC)
+	require.NoError(t, err)
+	assert.True(t, s.Next(time.Now()).IsZero())
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"* * * foo *",
+		"@every -1m",
+		"@every nope",
+		"CRON_TZ=Nowhere/City * * * * *",
+	} {
+		_, err := ParseCron(spec)
+		assert.Error(t, err, spec)
+	}
+	assert.Panics(t, func() { MustParseCron("bad") })
+}
+
+func TestEvery(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 500, time.UTC)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 30, 0, time.UTC), Every(30*time.Second).Next(base))
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 1, 0, time.UTC), Every(time.Millisecond).Next(base))
+
+	// Replicas started at different times agree on the activations
+	assert.Equal(t, Every(time.Minute).Next(base.Add(10*time.Second)), Every(time.Minute).Next(base.Add(40*time.Second)))
+}
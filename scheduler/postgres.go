@@ -0,0 +1,101 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/db/postgres"
+	pginterfaces "github.com/fsvxavier/nexs-lib/db/postgres/interfaces"
+)
+
+// DefaultTable stores the claims of a PostgresCoordinator.
+const DefaultTable = "scheduler_activations"
+
+// PostgresPool is the part of the db/postgres pool used by the
+// PostgresCoordinator; interfaces.IPool satisfies it.
+type PostgresPool interface {
+	Acquire(ctx context.Context) (pginterfaces.IConn, error)
+}
+
+// PostgresCoordinator claims activations in a PostgreSQL table holding the
+// last claimed activation of each job. A claim only succeeds for a later
+// activation, so it never expires and the TTL is not used, and no connection
+// is held while the job runs.
+//
+// Advisory locks are not used, since a transaction-scoped lock is released
+// when the run returns and a late replica could take it again. The table must
+// exist before the coordinator is used: run Migrate or add Schema to the
+// application migrations.
+type PostgresCoordinator struct {
+	pool      PostgresPool
+	table     string
+	namespace string
+}
+
+// NewPostgresCoordinator creates a PostgresCoordinator storing claims in
+// table, DefaultTable when empty. Rows are keyed by the namespace and the job
+// name, so applications sharing a table use different namespaces.
+func NewPostgresCoordinator(pool PostgresPool, table, namespace string) (*PostgresCoordinator, error) {
+	if table == "" {
+		table = DefaultTable
+	}
+	if !postgres.ValidIdentifier(table) {
+		return nil, fmt.Errorf("invalid table name: %q", table)
+	}
+	if namespace == "" {
+		namespace = "scheduler"
+	}
+	return &PostgresCoordinator{pool: pool, table: table, namespace: namespace}, nil
+}
+
+// Schema returns the statement creating the table.
+func (c *PostgresCoordinator) Schema() string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	namespace TEXT NOT NULL,
+	job TEXT NOT NULL,
+	activation TIMESTAMPTZ NOT NULL,
+	claimed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	PRIMARY KEY (namespace, job)
+)`, c.table)
+}
+
+// Migrate creates the table when it does not exist.
+func (c *PostgresCoordinator) Migrate(ctx context.Context) error {
+	return c.withConn(ctx, func(conn pginterfaces.IConn) error {
+		if _, err := conn.Exec(ctx, c.Schema()); err != nil {
+			return fmt.Errorf("failed to create table %s: %w", c.table, err)
+		}
+		return nil
+	})
+}
+
+// Lock implements Coordinator.
+func (c *PostgresCoordinator) Lock(ctx context.Context, job string, activation time.Time, _ time.Duration) (bool, error) {
+	var acquired bool
+	err := c.withConn(ctx, func(conn pginterfaces.IConn) error {
+		tag, err := conn.Exec(ctx, fmt.Sprintf(`INSERT INTO %s AS a (namespace, job, activation)
+VALUES ($1, $2, $3)
+ON CONFLICT (namespace, job) DO UPDATE SET activation = EXCLUDED.activation, claimed_at = now()
+WHERE a.activation < EXCLUDED.activation`, c.table), c.namespace, job, activation)
+		if err != nil {
+			return err
+		}
+		acquired = tag.RowsAffected() == 1
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to lock job: %w", err)
+	}
+	return acquired, nil
+}
+
+// withConn runs fn on a connection of the pool.
+func (c *PostgresCoordinator) withConn(ctx context.Context, fn func(conn pginterfaces.IConn) error) error {
+	conn, err := c.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+	return fn(conn)
+}
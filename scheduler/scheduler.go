@@ -0,0 +1,495 @@
+// Package scheduler runs background jobs on cron specs or fixed intervals,
+// with per-job timeouts, panic recovery into domain errors, overlapping-run
+// prevention and an optional Coordinator so only one replica runs each
+// activation of a job. The PostgreSQL coordinator claims activations in a
+// table rather than with advisory locks, so it needs a migration; see
+// PostgresCoordinator.Schema.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+// Scheduler errors.
+var (
+	ErrJobExists        = errors.New("scheduler: job already registered")
+	ErrJobNotFound      = errors.New("scheduler: job not found")
+	ErrJobRunning       = errors.New("scheduler: job already running")
+	ErrLockNotAcquired  = errors.New("scheduler: job locked by another replica")
+	ErrSchedulerRunning = errors.New("scheduler: scheduler already running")
+	ErrJobTimeout       = errors.New("scheduler: job timeout")
+)
+
+// DefaultLockTTL is the lock TTL of jobs without timeout.
+const DefaultLockTTL = 5 * time.Minute
+
+// Job is a background job. It should return when ctx is done.
+type Job func(ctx context.Context) error
+
+// Coordinator elects the replica running each activation of a job.
+type Coordinator interface {
+	// Lock tries to claim the activation of the job scheduled at activation,
+	// returning false when another replica claimed it. Claims are not
+	// released when the run returns but kept for at least ttl, so a replica
+	// whose timer fires late skips the activation instead of running it again.
+	Lock(ctx context.Context, job string, activation time.Time, ttl time.Duration) (acquired bool, err error)
+}
+
+// ErrorHandler receives the errors of the jobs, including recovered panics
+// and timeouts.
+type ErrorHandler func(ctx context.Context, job string, err error)
+
+// Option configures a Scheduler.
+type Option func(*Scheduler)
+
+// WithCoordinator makes each activation of every job run on a single replica.
+func WithCoordinator(coordinator Coordinator) Option {
+	return func(s *Scheduler) {
+		s.coordinator = coordinator
+	}
+}
+
+// WithLocation sets the time zone of cron specs without a CRON_TZ prefix.
+// Defaults to the local time zone.
+func WithLocation(location *time.Location) Option {
+	return func(s *Scheduler) {
+		if location != nil {
+			s.location = location
+		}
+	}
+}
+
+// WithLogger sets the logger of the scheduler. Defaults to slog.Default().
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Scheduler) {
+		if logger != nil {
+			s.logger = logger
+		}
+	}
+}
+
+// WithErrorHandler sets the handler of job errors. By default they are
+// logged.
+func WithErrorHandler(handler ErrorHandler) Option {
+	return func(s *Scheduler) {
+		s.onError = handler
+	}
+}
+
+// WithClock sets the clock activations are computed and waited with, e.g. a
+// clock.Fake in tests. Defaults to clock.Real().
+func WithClock(c clock.Clock) Option {
+	return func(s *Scheduler) {
+		s.clock = clock.OrReal(c)
+	}
+}
+
+// WithDefaultTimeout sets the timeout of jobs registered without one.
+func WithDefaultTimeout(timeout time.Duration) Option {
+	return func(s *Scheduler) {
+		s.defaultTimeout = timeout
+	}
+}
+
+// JobOption configures a job.
+type JobOption func(*job)
+
+// WithTimeout bounds the duration of each run of the job.
+func WithTimeout(timeout time.Duration) JobOption {
+	return func(j *job) {
+		j.timeout = timeout
+	}
+}
+
+// WithOverlap allows a run of the job to start while the previous one is
+// still running. By default the activation is skipped.
+func WithOverlap() JobOption {
+	return func(j *job) {
+		j.allowOverlap = true
+	}
+}
+
+// WithLockTTL sets how long the coordinator keeps the claim of an
+// activation, which must exceed the clock skew between replicas. Defaults to
+// the job timeout, or DefaultLockTTL without timeout.
+func WithLockTTL(ttl time.Duration) JobOption {
+	return func(j *job) {
+		j.lockTTL = ttl
+	}
+}
+
+// WithoutCoordination runs the job on every replica.
+func WithoutCoordination() JobOption {
+	return func(j *job) {
+		j.local = true
+	}
+}
+
+// job is a registered job.
+type job struct {
+	name         string
+	schedule     Schedule
+	run          Job
+	timeout      time.Duration
+	lockTTL      time.Duration
+	allowOverlap bool
+	local        bool
+
+	running atomic.Int32
+
+	mu      sync.Mutex
+	next    time.Time
+	lastRun time.Time
+	lastErr error
+	runs    int64
+}
+
+// JobInfo describes the state of a job.
+type JobInfo struct {
+	Name      string
+	Next      time.Time
+	LastRun   time.Time
+	LastError error
+	Runs      int64
+	Running   bool
+}
+
+// Scheduler runs registered jobs. It is safe for concurrent use.
+type Scheduler struct {
+	coordinator    Coordinator
+	location       *time.Location
+	logger         *slog.Logger
+	onError        ErrorHandler
+	defaultTimeout time.Duration
+	clock          clock.Clock
+
+	mu      sync.Mutex
+	jobs    map[string]*job
+	running bool
+	stop    context.CancelFunc
+	loopCtx context.Context
+
+	// runCtx is cancelled when Stop gives up waiting for running jobs.
+	runCtx    context.Context
+	cancelRun context.CancelFunc
+
+	loops sync.WaitGroup
+	runs  sync.WaitGroup
+}
+
+// New creates a Scheduler.
+func New(opts ...Option) *Scheduler {
+	s := &Scheduler{
+		location: time.Local,
+		logger:   slog.Default(),
+		clock:    clock.Real(),
+		jobs:     make(map[string]*job),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.onError == nil {
+		s.onError = func(ctx context.Context, name string, err error) {
+			s.logger.ErrorContext(ctx, "scheduler job failed", "job", name, "error", err)
+		}
+	}
+	return s
+}
+
+// Add registers a job running on a cron spec, see ParseCron.
+func (s *Scheduler) Add(name, spec string, run Job, opts ...JobOption) error {
+	schedule, err := parseCron(spec, s.location)
+	if err != nil {
+		return err
+	}
+	return s.Schedule(name, schedule, run, opts...)
+}
+
+// Every registers a job running at fixed intervals.
+func (s *Scheduler) Every(name string, interval time.Duration, run Job, opts ...JobOption) error {
+	return s.Schedule(name, Every(interval), run, opts...)
+}
+
+// Schedule registers a job running on the schedule. Jobs registered while
+// the scheduler is running start at once.
+func (s *Scheduler) Schedule(name string, schedule Schedule, run Job, opts ...JobOption) error {
+	if name == "" || schedule == nil || run == nil {
+		return fmt.Errorf("scheduler: job name, schedule and function are required")
+	}
+
+	j := &job{name: name, schedule: schedule, run: run, timeout: s.defaultTimeout}
+	for _, opt := range opts {
+		opt(j)
+	}
+	if j.lockTTL <= 0 {
+		j.lockTTL = j.timeout
+		if j.lockTTL <= 0 {
+			j.lockTTL = DefaultLockTTL
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[name]; ok {
+		return fmt.Errorf("%w: %s", ErrJobExists, name)
+	}
+	s.jobs[name] = j
+	if s.running {
+		s.startLoop(j)
+	}
+	return nil
+}
+
+// Remove unregisters a job. A running run of the job is not interrupted.
+func (s *Scheduler) Remove(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrJobNotFound, name)
+	}
+	delete(s.jobs, name)
+	return nil
+}
+
+// Start starts scheduling the registered jobs. Jobs run until Stop is called
+// or ctx is done.
+func (s *Scheduler) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return ErrSchedulerRunning
+	}
+
+	s.running = true
+	s.loopCtx, s.stop = context.WithCancel(ctx)
+	s.runCtx, s.cancelRun = context.WithCancel(context.WithoutCancel(ctx))
+	for _, j := range s.jobs {
+		s.startLoop(j)
+	}
+	return nil
+}
+
+// Stop stops scheduling jobs and waits for the running ones. When ctx is
+// done first, the running jobs are cancelled and ctx.Err() is returned.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return nil
+	}
+	s.running = false
+	s.stop()
+	cancelRun := s.cancelRun
+	s.mu.Unlock()
+
+	s.loops.Wait()
+
+	done := make(chan struct{})
+	go func() {
+		s.runs.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		cancelRun()
+		return nil
+	case <-ctx.Done():
+		cancelRun()
+		return ctx.Err()
+	}
+}
+
+// RunNow runs the job at once on this replica, with the same timeout and
+// overlap prevention as scheduled runs, and returns its error. Manual runs are
+// not coordinated, as they have no scheduled activation.
+func (s *Scheduler) RunNow(ctx context.Context, name string) error {
+	s.mu.Lock()
+	j, ok := s.jobs[name]
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrJobNotFound, name)
+	}
+
+	s.runs.Add(1)
+	defer s.runs.Done()
+	return s.execute(ctx, j, time.Time{})
+}
+
+// Jobs returns the state of the registered jobs sorted by name.
+func (s *Scheduler) Jobs() []JobInfo {
+	s.mu.Lock()
+	jobs := make([]*job, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		jobs = append(jobs, j)
+	}
+	s.mu.Unlock()
+
+	infos := make([]JobInfo, 0, len(jobs))
+	for _, j := range jobs {
+		j.mu.Lock()
+		infos = append(infos, JobInfo{
+			Name:      j.name,
+			Next:      j.next,
+			LastRun:   j.lastRun,
+			LastError: j.lastErr,
+			Runs:      j.runs,
+			Running:   j.running.Load() > 0,
+		})
+		j.mu.Unlock()
+	}
+	sort.Slice(infos, func(a, b int) bool { return infos[a].Name < infos[b].Name })
+	return infos
+}
+
+// startLoop starts the scheduling loop of the job. s.mu must be held.
+func (s *Scheduler) startLoop(j *job) {
+	ctx, runCtx := s.loopCtx, s.runCtx
+	s.loops.Add(1)
+	go func() {
+		defer s.loops.Done()
+		s.loop(ctx, runCtx, j)
+	}()
+}
+
+// loop waits for each activation of the job and starts a run.
+func (s *Scheduler) loop(ctx, runCtx context.Context, j *job) {
+	for {
+		next := j.schedule.Next(s.clock.Now())
+		j.mu.Lock()
+		j.next = next
+		j.mu.Unlock()
+		if next.IsZero() {
+			return
+		}
+
+		timer := s.clock.NewTimer(s.clock.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C():
+		}
+
+		if !s.registered(j) {
+			return
+		}
+
+		s.runs.Add(1)
+		go func() {
+			defer s.runs.Done()
+			err := s.execute(runCtx, j, next)
+			switch {
+			case errors.Is(err, ErrJobRunning), errors.Is(err, ErrLockNotAcquired):
+				s.logger.DebugContext(runCtx, "scheduler job skipped", "job", j.name, "reason", err)
+			case err != nil:
+				s.onError(runCtx, j.name, err)
+			}
+		}()
+	}
+}
+
+// registered reports whether the job is still registered.
+func (s *Scheduler) registered(j *job) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.jobs[j.name] == j
+}
+
+// execute runs the job once. Scheduled runs claim their activation from the
+// coordinator; a zero activation runs without coordination. The run goroutine
+// releases the overlap guard when the job returns, even after a timeout was
+// reported, so a job ignoring its context keeps blocking new runs.
+func (s *Scheduler) execute(ctx context.Context, j *job, activation time.Time) error {
+	if j.running.Add(1) > 1 && !j.allowOverlap {
+		j.running.Add(-1)
+		return fmt.Errorf("%w: %s", ErrJobRunning, j.name)
+	}
+
+	if s.coordinator != nil && !j.local && !activation.IsZero() {
+		acquired, err := s.coordinator.Lock(ctx, j.name, activation, j.lockTTL)
+		if err != nil {
+			j.running.Add(-1)
+			return domainerrors.Wrap(err, interfaces.InfrastructureError, "JOB_LOCK_FAILED",
+				fmt.Sprintf("failed to lock job %s", j.name))
+		}
+		if !acquired {
+			j.running.Add(-1)
+			return fmt.Errorf("%w: %s", ErrLockNotAcquired, j.name)
+		}
+	}
+
+	runCtx, cancel := ctx, context.CancelFunc(func() {})
+	if j.timeout > 0 {
+		runCtx, cancel = context.WithTimeout(ctx, j.timeout)
+	}
+
+	started := s.clock.Now()
+	done := make(chan error, 1)
+	s.runs.Add(1)
+	go func() {
+		defer s.runs.Done()
+		err := call(runCtx, j)
+
+		j.running.Add(-1)
+		done <- err
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-runCtx.Done():
+		select {
+		case err = <-done:
+		default:
+			err = runCtx.Err()
+		}
+	}
+	cancel()
+	if err != nil && errors.Is(runCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+		err = s.timeoutError(j, err)
+	}
+
+	j.mu.Lock()
+	j.lastRun = started
+	j.lastErr = err
+	j.runs++
+	j.mu.Unlock()
+	return err
+}
+
+// call runs the job, returning panics as ServerError domain errors.
+func call(ctx context.Context, j *job) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = domainerrors.NewWithMetadata(interfaces.ServerError, "JOB_PANIC",
+				fmt.Sprintf("job %s panicked: %v", j.name, p), map[string]interface{}{
+					"job":   j.name,
+					"stack": string(debug.Stack()),
+				})
+		}
+	}()
+	return j.run(ctx)
+}
+
+// timeoutError returns the Timeout domain error of the job.
+func (s *Scheduler) timeoutError(j *job, cause error) error {
+	return domainerrors.NewWithMetadata(interfaces.TimeoutError, "JOB_TIMEOUT",
+		fmt.Sprintf("job %s exceeded its timeout of %v", j.name, j.timeout), map[string]interface{}{
+			"job":     j.name,
+			"timeout": j.timeout.String(),
+			"cause":   cause.Error(),
+		}).Wrap(ErrJobTimeout)
+}
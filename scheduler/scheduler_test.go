@@ -0,0 +1,477 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	valkeyinterfaces "github.com/fsvxavier/nexs-lib/cache/valkey/interfaces"
+	"github.com/fsvxavier/nexs-lib/clock"
+	pginterfaces "github.com/fsvxavier/nexs-lib/db/postgres/interfaces"
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryCoordinator grants each activation to the first replica claiming it.
+type memoryCoordinator struct {
+	mu       sync.Mutex
+	claimed  map[string][]time.Time
+	attempts int
+	err      error
+}
+
+func newMemoryCoordinator() *memoryCoordinator {
+	return &memoryCoordinator{claimed: map[string][]time.Time{}}
+}
+
+func (c *memoryCoordinator) Lock(ctx context.Context, job string, activation time.Time, ttl time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.attempts++
+	if c.err != nil {
+		return false, c.err
+	}
+	for _, claimed := range c.claimed[job] {
+		if claimed.Equal(activation) {
+			return false, nil
+		}
+	}
+	c.claimed[job] = append(c.claimed[job], activation)
+	return true, nil
+}
+
+func (c *memoryCoordinator) Attempts() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.attempts
+}
+
+// receive returns the next value of ch, failing the test if none arrives.
+func receive[T any](t *testing.T, ch <-chan T) T {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the job")
+		var zero T
+		return zero
+	}
+}
+
+func TestScheduler_Register(t *testing.T) {
+	s := New()
+	noop := func(ctx context.Context) error { return nil }
+
+	require.NoError(t, s.Add("cleanup", "*/5 * * * *", noop))
+	require.NoError(t, s.Every("sync", time.Minute, noop))
+	assert.ErrorIs(t, s.Every("sync", time.Minute, noop), ErrJobExists)
+	assert.Error(t, s.Add("bad", "not a spec", noop))
+	assert.Error(t, s.Every("", time.Minute, noop))
+
+	jobs := s.Jobs()
+	require.Len(t, jobs, 2)
+	assert.Equal(t, "cleanup", jobs[0].Name)
+	assert.Equal(t, "sync", jobs[1].Name)
+
+	require.NoError(t, s.Remove("sync"))
+	assert.ErrorIs(t, s.Remove("sync"), ErrJobNotFound)
+	assert.ErrorIs(t, s.RunNow(context.Background(), "sync"), ErrJobNotFound)
+}
+
+func TestScheduler_StartStop(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, time.March, 10, 12, 0, 0, 0, time.UTC))
+	runs := make(chan time.Time, 10)
+	s := New(WithClock(fake))
+	require.NoError(t, s.Every("tick", time.Second, func(ctx context.Context) error {
+		runs <- fake.Now()
+		return nil
+	}))
+
+	require.NoError(t, s.Start(context.Background()))
+	assert.ErrorIs(t, s.Start(context.Background()), ErrSchedulerRunning)
+
+	for range 3 {
+		fake.BlockUntil(1)
+		fake.Advance(time.Second)
+		receive(t, runs)
+	}
+	require.NoError(t, s.Stop(context.Background()))
+
+	// The loops returned, so nothing waits on the clock anymore
+	assert.Zero(t, fake.Waiters())
+	fake.Advance(time.Hour)
+	assert.Empty(t, runs, "no runs after Stop")
+
+	info := s.Jobs()[0]
+	assert.False(t, info.LastRun.IsZero())
+	assert.Equal(t, int64(3), info.Runs)
+}
+
+func TestScheduler_FakeClock(t *testing.T) {
+	start := time.Date(2025, time.March, 10, 12, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	runs := make(chan time.Time, 10)
+	s := New(WithClock(fake), WithLocation(time.UTC))
+	require.NoError(t, s.Add("hourly", "0 * * * *", func(ctx context.Context) error {
+		runs <- fake.Now()
+		return nil
+	}))
+
+	require.NoError(t, s.Start(context.Background()))
+	defer s.Stop(context.Background())
+
+	fake.BlockUntil(1)
+	assert.Equal(t, start.Add(time.Hour), s.Jobs()[0].Next)
+	fake.Advance(59 * time.Minute)
+	assert.Empty(t, runs)
+
+	fake.Advance(time.Minute)
+	select {
+	case ran := <-runs:
+		assert.Equal(t, start.Add(time.Hour), ran)
+	case <-time.After(time.Second):
+		t.Fatal("job did not run")
+	}
+
+	fake.BlockUntil(1)
+	assert.Equal(t, start.Add(2*time.Hour), s.Jobs()[0].Next)
+	require.Eventually(t, func() bool { return s.Jobs()[0].Runs == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, start.Add(time.Hour), s.Jobs()[0].LastRun)
+}
+
+func TestScheduler_StopWaitsForRunningJobs(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, time.March, 10, 12, 0, 0, 0, time.UTC))
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var finished atomic.Bool
+	s := New(WithClock(fake))
+	require.NoError(t, s.Every("slow", time.Second, func(ctx context.Context) error {
+		close(started)
+		<-release
+		finished.Store(true)
+		return nil
+	}))
+
+	require.NoError(t, s.Start(context.Background()))
+	fake.BlockUntil(1)
+	fake.Advance(time.Second)
+	receive(t, started)
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- s.Stop(context.Background()) }()
+	select {
+	case <-stopped:
+		t.Fatal("Stop returned while the job was running")
+	default:
+	}
+
+	close(release)
+	require.NoError(t, receive(t, stopped))
+	assert.True(t, finished.Load())
+}
+
+func TestScheduler_StopCancelsJobsOnTimeout(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, time.March, 10, 12, 0, 0, 0, time.UTC))
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	s := New(WithClock(fake))
+	require.NoError(t, s.Every("blocking", time.Second, func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		close(cancelled)
+		return ctx.Err()
+	}))
+
+	require.NoError(t, s.Start(context.Background()))
+	fake.BlockUntil(1)
+	fake.Advance(time.Second)
+	receive(t, started)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, s.Stop(ctx), context.Canceled)
+	receive(t, cancelled)
+}
+
+func TestScheduler_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	s := New()
+	require.NoError(t, s.Every("slow", time.Hour, func(ctx context.Context) error {
+		<-release
+		return nil
+	}, WithTimeout(20*time.Millisecond)))
+
+	err := s.RunNow(context.Background(), "slow")
+	require.ErrorIs(t, err, ErrJobTimeout)
+	assert.True(t, domainerrors.IsType(err, interfaces.TimeoutError))
+
+	// The run keeps the job busy until it actually returns
+	assert.ErrorIs(t, s.RunNow(context.Background(), "slow"), ErrJobRunning)
+
+	require.NoError(t, s.Every("cooperative", time.Hour, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, WithTimeout(20*time.Millisecond)))
+	assert.ErrorIs(t, s.RunNow(context.Background(), "cooperative"), ErrJobTimeout)
+}
+
+func TestScheduler_TimeoutFastJob(t *testing.T) {
+	s := New()
+	require.NoError(t, s.Every("fast", time.Hour, func(ctx context.Context) error {
+		return nil
+	}, WithTimeout(time.Second)))
+
+	// A run that finishes well within its timeout must never be reported
+	// as JOB_TIMEOUT
+	for i := 0; i < 1000; i++ {
+		require.NoError(t, s.RunNow(context.Background(), "fast"))
+	}
+}
+
+func TestScheduler_Panic(t *testing.T) {
+	var reported error
+	s := New(WithErrorHandler(func(ctx context.Context, job string, err error) {
+		reported = err
+	}))
+	require.NoError(t, s.Every("panics", time.Hour, func(ctx context.Context) error {
+		panic("boom")
+	}))
+
+	err := s.RunNow(context.Background(), "panics")
+	var domainErr interfaces.DomainErrorInterface
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, "JOB_PANIC", domainErr.Code())
+	assert.Equal(t, interfaces.ServerError, domainErr.Type())
+	assert.Equal(t, "panics", domainErr.Metadata()["job"])
+	assert.Nil(t, reported, "RunNow returns the error instead of reporting it")
+
+	assert.Equal(t, err, s.Jobs()[0].LastError)
+}
+
+func TestScheduler_ErrorHandler(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, time.March, 10, 12, 0, 0, 0, time.UTC))
+	reported := make(chan string, 1)
+	s := New(WithClock(fake), WithErrorHandler(func(ctx context.Context, job string, err error) {
+		reported <- job + ": " + err.Error()
+	}))
+	require.NoError(t, s.Every("failing", time.Second, func(ctx context.Context) error {
+		return errors.New("failed")
+	}))
+
+	require.NoError(t, s.Start(context.Background()))
+	defer s.Stop(context.Background())
+	fake.BlockUntil(1)
+	fake.Advance(time.Second)
+
+	assert.Equal(t, "failing: failed", receive(t, reported))
+}
+
+func TestScheduler_Overlap(t *testing.T) {
+	release := make(chan struct{})
+	s := New()
+	require.NoError(t, s.Every("exclusive", time.Hour, func(ctx context.Context) error {
+		<-release
+		return nil
+	}))
+
+	errs := make(chan error, 1)
+	go func() { errs <- s.RunNow(context.Background(), "exclusive") }()
+	require.Eventually(t, func() bool { return s.Jobs()[0].Running }, time.Second, time.Millisecond)
+
+	assert.ErrorIs(t, s.RunNow(context.Background(), "exclusive"), ErrJobRunning)
+	close(release)
+	require.NoError(t, <-errs)
+	assert.False(t, s.Jobs()[0].Running)
+}
+
+func TestScheduler_Coordinator(t *testing.T) {
+	start := time.Date(2025, time.March, 10, 12, 0, 0, 0, time.UTC)
+	coordinator := newMemoryCoordinator()
+	clock1, clock2 := clock.NewFake(start), clock.NewFake(start)
+	replica1 := New(WithCoordinator(coordinator), WithClock(clock1), WithLocation(time.UTC))
+	replica2 := New(WithCoordinator(coordinator), WithClock(clock2), WithLocation(time.UTC))
+
+	runs := make(chan string, 10)
+	job := func(replica string) Job {
+		return func(ctx context.Context) error {
+			runs <- replica
+			return nil
+		}
+	}
+	require.NoError(t, replica1.Add("report", "0 * * * *", job("replica1")))
+	require.NoError(t, replica2.Add("report", "0 * * * *", job("replica2")))
+	require.NoError(t, replica1.Start(context.Background()))
+	defer replica1.Stop(context.Background())
+	require.NoError(t, replica2.Start(context.Background()))
+	defer replica2.Stop(context.Background())
+
+	clock1.BlockUntil(1)
+	clock1.Advance(time.Hour)
+	assert.Equal(t, "replica1", receive(t, runs))
+	require.Eventually(t, func() bool {
+		info := replica1.Jobs()[0]
+		return info.Runs == 1 && !info.Running
+	}, time.Second, time.Millisecond)
+
+	// The timer of the second replica fires late, after the run finished
+	clock2.BlockUntil(1)
+	clock2.Advance(time.Hour + 50*time.Millisecond)
+	require.Eventually(t, func() bool { return coordinator.Attempts() == 2 }, time.Second, time.Millisecond)
+
+	// The next activation goes to the first replica to fire
+	clock2.BlockUntil(1)
+	clock2.Advance(time.Hour)
+	assert.Equal(t, "replica2", receive(t, runs))
+	clock1.BlockUntil(1)
+	clock1.Advance(time.Hour)
+	require.Eventually(t, func() bool { return coordinator.Attempts() == 4 }, time.Second, time.Millisecond)
+
+	assert.Empty(t, runs, "each activation runs once")
+	assert.Equal(t, []time.Time{start.Add(time.Hour), start.Add(2 * time.Hour)}, coordinator.claimed["report"])
+}
+
+func TestScheduler_CoordinatorBypass(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, time.March, 10, 12, 0, 0, 0, time.UTC))
+	coordinator := newMemoryCoordinator()
+	coordinator.err = errors.New("connection refused")
+	reported := make(chan error, 1)
+	s := New(WithCoordinator(coordinator), WithClock(fake), WithErrorHandler(func(ctx context.Context, job string, err error) {
+		reported <- err
+	}))
+
+	var runs atomic.Int32
+	job := func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	}
+	require.NoError(t, s.Every("report", time.Hour, job))
+	require.NoError(t, s.Every("local", time.Hour, job, WithoutCoordination()))
+
+	// Manual runs have no activation to claim
+	require.NoError(t, s.RunNow(context.Background(), "report"))
+	assert.Zero(t, coordinator.Attempts())
+
+	require.NoError(t, s.Start(context.Background()))
+	defer s.Stop(context.Background())
+	fake.BlockUntil(2)
+	fake.Advance(time.Hour)
+
+	err := receive(t, reported)
+	assert.True(t, domainerrors.IsType(err, interfaces.InfrastructureError))
+	require.Eventually(t, func() bool { return runs.Load() == 2 }, time.Second, time.Millisecond)
+	assert.Equal(t, 1, coordinator.Attempts(), "jobs without coordination do not lock")
+}
+
+// fakeValkey emulates the script of the ValkeyCoordinator.
+type fakeValkey struct {
+	valkeyinterfaces.IClient
+	mu      sync.Mutex
+	strings map[string]string
+	expires map[string]string
+}
+
+func newFakeValkey() *fakeValkey {
+	return &fakeValkey{strings: map[string]string{}, expires: map[string]string{}}
+}
+
+func (f *fakeValkey) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if script != valkeyLockScript {
+		return nil, errors.New("unknown script")
+	}
+	if _, exists := f.strings[keys[0]]; exists {
+		return int64(0), nil
+	}
+	f.strings[keys[0]] = args[0].(string)
+	f.expires[keys[0]] = fmt.Sprint(args[1])
+	return int64(1), nil
+}
+
+func TestValkeyCoordinator(t *testing.T) {
+	client := newFakeValkey()
+	c := NewValkeyCoordinator(client, "")
+	ctx := context.Background()
+	activation := time.Date(2025, time.March, 10, 13, 0, 0, 0, time.UTC)
+	key := fmt.Sprintf("scheduler:report:%d:lock", activation.UnixMilli())
+
+	acquired, err := c.Lock(ctx, "report", activation, time.Minute)
+	require.NoError(t, err)
+	require.True(t, acquired)
+	assert.Equal(t, "60000", client.expires[key])
+	assert.Equal(t, "2025-03-10T13:00:00Z", client.strings[key])
+
+	acquired, err = c.Lock(ctx, "report", activation, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, acquired, "the claim is kept after the run")
+
+	acquired, err = c.Lock(ctx, "report", activation.Add(time.Hour), time.Minute)
+	require.NoError(t, err)
+	assert.True(t, acquired, "each activation has its own claim")
+}
+
+// fakePgDB records the statements of the PostgresCoordinator and answers
+// with the configured rows affected.
+type fakePgDB struct {
+	mu       sync.Mutex
+	queries  []string
+	args     [][]interface{}
+	affected int64
+}
+
+func (db *fakePgDB) Acquire(ctx context.Context) (pginterfaces.IConn, error) {
+	return &fakePgConn{db: db}, nil
+}
+
+type fakePgConn struct {
+	pginterfaces.IConn
+	db *fakePgDB
+}
+
+func (c *fakePgConn) Release() {}
+
+type fakeTag struct {
+	pginterfaces.ICommandTag
+	rows int64
+}
+
+func (t fakeTag) RowsAffected() int64 { return t.rows }
+
+func (c *fakePgConn) Exec(ctx context.Context, query string, args ...interface{}) (pginterfaces.ICommandTag, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.db.queries = append(c.db.queries, query)
+	c.db.args = append(c.db.args, args)
+	return fakeTag{rows: c.db.affected}, nil
+}
+
+func TestPostgresCoordinator(t *testing.T) {
+	_, err := NewPostgresCoordinator(&fakePgDB{}, "claims; DROP TABLE users", "")
+	assert.Error(t, err)
+
+	db := &fakePgDB{affected: 1}
+	c, err := NewPostgresCoordinator(db, "", "billing")
+	require.NoError(t, err)
+	assert.Contains(t, c.Schema(), "CREATE TABLE IF NOT EXISTS scheduler_activations")
+	ctx := context.Background()
+	activation := time.Date(2025, time.March, 10, 13, 0, 0, 0, time.UTC)
+
+	acquired, err := c.Lock(ctx, "report", activation, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	assert.Contains(t, db.queries[0], "WHERE a.activation < EXCLUDED.activation")
+	assert.Equal(t, []interface{}{"billing", "report", activation}, db.args[0])
+
+	db.affected = 0
+	acquired, err = c.Lock(ctx, "report", activation, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, acquired, "an activation already claimed is skipped")
+}
@@ -0,0 +1,51 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	valkeyinterfaces "github.com/fsvxavier/nexs-lib/cache/valkey/interfaces"
+)
+
+// valkeyLockScript claims an activation. The claim is a key per activation,
+// written with its TTL by a single SET NX PX, so it expires on its own and a
+// replica dying right after claiming cannot leave a key without expiration.
+const valkeyLockScript = `if redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+	return 1
+end
+return 0`
+
+// ValkeyCoordinator claims activations in Valkey or Redis through the
+// cache/valkey client, with a Lua script run by Eval. Each claim is kept
+// until its TTL expires, so it must exceed the clock skew between replicas.
+type ValkeyCoordinator struct {
+	client valkeyinterfaces.IClient
+	prefix string
+}
+
+// NewValkeyCoordinator creates a ValkeyCoordinator. Keys are prefixed with
+// prefix, "scheduler" when empty.
+func NewValkeyCoordinator(client valkeyinterfaces.IClient, prefix string) *ValkeyCoordinator {
+	if prefix == "" {
+		prefix = "scheduler"
+	}
+	return &ValkeyCoordinator{client: client, prefix: prefix}
+}
+
+// Lock implements Coordinator.
+func (c *ValkeyCoordinator) Lock(ctx context.Context, job string, activation time.Time, ttl time.Duration) (bool, error) {
+	result, err := c.client.Eval(ctx, valkeyLockScript, []string{c.lockKey(job, activation)},
+		activation.UTC().Format(time.RFC3339Nano), max(ttl.Milliseconds(), 1))
+	if err != nil {
+		return false, fmt.Errorf("failed to lock job: %w", err)
+	}
+	acquired, _ := result.(int64)
+	return acquired == 1, nil
+}
+
+// lockKey returns the key claiming the activation of the job.
+func (c *ValkeyCoordinator) lockKey(job string, activation time.Time) string {
+	return c.prefix + ":" + job + ":" + strconv.FormatInt(activation.UnixMilli(), 10) + ":lock"
+}
@@ -0,0 +1,272 @@
+package streams
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+)
+
+// StageOption configures a stage.
+type StageOption func(*stageConfig)
+
+type stageConfig struct {
+	buffer  int
+	workers int
+}
+
+// Buffer sets the output buffer of the stage.
+func Buffer(n int) StageOption {
+	return func(c *stageConfig) {
+		c.buffer = max(n, 0)
+	}
+}
+
+// Workers runs the function of the stage in n goroutines. Items may then
+// leave the stage out of order.
+func Workers(n int) StageOption {
+	return func(c *stageConfig) {
+		c.workers = max(n, 1)
+	}
+}
+
+func (p *Pipeline) stage(opts []StageOption) stageConfig {
+	c := stageConfig{buffer: p.buffer, workers: 1}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// workers runs work in c.workers goroutines and closes out when all return.
+func workers[T any](p *Pipeline, c stageConfig, out chan T, work func()) {
+	var wg sync.WaitGroup
+	wg.Add(c.workers)
+	for i := 0; i < c.workers; i++ {
+		p.spawn(func() {
+			defer wg.Done()
+			work()
+		})
+	}
+	p.spawn(func() {
+		wg.Wait()
+		close(out)
+	})
+}
+
+// From emits items.
+func From[T any](p *Pipeline, items []T, opts ...StageOption) <-chan T {
+	c := p.stage(opts)
+	out := make(chan T, c.buffer)
+	p.spawn(func() {
+		defer close(out)
+		for _, item := range items {
+			if !send(p.ctx, out, item) {
+				return
+			}
+		}
+	})
+	return out
+}
+
+// Generate emits the items produced by fn, which stops when emit returns
+// false. An error returned by fn is collected under the stage name.
+func Generate[T any](p *Pipeline, name string, fn func(ctx context.Context, emit func(T) bool) error, opts ...StageOption) <-chan T {
+	c := p.stage(opts)
+	out := make(chan T, c.buffer)
+	p.spawn(func() {
+		defer close(out)
+		emit := func(item T) bool { return send(p.ctx, out, item) }
+		_, err := call(name, func() (struct{}, error) { return struct{}{}, fn(p.ctx, emit) })
+		if err != nil {
+			p.fail(name, nil, err)
+		}
+	})
+	return out
+}
+
+// Map emits fn of every item. Items fn fails on are dropped and their error
+// is collected under the stage name.
+func Map[In, Out any](p *Pipeline, name string, in <-chan In, fn func(ctx context.Context, item In) (Out, error), opts ...StageOption) <-chan Out {
+	c := p.stage(opts)
+	out := make(chan Out, c.buffer)
+	workers(p, c, out, func() {
+		for item := range receive(p.ctx, in) {
+			result, err := call(name, func() (Out, error) { return fn(p.ctx, item) })
+			if err != nil {
+				p.fail(name, item, err)
+				continue
+			}
+			if !send(p.ctx, out, result) {
+				return
+			}
+		}
+	})
+	return out
+}
+
+// Filter emits the items keep returns true for. Items keep fails on are
+// dropped and their error is collected under the stage name.
+func Filter[T any](p *Pipeline, name string, in <-chan T, keep func(ctx context.Context, item T) (bool, error), opts ...StageOption) <-chan T {
+	c := p.stage(opts)
+	out := make(chan T, c.buffer)
+	workers(p, c, out, func() {
+		for item := range receive(p.ctx, in) {
+			ok, err := call(name, func() (bool, error) { return keep(p.ctx, item) })
+			if err != nil {
+				p.fail(name, item, err)
+				continue
+			}
+			if ok && !send(p.ctx, out, item) {
+				return
+			}
+		}
+	})
+	return out
+}
+
+// Batch groups items into batches of up to size items. A batch is emitted
+// when full, when maxWait has passed since its first item, or when the
+// input closes. A zero maxWait waits for full batches.
+func Batch[T any](p *Pipeline, in <-chan T, size int, maxWait time.Duration, opts ...StageOption) <-chan []T {
+	c := p.stage(opts)
+	size = max(size, 1)
+	out := make(chan []T, c.buffer)
+	p.spawn(func() {
+		defer close(out)
+
+		var batch []T
+		var timer clock.Timer
+		var expired <-chan time.Time
+		flush := func() bool {
+			if timer != nil {
+				timer.Stop()
+				timer, expired = nil, nil
+			}
+			if len(batch) == 0 {
+				return true
+			}
+			full := batch
+			batch = nil
+			return send(p.ctx, out, full)
+		}
+		defer func() {
+			if timer != nil {
+				timer.Stop()
+			}
+		}()
+
+		for {
+			select {
+			case item, ok := <-in:
+				if !ok {
+					flush()
+					return
+				}
+				if batch == nil {
+					batch = make([]T, 0, size)
+					if maxWait > 0 {
+						timer = p.clock.NewTimer(maxWait)
+						expired = timer.C()
+					}
+				}
+				batch = append(batch, item)
+				if len(batch) >= size && !flush() {
+					return
+				}
+			case <-expired:
+				if !flush() {
+					return
+				}
+			case <-p.ctx.Done():
+				return
+			}
+		}
+	})
+	return out
+}
+
+// FanOut distributes the items among n outputs, each item going to the
+// first output ready to take it, so that n consumers share the load.
+func FanOut[T any](p *Pipeline, in <-chan T, n int, opts ...StageOption) []<-chan T {
+	c := p.stage(opts)
+	outs := make([]<-chan T, max(n, 1))
+	for i := range outs {
+		out := make(chan T, c.buffer)
+		outs[i] = out
+		p.spawn(func() {
+			defer close(out)
+			for item := range receive(p.ctx, in) {
+				if !send(p.ctx, out, item) {
+					return
+				}
+			}
+		})
+	}
+	return outs
+}
+
+// FanIn merges the items of the inputs into one output.
+func FanIn[T any](p *Pipeline, ins ...<-chan T) <-chan T {
+	out := make(chan T, p.buffer)
+	var wg sync.WaitGroup
+	wg.Add(len(ins))
+	for _, in := range ins {
+		p.spawn(func() {
+			defer wg.Done()
+			for item := range receive(p.ctx, in) {
+				if !send(p.ctx, out, item) {
+					return
+				}
+			}
+		})
+	}
+	p.spawn(func() {
+		wg.Wait()
+		close(out)
+	})
+	return out
+}
+
+// ForEach calls fn for every item, ending the pipeline. Errors are collected
+// under the stage name; Wait returns once all items are consumed.
+func ForEach[T any](p *Pipeline, name string, in <-chan T, fn func(ctx context.Context, item T) error, opts ...StageOption) {
+	c := p.stage(opts)
+	for i := 0; i < c.workers; i++ {
+		p.spawn(func() {
+			for item := range receive(p.ctx, in) {
+				_, err := call(name, func() (struct{}, error) { return struct{}{}, fn(p.ctx, item) })
+				if err != nil {
+					p.fail(name, item, err)
+				}
+			}
+		})
+	}
+}
+
+// Collect returns the items of in once it closes or the pipeline stops.
+// It blocks; call Wait afterwards for the errors.
+func Collect[T any](p *Pipeline, in <-chan T) []T {
+	var items []T
+	for item := range receive(p.ctx, in) {
+		items = append(items, item)
+	}
+	return items
+}
+
+// receive iterates over in until it closes or ctx is done.
+func receive[T any](ctx context.Context, in <-chan T) func(yield func(T) bool) {
+	return func(yield func(T) bool) {
+		for {
+			select {
+			case item, ok := <-in:
+				if !ok || !yield(item) {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,229 @@
+// Package streams builds channel-based data pipelines from generic stages —
+// Map, Filter, Batch, FanOut, FanIn and sinks — that stop with their
+// context, use bounded buffers, and route the errors of every stage into a
+// MultiError instead of ad hoc error channels.
+package streams
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"sync"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+// ErrPanic is wrapped by the errors of stage functions that panicked.
+var ErrPanic = errors.New("streams: stage panicked")
+
+// DefaultBuffer is the output buffer of stages without their own.
+const DefaultBuffer = 16
+
+// StageError is the failure of a stage on an item.
+type StageError struct {
+	// Stage is the name of the stage.
+	Stage string
+	// Item is the input the stage failed on, nil for failures not tied to
+	// an item.
+	Item any
+	// Err is the error returned by the stage function.
+	Err error
+}
+
+// Error implements error.
+func (e *StageError) Error() string {
+	return fmt.Sprintf("stage %s: %v", e.Stage, e.Err)
+}
+
+// Unwrap returns the error of the stage function, so errors.As finds the
+// domain errors it returned.
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+// MultiError collects the errors of the stages of a pipeline. It is safe for
+// concurrent use.
+type MultiError struct {
+	mu     sync.Mutex
+	errors []*StageError
+}
+
+// Error implements error.
+func (m *MultiError) Error() string {
+	errs := m.Errors()
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("pipeline failed with %d error(s): %s", len(errs), strings.Join(msgs, "; "))
+}
+
+// Unwrap allows errors.Is and errors.As on the collected errors.
+func (m *MultiError) Unwrap() []error {
+	errs := m.Errors()
+	unwrapped := make([]error, len(errs))
+	for i, err := range errs {
+		unwrapped[i] = err
+	}
+	return unwrapped
+}
+
+// Errors returns the collected errors in arrival order.
+func (m *MultiError) Errors() []*StageError {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*StageError(nil), m.errors...)
+}
+
+// ByStage returns the errors of a stage.
+func (m *MultiError) ByStage(stage string) []*StageError {
+	var errs []*StageError
+	for _, err := range m.Errors() {
+		if err.Stage == stage {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// Len returns the number of collected errors.
+func (m *MultiError) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.errors)
+}
+
+// add collects an error and returns the new count.
+func (m *MultiError) add(err *StageError) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.errors = append(m.errors, err)
+	return len(m.errors)
+}
+
+// Option configures a Pipeline.
+type Option func(*Pipeline)
+
+// WithBuffer sets the default output buffer of the stages.
+func WithBuffer(n int) Option {
+	return func(p *Pipeline) {
+		p.buffer = max(n, 0)
+	}
+}
+
+// StopOnError cancels the pipeline on the first error. By default the
+// failed item is dropped and the pipeline goes on.
+func StopOnError() Option {
+	return func(p *Pipeline) {
+		p.stopOnError = true
+	}
+}
+
+// WithClock sets the clock of Batch timeouts. Defaults to clock.Real().
+func WithClock(c clock.Clock) Option {
+	return func(p *Pipeline) {
+		p.clock = clock.OrReal(c)
+	}
+}
+
+// Pipeline holds the context, goroutines and errors of a set of stages.
+type Pipeline struct {
+	ctx         context.Context
+	cancel      context.CancelCauseFunc
+	buffer      int
+	stopOnError bool
+	clock       clock.Clock
+
+	wg   sync.WaitGroup
+	errs MultiError
+}
+
+// New creates a Pipeline whose stages stop when ctx is done.
+func New(ctx context.Context, opts ...Option) *Pipeline {
+	ctx, cancel := context.WithCancelCause(ctx)
+	p := &Pipeline{
+		ctx:    ctx,
+		cancel: cancel,
+		buffer: DefaultBuffer,
+		clock:  clock.Real(),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Context returns the context of the stages.
+func (p *Pipeline) Context() context.Context {
+	return p.ctx
+}
+
+// Cancel stops the stages.
+func (p *Pipeline) Cancel() {
+	p.cancel(context.Canceled)
+}
+
+// Errors returns the errors collected so far.
+func (p *Pipeline) Errors() *MultiError {
+	return &p.errs
+}
+
+// Wait waits for the stages to finish and releases the pipeline context.
+// It returns the *MultiError of the stages, or the context error when the
+// pipeline was cancelled without stage errors, or nil.
+func (p *Pipeline) Wait() error {
+	p.wg.Wait()
+	err := p.ctx.Err()
+	p.cancel(context.Canceled)
+
+	if p.errs.Len() > 0 {
+		return &p.errs
+	}
+	return err
+}
+
+// spawn runs fn in a goroutine tracked by Wait.
+func (p *Pipeline) spawn(fn func()) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		fn()
+	}()
+}
+
+// fail collects the error of a stage.
+func (p *Pipeline) fail(stage string, item any, err error) {
+	stageErr := &StageError{Stage: stage, Item: item, Err: err}
+	if p.errs.add(stageErr) == 1 && p.stopOnError {
+		p.cancel(stageErr)
+	}
+}
+
+// call runs a stage function, turning a panic into an error.
+func call[T any](stage string, fn func() (T, error)) (result T, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = domainerrors.NewWithMetadata(interfaces.ServerError, "STAGE_PANIC",
+				fmt.Sprintf("stage %s panicked: %v", stage, r), map[string]interface{}{
+					"stage": stage,
+					"panic": fmt.Sprint(r),
+					"stack": string(debug.Stack()),
+				}).Wrap(ErrPanic)
+		}
+	}()
+	return fn()
+}
+
+// send sends v on out unless ctx is done first.
+func send[T any](ctx context.Context, out chan<- T, v T) bool {
+	select {
+	case out <- v:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
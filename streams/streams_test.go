@@ -0,0 +1,220 @@
+package streams
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+func TestPipeline(t *testing.T) {
+	p := New(context.Background())
+
+	numbers := From(p, []int{1, 2, 3, 4, 5, 6})
+	even := Filter(p, "even", numbers, func(_ context.Context, n int) (bool, error) {
+		return n%2 == 0, nil
+	})
+	labels := Map(p, "label", even, func(_ context.Context, n int) (string, error) {
+		return "n" + strconv.Itoa(n), nil
+	})
+
+	assert.Equal(t, []string{"n2", "n4", "n6"}, Collect(p, labels))
+	require.NoError(t, p.Wait())
+}
+
+func TestPipeline_Errors(t *testing.T) {
+	p := New(context.Background())
+
+	numbers := From(p, []int{1, 2, 3, 4})
+	parsed := Map(p, "validate", numbers, func(_ context.Context, n int) (int, error) {
+		if n == 2 {
+			return 0, domainerrors.NewValidationError("INVALID_ITEM", "item 2 is invalid")
+		}
+		if n == 3 {
+			panic("item 3")
+		}
+		return n, nil
+	})
+	var saved []int
+	ForEach(p, "save", parsed, func(_ context.Context, n int) error {
+		if n == 4 {
+			return errors.New("db down")
+		}
+		saved = append(saved, n)
+		return nil
+	})
+
+	err := p.Wait()
+	require.Error(t, err)
+	assert.Equal(t, []int{1}, saved)
+
+	var multi *MultiError
+	require.ErrorAs(t, err, &multi)
+	assert.Equal(t, 3, multi.Len())
+	assert.Len(t, multi.ByStage("validate"), 2)
+	require.Len(t, multi.ByStage("save"), 1)
+	assert.Equal(t, 4, multi.ByStage("save")[0].Item)
+	assert.EqualError(t, multi.ByStage("save")[0], "stage save: db down")
+	assert.ErrorIs(t, err, ErrPanic)
+
+	// Domain errors of the stages are reachable.
+	var domainErr interfaces.DomainErrorInterface
+	require.True(t, errors.As(err, &domainErr))
+	assert.Equal(t, "INVALID_ITEM", domainErr.Code())
+	assert.Contains(t, err.Error(), "pipeline failed with 3 error(s)")
+}
+
+func TestPipeline_StopOnError(t *testing.T) {
+	p := New(context.Background(), StopOnError(), WithBuffer(0))
+
+	var emitted atomic.Int32
+	source := Generate(p, "source", func(ctx context.Context, emit func(int) bool) error {
+		for i := 0; ; i++ {
+			if !emit(i) {
+				return nil
+			}
+			emitted.Add(1)
+		}
+	})
+	ForEach(p, "sink", source, func(_ context.Context, n int) error {
+		if n == 3 {
+			return fmt.Errorf("item %d", n)
+		}
+		return nil
+	})
+
+	err := p.Wait()
+	var multi *MultiError
+	require.ErrorAs(t, err, &multi)
+	assert.Equal(t, 1, multi.Len())
+	assert.Less(t, emitted.Load(), int32(10))
+	assert.Error(t, p.Context().Err())
+}
+
+func TestPipeline_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := New(ctx)
+
+	source := Generate(p, "ticks", func(ctx context.Context, emit func(int) bool) error {
+		for i := 0; emit(i); i++ {
+		}
+		return nil
+	})
+	ForEach(p, "sink", source, func(context.Context, int) error {
+		cancel()
+		return nil
+	})
+
+	assert.ErrorIs(t, p.Wait(), context.Canceled)
+}
+
+func TestGenerate_Error(t *testing.T) {
+	p := New(context.Background())
+	source := Generate(p, "read", func(_ context.Context, emit func(string) bool) error {
+		emit("a")
+		return errors.New("connection reset")
+	})
+	assert.Equal(t, []string{"a"}, Collect(p, source))
+
+	var multi *MultiError
+	require.ErrorAs(t, p.Wait(), &multi)
+	require.Len(t, multi.Errors(), 1)
+	assert.Nil(t, multi.Errors()[0].Item)
+	assert.Equal(t, "read", multi.Errors()[0].Stage)
+}
+
+func TestMap_Workers(t *testing.T) {
+	p := New(context.Background())
+	items := make([]int, 100)
+	for i := range items {
+		items[i] = i
+	}
+
+	var running, peak atomic.Int32
+	squares := Map(p, "square", From(p, items), func(_ context.Context, n int) (int, error) {
+		current := running.Add(1)
+		for {
+			old := peak.Load()
+			if current <= old || peak.CompareAndSwap(old, current) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		running.Add(-1)
+		return n * n, nil
+	}, Workers(4))
+
+	results := Collect(p, squares)
+	require.NoError(t, p.Wait())
+	require.Len(t, results, 100)
+	slices.Sort(results)
+	assert.Equal(t, 99*99, results[99])
+	assert.LessOrEqual(t, peak.Load(), int32(4))
+	assert.Greater(t, peak.Load(), int32(1))
+}
+
+func TestBatch(t *testing.T) {
+	p := New(context.Background())
+	batches := Batch(p, From(p, []int{1, 2, 3, 4, 5}), 2, 0)
+	assert.Equal(t, [][]int{{1, 2}, {3, 4}, {5}}, Collect(p, batches))
+	require.NoError(t, p.Wait())
+}
+
+func TestBatch_MaxWait(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	p := New(context.Background(), WithClock(fake))
+
+	in := make(chan int)
+	batches := Batch(p, in, 10, time.Second)
+
+	in <- 1
+	in <- 2
+	fake.BlockUntil(1)
+	fake.Advance(time.Second)
+	assert.Equal(t, []int{1, 2}, <-batches)
+
+	in <- 3
+	fake.BlockUntil(1)
+	close(in)
+	assert.Equal(t, []int{3}, <-batches)
+	_, open := <-batches
+	assert.False(t, open)
+	require.NoError(t, p.Wait())
+}
+
+func TestFanOutFanIn(t *testing.T) {
+	p := New(context.Background())
+	items := make([]int, 50)
+	for i := range items {
+		items[i] = i
+	}
+
+	outs := FanOut(p, From(p, items), 3)
+	require.Len(t, outs, 3)
+
+	var doubled []<-chan int
+	for i, out := range outs {
+		doubled = append(doubled, Map(p, fmt.Sprintf("double-%d", i), out, func(_ context.Context, n int) (int, error) {
+			return n * 2, nil
+		}))
+	}
+
+	results := Collect(p, FanIn(p, doubled...))
+	require.NoError(t, p.Wait())
+	require.Len(t, results, 50)
+	slices.Sort(results)
+	for i, n := range results {
+		assert.Equal(t, i*2, n)
+	}
+}
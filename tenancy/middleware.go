@@ -0,0 +1,158 @@
+package tenancy
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/fsvxavier/nexs-lib/httpserver/middlewares"
+)
+
+// Extractor returns the tenant of a request, or "" when the request does
+// not carry it.
+type Extractor func(r *http.Request) string
+
+// FromHeader extracts the tenant from a request header.
+func FromHeader(name string) Extractor {
+	return func(r *http.Request) string {
+		return strings.TrimSpace(r.Header.Get(name))
+	}
+}
+
+// FromJWTClaim extracts the tenant from a claim of the token validated by
+// the JWT middleware of httpserver/middlewares, which must run first.
+func FromJWTClaim(claim string) Extractor {
+	return func(r *http.Request) string {
+		claims, ok := middlewares.JWTClaimsFromContext(r.Context())
+		if !ok {
+			return ""
+		}
+		switch value := claims.Raw[claim].(type) {
+		case string:
+			return value
+		case float64:
+			return fmt.Sprint(value)
+		}
+		return ""
+	}
+}
+
+// FromSubdomain extracts the tenant from the first label of hosts under the
+// base domain: "acme.example.com" is tenant "acme" for base "example.com".
+// The base domain itself and deeper subdomains carry no tenant.
+func FromSubdomain(baseDomain string) Extractor {
+	suffix := "." + strings.ToLower(strings.Trim(baseDomain, "."))
+	return func(r *http.Request) string {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		host = strings.ToLower(strings.TrimSuffix(host, "."))
+		label, ok := strings.CutSuffix(host, suffix)
+		if !ok || label == "" || strings.Contains(label, ".") {
+			return ""
+		}
+		return label
+	}
+}
+
+// Config configures the middleware.
+type Config struct {
+	// Extractors are tried in order; the first tenant found is used.
+	// Defaults to the X-Tenant-ID header.
+	Extractors []Extractor
+
+	// Required rejects requests without tenant.
+	Required bool
+
+	// RequireMatch rejects requests whose extractors find different
+	// tenants, e.g. a header naming a tenant other than the token claim.
+	RequireMatch bool
+
+	// Validate checks the tenant, e.g. against known tenants. Defaults to
+	// the package Validate. Errors that are not domain errors are answered
+	// as INVALID_TENANT bad requests.
+	Validate func(tenantID string) error
+}
+
+// DefaultConfig returns a default middleware configuration.
+func DefaultConfig() Config {
+	return Config{
+		Extractors: []Extractor{FromHeader(HeaderTenantID)},
+		Validate:   Validate,
+	}
+}
+
+// Middleware sets the tenant of net/http requests in their context.
+type Middleware struct {
+	config Config
+}
+
+// New creates a Middleware. Zero values of the configuration take defaults.
+func New(config Config) *Middleware {
+	defaults := DefaultConfig()
+	if len(config.Extractors) == 0 {
+		config.Extractors = defaults.Extractors
+	}
+	if config.Validate == nil {
+		config.Validate = defaults.Validate
+	}
+	return &Middleware{config: config}
+}
+
+// Handler wraps the next handler.
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenantID, err := m.extract(r)
+		if err != nil {
+			middlewares.WriteProblem(w, err)
+			return
+		}
+		if tenantID == "" {
+			if m.config.Required {
+				middlewares.WriteProblem(w, domainerrors.New(interfaces.BadRequestError, "TENANT_REQUIRED",
+					"tenant is required").Wrap(ErrNoTenant))
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		if err := m.config.Validate(tenantID); err != nil {
+			var domainErr interfaces.DomainErrorInterface
+			if !errors.As(err, &domainErr) {
+				err = domainerrors.Wrap(err, interfaces.BadRequestError, "INVALID_TENANT", "invalid tenant")
+			}
+			middlewares.WriteProblem(w, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithTenant(r.Context(), tenantID)))
+	})
+}
+
+// extract runs the extractors.
+func (m *Middleware) extract(r *http.Request) (string, error) {
+	var tenantID string
+	for _, extract := range m.config.Extractors {
+		found := extract(r)
+		if found == "" {
+			continue
+		}
+		if tenantID == "" {
+			tenantID = found
+			if !m.config.RequireMatch {
+				break
+			}
+			continue
+		}
+		if found != tenantID {
+			return "", domainerrors.New(interfaces.AuthorizationError, "TENANT_MISMATCH",
+				"request names conflicting tenants").Wrap(ErrTenantMismatch)
+		}
+	}
+	return tenantID, nil
+}
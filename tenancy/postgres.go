@@ -0,0 +1,150 @@
+package tenancy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	pginterfaces "github.com/fsvxavier/nexs-lib/db/postgres/interfaces"
+)
+
+// DefaultSetting is the session setting holding the tenant, read by
+// row-level security policies with current_setting('app.tenant_id', true).
+const DefaultSetting = "app.tenant_id"
+
+// resetTimeout bounds the reset of a connection on release.
+const resetTimeout = 5 * time.Second
+
+// Acquirer is the part of a db/postgres pool used by Pool.
+type Acquirer interface {
+	Acquire(ctx context.Context) (pginterfaces.IConn, error)
+}
+
+// PoolOption configures a Pool.
+type PoolOption func(*Pool)
+
+// WithSetting sets the session setting receiving the tenant. Defaults to
+// DefaultSetting; "" disables it, e.g. for schema-per-tenant databases.
+func WithSetting(name string) PoolOption {
+	return func(p *Pool) {
+		p.setting = name
+	}
+}
+
+// WithSchema sets the search_path of connections to the schema of the
+// tenant, followed by public.
+func WithSchema(schema func(tenantID string) string) PoolOption {
+	return func(p *Pool) {
+		p.schema = schema
+	}
+}
+
+// Pool scopes acquired connections to the tenant of the context: it sets
+// the tenant setting, and the search_path with WithSchema, on acquire and
+// resets them on release, so pooled connections never leak a tenant to the
+// next request. Contexts without tenant get connections without settings.
+//
+// Pool satisfies the Acquire-only pool interfaces of jobs/pgqueue,
+// scheduler and leaderelection.
+type Pool struct {
+	pool    Acquirer
+	setting string
+	schema  func(tenantID string) string
+}
+
+// NewPool wraps a pool.
+func NewPool(pool Acquirer, opts ...PoolOption) *Pool {
+	p := &Pool{pool: pool, setting: DefaultSetting}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Acquire acquires a connection scoped to the tenant of the context.
+func (p *Pool) Acquire(ctx context.Context) (pginterfaces.IConn, error) {
+	conn, err := p.pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tenantID, ok := FromContext(ctx)
+	if !ok || (p.setting == "" && p.schema == nil) {
+		return conn, nil
+	}
+
+	scoped := &tenantConn{IConn: conn, pool: p}
+	if err := scoped.apply(ctx, tenantID); err != nil {
+		// The settings may be applied in part, so the connection goes
+		// back to no one
+		conn.Destroy(ctx)
+		return nil, fmt.Errorf("tenancy: scope connection to tenant %q: %w", tenantID, err)
+	}
+	return scoped, nil
+}
+
+// AcquireFunc runs f with a connection scoped to the tenant of the context.
+func (p *Pool) AcquireFunc(ctx context.Context, f func(pginterfaces.IConn) error) error {
+	conn, err := p.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+	return f(conn)
+}
+
+// tenantConn is a connection scoped to a tenant.
+type tenantConn struct {
+	pginterfaces.IConn
+	pool *Pool
+}
+
+// apply sets the tenant settings of the connection. They are session
+// settings, so they hold across the transactions run on the connection.
+func (c *tenantConn) apply(ctx context.Context, tenantID string) error {
+	if c.pool.setting != "" {
+		if _, err := c.IConn.Exec(ctx, "SELECT set_config($1, $2, false)", c.pool.setting, tenantID); err != nil {
+			return err
+		}
+	}
+	if c.pool.schema != nil {
+		path := quoteIdentifier(c.pool.schema(tenantID)) + ", public"
+		if _, err := c.IConn.Exec(ctx, "SELECT set_config('search_path', $1, false)", path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reset clears the tenant settings of the connection.
+func (c *tenantConn) reset(ctx context.Context) error {
+	if c.pool.setting != "" {
+		if _, err := c.IConn.Exec(ctx, "SELECT set_config($1, '', false)", c.pool.setting); err != nil {
+			return err
+		}
+	}
+	if c.pool.schema != nil {
+		if _, err := c.IConn.Exec(ctx, "RESET search_path"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Release resets the connection and returns it to the pool. Connections
+// that cannot be reset are destroyed instead, so their tenant settings never
+// reach another acquirer.
+func (c *tenantConn) Release() {
+	ctx, cancel := context.WithTimeout(context.Background(), resetTimeout)
+	defer cancel()
+	if err := c.reset(ctx); err != nil {
+		c.IConn.Destroy(ctx)
+		return
+	}
+	c.IConn.Release()
+}
+
+// quoteIdentifier quotes a PostgreSQL identifier.
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,162 @@
+// Package tenancy carries the tenant of a request through a multi-tenant
+// service: an HTTP middleware extracts the tenant from a header, a JWT claim
+// or the subdomain; context helpers store it and tag logs, spans and domain
+// errors with it; outgoing HTTP requests and messages propagate it; and a
+// PostgreSQL pool wrapper scopes connections to it with search_path or a
+// setting read by row-level security policies.
+package tenancy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/fsvxavier/nexs-lib/messaging/consumer"
+	loginterfaces "github.com/fsvxavier/nexs-lib/observability/logger/interfaces"
+)
+
+// Tenancy errors. The domain errors returned by the package wrap them, so
+// errors.Is can be used to tell failures apart.
+var (
+	ErrNoTenant       = errors.New("tenancy: no tenant in context")
+	ErrInvalidTenant  = errors.New("tenancy: invalid tenant")
+	ErrTenantMismatch = errors.New("tenancy: tenant sources disagree")
+)
+
+// Names used to propagate the tenant.
+const (
+	// HeaderTenantID is the HTTP header of the tenant.
+	HeaderTenantID = "X-Tenant-ID"
+
+	// MessageHeaderTenantID is the message header of the tenant.
+	MessageHeaderTenantID = "x-tenant-id"
+
+	// SpanAttribute is the span attribute of the tenant.
+	SpanAttribute = "tenant.id"
+
+	// MetadataKey is the domain error metadata key of the tenant.
+	MetadataKey = "tenant_id"
+)
+
+// tenantPattern is the default format of tenant IDs. It keeps them safe in
+// headers, log fields and schema names.
+var tenantPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,62}$`)
+
+// Validate checks a tenant ID against the default format: up to 63
+// letters, digits, "_" and "-", starting with a letter or digit.
+func Validate(tenantID string) error {
+	if !tenantPattern.MatchString(tenantID) {
+		return domainerrors.New(interfaces.BadRequestError, "INVALID_TENANT",
+			fmt.Sprintf("invalid tenant %q", tenantID)).Wrap(ErrInvalidTenant)
+	}
+	return nil
+}
+
+// tenantKey is the context key of the tenant.
+type tenantKey struct{}
+
+// WithTenant returns a context carrying the tenant. Loggers of
+// observability/logger add it to entries as tenant_id, and the recording
+// span of ctx gets the tenant.id attribute.
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	ctx = context.WithValue(ctx, tenantKey{}, tenantID)
+	ctx = context.WithValue(ctx, loginterfaces.TenantIDKey, tenantID)
+	if span := trace.SpanFromContext(ctx); span.IsRecording() {
+		span.SetAttributes(attribute.String(SpanAttribute, tenantID))
+	}
+	return ctx
+}
+
+// FromContext returns the tenant set by WithTenant.
+func FromContext(ctx context.Context) (string, bool) {
+	tenantID, ok := ctx.Value(tenantKey{}).(string)
+	return tenantID, ok && tenantID != ""
+}
+
+// Require returns the tenant of the context, or a BadRequest domain error
+// wrapping ErrNoTenant.
+func Require(ctx context.Context) (string, error) {
+	tenantID, ok := FromContext(ctx)
+	if !ok {
+		return "", domainerrors.New(interfaces.BadRequestError, "TENANT_REQUIRED",
+			"tenant is required").Wrap(ErrNoTenant)
+	}
+	return tenantID, nil
+}
+
+// SpanAttributes returns the span attributes of the tenant of the context,
+// for spans started before the tenant was known.
+func SpanAttributes(ctx context.Context) []attribute.KeyValue {
+	tenantID, ok := FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	return []attribute.KeyValue{attribute.String(SpanAttribute, tenantID)}
+}
+
+// TagError adds the tenant of the context to the metadata of a domain error.
+// Other errors, and domain errors wrapped by them, are returned unchanged.
+func TagError(ctx context.Context, err error) error {
+	tenantID, ok := FromContext(ctx)
+	if !ok {
+		return err
+	}
+	if domainErr, isDomain := err.(interfaces.DomainErrorInterface); isDomain {
+		return domainErr.WithMetadata(MetadataKey, tenantID)
+	}
+	return err
+}
+
+// Transport propagates the tenant of the request context in the
+// X-Tenant-ID header. A nil base uses http.DefaultTransport.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		tenantID, ok := FromContext(r.Context())
+		if !ok || r.Header.Get(HeaderTenantID) != "" {
+			return base.RoundTrip(r)
+		}
+		r = r.Clone(r.Context())
+		r.Header.Set(HeaderTenantID, tenantID)
+		return base.RoundTrip(r)
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// Inject sets the tenant of the context in message headers.
+func Inject(ctx context.Context, headers map[string]string) {
+	if tenantID, ok := FromContext(ctx); ok && headers != nil {
+		headers[MessageHeaderTenantID] = tenantID
+	}
+}
+
+// ConsumerMiddleware sets the tenant of message headers in the handler
+// context. Messages with an invalid tenant fail with consumer.Permanent.
+func ConsumerMiddleware() consumer.Middleware {
+	return func(next consumer.Handler) consumer.Handler {
+		return func(ctx context.Context, msg *consumer.Message) error {
+			tenantID := msg.Headers[MessageHeaderTenantID]
+			if tenantID == "" {
+				return next(ctx, msg)
+			}
+			if err := Validate(tenantID); err != nil {
+				return consumer.Permanent(err)
+			}
+			return next(WithTenant(ctx, tenantID), msg)
+		}
+	}
+}
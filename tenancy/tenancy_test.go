@@ -0,0 +1,394 @@
+package tenancy
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	pginterfaces "github.com/fsvxavier/nexs-lib/db/postgres/interfaces"
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/fsvxavier/nexs-lib/httpserver/middlewares"
+	"github.com/fsvxavier/nexs-lib/messaging/consumer"
+	loginterfaces "github.com/fsvxavier/nexs-lib/observability/logger/interfaces"
+)
+
+func TestWithTenant(t *testing.T) {
+	_, ok := FromContext(context.Background())
+	assert.False(t, ok)
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, span := provider.Tracer("test").Start(context.Background(), "request")
+
+	ctx = WithTenant(ctx, "acme")
+	span.End()
+
+	tenantID, ok := FromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "acme", tenantID)
+	assert.Equal(t, "acme", ctx.Value(loginterfaces.TenantIDKey))
+	assert.Equal(t, []attribute.KeyValue{attribute.String(SpanAttribute, "acme")}, SpanAttributes(ctx))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Contains(t, spans[0].Attributes(), attribute.String(SpanAttribute, "acme"))
+}
+
+func TestRequire(t *testing.T) {
+	_, err := Require(context.Background())
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrNoTenant)
+
+	var domainErr interfaces.DomainErrorInterface
+	require.True(t, errors.As(err, &domainErr))
+	assert.Equal(t, http.StatusBadRequest, domainErr.HTTPStatus())
+
+	tenantID, err := Require(WithTenant(context.Background(), "acme"))
+	require.NoError(t, err)
+	assert.Equal(t, "acme", tenantID)
+}
+
+func TestValidate(t *testing.T) {
+	for _, id := range []string{"acme", "acme-corp", "tenant_42", "A1"} {
+		assert.NoError(t, Validate(id), id)
+	}
+	for _, id := range []string{"", "-acme", "acme corp", "acme.com", "a\"b", strings.Repeat("a", 64)} {
+		assert.ErrorIs(t, Validate(id), ErrInvalidTenant, id)
+	}
+}
+
+func TestTagError(t *testing.T) {
+	ctx := WithTenant(context.Background(), "acme")
+
+	err := TagError(ctx, domainerrors.New(interfaces.NotFoundError, "ORDER_NOT_FOUND", "order not found"))
+	var domainErr interfaces.DomainErrorInterface
+	require.True(t, errors.As(err, &domainErr))
+	assert.Equal(t, "acme", domainErr.Metadata()[MetadataKey])
+
+	plain := errors.New("boom")
+	assert.Equal(t, plain, TagError(ctx, plain))
+	assert.Nil(t, TagError(ctx, nil))
+	assert.Equal(t, plain, TagError(context.Background(), plain))
+}
+
+func TestTransport(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(HeaderTenantID)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: Transport(nil)}
+	req, err := http.NewRequestWithContext(WithTenant(context.Background(), "acme"), http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, "acme", got)
+	assert.Empty(t, req.Header.Get(HeaderTenantID), "caller request must not be modified")
+}
+
+func TestConsumerMiddleware(t *testing.T) {
+	headers := map[string]string{}
+	Inject(WithTenant(context.Background(), "acme"), headers)
+	assert.Equal(t, "acme", headers[MessageHeaderTenantID])
+
+	var got string
+	handler := ConsumerMiddleware()(func(ctx context.Context, msg *consumer.Message) error {
+		got, _ = FromContext(ctx)
+		return nil
+	})
+
+	require.NoError(t, handler(context.Background(), &consumer.Message{Headers: headers}))
+	assert.Equal(t, "acme", got)
+
+	err := handler(context.Background(), &consumer.Message{Headers: map[string]string{MessageHeaderTenantID: "bad tenant"}})
+	assert.ErrorIs(t, err, consumer.ErrPoisonMessage)
+	assert.ErrorIs(t, err, ErrInvalidTenant)
+}
+
+// signToken builds an HS256 token.
+func signToken(secret []byte, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": "HS256", "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(input))
+	return input + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func serve(h http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+// echoTenant writes the tenant of the request context.
+var echoTenant = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	tenantID, _ := FromContext(r.Context())
+	w.Write([]byte(tenantID))
+})
+
+func TestMiddleware_Header(t *testing.T) {
+	handler := New(Config{}).Handler(echoTenant)
+
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set(HeaderTenantID, "acme")
+	rec := serve(handler, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "acme", rec.Body.String())
+
+	rec = serve(handler, httptest.NewRequest(http.MethodGet, "/orders", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set(HeaderTenantID, "../acme")
+	rec = serve(handler, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "INVALID_TENANT")
+}
+
+func TestMiddleware_Required(t *testing.T) {
+	handler := New(Config{Required: true}).Handler(echoTenant)
+
+	rec := serve(handler, httptest.NewRequest(http.MethodGet, "/orders", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, middlewares.ProblemContentType, rec.Header().Get("Content-Type"))
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "TENANT_REQUIRED", body["code"])
+}
+
+func TestMiddleware_CustomValidate(t *testing.T) {
+	handler := New(Config{Validate: func(tenantID string) error {
+		return fmt.Errorf("tenant %s not found in tenants table", tenantID)
+	}}).Handler(echoTenant)
+
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set(HeaderTenantID, "acme")
+	rec := serve(handler, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "INVALID_TENANT", body["code"])
+	assert.Equal(t, "invalid tenant", body["detail"])
+}
+
+func TestMiddleware_Subdomain(t *testing.T) {
+	extract := FromSubdomain("example.com")
+
+	for host, want := range map[string]string{
+		"acme.example.com":      "acme",
+		"ACME.example.com:8443": "acme",
+		"example.com":           "",
+		"a.b.example.com":       "",
+		"acme.example.org":      "",
+		"acmeexample.com":       "",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Host = host
+		assert.Equal(t, want, extract(req), host)
+	}
+}
+
+func TestMiddleware_JWTClaim(t *testing.T) {
+	secret := []byte("secret")
+	jwt := middlewares.NewJWTMiddlewareWithConfig(1, middlewares.JWTConfig{Secret: secret})
+	tenant := New(Config{
+		Extractors:   []Extractor{FromJWTClaim("tenant_id"), FromHeader(HeaderTenantID)},
+		Required:     true,
+		RequireMatch: true,
+	})
+	handler := jwt.Handler(tenant.Handler(echoTenant))
+
+	token := signToken(secret, map[string]interface{}{
+		"sub":       "user-1",
+		"tenant_id": "acme",
+		"exp":       time.Now().Add(time.Hour).Unix(),
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := serve(handler, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "acme", rec.Body.String())
+
+	req.Header.Set(HeaderTenantID, "acme")
+	rec = serve(handler, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	req.Header.Set(HeaderTenantID, "globex")
+	rec = serve(handler, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), "TENANT_MISMATCH")
+}
+
+// fakeConn records the statements run on it.
+type fakeConn struct {
+	pginterfaces.IConn
+	execs     []string
+	failOn    string
+	released  bool
+	destroyed bool
+}
+
+type fakeTag struct {
+	pginterfaces.ICommandTag
+}
+
+func (c *fakeConn) Exec(ctx context.Context, query string, args ...interface{}) (pginterfaces.ICommandTag, error) {
+	stmt := query
+	for _, arg := range args {
+		stmt += " | " + arg.(string)
+	}
+	c.execs = append(c.execs, stmt)
+	if c.failOn != "" && strings.Contains(stmt, c.failOn) {
+		return nil, errors.New("exec failed")
+	}
+	return fakeTag{}, nil
+}
+
+func (c *fakeConn) Release() {
+	c.released = true
+}
+
+func (c *fakeConn) Destroy(ctx context.Context) error {
+	c.destroyed = true
+	return nil
+}
+
+type fakePool struct {
+	conn *fakeConn
+}
+
+func (p *fakePool) Acquire(ctx context.Context) (pginterfaces.IConn, error) {
+	return p.conn, nil
+}
+
+// recyclingPool hands released connections to the next acquirer and opens
+// new ones when none is idle.
+type recyclingPool struct {
+	idle   []*recycledConn
+	opened int
+	failOn string
+}
+
+// recycledConn returns itself to its recyclingPool on release.
+type recycledConn struct {
+	*fakeConn
+	pool *recyclingPool
+}
+
+func (c *recycledConn) Release() {
+	c.pool.idle = append(c.pool.idle, c)
+}
+
+func (p *recyclingPool) Acquire(ctx context.Context) (pginterfaces.IConn, error) {
+	if n := len(p.idle); n > 0 {
+		conn := p.idle[n-1]
+		p.idle = p.idle[:n-1]
+		return conn, nil
+	}
+	p.opened++
+	return &recycledConn{fakeConn: &fakeConn{failOn: p.failOn}, pool: p}, nil
+}
+
+func TestPool_Setting(t *testing.T) {
+	conn := &fakeConn{}
+	pool := NewPool(&fakePool{conn: conn})
+
+	err := pool.AcquireFunc(WithTenant(context.Background(), "acme"), func(c pginterfaces.IConn) error {
+		_, err := c.Exec(context.Background(), "SELECT 1")
+		return err
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"SELECT set_config($1, $2, false) | app.tenant_id | acme",
+		"SELECT 1",
+		"SELECT set_config($1, '', false) | app.tenant_id",
+	}, conn.execs)
+	assert.True(t, conn.released)
+	assert.False(t, conn.destroyed)
+}
+
+func TestPool_Schema(t *testing.T) {
+	conn := &fakeConn{}
+	pool := NewPool(&fakePool{conn: conn}, WithSetting(""), WithSchema(func(tenantID string) string {
+		return "tenant_" + tenantID
+	}))
+
+	c, err := pool.Acquire(WithTenant(context.Background(), "acme"))
+	require.NoError(t, err)
+	c.Release()
+
+	assert.Equal(t, []string{
+		`SELECT set_config('search_path', $1, false) | "tenant_acme", public`,
+		"RESET search_path",
+	}, conn.execs)
+	assert.True(t, conn.released)
+}
+
+func TestPool_NoTenant(t *testing.T) {
+	conn := &fakeConn{}
+	pool := NewPool(&fakePool{conn: conn})
+
+	c, err := pool.Acquire(context.Background())
+	require.NoError(t, err)
+	assert.Same(t, conn, c)
+	c.Release()
+	assert.Empty(t, conn.execs)
+}
+
+func TestPool_Failures(t *testing.T) {
+	conn := &fakeConn{failOn: "acme"}
+	pool := NewPool(&fakePool{conn: conn})
+	_, err := pool.Acquire(WithTenant(context.Background(), "acme"))
+	require.Error(t, err)
+	assert.True(t, conn.destroyed, "connection that cannot be scoped must be destroyed")
+	assert.False(t, conn.released)
+
+	conn = &fakeConn{failOn: "''"}
+	pool = NewPool(&fakePool{conn: conn})
+	c, err := pool.Acquire(WithTenant(context.Background(), "acme"))
+	require.NoError(t, err)
+	c.Release()
+	assert.True(t, conn.destroyed, "connection that cannot be reset must be destroyed")
+	assert.False(t, conn.released)
+}
+
+func TestPool_ResetFailureNotReused(t *testing.T) {
+	recycling := &recyclingPool{failOn: "''"}
+	pool := NewPool(recycling)
+
+	tainted, err := pool.Acquire(WithTenant(context.Background(), "acme"))
+	require.NoError(t, err)
+	tainted.Release()
+	assert.Empty(t, recycling.idle, "connection that cannot be reset must not return to the pool")
+
+	next, err := pool.Acquire(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, recycling.opened)
+	assert.NotSame(t, tainted.(*tenantConn).IConn, next)
+	assert.Empty(t, next.(*recycledConn).execs, "next acquirer must get a fresh connection")
+}
@@ -0,0 +1,401 @@
+// Package chaos injects faults — latency, errors, panics and partial
+// responses — at named points of the code, to test how services behave when
+// their dependencies fail. Faults are configured per point with a
+// probability, a limit and context tags, and injected by middlewares of the
+// HTTP server and client, the db/postgres hooks and the messaging consumer,
+// or by calls to Inject in any code. A nil *Injector injects nothing, so
+// points can stay in production code paths. Scenarios run a workload under a
+// set of faults and report what was injected and how the workload failed.
+//
+//	inj := chaos.New(chaos.WithSeed(1))
+//	inj.Add("http.client.payments", chaos.Latency(2*time.Second), chaos.Probability(0.3))
+//	inj.Add("db.*", chaos.Error(nil), chaos.WhenTag("operation", "exec"), chaos.Times(1))
+//	client := &http.Client{Transport: chaos.Transport(inj, "http.client.payments", nil)}
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math/rand/v2"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+)
+
+// ErrInjected is the error of Error(nil) faults.
+var ErrInjected = errors.New("chaos: injected fault")
+
+// Kind is a kind of fault.
+type Kind int
+
+// Kinds of faults.
+const (
+	KindLatency Kind = iota
+	KindError
+	KindPanic
+	KindPartial
+)
+
+// String returns the name of the kind.
+func (k Kind) String() string {
+	switch k {
+	case KindLatency:
+		return "latency"
+	case KindError:
+		return "error"
+	case KindPanic:
+		return "panic"
+	case KindPartial:
+		return "partial"
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// Fault is a fault to inject.
+type Fault struct {
+	Kind Kind
+	// Latency is the delay of KindLatency, up to MaxLatency when greater.
+	Latency    time.Duration
+	MaxLatency time.Duration
+	// Err is the error of KindError.
+	Err error
+	// PanicValue is the value of KindPanic.
+	PanicValue any
+	// Keep is the fraction of the response kept by KindPartial, in [0, 1).
+	Keep float64
+}
+
+// Latency delays the operation by d.
+func Latency(d time.Duration) Fault {
+	return Fault{Kind: KindLatency, Latency: d}
+}
+
+// LatencyRange delays the operation by a random duration in [min, max].
+func LatencyRange(min, max time.Duration) Fault {
+	return Fault{Kind: KindLatency, Latency: min, MaxLatency: max}
+}
+
+// Error fails the operation with err, or ErrInjected when nil.
+func Error(err error) Fault {
+	if err == nil {
+		err = ErrInjected
+	}
+	return Fault{Kind: KindError, Err: err}
+}
+
+// Panic panics with v.
+func Panic(v any) Fault {
+	return Fault{Kind: KindPanic, PanicValue: v}
+}
+
+// PartialResponse cuts the response after the fraction keep of its bytes, as
+// a connection dropped in the middle of the body.
+func PartialResponse(keep float64) Fault {
+	return Fault{Kind: KindPartial, Keep: min(max(keep, 0), 1)}
+}
+
+// tagsKey is the context key of the tags.
+type tagsKey struct{}
+
+// WithTags returns a context with the tags, given as key and value pairs,
+// added to those already in ctx. Rules with WhenTag only inject faults in
+// operations whose context has their tags. The middlewares add their own:
+// method and path (HTTP server), method and host (HTTP client), operation
+// (db) and topic (messaging).
+func WithTags(ctx context.Context, kv ...string) context.Context {
+	tags := maps.Clone(Tags(ctx))
+	if tags == nil {
+		tags = make(map[string]string, len(kv)/2)
+	}
+	for i := 0; i+1 < len(kv); i += 2 {
+		tags[kv[i]] = kv[i+1]
+	}
+	return context.WithValue(ctx, tagsKey{}, tags)
+}
+
+// Tags returns the tags of the context.
+func Tags(ctx context.Context) map[string]string {
+	tags, _ := ctx.Value(tagsKey{}).(map[string]string)
+	return tags
+}
+
+// rule is a fault configured at points.
+type rule struct {
+	id          int
+	pattern     string
+	fault       Fault
+	probability float64
+	tags        map[string]string
+	remaining   int
+}
+
+// RuleOption configures a rule.
+type RuleOption func(*rule)
+
+// Probability injects the fault in the fraction p of the operations. The
+// default is 1, every operation.
+func Probability(p float64) RuleOption {
+	return func(r *rule) {
+		r.probability = p
+	}
+}
+
+// WhenTag injects the fault only in operations whose context has the tag.
+// Several WhenTag must all match.
+func WhenTag(key, value string) RuleOption {
+	return func(r *rule) {
+		r.tags[key] = value
+	}
+}
+
+// Times injects the fault at most n times.
+func Times(n int) RuleOption {
+	return func(r *rule) {
+		r.remaining = n
+	}
+}
+
+// Option configures an Injector.
+type Option func(*Injector)
+
+// WithSeed seeds the random choices of the probabilities and latency
+// ranges, so runs can be reproduced.
+func WithSeed(seed uint64) Option {
+	return func(in *Injector) {
+		in.rand = rand.New(rand.NewPCG(seed, seed))
+	}
+}
+
+// WithClock sets the clock of the latencies, for tests with a fake clock.
+func WithClock(c clock.Clock) Option {
+	return func(in *Injector) {
+		in.clock = clock.OrReal(c)
+	}
+}
+
+// Injector decides the faults injected at each point. It is safe for
+// concurrent use; rules can be added and removed while it is used.
+type Injector struct {
+	mu       sync.Mutex
+	rules    []*rule
+	nextID   int
+	disabled bool
+	rand     *rand.Rand
+	clock    clock.Clock
+	counts   map[string]map[Kind]int
+}
+
+// New creates an Injector without rules.
+func New(opts ...Option) *Injector {
+	in := &Injector{
+		rand:   rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+		clock:  clock.Real(),
+		counts: make(map[string]map[Kind]int),
+	}
+	for _, opt := range opts {
+		opt(in)
+	}
+	return in
+}
+
+// Add injects the fault at the points matching pattern, a point name or a
+// path.Match pattern such as "db.*". Rules are evaluated in the order they
+// were added; every matching rule injects its fault. The returned function
+// removes the rule.
+func (in *Injector) Add(pattern string, fault Fault, opts ...RuleOption) (remove func()) {
+	r := &rule{pattern: pattern, fault: fault, probability: 1, tags: make(map[string]string)}
+	for _, opt := range opts {
+		opt(r)
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.nextID++
+	r.id = in.nextID
+	in.rules = append(in.rules, r)
+	return func() {
+		in.mu.Lock()
+		defer in.mu.Unlock()
+		for i, existing := range in.rules {
+			if existing.id == r.id {
+				in.rules = append(in.rules[:i:i], in.rules[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Reset removes the rules and the counts.
+func (in *Injector) Reset() {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.rules = nil
+	in.counts = make(map[string]map[Kind]int)
+}
+
+// SetEnabled enables or disables the injection, keeping the rules.
+func (in *Injector) SetEnabled(enabled bool) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.disabled = !enabled
+}
+
+// Count returns the number of faults of the kind injected at the point.
+func (in *Injector) Count(point string, kind Kind) int {
+	if in == nil {
+		return 0
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return in.counts[point][kind]
+}
+
+// Counts returns the number of faults injected by point and kind.
+func (in *Injector) Counts() map[string]map[Kind]int {
+	if in == nil {
+		return nil
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	out := make(map[string]map[Kind]int, len(in.counts))
+	for point, kinds := range in.counts {
+		out[point] = maps.Clone(kinds)
+	}
+	return out
+}
+
+// outcome is the combination of the faults injected in an operation.
+type outcome struct {
+	latency    time.Duration
+	err        error
+	panics     bool
+	panicValue any
+	partial    bool
+	keep       float64
+}
+
+// decide returns the faults injected in the operation at the point.
+func (in *Injector) decide(ctx context.Context, point string) outcome {
+	var o outcome
+	if in == nil {
+		return o
+	}
+	tags := Tags(ctx)
+
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if in.disabled {
+		return o
+	}
+	for _, r := range in.rules {
+		if matched, _ := path.Match(r.pattern, point); !matched {
+			continue
+		}
+		if !matchTags(r.tags, tags) {
+			continue
+		}
+		if r.probability < 1 && in.rand.Float64() >= r.probability {
+			continue
+		}
+		if r.remaining < 0 {
+			continue
+		}
+		if r.remaining > 0 {
+			if r.remaining--; r.remaining == 0 {
+				r.remaining = -1
+			}
+		}
+
+		f := r.fault
+		switch f.Kind {
+		case KindLatency:
+			d := f.Latency
+			if f.MaxLatency > d {
+				d += time.Duration(in.rand.Int64N(int64(f.MaxLatency - d + 1)))
+			}
+			o.latency += d
+		case KindError:
+			if o.err == nil {
+				o.err = f.Err
+			}
+		case KindPanic:
+			if !o.panics {
+				o.panics, o.panicValue = true, f.PanicValue
+			}
+		case KindPartial:
+			if !o.partial {
+				o.partial, o.keep = true, f.Keep
+			}
+		}
+		if in.counts[point] == nil {
+			in.counts[point] = make(map[Kind]int)
+		}
+		in.counts[point][f.Kind]++
+	}
+	return o
+}
+
+// matchTags reports whether tags has every tag of want.
+func matchTags(want, tags map[string]string) bool {
+	for k, v := range want {
+		if tags[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// apply injects the latency, then the panic or the error of the outcome.
+// It returns ctx.Err() when the context ends during the latency.
+func (in *Injector) apply(ctx context.Context, o outcome) error {
+	if o.latency > 0 {
+		timer := in.clock.NewTimer(o.latency)
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+	if o.panics {
+		panic(o.panicValue)
+	}
+	return o.err
+}
+
+// Inject injects the faults of the point in the operation: it waits the
+// latency, panics or returns the error. Partial responses are ignored; use
+// InjectData for operations returning data.
+//
+//	if err := inj.Inject(ctx, "payments.charge"); err != nil {
+//		return err
+//	}
+func (in *Injector) Inject(ctx context.Context, point string) error {
+	return in.apply(ctx, in.decide(ctx, point))
+}
+
+// InjectData injects the faults of the point in an operation that returned
+// data: partial responses cut the data and return io.ErrUnexpectedEOF.
+func (in *Injector) InjectData(ctx context.Context, point string, data []byte) ([]byte, error) {
+	o := in.decide(ctx, point)
+	if err := in.apply(ctx, o); err != nil {
+		return nil, err
+	}
+	if o.partial {
+		return data[:cut(len(data), o.keep)], io.ErrUnexpectedEOF
+	}
+	return data, nil
+}
+
+// cut returns the number of bytes of n kept by keep, less than n when n > 0.
+func cut(n int, keep float64) int {
+	kept := int(float64(n) * keep)
+	if kept >= n && n > 0 {
+		kept = n - 1
+	}
+	return kept
+}
@@ -0,0 +1,252 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+	"github.com/fsvxavier/nexs-lib/db/postgres/hooks"
+	pginterfaces "github.com/fsvxavier/nexs-lib/db/postgres/interfaces"
+	"github.com/fsvxavier/nexs-lib/messaging/consumer"
+)
+
+func TestInjector_Rules(t *testing.T) {
+	ctx := context.Background()
+	in := New(WithSeed(1))
+	boom := errors.New("boom")
+
+	remove := in.Add("db.*", Error(boom), WhenTag("operation", "exec"))
+	assert.NoError(t, in.Inject(ctx, "db.orders"), "tag does not match")
+	assert.ErrorIs(t, in.Inject(WithTags(ctx, "operation", "exec"), "db.orders"), boom)
+	assert.NoError(t, in.Inject(WithTags(ctx, "operation", "exec"), "http.orders"), "point does not match")
+	remove()
+	assert.NoError(t, in.Inject(WithTags(ctx, "operation", "exec"), "db.orders"))
+
+	in.Add("payments", Error(nil), Times(2))
+	assert.ErrorIs(t, in.Inject(ctx, "payments"), ErrInjected)
+	assert.ErrorIs(t, in.Inject(ctx, "payments"), ErrInjected)
+	assert.NoError(t, in.Inject(ctx, "payments"))
+	assert.Equal(t, 2, in.Count("payments", KindError))
+
+	in.Add("flaky", Error(nil), Probability(0.3))
+	failures := 0
+	for range 1000 {
+		if in.Inject(ctx, "flaky") != nil {
+			failures++
+		}
+	}
+	assert.InDelta(t, 300, failures, 60)
+
+	in.Add("crash", Panic("kaboom"))
+	assert.PanicsWithValue(t, "kaboom", func() { _ = in.Inject(ctx, "crash") })
+
+	in.SetEnabled(false)
+	assert.NoError(t, in.Inject(ctx, "payments"))
+	assert.NotPanics(t, func() { _ = in.Inject(ctx, "crash") })
+	in.SetEnabled(true)
+
+	in.Reset()
+	assert.NoError(t, in.Inject(ctx, "crash"))
+	assert.Empty(t, in.Counts())
+
+	var disabled *Injector
+	assert.NoError(t, disabled.Inject(ctx, "payments"), "nil injectors inject nothing")
+	data, err := disabled.InjectData(ctx, "payments", []byte("abc"))
+	require.NoError(t, err)
+	assert.Equal(t, "abc", string(data))
+}
+
+func TestInjector_Latency(t *testing.T) {
+	fake := clock.NewFake(time.Time{})
+	in := New(WithClock(fake))
+	in.Add("slow", Latency(time.Second))
+
+	done := make(chan error, 1)
+	go func() { done <- in.Inject(context.Background(), "slow") }()
+	fake.BlockUntil(1)
+	fake.Advance(time.Second)
+	require.NoError(t, <-done)
+	assert.Equal(t, 1, in.Count("slow", KindLatency))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() { done <- in.Inject(ctx, "slow") }()
+	fake.BlockUntil(1)
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+
+	ranged := New(WithSeed(2))
+	ranged.Add("slow", LatencyRange(time.Millisecond, 3*time.Millisecond))
+	for range 20 {
+		o := ranged.decide(context.Background(), "slow")
+		assert.True(t, o.latency >= time.Millisecond && o.latency <= 3*time.Millisecond, o.latency)
+	}
+}
+
+func TestInjectData(t *testing.T) {
+	in := New()
+	in.Add("read", PartialResponse(0.5))
+	data, err := in.InjectData(context.Background(), "read", []byte("0123456789"))
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.Equal(t, "01234", string(data))
+	assert.NoError(t, in.Inject(context.Background(), "read"), "Inject ignores partial responses")
+}
+
+type statusError struct{ status int }
+
+func (e statusError) Error() string   { return "rate limited" }
+func (e statusError) HTTPStatus() int { return e.status }
+
+func TestMiddleware(t *testing.T) {
+	in := New()
+	handler := Middleware(in, "http.server")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = io.WriteString(w, "0123456789")
+	}))
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	get := func(path string) (*http.Response, string, error) {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			return nil, "", err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		return resp, string(body), err
+	}
+
+	resp, body, err := get("/orders")
+	require.NoError(t, err)
+	assert.Equal(t, "0123456789", body)
+
+	remove := in.Add("http.server", Error(nil), WhenTag("path", "/orders"))
+	resp, _, err = get("/orders")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	_, body, err = get("/health")
+	require.NoError(t, err)
+	assert.Equal(t, "0123456789", body)
+	remove()
+
+	remove = in.Add("http.server", Error(statusError{http.StatusTooManyRequests}))
+	resp, _, err = get("/orders")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	remove()
+
+	in.Add("http.server", PartialResponse(0.3))
+	resp, body, err = get("/orders")
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	if resp != nil {
+		assert.Equal(t, "012", body)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "0123456789")
+	}))
+	defer server.Close()
+
+	in := New()
+	client := &http.Client{Transport: Transport(in, "http.client", nil)}
+
+	remove := in.Add("http.client", Error(nil), WhenTag("method", http.MethodPost))
+	_, err := client.Post(server.URL, "text/plain", nil)
+	assert.ErrorIs(t, err, ErrInjected)
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	remove()
+
+	in.Add("http.client", PartialResponse(0.5))
+	resp, err = client.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.Equal(t, "01234", string(body))
+}
+
+func TestPostgresHook(t *testing.T) {
+	in := New()
+	manager := hooks.NewHookManager(time.Second)
+	require.NoError(t, RegisterPostgres(manager, in, "db"))
+
+	exec := &pginterfaces.ExecutionContext{Context: context.Background(), Operation: "exec"}
+	require.NoError(t, manager.ExecuteHooks(pginterfaces.BeforeExecHook, exec))
+
+	in.Add("db", Error(nil), WhenTag("operation", "exec"))
+	assert.ErrorIs(t, manager.ExecuteHooks(pginterfaces.BeforeExecHook, exec), ErrInjected)
+	query := &pginterfaces.ExecutionContext{Context: context.Background(), Operation: "query"}
+	assert.NoError(t, manager.ExecuteHooks(pginterfaces.BeforeQueryHook, query))
+}
+
+func TestConsumerMiddleware(t *testing.T) {
+	in := New()
+	var received []string
+	handler := consumer.Chain(func(ctx context.Context, msg *consumer.Message) error {
+		received = append(received, string(msg.Value))
+		return nil
+	}, ConsumerMiddleware(in, "consumer"))
+
+	msg := &consumer.Message{Topic: "orders", Value: []byte(`{"id":42}`)}
+	in.Add("consumer", Error(nil), WhenTag("topic", "orders"), Times(1))
+	assert.ErrorIs(t, handler(context.Background(), msg), ErrInjected)
+	require.NoError(t, handler(context.Background(), msg))
+
+	in.Add("consumer", PartialResponse(0.5))
+	require.NoError(t, handler(context.Background(), msg))
+	assert.Equal(t, []string{`{"id":42}`, `{"id`}, received)
+	assert.Equal(t, `{"id":42}`, string(msg.Value), "the original message is kept")
+}
+
+func TestRun(t *testing.T) {
+	in := New(WithSeed(3))
+	payments := func(ctx context.Context) error { return in.Inject(ctx, "payments") }
+	withRetry := func(ctx context.Context) error {
+		for range 3 {
+			if err := payments(ctx); err == nil {
+				return nil
+			}
+		}
+		return errors.New("payment failed")
+	}
+
+	Run(t, in,
+		Scenario{
+			Name:       "retries absorb transient errors",
+			Inject:     []Injection{{Point: "payments", Fault: Error(nil), Options: []RuleOption{Times(2)}}},
+			Iterations: 5,
+			Workload:   withRetry,
+		},
+		Scenario{
+			Name:       "outage",
+			Inject:     []Injection{{Point: "payments", Fault: Error(nil)}},
+			Iterations: 4,
+			Workload:   withRetry,
+			Expect: func(tb testing.TB, r Report) {
+				assert.Equal(tb, 4, r.Failures())
+				assert.Equal(tb, 12, r.Count("payments", KindError))
+			},
+		},
+		Scenario{
+			Name:     "panics are failures",
+			Inject:   []Injection{{Point: "payments", Fault: Panic("kaboom")}},
+			Workload: payments,
+			Expect: func(tb testing.TB, r Report) {
+				require.Equal(tb, 1, r.Failures())
+				assert.ErrorContains(tb, r.Errors[0], "kaboom")
+			},
+		},
+	)
+	assert.Empty(t, in.Counts(), "the injector is reset after the scenarios")
+}
@@ -0,0 +1,122 @@
+package chaos
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// Middleware injects the faults of the point in the requests of an HTTP
+// handler, tagged with method and path. Errors respond with the status of
+// the error, when it has an HTTPStatus() int method as the domain errors do,
+// or 503. Panics propagate to the recovery middleware. Partial responses
+// send the headers and the start of the body, then abort the connection, so
+// clients see an unexpected EOF.
+func Middleware(in *Injector, point string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := WithTags(r.Context(), "method", r.Method, "path", r.URL.Path)
+			o := in.decide(ctx, point)
+			if err := in.apply(ctx, o); err != nil {
+				http.Error(w, err.Error(), statusOf(err))
+				return
+			}
+			r = r.WithContext(ctx)
+			if !o.partial {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			buf := &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+			next.ServeHTTP(buf, r)
+			for k, v := range buf.header {
+				w.Header()[k] = v
+			}
+			body := buf.body.Bytes()
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+			w.WriteHeader(buf.status)
+			_, _ = w.Write(body[:cut(len(body), o.keep)])
+			if f, ok := w.(http.Flusher); ok {
+				f.Flush()
+			}
+			panic(http.ErrAbortHandler)
+		})
+	}
+}
+
+// statusOf returns the HTTP status of an injected error.
+func statusOf(err error) int {
+	var status interface{ HTTPStatus() int }
+	if errors.As(err, &status) && status.HTTPStatus() >= 400 {
+		return status.HTTPStatus()
+	}
+	return http.StatusServiceUnavailable
+}
+
+// bufferedResponse buffers a response to be cut.
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header         { return b.header }
+func (b *bufferedResponse) WriteHeader(status int)      { b.status = status }
+func (b *bufferedResponse) Write(p []byte) (int, error) { return b.body.Write(p) }
+
+// Transport injects the faults of the point in the requests of an HTTP
+// client, tagged with method and host, around base, or
+// http.DefaultTransport when nil. Errors are returned as transport errors
+// and partial responses end the body with io.ErrUnexpectedEOF.
+func Transport(in *Injector, point string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{in: in, point: point, base: base}
+}
+
+type transport struct {
+	in    *Injector
+	point string
+	base  http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := WithTags(req.Context(), "method", req.Method, "host", req.URL.Host)
+	o := t.in.decide(ctx, t.point)
+	if err := t.in.apply(ctx, o); err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || !o.partial {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = &partialBody{Reader: bytes.NewReader(body[:cut(len(body), o.keep)])}
+	return resp, nil
+}
+
+// partialBody returns io.ErrUnexpectedEOF after its bytes.
+type partialBody struct {
+	*bytes.Reader
+}
+
+func (b *partialBody) Read(p []byte) (int, error) {
+	n, err := b.Reader.Read(p)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func (b *partialBody) Close() error { return nil }
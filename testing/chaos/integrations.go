@@ -0,0 +1,60 @@
+package chaos
+
+import (
+	"context"
+
+	pginterfaces "github.com/fsvxavier/nexs-lib/db/postgres/interfaces"
+	"github.com/fsvxavier/nexs-lib/messaging/consumer"
+)
+
+// PostgresHook returns a db/postgres hook injecting the faults of the point,
+// tagged with the operation of the execution context. The hook manager runs
+// hooks with a timeout and recovers their panics as errors, so latencies are
+// bounded by the hook timeout and panics fail the operation.
+func PostgresHook(in *Injector, point string) pginterfaces.Hook {
+	return func(ec *pginterfaces.ExecutionContext) *pginterfaces.HookResult {
+		ctx := ec.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		ctx = WithTags(ctx, "operation", ec.Operation)
+		if err := in.Inject(ctx, point); err != nil {
+			return &pginterfaces.HookResult{Continue: false, Error: err}
+		}
+		return &pginterfaces.HookResult{Continue: true}
+	}
+}
+
+// RegisterPostgres registers PostgresHook before the queries and the
+// executions of the hook manager of a pool or connection.
+func RegisterPostgres(hooks pginterfaces.IHookManager, in *Injector, point string) error {
+	hook := PostgresHook(in, point)
+	for _, hookType := range []pginterfaces.HookType{pginterfaces.BeforeQueryHook, pginterfaces.BeforeExecHook} {
+		if err := hooks.RegisterHook(hookType, hook); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConsumerMiddleware injects the faults of the point in the messages of a
+// consumer, tagged with the topic. Errors fail the message, going through
+// the retries and dead letter of the stack; partial responses deliver a copy
+// of the message with its value cut, as a corrupted payload.
+func ConsumerMiddleware(in *Injector, point string) consumer.Middleware {
+	return func(next consumer.Handler) consumer.Handler {
+		return func(ctx context.Context, msg *consumer.Message) error {
+			ctx = WithTags(ctx, "topic", msg.Topic)
+			o := in.decide(ctx, point)
+			if err := in.apply(ctx, o); err != nil {
+				return err
+			}
+			if o.partial {
+				partial := *msg
+				partial.Value = msg.Value[:cut(len(msg.Value), o.keep)]
+				msg = &partial
+			}
+			return next(ctx, msg)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// Injection is a fault of a scenario, added as Injector.Add does.
+type Injection struct {
+	Point   string
+	Fault   Fault
+	Options []RuleOption
+}
+
+// Scenario runs a workload under a set of faults.
+type Scenario struct {
+	Name   string
+	Inject []Injection
+
+	// Iterations is the number of runs of the workload; the default is 1.
+	// Use several with faults of probability below 1.
+	Iterations int
+
+	// Workload exercises the system under test, e.g. requests to the
+	// service, returning the failures seen by its clients. Panics are
+	// recovered as failures.
+	Workload func(ctx context.Context) error
+
+	// Expect checks the report. Without it, any failure of the workload
+	// fails the scenario: the system must absorb the faults.
+	Expect func(tb testing.TB, report Report)
+}
+
+// Report is the result of a scenario.
+type Report struct {
+	Iterations int
+	// Errors are the failures of the workload, one per failed iteration.
+	Errors   []error
+	Duration time.Duration
+	// Injected is the number of faults injected by point and kind.
+	Injected map[string]map[Kind]int
+}
+
+// Failures returns the number of failed iterations.
+func (r Report) Failures() int {
+	return len(r.Errors)
+}
+
+// Count returns the number of faults of the kind injected at the point.
+func (r Report) Count(point string, kind Kind) int {
+	return r.Injected[point][kind]
+}
+
+// Run runs each scenario in a subtest: it resets the injector, adds the
+// faults of the scenario, runs the workload, resets the injector again and
+// checks the report. Scenarios share the injector, so they do not run in
+// parallel.
+func Run(t *testing.T, in *Injector, scenarios ...Scenario) {
+	t.Helper()
+	for _, sc := range scenarios {
+		t.Run(sc.Name, func(t *testing.T) {
+			report := RunScenario(t.Context(), in, sc)
+			if sc.Expect != nil {
+				sc.Expect(t, report)
+				return
+			}
+			for _, err := range report.Errors {
+				t.Errorf("workload failed: %v", err)
+			}
+		})
+	}
+}
+
+// RunScenario runs the scenario and returns its report, for runners other
+// than go test.
+func RunScenario(ctx context.Context, in *Injector, sc Scenario) Report {
+	in.Reset()
+	defer in.Reset()
+	for _, inj := range sc.Inject {
+		in.Add(inj.Point, inj.Fault, inj.Options...)
+	}
+
+	report := Report{Iterations: max(sc.Iterations, 1)}
+	start := in.clock.Now()
+	for range report.Iterations {
+		if err := runWorkload(ctx, sc.Workload); err != nil {
+			report.Errors = append(report.Errors, err)
+		}
+	}
+	report.Duration = in.clock.Since(start)
+	report.Injected = in.Counts()
+	return report
+}
+
+// runWorkload runs the workload, recovering its panics.
+func runWorkload(ctx context.Context, workload func(context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("chaos: workload panicked: %v", r)
+		}
+	}()
+	return workload(ctx)
+}
@@ -0,0 +1,150 @@
+package fixtures
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"testing"
+
+	"github.com/fsvxavier/nexs-lib/validation/validator"
+)
+
+// Modifier changes a value built by a Factory.
+type Modifier[T any] func(f *Faker, v *T)
+
+// Set adapts a function that does not need the Faker to a Modifier:
+//
+//	fixtures.People.MustBuild(t, f, fixtures.Set(func(p *fixtures.Person) {
+//		p.Name = "Ana Souza"
+//	}))
+func Set[T any](fn func(v *T)) Modifier[T] {
+	return func(_ *Faker, v *T) { fn(v) }
+}
+
+// Factory builds values of T: the defaults of its build function, changed by
+// named traits and by the modifiers of each call. Struct values are
+// validated with their validate tags after the modifiers, so a factory only
+// returns data the validator accepts; Raw skips the validation for tests
+// of invalid input. Factories are immutable: Trait and WithValidator return
+// new factories, so shared factories can be extended in each package.
+type Factory[T any] struct {
+	build     func(f *Faker) T
+	traits    map[string]Modifier[T]
+	validator *validator.Validator
+}
+
+// Define creates a Factory with the build function of the default value.
+func Define[T any](build func(f *Faker) T) *Factory[T] {
+	return &Factory[T]{build: build, traits: make(map[string]Modifier[T])}
+}
+
+// Trait returns a copy of the factory with the named trait, applied by With.
+func (fa *Factory[T]) Trait(name string, fn Modifier[T]) *Factory[T] {
+	cp := *fa
+	cp.traits = maps.Clone(fa.traits)
+	cp.traits[name] = fn
+	return &cp
+}
+
+// WithValidator returns a copy of the factory validating with v instead of
+// the default validator, e.g. one with custom rules.
+func (fa *Factory[T]) WithValidator(v *validator.Validator) *Factory[T] {
+	cp := *fa
+	cp.validator = v
+	return &cp
+}
+
+// With returns a Modifier applying the traits in order. Unknown traits
+// panic, as a mistake in the test.
+func (fa *Factory[T]) With(traits ...string) Modifier[T] {
+	mods := make([]Modifier[T], len(traits))
+	for i, name := range traits {
+		trait, ok := fa.traits[name]
+		if !ok {
+			panic(fmt.Sprintf("fixtures: unknown trait %q of %T", name, *new(T)))
+		}
+		mods[i] = trait
+	}
+	return func(f *Faker, v *T) {
+		for _, mod := range mods {
+			mod(f, v)
+		}
+	}
+}
+
+// Raw builds a value without validating it.
+func (fa *Factory[T]) Raw(f *Faker, mods ...Modifier[T]) T {
+	v := fa.build(f)
+	for _, mod := range mods {
+		mod(f, &v)
+	}
+	return v
+}
+
+// Build builds a value and validates it.
+func (fa *Factory[T]) Build(f *Faker, mods ...Modifier[T]) (T, error) {
+	v := fa.Raw(f, mods...)
+	if err := fa.validate(&v); err != nil {
+		var zero T
+		return zero, err
+	}
+	return v, nil
+}
+
+// BuildList builds n values, each validated.
+func (fa *Factory[T]) BuildList(f *Faker, n int, mods ...Modifier[T]) ([]T, error) {
+	list := make([]T, 0, n)
+	for range n {
+		v, err := fa.Build(f, mods...)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+	}
+	return list, nil
+}
+
+// MustBuild builds a value, failing the test when it is invalid.
+func (fa *Factory[T]) MustBuild(tb testing.TB, f *Faker, mods ...Modifier[T]) T {
+	tb.Helper()
+	v, err := fa.Build(f, mods...)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return v
+}
+
+// MustBuildList builds n values, failing the test when one is invalid.
+func (fa *Factory[T]) MustBuildList(tb testing.TB, f *Faker, n int, mods ...Modifier[T]) []T {
+	tb.Helper()
+	list, err := fa.BuildList(f, n, mods...)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return list
+}
+
+// validate validates struct values with their validate tags.
+func (fa *Factory[T]) validate(v *T) error {
+	t := reflect.TypeFor[T]()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	if rv := reflect.ValueOf(*v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return nil
+	}
+
+	var err error
+	if fa.validator != nil {
+		err = fa.validator.ValidateStruct(*v)
+	} else {
+		err = validator.ValidateStruct(*v)
+	}
+	if err != nil {
+		return fmt.Errorf("fixtures: built invalid %T: %w", *v, err)
+	}
+	return nil
+}
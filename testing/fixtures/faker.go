@@ -0,0 +1,213 @@
+// Package fixtures builds test data: a Faker generating deterministic fake
+// values (names, emails, phones, documents, addresses) seeded per test,
+// declarative factories for domain structs whose output is checked with the
+// validation/validator rules, and helpers seeding PostgreSQL tables with
+// the built values.
+//
+//	func TestSignup(t *testing.T) {
+//		f := fixtures.New(t)
+//		person := fixtures.People.MustBuild(t, f, fixtures.People.With("minor"))
+//		...
+//	}
+package fixtures
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/masking"
+)
+
+// Values of the addresses.
+var (
+	streets = []string{
+		"Rua das Flores", "Avenida Paulista", "Rua Augusta", "Avenida Brasil", "Rua XV de Novembro",
+		"Rua da Consolação", "Avenida Atlântica", "Rua Sete de Setembro", "Avenida Rio Branco", "Rua Bela Vista",
+	}
+	districts = []string{
+		"Centro", "Jardim América", "Vila Mariana", "Boa Vista", "Santa Cecília",
+		"Copacabana", "Savassi", "Batel", "Moinhos de Vento", "Pinheiros",
+	}
+	cities = []struct{ name, state string }{
+		{"São Paulo", "SP"}, {"Rio de Janeiro", "RJ"}, {"Belo Horizonte", "MG"}, {"Curitiba", "PR"},
+		{"Porto Alegre", "RS"}, {"Salvador", "BA"}, {"Recife", "PE"}, {"Fortaleza", "CE"},
+		{"Brasília", "DF"}, {"Florianópolis", "SC"},
+	}
+	companySuffixes = []string{"Ltda", "S.A.", "ME", "EIRELI"}
+	companyWords    = []string{"Comércio", "Tecnologia", "Serviços", "Logística", "Alimentos", "Consultoria"}
+)
+
+// Faker generates fake values from a seed: the same seed generates the same
+// values in the same order, so failures can be reproduced. Names, emails,
+// phones, card numbers and CPFs come from masking.Generator, in the same
+// formats as the masking pseudonyms. It is not safe for concurrent use;
+// create one per test or goroutine.
+type Faker struct {
+	seed uint64
+	r    *rand.Rand
+	gen  *masking.Generator
+}
+
+// NewFaker creates a Faker with the seed.
+func NewFaker(seed uint64) *Faker {
+	return &Faker{
+		seed: seed,
+		r:    rand.New(rand.NewPCG(seed, seed>>1|1)),
+		gen:  masking.NewGenerator(seed),
+	}
+}
+
+// New creates a Faker seeded with the name of the test, so every test gets
+// its own values, stable across runs and independent of the other tests.
+func New(tb testing.TB) *Faker {
+	h := fnv.New64a()
+	h.Write([]byte(tb.Name()))
+	return NewFaker(h.Sum64())
+}
+
+// Seed returns the seed of the Faker.
+func (f *Faker) Seed() uint64 {
+	return f.seed
+}
+
+// Int returns an int in [min, max].
+func (f *Faker) Int(min, max int) int {
+	if max <= min {
+		return min
+	}
+	return min + f.r.IntN(max-min+1)
+}
+
+// Bool returns a random bool.
+func (f *Faker) Bool() bool {
+	return f.r.IntN(2) == 1
+}
+
+// Time returns a time in [from, to), truncated to the second.
+func (f *Faker) Time(from, to time.Time) time.Time {
+	if !to.After(from) {
+		return from
+	}
+	return from.Add(time.Duration(f.r.Int64N(int64(to.Sub(from))))).Truncate(time.Second)
+}
+
+// Digits returns n random digits.
+func (f *Faker) Digits(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte('0' + f.r.IntN(10))
+	}
+	return string(b)
+}
+
+// UUID returns a version 4 UUID.
+func (f *Faker) UUID() string {
+	var b [16]byte
+	for i := range b {
+		b[i] = byte(f.r.IntN(256))
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// Name returns a full name, e.g. Ana Souza.
+func (f *Faker) Name() string {
+	return f.gen.Name()
+}
+
+// Email returns an email at example.com.
+func (f *Faker) Email() string {
+	return f.gen.Email()
+}
+
+// Phone returns a Brazilian mobile number in E.164, e.g. +5511987654321.
+func (f *Faker) Phone() string {
+	return "+" + strings.Map(func(r rune) rune {
+		if r < '0' || r > '9' {
+			return -1
+		}
+		return r
+	}, f.gen.Phone())
+}
+
+// CPF returns a formatted CPF with valid check digits.
+func (f *Faker) CPF() string {
+	return f.gen.CPF()
+}
+
+// CNPJ returns a formatted CNPJ of a head office, /0001, with valid check
+// digits.
+func (f *Faker) CNPJ() string {
+	d := make([]int, 0, 14)
+	for range 8 {
+		d = append(d, f.r.IntN(10))
+	}
+	d = append(d, 0, 0, 0, 1)
+	d = append(d, cnpjCheckDigit(d))
+	d = append(d, cnpjCheckDigit(d))
+	var b strings.Builder
+	for i, x := range d {
+		switch i {
+		case 2, 5:
+			b.WriteByte('.')
+		case 8:
+			b.WriteByte('/')
+		case 12:
+			b.WriteByte('-')
+		}
+		b.WriteByte(byte('0' + x))
+	}
+	return b.String()
+}
+
+// cnpjCheckDigit returns the next check digit of the CNPJ digits.
+func cnpjCheckDigit(d []int) int {
+	sum := 0
+	for i, x := range d {
+		weight := (len(d)-1-i)%8 + 2
+		sum += x * weight
+	}
+	if r := sum % 11; r >= 2 {
+		return 11 - r
+	}
+	return 0
+}
+
+// CreditCard returns a Luhn-valid 16 digit test card number.
+func (f *Faker) CreditCard() string {
+	return f.gen.PAN()
+}
+
+// CompanyName returns a company name, e.g. Souza Tecnologia Ltda.
+func (f *Faker) CompanyName() string {
+	last := strings.Fields(f.gen.Name())[1]
+	return fmt.Sprintf("%s %s %s", last, pick(f, companyWords), pick(f, companySuffixes))
+}
+
+// Address returns a Brazilian address.
+func (f *Faker) Address() Address {
+	city := pick(f, cities)
+	return Address{
+		Street:   pick(f, streets),
+		Number:   fmt.Sprint(f.Int(1, 2999)),
+		District: pick(f, districts),
+		City:     city.name,
+		State:    city.state,
+		ZipCode:  f.Digits(5) + "-" + f.Digits(3),
+	}
+}
+
+// Pick returns one of the items.
+func Pick[T any](f *Faker, items ...T) T {
+	return pick(f, items)
+}
+
+// pick returns one of the items.
+func pick[T any](f *Faker, items []T) T {
+	return items[f.r.IntN(len(items))]
+}
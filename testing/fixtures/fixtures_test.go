@@ -0,0 +1,161 @@
+package fixtures
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pginterfaces "github.com/fsvxavier/nexs-lib/db/postgres/interfaces"
+	"github.com/fsvxavier/nexs-lib/masking"
+	"github.com/fsvxavier/nexs-lib/validation/formats"
+	"github.com/fsvxavier/nexs-lib/validation/validator"
+)
+
+func TestFaker_Deterministic(t *testing.T) {
+	a, b := New(t), New(t)
+	assert.Equal(t, a.Seed(), b.Seed())
+	for range 10 {
+		assert.Equal(t, a.Name(), b.Name())
+		assert.Equal(t, a.UUID(), b.UUID())
+		assert.Equal(t, a.Address(), b.Address())
+	}
+
+	var other uint64
+	t.Run("other", func(t *testing.T) { other = New(t).Seed() })
+	assert.NotEqual(t, a.Seed(), other, "each test gets its own seed")
+}
+
+func TestFaker_Values(t *testing.T) {
+	f := NewFaker(7)
+	for range 50 {
+		assert.True(t, masking.ValidCPF(f.CPF()))
+		cnpj := f.CNPJ()
+		assert.True(t, formats.IsCNPJ(cnpj), cnpj)
+		assert.Regexp(t, `^\d{2}\.\d{3}\.\d{3}/0001-\d{2}$`, cnpj)
+		assert.True(t, masking.ValidPAN(f.CreditCard()))
+		assert.Regexp(t, `^\+55119\d{8}$`, f.Phone())
+		assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, f.UUID())
+		n := f.Int(3, 5)
+		assert.True(t, n >= 3 && n <= 5, n)
+	}
+	assert.Equal(t, 3, f.Int(3, 3))
+	assert.Len(t, f.Digits(6), 6)
+	assert.Contains(t, []string{"a", "b"}, Pick(f, "a", "b"))
+	assert.Len(t, strings.Fields(f.CompanyName()), 3)
+}
+
+func TestFactory(t *testing.T) {
+	f := New(t)
+
+	person := People.MustBuild(t, f)
+	assert.NoError(t, validator.ValidateStruct(person))
+	assert.True(t, person.BirthDate.Before(Epoch.AddDate(-18, 0, 0)))
+
+	minor := People.MustBuild(t, f, People.With("minor", "without_phone"))
+	assert.True(t, minor.BirthDate.After(Epoch.AddDate(-18, 0, 0)))
+	assert.Empty(t, minor.Phone)
+
+	named := People.MustBuild(t, f, Set(func(p *Person) { p.Name = "Ana Souza" }))
+	assert.Equal(t, "Ana Souza", named.Name)
+
+	companies := Companies.MustBuildList(t, f, 5)
+	assert.Len(t, companies, 5)
+	assert.NotEqual(t, companies[0].CNPJ, companies[1].CNPJ)
+	assert.NoError(t, validator.ValidateStruct(Addresses.MustBuild(t, f)))
+
+	assert.Panics(t, func() { People.With("unknown") })
+}
+
+func TestFactory_Validation(t *testing.T) {
+	f := New(t)
+	invalid := Set(func(p *Person) { p.CPF = "111.111.111-11" })
+
+	_, err := People.Build(f, invalid)
+	var errs validator.ValidationErrors
+	require.ErrorAs(t, err, &errs)
+	assert.Contains(t, errs.Fields(), "cpf")
+
+	raw := People.Raw(f, invalid)
+	assert.Equal(t, "111.111.111-11", raw.CPF, "Raw skips the validation")
+
+	_, err = People.BuildList(f, 3, invalid)
+	assert.Error(t, err)
+
+	// traits are added to copies
+	vip := People.Trait("vip", func(_ *Faker, p *Person) { p.Name = "VIP " + p.Name })
+	assert.NotPanics(t, func() { vip.With("vip") })
+	assert.Panics(t, func() { People.With("vip") })
+
+	// non-struct values are not validated
+	ints := Define(func(f *Faker) int { return f.Int(1, 9) })
+	n, err := ints.Build(f)
+	require.NoError(t, err)
+	assert.True(t, n >= 1 && n <= 9)
+}
+
+// fakeTag is the command tag of fakeConn.
+type fakeTag struct{ pginterfaces.ICommandTag }
+
+// fakeConn records the statements.
+type fakeConn struct {
+	queries []string
+	args    [][]interface{}
+	err     error
+}
+
+func (c *fakeConn) Exec(ctx context.Context, query string, args ...interface{}) (pginterfaces.ICommandTag, error) {
+	c.queries = append(c.queries, query)
+	c.args = append(c.args, args)
+	return fakeTag{}, c.err
+}
+
+func TestInsert(t *testing.T) {
+	f := New(t)
+	conn := &fakeConn{}
+	people := People.MustBuildList(t, f, 2)
+
+	Seed(t, conn, "crm.people", people[0], &people[1])
+	Seed(t, conn, "people", Rows(people)...)
+	require.Len(t, conn.queries, 2)
+	assert.Equal(t, conn.args[0], conn.args[1])
+	assert.Equal(t, `INSERT INTO "crm"."people" ("id", "name", "email", "phone", "cpf", "birth_date") VALUES `+
+		`($1, $2, $3, $4, $5, $6), ($7, $8, $9, $10, $11, $12)`, conn.queries[0])
+	assert.Equal(t, []interface{}{people[0].ID, people[0].Name, people[0].Email, people[0].Phone, people[0].CPF, people[0].BirthDate}, conn.args[0][:6])
+
+	type wide struct {
+		A, B, C, D, E, F, G, H, I, J, K, L, M, N, O, P int
+		CreatedAt                                      string `db:"-"`
+		internal                                       int
+	}
+	rows := make([]any, 5000)
+	for i := range rows {
+		rows[i] = wide{A: i}
+	}
+	conn = &fakeConn{}
+	require.NoError(t, Insert(context.Background(), conn, "wide", rows...))
+	require.Len(t, conn.queries, 2, "65535 parameters per statement")
+	assert.Len(t, conn.args[0], 4095*16)
+	assert.Equal(t, 4999, conn.args[1][len(conn.args[1])-16])
+	assert.NotContains(t, conn.queries[0], "created_at")
+
+	assert.Error(t, Insert(context.Background(), conn, "people", people[0], Companies.MustBuild(t, f)))
+	assert.Error(t, Insert(context.Background(), conn, "people", 42))
+	assert.NoError(t, Insert(context.Background(), conn, "people"))
+
+	failing := &fakeConn{err: errors.New("relation does not exist")}
+	assert.ErrorContains(t, Insert(context.Background(), failing, "people", people[0]), "relation does not exist")
+}
+
+func TestTruncate(t *testing.T) {
+	conn := &fakeConn{}
+	t.Run("test", func(t *testing.T) {
+		TruncateOnCleanup(t, conn, "people", "crm.companies")
+		assert.Empty(t, conn.queries)
+	})
+	require.Len(t, conn.queries, 1)
+	assert.Equal(t, `TRUNCATE "people", "crm"."companies" RESTART IDENTITY CASCADE`, conn.queries[0])
+}
@@ -0,0 +1,71 @@
+package fixtures
+
+import "time"
+
+// Epoch is the reference instant of the generated dates, so that they do not
+// depend on the clock: birth dates are counted back from it.
+var Epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Address is a Brazilian postal address.
+type Address struct {
+	Street   string `json:"street" db:"street" validate:"required"`
+	Number   string `json:"number" db:"number" validate:"required"`
+	District string `json:"district" db:"district" validate:"required"`
+	City     string `json:"city" db:"city" validate:"required"`
+	State    string `json:"state" db:"state" validate:"required,min_length=2,max_length=2"`
+	ZipCode  string `json:"zip_code" db:"zip_code" validate:"required,pattern=^[0-9]{5}-[0-9]{3}$"`
+}
+
+// Person is an individual customer.
+type Person struct {
+	ID        string    `json:"id" db:"id" validate:"required,uuid4"`
+	Name      string    `json:"name" db:"name" validate:"required,min_length=3"`
+	Email     string    `json:"email" db:"email" validate:"required,email"`
+	Phone     string    `json:"phone" db:"phone" validate:"e164"`
+	CPF       string    `json:"cpf" db:"cpf" validate:"required,cpf"`
+	BirthDate time.Time `json:"birth_date" db:"birth_date"`
+	Address   Address   `json:"address" db:"-"`
+}
+
+// Company is a business customer.
+type Company struct {
+	ID      string  `json:"id" db:"id" validate:"required,uuid4"`
+	Name    string  `json:"name" db:"name" validate:"required,min_length=3"`
+	CNPJ    string  `json:"cnpj" db:"cnpj" validate:"required,cnpj"`
+	Email   string  `json:"email" db:"email" validate:"required,email"`
+	Address Address `json:"address" db:"-"`
+}
+
+// Addresses builds valid addresses.
+var Addresses = Define(func(f *Faker) Address {
+	return f.Address()
+})
+
+// People builds valid adults, born 18 to 80 years before Epoch. Traits:
+// "minor", born 1 to 17 years before Epoch; "without_phone".
+var People = Define(func(f *Faker) Person {
+	return Person{
+		ID:        f.UUID(),
+		Name:      f.Name(),
+		Email:     f.Email(),
+		Phone:     f.Phone(),
+		CPF:       f.CPF(),
+		BirthDate: f.Time(Epoch.AddDate(-80, 0, 0), Epoch.AddDate(-18, 0, 0)),
+		Address:   f.Address(),
+	}
+}).Trait("minor", func(f *Faker, p *Person) {
+	p.BirthDate = f.Time(Epoch.AddDate(-17, 0, 0), Epoch.AddDate(-1, 0, 0))
+}).Trait("without_phone", func(_ *Faker, p *Person) {
+	p.Phone = ""
+})
+
+// Companies builds valid head offices.
+var Companies = Define(func(f *Faker) Company {
+	return Company{
+		ID:      f.UUID(),
+		Name:    f.CompanyName(),
+		CNPJ:    f.CNPJ(),
+		Email:   f.Email(),
+		Address: f.Address(),
+	}
+})
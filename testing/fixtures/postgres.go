@@ -0,0 +1,178 @@
+package fixtures
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	pginterfaces "github.com/fsvxavier/nexs-lib/db/postgres/interfaces"
+)
+
+// maxParams is the PostgreSQL limit of parameters of a statement.
+const maxParams = 65535
+
+// PostgresConn is the part of the db/postgres connection used to seed
+// tables; interfaces.IConn and transactions satisfy it.
+type PostgresConn interface {
+	Exec(ctx context.Context, query string, args ...interface{}) (pginterfaces.ICommandTag, error)
+}
+
+// Insert inserts the rows, structs or pointers to structs of one type, in
+// the table. Columns are mapped as the db/postgres scanner does: the db tag,
+// or the field name in snake_case; fields tagged db:"-" are skipped, so tag
+// that way the columns filled by the database and nested structs. Rows are
+// sent in as few statements as the parameter limit allows.
+func Insert(ctx context.Context, conn PostgresConn, table string, rows ...any) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	t := reflect.TypeOf(rows[0])
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return fmt.Errorf("fixtures: rows must be structs, got %T", rows[0])
+	}
+	columns, fields := columnsOf(t)
+	if len(columns) == 0 {
+		return fmt.Errorf("fixtures: %s has no columns", t)
+	}
+
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = quoteIdentifier(column)
+	}
+	prefix := fmt.Sprintf("INSERT INTO %s (%s) VALUES ", quoteTable(table), strings.Join(quoted, ", "))
+
+	batch := maxParams / len(columns)
+	for start := 0; start < len(rows); start += batch {
+		end := min(start+batch, len(rows))
+		var query strings.Builder
+		query.WriteString(prefix)
+		args := make([]interface{}, 0, (end-start)*len(columns))
+		for i, row := range rows[start:end] {
+			v := reflect.ValueOf(row)
+			for v.Kind() == reflect.Pointer && !v.IsNil() {
+				v = v.Elem()
+			}
+			if v.Type() != t {
+				return fmt.Errorf("fixtures: row %d is %T, not %s", start+i, row, t)
+			}
+			if i > 0 {
+				query.WriteString(", ")
+			}
+			query.WriteByte('(')
+			for j, index := range fields {
+				if j > 0 {
+					query.WriteString(", ")
+				}
+				args = append(args, v.Field(index).Interface())
+				fmt.Fprintf(&query, "$%d", len(args))
+			}
+			query.WriteByte(')')
+		}
+		if _, err := conn.Exec(ctx, query.String(), args...); err != nil {
+			return fmt.Errorf("fixtures: failed to insert into %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// Rows converts a list of rows to the arguments of Insert and Seed:
+//
+//	fixtures.Seed(t, conn, "people", fixtures.Rows(people)...)
+func Rows[T any](list []T) []any {
+	rows := make([]any, len(list))
+	for i, row := range list {
+		rows[i] = row
+	}
+	return rows
+}
+
+// Truncate empties the tables, restarting their sequences and cascading to
+// the tables that reference them.
+func Truncate(ctx context.Context, conn PostgresConn, tables ...string) error {
+	if len(tables) == 0 {
+		return nil
+	}
+	quoted := make([]string, len(tables))
+	for i, table := range tables {
+		quoted[i] = quoteTable(table)
+	}
+	if _, err := conn.Exec(ctx, fmt.Sprintf("TRUNCATE %s RESTART IDENTITY CASCADE", strings.Join(quoted, ", "))); err != nil {
+		return fmt.Errorf("fixtures: failed to truncate %s: %w", strings.Join(tables, ", "), err)
+	}
+	return nil
+}
+
+// Seed inserts the rows in the table, failing the test on error.
+func Seed(tb testing.TB, conn PostgresConn, table string, rows ...any) {
+	tb.Helper()
+	if err := Insert(tb.Context(), conn, table, rows...); err != nil {
+		tb.Fatal(err)
+	}
+}
+
+// TruncateOnCleanup truncates the tables when the test ends, so the next
+// test starts from empty tables. Use it only on test databases.
+func TruncateOnCleanup(tb testing.TB, conn PostgresConn, tables ...string) {
+	tb.Helper()
+	tb.Cleanup(func() {
+		// the test context is canceled before the cleanups run
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := Truncate(ctx, conn, tables...); err != nil {
+			tb.Error(err)
+		}
+	})
+}
+
+// columnsOf returns the columns of the struct type and the indexes of their
+// fields.
+func columnsOf(t reflect.Type) (columns []string, fields []int) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		column := f.Tag.Get("db")
+		switch column {
+		case "-":
+			continue
+		case "":
+			column = toSnakeCase(f.Name)
+		}
+		columns = append(columns, column)
+		fields = append(fields, i)
+	}
+	return columns, fields
+}
+
+// toSnakeCase converts CamelCase to snake_case, as the db/postgres scanner.
+func toSnakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			b.WriteByte('_')
+		}
+		b.WriteRune(r)
+	}
+	return strings.ToLower(b.String())
+}
+
+// quoteTable quotes a table name, schema qualified or not.
+func quoteTable(table string) string {
+	parts := strings.Split(table, ".")
+	for i, part := range parts {
+		parts[i] = quoteIdentifier(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// quoteIdentifier quotes a PostgreSQL identifier.
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
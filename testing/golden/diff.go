@@ -0,0 +1,150 @@
+package golden
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// maxDifferences limits the differences reported by a failure.
+const maxDifferences = 20
+
+// maxDiffCells limits the size of the line diff table; larger outputs only
+// report their first different line.
+const maxDiffCells = 4_000_000
+
+// textDiff returns the lines that differ between want and got, prefixed by
+// "-" for the golden file and "+" for the output, with their line numbers.
+func textDiff(want, got string) string {
+	a, b := strings.Split(want, "\n"), strings.Split(got, "\n")
+	if len(a)*len(b) > maxDiffCells {
+		for i := 0; i < len(a) && i < len(b); i++ {
+			if a[i] != b[i] {
+				return fmt.Sprintf("-%d: %s\n+%d: %s\n", i+1, a[i], i+1, b[i])
+			}
+		}
+		return fmt.Sprintf("outputs differ in length: %d lines, want %d\n", len(b), len(a))
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:], b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out strings.Builder
+	reported := 0
+	emit := func(format string, args ...any) {
+		if reported < maxDifferences {
+			fmt.Fprintf(&out, format, args...)
+		}
+		reported++
+	}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i, j = i+1, j+1
+		case j < len(b) && (i == len(a) || lcs[i][j+1] > lcs[i+1][j]):
+			emit("+%d: %s\n", j+1, b[j])
+			j++
+		default:
+			emit("-%d: %s\n", i+1, a[i])
+			i++
+		}
+	}
+	if reported > maxDifferences {
+		fmt.Fprintf(&out, "... and %d more lines\n", reported-maxDifferences)
+	}
+	return out.String()
+}
+
+// jsonDiff returns the differences between two decoded JSON documents, one
+// per line, by JSON path, e.g. $.error.code: "A" != "B" (golden != got).
+func jsonDiff(want, got any) string {
+	var diffs []string
+	walkJSON("$", want, got, &diffs)
+	if len(diffs) > maxDifferences {
+		diffs = append(diffs[:maxDifferences], fmt.Sprintf("... and %d more differences", len(diffs)-maxDifferences))
+	}
+	if len(diffs) == 0 {
+		return ""
+	}
+	return strings.Join(diffs, "\n") + "\n"
+}
+
+// walkJSON appends the differences under path to diffs.
+func walkJSON(path string, want, got any, diffs *[]string) {
+	switch w := want.(type) {
+	case map[string]any:
+		g, ok := got.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(w)+len(g))
+		for k := range w {
+			keys = append(keys, k)
+		}
+		for k := range g {
+			if _, ok := w[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			wv, inWant := w[k]
+			gv, inGot := g[k]
+			child := path + "." + k
+			switch {
+			case !inGot:
+				*diffs = append(*diffs, fmt.Sprintf("%s: missing, want %s", child, compact(wv)))
+			case !inWant:
+				*diffs = append(*diffs, fmt.Sprintf("%s: unexpected %s", child, compact(gv)))
+			default:
+				walkJSON(child, wv, gv, diffs)
+			}
+		}
+		return
+
+	case []any:
+		g, ok := got.([]any)
+		if !ok {
+			break
+		}
+		for i := 0; i < len(w) || i < len(g); i++ {
+			child := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(g):
+				*diffs = append(*diffs, fmt.Sprintf("%s: missing, want %s", child, compact(w[i])))
+			case i >= len(w):
+				*diffs = append(*diffs, fmt.Sprintf("%s: unexpected %s", child, compact(g[i])))
+			default:
+				walkJSON(child, w[i], g[i], diffs)
+			}
+		}
+		return
+	}
+	if !reflect.DeepEqual(want, got) {
+		*diffs = append(*diffs, fmt.Sprintf("%s: got %s, want %s", path, compact(got), compact(want)))
+	}
+}
+
+// compact returns the compact JSON of v.
+func compact(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -0,0 +1,248 @@
+// Package golden compares test outputs with golden files kept under
+// testdata/golden, for outputs too large to assert field by field such as
+// error serializations and API responses. Outputs are scrubbed of the values
+// that change between runs (timestamps, UUIDs, custom patterns) and JSON
+// outputs are compared by structure, failures listing the different paths.
+// Run the tests with -update, or GOLDEN_UPDATE=1, to create or update the
+// golden files, and review them in the diff of the change.
+//
+//	func TestRender(t *testing.T) {
+//		resp := profile.Render(err)
+//		golden.Assert(t, "public/not_found", resp)
+//	}
+package golden
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// DefaultDir is the directory of the golden files, relative to the package
+// of the test.
+const DefaultDir = "testdata/golden"
+
+// UpdateEnv is the environment variable that, set to true, updates the
+// golden files as the -update flag does.
+const UpdateEnv = "GOLDEN_UPDATE"
+
+func init() {
+	// the flag may already be defined by another helper of the test binary
+	if flag.Lookup("update") == nil {
+		flag.Bool("update", false, "update the golden files")
+	}
+}
+
+// Updating reports whether the golden files are being updated, with the
+// -update flag or UpdateEnv.
+func Updating() bool {
+	if update, _ := strconv.ParseBool(os.Getenv(UpdateEnv)); update {
+		return true
+	}
+	f := flag.Lookup("update")
+	if f == nil {
+		return false
+	}
+	update, _ := strconv.ParseBool(f.Value.String())
+	return update
+}
+
+// config is the configuration of an assertion.
+type config struct {
+	dir       string
+	scrubbers []Scrubber
+	defaults  bool
+	json      bool
+}
+
+// Option configures an assertion.
+type Option func(*config)
+
+// WithDir keeps the golden files in dir instead of DefaultDir.
+func WithDir(dir string) Option {
+	return func(c *config) {
+		c.dir = dir
+	}
+}
+
+// WithScrubbers applies the scrubbers after the default ones.
+func WithScrubbers(scrubbers ...Scrubber) Option {
+	return func(c *config) {
+		c.scrubbers = append(c.scrubbers, scrubbers...)
+	}
+}
+
+// WithoutDefaultScrubbers does not apply DefaultScrubbers, for outputs whose
+// timestamps and UUIDs are deterministic and must be compared.
+func WithoutDefaultScrubbers() Option {
+	return func(c *config) {
+		c.defaults = false
+	}
+}
+
+// JSON compares string and []byte outputs as JSON documents.
+func JSON() Option {
+	return func(c *config) {
+		c.json = true
+	}
+}
+
+// Assert compares got with the golden file of name, failing the test with
+// the differences when they do not match. Strings and []byte are compared as
+// text, in name.golden, unless JSON is given; json.RawMessage and any other
+// value are compared as indented JSON, in name.json. Names may contain
+// slashes, which become directories.
+func Assert(tb testing.TB, name string, got any, opts ...Option) {
+	tb.Helper()
+	c := config{dir: DefaultDir, defaults: true}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	var text string
+	isJSON := c.json
+	switch v := got.(type) {
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	case json.RawMessage:
+		text, isJSON = string(v), true
+	default:
+		b, err := json.Marshal(got)
+		if err != nil {
+			tb.Fatalf("golden: failed to encode %T: %v", got, err)
+			return
+		}
+		text, isJSON = string(b), true
+	}
+	if isJSON {
+		indented, err := indentJSON([]byte(text))
+		if err != nil {
+			tb.Fatalf("golden: output of %s is not JSON: %v", name, err)
+			return
+		}
+		text = indented
+	}
+	if c.defaults {
+		c.scrubbers = append(append([]Scrubber{}, DefaultScrubbers...), c.scrubbers...)
+	}
+	for _, scrub := range c.scrubbers {
+		text = scrub(text)
+	}
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+
+	path := filepath.Join(c.dir, filepath.FromSlash(name))
+	if filepath.Ext(name) == "" {
+		if isJSON {
+			path += ".json"
+		} else {
+			path += ".golden"
+		}
+	}
+
+	if Updating() {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			tb.Fatalf("golden: %v", err)
+			return
+		}
+		if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+			tb.Fatalf("golden: %v", err)
+		}
+		return
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		tb.Errorf("golden: %s does not exist; run the test with -update to create it", path)
+		return
+	}
+	if err != nil {
+		tb.Fatalf("golden: %v", err)
+		return
+	}
+	want := strings.ReplaceAll(string(content), "\r\n", "\n")
+	if want == text {
+		return
+	}
+
+	diff := ""
+	if isJSON {
+		var wantDoc, gotDoc any
+		if decodeJSON([]byte(want), &wantDoc) == nil && decodeJSON([]byte(text), &gotDoc) == nil {
+			diff = jsonDiff(wantDoc, gotDoc)
+		}
+	}
+	if diff == "" {
+		diff = textDiff(want, text)
+	}
+	tb.Errorf("golden: output differs from %s (run the test with -update to accept it):\n%s", path, diff)
+}
+
+// AssertResponse compares the status, content type and body of the response
+// with the golden file of name, as JSON; JSON bodies are compared by
+// structure. The body is read and closed.
+func AssertResponse(tb testing.TB, name string, resp *http.Response, opts ...Option) {
+	tb.Helper()
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		tb.Fatalf("golden: failed to read the response body: %v", err)
+		return
+	}
+
+	snapshot := struct {
+		Status      int    `json:"status"`
+		ContentType string `json:"content_type,omitempty"`
+		Body        any    `json:"body,omitempty"`
+	}{Status: resp.StatusCode, ContentType: resp.Header.Get("Content-Type")}
+	mediaType, _, _ := mime.ParseMediaType(snapshot.ContentType)
+	if (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")) && json.Valid(body) {
+		snapshot.Body = json.RawMessage(body)
+	} else if len(body) > 0 {
+		snapshot.Body = string(body)
+	}
+	Assert(tb, name, snapshot, opts...)
+}
+
+// indentJSON returns the document indented with two spaces, object keys
+// sorted, numbers kept as written and HTML characters not escaped.
+func indentJSON(data []byte) (string, error) {
+	var doc any
+	if err := decodeJSON(data, &doc); err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// decodeJSON decodes a single JSON document keeping the numbers as written.
+func decodeJSON(data []byte, v *any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return errors.New("unexpected data after the JSON document")
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package golden
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder records the failures of the assertions under test.
+type recorder struct {
+	testing.TB
+	errors []string
+	fatal  bool
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+	r.fatal = true
+}
+
+// update runs fn updating the golden files.
+func update(t *testing.T, fn func()) {
+	t.Setenv(UpdateEnv, "true")
+	fn()
+	require.NoError(t, os.Unsetenv(UpdateEnv))
+}
+
+type response struct {
+	Code      string            `json:"code"`
+	Message   string            `json:"message"`
+	RequestID string            `json:"request_id"`
+	Time      time.Time         `json:"time"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+}
+
+func TestAssert_JSON(t *testing.T) {
+	dir := t.TempDir()
+	got := response{
+		Code:      "NOT_FOUND",
+		Message:   "order <42> not found",
+		RequestID: "7c9e6679-7425-40de-944b-e07fc1f90ae7",
+		Time:      time.Date(2024, 5, 1, 12, 30, 0, 123, time.UTC),
+		Metadata:  map[string]string{"order": "42", "b": "x"},
+	}
+
+	r := &recorder{TB: t}
+	Assert(r, "errors/not_found", got, WithDir(dir))
+	require.Len(t, r.errors, 1)
+	assert.Contains(t, r.errors[0], "does not exist; run the test with -update")
+
+	update(t, func() { Assert(t, "errors/not_found", got, WithDir(dir)) })
+	content, err := os.ReadFile(filepath.Join(dir, "errors", "not_found.json"))
+	require.NoError(t, err)
+	assert.Equal(t, `{
+  "code": "NOT_FOUND",
+  "message": "order <42> not found",
+  "metadata": {
+    "b": "x",
+    "order": "42"
+  },
+  "request_id": "<UUID>",
+  "time": "<TIMESTAMP>"
+}
+`, string(content))
+
+	// new timestamps and ids match the scrubbed golden file
+	got.RequestID, got.Time = "0b5d2a4e-8c1f-4b7a-9d3e-2f6a1c8e5b90", time.Now()
+	Assert(t, "errors/not_found", got, WithDir(dir))
+
+	got.Code, got.Metadata = "GONE", map[string]string{"b": "x", "c": "y"}
+	r = &recorder{TB: t}
+	Assert(r, "errors/not_found", got, WithDir(dir))
+	require.Len(t, r.errors, 1)
+	assert.Contains(t, r.errors[0], `$.code: got "GONE", want "NOT_FOUND"`)
+	assert.Contains(t, r.errors[0], `$.metadata.c: unexpected "y"`)
+	assert.Contains(t, r.errors[0], `$.metadata.order: missing, want "42"`)
+	assert.False(t, r.fatal)
+}
+
+func TestAssert_Text(t *testing.T) {
+	dir := t.TempDir()
+	want := "line 1\nline 2\nline 3"
+	update(t, func() { Assert(t, "report", want, WithDir(dir)) })
+	Assert(t, "report", []byte(want+"\n"), WithDir(dir))
+
+	r := &recorder{TB: t}
+	Assert(r, "report", "line 1\nline two\nline 3", WithDir(dir))
+	require.Len(t, r.errors, 1)
+	assert.Contains(t, r.errors[0], "-2: line 2\n+2: line two\n")
+
+	// JSON strings are compared by structure
+	update(t, func() { Assert(t, "doc", `{"a":1,"b":[1,2]}`, WithDir(dir), JSON()) })
+	Assert(t, "doc", `{ "b": [1, 2], "a": 1 }`, WithDir(dir), JSON())
+	r = &recorder{TB: t}
+	Assert(r, "doc", `{"a":1,"b":[1]}`, WithDir(dir), JSON())
+	require.Len(t, r.errors, 1)
+	assert.Contains(t, r.errors[0], "$.b[1]: missing, want 2")
+
+	r = &recorder{TB: t}
+	Assert(r, "doc", `{"a":`, WithDir(dir), JSON())
+	assert.True(t, r.fatal)
+}
+
+func TestScrubbers(t *testing.T) {
+	dir := t.TempDir()
+	scrub := ScrubRegexp(`tok_[a-z0-9]+`, "<TOKEN>")
+	update(t, func() {
+		Assert(t, "token", "token tok_abc at 2024-01-01T00:00:00Z", WithDir(dir), WithScrubbers(scrub))
+	})
+	Assert(t, "token", "token tok_xyz at 2025-06-30 10:11:12.5-03:00", WithDir(dir), WithScrubbers(scrub))
+
+	update(t, func() { Assert(t, "raw", "at 2024-01-01T00:00:00Z", WithDir(dir), WithoutDefaultScrubbers()) })
+	r := &recorder{TB: t}
+	Assert(r, "raw", "at 2024-01-02T00:00:00Z", WithDir(dir), WithoutDefaultScrubbers())
+	assert.Len(t, r.errors, 1)
+
+	assert.Equal(t, "id <UUID>", ScrubUUIDs("id 7C9E6679-7425-40DE-944B-E07FC1F90AE7"))
+	assert.Equal(t, "at <TIMESTAMP>.", ScrubTimestamps("at 2024-01-01T10:00:00.123456+0000."))
+}
+
+func TestAssertResponse(t *testing.T) {
+	dir := t.TempDir()
+	handler := func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"title":"Not Found","status":404}`))
+	}
+	serve := func() *http.Response {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, "/orders/42", nil))
+		return rec.Result()
+	}
+
+	update(t, func() { AssertResponse(t, "orders/not_found", serve(), WithDir(dir)) })
+	content, err := os.ReadFile(filepath.Join(dir, "orders", "not_found.json"))
+	require.NoError(t, err)
+	assert.Equal(t, `{
+  "body": {
+    "status": 404,
+    "title": "Not Found"
+  },
+  "content_type": "application/problem+json",
+  "status": 404
+}
+`, string(content))
+	AssertResponse(t, "orders/not_found", serve(), WithDir(dir))
+}
+
+func TestTextDiff(t *testing.T) {
+	assert.Equal(t, "+2: b\n", textDiff("a\nc", "a\nb\nc"))
+	assert.Equal(t, "-1: a\n", textDiff("a\nb", "b"))
+	assert.Empty(t, textDiff("a", "a"))
+}
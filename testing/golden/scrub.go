@@ -0,0 +1,45 @@
+package golden
+
+import "regexp"
+
+// Scrubber replaces the parts of the output that change between runs, such
+// as timestamps and generated ids, by stable placeholders before the output
+// is compared or written.
+type Scrubber func(s string) string
+
+// Placeholders of the default scrubbers.
+const (
+	TimestampPlaceholder = "<TIMESTAMP>"
+	UUIDPlaceholder      = "<UUID>"
+)
+
+var (
+	timestampPattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:Z|[+-]\d{2}:?\d{2})?`)
+	uuidPattern      = regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`)
+)
+
+// ScrubTimestamps replaces RFC 3339 timestamps, with or without fraction
+// and zone, by TimestampPlaceholder.
+func ScrubTimestamps(s string) string {
+	return timestampPattern.ReplaceAllString(s, TimestampPlaceholder)
+}
+
+// ScrubUUIDs replaces UUIDs by UUIDPlaceholder.
+func ScrubUUIDs(s string) string {
+	return uuidPattern.ReplaceAllString(s, UUIDPlaceholder)
+}
+
+// ScrubRegexp returns a Scrubber replacing the matches of pattern by
+// replacement, which may reference groups as regexp.ReplaceAllString does:
+//
+//	golden.ScrubRegexp(`"request_id": "[^"]*"`, `"request_id": "<ID>"`)
+func ScrubRegexp(pattern, replacement string) Scrubber {
+	re := regexp.MustCompile(pattern)
+	return func(s string) string {
+		return re.ReplaceAllString(s, replacement)
+	}
+}
+
+// DefaultScrubbers are the scrubbers applied unless WithoutDefaultScrubbers
+// is given.
+var DefaultScrubbers = []Scrubber{ScrubTimestamps, ScrubUUIDs}
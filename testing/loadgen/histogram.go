@@ -0,0 +1,167 @@
+package loadgen
+
+import (
+	"math"
+	"math/bits"
+	"sync"
+	"time"
+)
+
+// subBits is the number of bits of the sub-buckets of each power of two:
+// values are recorded with a relative error below 1/2^subBits (0.8%), as an
+// HDR histogram with two significant digits.
+const (
+	subBits  = 7
+	subCount = 1 << subBits
+	buckets  = (64 - subBits) * subCount
+)
+
+// Histogram is a log-linear histogram of latencies, in the style of HDR
+// histograms: values below 256ns are exact and greater ones are kept with a
+// relative error below 1%, in constant memory whatever the number and the
+// range of the values. It is safe for concurrent use.
+type Histogram struct {
+	mu     sync.Mutex
+	counts []int64
+	count  int64
+	sum    float64
+	min    time.Duration
+	max    time.Duration
+}
+
+// NewHistogram creates an empty Histogram.
+func NewHistogram() *Histogram {
+	return &Histogram{counts: make([]int64, buckets)}
+}
+
+// bucketOf returns the bucket of the value v, in nanoseconds.
+func bucketOf(v uint64) int {
+	if v < 2*subCount {
+		return int(v)
+	}
+	shift := bits.Len64(v) - subBits - 1
+	return shift*subCount + int(v>>shift)
+}
+
+// bucketRange returns the lowest and the highest value of the bucket.
+func bucketRange(i int) (lo, hi uint64) {
+	if i < 2*subCount {
+		return uint64(i), uint64(i)
+	}
+	shift := i/subCount - 1
+	lo = uint64(i-shift*subCount) << shift
+	return lo, lo + 1<<shift - 1
+}
+
+// Record adds a latency; negative ones are recorded as 0.
+func (h *Histogram) Record(d time.Duration) {
+	d = max(d, 0)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.counts[bucketOf(uint64(d))]++
+	if h.count == 0 || d < h.min {
+		h.min = d
+	}
+	h.max = max(h.max, d)
+	h.count++
+	h.sum += float64(d)
+}
+
+// Merge adds the latencies of other.
+func (h *Histogram) Merge(other *Histogram) {
+	if other == h {
+		return
+	}
+	other.mu.Lock()
+	counts := append([]int64(nil), other.counts...)
+	count, sum, lo, hi := other.count, other.sum, other.min, other.max
+	other.mu.Unlock()
+	if count == 0 {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, c := range counts {
+		h.counts[i] += c
+	}
+	if h.count == 0 || lo < h.min {
+		h.min = lo
+	}
+	h.max = max(h.max, hi)
+	h.count += count
+	h.sum += sum
+}
+
+// Count returns the number of latencies.
+func (h *Histogram) Count() int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.count
+}
+
+// Min returns the lowest latency.
+func (h *Histogram) Min() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.min
+}
+
+// Max returns the highest latency.
+func (h *Histogram) Max() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.max
+}
+
+// Mean returns the mean latency.
+func (h *Histogram) Mean() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.count == 0 {
+		return 0
+	}
+	return time.Duration(h.sum / float64(h.count))
+}
+
+// Quantile returns the latency below which the fraction q of the latencies
+// fall, q in [0, 1]: Quantile(0.99) is the p99. As HDR histograms do, it
+// returns the highest value of the bucket of the quantile, so latency
+// assertions err on the safe side.
+func (h *Histogram) Quantile(q float64) time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.count == 0 || q <= 0 {
+		return h.min
+	}
+	rank := int64(math.Ceil(min(q, 1) * float64(h.count)))
+	var seen int64
+	for i, c := range h.counts {
+		if seen += c; seen >= rank {
+			_, hi := bucketRange(i)
+			return min(max(time.Duration(hi), h.min), h.max)
+		}
+	}
+	return h.max
+}
+
+// Bucket is a non-empty bucket of a Histogram.
+type Bucket struct {
+	// UpperBound is the highest latency of the bucket.
+	UpperBound time.Duration
+	Count      int64
+}
+
+// Buckets returns the non-empty buckets, by increasing latency.
+func (h *Histogram) Buckets() []Bucket {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var out []Bucket
+	for i, c := range h.counts {
+		if c > 0 {
+			_, hi := bucketRange(i)
+			out = append(out, Bucket{UpperBound: time.Duration(hi), Count: c})
+		}
+	}
+	return out
+}
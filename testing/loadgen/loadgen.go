@@ -0,0 +1,329 @@
+// Package loadgen runs load tests against functions and HTTP endpoints, in
+// go test or in CI jobs. A workload runs in closed loop — a fixed number of
+// workers calling the target back to back — or in open loop, at a fixed rate
+// of arrivals whatever the latency of the target, measuring each call from
+// its scheduled start so that a slow target is not hidden by coordinated
+// omission. Latencies go to an HDR-style histogram; SLOs such as "p99 below
+// 200ms" and "error rate below 1%" fail the test, and the report is written
+// as JSON for CI dashboards.
+//
+//	report := loadgen.RunTest(t, loadgen.Get(nil, server.URL+"/orders"), loadgen.Config{
+//		Rate:     200,
+//		Duration: 10 * time.Second,
+//	}, loadgen.P99Below(200*time.Millisecond), loadgen.ErrorRateBelow(0.01))
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+)
+
+// ErrDropped is the error of the open loop arrivals dropped because
+// MaxInFlight calls were running: the generator could not keep the rate.
+var ErrDropped = errors.New("loadgen: dropped, max in flight reached")
+
+// ErrPanic is wrapped by the errors of the calls that panicked.
+var ErrPanic = errors.New("loadgen: target panicked")
+
+// maxErrorTypes is the number of distinct errors kept by a report; the
+// others are counted as "other".
+const maxErrorTypes = 20
+
+// Target is the operation under load. It returns the failure of the call.
+type Target func(ctx context.Context) error
+
+// Mode is the mode of a load test.
+type Mode string
+
+// Modes of a load test.
+const (
+	// ClosedLoop runs Workers workers calling the target back to back.
+	ClosedLoop Mode = "closed"
+	// OpenLoop starts Rate calls per second, however long they take.
+	OpenLoop Mode = "open"
+)
+
+// Config configures a load test. It must set Duration, Requests or both.
+type Config struct {
+	// Name names the report; RunTest defaults it to the name of the test.
+	Name string
+
+	// Workers is the number of workers of a closed loop; the default is 1.
+	Workers int
+	// Think is the pause of the workers between their calls.
+	Think time.Duration
+
+	// Rate is the number of calls per second of an open loop. Setting it
+	// selects the open loop.
+	Rate float64
+	// MaxInFlight bounds the concurrent calls of an open loop; arrivals
+	// beyond it fail with ErrDropped. The default is 10000.
+	MaxInFlight int
+
+	// Duration is the duration of the test, after the warmup.
+	Duration time.Duration
+	// Requests is the maximum number of calls, after the warmup.
+	Requests int64
+	// Warmup runs the workload before the measurement, e.g. to fill the
+	// caches and the connection pools; its calls are not reported.
+	Warmup time.Duration
+
+	// Timeout bounds each call through its context.
+	Timeout time.Duration
+
+	// Clock is the clock of the test; the default is the real one.
+	Clock clock.Clock
+}
+
+// Mode returns the mode of the configuration.
+func (c Config) Mode() Mode {
+	if c.Rate > 0 {
+		return OpenLoop
+	}
+	return ClosedLoop
+}
+
+func (c Config) validate() error {
+	switch {
+	case c.Duration <= 0 && c.Requests <= 0:
+		return errors.New("loadgen: Duration or Requests is required")
+	case c.Rate < 0:
+		return errors.New("loadgen: Rate must not be negative")
+	case c.Rate > 0 && c.Workers > 0:
+		return errors.New("loadgen: Workers and Rate are exclusive")
+	case c.Workers < 0 || c.MaxInFlight < 0:
+		return errors.New("loadgen: Workers and MaxInFlight must not be negative")
+	}
+	return nil
+}
+
+// Run runs the workload against the target and returns its report. It
+// stops early, with the report of the calls made so far, when ctx ends.
+func Run(ctx context.Context, target Target, cfg Config) (*Report, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	r := &runner{target: target, cfg: cfg, clock: clock.OrReal(cfg.Clock)}
+
+	if cfg.Warmup > 0 {
+		warmup := cfg
+		warmup.Duration, warmup.Requests = cfg.Warmup, 0
+		r.run(ctx, warmup, nil)
+	}
+
+	rec := newRecorder()
+	start := r.clock.Now()
+	r.run(ctx, cfg, rec)
+	return rec.report(cfg, start, r.clock.Since(start)), nil
+}
+
+// runner runs the calls of a load test.
+type runner struct {
+	target Target
+	cfg    Config
+	clock  clock.Clock
+}
+
+// run runs a phase of the test, recording in rec when not nil.
+func (r *runner) run(ctx context.Context, cfg Config, rec *recorder) {
+	end := r.clock.Now().Add(cfg.Duration)
+	var issued atomic.Int64
+	next := func() bool {
+		if ctx.Err() != nil || cfg.Duration > 0 && !r.clock.Now().Before(end) {
+			return false
+		}
+		return cfg.Requests <= 0 || issued.Add(1) <= cfg.Requests
+	}
+
+	if cfg.Mode() == OpenLoop {
+		r.open(ctx, cfg, rec, next)
+		return
+	}
+	var wg sync.WaitGroup
+	for range max(cfg.Workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for next() {
+				r.call(ctx, r.clock.Now(), rec)
+				if cfg.Think > 0 && !r.sleep(ctx, cfg.Think) {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// open starts the calls at their scheduled times until next stops them.
+func (r *runner) open(ctx context.Context, cfg Config, rec *recorder, next func() bool) {
+	interval := time.Duration(float64(time.Second) / cfg.Rate)
+	maxInFlight := cfg.MaxInFlight
+	if maxInFlight == 0 {
+		maxInFlight = 10000
+	}
+	slots := make(chan struct{}, maxInFlight)
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	start := r.clock.Now()
+	for i := 0; ; i++ {
+		scheduled := start.Add(time.Duration(i) * interval)
+		if wait := scheduled.Sub(r.clock.Now()); wait > 0 && !r.sleep(ctx, wait) {
+			return
+		}
+		if !next() {
+			return
+		}
+		select {
+		case slots <- struct{}{}:
+		default:
+			rec.record(0, ErrDropped, false)
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			r.call(ctx, scheduled, rec)
+		}()
+	}
+}
+
+// call calls the target and records its latency since the scheduled start.
+func (r *runner) call(ctx context.Context, scheduled time.Time, rec *recorder) {
+	if r.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.cfg.Timeout)
+		defer cancel()
+	}
+	err := r.safeCall(ctx)
+	rec.record(r.clock.Since(scheduled), err, true)
+}
+
+// safeCall calls the target, recovering its panics.
+func (r *runner) safeCall(ctx context.Context) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("%w: %v", ErrPanic, v)
+		}
+	}()
+	return r.target(ctx)
+}
+
+// sleep waits d, reporting false when ctx ends first.
+func (r *runner) sleep(ctx context.Context, d time.Duration) bool {
+	timer := r.clock.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// recorder collects the results of the calls. A nil recorder discards them,
+// during the warmup.
+type recorder struct {
+	latency  *Histogram
+	mu       sync.Mutex
+	requests int64
+	errors   int64
+	byType   map[string]int64
+}
+
+func newRecorder() *recorder {
+	return &recorder{latency: NewHistogram(), byType: make(map[string]int64)}
+}
+
+func (rec *recorder) record(latency time.Duration, err error, measured bool) {
+	if rec == nil {
+		return
+	}
+	if measured {
+		rec.latency.Record(latency)
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.requests++
+	if err == nil {
+		return
+	}
+	rec.errors++
+	key := err.Error()
+	if _, ok := rec.byType[key]; !ok && len(rec.byType) >= maxErrorTypes {
+		key = "other"
+	}
+	rec.byType[key]++
+}
+
+func (rec *recorder) report(cfg Config, start time.Time, d time.Duration) *Report {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	report := &Report{
+		Name:      cfg.Name,
+		Mode:      cfg.Mode(),
+		StartedAt: start,
+		Duration:  d,
+		Requests:  rec.requests,
+		Errors:    rec.errors,
+		Latency:   rec.latency,
+	}
+	if len(rec.byType) > 0 {
+		report.ErrorsByType = rec.byType
+	}
+	return report
+}
+
+// StatusError is the error of the HTTP responses with a status of 400 or
+// more.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("status %d", e.StatusCode)
+}
+
+// HTTP returns a target sending the requests built by newRequest with
+// client, or http.DefaultClient when nil. Responses with a status of 400 or
+// more fail with a *StatusError; bodies are read in full, as clients do.
+func HTTP(client *http.Client, newRequest func(ctx context.Context) (*http.Request, error)) Target {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return func(ctx context.Context) error {
+		req, err := newRequest(ctx)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+			return err
+		}
+		if resp.StatusCode >= 400 {
+			return &StatusError{StatusCode: resp.StatusCode}
+		}
+		return nil
+	}
+}
+
+// Get returns an HTTP target sending GET requests to url.
+func Get(client *http.Client, url string) Target {
+	return HTTP(client, func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	})
+}
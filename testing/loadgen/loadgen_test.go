@@ -0,0 +1,226 @@
+package loadgen
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistogram(t *testing.T) {
+	h := NewHistogram()
+	for i := 1; i <= 10000; i++ {
+		h.Record(time.Duration(i) * time.Microsecond)
+	}
+	assert.Equal(t, int64(10000), h.Count())
+	assert.Equal(t, time.Microsecond, h.Min())
+	assert.Equal(t, 10*time.Millisecond, h.Max())
+	assert.InEpsilon(t, float64(5000500*time.Nanosecond), float64(h.Mean()), 0.0001)
+	for q, want := range map[float64]time.Duration{0.5: 5 * time.Millisecond, 0.9: 9 * time.Millisecond, 0.99: 9900 * time.Microsecond} {
+		got := h.Quantile(q)
+		assert.GreaterOrEqual(t, got, want, "quantiles err on the safe side")
+		assert.InEpsilon(t, float64(want), float64(got), 0.01, "p%v", 100*q)
+	}
+	assert.Equal(t, 10*time.Millisecond, h.Quantile(1))
+	assert.Equal(t, time.Microsecond, h.Quantile(0))
+
+	var total int64
+	for _, b := range h.Buckets() {
+		total += b.Count
+	}
+	assert.Equal(t, h.Count(), total)
+
+	small := NewHistogram()
+	for _, d := range []time.Duration{3, 7, 7, 200} {
+		small.Record(d)
+	}
+	assert.Equal(t, time.Duration(7), small.Quantile(0.5), "small values are exact")
+	small.Record(-time.Second)
+	assert.Equal(t, time.Duration(0), small.Min())
+
+	h.Merge(small)
+	assert.Equal(t, int64(10005), h.Count())
+	assert.Equal(t, time.Duration(0), h.Min())
+	assert.Equal(t, 10*time.Millisecond, h.Max())
+}
+
+func TestRun_ClosedLoop(t *testing.T) {
+	var calls atomic.Int64
+	target := func(ctx context.Context) error {
+		if calls.Add(1)%10 == 0 {
+			return errors.New("boom")
+		}
+		return nil
+	}
+
+	report, err := Run(context.Background(), target, Config{Name: "closed", Workers: 4, Requests: 100})
+	require.NoError(t, err)
+	assert.Equal(t, ClosedLoop, report.Mode)
+	assert.Equal(t, int64(100), report.Requests)
+	assert.Equal(t, int64(100), calls.Load())
+	assert.Equal(t, int64(10), report.Errors)
+	assert.InDelta(t, 0.1, report.ErrorRate(), 1e-9)
+	assert.Equal(t, map[string]int64{"boom": 10}, report.ErrorsByType)
+	assert.Equal(t, int64(100), report.Latency.Count())
+	assert.Positive(t, report.Throughput())
+
+	calls.Store(0)
+	report, err = Run(context.Background(), target, Config{Requests: 10, Warmup: 20 * time.Millisecond})
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), report.Requests, "warmup calls are not reported")
+	assert.Greater(t, calls.Load(), int64(10))
+
+	report, err = Run(context.Background(), func(context.Context) error { panic("kaboom") }, Config{Requests: 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), report.Errors)
+	assert.Contains(t, report.ErrorsByType, "loadgen: target panicked: kaboom")
+}
+
+func TestRun_OpenLoop(t *testing.T) {
+	target := func(ctx context.Context) error {
+		time.Sleep(5 * time.Millisecond)
+		return nil
+	}
+	report, err := Run(context.Background(), target, Config{Rate: 200, Duration: 250 * time.Millisecond})
+	require.NoError(t, err)
+	assert.Equal(t, OpenLoop, report.Mode)
+	assert.InDelta(t, 50, report.Requests, 8)
+	assert.Zero(t, report.Errors)
+	assert.GreaterOrEqual(t, report.Latency.Min(), 5*time.Millisecond)
+
+	report, err = Run(context.Background(), func(ctx context.Context) error {
+		time.Sleep(100 * time.Millisecond)
+		return nil
+	}, Config{Rate: 1000, Requests: 20, MaxInFlight: 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(20), report.Requests)
+	assert.Equal(t, int64(19), report.ErrorsByType[ErrDropped.Error()])
+}
+
+func TestRun_Timeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	report, err := Run(ctx, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, Config{Workers: 2, Requests: 4, Timeout: 10 * time.Millisecond})
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), report.ErrorsByType[context.DeadlineExceeded.Error()])
+
+	cancel()
+	report, err = Run(ctx, func(context.Context) error { return nil }, Config{Duration: time.Hour})
+	require.NoError(t, err, "a cancelled run returns the partial report")
+	assert.Zero(t, report.Requests)
+}
+
+func TestConfig_Validate(t *testing.T) {
+	for _, cfg := range []Config{
+		{},
+		{Workers: 2, Rate: 10, Requests: 1},
+		{Rate: -1, Requests: 1},
+		{Workers: -1, Requests: 1},
+	} {
+		_, err := Run(context.Background(), func(context.Context) error { return nil }, cfg)
+		assert.Error(t, err, "%+v", cfg)
+	}
+}
+
+func TestHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+
+	require.NoError(t, Get(server.Client(), server.URL+"/ok")(context.Background()))
+	err := Get(server.Client(), server.URL+"/fail")(context.Background())
+	var status *StatusError
+	require.ErrorAs(t, err, &status)
+	assert.Equal(t, http.StatusServiceUnavailable, status.StatusCode)
+}
+
+func TestReport(t *testing.T) {
+	h := NewHistogram()
+	for i := 1; i <= 100; i++ {
+		h.Record(time.Duration(i) * time.Millisecond)
+	}
+	report := &Report{Name: "orders", Mode: OpenLoop, Duration: 2 * time.Second, Requests: 100, Errors: 2, Latency: h}
+
+	assert.False(t, report.Check(P99Below(50*time.Millisecond), ErrorRateBelow(0.05), ThroughputAtLeast(40)))
+	require.Len(t, report.SLOs, 3)
+	assert.Equal(t, SLOResult{Name: "p99 < 50ms", Unit: "ms", Threshold: 50, Actual: 99.0, Passed: false}, withRounded(report.SLOs[0]))
+	assert.True(t, report.SLOs[1].Passed)
+	assert.Equal(t, "throughput >= 40 req/s", report.SLOs[2].Name)
+	assert.True(t, report.SLOs[2].Passed)
+	assert.Contains(t, report.String(), "SLO p99 < 50ms: FAILED")
+
+	data, err := json.Marshal(report)
+	require.NoError(t, err)
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, 0.02, decoded["error_rate"])
+	assert.Equal(t, 50.0, decoded["throughput_rps"])
+	assert.Equal(t, false, decoded["passed"])
+	latency := decoded["latency_ms"].(map[string]any)
+	assert.Equal(t, 1.0, latency["min"])
+	assert.Equal(t, 100.0, latency["max"])
+	assert.NotEmpty(t, latency["histogram"])
+}
+
+// withRounded rounds the measured value, a bucket bound, to the millisecond.
+func withRounded(res SLOResult) SLOResult {
+	res.Actual = float64(int(res.Actual))
+	return res
+}
+
+// recordingTB records the failures of an assertion.
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingTB) Helper()         {}
+func (r *recordingTB) Log(args ...any) {}
+func (r *recordingTB) Name() string    { return "TestOrders/open loop" }
+func (r *recordingTB) Errorf(f string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(f, args...))
+}
+
+func TestAssert(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(ReportDirEnv, dir)
+
+	h := NewHistogram()
+	h.Record(300 * time.Millisecond)
+	report := &Report{Name: "orders", Duration: time.Second, Requests: 1, Latency: h}
+	tb := &recordingTB{TB: t}
+	assert.False(t, Assert(tb, report, P99Below(200*time.Millisecond), ErrorRateBelow(0.01)))
+	require.Len(t, tb.errors, 1)
+	assert.Contains(t, tb.errors[0], "SLO p99 < 200ms missed")
+
+	data, err := os.ReadFile(filepath.Join(dir, "TestOrders__open_loop.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"name": "orders"`)
+}
+
+func TestRunTest(t *testing.T) {
+	report := RunTest(t, func(context.Context) error { return nil },
+		Config{Workers: 2, Duration: 50 * time.Millisecond},
+		P99Below(time.Second), ErrorRateBelow(0.01), ThroughputAtLeast(1))
+	assert.Equal(t, t.Name(), report.Name)
+	assert.True(t, report.Passed())
+	assert.Positive(t, report.Requests)
+}
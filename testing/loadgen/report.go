@@ -0,0 +1,163 @@
+package loadgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Report is the result of a load test.
+type Report struct {
+	Name      string
+	Mode      Mode
+	StartedAt time.Time
+	// Duration is the measured duration, without the warmup.
+	Duration time.Duration
+	// Requests is the number of calls, Errors the number of failed ones.
+	Requests int64
+	Errors   int64
+	// ErrorsByType counts the errors by message.
+	ErrorsByType map[string]int64
+	// Latency is the histogram of the latencies of the calls, failed ones
+	// included.
+	Latency *Histogram
+	// SLOs are the results of the SLOs checked by Check.
+	SLOs []SLOResult
+}
+
+// ErrorRate returns the fraction of failed calls.
+func (r *Report) ErrorRate() float64 {
+	if r.Requests == 0 {
+		return 0
+	}
+	return float64(r.Errors) / float64(r.Requests)
+}
+
+// Throughput returns the number of calls per second.
+func (r *Report) Throughput() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Requests) / r.Duration.Seconds()
+}
+
+// Passed reports whether every SLO checked passed.
+func (r *Report) Passed() bool {
+	for _, res := range r.SLOs {
+		if !res.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns a summary of the report for the test logs.
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%s loop): %d requests in %s, %.1f req/s, %.2f%% errors\n",
+		r.Name, r.Mode, r.Requests, r.Duration.Round(time.Millisecond), r.Throughput(), 100*r.ErrorRate())
+	fmt.Fprintf(&b, "latency: min %s, mean %s, p50 %s, p90 %s, p99 %s, p99.9 %s, max %s",
+		r.Latency.Min(), r.Latency.Mean(), r.Latency.Quantile(0.5), r.Latency.Quantile(0.9),
+		r.Latency.Quantile(0.99), r.Latency.Quantile(0.999), r.Latency.Max())
+	for _, res := range r.SLOs {
+		status := "ok"
+		if !res.Passed {
+			status = "FAILED"
+		}
+		fmt.Fprintf(&b, "\nSLO %s: %s (%s)", res.Name, status, res.formatActual())
+	}
+	return b.String()
+}
+
+// jsonReport is the JSON form of a Report. Latencies are in milliseconds.
+type jsonReport struct {
+	Name            string           `json:"name"`
+	Mode            Mode             `json:"mode"`
+	StartedAt       time.Time        `json:"started_at"`
+	DurationSeconds float64          `json:"duration_seconds"`
+	Requests        int64            `json:"requests"`
+	Errors          int64            `json:"errors"`
+	ErrorRate       float64          `json:"error_rate"`
+	Throughput      float64          `json:"throughput_rps"`
+	ErrorsByType    map[string]int64 `json:"errors_by_type,omitempty"`
+	Latency         jsonLatency      `json:"latency_ms"`
+	SLOs            []SLOResult      `json:"slos,omitempty"`
+	Passed          bool             `json:"passed"`
+}
+
+type jsonLatency struct {
+	Min       float64      `json:"min"`
+	Mean      float64      `json:"mean"`
+	P50       float64      `json:"p50"`
+	P90       float64      `json:"p90"`
+	P95       float64      `json:"p95"`
+	P99       float64      `json:"p99"`
+	P999      float64      `json:"p99_9"`
+	Max       float64      `json:"max"`
+	Histogram []jsonBucket `json:"histogram"`
+}
+
+type jsonBucket struct {
+	UpperBound float64 `json:"le"`
+	Count      int64   `json:"count"`
+}
+
+// milliseconds returns d in milliseconds.
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// MarshalJSON encodes the report for CI dashboards, with the latencies in
+// milliseconds and the non-empty buckets of the histogram.
+func (r *Report) MarshalJSON() ([]byte, error) {
+	h := r.Latency
+	if h == nil {
+		h = NewHistogram()
+	}
+	out := jsonReport{
+		Name:            r.Name,
+		Mode:            r.Mode,
+		StartedAt:       r.StartedAt,
+		DurationSeconds: r.Duration.Seconds(),
+		Requests:        r.Requests,
+		Errors:          r.Errors,
+		ErrorRate:       r.ErrorRate(),
+		Throughput:      r.Throughput(),
+		ErrorsByType:    r.ErrorsByType,
+		Latency: jsonLatency{
+			Min:       milliseconds(h.Min()),
+			Mean:      milliseconds(h.Mean()),
+			P50:       milliseconds(h.Quantile(0.5)),
+			P90:       milliseconds(h.Quantile(0.9)),
+			P95:       milliseconds(h.Quantile(0.95)),
+			P99:       milliseconds(h.Quantile(0.99)),
+			P999:      milliseconds(h.Quantile(0.999)),
+			Max:       milliseconds(h.Max()),
+			Histogram: []jsonBucket{},
+		},
+		SLOs:   r.SLOs,
+		Passed: r.Passed(),
+	}
+	for _, b := range h.Buckets() {
+		out.Latency.Histogram = append(out.Latency.Histogram, jsonBucket{UpperBound: milliseconds(b.UpperBound), Count: b.Count})
+	}
+	return json.Marshal(out)
+}
+
+// WriteFile writes the JSON report to path, creating its directory.
+func (r *Report) WriteFile(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("loadgen: failed to encode report: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("loadgen: failed to create report directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("loadgen: failed to write report: %w", err)
+	}
+	return nil
+}
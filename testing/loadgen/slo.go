@@ -0,0 +1,141 @@
+package loadgen
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ReportDirEnv is the environment variable of the directory where RunTest
+// and Assert write the JSON reports, one per test, for CI dashboards.
+const ReportDirEnv = "LOADGEN_REPORT_DIR"
+
+// SLO is an objective checked on a report: the measured value must be below
+// the threshold, or above it when AtLeast is set.
+type SLO struct {
+	// Name describes the objective, e.g. "p99 < 200ms".
+	Name string
+	// Unit is the unit of the threshold in the JSON report.
+	Unit      string
+	Threshold float64
+	AtLeast   bool
+	// Measure returns the value of the report compared with the threshold.
+	Measure func(*Report) float64
+}
+
+// SLOResult is the result of an SLO.
+type SLOResult struct {
+	Name      string  `json:"name"`
+	Unit      string  `json:"unit"`
+	Threshold float64 `json:"threshold"`
+	Actual    float64 `json:"actual"`
+	Passed    bool    `json:"passed"`
+}
+
+func (res SLOResult) formatActual() string {
+	return fmt.Sprintf("%s %s", strconv.FormatFloat(res.Actual, 'f', -1, 64), res.Unit)
+}
+
+// LatencyBelow requires the quantile q of the latencies to be below d.
+func LatencyBelow(q float64, d time.Duration) SLO {
+	return SLO{
+		Name:      fmt.Sprintf("p%s < %s", strconv.FormatFloat(100*q, 'f', -1, 64), d),
+		Unit:      "ms",
+		Threshold: milliseconds(d),
+		Measure:   func(r *Report) float64 { return milliseconds(r.Latency.Quantile(q)) },
+	}
+}
+
+// P99Below requires the p99 of the latencies to be below d.
+func P99Below(d time.Duration) SLO {
+	return LatencyBelow(0.99, d)
+}
+
+// ErrorRateBelow requires the fraction of failed calls to be below rate.
+func ErrorRateBelow(rate float64) SLO {
+	return SLO{
+		Name:      fmt.Sprintf("error rate < %s%%", strconv.FormatFloat(100*rate, 'f', -1, 64)),
+		Unit:      "ratio",
+		Threshold: rate,
+		Measure:   (*Report).ErrorRate,
+	}
+}
+
+// ThroughputAtLeast requires at least rps calls per second.
+func ThroughputAtLeast(rps float64) SLO {
+	return SLO{
+		Name:      fmt.Sprintf("throughput >= %s req/s", strconv.FormatFloat(rps, 'f', -1, 64)),
+		Unit:      "req/s",
+		Threshold: rps,
+		AtLeast:   true,
+		Measure:   (*Report).Throughput,
+	}
+}
+
+// Check checks the SLOs, adding their results to the report, and reports
+// whether they all passed.
+func (r *Report) Check(slos ...SLO) bool {
+	passed := true
+	for _, slo := range slos {
+		actual := slo.Measure(r)
+		ok := actual < slo.Threshold
+		if slo.AtLeast {
+			ok = actual >= slo.Threshold
+		}
+		r.SLOs = append(r.SLOs, SLOResult{Name: slo.Name, Unit: slo.Unit, Threshold: slo.Threshold, Actual: actual, Passed: ok})
+		passed = passed && ok
+	}
+	return passed
+}
+
+// Assert checks the SLOs, failing the test for each one missed, logs the
+// summary of the report and, when ReportDirEnv is set, writes the JSON
+// report there, named after the test.
+func Assert(tb testing.TB, report *Report, slos ...SLO) bool {
+	tb.Helper()
+	passed := report.Check(slos...)
+	tb.Log(report.String())
+	for _, res := range report.SLOs {
+		if !res.Passed {
+			tb.Errorf("loadgen: SLO %s missed: got %s", res.Name, res.formatActual())
+		}
+	}
+	if dir := os.Getenv(ReportDirEnv); dir != "" {
+		if err := report.WriteFile(filepath.Join(dir, reportFile(tb.Name()))); err != nil {
+			tb.Errorf("%v", err)
+		}
+	}
+	return passed
+}
+
+// reportFile returns the name of the report file of a test.
+func reportFile(testName string) string {
+	return strings.NewReplacer("/", "__", " ", "_", ":", "_").Replace(testName) + ".json"
+}
+
+// RunTest runs the load test in a test, named after it unless cfg.Name is
+// set, and asserts the SLOs. Load tests are skipped with -short.
+//
+//	func TestOrdersLoad(t *testing.T) {
+//		loadgen.RunTest(t, target, loadgen.Config{Workers: 8, Duration: 5 * time.Second},
+//			loadgen.P99Below(100*time.Millisecond), loadgen.ErrorRateBelow(0.001))
+//	}
+func RunTest(tb testing.TB, target Target, cfg Config, slos ...SLO) *Report {
+	tb.Helper()
+	if testing.Short() {
+		tb.Skip("loadgen: load test skipped in short mode")
+	}
+	if cfg.Name == "" {
+		cfg.Name = tb.Name()
+	}
+	report, err := Run(tb.Context(), target, cfg)
+	if err != nil {
+		tb.Fatalf("%v", err)
+	}
+	Assert(tb, report, slos...)
+	return report
+}
@@ -0,0 +1,225 @@
+// Command perf runs go benchmarks and fails on performance regressions:
+//
+//	perf run -bench . -count 10 -o head.json ./domainerrors/...
+//	perf compare base.json head.json
+//	perf check -baseline testdata/perf/baseline.json -limits testdata/perf/limits.json ./domainerrors/...
+//
+// compare accepts JSON snapshots or saved go test -bench outputs. check runs
+// the benchmarks and compares them with the baseline, or writes it with
+// -update. Both exit with status 1 on a regression or a limit exceeded.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+
+	"github.com/fsvxavier/nexs-lib/testing/perf"
+)
+
+// errFailed reports regressions or limits exceeded, already printed.
+var errFailed = errors.New("performance check failed")
+
+const usage = `usage:
+  perf run [flags] packages...
+  perf compare [flags] base head
+  perf check -baseline file [flags] packages...
+
+Run "perf <command> -h" for the flags of a command.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var err error
+	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
+	case "run":
+		err = runCmd(ctx, args)
+	case "compare":
+		err = compareCmd(args)
+	case "check":
+		err = checkCmd(ctx, args)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		if !errors.Is(err, errFailed) {
+			fmt.Fprintln(os.Stderr, "perf:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+// runFlags are the flags of a run of benchmarks.
+func runFlags(fs *flag.FlagSet) *perf.RunConfig {
+	cfg := &perf.RunConfig{}
+	fs.StringVar(&cfg.Bench, "bench", perf.DefaultBench, "benchmarks to run, as go test -bench")
+	fs.IntVar(&cfg.Count, "count", perf.DefaultCount, "runs of each benchmark")
+	fs.StringVar(&cfg.Benchtime, "benchtime", "", "duration or iterations of each run, as go test -benchtime")
+	return cfg
+}
+
+// compareFlags are the flags of a comparison.
+func compareFlags(fs *flag.FlagSet) func() ([]perf.CompareOption, error) {
+	alpha := fs.Float64("alpha", perf.DefaultAlpha, "significance level")
+	threshold := fs.Float64("threshold", perf.DefaultThreshold, "change, as a fraction, above which a significant change is a regression")
+	unitThresholds := fs.String("unit-threshold", "", "thresholds of units, e.g. allocs/op=0,B/op=0.1")
+	return func() ([]perf.CompareOption, error) {
+		opts := []perf.CompareOption{perf.WithAlpha(*alpha), perf.WithThreshold(*threshold)}
+		for _, pair := range strings.Split(*unitThresholds, ",") {
+			if pair == "" {
+				continue
+			}
+			unit, value, ok := strings.Cut(pair, "=")
+			t, err := strconv.ParseFloat(value, 64)
+			if !ok || err != nil {
+				return nil, fmt.Errorf("invalid -unit-threshold %q", pair)
+			}
+			opts = append(opts, perf.WithUnitThreshold(unit, t))
+		}
+		return opts, nil
+	}
+}
+
+func runCmd(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("perf run", flag.ExitOnError)
+	cfg := runFlags(fs)
+	out := fs.String("o", "", "snapshot file (defaults to standard output)")
+	_ = fs.Parse(args)
+	cfg.Packages = fs.Args()
+	cfg.Output = os.Stderr
+
+	snapshot, err := perf.Run(ctx, *cfg)
+	if err != nil {
+		return err
+	}
+	if *out != "" {
+		return snapshot.Save(*out)
+	}
+	return writeJSON(os.Stdout, snapshot)
+}
+
+func compareCmd(args []string) error {
+	fs := flag.NewFlagSet("perf compare", flag.ExitOnError)
+	options := compareFlags(fs)
+	asJSON := fs.Bool("json", false, "write the comparison as JSON")
+	_ = fs.Parse(args)
+	if fs.NArg() != 2 {
+		return errors.New("compare needs a base and a head file")
+	}
+	opts, err := options()
+	if err != nil {
+		return err
+	}
+	base, err := perf.Load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	head, err := perf.Load(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	comparison := perf.Compare(base, head, opts...)
+	if *asJSON {
+		err = writeJSON(os.Stdout, comparison)
+	} else {
+		err = comparison.WriteText(os.Stdout)
+	}
+	if err != nil {
+		return err
+	}
+	return report(comparison, nil)
+}
+
+func checkCmd(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("perf check", flag.ExitOnError)
+	cfg := runFlags(fs)
+	options := compareFlags(fs)
+	baselinePath := fs.String("baseline", "", "baseline snapshot")
+	limitsPath := fs.String("limits", "", "limits file, a JSON array of {benchmark, unit, max}")
+	update := fs.Bool("update", false, "write the results as the new baseline")
+	out := fs.String("o", "", "also write the results to this snapshot file")
+	_ = fs.Parse(args)
+	cfg.Packages = fs.Args()
+	cfg.Output = os.Stderr
+	if *baselinePath == "" {
+		return errors.New("check needs -baseline")
+	}
+	opts, err := options()
+	if err != nil {
+		return err
+	}
+	var limits []perf.Limit
+	if *limitsPath != "" {
+		if limits, err = perf.LoadLimits(*limitsPath); err != nil {
+			return err
+		}
+	}
+
+	head, err := perf.Run(ctx, *cfg)
+	if err != nil {
+		return err
+	}
+	if *out != "" {
+		if err := head.Save(*out); err != nil {
+			return err
+		}
+	}
+	violations := head.CheckLimits(limits)
+	if *update {
+		if err := head.Save(*baselinePath); err != nil {
+			return err
+		}
+		fmt.Printf("baseline written to %s\n", *baselinePath)
+		return report(nil, violations)
+	}
+
+	base, err := perf.Load(*baselinePath)
+	if err != nil {
+		return err
+	}
+	comparison := perf.Compare(base, head, opts...)
+	if err := comparison.WriteText(os.Stdout); err != nil {
+		return err
+	}
+	return report(comparison, violations)
+}
+
+// report prints the regressions and the violations, returning errFailed
+// when there are any.
+func report(comparison *perf.Comparison, violations []perf.Violation) error {
+	var regressions []perf.Delta
+	if comparison != nil {
+		regressions = comparison.Regressions()
+	}
+	for _, d := range regressions {
+		fmt.Fprintf(os.Stderr, "regression: %s %s %+.2f%% (p=%.3f)\n", d.Name, d.Unit, 100*d.Change, d.P)
+	}
+	for _, v := range violations {
+		fmt.Fprintf(os.Stderr, "limit exceeded: %s\n", v)
+	}
+	if len(regressions)+len(violations) > 0 {
+		return errFailed
+	}
+	return nil
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,308 @@
+package perf
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Defaults of a comparison.
+const (
+	// DefaultAlpha is the significance level: changes with a p-value of
+	// DefaultAlpha or more are noise.
+	DefaultAlpha = 0.05
+	// DefaultThreshold is the change, as a fraction of the baseline, above
+	// which a significant change is a regression.
+	DefaultThreshold = 0.05
+)
+
+// CompareOption configures a comparison.
+type CompareOption func(*compareConfig)
+
+type compareConfig struct {
+	alpha      float64
+	threshold  float64
+	thresholds map[string]float64
+}
+
+// WithAlpha sets the significance level.
+func WithAlpha(alpha float64) CompareOption {
+	return func(c *compareConfig) {
+		c.alpha = alpha
+	}
+}
+
+// WithThreshold sets the threshold of every unit without its own.
+func WithThreshold(threshold float64) CompareOption {
+	return func(c *compareConfig) {
+		c.threshold = threshold
+	}
+}
+
+// WithUnitThreshold sets the threshold of a unit, e.g. 0 for allocs/op so
+// that any new allocation fails.
+func WithUnitThreshold(unit string, threshold float64) CompareOption {
+	return func(c *compareConfig) {
+		c.thresholds[unit] = threshold
+	}
+}
+
+// HigherIsBetter reports whether greater values of the unit are better, as
+// for throughputs such as MB/s.
+func HigherIsBetter(unit string) bool {
+	return strings.HasSuffix(unit, "/s")
+}
+
+// Delta is the change of a benchmark in a unit.
+type Delta struct {
+	Package string  `json:"package,omitempty"`
+	Name    string  `json:"name"`
+	Unit    string  `json:"unit"`
+	Base    Summary `json:"base"`
+	Head    Summary `json:"head"`
+	// Change is the change of the median, as a fraction of the baseline;
+	// changes from 0, such as a first allocation, count as 100%.
+	Change float64 `json:"change"`
+	P      float64 `json:"p"`
+	// Significant reports whether the change is not noise.
+	Significant bool `json:"significant"`
+	// Regression and Improvement report significant changes beyond the
+	// threshold of the unit, for the worse or for the better.
+	Regression  bool `json:"regression"`
+	Improvement bool `json:"improvement"`
+}
+
+// Comparison is the comparison of a snapshot with a baseline.
+type Comparison struct {
+	Alpha  float64 `json:"alpha"`
+	Deltas []Delta `json:"deltas"`
+	// Removed and Added are the benchmarks only in the baseline or only in
+	// the snapshot.
+	Removed []string `json:"removed,omitempty"`
+	Added   []string `json:"added,omitempty"`
+}
+
+// Compare compares the head snapshot with the base one, benchmark by
+// benchmark and unit by unit.
+func Compare(base, head *Snapshot, opts ...CompareOption) *Comparison {
+	cfg := compareConfig{alpha: DefaultAlpha, threshold: DefaultThreshold, thresholds: make(map[string]float64)}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	c := &Comparison{Alpha: cfg.alpha}
+	for _, b := range base.Benchmarks {
+		h := head.Benchmark(b.Package, b.Name)
+		if h == nil {
+			c.Removed = append(c.Removed, b.ID())
+			continue
+		}
+		for _, unit := range units(b) {
+			if samples, ok := h.Samples[unit]; ok {
+				c.Deltas = append(c.Deltas, cfg.delta(b, unit, b.Samples[unit], samples))
+			}
+		}
+	}
+	for _, h := range head.Benchmarks {
+		if base.Benchmark(h.Package, h.Name) == nil {
+			c.Added = append(c.Added, h.ID())
+		}
+	}
+	return c
+}
+
+// units returns the units of the benchmark, ns/op, B/op and allocs/op
+// first.
+func units(b *Benchmark) []string {
+	rank := func(unit string) int {
+		switch unit {
+		case "ns/op":
+			return 0
+		case "B/op":
+			return 1
+		case "allocs/op":
+			return 2
+		}
+		return 3
+	}
+	return slices.SortedFunc(maps.Keys(b.Samples), func(x, y string) int {
+		return cmp.Or(cmp.Compare(rank(x), rank(y)), strings.Compare(x, y))
+	})
+}
+
+func (cfg compareConfig) delta(b *Benchmark, unit string, base, head []float64) Delta {
+	d := Delta{Package: b.Package, Name: b.Name, Unit: unit, Base: Summarize(base), Head: Summarize(head)}
+	switch {
+	case d.Base.Median != 0:
+		d.Change = (d.Head.Median - d.Base.Median) / math.Abs(d.Base.Median)
+	case d.Head.Median > 0:
+		d.Change = 1
+	case d.Head.Median < 0:
+		d.Change = -1
+	}
+	d.P = MannWhitneyU(base, head)
+	d.Significant = d.P < cfg.alpha
+
+	threshold, ok := cfg.thresholds[unit]
+	if !ok {
+		threshold = cfg.threshold
+	}
+	worse := d.Change
+	if HigherIsBetter(unit) {
+		worse = -worse
+	}
+	d.Regression = d.Significant && worse > threshold
+	d.Improvement = d.Significant && -worse > threshold
+	return d
+}
+
+// Regressions returns the deltas that are regressions.
+func (c *Comparison) Regressions() []Delta {
+	var out []Delta
+	for _, d := range c.Deltas {
+		if d.Regression {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// WriteText writes the comparison as a table, one section per unit, in the
+// style of benchstat.
+func (c *Comparison) WriteText(w io.Writer) error {
+	var units []string
+	for _, d := range c.Deltas {
+		if !slices.Contains(units, d.Unit) {
+			units = append(units, d.Unit)
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for i, unit := range units {
+		if i > 0 {
+			fmt.Fprintln(tw)
+		}
+		fmt.Fprintf(tw, "%s\tbase\thead\tdelta\t\n", unit)
+		for _, d := range c.Deltas {
+			if d.Unit != unit {
+				continue
+			}
+			name := d.Name
+			if d.Package != "" {
+				name = path.Base(d.Package) + "." + d.Name
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", name, formatSummary(d.Base), formatSummary(d.Head), formatChange(d), verdict(d))
+		}
+	}
+	for _, id := range c.Removed {
+		fmt.Fprintf(tw, "\nremoved: %s", id)
+	}
+	for _, id := range c.Added {
+		fmt.Fprintf(tw, "\nadded: %s", id)
+	}
+	if len(c.Removed)+len(c.Added) > 0 {
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
+
+// formatSummary formats the median and the spread of a summary.
+func formatSummary(s Summary) string {
+	return fmt.Sprintf("%s ± %.0f%%", strconv.FormatFloat(s.Median, 'g', 4, 64), 100*s.Spread)
+}
+
+// formatChange formats the change of a delta, "~" when it is noise.
+func formatChange(d Delta) string {
+	stats := fmt.Sprintf("(p=%.3f n=%d+%d)", d.P, d.Base.N, d.Head.N)
+	if !d.Significant {
+		return "~ " + stats
+	}
+	return fmt.Sprintf("%+.2f%% %s", 100*d.Change, stats)
+}
+
+func verdict(d Delta) string {
+	switch {
+	case d.Regression:
+		return "REGRESSION"
+	case d.Improvement:
+		return "improvement"
+	}
+	return ""
+}
+
+// Limit is an absolute bound of the median of the benchmarks matching a
+// pattern, for the claims of the documentation such as "0 allocs/op".
+type Limit struct {
+	// Benchmark is the name of the benchmarks, or a path.Match pattern such
+	// as "BenchmarkParse/*".
+	Benchmark string  `json:"benchmark"`
+	Unit      string  `json:"unit"`
+	Max       float64 `json:"max"`
+}
+
+// Violation is a benchmark beyond a limit.
+type Violation struct {
+	Benchmark string  `json:"benchmark"`
+	Limit     Limit   `json:"limit"`
+	Median    float64 `json:"median"`
+	// Missing reports a limit matching no benchmark.
+	Missing bool `json:"missing,omitempty"`
+}
+
+func (v Violation) String() string {
+	if v.Missing {
+		return fmt.Sprintf("%s: no benchmark reports %s", v.Benchmark, v.Limit.Unit)
+	}
+	return fmt.Sprintf("%s: %s %s exceeds %s", v.Benchmark,
+		strconv.FormatFloat(v.Median, 'g', 4, 64), v.Limit.Unit, strconv.FormatFloat(v.Limit.Max, 'g', 4, 64))
+}
+
+// CheckLimits returns the benchmarks of the snapshot whose median exceeds a
+// limit. A limit matching no benchmark is a violation too, so that renamed
+// benchmarks do not silently drop their limits.
+func (s *Snapshot) CheckLimits(limits []Limit) []Violation {
+	var out []Violation
+	for _, l := range limits {
+		matched := false
+		for _, b := range s.Benchmarks {
+			if ok, _ := path.Match(l.Benchmark, b.Name); !ok {
+				continue
+			}
+			samples, ok := b.Samples[l.Unit]
+			if !ok {
+				continue
+			}
+			matched = true
+			if median := Summarize(samples).Median; median > l.Max {
+				out = append(out, Violation{Benchmark: b.ID(), Limit: l, Median: median})
+			}
+		}
+		if !matched {
+			out = append(out, Violation{Benchmark: l.Benchmark, Limit: l, Missing: true})
+		}
+	}
+	return out
+}
+
+// LoadLimits reads limits from a JSON file holding an array of limits.
+func LoadLimits(path string) ([]Limit, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("perf: failed to read %s: %w", path, err)
+	}
+	var limits []Limit
+	if err := json.Unmarshal(data, &limits); err != nil {
+		return nil, fmt.Errorf("perf: failed to decode %s: %w", path, err)
+	}
+	return limits, nil
+}
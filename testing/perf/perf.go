@@ -0,0 +1,233 @@
+// Package perf guards against performance regressions: it runs go
+// benchmarks, stores their results as JSON snapshots and compares a snapshot
+// with a baseline the way benchstat does — medians of several runs and a
+// Mann-Whitney U test, so that noise is not reported as a regression — and
+// fails when a significant change exceeds the threshold of its unit. Limits
+// turn the absolute claims of the documentation, such as "2 allocs/op",
+// into checks. The perf command wraps it for CI:
+//
+//	go run ./testing/perf/cmd/perf check -baseline testdata/perf/baseline.json -bench . ./domainerrors/...
+package perf
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Snapshot is the results of a run of benchmarks.
+type Snapshot struct {
+	GoVersion  string       `json:"go_version,omitempty"`
+	GOOS       string       `json:"goos,omitempty"`
+	GOARCH     string       `json:"goarch,omitempty"`
+	CPU        string       `json:"cpu,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+	Benchmarks []*Benchmark `json:"benchmarks"`
+}
+
+// Benchmark is the samples of a benchmark, one per run and unit.
+type Benchmark struct {
+	Package string `json:"package,omitempty"`
+	// Name is the name of the benchmark without the GOMAXPROCS suffix,
+	// e.g. "BenchmarkParse/small".
+	Name    string               `json:"name"`
+	Samples map[string][]float64 `json:"samples"`
+}
+
+// ID returns the package and the name of the benchmark.
+func (b *Benchmark) ID() string {
+	if b.Package == "" {
+		return b.Name
+	}
+	return b.Package + "." + b.Name
+}
+
+// Benchmark returns the benchmark of the package with the name, or nil.
+func (s *Snapshot) Benchmark(pkg, name string) *Benchmark {
+	for _, b := range s.Benchmarks {
+		if b.Package == pkg && b.Name == name {
+			return b
+		}
+	}
+	return nil
+}
+
+// add adds a sample of the benchmark.
+func (s *Snapshot) add(pkg, name, unit string, value float64) {
+	b := s.Benchmark(pkg, name)
+	if b == nil {
+		b = &Benchmark{Package: pkg, Name: name, Samples: make(map[string][]float64)}
+		s.Benchmarks = append(s.Benchmarks, b)
+	}
+	b.Samples[unit] = append(b.Samples[unit], value)
+}
+
+// Parse reads the output of go test -bench, as benchstat does: the result
+// lines of each benchmark become its samples and the configuration lines
+// (pkg, goos, goarch, cpu) are kept. Other lines are ignored.
+func Parse(r io.Reader) (*Snapshot, error) {
+	s := &Snapshot{CreatedAt: time.Now().UTC()}
+	var pkg string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if key, value, ok := strings.Cut(line, ": "); ok && !strings.Contains(key, " ") {
+			switch key {
+			case "pkg":
+				pkg = value
+			case "goos":
+				s.GOOS = value
+			case "goarch":
+				s.GOARCH = value
+			case "cpu":
+				s.CPU = value
+			}
+			continue
+		}
+		parseResult(s, pkg, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("perf: failed to read benchmark output: %w", err)
+	}
+	return s, nil
+}
+
+// parseResult adds the samples of a result line such as
+// "BenchmarkParse-8  1000  1234 ns/op  128 B/op  2 allocs/op".
+func parseResult(s *Snapshot, pkg, line string) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 || len(fields)%2 != 0 || !strings.HasPrefix(fields[0], "Benchmark") {
+		return
+	}
+	if _, err := strconv.ParseInt(fields[1], 10, 64); err != nil {
+		return
+	}
+	name := trimProcs(fields[0])
+	for i := 2; i+1 < len(fields); i += 2 {
+		value, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return
+		}
+		s.add(pkg, name, fields[i+1], value)
+	}
+}
+
+// trimProcs removes the GOMAXPROCS suffix of a benchmark name.
+func trimProcs(name string) string {
+	i := strings.LastIndexByte(name, '-')
+	if i < 0 {
+		return name
+	}
+	if _, err := strconv.Atoi(name[i+1:]); err != nil {
+		return name
+	}
+	return name[:i]
+}
+
+// Load reads a snapshot from a JSON file or, when the file is not JSON, from
+// the saved output of go test -bench.
+func Load(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("perf: failed to read %s: %w", path, err)
+	}
+	if trimmed := strings.TrimSpace(string(data)); !strings.HasPrefix(trimmed, "{") {
+		return Parse(strings.NewReader(trimmed))
+	}
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("perf: failed to decode %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// Save writes the snapshot as JSON to path, creating its directory.
+func (s *Snapshot) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("perf: failed to encode snapshot: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("perf: failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("perf: failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// Defaults of a RunConfig.
+const (
+	DefaultBench = "."
+	DefaultCount = 10
+)
+
+// RunConfig configures a run of benchmarks.
+type RunConfig struct {
+	// Packages are the packages of the benchmarks, as given to go test.
+	Packages []string
+	// Bench is the -bench regular expression; the default is DefaultBench.
+	Bench string
+	// Count is the number of runs of each benchmark; the default is
+	// DefaultCount. Comparisons need 4 runs or more to be significant.
+	Count int
+	// Benchtime is the -benchtime of each run, e.g. "100ms" or "1000x".
+	Benchtime string
+	// Dir is the directory go test runs in; the default is the current one.
+	Dir string
+	// Output, when set, receives the output of go test as it runs.
+	Output io.Writer
+}
+
+// Run runs the benchmarks with go test -benchmem and returns their snapshot.
+func Run(ctx context.Context, cfg RunConfig) (*Snapshot, error) {
+	if len(cfg.Packages) == 0 {
+		return nil, errors.New("perf: no packages to benchmark")
+	}
+	bench := cfg.Bench
+	if bench == "" {
+		bench = DefaultBench
+	}
+	count := cfg.Count
+	if count <= 0 {
+		count = DefaultCount
+	}
+	args := []string{"test", "-run", "^$", "-bench", bench, "-benchmem", "-count", strconv.Itoa(count)}
+	if cfg.Benchtime != "" {
+		args = append(args, "-benchtime", cfg.Benchtime)
+	}
+	args = append(args, cfg.Packages...)
+
+	var stdout, stderr strings.Builder
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = cfg.Dir
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if cfg.Output != nil {
+		cmd.Stdout = io.MultiWriter(&stdout, cfg.Output)
+		cmd.Stderr = io.MultiWriter(&stderr, cfg.Output)
+	}
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("perf: go test failed: %w\n%s%s", err, stdout.String(), stderr.String())
+	}
+
+	s, err := Parse(strings.NewReader(stdout.String()))
+	if err != nil {
+		return nil, err
+	}
+	if len(s.Benchmarks) == 0 {
+		return nil, fmt.Errorf("perf: no benchmark matched %q", bench)
+	}
+	s.GoVersion = runtime.Version()
+	return s, nil
+}
@@ -0,0 +1,189 @@
+package perf
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const output = `goos: linux
+goarch: amd64
+pkg: github.com/fsvxavier/nexs-lib/domainerrors
+cpu: Intel(R) Xeon(R) Processor
+BenchmarkNew-8        	 1000000	      1200 ns/op	     128 B/op	       2 allocs/op
+BenchmarkNew-8        	 1000000	      1250 ns/op	     128 B/op	       2 allocs/op
+BenchmarkParse/small-8	  500000	      2500 ns/op	  150.5 MB/s
+--- FAIL: TestSomething (0.00s)
+PASS
+ok  	github.com/fsvxavier/nexs-lib/domainerrors	3.2s
+pkg: github.com/fsvxavier/nexs-lib/strutil
+BenchmarkNew	 2000000	       600 ns/op
+`
+
+func TestParse(t *testing.T) {
+	s, err := Parse(strings.NewReader(output))
+	require.NoError(t, err)
+	assert.Equal(t, "linux", s.GOOS)
+	assert.Equal(t, "amd64", s.GOARCH)
+	assert.Equal(t, "Intel(R) Xeon(R) Processor", s.CPU)
+	require.Len(t, s.Benchmarks, 3)
+
+	b := s.Benchmark("github.com/fsvxavier/nexs-lib/domainerrors", "BenchmarkNew")
+	require.NotNil(t, b)
+	assert.Equal(t, map[string][]float64{"ns/op": {1200, 1250}, "B/op": {128, 128}, "allocs/op": {2, 2}}, b.Samples)
+	assert.Equal(t, []float64{150.5}, s.Benchmark("github.com/fsvxavier/nexs-lib/domainerrors", "BenchmarkParse/small").Samples["MB/s"])
+	assert.Equal(t, "github.com/fsvxavier/nexs-lib/strutil.BenchmarkNew", s.Benchmarks[2].ID())
+}
+
+func TestSummarize(t *testing.T) {
+	s := Summarize([]float64{110, 90, 100, 105})
+	assert.Equal(t, Summary{N: 4, Median: 102.5, Min: 90, Max: 110, Spread: 12.5 / 102.5}, s)
+	assert.Equal(t, 100.0, Summarize([]float64{100, 300, 90}).Median)
+	assert.Equal(t, Summary{}, Summarize(nil))
+}
+
+func TestMannWhitneyU(t *testing.T) {
+	low := []float64{1, 2, 3, 4, 5}
+	high := []float64{6, 7, 8, 9, 10}
+	// the most extreme of the 252 arrangements, on both sides
+	assert.InDelta(t, 2.0/252, MannWhitneyU(low, high), 1e-12)
+	assert.InDelta(t, 2.0/252, MannWhitneyU(high, low), 1e-12)
+	assert.InDelta(t, 14.0/20, MannWhitneyU([]float64{1, 3, 5}, []float64{2, 4, 6}), 1e-12)
+	assert.Equal(t, 1.0, MannWhitneyU([]float64{5, 5, 5}, []float64{5, 5, 5}))
+	assert.Greater(t, MannWhitneyU([]float64{1, 2, 3}, []float64{4, 5, 6}), 0.05, "3 samples are never significant")
+
+	ties := MannWhitneyU([]float64{2, 2, 2, 2, 2, 2}, []float64{3, 3, 3, 3, 3, 3})
+	assert.Less(t, ties, 0.01, "normal approximation with ties")
+
+	large := make([]float64, 60)
+	shifted := make([]float64, 60)
+	for i := range large {
+		large[i], shifted[i] = float64(i), float64(i)+0.5
+	}
+	assert.Greater(t, MannWhitneyU(large, shifted), 0.5)
+}
+
+func snapshot(benchmarks ...*Benchmark) *Snapshot {
+	return &Snapshot{Benchmarks: benchmarks}
+}
+
+func TestCompare(t *testing.T) {
+	base := snapshot(
+		&Benchmark{Name: "BenchmarkSlower", Samples: map[string][]float64{
+			"ns/op":     {100, 101, 99, 98, 102},
+			"allocs/op": {2, 2, 2, 2, 2},
+		}},
+		&Benchmark{Name: "BenchmarkNoisy", Samples: map[string][]float64{"ns/op": {100, 130, 90, 120, 95}}},
+		&Benchmark{Name: "BenchmarkThroughput", Samples: map[string][]float64{"MB/s": {100, 101, 99, 100, 102}}},
+		&Benchmark{Name: "BenchmarkRemoved", Samples: map[string][]float64{"ns/op": {1}}},
+	)
+	head := snapshot(
+		&Benchmark{Name: "BenchmarkSlower", Samples: map[string][]float64{
+			"ns/op":     {120, 121, 119, 122, 118},
+			"allocs/op": {3, 3, 3, 3, 3},
+		}},
+		&Benchmark{Name: "BenchmarkNoisy", Samples: map[string][]float64{"ns/op": {110, 98, 135, 92, 125}}},
+		&Benchmark{Name: "BenchmarkThroughput", Samples: map[string][]float64{"MB/s": {120, 121, 119, 122, 120}}},
+		&Benchmark{Name: "BenchmarkAdded", Samples: map[string][]float64{"ns/op": {1}}},
+	)
+
+	c := Compare(base, head)
+	require.Len(t, c.Deltas, 4)
+	slower := c.Deltas[0]
+	assert.Equal(t, "ns/op", slower.Unit)
+	assert.InDelta(t, 0.2, slower.Change, 1e-9)
+	assert.True(t, slower.Significant)
+	assert.True(t, slower.Regression)
+	assert.Equal(t, "allocs/op", c.Deltas[1].Unit)
+	assert.True(t, c.Deltas[1].Regression)
+	assert.False(t, c.Deltas[2].Significant, "noise is not a regression")
+	assert.True(t, c.Deltas[3].Improvement, "higher throughput is better")
+	assert.Len(t, c.Regressions(), 2)
+	assert.Equal(t, []string{"BenchmarkRemoved"}, c.Removed)
+	assert.Equal(t, []string{"BenchmarkAdded"}, c.Added)
+
+	relaxed := Compare(base, head, WithThreshold(0.3), WithUnitThreshold("allocs/op", 0.6))
+	assert.Empty(t, relaxed.Regressions())
+	strict := Compare(base, head, WithAlpha(0.001))
+	assert.Empty(t, strict.Regressions(), "5 samples cannot reach alpha 0.001")
+
+	var text strings.Builder
+	require.NoError(t, c.WriteText(&text))
+	assert.Equal(t, 1, strings.Count(text.String(), "ns/op "), "one section per unit")
+	assert.Contains(t, text.String(), "BenchmarkSlower")
+	assert.Contains(t, text.String(), "+20.00% (p=0.008 n=5+5)")
+	assert.Contains(t, text.String(), "REGRESSION")
+	assert.Contains(t, text.String(), "~ (p=")
+	assert.Contains(t, text.String(), "removed: BenchmarkRemoved")
+
+	_, err := json.Marshal(c)
+	require.NoError(t, err)
+}
+
+func TestCompare_FromZero(t *testing.T) {
+	base := snapshot(&Benchmark{Name: "BenchmarkNoAlloc", Samples: map[string][]float64{"allocs/op": {0, 0, 0, 0, 0}}})
+	head := snapshot(&Benchmark{Name: "BenchmarkNoAlloc", Samples: map[string][]float64{"allocs/op": {1, 1, 1, 1, 1}}})
+	c := Compare(base, head)
+	require.Len(t, c.Regressions(), 1)
+	assert.Equal(t, 1.0, c.Deltas[0].Change)
+}
+
+func TestCheckLimits(t *testing.T) {
+	s, err := Parse(strings.NewReader(output))
+	require.NoError(t, err)
+
+	violations := s.CheckLimits([]Limit{
+		{Benchmark: "BenchmarkNew", Unit: "allocs/op", Max: 2},
+		{Benchmark: "BenchmarkNew", Unit: "ns/op", Max: 1000},
+		{Benchmark: "BenchmarkParse/*", Unit: "B/op", Max: 0},
+	})
+	require.Len(t, violations, 2)
+	assert.Equal(t, "github.com/fsvxavier/nexs-lib/domainerrors.BenchmarkNew: 1225 ns/op exceeds 1000", violations[0].String())
+	assert.True(t, violations[1].Missing)
+	assert.Equal(t, "BenchmarkParse/*: no benchmark reports B/op", violations[1].String())
+}
+
+func TestLoadAndSave(t *testing.T) {
+	dir := t.TempDir()
+	text := filepath.Join(dir, "bench.txt")
+	require.NoError(t, os.WriteFile(text, []byte(output), 0o644))
+	fromText, err := Load(text)
+	require.NoError(t, err)
+	require.Len(t, fromText.Benchmarks, 3)
+
+	path := filepath.Join(dir, "perf", "baseline.json")
+	require.NoError(t, fromText.Save(path))
+	fromJSON, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, fromText.Benchmarks, fromJSON.Benchmarks)
+
+	limits := filepath.Join(dir, "limits.json")
+	require.NoError(t, os.WriteFile(limits, []byte(`[{"benchmark": "BenchmarkNew", "unit": "allocs/op", "max": 2}]`), 0o644))
+	loaded, err := LoadLimits(limits)
+	require.NoError(t, err)
+	assert.Equal(t, []Limit{{Benchmark: "BenchmarkNew", Unit: "allocs/op", Max: 2}}, loaded)
+}
+
+func TestRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go test")
+	}
+	s, err := Run(context.Background(), RunConfig{Packages: []string{"./testdata/bench"}, Count: 2, Benchtime: "10x"})
+	require.NoError(t, err)
+	b := s.Benchmark("github.com/fsvxavier/nexs-lib/testing/perf/testdata/bench", "BenchmarkRepeat")
+	require.NotNil(t, b)
+	assert.Len(t, b.Samples["ns/op"], 2)
+	assert.Equal(t, []float64{1, 1}, b.Samples["allocs/op"])
+	assert.NotEmpty(t, s.GoVersion)
+
+	_, err = Run(context.Background(), RunConfig{Packages: []string{"./testdata/bench"}, Bench: "Missing", Count: 1})
+	assert.ErrorContains(t, err, "no benchmark matched")
+	_, err = Run(context.Background(), RunConfig{})
+	assert.Error(t, err)
+}
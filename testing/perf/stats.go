@@ -0,0 +1,136 @@
+package perf
+
+import (
+	"math"
+	"slices"
+)
+
+// Summary summarizes the samples of a benchmark in a unit.
+type Summary struct {
+	N      int     `json:"n"`
+	Median float64 `json:"median"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	// Spread is the largest deviation of a sample from the median, as a
+	// fraction of the median, as the ± of benchstat.
+	Spread float64 `json:"spread"`
+}
+
+// Summarize returns the summary of the samples.
+func Summarize(samples []float64) Summary {
+	if len(samples) == 0 {
+		return Summary{}
+	}
+	sorted := slices.Sorted(slices.Values(samples))
+	n := len(sorted)
+	s := Summary{N: n, Min: sorted[0], Max: sorted[n-1], Median: sorted[n/2]}
+	if n%2 == 0 {
+		s.Median = (sorted[n/2-1] + sorted[n/2]) / 2
+	}
+	if s.Median != 0 {
+		s.Spread = max(s.Median-s.Min, s.Max-s.Median) / math.Abs(s.Median)
+	}
+	return s
+}
+
+// exactLimit is the largest sample size of the exact distribution of the
+// Mann-Whitney U statistic; larger samples use the normal approximation.
+const exactLimit = 50
+
+// MannWhitneyU returns the two-sided p-value of the Mann-Whitney U test of
+// the samples: the probability of differences at least as large between
+// samples of the same distribution. It is exact for samples without ties of
+// up to 50 values, as the counts of benchmarks are, and uses the normal
+// approximation with tie correction otherwise.
+func MannWhitneyU(a, b []float64) float64 {
+	m, n := len(a), len(b)
+	if m == 0 || n == 0 {
+		return 1
+	}
+
+	// U of a: the number of pairs where a wins, ties counting half
+	var u float64
+	for _, x := range a {
+		for _, y := range b {
+			switch {
+			case x > y:
+				u++
+			case x == y:
+				u += 0.5
+			}
+		}
+	}
+
+	ties := tieCounts(a, b)
+	if len(ties) == 0 && m <= exactLimit && n <= exactLimit {
+		return exactP(m, n, u)
+	}
+	return normalP(m, n, u, ties)
+}
+
+// tieCounts returns the sizes of the groups of equal values of the samples.
+func tieCounts(a, b []float64) []int {
+	all := slices.Sorted(slices.Values(slices.Concat(a, b)))
+	var ties []int
+	for i := 0; i < len(all); {
+		j := i + 1
+		for j < len(all) && all[j] == all[i] {
+			j++
+		}
+		if j-i > 1 {
+			ties = append(ties, j-i)
+		}
+		i = j
+	}
+	return ties
+}
+
+// exactP returns the two-sided p-value of U from its exact distribution.
+// The number of arrangements of m and n values with each U are the
+// coefficients of the Gaussian binomial [m+n choose m], the product of
+// (1 - q^(n+i)) / (1 - q^i) for i in 1..m.
+func exactP(m, n int, u float64) float64 {
+	size := m*n + 1
+	counts := make([]float64, size)
+	counts[0] = 1
+	for i := 1; i <= m; i++ {
+		// multiply by 1 - q^(n+i), then divide by 1 - q^i
+		for k := size - 1; k >= n+i; k-- {
+			counts[k] -= counts[k-n-i]
+		}
+		for k := i; k < size; k++ {
+			counts[k] += counts[k-i]
+		}
+	}
+
+	var total, below, above float64
+	for k, c := range counts {
+		total += c
+		if float64(k) <= u {
+			below += c
+		}
+		if float64(k) >= u {
+			above += c
+		}
+	}
+	return min(1, 2*min(below, above)/total)
+}
+
+// normalP returns the two-sided p-value of U from the normal approximation,
+// with continuity and tie corrections.
+func normalP(m, n int, u float64, ties []int) float64 {
+	mn, total := float64(m*n), float64(m+n)
+	var correction float64
+	for _, t := range ties {
+		correction += float64(t*t*t - t)
+	}
+	variance := mn / 12 * (total + 1 - correction/(total*(total-1)))
+	if variance <= 0 {
+		return 1
+	}
+	z := (math.Abs(u-mn/2) - 0.5) / math.Sqrt(variance)
+	if z <= 0 {
+		return 1
+	}
+	return min(1, math.Erfc(z/math.Sqrt2))
+}
@@ -0,0 +1,14 @@
+package bench
+
+import (
+	"strings"
+	"testing"
+)
+
+var sink string
+
+func BenchmarkRepeat(b *testing.B) {
+	for b.Loop() {
+		sink = strings.Repeat("x", 64)
+	}
+}
@@ -0,0 +1,150 @@
+package tlsutil
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/advanced"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+)
+
+// meterName identifies the instruments created by the reloader.
+const meterName = "github.com/fsvxavier/nexs-lib/tlsutil"
+
+// Metric names.
+const (
+	MetricExpiry  = "tls.certificate.expiry"
+	MetricReloads = "tls.certificate.reloads"
+)
+
+// Attributes of the metrics.
+const (
+	AttrName    = "tls.certificate.name"
+	AttrSubject = "tls.certificate.subject"
+	AttrOutcome = "outcome"
+)
+
+// Codes of the expiry alerts.
+const (
+	CodeCertificateExpiring = "TLS_CERTIFICATE_EXPIRING"
+	CodeCertificateExpired  = "TLS_CERTIFICATE_EXPIRED"
+)
+
+// reloaderMetrics are the instruments of a reloader. Its methods accept a
+// nil receiver, for reloaders without metrics.
+type reloaderMetrics struct {
+	reloads      metric.Int64Counter
+	registration metric.Registration
+}
+
+// newReloaderMetrics creates the instruments with the meter provider of r.
+func newReloaderMetrics(r *Reloader) (*reloaderMetrics, error) {
+	meter := r.meterProvider.Meter(meterName)
+	m := &reloaderMetrics{}
+
+	var err error
+	if m.reloads, err = meter.Int64Counter(MetricReloads,
+		metric.WithDescription("Certificate reloads by outcome"), metric.WithUnit("{reload}")); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", MetricReloads, err)
+	}
+	expiry, err := meter.Float64ObservableGauge(MetricExpiry,
+		metric.WithDescription("Time left before the current certificate expires"), metric.WithUnit("s"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", MetricExpiry, err)
+	}
+	if m.registration, err = meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		b := r.current.Load()
+		if b == nil {
+			return nil
+		}
+		o.ObserveFloat64(expiry, r.clock.Until(b.cert.Leaf.NotAfter).Seconds(), metric.WithAttributes(
+			attribute.String(AttrName, r.name), attribute.String(AttrSubject, b.cert.Leaf.Subject.String())))
+		return nil
+	}, expiry); err != nil {
+		return nil, fmt.Errorf("failed to register %s: %w", MetricExpiry, err)
+	}
+	return m, nil
+}
+
+// reloaded counts a reload that swapped the certificate or failed.
+func (m *reloaderMetrics) reloaded(ctx context.Context, r *Reloader, ok bool) {
+	if m == nil {
+		return
+	}
+	outcome := "success"
+	if !ok {
+		outcome = "failure"
+	}
+	m.reloads.Add(ctx, 1, metric.WithAttributes(attribute.String(AttrName, r.name), attribute.String(AttrOutcome, outcome)))
+}
+
+// close unregisters the expiry gauge.
+func (m *reloaderMetrics) close() error {
+	if m == nil || m.registration == nil {
+		return nil
+	}
+	return m.registration.Unregister()
+}
+
+// checkExpiry sends an alert to the sinks when the certificate entered a
+// more severe expiry window than the last alert of the same certificate.
+// Renewed certificates start over.
+func (r *Reloader) checkExpiry(ctx context.Context, b *bundle) {
+	if len(r.sinks) == 0 {
+		return
+	}
+
+	leaf := b.cert.Leaf
+	remaining := r.clock.Until(leaf.NotAfter)
+	var severity domainerrors.Severity
+	switch {
+	case remaining <= 0:
+		severity = domainerrors.SeverityCritical
+	case remaining <= r.critical:
+		severity = domainerrors.SeverityHigh
+	case remaining <= r.warning:
+		severity = domainerrors.SeverityMedium
+	default:
+		return
+	}
+	if severity <= r.alerted[b.fingerprint] {
+		return
+	}
+	r.alerted[b.fingerprint] = severity
+
+	code := CodeCertificateExpiring
+	message := fmt.Sprintf("TLS certificate %s (%s) expires in %s, at %s", r.name, leaf.Subject,
+		remaining.Round(time.Minute), leaf.NotAfter.UTC().Format(time.RFC3339))
+	if remaining <= 0 {
+		code = CodeCertificateExpired
+		message = fmt.Sprintf("TLS certificate %s (%s) expired at %s", r.name, leaf.Subject,
+			leaf.NotAfter.UTC().Format(time.RFC3339))
+	}
+	err := domainerrors.NewWithMetadata(interfaces.SecurityError, code, message, map[string]interface{}{
+		"certificate": r.name,
+		"subject":     leaf.Subject.String(),
+		"not_after":   leaf.NotAfter.UTC().Format(time.RFC3339),
+		"fingerprint": b.fingerprint,
+	})
+
+	alert := advanced.Alert{
+		Fingerprint:  b.fingerprint,
+		Code:         code,
+		Type:         interfaces.SecurityError,
+		BaseSeverity: severity,
+		Severity:     severity,
+		Count:        1,
+		Error:        err,
+		At:           r.clock.Now(),
+	}
+	for _, sink := range r.sinks {
+		if sinkErr := sink.Alert(ctx, alert); sinkErr != nil && r.onError != nil {
+			r.onError(fmt.Errorf("tlsutil: expiry alert of %s: %w", r.name, sinkErr))
+		}
+	}
+}
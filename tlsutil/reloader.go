@@ -0,0 +1,331 @@
+// Package tlsutil manages TLS and mTLS certificates with hot reload. A
+// Reloader loads the certificate, key and CA from files or a secret
+// provider, checks them periodically for renewals and swaps them without
+// restarting: servers and clients read the current certificate through the
+// GetCertificate and GetClientCertificate callbacks, and verify peers with
+// the current CA. The time left before expiry is reported as a metric, and
+// certificates close to expiry raise alerts through the alert sinks of
+// domainerrors/advanced.
+package tlsutil
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/advanced"
+)
+
+// Defaults of the reloader.
+const (
+	DefaultName           = "default"
+	DefaultInterval       = time.Minute
+	DefaultExpiryWarning  = 30 * 24 * time.Hour
+	DefaultExpiryCritical = 7 * 24 * time.Hour
+)
+
+// defaultMinTLSVersion is the minimum version of the generated configs.
+const defaultMinTLSVersion = tls.VersionTLS12
+
+// ErrInvalidCertificate is returned when the loaded material cannot be
+// used; the current certificate is kept.
+var ErrInvalidCertificate = errors.New("tlsutil: invalid certificate")
+
+// Option configures a Reloader.
+type Option func(*Reloader)
+
+// WithName names the certificate in metrics, alerts and errors. Defaults
+// to DefaultName.
+func WithName(name string) Option {
+	return func(r *Reloader) {
+		if name != "" {
+			r.name = name
+		}
+	}
+}
+
+// WithInterval sets the interval between checks of the source in Run.
+// Defaults to DefaultInterval.
+func WithInterval(interval time.Duration) Option {
+	return func(r *Reloader) {
+		if interval > 0 {
+			r.interval = interval
+		}
+	}
+}
+
+// WithClock sets the clock of the checks and of the expiry. Defaults to the
+// real clock.
+func WithClock(c clock.Clock) Option {
+	return func(r *Reloader) {
+		r.clock = c
+	}
+}
+
+// WithErrorHandler sets the handler of the reload errors of Run. The
+// current certificate is kept when a reload fails.
+func WithErrorHandler(fn func(error)) Option {
+	return func(r *Reloader) {
+		r.onError = fn
+	}
+}
+
+// WithOnReload sets a function called after a new certificate is swapped
+// in, e.g. to log its expiry.
+func WithOnReload(fn func(cert *x509.Certificate)) Option {
+	return func(r *Reloader) {
+		r.onReload = fn
+	}
+}
+
+// WithMeterProvider reports the expiry and the reloads with the meter
+// provider. Without it, no metrics are recorded.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(r *Reloader) {
+		r.meterProvider = provider
+	}
+}
+
+// WithAlertSink adds a destination for the expiry alerts.
+func WithAlertSink(sink advanced.AlertSink) Option {
+	return func(r *Reloader) {
+		if sink != nil {
+			r.sinks = append(r.sinks, sink)
+		}
+	}
+}
+
+// WithExpiryAlerts sets how long before expiry the alerts are raised: a
+// SeverityMedium alert within warning and a SeverityHigh one within
+// critical. Expired certificates raise a SeverityCritical alert. Defaults
+// to DefaultExpiryWarning and DefaultExpiryCritical.
+func WithExpiryAlerts(warning, critical time.Duration) Option {
+	return func(r *Reloader) {
+		r.warning, r.critical = warning, critical
+	}
+}
+
+// bundle is a loaded certificate.
+type bundle struct {
+	material    Material
+	cert        *tls.Certificate
+	ca          *x509.CertPool
+	fingerprint string
+}
+
+// Reloader holds the current certificate of a Source and reloads it.
+type Reloader struct {
+	source            Source
+	name              string
+	interval          time.Duration
+	clock             clock.Clock
+	onError           func(error)
+	onReload          func(cert *x509.Certificate)
+	sinks             []advanced.AlertSink
+	warning, critical time.Duration
+	meterProvider     metric.MeterProvider
+	metrics           *reloaderMetrics
+
+	current atomic.Pointer[bundle]
+
+	// reloadMu serializes the reloads; alerted holds the highest severity
+	// alerted for each certificate fingerprint.
+	reloadMu sync.Mutex
+	alerted  map[string]domainerrors.Severity
+}
+
+// New creates a Reloader and loads the certificate, failing when it cannot
+// be loaded.
+func New(ctx context.Context, source Source, opts ...Option) (*Reloader, error) {
+	r := &Reloader{
+		source:   source,
+		name:     DefaultName,
+		interval: DefaultInterval,
+		warning:  DefaultExpiryWarning,
+		critical: DefaultExpiryCritical,
+		alerted:  make(map[string]domainerrors.Severity),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	r.clock = clock.OrReal(r.clock)
+
+	if r.meterProvider != nil {
+		m, err := newReloaderMetrics(r)
+		if err != nil {
+			return nil, err
+		}
+		r.metrics = m
+	}
+	if err := r.Reload(ctx); err != nil {
+		r.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload loads the source and swaps the certificate when it changed, then
+// checks its expiry. Invalid material returns an error wrapping
+// ErrInvalidCertificate and keeps the current certificate.
+func (r *Reloader) Reload(ctx context.Context) error {
+	r.reloadMu.Lock()
+	defer r.reloadMu.Unlock()
+
+	material, err := r.source.Load(ctx)
+	if err != nil {
+		r.metrics.reloaded(ctx, r, false)
+		return err
+	}
+
+	current := r.current.Load()
+	if current == nil || !current.material.equal(material) {
+		b, err := parse(material)
+		if err != nil {
+			r.metrics.reloaded(ctx, r, false)
+			return fmt.Errorf("%w %s: %v", ErrInvalidCertificate, r.name, err)
+		}
+		r.current.Store(b)
+		current = b
+		r.metrics.reloaded(ctx, r, true)
+		if r.onReload != nil {
+			r.onReload(b.cert.Leaf)
+		}
+	}
+
+	r.checkExpiry(ctx, current)
+	return nil
+}
+
+// parse validates the material: the key must match the certificate and the
+// CA, when set, must hold PEM certificates.
+func parse(m Material) (*bundle, error) {
+	cert, err := tls.X509KeyPair(m.Cert, m.Key)
+	if err != nil {
+		return nil, err
+	}
+	if cert.Leaf == nil {
+		if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+			return nil, err
+		}
+	}
+
+	b := &bundle{material: m, cert: &cert}
+	if len(m.CA) > 0 {
+		b.ca = x509.NewCertPool()
+		if !b.ca.AppendCertsFromPEM(m.CA) {
+			return nil, errors.New("CA has no PEM certificates")
+		}
+	}
+	sum := sha256.Sum256(cert.Leaf.Raw)
+	b.fingerprint = hex.EncodeToString(sum[:])
+	return b, nil
+}
+
+// Run checks the source at the interval until ctx is done, reporting the
+// errors to the error handler.
+func (r *Reloader) Run(ctx context.Context) error {
+	ticker := r.clock.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C():
+			if err := r.Reload(ctx); err != nil && r.onError != nil {
+				r.onError(err)
+			}
+		}
+	}
+}
+
+// Certificate returns the current leaf certificate.
+func (r *Reloader) Certificate() *x509.Certificate {
+	return r.current.Load().cert.Leaf
+}
+
+// GetCertificate returns the current certificate; it is the
+// tls.Config.GetCertificate callback of servers.
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.current.Load().cert, nil
+}
+
+// GetClientCertificate returns the current certificate; it is the
+// tls.Config.GetClientCertificate callback of clients.
+func (r *Reloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return r.current.Load().cert, nil
+}
+
+// ServerConfig returns a server TLS configuration using the current
+// certificate. With a CA, clients must present a certificate signed by it
+// (mTLS); the current CA is used for each handshake.
+func (r *Reloader) ServerConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: defaultMinTLSVersion,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			b := r.current.Load()
+			config := &tls.Config{
+				MinVersion:   defaultMinTLSVersion,
+				Certificates: []tls.Certificate{*b.cert},
+			}
+			if b.ca != nil {
+				config.ClientCAs = b.ca
+				config.ClientAuth = tls.RequireAndVerifyClientCert
+			}
+			return config, nil
+		},
+	}
+}
+
+// ClientConfig returns a client TLS configuration presenting the current
+// certificate to servers requesting one, and verifying servers with the
+// current CA, or the system roots without one. The server name is checked
+// against tls.Config.ServerName, set by most clients from the address;
+// handshakes without a server name fail, since any certificate of the CA
+// would be accepted.
+func (r *Reloader) ClientConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:           defaultMinTLSVersion,
+		GetClientCertificate: r.GetClientCertificate,
+		// the chain is verified by VerifyConnection, with the current CA
+		InsecureSkipVerify: true,
+		VerifyConnection:   r.verifyServer,
+	}
+}
+
+// verifyServer verifies the certificate chain and the name of the server.
+func (r *Reloader) verifyServer(cs tls.ConnectionState) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("tlsutil: server presented no certificate")
+	}
+	if cs.ServerName == "" {
+		return errors.New("tlsutil: server name not set, set tls.Config.ServerName")
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range cs.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+		Roots:         r.current.Load().ca,
+		Intermediates: intermediates,
+		DNSName:       cs.ServerName,
+		CurrentTime:   r.clock.Now(),
+	})
+	return err
+}
+
+// Close stops reporting the metrics.
+func (r *Reloader) Close() error {
+	return r.metrics.close()
+}
@@ -0,0 +1,113 @@
+package tlsutil
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+)
+
+// Material is the PEM encoded certificate chain, private key and CA
+// certificates loaded from a Source.
+type Material struct {
+	// Cert is the certificate chain, leaf first.
+	Cert []byte
+	// Key is the private key of the leaf certificate.
+	Key []byte
+	// CA holds the certificate authorities verifying peers: client
+	// certificates on servers, server certificates on clients. Empty uses
+	// the system roots on clients and disables client authentication on
+	// servers.
+	CA []byte
+}
+
+// equal reports whether both materials hold the same bytes.
+func (m Material) equal(other Material) bool {
+	return bytes.Equal(m.Cert, other.Cert) && bytes.Equal(m.Key, other.Key) && bytes.Equal(m.CA, other.CA)
+}
+
+// Source loads the TLS material. Reloader calls it on every check, so it
+// should be cheap when nothing changed.
+type Source interface {
+	Load(ctx context.Context) (Material, error)
+}
+
+// SourceFunc adapts a function to Source.
+type SourceFunc func(ctx context.Context) (Material, error)
+
+// Load implements Source.
+func (f SourceFunc) Load(ctx context.Context) (Material, error) {
+	return f(ctx)
+}
+
+// FileSource loads the material from PEM files, such as those mounted from
+// Kubernetes secrets or written by cert-manager and certbot.
+type FileSource struct {
+	CertFile string
+	KeyFile  string
+	// CAFile is optional.
+	CAFile string
+}
+
+// Load implements Source.
+func (s FileSource) Load(ctx context.Context) (Material, error) {
+	var m Material
+	for _, f := range []struct {
+		path   string
+		target *[]byte
+	}{
+		{s.CertFile, &m.Cert},
+		{s.KeyFile, &m.Key},
+		{s.CAFile, &m.CA},
+	} {
+		if f.path == "" {
+			continue
+		}
+		data, err := os.ReadFile(f.path)
+		if err != nil {
+			return Material{}, fmt.Errorf("tlsutil: read %s: %w", f.path, err)
+		}
+		*f.target = data
+	}
+	return m, nil
+}
+
+// SecretResolver resolves secret references; *config.Secrets implements it.
+type SecretResolver interface {
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// SecretSource loads the material from a secret provider, with references
+// such as secretref://vault/secret/data/tls#cert. Renewals are picked up
+// when the resolver fetches the secrets again: with config.WithSecretTTL or
+// config.Secrets.Watch.
+type SecretSource struct {
+	Resolver SecretResolver
+	CertRef  string
+	KeyRef   string
+	// CARef is optional.
+	CARef string
+}
+
+// Load implements Source.
+func (s SecretSource) Load(ctx context.Context) (Material, error) {
+	var m Material
+	for _, r := range []struct {
+		ref    string
+		target *[]byte
+	}{
+		{s.CertRef, &m.Cert},
+		{s.KeyRef, &m.Key},
+		{s.CARef, &m.CA},
+	} {
+		if r.ref == "" {
+			continue
+		}
+		value, err := s.Resolver.Resolve(ctx, r.ref)
+		if err != nil {
+			return Material{}, fmt.Errorf("tlsutil: resolve %s: %w", r.ref, err)
+		}
+		*r.target = []byte(value)
+	}
+	return m, nil
+}
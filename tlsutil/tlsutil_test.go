@@ -0,0 +1,374 @@
+package tlsutil
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/clock"
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/advanced"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/fsvxavier/nexs-lib/observability/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// authority signs the test certificates.
+type authority struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newAuthority(t *testing.T) *authority {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "test CA"},
+		NotBefore: time.Now().Add(-time.Hour), NotAfter: time.Now().Add(365 * 24 * time.Hour),
+		IsCA: true, BasicConstraintsValid: true, KeyUsage: x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &authority{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns a PEM certificate for name, valid for validity, and its key.
+func (a *authority) issue(t *testing.T, name string, validity time.Duration) (cert, key []byte) {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: serial, Subject: pkix.Name{CommonName: name}, DNSNames: []string{name},
+		NotBefore: time.Now().Add(-time.Hour), NotAfter: time.Now().Add(validity),
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}, a.cert, &priv.PublicKey, a.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(priv)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// write issues a certificate for name and writes it, its key and the CA to
+// dir, returning the FileSource reading them.
+func (a *authority) write(t *testing.T, dir, name string, validity time.Duration) FileSource {
+	t.Helper()
+	cert, key := a.issue(t, name, validity)
+	source := FileSource{
+		CertFile: filepath.Join(dir, "tls.crt"),
+		KeyFile:  filepath.Join(dir, "tls.key"),
+		CAFile:   filepath.Join(dir, "ca.crt"),
+	}
+	require.NoError(t, os.WriteFile(source.CertFile, cert, 0o600))
+	require.NoError(t, os.WriteFile(source.KeyFile, key, 0o600))
+	require.NoError(t, os.WriteFile(source.CAFile, a.pem, 0o600))
+	return source
+}
+
+func TestReloader_HotSwap(t *testing.T) {
+	ca := newAuthority(t)
+	dir := t.TempDir()
+	source := ca.write(t, dir, "v1.internal", time.Hour)
+
+	var reloaded []string
+	r, err := New(context.Background(), source, WithOnReload(func(cert *x509.Certificate) {
+		reloaded = append(reloaded, cert.Subject.CommonName)
+	}))
+	require.NoError(t, err)
+	defer r.Close()
+	assert.Equal(t, "v1.internal", r.Certificate().Subject.CommonName)
+
+	// unchanged material is not swapped
+	require.NoError(t, r.Reload(context.Background()))
+	assert.Equal(t, []string{"v1.internal"}, reloaded)
+
+	ca.write(t, dir, "v2.internal", time.Hour)
+	require.NoError(t, r.Reload(context.Background()))
+	cert, err := r.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "v2.internal", cert.Leaf.Subject.CommonName)
+	cert, err = r.GetClientCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "v2.internal", cert.Leaf.Subject.CommonName)
+	assert.Equal(t, []string{"v1.internal", "v2.internal"}, reloaded)
+}
+
+func TestReloader_InvalidMaterialKeepsCurrent(t *testing.T) {
+	ca := newAuthority(t)
+	dir := t.TempDir()
+	source := ca.write(t, dir, "orders.internal", time.Hour)
+	r, err := New(context.Background(), source)
+	require.NoError(t, err)
+	defer r.Close()
+
+	// a renewal caught halfway: new certificate, old key
+	cert, _ := ca.issue(t, "orders.internal", time.Hour)
+	require.NoError(t, os.WriteFile(source.CertFile, cert, 0o600))
+	assert.ErrorIs(t, r.Reload(context.Background()), ErrInvalidCertificate)
+
+	require.NoError(t, os.Remove(source.KeyFile))
+	assert.ErrorIs(t, r.Reload(context.Background()), os.ErrNotExist)
+	assert.Equal(t, ca.cert.Subject.CommonName, r.Certificate().Issuer.CommonName)
+	assert.NotEqual(t, cert, r.current.Load().material.Cert)
+
+	_, err = New(context.Background(), source)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	require.NoError(t, os.WriteFile(source.KeyFile, []byte("not a key"), 0o600))
+	_, err = New(context.Background(), source)
+	assert.ErrorIs(t, err, ErrInvalidCertificate)
+}
+
+// handshake runs a TLS handshake between both configs over an in-memory
+// connection, returning the client and server errors.
+func handshake(client, server *tls.Config) (clientErr, serverErr error) {
+	c, s := net.Pipe()
+	defer c.Close()
+	defer s.Close()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		serverErr = tls.Server(s, server).Handshake()
+		s.Close()
+	}()
+	clientErr = tls.Client(c, client).Handshake()
+	c.Close()
+	wg.Wait()
+	return clientErr, serverErr
+}
+
+func TestReloader_MutualTLS(t *testing.T) {
+	ca := newAuthority(t)
+	serverDir, clientDir := t.TempDir(), t.TempDir()
+	server, err := New(context.Background(), ca.write(t, serverDir, "orders.internal", time.Hour))
+	require.NoError(t, err)
+	defer server.Close()
+	client, err := New(context.Background(), ca.write(t, clientDir, "billing.internal", time.Hour))
+	require.NoError(t, err)
+	defer client.Close()
+
+	clientConfig := client.ClientConfig()
+	clientConfig.ServerName = "orders.internal"
+	clientErr, serverErr := handshake(clientConfig, server.ServerConfig())
+	require.NoError(t, clientErr)
+	require.NoError(t, serverErr)
+
+	// the server name is verified
+	clientConfig.ServerName = "payments.internal"
+	clientErr, _ = handshake(clientConfig, server.ServerConfig())
+	assert.Error(t, clientErr)
+
+	// a missing server name is refused, not skipped
+	clientConfig.ServerName = ""
+	clientErr, _ = handshake(clientConfig, server.ServerConfig())
+	require.Error(t, clientErr)
+	assert.Contains(t, clientErr.Error(), "server name not set")
+
+	// a client of another CA is rejected, and trusted after the server
+	// reloads a bundle with both CAs
+	other := newAuthority(t)
+	otherDir := t.TempDir()
+	otherClient, err := New(context.Background(), other.write(t, otherDir, "billing.internal", time.Hour))
+	require.NoError(t, err)
+	defer otherClient.Close()
+	otherConfig := otherClient.ClientConfig()
+	otherConfig.ServerName = "orders.internal"
+	require.NoError(t, os.WriteFile(filepath.Join(otherDir, "ca.crt"), append(append([]byte{}, other.pem...), ca.pem...), 0o600))
+	require.NoError(t, otherClient.Reload(context.Background()))
+
+	_, serverErr = handshake(otherConfig, server.ServerConfig())
+	assert.Error(t, serverErr)
+
+	require.NoError(t, os.WriteFile(filepath.Join(serverDir, "ca.crt"), append(append([]byte{}, ca.pem...), other.pem...), 0o600))
+	require.NoError(t, server.Reload(context.Background()))
+	clientErr, serverErr = handshake(otherConfig, server.ServerConfig())
+	assert.NoError(t, clientErr)
+	assert.NoError(t, serverErr)
+}
+
+func TestReloader_ExpiryAlerts(t *testing.T) {
+	ca := newAuthority(t)
+	dir := t.TempDir()
+	source := ca.write(t, dir, "orders.internal", 40*24*time.Hour)
+	fake := clock.NewFake(time.Now())
+
+	var alerts []advanced.Alert
+	r, err := New(context.Background(), source, WithName("orders"), WithClock(fake),
+		WithExpiryAlerts(30*24*time.Hour, 7*24*time.Hour),
+		WithAlertSink(advanced.AlertSinkFunc(func(_ context.Context, alert advanced.Alert) error {
+			alerts = append(alerts, alert)
+			return nil
+		})))
+	require.NoError(t, err)
+	defer r.Close()
+	assert.Empty(t, alerts)
+
+	reloadAfter := func(d time.Duration) {
+		fake.Advance(d)
+		require.NoError(t, r.Reload(context.Background()))
+	}
+	reloadAfter(15 * 24 * time.Hour)
+	reloadAfter(time.Hour)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, domainerrors.SeverityMedium, alerts[0].Severity)
+	assert.Equal(t, CodeCertificateExpiring, alerts[0].Code)
+	assert.Equal(t, interfaces.SecurityError, alerts[0].Type)
+	assert.Equal(t, "orders", alerts[0].Error.Metadata()["certificate"])
+
+	reloadAfter(20 * 24 * time.Hour)
+	require.Len(t, alerts, 2)
+	assert.Equal(t, domainerrors.SeverityHigh, alerts[1].Severity)
+
+	reloadAfter(5 * 24 * time.Hour)
+	require.Len(t, alerts, 3)
+	assert.Equal(t, domainerrors.SeverityCritical, alerts[2].Severity)
+	assert.Equal(t, CodeCertificateExpired, alerts[2].Code)
+
+	// a renewed certificate starts over
+	ca.write(t, dir, "orders.internal", 100*24*time.Hour)
+	reloadAfter(time.Hour)
+	assert.Len(t, alerts, 3)
+}
+
+func TestReloader_AlertSinkErrors(t *testing.T) {
+	ca := newAuthority(t)
+	var errs []error
+	r, err := New(context.Background(), ca.write(t, t.TempDir(), "orders.internal", time.Hour),
+		WithErrorHandler(func(err error) { errs = append(errs, err) }),
+		WithAlertSink(advanced.AlertSinkFunc(func(context.Context, advanced.Alert) error {
+			return errors.New("pager down")
+		})))
+	require.NoError(t, err)
+	defer r.Close()
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "pager down")
+}
+
+func TestReloader_Run(t *testing.T) {
+	ca := newAuthority(t)
+	dir := t.TempDir()
+	fake := clock.NewFake(time.Now())
+	reloaded := make(chan string, 2)
+	r, err := New(context.Background(), ca.write(t, dir, "v1.internal", time.Hour),
+		WithClock(fake), WithInterval(time.Minute),
+		WithOnReload(func(cert *x509.Certificate) { reloaded <- cert.Subject.CommonName }))
+	require.NoError(t, err)
+	defer r.Close()
+	assert.Equal(t, "v1.internal", <-reloaded)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- r.Run(ctx) }()
+
+	fake.BlockUntil(1)
+	ca.write(t, dir, "v2.internal", time.Hour)
+	fake.Advance(time.Minute)
+	select {
+	case name := <-reloaded:
+		assert.Equal(t, "v2.internal", name)
+	case <-time.After(5 * time.Second):
+		t.Fatal("certificate not reloaded")
+	}
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestReloader_Metrics(t *testing.T) {
+	ca := newAuthority(t)
+	dir := t.TempDir()
+	source := ca.write(t, dir, "orders.internal", 10*time.Hour)
+	p := metrics.NewProvider()
+	defer p.Shutdown(context.Background())
+	r, err := New(context.Background(), source, WithName("orders"), WithMeterProvider(p.MeterProvider()))
+	require.NoError(t, err)
+	defer r.Close()
+
+	require.NoError(t, os.WriteFile(source.KeyFile, []byte("not a key"), 0o600))
+	require.Error(t, r.Reload(context.Background()))
+
+	rm, err := p.Collect(context.Background())
+	require.NoError(t, err)
+	found := map[string]metricdata.Aggregation{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			found[m.Name] = m.Data
+		}
+	}
+
+	gauge, ok := found[MetricExpiry].(metricdata.Gauge[float64])
+	require.True(t, ok)
+	require.Len(t, gauge.DataPoints, 1)
+	assert.InDelta(t, (10 * time.Hour).Seconds(), gauge.DataPoints[0].Value, 60)
+	name, _ := gauge.DataPoints[0].Attributes.Value(AttrName)
+	assert.Equal(t, "orders", name.AsString())
+
+	sum, ok := found[MetricReloads].(metricdata.Sum[int64])
+	require.True(t, ok)
+	outcomes := map[string]int64{}
+	for _, dp := range sum.DataPoints {
+		v, _ := dp.Attributes.Value(attribute.Key(AttrOutcome))
+		outcomes[v.AsString()] += dp.Value
+	}
+	assert.Equal(t, map[string]int64{"success": 1, "failure": 1}, outcomes)
+}
+
+// resolverFunc adapts a function to SecretResolver.
+type resolverFunc func(ctx context.Context, ref string) (string, error)
+
+func (f resolverFunc) Resolve(ctx context.Context, ref string) (string, error) {
+	return f(ctx, ref)
+}
+
+func TestSecretSource(t *testing.T) {
+	ca := newAuthority(t)
+	cert, key := ca.issue(t, "orders.internal", time.Hour)
+	secrets := map[string]string{
+		"secretref://vault/tls#cert": string(cert),
+		"secretref://vault/tls#key":  string(key),
+		"secretref://vault/tls#ca":   string(ca.pem),
+	}
+	source := SecretSource{
+		Resolver: resolverFunc(func(_ context.Context, ref string) (string, error) {
+			value, ok := secrets[ref]
+			if !ok {
+				return "", errors.New("secret not found")
+			}
+			return value, nil
+		}),
+		CertRef: "secretref://vault/tls#cert",
+		KeyRef:  "secretref://vault/tls#key",
+		CARef:   "secretref://vault/tls#ca",
+	}
+
+	r, err := New(context.Background(), source)
+	require.NoError(t, err)
+	defer r.Close()
+	assert.Equal(t, "orders.internal", r.Certificate().Subject.CommonName)
+
+	source.KeyRef = "secretref://vault/tls#missing"
+	_, err = source.Load(context.Background())
+	assert.ErrorContains(t, err, "secretref://vault/tls#missing")
+}
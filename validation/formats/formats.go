@@ -0,0 +1,206 @@
+// Package formats valida formatos de identificadores e documentos, como UUID,
+// ULID, telefone E.164, IBAN, cartão de crédito, CPF e CNPJ
+package formats
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	uuidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	ulidRegex = regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Za-hjkmnp-tv-z]{25}$`)
+	e164Regex = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+)
+
+// ibanLengths tamanho do IBAN por país (registro SWIFT)
+var ibanLengths = map[string]int{
+	"AD": 24, "AE": 23, "AL": 28, "AT": 20, "AZ": 28, "BA": 20, "BE": 16, "BG": 22,
+	"BH": 22, "BR": 29, "BY": 28, "CH": 21, "CR": 22, "CY": 28, "CZ": 24, "DE": 22,
+	"DK": 18, "DO": 28, "EE": 20, "EG": 29, "ES": 24, "FI": 18, "FO": 18, "FR": 27,
+	"GB": 22, "GE": 22, "GI": 23, "GL": 18, "GR": 27, "GT": 28, "HR": 21, "HU": 28,
+	"IE": 22, "IL": 23, "IQ": 23, "IS": 26, "IT": 27, "JO": 30, "KW": 30, "KZ": 20,
+	"LB": 28, "LC": 32, "LI": 21, "LT": 20, "LU": 20, "LV": 21, "MC": 27, "MD": 24,
+	"ME": 22, "MK": 19, "MR": 27, "MT": 31, "MU": 30, "NL": 18, "NO": 15, "PK": 24,
+	"PL": 28, "PS": 29, "PT": 25, "QA": 29, "RO": 24, "RS": 22, "SA": 24, "SC": 31,
+	"SE": 24, "SI": 19, "SK": 24, "SM": 27, "ST": 25, "SV": 28, "TL": 23, "TN": 24,
+	"TR": 26, "UA": 29, "VA": 22, "VG": 24, "XK": 20,
+}
+
+// IsUUID verifica se o valor é um UUID (RFC 9562) com a variante padrão.
+// version 0 aceita qualquer versão
+func IsUUID(value string, version int) bool {
+	if !uuidRegex.MatchString(value) {
+		return false
+	}
+
+	// Variante RFC 4122/9562: 10xx no primeiro nibble do quarto grupo
+	switch value[19] {
+	case '8', '9', 'a', 'b', 'A', 'B':
+	default:
+		return false
+	}
+
+	if version == 0 {
+		return true
+	}
+	return hexValue(value[14]) == version
+}
+
+// IsULID verifica se o valor é um ULID (26 caracteres em Crockford base32)
+func IsULID(value string) bool {
+	return ulidRegex.MatchString(value)
+}
+
+// IsE164 verifica se o valor é um telefone no formato E.164 (ex.: +5511987654321)
+func IsE164(value string) bool {
+	return e164Regex.MatchString(value)
+}
+
+// IsIBAN verifica o tamanho por país e o dígito verificador (ISO 13616, mod 97)
+// do IBAN. Espaços são ignorados
+func IsIBAN(value string) bool {
+	iban := strings.ToUpper(strings.ReplaceAll(value, " ", ""))
+	if len(iban) < 4 {
+		return false
+	}
+
+	length, ok := ibanLengths[iban[:2]]
+	if !ok || len(iban) != length || !isDigit(iban[2]) || !isDigit(iban[3]) {
+		return false
+	}
+
+	// Move os quatro primeiros caracteres para o fim e converte letras em números (A=10)
+	remainder := 0
+	for _, c := range iban[4:] + iban[:4] {
+		switch {
+		case c >= '0' && c <= '9':
+			remainder = (remainder*10 + int(c-'0')) % 97
+		case c >= 'A' && c <= 'Z':
+			remainder = (remainder*100 + int(c-'A') + 10) % 97
+		default:
+			return false
+		}
+	}
+	return remainder == 1
+}
+
+// IsCreditCard verifica se o valor é um número de cartão com 12 a 19 dígitos
+// e dígito verificador de Luhn válido. Espaços e hífens são ignorados
+func IsCreditCard(value string) bool {
+	number := strings.NewReplacer(" ", "", "-", "").Replace(value)
+	if len(number) < 12 || len(number) > 19 {
+		return false
+	}
+	return IsLuhn(number)
+}
+
+// IsLuhn verifica o dígito verificador de Luhn de uma sequência de dígitos
+func IsLuhn(value string) bool {
+	if value == "" {
+		return false
+	}
+
+	sum := 0
+	double := false
+	for i := len(value) - 1; i >= 0; i-- {
+		if !isDigit(value[i]) {
+			return false
+		}
+		d := int(value[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// IsCPF verifica os dígitos verificadores do CPF. Aceita o valor com ou sem
+// máscara (000.000.000-00)
+func IsCPF(value string) bool {
+	cpf := strings.NewReplacer(".", "", "-", "").Replace(value)
+	if len(cpf) != 11 || allSame(cpf) {
+		return false
+	}
+	for i := 0; i < len(cpf); i++ {
+		if !isDigit(cpf[i]) {
+			return false
+		}
+	}
+
+	for _, size := range []int{9, 10} {
+		sum := 0
+		for i := 0; i < size; i++ {
+			sum += int(cpf[i]-'0') * (size + 1 - i)
+		}
+		digit := sum * 10 % 11
+		if digit == 10 {
+			digit = 0
+		}
+		if digit != int(cpf[size]-'0') {
+			return false
+		}
+	}
+	return true
+}
+
+// IsCNPJ verifica os dígitos verificadores do CNPJ, numérico ou alfanumérico
+// (letras maiúsculas nas 12 primeiras posições). Aceita o valor com ou sem
+// máscara (00.000.000/0000-00)
+func IsCNPJ(value string) bool {
+	cnpj := strings.NewReplacer(".", "", "/", "", "-", "").Replace(value)
+	if len(cnpj) != 14 || allSame(cnpj) {
+		return false
+	}
+	for i := 0; i < len(cnpj); i++ {
+		c := cnpj[i]
+		if !isDigit(c) && (i >= 12 || c < 'A' || c > 'Z') {
+			return false
+		}
+	}
+
+	weights := []int{6, 5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}
+	for _, size := range []int{12, 13} {
+		sum := 0
+		for i := 0; i < size; i++ {
+			// O valor de cada caractere é o código ASCII menos 48 ('0')
+			sum += int(cnpj[i]-'0') * weights[len(weights)-size+i]
+		}
+		digit := 11 - sum%11
+		if digit >= 10 {
+			digit = 0
+		}
+		if digit != int(cnpj[size]-'0') {
+			return false
+		}
+	}
+	return true
+}
+
+// isDigit verifica se o caractere é um dígito ASCII
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// hexValue retorna o valor de um dígito hexadecimal
+func hexValue(c byte) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0')
+	case c >= 'a' && c <= 'f':
+		return int(c-'a') + 10
+	case c >= 'A' && c <= 'F':
+		return int(c-'A') + 10
+	}
+	return -1
+}
+
+// allSame verifica se todos os caracteres são iguais (ex.: 111.111.111-11)
+func allSame(value string) bool {
+	return strings.Count(value, value[:1]) == len(value)
+}
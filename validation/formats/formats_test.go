@@ -0,0 +1,86 @@
+package formats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsUUID(t *testing.T) {
+	tests := []struct {
+		value   string
+		version int
+		valid   bool
+	}{
+		{"6fa459ea-ee8a-3ca4-894e-db77e160355e", 3, true},
+		{"550e8400-e29b-41d4-a716-446655440000", 4, true},
+		{"886313e1-3b8a-5372-9b90-0c9aee199e5d", 5, true},
+		{"01890a5d-ac96-774b-bcce-b302099a8057", 7, true},
+		{"01890A5D-AC96-774B-BCCE-B302099A8057", 7, true},
+		{"550e8400-e29b-41d4-a716-446655440000", 0, true},
+		{"550e8400-e29b-41d4-a716-446655440000", 7, false},
+		{"550e8400-e29b-41d4-c716-446655440000", 4, false}, // variante inválida
+		{"550e8400e29b41d4a716446655440000", 0, false},
+		{"550e8400-e29b-41d4-a716-44665544000g", 0, false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.valid, IsUUID(tt.value, tt.version), "%s (v%d)", tt.value, tt.version)
+	}
+}
+
+func TestIsULID(t *testing.T) {
+	assert.True(t, IsULID("01ARZ3NDEKTSV4RRFFQ69G5FAV"))
+	assert.True(t, IsULID("01arz3ndektsv4rrffq69g5fav"))
+	assert.False(t, IsULID("81ARZ3NDEKTSV4RRFFQ69G5FAV")) // excede 48 bits de timestamp
+	assert.False(t, IsULID("01ARZ3NDEKTSV4RRFFQ69G5FAU")) // U não pertence ao alfabeto
+	assert.False(t, IsULID("01ARZ3NDEKTSV4RRFFQ69G5FA"))
+}
+
+func TestIsE164(t *testing.T) {
+	assert.True(t, IsE164("+5511987654321"))
+	assert.True(t, IsE164("+14155552671"))
+	assert.False(t, IsE164("5511987654321"))
+	assert.False(t, IsE164("+0511987654321"))
+	assert.False(t, IsE164("+55 11 98765-4321"))
+	assert.False(t, IsE164("+1234567890123456"))
+}
+
+func TestIsIBAN(t *testing.T) {
+	assert.True(t, IsIBAN("GB82 WEST 1234 5698 7654 32"))
+	assert.True(t, IsIBAN("DE89370400440532013000"))
+	assert.True(t, IsIBAN("br1800360305000010009795493c1"))
+	assert.False(t, IsIBAN("GB82WEST12345698765433")) // dígito verificador
+	assert.False(t, IsIBAN("DE8937040044053201300"))  // tamanho do país
+	assert.False(t, IsIBAN("ZZ82WEST12345698765432")) // país desconhecido
+	assert.False(t, IsIBAN("GB8"))
+}
+
+func TestIsCreditCard(t *testing.T) {
+	assert.True(t, IsCreditCard("4111 1111 1111 1111"))
+	assert.True(t, IsCreditCard("5500-0000-0000-0004"))
+	assert.True(t, IsCreditCard("378282246310005"))
+	assert.False(t, IsCreditCard("4111111111111112"))
+	assert.False(t, IsCreditCard("4111-1111-1111-111a"))
+	assert.False(t, IsCreditCard("0"))
+	assert.False(t, IsLuhn(""))
+}
+
+func TestIsCPF(t *testing.T) {
+	assert.True(t, IsCPF("529.982.247-25"))
+	assert.True(t, IsCPF("52998224725"))
+	assert.False(t, IsCPF("529.982.247-24"))
+	assert.False(t, IsCPF("111.111.111-11"))
+	assert.False(t, IsCPF("5299822472"))
+	assert.False(t, IsCPF("5299822472a"))
+}
+
+func TestIsCNPJ(t *testing.T) {
+	assert.True(t, IsCNPJ("11.222.333/0001-81"))
+	assert.True(t, IsCNPJ("11222333000181"))
+	assert.True(t, IsCNPJ("12.ABC.345/01DE-35"))
+	assert.False(t, IsCNPJ("12.abc.345/01de-35"))
+	assert.False(t, IsCNPJ("11.222.333/0001-80"))
+	assert.False(t, IsCNPJ("00.000.000/0000-00"))
+	assert.False(t, IsCNPJ("11222333000"))
+}
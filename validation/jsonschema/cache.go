@@ -0,0 +1,105 @@
+package jsonschema
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// DefaultSchemaCacheSize quantidade padrão de schemas compilados mantidos em cache
+const DefaultSchemaCacheSize = 128
+
+// schemaCache cache LRU de schemas compilados indexado pelo hash do schema
+type schemaCache struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List
+}
+
+// cacheEntry item armazenado no cache
+type cacheEntry struct {
+	key    string
+	schema *Schema
+}
+
+// newSchemaCache cria um cache com a capacidade informada
+func newSchemaCache(capacity int) *schemaCache {
+	return &schemaCache{
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// get retorna o schema em cache, marcando-o como usado recentemente
+func (c *schemaCache) get(key string) (*Schema, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*cacheEntry).schema, true
+}
+
+// add armazena o schema, removendo o menos usado quando a capacidade é excedida
+func (c *schemaCache) add(key string, schema *Schema) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		elem.Value.(*cacheEntry).schema = schema
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&cacheEntry{key: key, schema: schema})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// len retorna a quantidade de schemas em cache
+func (c *schemaCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// purge remove todos os schemas do cache
+func (c *schemaCache) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items = make(map[string]*list.Element)
+	c.order.Init()
+}
+
+// schemaHash calcula o hash do conteúdo do schema
+func schemaHash(schema interface{}) (string, error) {
+	var raw []byte
+	switch s := schema.(type) {
+	case string:
+		raw = []byte(s)
+	case []byte:
+		raw = s
+	case map[string]interface{}:
+		encoded, err := json.Marshal(s)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode schema: %w", err)
+		}
+		raw = encoded
+	default:
+		return "", fmt.Errorf("unsupported schema type: %T", schema)
+	}
+
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,56 @@
+package checks
+
+import (
+	"github.com/fsvxavier/nexs-lib/validation/formats"
+	"github.com/fsvxavier/nexs-lib/validation/jsonschema/interfaces"
+)
+
+// Nomes dos formatos registrados por FormatCheckers
+const (
+	FormatUUID       = "uuid"
+	FormatUUID3      = "uuid3"
+	FormatUUID4      = "uuid4"
+	FormatUUID5      = "uuid5"
+	FormatUUID7      = "uuid7"
+	FormatULID       = "ulid"
+	FormatE164       = "e164"
+	FormatIBAN       = "iban"
+	FormatCreditCard = "credit-card"
+	FormatCPF        = "cpf"
+	FormatCNPJ       = "cnpj"
+)
+
+// FormatFunc adapta uma função de validação de string para interfaces.FormatChecker.
+// Valores que não são strings são considerados inválidos
+type FormatFunc func(value string) bool
+
+// IsFormat implementa interfaces.FormatChecker
+func (f FormatFunc) IsFormat(input interface{}) bool {
+	value, ok := input.(string)
+	return ok && f(value)
+}
+
+// NewUUIDChecker cria um validador de UUID na versão informada (0 para qualquer versão)
+func NewUUIDChecker(version int) FormatFunc {
+	return func(value string) bool {
+		return formats.IsUUID(value, version)
+	}
+}
+
+// FormatCheckers retorna os validadores de formato disponíveis, indexados pelo
+// nome do formato, para registro individual ou em lote nos providers
+func FormatCheckers() map[string]interfaces.FormatChecker {
+	return map[string]interfaces.FormatChecker{
+		FormatUUID:       NewUUIDChecker(0),
+		FormatUUID3:      NewUUIDChecker(3),
+		FormatUUID4:      NewUUIDChecker(4),
+		FormatUUID5:      NewUUIDChecker(5),
+		FormatUUID7:      NewUUIDChecker(7),
+		FormatULID:       FormatFunc(formats.IsULID),
+		FormatE164:       FormatFunc(formats.IsE164),
+		FormatIBAN:       FormatFunc(formats.IsIBAN),
+		FormatCreditCard: FormatFunc(formats.IsCreditCard),
+		FormatCPF:        FormatFunc(formats.IsCPF),
+		FormatCNPJ:       FormatFunc(formats.IsCNPJ),
+	}
+}
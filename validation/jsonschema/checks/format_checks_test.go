@@ -0,0 +1,34 @@
+package checks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatCheckers(t *testing.T) {
+	checkers := FormatCheckers()
+
+	valid := map[string]string{
+		FormatUUID:       "550e8400-e29b-41d4-a716-446655440000",
+		FormatUUID3:      "6fa459ea-ee8a-3ca4-894e-db77e160355e",
+		FormatUUID4:      "550e8400-e29b-41d4-a716-446655440000",
+		FormatUUID5:      "886313e1-3b8a-5372-9b90-0c9aee199e5d",
+		FormatUUID7:      "01890a5d-ac96-774b-bcce-b302099a8057",
+		FormatULID:       "01ARZ3NDEKTSV4RRFFQ69G5FAV",
+		FormatE164:       "+5511987654321",
+		FormatIBAN:       "DE89370400440532013000",
+		FormatCreditCard: "4111111111111111",
+		FormatCPF:        "529.982.247-25",
+		FormatCNPJ:       "11.222.333/0001-81",
+	}
+
+	assert.Len(t, checkers, len(valid))
+	for name, value := range valid {
+		checker, ok := checkers[name]
+		if assert.True(t, ok, name) {
+			assert.True(t, checker.IsFormat(value), name)
+			assert.False(t, checker.IsFormat(123), name)
+		}
+	}
+}
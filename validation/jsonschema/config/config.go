@@ -13,6 +13,17 @@ const (
 	SchemaJSONProvider   ProviderType = "schemajson"
 )
 
+// SchemaDraft define a versão do JSON Schema usada quando o schema não declara $schema
+type SchemaDraft string
+
+const (
+	Draft4    SchemaDraft = "draft-04"
+	Draft6    SchemaDraft = "draft-06"
+	Draft7    SchemaDraft = "draft-07"
+	Draft2019 SchemaDraft = "2019-09"
+	Draft2020 SchemaDraft = "2020-12"
+)
+
 // Config define a configuração para o validador JSON Schema
 type Config struct {
 	// Provider especifica qual engine de validação usar
@@ -41,6 +52,19 @@ type Config struct {
 
 	// ErrorMapping mapeia tipos de erro para mensagens customizadas
 	ErrorMapping map[string]string `json:"error_mapping"`
+
+	// Draft define a versão padrão do JSON Schema (suportado pelo provider schemajson)
+	Draft SchemaDraft `json:"draft,omitempty"`
+
+	// SchemaLoader carrega schemas remotos referenciados via $ref (provider schemajson)
+	SchemaLoader interfaces.SchemaLoader `json:"-"`
+
+	// SchemaResources registra schemas por URL para resolução de $ref sem acesso remoto
+	SchemaResources map[string]interface{} `json:"-"`
+
+	// SchemaCacheSize define quantos schemas compilados são mantidos em cache
+	// (0 usa o padrão, valores negativos desabilitam o cache)
+	SchemaCacheSize int `json:"schema_cache_size"`
 }
 
 // NewConfig cria uma nova configuração com valores padrão
@@ -55,6 +79,7 @@ func NewConfig() *Config {
 		StrictMode:          false,
 		SchemaRegistry:      make(map[string]interface{}),
 		ErrorMapping:        getDefaultErrorMapping(),
+		SchemaResources:     make(map[string]interface{}),
 	}
 }
 
@@ -106,6 +131,33 @@ func (c *Config) RegisterSchema(name string, schema interface{}) *Config {
 	return c
 }
 
+// WithDraft define a versão padrão do JSON Schema
+func (c *Config) WithDraft(draft SchemaDraft) *Config {
+	c.Draft = draft
+	return c
+}
+
+// WithSchemaLoader define o loader de schemas remotos usado na resolução de $ref
+func (c *Config) WithSchemaLoader(loader interfaces.SchemaLoader) *Config {
+	c.SchemaLoader = loader
+	return c
+}
+
+// AddSchemaResource registra um schema pela URL usada nas referências $ref
+func (c *Config) AddSchemaResource(url string, schema interface{}) *Config {
+	if c.SchemaResources == nil {
+		c.SchemaResources = make(map[string]interface{})
+	}
+	c.SchemaResources[url] = schema
+	return c
+}
+
+// WithSchemaCacheSize define a capacidade do cache de schemas compilados
+func (c *Config) WithSchemaCacheSize(size int) *Config {
+	c.SchemaCacheSize = size
+	return c
+}
+
 // SetErrorMapping define mapeamento customizado de erros
 func (c *Config) SetErrorMapping(mapping map[string]string) *Config {
 	c.ErrorMapping = mapping
@@ -218,3 +218,22 @@ type mockFormatChecker struct{}
 func (m *mockFormatChecker) IsFormat(input interface{}) bool {
 	return true
 }
+
+func TestConfig_SchemaResolution(t *testing.T) {
+	loader := interfaces.SchemaLoaderFunc(func(url string) (interface{}, error) {
+		return `{"type": "string"}`, nil
+	})
+
+	cfg := NewConfig().
+		WithDraft(Draft2020).
+		WithSchemaLoader(loader).
+		AddSchemaResource("https://example.com/a.json", `{"type": "string"}`)
+
+	assert.Equal(t, Draft2020, cfg.Draft)
+	assert.NotNil(t, cfg.SchemaLoader)
+	assert.Contains(t, cfg.SchemaResources, "https://example.com/a.json")
+
+	cfg = &Config{}
+	cfg.AddSchemaResource("https://example.com/b.json", true)
+	assert.Len(t, cfg.SchemaResources, 1)
+}
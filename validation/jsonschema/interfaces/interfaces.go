@@ -7,6 +7,21 @@ type ValidationError struct {
 	ErrorType   string      `json:"error_type"`
 	Value       interface{} `json:"value,omitempty"`
 	Description string      `json:"description,omitempty"`
+	// Pointer é o JSON Pointer (RFC 6901) do valor inválido; em propriedades
+	// obrigatórias ausentes, aponta para o objeto que deveria contê-las
+	Pointer string `json:"pointer,omitempty"`
+	// Position localiza o valor no documento quando os dados são JSON bruto
+	Position *Position `json:"position,omitempty"`
+}
+
+// Position localiza um valor no documento JSON validado. Line e Column
+// começam em 1, com a coluna contada em caracteres; Offset e End delimitam
+// os bytes do valor, com End exclusivo
+type Position struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	Offset int `json:"offset"`
+	End    int `json:"end"`
 }
 
 // Error implementa a interface error
@@ -29,6 +44,17 @@ type Provider interface {
 	GetName() string
 }
 
+// CompiledSchema representa um schema já compilado, reutilizável e seguro
+// para uso concorrente
+type CompiledSchema interface {
+	Validate(data interface{}) ([]ValidationError, error)
+}
+
+// SchemaCompiler é implementado por providers capazes de pré-compilar schemas
+type SchemaCompiler interface {
+	CompileSchema(schema interface{}) (CompiledSchema, error)
+}
+
 // PreValidationHook executa lógica antes da validação
 type PreValidationHook interface {
 	Execute(data interface{}) (interface{}, error)
@@ -54,3 +80,17 @@ type Check interface {
 type FormatChecker interface {
 	IsFormat(input interface{}) bool
 }
+
+// SchemaLoader carrega schemas externos referenciados via $ref (ex.: http, https).
+// O retorno pode ser []byte, string com o JSON ou o documento já decodificado
+type SchemaLoader interface {
+	Load(url string) (interface{}, error)
+}
+
+// SchemaLoaderFunc adapta uma função para a interface SchemaLoader
+type SchemaLoaderFunc func(url string) (interface{}, error)
+
+// Load implementa SchemaLoader
+func (f SchemaLoaderFunc) Load(url string) (interface{}, error) {
+	return f(url)
+}
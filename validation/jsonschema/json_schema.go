@@ -14,6 +14,7 @@ import (
 type JSONSchemaValidator struct {
 	config   *config.Config
 	provider interfaces.Provider
+	cache    *schemaCache
 }
 
 // NewValidator cria um novo validador com configuração padrão
@@ -22,7 +23,7 @@ func NewValidator(cfg *config.Config) (*JSONSchemaValidator, error) {
 		cfg = config.NewConfig()
 	}
 
-	provider, err := createProvider(cfg.Provider)
+	provider, err := createProvider(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create provider: %w", err)
 	}
@@ -35,74 +36,51 @@ func NewValidator(cfg *config.Config) (*JSONSchemaValidator, error) {
 		}
 	}
 
-	return &JSONSchemaValidator{
+	validator := &JSONSchemaValidator{
 		config:   cfg,
 		provider: provider,
-	}, nil
-}
-
-// ValidateFromFile valida dados usando schema de arquivo
-func (v *JSONSchemaValidator) ValidateFromFile(schemaPath string, data interface{}) ([]interfaces.ValidationError, error) {
-	// Executa hooks de pré-validação
-	processedData, err := v.executePreValidationHooks(data)
-	if err != nil {
-		return nil, fmt.Errorf("pre-validation hook failed: %w", err)
 	}
 
-	// Executa validação principal
-	errors, err := v.provider.ValidateFromFile(schemaPath, processedData)
-	if err != nil {
-		return nil, err
+	// Tamanho zero usa o padrão; valores negativos desabilitam o cache
+	switch {
+	case cfg.SchemaCacheSize == 0:
+		validator.cache = newSchemaCache(DefaultSchemaCacheSize)
+	case cfg.SchemaCacheSize > 0:
+		validator.cache = newSchemaCache(cfg.SchemaCacheSize)
 	}
 
-	// Executa checks adicionais
-	additionalErrors := v.executeAdditionalChecks(processedData)
-	errors = append(errors, additionalErrors...)
+	return validator, nil
+}
 
-	// Executa hooks de pós-validação
-	errors, err = v.executePostValidationHooks(processedData, errors)
-	if err != nil {
-		return nil, fmt.Errorf("post-validation hook failed: %w", err)
+// RegisterFormat registra um validador de formato no provider (ex.:
+// checks.FormatCheckers()["cpf"]). Os schemas compilados em cache são
+// descartados para que passem a usar o novo formato
+func (v *JSONSchemaValidator) RegisterFormat(name string, checker interfaces.FormatChecker) error {
+	if err := v.provider.RegisterCustomFormat(name, checker); err != nil {
+		return fmt.Errorf("failed to register format %s: %w", name, err)
 	}
-
-	// Executa hooks de erro se houver erros
-	if len(errors) > 0 {
-		errors = v.executeErrorHooks(errors)
+	if v.cache != nil {
+		v.cache.purge()
 	}
+	return nil
+}
 
-	return errors, nil
+// ValidateFromFile valida dados usando schema de arquivo
+func (v *JSONSchemaValidator) ValidateFromFile(schemaPath string, data interface{}) ([]interfaces.ValidationError, error) {
+	return v.run(data, func(processedData interface{}) ([]interfaces.ValidationError, error) {
+		return v.provider.ValidateFromFile(schemaPath, processedData)
+	})
 }
 
-// ValidateFromBytes valida dados usando schema em bytes
+// ValidateFromBytes valida dados usando schema em bytes. O schema é compilado
+// uma única vez e reutilizado nas chamadas seguintes
 func (v *JSONSchemaValidator) ValidateFromBytes(schema []byte, data interface{}) ([]interfaces.ValidationError, error) {
-	// Executa hooks de pré-validação
-	processedData, err := v.executePreValidationHooks(data)
-	if err != nil {
-		return nil, fmt.Errorf("pre-validation hook failed: %w", err)
-	}
-
-	// Executa validação principal
-	errors, err := v.provider.Validate(schema, processedData)
+	compiled, err := v.CompileSchema(schema)
 	if err != nil {
 		return nil, err
 	}
 
-	// Executa checks adicionais
-	additionalErrors := v.executeAdditionalChecks(processedData)
-	errors = append(errors, additionalErrors...)
-
-	// Executa hooks de pós-validação
-	errors, err = v.executePostValidationHooks(processedData, errors)
-	if err != nil {
-		return nil, fmt.Errorf("post-validation hook failed: %w", err)
-	}
-
-	// Executa hooks de erro se houver erros
-	if len(errors) > 0 {
-		errors = v.executeErrorHooks(errors)
-	}
-
-	return errors, nil
+	return compiled.Validate(data)
 }
 
 // ValidateFromStruct valida dados usando schema registrado
@@ -113,6 +91,18 @@ func (v *JSONSchemaValidator) ValidateFromStruct(schemaName string, data interfa
 		return nil, fmt.Errorf("schema '%s' not found in registry", schemaName)
 	}
 
+	compiled, err := v.CompileSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	return compiled.Validate(data)
+}
+
+// run executa o fluxo completo de validação: hooks de pré-validação, validação
+// principal, checks adicionais, hooks de pós-validação, localização dos erros
+// no JSON bruto e hooks de erro
+func (v *JSONSchemaValidator) run(data interface{}, validate func(interface{}) ([]interfaces.ValidationError, error)) ([]interfaces.ValidationError, error) {
 	// Executa hooks de pré-validação
 	processedData, err := v.executePreValidationHooks(data)
 	if err != nil {
@@ -120,7 +110,7 @@ func (v *JSONSchemaValidator) ValidateFromStruct(schemaName string, data interfa
 	}
 
 	// Executa validação principal
-	errors, err := v.provider.Validate(schema, processedData)
+	errors, err := validate(processedData)
 	if err != nil {
 		return nil, err
 	}
@@ -135,6 +125,13 @@ func (v *JSONSchemaValidator) ValidateFromStruct(schemaName string, data interfa
 		return nil, fmt.Errorf("post-validation hook failed: %w", err)
 	}
 
+	// Localiza os erros no documento quando os dados são JSON bruto
+	if len(errors) > 0 {
+		if document, ok := rawDocument(data); ok {
+			locateErrors(document, errors)
+		}
+	}
+
 	// Executa hooks de erro se houver erros
 	if len(errors) > 0 {
 		errors = v.executeErrorHooks(errors)
@@ -189,33 +186,56 @@ func (v *JSONSchemaValidator) executeAdditionalChecks(data interface{}) []interf
 }
 
 // createProvider cria um provider baseado no tipo configurado
-func createProvider(providerType config.ProviderType) (interfaces.Provider, error) {
-	switch providerType {
+func createProvider(cfg *config.Config) (interfaces.Provider, error) {
+	switch cfg.Provider {
 	case config.GoJSONSchemaProvider:
 		return gojsonschema.NewProvider(), nil
 	case config.JSONSchemaProvider:
 		return kaptinlin.NewProvider(), nil
 	case config.SchemaJSONProvider:
-		return santhosh.NewProvider(), nil
+		return createSanthoshProvider(cfg)
 	default:
 		// Usa kaptinlin como fallback
 		return kaptinlin.NewProvider(), nil
 	}
 }
 
+// createSanthoshProvider cria o provider santhosh aplicando draft, loader e
+// recursos para resolução de $ref
+func createSanthoshProvider(cfg *config.Config) (interfaces.Provider, error) {
+	provider := santhosh.NewProvider()
+
+	if cfg.Draft != "" {
+		if err := provider.SetDraft(string(cfg.Draft)); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.SchemaLoader != nil {
+		provider.SetLoader(cfg.SchemaLoader)
+	}
+
+	for url, schema := range cfg.SchemaResources {
+		if err := provider.AddResource(url, schema); err != nil {
+			return nil, err
+		}
+	}
+
+	return provider, nil
+}
+
 // --- Funções de retrocompatibilidade com _old/validator ---
 
 // Validate mantém compatibilidade com a função original do _old/validator
-// Usa gojsonschema por padrão para manter compatibilidade total
+// Usa gojsonschema por padrão para manter compatibilidade total. O schema é
+// compilado uma única vez e reutilizado nas chamadas seguintes
 func Validate(loader interface{}, schemaLoader string) error {
-	validator, err := NewValidator(&config.Config{
-		Provider: config.GoJSONSchemaProvider,
-	})
+	schema, err := CompileSchema(schemaLoader)
 	if err != nil {
 		return err
 	}
 
-	errors, err := validator.provider.Validate(schemaLoader, loader)
+	errors, err := schema.validate(loader)
 	if err != nil {
 		return err
 	}
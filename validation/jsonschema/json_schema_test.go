@@ -280,7 +280,7 @@ func TestCreateProvider(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			provider, err := createProvider(tt.providerType)
+			provider, err := createProvider(config.NewConfig().WithProvider(tt.providerType))
 
 			assert.NoError(t, err)
 			assert.NotNil(t, provider)
@@ -288,3 +288,29 @@ func TestCreateProvider(t *testing.T) {
 		})
 	}
 }
+
+func TestNewValidator_SchemaJSONRefs(t *testing.T) {
+	cfg := config.NewConfig().
+		WithProvider(config.SchemaJSONProvider).
+		WithDraft(config.Draft2020).
+		AddSchemaResource("https://example.com/address.json", `{
+			"type": "object",
+			"properties": {"city": {"type": "string"}},
+			"required": ["city"]
+		}`)
+
+	validator, err := NewValidator(cfg)
+	require.NoError(t, err)
+
+	schema := []byte(`{
+		"type": "object",
+		"properties": {"address": {"$ref": "https://example.com/address.json"}}
+	}`)
+
+	errors, err := validator.ValidateFromBytes(schema, map[string]interface{}{"address": map[string]interface{}{}})
+	require.NoError(t, err)
+	assert.NotEmpty(t, errors)
+
+	_, err = NewValidator(config.NewConfig().WithProvider(config.SchemaJSONProvider).WithDraft("draft-99"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,140 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fsvxavier/nexs-lib/validation/jsonschema/interfaces"
+)
+
+// pointerUnescaper desfaz o escape de "~" e "/" em um token de JSON Pointer
+var pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+// Locate retorna a posição, no documento JSON, do valor apontado pelo JSON
+// Pointer (RFC 6901). O ponteiro vazio localiza o documento inteiro
+func Locate(document []byte, pointer string) (interfaces.Position, bool) {
+	var tokens []string
+	if pointer != "" {
+		if pointer[0] != '/' {
+			return interfaces.Position{}, false
+		}
+		tokens = strings.Split(pointer[1:], "/")
+		for i, token := range tokens {
+			tokens[i] = pointerUnescaper.Replace(token)
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(document))
+	start, end, ok := locateValue(dec, document, tokens)
+	if !ok {
+		return interfaces.Position{}, false
+	}
+
+	lineStart := bytes.LastIndexByte(document[:start], '\n') + 1
+	return interfaces.Position{
+		Line:   bytes.Count(document[:start], []byte{'\n'}) + 1,
+		Column: utf8.RuneCount(document[lineStart:start]) + 1,
+		Offset: start,
+		End:    end,
+	}, true
+}
+
+// locateValue percorre o próximo valor do decoder até o valor dos tokens,
+// retornando o intervalo de bytes encontrado
+func locateValue(dec *json.Decoder, document []byte, tokens []string) (start, end int, ok bool) {
+	start = skipSeparators(document, int(dec.InputOffset()))
+	if len(tokens) == 0 {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return 0, 0, false
+		}
+		return start, int(dec.InputOffset()), true
+	}
+
+	token, err := dec.Token()
+	if err != nil {
+		return 0, 0, false
+	}
+
+	switch token {
+	case json.Delim('{'):
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return 0, 0, false
+			}
+			if key == tokens[0] {
+				return locateValue(dec, document, tokens[1:])
+			}
+			if !skipValue(dec) {
+				return 0, 0, false
+			}
+		}
+	case json.Delim('['):
+		index, err := strconv.Atoi(tokens[0])
+		if err != nil || index < 0 {
+			return 0, 0, false
+		}
+		for i := 0; dec.More(); i++ {
+			if i == index {
+				return locateValue(dec, document, tokens[1:])
+			}
+			if !skipValue(dec) {
+				return 0, 0, false
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// skipValue descarta o próximo valor do decoder
+func skipValue(dec *json.Decoder) bool {
+	var raw json.RawMessage
+	return dec.Decode(&raw) == nil
+}
+
+// skipSeparators avança sobre espaços, ":" e "," até o início do próximo valor
+func skipSeparators(document []byte, offset int) int {
+	for offset < len(document) {
+		switch document[offset] {
+		case ' ', '\t', '\r', '\n', ':', ',':
+			offset++
+		default:
+			return offset
+		}
+	}
+	return offset
+}
+
+// rawDocument retorna os dados como documento JSON quando são JSON bruto
+// (string ou []byte)
+func rawDocument(data interface{}) ([]byte, bool) {
+	var document []byte
+	switch d := data.(type) {
+	case []byte:
+		document = d
+	case string:
+		document = []byte(d)
+	default:
+		return nil, false
+	}
+	return document, json.Valid(document)
+}
+
+// locateErrors preenche a posição dos erros no documento JSON bruto. Erros
+// sem ponteiro, como os de checks adicionais, usam o campo com pontos
+func locateErrors(document []byte, errors []interfaces.ValidationError) {
+	for i := range errors {
+		pointer := errors[i].Pointer
+		if pointer == "" && errors[i].Field != "" && errors[i].Field != "(root)" {
+			pointer = "/" + strings.ReplaceAll(errors[i].Field, ".", "/")
+		}
+		if position, ok := Locate(document, pointer); ok {
+			errors[i].Pointer = pointer
+			errors[i].Position = &position
+		}
+	}
+}
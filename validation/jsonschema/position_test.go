@@ -0,0 +1,105 @@
+package jsonschema
+
+import (
+	"testing"
+
+	"github.com/fsvxavier/nexs-lib/validation/jsonschema/config"
+	"github.com/fsvxavier/nexs-lib/validation/jsonschema/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const orderDocument = `{
+  "customer": {"name": "Zoë", "tags": ["vip", 42]},
+  "items/list": [
+    {"sku": "A-1", "qty": 2},
+    {"sku": "B~2", "qty": "three"}
+  ]
+}`
+
+func TestLocate(t *testing.T) {
+	tests := []struct {
+		pointer string
+		value   string
+		line    int
+		column  int
+	}{
+		{"", orderDocument, 1, 1},
+		{"/customer/name", `"Zoë"`, 2, 24},
+		{"/customer/tags/1", `42`, 2, 47},
+		{"/items~1list/1", `{"sku": "B~2", "qty": "three"}`, 5, 5},
+		{"/items~1list/1/qty", `"three"`, 5, 27},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pointer, func(t *testing.T) {
+			position, ok := Locate([]byte(orderDocument), tt.pointer)
+			require.True(t, ok)
+			assert.Equal(t, tt.value, orderDocument[position.Offset:position.End])
+			assert.Equal(t, tt.line, position.Line)
+			assert.Equal(t, tt.column, position.Column)
+		})
+	}
+
+	for _, pointer := range []string{"/customer/email", "/customer/tags/2", "/customer/tags/-", "customer", "/items~1list/x"} {
+		_, ok := Locate([]byte(orderDocument), pointer)
+		assert.False(t, ok, pointer)
+	}
+	_, ok := Locate([]byte(`{"a":`), "/a")
+	assert.False(t, ok)
+}
+
+func TestJSONSchemaValidator_ErrorPositions(t *testing.T) {
+	schema := `{
+		"type": "object",
+		"properties": {
+			"customer": {
+				"type": "object",
+				"properties": {"tags": {"type": "array", "items": {"type": "string"}}},
+				"required": ["email"]
+			}
+		}
+	}`
+
+	for _, provider := range []config.ProviderType{
+		config.GoJSONSchemaProvider, config.JSONSchemaProvider, config.SchemaJSONProvider,
+	} {
+		t.Run(string(provider), func(t *testing.T) {
+			validator, err := NewValidator(config.NewConfig().WithProvider(provider))
+			require.NoError(t, err)
+
+			errors, err := validator.ValidateFromBytes([]byte(schema), []byte(orderDocument))
+			require.NoError(t, err)
+
+			tags := findPointer(errors, "/customer/tags/1")
+			require.NotNil(t, tags, "%+v", errors)
+			require.NotNil(t, tags.Position)
+			assert.Equal(t, interfaces.Position{Line: 2, Column: 47, Offset: 49, End: 51}, *tags.Position)
+
+			// Propriedades ausentes apontam para o objeto que deveria contê-las
+			customer := findPointer(errors, "/customer")
+			require.NotNil(t, customer, "%+v", errors)
+			assert.Equal(t, 2, customer.Position.Line)
+			assert.Equal(t, 15, customer.Position.Column)
+
+			// Dados decodificados não têm posição
+			errors, err = validator.ValidateFromBytes([]byte(schema), map[string]interface{}{
+				"customer": map[string]interface{}{"tags": []interface{}{1}},
+			})
+			require.NoError(t, err)
+			require.NotEmpty(t, errors)
+			for _, validationErr := range errors {
+				assert.Nil(t, validationErr.Position)
+			}
+		})
+	}
+}
+
+func findPointer(errors []interfaces.ValidationError, pointer string) *interfaces.ValidationError {
+	for i := range errors {
+		if errors[i].Pointer == pointer {
+			return &errors[i]
+		}
+	}
+	return nil
+}
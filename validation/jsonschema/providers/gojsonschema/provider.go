@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/fsvxavier/nexs-lib/validation/jsonschema/interfaces"
 	"github.com/xeipuuv/gojsonschema"
@@ -25,49 +26,89 @@ func NewProvider() *Provider {
 
 // Validate executa validação usando xeipuuv/gojsonschema
 func (p *Provider) Validate(schema interface{}, data interface{}) ([]interfaces.ValidationError, error) {
-	var schemaLoader gojsonschema.JSONLoader
-	var dataLoader gojsonschema.JSONLoader
+	schemaLoader, err := newSchemaLoader(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	// Executa validação
+	result, err := gojsonschema.Validate(schemaLoader, newDataLoader(data))
+	if err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	if result.Valid() {
+		return []interfaces.ValidationError{}, nil
+	}
+
+	// Converte erros para o formato padrão
+	return p.convertErrors(result), nil
+}
+
+// CompileSchema compila o schema uma única vez para validações repetidas
+func (p *Provider) CompileSchema(schema interface{}) (interfaces.CompiledSchema, error) {
+	schemaLoader, err := newSchemaLoader(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	compiled, err := gojsonschema.NewSchema(schemaLoader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile schema: %w", err)
+	}
+
+	return &compiledSchema{provider: p, schema: compiled}, nil
+}
+
+// compiledSchema schema gojsonschema compilado
+type compiledSchema struct {
+	provider *Provider
+	schema   *gojsonschema.Schema
+}
+
+// Validate valida os dados com o schema compilado
+func (c *compiledSchema) Validate(data interface{}) ([]interfaces.ValidationError, error) {
+	result, err := c.schema.Validate(newDataLoader(data))
+	if err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	if result.Valid() {
+		return []interfaces.ValidationError{}, nil
+	}
+
+	return c.provider.convertErrors(result), nil
+}
 
-	// Configura schema loader
+// newSchemaLoader cria o loader do schema
+func newSchemaLoader(schema interface{}) (gojsonschema.JSONLoader, error) {
 	switch s := schema.(type) {
 	case string:
-		schemaLoader = gojsonschema.NewStringLoader(s)
+		return gojsonschema.NewStringLoader(s), nil
 	case []byte:
-		schemaLoader = gojsonschema.NewBytesLoader(s)
+		return gojsonschema.NewBytesLoader(s), nil
 	case map[string]interface{}:
-		schemaLoader = gojsonschema.NewGoLoader(s)
+		return gojsonschema.NewGoLoader(s), nil
 	default:
 		return nil, fmt.Errorf("unsupported schema type: %T", schema)
 	}
+}
 
-	// Configura data loader
+// newDataLoader cria o loader dos dados
+func newDataLoader(data interface{}) gojsonschema.JSONLoader {
 	switch d := data.(type) {
 	case string:
 		// Tenta fazer parse do JSON se for string
 		var parsed interface{}
 		if err := json.Unmarshal([]byte(d), &parsed); err == nil {
-			dataLoader = gojsonschema.NewGoLoader(parsed)
-		} else {
-			dataLoader = gojsonschema.NewStringLoader(d)
+			return gojsonschema.NewGoLoader(parsed)
 		}
+		return gojsonschema.NewStringLoader(d)
 	case []byte:
-		dataLoader = gojsonschema.NewBytesLoader(d)
+		return gojsonschema.NewBytesLoader(d)
 	default:
-		dataLoader = gojsonschema.NewGoLoader(d)
-	}
-
-	// Executa validação
-	result, err := gojsonschema.Validate(schemaLoader, dataLoader)
-	if err != nil {
-		return nil, fmt.Errorf("validation failed: %w", err)
+		return gojsonschema.NewGoLoader(d)
 	}
-
-	if result.Valid() {
-		return []interfaces.ValidationError{}, nil
-	}
-
-	// Converte erros para o formato padrão
-	return p.convertErrors(result), nil
 }
 
 // RegisterCustomFormat registra um formato customizado
@@ -108,6 +149,7 @@ func (p *Provider) convertErrors(result *gojsonschema.Result) []interfaces.Valid
 			ErrorType:   errorType,
 			Value:       err.Value(),
 			Description: err.Description(),
+			Pointer:     p.extractPointer(err),
 		}
 
 		errors = append(errors, validationErr)
@@ -130,6 +172,26 @@ func (p *Provider) extractField(err gojsonschema.ResultError) string {
 	return field
 }
 
+// extractPointer converte o contexto do erro, como (root).items.0, em JSON
+// Pointer. Em propriedades ausentes, o contexto é o objeto que as contém
+func (p *Provider) extractPointer(err gojsonschema.ResultError) string {
+	if err.Context() == nil {
+		return ""
+	}
+	pointer := strings.TrimPrefix(err.Context().String("\x00"), gojsonschema.STRING_CONTEXT_ROOT)
+	if pointer == "" {
+		return ""
+	}
+	tokens := strings.Split(strings.TrimPrefix(pointer, "\x00"), "\x00")
+	for i, token := range tokens {
+		tokens[i] = pointerEscaper.Replace(token)
+	}
+	return "/" + strings.Join(tokens, "/")
+}
+
+// pointerEscaper escapa "~" e "/" em um token de JSON Pointer
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
 // mapErrorType mapeia tipos de erro (compatível com _old/validator)
 func (p *Provider) mapErrorType(errorType string) string {
 	if mappedType, exists := p.errorMapping[errorType]; exists {
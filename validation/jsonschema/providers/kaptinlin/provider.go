@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/fsvxavier/nexs-lib/validation/jsonschema/interfaces"
 	"github.com/kaptinlin/jsonschema"
@@ -111,13 +112,17 @@ func (p *Provider) convertErrors(result *jsonschema.EvaluationResult) []interfac
 	}
 
 	// Coleta erros da estrutura hierárquica
-	p.collectErrors(result, &errors)
+	p.collectErrors(result, "", &errors)
 
 	return errors
 }
 
-// collectErrors coleta erros recursivamente da estrutura de resultados
-func (p *Provider) collectErrors(result *jsonschema.EvaluationResult, errors *[]interfaces.ValidationError) {
+// collectErrors coleta erros recursivamente da estrutura de resultados. As
+// localizações dos detalhes são relativas ao resultado pai e são acumuladas
+// em pointer
+func (p *Provider) collectErrors(result *jsonschema.EvaluationResult, pointer string, errors *[]interfaces.ValidationError) {
+	pointer += strings.TrimPrefix(result.InstanceLocation, "#")
+
 	// Processa erros diretos
 	for keyword, evalError := range result.Errors {
 		validationErr := interfaces.ValidationError{
@@ -125,13 +130,14 @@ func (p *Provider) collectErrors(result *jsonschema.EvaluationResult, errors *[]
 			Message:     evalError.Message,
 			ErrorType:   p.mapErrorType(keyword),
 			Description: evalError.Code,
+			Pointer:     pointer,
 		}
 		*errors = append(*errors, validationErr)
 	}
 
 	// Processa erros dos detalhes (recursivo)
 	for _, detail := range result.Details {
-		p.collectErrors(detail, errors)
+		p.collectErrors(detail, pointer, errors)
 	}
 }
 
@@ -0,0 +1,67 @@
+package santhosh
+
+import (
+	"container/list"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// compiledCache cache LRU de schemas compilados. Não é seguro para uso
+// concorrente: o provider o acessa com p.mu travado
+type compiledCache struct {
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List
+}
+
+// compiledEntry item armazenado no cache
+type compiledEntry struct {
+	key    string
+	schema *jsonschema.Schema
+}
+
+// newCompiledCache cria um cache com a capacidade informada
+func newCompiledCache(capacity int) *compiledCache {
+	return &compiledCache{
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// get retorna o schema em cache, marcando-o como usado recentemente
+func (c *compiledCache) get(key string) (*jsonschema.Schema, bool) {
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*compiledEntry).schema, true
+}
+
+// add armazena o schema, removendo o menos usado quando a capacidade é excedida
+func (c *compiledCache) add(key string, schema *jsonschema.Schema) {
+	if elem, ok := c.items[key]; ok {
+		elem.Value.(*compiledEntry).schema = schema
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&compiledEntry{key: key, schema: schema})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*compiledEntry).key)
+	}
+}
+
+// len retorna a quantidade de schemas em cache
+func (c *compiledCache) len() int {
+	return c.order.Len()
+}
+
+// purge remove todos os schemas do cache
+func (c *compiledCache) purge() {
+	c.items = make(map[string]*list.Element)
+	c.order.Init()
+}
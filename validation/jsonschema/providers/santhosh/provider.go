@@ -1,99 +1,209 @@
 package santhosh
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/fsvxavier/nexs-lib/validation/jsonschema/interfaces"
 	"github.com/santhosh-tekuri/jsonschema/v6"
 )
 
-// Provider implementa validação usando santhosh-tekuri/jsonschema v6
+// DefaultCacheSize quantidade padrão de schemas compilados mantidos em cache
+const DefaultCacheSize = 128
+
+// Provider implementa validação usando santhosh-tekuri/jsonschema v6, com
+// suporte a $ref (locais e remotos), $defs e às keywords do draft 2020-12.
+//
+// Cada schema é compilado por um compilador novo, configurado com o draft, o
+// loader, os formatos e os recursos registrados: uma compilação que falha não
+// deixa resíduos, e os recursos dos schemas enviados pelos chamadores não se
+// acumulam. Os schemas compilados ficam em um cache LRU limitado
 type Provider struct {
-	compiler     *jsonschema.Compiler
+	mu           sync.Mutex
+	defaultDraft *jsonschema.Draft
+	loader       jsonschema.URLLoader
+	formats      map[string]*jsonschema.Format
+	resources    map[string]interface{}
 	schemas      map[string]*jsonschema.Schema
+	compiled     *compiledCache
+	draft        string
+	generation   int // incrementado a cada formato ou recurso registrado
 	errorMapping map[string]string
 }
 
 // NewProvider cria um novo provider santhosh-tekuri/jsonschema v6
 func NewProvider() *Provider {
-	compiler := jsonschema.NewCompiler()
-	compiler.DefaultDraft(jsonschema.Draft7) // Usar Draft 7 por padrão
-
 	return &Provider{
-		compiler:     compiler,
+		defaultDraft: jsonschema.Draft7, // Usar Draft 7 por padrão
+		loader:       &urlLoader{},
+		formats:      make(map[string]*jsonschema.Format),
+		resources:    make(map[string]interface{}),
 		schemas:      make(map[string]*jsonschema.Schema),
+		compiled:     newCompiledCache(DefaultCacheSize),
+		draft:        "draft-07",
 		errorMapping: getDefaultErrorMapping(),
 	}
 }
 
-// Validate executa validação usando santhosh-tekuri/jsonschema v6
-func (p *Provider) Validate(schema interface{}, data interface{}) ([]interfaces.ValidationError, error) {
-	var compiledSchema *jsonschema.Schema
-	var err error
+// SetCacheSize define quantos schemas compilados são mantidos em cache;
+// valores menores que 1 usam DefaultCacheSize
+func (p *Provider) SetCacheSize(size int) {
+	if size < 1 {
+		size = DefaultCacheSize
+	}
 
-	// Determina o tipo de schema e compila
-	switch s := schema.(type) {
-	case string:
-		// Schema como string JSON
-		err = p.compiler.AddResource("schema.json", s)
-		if err != nil {
-			return nil, fmt.Errorf("failed to add schema resource: %w", err)
-		}
-		compiledSchema, err = p.compiler.Compile("schema.json")
-		if err != nil {
-			return nil, fmt.Errorf("failed to compile schema: %w", err)
-		}
-	case []byte:
-		// Schema como bytes - converte para string
-		err = p.compiler.AddResource("schema.json", string(s))
-		if err != nil {
-			return nil, fmt.Errorf("failed to add schema resource: %w", err)
-		}
-		compiledSchema, err = p.compiler.Compile("schema.json")
-		if err != nil {
-			return nil, fmt.Errorf("failed to compile schema: %w", err)
-		}
-	case map[string]interface{}:
-		// Schema como map
-		err = p.compiler.AddResource("schema.json", s)
-		if err != nil {
-			return nil, fmt.Errorf("failed to add schema resource: %w", err)
-		}
-		compiledSchema, err = p.compiler.Compile("schema.json")
-		if err != nil {
-			return nil, fmt.Errorf("failed to compile schema: %w", err)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.compiled = newCompiledCache(size)
+}
+
+// newCompiler cria um compilador com a configuração do provider. Deve ser
+// chamado com p.mu travado
+func (p *Provider) newCompiler() (*jsonschema.Compiler, error) {
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(p.defaultDraft)
+	compiler.UseLoader(p.loader)
+	for _, format := range p.formats {
+		compiler.RegisterFormat(format)
+	}
+	if len(p.formats) > 0 {
+		compiler.AssertFormat()
+	}
+	for url, doc := range p.resources {
+		if err := compiler.AddResource(url, doc); err != nil {
+			return nil, fmt.Errorf("failed to add schema resource %s: %w", url, err)
 		}
-	default:
-		return nil, fmt.Errorf("unsupported schema type: %T", schema)
 	}
+	return compiler, nil
+}
 
-	// Converte data para interface{} se necessário
-	var validationData interface{}
-	switch d := data.(type) {
-	case string:
-		// Tenta fazer parse do JSON se for string
-		var parsed interface{}
-		if err := json.Unmarshal([]byte(d), &parsed); err == nil {
-			validationData = parsed
-		} else {
-			validationData = d
-		}
-	case []byte:
-		// Tenta fazer parse do JSON se for bytes
-		var parsed interface{}
-		if err := json.Unmarshal(d, &parsed); err == nil {
-			validationData = parsed
-		} else {
-			validationData = string(d)
-		}
-	default:
-		validationData = d
+// SetDraft define o draft usado por schemas que não declaram $schema.
+// Aceita "draft-04", "draft-06", "draft-07", "2019-09" e "2020-12"
+func (p *Provider) SetDraft(draft string) error {
+	d, err := parseDraft(draft)
+	if err != nil {
+		return err
 	}
 
-	// Executa validação
-	err = compiledSchema.Validate(validationData)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.defaultDraft = d
+	p.draft = draft
+	return nil
+}
+
+// SetLoader define o loader usado para resolver $ref remotos. URLs file://
+// continuam sendo carregadas do sistema de arquivos
+func (p *Provider) SetLoader(loader interfaces.SchemaLoader) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.loader = &urlLoader{custom: loader}
+	p.generation++
+	p.compiled.purge()
+}
+
+// AddResource registra um schema pela URL, permitindo resolver $ref para ele
+// sem acesso remoto
+func (p *Provider) AddResource(url string, schema interface{}) error {
+	doc, err := decodeSchema(schema)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, exists := p.resources[url]; exists {
+		return fmt.Errorf("failed to add schema resource %s: resource already exists", url)
+	}
+	p.resources[url] = doc
+	p.generation++
+	p.compiled.purge()
+	return nil
+}
+
+// Compile compila o schema resolvendo todas as referências. O resultado fica
+// em cache pelo conteúdo do schema, pelo draft e pelos formatos registrados
+func (p *Provider) Compile(schema interface{}) (*jsonschema.Schema, error) {
+	raw, err := schemaBytes(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	sum := sha256.Sum256(append([]byte(fmt.Sprintf("%s\n%d\n", p.draft, p.generation)), raw...))
+	key := hex.EncodeToString(sum[:])
+	if compiled, ok := p.compiled.get(key); ok {
+		return compiled, nil
+	}
+
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+
+	compiler, err := p.newCompiler()
+	if err != nil {
+		return nil, err
+	}
+	url := "mem:///schemas/" + key + ".json"
+	if err := compiler.AddResource(url, doc); err != nil {
+		return nil, fmt.Errorf("failed to add schema resource: %w", err)
+	}
+
+	compiled, err := compiler.Compile(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile schema: %w", err)
+	}
+
+	p.compiled.add(key, compiled)
+	return compiled, nil
+}
+
+// CompileSchema compila o schema para validações repetidas
+func (p *Provider) CompileSchema(schema interface{}) (interfaces.CompiledSchema, error) {
+	compiled, err := p.Compile(schema)
+	if err != nil {
+		return nil, err
+	}
+	return &compiledSchema{provider: p, schema: compiled}, nil
+}
+
+// compiledSchema schema santhosh-tekuri compilado
+type compiledSchema struct {
+	provider *Provider
+	schema   *jsonschema.Schema
+}
+
+// Validate valida os dados com o schema compilado
+func (c *compiledSchema) Validate(data interface{}) ([]interfaces.ValidationError, error) {
+	return c.provider.validateCompiled(c.schema, data)
+}
+
+// Validate executa validação usando santhosh-tekuri/jsonschema v6
+func (p *Provider) Validate(schema interface{}, data interface{}) ([]interfaces.ValidationError, error) {
+	compiledSchema, err := p.Compile(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.validateCompiled(compiledSchema, data)
+}
+
+// validateCompiled valida os dados com um schema já compilado
+func (p *Provider) validateCompiled(schema *jsonschema.Schema, data interface{}) ([]interfaces.ValidationError, error) {
+	validationData, err := normalizeData(data)
+	if err != nil {
+		return nil, err
+	}
+
+	err = schema.Validate(validationData)
 	if err == nil {
 		return []interfaces.ValidationError{}, nil
 	}
@@ -102,23 +212,37 @@ func (p *Provider) Validate(schema interface{}, data interface{}) ([]interfaces.
 	return p.convertErrors(err), nil
 }
 
-// RegisterCustomFormat registra um formato customizado
+// RegisterCustomFormat registra um formato customizado. Aceita uma
+// interfaces.FormatChecker ou uma func(interface{}) bool. Os formatos passam a
+// ser verificados em todos os drafts (no 2019-09 e no 2020-12 o format é
+// apenas anotação por padrão) e o cache de schemas compilados é descartado
 func (p *Provider) RegisterCustomFormat(name string, validator interface{}) error {
-	if fn, ok := validator.(func(interface{}) bool); ok {
-		format := &jsonschema.Format{
-			Name: name,
-			Validate: func(v interface{}) error {
-				if fn(v) {
-					return nil
-				}
-				return fmt.Errorf("invalid format: %s", name)
-			},
-		}
-		p.compiler.RegisterFormat(format)
-		return nil
+	var fn func(interface{}) bool
+	switch v := validator.(type) {
+	case interfaces.FormatChecker:
+		fn = v.IsFormat
+	case func(interface{}) bool:
+		fn = v
+	default:
+		return fmt.Errorf("validator must implement interfaces.FormatChecker or be a func(interface{}) bool")
 	}
 
-	return fmt.Errorf("validator must be a func(interface{}) bool")
+	format := &jsonschema.Format{
+		Name: name,
+		Validate: func(v interface{}) error {
+			if fn(v) {
+				return nil
+			}
+			return fmt.Errorf("invalid format: %s", name)
+		},
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.formats[name] = format
+	p.generation++
+	p.compiled.purge()
+	return nil
 }
 
 // GetName retorna o nome do provider
@@ -137,6 +261,7 @@ func (p *Provider) convertErrors(err error) []interfaces.ValidationError {
 			Message:     p.getErrorMessage(validationErr.Error()),
 			ErrorType:   p.mapErrorTypeFromKind(validationErr.ErrorKind),
 			Description: validationErr.Error(),
+			Pointer:     p.extractPointer(validationErr.InstanceLocation),
 		}
 		errors = append(errors, mainError)
 
@@ -160,6 +285,19 @@ func (p *Provider) extractField(location []string) string {
 	return strings.Join(location, ".")
 }
 
+// extractPointer converte o caminho de localização em JSON Pointer
+func (p *Provider) extractPointer(location []string) string {
+	var sb strings.Builder
+	for _, token := range location {
+		sb.WriteByte('/')
+		sb.WriteString(pointerEscaper.Replace(token))
+	}
+	return sb.String()
+}
+
+// pointerEscaper escapa "~" e "/" em um token de JSON Pointer
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
 // mapErrorTypeFromKind mapeia ErrorKind para nosso padrão
 func (p *Provider) mapErrorTypeFromKind(kind jsonschema.ErrorKind) string {
 	// Como ErrorKind é uma interface, vamos usar o string da mensagem
@@ -211,108 +349,64 @@ func (p *Provider) getErrorMessage(originalMessage string) string {
 
 // ValidateFromFile valida dados usando schema de arquivo
 func (p *Provider) ValidateFromFile(schemaPath string, data interface{}) ([]interfaces.ValidationError, error) {
+	schema, err := p.compileFile(schemaPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.validateCompiled(schema, data)
+}
+
+// compileFile compila o schema do arquivo, mantendo-o no cache de schemas
+// compilados
+func (p *Provider) compileFile(schemaPath string) (*jsonschema.Schema, error) {
 	// Na v6, usamos file:// URLs para carregar arquivos
 	schemaURL := "file://" + schemaPath
 
-	schema, err := p.compiler.Compile(schemaURL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to compile schema from file: %w", err)
-	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
 
-	// Converte data se necessário
-	var validationData interface{}
-	switch d := data.(type) {
-	case string:
-		var parsed interface{}
-		if err := json.Unmarshal([]byte(d), &parsed); err == nil {
-			validationData = parsed
-		} else {
-			validationData = d
-		}
-	case []byte:
-		var parsed interface{}
-		if err := json.Unmarshal(d, &parsed); err == nil {
-			validationData = parsed
-		} else {
-			validationData = string(d)
-		}
-	default:
-		validationData = d
+	key := fmt.Sprintf("%s\n%d\n%s", p.draft, p.generation, schemaURL)
+	if compiled, ok := p.compiled.get(key); ok {
+		return compiled, nil
 	}
 
-	err = schema.Validate(validationData)
-	if err == nil {
-		return []interfaces.ValidationError{}, nil
+	compiler, err := p.newCompiler()
+	if err != nil {
+		return nil, err
+	}
+	schema, err := compiler.Compile(schemaURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile schema from file: %w", err)
 	}
 
-	return p.convertErrors(err), nil
+	p.compiled.add(key, schema)
+	return schema, nil
 }
 
 // CacheSchema armazena um schema compilado para reutilização
 func (p *Provider) CacheSchema(name string, schema interface{}) error {
-	switch s := schema.(type) {
-	case string:
-		err := p.compiler.AddResource(name, s)
-		if err != nil {
-			return err
-		}
-	case []byte:
-		err := p.compiler.AddResource(name, string(s))
-		if err != nil {
-			return err
-		}
-	case map[string]interface{}:
-		err := p.compiler.AddResource(name, s)
-		if err != nil {
-			return err
-		}
-	default:
-		return fmt.Errorf("unsupported schema type: %T", schema)
-	}
-
-	compiledSchema, err := p.compiler.Compile(name)
+	compiledSchema, err := p.Compile(schema)
 	if err != nil {
 		return err
 	}
 
+	p.mu.Lock()
 	p.schemas[name] = compiledSchema
+	p.mu.Unlock()
 	return nil
 }
 
 // ValidateWithCachedSchema valida usando schema em cache
 func (p *Provider) ValidateWithCachedSchema(schemaName string, data interface{}) ([]interfaces.ValidationError, error) {
+	p.mu.Lock()
 	schema, exists := p.schemas[schemaName]
+	p.mu.Unlock()
 	if !exists {
 		return nil, fmt.Errorf("schema '%s' not found in cache", schemaName)
 	}
 
-	// Converte data se necessário
-	var validationData interface{}
-	switch d := data.(type) {
-	case string:
-		var parsed interface{}
-		if err := json.Unmarshal([]byte(d), &parsed); err == nil {
-			validationData = parsed
-		} else {
-			validationData = d
-		}
-	case []byte:
-		var parsed interface{}
-		if err := json.Unmarshal(d, &parsed); err == nil {
-			validationData = parsed
-		} else {
-			validationData = string(d)
-		}
-	default:
-		validationData = d
-	}
-
-	err := schema.Validate(validationData)
-	if err == nil {
-		return []interfaces.ValidationError{}, nil
-	}
-
-	return p.convertErrors(err), nil
+	return p.validateCompiled(schema, data)
 }
 
 // SetErrorMapping define mapeamento customizado de erros
@@ -349,3 +443,98 @@ func getDefaultErrorMapping() map[string]string {
 		"not":                  "INVALID_DATA_TYPE",
 	}
 }
+
+// urlLoader carrega arquivos locais e delega os demais esquemas ao loader customizado
+type urlLoader struct {
+	custom interfaces.SchemaLoader
+}
+
+// Load implementa jsonschema.URLLoader
+func (l *urlLoader) Load(url string) (any, error) {
+	if strings.HasPrefix(url, "file://") {
+		return jsonschema.FileLoader{}.Load(url)
+	}
+	if l.custom == nil {
+		return nil, fmt.Errorf("no schema loader configured for %s", url)
+	}
+
+	doc, err := l.custom.Load(url)
+	if err != nil {
+		return nil, err
+	}
+	return decodeSchema(doc)
+}
+
+// parseDraft converte o nome do draft para a versão do compilador
+func parseDraft(draft string) (*jsonschema.Draft, error) {
+	switch draft {
+	case "draft-04", "4":
+		return jsonschema.Draft4, nil
+	case "draft-06", "6":
+		return jsonschema.Draft6, nil
+	case "draft-07", "7":
+		return jsonschema.Draft7, nil
+	case "2019-09", "draft-2019-09":
+		return jsonschema.Draft2019, nil
+	case "2020-12", "draft-2020-12":
+		return jsonschema.Draft2020, nil
+	default:
+		return nil, fmt.Errorf("unsupported JSON Schema draft: %s", draft)
+	}
+}
+
+// schemaBytes converte o schema para JSON
+func schemaBytes(schema interface{}) ([]byte, error) {
+	switch s := schema.(type) {
+	case string:
+		return []byte(s), nil
+	case []byte:
+		return s, nil
+	case map[string]interface{}, bool:
+		raw, err := json.Marshal(s)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode schema: %w", err)
+		}
+		return raw, nil
+	default:
+		return nil, fmt.Errorf("unsupported schema type: %T", schema)
+	}
+}
+
+// decodeSchema converte o schema para o documento esperado pelo compilador
+func decodeSchema(schema interface{}) (interface{}, error) {
+	raw, err := schemaBytes(schema)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	return doc, nil
+}
+
+// normalizeData converte os dados para valores JSON genéricos. Strings e bytes
+// com JSON válido são decodificados; structs são convertidas via encoding/json
+func normalizeData(data interface{}) (interface{}, error) {
+	switch d := data.(type) {
+	case string:
+		if parsed, err := jsonschema.UnmarshalJSON(strings.NewReader(d)); err == nil {
+			return parsed, nil
+		}
+		return d, nil
+	case []byte:
+		if parsed, err := jsonschema.UnmarshalJSON(bytes.NewReader(d)); err == nil {
+			return parsed, nil
+		}
+		return string(d), nil
+	case nil, bool, float64, json.Number, map[string]interface{}, []interface{}:
+		return d, nil
+	default:
+		raw, err := json.Marshal(d)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode data: %w", err)
+		}
+		return jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	}
+}
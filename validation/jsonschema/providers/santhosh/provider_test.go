@@ -0,0 +1,270 @@
+package santhosh
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fsvxavier/nexs-lib/validation/jsonschema/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewProvider(t *testing.T) {
+	provider := NewProvider()
+
+	assert.NotNil(t, provider)
+	assert.Equal(t, "santhosh-tekuri/jsonschema-v6", provider.GetName())
+	assert.Equal(t, "draft-07", provider.draft)
+}
+
+func TestProvider_Validate(t *testing.T) {
+	tests := []struct {
+		name       string
+		schema     interface{}
+		data       interface{}
+		wantErrors bool
+	}{
+		{
+			name: "map schema with int data",
+			schema: map[string]interface{}{
+				"type":     "object",
+				"required": []string{"name"},
+				"properties": map[string]interface{}{
+					"age": map[string]interface{}{"type": "integer", "minimum": 18},
+				},
+			},
+			data:       map[string]interface{}{"name": "John", "age": 30},
+			wantErrors: false,
+		},
+		{
+			name:       "string schema with invalid json data",
+			schema:     `{"type": "object", "required": ["name"]}`,
+			data:       `{"age": 30}`,
+			wantErrors: true,
+		},
+		{
+			name: "local $ref with $defs",
+			schema: `{
+				"$schema": "https://json-schema.org/draft/2020-12/schema",
+				"$defs": {"positive": {"type": "number", "exclusiveMinimum": 0}},
+				"properties": {"price": {"$ref": "#/$defs/positive"}}
+			}`,
+			data:       `{"price": -1}`,
+			wantErrors: true,
+		},
+		{
+			name: "allOf, anyOf, oneOf and not",
+			schema: `{
+				"allOf": [{"type": "object"}, {"required": ["id"]}],
+				"anyOf": [{"required": ["email"]}, {"required": ["phone"]}],
+				"oneOf": [{"properties": {"kind": {"const": "a"}}}, {"properties": {"kind": {"const": "b"}}}],
+				"not": {"required": ["forbidden"]}
+			}`,
+			data:       `{"id": 1, "email": "a@b.com", "kind": "a"}`,
+			wantErrors: false,
+		},
+		{
+			name: "if/then/else",
+			schema: `{
+				"if": {"properties": {"country": {"const": "BR"}}},
+				"then": {"required": ["cpf"]},
+				"else": {"required": ["passport"]}
+			}`,
+			data:       `{"country": "BR", "passport": "X1"}`,
+			wantErrors: true,
+		},
+		{
+			name: "draft 2020-12 keywords",
+			schema: `{
+				"$schema": "https://json-schema.org/draft/2020-12/schema",
+				"type": "object",
+				"properties": {"point": {"prefixItems": [{"type": "number"}, {"type": "number"}], "items": false}},
+				"dependentRequired": {"credit_card": ["billing_address"]},
+				"unevaluatedProperties": false
+			}`,
+			data:       `{"point": [1, 2, 3]}`,
+			wantErrors: true,
+		},
+		{
+			name:   "struct data is encoded as json",
+			schema: `{"type": "object", "required": ["name"]}`,
+			data: struct {
+				Name string `json:"name"`
+			}{Name: "John"},
+			wantErrors: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := NewProvider()
+
+			errs, err := provider.Validate(tt.schema, tt.data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantErrors, len(errs) > 0, "errors: %v", errs)
+		})
+	}
+}
+
+func TestProvider_Compile(t *testing.T) {
+	provider := NewProvider()
+	schema := `{"type": "string"}`
+
+	first, err := provider.Compile(schema)
+	require.NoError(t, err)
+	second, err := provider.Compile([]byte(schema))
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+
+	require.NoError(t, provider.SetDraft("2020-12"))
+	third, err := provider.Compile(schema)
+	require.NoError(t, err)
+	assert.NotSame(t, first, third)
+
+	_, err = provider.Compile(`{invalid`)
+	assert.Error(t, err)
+	_, err = provider.Compile(42)
+	assert.Error(t, err)
+}
+
+func TestProvider_CompileFailureIsNotCached(t *testing.T) {
+	provider := NewProvider()
+	schema := `{"$ref": "https://schemas.example.com/id.json"}`
+
+	_, first := provider.Compile(schema)
+	require.Error(t, first)
+	_, second := provider.Compile(schema)
+	require.Error(t, second)
+	assert.Equal(t, first.Error(), second.Error())
+	assert.NotContains(t, second.Error(), "already exists")
+
+	// O schema compila assim que a referência é registrada
+	require.NoError(t, provider.AddResource("https://schemas.example.com/id.json", `{"type": "string"}`))
+	_, err := provider.Compile(schema)
+	require.NoError(t, err)
+	assert.Error(t, provider.AddResource("https://schemas.example.com/id.json", `{"type": "number"}`))
+}
+
+func TestProvider_CacheSize(t *testing.T) {
+	provider := NewProvider()
+	provider.SetCacheSize(2)
+
+	first, err := provider.Compile(`{"type": "string"}`)
+	require.NoError(t, err)
+	for _, schema := range []string{`{"type": "number"}`, `{"type": "boolean"}`} {
+		_, err := provider.Compile(schema)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, provider.compiled.len())
+
+	again, err := provider.Compile(`{"type": "string"}`)
+	require.NoError(t, err)
+	assert.NotSame(t, first, again, "the least recently used schema is evicted")
+}
+
+func TestProvider_SetDraft(t *testing.T) {
+	provider := NewProvider()
+
+	assert.Error(t, provider.SetDraft("draft-99"))
+
+	// Sem $schema, prefixItems só é reconhecido a partir do draft 2020-12
+	schema := `{"prefixItems": [{"type": "number"}]}`
+	errs, err := provider.Validate(schema, `["x"]`)
+	require.NoError(t, err)
+	assert.Empty(t, errs)
+
+	require.NoError(t, provider.SetDraft("2020-12"))
+	errs, err = provider.Validate(schema, `["x"]`)
+	require.NoError(t, err)
+	assert.NotEmpty(t, errs)
+}
+
+func TestProvider_RemoteRefs(t *testing.T) {
+	var loaded []string
+	provider := NewProvider()
+	provider.SetLoader(interfaces.SchemaLoaderFunc(func(url string) (interface{}, error) {
+		loaded = append(loaded, url)
+		if url == "https://schemas.example.com/money.json" {
+			return []byte(`{"type": "object", "required": ["amount", "currency"]}`), nil
+		}
+		return nil, errors.New("not found")
+	}))
+
+	schema := `{"properties": {"total": {"$ref": "https://schemas.example.com/money.json"}}}`
+
+	errs, err := provider.Validate(schema, `{"total": {"amount": 10}}`)
+	require.NoError(t, err)
+	assert.NotEmpty(t, errs)
+
+	errs, err = provider.Validate(schema, `{"total": {"amount": 10, "currency": "BRL"}}`)
+	require.NoError(t, err)
+	assert.Empty(t, errs)
+	assert.Equal(t, []string{"https://schemas.example.com/money.json"}, loaded)
+
+	_, err = provider.Validate(`{"$ref": "https://schemas.example.com/missing.json"}`, `{}`)
+	assert.Error(t, err)
+}
+
+func TestProvider_AddResource(t *testing.T) {
+	provider := NewProvider()
+	require.NoError(t, provider.AddResource("https://schemas.example.com/id.json", map[string]interface{}{
+		"type":      "string",
+		"minLength": 3,
+	}))
+
+	errs, err := provider.Validate(`{"$ref": "https://schemas.example.com/id.json"}`, `"ab"`)
+	require.NoError(t, err)
+	assert.NotEmpty(t, errs)
+
+	// Sem loader configurado, referências remotas não registradas falham
+	_, err = provider.Validate(`{"$ref": "https://schemas.example.com/other.json"}`, `"ab"`)
+	assert.Error(t, err)
+}
+
+func TestProvider_CacheSchema(t *testing.T) {
+	provider := NewProvider()
+	require.NoError(t, provider.CacheSchema("user", `{"type": "object", "required": ["id"]}`))
+
+	errs, err := provider.ValidateWithCachedSchema("user", `{}`)
+	require.NoError(t, err)
+	assert.NotEmpty(t, errs)
+
+	_, err = provider.ValidateWithCachedSchema("unknown", `{}`)
+	assert.Error(t, err)
+}
+
+type cpfFormat struct{}
+
+func (cpfFormat) IsFormat(input interface{}) bool {
+	s, ok := input.(string)
+	return ok && s == "529.982.247-25"
+}
+
+func TestProvider_RegisterCustomFormat(t *testing.T) {
+	provider := NewProvider()
+	require.NoError(t, provider.SetDraft("2020-12"))
+
+	schema := `{"type": "object", "properties": {"document": {"type": "string", "format": "cpf"}}}`
+
+	// Schemas compilados antes do registro são descartados do cache
+	errs, err := provider.Validate(schema, map[string]interface{}{"document": "invalid"})
+	require.NoError(t, err)
+	assert.Empty(t, errs)
+
+	require.NoError(t, provider.RegisterCustomFormat("cpf", cpfFormat{}))
+
+	errs, err = provider.Validate(schema, map[string]interface{}{"document": "invalid"})
+	require.NoError(t, err)
+	require.NotEmpty(t, errs)
+	assert.Equal(t, "document", errs[len(errs)-1].Field)
+
+	errs, err = provider.Validate(schema, map[string]interface{}{"document": "529.982.247-25"})
+	require.NoError(t, err)
+	assert.Empty(t, errs)
+
+	require.NoError(t, provider.RegisterCustomFormat("even", func(v interface{}) bool {
+		n, ok := v.(float64)
+		return ok && int(n)%2 == 0
+	}))
+	assert.Error(t, provider.RegisterCustomFormat("invalid", "not a checker"))
+}
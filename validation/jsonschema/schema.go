@@ -0,0 +1,90 @@
+package jsonschema
+
+import (
+	"sync"
+
+	"github.com/fsvxavier/nexs-lib/validation/jsonschema/config"
+	"github.com/fsvxavier/nexs-lib/validation/jsonschema/interfaces"
+)
+
+// Schema representa um schema compilado e reutilizável. É seguro para uso
+// concorrente e executa os hooks e checks do validador que o compilou
+type Schema struct {
+	validator *JSONSchemaValidator
+	hash      string
+	raw       interface{}
+	compiled  interfaces.CompiledSchema
+}
+
+// Hash retorna o hash SHA-256 do conteúdo do schema
+func (s *Schema) Hash() string {
+	return s.hash
+}
+
+// Validate valida os dados com o schema compilado
+func (s *Schema) Validate(data interface{}) ([]interfaces.ValidationError, error) {
+	return s.validator.run(data, s.validate)
+}
+
+// validate executa somente a validação do schema, sem hooks
+func (s *Schema) validate(data interface{}) ([]interfaces.ValidationError, error) {
+	if s.compiled != nil {
+		return s.compiled.Validate(data)
+	}
+	// Providers sem suporte a compilação validam a partir do schema original
+	return s.validator.provider.Validate(s.raw, data)
+}
+
+// CompileSchema compila o schema (string, []byte ou map) para validações
+// repetidas. Schemas compilados ficam em um cache LRU indexado pelo hash
+func (v *JSONSchemaValidator) CompileSchema(schema interface{}) (*Schema, error) {
+	hash, err := schemaHash(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	if v.cache != nil {
+		if cached, ok := v.cache.get(hash); ok {
+			return cached, nil
+		}
+	}
+
+	compiled := &Schema{validator: v, hash: hash, raw: schema}
+	if compiler, ok := v.provider.(interfaces.SchemaCompiler); ok {
+		if compiled.compiled, err = compiler.CompileSchema(schema); err != nil {
+			return nil, err
+		}
+	}
+
+	if v.cache != nil {
+		v.cache.add(hash, compiled)
+	}
+	return compiled, nil
+}
+
+var (
+	legacyValidator     *JSONSchemaValidator
+	legacyValidatorErr  error
+	legacyValidatorOnce sync.Once
+)
+
+// getLegacyValidator retorna o validador compartilhado usado pelas funções de
+// retrocompatibilidade, baseado em gojsonschema
+func getLegacyValidator() (*JSONSchemaValidator, error) {
+	legacyValidatorOnce.Do(func() {
+		legacyValidator, legacyValidatorErr = NewValidator(&config.Config{
+			Provider: config.GoJSONSchemaProvider,
+		})
+	})
+	return legacyValidator, legacyValidatorErr
+}
+
+// CompileSchema compila o schema usando o validador padrão (gojsonschema),
+// retornando um Schema reutilizável
+func CompileSchema(schema string) (*Schema, error) {
+	validator, err := getLegacyValidator()
+	if err != nil {
+		return nil, err
+	}
+	return validator.CompileSchema(schema)
+}
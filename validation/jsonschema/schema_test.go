@@ -0,0 +1,204 @@
+package jsonschema
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/fsvxavier/nexs-lib/validation/jsonschema/checks"
+	"github.com/fsvxavier/nexs-lib/validation/jsonschema/config"
+	"github.com/fsvxavier/nexs-lib/validation/jsonschema/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const userSchema = `{
+	"type": "object",
+	"properties": {
+		"name": {"type": "string", "minLength": 2},
+		"age": {"type": "integer", "minimum": 0}
+	},
+	"required": ["name"]
+}`
+
+func newGoJSONSchemaValidator(t testing.TB, cfg *config.Config) *JSONSchemaValidator {
+	if cfg == nil {
+		cfg = config.NewConfig()
+	}
+	cfg.WithProvider(config.GoJSONSchemaProvider)
+
+	validator, err := NewValidator(cfg)
+	require.NoError(t, err)
+	return validator
+}
+
+func TestJSONSchemaValidator_CompileSchema(t *testing.T) {
+	validator := newGoJSONSchemaValidator(t, nil)
+
+	schema, err := validator.CompileSchema(userSchema)
+	require.NoError(t, err)
+	assert.Len(t, schema.Hash(), 64)
+
+	again, err := validator.CompileSchema([]byte(userSchema))
+	require.NoError(t, err)
+	assert.Same(t, schema, again)
+
+	errors, err := schema.Validate(map[string]interface{}{"name": "John", "age": 30})
+	require.NoError(t, err)
+	assert.Empty(t, errors)
+
+	errors, err = schema.Validate(`{"age": -1}`)
+	require.NoError(t, err)
+	assert.Len(t, errors, 2)
+
+	_, err = validator.CompileSchema(`{"type": 1}`)
+	assert.Error(t, err)
+
+	_, err = validator.CompileSchema(42)
+	assert.Error(t, err)
+}
+
+func TestJSONSchemaValidator_CompileSchemaRunsHooks(t *testing.T) {
+	var calls int
+	cfg := config.NewConfig().AddErrorHook(errorHookFunc(func(errors []interfaces.ValidationError) []interfaces.ValidationError {
+		calls++
+		return errors
+	}))
+	validator := newGoJSONSchemaValidator(t, cfg)
+
+	schema, err := validator.CompileSchema(userSchema)
+	require.NoError(t, err)
+
+	_, err = schema.Validate(`{}`)
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestJSONSchemaValidator_SchemaCacheEviction(t *testing.T) {
+	validator := newGoJSONSchemaValidator(t, config.NewConfig().WithSchemaCacheSize(2))
+
+	first, err := validator.CompileSchema(`{"type": "string"}`)
+	require.NoError(t, err)
+	_, err = validator.CompileSchema(`{"type": "number"}`)
+	require.NoError(t, err)
+
+	// Acessa o primeiro para que o segundo seja o menos usado
+	_, err = validator.CompileSchema(`{"type": "string"}`)
+	require.NoError(t, err)
+	_, err = validator.CompileSchema(`{"type": "boolean"}`)
+	require.NoError(t, err)
+	assert.Equal(t, 2, validator.cache.len())
+
+	_, ok := validator.cache.get(first.Hash())
+	assert.True(t, ok)
+	hash, err := schemaHash(`{"type": "number"}`)
+	require.NoError(t, err)
+	_, ok = validator.cache.get(hash)
+	assert.False(t, ok)
+
+	disabled := newGoJSONSchemaValidator(t, config.NewConfig().WithSchemaCacheSize(-1))
+	assert.Nil(t, disabled.cache)
+	a, err := disabled.CompileSchema(`{"type": "string"}`)
+	require.NoError(t, err)
+	b, err := disabled.CompileSchema(`{"type": "string"}`)
+	require.NoError(t, err)
+	assert.NotSame(t, a, b)
+}
+
+func TestSchema_ConcurrentValidate(t *testing.T) {
+	schema, err := CompileSchema(userSchema)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errors, err := schema.Validate(fmt.Sprintf(`{"name": "user-%d", "age": %d}`, i, i))
+			assert.NoError(t, err)
+			assert.Empty(t, errors)
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestValidate_Legacy(t *testing.T) {
+	assert.NoError(t, Validate(map[string]interface{}{"name": "John"}, userSchema))
+	assert.Error(t, Validate(map[string]interface{}{"age": 10}, userSchema))
+}
+
+// errorHookFunc adapta uma função para interfaces.ErrorHook
+type errorHookFunc func([]interfaces.ValidationError) []interfaces.ValidationError
+
+func (f errorHookFunc) Execute(errors []interfaces.ValidationError) []interfaces.ValidationError {
+	return f(errors)
+}
+
+func BenchmarkValidateFromBytes_Uncached(b *testing.B) {
+	validator := newGoJSONSchemaValidator(b, config.NewConfig().WithSchemaCacheSize(-1))
+	schema := []byte(userSchema)
+	data := map[string]interface{}{"name": "John", "age": 30}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := validator.ValidateFromBytes(schema, data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkValidateFromBytes_Cached(b *testing.B) {
+	validator := newGoJSONSchemaValidator(b, nil)
+	schema := []byte(userSchema)
+	data := map[string]interface{}{"name": "John", "age": 30}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := validator.ValidateFromBytes(schema, data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSchema_Validate(b *testing.B) {
+	schema, err := CompileSchema(userSchema)
+	require.NoError(b, err)
+	data := map[string]interface{}{"name": "John", "age": 30}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := schema.Validate(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestJSONSchemaValidator_RegisterFormat(t *testing.T) {
+	validator, err := NewValidator(&config.Config{Provider: config.SchemaJSONProvider, Draft: config.Draft2020})
+	require.NoError(t, err)
+
+	schema := `{"type": "object", "properties": {"document": {"type": "string", "format": "cnpj"}}}`
+	compiled, err := validator.CompileSchema(schema)
+	require.NoError(t, err)
+
+	errs, err := compiled.Validate(map[string]interface{}{"document": "invalid"})
+	require.NoError(t, err)
+	assert.Empty(t, errs)
+
+	require.NoError(t, validator.RegisterFormat(checks.FormatCNPJ, checks.FormatCheckers()[checks.FormatCNPJ]))
+	assert.Equal(t, 0, validator.cache.len())
+
+	compiled, err = validator.CompileSchema(schema)
+	require.NoError(t, err)
+
+	errs, err = compiled.Validate(map[string]interface{}{"document": "invalid"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, errs)
+
+	errs, err = compiled.Validate(map[string]interface{}{"document": "11.222.333/0001-81"})
+	require.NoError(t, err)
+	assert.Empty(t, errs)
+}
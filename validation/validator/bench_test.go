@@ -0,0 +1,71 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type benchRequest struct {
+	Name    string  `json:"name" validate:"required,min_length=3,max_length=50"`
+	Age     int     `json:"age" validate:"min=18,max=130"`
+	Score   float64 `json:"score" validate:"min=0,max=100"`
+	Country string  `json:"country" validate:"required,max_length=2"`
+	Nick    string  `json:"nick" validate:"max_length=20"`
+}
+
+func TestValidateStruct_PooledErrorsAreNotShared(t *testing.T) {
+	first := validationErrors(t, ValidateStruct(benchRequest{Name: "Jo", Age: 10, Country: "BR"}))
+	second := validationErrors(t, ValidateStruct(benchRequest{Name: "John", Age: 10}))
+
+	assert.Equal(t, []string{"name", "age"}, first.Fields())
+	assert.Equal(t, "must have at least 3 characters", first[0].Message)
+	assert.Equal(t, []string{"age", "country"}, second.Fields())
+	assert.Nil(t, first[0].Params["unknown"])
+}
+
+func BenchmarkValidateStruct_Valid(b *testing.B) {
+	req := benchRequest{Name: "John", Age: 30, Score: 90, Country: "BR"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := ValidateStruct(&req); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkValidateStruct_Invalid(b *testing.B) {
+	req := benchRequest{Name: "Jo", Age: 10, Score: 90, Country: "BRA"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := ValidateStruct(&req); err == nil {
+			b.Fatal("expected error")
+		}
+	}
+}
+
+func BenchmarkValidateStruct_ValidParallel(b *testing.B) {
+	req := benchRequest{Name: "John", Age: 30, Score: 90, Country: "BR"}
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if err := ValidateStruct(&req); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkValidate_RuleSet(b *testing.B) {
+	rules := NewRuleBuilder().
+		Field("name", Required(), MinLength(3)).
+		Field("age", Min(18)).
+		Build()
+	req := benchRequest{Name: "John", Age: 30}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := Validate(&req, rules); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package validator
+
+// fieldRules associa um campo às suas regras
+type fieldRules struct {
+	field      string
+	rules      []*Rule
+	sanitizers []Sanitizer
+}
+
+// RuleSet conjunto imutável de regras por campo criado pelo RuleBuilder
+type RuleSet struct {
+	fields []fieldRules
+}
+
+// Fields retorna os campos com regras, na ordem de declaração
+func (rs *RuleSet) Fields() []string {
+	fields := make([]string, len(rs.fields))
+	for i, f := range rs.fields {
+		fields[i] = f.field
+	}
+	return fields
+}
+
+// RuleBuilder monta conjuntos de regras de forma programática
+type RuleBuilder struct {
+	fields []fieldRules
+	index  map[string]int
+}
+
+// NewRuleBuilder cria um novo builder de regras
+func NewRuleBuilder() *RuleBuilder {
+	return &RuleBuilder{
+		index: make(map[string]int),
+	}
+}
+
+// Field adiciona regras para um campo. O nome pode ser o nome Go, o nome JSON
+// ou um caminho com pontos para structs aninhadas (ex.: address.city)
+func (b *RuleBuilder) Field(name string, rules ...*Rule) *RuleBuilder {
+	if idx, ok := b.index[name]; ok {
+		b.fields[idx].rules = append(b.fields[idx].rules, rules...)
+		return b
+	}
+
+	b.index[name] = len(b.fields)
+	b.fields = append(b.fields, fieldRules{field: name, rules: rules})
+	return b
+}
+
+// Sanitize adiciona sanitizadores ao campo, aplicados antes das regras
+// (ex.: Sanitize("email", validator.Trim, validator.Lower))
+func (b *RuleBuilder) Sanitize(name string, sanitizers ...Sanitizer) *RuleBuilder {
+	idx, ok := b.index[name]
+	if !ok {
+		idx = len(b.fields)
+		b.index[name] = idx
+		b.fields = append(b.fields, fieldRules{field: name})
+	}
+	b.fields[idx].sanitizers = append(b.fields[idx].sanitizers, sanitizers...)
+	return b
+}
+
+// When adiciona regras a um campo aplicadas somente quando o predicado é verdadeiro
+func (b *RuleBuilder) When(predicate Predicate, name string, rules ...*Rule) *RuleBuilder {
+	return b.Field(name, When(predicate, rules...))
+}
+
+// Build cria o RuleSet
+func (b *RuleBuilder) Build() *RuleSet {
+	fields := make([]fieldRules, len(b.fields))
+	for i, f := range b.fields {
+		fields[i] = fieldRules{
+			field:      f.field,
+			rules:      append([]*Rule(nil), f.rules...),
+			sanitizers: append([]Sanitizer(nil), f.sanitizers...),
+		}
+	}
+	return &RuleSet{fields: fields}
+}
@@ -0,0 +1,95 @@
+package validator
+
+import "strings"
+
+// And valida que o campo satisfaz todas as regras. O erro informa a primeira
+// regra que falhou ({failed}) e a sua mensagem ({reason})
+func And(rules ...*Rule) *Rule {
+	return &Rule{
+		name:    "and",
+		message: "{reason}",
+		check: func(fc *FieldContext) bool {
+			for _, rule := range rules {
+				if len(rule.failures(fc)) > 0 {
+					return false
+				}
+			}
+			return true
+		},
+		details: func(fc *FieldContext) map[string]any {
+			for _, rule := range rules {
+				if errs := rule.failures(fc); len(errs) > 0 {
+					return map[string]any{"failed": errs[0].Rule, "reason": errs[0].Message}
+				}
+			}
+			return nil
+		},
+		// Valores vazios são decididos pelas regras combinadas
+		evaluateEmpty: true,
+	}
+}
+
+// Or valida que o campo satisfaz ao menos uma das regras, como em
+// Or(And(Email(), MaxLength(100)), Empty()). O erro informa as regras que
+// falharam ({failed}) e o motivo de cada alternativa ({alternatives})
+func Or(rules ...*Rule) *Rule {
+	return &Rule{
+		name:    "or",
+		message: "must satisfy one of: {alternatives}",
+		check: func(fc *FieldContext) bool {
+			for _, rule := range rules {
+				if len(rule.failures(fc)) == 0 {
+					return true
+				}
+			}
+			return len(rules) == 0
+		},
+		details: func(fc *FieldContext) map[string]any {
+			failed := make([]string, 0, len(rules))
+			reasons := make([]string, 0, len(rules))
+			for _, rule := range rules {
+				errs := rule.failures(fc)
+				messages := make([]string, len(errs))
+				for i, err := range errs {
+					messages[i] = err.Message
+				}
+				failed = append(failed, rule.name)
+				reasons = append(reasons, strings.Join(messages, " and "))
+			}
+			return map[string]any{"failed": failed, "alternatives": strings.Join(reasons, " or ")}
+		},
+		evaluateEmpty: true,
+	}
+}
+
+// Not valida que o campo não satisfaz a regra ({rule}). Valores vazios são
+// ignorados quando a regra também os ignora
+func Not(rule *Rule) *Rule {
+	return &Rule{
+		name:    "not",
+		message: "must not satisfy {rule}",
+		params:  map[string]any{"rule": rule.name},
+		check: func(fc *FieldContext) bool {
+			return len(rule.failures(fc)) > 0
+		},
+		evaluateEmpty: rule.evaluateEmpty,
+	}
+}
+
+// Empty valida que o campo está vazio, útil como alternativa em Or
+func Empty() *Rule {
+	return &Rule{
+		name:          "empty",
+		message:       "must be empty",
+		check:         func(fc *FieldContext) bool { return isEmpty(fc.Value) },
+		evaluateEmpty: true,
+	}
+}
+
+// failures avalia a regra dentro de um combinador, retornando as falhas
+// encontradas. Regras AsWarning também contam como falha
+func (r *Rule) failures(fc *FieldContext) ValidationErrors {
+	var errs ValidationErrors
+	r.apply(fc, &errs, &errs)
+	return errs
+}
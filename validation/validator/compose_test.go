@@ -0,0 +1,67 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOr_EmailOrEmpty(t *testing.T) {
+	rules := NewRuleBuilder().
+		Field("contact", Or(And(Email(), MaxLength(20)), Empty())).
+		Build()
+
+	assert.NoError(t, Validate(map[string]string{"contact": "john@example.com"}, rules))
+	assert.NoError(t, Validate(map[string]string{"contact": ""}, rules))
+
+	errs := validationErrors(t, Validate(map[string]string{"contact": "not-an-email"}, rules))
+	require.Len(t, errs, 1)
+	assert.Equal(t, "or", errs[0].Rule)
+	assert.Equal(t, "must satisfy one of: must be a valid email address or must be empty", errs[0].Message)
+	assert.Equal(t, []string{"and", "empty"}, errs[0].Params["failed"])
+
+	// A mensagem indica qual regra do ramo falhou
+	errs = validationErrors(t, Validate(map[string]string{"contact": "john.doe@example.com.br"}, rules))
+	assert.Equal(t, "must satisfy one of: must have at most 20 characters or must be empty", errs[0].Message)
+}
+
+func TestAnd(t *testing.T) {
+	rules := NewRuleBuilder().Field("code", And(Required(), MinLength(3), MaxLength(5))).Build()
+
+	assert.NoError(t, Validate(map[string]string{"code": "ABCD"}, rules))
+
+	errs := validationErrors(t, Validate(map[string]string{"code": "AB"}, rules))
+	assert.Equal(t, "and", errs[0].Rule)
+	assert.Equal(t, "must have at least 3 characters", errs[0].Message)
+	assert.Equal(t, "min_length", errs[0].Params["failed"])
+
+	errs = validationErrors(t, Validate(map[string]string{}, rules))
+	assert.Equal(t, "is required", errs[0].Message)
+}
+
+func TestNot(t *testing.T) {
+	rules := NewRuleBuilder().
+		Field("username", Not(OneOf([]string{"admin", "root"}))).
+		Field("nickname", Not(Email()).WithMessage("{field} must not be an email address")).
+		Build()
+
+	assert.NoError(t, Validate(map[string]string{"username": "john", "nickname": "johnny"}, rules))
+	// Not(Email()) ignora valores vazios, como Email
+	assert.NoError(t, Validate(map[string]string{}, rules))
+
+	errs := validationErrors(t, Validate(map[string]string{"username": "root", "nickname": "john@example.com"}, rules))
+	require.Len(t, errs, 2)
+	assert.Equal(t, "must not satisfy one_of", errs[0].Message)
+	assert.Equal(t, "nickname must not be an email address", errs[1].Message)
+}
+
+func TestCompose_Warnings(t *testing.T) {
+	rules := NewRuleBuilder().Field("legacy", Empty().WithMessage("{field} is deprecated").AsWarning()).Build()
+
+	result, err := ValidateResult(map[string]string{"legacy": "42"}, rules)
+	require.NoError(t, err)
+	assert.True(t, result.Valid())
+	require.Len(t, result.Warnings, 1)
+	assert.Equal(t, "legacy is deprecated", result.Warnings[0].Message)
+}
@@ -0,0 +1,128 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// RequiredIf exige o campo quando outro campo possui o valor informado
+func RequiredIf(field string, value any) *Rule {
+	return &Rule{
+		name:    "required_if",
+		message: "is required when {other} is {other_value}",
+		params:  map[string]any{"other": field, "other_value": value},
+		check: func(fc *FieldContext) bool {
+			return !FieldEquals(field, value)(fc) || !isEmpty(fc.Value)
+		},
+		evaluateEmpty: true,
+	}
+}
+
+// RequiredUnless exige o campo exceto quando outro campo possui o valor informado
+func RequiredUnless(field string, value any) *Rule {
+	return &Rule{
+		name:    "required_unless",
+		message: "is required unless {other} is {other_value}",
+		params:  map[string]any{"other": field, "other_value": value},
+		check: func(fc *FieldContext) bool {
+			return FieldEquals(field, value)(fc) || !isEmpty(fc.Value)
+		},
+		evaluateEmpty: true,
+	}
+}
+
+// RequiredWith exige o campo quando outro campo está preenchido
+func RequiredWith(field string) *Rule {
+	return &Rule{
+		name:    "required_with",
+		message: "is required when {other} is present",
+		params:  map[string]any{"other": field},
+		check: func(fc *FieldContext) bool {
+			return !FieldPresent(field)(fc) || !isEmpty(fc.Value)
+		},
+		evaluateEmpty: true,
+	}
+}
+
+// EqualsField valida que o campo é igual a outro campo (ex.: confirmação de senha)
+func EqualsField(field string) *Rule {
+	return &Rule{
+		name:    "eq_field",
+		message: "must be equal to {other}",
+		params:  map[string]any{"other": field},
+		check: func(fc *FieldContext) bool {
+			other, ok := fc.Field(field)
+			return ok && valuesEqual(fc.Value, other)
+		},
+		evaluateEmpty: true,
+	}
+}
+
+// NotEqualsField valida que o campo é diferente de outro campo. Se o outro
+// campo não existir a validação falha, como em EqualsField
+func NotEqualsField(field string) *Rule {
+	return &Rule{
+		name:    "ne_field",
+		message: "must not be equal to {other}",
+		params:  map[string]any{"other": field},
+		check: func(fc *FieldContext) bool {
+			other, ok := fc.Field(field)
+			return ok && !valuesEqual(fc.Value, other)
+		},
+	}
+}
+
+// valuesEqual compara dois valores, numericamente quando possível e pela
+// representação textual nos demais casos
+func valuesEqual(x, y reflect.Value) bool {
+	a, b := indirect(x), indirect(y)
+	if !a.IsValid() || !b.IsValid() {
+		return a.IsValid() == b.IsValid()
+	}
+	if cmp, ok := compareValues(a, b); ok {
+		return cmp == 0
+	}
+	return a.CanInterface() && b.CanInterface() &&
+		fmt.Sprint(a.Interface()) == fmt.Sprint(b.Interface())
+}
+
+// GreaterThanField valida que o campo é maior que outro campo (números, strings ou datas)
+func GreaterThanField(field string) *Rule {
+	return compareFieldRule("gt_field", "must be greater than {other}", field, func(cmp int) bool { return cmp > 0 })
+}
+
+// GreaterOrEqualField valida que o campo é maior ou igual a outro campo
+func GreaterOrEqualField(field string) *Rule {
+	return compareFieldRule("gte_field", "must be greater than or equal to {other}", field, func(cmp int) bool { return cmp >= 0 })
+}
+
+// LessThanField valida que o campo é menor que outro campo
+func LessThanField(field string) *Rule {
+	return compareFieldRule("lt_field", "must be less than {other}", field, func(cmp int) bool { return cmp < 0 })
+}
+
+// LessOrEqualField valida que o campo é menor ou igual a outro campo
+func LessOrEqualField(field string) *Rule {
+	return compareFieldRule("lte_field", "must be less than or equal to {other}", field, func(cmp int) bool { return cmp <= 0 })
+}
+
+// compareFieldRule cria uma regra de comparação entre campos. Se o outro campo
+// estiver vazio a comparação é ignorada
+func compareFieldRule(name, message, field string, accept func(cmp int) bool) *Rule {
+	return &Rule{
+		name:    name,
+		message: message,
+		params:  map[string]any{"other": field},
+		check: func(fc *FieldContext) bool {
+			other, ok := fc.Field(field)
+			if !ok {
+				return false
+			}
+			if isEmpty(other) {
+				return true
+			}
+			cmp, ok := compareValues(fc.Value, other)
+			return ok && accept(cmp)
+		},
+	}
+}
@@ -0,0 +1,123 @@
+package validator
+
+import (
+	"strings"
+)
+
+// FieldError representa a falha de uma regra em um campo
+type FieldError struct {
+	Field   string         `json:"field"`
+	Rule    string         `json:"rule"`
+	Message string         `json:"message"`
+	Value   interface{}    `json:"value,omitempty"`
+	Params  map[string]any `json:"params,omitempty"`
+}
+
+// Error implementa a interface error
+func (e FieldError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + ": " + e.Message
+}
+
+// templateVar retorna uma variável disponível nos templates de mensagem:
+// {field}, {value} ou um parâmetro da regra
+func (e FieldError) templateVar(name string) (any, bool) {
+	switch name {
+	case "field":
+		return e.Field, true
+	case "value":
+		return e.Value, true
+	}
+	value, ok := e.Params[name]
+	return value, ok
+}
+
+// templateVars retorna as variáveis disponíveis nos templates de mensagem
+func (e FieldError) templateVars() map[string]any {
+	vars := make(map[string]any, len(e.Params)+2)
+	for k, v := range e.Params {
+		vars[k] = v
+	}
+	vars["field"] = e.Field
+	vars["value"] = e.Value
+	return vars
+}
+
+// ValidationErrors agrega os erros de validação de uma estrutura
+type ValidationErrors []FieldError
+
+// Error implementa a interface error
+func (ve ValidationErrors) Error() string {
+	msgs := make([]string, len(ve))
+	for i, err := range ve {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// HasErrors verifica se existem erros
+func (ve ValidationErrors) HasErrors() bool {
+	return len(ve) > 0
+}
+
+// ByField retorna os erros de um campo específico
+func (ve ValidationErrors) ByField(field string) []FieldError {
+	var result []FieldError
+	for _, err := range ve {
+		if err.Field == field {
+			result = append(result, err)
+		}
+	}
+	return result
+}
+
+// Fields retorna os campos com erro, sem repetição e na ordem de ocorrência
+func (ve ValidationErrors) Fields() []string {
+	seen := make(map[string]bool, len(ve))
+	fields := make([]string, 0, len(ve))
+	for _, err := range ve {
+		if !seen[err.Field] {
+			seen[err.Field] = true
+			fields = append(fields, err.Field)
+		}
+	}
+	return fields
+}
+
+// ToMap agrupa as mensagens de erro por campo
+func (ve ValidationErrors) ToMap() map[string][]string {
+	result := make(map[string][]string, len(ve))
+	for _, err := range ve {
+		result[err.Field] = append(result[err.Field], err.Message)
+	}
+	return result
+}
+
+// ValidationResult resultado de uma validação, com os erros que bloqueiam a
+// operação separados dos avisos das regras AsWarning. Serializado como
+// {"errors": [...], "warnings": [...]}
+type ValidationResult struct {
+	Errors   ValidationErrors `json:"errors,omitempty"`
+	Warnings ValidationErrors `json:"warnings,omitempty"`
+}
+
+// Valid verifica se não há erros; avisos não invalidam o resultado
+func (r ValidationResult) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+// HasWarnings verifica se existem avisos
+func (r ValidationResult) HasWarnings() bool {
+	return len(r.Warnings) > 0
+}
+
+// Err retorna os erros como ValidationErrors, ou nil quando o resultado é
+// válido, ignorando os avisos
+func (r ValidationResult) Err() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+	return r.Errors
+}
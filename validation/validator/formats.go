@@ -0,0 +1,70 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/fsvxavier/nexs-lib/validation/formats"
+)
+
+// formatRule cria uma regra que valida strings com a função de formato
+func formatRule(name, message string, valid func(string) bool) *Rule {
+	return &Rule{
+		name:    name,
+		message: message,
+		check: func(fc *FieldContext) bool {
+			v := indirect(fc.Value)
+			return v.IsValid() && v.Kind() == reflect.String && valid(v.String())
+		},
+	}
+}
+
+// UUID valida que a string é um UUID na versão informada (3, 4, 5 ou 7).
+// A versão 0 aceita qualquer versão
+func UUID(version int) *Rule {
+	name := "uuid"
+	message := "must be a valid UUID"
+	if version > 0 {
+		name = fmt.Sprintf("uuid%d", version)
+		message = fmt.Sprintf("must be a valid UUID v%d", version)
+	}
+
+	rule := formatRule(name, message, func(s string) bool {
+		return formats.IsUUID(s, version)
+	})
+	if version > 0 {
+		rule.params = map[string]any{"version": version}
+	}
+	return rule
+}
+
+// ULID valida que a string é um ULID
+func ULID() *Rule {
+	return formatRule("ulid", "must be a valid ULID", formats.IsULID)
+}
+
+// E164 valida que a string é um telefone no formato E.164 (ex.: +5511987654321)
+func E164() *Rule {
+	return formatRule("e164", "must be a valid E.164 phone number", formats.IsE164)
+}
+
+// IBAN valida o formato e o dígito verificador do IBAN
+func IBAN() *Rule {
+	return formatRule("iban", "must be a valid IBAN", formats.IsIBAN)
+}
+
+// CreditCard valida o número do cartão de crédito pelo algoritmo de Luhn
+func CreditCard() *Rule {
+	return formatRule("credit_card", "must be a valid credit card number", formats.IsCreditCard)
+}
+
+// CPF valida os dígitos verificadores do CPF, com ou sem máscara
+func CPF() *Rule {
+	return formatRule("cpf", "must be a valid CPF", formats.IsCPF)
+}
+
+// CNPJ valida os dígitos verificadores do CNPJ numérico ou alfanumérico,
+// com ou sem máscara
+func CNPJ() *Rule {
+	return formatRule("cnpj", "must be a valid CNPJ", formats.IsCNPJ)
+}
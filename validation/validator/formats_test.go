@@ -0,0 +1,51 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type paymentRequest struct {
+	ID       string `json:"id" validate:"uuid7"`
+	TraceID  string `json:"trace_id" validate:"ulid"`
+	Phone    string `json:"phone" validate:"e164"`
+	IBAN     string `json:"iban" validate:"iban"`
+	Card     string `json:"card" validate:"credit_card"`
+	Document string `json:"document" validate:"required_if=document_type cpf,cpf"`
+	Company  string `json:"company" validate:"cnpj"`
+}
+
+func TestFormatRules(t *testing.T) {
+	valid := paymentRequest{
+		ID:       "01890a5d-ac96-774b-bcce-b302099a8057",
+		TraceID:  "01ARZ3NDEKTSV4RRFFQ69G5FAV",
+		Phone:    "+5511987654321",
+		IBAN:     "GB82 WEST 1234 5698 7654 32",
+		Card:     "4111 1111 1111 1111",
+		Document: "529.982.247-25",
+		Company:  "12.ABC.345/01DE-35",
+	}
+	assert.NoError(t, ValidateStruct(valid))
+
+	errs := validationErrors(t, ValidateStruct(paymentRequest{
+		ID:       "550e8400-e29b-41d4-a716-446655440000",
+		TraceID:  "not-a-ulid",
+		Phone:    "11987654321",
+		IBAN:     "GB82WEST12345698765433",
+		Card:     "4111111111111112",
+		Document: "111.111.111-11",
+		Company:  "11.222.333/0001-80",
+	}))
+	assert.Equal(t, []string{"id", "trace_id", "phone", "iban", "card", "document", "company"}, errs.Fields())
+	assert.Equal(t, "uuid7", errs[0].Rule)
+	assert.Equal(t, "must be a valid UUID v7", errs[0].Message)
+	assert.Equal(t, 7, errs[0].Params["version"])
+
+	rules := NewRuleBuilder().
+		Field("id", UUID(0)).
+		Field("cnpj", CNPJ()).
+		Build()
+	assert.NoError(t, Validate(map[string]string{"id": "550e8400-e29b-41d4-a716-446655440000", "cnpj": "11222333000181"}, rules))
+	assert.Error(t, Validate(map[string]string{"id": "invalid"}, rules))
+}
@@ -0,0 +1,162 @@
+package validator
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// MessageTagName nome da struct tag com mensagens customizadas. Aceita uma
+// mensagem para todas as regras do campo ou mensagens por regra no formato
+// "regra:mensagem;regra:mensagem"
+const MessageTagName = "message"
+
+// TranslationKeyPrefix prefixo das chaves de tradução das regras (ex.: validation.required)
+const TranslationKeyPrefix = "validation."
+
+// WithMessages substitui as mensagens padrão das regras, indexadas pelo nome da
+// regra. Mensagens definidas na regra ou na struct tag têm precedência
+func WithMessages(messages map[string]string) Option {
+	return func(v *Validator) {
+		if v.messages == nil {
+			v.messages = make(map[string]string, len(messages))
+		}
+		for rule, message := range messages {
+			v.messages[rule] = message
+		}
+	}
+}
+
+// Translator traduz chaves de mensagem para um idioma. É compatível com o
+// cliente i18n (i18n/interfaces.I18n) usado pela camada de i18n do domainerrors,
+// permitindo compartilhar os mesmos arquivos de tradução
+type Translator interface {
+	Translate(ctx context.Context, key string, lang string, params map[string]interface{}) (string, error)
+}
+
+// MessageBundle conjunto de templates de mensagem por idioma e regra.
+// Implementa Translator e é seguro para uso concorrente
+type MessageBundle struct {
+	mu       sync.RWMutex
+	messages map[string]map[string]string
+}
+
+// NewMessageBundle cria um novo bundle de mensagens vazio
+func NewMessageBundle() *MessageBundle {
+	return &MessageBundle{
+		messages: make(map[string]map[string]string),
+	}
+}
+
+// Add registra as mensagens de um idioma, indexadas pelo nome da regra
+func (b *MessageBundle) Add(locale string, messages map[string]string) *MessageBundle {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.messages[locale] == nil {
+		b.messages[locale] = make(map[string]string, len(messages))
+	}
+	for rule, message := range messages {
+		b.messages[locale][rule] = message
+	}
+	return b
+}
+
+// Translate implementa Translator. A chave pode ser o nome da regra ou
+// possuir o prefixo TranslationKeyPrefix
+func (b *MessageBundle) Translate(_ context.Context, key string, lang string, params map[string]interface{}) (string, error) {
+	b.mu.RLock()
+	message, ok := b.messages[lang][strings.TrimPrefix(key, TranslationKeyPrefix)]
+	b.mu.RUnlock()
+
+	if !ok {
+		return "", &missingTranslationError{key: key, lang: lang}
+	}
+	return renderMessage(message, mapLookup(params)), nil
+}
+
+// missingTranslationError indica que não há tradução para a chave
+type missingTranslationError struct {
+	key  string
+	lang string
+}
+
+// Error implementa a interface error
+func (e *missingTranslationError) Error() string {
+	return "validator: no translation for " + e.key + " in " + e.lang
+}
+
+// Translate retorna uma cópia dos erros com as mensagens traduzidas para o
+// idioma. As chaves usadas são TranslationKeyPrefix + nome da regra, com as
+// variáveis {field}, {value} e os parâmetros da regra. Erros sem tradução
+// mantêm a mensagem original
+func (ve ValidationErrors) Translate(ctx context.Context, translator Translator, locale string) ValidationErrors {
+	result := make(ValidationErrors, len(ve))
+	copy(result, ve)
+	if translator == nil {
+		return result
+	}
+
+	for i, err := range result {
+		message, tErr := translator.Translate(ctx, TranslationKeyPrefix+err.Rule, locale, err.templateVars())
+		if tErr == nil && message != "" {
+			result[i].Message = message
+		}
+	}
+	return result
+}
+
+// applyMessageTag aplica as mensagens da struct tag às regras do campo
+func applyMessageTag(rules *tagRules, tag string) {
+	if tag == "" || rules == nil {
+		return
+	}
+
+	general, byRule := parseMessageTag(tag)
+	for r := rules; r != nil; r = r.elem {
+		r.rules = withTagMessages(r.rules, general, byRule)
+	}
+}
+
+// withTagMessages retorna as regras com as mensagens da tag aplicadas
+func withTagMessages(rules []*Rule, general string, byRule map[string]string) []*Rule {
+	result := make([]*Rule, len(rules))
+	for i, rule := range rules {
+		switch {
+		case rule.condition != nil:
+			clone := rule.clone()
+			clone.children = withTagMessages(rule.children, general, byRule)
+			result[i] = clone
+		case byRule[rule.name] != "":
+			result[i] = rule.WithMessage(byRule[rule.name])
+		case general != "":
+			result[i] = rule.WithMessage(general)
+		default:
+			result[i] = rule
+		}
+	}
+	return result
+}
+
+// parseMessageTag separa a mensagem geral das mensagens por regra. Itens que
+// não começam com o nome de uma regra registrada são tratados como mensagem geral
+func parseMessageTag(tag string) (string, map[string]string) {
+	var general string
+	byRule := make(map[string]string)
+
+	for _, item := range strings.Split(tag, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if name, message, ok := strings.Cut(item, ":"); ok {
+			if _, known := lookupRule(strings.TrimSpace(name)); known {
+				byRule[strings.TrimSpace(name)] = strings.TrimSpace(message)
+				continue
+			}
+		}
+		general = item
+	}
+
+	return general, byRule
+}
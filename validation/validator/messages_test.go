@@ -0,0 +1,86 @@
+package validator
+
+import (
+	"context"
+	"testing"
+
+	i18nInterfaces "github.com/fsvxavier/nexs-lib/i18n/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// O cliente i18n usado pelo domainerrors pode ser usado como Translator
+var _ Translator = (i18nInterfaces.I18n)(nil)
+
+type profileRequest struct {
+	Nickname string   `json:"nickname" validate:"required,min_length=3" message:"min_length:{field} needs {min}+ chars, got {value}"`
+	Bio      string   `json:"bio" validate:"max_length=5" message:"bio too long"`
+	Tags     []string `json:"tags" validate:"dive,min_length=2" message:"min_length:tag {field} is too short"`
+}
+
+func TestRule_WithMessage(t *testing.T) {
+	rule := MinLength(5).WithMessage("{field} must have at least {min} characters")
+	assert.Equal(t, "{field} must have at least {min} characters", rule.Message())
+	assert.Equal(t, "must have at least {min} characters", MinLength(5).Message())
+
+	rules := NewRuleBuilder().Field("name", rule).Build()
+	errs := validationErrors(t, Validate(map[string]string{"name": "abc"}, rules))
+	assert.Equal(t, "name must have at least 5 characters", errs[0].Message)
+}
+
+func TestValidateStruct_MessageTag(t *testing.T) {
+	err := ValidateStruct(profileRequest{Nickname: "ab", Bio: "too long bio", Tags: []string{"ok", "x"}})
+	errs := validationErrors(t, err)
+
+	require.Len(t, errs, 3)
+	assert.Equal(t, "nickname needs 3+ chars, got ab", errs[0].Message)
+	assert.Equal(t, "bio too long", errs[1].Message)
+	assert.Equal(t, "tag tags[1] is too short", errs[2].Message)
+
+	// Regras sem mensagem na tag mantêm a mensagem padrão
+	errs = validationErrors(t, ValidateStruct(profileRequest{}))
+	assert.Equal(t, "is required", errs[0].Message)
+}
+
+func TestWithMessages(t *testing.T) {
+	v := New(WithMessages(map[string]string{
+		"required":   "{field} é obrigatório",
+		"min_length": "ignored because the tag wins",
+	}))
+
+	errs := validationErrors(t, v.ValidateStruct(profileRequest{}))
+	assert.Equal(t, "nickname é obrigatório", errs[0].Message)
+
+	errs = validationErrors(t, v.ValidateStruct(profileRequest{Nickname: "ab"}))
+	assert.Equal(t, "nickname needs 3+ chars, got ab", errs[0].Message)
+
+	rules := NewRuleBuilder().Field("code", Required()).Build()
+	errs = validationErrors(t, v.Validate(map[string]string{}, rules))
+	assert.Equal(t, "code é obrigatório", errs[0].Message)
+}
+
+func TestValidationErrors_Translate(t *testing.T) {
+	bundle := NewMessageBundle().
+		Add("pt-BR", map[string]string{
+			"required":   "{field} é obrigatório",
+			"min_length": "{field} deve ter pelo menos {min} caracteres",
+		}).
+		Add("es", map[string]string{"required": "{field} es obligatorio"})
+
+	errs := validationErrors(t, ValidateStruct(signupRequest{Name: "Jo", Email: "a@b.com", Age: 20}))
+
+	translated := errs.Translate(context.Background(), bundle, "pt-BR")
+	assert.Equal(t, "name deve ter pelo menos 3 caracteres", translated.ByField("name")[0].Message)
+	assert.Equal(t, "password é obrigatório", translated.ByField("password")[0].Message)
+
+	translated = errs.Translate(context.Background(), bundle, "es")
+	assert.Equal(t, "must have at least 3 characters", translated.ByField("name")[0].Message)
+	assert.Equal(t, "password es obligatorio", translated.ByField("password")[0].Message)
+
+	// O original não é alterado
+	assert.Equal(t, "is required", errs.ByField("password")[0].Message)
+	assert.Equal(t, errs, errs.Translate(context.Background(), nil, "pt-BR"))
+
+	_, err := bundle.Translate(context.Background(), "validation.email", "fr", nil)
+	assert.Error(t, err)
+}
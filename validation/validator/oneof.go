@@ -0,0 +1,202 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// OneOfOption configura a regra OneOf
+type OneOfOption func(*oneOfConfig)
+
+type oneOfConfig struct {
+	ignoreCase bool
+}
+
+// IgnoreCase faz a regra OneOf comparar strings sem diferenciar maiúsculas
+// de minúsculas
+func IgnoreCase() OneOfOption {
+	return func(c *oneOfConfig) {
+		c.ignoreCase = true
+	}
+}
+
+// OneOf valida que o valor está entre os valores permitidos. Aceita strings,
+// inteiros e enums baseados em iota: quando o tipo implementa fmt.Stringer, os
+// nomes retornados por String() são usados na mensagem e aceitos em campos
+// string. Quando um campo string é inválido, o parâmetro suggestion do erro
+// traz o valor permitido mais próximo.
+//
+// Como nas demais regras, valores vazios (incluindo o zero de um enum) só são
+// rejeitados em conjunto com required.
+func OneOf[T comparable](values []T, opts ...OneOfOption) *Rule {
+	var cfg oneOfConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	set := newOneOfSet(cfg.ignoreCase)
+	for _, value := range values {
+		set.add(value)
+	}
+
+	name := "one_of"
+	if cfg.ignoreCase {
+		name = "one_of_ci"
+	}
+	return &Rule{
+		name:    name,
+		message: "must be one of {values}",
+		params:  map[string]any{"values": set.names},
+		check:   set.contains,
+		details: set.suggest,
+	}
+}
+
+// oneOfSet valores permitidos pela regra OneOf, indexados para comparação
+type oneOfSet struct {
+	ignoreCase bool
+	names      []string
+	byName     map[string]struct{}
+	ints       map[int64]struct{}
+	uints      map[uint64]struct{}
+	others     []any
+}
+
+func newOneOfSet(ignoreCase bool) *oneOfSet {
+	return &oneOfSet{
+		ignoreCase: ignoreCase,
+		byName:     make(map[string]struct{}),
+		ints:       make(map[int64]struct{}),
+		uints:      make(map[uint64]struct{}),
+	}
+}
+
+// add registra o valor pelo nome e, para inteiros, pelo valor numérico
+func (s *oneOfSet) add(value any) {
+	name := formatValue(value)
+	if stringer, ok := value.(fmt.Stringer); ok {
+		name = stringer.String()
+	}
+	s.names = append(s.names, name)
+	s.byName[s.key(name)] = struct{}{}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s.ints[v.Int()] = struct{}{}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s.uints[v.Uint()] = struct{}{}
+	case reflect.String:
+		s.byName[s.key(v.String())] = struct{}{}
+	default:
+		s.others = append(s.others, value)
+	}
+}
+
+// key normaliza o nome conforme a sensibilidade a maiúsculas
+func (s *oneOfSet) key(name string) string {
+	if s.ignoreCase {
+		return strings.ToLower(name)
+	}
+	return name
+}
+
+// contains verifica se o valor do campo está entre os permitidos. Campos
+// string são comparados pelos nomes, inteiros pelo valor numérico e os demais
+// tipos por valueEquals
+func (s *oneOfSet) contains(fc *FieldContext) bool {
+	v := indirect(fc.Value)
+	if !v.IsValid() {
+		return false
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		_, ok := s.byName[s.key(v.String())]
+		return ok
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if _, ok := s.ints[v.Int()]; ok {
+			return true
+		}
+		if v.Int() >= 0 {
+			if _, ok := s.uints[uint64(v.Int())]; ok {
+				return true
+			}
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if _, ok := s.uints[v.Uint()]; ok {
+			return true
+		}
+		if v.Uint() <= 1<<63-1 {
+			if _, ok := s.ints[int64(v.Uint())]; ok {
+				return true
+			}
+		}
+	}
+	if !v.CanInterface() {
+		return false
+	}
+
+	// Valores vindos de struct tags são strings, comparadas com a
+	// representação textual do campo (que usa String() nos enums)
+	if _, ok := s.byName[s.key(fmt.Sprint(v.Interface()))]; ok {
+		return true
+	}
+	for _, other := range s.others {
+		if valueEquals(v, other) {
+			return true
+		}
+	}
+	return false
+}
+
+// suggest retorna o valor permitido mais próximo de um campo string inválido
+func (s *oneOfSet) suggest(fc *FieldContext) map[string]any {
+	v := indirect(fc.Value)
+	if !v.IsValid() || v.Kind() != reflect.String {
+		return nil
+	}
+	if suggestion, ok := closestName(v.String(), s.names); ok {
+		return map[string]any{"suggestion": suggestion}
+	}
+	return nil
+}
+
+// closestName retorna o nome com a menor distância de edição até value,
+// ignorando maiúsculas, desde que a distância não passe de um terço do nome
+func closestName(value string, names []string) (string, bool) {
+	value = strings.ToLower(value)
+	best, bestDistance := "", -1
+	for _, name := range names {
+		distance := editDistance(value, strings.ToLower(name))
+		limit := max(1, utf8.RuneCountInString(name)/3)
+		if distance <= limit && (bestDistance < 0 || distance < bestDistance) {
+			best, bestDistance = name, distance
+		}
+	}
+	return best, bestDistance >= 0
+}
+
+// editDistance calcula a distância de Levenshtein entre duas strings
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,103 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type orderStatus int
+
+const (
+	orderPending orderStatus = iota + 1
+	orderPaid
+	orderShipped
+	orderCanceled
+)
+
+func (s orderStatus) String() string {
+	switch s {
+	case orderPending:
+		return "pending"
+	case orderPaid:
+		return "paid"
+	case orderShipped:
+		return "shipped"
+	case orderCanceled:
+		return "canceled"
+	default:
+		return "unknown"
+	}
+}
+
+func TestOneOf_Strings(t *testing.T) {
+	rules := NewRuleBuilder().
+		Field("currency", OneOf([]string{"BRL", "USD", "EUR"})).
+		Field("country", OneOf([]string{"br", "us"}, IgnoreCase())).
+		Build()
+
+	assert.NoError(t, Validate(map[string]any{"currency": "USD", "country": "BR"}, rules))
+
+	errs := validationErrors(t, Validate(map[string]any{"currency": "usd", "country": "ar"}, rules))
+	assert.Equal(t, []string{"currency", "country"}, errs.Fields())
+	assert.Equal(t, "one_of", errs[0].Rule)
+	assert.Equal(t, "must be one of BRL, USD, EUR", errs[0].Message)
+	assert.Equal(t, []string{"BRL", "USD", "EUR"}, errs[0].Params["values"])
+	assert.Equal(t, "USD", errs[0].Params["suggestion"])
+	assert.Equal(t, "one_of_ci", errs[1].Rule)
+	assert.Equal(t, "br", errs[1].Params["suggestion"])
+}
+
+func TestOneOf_Enums(t *testing.T) {
+	open := []orderStatus{orderPending, orderPaid, orderShipped}
+	rules := NewRuleBuilder().
+		Field("status", OneOf(open)).
+		Field("priority", OneOf([]int{1, 2, 3})).
+		Build()
+
+	// Enums aceitam o próprio tipo, inteiros e os nomes retornados por String()
+	assert.NoError(t, Validate(map[string]any{"status": orderPaid, "priority": int64(2)}, rules))
+	assert.NoError(t, Validate(map[string]any{"status": 3}, rules))
+	assert.NoError(t, Validate(map[string]any{"status": "shipped"}, rules))
+
+	errs := validationErrors(t, Validate(map[string]any{"status": orderCanceled, "priority": uint8(7)}, rules))
+	assert.Equal(t, "must be one of pending, paid, shipped", errs[0].Message)
+	assert.NotContains(t, errs[0].Params, "suggestion")
+	assert.Equal(t, "must be one of 1, 2, 3", errs[1].Message)
+
+	errs = validationErrors(t, Validate(map[string]any{"status": "shiped"}, rules))
+	assert.Equal(t, "shipped", errs[0].Params["suggestion"])
+
+	// Valores muito distantes não geram sugestão
+	errs = validationErrors(t, Validate(map[string]any{"status": "refunded"}, rules))
+	assert.NotContains(t, errs[0].Params, "suggestion")
+}
+
+type subscription struct {
+	Plan   string      `json:"plan" validate:"required,one_of=free pro enterprise"`
+	Region string      `json:"region" validate:"one_of_ci=us eu"`
+	Seats  int         `json:"seats" validate:"one_of=5 10 25"`
+	Status orderStatus `json:"status" validate:"one_of=pending paid"`
+}
+
+func TestOneOf_Tags(t *testing.T) {
+	assert.NoError(t, ValidateStruct(subscription{Plan: "pro", Region: "EU", Seats: 10, Status: orderPaid}))
+
+	errs := validationErrors(t, ValidateStruct(subscription{Plan: "premium", Region: "asia", Seats: 7, Status: orderShipped}))
+	assert.Equal(t, []string{"plan", "region", "seats", "status"}, errs.Fields())
+	assert.NotContains(t, errs[0].Params, "suggestion")
+
+	errs = validationErrors(t, ValidateStruct(subscription{Plan: "enterprize"}))
+	assert.Equal(t, "enterprise", errs[0].Params["suggestion"])
+
+	_, err := ParseTag("one_of=")
+	assert.Error(t, err)
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("paid", "paid"))
+	assert.Equal(t, 1, editDistance("paid", "pad"))
+	assert.Equal(t, 2, editDistance("shipped", "shpiped"))
+	assert.Equal(t, 3, editDistance("", "abc"))
+	assert.Equal(t, 1, editDistance("ação", "acão"))
+}
@@ -0,0 +1,408 @@
+// Package openapi valida requisições e respostas HTTP contra um documento
+// OpenAPI 3.1 (schemas JSON Schema 2020-12), retornando erros de domínio com
+// os caminhos dos campos inválidos
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"gopkg.in/yaml.v3"
+)
+
+// documentURL URL interna usada para resolver as referências do documento
+const documentURL = "mem:///openapi.json"
+
+// ErrInvalidDocument indica que o documento OpenAPI é inválido
+var ErrInvalidDocument = errors.New("openapi: invalid document")
+
+// Document documento OpenAPI carregado, com as operações indexadas por rota
+type Document struct {
+	version  string
+	basePath string
+	root     map[string]any
+	routes   []*route
+
+	mu       sync.Mutex
+	compiler *jsonschema.Compiler
+	schemas  map[string]*jsonschema.Schema
+}
+
+// route operação do documento associada a um template de caminho
+type route struct {
+	method    string
+	template  string
+	segments  []string
+	literals  int
+	pointer   string
+	operation map[string]any
+	params    []*parameter
+}
+
+// parameter parâmetro de operação já resolvido
+type parameter struct {
+	name     string
+	in       string
+	required bool
+	explode  bool
+	schema   map[string]any
+	pointer  string
+}
+
+// LoadFile carrega um documento OpenAPI em JSON ou YAML
+func LoadFile(path string) (*Document, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("openapi: failed to read document: %w", err)
+	}
+	return Load(data)
+}
+
+// Load carrega um documento OpenAPI em JSON ou YAML
+func Load(data []byte) (*Document, error) {
+	raw, err := decodeDocument(data)
+	if err != nil {
+		return nil, err
+	}
+
+	root, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: root must be an object", ErrInvalidDocument)
+	}
+
+	version, _ := root["openapi"].(string)
+	if !strings.HasPrefix(version, "3.") {
+		return nil, fmt.Errorf("%w: unsupported openapi version %q", ErrInvalidDocument, version)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	if strings.HasPrefix(version, "3.0") {
+		// OpenAPI 3.0 usa um subconjunto estendido do draft 4
+		compiler.DefaultDraft(jsonschema.Draft4)
+	} else {
+		compiler.DefaultDraft(jsonschema.Draft2020)
+	}
+	if err := compiler.AddResource(documentURL, root); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+	}
+
+	doc := &Document{
+		version:  version,
+		basePath: serverBasePath(root),
+		root:     root,
+		compiler: compiler,
+		schemas:  make(map[string]*jsonschema.Schema),
+	}
+
+	if err := doc.indexRoutes(); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// Version retorna a versão OpenAPI declarada no documento
+func (d *Document) Version() string {
+	return d.version
+}
+
+// BasePath retorna o caminho base obtido do primeiro servidor do documento
+func (d *Document) BasePath() string {
+	return d.basePath
+}
+
+// decodeDocument decodifica JSON ou YAML para valores JSON genéricos
+func decodeDocument(data []byte) (any, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(trimmed))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+		}
+		return doc, nil
+	}
+
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+	}
+
+	// Normaliza via JSON para obter os mesmos tipos do documento em JSON
+	encoded, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+	}
+	return jsonschema.UnmarshalJSON(bytes.NewReader(encoded))
+}
+
+// serverBasePath extrai o caminho da URL do primeiro servidor
+func serverBasePath(root map[string]any) string {
+	servers, _ := root["servers"].([]any)
+	if len(servers) == 0 {
+		return ""
+	}
+	server, _ := servers[0].(map[string]any)
+	rawURL, _ := server["url"].(string)
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimRight(u.Path, "/")
+}
+
+// operationMethods métodos HTTP aceitos em um path item
+var operationMethods = []string{
+	http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete,
+	http.MethodOptions, http.MethodHead, http.MethodPatch, http.MethodTrace,
+}
+
+// indexRoutes cria o índice de rotas a partir de paths
+func (d *Document) indexRoutes() error {
+	paths, _ := d.root["paths"].(map[string]any)
+	for template, rawItem := range paths {
+		itemPointer := "/paths/" + escapePointer(template)
+		item, itemPointer, err := d.resolve(rawItem, itemPointer)
+		if err != nil {
+			return err
+		}
+
+		pathParams, err := d.parameters(item["parameters"], itemPointer+"/parameters")
+		if err != nil {
+			return err
+		}
+
+		for _, method := range operationMethods {
+			key := strings.ToLower(method)
+			operation, ok := item[key].(map[string]any)
+			if !ok {
+				continue
+			}
+
+			opPointer := itemPointer + "/" + key
+			opParams, err := d.parameters(operation["parameters"], opPointer+"/parameters")
+			if err != nil {
+				return err
+			}
+
+			r := &route{
+				method:    method,
+				template:  template,
+				segments:  splitPath(template),
+				pointer:   opPointer,
+				operation: operation,
+				params:    mergeParameters(pathParams, opParams),
+			}
+			for _, segment := range r.segments {
+				if !isTemplateSegment(segment) {
+					r.literals++
+				}
+			}
+			d.routes = append(d.routes, r)
+		}
+	}
+
+	// Rotas com mais segmentos literais têm precedência (/users/me antes de /users/{id})
+	sort.SliceStable(d.routes, func(i, j int) bool {
+		if d.routes[i].literals != d.routes[j].literals {
+			return d.routes[i].literals > d.routes[j].literals
+		}
+		return d.routes[i].template < d.routes[j].template
+	})
+	return nil
+}
+
+// parameters resolve a lista de parâmetros de um path item ou operação
+func (d *Document) parameters(raw any, pointer string) ([]*parameter, error) {
+	list, _ := raw.([]any)
+	params := make([]*parameter, 0, len(list))
+
+	for i, item := range list {
+		node, nodePointer, err := d.resolve(item, fmt.Sprintf("%s/%d", pointer, i))
+		if err != nil {
+			return nil, err
+		}
+
+		p := &parameter{}
+		p.name, _ = node["name"].(string)
+		p.in, _ = node["in"].(string)
+		p.required, _ = node["required"].(bool)
+		if p.in == "path" {
+			p.required = true
+		}
+
+		// Estilo form (query/cookie) usa explode=true por padrão
+		p.explode = p.in == "query" || p.in == "cookie"
+		if explode, ok := node["explode"].(bool); ok {
+			p.explode = explode
+		}
+
+		if _, ok := node["schema"]; ok {
+			p.pointer = nodePointer + "/schema"
+			p.schema, _, err = d.resolve(node["schema"], p.pointer)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		params = append(params, p)
+	}
+	return params, nil
+}
+
+// mergeParameters combina os parâmetros do path item com os da operação,
+// que os sobrescrevem quando possuem o mesmo nome e localização
+func mergeParameters(pathParams, opParams []*parameter) []*parameter {
+	result := make([]*parameter, 0, len(pathParams)+len(opParams))
+	for _, p := range pathParams {
+		overridden := false
+		for _, op := range opParams {
+			if op.name == p.name && op.in == p.in {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			result = append(result, p)
+		}
+	}
+	return append(result, opParams...)
+}
+
+// resolve segue referências locais ($ref: "#/...") retornando o objeto
+// referenciado e seu JSON pointer
+func (d *Document) resolve(node any, pointer string) (map[string]any, string, error) {
+	for depth := 0; depth < 32; depth++ {
+		obj, ok := node.(map[string]any)
+		if !ok {
+			return nil, pointer, fmt.Errorf("%w: %s is not an object", ErrInvalidDocument, pointer)
+		}
+
+		ref, ok := obj["$ref"].(string)
+		if !ok {
+			return obj, pointer, nil
+		}
+		if !strings.HasPrefix(ref, "#/") {
+			return nil, pointer, fmt.Errorf("%w: only local references are supported, got %q", ErrInvalidDocument, ref)
+		}
+
+		pointer = strings.TrimPrefix(ref, "#")
+		if node, ok = lookupPointer(d.root, pointer); !ok {
+			return nil, pointer, fmt.Errorf("%w: unresolved reference %q", ErrInvalidDocument, ref)
+		}
+	}
+	return nil, pointer, fmt.Errorf("%w: reference cycle at %s", ErrInvalidDocument, pointer)
+}
+
+// schema compila (e armazena em cache) o schema localizado no JSON pointer
+func (d *Document) schema(pointer string) (*jsonschema.Schema, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if compiled, ok := d.schemas[pointer]; ok {
+		return compiled, nil
+	}
+
+	compiled, err := d.compiler.Compile(documentURL + "#" + pointer)
+	if err != nil {
+		return nil, fmt.Errorf("openapi: failed to compile schema at %s: %w", pointer, err)
+	}
+	d.schemas[pointer] = compiled
+	return compiled, nil
+}
+
+// findRoute localiza a operação correspondente ao método e caminho
+func (d *Document) findRoute(method, path string) (*route, map[string]string, bool) {
+	if d.basePath != "" {
+		if !strings.HasPrefix(path, d.basePath) {
+			return nil, nil, false
+		}
+		path = strings.TrimPrefix(path, d.basePath)
+	}
+
+	segments := splitPath(path)
+	for _, r := range d.routes {
+		if r.method != method || len(r.segments) != len(segments) {
+			continue
+		}
+		if values, ok := r.match(segments); ok {
+			return r, values, true
+		}
+	}
+	return nil, nil, false
+}
+
+// match verifica se os segmentos correspondem ao template, extraindo os parâmetros
+func (r *route) match(segments []string) (map[string]string, bool) {
+	values := make(map[string]string)
+	for i, segment := range r.segments {
+		if isTemplateSegment(segment) {
+			value, err := url.PathUnescape(segments[i])
+			if err != nil || value == "" {
+				return nil, false
+			}
+			values[segment[1:len(segment)-1]] = value
+			continue
+		}
+		if segment != segments[i] {
+			return nil, false
+		}
+	}
+	return values, true
+}
+
+// splitPath separa o caminho em segmentos
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// isTemplateSegment verifica se o segmento é um parâmetro ({id})
+func isTemplateSegment(segment string) bool {
+	return len(segment) > 2 && segment[0] == '{' && segment[len(segment)-1] == '}'
+}
+
+// escapePointer escapa um token de JSON pointer (RFC 6901)
+func escapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// lookupPointer localiza o valor referenciado pelo JSON pointer
+func lookupPointer(root any, pointer string) (any, bool) {
+	current := root
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		if token == "" {
+			continue
+		}
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch node := current.(type) {
+		case map[string]any:
+			value, ok := node[token]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case []any:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, false
+			}
+			current = node[index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
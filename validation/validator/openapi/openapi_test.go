@@ -0,0 +1,342 @@
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/fsvxavier/nexs-lib/validation/validator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const petstore = `
+openapi: 3.1.0
+info:
+  title: Orders
+  version: "1.0"
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /orders:
+    get:
+      parameters:
+        - $ref: "#/components/parameters/Limit"
+        - name: status
+          in: query
+          schema:
+            type: array
+            items:
+              type: string
+              enum: [open, closed]
+        - name: X-Tenant-ID
+          in: header
+          required: true
+          schema:
+            type: string
+            format: uuid
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: "#/components/schemas/Order"
+    post:
+      requestBody:
+        $ref: "#/components/requestBodies/Order"
+      responses:
+        "201":
+          description: created
+        default:
+          description: error
+          content:
+            application/problem+json:
+              schema:
+                type: object
+                required: [code]
+  /orders/{id}:
+    parameters:
+      - name: id
+        in: path
+        schema:
+          type: integer
+          minimum: 1
+    get:
+      responses:
+        2XX:
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Order"
+  /orders/summary:
+    get:
+      responses:
+        "200":
+          description: ok
+components:
+  parameters:
+    Limit:
+      name: limit
+      in: query
+      schema:
+        type: integer
+        minimum: 1
+        maximum: 100
+  requestBodies:
+    Order:
+      required: true
+      content:
+        application/json:
+          schema:
+            $ref: "#/components/schemas/Order"
+  schemas:
+    Order:
+      type: object
+      required: [customer, items]
+      properties:
+        customer:
+          type: string
+          minLength: 3
+        notes:
+          type: [string, "null"]
+        items:
+          type: array
+          minItems: 1
+          items:
+            $ref: "#/components/schemas/Item"
+        attributes:
+          type: object
+          additionalProperties:
+            type: string
+            maxLength: 5
+    Item:
+      type: object
+      required: [sku, price]
+      properties:
+        sku:
+          type: string
+        price:
+          type: number
+          exclusiveMinimum: 0
+`
+
+func newValidator(t *testing.T, opts ...Option) *Validator {
+	t.Helper()
+	doc, err := Load([]byte(petstore))
+	require.NoError(t, err)
+	return New(doc, opts...)
+}
+
+func fieldErrors(t *testing.T, err error) validator.ValidationErrors {
+	t.Helper()
+	var errs validator.ValidationErrors
+	require.True(t, errors.As(err, &errs), "expected field errors, got %v", err)
+	return errs
+}
+
+func TestLoad(t *testing.T) {
+	doc, err := Load([]byte(petstore))
+	require.NoError(t, err)
+	assert.Equal(t, "3.1.0", doc.Version())
+	assert.Equal(t, "/v1", doc.BasePath())
+
+	_, err = Load([]byte(`{"swagger": "2.0"}`))
+	assert.ErrorIs(t, err, ErrInvalidDocument)
+
+	_, err = Load([]byte(`openapi: 3.1.0
+paths:
+  /x:
+    get:
+      parameters:
+        - $ref: "other.yaml#/Param"
+`))
+	assert.ErrorIs(t, err, ErrInvalidDocument)
+
+	path := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(petstore), 0o600))
+	_, err = LoadFile(path)
+	assert.NoError(t, err)
+
+	_, err = LoadFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestValidateRequest_Parameters(t *testing.T) {
+	v := newValidator(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/orders?limit=10&status=open&status=closed", nil)
+	r.Header.Set("X-Tenant-ID", "5f0c8a52-1b0e-4d7c-9a55-3c2f7f6a4b10")
+	assert.NoError(t, v.ValidateRequest(r))
+
+	r = httptest.NewRequest(http.MethodGet, "/v1/orders?limit=500&status=pending", nil)
+	errs := fieldErrors(t, v.ValidateRequest(r))
+	assert.Equal(t, []string{"query.limit", "query.status[0]", "header.X-Tenant-ID"}, errs.Fields())
+	assert.Equal(t, "maximum", errs[0].Rule)
+	assert.Equal(t, "required", errs[2].Rule)
+
+	r = httptest.NewRequest(http.MethodGet, "/v1/orders?limit=abc", nil)
+	r.Header.Set("X-Tenant-ID", "5f0c8a52-1b0e-4d7c-9a55-3c2f7f6a4b10")
+	errs = fieldErrors(t, v.ValidateRequest(r))
+	assert.Equal(t, "type", errs[0].Rule)
+
+	r = httptest.NewRequest(http.MethodGet, "/v1/orders/0", nil)
+	errs = fieldErrors(t, v.ValidateRequest(r))
+	assert.Equal(t, []string{"path.id"}, errs.Fields())
+
+	// Rotas literais têm precedência sobre templates
+	r = httptest.NewRequest(http.MethodGet, "/v1/orders/summary", nil)
+	assert.NoError(t, v.ValidateRequest(r))
+}
+
+func TestValidateRequest_Body(t *testing.T) {
+	v := newValidator(t)
+
+	body := `{"customer": "ACME", "notes": null, "items": [{"sku": "a", "price": 10}]}`
+	r := httptest.NewRequest(http.MethodPost, "/v1/orders", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
+	require.NoError(t, v.ValidateRequest(r))
+
+	// O corpo continua disponível para o handler
+	restored, err := readBody(r, DefaultMaxBodySize)
+	require.NoError(t, err)
+	assert.JSONEq(t, body, string(restored))
+
+	body = `{"customer": "AC", "items": [{"sku": "a", "price": 1}, {"sku": "b", "price": 1}, {"sku": "c", "price": 1}, {"price": -1}], "attributes": {"color": "turquoise"}}`
+	r = httptest.NewRequest(http.MethodPost, "/v1/orders", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	err = v.ValidateRequest(r)
+
+	var domainErr interfaces.DomainErrorInterface
+	require.True(t, errors.As(err, &domainErr))
+	assert.Equal(t, CodeInvalidRequest, domainErr.Code())
+	assert.Equal(t, http.StatusBadRequest, domainErr.HTTPStatus())
+
+	errs := fieldErrors(t, err)
+	assert.ElementsMatch(t, []string{
+		"body.customer",
+		"body.items[3].sku",
+		"body.items[3].price",
+		"body.attributes.color",
+	}, errs.Fields())
+	assert.Equal(t, "required", errs.ByField("body.items[3].sku")[0].Rule)
+	assert.Equal(t, json.Number("-1"), errs.ByField("body.items[3].price")[0].Value)
+}
+
+func TestValidateRequest_BodyErrors(t *testing.T) {
+	v := newValidator(t)
+
+	r := httptest.NewRequest(http.MethodPost, "/v1/orders", nil)
+	errs := fieldErrors(t, v.ValidateRequest(r))
+	assert.Equal(t, []string{"body"}, errs.Fields())
+
+	r = httptest.NewRequest(http.MethodPost, "/v1/orders", strings.NewReader(`customer=acme`))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	var domainErr interfaces.DomainErrorInterface
+	require.True(t, errors.As(v.ValidateRequest(r), &domainErr))
+	assert.Equal(t, CodeUnsupportedMediaType, domainErr.Code())
+
+	r = httptest.NewRequest(http.MethodPost, "/v1/orders", strings.NewReader(`{invalid`))
+	r.Header.Set("Content-Type", "application/json")
+	require.True(t, errors.As(v.ValidateRequest(r), &domainErr))
+	assert.Equal(t, CodeMalformedBody, domainErr.Code())
+}
+
+func TestValidateRequest_MaxBodySize(t *testing.T) {
+	v := newValidator(t, WithMaxBodySize(64))
+
+	body := `{"customer": "ACME", "items": [{"sku": "a", "price": 10}]}`
+	r := httptest.NewRequest(http.MethodPost, "/v1/orders", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	require.NoError(t, v.ValidateRequest(r))
+
+	body = `{"customer": "ACME", "items": [{"sku": "a", "price": 10}, {"sku": "b", "price": 20}]}`
+	r = httptest.NewRequest(http.MethodPost, "/v1/orders", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	var domainErr interfaces.DomainErrorInterface
+	require.True(t, errors.As(v.ValidateRequest(r), &domainErr))
+	assert.Equal(t, CodeBodyTooLarge, domainErr.Code())
+	assert.Equal(t, http.StatusRequestEntityTooLarge, domainErr.HTTPStatus())
+	assert.Equal(t, int64(64), domainErr.Metadata()["max_size"])
+}
+
+func TestValidateRequest_UnknownRoute(t *testing.T) {
+	r := httptest.NewRequest(http.MethodDelete, "/v1/orders", nil)
+
+	var domainErr interfaces.DomainErrorInterface
+	require.True(t, errors.As(newValidator(t).ValidateRequest(r), &domainErr))
+	assert.Equal(t, CodeRouteNotFound, domainErr.Code())
+	assert.Equal(t, http.StatusNotFound, domainErr.HTTPStatus())
+
+	assert.NoError(t, newValidator(t, WithIgnoreUnknownRoutes()).ValidateRequest(r))
+	assert.NoError(t, newValidator(t, WithIgnoreUnknownRoutes()).ValidateRequest(httptest.NewRequest(http.MethodGet, "/other", nil)))
+}
+
+func TestValidateResponse(t *testing.T) {
+	v := newValidator(t)
+	header := http.Header{"Content-Type": []string{"application/json"}}
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/orders/1", nil)
+	assert.NoError(t, v.ValidateResponse(r, http.StatusOK, header, []byte(`{"customer": "ACME", "items": [{"sku": "a", "price": 1}]}`)))
+
+	err := v.ValidateResponse(r, http.StatusOK, header, []byte(`{"customer": "ACME", "items": []}`))
+	errs := fieldErrors(t, err)
+	assert.Equal(t, []string{"body.items"}, errs.Fields())
+
+	var domainErr interfaces.DomainErrorInterface
+	require.True(t, errors.As(err, &domainErr))
+	assert.Equal(t, interfaces.ServerError, domainErr.Type())
+
+	r = httptest.NewRequest(http.MethodPost, "/v1/orders", nil)
+	problem := http.Header{"Content-Type": []string{"application/problem+json"}}
+	assert.NoError(t, v.ValidateResponse(r, http.StatusCreated, nil, nil))
+	assert.Error(t, v.ValidateResponse(r, http.StatusConflict, problem, []byte(`{}`)))
+	assert.Error(t, v.ValidateResponse(r, http.StatusConflict, header, []byte(`{"code": "x"}`)))
+
+	r = httptest.NewRequest(http.MethodGet, "/v1/orders/summary", nil)
+	assert.Error(t, v.ValidateResponse(r, http.StatusInternalServerError, header, []byte(`{}`)))
+}
+
+func TestMiddleware(t *testing.T) {
+	var called bool
+	handler := newValidator(t).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	r := httptest.NewRequest(http.MethodPost, "/v1/orders", strings.NewReader(`{"customer": "ACME"}`))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	assert.False(t, called)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{
+		"code": "OPENAPI_INVALID_REQUEST",
+		"message": "request does not match the API specification",
+		"errors": [{"field": "body.items", "rule": "required", "message": "is required"}]
+	}`, w.Body.String())
+
+	r = httptest.NewRequest(http.MethodPost, "/v1/orders", strings.NewReader(`{"customer": "ACME", "items": [{"sku": "a", "price": 1}]}`))
+	r.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.True(t, called)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	custom := newValidator(t, WithErrorHandler(func(w http.ResponseWriter, _ *http.Request, err interfaces.DomainErrorInterface) {
+		w.WriteHeader(http.StatusTeapot)
+	})).Middleware(http.NotFoundHandler())
+	w = httptest.NewRecorder()
+	custom.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/orders/abc", nil))
+	assert.Equal(t, http.StatusTeapot, w.Code)
+}
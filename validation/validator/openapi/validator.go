@@ -0,0 +1,620 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fsvxavier/nexs-lib/domainerrors"
+	"github.com/fsvxavier/nexs-lib/domainerrors/interfaces"
+	"github.com/fsvxavier/nexs-lib/validation/validator"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// Códigos dos erros de domínio retornados pelo Validator
+const (
+	CodeRouteNotFound        = "OPENAPI_ROUTE_NOT_FOUND"
+	CodeInvalidRequest       = "OPENAPI_INVALID_REQUEST"
+	CodeInvalidResponse      = "OPENAPI_INVALID_RESPONSE"
+	CodeUnsupportedMediaType = "OPENAPI_UNSUPPORTED_MEDIA_TYPE"
+	CodeMalformedBody        = "OPENAPI_MALFORMED_BODY"
+	CodeBodyTooLarge         = "OPENAPI_BODY_TOO_LARGE"
+)
+
+// DefaultMaxBodySize limite padrão do corpo lido para validação
+const DefaultMaxBodySize = 1 << 20
+
+// errBodyTooLarge indica um corpo acima de MaxBodySize
+var errBodyTooLarge = errors.New("request body too large")
+
+// ErrorHandler escreve a resposta HTTP para um erro de validação
+type ErrorHandler func(w http.ResponseWriter, r *http.Request, err interfaces.DomainErrorInterface)
+
+// Option configura o Validator
+type Option func(*Validator)
+
+// WithIgnoreUnknownRoutes faz requisições sem operação correspondente no
+// documento serem aceitas sem validação
+func WithIgnoreUnknownRoutes() Option {
+	return func(v *Validator) {
+		v.ignoreUnknownRoutes = true
+	}
+}
+
+// WithErrorHandler define como o middleware responde a requisições inválidas
+func WithErrorHandler(handler ErrorHandler) Option {
+	return func(v *Validator) {
+		if handler != nil {
+			v.errorHandler = handler
+		}
+	}
+}
+
+// WithMaxBodySize limita o tamanho do corpo lido para validação; corpos
+// maiores são rejeitados com 413. O padrão é DefaultMaxBodySize
+func WithMaxBodySize(size int64) Option {
+	return func(v *Validator) {
+		if size > 0 {
+			v.maxBodySize = size
+		}
+	}
+}
+
+// Validator valida requisições e respostas HTTP contra um Document.
+// É seguro para uso concorrente
+type Validator struct {
+	doc                 *Document
+	ignoreUnknownRoutes bool
+	errorHandler        ErrorHandler
+	maxBodySize         int64
+	printer             *message.Printer
+}
+
+// New cria um novo Validator para o documento
+func New(doc *Document, opts ...Option) *Validator {
+	v := &Validator{
+		doc:          doc,
+		errorHandler: DefaultErrorHandler,
+		maxBodySize:  DefaultMaxBodySize,
+		printer:      message.NewPrinter(language.English),
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// ValidateRequest valida parâmetros de caminho, query, headers, cookies e o
+// corpo da requisição. O corpo é restaurado para leitura posterior. Erros de
+// campo são retornados como validator.ValidationErrors encapsulados no erro
+// de domínio, com caminhos como query.limit e body.items[0].price
+func (v *Validator) ValidateRequest(r *http.Request) error {
+	rt, pathValues, ok := v.doc.findRoute(r.Method, r.URL.Path)
+	if !ok {
+		if v.ignoreUnknownRoutes {
+			return nil
+		}
+		return domainerrors.NewNotFoundError(CodeRouteNotFound,
+			fmt.Sprintf("no operation for %s %s", r.Method, r.URL.Path))
+	}
+
+	var errs validator.ValidationErrors
+	for _, p := range rt.params {
+		if err := v.validateParameter(r, p, pathValues, &errs); err != nil {
+			return err
+		}
+	}
+
+	if err := v.validateRequestBody(r, rt, &errs); err != nil {
+		return err
+	}
+
+	if len(errs) > 0 {
+		return newFieldsError(interfaces.ValidationError, CodeInvalidRequest, "request does not match the API specification", errs)
+	}
+	return nil
+}
+
+// ValidateResponse valida o corpo da resposta de uma operação contra o schema
+// declarado para o status (ou faixa 2XX, ou default)
+func (v *Validator) ValidateResponse(r *http.Request, status int, header http.Header, body []byte) error {
+	rt, _, ok := v.doc.findRoute(r.Method, r.URL.Path)
+	if !ok {
+		if v.ignoreUnknownRoutes {
+			return nil
+		}
+		return domainerrors.NewNotFoundError(CodeRouteNotFound,
+			fmt.Sprintf("no operation for %s %s", r.Method, r.URL.Path))
+	}
+
+	responses, _ := rt.operation["responses"].(map[string]any)
+	key, raw, ok := matchResponse(responses, status)
+	if !ok {
+		return newServerError(fmt.Sprintf("status %d is not declared for %s %s", status, r.Method, rt.template), nil)
+	}
+
+	pointer := rt.pointer + "/responses/" + escapePointer(key)
+	response, pointer, err := v.doc.resolve(raw, pointer)
+	if err != nil {
+		return err
+	}
+
+	content, _ := response["content"].(map[string]any)
+	if len(content) == 0 || len(body) == 0 {
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	contentKey, ok := matchMediaType(content, mediaType)
+	if !ok {
+		return newServerError(fmt.Sprintf("content type %q is not declared for status %d", mediaType, status), nil)
+	}
+
+	var errs validator.ValidationErrors
+	if err := v.validateContent(content, contentKey, pointer+"/content", body, "body", &errs); err != nil {
+		return newServerError("malformed response body", err)
+	}
+
+	if len(errs) > 0 {
+		return newFieldsError(interfaces.ServerError, CodeInvalidResponse, "response does not match the API specification", errs)
+	}
+	return nil
+}
+
+// Middleware valida as requisições antes de encaminhá-las ao handler
+func (v *Validator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := v.ValidateRequest(r); err != nil {
+			domainErr, ok := err.(interfaces.DomainErrorInterface)
+			if !ok {
+				domainErr = domainerrors.New(interfaces.BadRequestError, CodeInvalidRequest, err.Error())
+			}
+			v.errorHandler(w, r, domainErr)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// errorResponse corpo JSON escrito pelo DefaultErrorHandler
+type errorResponse struct {
+	Code    string                 `json:"code"`
+	Message string                 `json:"message"`
+	Errors  []validator.FieldError `json:"errors,omitempty"`
+}
+
+// DefaultErrorHandler responde com o status do erro de domínio e um corpo
+// JSON com código, mensagem e erros por campo
+func DefaultErrorHandler(w http.ResponseWriter, _ *http.Request, err interfaces.DomainErrorInterface) {
+	body := errorResponse{Code: err.Code(), Message: err.Error()}
+	if fields, ok := err.Unwrap().(validator.ValidationErrors); ok {
+		body.Errors = fields
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(err.HTTPStatus())
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// validateParameter valida um parâmetro da requisição
+func (v *Validator) validateParameter(r *http.Request, p *parameter, pathValues map[string]string, errs *validator.ValidationErrors) error {
+	field := p.in + "." + p.name
+
+	values, present := parameterValues(r, p, pathValues)
+	if !present {
+		if p.required {
+			*errs = append(*errs, validator.FieldError{
+				Field:   field,
+				Rule:    "required",
+				Message: "is required",
+			})
+		}
+		return nil
+	}
+
+	if p.pointer == "" {
+		return nil
+	}
+
+	schema, err := v.doc.schema(p.pointer)
+	if err != nil {
+		return err
+	}
+
+	value := coerceParameter(values, p)
+	if err := schema.Validate(value); err != nil {
+		v.appendSchemaErrors(err, field, value, errs)
+	}
+	return nil
+}
+
+// parameterValues extrai os valores brutos do parâmetro
+func parameterValues(r *http.Request, p *parameter, pathValues map[string]string) ([]string, bool) {
+	switch p.in {
+	case "path":
+		value, ok := pathValues[p.name]
+		return []string{value}, ok
+	case "query":
+		values, ok := r.URL.Query()[p.name]
+		return values, ok
+	case "header":
+		values := r.Header.Values(p.name)
+		return values, len(values) > 0
+	case "cookie":
+		cookie, err := r.Cookie(p.name)
+		if err != nil {
+			return nil, false
+		}
+		return []string{cookie.Value}, true
+	default:
+		return nil, false
+	}
+}
+
+// coerceParameter converte os valores textuais para o tipo declarado no
+// schema. Valores não conversíveis são mantidos como string para que o
+// schema reporte o erro de tipo
+func coerceParameter(values []string, p *parameter) any {
+	schemaType := typeOf(p.schema)
+	if schemaType != "array" {
+		if len(values) == 0 {
+			return ""
+		}
+		return coerceScalar(values[0], schemaType)
+	}
+
+	if !p.explode || len(values) == 1 {
+		var split []string
+		for _, value := range values {
+			split = append(split, strings.Split(value, ",")...)
+		}
+		values = split
+	}
+
+	itemType := ""
+	if items, ok := p.schema["items"].(map[string]any); ok {
+		itemType = typeOf(items)
+	}
+
+	result := make([]any, len(values))
+	for i, value := range values {
+		result[i] = coerceScalar(value, itemType)
+	}
+	return result
+}
+
+// typeOf retorna o tipo declarado no schema (o primeiro, quando é uma lista)
+func typeOf(schema map[string]any) string {
+	switch t := schema["type"].(type) {
+	case string:
+		return t
+	case []any:
+		for _, item := range t {
+			if s, ok := item.(string); ok && s != "null" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// coerceScalar converte um valor textual para integer, number ou boolean
+func coerceScalar(value, schemaType string) any {
+	switch schemaType {
+	case "integer":
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+// validateRequestBody valida o corpo da requisição contra o requestBody da operação
+func (v *Validator) validateRequestBody(r *http.Request, rt *route, errs *validator.ValidationErrors) error {
+	raw, ok := rt.operation["requestBody"]
+	if !ok {
+		return nil
+	}
+
+	requestBody, pointer, err := v.doc.resolve(raw, rt.pointer+"/requestBody")
+	if err != nil {
+		return err
+	}
+
+	body, err := readBody(r, v.maxBodySize)
+	if errors.Is(err, errBodyTooLarge) {
+		return domainerrors.NewWithMetadata(interfaces.PayloadTooLargeError, CodeBodyTooLarge,
+			"request body too large", map[string]interface{}{"max_size": v.maxBodySize}).Wrap(err)
+	}
+	if err != nil {
+		return domainerrors.New(interfaces.BadRequestError, CodeMalformedBody, "failed to read request body").Wrap(err)
+	}
+
+	if len(body) == 0 {
+		if required, _ := requestBody["required"].(bool); required {
+			*errs = append(*errs, validator.FieldError{
+				Field:   "body",
+				Rule:    "required",
+				Message: "is required",
+			})
+		}
+		return nil
+	}
+
+	content, _ := requestBody["content"].(map[string]any)
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	contentKey, ok := matchMediaType(content, mediaType)
+	if !ok {
+		return domainerrors.New(interfaces.UnsupportedMediaTypeError, CodeUnsupportedMediaType,
+			fmt.Sprintf("content type %q is not supported", mediaType))
+	}
+
+	if err := v.validateContent(content, contentKey, pointer+"/content", body, "body", errs); err != nil {
+		return domainerrors.New(interfaces.BadRequestError, CodeMalformedBody, "malformed request body").Wrap(err)
+	}
+	return nil
+}
+
+// validateContent valida um corpo JSON contra o schema do media type
+func (v *Validator) validateContent(content map[string]any, contentKey, pointer string, body []byte, field string, errs *validator.ValidationErrors) error {
+	media, _ := content[contentKey].(map[string]any)
+	if _, ok := media["schema"]; !ok || !isJSONMediaType(contentKey) {
+		return nil
+	}
+
+	data, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	schema, err := v.doc.schema(pointer + "/" + escapePointer(contentKey) + "/schema")
+	if err != nil {
+		return err
+	}
+
+	if err := schema.Validate(data); err != nil {
+		v.appendSchemaErrors(err, field, data, errs)
+	}
+	return nil
+}
+
+// readBody lê até limit bytes do corpo da requisição e o restaura para os
+// próximos handlers. Retorna errBodyTooLarge se o corpo exceder limit
+func readBody(r *http.Request, limit int64) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, errBodyTooLarge
+	}
+	_ = r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// appendSchemaErrors converte os erros do JSON Schema em erros de campo
+func (v *Validator) appendSchemaErrors(err error, prefix string, data any, errs *validator.ValidationErrors) {
+	validationErr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		*errs = append(*errs, validator.FieldError{Field: prefix, Rule: "schema", Message: err.Error()})
+		return
+	}
+
+	for _, leaf := range leafErrors(validationErr) {
+		path := formatPath(prefix, leaf.InstanceLocation, data)
+
+		if required, ok := leaf.ErrorKind.(*kind.Required); ok {
+			for _, missing := range required.Missing {
+				*errs = append(*errs, validator.FieldError{
+					Field:   joinField(path, missing),
+					Rule:    "required",
+					Message: "is required",
+				})
+			}
+			continue
+		}
+
+		keywordPath := leaf.ErrorKind.KeywordPath()
+		rule := "schema"
+		if len(keywordPath) > 0 {
+			rule = keywordPath[len(keywordPath)-1]
+		}
+
+		value, _ := lookupInstance(data, leaf.InstanceLocation)
+		*errs = append(*errs, validator.FieldError{
+			Field:   path,
+			Rule:    rule,
+			Message: leaf.ErrorKind.LocalizedString(v.printer),
+			Value:   value,
+		})
+	}
+}
+
+// leafErrors retorna os erros sem causas (os mais específicos)
+func leafErrors(err *jsonschema.ValidationError) []*jsonschema.ValidationError {
+	if len(err.Causes) == 0 {
+		return []*jsonschema.ValidationError{err}
+	}
+
+	var leaves []*jsonschema.ValidationError
+	for _, cause := range err.Causes {
+		leaves = append(leaves, leafErrors(cause)...)
+	}
+	return leaves
+}
+
+// identifierPattern nomes de propriedades que dispensam colchetes no caminho
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// formatPath converte a localização do JSON Schema para o formato de caminho
+// do validator (ex.: body.items[0].price, body.attributes["x.y"])
+func formatPath(prefix string, location []string, data any) string {
+	var sb strings.Builder
+	sb.WriteString(prefix)
+
+	current := data
+	for _, token := range location {
+		if list, ok := current.([]any); ok {
+			if index, err := strconv.Atoi(token); err == nil && index >= 0 && index < len(list) {
+				sb.WriteString("[" + token + "]")
+				current = list[index]
+				continue
+			}
+		}
+
+		sb.WriteString(propertyPath(sb.Len() == 0, token))
+		if obj, ok := current.(map[string]any); ok {
+			current = obj[token]
+		} else {
+			current = nil
+		}
+	}
+	return sb.String()
+}
+
+// joinField adiciona uma propriedade ao caminho
+func joinField(path, name string) string {
+	return path + propertyPath(path == "", name)
+}
+
+// propertyPath formata o acesso a uma propriedade
+func propertyPath(first bool, name string) string {
+	if !identifierPattern.MatchString(name) {
+		return "[" + strconv.Quote(name) + "]"
+	}
+	if first {
+		return name
+	}
+	return "." + name
+}
+
+// lookupInstance retorna o valor na localização informada
+func lookupInstance(data any, location []string) (any, bool) {
+	current := data
+	for _, token := range location {
+		switch node := current.(type) {
+		case map[string]any:
+			value, ok := node[token]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case []any:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, false
+			}
+			current = node[index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// matchMediaType localiza o media type declarado que aceita o tipo informado,
+// considerando curingas (application/*, */*)
+func matchMediaType(content map[string]any, mediaType string) (string, bool) {
+	if _, ok := content[mediaType]; ok {
+		return mediaType, true
+	}
+
+	if major, _, ok := strings.Cut(mediaType, "/"); ok {
+		if _, ok := content[major+"/*"]; ok {
+			return major + "/*", true
+		}
+	}
+
+	if _, ok := content["*/*"]; ok {
+		return "*/*", true
+	}
+	return "", false
+}
+
+// isJSONMediaType verifica se o media type é JSON (application/json, +json ou curingas)
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") ||
+		mediaType == "application/*" || mediaType == "*/*"
+}
+
+// matchResponse localiza a resposta declarada para o status: código exato,
+// faixa (2XX) ou default
+func matchResponse(responses map[string]any, status int) (string, any, bool) {
+	code := strconv.Itoa(status)
+	if raw, ok := responses[code]; ok {
+		return code, raw, true
+	}
+
+	rangeKey := code[:1] + "XX"
+	for key, raw := range responses {
+		if strings.EqualFold(key, rangeKey) {
+			return key, raw, true
+		}
+	}
+
+	if raw, ok := responses["default"]; ok {
+		return "default", raw, true
+	}
+	return "", nil, false
+}
+
+// newFieldsError cria o erro de domínio com os erros de campo
+func newFieldsError(errorType interfaces.ErrorType, code, msg string, errs validator.ValidationErrors) interfaces.DomainErrorInterface {
+	sort.SliceStable(errs, func(i, j int) bool {
+		return fieldOrder(errs[i].Field) < fieldOrder(errs[j].Field)
+	})
+
+	return domainerrors.New(errorType, code, msg).
+		WithMetadata("fields", errs.ToMap()).
+		Wrap(errs)
+}
+
+// newServerError cria o erro de domínio para respostas fora da especificação
+func newServerError(msg string, cause error) interfaces.DomainErrorInterface {
+	err := domainerrors.New(interfaces.ServerError, CodeInvalidResponse, msg)
+	if cause != nil {
+		return err.Wrap(cause)
+	}
+	return err
+}
+
+// fieldOrder ordena os erros por localização: path, query, header, cookie e body
+func fieldOrder(field string) int {
+	location, _, _ := strings.Cut(field, ".")
+	switch {
+	case location == "path":
+		return 0
+	case location == "query":
+		return 1
+	case location == "header":
+		return 2
+	case location == "cookie":
+		return 3
+	default:
+		return 4
+	}
+}
@@ -0,0 +1,224 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Mode indica a operação em validação, permitindo regras obrigatórias
+// apenas na criação ou apenas na atualização
+type Mode int
+
+const (
+	// ModeCreate validação de criação (ValidateStruct e Validate)
+	ModeCreate Mode = iota
+	// ModeUpdate validação de atualização (ValidatePartial)
+	ModeUpdate
+)
+
+// String retorna o nome do modo
+func (m Mode) String() string {
+	switch m {
+	case ModeCreate:
+		return "create"
+	case ModeUpdate:
+		return "update"
+	default:
+		return fmt.Sprintf("Mode(%d)", int(m))
+	}
+}
+
+// InMode retorna um predicado verdadeiro quando a validação está no modo informado
+func InMode(mode Mode) Predicate {
+	return func(fc *FieldContext) bool {
+		return fc.Mode == mode
+	}
+}
+
+// RequiredOnCreate exige o campo apenas na criação
+func RequiredOnCreate() *Rule {
+	return When(InMode(ModeCreate), Required())
+}
+
+// RequiredOnUpdate exige o campo apenas na atualização. Na validação parcial,
+// vale somente quando o campo foi informado (ex.: enviado como null)
+func RequiredOnUpdate() *Rule {
+	return When(InMode(ModeUpdate), Required())
+}
+
+// ValidatePartial valida a struct usando o Validator padrão, aplicando as
+// regras somente aos campos alterados
+func ValidatePartial(ctx context.Context, s interface{}, changedFields []string) error {
+	return defaultValidator.ValidatePartial(ctx, s, changedFields)
+}
+
+// ValidatePartial valida a struct no modo de atualização aplicando as regras
+// somente aos campos alterados, com a semântica do JSON merge patch (RFC 7396):
+// campos ausentes são mantidos e não são validados. Os campos podem ser
+// informados pelo nome Go, pelo nome JSON ou por caminhos com pontos
+// (ex.: address.city). Um campo informado valida também todo o seu conteúdo
+func (v *Validator) ValidatePartial(ctx context.Context, s interface{}, changedFields []string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	root := indirect(reflect.ValueOf(s))
+	if !root.IsValid() || root.Kind() != reflect.Struct {
+		return ErrInvalidInput
+	}
+
+	scope := make(fieldSet, len(changedFields))
+	for _, field := range changedFields {
+		path, err := resolveFieldPath(root.Type(), field)
+		if err != nil {
+			return err
+		}
+		scope[path] = true
+	}
+
+	result, err := v.validateRoot(ctx, ModeUpdate, scope, s)
+	if err != nil {
+		return err
+	}
+	return result.Err()
+}
+
+// fieldSet caminhos dos campos validados na validação parcial. Um conjunto
+// nil representa a validação completa
+type fieldSet map[string]bool
+
+// includes verifica se as regras do campo devem ser aplicadas, ou seja, se o
+// campo ou um de seus ancestrais foi informado
+func (s fieldSet) includes(path string) bool {
+	if s == nil {
+		return true
+	}
+	for changed := range s {
+		if isPathPrefix(changed, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// visits verifica se o campo deve ser navegado: quando está incluído ou
+// quando algum de seus descendentes foi informado
+func (s fieldSet) visits(path string) bool {
+	if s == nil {
+		return true
+	}
+	for changed := range s {
+		if isPathPrefix(changed, path) || isPathPrefix(path, changed) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPathPrefix verifica se prefix é igual ou ancestral de path
+func isPathPrefix(prefix, path string) bool {
+	if !strings.HasPrefix(path, prefix) {
+		return false
+	}
+	rest := path[len(prefix):]
+	return rest == "" || rest[0] == '.' || rest[0] == '['
+}
+
+// resolveFieldPath converte o caminho informado para o caminho usado nos
+// erros (nomes JSON). Partes após um campo que não é struct (slices, maps)
+// são descartadas, considerando alterado o campo inteiro
+func resolveFieldPath(t reflect.Type, field string) (string, error) {
+	names := make([]string, 0, strings.Count(field, ".")+1)
+	for _, part := range strings.Split(field, ".") {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct || t == reflect.TypeOf(time.Time{}) {
+			break
+		}
+
+		meta := getStructMeta(t)
+		idx, ok := meta.lookup[part]
+		if !ok {
+			return "", fmt.Errorf("validator: field %q not found in %s", field, t)
+		}
+		names = append(names, meta.fields[idx].name)
+		t = t.Field(meta.fields[idx].index).Type
+	}
+	return strings.Join(names, "."), nil
+}
+
+// ChangedFields extrai os caminhos dos campos presentes em um JSON merge patch,
+// para uso com ValidatePartial. Objetos aninhados geram caminhos com pontos
+// (ex.: address.city); arrays, valores simples e null são considerados por inteiro
+func ChangedFields(patch []byte) ([]string, error) {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &object); err != nil {
+		return nil, fmt.Errorf("validator: invalid merge patch: %w", err)
+	}
+
+	var fields []string
+	collectChangedFields("", object, &fields)
+	sort.Strings(fields)
+	return fields, nil
+}
+
+// collectChangedFields percorre o patch acumulando os caminhos alterados
+func collectChangedFields(prefix string, object map[string]json.RawMessage, fields *[]string) {
+	for key, raw := range object {
+		path := joinPath(prefix, key)
+
+		var nested map[string]json.RawMessage
+		trimmed := bytes.TrimSpace(raw)
+		if len(trimmed) > 0 && trimmed[0] == '{' && json.Unmarshal(trimmed, &nested) == nil && len(nested) > 0 {
+			collectChangedFields(path, nested, fields)
+			continue
+		}
+		*fields = append(*fields, path)
+	}
+}
+
+// DiffFields compara duas structs do mesmo tipo e retorna os caminhos dos
+// campos com valores diferentes, navegando em structs aninhadas
+func DiffFields(before, after interface{}) ([]string, error) {
+	a := indirect(reflect.ValueOf(before))
+	b := indirect(reflect.ValueOf(after))
+	if !a.IsValid() || !b.IsValid() || a.Kind() != reflect.Struct || a.Type() != b.Type() {
+		return nil, ErrInvalidInput
+	}
+
+	var fields []string
+	diffStruct("", a, b, &fields)
+	return fields, nil
+}
+
+// diffStruct acumula os campos diferentes entre duas structs do mesmo tipo
+func diffStruct(prefix string, a, b reflect.Value, fields *[]string) {
+	for _, fm := range getStructMeta(a.Type()).fields {
+		path := joinPath(prefix, fm.name)
+		fa, fb := a.Field(fm.index), b.Field(fm.index)
+
+		ia, ib := indirect(fa), indirect(fb)
+		if ia.IsValid() && ib.IsValid() && ia.Kind() == reflect.Struct {
+			ta, isTime := asTime(ia)
+			if !isTime {
+				diffStruct(path, ia, ib, fields)
+				continue
+			}
+			if tb, _ := asTime(ib); !ta.Equal(tb) {
+				*fields = append(*fields, path)
+			}
+			continue
+		}
+
+		if !reflect.DeepEqual(fa.Interface(), fb.Interface()) {
+			*fields = append(*fields, path)
+		}
+	}
+}
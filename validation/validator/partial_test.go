@@ -0,0 +1,114 @@
+package validator
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type profile struct {
+	Bio     string `json:"bio" validate:"max_length=10"`
+	Website string `json:"website" validate:"required"`
+}
+
+type userRequest struct {
+	ID       string    `json:"id" validate:"required_on_update"`
+	Name     string    `json:"name" validate:"required,min_length=3"`
+	Email    string    `json:"email" validate:"required,email"`
+	Password string    `json:"password" validate:"required_on_create,min_length=8"`
+	Tags     []string  `json:"tags" validate:"dive,min_length=2"`
+	Profile  *profile  `json:"profile"`
+	Birth    time.Time `json:"birth"`
+}
+
+func TestValidatePartial(t *testing.T) {
+	ctx := context.Background()
+
+	// Campos ausentes no PATCH não são validados
+	patch := userRequest{Name: "John"}
+	assert.NoError(t, ValidatePartial(ctx, &patch, []string{"name"}))
+
+	errs := validationErrors(t, ValidatePartial(ctx, &userRequest{Name: "Jo"}, []string{"Name", "email"}))
+	assert.Equal(t, []string{"name", "email"}, errs.Fields())
+	assert.Equal(t, "min_length", errs[0].Rule)
+	assert.Equal(t, "required", errs[1].Rule)
+
+	// Um campo informado valida todo o seu conteúdo
+	patch = userRequest{Tags: []string{"ok", "x"}, Profile: &profile{Bio: "a very long biography"}}
+	errs = validationErrors(t, ValidatePartial(ctx, &patch, []string{"tags", "profile"}))
+	assert.Equal(t, []string{"tags[1]", "profile.bio", "profile.website"}, errs.Fields())
+
+	// Campos aninhados podem ser informados individualmente
+	errs = validationErrors(t, ValidatePartial(ctx, &patch, []string{"profile.bio"}))
+	assert.Equal(t, []string{"profile.bio"}, errs.Fields())
+
+	assert.NoError(t, ValidatePartial(ctx, &patch, nil))
+
+	_, err := resolveFieldPath(reflect.TypeOf(patch), "profile.unknown")
+	assert.Error(t, err)
+	assert.Error(t, ValidatePartial(ctx, &patch, []string{"unknown"}))
+	assert.ErrorIs(t, ValidatePartial(ctx, "string", nil), ErrInvalidInput)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, ValidatePartial(canceled, &patch, []string{"name"}), context.Canceled)
+}
+
+func TestValidatePartial_Modes(t *testing.T) {
+	create := userRequest{Name: "John", Email: "john@example.com"}
+	errs := validationErrors(t, ValidateStruct(create))
+	assert.Equal(t, []string{"password"}, errs.Fields())
+
+	create.Password = "supersecret"
+	assert.NoError(t, ValidateStruct(create))
+
+	// Na atualização a senha não é obrigatória, mas o id é
+	update := userRequest{}
+	assert.NoError(t, ValidatePartial(context.Background(), &update, []string{"password"}))
+	errs = validationErrors(t, ValidatePartial(context.Background(), &update, []string{"id", "password"}))
+	assert.Equal(t, []string{"id"}, errs.Fields())
+
+	update.Password = "short"
+	errs = validationErrors(t, ValidatePartial(context.Background(), &update, []string{"password"}))
+	assert.Equal(t, "min_length", errs[0].Rule)
+
+	rule := NewRule("mode", "invalid mode", func(fc *FieldContext) bool {
+		return fc.Mode == ModeCreate && fc.Context() != nil
+	})
+	assert.NoError(t, Validate(map[string]string{"x": "1"}, NewRuleBuilder().Field("x", rule).Build()))
+	assert.Equal(t, "update", ModeUpdate.String())
+}
+
+func TestChangedFields(t *testing.T) {
+	fields, err := ChangedFields([]byte(`{"name": "John", "profile": {"bio": null, "links": {}}, "tags": ["a"]}`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"name", "profile.bio", "profile.links", "tags"}, fields)
+
+	_, err = ChangedFields([]byte(`["name"]`))
+	assert.Error(t, err)
+}
+
+func TestDiffFields(t *testing.T) {
+	birth := time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)
+	before := userRequest{Name: "John", Tags: []string{"a"}, Profile: &profile{Bio: "old"}, Birth: birth}
+	after := before
+	after.Tags = []string{"a", "b"}
+	after.Profile = &profile{Bio: "new"}
+	after.Birth = birth.In(time.FixedZone("BRT", -3*3600))
+
+	fields, err := DiffFields(before, &after)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tags", "profile.bio"}, fields)
+
+	after.Profile = nil
+	fields, err = DiffFields(before, after)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tags", "profile"}, fields)
+
+	_, err = DiffFields(before, profile{})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
@@ -0,0 +1,353 @@
+package validator
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CharClass conjunto de classes de caracteres exigidas em senhas
+type CharClass uint8
+
+const (
+	// ClassLower letras minúsculas
+	ClassLower CharClass = 1 << iota
+	// ClassUpper letras maiúsculas
+	ClassUpper
+	// ClassDigit dígitos
+	ClassDigit
+	// ClassSymbol símbolos, espaços e demais caracteres
+	ClassSymbol
+)
+
+// Motivos de rejeição retornados em Params["reasons"] pela regra Password
+const (
+	PasswordTooShort      = "too_short"
+	PasswordMissingLower  = "missing_lower"
+	PasswordMissingUpper  = "missing_upper"
+	PasswordMissingDigit  = "missing_digit"
+	PasswordMissingSymbol = "missing_symbol"
+	PasswordTooFewClasses = "too_few_classes"
+	PasswordCommon        = "common_password"
+	PasswordWeak          = "weak"
+)
+
+// PasswordPolicy define os requisitos da regra Password
+type PasswordPolicy struct {
+	// MinLength tamanho mínimo em caracteres
+	MinLength int
+	// Require classes de caracteres obrigatórias
+	Require CharClass
+	// MinClasses número mínimo de classes distintas
+	MinClasses int
+	// MinScore pontuação mínima de PasswordStrength, de 0 a 4
+	MinScore int
+	// DenyList senhas comuns rejeitadas, inclusive com variações simples
+	// (maiúsculas, l33t e dígitos ou símbolos no início ou no fim)
+	DenyList *DenyList
+}
+
+// DefaultPasswordPolicy política usada pela tag password: ao menos 8
+// caracteres, 3 classes e pontuação 2
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:  8,
+		MinClasses: 3,
+		MinScore:   2,
+	}
+}
+
+// Check retorna os motivos pelos quais a senha não atende à política, ou nil
+// quando ela é aceita
+func (p PasswordPolicy) Check(password string) []string {
+	var reasons []string
+	if utf8.RuneCountInString(password) < p.MinLength {
+		reasons = append(reasons, PasswordTooShort)
+	}
+
+	classes := charClasses(password)
+	for _, required := range []struct {
+		class  CharClass
+		reason string
+	}{
+		{ClassLower, PasswordMissingLower},
+		{ClassUpper, PasswordMissingUpper},
+		{ClassDigit, PasswordMissingDigit},
+		{ClassSymbol, PasswordMissingSymbol},
+	} {
+		if p.Require&required.class != 0 && classes&required.class == 0 {
+			reasons = append(reasons, required.reason)
+		}
+	}
+	if classes.count() < p.MinClasses {
+		reasons = append(reasons, PasswordTooFewClasses)
+	}
+
+	if p.DenyList.Contains(password) {
+		reasons = append(reasons, PasswordCommon)
+	}
+	if p.MinScore > 0 && PasswordStrength(password, p.DenyList).Score < p.MinScore {
+		reasons = append(reasons, PasswordWeak)
+	}
+	return reasons
+}
+
+// Password valida a senha conforme a política. Os motivos da rejeição ficam em
+// Params["reasons"], junto com score e entropy; o valor do campo é omitido
+// dos erros
+func Password(policy PasswordPolicy) *Rule {
+	return &Rule{
+		name:    "password",
+		message: "does not meet the password policy: {reasons}",
+		params: map[string]any{
+			"min_length": policy.MinLength,
+			"min_score":  policy.MinScore,
+		},
+		check: func(fc *FieldContext) bool {
+			v := indirect(fc.Value)
+			return v.IsValid() && v.Kind() == reflect.String && policy.Check(v.String()) == nil
+		},
+		details: func(fc *FieldContext) map[string]any {
+			v := indirect(fc.Value)
+			if !v.IsValid() || v.Kind() != reflect.String {
+				return nil
+			}
+			strength := PasswordStrength(v.String(), policy.DenyList)
+			return map[string]any{
+				"reasons": policy.Check(v.String()),
+				"score":   strength.Score,
+				"entropy": math.Round(strength.Entropy*10) / 10,
+			}
+		},
+		sensitive: true,
+	}
+}
+
+// PasswordScore estimativa da força de uma senha
+type PasswordScore struct {
+	// Score de 0 (muito fraca) a 4 (muito forte)
+	Score int
+	// Entropy entropia estimada em bits
+	Entropy float64
+}
+
+// scoreThresholds limites de entropia, em bits, das pontuações 1 a 4
+var scoreThresholds = [...]float64{28, 36, 60, 128}
+
+// PasswordStrength estima a força da senha no estilo do zxcvbn: caracteres
+// que repetem o anterior, continuam uma sequência (abc, 321) ou são vizinhos
+// no teclado valem 1 bit, e senhas da deny list (com variações) valem apenas
+// o tamanho da lista mais as decorações. A pontuação segue os limites 28, 36,
+// 60 e 128 bits
+func PasswordStrength(password string, deny *DenyList) PasswordScore {
+	var entropy float64
+	if word, decoration, ok := deny.match(password); ok {
+		entropy = math.Log2(float64(max(deny.Len(), 2))) + patternEntropy(decoration)
+		if word != strings.ToLower(word) {
+			entropy++
+		}
+	} else {
+		entropy = patternEntropy(password)
+	}
+
+	score := 0
+	for _, threshold := range scoreThresholds {
+		if entropy >= threshold {
+			score++
+		}
+	}
+	return PasswordScore{Score: score, Entropy: entropy}
+}
+
+// patternEntropy soma a entropia de cada caractere, penalizando repetições,
+// sequências e vizinhos no teclado
+func patternEntropy(s string) float64 {
+	bitsPerChar := math.Log2(float64(charClasses(s).cardinality()))
+	var entropy float64
+	var prev rune = -1
+	for _, r := range s {
+		if prev >= 0 && predictable(prev, r) {
+			entropy++
+		} else {
+			entropy += bitsPerChar
+		}
+		prev = r
+	}
+	return entropy
+}
+
+// keyboardRows linhas do teclado QWERTY usadas para detectar vizinhos
+var keyboardRows = []string{"1234567890", "qwertyuiop", "asdfghjkl", "zxcvbnm"}
+
+// predictable indica se r é previsível a partir do caractere anterior
+func predictable(prev, r rune) bool {
+	if r == prev || r == prev+1 || r == prev-1 {
+		return true
+	}
+	prev, r = unicode.ToLower(prev), unicode.ToLower(r)
+	for _, row := range keyboardRows {
+		i, j := strings.IndexRune(row, prev), strings.IndexRune(row, r)
+		if i >= 0 && j >= 0 && (i-j == 1 || j-i == 1) {
+			return true
+		}
+	}
+	return false
+}
+
+// charClasses retorna as classes presentes na string
+func charClasses(s string) CharClass {
+	var classes CharClass
+	for _, r := range s {
+		switch {
+		case unicode.IsLower(r):
+			classes |= ClassLower
+		case unicode.IsUpper(r):
+			classes |= ClassUpper
+		case unicode.IsDigit(r):
+			classes |= ClassDigit
+		default:
+			classes |= ClassSymbol
+		}
+	}
+	return classes
+}
+
+// count retorna o número de classes do conjunto
+func (c CharClass) count() int {
+	n := 0
+	for class := ClassLower; class <= ClassSymbol; class <<= 1 {
+		if c&class != 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// cardinality retorna o número de caracteres possíveis nas classes
+func (c CharClass) cardinality() int {
+	n := 0
+	if c&ClassLower != 0 {
+		n += 26
+	}
+	if c&ClassUpper != 0 {
+		n += 26
+	}
+	if c&ClassDigit != 0 {
+		n += 10
+	}
+	if c&ClassSymbol != 0 {
+		n += 33
+	}
+	return max(n, 2)
+}
+
+// DenyList lista de senhas comuns. O valor nil é uma lista vazia
+type DenyList struct {
+	words map[string]struct{}
+}
+
+// NewDenyList cria uma deny list com as senhas informadas
+func NewDenyList(words ...string) *DenyList {
+	d := &DenyList{words: make(map[string]struct{}, len(words))}
+	for _, word := range words {
+		d.add(word)
+	}
+	return d
+}
+
+// LoadDenyList lê uma senha por linha, ignorando linhas vazias e comentários
+// iniciados por #
+func LoadDenyList(r io.Reader) (*DenyList, error) {
+	d := NewDenyList()
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		d.add(line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read deny list: %w", err)
+	}
+	return d, nil
+}
+
+// LoadDenyListFile carrega a deny list de um arquivo, como as listas de senhas
+// mais comuns do SecLists
+func LoadDenyListFile(path string) (*DenyList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open deny list: %w", err)
+	}
+	defer f.Close()
+	return LoadDenyList(f)
+}
+
+func (d *DenyList) add(word string) {
+	if word = strings.ToLower(strings.TrimSpace(word)); word != "" {
+		d.words[word] = struct{}{}
+	}
+}
+
+// Len retorna o número de senhas da lista
+func (d *DenyList) Len() int {
+	if d == nil {
+		return 0
+	}
+	return len(d.words)
+}
+
+// Contains verifica se a senha está na lista, ignorando maiúsculas,
+// substituições l33t (p4ssw0rd) e dígitos ou símbolos no início ou no fim
+// (password123!)
+func (d *DenyList) Contains(password string) bool {
+	_, _, ok := d.match(password)
+	return ok
+}
+
+// match retorna a parte da senha encontrada na lista e as decorações que a
+// cercam
+func (d *DenyList) match(password string) (word, decoration string, ok bool) {
+	if d.Len() == 0 || password == "" {
+		return "", "", false
+	}
+	if d.has(strings.ToLower(password)) || d.has(unleet(password)) {
+		return password, "", true
+	}
+
+	start := strings.IndexFunc(password, unicode.IsLetter)
+	end := strings.LastIndexFunc(password, unicode.IsLetter)
+	if start < 0 {
+		return "", "", false
+	}
+	_, size := utf8.DecodeRuneInString(password[end:])
+	end += size
+
+	core := password[start:end]
+	if d.has(strings.ToLower(core)) || d.has(unleet(core)) {
+		return core, password[:start] + password[end:], true
+	}
+	return "", "", false
+}
+
+func (d *DenyList) has(word string) bool {
+	_, ok := d.words[word]
+	return ok
+}
+
+// leetReplacer desfaz as substituições l33t mais comuns
+var leetReplacer = strings.NewReplacer(
+	"4", "a", "@", "a", "3", "e", "1", "i", "!", "i", "0", "o", "5", "s", "$", "s", "7", "t",
+)
+
+// unleet normaliza a senha para comparação com a deny list
+func unleet(s string) string {
+	return leetReplacer.Replace(strings.ToLower(s))
+}
@@ -0,0 +1,93 @@
+package validator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordStrength(t *testing.T) {
+	deny := NewDenyList("password", "qwerty", "dragon")
+
+	tests := []struct {
+		password string
+		score    int
+	}{
+		{"aaaaaaaaaaaa", 0},
+		{"abcdefgh12345678", 0},
+		{"qwertyuiop", 0},
+		{"P@ssw0rd123!", 0},
+		{"kT9#mQ2x", 2},
+		{"kT9#mQ2xVb7!wL4z", 3},
+		{"correct horse battery staple, but longer", 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			assert.Equal(t, tt.score, PasswordStrength(tt.password, deny).Score)
+		})
+	}
+}
+
+func TestDenyList(t *testing.T) {
+	deny, err := LoadDenyList(strings.NewReader("# top passwords\npassword\n\nLetMeIn\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, deny.Len())
+
+	for _, password := range []string{"password", "PASSWORD", "p4ssw0rd", "Password123!", "2024letmein"} {
+		assert.True(t, deny.Contains(password), password)
+	}
+	assert.False(t, deny.Contains("passwords are hard"))
+	assert.False(t, (*DenyList)(nil).Contains("password"))
+
+	path := filepath.Join(t.TempDir(), "common.txt")
+	require.NoError(t, os.WriteFile(path, []byte("123456\nqwerty\n"), 0o600))
+	deny, err = LoadDenyListFile(path)
+	require.NoError(t, err)
+	assert.True(t, deny.Contains("Qwerty!"))
+
+	_, err = LoadDenyListFile(filepath.Join(t.TempDir(), "missing.txt"))
+	assert.Error(t, err)
+}
+
+func TestPasswordRule(t *testing.T) {
+	policy := PasswordPolicy{
+		MinLength: 10,
+		Require:   ClassUpper | ClassDigit,
+		MinScore:  2,
+		DenyList:  NewDenyList("password"),
+	}
+	rules := NewRuleBuilder().Field("password", Password(policy)).Build()
+
+	assert.NoError(t, Validate(map[string]string{"password": "kT9#mQ2xVb"}, rules))
+
+	errs := validationErrors(t, Validate(map[string]string{"password": "password1"}, rules))
+	require.Len(t, errs, 1)
+	assert.Equal(t, "password", errs[0].Rule)
+	assert.Equal(t, []string{PasswordTooShort, PasswordMissingUpper, PasswordCommon, PasswordWeak}, errs[0].Params["reasons"])
+	assert.Equal(t, 0, errs[0].Params["score"])
+	assert.Equal(t, "password: does not meet the password policy: too_short, missing_upper, common_password, weak", errs[0].Error())
+
+	// O valor da senha nunca aparece no erro
+	assert.Nil(t, errs[0].Value)
+	data, err := json.Marshal(errs)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "password1")
+}
+
+type credentials struct {
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required,password"`
+}
+
+func TestPasswordTag(t *testing.T) {
+	assert.NoError(t, ValidateStruct(credentials{Username: "john", Password: "Blue-Otter-42"}))
+
+	errs := validationErrors(t, ValidateStruct(credentials{Username: "john", Password: "abc123"}))
+	assert.Equal(t, []string{"password"}, errs.Fields())
+	assert.Equal(t, []string{PasswordTooShort, PasswordTooFewClasses, PasswordWeak}, errs[0].Params["reasons"])
+}
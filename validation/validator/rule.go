@@ -0,0 +1,474 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Predicate decide se um conjunto de regras deve ser aplicado ao campo
+type Predicate func(fc *FieldContext) bool
+
+// FieldContext contém o campo em validação e a estrutura que o contém,
+// permitindo que regras consultem outros campos (cross-field). O contexto é
+// reutilizado entre campos e não deve ser retido após a execução da regra
+type FieldContext struct {
+	// Path é o caminho completo do campo usado nos erros
+	Path string
+	// Name é o nome do campo (nome do JSON quando disponível)
+	Name string
+	// Value é o valor do campo
+	Value reflect.Value
+	// Parent é a estrutura (struct ou map) que contém o campo
+	Parent reflect.Value
+	// Root é o valor raiz em validação
+	Root reflect.Value
+	// Mode indica se a validação é de criação ou de atualização
+	Mode Mode
+
+	// ctx contexto da validação
+	ctx context.Context
+	// messages mensagens definidas no nível do Validator, por regra
+	messages map[string]string
+}
+
+// Context retorna o contexto da validação
+func (fc *FieldContext) Context() context.Context {
+	if fc.ctx == nil {
+		return context.Background()
+	}
+	return fc.ctx
+}
+
+// Interface retorna o valor do campo como interface{}
+func (fc *FieldContext) Interface() interface{} {
+	v := indirect(fc.Value)
+	if !v.IsValid() || !v.CanInterface() {
+		return nil
+	}
+	return v.Interface()
+}
+
+// Field retorna o valor de outro campo da mesma estrutura. O nome pode ser o
+// nome Go do campo, o nome do JSON ou um caminho com pontos (ex.: address.city)
+func (fc *FieldContext) Field(name string) (reflect.Value, bool) {
+	current := fc.Parent
+	for _, part := range strings.Split(name, ".") {
+		next, ok := lookupField(current, part)
+		if !ok {
+			return reflect.Value{}, false
+		}
+		current = next
+	}
+	return current, true
+}
+
+// lookupField busca um campo em uma struct ou map[string]T
+func lookupField(container reflect.Value, name string) (reflect.Value, bool) {
+	container = indirect(container)
+	if !container.IsValid() {
+		return reflect.Value{}, false
+	}
+
+	switch container.Kind() {
+	case reflect.Struct:
+		meta := getStructMeta(container.Type())
+		if idx, ok := meta.lookup[name]; ok {
+			return container.Field(meta.fields[idx].index), true
+		}
+	case reflect.Map:
+		if container.Type().Key().Kind() != reflect.String {
+			return reflect.Value{}, false
+		}
+		v := container.MapIndex(reflect.ValueOf(name).Convert(container.Type().Key()))
+		if v.IsValid() {
+			return v, true
+		}
+	}
+
+	return reflect.Value{}, false
+}
+
+// Rule representa uma regra de validação aplicável a um campo
+type Rule struct {
+	name          string
+	message       string
+	params        map[string]any
+	check         func(fc *FieldContext) bool
+	condition     Predicate
+	children      []*Rule
+	details       func(fc *FieldContext) map[string]any
+	evaluateEmpty bool
+	customMessage bool
+	sensitive     bool
+	warning       bool
+}
+
+// NewRule cria uma regra customizada. A função check deve retornar true quando
+// o valor é válido. Valores vazios são ignorados, exceto em regras de presença
+func NewRule(name, message string, check func(fc *FieldContext) bool) *Rule {
+	return &Rule{
+		name:    name,
+		message: message,
+		check:   check,
+	}
+}
+
+// Name retorna o nome da regra
+func (r *Rule) Name() string {
+	return r.name
+}
+
+// Params retorna os parâmetros da regra
+func (r *Rule) Params() map[string]any {
+	if len(r.params) == 0 {
+		return nil
+	}
+	result := make(map[string]any, len(r.params))
+	for k, v := range r.params {
+		result[k] = v
+	}
+	return result
+}
+
+// WithParam adiciona um parâmetro disponível para a mensagem da regra
+func (r *Rule) WithParam(key string, value any) *Rule {
+	clone := r.clone()
+	if clone.params == nil {
+		clone.params = make(map[string]any)
+	}
+	clone.params[key] = value
+	return clone
+}
+
+// WithMessage substitui a mensagem da regra. A mensagem aceita as variáveis
+// {field}, {value} e os parâmetros da regra (ex.: {min})
+func (r *Rule) WithMessage(message string) *Rule {
+	clone := r.clone()
+	clone.message = message
+	clone.customMessage = true
+	return clone
+}
+
+// Message retorna o template da mensagem da regra
+func (r *Rule) Message() string {
+	return r.message
+}
+
+// EvaluateEmpty faz a regra ser avaliada também para valores vazios
+func (r *Rule) EvaluateEmpty() *Rule {
+	clone := r.clone()
+	clone.evaluateEmpty = true
+	return clone
+}
+
+// Sensitive omite o valor do campo nos erros da regra, como em senhas e tokens
+func (r *Rule) Sensitive() *Rule {
+	clone := r.clone()
+	clone.sensitive = true
+	return clone
+}
+
+// AsWarning transforma a regra em um aviso: suas falhas não bloqueiam a
+// validação e são retornadas em ValidationResult.Warnings, como em campos
+// depreciados ou valores que serão truncados. Em regras condicionais, vale
+// para todas as regras filhas
+func (r *Rule) AsWarning() *Rule {
+	clone := r.clone()
+	clone.warning = true
+	return clone
+}
+
+// IsWarning verifica se a regra é um aviso
+func (r *Rule) IsWarning() bool {
+	return r.warning
+}
+
+// clone cria uma cópia rasa da regra para manter as regras imutáveis
+func (r *Rule) clone() *Rule {
+	c := *r
+	if r.params != nil {
+		c.params = make(map[string]any, len(r.params))
+		for k, v := range r.params {
+			c.params[k] = v
+		}
+	}
+	return &c
+}
+
+// apply executa a regra e acumula os erros encontrados em errs, ou em warns
+// quando a regra é um aviso
+func (r *Rule) apply(fc *FieldContext, errs, warns *ValidationErrors) {
+	if r.warning {
+		errs = warns
+	}
+
+	if r.condition != nil {
+		if !r.condition(fc) {
+			return
+		}
+		for _, child := range r.children {
+			child.apply(fc, errs, warns)
+		}
+		return
+	}
+
+	if !r.evaluateEmpty && isEmpty(fc.Value) {
+		return
+	}
+
+	if r.check(fc) {
+		return
+	}
+
+	*errs = append(*errs, r.newError(fc))
+}
+
+// newError cria o erro da regra para o campo. Mensagens definidas na própria
+// regra têm precedência sobre as mensagens do Validator
+func (r *Rule) newError(fc *FieldContext) FieldError {
+	message := r.message
+	if override, ok := fc.messages[r.name]; ok && !r.customMessage {
+		message = override
+	}
+
+	err := FieldError{
+		Field:  fc.Path,
+		Rule:   r.name,
+		Value:  fc.Interface(),
+		Params: r.Params(),
+	}
+	if r.sensitive {
+		err.Value = nil
+	}
+	if r.details != nil {
+		for k, v := range r.details(fc) {
+			if err.Params == nil {
+				err.Params = make(map[string]any)
+			}
+			err.Params[k] = v
+		}
+	}
+	err.Message = renderMessage(message, err.templateVar)
+	return err
+}
+
+// When aplica as regras somente quando o predicado é verdadeiro
+func When(predicate Predicate, rules ...*Rule) *Rule {
+	return &Rule{
+		name:      "when",
+		condition: predicate,
+		children:  rules,
+	}
+}
+
+// FieldEquals cria um predicado verdadeiro quando outro campo possui o valor informado
+func FieldEquals(field string, value any) Predicate {
+	return func(fc *FieldContext) bool {
+		other, ok := fc.Field(field)
+		return ok && valueEquals(other, value)
+	}
+}
+
+// FieldPresent cria um predicado verdadeiro quando outro campo está preenchido
+func FieldPresent(field string) Predicate {
+	return func(fc *FieldContext) bool {
+		other, ok := fc.Field(field)
+		return ok && !isEmpty(other)
+	}
+}
+
+// renderMessage substitui os placeholders {nome} pelos valores retornados por
+// lookup. Placeholders desconhecidos são mantidos
+func renderMessage(message string, lookup func(name string) (any, bool)) string {
+	start := strings.IndexByte(message, '{')
+	if start < 0 {
+		return message
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(message) + 16)
+	for start >= 0 {
+		end := strings.IndexByte(message[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start
+
+		sb.WriteString(message[:start])
+		if value, ok := lookup(message[start+1 : end]); ok {
+			sb.WriteString(formatValue(value))
+		} else {
+			sb.WriteString(message[start : end+1])
+		}
+
+		message = message[end+1:]
+		start = strings.IndexByte(message, '{')
+	}
+	sb.WriteString(message)
+	return sb.String()
+}
+
+// mapLookup adapta um map de parâmetros para renderMessage
+func mapLookup(params map[string]any) func(string) (any, bool) {
+	return func(name string) (any, bool) {
+		value, ok := params[name]
+		return value, ok
+	}
+}
+
+// formatValue formata o valor do placeholder, evitando fmt nos tipos comuns
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []string:
+		return strings.Join(v, ", ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// indirect remove ponteiros e interfaces até o valor concreto
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// isEmpty verifica se o valor é considerado vazio (ausente)
+func isEmpty(v reflect.Value) bool {
+	v = indirect(v)
+	if !v.IsValid() {
+		return true
+	}
+
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	case reflect.Struct:
+		if t, ok := asTime(v); ok {
+			return t.IsZero()
+		}
+		return false
+	default:
+		return v.IsZero()
+	}
+}
+
+// length retorna o tamanho de strings (em runes), slices, arrays e maps
+func length(v reflect.Value) (int, bool) {
+	v = indirect(v)
+	if !v.IsValid() {
+		return 0, false
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(v.String()), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return v.Len(), true
+	default:
+		return 0, false
+	}
+}
+
+// toFloat converte valores numéricos para float64
+func toFloat(v reflect.Value) (float64, bool) {
+	v = indirect(v)
+	if !v.IsValid() {
+		return 0, false
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// asTime converte o valor para time.Time quando possível
+func asTime(v reflect.Value) (time.Time, bool) {
+	if v.IsValid() && v.Type() == reflect.TypeOf(time.Time{}) && v.CanInterface() {
+		return v.Interface().(time.Time), true
+	}
+	return time.Time{}, false
+}
+
+// compareValues compara dois valores do mesmo tipo lógico (número, string ou time.Time).
+// Retorna -1, 0 ou 1 e false quando os valores não são comparáveis
+func compareValues(a, b reflect.Value) (int, bool) {
+	a, b = indirect(a), indirect(b)
+	if !a.IsValid() || !b.IsValid() {
+		return 0, false
+	}
+
+	if ta, ok := asTime(a); ok {
+		tb, ok := asTime(b)
+		if !ok {
+			return 0, false
+		}
+		return ta.Compare(tb), true
+	}
+
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case fa < fb:
+			return -1, true
+		case fa > fb:
+			return 1, true
+		default:
+			return 0, true
+		}
+	}
+
+	if a.Kind() == reflect.String && b.Kind() == reflect.String {
+		return strings.Compare(a.String(), b.String()), true
+	}
+
+	return 0, false
+}
+
+// valueEquals compara um valor refletido com um valor arbitrário. Valores de
+// tipos diferentes são comparados pela representação textual, permitindo o uso
+// de parâmetros vindos de struct tags
+func valueEquals(v reflect.Value, expected any) bool {
+	v = indirect(v)
+	if !v.IsValid() {
+		return expected == nil
+	}
+	if !v.CanInterface() {
+		return false
+	}
+
+	actual := v.Interface()
+	if expected != nil && reflect.TypeOf(actual) == reflect.TypeOf(expected) && reflect.TypeOf(actual).Comparable() {
+		return actual == expected
+	}
+	return fmt.Sprint(actual) == fmt.Sprint(expected)
+}
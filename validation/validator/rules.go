@@ -0,0 +1,128 @@
+package validator
+
+import (
+	"net/mail"
+	"reflect"
+	"regexp"
+	"time"
+)
+
+// Required valida que o campo está preenchido
+func Required() *Rule {
+	return &Rule{
+		name:          "required",
+		message:       "is required",
+		check:         func(fc *FieldContext) bool { return !isEmpty(fc.Value) },
+		evaluateEmpty: true,
+	}
+}
+
+// MinLength valida o tamanho mínimo de strings, slices e maps
+func MinLength(min int) *Rule {
+	return &Rule{
+		name:    "min_length",
+		message: "must have at least {min} characters",
+		params:  map[string]any{"min": min},
+		check: func(fc *FieldContext) bool {
+			l, ok := length(fc.Value)
+			return ok && l >= min
+		},
+	}
+}
+
+// MaxLength valida o tamanho máximo de strings, slices e maps
+func MaxLength(max int) *Rule {
+	return &Rule{
+		name:    "max_length",
+		message: "must have at most {max} characters",
+		params:  map[string]any{"max": max},
+		check: func(fc *FieldContext) bool {
+			l, ok := length(fc.Value)
+			return ok && l <= max
+		},
+	}
+}
+
+// Min valida o valor numérico mínimo
+func Min(min float64) *Rule {
+	return &Rule{
+		name:    "min",
+		message: "must be greater than or equal to {min}",
+		params:  map[string]any{"min": min},
+		check: func(fc *FieldContext) bool {
+			f, ok := toFloat(fc.Value)
+			return ok && f >= min
+		},
+	}
+}
+
+// Max valida o valor numérico máximo
+func Max(max float64) *Rule {
+	return &Rule{
+		name:    "max",
+		message: "must be less than or equal to {max}",
+		params:  map[string]any{"max": max},
+		check: func(fc *FieldContext) bool {
+			f, ok := toFloat(fc.Value)
+			return ok && f <= max
+		},
+	}
+}
+
+// Pattern valida que a string corresponde à expressão regular
+func Pattern(re *regexp.Regexp) *Rule {
+	return &Rule{
+		name:    "pattern",
+		message: "must match the pattern {pattern}",
+		params:  map[string]any{"pattern": re.String()},
+		check: func(fc *FieldContext) bool {
+			v := indirect(fc.Value)
+			return v.IsValid() && v.Kind() == reflect.String && re.MatchString(v.String())
+		},
+	}
+}
+
+// Email valida que a string é um endereço de e-mail
+func Email() *Rule {
+	return &Rule{
+		name:    "email",
+		message: "must be a valid email address",
+		check: func(fc *FieldContext) bool {
+			v := indirect(fc.Value)
+			if !v.IsValid() || v.Kind() != reflect.String {
+				return false
+			}
+			addr, err := mail.ParseAddress(v.String())
+			return err == nil && addr.Address == v.String()
+		},
+	}
+}
+
+// dateTimeLayouts layouts nomeados aceitos pela regra datetime
+var dateTimeLayouts = map[string]string{
+	"iso8601": time.RFC3339,
+	"rfc3339": time.RFC3339,
+	"date":    time.DateOnly,
+	"time":    time.TimeOnly,
+}
+
+// DateTime valida que a string é uma data/hora no layout informado. Aceita
+// layouts do pacote time ou os nomes iso8601, rfc3339, date e time
+func DateTime(layout string) *Rule {
+	if named, ok := dateTimeLayouts[layout]; ok {
+		layout = named
+	}
+	return &Rule{
+		name:    "datetime",
+		message: "must be a valid date/time in the format {layout}",
+		params:  map[string]any{"layout": layout},
+		check: func(fc *FieldContext) bool {
+			v := indirect(fc.Value)
+			if !v.IsValid() || v.Kind() != reflect.String {
+				return false
+			}
+			_, err := time.Parse(layout, v.String())
+			return err == nil
+		},
+	}
+}
@@ -0,0 +1,290 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// SanitizeTagName nome da struct tag com a cadeia de sanitizadores do campo
+// (ex.: sanitize:"trim,lower"), aplicados antes das regras de validação
+const SanitizeTagName = "sanitize"
+
+// Sanitizer normaliza um valor textual antes da validação
+type Sanitizer func(string) string
+
+// sanitizers sanitizadores disponíveis para uso em struct tags
+var sanitizers = struct {
+	mu sync.RWMutex
+	m  map[string]Sanitizer
+}{
+	m: make(map[string]Sanitizer),
+}
+
+func init() {
+	RegisterSanitizer("trim", Trim)
+	RegisterSanitizer("lower", Lower)
+	RegisterSanitizer("upper", Upper)
+	RegisterSanitizer("digits", DigitsOnly)
+	RegisterSanitizer("alphanum", AlphaNumeric)
+	RegisterSanitizer("squish", CollapseSpaces)
+}
+
+// RegisterSanitizer registra um sanitizador para uso na struct tag sanitize
+func RegisterSanitizer(name string, sanitizer Sanitizer) {
+	sanitizers.mu.Lock()
+	defer sanitizers.mu.Unlock()
+	sanitizers.m[name] = sanitizer
+}
+
+// lookupSanitizer retorna o sanitizador registrado
+func lookupSanitizer(name string) (Sanitizer, bool) {
+	sanitizers.mu.RLock()
+	defer sanitizers.mu.RUnlock()
+	sanitizer, ok := sanitizers.m[name]
+	return sanitizer, ok
+}
+
+// Trim remove espaços no início e no fim
+func Trim(s string) string {
+	return strings.TrimSpace(s)
+}
+
+// Lower converte para minúsculas
+func Lower(s string) string {
+	return strings.ToLower(s)
+}
+
+// Upper converte para maiúsculas
+func Upper(s string) string {
+	return strings.ToUpper(s)
+}
+
+// DigitsOnly remove todos os caracteres que não são dígitos (ex.: telefones, CPF)
+func DigitsOnly(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+// AlphaNumeric remove todos os caracteres que não são letras ou dígitos
+func AlphaNumeric(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+// CollapseSpaces remove espaços nas extremidades e reduz sequências de
+// espaços internos a um único espaço
+func CollapseSpaces(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// parseSanitizeTag converte a struct tag sanitize na cadeia de sanitizadores
+func parseSanitizeTag(tag string) ([]Sanitizer, error) {
+	var chain []Sanitizer
+	for _, name := range strings.Split(tag, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		sanitizer, ok := lookupSanitizer(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown sanitizer %q", name)
+		}
+		chain = append(chain, sanitizer)
+	}
+	return chain, nil
+}
+
+// applySanitizers aplica a cadeia de sanitizadores ao texto
+func applySanitizers(s string, chain []Sanitizer) string {
+	for _, sanitize := range chain {
+		s = sanitize(s)
+	}
+	return s
+}
+
+// sanitizeValue retorna o valor com os sanitizadores aplicados a strings,
+// ponteiros para string e elementos string de slices e maps. Com inPlace,
+// ponteiros, slices e maps são alterados diretamente; caso contrário, novos
+// valores são criados e os dados originais permanecem intactos
+func sanitizeValue(value reflect.Value, chain []Sanitizer, inPlace bool) reflect.Value {
+	if !value.IsValid() {
+		return value
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(applySanitizers(value.String(), chain)).Convert(value.Type())
+	case reflect.Interface:
+		if value.IsNil() {
+			return value
+		}
+		return sanitizeValue(value.Elem(), chain, inPlace)
+	case reflect.Ptr:
+		if value.IsNil() || value.Type().Elem().Kind() != reflect.String {
+			return value
+		}
+		sanitized := sanitizeValue(value.Elem(), chain, inPlace)
+		if inPlace {
+			value.Elem().Set(sanitized)
+			return value
+		}
+		ptr := reflect.New(value.Type().Elem())
+		ptr.Elem().Set(sanitized)
+		return ptr
+	case reflect.Slice, reflect.Array:
+		if value.Len() == 0 || !isStringElem(value.Type()) {
+			return value
+		}
+		target := value
+		if !inPlace || !value.Index(0).CanSet() {
+			target = copyList(value)
+		}
+		for i := 0; i < target.Len(); i++ {
+			elem := target.Index(i)
+			elem.Set(sanitizeValue(elem, chain, inPlace))
+		}
+		return target
+	case reflect.Map:
+		if value.IsNil() || !isStringElem(value.Type()) {
+			return value
+		}
+		target := value
+		if !inPlace {
+			target = copyMap(value)
+		}
+		for _, key := range target.MapKeys() {
+			target.SetMapIndex(key, sanitizeValue(target.MapIndex(key), chain, inPlace))
+		}
+		return target
+	}
+	return value
+}
+
+// sanitizeStruct aplica os sanitizadores declarados nas tags dos campos. Com
+// write-back em uma struct endereçável os campos são alterados diretamente;
+// caso contrário, é retornada uma cópia sanitizada
+func (v *Validator) sanitizeStruct(sv reflect.Value, fields []compiledField) reflect.Value {
+	inPlace := v.writeBack && sv.CanAddr()
+
+	target := sv
+	if !inPlace {
+		target = reflect.New(sv.Type()).Elem()
+		target.Set(sv)
+	}
+
+	for _, field := range fields {
+		if len(field.sanitizers) == 0 {
+			continue
+		}
+		value := target.Field(field.meta.index)
+		value.Set(sanitizeValue(value, field.sanitizers, inPlace))
+	}
+	return target
+}
+
+// sanitizePath aplica os sanitizadores ao campo localizado pelo caminho,
+// retornando o contêiner atualizado. Sem write-back, os contêineres do caminho
+// são copiados para não alterar os dados originais
+func (v *Validator) sanitizePath(container reflect.Value, parts []string, chain []Sanitizer) reflect.Value {
+	if len(parts) == 0 {
+		return sanitizeValue(container, chain, v.writeBack)
+	}
+	if !container.IsValid() {
+		return container
+	}
+
+	switch container.Kind() {
+	case reflect.Ptr:
+		if container.IsNil() {
+			return container
+		}
+		elem := v.sanitizePath(container.Elem(), parts, chain)
+		if v.writeBack && container.Elem().CanSet() {
+			container.Elem().Set(elem)
+			return container
+		}
+		ptr := reflect.New(container.Type().Elem())
+		ptr.Elem().Set(elem)
+		return ptr
+	case reflect.Interface:
+		if container.IsNil() {
+			return container
+		}
+		return v.sanitizePath(container.Elem(), parts, chain)
+	case reflect.Struct:
+		meta := getStructMeta(container.Type())
+		idx, ok := meta.lookup[parts[0]]
+		if !ok {
+			return container
+		}
+		target := container
+		if !v.writeBack || !container.CanAddr() {
+			target = reflect.New(container.Type()).Elem()
+			target.Set(container)
+		}
+		field := target.Field(meta.fields[idx].index)
+		field.Set(v.sanitizePath(field, parts[1:], chain))
+		return target
+	case reflect.Map:
+		if container.IsNil() || container.Type().Key().Kind() != reflect.String {
+			return container
+		}
+		key := reflect.ValueOf(parts[0]).Convert(container.Type().Key())
+		elem := container.MapIndex(key)
+		if !elem.IsValid() {
+			return container
+		}
+		target := container
+		if !v.writeBack {
+			target = copyMap(container)
+		}
+		target.SetMapIndex(key, v.sanitizePath(elem, parts[1:], chain))
+		return target
+	}
+	return container
+}
+
+// isStringElem verifica se os elementos da coleção são strings
+func isStringElem(t reflect.Type) bool {
+	elem := t.Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	return elem.Kind() == reflect.String || t.Elem().Kind() == reflect.Interface
+}
+
+// copyList cria uma cópia endereçável do slice ou array
+func copyList(list reflect.Value) reflect.Value {
+	if list.Kind() == reflect.Array {
+		target := reflect.New(list.Type()).Elem()
+		target.Set(list)
+		return target
+	}
+	if list.IsNil() {
+		return list
+	}
+	target := reflect.MakeSlice(list.Type(), list.Len(), list.Len())
+	reflect.Copy(target, list)
+	return target
+}
+
+// copyMap cria uma cópia rasa do map
+func copyMap(m reflect.Value) reflect.Value {
+	target := reflect.MakeMapWithSize(m.Type(), m.Len())
+	iter := m.MapRange()
+	for iter.Next() {
+		target.SetMapIndex(iter.Key(), iter.Value())
+	}
+	return target
+}
@@ -0,0 +1,126 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type contactRequest struct {
+	Name         string            `json:"name" sanitize:"squish" validate:"required,max_length=10"`
+	Email        string            `json:"email" sanitize:"trim,lower" validate:"required,email"`
+	EmailConfirm string            `json:"email_confirm" sanitize:"trim,lower" validate:"eq_field=email"`
+	Phone        *string           `json:"phone" sanitize:"digits" validate:"min_length=10,max_length=11"`
+	Tags         []string          `json:"tags" sanitize:"trim,upper" validate:"dive,min_length=2"`
+	Meta         map[string]string `json:"meta" sanitize:"trim"`
+}
+
+func newContact() contactRequest {
+	phone := "(11) 98765-4321"
+	return contactRequest{
+		Name:         "  John   Doe ",
+		Email:        " John@Example.COM ",
+		EmailConfirm: "john@example.com",
+		Phone:        &phone,
+		Tags:         []string{" vip ", "new"},
+		Meta:         map[string]string{"source": " web "},
+	}
+}
+
+func TestSanitizers(t *testing.T) {
+	assert.Equal(t, "abc", Trim("  abc\n"))
+	assert.Equal(t, "abc", Lower("AbC"))
+	assert.Equal(t, "ABC", Upper("aBc"))
+	assert.Equal(t, "11987654321", DigitsOnly("+1 (198) 765-4321"))
+	assert.Equal(t, "ação123", AlphaNumeric("a-ç_ã.o 123"))
+	assert.Equal(t, "a b c", CollapseSpaces("  a \t b\n\nc "))
+}
+
+func TestValidateStruct_Sanitize(t *testing.T) {
+	req := newContact()
+	require.NoError(t, ValidateStruct(&req))
+
+	// Sem write-back os dados originais permanecem intactos
+	assert.Equal(t, "  John   Doe ", req.Name)
+	assert.Equal(t, " John@Example.COM ", req.Email)
+	assert.Equal(t, "(11) 98765-4321", *req.Phone)
+	assert.Equal(t, []string{" vip ", "new"}, req.Tags)
+	assert.Equal(t, " web ", req.Meta["source"])
+
+	req.Tags = []string{" a "}
+	errs := validationErrors(t, ValidateStruct(req))
+	assert.Equal(t, []string{"tags[0]"}, errs.Fields())
+	assert.Equal(t, "A", errs[0].Value)
+}
+
+func TestValidateStruct_SanitizeWriteBack(t *testing.T) {
+	v := New(WithWriteBack())
+
+	req := newContact()
+	require.NoError(t, v.ValidateStruct(&req))
+
+	assert.Equal(t, "John Doe", req.Name)
+	assert.Equal(t, "john@example.com", req.Email)
+	assert.Equal(t, "11987654321", *req.Phone)
+	assert.Equal(t, []string{"VIP", "NEW"}, req.Tags)
+	assert.Equal(t, "web", req.Meta["source"])
+
+	// Structs passadas por valor não podem ser alteradas
+	byValue := newContact()
+	require.NoError(t, v.ValidateStruct(byValue))
+	assert.Equal(t, "  John   Doe ", byValue.Name)
+}
+
+type invalidSanitizeRequest struct {
+	Name string `sanitize:"unknown"`
+}
+
+func TestValidateStruct_UnknownSanitizer(t *testing.T) {
+	err := ValidateStruct(invalidSanitizeRequest{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown sanitizer")
+
+	RegisterSanitizer("no_dots", func(s string) string {
+		return strings.ReplaceAll(s, ".", "")
+	})
+	chain, err := parseSanitizeTag("trim, no_dots")
+	require.NoError(t, err)
+	assert.Len(t, chain, 2)
+}
+
+func TestRuleBuilder_Sanitize(t *testing.T) {
+	rules := NewRuleBuilder().
+		Sanitize("email", Trim, Lower).
+		Field("email", Required(), Email()).
+		Sanitize("confirm", Trim).
+		Field("confirm", EqualsField("email")).
+		Field("address.zip", Required(), MinLength(8), MaxLength(8)).
+		Sanitize("address.zip", DigitsOnly).
+		Build()
+
+	data := map[string]interface{}{
+		"email":   " USER@example.com",
+		"confirm": "user@example.com ",
+		"address": map[string]interface{}{"zip": "01310-100"},
+	}
+	require.NoError(t, Validate(data, rules))
+	assert.Equal(t, " USER@example.com", data["email"])
+	assert.Equal(t, "01310-100", data["address"].(map[string]interface{})["zip"])
+
+	require.NoError(t, New(WithWriteBack()).Validate(data, rules))
+	assert.Equal(t, "user@example.com", data["email"])
+	assert.Equal(t, "01310100", data["address"].(map[string]interface{})["zip"])
+
+	c := &customer{Name: " ", Address: address{City: " Lisbon "}}
+	rules = NewRuleBuilder().
+		Sanitize("name", Trim).
+		Field("name", Required()).
+		Sanitize("address.city", Trim, Upper).
+		Build()
+
+	errs := validationErrors(t, New(WithWriteBack()).Validate(c, rules))
+	assert.Equal(t, []string{"name"}, errs.Fields())
+	assert.Equal(t, "LISBON", c.Address.City)
+}
@@ -0,0 +1,461 @@
+// Package schema gera documentos JSON Schema (draft 2020-12) a partir de
+// structs Go, usando as tags json e validate como fonte única para a
+// documentação da API e a validação no cliente
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fsvxavier/nexs-lib/validation/validator"
+)
+
+// Draft URI do draft de JSON Schema gerado
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Tags lidas pelo gerador além de json e validate
+const (
+	// EnumTagName lista os valores permitidos, separados por vírgula
+	EnumTagName = "enum"
+	// DescriptionTagName descrição do campo
+	DescriptionTagName = "description"
+)
+
+// Schema documento ou sub-schema JSON Schema
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	ContentEncoding      string             `json:"contentEncoding,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	MinProperties        *int               `json:"minProperties,omitempty"`
+	MaxProperties        *int               `json:"maxProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// JSON retorna o schema serializado
+func (s *Schema) JSON() ([]byte, error) {
+	return json.Marshal(s)
+}
+
+// Option configura a geração do schema
+type Option func(*generator)
+
+// WithTagName define a chave da struct tag de validação (padrão "validate")
+func WithTagName(name string) Option {
+	return func(g *generator) {
+		if name != "" {
+			g.tagName = name
+		}
+	}
+}
+
+// WithID define o $id do documento gerado
+func WithID(id string) Option {
+	return func(g *generator) {
+		g.id = id
+	}
+}
+
+// formats formato JSON Schema correspondente a cada regra de formato. Os nomes
+// coincidem com os registrados por jsonschema/checks.FormatCheckers
+var formats = map[string]string{
+	"email":       "email",
+	"uuid":        "uuid",
+	"uuid3":       "uuid3",
+	"uuid4":       "uuid4",
+	"uuid5":       "uuid5",
+	"uuid7":       "uuid7",
+	"ulid":        "ulid",
+	"e164":        "e164",
+	"iban":        "iban",
+	"credit_card": "credit-card",
+	"cpf":         "cpf",
+	"cnpj":        "cnpj",
+}
+
+// dateTimeFormats formato correspondente aos layouts da regra datetime
+var dateTimeFormats = map[string]string{
+	time.RFC3339:  "date-time",
+	time.DateOnly: "date",
+	time.TimeOnly: "time",
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// generator estado da geração de um documento
+type generator struct {
+	tagName string
+	id      string
+	defs    map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+// Generate gera o JSON Schema do tipo informado (struct ou ponteiro para
+// struct). Structs nomeadas aninhadas são declaradas em $defs e referenciadas
+// via $ref, permitindo tipos recursivos
+func Generate(t reflect.Type, opts ...Option) (*Schema, error) {
+	g := &generator{
+		tagName: validator.DefaultTagName,
+		defs:    make(map[string]*Schema),
+		names:   make(map[reflect.Type]string),
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	t = deref(t)
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("schema: type must be a struct or a pointer to struct, got %v", t)
+	}
+
+	// O tipo raiz pode ser referenciado por tipos recursivos via "#"
+	g.names[t] = ""
+
+	root, err := g.structSchema(t)
+	if err != nil {
+		return nil, err
+	}
+
+	root.Schema = Draft
+	root.ID = g.id
+	root.Title = t.Name()
+	if len(g.defs) > 0 {
+		root.Defs = g.defs
+	}
+	return root, nil
+}
+
+// For gera o JSON Schema do tipo T
+func For[T any](opts ...Option) (*Schema, error) {
+	return Generate(reflect.TypeOf((*T)(nil)).Elem(), opts...)
+}
+
+// typeSchema gera o schema de um tipo Go
+func (g *generator) typeSchema(t reflect.Type) (*Schema, error) {
+	t = deref(t)
+
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}, nil
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}, nil
+	case reflect.Interface:
+		return &Schema{}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json serializa []byte em base64
+			return &Schema{Type: "string", ContentEncoding: "base64"}, nil
+		}
+		items, err := g.typeSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("schema: unsupported map key type %s", t.Key())
+		}
+		values, err := g.typeSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "object", AdditionalProperties: values}, nil
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return g.refSchema(t)
+	default:
+		return nil, fmt.Errorf("schema: unsupported type %s", t)
+	}
+}
+
+// refSchema declara a struct nomeada em $defs e retorna a referência
+func (g *generator) refSchema(t reflect.Type) (*Schema, error) {
+	if name, ok := g.names[t]; ok {
+		if name == "" {
+			return &Schema{Ref: "#"}, nil
+		}
+		return &Schema{Ref: "#/$defs/" + name}, nil
+	}
+
+	name := t.Name()
+	for i := 2; g.isNameTaken(name); i++ {
+		name = t.Name() + strconv.Itoa(i)
+	}
+	g.names[t] = name
+	g.defs[name] = &Schema{} // reservado para tipos recursivos
+
+	def, err := g.structSchema(t)
+	if err != nil {
+		return nil, err
+	}
+	g.defs[name] = def
+	return &Schema{Ref: "#/$defs/" + name}, nil
+}
+
+// isNameTaken verifica se o nome já foi usado por outro tipo
+func (g *generator) isNameTaken(name string) bool {
+	for _, used := range g.names {
+		if used == name {
+			return true
+		}
+	}
+	return false
+}
+
+// structSchema gera o schema de objeto da struct
+func (g *generator) structSchema(t reflect.Type) (*Schema, error) {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	if err := g.addFields(s, t); err != nil {
+		return nil, err
+	}
+	sort.Strings(s.Required)
+	return s, nil
+}
+
+// addFields adiciona as propriedades dos campos exportados da struct.
+// Structs embutidas sem nome JSON têm os campos promovidos, como no encoding/json
+func (g *generator) addFields(s *Schema, t reflect.Type) error {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		jsonName, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if jsonName == "-" {
+			continue
+		}
+
+		if sf.Anonymous && jsonName == "" && deref(sf.Type).Kind() == reflect.Struct {
+			if err := g.addFields(s, deref(sf.Type)); err != nil {
+				return err
+			}
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		name := sf.Name
+		if jsonName != "" {
+			name = jsonName
+		}
+
+		prop, required, err := g.fieldSchema(sf)
+		if err != nil {
+			return fmt.Errorf("schema: field %s.%s: %w", t.Name(), sf.Name, err)
+		}
+		s.Properties[name] = prop
+		if required {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return nil
+}
+
+// fieldSchema gera o schema do campo aplicando as regras da tag de validação
+func (g *generator) fieldSchema(sf reflect.StructField) (*Schema, bool, error) {
+	tag := sf.Tag.Get(g.tagName)
+	if tag == "-" {
+		tag = ""
+	}
+
+	levels, err := validator.ParseTag(tag)
+	if err != nil {
+		return nil, false, err
+	}
+
+	prop, err := g.typeSchema(sf.Type)
+	if err != nil {
+		return nil, false, err
+	}
+
+	required := false
+	current := prop
+	for depth, rules := range levels {
+		if current == nil {
+			break
+		}
+		for _, rule := range rules {
+			if depth == 0 && rule.Name() == "required" {
+				required = true
+				continue
+			}
+			applyRule(current, rule)
+		}
+		current = elemSchema(current)
+	}
+
+	if enum := sf.Tag.Get(EnumTagName); enum != "" {
+		// Em coleções os valores permitidos se aplicam aos elementos
+		target, t := prop, deref(sf.Type)
+		if prop.Type == "array" && prop.Items != nil {
+			target, t = prop.Items, deref(t.Elem())
+		}
+		values, err := parseEnum(enum, t)
+		if err != nil {
+			return nil, false, err
+		}
+		target.Enum = values
+	}
+	prop.Description = sf.Tag.Get(DescriptionTagName)
+
+	return prop, required, nil
+}
+
+// elemSchema retorna o schema dos elementos da coleção (usado após "dive")
+func elemSchema(s *Schema) *Schema {
+	if s.Items != nil {
+		return s.Items
+	}
+	return s.AdditionalProperties
+}
+
+// applyRule traduz a regra de validação para as keywords do JSON Schema.
+// Regras sem equivalente (cross-field, condicionais, customizadas) são ignoradas
+func applyRule(s *Schema, rule *validator.Rule) {
+	params := rule.Params()
+
+	switch name := rule.Name(); name {
+	case "min_length":
+		if n, ok := params["min"].(int); ok {
+			setLength(s, true, n)
+		}
+	case "max_length":
+		if n, ok := params["max"].(int); ok {
+			setLength(s, false, n)
+		}
+	case "min":
+		if f, ok := params["min"].(float64); ok {
+			s.Minimum = &f
+		}
+	case "max":
+		if f, ok := params["max"].(float64); ok {
+			s.Maximum = &f
+		}
+	case "pattern":
+		s.Pattern, _ = params["pattern"].(string)
+	case "datetime":
+		layout, _ := params["layout"].(string)
+		s.Format = dateTimeFormats[layout]
+	case "password":
+		if n, ok := params["min_length"].(int); ok && n > 0 {
+			setLength(s, true, n)
+		}
+	case "one_of":
+		// one_of_ci não é exportada: enum do JSON Schema diferencia maiúsculas
+		values, _ := params["values"].([]string)
+		s.Enum = enumValues(values, s.Type)
+	default:
+		if format, ok := formats[name]; ok {
+			s.Format = format
+		}
+	}
+}
+
+// setLength aplica min/max ao tamanho conforme o tipo do schema
+func setLength(s *Schema, isMin bool, n int) {
+	switch s.Type {
+	case "array":
+		if isMin {
+			s.MinItems = &n
+		} else {
+			s.MaxItems = &n
+		}
+	case "object":
+		if isMin {
+			s.MinProperties = &n
+		} else {
+			s.MaxProperties = &n
+		}
+	default:
+		if isMin {
+			s.MinLength = &n
+		} else {
+			s.MaxLength = &n
+		}
+	}
+}
+
+// parseEnum converte os valores da tag enum para o tipo do campo
+func parseEnum(tag string, t reflect.Type) ([]any, error) {
+	items := strings.Split(tag, ",")
+	values := make([]any, 0, len(items))
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+
+		var value any = item
+		var err error
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			value, err = strconv.ParseInt(item, 10, 64)
+		case reflect.Float32, reflect.Float64:
+			value, err = strconv.ParseFloat(item, 64)
+		case reflect.Bool:
+			value, err = strconv.ParseBool(item)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid enum value %q for %s", item, t)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// enumValues converte os nomes permitidos por one_of para o tipo do schema.
+// Enums numéricos com nomes (fmt.Stringer) não têm equivalente e são ignorados
+func enumValues(names []string, schemaType string) []any {
+	values := make([]any, 0, len(names))
+	for _, name := range names {
+		var value any = name
+		var err error
+		switch schemaType {
+		case "integer":
+			value, err = strconv.ParseInt(name, 10, 64)
+		case "number":
+			value, err = strconv.ParseFloat(name, 64)
+		}
+		if err != nil {
+			return nil
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// deref remove os ponteiros do tipo
+func deref(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
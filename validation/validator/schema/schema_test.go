@@ -0,0 +1,186 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Address struct {
+	City    string `json:"city" validate:"required,max_length=50"`
+	ZipCode string `json:"zip_code" validate:"pattern=^[0-9]{8}$"`
+}
+
+type Category struct {
+	Name     string      `json:"name" validate:"required"`
+	Children []*Category `json:"children"`
+}
+
+type Audit struct {
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Customer struct {
+	Audit
+	ID        string            `json:"id" validate:"required,uuid4"`
+	Name      string            `json:"name" validate:"required,min_length=3" description:"Nome completo"`
+	Email     string            `json:"email,omitempty" validate:"email"`
+	Document  string            `json:"document" validate:"cpf"`
+	Card      string            `json:"card" validate:"credit_card"`
+	Birth     string            `json:"birth" validate:"datetime=date"`
+	Age       int               `json:"age" validate:"min=18,max=120"`
+	Score     *float64          `json:"score"`
+	Status    string            `json:"status" validate:"required" enum:"active,blocked"`
+	Level     int               `json:"level" enum:"1,2,3"`
+	Roles     []string          `json:"roles" validate:"min_length=1,dive,min_length=2" enum:"admin,user"`
+	Labels    map[string]string `json:"labels" validate:"max_length=5,dive,max_length=20"`
+	Avatar    []byte            `json:"avatar"`
+	Address   Address           `json:"address" validate:"required"`
+	Shipping  *Address          `json:"shipping"`
+	Category  Category          `json:"category"`
+	Extra     interface{}       `json:"extra"`
+	Internal  string            `json:"-"`
+	NoJSONTag bool
+	secret    string
+}
+
+func TestGenerate(t *testing.T) {
+	s, err := For[Customer]()
+	require.NoError(t, err)
+
+	assert.Equal(t, Draft, s.Schema)
+	assert.Equal(t, "Customer", s.Title)
+	assert.Equal(t, "object", s.Type)
+	assert.Equal(t, []string{"address", "id", "name", "status"}, s.Required)
+
+	props := s.Properties
+	assert.NotContains(t, props, "Internal")
+	assert.NotContains(t, props, "secret")
+	assert.Contains(t, props, "NoJSONTag")
+	assert.Equal(t, "date-time", props["created_at"].Format)
+
+	assert.Equal(t, "uuid4", props["id"].Format)
+	assert.Equal(t, "email", props["email"].Format)
+	assert.Equal(t, "cpf", props["document"].Format)
+	assert.Equal(t, "credit-card", props["card"].Format)
+	assert.Equal(t, "date", props["birth"].Format)
+	assert.Equal(t, 3, *props["name"].MinLength)
+	assert.Equal(t, "Nome completo", props["name"].Description)
+
+	assert.Equal(t, "integer", props["age"].Type)
+	assert.Equal(t, 18.0, *props["age"].Minimum)
+	assert.Equal(t, 120.0, *props["age"].Maximum)
+	assert.Equal(t, "number", props["score"].Type)
+
+	assert.Equal(t, []any{"active", "blocked"}, props["status"].Enum)
+	assert.Equal(t, []any{int64(1), int64(2), int64(3)}, props["level"].Enum)
+
+	roles := props["roles"]
+	assert.Equal(t, "array", roles.Type)
+	assert.Equal(t, 1, *roles.MinItems)
+	assert.Equal(t, 2, *roles.Items.MinLength)
+	assert.Equal(t, []any{"admin", "user"}, roles.Items.Enum)
+
+	labels := props["labels"]
+	assert.Equal(t, "object", labels.Type)
+	assert.Equal(t, 5, *labels.MaxProperties)
+	assert.Equal(t, 20, *labels.AdditionalProperties.MaxLength)
+
+	assert.Equal(t, "base64", props["avatar"].ContentEncoding)
+	assert.Equal(t, &Schema{}, props["extra"])
+
+	// Structs nomeadas são declaradas uma única vez em $defs
+	assert.Equal(t, "#/$defs/Address", props["address"].Ref)
+	assert.Equal(t, "#/$defs/Address", props["shipping"].Ref)
+	address := s.Defs["Address"]
+	assert.Equal(t, []string{"city"}, address.Required)
+	assert.Equal(t, "^[0-9]{8}$", address.Properties["zip_code"].Pattern)
+
+	// Tipos recursivos referenciam a própria definição
+	assert.Equal(t, "#/$defs/Category", s.Defs["Category"].Properties["children"].Items.Ref)
+}
+
+func TestGenerate_ValidatesDocuments(t *testing.T) {
+	s, err := For[Customer](WithID("https://example.com/customer.json"))
+	require.NoError(t, err)
+
+	data, err := s.JSON()
+	require.NoError(t, err)
+
+	doc, err := jsonschema.UnmarshalJSON(strings.NewReader(string(data)))
+	require.NoError(t, err)
+
+	compiler := jsonschema.NewCompiler()
+	require.NoError(t, compiler.AddResource(s.ID, doc))
+	compiled, err := compiler.Compile(s.ID)
+	require.NoError(t, err)
+
+	valid := map[string]any{
+		"id":       "550e8400-e29b-41d4-a716-446655440000",
+		"name":     "John",
+		"status":   "active",
+		"age":      30,
+		"roles":    []any{"admin"},
+		"address":  map[string]any{"city": "São Paulo"},
+		"category": map[string]any{"name": "root", "children": []any{map[string]any{"name": "leaf"}}},
+	}
+	assert.NoError(t, compiled.Validate(toJSON(t, valid)))
+
+	valid["status"] = "deleted"
+	valid["age"] = 10
+	assert.Error(t, compiled.Validate(toJSON(t, valid)))
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	_, err := Generate(reflect.TypeOf("string"))
+	assert.Error(t, err)
+
+	_, err = For[struct {
+		Callback func() `json:"callback"`
+	}]()
+	assert.Error(t, err)
+
+	_, err = For[struct {
+		Level int `json:"level" enum:"low"`
+	}]()
+	assert.Error(t, err)
+
+	_, err = For[struct {
+		Name string `json:"name" validate:"unknown_rule"`
+	}]()
+	assert.Error(t, err)
+
+	s, err := Generate(reflect.TypeOf(&Address{}), WithTagName("rules"))
+	require.NoError(t, err)
+	assert.Empty(t, s.Required)
+}
+
+// toJSON normaliza o valor para os tipos produzidos pelo decoder JSON
+func toJSON(t *testing.T, v any) any {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	out, err := jsonschema.UnmarshalJSON(strings.NewReader(string(data)))
+	require.NoError(t, err)
+	return out
+}
+
+func TestGenerate_OneOf(t *testing.T) {
+	s, err := For[struct {
+		Plan     string   `json:"plan" validate:"one_of=free pro"`
+		Priority int      `json:"priority" validate:"one_of=1 2 3"`
+		Region   string   `json:"region" validate:"one_of_ci=us eu"`
+		Tags     []string `json:"tags" validate:"dive,one_of=new sale"`
+	}]()
+	require.NoError(t, err)
+
+	assert.Equal(t, []any{"free", "pro"}, s.Properties["plan"].Enum)
+	assert.Equal(t, []any{int64(1), int64(2), int64(3)}, s.Properties["priority"].Enum)
+	assert.Nil(t, s.Properties["region"].Enum)
+	assert.Equal(t, []any{"new", "sale"}, s.Properties["tags"].Items.Enum)
+}
@@ -0,0 +1,399 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultTagName nome padrão da struct tag de validação
+const DefaultTagName = "validate"
+
+// RuleFactory cria uma regra a partir do parâmetro informado na struct tag
+type RuleFactory func(param string) (*Rule, error)
+
+// registry mantém as regras e predicados disponíveis para uso em struct tags
+var registry = struct {
+	mu         sync.RWMutex
+	rules      map[string]RuleFactory
+	predicates map[string]Predicate
+	aliases    map[string]string
+}{
+	rules:      make(map[string]RuleFactory),
+	predicates: make(map[string]Predicate),
+	aliases:    make(map[string]string),
+}
+
+// maxAliasDepth limita a expansão de aliases que referenciam outros aliases
+const maxAliasDepth = 8
+
+// TagFunc valida o campo usando o parâmetro informado na struct tag.
+// Deve retornar true quando o valor é válido
+type TagFunc func(fc *FieldContext, param string) bool
+
+func init() {
+	RegisterRule("required", noParam(Required))
+	RegisterRule("required_on_create", noParam(RequiredOnCreate))
+	RegisterRule("required_on_update", noParam(RequiredOnUpdate))
+	RegisterRule("email", noParam(Email))
+	RegisterRule("min_length", intParam(MinLength))
+	RegisterRule("max_length", intParam(MaxLength))
+	RegisterRule("min", floatParam(Min))
+	RegisterRule("max", floatParam(Max))
+	RegisterRule("pattern", func(param string) (*Rule, error) {
+		re, err := regexp.Compile(param)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", param, err)
+		}
+		return Pattern(re), nil
+	})
+	RegisterRule("datetime", func(param string) (*Rule, error) {
+		if param == "" {
+			return nil, fmt.Errorf("layout parameter is required")
+		}
+		return DateTime(param), nil
+	})
+	RegisterAlias("iso8601", "datetime=iso8601")
+	RegisterRule("one_of", listParam(func(values []string) *Rule { return OneOf(values) }))
+	RegisterRule("password", noParam(func() *Rule { return Password(DefaultPasswordPolicy()) }))
+	RegisterRule("one_of_ci", listParam(func(values []string) *Rule { return OneOf(values, IgnoreCase()) }))
+
+	// Formatos
+	RegisterRule("uuid", noParam(func() *Rule { return UUID(0) }))
+	for _, version := range []int{3, 4, 5, 7} {
+		RegisterRule(fmt.Sprintf("uuid%d", version), noParam(func() *Rule { return UUID(version) }))
+	}
+	RegisterRule("ulid", noParam(ULID))
+	RegisterRule("e164", noParam(E164))
+	RegisterRule("iban", noParam(IBAN))
+	RegisterRule("credit_card", noParam(CreditCard))
+	RegisterRule("cpf", noParam(CPF))
+	RegisterRule("cnpj", noParam(CNPJ))
+
+	// Regras cross-field
+	RegisterRule("required_if", fieldValueParam(RequiredIf))
+	RegisterRule("required_unless", fieldValueParam(RequiredUnless))
+	RegisterRule("required_with", fieldParam(RequiredWith))
+	RegisterRule("eq_field", fieldParam(EqualsField))
+	RegisterRule("ne_field", fieldParam(NotEqualsField))
+	RegisterRule("gt_field", fieldParam(GreaterThanField))
+	RegisterRule("gte_field", fieldParam(GreaterOrEqualField))
+	RegisterRule("lt_field", fieldParam(LessThanField))
+	RegisterRule("lte_field", fieldParam(LessOrEqualField))
+}
+
+// RegisterRule registra uma regra para uso em struct tags
+func RegisterRule(name string, factory RuleFactory) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.rules[name] = factory
+}
+
+// RegisterAlias registra um alias expandido para outras regras da tag
+// (ex.: RegisterAlias("iso8601", "datetime=iso8601")), facilitando a migração
+// de tags usadas por outras bibliotecas de validação
+func RegisterAlias(alias, tag string) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.aliases[alias] = tag
+}
+
+// RegisterTagFunc registra uma função de validação para uso em struct tags
+// (ex.: validate:"divisible_by=3"). O parâmetro fica disponível na mensagem como {param}
+func RegisterTagFunc(name, message string, fn TagFunc) {
+	RegisterRule(name, func(param string) (*Rule, error) {
+		rule := NewRule(name, message, func(fc *FieldContext) bool {
+			return fn(fc, param)
+		})
+		if param != "" {
+			rule.params = map[string]any{"param": param}
+		}
+		return rule, nil
+	})
+}
+
+// RegisterPredicate registra um predicado nomeado, usado na tag when=<nome>.
+// As regras seguintes ao when na tag só são aplicadas se o predicado for verdadeiro
+func RegisterPredicate(name string, predicate Predicate) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.predicates[name] = predicate
+}
+
+// lookupRule retorna a fábrica registrada para a regra
+func lookupRule(name string) (RuleFactory, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	factory, ok := registry.rules[name]
+	return factory, ok
+}
+
+// lookupAlias retorna a expansão registrada para o alias
+func lookupAlias(name string) (string, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	tag, ok := registry.aliases[name]
+	return tag, ok
+}
+
+// expandAliases substitui os aliases da tag pelas regras correspondentes
+func expandAliases(tag string, depth int) (string, error) {
+	if depth > maxAliasDepth {
+		return "", fmt.Errorf("alias expansion exceeds %d levels", maxAliasDepth)
+	}
+
+	items := strings.Split(tag, ",")
+	for i, item := range items {
+		name := strings.TrimSpace(item)
+		expansion, ok := lookupAlias(name)
+		if !ok {
+			continue
+		}
+		expanded, err := expandAliases(expansion, depth+1)
+		if err != nil {
+			return "", fmt.Errorf("alias %q: %w", name, err)
+		}
+		items[i] = expanded
+	}
+	return strings.Join(items, ","), nil
+}
+
+// lookupPredicate retorna o predicado registrado
+func lookupPredicate(name string) (Predicate, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	predicate, ok := registry.predicates[name]
+	return predicate, ok
+}
+
+// tagRules regras de um campo. Quando a tag contém "dive", elem guarda as
+// regras aplicadas a cada elemento do slice, array ou map
+type tagRules struct {
+	rules []*Rule
+	elem  *tagRules
+}
+
+// parseFieldTag interpreta a tag completa, separando as regras do campo das
+// regras de elementos declaradas após "dive" (que pode ser repetido para
+// coleções aninhadas)
+func parseFieldTag(tag string) (*tagRules, error) {
+	tag, err := expandAliases(tag, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	before, after, dive := cutItem(tag, "dive")
+
+	rules, err := parseTag(before)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &tagRules{rules: rules}
+	if dive {
+		if result.elem, err = parseFieldTag(after); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// ParseTag interpreta uma tag de validação e retorna as regras por nível: o
+// primeiro item contém as regras do campo e os seguintes as regras dos
+// elementos declaradas após cada "dive". Útil para geradores de schema
+func ParseTag(tag string) ([][]*Rule, error) {
+	rules, err := parseFieldTag(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	var levels [][]*Rule
+	for r := rules; r != nil; r = r.elem {
+		levels = append(levels, r.rules)
+	}
+	return levels, nil
+}
+
+// cutItem separa a tag no primeiro item igual a sep
+func cutItem(tag, sep string) (before, after string, found bool) {
+	items := strings.Split(tag, ",")
+	for i, item := range items {
+		if strings.TrimSpace(item) == sep {
+			return strings.Join(items[:i], ","), strings.Join(items[i+1:], ","), true
+		}
+	}
+	return tag, "", false
+}
+
+// parseTag converte a struct tag em regras. Formato: "regra,regra=param,...".
+// O item "when=<predicado>" condiciona todas as regras seguintes e o item
+// "warn" transforma todas as regras seguintes em avisos
+func parseTag(tag string) ([]*Rule, error) {
+	var rules []*Rule
+	var conditional *Rule
+	var warning bool
+
+	for _, item := range strings.Split(tag, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		name, param, _ := strings.Cut(item, "=")
+
+		if name == "when" {
+			predicate, ok := lookupPredicate(param)
+			if !ok {
+				return nil, fmt.Errorf("unknown predicate %q", param)
+			}
+			conditional = When(predicate)
+			rules = append(rules, conditional)
+			continue
+		}
+
+		if name == "warn" {
+			warning = true
+			continue
+		}
+
+		factory, ok := lookupRule(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown validation rule %q", name)
+		}
+
+		rule, err := factory(param)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", name, err)
+		}
+		if warning {
+			rule = rule.AsWarning()
+		}
+
+		if conditional != nil {
+			conditional.children = append(conditional.children, rule)
+		} else {
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules, nil
+}
+
+// fieldMeta metadados de um campo exportado de uma struct
+type fieldMeta struct {
+	index   int
+	goName  string
+	name    string
+	tags    reflect.StructTag
+	message string
+}
+
+// structMeta metadados de uma struct, com busca por nome Go ou nome JSON
+type structMeta struct {
+	fields []fieldMeta
+	lookup map[string]int
+}
+
+var structMetaCache sync.Map
+
+// getStructMeta retorna (e armazena em cache) os metadados da struct
+func getStructMeta(t reflect.Type) *structMeta {
+	if cached, ok := structMetaCache.Load(t); ok {
+		return cached.(*structMeta)
+	}
+
+	meta := &structMeta{lookup: make(map[string]int)}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		name := sf.Name
+		if jsonName, _, _ := strings.Cut(sf.Tag.Get("json"), ","); jsonName != "" && jsonName != "-" {
+			name = jsonName
+		}
+
+		meta.fields = append(meta.fields, fieldMeta{
+			index:   i,
+			goName:  sf.Name,
+			name:    name,
+			tags:    sf.Tag,
+			message: sf.Tag.Get(MessageTagName),
+		})
+		idx := len(meta.fields) - 1
+		meta.lookup[sf.Name] = idx
+		meta.lookup[name] = idx
+	}
+
+	actual, _ := structMetaCache.LoadOrStore(t, meta)
+	return actual.(*structMeta)
+}
+
+// noParam adapta regras sem parâmetro
+func noParam(fn func() *Rule) RuleFactory {
+	return func(param string) (*Rule, error) {
+		if param != "" {
+			return nil, fmt.Errorf("unexpected parameter %q", param)
+		}
+		return fn(), nil
+	}
+}
+
+// intParam adapta regras com parâmetro inteiro
+func intParam(fn func(int) *Rule) RuleFactory {
+	return func(param string) (*Rule, error) {
+		n, err := strconv.Atoi(param)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer parameter %q", param)
+		}
+		return fn(n), nil
+	}
+}
+
+// floatParam adapta regras com parâmetro numérico
+func floatParam(fn func(float64) *Rule) RuleFactory {
+	return func(param string) (*Rule, error) {
+		f, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid numeric parameter %q", param)
+		}
+		return fn(f), nil
+	}
+}
+
+// fieldParam adapta regras que referenciam outro campo
+func fieldParam(fn func(string) *Rule) RuleFactory {
+	return func(param string) (*Rule, error) {
+		if param == "" {
+			return nil, fmt.Errorf("field parameter is required")
+		}
+		return fn(param), nil
+	}
+}
+
+// fieldValueParam adapta regras no formato "Campo valor"
+func fieldValueParam(fn func(string, any) *Rule) RuleFactory {
+	return func(param string) (*Rule, error) {
+		field, value, ok := strings.Cut(param, " ")
+		if !ok || field == "" {
+			return nil, fmt.Errorf("expected parameter in the format \"field value\", got %q", param)
+		}
+		return fn(field, value), nil
+	}
+}
+
+// listParam adapta regras com uma lista de valores separados por espaço
+// (ex.: one_of=active blocked)
+func listParam(fn func([]string) *Rule) RuleFactory {
+	return func(param string) (*Rule, error) {
+		values := strings.Fields(param)
+		if len(values) == 0 {
+			return nil, fmt.Errorf("at least one value is required")
+		}
+		return fn(values), nil
+	}
+}
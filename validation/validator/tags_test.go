@@ -0,0 +1,76 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type bindingRequest struct {
+	Name      string `json:"name" binding:"required" validate:"min_length=100"`
+	CreatedAt string `json:"created_at" binding:"iso8601"`
+}
+
+func TestWithTagName(t *testing.T) {
+	v := New(WithTagName("binding"))
+
+	errs := validationErrors(t, v.ValidateStruct(bindingRequest{CreatedAt: "2025-01-10"}))
+	assert.Equal(t, []string{"name", "created_at"}, errs.Fields())
+	assert.Equal(t, "datetime", errs[1].Rule)
+
+	assert.NoError(t, v.ValidateStruct(bindingRequest{Name: "John", CreatedAt: "2025-01-10T10:00:00Z"}))
+
+	// O Validator padrão continua lendo a tag validate
+	errs = validationErrors(t, ValidateStruct(bindingRequest{Name: "John"}))
+	assert.Equal(t, "min_length", errs[0].Rule)
+}
+
+type aliasRequest struct {
+	Username string `json:"username" validate:"username"`
+	Birthday string `json:"birthday" validate:"datetime=date"`
+}
+
+func TestRegisterAlias(t *testing.T) {
+	RegisterAlias("username", "required,min_length=3,max_length=16")
+
+	errs := validationErrors(t, ValidateStruct(aliasRequest{Birthday: "10/01/2025"}))
+	assert.Equal(t, "required", errs.ByField("username")[0].Rule)
+	assert.Equal(t, "must be a valid date/time in the format 2006-01-02", errs.ByField("birthday")[0].Message)
+
+	assert.NoError(t, ValidateStruct(aliasRequest{Username: "john", Birthday: "2025-01-10"}))
+
+	RegisterAlias("loop_a", "loop_b")
+	RegisterAlias("loop_b", "loop_a")
+	_, err := parseFieldTag("loop_a")
+	assert.ErrorContains(t, err, "alias expansion")
+}
+
+type tagFuncRequest struct {
+	Quantity int `json:"quantity" validate:"divisible_by=3"`
+}
+
+func TestRegisterTagFunc(t *testing.T) {
+	RegisterTagFunc("divisible_by", "must be divisible by {param}", func(fc *FieldContext, param string) bool {
+		n, ok := toFloat(fc.Value)
+		return ok && param == "3" && int(n)%3 == 0
+	})
+
+	assert.NoError(t, ValidateStruct(tagFuncRequest{Quantity: 9}))
+
+	errs := validationErrors(t, ValidateStruct(tagFuncRequest{Quantity: 10}))
+	require.Len(t, errs, 1)
+	assert.Equal(t, "must be divisible by 3", errs[0].Message)
+	assert.Equal(t, map[string]any{"param": "3"}, errs[0].Params)
+}
+
+func TestDateTime(t *testing.T) {
+	rules := NewRuleBuilder().Field("at", DateTime("iso8601")).Build()
+
+	assert.NoError(t, Validate(map[string]string{"at": "2025-01-10T10:00:00.123-03:00"}, rules))
+	assert.Error(t, Validate(map[string]string{"at": "2025-01-10 10:00"}, rules))
+	assert.Error(t, Validate(map[string]int{"at": 10}, rules))
+
+	_, err := parseFieldTag("datetime")
+	assert.Error(t, err)
+}
@@ -0,0 +1,498 @@
+// Package validator fornece validação de structs e maps baseada em regras,
+// declaradas via struct tags ou programaticamente pelo RuleBuilder
+package validator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidInput indica que o valor informado não pode ser validado
+var ErrInvalidInput = errors.New("validator: input must be a struct, a pointer to struct or a map")
+
+// ErrMaxDepthExceeded indica que a estrutura excede a profundidade máxima de
+// navegação (normalmente causada por referências cíclicas)
+var ErrMaxDepthExceeded = errors.New("validator: max depth exceeded")
+
+// DefaultMaxDepth profundidade máxima padrão de navegação em estruturas aninhadas
+const DefaultMaxDepth = 32
+
+// NilPolicy define o tratamento de ponteiros nil para structs aninhadas
+type NilPolicy int
+
+const (
+	// NilSkip ignora structs aninhadas nil (padrão). Use a regra required
+	// no campo para exigir sua presença
+	NilSkip NilPolicy = iota
+	// NilAsZero valida structs aninhadas nil como se fossem o valor zero,
+	// reportando os campos obrigatórios ausentes
+	NilAsZero
+)
+
+// Option configura o Validator
+type Option func(*Validator)
+
+// WithMaxDepth define a profundidade máxima de navegação em structs, slices e maps
+func WithMaxDepth(depth int) Option {
+	return func(v *Validator) {
+		if depth > 0 {
+			v.maxDepth = depth
+		}
+	}
+}
+
+// WithTagName define a chave da struct tag lida pelo Validator (ex.: "binding"),
+// permitindo reutilizar tags de outras bibliotecas durante a migração
+func WithTagName(name string) Option {
+	return func(v *Validator) {
+		if name != "" {
+			v.tagName = name
+		}
+	}
+}
+
+// WithNilPolicy define o tratamento de ponteiros nil para structs aninhadas
+func WithNilPolicy(policy NilPolicy) Option {
+	return func(v *Validator) {
+		v.nilPolicy = policy
+	}
+}
+
+// WithWriteBack grava os valores sanitizados de volta na struct ou map
+// validado. Requer um ponteiro para struct; sem esta opção os sanitizadores
+// afetam apenas a validação e os dados originais permanecem intactos
+func WithWriteBack() Option {
+	return func(v *Validator) {
+		v.writeBack = true
+	}
+}
+
+// compiledField regras já interpretadas de um campo da struct
+type compiledField struct {
+	meta       fieldMeta
+	rules      *tagRules
+	sanitizers []Sanitizer
+}
+
+// Validator valida structs usando as regras declaradas nas struct tags,
+// navegando em structs, slices e maps aninhados. É seguro para uso concorrente
+type Validator struct {
+	tagName   string
+	maxDepth  int
+	nilPolicy NilPolicy
+	writeBack bool
+	messages  map[string]string
+	cache     sync.Map // reflect.Type -> []compiledField
+}
+
+// New cria um novo Validator
+func New(opts ...Option) *Validator {
+	v := &Validator{
+		tagName:   DefaultTagName,
+		maxDepth:  DefaultMaxDepth,
+		nilPolicy: NilSkip,
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+var defaultValidator = New()
+
+// ValidateStruct valida uma struct usando o Validator padrão
+func ValidateStruct(s interface{}) error {
+	return defaultValidator.ValidateStruct(s)
+}
+
+// Validate valida dados usando o RuleSet informado e o Validator padrão
+func Validate(data interface{}, rules *RuleSet) error {
+	return defaultValidator.Validate(data, rules)
+}
+
+// ValidateStructResult valida uma struct usando o Validator padrão,
+// retornando erros e avisos
+func ValidateStructResult(s interface{}) (ValidationResult, error) {
+	return defaultValidator.ValidateStructResult(s)
+}
+
+// ValidateResult valida dados usando o RuleSet informado e o Validator
+// padrão, retornando erros e avisos
+func ValidateResult(data interface{}, rules *RuleSet) (ValidationResult, error) {
+	return defaultValidator.ValidateResult(data, rules)
+}
+
+// ValidateStruct valida a struct usando as regras das struct tags, incluindo
+// structs aninhadas e elementos de slices e maps. Os erros usam caminhos como
+// items[3].price e attributes["color"]. Retorna ValidationErrors quando há
+// falhas de validação
+func (v *Validator) ValidateStruct(s interface{}) error {
+	result, err := v.validateRoot(context.Background(), ModeCreate, nil, s)
+	if err != nil {
+		return err
+	}
+	return result.Err()
+}
+
+// ValidateStructResult valida a struct como ValidateStruct, retornando
+// também os avisos das regras AsWarning. O erro é reservado para falhas que
+// impedem a validação, como ErrInvalidInput
+func (v *Validator) ValidateStructResult(s interface{}) (ValidationResult, error) {
+	return v.validateRoot(context.Background(), ModeCreate, nil, s)
+}
+
+// maxPooledErrors capacidade máxima do buffer de erros devolvido ao pool,
+// evitando reter buffers grandes após validações com muitas falhas
+const maxPooledErrors = 64
+
+// run estado de uma execução de validação. É reutilizado via runPool para
+// que validações bem-sucedidas não aloquem memória
+type run struct {
+	ctx   context.Context
+	mode  Mode
+	scope fieldSet
+	errs  ValidationErrors
+	warns ValidationErrors
+}
+
+var runPool = sync.Pool{New: func() any { return new(run) }}
+
+var fieldContextPool = sync.Pool{New: func() any { return new(FieldContext) }}
+
+// newRun obtém um estado de validação do pool
+func newRun(ctx context.Context, mode Mode, scope fieldSet) *run {
+	r := runPool.Get().(*run)
+	r.ctx, r.mode, r.scope = ctx, mode, scope
+	return r
+}
+
+// release devolve o estado ao pool, retornando cópias dos erros e avisos
+// acumulados (nil quando não há)
+func (r *run) release() ValidationResult {
+	var result ValidationResult
+	if len(r.errs) > 0 {
+		result.Errors = make(ValidationErrors, len(r.errs))
+		copy(result.Errors, r.errs)
+	}
+	if len(r.warns) > 0 {
+		result.Warnings = make(ValidationErrors, len(r.warns))
+		copy(result.Warnings, r.warns)
+	}
+
+	clear(r.errs)
+	clear(r.warns)
+	*r = run{errs: r.errs[:0], warns: r.warns[:0]}
+	if cap(r.errs) <= maxPooledErrors && cap(r.warns) <= maxPooledErrors {
+		runPool.Put(r)
+	}
+	return result
+}
+
+// newFieldContext obtém um FieldContext do pool
+func newFieldContext(r *run, parent, root reflect.Value, messages map[string]string) *FieldContext {
+	fc := fieldContextPool.Get().(*FieldContext)
+	*fc = FieldContext{Parent: parent, Root: root, Mode: r.mode, ctx: r.ctx, messages: messages}
+	return fc
+}
+
+// releaseFieldContext devolve o FieldContext ao pool
+func releaseFieldContext(fc *FieldContext) {
+	*fc = FieldContext{}
+	fieldContextPool.Put(fc)
+}
+
+// validateRoot valida a struct raiz no modo e escopo informados
+func (v *Validator) validateRoot(ctx context.Context, mode Mode, scope fieldSet, s interface{}) (ValidationResult, error) {
+	root := indirect(reflect.ValueOf(s))
+	if !root.IsValid() || root.Kind() != reflect.Struct {
+		return ValidationResult{}, ErrInvalidInput
+	}
+
+	r := newRun(ctx, mode, scope)
+	if err := v.validateStruct(r, root, root, "", 0); err != nil {
+		r.release()
+		return ValidationResult{}, err
+	}
+	return r.release(), nil
+}
+
+// validateStruct aplica as regras dos campos da struct e navega nos valores aninhados
+func (v *Validator) validateStruct(r *run, root, sv reflect.Value, prefix string, depth int) error {
+	if depth > v.maxDepth {
+		return fmt.Errorf("%w at %q (max depth %d)", ErrMaxDepthExceeded, prefix, v.maxDepth)
+	}
+
+	fields, err := v.compile(sv.Type())
+	if err != nil {
+		return err
+	}
+
+	if hasSanitizers(fields) {
+		sv = v.sanitizeStruct(sv, fields)
+		if depth == 0 {
+			root = sv
+		}
+	}
+
+	// O mesmo FieldContext é reutilizado por todos os campos da struct
+	fc := newFieldContext(r, sv, root, v.messages)
+	defer releaseFieldContext(fc)
+
+	for _, field := range fields {
+		path := joinPath(prefix, field.meta.name)
+		if !r.scope.visits(path) {
+			continue
+		}
+
+		fc.Path = path
+		fc.Name = field.meta.name
+		fc.Value = sv.Field(field.meta.index)
+		if err := v.validateValue(r, fc, field.rules, depth); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateValue aplica as regras ao valor e navega em structs e coleções aninhadas
+func (v *Validator) validateValue(r *run, fc *FieldContext, rules *tagRules, depth int) error {
+	if rules != nil && r.scope.includes(fc.Path) {
+		for _, rule := range rules.rules {
+			rule.apply(fc, &r.errs, &r.warns)
+		}
+	}
+
+	value := fc.Value
+	if value.Kind() == reflect.Ptr && value.IsNil() && v.nilPolicy == NilAsZero &&
+		value.Type().Elem().Kind() == reflect.Struct {
+		value = reflect.Zero(value.Type().Elem())
+	}
+	value = indirect(value)
+	if !value.IsValid() {
+		return nil
+	}
+
+	var elem *tagRules
+	if rules != nil {
+		elem = rules.elem
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		if _, ok := asTime(value); ok {
+			return nil
+		}
+		return v.validateStruct(r, fc.Root, value, fc.Path, depth+1)
+	case reflect.Slice, reflect.Array:
+		if elem == nil && !hasStructElem(value.Type()) {
+			return nil
+		}
+		elemCtx := newFieldContext(r, fc.Parent, fc.Root, fc.messages)
+		defer releaseFieldContext(elemCtx)
+		for i := 0; i < value.Len(); i++ {
+			path := fc.Path + "[" + strconv.Itoa(i) + "]"
+			if err := v.validateElem(r, elemCtx, fc.Name, value.Index(i), path, elem, depth); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if elem == nil && !hasStructElem(value.Type()) {
+			return nil
+		}
+		elemCtx := newFieldContext(r, fc.Parent, fc.Root, fc.messages)
+		defer releaseFieldContext(elemCtx)
+		for _, key := range sortedKeys(value) {
+			if err := v.validateElem(r, elemCtx, fc.Name, value.MapIndex(key), fc.Path+formatKey(key), elem, depth); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateElem valida um elemento de slice, array ou map usando o contexto
+// compartilhado pelos elementos da coleção. O Parent continua sendo a struct
+// que contém a coleção, permitindo regras cross-field
+func (v *Validator) validateElem(r *run, elemCtx *FieldContext, name string, value reflect.Value, path string, rules *tagRules, depth int) error {
+	if depth+1 > v.maxDepth {
+		return fmt.Errorf("%w at %q (max depth %d)", ErrMaxDepthExceeded, path, v.maxDepth)
+	}
+
+	elemCtx.Path = path
+	elemCtx.Name = name
+	elemCtx.Value = value
+	return v.validateValue(r, elemCtx, rules, depth+1)
+}
+
+// Validate valida uma struct ou map[string]T usando o RuleSet informado
+func (v *Validator) Validate(data interface{}, rules *RuleSet) error {
+	result, err := v.ValidateResult(data, rules)
+	if err != nil {
+		return err
+	}
+	return result.Err()
+}
+
+// ValidateResult valida os dados como Validate, retornando também os avisos
+// das regras AsWarning
+func (v *Validator) ValidateResult(data interface{}, rules *RuleSet) (ValidationResult, error) {
+	if rules == nil {
+		return ValidationResult{}, errors.New("validator: rule set cannot be nil")
+	}
+
+	root := indirect(reflect.ValueOf(data))
+	if !root.IsValid() || (root.Kind() != reflect.Struct && root.Kind() != reflect.Map) {
+		return ValidationResult{}, ErrInvalidInput
+	}
+
+	// Os sanitizadores são aplicados antes de todas as regras, para que
+	// regras cross-field também enxerguem os valores normalizados
+	for _, field := range rules.fields {
+		if len(field.sanitizers) > 0 {
+			root = v.sanitizePath(root, strings.Split(field.field, "."), field.sanitizers)
+		}
+	}
+
+	r := newRun(context.Background(), ModeCreate, nil)
+	fc := newFieldContext(r, root, root, v.messages)
+	defer releaseFieldContext(fc)
+
+	for _, field := range rules.fields {
+		if err := resolvePath(root, field.field, fc); err != nil {
+			r.release()
+			return ValidationResult{}, err
+		}
+		for _, rule := range field.rules {
+			rule.apply(fc, &r.errs, &r.warns)
+		}
+	}
+	return r.release(), nil
+}
+
+// compile interpreta (e armazena em cache) as struct tags do tipo
+func (v *Validator) compile(t reflect.Type) ([]compiledField, error) {
+	if cached, ok := v.cache.Load(t); ok {
+		return cached.([]compiledField), nil
+	}
+
+	meta := getStructMeta(t)
+	fields := make([]compiledField, 0, len(meta.fields))
+	for _, fm := range meta.fields {
+		tag := fm.tags.Get(v.tagName)
+		if tag == "-" {
+			continue
+		}
+		rules, err := parseFieldTag(tag)
+		if err != nil {
+			return nil, fmt.Errorf("validator: invalid tag on %s.%s: %w", t.Name(), fm.goName, err)
+		}
+		applyMessageTag(rules, fm.message)
+
+		sanitizers, err := parseSanitizeTag(fm.tags.Get(SanitizeTagName))
+		if err != nil {
+			return nil, fmt.Errorf("validator: invalid sanitize tag on %s.%s: %w", t.Name(), fm.goName, err)
+		}
+		fields = append(fields, compiledField{meta: fm, rules: rules, sanitizers: sanitizers})
+	}
+
+	actual, _ := v.cache.LoadOrStore(t, fields)
+	return actual.([]compiledField), nil
+}
+
+// hasSanitizers verifica se algum campo declara sanitizadores
+func hasSanitizers(fields []compiledField) bool {
+	for _, field := range fields {
+		if len(field.sanitizers) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// hasStructElem verifica se os elementos da coleção são structs (ou ponteiros
+// para structs) que devem ser navegadas mesmo sem "dive"
+func hasStructElem(t reflect.Type) bool {
+	elem := t.Elem()
+	for elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	return elem.Kind() == reflect.Struct && elem != reflect.TypeOf(time.Time{})
+}
+
+// joinPath concatena o prefixo e o nome do campo
+func joinPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// formatKey formata a chave de map para o caminho do erro (ex.: ["color"])
+func formatKey(key reflect.Value) string {
+	if key.Kind() == reflect.String {
+		return "[" + strconv.Quote(key.String()) + "]"
+	}
+	return fmt.Sprintf("[%v]", key.Interface())
+}
+
+// sortedKeys retorna as chaves do map ordenadas, garantindo erros determinísticos
+func sortedKeys(m reflect.Value) []reflect.Value {
+	keys := m.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		if cmp, ok := compareValues(keys[i], keys[j]); ok {
+			return cmp < 0
+		}
+		return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+	})
+	return keys
+}
+
+// resolvePath localiza o campo pelo caminho com pontos, preenchendo o contexto
+// com a estrutura que o contém. Campos ausentes em maps são tratados como vazios
+func resolvePath(root reflect.Value, path string, fc *FieldContext) error {
+	parent := root
+	var names strings.Builder
+
+	for rest := path; ; {
+		part, next, more := strings.Cut(rest, ".")
+
+		container := indirect(parent)
+		value, ok := lookupField(container, part)
+		if !ok && container.IsValid() && container.Kind() == reflect.Struct {
+			return fmt.Errorf("validator: field %q not found in %s", path, container.Type())
+		}
+
+		name := part
+		if container.IsValid() && container.Kind() == reflect.Struct {
+			meta := getStructMeta(container.Type())
+			name = meta.fields[meta.lookup[part]].name
+		}
+
+		if !more {
+			fc.Name = name
+			fc.Value = value
+			fc.Parent = parent
+			fc.Root = root
+			fc.Path = name
+			if names.Len() > 0 {
+				names.WriteString(name)
+				fc.Path = names.String()
+			}
+			return nil
+		}
+
+		names.WriteString(name)
+		names.WriteByte('.')
+		parent = value
+		rest = next
+	}
+}
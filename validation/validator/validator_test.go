@@ -0,0 +1,367 @@
+package validator
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type signupRequest struct {
+	Name            string `json:"name" validate:"required,min_length=3,max_length=20"`
+	Email           string `json:"email" validate:"required,email"`
+	Password        string `json:"password" validate:"required,min_length=8"`
+	PasswordConfirm string `json:"password_confirm" validate:"eq_field=Password"`
+	AccountType     string `json:"account_type"`
+	CompanyName     string `json:"company_name" validate:"required_if=account_type business"`
+	Age             int    `json:"age" validate:"min=18,max=130"`
+}
+
+type bookingRequest struct {
+	StartDate time.Time `json:"start_date" validate:"required"`
+	EndDate   time.Time `json:"end_date" validate:"required,gt_field=start_date"`
+	Guests    int       `json:"guests"`
+	MaxGuests int       `json:"max_guests" validate:"gte_field=Guests"`
+}
+
+func validationErrors(t *testing.T, err error) ValidationErrors {
+	t.Helper()
+	var errs ValidationErrors
+	require.True(t, errors.As(err, &errs), "expected ValidationErrors, got %v", err)
+	return errs
+}
+
+func TestValidateStruct_Valid(t *testing.T) {
+	req := signupRequest{
+		Name:            "John",
+		Email:           "john@example.com",
+		Password:        "supersecret",
+		PasswordConfirm: "supersecret",
+		AccountType:     "personal",
+		Age:             30,
+	}
+
+	assert.NoError(t, ValidateStruct(req))
+	assert.NoError(t, ValidateStruct(&req))
+}
+
+func TestValidateStruct_SingleFieldRules(t *testing.T) {
+	err := ValidateStruct(signupRequest{Name: "Jo", Email: "invalid", Age: 10})
+	errs := validationErrors(t, err)
+
+	assert.Equal(t, "min_length", errs.ByField("name")[0].Rule)
+	assert.Equal(t, "must have at least 3 characters", errs.ByField("name")[0].Message)
+	assert.Equal(t, "email", errs.ByField("email")[0].Rule)
+	assert.Equal(t, "required", errs.ByField("password")[0].Rule)
+	assert.Equal(t, "min", errs.ByField("age")[0].Rule)
+}
+
+func TestValidateStruct_CrossField(t *testing.T) {
+	req := signupRequest{
+		Name:            "John",
+		Email:           "john@example.com",
+		Password:        "supersecret",
+		PasswordConfirm: "different",
+		AccountType:     "business",
+		Age:             30,
+	}
+
+	errs := validationErrors(t, ValidateStruct(req))
+	assert.Equal(t, []string{"password_confirm", "company_name"}, errs.Fields())
+	assert.Equal(t, "eq_field", errs.ByField("password_confirm")[0].Rule)
+	assert.Equal(t, "required_if", errs.ByField("company_name")[0].Rule)
+	assert.Equal(t, "is required when account_type is business", errs.ByField("company_name")[0].Message)
+
+	req.PasswordConfirm = "supersecret"
+	req.CompanyName = "ACME"
+	assert.NoError(t, ValidateStruct(req))
+}
+
+func TestValidateStruct_CompareFields(t *testing.T) {
+	start := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	err := ValidateStruct(bookingRequest{StartDate: start, EndDate: start.Add(-time.Hour), Guests: 4, MaxGuests: 2})
+	errs := validationErrors(t, err)
+	assert.Equal(t, "gt_field", errs.ByField("end_date")[0].Rule)
+	assert.Equal(t, "gte_field", errs.ByField("max_guests")[0].Rule)
+
+	assert.NoError(t, ValidateStruct(bookingRequest{StartDate: start, EndDate: start.Add(time.Hour), Guests: 2, MaxGuests: 2}))
+}
+
+type conditionalRequest struct {
+	DocumentType string `json:"document_type"`
+	Document     string `json:"document" validate:"when=is_company,required,min_length=14"`
+}
+
+func TestValidateStruct_WhenTag(t *testing.T) {
+	RegisterPredicate("is_company", FieldEquals("document_type", "cnpj"))
+
+	assert.NoError(t, ValidateStruct(conditionalRequest{DocumentType: "cpf"}))
+
+	errs := validationErrors(t, ValidateStruct(conditionalRequest{DocumentType: "cnpj", Document: "123"}))
+	assert.Equal(t, "min_length", errs[0].Rule)
+}
+
+type invalidTagRequest struct {
+	Name string `validate:"unknown_rule"`
+}
+
+func TestValidateStruct_InvalidInput(t *testing.T) {
+	assert.ErrorIs(t, ValidateStruct("string"), ErrInvalidInput)
+	assert.ErrorIs(t, ValidateStruct(nil), ErrInvalidInput)
+
+	err := ValidateStruct(invalidTagRequest{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown validation rule")
+}
+
+func TestRuleBuilder(t *testing.T) {
+	rules := NewRuleBuilder().
+		Field("password", Required(), MinLength(8)).
+		Field("password_confirm", EqualsField("password")).
+		Field("end", GreaterThanField("start")).
+		When(FieldEquals("type", "business"), "company", Required()).
+		Field("code", Pattern(regexp.MustCompile(`^[A-Z]{3}$`))).
+		Build()
+
+	assert.Equal(t, []string{"password", "password_confirm", "end", "company", "code"}, rules.Fields())
+
+	data := map[string]interface{}{
+		"password":         "supersecret",
+		"password_confirm": "supersecret",
+		"start":            10,
+		"end":              20,
+		"type":             "personal",
+		"code":             "ABC",
+	}
+	assert.NoError(t, Validate(data, rules))
+
+	data["type"] = "business"
+	data["end"] = 5
+	data["code"] = "abc"
+	errs := validationErrors(t, Validate(data, rules))
+	assert.Equal(t, []string{"end", "company", "code"}, errs.Fields())
+}
+
+func TestNotEqualsField(t *testing.T) {
+	rules := NewRuleBuilder().Field("new_password", NotEqualsField("password")).Build()
+
+	assert.NoError(t, Validate(map[string]interface{}{"password": "old", "new_password": "new"}, rules))
+
+	errs := validationErrors(t, Validate(map[string]interface{}{"password": "same", "new_password": "same"}, rules))
+	assert.Equal(t, "ne_field", errs[0].Rule)
+
+	errs = validationErrors(t, Validate(map[string]interface{}{"new_password": "new"}, rules))
+	assert.Equal(t, "ne_field", errs[0].Rule)
+}
+
+type address struct {
+	City    string `json:"city"`
+	Country string `json:"country"`
+}
+
+type customer struct {
+	Name    string  `json:"name"`
+	Address address `json:"address"`
+}
+
+func TestRuleBuilder_NestedPaths(t *testing.T) {
+	rules := NewRuleBuilder().
+		Field("Address.City", Required()).
+		Field("address.country", RequiredWith("city")).
+		Build()
+
+	errs := validationErrors(t, Validate(customer{Address: address{City: "Lisbon"}}, rules))
+	require.Len(t, errs, 1)
+	assert.Equal(t, "address.country", errs[0].Field)
+
+	err := Validate(customer{}, NewRuleBuilder().Field("missing", Required()).Build())
+	require.Error(t, err)
+	assert.NotErrorAs(t, err, &ValidationErrors{})
+}
+
+func TestNewRule(t *testing.T) {
+	even := NewRule("even", "must be even", func(fc *FieldContext) bool {
+		n, ok := toFloat(fc.Value)
+		return ok && int(n)%2 == 0
+	})
+
+	rules := NewRuleBuilder().Field("n", even).Build()
+	assert.NoError(t, Validate(map[string]int{"n": 2}, rules))
+	assert.Error(t, Validate(map[string]int{"n": 3}, rules))
+	// Valores ausentes são ignorados por regras que não são de presença
+	assert.NoError(t, Validate(map[string]int{}, rules))
+}
+
+func TestValidationErrors(t *testing.T) {
+	errs := ValidationErrors{
+		{Field: "name", Rule: "required", Message: "is required"},
+		{Field: "name", Rule: "min_length", Message: "too short"},
+		{Field: "email", Rule: "email", Message: "invalid"},
+	}
+
+	assert.True(t, errs.HasErrors())
+	assert.Equal(t, "name: is required; name: too short; email: invalid", errs.Error())
+	assert.Equal(t, []string{"name", "email"}, errs.Fields())
+	assert.Equal(t, map[string][]string{"name": {"is required", "too short"}, "email": {"invalid"}}, errs.ToMap())
+}
+
+type warningRequest struct {
+	Name     string `json:"name" validate:"required,warn,max_length=10"`
+	Nickname string `json:"nickname" validate:"warn,when=is_company,min_length=3"`
+	Document string `json:"document_type"`
+}
+
+func TestValidateStruct_Warnings(t *testing.T) {
+	RegisterPredicate("is_company", FieldEquals("document_type", "cnpj"))
+
+	// Avisos não bloqueiam a validação
+	request := warningRequest{Name: "a very long name", Nickname: "ab", Document: "cnpj"}
+	require.NoError(t, ValidateStruct(request))
+
+	result, err := ValidateStructResult(request)
+	require.NoError(t, err)
+	assert.True(t, result.Valid())
+	assert.True(t, result.HasWarnings())
+	assert.NoError(t, result.Err())
+	assert.Equal(t, []string{"name", "nickname"}, result.Warnings.Fields())
+
+	result, err = ValidateStructResult(warningRequest{})
+	require.NoError(t, err)
+	assert.False(t, result.Valid())
+	assert.False(t, result.HasWarnings())
+	assert.Equal(t, "required", result.Errors[0].Rule)
+
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"errors":[{"field":"name","rule":"required","message":"is required","value":""}]}`, string(data))
+
+	_, err = ValidateStructResult("string")
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
+
+func TestRuleBuilder_Warnings(t *testing.T) {
+	deprecated := NewRule("deprecated", "is deprecated", func(fc *FieldContext) bool { return false })
+	rules := NewRuleBuilder().
+		Field("name", Required()).
+		Field("legacy_id", deprecated.AsWarning()).
+		When(FieldEquals("type", "business"), "company", Required()).
+		Build()
+
+	assert.False(t, deprecated.IsWarning())
+
+	data := map[string]interface{}{"name": "John", "legacy_id": "42"}
+	require.NoError(t, Validate(data, rules))
+
+	result, err := ValidateResult(data, rules)
+	require.NoError(t, err)
+	require.Len(t, result.Warnings, 1)
+	assert.Equal(t, "deprecated", result.Warnings[0].Rule)
+
+	data = map[string]interface{}{"legacy_id": "42"}
+	result, err = ValidateResult(data, rules)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"name"}, result.Errors.Fields())
+	assert.Equal(t, []string{"legacy_id"}, result.Warnings.Fields())
+
+	body, err := json.Marshal(result)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"errors": [{"field":"name","rule":"required","message":"is required"}],
+		"warnings": [{"field":"legacy_id","rule":"deprecated","message":"is deprecated","value":"42"}]
+	}`, string(body))
+}
+
+type orderItem struct {
+	SKU   string  `json:"sku" validate:"required"`
+	Price float64 `json:"price" validate:"min=0.01"`
+}
+
+type attribute struct {
+	Value string `json:"value" validate:"required"`
+}
+
+type shipping struct {
+	Street string `json:"street" validate:"required"`
+}
+
+type order struct {
+	ID         string               `json:"id" validate:"required"`
+	Items      []orderItem          `json:"items" validate:"min_length=1"`
+	Refs       []*orderItem         `json:"refs"`
+	Attributes map[string]attribute `json:"attributes"`
+	Tags       []string             `json:"tags" validate:"max_length=3,dive,required,min_length=2"`
+	Labels     map[string]string    `json:"labels" validate:"dive,max_length=5"`
+	Matrix     [][]int              `json:"matrix" validate:"dive,dive,min=1"`
+	Shipping   *shipping            `json:"shipping"`
+	Internal   orderItem            `json:"-" validate:"-"`
+}
+
+func validOrder() order {
+	return order{
+		ID:         "1",
+		Items:      []orderItem{{SKU: "a", Price: 1}},
+		Attributes: map[string]attribute{"color": {Value: "red"}},
+		Tags:       []string{"new"},
+		Labels:     map[string]string{"env": "prod"},
+		Matrix:     [][]int{{1, 2}},
+	}
+}
+
+func TestValidateStruct_Dive(t *testing.T) {
+	assert.NoError(t, ValidateStruct(validOrder()))
+
+	o := validOrder()
+	o.Items = append(o.Items, orderItem{SKU: "b"}, orderItem{Price: 2}, orderItem{SKU: "d", Price: -1})
+	o.Refs = []*orderItem{nil, {Price: 1}}
+	o.Attributes["size"] = attribute{}
+	o.Tags = []string{"ok", "", "x"}
+	o.Labels["region"] = "sa-east-1"
+	o.Matrix = [][]int{{1}, {1, -1}}
+	o.Shipping = &shipping{}
+
+	errs := validationErrors(t, ValidateStruct(o))
+	assert.Equal(t, []string{
+		"items[2].sku",
+		"items[3].price",
+		"refs[1].sku",
+		`attributes["size"].value`,
+		"tags[1]",
+		"tags[2]",
+		`labels["region"]`,
+		"matrix[1][1]",
+		"shipping.street",
+	}, errs.Fields())
+}
+
+func TestValidateStruct_NilPolicy(t *testing.T) {
+	o := validOrder()
+	assert.NoError(t, ValidateStruct(o))
+
+	v := New(WithNilPolicy(NilAsZero))
+	errs := validationErrors(t, v.ValidateStruct(o))
+	assert.Equal(t, []string{"shipping.street"}, errs.Fields())
+}
+
+type node struct {
+	Name string `json:"name" validate:"required"`
+	Next *node  `json:"next"`
+}
+
+func TestValidateStruct_MaxDepth(t *testing.T) {
+	list := &node{Name: "a", Next: &node{Name: "b", Next: &node{}}}
+
+	errs := validationErrors(t, ValidateStruct(list))
+	assert.Equal(t, []string{"next.next.name"}, errs.Fields())
+
+	err := New(WithMaxDepth(1)).ValidateStruct(list)
+	assert.ErrorIs(t, err, ErrMaxDepthExceeded)
+
+	cyclic := &node{Name: "loop"}
+	cyclic.Next = cyclic
+	assert.ErrorIs(t, ValidateStruct(cyclic), ErrMaxDepthExceeded)
+}